@@ -277,7 +277,7 @@ func main() {
 			Fatal("Could not create archiver engine")
 	}
 
-	health.InitHeartbeat(rootScope, cfg.Health, nil)
+	health.InitHeartbeat(rootScope, cfg.Health, nil, archiverEngine.FreezeState())
 	log.Info("Started archiver")
 
 	if err := archiverEngine.Start(); err != nil {
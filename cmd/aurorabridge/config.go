@@ -17,6 +17,7 @@ package main
 import (
 	"github.com/uber/peloton/pkg/aurorabridge"
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -38,4 +39,5 @@ type Config struct {
 	EventPublisher aurorabridge.EventPublisherConfig `yaml:"event_publisher"`
 	Auth           auth.Config                       `yaml:"auth"`
 	RateLimit      inbound.RateLimitConfig           `yaml:"rate_limit"`
+	Freeze         freeze.Config                     `yaml:"freeze"`
 }
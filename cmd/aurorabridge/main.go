@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -35,6 +36,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -355,8 +357,20 @@ func main() {
 		"httpPort": cfg.HTTPPort,
 	}).Info("Started Aurora Bridge")
 
+	// freezeTracker tracks whether the cluster is frozen, aurora bridge
+	// does not take automatic actions of its own so the freeze state is
+	// only reported with the heartbeat
+	freezeTracker := freeze.NewTracker(
+		freeze.NewRemoteOps(adminsvc.NewAdminServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager))),
+		cfg.Freeze,
+		rootScope,
+	)
+	freezeTracker.Start()
+	defer freezeTracker.Stop()
+
 	// we can *honestly* say the server is booted up now
-	health.InitHeartbeat(rootScope, cfg.Health, candidate)
+	health.InitHeartbeat(rootScope, cfg.Health, candidate, freezeTracker)
 
 	select {}
 }
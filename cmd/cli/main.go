@@ -149,6 +149,10 @@ var (
 	jobStatus     = job.Command("status", "get job status")
 	jobStatusName = jobStatus.Arg("job", "job identifier").Required().String()
 
+	jobCronRuns         = job.Command("cron-runs", "list the past and upcoming runs of a cron job")
+	jobCronRunsName     = jobCronRuns.Arg("job", "job identifier").Required().String()
+	jobCronRunsUpcoming = jobCronRuns.Flag("upcoming", "number of upcoming runs to list").Default("5").Short('n').Uint32()
+
 	// peloton -z zookeeper-peloton-devel01 job query --labels="x=y,a=b" --respool=xx --keywords=k1,k2 --states=running,killed --limit=1
 	jobQuery            = job.Command("query", "query jobs by mesos label / respool")
 	jobQueryLabels      = jobQuery.Flag("labels", "labels").Default("").Short('l').String()
@@ -402,6 +406,7 @@ var (
 	podGetEventsInstanceID = podGetEvents.Arg("instance", "job instance id").Required().Uint32()
 	podGetEventsRunID      = podGetEvents.Flag("run", "get pod events for this runID only").Short('r').String()
 	podGetEventsLimit      = podGetEvents.Flag("limit", "limit to last n runs of the pod, default value 10").Short('l').Uint64()
+	podGetEventsCompact    = podGetEvents.Flag("compact", "get only the last event of each run of the pod").Default("false").Bool()
 
 	podGetCache        = pod.Command("cache", "get pod status from cache")
 	podGetCachePodName = podGetCache.Arg("name", "pod name").Required().String()
@@ -580,6 +585,19 @@ var (
 	hostQuery       = host.Command("query", "query hosts by state(s)")
 	hostQueryStates = hostQuery.Flag("states", "host state(s) to filter").Default("").Short('s').String()
 
+	hostDrain         = host.Command("drain", "drain the tasks of a host and put it into maintenance")
+	hostDrainHostname = hostDrain.Arg("hostname", "hostname").Required().String()
+	hostDrainReason   = hostDrain.Flag("reason", "reason for draining the host, recorded in the audit log").Default("").String()
+	hostDrainWatch    = hostDrain.Flag("watch", "wait for the host to be drained, showing the progress of evicted tasks").Default("false").Bool()
+
+	hostDrainStatus         = host.Command("drain-status", "show the drain progress of a host")
+	hostDrainStatusHostname = hostDrainStatus.Arg("hostname", "hostname").Required().String()
+	hostDrainStatusWatch    = hostDrainStatus.Flag("watch", "wait for the host to be drained, showing the progress of evicted tasks").Default("false").Bool()
+
+	hostUndrain         = host.Command("undrain", "bring a drained host back up")
+	hostUndrainHostname = hostUndrain.Arg("hostname", "hostname").Required().String()
+	hostUndrainReason   = hostUndrain.Flag("reason", "reason for bringing the host back up, recorded in the audit log").Default("").String()
+
 	// Top level volume command
 	volume = app.Command("volume", "manage persistent volume")
 
@@ -702,6 +720,17 @@ var (
 	// command to disable the kill tasks request to mesos master
 	disableKillTasks = hostmgr.Command("disable-kill-tasks", "disable the kill task request to mesos master")
 
+	// commands for hosts quarantined since their offers are rescinded repeatedly
+	quarantine               = hostmgr.Command("quarantine", "manage hosts quarantined since their offers are rescinded repeatedly")
+	quarantineList           = quarantine.Command("list", "list the quarantined hosts")
+	quarantineClear          = quarantine.Command("clear", "release hosts from quarantine")
+	quarantineClearHostnames = quarantineClear.Arg("hostnames", "hosts to release from quarantine").Strings()
+	quarantineClearAll       = quarantineClear.Flag("all", "release all the quarantined hosts").Default("false").Bool()
+
+	// command to get the capacity and the resources offered and allocated to Peloton on hosts
+	hostResources          = hostmgr.Command("resources", "show allocated/offered/capacity resources of hosts")
+	hostResourcesHostnames = hostResources.Arg("hostnames", "hosts to show the resources of, all the hosts if not specified").Strings()
+
 	// Top level admin command
 	admin = app.Command("admin", "administrative APIs")
 	// command for locking down components
@@ -714,6 +743,11 @@ var (
 	unlockComponents = unlock.Arg("components", "components to remove lockdown. "+
 		"Now support GoalStateEngine, Read, Write").
 		Enums("GoalStateEngine", "Read", "Write", "Kill")
+	// command for dumping the job manager cache of a job
+	adminJobCache      = admin.Command("job-cache", "dump the state of a job cached in job manager")
+	adminJobCacheJobID = adminJobCache.Arg("job", "job identifier").Required().String()
+	// command for listing the entities stuck in the goal state of job manager
+	adminStuckEntities = admin.Command("stuck-entities", "list the jobs, tasks and updates stuck in goal state")
 
 	// Top level hostcache commands
 	hostcache     = hostmgr.Command("hostcache", "manage hostcache")
@@ -935,6 +969,8 @@ func main() {
 		err = client.JobRefreshAction(*jobRefreshName)
 	case jobStatus.FullCommand():
 		err = client.JobStatusAction(*jobStatusName)
+	case jobCronRuns.FullCommand():
+		err = client.JobCronRunsAction(*jobCronRunsName, *jobCronRunsUpcoming)
 	case jobQuery.FullCommand():
 		err = client.JobQueryAction(*jobQueryLabels, *jobQueryRespoolPath, *jobQueryKeywords, *jobQueryStates, *jobQueryOwner, *jobQueryName, *jobQueryTimeRange, *jobQueryLimit, *jobQueryMaxLimit, *jobQueryOffset, *jobQuerySortBy, *jobQuerySortOrder)
 	case jobUpdate.FullCommand():
@@ -979,6 +1015,12 @@ func main() {
 		err = client.HostMaintenanceCompleteAction(*hostMaintenanceCompleteHostname)
 	case hostQuery.FullCommand():
 		err = client.HostQueryAction(*hostQueryStates)
+	case hostDrain.FullCommand():
+		err = client.HostDrainAction(*hostDrainHostname, *hostDrainReason, *hostDrainWatch)
+	case hostDrainStatus.FullCommand():
+		err = client.HostDrainStatusAction(*hostDrainStatusHostname, *hostDrainStatusWatch)
+	case hostUndrain.FullCommand():
+		err = client.HostUndrainAction(*hostUndrainHostname, *hostUndrainReason)
 	case hostcacheDump.FullCommand():
 		err = client.HostCacheDump()
 	case jobMgrThrottledPods.FullCommand():
@@ -1045,8 +1087,14 @@ func main() {
 		)
 	case disableKillTasks.FullCommand():
 		err = client.DisableKillTasksAction()
+	case quarantineList.FullCommand():
+		err = client.HostQuarantineListAction()
+	case quarantineClear.FullCommand():
+		err = client.HostQuarantineClearAction(*quarantineClearHostnames, *quarantineClearAll)
+	case hostResources.FullCommand():
+		err = client.HostResourcesAction(*hostResourcesHostnames)
 	case podGetEvents.FullCommand():
-		err = client.PodGetEventsAction(*podGetEventsJobName, *podGetEventsInstanceID, *podGetEventsRunID, *podGetEventsLimit)
+		err = client.PodGetEventsAction(*podGetEventsJobName, *podGetEventsInstanceID, *podGetEventsRunID, *podGetEventsLimit, *podGetEventsCompact)
 	case podGetCache.FullCommand():
 		err = client.PodGetCacheAction(*podGetCachePodName)
 	case podGetEventsV1Alpha.FullCommand():
@@ -1196,6 +1244,10 @@ func main() {
 		err = client.LockComponents(*lockComponents)
 	case unlock.FullCommand():
 		err = client.UnlockComponents(*unlockComponents)
+	case adminJobCache.FullCommand():
+		err = client.JobCacheSnapshot(*adminJobCacheJobID)
+	case adminStuckEntities.FullCommand():
+		err = client.StuckGoalStateEntities()
 	case hostpoolList.FullCommand():
 		err = client.HostPoolList()
 	case hostpoolListHosts.FullCommand():
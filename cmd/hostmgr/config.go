@@ -16,10 +16,12 @@ package main
 
 import (
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/hostmgr/config"
 	"github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/config"
@@ -28,13 +30,18 @@ import (
 
 // Config holds all configs to run a peloton-hostmgr server.
 type Config struct {
-	Metrics      metrics.Config        `yaml:"metrics"`
-	Storage      storage.Config        `yaml:"storage"`
-	HostManager  config.Config         `yaml:"host_manager"`
-	Mesos        mesos.Config          `yaml:"mesos"`
-	Election     leader.ElectionConfig `yaml:"election"`
-	Health       health.Config         `yaml:"health"`
-	SentryConfig logging.SentryConfig  `yaml:"sentry"`
-	Auth         auth.Config           `yaml:"auth"`
-	K8s          p2kconfig.K8sConfig   `yaml:"k8s"`
+	Metrics          metrics.Config                   `yaml:"metrics"`
+	Storage          storage.Config                   `yaml:"storage"`
+	HostManager      config.Config                    `yaml:"host_manager"`
+	Mesos            mesos.Config                     `yaml:"mesos"`
+	Election         leader.ElectionConfig            `yaml:"election"`
+	Health           health.Config                    `yaml:"health"`
+	Freeze           freeze.Config                    `yaml:"freeze"`
+	Pagination       pagination.Config                `yaml:"pagination"`
+	SentryConfig     logging.SentryConfig             `yaml:"sentry"`
+	Auth             auth.Config                      `yaml:"auth"`
+	K8s              p2kconfig.K8sConfig              `yaml:"k8s"`
+	Fake             p2kconfig.FakeConfig             `yaml:"fake"`
+	MesosPlugin      p2kconfig.MesosConfig            `yaml:"mesos_plugin"`
+	Oversubscription p2kconfig.OversubscriptionConfig `yaml:"oversubscription"`
 }
@@ -15,9 +15,12 @@
 package main
 
 import (
+	"context"
 	"net/url"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -30,13 +33,17 @@ import (
 	"github.com/uber/peloton/pkg/common/backoff"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/eventstream"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr"
 	bin_packing "github.com/uber/peloton/pkg/hostmgr/binpacking"
+	hostmgr_config "github.com/uber/peloton/pkg/hostmgr/config"
 	"github.com/uber/peloton/pkg/hostmgr/goalstate"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/host/drainer"
@@ -47,11 +54,13 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/transport/mhttp"
+	"github.com/uber/peloton/pkg/hostmgr/metadata"
 	hostmetric "github.com/uber/peloton/pkg/hostmgr/metrics"
 	"github.com/uber/peloton/pkg/hostmgr/offer"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostmgrsvc"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins/k8s"
 	mesosplugins "github.com/uber/peloton/pkg/hostmgr/p2k/plugins/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/podeventmanager"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
@@ -383,6 +392,12 @@ func main() {
 
 	mux.HandleFunc(buildversion.Get, buildversion.Handler(version))
 
+	var metadataRegistry *metadata.Registry
+	if cfg.HostManager.EnableTaskMetadata {
+		metadataRegistry = metadata.NewRegistry(rootScope)
+		mux.Handle(metadata.Path, metadataRegistry)
+	}
+
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
 		cfg.HostManager.HTTPPort,
@@ -401,16 +416,28 @@ func main() {
 
 	rootScope.Counter("boot").Inc(1)
 
-	store := stores.MustCreateStore(&cfg.Storage, rootScope)
-
 	ormStore, ormErr := ormobjects.NewCassandraStore(
 		cassandra.ToOrmConfig(&cfg.Storage.Cassandra),
 		rootScope)
 	if ormErr != nil {
 		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
 	}
+	store := stores.MustCreateStore(&cfg.Storage, ormStore, rootScope)
+
 	activeJobsOps := ormobjects.NewActiveJobsOps(ormStore)
 
+	pagination.Init(cfg.Pagination)
+
+	// freezeTracker tracks whether the cluster is frozen, during which
+	// non-essential automatic actions are halted
+	freezeTracker := freeze.NewTracker(
+		ormobjects.NewClusterFreezeOps(ormStore),
+		cfg.Freeze,
+		rootScope,
+	)
+	freezeTracker.Start()
+	defer freezeTracker.Stop()
+
 	authHeader, err := mesos.GetAuthHeader(&cfg.Mesos, *mesosSecretFile)
 	if err != nil {
 		log.WithError(err).Fatal("Cannot initialize auth header")
@@ -533,13 +560,33 @@ func main() {
 	// Mesos callbacks
 	// NOTE: This blocks us to move all Mesos related logic into
 	// hostmgr.Server because schedulerClient uses dispatcher...
+	schedulerBreaker := mpb.NewCircuitBreaker(
+		common.MesosMasterScheduler,
+		cfg.Mesos.SchedulerBreaker,
+		rootScope,
+	)
+	operatorBreaker := mpb.NewCircuitBreaker(
+		common.MesosMasterOperator,
+		cfg.Mesos.OperatorBreaker,
+		rootScope,
+	)
+	for _, breaker := range []*mpb.CircuitBreaker{
+		schedulerBreaker, operatorBreaker} {
+		if breaker != nil {
+			health.RegisterCircuitBreaker(breaker.Name(), breaker)
+		}
+	}
+	mux.HandleFunc(health.CircuitBreakersPath, health.CircuitBreakersHandler)
+
 	schedulerClient := mpb.NewSchedulerClient(
 		dispatcher.ClientConfig(common.MesosMasterScheduler),
 		cfg.Mesos.Encoding,
+		schedulerBreaker,
 	)
 	masterOperatorClient := mpb.NewMasterOperatorClient(
 		dispatcher.ClientConfig(common.MesosMasterOperator),
 		cfg.Mesos.Encoding,
+		operatorBreaker,
 	)
 
 	mesos.InitManager(
@@ -624,6 +671,12 @@ func main() {
 	} else {
 		bin_packing.Init(nil, nil)
 	}
+	if cfg.HostManager.CustomRankerWeights != nil {
+		bin_packing.RegisterScorer(
+			bin_packing.Custom,
+			bin_packing.WeightedScore(*cfg.HostManager.CustomRankerWeights),
+		)
+	}
 
 	log.WithField("ranker_name", cfg.HostManager.BinPacking).
 		Info("Bin packing is enabled")
@@ -643,16 +696,34 @@ func main() {
 
 	// If k8s is enabled, return a k8s plugin.
 	// TODO: start MesosPlugin after it's implemented.
+	var k8sPlugin *k8s.K8SManager
 	if cfg.K8s.Enabled {
 		var err error
-		plugin, err = plugins.NewK8sPlugin(
-			cfg.K8s.Kubeconfig,
+		k8sPlugin, err = plugins.NewK8sPlugin(
+			cfg.K8s,
+			rootScope,
 			podEventCh,
 			hostEventCh,
 		)
 		if err != nil {
 			log.WithError(err).Fatal("Cannot init host manager plugin.")
 		}
+		plugin = k8sPlugin
+	} else if cfg.Fake.Enabled {
+		// The fake plugin simulates a cluster in memory, for the dev
+		// harness and scale tests.
+		plugin = plugins.NewFakePlugin(
+			cfg.Fake,
+			podEventCh,
+			hostEventCh,
+		)
+	}
+
+	if cfg.MesosPlugin.Role == "" {
+		cfg.MesosPlugin.Role = cfg.Mesos.Framework.Role
+	}
+	if cfg.MesosPlugin.Principal == "" {
+		cfg.MesosPlugin.Principal = cfg.Mesos.Framework.Principal
 	}
 
 	// a temporary measure to enable mesos plugins for some usecases,
@@ -664,10 +735,15 @@ func main() {
 		masterOperatorClient,
 		cfg.HostManager.HostmapRefreshInterval,
 		time.Duration(cfg.HostManager.OfferHoldTimeSec)*time.Second,
+		cfg.HostManager.TaskUpdateAckConcurrency,
+		cfg.HostManager.TaskUpdateBufferSize,
+		cfg.MesosPlugin,
 		rootScope,
 		podEventCh,
 		hostEventCh,
+		metadataRegistry,
 	)
+	mux.HandleFunc(mesosplugins.OfferStatsPath, mesosPlugin.OfferStatsHandler)
 
 	// Initialize offer pool event handler with nil host pool manager.
 	// TODO: Refactor event stream handler and move it out of offer package
@@ -688,6 +764,56 @@ func main() {
 		mesosPlugin,
 	)
 
+	// Persist the cursors of the clients of the task status update stream,
+	// so that its offsets continue past them once host manager restarts.
+	if err := offer.GetEventHandler().GetEventStreamHandler().SetCursorStore(
+		context.Background(),
+		eventstream.NewCursorStore(
+			ormobjects.NewEventStreamCursorOps(ormStore),
+			offer.TaskUpdateStreamName,
+		),
+	); err != nil {
+		log.WithError(err).
+			Fatal("Cannot restore the cursors of the task status update stream")
+	}
+
+	// Apply the host manager config fields which are safe to change at
+	// runtime on SIGHUP, on request, or when the config files change.
+	configReloader := hostmgr_config.NewReloader(
+		cfg.HostManager,
+		func() (hostmgr_config.Config, error) {
+			var reloaded Config
+			if err := config.Parse(&reloaded, *configFiles...); err != nil {
+				return hostmgr_config.Config{}, err
+			}
+			if *httpPort != 0 {
+				reloaded.HostManager.HTTPPort = *httpPort
+			}
+			if *grpcPort != 0 {
+				reloaded.HostManager.GRPCPort = *grpcPort
+			}
+			return reloaded.HostManager, nil
+		},
+	)
+	offer.RegisterConfigReloads(configReloader, offer.GetEventHandler())
+	mux.HandleFunc(hostmgr_config.ReloadPath, configReloader.ReloadHandler)
+
+	sighupCh := make(chan os.Signal, 1)
+	signal.Notify(sighupCh, syscall.SIGHUP)
+	go func() {
+		for range sighupCh {
+			configReloader.Reload()
+		}
+	}()
+
+	if cfg.HostManager.ConfigReloadInterval > 0 {
+		go configReloader.WatchFiles(
+			*configFiles,
+			cfg.HostManager.ConfigReloadInterval,
+			make(chan struct{}),
+		)
+	}
+
 	// Construct host pool manager if it is enabled.
 	var hostPoolManager manager.HostPoolManager
 	if cfg.HostManager.EnableHostPool {
@@ -706,9 +832,17 @@ func main() {
 	hostCache = hostcache.New(
 		hostEventCh,
 		backgroundManager,
+		plugin,
+		cfg.Oversubscription,
 		rootScope,
 	)
 
+	// The k8s plugin garbage collects the pods in the cluster which are
+	// unknown to the host cache.
+	if k8sPlugin != nil {
+		k8sPlugin.SetPodCache(hostCache)
+	}
+
 	pem := podeventmanager.New(
 		dispatcher,
 		podEventCh,
@@ -753,6 +887,7 @@ func main() {
 		dispatcher,
 		metric,
 		schedulerClient,
+		schedulerBreaker,
 		masterOperatorClient,
 		driver,
 		cfg.Mesos,
@@ -765,6 +900,7 @@ func main() {
 		ormobjects.GetHostInfoOps(),
 		hostCache,
 		mesosPlugin,
+		reconciler,
 	)
 
 	hostDrainer := drainer.NewDrainer(
@@ -846,7 +982,7 @@ func main() {
 	}).Info("Started host manager")
 
 	// We can *honestly* say the server is booted up now.
-	health.InitHeartbeat(rootScope, cfg.Health, candidate)
+	health.InitHeartbeat(rootScope, cfg.Health, candidate, freezeTracker)
 
 	// Start collecting runtime metrics.
 	defer metrics.StartCollectingRuntimeMetrics(
@@ -16,10 +16,12 @@ package main
 
 import (
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/jobmgr"
 	"github.com/uber/peloton/pkg/middleware/inbound"
 	storage "github.com/uber/peloton/pkg/storage/config"
@@ -32,6 +34,8 @@ type Config struct {
 	Election     leader.ElectionConfig   `yaml:"election"`
 	JobManager   jobmgr.Config           `yaml:"job_manager"`
 	Health       health.Config           `yaml:"health"`
+	Freeze       freeze.Config           `yaml:"freeze"`
+	Pagination   pagination.Config       `yaml:"pagination"`
 	SentryConfig logging.SentryConfig    `yaml:"sentry"`
 	Auth         auth.Config             `yaml:"auth"`
 	RateLimit    inbound.RateLimitConfig `yaml:"rate_limit"`
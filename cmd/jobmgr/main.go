@@ -29,10 +29,12 @@ import (
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
 	"github.com/uber/peloton/pkg/jobmgr"
@@ -43,7 +45,9 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/private"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/stateless"
 	"github.com/uber/peloton/pkg/jobmgr/logmanager"
+	"github.com/uber/peloton/pkg/jobmgr/podevents"
 	"github.com/uber/peloton/pkg/jobmgr/podsvc"
+	"github.com/uber/peloton/pkg/jobmgr/replication"
 	"github.com/uber/peloton/pkg/jobmgr/task/activermtask"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
 	"github.com/uber/peloton/pkg/jobmgr/task/event"
@@ -431,13 +435,25 @@ func main() {
 
 	// store implements JobStore, TaskStore, VolumeStore, UpdateStore
 	// and FrameworkInfoStore
-	store := stores.MustCreateStore(&cfg.Storage, rootScope)
 	ormStore, ormErr := ormobjects.NewCassandraStore(
 		cassandra.ToOrmConfig(&cfg.Storage.Cassandra),
 		rootScope)
 	if ormErr != nil {
 		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
 	}
+	store := stores.MustCreateStore(&cfg.Storage, ormStore, rootScope)
+
+	pagination.Init(cfg.Pagination)
+
+	// freezeTracker tracks whether the cluster is frozen, during which
+	// non-essential automatic actions are halted
+	freezeTracker := freeze.NewTracker(
+		ormobjects.NewClusterFreezeOps(ormStore),
+		cfg.Freeze,
+		rootScope,
+	)
+	freezeTracker.Start()
+	defer freezeTracker.Stop()
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -563,6 +579,7 @@ func main() {
 		ormStore,
 		rootScope,
 		[]cached.JobTaskListener{watchsvc.NewWatchListener(watchProcessor)},
+		cfg.JobManager.JobCache,
 	)
 
 	// Register WorkflowProgressCheck
@@ -576,6 +593,42 @@ func main() {
 			Fatal("fail to register workflowCheck in backgroundManager")
 	}
 
+	// Register replication of job summaries to the read-only mirror
+	if cfg.JobManager.Replication.Enabled {
+		mirrorStore, err := ormobjects.NewCassandraStore(
+			cassandra.ToOrmConfig(&cfg.JobManager.Replication.Mirror),
+			rootScope.SubScope("mirror"))
+		if err != nil {
+			log.WithError(err).
+				Fatal("Failed to create ORM store for mirror Cassandra")
+		}
+
+		jobReplicator := &replication.JobReplicator{
+			JobFactory:        jobFactory,
+			JobConfigOps:      ormobjects.NewJobConfigOps(ormStore),
+			JobIndexOps:       ormobjects.NewJobIndexOps(ormStore),
+			MirrorJobIndexOps: ormobjects.NewJobIndexOps(mirrorStore),
+			Metrics:           replication.NewMetrics(rootScope),
+			Config:            &cfg.JobManager.Replication,
+		}
+		if err := jobReplicator.Register(backgroundManager); err != nil {
+			log.WithError(err).
+				Fatal("fail to register jobReplicator in backgroundManager")
+		}
+	}
+
+	// Register compaction of pod events beyond their retention
+	podEventsCompactor := &podevents.Compactor{
+		JobFactory:   jobFactory,
+		PodEventsOps: ormobjects.NewPodEventsOps(ormStore),
+		Metrics:      podevents.NewMetrics(rootScope),
+		Config:       &cfg.JobManager.PodEventsRetention,
+	}
+	if err := podEventsCompactor.Register(backgroundManager); err != nil {
+		log.WithError(err).
+			Fatal("fail to register podEventsCompactor in backgroundManager")
+	}
+
 	goalStateDriver := goalstate.NewDriver(
 		dispatcher,
 		store, // store implements JobStore
@@ -588,6 +641,7 @@ func main() {
 		rootScope,
 		cfg.JobManager.GoalState,
 		cfg.JobManager.HostManagerAPIVersion,
+		freezeTracker,
 	)
 
 	// Init placement processor
@@ -623,6 +677,7 @@ func main() {
 		goalStateDriver,
 		rootScope,
 		&cfg.JobManager.Deadline,
+		freezeTracker,
 	)
 
 	// Create the Task status update which pulls task update events
@@ -637,6 +692,7 @@ func main() {
 		[]event.Listener{},
 		rootScope,
 		cfg.JobManager.HostManagerAPIVersion,
+		cfg.JobManager.TaskEvents,
 	)
 
 	server := jobmgr.NewServer(
@@ -748,6 +804,8 @@ func main() {
 		dispatcher,
 		goalStateDriver,
 		apiLockInboundMiddleware,
+		freezeTracker,
+		jobFactory,
 	)
 
 	// Start dispatch loop
@@ -767,7 +825,7 @@ func main() {
 	}).Info("Started job manager")
 
 	// we can *honestly* say the server is booted up now
-	health.InitHeartbeat(rootScope, cfg.Health, candidate)
+	health.InitHeartbeat(rootScope, cfg.Health, candidate, freezeTracker)
 
 	// start collecting runtime metrics
 	defer metrics.StartCollectingRuntimeMetrics(
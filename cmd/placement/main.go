@@ -25,6 +25,7 @@ import (
 	"github.com/uber/peloton/pkg/common/async"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	common_config "github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
@@ -45,6 +46,7 @@ import (
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
 	"github.com/uber/peloton/pkg/placement/tasks"
 
+	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	hostsvc_v1 "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -342,6 +344,24 @@ func main() {
 
 	resmgrOutbound := t.NewOutbound(resmgrPeerChooser)
 
+	log.Info("Connecting to JobManager")
+	jobmgrPeerChooser, err := peer.NewSmartChooser(
+		cfg.Election,
+		rootScope,
+		common.JobManagerRole,
+		t,
+	)
+	if err != nil {
+		log.WithFields(
+			log.Fields{
+				"error": err,
+				"role":  common.JobManagerRole},
+		).Fatal("Could not create smart peer chooser for job manager")
+	}
+	defer jobmgrPeerChooser.Stop()
+
+	jobmgrOutbound := t.NewOutbound(jobmgrPeerChooser)
+
 	log.Info("Setup the PlacementEngine server")
 	// Now attempt to setup the dispatcher
 	outbounds := yarpc.Outbounds{
@@ -351,6 +371,9 @@ func main() {
 		common.PelotonHostManager: transport.Outbounds{
 			Unary: hostmgrOutbound,
 		},
+		common.PelotonJobManager: transport.Outbounds{
+			Unary: jobmgrOutbound,
+		},
 	}
 
 	securityManager, err := auth_impl.CreateNewSecurityManager(&cfg.Auth)
@@ -458,7 +481,19 @@ func main() {
 
 	log.Info("Initialize the Heartbeat process")
 	// we can *honestly* say the server is booted up now
-	health.InitHeartbeat(rootScope, cfg.Health, nil)
+	// freezeTracker tracks whether the cluster is frozen, the placement
+	// engine does not take automatic actions of its own so the freeze
+	// state is only reported with the heartbeat
+	freezeTracker := freeze.NewTracker(
+		freeze.NewRemoteOps(adminsvc.NewAdminServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager))),
+		cfg.Freeze,
+		rootScope,
+	)
+	freezeTracker.Start()
+	defer freezeTracker.Stop()
+
+	health.InitHeartbeat(rootScope, cfg.Health, nil, freezeTracker)
 
 	// start collecting runtime metrics
 	defer metrics.StartCollectingRuntimeMetrics(
@@ -16,6 +16,7 @@ package main
 
 import (
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -31,6 +32,7 @@ type Config struct {
 	ResManager   resmgr.Config         `yaml:"resmgr"`
 	Election     leader.ElectionConfig `yaml:"election"`
 	Health       health.Config         `yaml:"health"`
+	Freeze       freeze.Config         `yaml:"freeze"`
 	SentryConfig logging.SentryConfig  `yaml:"sentry"`
 	Auth         auth.Config           `yaml:"auth"`
 }
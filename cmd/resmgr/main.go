@@ -26,6 +26,7 @@ import (
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -300,16 +301,26 @@ func main() {
 	mux.HandleFunc(logging.LevelOverwrite, logging.LevelOverwriteHandler(initialLevel))
 	mux.HandleFunc(buildversion.Get, buildversion.Handler(version))
 
-	store := stores.MustCreateStore(&cfg.Storage, rootScope)
 	ormStore, ormErr := ormobjects.NewCassandraStore(
 		cassandra.ToOrmConfig(&cfg.Storage.Cassandra),
 		rootScope)
 	if ormErr != nil {
 		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
 	}
+	store := stores.MustCreateStore(&cfg.Storage, ormStore, rootScope)
 	respoolOps := ormobjects.NewResPoolOps(ormStore)
 	activeJobsOps := ormobjects.NewActiveJobsOps(ormStore)
 
+	// freezeTracker tracks whether the cluster is frozen, during which
+	// non-essential automatic actions are halted
+	freezeTracker := freeze.NewTracker(
+		ormobjects.NewClusterFreezeOps(ormStore),
+		cfg.Freeze,
+		rootScope,
+	)
+	freezeTracker.Start()
+	defer freezeTracker.Stop()
+
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
 		cfg.ResManager.HTTPPort,
@@ -419,12 +430,14 @@ func main() {
 		rootScope,
 		tree,
 		cfg.ResManager.TaskSchedulingPeriod,
+		cfg.ResManager.SchedulingCycleBudget,
 		task.GetTracker(),
 	)
 
 	// Initializing the entitlement calculator
 	calculator := entitlement.NewCalculator(
 		cfg.ResManager.EntitlementCaculationPeriod,
+		cfg.ResManager.EntitlementRebalancePeriod,
 		rootScope,
 		dispatcher,
 		tree,
@@ -446,6 +459,7 @@ func main() {
 		cfg.ResManager.PreemptionConfig,
 		task.GetTracker(),
 		tree,
+		freezeTracker,
 	)
 
 	// Initializing the host drainer
@@ -474,18 +488,29 @@ func main() {
 	)
 
 	// Initialize recovery
+	queueSnapshotOps := ormobjects.NewQueueSnapshotOps(ormStore)
 	recoveryHandler := resmgr.NewRecovery(
 		rootScope,
 		store, // store implements TaskStore
 		activeJobsOps,
 		ormobjects.NewJobConfigOps(ormStore),
 		ormobjects.NewJobRuntimeOps(ormStore),
+		queueSnapshotOps,
 		serviceHandler,
 		tree,
 		cfg.ResManager,
 		hostmgrClient,
 	)
 
+	queueSnapshotter := resmgr.NewQueueSnapshotter(
+		rootScope,
+		tree,
+		task.GetScheduler(),
+		task.GetTracker(),
+		queueSnapshotOps,
+		cfg.ResManager,
+	)
+
 	// Initialize the server
 	server := resmgr.NewServer(rootScope,
 		cfg.ResManager.HTTPPort,
@@ -497,6 +522,7 @@ func main() {
 		preemptor,
 		drainer,
 		batchScorer,
+		queueSnapshotter,
 	)
 	// Set nomination for leader check middleware
 	leaderCheckMiddleware.SetNomination(server)
@@ -529,7 +555,7 @@ func main() {
 	}).Info("Started resource manager")
 
 	// we can *honestly* say the server is booted up now
-	health.InitHeartbeat(rootScope, cfg.Health, candidate)
+	health.InitHeartbeat(rootScope, cfg.Health, candidate, freezeTracker)
 
 	// start collecting runtime metrics
 	defer metrics.StartCollectingRuntimeMetrics(
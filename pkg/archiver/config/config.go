@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -55,6 +56,7 @@ type Config struct {
 	Health       health.Config         `yaml:"health"`
 	SentryConfig logging.SentryConfig  `yaml:"sentry"`
 	Auth         auth.Config           `yaml:"auth"`
+	Freeze       freeze.Config         `yaml:"freeze"`
 }
 
 // ArchiverConfig contains archiver specific configuration
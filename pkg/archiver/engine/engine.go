@@ -25,10 +25,12 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/query"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
 	"github.com/uber/peloton/pkg/archiver/config"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/middleware/inbound"
@@ -78,6 +80,9 @@ type Engine interface {
 	// Cleanup cleans the archiver engine before
 	// restart
 	Cleanup()
+	// FreezeState returns the freeze state of the cluster, the archiver
+	// does not delete jobs and pod events while the cluster is frozen
+	FreezeState() freeze.State
 }
 
 type engine struct {
@@ -93,6 +98,8 @@ type engine struct {
 	metrics *Metrics
 	// Archiver backoff/retry policy
 	retryPolicy backoff.RetryPolicy
+	// Tracker of the freeze state of the cluster
+	freezeTracker *freeze.Tracker
 }
 
 // New creates a new Archiver Engine.
@@ -149,6 +156,13 @@ func New(
 		return nil, fmt.Errorf("Unable to start dispatcher: %v", err)
 	}
 
+	freezeTracker := freeze.NewTracker(
+		freeze.NewRemoteOps(adminsvc.NewAdminServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager))),
+		cfg.Freeze,
+		scope,
+	)
+
 	return &engine{
 		jobClient: job.NewJobManagerYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
@@ -162,6 +176,7 @@ func New(
 		retryPolicy: backoff.NewRetryPolicy(
 			cfg.Archiver.MaxRetryAttemptsJobQuery,
 			cfg.Archiver.RetryIntervalJobQuery),
+		freezeTracker: freezeTracker,
 	}, nil
 }
 
@@ -176,6 +191,9 @@ func (e *engine) Start() error {
 	// around with core jobmgr functionality.
 	// TODO: remove this delay once we move to API server
 	e.metrics.ArchiverStart.Inc(1)
+	if e.freezeTracker != nil {
+		e.freezeTracker.Start()
+	}
 	jitter := time.Duration(rand.Intn(jitterMax)) * time.Millisecond
 	time.Sleep(e.config.Archiver.BootstrapDelay + jitter)
 	// At first, the time range will be [(t-30d-1d), (t-30d))
@@ -183,7 +201,14 @@ func (e *engine) Start() error {
 	minTime := maxTime.Add(-e.config.Archiver.ArchiveStepSize)
 
 	for {
-		if e.config.Archiver.Enable {
+		// jobs and pod events are deleted once the cluster is unfrozen,
+		// the time range of the jobs to archive is kept meanwhile
+		frozen := e.freezeTracker.IsFrozen()
+		if frozen {
+			e.metrics.ArchiverRunFrozen.Inc(1)
+		}
+
+		if e.config.Archiver.Enable && !frozen {
 			startTime := time.Now()
 			max, err := ptypes.TimestampProto(maxTime)
 			if err != nil {
@@ -223,7 +248,7 @@ func (e *engine) Start() error {
 			minTime = minTime.Add(-e.config.Archiver.ArchiveStepSize)
 		}
 
-		if e.config.Archiver.PodEventsCleanup {
+		if e.config.Archiver.PodEventsCleanup && !frozen {
 			startTime := time.Now()
 			spec := job.QuerySpec{
 				JobStates: []job.JobState{
@@ -255,10 +280,18 @@ func (e *engine) Start() error {
 
 // Cleanup cleans the archiver engine before restarting
 func (e *engine) Cleanup() {
+	if e.freezeTracker != nil {
+		e.freezeTracker.Stop()
+	}
 	e.dispatcher.Stop()
 	return
 }
 
+// FreezeState returns the freeze state of the cluster
+func (e *engine) FreezeState() freeze.State {
+	return e.freezeTracker
+}
+
 // runArchiver runs the action(s) for Archiver
 func (e *engine) runArchiver(
 	queryReq *job.QueryRequest,
@@ -35,6 +35,8 @@ type Metrics struct {
 	ArchiverRunDuration        tally.Timer
 	PodDeleteEventsRun         tally.Counter
 	PodDeleteEventsRunDuration tally.Timer
+
+	ArchiverRunFrozen tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -54,5 +56,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ArchiverRunDuration:        scope.Timer("archiver_run_duration"),
 		PodDeleteEventsRun:         scope.Counter("pod_delete_events_run"),
 		PodDeleteEventsRunDuration: scope.Timer("pod_delete_events_run_duration"),
+
+		ArchiverRunFrozen: scope.Counter("archiver_run_frozen"),
 	}
 }
@@ -38,6 +38,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/atomic"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 const (
@@ -98,6 +99,10 @@ type eventPublisher struct {
 	// is running to receive the pod state changes.
 	isWatchPodRunning atomic.Bool
 
+	// revision of the last pod state change received, used to resume
+	// the watch from after the stream is lost
+	revision atomic.Uint64
+
 	// isPublisherWorkersRunning ensures the pod event publisher workers
 	// are spawed once only for entire lifecycle
 	isPublisherWorkersRunning atomic.Bool
@@ -217,6 +222,7 @@ func (e *eventPublisher) ensureWatchPodRunning() {
 			stream, err = e.watchClient.Watch(
 				ctx,
 				&watchsvc.WatchRequest{
+					StartRevision: e.revision.Load(),
 					PodFilter: &watch.PodFilter{
 						Labels: []*peloton.Label{
 							common.BridgePodLabel,
@@ -271,6 +277,12 @@ func (e *eventPublisher) watchPod(
 			return
 		}
 
+		// the initial response of a resumed watch has the current
+		// revision, which is ahead of the changes replayed after it
+		if len(msg.GetPods()) > 0 || e.revision.Load() == 0 {
+			e.revision.Store(msg.GetRevision())
+		}
+
 		for _, pod := range msg.GetPods() {
 			podName := pod.GetPodName().GetValue()
 			index := common.Hash(podName) % uint32(podBuckets)
@@ -302,6 +314,11 @@ func (e *eventPublisher) receivePod(
 		log.Info("stream EOF reached")
 	default:
 		log.WithError(err).Error("error reading from stream")
+
+		// the watch cannot be resumed, start it from the current revision
+		if yarpcerrors.IsOutOfRange(err) || yarpcerrors.IsInvalidArgument(err) {
+			e.revision.Store(0)
+		}
 	}
 
 	return nil, false
@@ -15,9 +15,12 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 
 	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 )
 
 const (
@@ -51,6 +54,39 @@ func (c *Client) UnlockComponents(components []string) error {
 	return err
 }
 
+// JobCacheSnapshot prints the state of a job cached in job manager
+func (c *Client) JobCacheSnapshot(jobID string) error {
+	resp, err := c.adminClient.GetJobCacheSnapshot(
+		c.ctx,
+		&adminsvc.GetJobCacheSnapshotRequest{
+			JobId: &v1alphapeloton.JobID{Value: jobID},
+		})
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(resp.GetSnapshot()), "", "  "); err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// StuckGoalStateEntities prints the jobs, tasks and updates stuck
+// in the goal state of job manager
+func (c *Client) StuckGoalStateEntities() error {
+	resp, err := c.adminClient.GetStuckGoalStateEntities(
+		c.ctx,
+		&adminsvc.GetStuckGoalStateEntitiesRequest{})
+	if err != nil {
+		return err
+	}
+
+	printResponseJSON(resp)
+	return nil
+}
+
 func getComponentFromStrings(components []string) []adminsvc.Component {
 	var result []adminsvc.Component
 	for _, component := range components {
@@ -20,6 +20,7 @@ import (
 
 	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
 	adminmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc/mocks"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
@@ -99,3 +100,37 @@ func (suite *adminActionsTestSuite) TestRemoveLockdownFailure() {
 
 	suite.NoError(suite.client.UnlockComponents([]string{adminsvc.Component_GoalStateEngine.String()}))
 }
+
+func (suite *adminActionsTestSuite) TestJobCacheSnapshot() {
+	jobID := "b64fd26b-0e39-41b7-b22a-205b69f247bd"
+	suite.mockAdmin.EXPECT().GetJobCacheSnapshot(suite.ctx, &adminsvc.GetJobCacheSnapshotRequest{
+		JobId: &v1alphapeloton.JobID{Value: jobID},
+	}).Return(&adminsvc.GetJobCacheSnapshotResponse{
+		Snapshot: `{"job_id":"b64fd26b-0e39-41b7-b22a-205b69f247bd"}`,
+	}, nil)
+	suite.NoError(suite.client.JobCacheSnapshot(jobID))
+
+	suite.mockAdmin.EXPECT().GetJobCacheSnapshot(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found in cache"))
+	suite.Error(suite.client.JobCacheSnapshot(jobID))
+}
+
+func (suite *adminActionsTestSuite) TestStuckGoalStateEntities() {
+	suite.mockAdmin.EXPECT().GetStuckGoalStateEntities(
+		suite.ctx, &adminsvc.GetStuckGoalStateEntitiesRequest{},
+	).Return(&adminsvc.GetStuckGoalStateEntitiesResponse{
+		Entities: []*adminsvc.StuckGoalStateEntity{
+			{
+				Kind:       "task",
+				JobId:      &v1alphapeloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"},
+				InstanceId: 1,
+				StuckSince: "2017-07-14T02:40:00Z",
+			},
+		},
+	}, nil)
+	suite.NoError(suite.client.StuckGoalStateEntities())
+
+	suite.mockAdmin.EXPECT().GetStuckGoalStateEntities(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("job manager unavailable"))
+	suite.Error(suite.client.StuckGoalStateEntities())
+}
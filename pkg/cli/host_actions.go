@@ -16,8 +16,10 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
@@ -26,6 +28,8 @@ import (
 	host_svc_v1 "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
+
+	pb "gopkg.in/cheggaaa/pb.v1"
 )
 
 const (
@@ -36,6 +40,9 @@ const (
 	getHostsFormatBody    = "%s\t%.2f\t%.2f\t%.2f MB\t%.2f MB\t%s\t%s\t%s\n"
 	hostCacheFormatHeader = "Hostname\tCPU\tGPU\tMEM\tDisk\tStatus\n"
 	hostCacheFormatBody   = "%s\t%.2f/%.2f\t%.2f/%.2f\t%.2f/%.2f MB\t%.2f/%.2f MB\t%s\n"
+
+	hostDrainProgressTimeout = 30 * time.Minute
+	hostDrainProgressRefresh = 5 * time.Second
 )
 
 // HostCacheDump dumps the contents of the host cache.
@@ -114,6 +121,159 @@ func (c *Client) HostMaintenanceCompleteAction(hostname string) error {
 	return nil
 }
 
+// HostDrainAction is the action for draining a host. It starts maintenance
+// on the host, recording the reason in the audit log of host manager. If
+// watch is set, it waits for the host to be DOWN while showing how many of
+// the tasks running on the host have been evicted.
+func (c *Client) HostDrainAction(hostname string, reason string, watch bool) error {
+	if len(hostname) == 0 {
+		return fmt.Errorf("Empty hostname")
+	}
+	resp, err := c.hostClient.StartMaintenance(
+		c.ctx,
+		&host_svc.StartMaintenanceRequest{
+			Hostname: hostname,
+			Reason:   reason,
+		})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tabWriter, "Host drain started: %s\n", resp.GetHostname())
+	tabWriter.Flush()
+
+	if !watch {
+		return nil
+	}
+	return c.watchHostDrain(hostname)
+}
+
+// HostDrainStatusAction is the action for showing the drain progress of a
+// host, which is its state and the number of tasks still running on it.
+// If watch is set, it waits for the host to be DOWN while showing how many
+// of the tasks running on the host have been evicted.
+func (c *Client) HostDrainStatusAction(hostname string, watch bool) error {
+	if len(hostname) == 0 {
+		return fmt.Errorf("Empty hostname")
+	}
+	if watch {
+		return c.watchHostDrain(hostname)
+	}
+
+	state, running, err := c.hostDrainStatus(hostname)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tabWriter, "Host %s state: %s, running tasks: %d\n",
+		hostname, state, running)
+	tabWriter.Flush()
+	return nil
+}
+
+// HostUndrainAction is the action for bringing a drained host back UP.
+// The reason is recorded in the audit log of host manager.
+func (c *Client) HostUndrainAction(hostname string, reason string) error {
+	if len(hostname) == 0 {
+		return fmt.Errorf("Empty hostname")
+	}
+	resp, err := c.hostClient.CompleteMaintenance(
+		c.ctx,
+		&host_svc.CompleteMaintenanceRequest{
+			Hostname: hostname,
+			Reason:   reason,
+		})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(tabWriter, "Host undrain started: %s\n", resp.GetHostname())
+	tabWriter.Flush()
+	return nil
+}
+
+// watchHostDrain waits for a host to be DOWN, showing the number of tasks
+// evicted from the host since the watch started.
+func (c *Client) watchHostDrain(hostname string) error {
+	state, running, err := c.hostDrainStatus(hostname)
+	if err != nil {
+		return err
+	}
+	if state == host.HostState_HOST_STATE_DOWN {
+		fmt.Fprintf(tabWriter, "Host %s drained\n", hostname)
+		tabWriter.Flush()
+		return nil
+	}
+
+	// init the bar to the tasks running on the host
+	total := running
+	bar := pb.Simple.
+		Start(total).
+		SetTotal(int64(total)).
+		SetWidth(150).
+		SetRefreshRate(time.Second).
+		Set("prefix", fmt.Sprintf("Host %s evicted/total: ", hostname))
+	defer bar.Finish()
+
+	// Keep trying until we're timed out or the host is down or got an error
+	timeout := time.After(hostDrainProgressTimeout)
+	refresh := time.Tick(hostDrainProgressRefresh)
+	for {
+		select {
+		case <-timeout:
+			fmt.Fprint(os.Stderr, "Timed out waiting for host to drain")
+			return nil
+		case <-refresh:
+			state, running, err := c.hostDrainStatus(hostname)
+			if err != nil {
+				return err
+			}
+
+			if running < total {
+				bar.SetCurrent(int64(total - running))
+			}
+			if state == host.HostState_HOST_STATE_DOWN {
+				bar.SetCurrent(int64(total))
+				return nil
+			}
+		}
+	}
+}
+
+// hostDrainStatus returns the state of a host and the number of tasks
+// running on it.
+func (c *Client) hostDrainStatus(hostname string) (host.HostState, int, error) {
+	queryResp, err := c.hostClient.QueryHosts(
+		c.ctx,
+		&host_svc.QueryHostsRequest{})
+	if err != nil {
+		return host.HostState_HOST_STATE_INVALID, 0, err
+	}
+
+	state := host.HostState_HOST_STATE_INVALID
+	for _, h := range queryResp.GetHostInfos() {
+		if h.GetHostname() == hostname {
+			state = h.GetState()
+			break
+		}
+	}
+	if state == host.HostState_HOST_STATE_INVALID {
+		return state, 0, fmt.Errorf("host %s not found", hostname)
+	}
+
+	hostsResp, err := c.hostMgrClient.GetHostsByQuery(
+		c.ctx,
+		&hostsvc.GetHostsByQueryRequest{
+			Hostnames: []string{hostname},
+		})
+	if err != nil {
+		return state, 0, err
+	}
+
+	running := 0
+	for _, h := range hostsResp.GetHosts() {
+		running += len(h.GetTasks())
+	}
+	return state, running, nil
+}
+
 // HostQueryAction is the action for querying hosts by states. This can be to used to monitor the state of the host(s)
 // Eg. When a list of hosts are put into maintenance (`host maintenance start`).
 // A host, at any given time, will be in one of the following states
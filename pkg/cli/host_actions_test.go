@@ -114,6 +114,129 @@ func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCompleteAction()
 	suite.Error(err)
 }
 
+func (suite *hostmgrActionsTestSuite) TestClientHostDrainAction() {
+	mockHostMgr := hostmgrMocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	c := Client{
+		Debug:         false,
+		hostClient:    suite.mockHostmgr,
+		hostMgrClient: mockHostMgr,
+		dispatcher:    nil,
+		ctx:           suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		StartMaintenance(gomock.Any(), &hostsvc.StartMaintenanceRequest{
+			Hostname: "hostname",
+			Reason:   "kernel upgrade",
+		}).
+		Return(&hostsvc.StartMaintenanceResponse{Hostname: "hostname"}, nil)
+	suite.NoError(c.HostDrainAction("hostname", "kernel upgrade", false))
+
+	// watch returns once the host is down
+	suite.mockHostmgr.EXPECT().
+		StartMaintenance(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.StartMaintenanceResponse{Hostname: "hostname"}, nil)
+	suite.mockHostmgr.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.QueryHostsResponse{
+			HostInfos: []*host.HostInfo{
+				{Hostname: "hostname", State: host.HostState_HOST_STATE_DOWN},
+			},
+		}, nil)
+	mockHostMgr.EXPECT().
+		GetHostsByQuery(gomock.Any(), gomock.Any()).
+		Return(&hostmgrsvc.GetHostsByQueryResponse{}, nil)
+	suite.NoError(c.HostDrainAction("hostname", "", true))
+
+	// Test StartMaintenance error
+	suite.mockHostmgr.EXPECT().
+		StartMaintenance(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake StartMaintenance error"))
+	suite.Error(c.HostDrainAction("hostname", "", true))
+
+	// Test empty hostname error
+	suite.Error(c.HostDrainAction("", "", false))
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostDrainStatusAction() {
+	mockHostMgr := hostmgrMocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	c := Client{
+		Debug:         false,
+		hostClient:    suite.mockHostmgr,
+		hostMgrClient: mockHostMgr,
+		dispatcher:    nil,
+		ctx:           suite.ctx,
+	}
+	taskID := "task-1"
+
+	suite.mockHostmgr.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.QueryHostsResponse{
+			HostInfos: []*host.HostInfo{
+				{Hostname: "other", State: host.HostState_HOST_STATE_UP},
+				{Hostname: "hostname", State: host.HostState_HOST_STATE_DRAINING},
+			},
+		}, nil).
+		Times(2)
+	mockHostMgr.EXPECT().
+		GetHostsByQuery(gomock.Any(), &hostmgrsvc.GetHostsByQueryRequest{
+			Hostnames: []string{"hostname"},
+		}).
+		Return(&hostmgrsvc.GetHostsByQueryResponse{
+			Hosts: []*hostmgrsvc.GetHostsByQueryResponse_Host{
+				{
+					Hostname: "hostname",
+					Tasks:    []*mesos.TaskID{{Value: &taskID}},
+				},
+			},
+		}, nil)
+	suite.NoError(c.HostDrainStatusAction("hostname", false))
+
+	// Test GetHostsByQuery error
+	mockHostMgr.EXPECT().
+		GetHostsByQuery(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake GetHostsByQuery error"))
+	state, running, err := c.hostDrainStatus("hostname")
+	suite.Error(err)
+	suite.Equal(host.HostState_HOST_STATE_DRAINING, state)
+	suite.Zero(running)
+
+	// Test host not found
+	suite.mockHostmgr.EXPECT().
+		QueryHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.QueryHostsResponse{}, nil)
+	suite.Error(c.HostDrainStatusAction("hostname", false))
+
+	// Test empty hostname error
+	suite.Error(c.HostDrainStatusAction("", false))
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostUndrainAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		CompleteMaintenance(gomock.Any(), &hostsvc.CompleteMaintenanceRequest{
+			Hostname: "hostname",
+			Reason:   "done",
+		}).
+		Return(&hostsvc.CompleteMaintenanceResponse{Hostname: "hostname"}, nil)
+	suite.NoError(c.HostUndrainAction("hostname", "done"))
+
+	// Test CompleteMaintenance error
+	suite.mockHostmgr.EXPECT().
+		CompleteMaintenance(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake CompleteMaintenance error"))
+	suite.Error(c.HostUndrainAction("hostname", ""))
+
+	// Test empty hostname error
+	suite.Error(c.HostUndrainAction("", ""))
+}
+
 func (suite *hostmgrActionsTestSuite) TestClientHostQueryAction() {
 	c := Client{
 		Debug:      false,
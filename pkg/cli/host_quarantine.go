@@ -0,0 +1,77 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+)
+
+const quarantinedHostsFormatHeader = "Hostname\tExpiration\t\n"
+
+// HostQuarantineListAction prints the hosts quarantined from placement
+// since their offers are rescinded repeatedly.
+func (c *Client) HostQuarantineListAction() error {
+	resp, err := c.hostMgrClient.GetQuarantinedHosts(
+		c.ctx,
+		&hostsvc.GetQuarantinedHostsRequest{})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.GetHosts()) == 0 {
+		fmt.Fprint(tabWriter, "No hosts are quarantined\n")
+		tabWriter.Flush()
+		return nil
+	}
+
+	fmt.Fprint(tabWriter, quarantinedHostsFormatHeader)
+	for _, h := range resp.GetHosts() {
+		fmt.Fprintf(tabWriter, "%s\t%s\t\n", h.GetHostname(), h.GetExpiration())
+	}
+	tabWriter.Flush()
+	return nil
+}
+
+// HostQuarantineClearAction releases the given hosts, or all the hosts
+// if all is set, from quarantine.
+func (c *Client) HostQuarantineClearAction(hostnames []string, all bool) error {
+	if len(hostnames) == 0 && !all {
+		return errors.New("no hosts to clear quarantine of")
+	}
+
+	resp, err := c.hostMgrClient.ClearHostQuarantine(
+		c.ctx,
+		&hostsvc.ClearHostQuarantineRequest{
+			Hostnames: hostnames,
+			All:       all,
+		})
+	if err != nil {
+		return err
+	}
+	if resp.GetError().GetInvalidArgument() != nil {
+		return errors.New(resp.GetError().GetInvalidArgument().GetMessage())
+	}
+
+	fmt.Fprintf(tabWriter, "Cleared quarantine of %d hosts\n",
+		len(resp.GetClearedHostnames()))
+	for _, hostname := range resp.GetClearedHostnames() {
+		fmt.Fprintf(tabWriter, "%s\n", hostname)
+	}
+	tabWriter.Flush()
+	return nil
+}
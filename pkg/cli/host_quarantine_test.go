@@ -0,0 +1,103 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostMocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type hostQuarantineActionsTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockHostMgr *hostMocks.MockInternalHostServiceYARPCClient
+	client      Client
+}
+
+func (suite *hostQuarantineActionsTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHostMgr = hostMocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	suite.client = Client{
+		Debug:         false,
+		hostMgrClient: suite.mockHostMgr,
+		dispatcher:    nil,
+		ctx:           context.Background(),
+	}
+}
+
+func (suite *hostQuarantineActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *hostQuarantineActionsTestSuite) TestHostQuarantineListAction() {
+	suite.mockHostMgr.EXPECT().
+		GetQuarantinedHosts(gomock.Any(), &hostsvc.GetQuarantinedHostsRequest{}).
+		Return(&hostsvc.GetQuarantinedHostsResponse{
+			Hosts: []*hostsvc.QuarantinedHost{
+				{Hostname: "h1", Expiration: "2019-01-01T00:00:00Z"},
+			},
+		}, nil)
+	suite.NoError(suite.client.HostQuarantineListAction())
+
+	suite.mockHostMgr.EXPECT().
+		GetQuarantinedHosts(gomock.Any(), &hostsvc.GetQuarantinedHostsRequest{}).
+		Return(&hostsvc.GetQuarantinedHostsResponse{}, nil)
+	suite.NoError(suite.client.HostQuarantineListAction())
+
+	suite.mockHostMgr.EXPECT().
+		GetQuarantinedHosts(gomock.Any(), &hostsvc.GetQuarantinedHostsRequest{}).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.HostQuarantineListAction())
+}
+
+func (suite *hostQuarantineActionsTestSuite) TestHostQuarantineClearAction() {
+	suite.Error(suite.client.HostQuarantineClearAction(nil, false))
+
+	suite.mockHostMgr.EXPECT().
+		ClearHostQuarantine(gomock.Any(), &hostsvc.ClearHostQuarantineRequest{
+			Hostnames: []string{"h1", "h2"},
+		}).
+		Return(&hostsvc.ClearHostQuarantineResponse{
+			ClearedHostnames: []string{"h1"},
+		}, nil)
+	suite.NoError(suite.client.HostQuarantineClearAction([]string{"h1", "h2"}, false))
+
+	suite.mockHostMgr.EXPECT().
+		ClearHostQuarantine(gomock.Any(), &hostsvc.ClearHostQuarantineRequest{
+			All: true,
+		}).
+		Return(&hostsvc.ClearHostQuarantineResponse{
+			Error: &hostsvc.ClearHostQuarantineResponse_Error{
+				InvalidArgument: &hostsvc.InvalidArgument{Message: "invalid"},
+			},
+		}, nil)
+	suite.Error(suite.client.HostQuarantineClearAction(nil, true))
+
+	suite.mockHostMgr.EXPECT().
+		ClearHostQuarantine(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.HostQuarantineClearAction(nil, true))
+}
+
+func TestHostQuarantineActions(t *testing.T) {
+	suite.Run(t, new(hostQuarantineActionsTestSuite))
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/pkg/common"
+)
+
+const hostResourcesFormatHeader = "Hostname\tCPU\tMem(MB)\tDisk(MB)\tGPU\t" +
+	"Slack CPU\t\n"
+
+// HostResourcesAction prints the capacity of the given hosts, or of all the
+// hosts if none are given, along with the resources offered and allocated
+// to Peloton on them, as allocated/offered/capacity.
+func (c *Client) HostResourcesAction(hostnames []string) error {
+	resp, err := c.hostMgrClient.GetHostResources(
+		c.ctx,
+		&hostsvc.GetHostResourcesRequest{Hostnames: hostnames})
+	if err != nil {
+		return err
+	}
+	if resp.GetError().GetClusterUnavailable() != nil {
+		return errors.New(resp.GetError().GetClusterUnavailable().GetMessage())
+	}
+
+	if len(resp.GetHosts()) == 0 {
+		fmt.Fprint(tabWriter, "No hosts found\n")
+		tabWriter.Flush()
+		return nil
+	}
+
+	fmt.Fprint(tabWriter, hostResourcesFormatHeader)
+	for _, h := range resp.GetHosts() {
+		fmt.Fprintf(tabWriter, "%s\t", h.GetHostname())
+		for _, kind := range []string{
+			common.CPU, common.MEMORY, common.DISK, common.GPU} {
+			fmt.Fprintf(tabWriter, "%s\t", formatHostResource(
+				kind, h.GetAllocated(), h.GetOffered(), h.GetPhysicalCapacity()))
+		}
+		fmt.Fprintf(tabWriter, "%s\t\n", formatHostResource(
+			common.CPU, h.GetAllocatedSlack(), h.GetOfferedSlack(), h.GetSlackCapacity()))
+	}
+	tabWriter.Flush()
+	return nil
+}
+
+// formatHostResource formats a kind of host resource as
+// allocated/offered/capacity.
+func formatHostResource(
+	kind string,
+	allocated, offered, capacity []*hostsvc.Resource) string {
+	return fmt.Sprintf("%.2f/%.2f/%.2f",
+		getHostResource(kind, allocated),
+		getHostResource(kind, offered),
+		getHostResource(kind, capacity))
+}
+
+func getHostResource(kind string, resources []*hostsvc.Resource) float64 {
+	for _, r := range resources {
+		if r.GetKind() == kind {
+			return r.GetCapacity()
+		}
+	}
+	return 0
+}
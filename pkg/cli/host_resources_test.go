@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostMocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/pkg/common"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type hostResourcesActionsTestSuite struct {
+	suite.Suite
+	mockCtrl    *gomock.Controller
+	mockHostMgr *hostMocks.MockInternalHostServiceYARPCClient
+	client      Client
+}
+
+func (suite *hostResourcesActionsTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHostMgr = hostMocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	suite.client = Client{
+		Debug:         false,
+		hostMgrClient: suite.mockHostMgr,
+		dispatcher:    nil,
+		ctx:           context.Background(),
+	}
+}
+
+func (suite *hostResourcesActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *hostResourcesActionsTestSuite) TestHostResourcesAction() {
+	suite.mockHostMgr.EXPECT().
+		GetHostResources(gomock.Any(), &hostsvc.GetHostResourcesRequest{
+			Hostnames: []string{"h1"},
+		}).
+		Return(&hostsvc.GetHostResourcesResponse{
+			Hosts: []*hostsvc.HostResources{
+				{
+					Hostname: "h1",
+					PhysicalCapacity: []*hostsvc.Resource{
+						{Kind: common.CPU, Capacity: 4},
+					},
+					Offered: []*hostsvc.Resource{
+						{Kind: common.CPU, Capacity: 1},
+					},
+					Allocated: []*hostsvc.Resource{
+						{Kind: common.CPU, Capacity: 2},
+					},
+				},
+			},
+		}, nil)
+	suite.NoError(suite.client.HostResourcesAction([]string{"h1"}))
+
+	suite.mockHostMgr.EXPECT().
+		GetHostResources(gomock.Any(), &hostsvc.GetHostResourcesRequest{}).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil)
+	suite.NoError(suite.client.HostResourcesAction(nil))
+
+	suite.mockHostMgr.EXPECT().
+		GetHostResources(gomock.Any(), &hostsvc.GetHostResourcesRequest{}).
+		Return(&hostsvc.GetHostResourcesResponse{
+			Error: &hostsvc.GetHostResourcesResponse_Error{
+				ClusterUnavailable: &hostsvc.ClusterUnavailable{
+					Message: "error getting host agentmap",
+				},
+			},
+		}, nil)
+	suite.Error(suite.client.HostResourcesAction(nil))
+
+	suite.mockHostMgr.EXPECT().
+		GetHostResources(gomock.Any(), &hostsvc.GetHostResourcesRequest{}).
+		Return(nil, errors.New("test error"))
+	suite.Error(suite.client.HostResourcesAction(nil))
+}
+
+func (suite *hostResourcesActionsTestSuite) TestFormatHostResource() {
+	suite.Equal("2.00/1.00/4.00", formatHostResource(
+		common.CPU,
+		[]*hostsvc.Resource{{Kind: common.CPU, Capacity: 2}},
+		[]*hostsvc.Resource{{Kind: common.CPU, Capacity: 1}},
+		[]*hostsvc.Resource{
+			{Kind: common.MEMORY, Capacity: 8},
+			{Kind: common.CPU, Capacity: 4},
+		}))
+}
+
+func TestHostResourcesActions(t *testing.T) {
+	suite.Run(t, new(hostResourcesActionsTestSuite))
+}
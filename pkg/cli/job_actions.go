@@ -28,6 +28,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/query"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
+	"github.com/uber/peloton/pkg/common/cron"
 	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/common/util"
 	jobmgrtask "github.com/uber/peloton/pkg/jobmgr/task"
@@ -53,6 +54,9 @@ const (
 		"Running\tSucceeded\tFailed\tKilled\t\n"
 	jobSummaryFormatBody = "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t\n"
 
+	cronRunsFormatHeader = "Run ID\tScheduled Time\tState\t\n"
+	cronRunsFormatBody   = "%s\t%s\t%s\t\n"
+
 	jobStopConfirmationMessage = "The above jobs will be stopped. " +
 		"Are you sure you want to continue?"
 )
@@ -189,6 +193,66 @@ func (c *Client) JobStatusAction(jobID string) error {
 	return nil
 }
 
+// JobCronRunsAction is the action for listing the past runs of a cron job,
+// along with their state, and its next scheduled times
+func (c *Client) JobCronRunsAction(jobID string, upcoming uint32) error {
+	response, err := c.jobGet(jobID)
+	if err != nil {
+		return err
+	}
+
+	cronConfig := response.GetJobInfo().GetConfig().GetCronConfig()
+	if cronConfig == nil {
+		return fmt.Errorf("job %s is not a cron job", jobID)
+	}
+	schedule, err := cron.Parse(cronConfig.GetSchedule())
+	if err != nil {
+		return err
+	}
+
+	runtime := response.GetJobInfo().GetRuntime()
+	cronStatus := runtime.GetCronStatus()
+	fmt.Fprintf(tabWriter, "Schedule: %s\n", cronConfig.GetSchedule())
+	fmt.Fprintf(tabWriter, "Skipped runs: %d\n\n", cronStatus.GetSkippedRuns())
+
+	fmt.Fprint(tabWriter, cronRunsFormatHeader)
+	for _, run := range cronStatus.GetRuns() {
+		state := "--"
+		runResponse, err := c.jobGet(run.GetJobId().GetValue())
+		if err != nil {
+			return err
+		}
+		if runResponse.GetJobInfo().GetRuntime() != nil {
+			state = runResponse.GetJobInfo().GetRuntime().GetState().String()
+		}
+		fmt.Fprintf(
+			tabWriter,
+			cronRunsFormatBody,
+			run.GetJobId().GetValue(),
+			run.GetScheduledTime(),
+			state,
+		)
+	}
+	tabWriter.Flush()
+
+	// a terminated cron job does not run anymore
+	if util.IsPelotonJobStateTerminal(runtime.GetState()) {
+		return nil
+	}
+
+	fmt.Fprint(tabWriter, "\nUpcoming runs:\n")
+	next := time.Now()
+	for i := uint32(0); i < upcoming; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+		fmt.Fprintf(tabWriter, "%s\n", next.Format(time.RFC3339))
+	}
+	tabWriter.Flush()
+	return nil
+}
+
 // JobQueryAction is the action for getting job ids by labels,
 // respool path, keywords, state(s), owner and jobname
 func (c *Client) JobQueryAction(
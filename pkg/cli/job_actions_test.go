@@ -903,6 +903,69 @@ func (suite *jobActionsTestSuite) TestClientJobGetAction() {
 	}
 }
 
+// TestClientJobCronRunsAction tests listing the runs of a cron job
+func (suite *jobActionsTestSuite) TestClientJobCronRunsAction() {
+	cronJob := &job.GetResponse{
+		JobInfo: &job.JobInfo{
+			Id: &peloton.JobID{Value: testJobID},
+			Config: &job.JobConfig{
+				Type: job.JobType_BATCH,
+				CronConfig: &job.CronConfig{
+					Schedule: "@hourly",
+				},
+			},
+			Runtime: &job.RuntimeInfo{
+				State: job.JobState_INITIALIZED,
+				CronStatus: &job.CronStatus{
+					Runs: []*job.CronRun{{
+						JobId:         &peloton.JobID{Value: testJobID2},
+						ScheduledTime: "2019-01-01T10:00:00Z",
+					}},
+				},
+			},
+		},
+	}
+
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{
+			Id: &peloton.JobID{Value: testJobID},
+		}).
+		Return(cronJob, nil)
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{
+			Id: &peloton.JobID{Value: testJobID2},
+		}).
+		Return(&job.GetResponse{
+			JobInfo: &job.JobInfo{
+				Runtime: &job.RuntimeInfo{State: job.JobState_SUCCEEDED},
+			},
+		}, nil)
+	suite.NoError(suite.client.JobCronRunsAction(testJobID, 3))
+
+	// failure to get a run
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{
+			Id: &peloton.JobID{Value: testJobID},
+		}).
+		Return(cronJob, nil)
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{
+			Id: &peloton.JobID{Value: testJobID2},
+		}).
+		Return(nil, errors.New("unable to get job"))
+	suite.Error(suite.client.JobCronRunsAction(testJobID, 3))
+
+	// not a cron job
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(&job.GetResponse{
+			JobInfo: &job.JobInfo{
+				Config: &job.JobConfig{Type: job.JobType_BATCH},
+			},
+		}, nil)
+	suite.Error(suite.client.JobCronRunsAction(testJobID, 3))
+}
+
 // TestClientJobStatusAction tests fetching job status
 func (suite *jobActionsTestSuite) TestClientJobStatusAction() {
 
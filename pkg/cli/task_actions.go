@@ -30,8 +30,8 @@ import (
 
 const (
 	taskListFormatHeader = "Instance\tName\tState\tHealthy\tStart Time\tRun Time\t" +
-		"Host\tMessage\tReason\tTermination Status\t\n"
-	taskListFormatBody    = "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n"
+		"Host\tMessage\tReason\tTermination Status\tRestart Backoff\t\n"
+	taskListFormatBody    = "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n"
 	podEventsFormatHeader = "Mesos Task Id\tDesired Mesos Task Id\tActual State\tGoal State\tConfig Version\tDesired Config Version\tHealthy\tHost\tMessage\tReason\tUpdate Time\t\n"
 	podEventsFormatBody   = "%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t\n"
 )
@@ -154,7 +154,8 @@ func (c *Client) PodGetEventsAction(
 	jobID string,
 	instanceID uint32,
 	runID string,
-	limit uint64) error {
+	limit uint64,
+	compact bool) error {
 	var request = &task.GetPodEventsRequest{
 		JobId: &peloton.JobID{
 			Value: jobID,
@@ -162,6 +163,7 @@ func (c *Client) PodGetEventsAction(
 		InstanceId: instanceID,
 		RunId:      runID,
 		Limit:      limit,
+		Compact:    compact,
 	}
 	response, err := c.taskClient.GetPodEvents(c.ctx, request)
 	if err != nil {
@@ -371,6 +373,21 @@ func printTask(t *task.TaskInfo) {
 		termStatusStr = strings.TrimPrefix(termStatusStr, "TERMINATION_STATUS_REASON_")
 	}
 
+	// the remaining time before a failed task is restarted
+	backoffStr := ""
+	backoffUntil, err := time.Parse(
+		time.RFC3339Nano, runtime.GetRestartBackoffUntil())
+	if err == nil && time.Now().Before(backoffUntil) {
+		backoff := time.Until(backoffUntil)
+		backoffStr = fmt.Sprintf(
+			"%02d:%02d:%02d (failures: %d)",
+			uint(backoff.Hours()),
+			uint(backoff.Minutes())%60,
+			uint(backoff.Seconds())%60,
+			runtime.GetConsecutiveFailureCount(),
+		)
+	}
+
 	// Print the task record
 	fmt.Fprintf(
 		tabWriter,
@@ -385,6 +402,7 @@ func printTask(t *task.TaskInfo) {
 		runtime.GetMessage(),
 		runtime.GetReason(),
 		termStatusStr,
+		backoffStr,
 	)
 }
 
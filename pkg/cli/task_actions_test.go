@@ -300,7 +300,7 @@ func (suite *taskActionsTestSuite) TestGetPodEvents() {
 
 	suite.mockTask.EXPECT().GetPodEvents(context.Background(), req).
 		Return(nil, errors.New("get pod events request failed"))
-	err := c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5)
+	err := c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5, false)
 	suite.Error(err)
 
 	podEvent := &task.PodEvent{
@@ -320,7 +320,7 @@ func (suite *taskActionsTestSuite) TestGetPodEvents() {
 	}
 	suite.mockTask.EXPECT().GetPodEvents(context.Background(), req).
 		Return(response, nil)
-	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5)
+	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5, false)
 	suite.NoError(err)
 
 	response = &task.GetPodEventsResponse{
@@ -330,13 +330,13 @@ func (suite *taskActionsTestSuite) TestGetPodEvents() {
 	}
 	suite.mockTask.EXPECT().GetPodEvents(context.Background(), req).
 		Return(response, nil)
-	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5)
+	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5, false)
 	suite.NoError(err)
 
 	c.Debug = true
 	suite.mockTask.EXPECT().GetPodEvents(context.Background(), req).
 		Return(response, nil)
-	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5)
+	err = c.PodGetEventsAction(jobID.GetValue(), 0, runID, 5, false)
 	suite.NoError(err)
 }
 
@@ -339,6 +339,9 @@ func printUpdateGetResponse(resp *updatesvc.GetUpdateResponse, debug bool) {
 
 	fmt.Fprint(tabWriter, updateListFormatHeader)
 	printUpdate(resp.GetUpdateInfo())
+	if reason := resp.GetUpdateInfo().GetStatus().GetGateTripReason(); len(reason) != 0 {
+		fmt.Fprintf(tabWriter, "Health gate tripped: %s\n", reason)
+	}
 	return
 }
 
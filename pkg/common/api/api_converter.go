@@ -16,6 +16,7 @@ package api
 
 import (
 	"reflect"
+	"strings"
 
 	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -226,7 +227,10 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 
 	if taskConfig.GetRestartPolicy() != nil {
 		result.RestartPolicy = &pod.RestartPolicy{
-			MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:       taskConfig.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSec: taskConfig.GetRestartPolicy().GetInitialBackoffSec(),
+			MaxBackoffSec:     taskConfig.GetRestartPolicy().GetMaxBackoffSec(),
+			StableRunningSec:  taskConfig.GetRestartPolicy().GetStableRunningSec(),
 		}
 	}
 
@@ -265,29 +269,13 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 	}
 
 	if taskConfig.GetHealthCheck() != nil {
-		container.LivenessCheck = &pod.HealthCheckSpec{
-			Enabled:                taskConfig.GetHealthCheck().GetEnabled(),
-			InitialIntervalSecs:    taskConfig.GetHealthCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           taskConfig.GetHealthCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: taskConfig.GetHealthCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            taskConfig.GetHealthCheck().GetTimeoutSecs(),
-			Type:                   pod.HealthCheckSpec_HealthCheckType(taskConfig.GetHealthCheck().GetType()),
-		}
-
-		if taskConfig.GetHealthCheck().GetCommandCheck() != nil {
-			container.LivenessCheck.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
-				Command:             taskConfig.GetHealthCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: taskConfig.GetHealthCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
+		container.LivenessCheck = ConvertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetHealthCheck())
+	}
 
-		if taskConfig.GetHealthCheck().GetHttpCheck() != nil {
-			container.LivenessCheck.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
-				Scheme: taskConfig.GetHealthCheck().GetHttpCheck().GetScheme(),
-				Port:   taskConfig.GetHealthCheck().GetHttpCheck().GetPort(),
-				Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
-			}
-		}
+	if taskConfig.GetReadinessCheck() != nil {
+		container.ReadinessCheck = ConvertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetReadinessCheck())
 	}
 
 	if !reflect.DeepEqual(*container, pod.ContainerSpec{}) {
@@ -372,6 +360,126 @@ func ConvertPortConfigsToPortSpecs(ports []*task.PortConfig) []*pod.PortSpec {
 	return containerPorts
 }
 
+// ConvertHealthCheckConfigToHealthCheckSpec converts v0
+// task.HealthCheckConfig to v1alpha pod.HealthCheckSpec
+func ConvertHealthCheckConfigToHealthCheckSpec(
+	healthCheck *task.HealthCheckConfig,
+) *pod.HealthCheckSpec {
+	result := &pod.HealthCheckSpec{
+		Enabled:                healthCheck.GetEnabled(),
+		InitialIntervalSecs:    healthCheck.GetInitialIntervalSecs(),
+		IntervalSecs:           healthCheck.GetIntervalSecs(),
+		MaxConsecutiveFailures: healthCheck.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            healthCheck.GetTimeoutSecs(),
+		GracePeriodSecs:        healthCheck.GetGracePeriodSecs(),
+	}
+
+	switch healthCheck.GetType() {
+	case task.HealthCheckConfig_COMMAND:
+		result.Type = pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND
+	case task.HealthCheckConfig_HTTP:
+		result.Type = pod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP
+	case task.HealthCheckConfig_TCP:
+		result.Type = pod.HealthCheckSpec_HEALTH_CHECK_TYPE_TCP
+	default:
+		result.Type = pod.HealthCheckSpec_HEALTH_CHECK_TYPE_UNKNOWN
+	}
+
+	if healthCheck.GetCommandCheck() != nil {
+		result.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
+			Command:             healthCheck.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: healthCheck.GetCommandCheck().GetUnshareEnvironments(),
+		}
+	}
+
+	if healthCheck.GetHttpCheck() != nil {
+		result.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
+			Scheme: healthCheck.GetHttpCheck().GetScheme(),
+			Port:   healthCheck.GetHttpCheck().GetPort(),
+			Path:   healthCheck.GetHttpCheck().GetPath(),
+		}
+	}
+
+	if healthCheck.GetTcpCheck() != nil {
+		result.TcpSocket = &pod.TCPSocketSpec{
+			Port: healthCheck.GetTcpCheck().GetPort(),
+		}
+	}
+
+	return result
+}
+
+// ConvertHealthCheckSpecToHealthCheckConfig converts v1alpha
+// pod.HealthCheckSpec to v0 task.HealthCheckConfig. The command and
+// http_get fields take precedence over the deprecated command_check and
+// http_check fields when both are set.
+func ConvertHealthCheckSpecToHealthCheckConfig(
+	spec *pod.HealthCheckSpec,
+) *task.HealthCheckConfig {
+	result := &task.HealthCheckConfig{
+		Enabled:                spec.GetEnabled(),
+		InitialIntervalSecs:    spec.GetInitialIntervalSecs(),
+		IntervalSecs:           spec.GetIntervalSecs(),
+		MaxConsecutiveFailures: spec.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            spec.GetTimeoutSecs(),
+		GracePeriodSecs:        spec.GetGracePeriodSecs(),
+	}
+
+	switch spec.GetType() {
+	case pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND:
+		result.Type = task.HealthCheckConfig_COMMAND
+	case pod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP:
+		result.Type = task.HealthCheckConfig_HTTP
+	case pod.HealthCheckSpec_HEALTH_CHECK_TYPE_TCP:
+		result.Type = task.HealthCheckConfig_TCP
+	default:
+		result.Type = task.HealthCheckConfig_UNKNOWN
+	}
+
+	if spec.GetCommand() != nil {
+		result.CommandCheck = &task.HealthCheckConfig_CommandCheck{
+			Command: strings.Join(
+				append(
+					[]string{spec.GetCommand().GetValue()},
+					spec.GetCommand().GetArguments()...,
+				),
+				" ",
+			),
+		}
+	} else if spec.GetCommandCheck() != nil {
+		result.CommandCheck = &task.HealthCheckConfig_CommandCheck{
+			Command:             spec.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: spec.GetCommandCheck().GetUnshareEnvironments(),
+		}
+	}
+
+	if spec.GetHttpGet() != nil {
+		port := spec.GetHttpGet().GetPortSpec().GetValue()
+		if port == 0 {
+			port = spec.GetHttpGet().GetPort()
+		}
+		result.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
+			Scheme: spec.GetHttpGet().GetScheme(),
+			Port:   port,
+			Path:   spec.GetHttpGet().GetPath(),
+		}
+	} else if spec.GetHttpCheck() != nil {
+		result.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
+			Scheme: spec.GetHttpCheck().GetScheme(),
+			Port:   spec.GetHttpCheck().GetPort(),
+			Path:   spec.GetHttpCheck().GetPath(),
+		}
+	}
+
+	if spec.GetTcpSocket() != nil {
+		result.TcpCheck = &task.HealthCheckConfig_TCPCheck{
+			Port: spec.GetTcpSocket().GetPort(),
+		}
+	}
+
+	return result
+}
+
 // ConvertV0SecretsToV1Secrets converts v0 peloton.Secret to v1alpha peloton.Secret
 func ConvertV0SecretsToV1Secrets(secrets []*peloton.Secret) []*v1alphapeloton.Secret {
 	var v1secrets []*v1alphapeloton.Secret
@@ -471,6 +579,8 @@ func ConvertUpdateModelToWorkflowStatus(
 		CreationTime:          updateInfo.GetCreationTime(),
 		UpdateTime:            updateInfo.GetUpdateTime(),
 		CompletionTime:        updateInfo.GetCompletionTime(),
+		CanaryPromoted:        updateInfo.GetCanaryPromoted(),
+		GateTripReason:        updateInfo.GetGateTripReason(),
 	}
 }
 
@@ -571,6 +681,13 @@ func ConvertUpdateModelToWorkflowInfo(
 			MaxTolerableInstanceFailures: updateInfo.GetUpdateConfig().GetMaxFailureInstances(),
 			StartPaused:                  updateInfo.GetUpdateConfig().GetStartPaused(),
 			InPlace:                      updateInfo.GetUpdateConfig().GetInPlace(),
+			FailureDomainAttribute:       updateInfo.GetUpdateConfig().GetFailureDomainAttribute(),
+			VerificationHook: convertVerificationHookToVerificationHookSpec(
+				updateInfo.GetUpdateConfig().GetVerificationHook()),
+			Canary: convertCanaryConfigToCanarySpec(
+				updateInfo.GetUpdateConfig().GetCanary()),
+			HealthGate: convertHealthGateToHealthGateSpec(
+				updateInfo.GetUpdateConfig().GetHealthGate()),
 		}
 	} else if updateInfo.GetType() == models.WorkflowType_RESTART {
 		result.RestartSpec = &stateless.RestartSpec{
@@ -593,6 +710,7 @@ func ConvertUpdateModelToWorkflowInfo(
 // job query spec
 func ConvertStatelessQuerySpecToJobQuerySpec(spec *stateless.QuerySpec) *job.QuerySpec {
 	var labels []*peloton.Label
+	var labelSelectors []*peloton.Label
 	var jobStates []job.JobState
 	var creationTimeRange *peloton.TimeRange
 	var completionTimeRange *peloton.TimeRange
@@ -606,6 +724,13 @@ func ConvertStatelessQuerySpecToJobQuerySpec(spec *stateless.QuerySpec) *job.Que
 		})
 	}
 
+	for _, label := range spec.GetLabelSelectors() {
+		labelSelectors = append(labelSelectors, &peloton.Label{
+			Key:   label.GetKey(),
+			Value: label.GetValue(),
+		})
+	}
+
 	for _, jobState := range spec.GetJobStates() {
 		jobStates = append(jobStates, job.JobState(jobState))
 	}
@@ -646,6 +771,7 @@ func ConvertStatelessQuerySpecToJobQuerySpec(spec *stateless.QuerySpec) *job.Que
 		Name:                spec.GetName(),
 		CreationTimeRange:   creationTimeRange,
 		CompletionTimeRange: completionTimeRange,
+		LabelSelectors:      labelSelectors,
 	}
 }
 
@@ -1278,31 +1404,13 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	}
 
 	if mainContainer.GetLivenessCheck() != nil {
-		healthCheck := &task.HealthCheckConfig{
-			Enabled:                mainContainer.GetLivenessCheck().GetEnabled(),
-			InitialIntervalSecs:    mainContainer.GetLivenessCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           mainContainer.GetLivenessCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: mainContainer.GetLivenessCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            mainContainer.GetLivenessCheck().GetTimeoutSecs(),
-			Type:                   task.HealthCheckConfig_Type(mainContainer.GetLivenessCheck().GetType()),
-		}
-
-		if mainContainer.GetLivenessCheck().GetCommandCheck() != nil {
-			healthCheck.CommandCheck = &task.HealthCheckConfig_CommandCheck{
-				Command:             mainContainer.GetLivenessCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: mainContainer.GetLivenessCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
-
-		if mainContainer.GetLivenessCheck().GetHttpCheck() != nil {
-			healthCheck.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
-				Scheme: mainContainer.GetLivenessCheck().GetHttpCheck().GetScheme(),
-				Port:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPort(),
-				Path:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPath(),
-			}
-		}
+		result.HealthCheck = ConvertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetLivenessCheck())
+	}
 
-		result.HealthCheck = healthCheck
+	if mainContainer.GetReadinessCheck() != nil {
+		result.ReadinessCheck = ConvertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetReadinessCheck())
 	}
 
 	if len(mainContainer.GetPorts()) != 0 {
@@ -1325,7 +1433,10 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 
 	if spec.GetRestartPolicy() != nil {
 		result.RestartPolicy = &task.RestartPolicy{
-			MaxFailures: spec.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:       spec.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSec: spec.GetRestartPolicy().GetInitialBackoffSec(),
+			MaxBackoffSec:     spec.GetRestartPolicy().GetMaxBackoffSec(),
+			StableRunningSec:  spec.GetRestartPolicy().GetStableRunningSec(),
 		}
 	}
 
@@ -1402,16 +1513,151 @@ func ConvertPodConstraintsToTaskConstraints(
 // ConvertUpdateSpecToUpdateConfig converts update spec to update config
 func ConvertUpdateSpecToUpdateConfig(spec *stateless.UpdateSpec) *update.UpdateConfig {
 	return &update.UpdateConfig{
-		BatchSize:           spec.GetBatchSize(),
-		RollbackOnFailure:   spec.GetRollbackOnFailure(),
-		MaxInstanceAttempts: spec.GetMaxInstanceRetries(),
-		MaxFailureInstances: spec.GetMaxTolerableInstanceFailures(),
-		StartPaused:         spec.GetStartPaused(),
-		InPlace:             spec.GetInPlace(),
-		StartTasks:          spec.GetStartPods(),
+		BatchSize:              spec.GetBatchSize(),
+		RollbackOnFailure:      spec.GetRollbackOnFailure(),
+		MaxInstanceAttempts:    spec.GetMaxInstanceRetries(),
+		MaxFailureInstances:    spec.GetMaxTolerableInstanceFailures(),
+		StartPaused:            spec.GetStartPaused(),
+		InPlace:                spec.GetInPlace(),
+		StartTasks:             spec.GetStartPods(),
+		FailureDomainAttribute: spec.GetFailureDomainAttribute(),
+		VerificationHook: convertVerificationHookSpecToVerificationHook(
+			spec.GetVerificationHook()),
+		Canary:     convertCanarySpecToCanaryConfig(spec.GetCanary()),
+		HealthGate: convertHealthGateSpecToHealthGate(spec.GetHealthGate()),
+	}
+}
+
+// convertCanarySpecToCanaryConfig converts v1alpha canary spec
+// to v0 canary config
+func convertCanarySpecToCanaryConfig(
+	spec *stateless.CanarySpec,
+) *update.CanaryConfig {
+	if spec == nil {
+		return nil
+	}
+
+	return &update.CanaryConfig{
+		Instances:    spec.GetPods(),
+		BakeTimeSecs: spec.GetBakeTimeSecs(),
+	}
+}
+
+// convertCanaryConfigToCanarySpec converts v0 canary config
+// to v1alpha canary spec
+func convertCanaryConfigToCanarySpec(
+	canary *update.CanaryConfig,
+) *stateless.CanarySpec {
+	if canary == nil {
+		return nil
+	}
+
+	return &stateless.CanarySpec{
+		Pods:         canary.GetInstances(),
+		BakeTimeSecs: canary.GetBakeTimeSecs(),
 	}
 }
 
+// convertHealthGateSpecToHealthGate converts v1alpha health gate spec
+// to v0 health gate
+func convertHealthGateSpecToHealthGate(
+	spec *stateless.HealthGateSpec,
+) *update.HealthGate {
+	if spec == nil {
+		return nil
+	}
+
+	return &update.HealthGate{
+		MaxUnavailableInstances: spec.GetMaxUnavailablePods(),
+		MaxFailureRate:          spec.GetMaxFailureRate(),
+		WindowSecs:              spec.GetWindowSecs(),
+	}
+}
+
+// convertHealthGateToHealthGateSpec converts v0 health gate
+// to v1alpha health gate spec
+func convertHealthGateToHealthGateSpec(
+	gate *update.HealthGate,
+) *stateless.HealthGateSpec {
+	if gate == nil {
+		return nil
+	}
+
+	return &stateless.HealthGateSpec{
+		MaxUnavailablePods: gate.GetMaxUnavailableInstances(),
+		MaxFailureRate:     gate.GetMaxFailureRate(),
+		WindowSecs:         gate.GetWindowSecs(),
+	}
+}
+
+// convertVerificationHookSpecToVerificationHook converts v1alpha
+// verification hook spec to v0 verification hook
+func convertVerificationHookSpecToVerificationHook(
+	spec *stateless.VerificationHookSpec,
+) *update.VerificationHook {
+	if spec == nil {
+		return nil
+	}
+
+	hook := &update.VerificationHook{
+		Type:         update.VerificationHook_Type(spec.GetType()),
+		TimeoutSecs:  spec.GetTimeoutSecs(),
+		MaxAttempts:  spec.GetMaxAttempts(),
+		IntervalSecs: spec.GetIntervalSecs(),
+	}
+
+	if spec.GetCommandCheck() != nil {
+		hook.CommandCheck = &update.VerificationHook_CommandCheck{
+			Command: spec.GetCommandCheck().GetCommand(),
+		}
+	}
+
+	if spec.GetHttpCheck() != nil {
+		hook.HttpCheck = &update.VerificationHook_HTTPCheck{
+			Scheme:   spec.GetHttpCheck().GetScheme(),
+			Port:     spec.GetHttpCheck().GetPort(),
+			PortName: spec.GetHttpCheck().GetPortName(),
+			Path:     spec.GetHttpCheck().GetPath(),
+		}
+	}
+
+	return hook
+}
+
+// convertVerificationHookToVerificationHookSpec converts v0
+// verification hook to v1alpha verification hook spec
+func convertVerificationHookToVerificationHookSpec(
+	hook *update.VerificationHook,
+) *stateless.VerificationHookSpec {
+	if hook == nil {
+		return nil
+	}
+
+	spec := &stateless.VerificationHookSpec{
+		Type:         stateless.VerificationHookSpec_VerificationType(hook.GetType()),
+		TimeoutSecs:  hook.GetTimeoutSecs(),
+		MaxAttempts:  hook.GetMaxAttempts(),
+		IntervalSecs: hook.GetIntervalSecs(),
+	}
+
+	if hook.GetCommandCheck() != nil {
+		spec.CommandCheck = &stateless.VerificationHookSpec_CommandCheck{
+			Command: hook.GetCommandCheck().GetCommand(),
+		}
+	}
+
+	if hook.GetHttpCheck() != nil {
+		spec.HttpCheck = &stateless.VerificationHookSpec_HTTPCheck{
+			Scheme:   hook.GetHttpCheck().GetScheme(),
+			Port:     hook.GetHttpCheck().GetPort(),
+			PortName: hook.GetHttpCheck().GetPortName(),
+			Path:     hook.GetHttpCheck().GetPath(),
+		}
+	}
+
+	return spec
+}
+
 // ConvertCreateSpecToUpdateConfig converts create spec to update config
 func ConvertCreateSpecToUpdateConfig(spec *stateless.CreateSpec) *update.UpdateConfig {
 	return &update.UpdateConfig{
@@ -1529,6 +1775,8 @@ func convertTaskTerminationStatusToPodTerminationStatus(
 		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART
 	case task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART:
 		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART
+	case task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED:
+		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED
 	}
 	return &pod.TerminationStatus{
 		Reason:   podReason,
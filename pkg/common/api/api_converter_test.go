@@ -969,6 +969,90 @@ func (suite *apiConverterTestSuite) TestConvertContainerPorts() {
 	}
 }
 
+// TestConvertHealthCheckConfigToHealthCheckSpecAndViceVersa tests
+// conversion between v0 task.HealthCheckConfig and v1alpha
+// pod.HealthCheckSpec
+func (suite *apiConverterTestSuite) TestConvertHealthCheckConfigToHealthCheckSpecAndViceVersa() {
+	healthCheck := &task.HealthCheckConfig{
+		Enabled:                true,
+		InitialIntervalSecs:    1,
+		IntervalSecs:           2,
+		MaxConsecutiveFailures: 3,
+		TimeoutSecs:            4,
+		GracePeriodSecs:        5,
+		Type:                   task.HealthCheckConfig_TCP,
+		TcpCheck: &task.HealthCheckConfig_TCPCheck{
+			Port: 8080,
+		},
+	}
+	healthCheckSpec := &pod.HealthCheckSpec{
+		Enabled:                true,
+		InitialIntervalSecs:    1,
+		IntervalSecs:           2,
+		MaxConsecutiveFailures: 3,
+		TimeoutSecs:            4,
+		GracePeriodSecs:        5,
+		Type:                   pod.HealthCheckSpec_HEALTH_CHECK_TYPE_TCP,
+		TcpSocket: &pod.TCPSocketSpec{
+			Port: 8080,
+		},
+	}
+
+	suite.Equal(healthCheckSpec, ConvertHealthCheckConfigToHealthCheckSpec(healthCheck))
+	suite.Equal(healthCheck, ConvertHealthCheckSpecToHealthCheckConfig(healthCheckSpec))
+
+	// GRPC checks have no v1alpha equivalent
+	suite.Equal(
+		pod.HealthCheckSpec_HEALTH_CHECK_TYPE_UNKNOWN,
+		ConvertHealthCheckConfigToHealthCheckSpec(&task.HealthCheckConfig{
+			Type: task.HealthCheckConfig_GRPC,
+		}).GetType(),
+	)
+}
+
+// TestConvertHealthCheckSpecToHealthCheckConfigCommandAndHTTPGet tests
+// that the command and http_get fields are converted and take precedence
+// over the deprecated command_check and http_check fields
+func (suite *apiConverterTestSuite) TestConvertHealthCheckSpecToHealthCheckConfigCommandAndHTTPGet() {
+	commandSpec := &pod.HealthCheckSpec{
+		Type: pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+		Command: &pod.CommandSpec{
+			Value:     "/bin/check",
+			Arguments: []string{"--port", "8080"},
+		},
+		CommandCheck: &pod.HealthCheckSpec_CommandCheck{
+			Command: "/bin/deprecated",
+		},
+	}
+	healthCheck := ConvertHealthCheckSpecToHealthCheckConfig(commandSpec)
+	suite.Equal(task.HealthCheckConfig_COMMAND, healthCheck.GetType())
+	suite.Equal("/bin/check --port 8080", healthCheck.GetCommandCheck().GetCommand())
+
+	httpSpec := &pod.HealthCheckSpec{
+		Type: pod.HealthCheckSpec_HEALTH_CHECK_TYPE_HTTP,
+		HttpGet: &pod.HTTPGetSpec{
+			Scheme: "https",
+			Path:   "/health",
+			PortSpec: &pod.PortSpec{
+				Name:  "http",
+				Value: 8443,
+			},
+		},
+		HttpCheck: &pod.HealthCheckSpec_HTTPCheck{
+			Scheme: "http",
+			Port:   80,
+			Path:   "/deprecated",
+		},
+	}
+	healthCheck = ConvertHealthCheckSpecToHealthCheckConfig(httpSpec)
+	suite.Equal(task.HealthCheckConfig_HTTP, healthCheck.GetType())
+	suite.Equal(&task.HealthCheckConfig_HTTPCheck{
+		Scheme: "https",
+		Port:   8443,
+		Path:   "/health",
+	}, healthCheck.GetHttpCheck())
+}
+
 // TestConvertV0SecretsToV1Secrets tests conversion from
 // v0 peloton.Secret to v1alpha peloton.Secret
 func (suite *apiConverterTestSuite) TestConvertV0SecretsToV1Secrets() {
@@ -1235,6 +1319,8 @@ func (suite *apiConverterTestSuite) TestConvertUpdateModelToWorkflowStatus() {
 		PrevJobConfigVersion: prevJobConfigVersion,
 		CreationTime:         "2019-01-30T21:25:23Z",
 		UpdateTime:           "2019-01-30T21:35:23Z",
+		CanaryPromoted:       true,
+		GateTripReason:       "failure rate exceeded",
 	}
 	runtime := &job.RuntimeInfo{
 		ConfigurationVersion: _configVersion,
@@ -1254,6 +1340,8 @@ func (suite *apiConverterTestSuite) TestConvertUpdateModelToWorkflowStatus() {
 		PrevVersion:           versionutil.GetJobEntityVersion(prevJobConfigVersion, _desiredStateVersion, _workflowVersion),
 		CreationTime:          "2019-01-30T21:25:23Z",
 		UpdateTime:            "2019-01-30T21:35:23Z",
+		CanaryPromoted:        true,
+		GateTripReason:        "failure rate exceeded",
 	}
 
 	suite.Equal(workflowStatus, ConvertUpdateModelToWorkflowStatus(runtime, updateModel))
@@ -1550,6 +1638,12 @@ func (suite *apiConverterTestSuite) TestConvertStatelessQuerySpecToJobQuerySpec(
 				Value: "test-value",
 			},
 		},
+		LabelSelectors: []*v1alphapeloton.Label{
+			{
+				Key:   "test-selector-key",
+				Value: "test-selector-value",
+			},
+		},
 		JobStates: []stateless.JobState{
 			stateless.JobState_JOB_STATE_RUNNING,
 		},
@@ -1609,6 +1703,13 @@ func (suite *apiConverterTestSuite) TestConvertStatelessQuerySpecToJobQuerySpec(
 		})
 	}
 
+	for _, l := range statelessQuerySpec.GetLabelSelectors() {
+		jobSpec.LabelSelectors = append(jobSpec.LabelSelectors, &peloton.Label{
+			Key:   l.GetKey(),
+			Value: l.GetValue(),
+		})
+	}
+
 	suite.Equal(jobSpec, ConvertStatelessQuerySpecToJobQuerySpec(statelessQuerySpec))
 }
 
@@ -1620,6 +1721,25 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 		MaxInstanceRetries:           3,
 		MaxTolerableInstanceFailures: 2,
 		StartPaused:                  true,
+		FailureDomainAttribute:       "zone",
+		VerificationHook: &stateless.VerificationHookSpec{
+			Type: stateless.VerificationHookSpec_VERIFICATION_TYPE_HTTP,
+			HttpCheck: &stateless.VerificationHookSpec_HTTPCheck{
+				PortName: "http",
+				Path:     "/health",
+			},
+			TimeoutSecs: 5,
+			MaxAttempts: 2,
+		},
+		Canary: &stateless.CanarySpec{
+			Pods:         2,
+			BakeTimeSecs: 300,
+		},
+		HealthGate: &stateless.HealthGateSpec{
+			MaxUnavailablePods: 12,
+			MaxFailureRate:     0.25,
+			WindowSecs:         600,
+		},
 	}
 
 	config := ConvertUpdateSpecToUpdateConfig(spec)
@@ -1629,6 +1749,22 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 	suite.Equal(spec.GetMaxInstanceRetries(), config.GetMaxInstanceAttempts())
 	suite.Equal(spec.GetMaxTolerableInstanceFailures(), config.GetMaxFailureInstances())
 	suite.Equal(spec.GetStartPaused(), config.GetStartPaused())
+	suite.Equal(spec.GetFailureDomainAttribute(), config.GetFailureDomainAttribute())
+	suite.Equal(update.VerificationHook_HTTP, config.GetVerificationHook().GetType())
+	suite.Equal("http", config.GetVerificationHook().GetHttpCheck().GetPortName())
+	suite.Equal("/health", config.GetVerificationHook().GetHttpCheck().GetPath())
+	suite.Equal(uint32(5), config.GetVerificationHook().GetTimeoutSecs())
+	suite.Equal(uint32(2), config.GetVerificationHook().GetMaxAttempts())
+	suite.Equal(spec.GetVerificationHook(),
+		convertVerificationHookToVerificationHookSpec(config.GetVerificationHook()))
+	suite.Equal(uint32(2), config.GetCanary().GetInstances())
+	suite.Equal(uint32(300), config.GetCanary().GetBakeTimeSecs())
+	suite.Equal(uint32(12), config.GetHealthGate().GetMaxUnavailableInstances())
+	suite.Equal(0.25, config.GetHealthGate().GetMaxFailureRate())
+	suite.Equal(uint32(600), config.GetHealthGate().GetWindowSecs())
+	suite.Equal(spec.GetCanary(), convertCanaryConfigToCanarySpec(config.GetCanary()))
+	suite.Equal(spec.GetHealthGate(),
+		convertHealthGateToHealthGateSpec(config.GetHealthGate()))
 }
 
 // TestConvertInstanceIDListToInstanceRange tests conversion from
@@ -1776,6 +1912,7 @@ func (suite *apiConverterTestSuite) TestConvertTerminationStatusReason() {
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART:           pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART,
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE:            pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE,
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART: pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART,
+		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED: pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED,
 	}
 	// ensure that we have a test-case for every legal value of v0 reason
 	suite.Equal(len(task.TerminationStatus_Reason_name), len(expmap))
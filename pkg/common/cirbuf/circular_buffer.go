@@ -40,6 +40,15 @@ func NewCircularBuffer(bufferSize int) *CircularBuffer {
 	}
 }
 
+// NewCircularBufferAt creates an empty circular buffer with size
+// bufferSize, whose first item added has sequence id offset
+func NewCircularBufferAt(bufferSize int, offset uint64) *CircularBuffer {
+	c := NewCircularBuffer(bufferSize)
+	c.head = offset
+	c.tail = offset
+	return c
+}
+
 // Capacity returns the total capacity of the circular buffer
 func (c *CircularBuffer) Capacity() int {
 	c.RLock()
@@ -58,6 +58,31 @@ func TestCBBasic(t *testing.T) {
 	}
 }
 
+// TestCBAt tests a circular buffer starting at a given sequence id
+func TestCBAt(t *testing.T) {
+	cb := NewCircularBufferAt(5, 100)
+	head, tail := cb.GetRange()
+	assert.Equal(t, uint64(100), head)
+	assert.Equal(t, uint64(100), tail)
+
+	items, err := cb.GetItemsByRange(100, 104)
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+
+	for i := 0; i < cb.Capacity(); i++ {
+		item, err := cb.AddItem(event{value: i})
+		assert.Nil(t, err)
+		assert.Equal(t, 100+i, int(item.SequenceID))
+	}
+	_, err = cb.AddItem(event{value: -1})
+	assert.NotNil(t, err)
+
+	removedItems, err := cb.MoveTail(102)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(removedItems))
+	assert.Equal(t, 100, int(removedItems[0].SequenceID))
+}
+
 // Continuesly adding a lot of events into the circular buffer, also trimming data from it
 // Make sure that all events can be read correctly even through the buffer has been roll over
 // many times
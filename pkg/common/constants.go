@@ -121,12 +121,26 @@ const (
 	SystemLabelResourcePool = "resource_pool"
 	// SystemLabelJobName is the system label key name for job name
 	SystemLabelJobName = "job_name"
+	// SystemLabelJobID is the system label key name for job ID
+	SystemLabelJobID = "job_id"
 	// SystemLabelJobOwner is the system label key name for job owner
 	SystemLabelJobOwner = "job_owner"
 	// SystemLabelJobType is the system label key name for job type
 	SystemLabelJobType = "job_type"
 	// SystemLabelCluster is the system label key name for cluster
 	SystemLabelCluster = "cluster"
+	// SystemLabelStableInstanceNames is the system label key name set
+	// for jobs whose instances have stable names
+	SystemLabelStableInstanceNames = "stable_instance_names"
+	// SystemLabelInstanceName is the system label key name for the stable
+	// name of a task instance
+	SystemLabelInstanceName = "instance_name"
+	// SystemLabelCronJob is the system label key name for the ID of the
+	// cron job which created a job
+	SystemLabelCronJob = "cron_job"
+	// SystemLabelLaunchCorrelationID is the system label key name for the
+	// launch correlation ID of a task
+	SystemLabelLaunchCorrelationID = "launch_correlation_id"
 	// ClusterEnvVar is the cluster environment variable
 	ClusterEnvVar = "CLUSTER"
 	// PelotonExclusiveAttributeName is the name of Mesos agent attribute
@@ -140,6 +154,11 @@ const (
 	PelotonAuroraBridgeExecutorIDPrefix = "thermos-"
 	// HostNameKey is the special label key for hostname.
 	HostNameKey = "hostname"
+	// RegionKey is the special label key for the fault domain region of
+	// a host.
+	RegionKey = "region"
+	// ZoneKey is the special label key for the fault domain zone of a host.
+	ZoneKey = "zone"
 
 	// DefaultTaskConfigID is used for storing, and retrieving, the default
 	// task configuration, when no specific is available.
@@ -157,6 +176,10 @@ const (
 	// HostPoolKey is the key of host pool constraint.
 	HostPoolKey = "host_pool"
 
+	// DiskTypeAttribute is the agent attribute with the type of the
+	// disks of the host, such as SSD or HDD.
+	DiskTypeAttribute = "disk_type"
+
 	// BatchReservedHostPoolID is reserved batch host pool for
 	// non-preemptible tasks
 	BatchReservedHostPoolID = "batch_reserved"
@@ -0,0 +1,244 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cron parses cron expressions and computes the times they match.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// _maxSearchYears bounds the search for the next matching time, so that
+// expressions which can never match (e.g. "0 0 30 2 *") terminate.
+const _maxSearchYears = 5
+
+// _descriptors maps the supported descriptors to their expression.
+var _descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the range of values of a cron field.
+type field struct {
+	name  string
+	min   uint
+	max   uint
+	names map[string]uint
+}
+
+var (
+	_minuteField = field{name: "minute", min: 0, max: 59}
+	_hourField   = field{name: "hour", min: 0, max: 23}
+	_domField    = field{name: "day of month", min: 1, max: 31}
+	_monthField  = field{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as Sunday as well, and folded onto 0 after parsing.
+	_dowField = field{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Schedule is a parsed cron expression. Times are matched in UTC with
+// a granularity of one minute.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+
+	// domStar and dowStar record whether the day of month and day of week
+	// fields are unrestricted. If both are restricted, a day matches if
+	// either of them matches, as in the standard cron.
+	domStar bool
+	dowStar bool
+}
+
+// Parse parses a standard five field cron expression (minute, hour, day
+// of month, month, day of week) or one of the descriptors @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly. Each field
+// is a comma separated list of *, values or ranges, each optionally
+// followed by a /step. Months and days of week may be given by their
+// three letter English names.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@") {
+		e, ok := _descriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, errors.Errorf("unknown cron descriptor %q", expr)
+		}
+		expr = e
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf(
+			"cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &Schedule{}
+	var err error
+	if s.minute, err = parseField(fields[0], _minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], _hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], _domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], _monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], _dowField); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = (s.dow | 1) &^ (1 << 7)
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// Next returns the first time strictly after t which matches the
+// schedule, in UTC. It returns the zero time if the schedule does not
+// match any time in the following years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + _maxSearchYears
+
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches returns whether the day of t matches the day of month and
+// day of week fields.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField parses a comma separated cron field into a bitset of the
+// values it matches.
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		b, err := parseRange(part, f)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseRange parses one element of a cron field, i.e. *, a value or
+// a range, optionally followed by a step.
+func parseRange(expr string, f field) (uint64, error) {
+	rangeExpr := expr
+	step := uint(1)
+	if i := strings.Index(expr, "/"); i >= 0 {
+		s, err := strconv.ParseUint(expr[i+1:], 10, 32)
+		if err != nil || s == 0 {
+			return 0, errors.Errorf("invalid step in %s field %q", f.name, expr)
+		}
+		step = uint(s)
+		rangeExpr = expr[:i]
+	}
+
+	var start, end uint
+	switch {
+	case rangeExpr == "*":
+		start, end = f.min, f.max
+	case strings.Contains(rangeExpr, "-"):
+		bounds := strings.SplitN(rangeExpr, "-", 2)
+		var err error
+		if start, err = parseValue(bounds[0], f); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(bounds[1], f); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, errors.Errorf("invalid range in %s field %q", f.name, expr)
+		}
+	default:
+		var err error
+		if start, err = parseValue(rangeExpr, f); err != nil {
+			return 0, err
+		}
+		end = start
+		// "N/step" is a shorthand for "N-max/step".
+		if strings.Contains(expr, "/") {
+			end = f.max
+		}
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << v
+	}
+	return bits, nil
+}
+
+// parseValue parses a single value of a cron field, given as a number
+// or a name.
+func parseValue(expr string, f field) (uint, error) {
+	if v, ok := f.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	v, err := strconv.ParseUint(expr, 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid value in %s field %q", f.name, expr)
+	}
+	if uint(v) < f.min || uint(v) > f.max {
+		return 0, errors.Errorf(
+			"%s value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+	return uint(v), nil
+}
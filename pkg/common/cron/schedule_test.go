@@ -0,0 +1,98 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	v, err := time.Parse(time.RFC3339, s)
+	assert.NoError(t, err)
+	return v
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	}
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	nextTests := []struct {
+		expr string
+		from string
+		next string
+	}{
+		{"* * * * *", "2019-01-01T10:00:30Z", "2019-01-01T10:01:00Z"},
+		{"*/15 * * * *", "2019-01-01T10:00:00Z", "2019-01-01T10:15:00Z"},
+		{"*/15 * * * *", "2019-01-01T10:50:00Z", "2019-01-01T11:00:00Z"},
+		{"5/20 * * * *", "2019-01-01T10:30:00Z", "2019-01-01T10:45:00Z"},
+		{"0,30 9-17 * * *", "2019-01-01T17:30:00Z", "2019-01-02T09:00:00Z"},
+		{"0 0 * * MON-FRI", "2019-01-04T12:00:00Z", "2019-01-07T00:00:00Z"},
+		{"0 0 * * 7", "2019-01-01T00:00:00Z", "2019-01-06T00:00:00Z"},
+		{"0 0 31 * *", "2019-02-01T00:00:00Z", "2019-03-31T00:00:00Z"},
+		{"0 0 29 feb *", "2019-01-01T00:00:00Z", "2020-02-29T00:00:00Z"},
+		// Day of month and day of week match either way when both are set.
+		{"0 0 15 * SUN", "2019-01-01T00:00:00Z", "2019-01-06T00:00:00Z"},
+		{"0 0 15 * SUN", "2019-01-13T12:00:00Z", "2019-01-15T00:00:00Z"},
+		{"@hourly", "2019-01-01T10:00:00Z", "2019-01-01T11:00:00Z"},
+		{"@daily", "2019-01-01T10:00:00Z", "2019-01-02T00:00:00Z"},
+		{"@weekly", "2019-01-01T10:00:00Z", "2019-01-06T00:00:00Z"},
+		{"@monthly", "2019-12-15T10:00:00Z", "2020-01-01T00:00:00Z"},
+		{"@yearly", "2019-06-01T00:00:00Z", "2020-01-01T00:00:00Z"},
+	}
+	for _, tt := range nextTests {
+		s, err := Parse(tt.expr)
+		assert.NoError(t, err, tt.expr)
+		assert.Equal(t,
+			mustTime(t, tt.next),
+			s.Next(mustTime(t, tt.from)),
+			tt.expr)
+	}
+}
+
+func TestScheduleNextNeverMatches(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(mustTime(t, "2019-01-01T00:00:00Z")).IsZero())
+}
+
+func TestScheduleNextConvertsToUTC(t *testing.T) {
+	s, err := Parse("0 12 * * *")
+	assert.NoError(t, err)
+
+	from := time.Date(2019, 1, 1, 8, 0, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	assert.Equal(t, mustTime(t, "2019-01-02T12:00:00Z"), s.Next(from))
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventstream
+
+import (
+	"context"
+
+	"github.com/uber/peloton/pkg/storage/objects"
+)
+
+// CursorStore persists the purge offset of each client of an event
+// stream, which is the cursor up to which the client consumed the stream.
+type CursorStore interface {
+	// GetCursors returns the cursor persisted last for each client of the
+	// stream, keyed by client name.
+	GetCursors(ctx context.Context) (map[string]uint64, error)
+
+	// SetCursor persists the cursor of a client of the stream.
+	SetCursor(ctx context.Context, clientName string, purgeOffset uint64) error
+}
+
+// cursorStore implements CursorStore with the event_stream_cursors table.
+type cursorStore struct {
+	ops        objects.EventStreamCursorOps
+	streamName string
+}
+
+// NewCursorStore returns a CursorStore persisting the cursors of the
+// clients of the named stream in the event_stream_cursors table.
+func NewCursorStore(
+	ops objects.EventStreamCursorOps,
+	streamName string,
+) CursorStore {
+	return &cursorStore{
+		ops:        ops,
+		streamName: streamName,
+	}
+}
+
+// GetCursors returns the cursor of each client of the stream.
+func (s *cursorStore) GetCursors(ctx context.Context) (map[string]uint64, error) {
+	return s.ops.GetAll(ctx, s.streamName)
+}
+
+// SetCursor persists the cursor of a client of the stream.
+func (s *cursorStore) SetCursor(
+	ctx context.Context,
+	clientName string,
+	purgeOffset uint64,
+) error {
+	return s.ops.Create(ctx, s.streamName, clientName, purgeOffset)
+}
@@ -50,6 +50,9 @@ type Handler struct {
 	//  Tracks the purge offset per client
 	clientPurgeOffsets    map[string]uint64
 	purgedEventsProcessor PurgedEventsProcessor
+	// persists the purge offsets of the clients, nil if they are only
+	// kept in memory
+	cursorStore CursorStore
 
 	metrics *HandlerMetrics
 }
@@ -76,6 +79,72 @@ func NewEventStreamHandler(
 	return &handler
 }
 
+// SetCursorStore restores the purge offsets of the clients persisted in
+// the store, and persists them in it from now on. The offsets of the
+// events added to the stream continue past the ones handed out before the
+// handler restarted, so that the progress tracked by the clients is never
+// ahead of the stream, which would purge events the clients have not
+// processed yet. It must be called before any event is added.
+func (h *Handler) SetCursorStore(ctx context.Context, store CursorStore) error {
+	cursors, err := store.GetCursors(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the cursors of the stream")
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if head, _ := h.circularBuffer.GetRange(); head != 0 {
+		return errors.New("events were added before setting the cursor store")
+	}
+
+	var maxCursor uint64
+	for _, cursor := range cursors {
+		if cursor > maxCursor {
+			maxCursor = cursor
+		}
+	}
+	if maxCursor > 0 {
+		// The head of the stream is at most its capacity past the tail,
+		// which is at most the largest purge offset of the clients.
+		capacity := h.circularBuffer.Capacity()
+		start := maxCursor + uint64(capacity)
+		h.circularBuffer = cirbuf.NewCircularBufferAt(capacity, start)
+		for client := range h.clientPurgeOffsets {
+			h.clientPurgeOffsets[client] = start
+		}
+		// Clients which initialized the stream already have to do it
+		// again to move to its new offsets.
+		h.streamID = uuid.New()
+		h.metrics.Head.Update(float64(start))
+		h.metrics.Tail.Update(float64(start))
+		log.WithFields(log.Fields{
+			"cursors":      cursors,
+			"start_offset": start,
+		}).Info("Restored event stream cursors")
+	}
+	h.cursorStore = store
+	return nil
+}
+
+// persistCursor persists the purge offset of the client in the cursor
+// store, if any.
+func (h *Handler) persistCursor(
+	ctx context.Context,
+	clientName string,
+	purgeOffset uint64,
+) {
+	if err := h.cursorStore.SetCursor(ctx, clientName, purgeOffset); err != nil {
+		log.WithError(err).
+			WithField("client_name", clientName).
+			WithField("purge_offset", purgeOffset).
+			Warn("Failed to persist event stream cursor")
+		h.metrics.PersistCursorFail.Inc(1)
+		return
+	}
+	h.metrics.PersistCursor.Inc(1)
+}
+
 // Check if the client is expected
 func (h *Handler) isClientExpected(clientName string) bool {
 	for _, ok := h.clientPurgeOffsets[clientName]; ok; {
@@ -189,6 +258,15 @@ func (h *Handler) WaitForEvents(
 	ctx context.Context,
 	req *pb_eventstream.WaitForEventsRequest) (*pb_eventstream.WaitForEventsResponse, error) {
 
+	// The purge offset of the client is persisted once the lock is
+	// released, as it is deferred first.
+	persistCursor := false
+	defer func() {
+		if persistCursor {
+			h.persistCursor(ctx, req.ClientName, req.PurgeOffset)
+		}
+	}()
+
 	h.Lock()
 	defer h.Unlock()
 	h.metrics.WaitForEventsAPI.Inc(1)
@@ -265,6 +343,8 @@ func (h *Handler) WaitForEvents(
 			},
 		}
 	}
+	persistCursor = h.cursorStore != nil &&
+		h.clientPurgeOffsets[clientName] != req.PurgeOffset
 	h.purgeEvents(clientName, req.PurgeOffset)
 	return &response, nil
 }
@@ -430,3 +430,72 @@ func TestWaitForEventHostEvent(t *testing.T) {
 		assert.Equal(t, pb_eventstream.Event_HOST_EVENT, events[i].GetType())
 	}
 }
+
+// memoryCursorStore is a CursorStore keeping the cursors in memory
+type memoryCursorStore struct {
+	sync.Mutex
+	cursors map[string]uint64
+}
+
+func (s *memoryCursorStore) GetCursors(ctx context.Context) (map[string]uint64, error) {
+	s.Lock()
+	defer s.Unlock()
+	cursors := make(map[string]uint64)
+	for client, cursor := range s.cursors {
+		cursors[client] = cursor
+	}
+	return cursors, nil
+}
+
+func (s *memoryCursorStore) SetCursor(
+	ctx context.Context,
+	clientName string,
+	purgeOffset uint64) error {
+	s.Lock()
+	defer s.Unlock()
+	s.cursors[clientName] = purgeOffset
+	return nil
+}
+
+// TestCursorStore tests that the purge offsets of the clients are
+// persisted, and that the offsets of a restarted stream continue past
+// the ones handed out before.
+func TestCursorStore(t *testing.T) {
+	bufferSize := 10
+	store := &memoryCursorStore{cursors: make(map[string]uint64)}
+	clients := []string{"jobMgr", "resMgr"}
+	ctx := context.Background()
+
+	handler := NewEventStreamHandler(bufferSize, clients, nil, tally.NoopScope)
+	assert.NoError(t, handler.SetCursorStore(ctx, store))
+	for i := 0; i < bufferSize; i++ {
+		assert.NoError(t, handler.AddEvent(makeEvent("", "")))
+	}
+	// The cursor store cannot be set once events were added.
+	assert.Error(t, handler.SetCursorStore(ctx, store))
+
+	handler.WaitForEvents(ctx, makeWaitForEventsRequest(
+		"jobMgr", handler.streamID, 8, 10, 8))
+	handler.WaitForEvents(ctx, makeWaitForEventsRequest(
+		"resMgr", handler.streamID, 5, 10, 5))
+	assert.Equal(t, map[string]uint64{"jobMgr": 8, "resMgr": 5}, store.cursors)
+
+	// The restarted stream starts past every offset handed out before.
+	restarted := NewEventStreamHandler(bufferSize, clients, nil, tally.NoopScope)
+	streamID := restarted.streamID
+	assert.NoError(t, restarted.SetCursorStore(ctx, store))
+	assert.NotEqual(t, streamID, restarted.streamID)
+	response, err := restarted.InitStream(ctx, makeInitStreamRequest("resMgr"))
+	assert.NoError(t, err)
+	assert.Nil(t, response.Error)
+	assert.Equal(t, uint64(18), response.MinOffset)
+	assert.Equal(t, uint64(18), response.PreviousPurgeOffset)
+
+	assert.NoError(t, restarted.AddEvent(makeEvent("", "")))
+	waitResponse, err := restarted.WaitForEvents(ctx, makeWaitForEventsRequest(
+		"resMgr", restarted.streamID, 18, 10, 18))
+	assert.NoError(t, err)
+	assert.Nil(t, waitResponse.Error)
+	assert.Len(t, waitResponse.Events, 1)
+	assert.Equal(t, uint64(18), waitResponse.Events[0].Offset)
+}
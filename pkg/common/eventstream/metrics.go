@@ -27,6 +27,9 @@ type HandlerMetrics struct {
 	PurgeEventError       tally.Counter
 	InvalidStreamIDError  tally.Counter
 
+	PersistCursor     tally.Counter
+	PersistCursorFail tally.Counter
+
 	AddEventAPI          tally.Counter
 	AddEventSuccess      tally.Counter
 	AddEventFail         tally.Counter
@@ -62,6 +65,8 @@ func NewHandlerMetrics(scope tally.Scope) *HandlerMetrics {
 		WaitForEventsAPI:      handlerAPIScope.Counter("waitForEvents"),
 		WaitForEventsSuccess:  handlerSuccessScope.Counter("waitForEvents"),
 		WaitForEventsFailed:   handlerFailScope.Counter("waitForEvents"),
+		PersistCursor:         handlerSuccessScope.Counter("persistCursor"),
+		PersistCursorFail:     handlerFailScope.Counter("persistCursor"),
 	}
 }
 
@@ -0,0 +1,79 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"time"
+
+	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+)
+
+// ensure that remoteOps satisfies the ClusterFreezeOps interface
+var _ ormobjects.ClusterFreezeOps = (*remoteOps)(nil)
+
+// remoteOps implements ClusterFreezeOps through the admin service of job
+// manager, for the daemons which do not access storage.
+type remoteOps struct {
+	client adminsvc.AdminServiceYARPCClient
+}
+
+// NewRemoteOps returns a ClusterFreezeOps which reads and sets the
+// freeze state of the cluster through the admin service of job manager.
+func NewRemoteOps(client adminsvc.AdminServiceYARPCClient) ormobjects.ClusterFreezeOps {
+	return &remoteOps{client: client}
+}
+
+// Get returns the freeze state of the cluster.
+func (r *remoteOps) Get(ctx context.Context) (*ormobjects.ClusterFreezeObject, error) {
+	resp, err := r.client.GetFreezeStatus(ctx, &adminsvc.GetFreezeStatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := resp.GetStatus()
+	obj := &ormobjects.ClusterFreezeObject{
+		Frozen: status.GetFrozen(),
+		Reason: status.GetReason(),
+		Owner:  status.GetOwner(),
+	}
+	if len(status.GetUpdateTime()) != 0 {
+		// the update time is informational, it is left unset if it
+		// cannot be parsed
+		obj.UpdateTime, _ = time.Parse(time.RFC3339, status.GetUpdateTime())
+	}
+	return obj, nil
+}
+
+// Set sets the freeze state of the cluster.
+func (r *remoteOps) Set(
+	ctx context.Context,
+	frozen bool,
+	reason string,
+	owner string,
+) error {
+	if frozen {
+		_, err := r.client.Freeze(ctx, &adminsvc.FreezeRequest{
+			Reason: reason,
+			Owner:  owner,
+		})
+		return err
+	}
+
+	_, err := r.client.Unfreeze(ctx, &adminsvc.UnfreezeRequest{Owner: owner})
+	return err
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	adminmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRemoteOpsGet tests reading the freeze state through the admin
+// service of job manager
+func TestRemoteOpsGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := adminmocks.NewMockAdminServiceYARPCClient(ctrl)
+	ops := NewRemoteOps(client)
+
+	updateTime := time.Now().UTC().Truncate(time.Second)
+	client.EXPECT().
+		GetFreezeStatus(gomock.Any(), &adminsvc.GetFreezeStatusRequest{}).
+		Return(&adminsvc.GetFreezeStatusResponse{
+			Status: &adminsvc.FreezeStatus{
+				Frozen:     true,
+				Reason:     "incident",
+				Owner:      "oncall",
+				UpdateTime: updateTime.Format(time.RFC3339),
+			},
+		}, nil)
+
+	status, err := ops.Get(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Frozen)
+	assert.Equal(t, "incident", status.Reason)
+	assert.Equal(t, "oncall", status.Owner)
+	assert.True(t, updateTime.Equal(status.UpdateTime))
+
+	client.EXPECT().
+		GetFreezeStatus(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	_, err = ops.Get(context.Background())
+	assert.Error(t, err)
+}
+
+// TestRemoteOpsSet tests setting the freeze state through the admin
+// service of job manager
+func TestRemoteOpsSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := adminmocks.NewMockAdminServiceYARPCClient(ctrl)
+	ops := NewRemoteOps(client)
+
+	client.EXPECT().
+		Freeze(gomock.Any(), &adminsvc.FreezeRequest{
+			Reason: "incident",
+			Owner:  "oncall",
+		}).
+		Return(&adminsvc.FreezeResponse{}, nil)
+	assert.NoError(t, ops.Set(context.Background(), true, "incident", "oncall"))
+
+	client.EXPECT().
+		Unfreeze(gomock.Any(), &adminsvc.UnfreezeRequest{Owner: "oncall"}).
+		Return(nil, errors.New("test error"))
+	assert.Error(t, ops.Set(context.Background(), false, "", "oncall"))
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// _defaultRefreshInterval is the default interval at which the
+	// freeze state is read from storage
+	_defaultRefreshInterval = 30 * time.Second
+
+	// _refreshTimeout is the timeout to read the freeze state from storage
+	_refreshTimeout = 10 * time.Second
+)
+
+// Config for tracking the freeze state of the cluster
+type Config struct {
+	// RefreshInterval is the interval at which the freeze state
+	// is read from storage
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+func (c *Config) normalize() {
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = _defaultRefreshInterval
+	}
+}
+
+// State provides the freeze state of the cluster. While the cluster is
+// frozen, non-essential automatic actions like task restarts, updates,
+// preemptions and SLA enforcement are halted, while state is still
+// being tracked.
+type State interface {
+	// IsFrozen returns true if the cluster is frozen
+	IsFrozen() bool
+}
+
+// Tracker tracks the freeze state of the cluster, which is persisted
+// in storage so that it is shared by all the daemons and survives
+// restarts. The freeze state is lifted only explicitly.
+type Tracker struct {
+	ops       ormobjects.ClusterFreezeOps
+	config    Config
+	frozen    atomic.Bool
+	metrics   *Metrics
+	lifeCycle lifecycle.LifeCycle
+}
+
+// NewTracker creates a Tracker of the freeze state of the cluster
+func NewTracker(
+	ops ormobjects.ClusterFreezeOps,
+	config Config,
+	parent tally.Scope,
+) *Tracker {
+	config.normalize()
+	return &Tracker{
+		ops:       ops,
+		config:    config,
+		metrics:   NewMetrics(parent.SubScope("freeze")),
+		lifeCycle: lifecycle.NewLifeCycle(),
+	}
+}
+
+// IsFrozen returns true if the cluster is frozen
+func (t *Tracker) IsFrozen() bool {
+	if t == nil {
+		return false
+	}
+	return t.frozen.Load()
+}
+
+// Start reads the freeze state from storage, and starts refreshing it
+// periodically
+func (t *Tracker) Start() {
+	if !t.lifeCycle.Start() {
+		return
+	}
+
+	t.refresh()
+
+	go func() {
+		defer t.lifeCycle.StopComplete()
+
+		ticker := time.NewTicker(t.config.RefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-t.lifeCycle.StopCh():
+				return
+			case <-ticker.C:
+				t.refresh()
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing the freeze state
+func (t *Tracker) Stop() {
+	if !t.lifeCycle.Stop() {
+		return
+	}
+	t.lifeCycle.Wait()
+}
+
+// refresh reads the freeze state from storage. The last known freeze
+// state is kept if it cannot be read.
+func (t *Tracker) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), _refreshTimeout)
+	defer cancel()
+
+	status, err := t.ops.Get(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to read cluster freeze state")
+		t.metrics.RefreshFail.Inc(1)
+		return
+	}
+	t.metrics.Refresh.Inc(1)
+	t.setFrozen(status.Frozen)
+}
+
+// setFrozen updates the freeze state in memory
+func (t *Tracker) setFrozen(frozen bool) {
+	if t.frozen.Swap(frozen) != frozen {
+		log.WithField("frozen", frozen).Info("cluster freeze state changed")
+	}
+	if frozen {
+		t.metrics.Frozen.Update(1)
+	} else {
+		t.metrics.Frozen.Update(0)
+	}
+}
+
+// Freeze freezes the cluster and persists the freeze state
+func (t *Tracker) Freeze(ctx context.Context, reason string, owner string) error {
+	if err := t.ops.Set(ctx, true, reason, owner); err != nil {
+		return err
+	}
+	t.setFrozen(true)
+	return nil
+}
+
+// Unfreeze lifts the freeze on the cluster and persists the freeze state
+func (t *Tracker) Unfreeze(ctx context.Context, owner string) error {
+	if err := t.ops.Set(ctx, false, "", owner); err != nil {
+		return err
+	}
+	t.setFrozen(false)
+	return nil
+}
+
+// Status reads the freeze state of the cluster from storage
+func (t *Tracker) Status(ctx context.Context) (*ormobjects.ClusterFreezeObject, error) {
+	status, err := t.ops.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.setFrozen(status.Frozen)
+	return status, nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package freeze
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type TrackerTestSuite struct {
+	suite.Suite
+
+	ctrl    *gomock.Controller
+	ops     *objectmocks.MockClusterFreezeOps
+	scope   tally.TestScope
+	tracker *Tracker
+}
+
+func TestTracker(t *testing.T) {
+	suite.Run(t, new(TrackerTestSuite))
+}
+
+func (suite *TrackerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.ops = objectmocks.NewMockClusterFreezeOps(suite.ctrl)
+	suite.scope = tally.NewTestScope("", nil)
+	suite.tracker = NewTracker(suite.ops, Config{}, suite.scope)
+}
+
+func (suite *TrackerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// TestNilTracker tests that a nil tracker is never frozen
+func (suite *TrackerTestSuite) TestNilTracker() {
+	var tracker *Tracker
+	suite.False(tracker.IsFrozen())
+}
+
+// TestStartReadsFreezeState tests that the persisted freeze state is
+// read when the tracker starts
+func (suite *TrackerTestSuite) TestStartReadsFreezeState() {
+	suite.ops.EXPECT().
+		Get(gomock.Any()).
+		Return(&ormobjects.ClusterFreezeObject{Frozen: true}, nil)
+
+	suite.tracker.Start()
+	defer suite.tracker.Stop()
+
+	suite.True(suite.tracker.IsFrozen())
+	suite.Equal(
+		float64(1),
+		suite.scope.Snapshot().Gauges()["freeze.frozen+"].Value())
+}
+
+// TestRefreshFailKeepsFreezeState tests that the last known freeze
+// state is kept when it cannot be read from storage
+func (suite *TrackerTestSuite) TestRefreshFailKeepsFreezeState() {
+	suite.tracker.setFrozen(true)
+	suite.ops.EXPECT().
+		Get(gomock.Any()).
+		Return(nil, errors.New("get failed"))
+
+	suite.tracker.refresh()
+	suite.True(suite.tracker.IsFrozen())
+}
+
+// TestFreezeUnfreeze tests freezing the cluster and lifting the freeze
+func (suite *TrackerTestSuite) TestFreezeUnfreeze() {
+	suite.ops.EXPECT().
+		Set(gomock.Any(), true, "incident", "operator").
+		Return(nil)
+	suite.NoError(
+		suite.tracker.Freeze(context.Background(), "incident", "operator"))
+	suite.True(suite.tracker.IsFrozen())
+
+	suite.ops.EXPECT().
+		Set(gomock.Any(), false, "", "operator").
+		Return(nil)
+	suite.NoError(suite.tracker.Unfreeze(context.Background(), "operator"))
+	suite.False(suite.tracker.IsFrozen())
+}
+
+// TestFreezeFail tests that the freeze state does not change when it
+// cannot be persisted
+func (suite *TrackerTestSuite) TestFreezeFail() {
+	suite.ops.EXPECT().
+		Set(gomock.Any(), true, "incident", "operator").
+		Return(errors.New("set failed"))
+	suite.Error(
+		suite.tracker.Freeze(context.Background(), "incident", "operator"))
+	suite.False(suite.tracker.IsFrozen())
+}
+
+// TestStatus tests reading the freeze state from storage
+func (suite *TrackerTestSuite) TestStatus() {
+	suite.ops.EXPECT().
+		Get(gomock.Any()).
+		Return(&ormobjects.ClusterFreezeObject{
+			Frozen: true,
+			Reason: "incident",
+		}, nil)
+
+	status, err := suite.tracker.Status(context.Background())
+	suite.NoError(err)
+	suite.Equal("incident", status.Reason)
+	suite.True(suite.tracker.IsFrozen())
+}
@@ -24,5 +24,9 @@ an entity with a deadline of when it is should be dequeued for evaluation.
 When its deadline expires, the action list corresponding to its state and
 goal state are executed in order. If any of the actions return an error on
 execution, the entity is requeued for evaluation with an exponential backoff.
+The engine tracks the entities which keep being evaluated without converging
+to their goal state, or whose actions keep failing. A Watchdog periodically
+alerts on such stuck entities, and applies remediations to them, like
+dead-lettering the entity so that it is not evaluated till its state changes.
 */
 package goalstate
@@ -16,6 +16,7 @@ package goalstate
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"time"
 
@@ -23,9 +24,20 @@ import (
 	queue "github.com/uber/peloton/pkg/common/deadline_queue"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
 	"github.com/uber-go/tally"
 )
 
+const (
+	// _prerequisiteWaitDelay is the delay after which a dependent entity,
+	// deferred because one of its prerequisites is pending, is evaluated.
+	_prerequisiteWaitDelay = 10 * time.Millisecond
+	// _maxPrerequisiteWaits is the maximum number of times in a row the
+	// evaluation of a dependent entity is deferred, so that a prerequisite
+	// which keeps getting rescheduled does not block it.
+	_maxPrerequisiteWaits = 10
+)
+
 // asyncWorkerQueueItem implements the async.Job interface while
 // storing the metadata for async.Queue which includes information
 // to be provided to the deadline queue on Enqueue
@@ -116,6 +128,22 @@ type Engine interface {
 	// IsScheduled is used to determine if a given entity is queued in
 	// the deadline queue for evaluation
 	IsScheduled(entity Entity) bool
+	// IsPending is used to determine if a given entity is being
+	// evaluated, or is queued with a deadline which has expired.
+	IsPending(entity Entity) bool
+	// GetStuckEntities returns the entities which have been evaluated
+	// without converging to their goal state, or whose actions have kept
+	// failing, for longer than the given duration, along with the
+	// dead-lettered entities.
+	GetStuckEntities(threshold time.Duration) []StuckEntity
+	// DeadLetter stops the evaluation of an entity which is stuck, until
+	// its state changes or it is deleted.
+	DeadLetter(entity Entity)
+	// Remediate schedules a remediation of a stuck entity, and enqueues
+	// the entity for evaluation now. The remediation is run by the workers
+	// of the engine at the next evaluation of the entity, before its
+	// actions, so that it does not race with them.
+	Remediate(entity Entity, remediation Action)
 	// Delete is used clean up the state created in the goal state
 	// engine for the entity. It is the caller's responsibility to
 	// explicitly call delete when an entity is being removed from the system.
@@ -158,6 +186,39 @@ type entityMapItem struct {
 	// delay is used by goal state to track expoenential backoff of scheduling
 	// duration in case entity actions keep returning an error.
 	delay time.Duration
+
+	// running is set while the entity actions are being evaluated
+	running atomic.Bool
+	// prerequisiteWaits is the number of times in a row the evaluation
+	// of the entity has been deferred for its prerequisites
+	prerequisiteWaits atomic.Int32
+
+	// state is the state of the entity at its last evaluation
+	state interface{}
+	// unconvergedSince is the time since which the entity has been
+	// evaluated with actions to run while its state did not change.
+	// It is zero if the entity has converged.
+	unconvergedSince time.Time
+	// failures is the number of evaluations in a row whose actions
+	// failed, failingSince the time of the first of them, and lastError
+	// the error of the last one.
+	failures     int
+	failingSince time.Time
+	lastError    string
+	// deadLettered is set when the entity is dead-lettered, after which
+	// it is not evaluated till its state changes.
+	deadLettered bool
+	// remediations are the remediations scheduled to be run at the next
+	// evaluation of the entity.
+	remediations []Action
+}
+
+// resetFailures resets the tracking of the failed evaluations of the entity.
+// It should be called holding the entityItem lock.
+func (entityItem *entityMapItem) resetFailures() {
+	entityItem.failures = 0
+	entityItem.failingSince = time.Time{}
+	entityItem.lastError = ""
 }
 
 // engine implements the goal state engine interface
@@ -262,6 +323,86 @@ func (e *engine) IsScheduled(entity Entity) bool {
 	return entityItem.queueItem.IsScheduled()
 }
 
+func (e *engine) IsPending(entity Entity) bool {
+	id := entity.GetID()
+	entityItem := e.getItemFromEntityMap(id)
+	if entityItem == nil {
+		return false
+	}
+
+	if entityItem.running.Load() {
+		return true
+	}
+
+	deadline := entityItem.queueItem.Deadline()
+	return !deadline.IsZero() && !deadline.After(time.Now())
+}
+
+func (e *engine) GetStuckEntities(threshold time.Duration) []StuckEntity {
+	e.RLock()
+	entityItems := make([]*entityMapItem, 0, len(e.entityMap))
+	for _, entityItem := range e.entityMap {
+		entityItems = append(entityItems, entityItem)
+	}
+	e.RUnlock()
+
+	var stuckEntities []StuckEntity
+	now := time.Now()
+	for _, entityItem := range entityItems {
+		entityItem.RLock()
+		stuckSince := entityItem.unconvergedSince
+		if !entityItem.failingSince.IsZero() &&
+			(stuckSince.IsZero() || entityItem.failingSince.Before(stuckSince)) {
+			stuckSince = entityItem.failingSince
+		}
+		if entityItem.deadLettered ||
+			(!stuckSince.IsZero() && now.Sub(stuckSince) >= threshold) {
+			stuckEntities = append(stuckEntities, StuckEntity{
+				Entity:       entityItem.entity,
+				StuckSince:   stuckSince,
+				Failures:     entityItem.failures,
+				LastError:    entityItem.lastError,
+				DeadLettered: entityItem.deadLettered,
+			})
+		}
+		entityItem.RUnlock()
+	}
+	return stuckEntities
+}
+
+func (e *engine) DeadLetter(entity Entity) {
+	id := entity.GetID()
+	entityItem := e.getItemFromEntityMap(id)
+	if entityItem == nil {
+		return
+	}
+
+	entityItem.Lock()
+	defer entityItem.Unlock()
+
+	entityItem.deadLettered = true
+	entityItem.state = entityItem.entity.GetState()
+	log.WithField("entity_id", id).
+		Warn("goal state entity dead-lettered")
+}
+
+func (e *engine) Remediate(entity Entity, remediation Action) {
+	id := entity.GetID()
+	entityItem := e.getItemFromEntityMap(id)
+	if entityItem == nil {
+		return
+	}
+
+	entityItem.Lock()
+	entityItem.remediations = append(entityItem.remediations, remediation)
+	entityItem.Unlock()
+
+	e.pool.Enqueue(&asyncWorkerQueueItem{
+		item:     entityItem.queueItem,
+		deadline: time.Now(),
+	})
+}
+
 func (e *engine) Delete(entity Entity) {
 	id := entity.GetID()
 	e.deleteItemFromEntityMap(id)
@@ -285,15 +426,31 @@ func (e *engine) runActions(entityItem *entityMapItem) (reschedule bool, delay t
 	entityItem.Lock()
 	defer entityItem.Unlock()
 
+	// Run the remediations first, so that the actions are computed from
+	// the remediated entity.
+	e.runRemediations(entityItem)
+
 	// Get the actions based on state and goal state of entity.
 	state := entityItem.entity.GetState()
 	goalState := entityItem.entity.GetGoalState()
+
+	// A dead-lettered entity is evaluated again only once its state changes.
+	if entityItem.deadLettered {
+		if reflect.DeepEqual(state, entityItem.state) {
+			e.mtx.deadLetterSkips.Inc(1)
+			return false, 0
+		}
+		entityItem.deadLettered = false
+	}
+
 	ctx, cancel, actions := entityItem.entity.GetActionList(state, goalState)
 	if cancel != nil {
 		defer cancel()
 	}
 
+	e.trackConvergence(entityItem, state, goalState, len(actions) != 0)
 	if len(actions) == 0 {
+		entityItem.resetFailures()
 		return false, 0
 	}
 
@@ -310,6 +467,11 @@ func (e *engine) runActions(entityItem *entityMapItem) (reschedule bool, delay t
 					"action_name": action.Name,
 				}).
 				Info("goal state action failed to execute")
+			if entityItem.failures == 0 {
+				entityItem.failingSince = time.Now()
+			}
+			entityItem.failures++
+			entityItem.lastError = err.Error()
 			// Backoff and reevaluate the entity again.
 			e.calculateDelay(entityItem)
 			return true, entityItem.delay
@@ -317,9 +479,68 @@ func (e *engine) runActions(entityItem *entityMapItem) (reschedule bool, delay t
 		// set delay to 0
 		entityItem.delay = 0
 	}
+	entityItem.resetFailures()
 	return false, 0
 }
 
+// runRemediations runs the remediations scheduled for an entity, each
+// with its own timeout.
+// It should be called holding the entityItem lock.
+func (e *engine) runRemediations(entityItem *entityMapItem) {
+	remediations := entityItem.remediations
+	entityItem.remediations = nil
+
+	for _, remediation := range remediations {
+		logFields := log.Fields{
+			"entity_id":   entityItem.entity.GetID(),
+			"remediation": remediation.Name,
+		}
+		scope := e.mtx.scope.Tagged(
+			map[string]string{"remediation": remediation.Name})
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), _remediationTimeout)
+		err := remediation.Execute(ctx, entityItem.entity)
+		cancel()
+		if err != nil {
+			scope.Counter("remediation_fail").Inc(1)
+			log.WithFields(logFields).
+				WithError(err).
+				Error("failed to remediate stuck goal state entity")
+			continue
+		}
+		scope.Counter("remediation").Inc(1)
+		log.WithFields(logFields).
+			Info("remediated stuck goal state entity")
+	}
+}
+
+// trackConvergence tracks the time since which an entity has been evaluated
+// with actions to run without its state changing. An entity with no actions
+// to run, or which reports itself to have converged, has converged.
+// It should be called holding the entityItem lock.
+func (e *engine) trackConvergence(
+	entityItem *entityMapItem,
+	state interface{},
+	goalState interface{},
+	hasActions bool,
+) {
+	converged := !hasActions
+	if convergent, ok := entityItem.entity.(ConvergentEntity); ok &&
+		convergent.IsConverged(state, goalState) {
+		converged = true
+	}
+
+	switch {
+	case converged:
+		entityItem.unconvergedSince = time.Time{}
+	case entityItem.unconvergedSince.IsZero(),
+		!reflect.DeepEqual(state, entityItem.state):
+		entityItem.unconvergedSince = time.Now()
+	}
+	entityItem.state = state
+}
+
 // processEntityAfterDequeue is a helper function to evaluate
 // an entity dequeued from the deadline queue, and execute the
 // corresponding actions.
@@ -338,7 +559,19 @@ func (e *engine) processEntityAfterDequeue(queueItem *queue.Item) {
 		return
 	}
 
+	if e.waitForPrerequisites(entityItem) {
+		e.mtx.prerequisiteWaits.Inc(1)
+		asyncQueueItem := &asyncWorkerQueueItem{
+			item:     queueItem,
+			deadline: time.Now().Add(_prerequisiteWaitDelay),
+		}
+		e.pool.Enqueue(asyncQueueItem)
+		return
+	}
+
+	entityItem.running.Store(true)
 	reschedule, delay := e.runActions(entityItem)
+	entityItem.running.Store(false)
 	if reschedule == true {
 		asyncQueueItem := &asyncWorkerQueueItem{
 			item:     queueItem,
@@ -348,6 +581,27 @@ func (e *engine) processEntityAfterDequeue(queueItem *queue.Item) {
 	}
 }
 
+// waitForPrerequisites returns true if the evaluation of a dependent entity
+// should be deferred because one of its prerequisites is pending.
+func (e *engine) waitForPrerequisites(entityItem *entityMapItem) bool {
+	dependent, ok := entityItem.entity.(DependentEntity)
+	if !ok {
+		return false
+	}
+
+	if entityItem.prerequisiteWaits.Load() < _maxPrerequisiteWaits {
+		for _, p := range dependent.GetPrerequisites() {
+			if p.Engine != nil && p.Engine.IsPending(p.Entity) {
+				entityItem.prerequisiteWaits.Inc()
+				return true
+			}
+		}
+	}
+
+	entityItem.prerequisiteWaits.Store(0)
+	return false
+}
+
 func (e *engine) Start() {
 	e.Lock()
 	defer e.Unlock()
@@ -371,3 +371,267 @@ func TestMultiRequeue(t *testing.T) {
 	e.pool.Stop()
 	assert.Equal(t, count, len(idList))
 }
+
+// Test implementation of DependentEntity
+type testDependentEntity struct {
+	*testEntity
+	prerequisites []Prerequisite
+}
+
+func (te *testDependentEntity) GetPrerequisites() []Prerequisite {
+	return te.prerequisites
+}
+
+// TestEngineIsPending tests determining if an entity is being evaluated
+// or is due for evaluation.
+func TestEngineIsPending(t *testing.T) {
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope).(*engine)
+
+	ent := newTestEntity("0", stateValue, goalStateValue)
+	assert.False(t, e.IsPending(ent))
+
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	assert.True(t, e.IsScheduled(ent))
+	assert.False(t, e.IsPending(ent))
+
+	e.Enqueue(ent, time.Now().Add(-time.Second))
+	assert.True(t, e.IsPending(ent))
+
+	entityItem := e.getItemFromEntityMap(ent.GetID())
+	entityItem.queueItem.SetDeadline(time.Time{})
+	assert.False(t, e.IsPending(ent))
+	entityItem.running.Store(true)
+	assert.True(t, e.IsPending(ent))
+}
+
+// TestEngineWaitForPrerequisites tests deferring the evaluation of a
+// dependent entity while its prerequisite, tracked by another
+// engine, is pending.
+func TestEngineWaitForPrerequisites(t *testing.T) {
+	prerequisiteEngine := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope)
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope).(*engine)
+
+	prerequisite := newTestEntity("job", stateValue, goalStateValue)
+	dependent := &testDependentEntity{
+		testEntity: newTestEntity("job-0", stateValue, goalStateValue),
+		prerequisites: []Prerequisite{
+			{Engine: prerequisiteEngine, Entity: prerequisite},
+		},
+	}
+	e.Enqueue(dependent, time.Now().Add(time.Hour))
+	entityItem := e.getItemFromEntityMap(dependent.GetID())
+
+	// prerequisite is not tracked
+	assert.False(t, e.waitForPrerequisites(entityItem))
+
+	// prerequisite is due for evaluation
+	prerequisiteEngine.Enqueue(prerequisite, time.Now())
+	for i := 0; i < _maxPrerequisiteWaits; i++ {
+		assert.True(t, e.waitForPrerequisites(entityItem))
+	}
+	// the dependent entity is not deferred any longer
+	assert.False(t, e.waitForPrerequisites(entityItem))
+	assert.Equal(t, int32(0), entityItem.prerequisiteWaits.Load())
+	assert.True(t, e.waitForPrerequisites(entityItem))
+
+	// entities without prerequisites are never deferred
+	ent := newTestEntity("1", stateValue, goalStateValue)
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	assert.False(t, e.waitForPrerequisites(e.getItemFromEntityMap(ent.GetID())))
+}
+
+// Test implementation of an entity which keeps running an action, which
+// fails while err is set, till its state reaches its goal state
+type testStuckEntity struct {
+	*testEntity
+	err       error
+	runs      int
+	converged bool
+}
+
+func (te *testStuckEntity) GetActionList(state interface{}, goalstate interface{}) (context.Context, context.CancelFunc, []Action) {
+	if state == goalstate {
+		return context.Background(), nil, nil
+	}
+	return context.Background(), nil, []Action{{
+		Name: "testStuckAction",
+		Execute: func(ctx context.Context, entity Entity) error {
+			te.runs++
+			return te.err
+		},
+	}}
+}
+
+// Test implementation of ConvergentEntity
+type testConvergentEntity struct {
+	*testStuckEntity
+}
+
+func (te *testConvergentEntity) IsConverged(state interface{}, goalstate interface{}) bool {
+	return te.converged
+}
+
+// TestEngineGetStuckEntities tests tracking the entities which are not
+// converging to their goal state, or whose actions keep failing.
+func TestEngineGetStuckEntities(t *testing.T) {
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope).(*engine)
+
+	ent := &testStuckEntity{
+		testEntity: newTestEntity("0", stateValue, goalStateValue),
+	}
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	entityItem := e.getItemFromEntityMap(ent.GetID())
+
+	// the entity has actions to run, but is not stuck for long
+	e.runActions(entityItem)
+	assert.Empty(t, e.GetStuckEntities(time.Hour))
+	stuckEntities := e.GetStuckEntities(0)
+	assert.Len(t, stuckEntities, 1)
+	assert.Equal(t, ent, stuckEntities[0].Entity)
+	assert.Equal(t, 0, stuckEntities[0].Failures)
+
+	// the entity keeps not converging with the same state
+	entityItem.unconvergedSince = time.Now().Add(-2 * time.Hour)
+	e.runActions(entityItem)
+	stuckEntities = e.GetStuckEntities(time.Hour)
+	assert.Len(t, stuckEntities, 1)
+	assert.Equal(t, entityItem.unconvergedSince, stuckEntities[0].StuckSince)
+
+	// the actions of the entity fail
+	ent.err = fmt.Errorf("fake error")
+	e.runActions(entityItem)
+	e.runActions(entityItem)
+	stuckEntities = e.GetStuckEntities(time.Hour)
+	assert.Len(t, stuckEntities, 1)
+	assert.Equal(t, 2, stuckEntities[0].Failures)
+	assert.Equal(t, "fake error", stuckEntities[0].LastError)
+
+	// the state of the entity changes
+	ent.state = stateValueMulti
+	e.runActions(entityItem)
+	assert.Empty(t, e.GetStuckEntities(time.Hour))
+	assert.Equal(t, 3, e.GetStuckEntities(0)[0].Failures)
+
+	// the entity converges
+	ent.state = goalStateValue
+	e.runActions(entityItem)
+	assert.Empty(t, e.GetStuckEntities(0))
+
+	// an entity which reports itself converged is not stuck
+	convergent := &testConvergentEntity{
+		testStuckEntity: &testStuckEntity{
+			testEntity: newTestEntity("1", stateValue, goalStateValue),
+			converged:  true,
+		},
+	}
+	e.Enqueue(convergent, time.Now().Add(time.Hour))
+	e.runActions(e.getItemFromEntityMap(convergent.GetID()))
+	assert.Equal(t, 1, convergent.runs)
+	assert.Empty(t, e.GetStuckEntities(0))
+}
+
+// TestEngineDeadLetter tests that a dead-lettered entity is not evaluated
+// till its state changes.
+func TestEngineDeadLetter(t *testing.T) {
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope).(*engine)
+
+	ent := &testStuckEntity{
+		testEntity: newTestEntity("0", stateValue, goalStateValue),
+		err:        fmt.Errorf("fake error"),
+	}
+
+	// dead-lettering an entity not tracked is a no-op
+	e.DeadLetter(ent)
+
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	entityItem := e.getItemFromEntityMap(ent.GetID())
+	reschedule, _ := e.runActions(entityItem)
+	assert.True(t, reschedule)
+	assert.Equal(t, 1, ent.runs)
+
+	e.DeadLetter(ent)
+	reschedule, _ = e.runActions(entityItem)
+	assert.False(t, reschedule)
+	assert.Equal(t, 1, ent.runs)
+	stuckEntities := e.GetStuckEntities(time.Hour)
+	assert.Len(t, stuckEntities, 1)
+	assert.True(t, stuckEntities[0].DeadLettered)
+
+	// the entity is evaluated again once its state changes
+	ent.state = stateValueMulti
+	reschedule, _ = e.runActions(entityItem)
+	assert.True(t, reschedule)
+	assert.Equal(t, 2, ent.runs)
+	assert.Empty(t, e.GetStuckEntities(time.Hour))
+}
+
+// TestEngineRemediate tests that a remediation is run at the next
+// evaluation of the entity, before its actions.
+func TestEngineRemediate(t *testing.T) {
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope).(*engine)
+
+	ent := &testStuckEntity{
+		testEntity: newTestEntity("0", stateValue, goalStateValue),
+	}
+
+	// remediating an entity not tracked is a no-op
+	e.Remediate(ent, Action{Name: "noop"})
+
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	entityItem := e.getItemFromEntityMap(ent.GetID())
+	assert.False(t, e.IsPending(ent))
+
+	var remediations int
+	e.Remediate(ent, Action{
+		Name: "converge",
+		Execute: func(ctx context.Context, entity Entity) error {
+			remediations++
+			entity.(*testStuckEntity).state = goalStateValue
+			return nil
+		},
+	})
+	e.Remediate(ent, Action{
+		Name: "fail",
+		Execute: func(ctx context.Context, entity Entity) error {
+			return fmt.Errorf("fake error")
+		},
+	})
+	assert.True(t, e.IsPending(ent))
+	assert.Equal(t, 0, remediations)
+
+	// the actions are computed from the remediated entity, and the
+	// failure of a remediation does not fail the evaluation
+	reschedule, _ := e.runActions(entityItem)
+	assert.False(t, reschedule)
+	assert.Equal(t, 1, remediations)
+	assert.Equal(t, 0, ent.runs)
+	assert.Empty(t, entityItem.remediations)
+
+	// the remediations are run only once
+	e.runActions(entityItem)
+	assert.Equal(t, 1, remediations)
+}
@@ -26,6 +26,10 @@ type Metrics struct {
 	missingItems tally.Counter
 	// counter to track total items in the goal state engine
 	totalItems tally.Gauge
+	// counter to track evaluations deferred for pending prerequisites
+	prerequisiteWaits tally.Counter
+	// counter to track evaluations skipped for dead-lettered entities
+	deadLetterSkips tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct.
@@ -34,5 +38,27 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		scope:        scope,
 		missingItems: scope.Counter("missing_items"),
 		totalItems:   scope.Gauge("total_items"),
+
+		prerequisiteWaits: scope.Counter("prerequisite_waits"),
+		deadLetterSkips:   scope.Counter("dead_letter_skips"),
+	}
+}
+
+// watchdogMetrics contains the metrics of the watchdog of a goal state engine
+type watchdogMetrics struct {
+	// the metrics scope for the watchdog
+	scope tally.Scope
+	// gauge to track the number of stuck entities
+	stuckEntities tally.Gauge
+	// counter to track the entities found newly stuck
+	stuckAlerts tally.Counter
+}
+
+// newWatchdogMetrics returns a new watchdogMetrics struct.
+func newWatchdogMetrics(scope tally.Scope) *watchdogMetrics {
+	return &watchdogMetrics{
+		scope:         scope,
+		stuckEntities: scope.Gauge("stuck_entities"),
+		stuckAlerts:   scope.Counter("stuck_alerts"),
 	}
 }
@@ -16,6 +16,7 @@ package goalstate
 
 import (
 	"context"
+	"time"
 )
 
 // Entity defines the interface of an item which can queued into the goal state engine.
@@ -34,6 +35,36 @@ type Entity interface {
 		context.Context, context.CancelFunc, []Action)
 }
 
+// DependentEntity is implemented by entities whose actions should run
+// only after other entities, their prerequisites, have converged. It is
+// a scheduling hint: if a prerequisite is being evaluated or is due for
+// evaluation when the dependent entity is dequeued, the evaluation of the
+// dependent entity is deferred a little, a bounded number of times.
+type DependentEntity interface {
+	Entity
+	// GetPrerequisites fetches the prerequisites of the entity.
+	GetPrerequisites() []Prerequisite
+}
+
+// ConvergentEntity is implemented by entities which keep having actions to
+// run once they have reached their goal state, like periodic bookkeeping.
+// Other entities are deemed to have converged when they have no actions
+// to run.
+type ConvergentEntity interface {
+	Entity
+	// IsConverged returns true if the state of the entity has
+	// reached its goal state.
+	IsConverged(state interface{}, goalState interface{}) bool
+}
+
+// Prerequisite identifies an entity along with the goal state engine
+// it is tracked by, which may be different from the engine tracking
+// the dependent entity.
+type Prerequisite struct {
+	Engine Engine
+	Entity Entity
+}
+
 // ActionExecute defines the interface for the function to be used by the
 // goal state engine clients to implement the execution of an action.
 type ActionExecute func(ctx context.Context, entity Entity) error
@@ -48,3 +79,23 @@ type Action struct {
 	// engine to execute the action.
 	Execute ActionExecute
 }
+
+// StuckEntity describes an entity which has been evaluated without
+// converging to its goal state, or whose actions have kept failing,
+// for a long time.
+type StuckEntity struct {
+	// Entity is the stuck entity.
+	Entity Entity
+	// StuckSince is the time since which the entity is stuck.
+	StuckSince time.Time
+	// Failures is the number of evaluations in a row whose actions failed.
+	Failures int
+	// LastError is the error of the last failed evaluation.
+	LastError string
+	// DeadLettered is set if the entity is not evaluated anymore
+	// till its state changes.
+	DeadLettered bool
+	// Remediations are the names of the remediations applied to
+	// the entity by the watchdog.
+	Remediations []string
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// DeadLetterRemediation is the name of the remediation, provided by
+	// every watchdog, which dead-letters a stuck entity.
+	DeadLetterRemediation = "dead_letter"
+
+	// _remediationTimeout is the timeout to run a remediation.
+	_remediationTimeout = 60 * time.Second
+)
+
+// WatchdogConfig is the configuration of the watchdog of a goal state engine.
+type WatchdogConfig struct {
+	// CheckPeriod is the period at which the entities are checked.
+	// The watchdog is disabled if it is not positive.
+	CheckPeriod time.Duration `yaml:"check_period"`
+	// StuckThreshold is the duration after which an entity which is not
+	// converging to its goal state, or is failing, is considered stuck.
+	StuckThreshold time.Duration `yaml:"stuck_threshold"`
+	// Remediations are the names of the remediations applied in order to
+	// a stuck entity, one at each check, till it is not stuck anymore.
+	Remediations []string `yaml:"remediations"`
+}
+
+// Watchdog periodically checks a goal state engine for stuck entities,
+// alerts on them and applies remediations to them.
+type Watchdog interface {
+	// Start starts checking the entities periodically.
+	Start()
+	// Stop stops checking the entities.
+	Stop()
+	// GetStuckEntities returns the stuck entities found in the last check.
+	GetStuckEntities() []StuckEntity
+}
+
+// watchdog implements the Watchdog interface
+type watchdog struct {
+	sync.RWMutex // the mutex to synchronize access to this object
+
+	engine       Engine
+	cfg          WatchdogConfig
+	remediations map[string]Action
+
+	// stuckEntities are the stuck entities found in the last check
+	stuckEntities []StuckEntity
+	// applied stores the remediations applied to each stuck entity,
+	// keyed by the entity identifier
+	applied map[string][]string
+
+	lifeCycle lifecycle.LifeCycle
+	mtx       *watchdogMetrics
+}
+
+// NewWatchdog returns a new watchdog of a goal state engine, which can
+// apply the given remediations along with DeadLetterRemediation.
+func NewWatchdog(
+	engine Engine,
+	cfg WatchdogConfig,
+	remediations []Action,
+	parentScope tally.Scope,
+) Watchdog {
+	w := &watchdog{
+		engine:       engine,
+		cfg:          cfg,
+		remediations: make(map[string]Action),
+		applied:      make(map[string][]string),
+		lifeCycle:    lifecycle.NewLifeCycle(),
+		mtx:          newWatchdogMetrics(parentScope.SubScope("watchdog")),
+	}
+	for _, remediation := range remediations {
+		w.remediations[remediation.Name] = remediation
+	}
+	return w
+}
+
+func (w *watchdog) Start() {
+	if w.cfg.CheckPeriod <= 0 {
+		return
+	}
+
+	if w.lifeCycle.Start() {
+		go func() {
+			defer w.lifeCycle.StopComplete()
+
+			ticker := time.NewTicker(w.cfg.CheckPeriod)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-w.lifeCycle.StopCh():
+					return
+				case <-ticker.C:
+					w.check()
+				}
+			}
+		}()
+	}
+}
+
+func (w *watchdog) Stop() {
+	if !w.lifeCycle.Stop() {
+		return
+	}
+	w.lifeCycle.Wait()
+}
+
+func (w *watchdog) GetStuckEntities() []StuckEntity {
+	w.RLock()
+	defer w.RUnlock()
+
+	return append([]StuckEntity(nil), w.stuckEntities...)
+}
+
+// check checks the engine for stuck entities once, alerts on the
+// entities newly stuck and applies the next remediation to each of
+// the stuck entities.
+func (w *watchdog) check() {
+	stuckEntities := w.engine.GetStuckEntities(w.cfg.StuckThreshold)
+
+	w.RLock()
+	previous := w.applied
+	w.RUnlock()
+
+	applied := make(map[string][]string)
+	for i := range stuckEntities {
+		stuck := &stuckEntities[i]
+		id := stuck.Entity.GetID()
+
+		remediations, ok := previous[id]
+		if !ok {
+			log.WithFields(log.Fields{
+				"entity_id":   id,
+				"stuck_since": stuck.StuckSince,
+				"failures":    stuck.Failures,
+				"last_error":  stuck.LastError,
+			}).Warn("goal state entity is stuck")
+			w.mtx.stuckAlerts.Inc(1)
+		}
+
+		if !stuck.DeadLettered && len(remediations) < len(w.cfg.Remediations) {
+			name := w.cfg.Remediations[len(remediations)]
+			w.remediate(stuck.Entity, name)
+			remediations = append(remediations, name)
+		}
+
+		applied[id] = remediations
+		stuck.Remediations = remediations
+	}
+
+	w.Lock()
+	w.stuckEntities = stuckEntities
+	w.applied = applied
+	w.Unlock()
+
+	w.mtx.stuckEntities.Update(float64(len(stuckEntities)))
+}
+
+// remediate applies a remediation to a stuck entity. The remediations
+// other than dead-lettering are run by the engine when it evaluates the
+// entity, so that the watchdog is not blocked by them and they do not
+// race with the actions of the entity.
+func (w *watchdog) remediate(entity Entity, name string) {
+	if name == DeadLetterRemediation {
+		w.engine.DeadLetter(entity)
+		return
+	}
+
+	remediation, ok := w.remediations[name]
+	if !ok {
+		log.WithFields(log.Fields{
+			"entity_id":   entity.GetID(),
+			"remediation": name,
+		}).Error("unknown goal state remediation")
+		return
+	}
+
+	w.mtx.scope.Tagged(map[string]string{"remediation": name}).
+		Counter("remediation_scheduled").Inc(1)
+	w.engine.Remediate(entity, remediation)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+// TestWatchdogCheck tests alerting on the stuck entities and applying
+// the remediations to them in order, on the evaluation of the entities
+// by the engine.
+func TestWatchdogCheck(t *testing.T) {
+	scope := tally.NewTestScope("", map[string]string{})
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		scope).(*engine)
+
+	var remediated []string
+	w := NewWatchdog(
+		e,
+		WatchdogConfig{
+			StuckThreshold: time.Hour,
+			Remediations:   []string{"refresh", "fail", DeadLetterRemediation},
+		},
+		[]Action{
+			{
+				Name: "refresh",
+				Execute: func(ctx context.Context, entity Entity) error {
+					remediated = append(remediated, entity.GetID())
+					return nil
+				},
+			},
+			{
+				Name: "fail",
+				Execute: func(ctx context.Context, entity Entity) error {
+					return fmt.Errorf("fake error")
+				},
+			},
+		},
+		scope).(*watchdog)
+
+	ent := &testStuckEntity{
+		testEntity: newTestEntity("0", stateValue, goalStateValue),
+	}
+	e.Enqueue(ent, time.Now().Add(time.Hour))
+	entityItem := e.getItemFromEntityMap(ent.GetID())
+	e.runActions(entityItem)
+
+	// the entity is not stuck yet
+	w.check()
+	assert.Empty(t, w.GetStuckEntities())
+
+	entityItem.unconvergedSince = time.Now().Add(-2 * time.Hour)
+	w.check()
+	stuckEntities := w.GetStuckEntities()
+	assert.Len(t, stuckEntities, 1)
+	assert.Equal(t, []string{"refresh"}, stuckEntities[0].Remediations)
+	// the remediation is run when the engine evaluates the entity
+	assert.Empty(t, remediated)
+	assert.Len(t, entityItem.remediations, 1)
+	assert.True(t, e.IsPending(ent))
+	e.runActions(entityItem)
+	assert.Equal(t, []string{ent.GetID()}, remediated)
+	assert.Empty(t, entityItem.remediations)
+
+	w.check()
+	e.runActions(entityItem)
+	w.check()
+	stuckEntities = w.GetStuckEntities()
+	assert.Len(t, stuckEntities, 1)
+	assert.Equal(t,
+		[]string{"refresh", "fail", DeadLetterRemediation},
+		stuckEntities[0].Remediations)
+	assert.True(t, entityItem.deadLettered)
+
+	// no more remediations are applied once exhausted
+	w.check()
+	assert.Len(t, w.GetStuckEntities()[0].Remediations, 3)
+
+	snapshot := scope.Snapshot()
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["watchdog.stuck_alerts+"].Value())
+	assert.Equal(t, float64(1),
+		snapshot.Gauges()["watchdog.stuck_entities+"].Value())
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["watchdog.remediation_scheduled+remediation=refresh"].Value())
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["remediation+remediation=refresh"].Value())
+	assert.Equal(t, int64(1),
+		snapshot.Counters()["remediation_fail+remediation=fail"].Value())
+
+	// the entity is not stuck anymore once it converges
+	ent.state = goalStateValue
+	e.runActions(entityItem)
+	w.check()
+	assert.Empty(t, w.GetStuckEntities())
+}
+
+// TestWatchdogStartStop tests starting and stopping the watchdog.
+func TestWatchdogStartStop(t *testing.T) {
+	e := NewEngine(
+		numWorkerThreads,
+		1*time.Second,
+		1*time.Second,
+		tally.NoopScope)
+
+	w := NewWatchdog(
+		e,
+		WatchdogConfig{CheckPeriod: time.Millisecond, StuckThreshold: time.Hour},
+		nil,
+		tally.NoopScope)
+	w.Start()
+	w.Start()
+	w.Stop()
+	w.Stop()
+
+	// the watchdog does not start if disabled
+	w = NewWatchdog(e, WatchdogConfig{}, nil, tally.NoopScope)
+	w.Start()
+	w.Stop()
+}
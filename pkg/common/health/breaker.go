@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// CircuitBreakersPath is the endpoint reporting the state of the
+// registered circuit breakers.
+const CircuitBreakersPath = "/health/circuit_breakers"
+
+// CircuitBreaker provides the state of a circuit breaker guarding the
+// calls to a dependency of the service.
+type CircuitBreaker interface {
+	// IsOpen returns true if the calls guarded by the breaker fail fast
+	IsOpen() bool
+}
+
+var (
+	breakersLock sync.RWMutex
+	breakers     = make(map[string]CircuitBreaker)
+)
+
+// RegisterCircuitBreaker registers a circuit breaker whose state is
+// emitted with the heartbeat. Registering a breaker under an existing
+// name replaces it.
+func RegisterCircuitBreaker(name string, breaker CircuitBreaker) {
+	breakersLock.Lock()
+	defer breakersLock.Unlock()
+	breakers[name] = breaker
+}
+
+// OpenCircuitBreakers returns the sorted names of the registered circuit
+// breakers which are currently open.
+func OpenCircuitBreakers() []string {
+	var open []string
+	for name, breaker := range getCircuitBreakers() {
+		if breaker.IsOpen() {
+			open = append(open, name)
+		}
+	}
+	sort.Strings(open)
+	return open
+}
+
+// CircuitBreakersHandler reports the state, open or closed, of every
+// registered circuit breaker as a JSON object keyed by breaker name.
+func CircuitBreakersHandler(w http.ResponseWriter, _ *http.Request) {
+	states := make(map[string]string)
+	for name, breaker := range getCircuitBreakers() {
+		if breaker.IsOpen() {
+			states[name] = "open"
+		} else {
+			states[name] = "closed"
+		}
+	}
+
+	body, err := json.Marshal(states)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// getCircuitBreakers returns a copy of the registered circuit breakers.
+func getCircuitBreakers() map[string]CircuitBreaker {
+	breakersLock.RLock()
+	defer breakersLock.RUnlock()
+
+	result := make(map[string]CircuitBreaker, len(breakers))
+	for name, breaker := range breakers {
+		result[name] = breaker
+	}
+	return result
+}
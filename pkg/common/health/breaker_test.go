@@ -0,0 +1,47 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testBreaker bool
+
+func (b testBreaker) IsOpen() bool {
+	return bool(b)
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	RegisterCircuitBreaker("b", testBreaker(true))
+	RegisterCircuitBreaker("a", testBreaker(true))
+	RegisterCircuitBreaker("c", testBreaker(false))
+	assert.Equal(t, []string{"a", "b"}, OpenCircuitBreakers())
+
+	RegisterCircuitBreaker("b", testBreaker(false))
+	assert.Equal(t, []string{"a"}, OpenCircuitBreakers())
+
+	req := httptest.NewRequest("GET", "http://example.com"+CircuitBreakersPath, nil)
+	w := httptest.NewRecorder()
+	CircuitBreakersHandler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`{"a": "open", "b": "closed", "c": "closed"}`,
+		w.Body.String())
+}
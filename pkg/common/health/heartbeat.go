@@ -25,6 +25,12 @@ import (
 	"github.com/uber/peloton/pkg/common/leader"
 )
 
+// FreezeState provides the freeze state of the cluster
+type FreezeState interface {
+	// IsFrozen returns true if the cluster is frozen
+	IsFrozen() bool
+}
+
 // Heartbeat is the heartbeat interface
 type Heartbeat interface {
 	Start()
@@ -40,21 +46,25 @@ type heartbeat struct {
 	metrics           *Metrics
 	heartbeatInterval time.Duration
 	candidate         leader.Candidate
+	freezeState       FreezeState
 }
 
 var hb *heartbeat
 var onceInitHeartbeat sync.Once
 
-// InitHeartbeat inits heartbeat
+// InitHeartbeat inits heartbeat. The freeze state of the cluster is
+// emitted with the heartbeat if freezeState is not nil.
 func InitHeartbeat(
 	parent tally.Scope,
 	config Config,
-	candidate leader.Candidate) {
+	candidate leader.Candidate,
+	freezeState FreezeState) {
 	onceInitHeartbeat.Do(func() {
 		hb = &heartbeat{
 			metrics:           NewMetrics(parent.SubScope("health")),
 			heartbeatInterval: config.HeartbeatInterval,
 			candidate:         candidate,
+			freezeState:       freezeState,
 		}
 		hb.metrics.Init.Inc(1)
 		hb.Start()
@@ -95,6 +105,22 @@ func (*heartbeat) Start() {
 				} else {
 					hb.metrics.Leader.Update(0)
 				}
+
+				if hb.freezeState != nil {
+					if hb.freezeState.IsFrozen() {
+						hb.metrics.Frozen.Update(1)
+					} else {
+						hb.metrics.Frozen.Update(0)
+					}
+				}
+
+				for name, breaker := range getCircuitBreakers() {
+					if breaker.IsOpen() {
+						hb.metrics.CircuitBreakerOpen(name).Update(1)
+					} else {
+						hb.metrics.CircuitBreakerOpen(name).Update(0)
+					}
+				}
 			}
 			ticker.Stop()
 		}
@@ -23,6 +23,9 @@ type Metrics struct {
 	Init      tally.Counter
 	Heartbeat tally.Gauge
 	Leader    tally.Gauge
+	Frozen    tally.Gauge
+
+	scope tally.Scope
 }
 
 // NewMetrics returns a new instance of Metrics.
@@ -31,5 +34,14 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		Init:      scope.Counter("init"),
 		Heartbeat: scope.Gauge("heartbeat"),
 		Leader:    scope.Gauge("leader"),
+		Frozen:    scope.Gauge("frozen"),
+		scope:     scope,
 	}
 }
+
+// CircuitBreakerOpen returns the gauge reporting whether the named
+// circuit breaker is open.
+func (m *Metrics) CircuitBreakerOpen(name string) tally.Gauge {
+	return m.scope.Tagged(map[string]string{"breaker": name}).
+		Gauge("circuit_breaker_open")
+}
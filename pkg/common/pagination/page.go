@@ -0,0 +1,126 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagination
+
+// Offset returns the offset in the result of a query to resume from
+// with the given page token. An empty token starts from the beginning.
+// It is used by queries which are paginated by the storage layer.
+func Offset(pageToken string, fingerprint string) (uint32, error) {
+	if len(pageToken) == 0 {
+		return 0, nil
+	}
+
+	t, err := decode(pageToken, fingerprint)
+	if err != nil {
+		return 0, err
+	}
+	return t.Offset, nil
+}
+
+// NextPageToken returns the token to fetch the page after the one ending
+// at offset end, in a result of the given total size. It returns an
+// empty string if there is no page left.
+func NextPageToken(end uint32, total uint32, fingerprint string) string {
+	if end >= total {
+		return ""
+	}
+
+	t := &token{
+		Offset:      end,
+		Fingerprint: fingerprint,
+	}
+	return t.encode()
+}
+
+// Page returns the window [start, end) of the items to return for a page
+// of the given size, and the token to fetch the next page. The items
+// must be in a stable order, and are identified by their unique keys.
+// The page resumes after the last item of the previous page if it is
+// still present, so that items added or removed before it do not lead
+// to duplicate or skipped items. A page size of 0 returns all the items
+// left, and no next page token.
+func Page(
+	pageToken string,
+	pageSize uint32,
+	fingerprint string,
+	keys []string,
+) (start int, end int, nextPageToken string, err error) {
+	if len(pageToken) > 0 {
+		t, err := decode(pageToken, fingerprint)
+		if err != nil {
+			return 0, 0, "", err
+		}
+
+		start = int(t.Offset)
+		if len(t.LastKey) > 0 {
+			for i, k := range keys {
+				if k == t.LastKey {
+					start = i + 1
+					break
+				}
+			}
+		}
+		if start > len(keys) {
+			start = len(keys)
+		}
+	}
+
+	end = len(keys)
+	if pageSize > 0 && start+int(pageSize) < end {
+		end = start + int(pageSize)
+	}
+
+	if end < len(keys) {
+		t := &token{
+			Offset:      uint32(end),
+			Fingerprint: fingerprint,
+		}
+		if end > 0 {
+			t.LastKey = keys[end-1]
+		}
+		nextPageToken = t.encode()
+	}
+
+	return start, end, nextPageToken, nil
+}
+
+// After returns the cursor to resume a sorted query after with the given
+// page token. If the token was issued with an offset instead of a cursor,
+// it returns a nil cursor and the offset to resume the query at.
+func After(pageToken string, fingerprint string) (*Cursor, uint32, error) {
+	if len(pageToken) == 0 {
+		return nil, 0, nil
+	}
+
+	t, err := decode(pageToken, fingerprint)
+	if err != nil {
+		return nil, 0, err
+	}
+	return t.After, t.Offset, nil
+}
+
+// NextCursorToken returns the token to fetch the page after the given
+// cursor. It returns an empty string if there is no cursor.
+func NextCursorToken(after *Cursor, fingerprint string) string {
+	if after == nil {
+		return ""
+	}
+
+	t := &token{
+		After:       after,
+		Fingerprint: fingerprint,
+	}
+	return t.encode()
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+var testKeys = []string{"a", "b", "c", "d", "e"}
+
+// TestPageIteration tests iterating over all the items page by page
+func TestPageIteration(t *testing.T) {
+	fp := Fingerprint("query")
+
+	var (
+		result []string
+		token  string
+	)
+	for {
+		start, end, next, err := Page(token, 2, fp, testKeys)
+		assert.NoError(t, err)
+		result = append(result, testKeys[start:end]...)
+		if len(next) == 0 {
+			break
+		}
+		token = next
+	}
+	assert.Equal(t, testKeys, result)
+}
+
+// TestPageNoSize tests that all the items are returned without page size
+func TestPageNoSize(t *testing.T) {
+	start, end, next, err := Page("", 0, Fingerprint("query"), testKeys)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, start)
+	assert.Equal(t, len(testKeys), end)
+	assert.Empty(t, next)
+}
+
+// TestPageItemsAddedBefore tests that items added before the last item
+// of the previous page are not returned again
+func TestPageItemsAddedBefore(t *testing.T) {
+	fp := Fingerprint("query")
+
+	_, _, next, err := Page("", 2, fp, testKeys)
+	assert.NoError(t, err)
+
+	keys := append([]string{"new"}, testKeys...)
+	start, end, _, err := Page(next, 2, fp, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, keys[start:end])
+}
+
+// TestPageItemRemoved tests falling back to the offset if the last item
+// of the previous page has been removed
+func TestPageItemRemoved(t *testing.T) {
+	fp := Fingerprint("query")
+
+	_, _, next, err := Page("", 2, fp, testKeys)
+	assert.NoError(t, err)
+
+	keys := []string{"a", "c", "d", "e"}
+	start, end, _, err := Page(next, 2, fp, keys)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d", "e"}, keys[start:end])
+}
+
+// TestPageInvalidToken tests that tampered and stale tokens are rejected
+func TestPageInvalidToken(t *testing.T) {
+	fp := Fingerprint("query")
+
+	_, _, next, err := Page("", 2, fp, testKeys)
+	assert.NoError(t, err)
+
+	_, _, _, err = Page(next, 2, Fingerprint("other query"), testKeys)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	_, _, _, err = Page(next+"x", 2, fp, testKeys)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	_, _, _, err = Page("garbage", 2, fp, testKeys)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	forged := (&token{Offset: 4, Fingerprint: fp}).encode()
+	Init(Config{SigningKey: "other key"})
+	defer Init(Config{})
+	_, _, _, err = Page(forged, 2, fp, testKeys)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestOffset tests paginating a query by offset
+func TestOffset(t *testing.T) {
+	fp := Fingerprint("query")
+
+	offset, err := Offset("", fp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0), offset)
+
+	next := NextPageToken(10, 25, fp)
+	assert.NotEmpty(t, next)
+	offset, err = Offset(next, fp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), offset)
+
+	_, err = Offset(next, Fingerprint("other query"))
+	assert.Error(t, err)
+
+	assert.Empty(t, NextPageToken(25, 25, fp))
+}
+
+// TestAfter tests paginating a sorted query by cursor
+func TestAfter(t *testing.T) {
+	fp := Fingerprint("query")
+
+	after, offset, err := After("", fp)
+	assert.NoError(t, err)
+	assert.Nil(t, after)
+	assert.Equal(t, uint32(0), offset)
+
+	cursor := &Cursor{Values: []string{"RUNNING"}, Keys: []string{"a", "b"}}
+	next := NextCursorToken(cursor, fp)
+	assert.NotEmpty(t, next)
+	after, _, err = After(next, fp)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, after)
+
+	// tokens issued with an offset are still accepted
+	after, offset, err = After(NextPageToken(10, 25, fp), fp)
+	assert.NoError(t, err)
+	assert.Nil(t, after)
+	assert.Equal(t, uint32(10), offset)
+
+	_, _, err = After(next, Fingerprint("other query"))
+	assert.Error(t, err)
+
+	assert.Empty(t, NextCursorToken(nil, fp))
+}
+
+// TestProcessSigningKey tests that the page tokens are signed with a key
+// generated for the process if no signing key is configured
+func TestProcessSigningKey(t *testing.T) {
+	fp := Fingerprint("query")
+
+	Init(Config{SigningKey: "peloton-pagination"})
+	forged := (&token{Offset: 4, Fingerprint: fp}).encode()
+	Init(Config{})
+
+	_, err := Offset(forged, fp)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	offset, err := Offset(NextPageToken(10, 25, fp), fp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), offset)
+	assert.Len(t, processSigningKey, _signingKeySize)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// _signingKeySize is the size of the signing key generated per process
+const _signingKeySize = 32
+
+var (
+	// processSigningKey is used to sign the page tokens if no signing key
+	// is configured. It is generated for each process, so the tokens it
+	// signs are not accepted by another instance after a leader change.
+	processSigningKey = newSigningKey()
+
+	mu         sync.RWMutex
+	signingKey = processSigningKey
+
+	errInvalidToken = yarpcerrors.InvalidArgumentErrorf("invalid page token")
+	errStaleToken   = yarpcerrors.InvalidArgumentErrorf(
+		"page token does not match the query")
+)
+
+// Config for pagination
+type Config struct {
+	// SigningKey is the secret key used to sign the page tokens. All the
+	// instances of a daemon must use the same key, so that a token issued
+	// by one instance is accepted by another one after a leader change.
+	SigningKey string `yaml:"signing_key"`
+}
+
+// newSigningKey returns a random key to sign the page tokens
+func newSigningKey() []byte {
+	key := make([]byte, _signingKeySize)
+	if _, err := rand.Read(key); err != nil {
+		log.WithError(err).Fatal("failed to generate the page token signing key")
+	}
+	return key
+}
+
+// Init sets up the key used to sign the page tokens
+func Init(cfg Config) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(cfg.SigningKey) == 0 {
+		log.Warn("no page token signing key configured, page tokens " +
+			"are not accepted after a leader change")
+		signingKey = processSigningKey
+		return
+	}
+	signingKey = []byte(cfg.SigningKey)
+}
+
+// Cursor is the position of the last item of a page in a sorted result
+type Cursor struct {
+	// Values of the sort properties of the last item
+	Values []string `json:"v,omitempty"`
+	// Keys of the items returned with the same values as the last item
+	Keys []string `json:"k,omitempty"`
+}
+
+// token is the decoded form of an opaque page token
+type token struct {
+	// Offset of the next page in the result
+	Offset uint32 `json:"o"`
+	// Key of the last item returned, if the items have unique keys.
+	// It is used to resume from the same item even if items were
+	// added to or removed from the result before it.
+	LastKey string `json:"k,omitempty"`
+	// Cursor of the last item returned, if the query is sorted and
+	// paginated by the storage layer.
+	After *Cursor `json:"a,omitempty"`
+	// Fingerprint of the query the token was issued for
+	Fingerprint string `json:"f"`
+}
+
+// Fingerprint returns a digest of the parameters of a query, except its
+// page size and token, so that a token is only accepted for the query
+// it was issued for.
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// sign returns the signature of an encoded token payload
+func sign(payload string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encode returns the opaque, signed form of the token
+func (t *token) encode() string {
+	buf, _ := json.Marshal(t)
+	payload := base64.RawURLEncoding.EncodeToString(buf)
+	return payload + "." + sign(payload)
+}
+
+// decode verifies and decodes an opaque page token issued for the
+// query with the given fingerprint
+func decode(s string, fingerprint string) (*token, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return nil, errInvalidToken
+	}
+	if !hmac.Equal([]byte(sign(parts[0])), []byte(parts[1])) {
+		return nil, errInvalidToken
+	}
+
+	buf, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errInvalidToken
+	}
+	t := &token{}
+	if err := json.Unmarshal(buf, t); err != nil {
+		return nil, errInvalidToken
+	}
+	if t.Fingerprint != fingerprint {
+		return nil, errStaleToken
+	}
+	return t, nil
+}
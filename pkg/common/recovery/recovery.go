@@ -226,9 +226,12 @@ func recoverJobsBatch(
 			continue
 		}
 
-		// Do not process jobs in terminal state and have no update
+		// Do not process jobs in terminal state and have no update, except
+		// batch jobs which still have to be garbage collected after their
+		// TTL expires
 		if util.IsPelotonJobStateTerminal(jobRuntime.GetState()) &&
-			util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) {
+			util.IsPelotonJobStateTerminal(jobRuntime.GetGoalState()) &&
+			jobConfig.GetMaxCompletedJobTtl() == 0 {
 			// Delete this job from active_jobs table ONLY if it is a terminal
 			// BATCH job
 			if jobConfig.GetType() == job.JobType_BATCH {
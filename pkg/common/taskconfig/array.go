@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/gogo/protobuf/proto"
+	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+)
+
+const (
+	// ArrayIndexEnv is the environment variable holding the index of
+	// the job array element of a task
+	ArrayIndexEnv = "PELOTON_ARRAY_INDEX"
+	// ArrayParameterEnv is the environment variable holding the parameter
+	// of the job array element of a task
+	ArrayParameterEnv = "PELOTON_ARRAY_PARAMETER"
+	// ArrayParameterFileEnv is the environment variable holding the name
+	// of the parameter file of a job array in the sandbox of a task
+	ArrayParameterFileEnv = "PELOTON_ARRAY_PARAMETER_FILE"
+)
+
+// arrayEnvironment returns the environment variables passed to the task
+// of a job array element.
+func arrayEnvironment(
+	arrayConfig *job.JobArrayConfig,
+	instanceID uint32,
+) []*pod.Environment {
+	env := []*pod.Environment{
+		{Name: ArrayIndexEnv, Value: fmt.Sprint(instanceID)},
+	}
+	if file := arrayConfig.GetParameterFile(); len(file) != 0 {
+		return append(env, &pod.Environment{
+			Name:  ArrayParameterFileEnv,
+			Value: path.Base(file),
+		})
+	}
+	if parameters := arrayConfig.GetParameters(); instanceID < uint32(len(parameters)) {
+		env = append(env, &pod.Environment{
+			Name:  ArrayParameterEnv,
+			Value: parameters[instanceID],
+		})
+	}
+	return env
+}
+
+// addParameterFile adds the parameter file of a job array, if any, to
+// the URIs fetched by a mesos CommandInfo in place.
+func addParameterFile(c *mesosv1.CommandInfo, arrayConfig *job.JobArrayConfig) {
+	if file := arrayConfig.GetParameterFile(); len(file) != 0 {
+		c.Uris = append(c.Uris, &mesosv1.CommandInfo_URI{
+			Value: proto.String(file),
+		})
+	}
+}
+
+// ApplyArrayParameter returns a copy of the task config of a job array
+// element with its array index and parameter added to the command.
+// The config passed in is returned as is if the job is not a job array,
+// and is never modified.
+func ApplyArrayParameter(
+	cfg *task.TaskConfig,
+	arrayConfig *job.JobArrayConfig,
+	instanceID uint32,
+) *task.TaskConfig {
+	if cfg == nil || arrayConfig == nil {
+		return cfg
+	}
+
+	applied := proto.Clone(cfg).(*task.TaskConfig)
+	if applied.Command == nil {
+		applied.Command = &mesosv1.CommandInfo{}
+	}
+	if applied.Command.Environment == nil {
+		applied.Command.Environment = &mesosv1.Environment{}
+	}
+	for _, env := range arrayEnvironment(arrayConfig, instanceID) {
+		applied.Command.Environment.Variables = append(
+			applied.Command.Environment.Variables,
+			&mesosv1.Environment_Variable{
+				Name:  proto.String(env.GetName()),
+				Value: proto.String(env.GetValue()),
+			})
+	}
+	addParameterFile(applied.Command, arrayConfig)
+	return applied
+}
+
+// ApplyArrayParameterToPodSpec returns a copy of the pod spec of a job
+// array element with its array index and parameter added to the
+// environment of every container. The parameter file is fetched by the
+// containers which have a command. The spec passed in is returned as is
+// if the job is not a job array, and is never modified.
+func ApplyArrayParameterToPodSpec(
+	spec *pod.PodSpec,
+	arrayConfig *job.JobArrayConfig,
+	instanceID uint32,
+) *pod.PodSpec {
+	if spec == nil || arrayConfig == nil {
+		return spec
+	}
+
+	applied := proto.Clone(spec).(*pod.PodSpec)
+	for _, container := range applied.GetContainers() {
+		container.Environment = append(
+			container.Environment,
+			arrayEnvironment(arrayConfig, instanceID)...)
+		if container.GetCommand() != nil {
+			addParameterFile(container.Command, arrayConfig)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyArrayParameter(t *testing.T) {
+	cfg := &task.TaskConfig{
+		Command: &mesos_v1.CommandInfo{
+			Value: util.PtrPrintf("run"),
+		},
+	}
+
+	// not a job array
+	assert.True(t, ApplyArrayParameter(cfg, nil, 1) == cfg)
+
+	applied := ApplyArrayParameter(cfg, &job.JobArrayConfig{
+		Parameters: []string{"a", "b"},
+	}, 1)
+	assert.Nil(t, cfg.GetCommand().GetEnvironment())
+	assert.Equal(t, []*mesos_v1.Environment_Variable{
+		{Name: util.PtrPrintf(ArrayIndexEnv), Value: util.PtrPrintf("1")},
+		{Name: util.PtrPrintf(ArrayParameterEnv), Value: util.PtrPrintf("b")},
+	}, applied.GetCommand().GetEnvironment().GetVariables())
+	assert.Empty(t, applied.GetCommand().GetUris())
+
+	applied = ApplyArrayParameter(cfg, &job.JobArrayConfig{
+		ParameterFile: "http://host/params/list.txt",
+	}, 0)
+	assert.Equal(t, []*mesos_v1.Environment_Variable{
+		{Name: util.PtrPrintf(ArrayIndexEnv), Value: util.PtrPrintf("0")},
+		{Name: util.PtrPrintf(ArrayParameterFileEnv), Value: util.PtrPrintf("list.txt")},
+	}, applied.GetCommand().GetEnvironment().GetVariables())
+	assert.Equal(t, []*mesos_v1.CommandInfo_URI{
+		{Value: util.PtrPrintf("http://host/params/list.txt")},
+	}, applied.GetCommand().GetUris())
+	assert.Empty(t, cfg.GetCommand().GetUris())
+}
+
+func TestApplyArrayParameterToPodSpec(t *testing.T) {
+	spec := &pod.PodSpec{
+		Containers: []*pod.ContainerSpec{
+			{
+				Name:        "main",
+				Environment: []*pod.Environment{{Name: "FOO", Value: "bar"}},
+				Command:     &mesos_v1.CommandInfo{Value: util.PtrPrintf("run")},
+			},
+			{Name: "sidecar"},
+		},
+	}
+
+	assert.True(t, ApplyArrayParameterToPodSpec(spec, nil, 1) == spec)
+
+	applied := ApplyArrayParameterToPodSpec(spec, &job.JobArrayConfig{
+		ParameterFile: "http://host/params.txt",
+	}, 2)
+	assert.Len(t, spec.GetContainers()[0].GetEnvironment(), 1)
+	assert.Equal(t, []*pod.Environment{
+		{Name: "FOO", Value: "bar"},
+		{Name: ArrayIndexEnv, Value: "2"},
+		{Name: ArrayParameterFileEnv, Value: "params.txt"},
+	}, applied.GetContainers()[0].GetEnvironment())
+	assert.Len(t, applied.GetContainers()[0].GetCommand().GetUris(), 1)
+	assert.Equal(t, []*pod.Environment{
+		{Name: ArrayIndexEnv, Value: "2"},
+		{Name: ArrayParameterFileEnv, Value: "params.txt"},
+	}, applied.GetContainers()[1].GetEnvironment())
+	assert.Nil(t, applied.GetContainers()[1].GetCommand())
+}
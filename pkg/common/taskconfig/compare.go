@@ -261,23 +261,30 @@ func hasMesosContainerChanged(
 }
 
 // HasTaskConfigChanged returns true if the task config (other than the name)
-// has changed.
+// has changed. Resource limits, environment variables and volumes are
+// normalized before comparison, so equivalent configs are not
+// reported as changed.
 func HasTaskConfigChanged(
 	prevTaskConfig *task.TaskConfig,
 	newTaskConfig *task.TaskConfig,
 ) bool {
-	if prevTaskConfig == nil ||
-		newTaskConfig == nil ||
-		HasPelotonLabelsChanged(prevTaskConfig.GetLabels(), newTaskConfig.GetLabels()) ||
-		HasPortConfigsChanged(prevTaskConfig.GetPorts(), newTaskConfig.GetPorts()) ||
-		hasMesosContainerChanged(prevTaskConfig.GetContainer(), newTaskConfig.GetContainer()) ||
-		hasMesosCommandChanged(prevTaskConfig.GetCommand(), newTaskConfig.GetCommand()) {
+	if prevTaskConfig == nil || newTaskConfig == nil {
 		return true
 	}
 
 	prevTask := proto.Clone(prevTaskConfig).(*task.TaskConfig)
 	newTask := proto.Clone(newTaskConfig).(*task.TaskConfig)
 
+	normalizeTaskConfig(prevTask)
+	normalizeTaskConfig(newTask)
+
+	if HasPelotonLabelsChanged(prevTask.GetLabels(), newTask.GetLabels()) ||
+		HasPortConfigsChanged(prevTask.GetPorts(), newTask.GetPorts()) ||
+		hasMesosContainerChanged(prevTask.GetContainer(), newTask.GetContainer()) ||
+		hasMesosCommandChanged(prevTask.GetCommand(), newTask.GetCommand()) {
+		return true
+	}
+
 	oldName := prevTask.GetName()
 	newName := newTask.GetName()
 	oldLabels := prevTask.GetLabels()
@@ -316,7 +323,34 @@ func HasTaskConfigChanged(
 	return !proto.Equal(prevTask, newTask)
 }
 
+// HasOnlyResourceLimitsChanged returns true if the task config has changed,
+// but only in its cpu and memory limits. Such a change can be applied to a
+// running task by resizing it in place instead of restarting it.
+func HasOnlyResourceLimitsChanged(
+	prevTaskConfig *task.TaskConfig,
+	newTaskConfig *task.TaskConfig,
+) bool {
+	if prevTaskConfig == nil ||
+		newTaskConfig == nil ||
+		!HasTaskConfigChanged(prevTaskConfig, newTaskConfig) {
+		return false
+	}
+
+	prevTask := proto.Clone(prevTaskConfig).(*task.TaskConfig)
+	newTask := proto.Clone(newTaskConfig).(*task.TaskConfig)
+	for _, t := range []*task.TaskConfig{prevTask, newTask} {
+		if t.GetResource() != nil {
+			t.Resource.CpuLimit = 0
+			t.Resource.MemLimitMb = 0
+		}
+	}
+
+	return !HasTaskConfigChanged(prevTask, newTask)
+}
+
 // HasContainerSpecChanged returns true if the container spec has changed.
+// Resource limits, environment variables and volume mounts are normalized
+// before comparison.
 func HasContainerSpecChanged(
 	prevContainerSpec *pod.ContainerSpec,
 	newContainerSpec *pod.ContainerSpec) bool {
@@ -336,6 +370,9 @@ func HasContainerSpecChanged(
 	prevContainer := proto.Clone(prevContainerSpec).(*pod.ContainerSpec)
 	newContainer := proto.Clone(newContainerSpec).(*pod.ContainerSpec)
 
+	normalizeContainerSpec(prevContainer)
+	normalizeContainerSpec(newContainer)
+
 	oldPorts := prevContainer.GetPorts()
 	newPorts := newContainer.GetPorts()
 	oldContainerInfo := prevContainer.GetContainer()
@@ -478,6 +478,51 @@ func TestHasTaskConfigChanged(t *testing.T) {
 		},
 	}
 
+	t6 := &task.TaskConfig{
+		Name: "task-1",
+		Resource: &task.ResourceConfig{
+			CpuLimit:   1.0,
+			MemLimitMb: 1024,
+		},
+		Container: &mesosv1.ContainerInfo{
+			Volumes: []*mesosv1.Volume{
+				{ContainerPath: ptr.String("/a"), HostPath: ptr.String("/tmp/a")},
+				{ContainerPath: ptr.String("/b"), HostPath: ptr.String("/tmp/b")},
+			},
+		},
+		Command: &mesosv1.CommandInfo{
+			Environment: &mesosv1.Environment{
+				Variables: []*mesosv1.Environment_Variable{
+					{Name: ptr.String("A"), Value: ptr.String("1")},
+					{Name: ptr.String("B"), Value: ptr.String("2")},
+				},
+			},
+		},
+	}
+	t7 := &task.TaskConfig{
+		Name: "task-1",
+		Resource: &task.ResourceConfig{
+			CpuLimit:   1000 * 0.001,
+			MemLimitMb: 1024.0000001,
+		},
+		Container: &mesosv1.ContainerInfo{
+			Volumes: []*mesosv1.Volume{
+				{ContainerPath: ptr.String("/b"), HostPath: ptr.String("/tmp/b")},
+				{ContainerPath: ptr.String("/a"), HostPath: ptr.String("/tmp/a")},
+			},
+		},
+		Command: &mesosv1.CommandInfo{
+			Environment: &mesosv1.Environment{
+				Variables: []*mesosv1.Environment_Variable{
+					{Name: ptr.String("B"), Value: ptr.String("2")},
+					{Name: ptr.String("A"), Value: ptr.String("1")},
+				},
+			},
+		},
+	}
+	t8 := proto.Clone(t7).(*task.TaskConfig)
+	t8.Resource.CpuLimit = 1.5
+
 	testCases := []struct {
 		name    string
 		taskA   *task.TaskConfig
@@ -502,6 +547,18 @@ func TestHasTaskConfigChanged(t *testing.T) {
 			t5,
 			false,
 		},
+		{
+			"equivalent resources, env and volumes in different order should be the same",
+			t6,
+			t7,
+			false,
+		},
+		{
+			"different cpu limit should be different",
+			t6,
+			t8,
+			true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -548,6 +605,61 @@ func TestHasContainerSpecChanged(t *testing.T) {
 	assert.True(t, HasContainerSpecChanged(oldContainer, nil))
 	assert.True(t, HasContainerSpecChanged(nil, newContainer))
 	assert.False(t, HasContainerSpecChanged(oldContainer, newContainer))
+
+	oldContainer.Resource = &pod.ResourceSpec{CpuLimit: 0.5}
+	oldContainer.Environment = []*pod.Environment{
+		{Name: "A", Value: "1"},
+		{Name: "B", Value: "2"},
+	}
+	oldContainer.VolumeMounts = []*pod.VolumeMount{
+		{Name: "v1", MountPath: "/a"},
+		{Name: "v2", MountPath: "/b"},
+	}
+	newContainer.Resource = &pod.ResourceSpec{CpuLimit: 500 * 0.001}
+	newContainer.Environment = []*pod.Environment{
+		{Name: "B", Value: "2"},
+		{Name: "A", Value: "1"},
+	}
+	newContainer.VolumeMounts = []*pod.VolumeMount{
+		{Name: "v2", MountPath: "/b"},
+		{Name: "v1", MountPath: "/a"},
+	}
+	assert.False(t, HasContainerSpecChanged(oldContainer, newContainer))
+
+	newContainer.Environment[0].Value = "3"
+	assert.True(t, HasContainerSpecChanged(oldContainer, newContainer))
+}
+
+// TestHasOnlyResourceLimitsChanged checks that only changes to the cpu and
+// memory limits of a task config are detected
+func TestHasOnlyResourceLimitsChanged(t *testing.T) {
+	t1 := &task.TaskConfig{
+		Name: "task-1",
+		Resource: &task.ResourceConfig{
+			CpuLimit:    1,
+			MemLimitMb:  100,
+			DiskLimitMb: 1000,
+		},
+		Command: &mesosv1.CommandInfo{Value: ptr.String("echo")},
+	}
+
+	t2 := proto.Clone(t1).(*task.TaskConfig)
+	assert.False(t, HasOnlyResourceLimitsChanged(t1, t2))
+
+	t2.Resource.CpuLimit = 2
+	t2.Resource.MemLimitMb = 200
+	assert.True(t, HasOnlyResourceLimitsChanged(t1, t2))
+
+	t3 := proto.Clone(t2).(*task.TaskConfig)
+	t3.Resource.DiskLimitMb = 2000
+	assert.False(t, HasOnlyResourceLimitsChanged(t1, t3))
+
+	t4 := proto.Clone(t2).(*task.TaskConfig)
+	t4.Command = &mesosv1.CommandInfo{Value: ptr.String("ls")}
+	assert.False(t, HasOnlyResourceLimitsChanged(t1, t4))
+
+	assert.False(t, HasOnlyResourceLimitsChanged(nil, t2))
+	assert.False(t, HasOnlyResourceLimitsChanged(t1, nil))
 }
 
 // TestHasPodSpecChanged checks PodSpec comparision util function
@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+)
+
+// ControllerInstanceIDs returns the instance IDs of the controller tasks
+// of a job in increasing order, or nil if the job does not have any. If the
+// job config names the controller tasks, they are the tasks which have that
+// name. Otherwise they are the tasks which have the controller flag set.
+// The default config makes at most one task a controller task, the one
+// with the lowest instance ID among the instances without their own config,
+// so multiple controller tasks have to be set in the instance configs.
+func ControllerInstanceIDs(config *job.JobConfig) []uint32 {
+	name := config.GetControllerTaskName()
+
+	// Instance 0 is always checked, so that a config without an instance
+	// count can still be checked for a controller task.
+	instanceCount := config.GetInstanceCount()
+	if instanceCount == 0 {
+		instanceCount = 1
+	}
+
+	var instanceIDs []uint32
+	for id, instanceConfig := range config.GetInstanceConfig() {
+		if id >= instanceCount {
+			continue
+		}
+		if IsControllerTask(MergeWithStrategy(
+			config.GetDefaultConfig(),
+			instanceConfig,
+			config.GetInstanceConfigMergeStrategy()), name) {
+			instanceIDs = append(instanceIDs, id)
+		}
+	}
+
+	defaultID := uint32(0)
+	for {
+		if _, ok := config.GetInstanceConfig()[defaultID]; !ok {
+			break
+		}
+		defaultID++
+	}
+	if defaultID < instanceCount &&
+		IsControllerTask(config.GetDefaultConfig(), name) {
+		instanceIDs = append(instanceIDs, defaultID)
+	}
+
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+	return instanceIDs
+}
+
+// IsControllerTask returns whether a task is a controller task of a job
+// which names its controller tasks controllerTaskName. If the job does not
+// name them, the task is a controller task if its controller flag is set.
+func IsControllerTask(
+	taskConfig *task.TaskConfig,
+	controllerTaskName string,
+) bool {
+	if len(controllerTaskName) != 0 {
+		return taskConfig.GetName() == controllerTaskName
+	}
+	return taskConfig.GetController()
+}
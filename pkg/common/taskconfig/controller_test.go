@@ -0,0 +1,139 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerInstanceIDs(t *testing.T) {
+	tests := []struct {
+		config      *job.JobConfig
+		instanceIDs []uint32
+	}{
+		{
+			config: &job.JobConfig{
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{},
+			},
+		},
+		{
+			// controller flag in default config
+			config: &job.JobConfig{
+				InstanceCount: 1,
+				DefaultConfig: &task.TaskConfig{Controller: true},
+			},
+			instanceIDs: []uint32{0},
+		},
+		{
+			// controller flag on an instance other than 0
+			config: &job.JobConfig{
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					2: {Controller: true},
+				},
+			},
+			instanceIDs: []uint32{2},
+		},
+		{
+			// instance 0 overriding the default config with controller flag
+			config: &job.JobConfig{
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{Controller: true},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					0: {Name: "worker"},
+					2: {Name: "worker"},
+				},
+			},
+			instanceIDs: []uint32{1},
+		},
+		{
+			// controller task named in the job config
+			config: &job.JobConfig{
+				InstanceCount:      4,
+				ControllerTaskName: "driver",
+				DefaultConfig:      &task.TaskConfig{Name: "worker"},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					1: {Name: "worker", Controller: true},
+					3: {Name: "driver"},
+				},
+			},
+			instanceIDs: []uint32{3},
+		},
+		{
+			// named controller task in default config
+			config: &job.JobConfig{
+				InstanceCount:      3,
+				ControllerTaskName: "driver",
+				DefaultConfig:      &task.TaskConfig{Name: "driver"},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					0: {Name: "worker"},
+				},
+			},
+			instanceIDs: []uint32{1},
+		},
+		{
+			// multiple controller tasks
+			config: &job.JobConfig{
+				InstanceCount: 4,
+				DefaultConfig: &task.TaskConfig{Name: "worker"},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					0: {Name: "driver", Controller: true},
+					2: {Name: "driver", Controller: true},
+				},
+			},
+			instanceIDs: []uint32{0, 2},
+		},
+		{
+			// controller flag in default config of multiple instances
+			config: &job.JobConfig{
+				InstanceCount: 3,
+				DefaultConfig: &task.TaskConfig{Controller: true},
+			},
+			instanceIDs: []uint32{0},
+		},
+		{
+			// instance configs beyond the instance count are ignored
+			config: &job.JobConfig{
+				InstanceCount: 2,
+				DefaultConfig: &task.TaskConfig{},
+				InstanceConfig: map[uint32]*task.TaskConfig{
+					1: {Controller: true},
+					5: {Controller: true},
+				},
+			},
+			instanceIDs: []uint32{1},
+		},
+		{
+			// named controller task not found
+			config: &job.JobConfig{
+				InstanceCount:      2,
+				ControllerTaskName: "driver",
+				DefaultConfig:      &task.TaskConfig{Controller: true},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		assert.Equal(t, test.instanceIDs,
+			ControllerInstanceIDs(test.config), "test %d", i)
+	}
+}
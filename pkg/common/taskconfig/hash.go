@@ -0,0 +1,99 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+)
+
+// HashJobConfig returns a stable checksum of a job config which can be used
+// to detect whether two config versions are semantically identical.
+// The change log is ignored, as are the ordering of labels, ports,
+// environment variables and volumes, and instance configs which do not
+// change anything from the default config.
+func HashJobConfig(cfg *job.JobConfig) (string, error) {
+	if cfg == nil {
+		return "", nil
+	}
+
+	c := proto.Clone(cfg).(*job.JobConfig)
+	c.ChangeLog = nil
+	sortPelotonLabels(c.GetLabels())
+	normalizeTaskConfigForHash(c.GetDefaultConfig())
+
+	for i, instanceConfig := range cfg.GetInstanceConfig() {
+		merged := MergeWithStrategy(
+			cfg.GetDefaultConfig(),
+			instanceConfig,
+			cfg.GetInstanceConfigMergeStrategy())
+		if cfg.GetDefaultConfig() != nil &&
+			merged.GetName() == cfg.GetDefaultConfig().GetName() &&
+			!HasTaskConfigChanged(cfg.GetDefaultConfig(), merged) {
+			delete(c.InstanceConfig, i)
+			continue
+		}
+		normalizeTaskConfigForHash(c.GetInstanceConfig()[i])
+	}
+
+	return hashMessage(c)
+}
+
+// hashMessage returns the hex encoded sha256 of the deterministic
+// serialization of a message.
+func hashMessage(msg proto.Message) (string, error) {
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(msg); err != nil {
+		return "", errors.Wrap(err, "failed to marshal config for hashing")
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeTaskConfigForHash canonicalizes a task config in place
+// before it is hashed.
+func normalizeTaskConfigForHash(cfg *task.TaskConfig) {
+	if cfg == nil {
+		return
+	}
+
+	normalizeTaskConfig(cfg)
+	sortPelotonLabels(cfg.GetLabels())
+
+	ports := cfg.GetPorts()
+	sort.SliceStable(ports, func(i, j int) bool {
+		return ports[i].GetName() < ports[j].GetName()
+	})
+}
+
+// sortPelotonLabels sorts v0 labels by key, then value, in place.
+func sortPelotonLabels(labels []*peloton.Label) {
+	sort.SliceStable(labels, func(i, j int) bool {
+		if labels[i].GetKey() != labels[j].GetKey() {
+			return labels[i].GetKey() < labels[j].GetKey()
+		}
+		return labels[i].GetValue() < labels[j].GetValue()
+	})
+}
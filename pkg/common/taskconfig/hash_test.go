@@ -0,0 +1,108 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/common/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashJobConfig(t *testing.T) {
+	newConfig := func() *job.JobConfig {
+		return &job.JobConfig{
+			Name:          "test-job",
+			InstanceCount: 2,
+			Labels: []*peloton.Label{
+				{Key: "k1", Value: "v1"},
+				{Key: "k2", Value: "v2"},
+			},
+			ChangeLog: &peloton.ChangeLog{Version: 1},
+			DefaultConfig: &task.TaskConfig{
+				Name: "task",
+				Resource: &task.ResourceConfig{
+					CpuLimit: 1,
+				},
+				Command: &mesos_v1.CommandInfo{
+					Value: util.PtrPrintf("run"),
+					Environment: &mesos_v1.Environment{
+						Variables: []*mesos_v1.Environment_Variable{
+							{
+								Name:  util.PtrPrintf("A"),
+								Value: util.PtrPrintf("1"),
+							},
+							{
+								Name:  util.PtrPrintf("B"),
+								Value: util.PtrPrintf("2"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	hash, err := HashJobConfig(newConfig())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hash)
+
+	// change log, ordering and resource precision are ignored
+	cfg := newConfig()
+	cfg.ChangeLog = &peloton.ChangeLog{Version: 5}
+	cfg.Labels[0], cfg.Labels[1] = cfg.Labels[1], cfg.Labels[0]
+	variables := cfg.DefaultConfig.Command.Environment.Variables
+	variables[0], variables[1] = variables[1], variables[0]
+	cfg.DefaultConfig.Resource.CpuLimit = 0.9999999
+	otherHash, err := HashJobConfig(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, otherHash)
+
+	// instance config identical to the default config is ignored
+	cfg = newConfig()
+	cfg.InstanceConfig = map[uint32]*task.TaskConfig{
+		0: {Resource: &task.ResourceConfig{CpuLimit: 1}},
+	}
+	otherHash, err = HashJobConfig(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, otherHash)
+
+	// the config passed in is not modified
+	assert.Len(t, cfg.GetInstanceConfig(), 1)
+
+	// actual changes are detected
+	cfg = newConfig()
+	cfg.InstanceConfig = map[uint32]*task.TaskConfig{
+		0: {Resource: &task.ResourceConfig{CpuLimit: 2}},
+	}
+	otherHash, err = HashJobConfig(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	cfg = newConfig()
+	cfg.InstanceCount = 3
+	otherHash, err = HashJobConfig(cfg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+
+	hash, err = HashJobConfig(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, hash)
+}
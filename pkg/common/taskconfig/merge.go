@@ -19,6 +19,9 @@ import (
 	"strings"
 
 	"github.com/gogo/protobuf/proto"
+	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/pkg/common"
@@ -30,6 +33,17 @@ import (
 // If any of the arguments is nil, no merge will happen, and the non-nil
 // argument (if exists) is returned.
 func Merge(base *task.TaskConfig, override *task.TaskConfig) *task.TaskConfig {
+	return MergeWithStrategy(base, override, nil)
+}
+
+// MergeWithStrategy returns the merged task config between a base and an
+// override, using the merge semantics selected in the job's instance
+// config merge strategy. A nil strategy behaves the same as Merge.
+func MergeWithStrategy(
+	base *task.TaskConfig,
+	override *task.TaskConfig,
+	strategy *job.InstanceConfigMergeStrategy,
+) *task.TaskConfig {
 	if override == nil {
 		return base
 	}
@@ -41,6 +55,42 @@ func Merge(base *task.TaskConfig, override *task.TaskConfig) *task.TaskConfig {
 	merged := &task.TaskConfig{}
 	merge(*base, *override, merged)
 
+	if strategy.GetLabels() == job.MergeMode_MERGE_MODE_APPEND &&
+		override.GetLabels() != nil {
+		merged.Labels = appendLabels(base.GetLabels(), override.GetLabels())
+	}
+
+	if strategy.GetPorts() == job.MergeMode_MERGE_MODE_APPEND &&
+		override.GetPorts() != nil {
+		merged.Ports = appendPorts(base.GetPorts(), override.GetPorts())
+	}
+
+	if base.GetContainer() == nil || override.GetContainer() == nil {
+		return retainBaseSecretsInInstanceConfig(base, merged)
+	}
+
+	if strategy.GetDeepMergeContainer() {
+		container := proto.Clone(base.GetContainer()).(*mesosv1.ContainerInfo)
+		deepMerge(
+			reflect.ValueOf(container).Elem(),
+			reflect.ValueOf(proto.Clone(override.GetContainer())).Elem())
+		// volumes are handled separately according to the volume merge mode
+		container.Volumes = override.GetContainer().GetVolumes()
+		merged.Container = container
+	}
+
+	if strategy.GetVolumes() == job.MergeMode_MERGE_MODE_APPEND {
+		if !strategy.GetDeepMergeContainer() {
+			merged.Container = proto.Clone(
+				override.GetContainer()).(*mesosv1.ContainerInfo)
+		}
+		// all the base volumes, including secrets, are retained
+		merged.Container.Volumes = appendVolumes(
+			base.GetContainer().GetVolumes(),
+			override.GetContainer().GetVolumes())
+		return merged
+	}
+
 	return retainBaseSecretsInInstanceConfig(base, merged)
 }
 
@@ -108,6 +158,110 @@ func merge(base interface{}, override interface{}, merged interface{}) {
 	}
 }
 
+// deepMerge merges the fields set in the override message into the merged
+// message, recursing into the nested messages set in both. Unlike
+// proto.Merge, which appends repeated fields, a repeated field set in the
+// override replaces the one of the merged message, so that e.g. docker
+// parameters, port mappings and network infos are not duplicated.
+func deepMerge(merged reflect.Value, override reflect.Value) {
+	overrideType := override.Type()
+
+	for i := 0; i < override.NumField(); i++ {
+		if strings.HasPrefix(overrideType.Field(i).Name, common.ReservedProtobufFieldPrefix) {
+			continue
+		}
+
+		field := override.Field(i)
+		switch field.Kind() {
+		case reflect.Ptr:
+			if field.IsNil() {
+				continue
+			}
+			if field.Elem().Kind() == reflect.Struct &&
+				!merged.Field(i).IsNil() {
+				// merge the nested message
+				deepMerge(merged.Field(i).Elem(), field.Elem())
+				continue
+			}
+			merged.Field(i).Set(field)
+		case reflect.Slice:
+			if field.Len() == 0 {
+				continue
+			}
+			merged.Field(i).Set(field)
+		case reflect.Map:
+			if field.Len() == 0 {
+				continue
+			}
+			if merged.Field(i).IsNil() {
+				merged.Field(i).Set(field)
+				continue
+			}
+			for _, key := range field.MapKeys() {
+				merged.Field(i).SetMapIndex(key, field.MapIndex(key))
+			}
+		default:
+			if reflect.DeepEqual(
+				field.Interface(), reflect.Zero(field.Type()).Interface()) {
+				continue
+			}
+			merged.Field(i).Set(field)
+		}
+	}
+}
+
+// appendLabels returns the base labels followed by the override labels.
+// A base label is dropped if the override has a label with the same key.
+func appendLabels(base, override []*peloton.Label) []*peloton.Label {
+	overridden := make(map[string]bool)
+	for _, l := range override {
+		overridden[l.GetKey()] = true
+	}
+
+	result := make([]*peloton.Label, 0, len(base)+len(override))
+	for _, l := range base {
+		if !overridden[l.GetKey()] {
+			result = append(result, l)
+		}
+	}
+	return append(result, override...)
+}
+
+// appendPorts returns the base ports followed by the override ports.
+// A base port is dropped if the override has a port with the same name.
+func appendPorts(base, override []*task.PortConfig) []*task.PortConfig {
+	overridden := make(map[string]bool)
+	for _, p := range override {
+		overridden[p.GetName()] = true
+	}
+
+	result := make([]*task.PortConfig, 0, len(base)+len(override))
+	for _, p := range base {
+		if !overridden[p.GetName()] {
+			result = append(result, p)
+		}
+	}
+	return append(result, override...)
+}
+
+// appendVolumes returns the base volumes followed by the override volumes.
+// A base volume is dropped if the override mounts a volume at the same
+// container path.
+func appendVolumes(base, override []*mesosv1.Volume) []*mesosv1.Volume {
+	overridden := make(map[string]bool)
+	for _, v := range override {
+		overridden[v.GetContainerPath()] = true
+	}
+
+	result := make([]*mesosv1.Volume, 0, len(base)+len(override))
+	for _, v := range base {
+		if !overridden[v.GetContainerPath()] {
+			result = append(result, v)
+		}
+	}
+	return append(result, override...)
+}
+
 // retainBaseSecretsInInstanceConfig ensures that instance config retains all
 // secrets from default config. We store secrets as secret volumes at the
 // default config level for the job as part of container info.
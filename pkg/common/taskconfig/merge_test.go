@@ -18,6 +18,8 @@ import (
 	"testing"
 
 	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/pkg/common/util"
@@ -263,3 +265,151 @@ func TestMergeSpecOverrideGracePeriod(t *testing.T) {
 		&pod.PodSpec{KillGracePeriodSeconds: uint32(0)})
 	assert.Equal(t, uint32(20), cfg.GetKillGracePeriodSeconds())
 }
+
+// TestMergeWithStrategyAppend checks that labels, ports and volumes of
+// the instance config are appended to the default config ones when the
+// append merge mode is selected.
+func TestMergeWithStrategyAppend(t *testing.T) {
+	defaultConfig := &task.TaskConfig{
+		Labels: []*peloton.Label{
+			{Key: "k1", Value: "v1"},
+			{Key: "k2", Value: "v2"},
+		},
+		Ports: []*task.PortConfig{
+			{Name: "http", Value: 8080},
+		},
+		Container: &mesos_v1.ContainerInfo{
+			Volumes: []*mesos_v1.Volume{
+				{ContainerPath: util.PtrPrintf("/data")},
+			},
+		},
+	}
+	instanceConfig := &task.TaskConfig{
+		Labels: []*peloton.Label{
+			{Key: "k2", Value: "v2-override"},
+			{Key: "k3", Value: "v3"},
+		},
+		Ports: []*task.PortConfig{
+			{Name: "debug", Value: 9090},
+		},
+		Container: &mesos_v1.ContainerInfo{
+			Volumes: []*mesos_v1.Volume{
+				{ContainerPath: util.PtrPrintf("/logs")},
+			},
+		},
+	}
+	strategy := &job.InstanceConfigMergeStrategy{
+		Labels:  job.MergeMode_MERGE_MODE_APPEND,
+		Ports:   job.MergeMode_MERGE_MODE_APPEND,
+		Volumes: job.MergeMode_MERGE_MODE_APPEND,
+	}
+
+	merged := MergeWithStrategy(defaultConfig, instanceConfig, strategy)
+	assert.Equal(t, []*peloton.Label{
+		{Key: "k1", Value: "v1"},
+		{Key: "k2", Value: "v2-override"},
+		{Key: "k3", Value: "v3"},
+	}, merged.GetLabels())
+	assert.Equal(t, []*task.PortConfig{
+		{Name: "http", Value: 8080},
+		{Name: "debug", Value: 9090},
+	}, merged.GetPorts())
+	assert.Len(t, merged.GetContainer().GetVolumes(), 2)
+	assert.Equal(t, "/data",
+		merged.GetContainer().GetVolumes()[0].GetContainerPath())
+	assert.Equal(t, "/logs",
+		merged.GetContainer().GetVolumes()[1].GetContainerPath())
+
+	// the instance config must not be modified by the merge
+	assert.Len(t, instanceConfig.GetContainer().GetVolumes(), 1)
+
+	// without a strategy, instance values replace the default ones
+	merged = MergeWithStrategy(defaultConfig, instanceConfig, nil)
+	assert.Equal(t, instanceConfig.GetLabels(), merged.GetLabels())
+	assert.Equal(t, instanceConfig.GetPorts(), merged.GetPorts())
+}
+
+// TestMergeWithStrategyDeepMergeContainer checks that the instance
+// container info is merged field by field into the default one.
+func TestMergeWithStrategyDeepMergeContainer(t *testing.T) {
+	defaultConfig := &task.TaskConfig{
+		Container: &mesos_v1.ContainerInfo{
+			Type: mesos_v1.ContainerInfo_MESOS.Enum(),
+			Mesos: &mesos_v1.ContainerInfo_MesosInfo{
+				Image: &mesos_v1.Image{
+					Type: mesos_v1.Image_DOCKER.Enum(),
+					Docker: &mesos_v1.Image_Docker{
+						Name: util.PtrPrintf("image:1"),
+					},
+				},
+			},
+		},
+	}
+	instanceConfig := &task.TaskConfig{
+		Container: &mesos_v1.ContainerInfo{
+			Hostname: util.PtrPrintf("host"),
+		},
+	}
+	strategy := &job.InstanceConfigMergeStrategy{
+		DeepMergeContainer: true,
+	}
+
+	merged := MergeWithStrategy(defaultConfig, instanceConfig, strategy)
+	assert.Equal(t, "host", merged.GetContainer().GetHostname())
+	assert.Equal(t, mesos_v1.ContainerInfo_MESOS,
+		merged.GetContainer().GetType())
+	assert.Equal(t, "image:1",
+		merged.GetContainer().GetMesos().GetImage().GetDocker().GetName())
+
+	merged = Merge(defaultConfig, instanceConfig)
+	assert.Equal(t, instanceConfig.GetContainer(), merged.GetContainer())
+}
+
+// TestMergeWithStrategyDeepMergeContainerRepeated checks that the repeated
+// fields of the instance container info replace the default ones instead
+// of being appended to them.
+func TestMergeWithStrategyDeepMergeContainerRepeated(t *testing.T) {
+	newParameter := func(key, value string) *mesos_v1.Parameter {
+		return &mesos_v1.Parameter{Key: &key, Value: &value}
+	}
+	defaultConfig := &task.TaskConfig{
+		Container: &mesos_v1.ContainerInfo{
+			Type: mesos_v1.ContainerInfo_DOCKER.Enum(),
+			Docker: &mesos_v1.ContainerInfo_DockerInfo{
+				Image: util.PtrPrintf("image:1"),
+				Parameters: []*mesos_v1.Parameter{
+					newParameter("env", "A=1"),
+				},
+			},
+			NetworkInfos: []*mesos_v1.NetworkInfo{
+				{Name: util.PtrPrintf("network1")},
+			},
+		},
+	}
+	instanceConfig := &task.TaskConfig{
+		Container: &mesos_v1.ContainerInfo{
+			Docker: &mesos_v1.ContainerInfo_DockerInfo{
+				Parameters: []*mesos_v1.Parameter{
+					newParameter("env", "A=1"),
+					newParameter("env", "B=2"),
+				},
+			},
+			NetworkInfos: []*mesos_v1.NetworkInfo{
+				{Name: util.PtrPrintf("network1")},
+			},
+		},
+	}
+	strategy := &job.InstanceConfigMergeStrategy{
+		DeepMergeContainer: true,
+	}
+
+	merged := MergeWithStrategy(defaultConfig, instanceConfig, strategy)
+	assert.Equal(t, "image:1", merged.GetContainer().GetDocker().GetImage())
+	assert.Equal(t, instanceConfig.GetContainer().GetDocker().GetParameters(),
+		merged.GetContainer().GetDocker().GetParameters())
+	assert.Equal(t, instanceConfig.GetContainer().GetNetworkInfos(),
+		merged.GetContainer().GetNetworkInfos())
+
+	// the default config is not modified by the merge
+	assert.Len(t, defaultConfig.GetContainer().GetDocker().GetParameters(), 1)
+}
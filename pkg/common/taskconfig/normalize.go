@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"math"
+	"sort"
+
+	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+)
+
+// resourceQuantumsPerUnit is the number of quantums a resource unit is
+// split into when comparing quantities. Any difference smaller than a
+// milli-unit (e.g. 1.0 cpu vs 1000 milli-cpu stored as 0.99999999)
+// is not considered a change.
+const resourceQuantumsPerUnit = 1000
+
+// normalizeQuantity rounds a resource quantity to the nearest quantum.
+func normalizeQuantity(v float64) float64 {
+	return math.Round(v*resourceQuantumsPerUnit) / resourceQuantumsPerUnit
+}
+
+// normalizeResourceConfig rounds all the resource limits of a v0
+// resource config in place.
+func normalizeResourceConfig(r *task.ResourceConfig) {
+	if r == nil {
+		return
+	}
+
+	r.CpuLimit = normalizeQuantity(r.GetCpuLimit())
+	r.MemLimitMb = normalizeQuantity(r.GetMemLimitMb())
+	r.DiskLimitMb = normalizeQuantity(r.GetDiskLimitMb())
+	r.GpuLimit = normalizeQuantity(r.GetGpuLimit())
+}
+
+// normalizeResourceSpec rounds all the resource limits of a v1alpha
+// resource spec in place.
+func normalizeResourceSpec(r *pod.ResourceSpec) {
+	if r == nil {
+		return
+	}
+
+	r.CpuLimit = normalizeQuantity(r.GetCpuLimit())
+	r.MemLimitMb = normalizeQuantity(r.GetMemLimitMb())
+	r.DiskLimitMb = normalizeQuantity(r.GetDiskLimitMb())
+	r.GpuLimit = normalizeQuantity(r.GetGpuLimit())
+}
+
+// normalizeMesosCommand sorts the environment variables of a mesos
+// CommandInfo by name in place, since their order is not significant.
+func normalizeMesosCommand(c *mesosv1.CommandInfo) {
+	if c.GetEnvironment() == nil {
+		return
+	}
+
+	variables := c.GetEnvironment().GetVariables()
+	sort.SliceStable(variables, func(i, j int) bool {
+		return variables[i].GetName() < variables[j].GetName()
+	})
+}
+
+// normalizeMesosContainer sorts the volumes of a mesos ContainerInfo
+// in place, since their order is not significant.
+func normalizeMesosContainer(c *mesosv1.ContainerInfo) {
+	if c == nil {
+		return
+	}
+
+	volumes := c.GetVolumes()
+	sort.SliceStable(volumes, func(i, j int) bool {
+		if volumes[i].GetContainerPath() != volumes[j].GetContainerPath() {
+			return volumes[i].GetContainerPath() < volumes[j].GetContainerPath()
+		}
+		return volumes[i].GetHostPath() < volumes[j].GetHostPath()
+	})
+}
+
+// normalizeTaskConfig canonicalizes the resource limits, environment
+// variables and volumes of a v0 task config in place, so that equivalent
+// configs compare as equal. The config passed in must be a copy owned
+// by the caller.
+func normalizeTaskConfig(cfg *task.TaskConfig) {
+	normalizeResourceConfig(cfg.GetResource())
+	normalizeMesosCommand(cfg.GetCommand())
+	normalizeMesosContainer(cfg.GetContainer())
+}
+
+// normalizeContainerSpec canonicalizes the resource limits, environment
+// variables and volume mounts of a v1alpha container spec in place. The
+// spec passed in must be a copy owned by the caller.
+func normalizeContainerSpec(spec *pod.ContainerSpec) {
+	normalizeResourceSpec(spec.GetResource())
+	normalizeMesosCommand(spec.GetCommand())
+	normalizeMesosContainer(spec.GetContainer())
+
+	environment := spec.GetEnvironment()
+	sort.SliceStable(environment, func(i, j int) bool {
+		return environment[i].GetName() < environment[j].GetName()
+	})
+
+	mounts := spec.GetVolumeMounts()
+	sort.SliceStable(mounts, func(i, j int) bool {
+		if mounts[i].GetMountPath() != mounts[j].GetMountPath() {
+			return mounts[i].GetMountPath() < mounts[j].GetMountPath()
+		}
+		return mounts[i].GetName() < mounts[j].GetName()
+	})
+}
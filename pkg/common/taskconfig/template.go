@@ -0,0 +1,172 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+)
+
+const (
+	// TemplateInstanceID is expanded to the instance id of the task
+	TemplateInstanceID = "instance_id"
+	// TemplateJobID is expanded to the id of the job
+	TemplateJobID = "job_id"
+	// TemplatePortPrefix followed by a port name is expanded to the
+	// value of the port assigned to the task
+	TemplatePortPrefix = "port:"
+)
+
+// templatePattern matches placeholders of the form ${name}.
+var templatePattern = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// TemplateVars are the values placeholders are expanded to at
+// launch time.
+type TemplateVars struct {
+	JobID      string
+	InstanceID uint32
+	// Ports maps the port name to the port assigned to the task.
+	Ports map[string]uint32
+}
+
+// lookup returns the value of a placeholder, and false if the
+// placeholder is not known.
+func (v *TemplateVars) lookup(name string) (string, bool) {
+	switch {
+	case name == TemplateInstanceID:
+		return fmt.Sprint(v.InstanceID), true
+	case name == TemplateJobID:
+		return v.JobID, true
+	case strings.HasPrefix(name, TemplatePortPrefix):
+		port, ok := v.Ports[strings.TrimPrefix(name, TemplatePortPrefix)]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprint(port), true
+	}
+	return "", false
+}
+
+// expand replaces the known placeholders in s. Unknown placeholders
+// are left untouched, so that shell variables such as ${HOME} in a
+// command keep working.
+func (v *TemplateVars) expand(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return templatePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := v.lookup(match[2 : len(match)-1]); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// expandMesosCommand expands the placeholders in the value, arguments and
+// environment variables of a mesos CommandInfo in place.
+func (v *TemplateVars) expandMesosCommand(c *mesosv1.CommandInfo) {
+	if c == nil {
+		return
+	}
+
+	if c.Value != nil {
+		c.Value = proto.String(v.expand(c.GetValue()))
+	}
+	for i, arg := range c.GetArguments() {
+		c.Arguments[i] = v.expand(arg)
+	}
+	for _, variable := range c.GetEnvironment().GetVariables() {
+		if variable.Value != nil {
+			variable.Value = proto.String(v.expand(variable.GetValue()))
+		}
+	}
+}
+
+// ExpandTaskConfig returns a copy of the task config with the placeholders
+// in the command, environment variables and labels expanded. Static ports
+// of the config can be referred to in addition to the ones in vars.
+// The config passed in is not modified.
+func ExpandTaskConfig(cfg *task.TaskConfig, vars TemplateVars) *task.TaskConfig {
+	if cfg == nil {
+		return nil
+	}
+
+	ports := make(map[string]uint32)
+	for _, port := range cfg.GetPorts() {
+		if port.GetValue() != 0 {
+			ports[port.GetName()] = port.GetValue()
+		}
+	}
+	for name, port := range vars.Ports {
+		ports[name] = port
+	}
+	vars.Ports = ports
+
+	expanded := proto.Clone(cfg).(*task.TaskConfig)
+	vars.expandMesosCommand(expanded.GetCommand())
+	for _, label := range expanded.GetLabels() {
+		label.Value = vars.expand(label.GetValue())
+	}
+	return expanded
+}
+
+// ExpandPodSpec returns a copy of the pod spec with the placeholders
+// in the entrypoint, command, environment variables and labels expanded.
+// The spec passed in is not modified.
+func ExpandPodSpec(spec *pod.PodSpec, vars TemplateVars) *pod.PodSpec {
+	if spec == nil {
+		return nil
+	}
+
+	ports := make(map[string]uint32)
+	for _, container := range spec.GetContainers() {
+		for _, port := range container.GetPorts() {
+			if port.GetValue() != 0 {
+				ports[port.GetName()] = port.GetValue()
+			}
+		}
+	}
+	for name, port := range vars.Ports {
+		ports[name] = port
+	}
+	vars.Ports = ports
+
+	expanded := proto.Clone(spec).(*pod.PodSpec)
+	var containers []*pod.ContainerSpec
+	containers = append(containers, expanded.GetInitContainers()...)
+	containers = append(containers, expanded.GetContainers()...)
+	for _, container := range containers {
+		vars.expandMesosCommand(container.GetCommand())
+		if entrypoint := container.GetEntrypoint(); entrypoint != nil {
+			entrypoint.Value = vars.expand(entrypoint.GetValue())
+			for i, arg := range entrypoint.GetArguments() {
+				entrypoint.Arguments[i] = vars.expand(arg)
+			}
+		}
+		for _, env := range container.GetEnvironment() {
+			env.Value = vars.expand(env.GetValue())
+		}
+	}
+	for _, label := range expanded.GetLabels() {
+		label.Value = vars.expand(label.GetValue())
+	}
+	return expanded
+}
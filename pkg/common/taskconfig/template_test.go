@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskconfig
+
+import (
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testTemplateVars = TemplateVars{
+	JobID:      "job-1",
+	InstanceID: 7,
+	Ports:      map[string]uint32{"http": 31000},
+}
+
+func TestExpandTaskConfig(t *testing.T) {
+	cfg := &task.TaskConfig{
+		Command: &mesos_v1.CommandInfo{
+			Value: util.PtrPrintf("run --shard=${instance_id} --home=${HOME}"),
+			Arguments: []string{
+				"--port=${port:http}",
+				"--admin=${port:admin}",
+				"--missing=${port:missing}",
+			},
+			Environment: &mesos_v1.Environment{
+				Variables: []*mesos_v1.Environment_Variable{
+					{
+						Name:  util.PtrPrintf("JOB"),
+						Value: util.PtrPrintf("${job_id}-${instance_id}"),
+					},
+				},
+			},
+		},
+		Labels: []*peloton.Label{
+			{Key: "shard", Value: "${instance_id}"},
+		},
+		Ports: []*task.PortConfig{
+			{Name: "http"},
+			{Name: "admin", Value: 8080},
+		},
+	}
+
+	expanded := ExpandTaskConfig(cfg, testTemplateVars)
+	assert.Equal(t, "run --shard=7 --home=${HOME}", expanded.GetCommand().GetValue())
+	assert.Equal(t, []string{
+		"--port=31000",
+		"--admin=8080",
+		"--missing=${port:missing}",
+	}, expanded.GetCommand().GetArguments())
+	assert.Equal(t, "job-1-7",
+		expanded.GetCommand().GetEnvironment().GetVariables()[0].GetValue())
+	assert.Equal(t, "7", expanded.GetLabels()[0].GetValue())
+
+	// the original config is not modified
+	assert.Equal(t, "${instance_id}", cfg.GetLabels()[0].GetValue())
+	assert.Equal(t, "--port=${port:http}", cfg.GetCommand().GetArguments()[0])
+
+	assert.Nil(t, ExpandTaskConfig(nil, testTemplateVars))
+}
+
+func TestExpandPodSpec(t *testing.T) {
+	spec := &pod.PodSpec{
+		Labels: []*v1alphapeloton.Label{
+			{Key: "shard", Value: "${instance_id}"},
+		},
+		InitContainers: []*pod.ContainerSpec{
+			{
+				Entrypoint: &pod.CommandSpec{
+					Value: "init ${job_id}",
+				},
+			},
+		},
+		Containers: []*pod.ContainerSpec{
+			{
+				Entrypoint: &pod.CommandSpec{
+					Value:     "run",
+					Arguments: []string{"--port=${port:http}"},
+				},
+				Environment: []*pod.Environment{
+					{Name: "SHARD", Value: "${instance_id}"},
+				},
+				Ports: []*pod.PortSpec{
+					{Name: "http"},
+					{Name: "admin", Value: 8080},
+				},
+			},
+		},
+	}
+
+	expanded := ExpandPodSpec(spec, testTemplateVars)
+	assert.Equal(t, "7", expanded.GetLabels()[0].GetValue())
+	assert.Equal(t, "init job-1",
+		expanded.GetInitContainers()[0].GetEntrypoint().GetValue())
+	assert.Equal(t, []string{"--port=31000"},
+		expanded.GetContainers()[0].GetEntrypoint().GetArguments())
+	assert.Equal(t, "7",
+		expanded.GetContainers()[0].GetEnvironment()[0].GetValue())
+
+	// the original spec is not modified
+	assert.Equal(t, "${instance_id}",
+		spec.GetContainers()[0].GetEnvironment()[0].GetValue())
+
+	assert.Nil(t, ExpandPodSpec(nil, testTemplateVars))
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 )
@@ -78,6 +79,7 @@ func ConvertTaskToResMgrTask(
 	}
 
 	slaConfig := jobConfig.GetSLA()
+	preemptionPolicy := jobConfig.GetPreemptionPolicy()
 	// If minInstances > 1, instances w/instanceID between 0..minInstances-1 should be gang-scheduled;
 	// only pass MinInstances value > 1 for those tasks.
 	minInstances := slaConfig.GetMinimumRunningInstances()
@@ -102,26 +104,46 @@ func ConvertTaskToResMgrTask(
 		preemptible = false
 	default:
 		// default to the policy at job level
-		preemptible = slaConfig.GetPreemptible()
+		switch preemptionPolicy.GetType() {
+		case task.PreemptionPolicy_TYPE_PREEMPTIBLE:
+			preemptible = true
+		case task.PreemptionPolicy_TYPE_NON_PREEMPTIBLE:
+			preemptible = false
+		default:
+			preemptible = slaConfig.GetPreemptible()
+		}
 	}
 
+	// A task named as the controller task of the job is a controller task
+	// even if its controller flag is not set.
+	isController := taskconfig.IsControllerTask(
+		taskInfo.GetConfig(), jobConfig.GetControllerTaskName())
+
 	resmgrTask := &resmgr.Task{
-		Id:                taskID,
-		JobId:             taskInfo.GetJobId(),
-		TaskId:            taskInfo.GetRuntime().GetMesosTaskId(),
-		Name:              taskInfo.GetConfig().GetName(),
-		Preemptible:       preemptible,
-		Priority:          slaConfig.GetPriority(),
-		MinInstances:      minInstances,
-		Resource:          taskInfo.GetConfig().GetResource(),
-		Constraint:        taskInfo.GetConfig().GetConstraint(),
-		NumPorts:          uint32(numPorts),
-		Type:              getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
-		Labels:            util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
-		Controller:        taskInfo.GetConfig().GetController(),
-		Revocable:         taskInfo.GetConfig().GetRevocable(),
-		DesiredHost:       taskInfo.GetRuntime().GetDesiredHost(),
-		PlacementStrategy: jobConfig.GetPlacementStrategy(),
+		Id:                  taskID,
+		JobId:               taskInfo.GetJobId(),
+		TaskId:              taskInfo.GetRuntime().GetMesosTaskId(),
+		Name:                taskInfo.GetConfig().GetName(),
+		Preemptible:         preemptible,
+		Priority:            slaConfig.GetPriority(),
+		MinInstances:        minInstances,
+		Resource:            taskInfo.GetConfig().GetResource(),
+		Constraint:          taskInfo.GetConfig().GetConstraint(),
+		NumPorts:            uint32(numPorts),
+		Type:                getTaskType(taskInfo.GetConfig(), jobConfig.GetType()),
+		Labels:              util.ConvertLabels(taskInfo.GetConfig().GetLabels()),
+		Controller:          isController,
+		Revocable:           taskInfo.GetConfig().GetRevocable(),
+		DesiredHost:         taskInfo.GetRuntime().GetDesiredHost(),
+		PlacementStrategy:   jobConfig.GetPlacementStrategy(),
+		Owner:               jobConfig.GetOwner(),
+		LaunchCorrelationId: taskInfo.GetRuntime().GetLaunchCorrelationId(),
+
+		PlacementRelaxationPolicy: jobConfig.GetPlacementRelaxationPolicy(),
+		PreemptionTier:            preemptionPolicy.GetTier(),
+		MinRunningSecs:            preemptionPolicy.GetMinRunningSecs(),
+		Volume:                    taskInfo.GetConfig().GetVolume(),
+		PlacementDeadline:         slaConfig.GetPlacementDeadline(),
 	}
 
 	taskState := taskInfo.GetRuntime().GetState()
@@ -116,10 +116,17 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 			JobId:      &jobID,
 			Config: &task.TaskConfig{
 				Ports: []*task.PortConfig{{Name: "http", Value: 0}},
+				Volume: &task.PersistentVolumeConfig{
+					ContainerPath: "/data",
+					SizeMB:        1024,
+					DiskType:      "SSD",
+					Mount:         true,
+				},
 			},
 			Runtime: &task.RuntimeInfo{
-				State: task.TaskState_SUCCEEDED,
-				Host:  "hostname",
+				State:               task.TaskState_SUCCEEDED,
+				Host:                "hostname",
+				LaunchCorrelationId: "launch-correlation-id",
 			},
 		},
 	}
@@ -127,6 +134,15 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 	jobConfig := &job.JobConfig{
 		SLA:               &job.SlaConfig{},
 		PlacementStrategy: job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
+		Owner:             "owner",
+		PlacementRelaxationPolicy: &job.PlacementRelaxationPolicy{
+			Steps: []*job.PlacementRelaxationStep{
+				{
+					WaitSecs:   60,
+					Preference: job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+				},
+			},
+		},
 	}
 	for _, taskInfo := range taskInfos {
 		rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig)
@@ -144,6 +160,16 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 			t,
 			job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
 			rmTask.GetPlacementStrategy())
+		assert.Equal(t, "owner", rmTask.GetOwner())
+		assert.Equal(
+			t,
+			jobConfig.GetPlacementRelaxationPolicy(),
+			rmTask.GetPlacementRelaxationPolicy())
+		assert.Equal(
+			t,
+			taskInfo.GetRuntime().GetLaunchCorrelationId(),
+			rmTask.GetLaunchCorrelationId())
+		assert.Equal(t, taskInfo.GetConfig().GetVolume(), rmTask.GetVolume())
 	}
 }
 
@@ -264,6 +290,36 @@ func TestConvertTaskToResMgrTaskPreemptible(t *testing.T) {
 			},
 			preemptible: false,
 		},
+		{
+			name: "job policy override should use job policy value: false",
+			taskInfo: &task.TaskInfo{
+				Config: &task.TaskConfig{},
+			},
+			jobConfig: &job.JobConfig{
+				SLA: &job.SlaConfig{Preemptible: true},
+				PreemptionPolicy: &job.PreemptionPolicy{
+					Type: task.PreemptionPolicy_TYPE_NON_PREEMPTIBLE,
+				},
+			},
+			preemptible: false,
+		},
+		{
+			name: "task override should take precedence over job policy: true",
+			taskInfo: &task.TaskInfo{
+				Config: &task.TaskConfig{
+					PreemptionPolicy: &task.PreemptionPolicy{
+						Type: task.PreemptionPolicy_TYPE_PREEMPTIBLE,
+					},
+				},
+			},
+			jobConfig: &job.JobConfig{
+				SLA: &job.SlaConfig{Preemptible: false},
+				PreemptionPolicy: &job.PreemptionPolicy{
+					Type: task.PreemptionPolicy_TYPE_NON_PREEMPTIBLE,
+				},
+			},
+			preemptible: true,
+		},
 	}
 
 	for _, test := range tt {
@@ -271,3 +327,56 @@ func TestConvertTaskToResMgrTaskPreemptible(t *testing.T) {
 		assert.Equal(t, test.preemptible, r.Preemptible, test.name)
 	}
 }
+
+// TestConvertTaskToResMgrTaskController tests that a task named as the
+// controller task of its job is converted to a controller resmgr task
+func TestConvertTaskToResMgrTaskController(t *testing.T) {
+	tt := []struct {
+		name       string
+		taskConfig *task.TaskConfig
+		jobConfig  *job.JobConfig
+		controller bool
+	}{
+		{
+			name:       "controller flag set",
+			taskConfig: &task.TaskConfig{Controller: true},
+			jobConfig:  &job.JobConfig{},
+			controller: true,
+		},
+		{
+			name:       "named controller task without the flag",
+			taskConfig: &task.TaskConfig{Name: "controller"},
+			jobConfig:  &job.JobConfig{ControllerTaskName: "controller"},
+			controller: true,
+		},
+		{
+			name:       "task not named as the controller task",
+			taskConfig: &task.TaskConfig{Name: "worker", Controller: true},
+			jobConfig:  &job.JobConfig{ControllerTaskName: "controller"},
+			controller: false,
+		},
+	}
+
+	for _, test := range tt {
+		r := ConvertTaskToResMgrTask(
+			&task.TaskInfo{Config: test.taskConfig}, test.jobConfig)
+		assert.Equal(t, test.controller, r.GetController(), test.name)
+	}
+}
+
+// TestConvertTaskToResMgrTaskPreemptionPolicy tests that the preemption
+// tier and minimum running time of the job are passed to the resmgr task
+func TestConvertTaskToResMgrTaskPreemptionPolicy(t *testing.T) {
+	r := ConvertTaskToResMgrTask(
+		&task.TaskInfo{Config: &task.TaskConfig{}},
+		&job.JobConfig{
+			SLA: &job.SlaConfig{Preemptible: true},
+			PreemptionPolicy: &job.PreemptionPolicy{
+				Tier:           2,
+				MinRunningSecs: 600,
+			},
+		})
+	assert.True(t, r.GetPreemptible())
+	assert.Equal(t, uint32(2), r.GetPreemptionTier())
+	assert.Equal(t, uint32(600), r.GetMinRunningSecs())
+}
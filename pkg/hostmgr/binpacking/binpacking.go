@@ -30,6 +30,17 @@ const (
 
 	// LoadAware is the name of the Load Aware policy
 	LoadAware = "LOAD_AWARE"
+
+	// Spread is the name of the policy spreading the load across hosts
+	Spread = "SPREAD"
+
+	// BinPackMemory is the name of the policy bin packing hosts by memory.
+	// Hosts are bin packed by CPU with the DeFrag policy.
+	BinPackMemory = "BIN_PACK_MEMORY"
+
+	// Custom is the name of the policy ranking hosts with the custom
+	// scorer registered by RegisterScorer
+	Custom = "CUSTOM"
 )
 
 // map of ranker name to Ranker. Not thread-safe -> should be
@@ -67,6 +78,8 @@ func Init(
 	metrics *metrics.Metrics) {
 	register(DeFrag, NewDeFragRanker)
 	register(FirstFit, NewFirstFitRanker)
+	register(Spread, NewSpreadRanker)
+	register(BinPackMemory, NewBinPackMemoryRanker)
 
 	// if QosAdivsorService discovery address is not set
 	if cqosClient == nil {
@@ -80,6 +93,19 @@ func Init(
 	rankers[LoadAware] = NewLoadAwareRanker(cqosClient, metrics)
 }
 
+// RegisterScorer registers a ranker with specified name, which ranks the
+// hosts by the scores of their offered resources. Like Init, it should be
+// called at initialization only.
+func RegisterScorer(name string, score ScoreFunc) {
+	if score == nil {
+		log.WithField("name", name).Error("invalid scorer function")
+		return
+	}
+	register(name, func() Ranker {
+		return NewScoreRanker(name, score)
+	})
+}
+
 // GetRankerByName returns a ranker with specified name
 func GetRankerByName(name string) Ranker {
 	return rankers[name]
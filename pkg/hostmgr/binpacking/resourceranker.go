@@ -0,0 +1,203 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binpacking
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/pkg/common/sorter"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+	"github.com/uber/peloton/pkg/hostmgr/util"
+)
+
+// ScoreFunc returns the score of a host based on the resources it offers.
+// Hosts with lower scores are ranked first.
+type ScoreFunc func(offered scalar.Resources) float64
+
+// ResourceWeights are the weights of the offered resources of a host
+// which a weighted score is computed with. Positive weights pack the
+// hosts on the resources, while negative weights spread them.
+type ResourceWeights struct {
+	CPU  float64 `yaml:"cpu"`
+	Mem  float64 `yaml:"mem"`
+	Disk float64 `yaml:"disk"`
+	GPU  float64 `yaml:"gpu"`
+}
+
+// WeightedScore returns the ScoreFunc which scores a host with the sum
+// of its offered resources multiplied by their weights.
+func WeightedScore(weights ResourceWeights) ScoreFunc {
+	return func(offered scalar.Resources) float64 {
+		return weights.CPU*offered.CPU +
+			weights.Mem*offered.Mem +
+			weights.Disk*offered.Disk +
+			weights.GPU*offered.GPU
+	}
+}
+
+// resourceRanker is the struct for implementation of the rankers
+// ordering the hosts on the resources they offer.
+type resourceRanker struct {
+	mu          sync.RWMutex
+	name        string
+	sort        func(summaryList []interface{})
+	summaryList []interface{}
+}
+
+// NewSpreadRanker returns the Spread Ranker, which ranks the hosts with
+// the most offered resources first to spread the load across the hosts.
+// GPU hosts are still ranked last so they are kept for GPU workloads.
+func NewSpreadRanker() Ranker {
+	return &resourceRanker{
+		name: Spread,
+		sort: func(summaryList []interface{}) {
+			sorter.OrderedBy(
+				lessGPU,
+				reverse(lessCPU),
+				reverse(lessMem),
+				reverse(lessDisk),
+			).Sort(summaryList)
+		},
+	}
+}
+
+// NewBinPackMemoryRanker returns the Bin Pack Memory Ranker, which ranks
+// the hosts with the least offered memory first.
+func NewBinPackMemoryRanker() Ranker {
+	return &resourceRanker{
+		name: BinPackMemory,
+		sort: func(summaryList []interface{}) {
+			sorter.OrderedBy(
+				lessGPU,
+				lessMem,
+				lessCPU,
+				lessDisk,
+			).Sort(summaryList)
+		},
+	}
+}
+
+// NewScoreRanker returns a ranker which ranks the hosts in ascending order
+// of the score of their offered resources.
+func NewScoreRanker(name string, score ScoreFunc) Ranker {
+	return &resourceRanker{
+		name: name,
+		sort: func(summaryList []interface{}) {
+			scores := make([]float64, len(summaryList))
+			for i, s := range summaryList {
+				scores[i] = score(offeredResources(s))
+			}
+			sort.Stable(&scoredList{
+				summaryList: summaryList,
+				scores:      scores,
+			})
+		},
+	}
+}
+
+// Name is the implementation for Ranker interface.Name method
+// returns the name
+func (r *resourceRanker) Name() string {
+	return r.name
+}
+
+// GetRankedHostList returns the ranked host list.
+// This checks if there is already a list present pass that
+// and it depends on RefreshRanking to refresh the list
+func (r *resourceRanker) GetRankedHostList(
+	ctx context.Context,
+	offerIndex map[string]summary.HostSummary) []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.summaryList) == 0 {
+		r.summaryList = r.getRankedHostList(offerIndex)
+	}
+	return r.summaryList
+}
+
+// RefreshRanking refreshes the hostlist based on new host summary index
+// This function has to be called periodically to refresh the list
+func (r *resourceRanker) RefreshRanking(
+	ctx context.Context,
+	offerIndex map[string]summary.HostSummary) {
+	summaryList := r.getRankedHostList(offerIndex)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summaryList = summaryList
+}
+
+// getRankedHostList is the unprotected method for sorting the offer index.
+func (r *resourceRanker) getRankedHostList(
+	offerIndex map[string]summary.HostSummary) []interface{} {
+	var summaryList []interface{}
+	for _, summary := range offerIndex {
+		summaryList = append(summaryList, summary)
+	}
+	r.sort(summaryList)
+	return summaryList
+}
+
+// scoredList sorts a list of host summaries by their scores.
+type scoredList struct {
+	summaryList []interface{}
+	scores      []float64
+}
+
+func (l *scoredList) Len() int {
+	return len(l.summaryList)
+}
+
+func (l *scoredList) Less(i, j int) bool {
+	return l.scores[i] < l.scores[j]
+}
+
+func (l *scoredList) Swap(i, j int) {
+	l.summaryList[i], l.summaryList[j] = l.summaryList[j], l.summaryList[i]
+	l.scores[i], l.scores[j] = l.scores[j], l.scores[i]
+}
+
+// offeredResources returns the resources offered by a host summary.
+func offeredResources(s interface{}) scalar.Resources {
+	return util.GetResourcesFromOffers(
+		s.(summary.HostSummary).GetOffers(summary.All))
+}
+
+func lessGPU(c1, c2 interface{}) bool {
+	return offeredResources(c1).GPU < offeredResources(c2).GPU
+}
+
+func lessCPU(c1, c2 interface{}) bool {
+	return offeredResources(c1).CPU < offeredResources(c2).CPU
+}
+
+func lessMem(c1, c2 interface{}) bool {
+	return offeredResources(c1).Mem < offeredResources(c2).Mem
+}
+
+func lessDisk(c1, c2 interface{}) bool {
+	return offeredResources(c1).Disk < offeredResources(c2).Disk
+}
+
+// reverse returns the less function ordering in the reverse order.
+func reverse(
+	less func(c1, c2 interface{}) bool,
+) func(c1, c2 interface{}) bool {
+	return func(c1, c2 interface{}) bool {
+		return less(c2, c1)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binpacking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/summary"
+	watchmocks "github.com/uber/peloton/pkg/hostmgr/watchevent/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// createResourceOfferIndex creates an offer index with a CPU heavy host and
+// a memory heavy host.
+func createResourceOfferIndex(
+	t *testing.T,
+) map[string]summary.HostSummary {
+	ctrl := gomock.NewController(t)
+	processor := watchmocks.NewMockWatchProcessor(ctrl)
+
+	offerIndex := make(map[string]summary.HostSummary)
+	for hostname, res := range map[string]scalar.Resources{
+		"cpu-host": {CPU: 4, Mem: 1, Disk: 1},
+		"mem-host": {CPU: 1, Mem: 4, Disk: 1},
+	} {
+		s := summary.New(nil, hostname, nil, 30*time.Second, processor)
+		s.AddMesosOffers(
+			context.Background(),
+			[]*mesos.Offer{CreateOffer(hostname, res)},
+		)
+		offerIndex[hostname] = s
+	}
+	return offerIndex
+}
+
+// rankedHostnames returns the hostnames of the ranked host list.
+func rankedHostnames(summaryList []interface{}) []string {
+	var hostnames []string
+	for _, s := range summaryList {
+		hostnames = append(hostnames, s.(summary.HostSummary).GetHostname())
+	}
+	return hostnames
+}
+
+func TestResourceRankers(t *testing.T) {
+	testTable := map[string]struct {
+		ranker   Ranker
+		name     string
+		expected []string
+	}{
+		"spread": {
+			ranker:   NewSpreadRanker(),
+			name:     Spread,
+			expected: []string{"cpu-host", "mem-host"},
+		},
+		"bin-pack-memory": {
+			ranker:   NewBinPackMemoryRanker(),
+			name:     BinPackMemory,
+			expected: []string{"cpu-host", "mem-host"},
+		},
+		"score-cpu": {
+			ranker:   NewScoreRanker(Custom, WeightedScore(ResourceWeights{CPU: 1})),
+			name:     Custom,
+			expected: []string{"mem-host", "cpu-host"},
+		},
+		"score-negative-mem": {
+			ranker:   NewScoreRanker(Custom, WeightedScore(ResourceWeights{Mem: -1})),
+			name:     Custom,
+			expected: []string{"mem-host", "cpu-host"},
+		},
+	}
+
+	for ttName, tt := range testTable {
+		offerIndex := createResourceOfferIndex(t)
+		require.Equal(t, tt.name, tt.ranker.Name(), ttName)
+		require.Equal(t,
+			tt.expected,
+			rankedHostnames(tt.ranker.GetRankedHostList(context.Background(), offerIndex)),
+			ttName)
+	}
+}
+
+func TestResourceRankerRefresh(t *testing.T) {
+	ranker := NewSpreadRanker()
+	offerIndex := createResourceOfferIndex(t)
+	require.Len(t, ranker.GetRankedHostList(context.Background(), offerIndex), 2)
+
+	// The ranked list is only updated on refresh.
+	offerIndex["new-host"] = summary.New(
+		nil, "new-host", nil, 30*time.Second, nil)
+	offerIndex["new-host"].AddMesosOffers(
+		context.Background(),
+		[]*mesos.Offer{CreateOffer("new-host", scalar.Resources{CPU: 8, Mem: 8})},
+	)
+	require.Len(t, ranker.GetRankedHostList(context.Background(), offerIndex), 2)
+
+	ranker.RefreshRanking(context.Background(), offerIndex)
+	require.Equal(t,
+		[]string{"new-host", "cpu-host", "mem-host"},
+		rankedHostnames(ranker.GetRankedHostList(context.Background(), offerIndex)))
+}
+
+func TestRegisterScorer(t *testing.T) {
+	defer CleanUpRanker()
+
+	RegisterScorer(Custom, nil)
+	require.Nil(t, GetRankerByName(Custom))
+
+	RegisterScorer(Custom, WeightedScore(ResourceWeights{CPU: 1}))
+	require.Equal(t, Custom, GetRankerByName(Custom).Name())
+}
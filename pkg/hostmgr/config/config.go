@@ -17,7 +17,9 @@ package config
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/hostmgr/binpacking"
 	"github.com/uber/peloton/pkg/hostmgr/goalstate"
+	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
 	"github.com/uber/peloton/pkg/hostmgr/reconcile"
 	"github.com/uber/peloton/pkg/hostmgr/watchevent"
 )
@@ -48,6 +50,12 @@ type Config struct {
 	// Size of the channel buffer of the status updates
 	TaskUpdateBufferSize int `yaml:"taskupdate_buffer_size"`
 
+	// Names of the clients consuming the stream of the status updates in
+	// addition to Job Manager and Resource Manager, like the archiver or
+	// auditors. The status updates are only purged from the stream, and
+	// acknowledged, once every client has processed them.
+	TaskUpdateStreamClients []string `yaml:"taskupdate_stream_clients"`
+
 	TaskReconcilerConfig *reconcile.TaskReconcilerConfig `yaml:"task_reconciler"`
 
 	HostmapRefreshInterval time.Duration `yaml:"hostmap_refresh_interval"`
@@ -78,6 +86,10 @@ type Config struct {
 
 	// Bin Packing tasks in hosts as much as possible
 	BinPacking string `yaml:"bin_packing"`
+
+	// Weights of the resources offered by the hosts, which the CUSTOM
+	// ranker scores the hosts with. The ranker is only registered if set.
+	CustomRankerWeights *binpacking.ResourceWeights `yaml:"custom_ranker_weights"`
 	// Bin Packing Refresh Interval
 	BinPackingRefreshIntervalSec time.Duration `yaml:"bin_packing_refresh_interval"`
 
@@ -96,4 +108,18 @@ type Config struct {
 
 	// GoalState configuration
 	GoalState goalstate.Config `yaml:"goal_state"`
+
+	// EnableTaskMetadata is the config switch to issue metadata tokens to
+	// the tasks launched by Host Manager, and serve the metadata of a task
+	// to the holder of its token.
+	EnableTaskMetadata bool `yaml:"enable_task_metadata"`
+
+	// Interval at which the config files are checked for changes, which
+	// are reloaded to apply the fields safe to change at runtime. Zero
+	// disables watching the config files.
+	ConfigReloadInterval time.Duration `yaml:"config_reload_interval"`
+
+	// Config to quarantine flapping hosts, whose offers are rescinded
+	// repeatedly, from placement.
+	HostQuarantine offerpool.QuarantineConfig `yaml:"host_quarantine"`
 }
@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReloadPath is the endpoint reloading the config of Host Manager and
+// applying the fields which are safe to change at runtime.
+const ReloadPath = "/config/reload"
+
+// ApplyFunc applies the new value of a config field at runtime. It
+// returns an error if the value is invalid, in which case the field keeps
+// its current value.
+type ApplyFunc func(cfg *Config) error
+
+// ReloadResult reports the config fields changed by a reload, keyed by
+// their yaml name.
+type ReloadResult struct {
+	// Fields applied at runtime
+	Applied []string `json:"applied"`
+	// Fields which require a restart of Host Manager to be applied
+	Ignored []string `json:"ignored"`
+	// Fields which failed to be applied, with the reason of the failure
+	Failed map[string]string `json:"failed"`
+}
+
+// Reloader reloads the config of Host Manager, and applies the changed
+// fields which have an ApplyFunc registered.
+type Reloader struct {
+	sync.Mutex
+
+	// Config currently in effect
+	current Config
+	// Loads the config from the config files
+	load func() (Config, error)
+	// Map from the yaml name of a field to the function applying it
+	appliers map[string]ApplyFunc
+}
+
+// NewReloader returns a Reloader of the given config in effect, which
+// reloads the config with load.
+func NewReloader(current Config, load func() (Config, error)) *Reloader {
+	return &Reloader{
+		current:  current,
+		load:     load,
+		appliers: make(map[string]ApplyFunc),
+	}
+}
+
+// Register registers the function applying the field with the given yaml
+// name at runtime.
+func (r *Reloader) Register(field string, apply ApplyFunc) {
+	r.Lock()
+	defer r.Unlock()
+	r.appliers[field] = apply
+}
+
+// Reload loads the config and applies the changed fields which can be
+// applied at runtime. Ignored and failed fields are reported again by the
+// next reload until they are reverted.
+func (r *Reloader) Reload() (*ReloadResult, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	cfg, err := r.load()
+	if err != nil {
+		log.WithError(err).Warn("Failed to reload host manager config")
+		return nil, err
+	}
+
+	result := &ReloadResult{
+		Applied: []string{},
+		Ignored: []string{},
+		Failed:  make(map[string]string),
+	}
+	current := reflect.ValueOf(&r.current).Elem()
+	next := reflect.ValueOf(&cfg).Elem()
+	for i := 0; i < current.NumField(); i++ {
+		if reflect.DeepEqual(
+			current.Field(i).Interface(),
+			next.Field(i).Interface()) {
+			continue
+		}
+
+		field := yamlName(current.Type().Field(i))
+		apply, ok := r.appliers[field]
+		if !ok {
+			result.Ignored = append(result.Ignored, field)
+			continue
+		}
+		if err := apply(&cfg); err != nil {
+			result.Failed[field] = err.Error()
+			continue
+		}
+		current.Field(i).Set(next.Field(i))
+		result.Applied = append(result.Applied, field)
+	}
+	sort.Strings(result.Applied)
+	sort.Strings(result.Ignored)
+
+	log.WithFields(log.Fields{
+		"applied": result.Applied,
+		"ignored": result.Ignored,
+		"failed":  result.Failed,
+	}).Info("Reloaded host manager config")
+	return result, nil
+}
+
+// ReloadHandler reloads the config on POST requests, and reports the
+// changed fields as a JSON object.
+func (r *Reloader) ReloadHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := r.Reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// WatchFiles reloads the config whenever the modification time of one of
+// the config files changes, checking them at the given interval until
+// stopCh is closed.
+func (r *Reloader) WatchFiles(
+	files []string,
+	interval time.Duration,
+	stopCh <-chan struct{},
+) {
+	modTimes := getModTimes(files)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			latest := getModTimes(files)
+			if reflect.DeepEqual(modTimes, latest) {
+				continue
+			}
+			modTimes = latest
+			r.Reload()
+		}
+	}
+}
+
+// getModTimes returns the modification time of each of the files which
+// exist.
+func getModTimes(files []string) map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes
+}
+
+// yamlName returns the name of the field in the yaml config.
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReloader returns a Reloader applying offer_hold_time_sec, which
+// reloads the config pointed to by next.
+func newTestReloader(next *Config, applied *int) *Reloader {
+	r := NewReloader(
+		Config{OfferHoldTimeSec: 60, HTTPPort: 5291},
+		func() (Config, error) {
+			if next == nil {
+				return Config{}, errors.New("failed to parse")
+			}
+			return *next, nil
+		},
+	)
+	r.Register("offer_hold_time_sec", func(cfg *Config) error {
+		if cfg.OfferHoldTimeSec <= 0 {
+			return errors.New("offer hold time must be positive")
+		}
+		*applied = cfg.OfferHoldTimeSec
+		return nil
+	})
+	return r
+}
+
+func TestReload(t *testing.T) {
+	var applied int
+	next := &Config{OfferHoldTimeSec: 120, HTTPPort: 5292}
+	r := newTestReloader(next, &applied)
+
+	result, err := r.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"offer_hold_time_sec"}, result.Applied)
+	assert.Equal(t, []string{"http_port"}, result.Ignored)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, 120, applied)
+
+	// Applied fields are not reported again, unlike ignored ones.
+	result, err = r.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, result.Applied)
+	assert.Equal(t, []string{"http_port"}, result.Ignored)
+
+	// Invalid values are not applied.
+	next.OfferHoldTimeSec = 0
+	result, err = r.Reload()
+	require.NoError(t, err)
+	assert.Empty(t, result.Applied)
+	assert.Contains(t, result.Failed, "offer_hold_time_sec")
+	assert.Equal(t, 120, applied)
+}
+
+func TestReloadLoadError(t *testing.T) {
+	var applied int
+	r := newTestReloader(nil, &applied)
+
+	_, err := r.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, 0, applied)
+}
+
+func TestReloadHandler(t *testing.T) {
+	var applied int
+	r := newTestReloader(&Config{OfferHoldTimeSec: 120}, &applied)
+
+	rec := httptest.NewRecorder()
+	r.ReloadHandler(rec, httptest.NewRequest(http.MethodGet, ReloadPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, 0, applied)
+
+	rec = httptest.NewRecorder()
+	r.ReloadHandler(rec, httptest.NewRequest(http.MethodPost, ReloadPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	result := &ReloadResult{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), result))
+	assert.Equal(t, []string{"offer_hold_time_sec"}, result.Applied)
+	assert.Equal(t, []string{"http_port"}, result.Ignored)
+	assert.Equal(t, 120, applied)
+}
+
+func TestWatchFiles(t *testing.T) {
+	file, err := ioutil.TempFile("", "hostmgr-config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	file.Close()
+
+	reloaded := make(chan struct{}, 1)
+	r := NewReloader(Config{}, func() (Config, error) {
+		reloaded <- struct{}{}
+		return Config{}, nil
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go r.WatchFiles([]string{file.Name()}, 10*time.Millisecond, stopCh)
+
+	// Wait for the watcher to record the initial modification time.
+	time.Sleep(50 * time.Millisecond)
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(file.Name(), modTime, modTime))
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		assert.Fail(t, "config was not reloaded")
+	}
+}
@@ -41,6 +41,12 @@ const (
 	PelotonInstanceIDLabelKey = "peloton.instance_id"
 	// PelotonTaskIDLabelKey is the task label key for task ID
 	PelotonTaskIDLabelKey = "peloton.task_id"
+	// PelotonInstanceNameLabelKey is the task label key for the stable
+	// name of the task instance, set by job manager if the job has one
+	PelotonInstanceNameLabelKey = "peloton.instance_name"
+	// PelotonRevocableLabelKey is the task label key marking a task
+	// launched on revocable resources
+	PelotonRevocableLabelKey = "peloton.revocable"
 
 	// Set default task kill grace period to 30 seconds
 	_defaultTaskKillGracePeriod = 30 * time.Second
@@ -137,6 +143,15 @@ type portPickResult struct {
 	portResources []*mesos.Resource
 }
 
+// ExtractResources takes the given scalar resources from the cached
+// resources, for example for an executor shared by several tasks. It
+// returns an error if there is not enough resources leftover.
+func (tb *Builder) ExtractResources(
+	resources *task.ResourceConfig,
+) ([]*mesos.Resource, error) {
+	return tb.extractScalarResources(resources, false)
+}
+
 // pickPorts inspects the given taskConfig, picks dynamic ports from
 // available resources, and return selected static and dynamic ports
 // as well as necessary environment variables and resources.
@@ -258,18 +273,29 @@ func (tb *Builder) Build(
 		taskID,
 	)
 	tb.populateKillPolicy(mesosTask, taskConfig.GetKillGracePeriodSeconds())
-	tb.populateDiscoveryInfo(mesosTask, pick.selectedPorts, jobID)
+	instanceName := getInstanceName(taskConfig.GetLabels())
+	tb.populateDiscoveryInfo(mesosTask, pick.selectedPorts, jobID, instanceName)
 	tb.populateCommandInfo(
 		mesosTask,
 		taskConfig.GetCommand(),
 		pick.portEnvs,
 		jobID,
 		instanceID,
+		instanceName,
 	)
 	tb.populateContainerInfo(mesosTask, taskConfig.GetContainer())
 	tb.populateLabels(mesosTask, taskConfig.GetLabels(), jobID, instanceID)
+	if taskConfig.GetRevocable() {
+		// Mark the task as launched on revocable resources, which the agent
+		// may preempt the task to reclaim.
+		mesosTask.Labels.Labels = append(mesosTask.Labels.Labels, &mesos.Label{
+			Key:   util.PtrPrintf(PelotonRevocableLabelKey),
+			Value: util.PtrPrintf("true"),
+		})
+	}
 
 	tb.populateHealthCheck(mesosTask, taskConfig.GetHealthCheck())
+	tb.populateReadinessCheck(mesosTask, taskConfig.GetReadinessCheck())
 
 	return mesosTask, nil
 }
@@ -324,6 +350,7 @@ func (tb *Builder) populateCommandInfo(
 	envMap map[string]string,
 	jobID string,
 	instanceID uint32,
+	instanceName string,
 ) {
 
 	if command == nil {
@@ -357,6 +384,17 @@ func (tb *Builder) populateCommandInfo(
 			Value: util.PtrPrintf("%s-%d", jobID, instanceID),
 		},
 	}
+	// The environment of a pod launched through the v1 API already has
+	// the stable instance name.
+	instanceNameEnv := hostmgrutil.LabelKeyToEnvVarName(
+		PelotonInstanceNameLabelKey)
+	if len(instanceName) != 0 &&
+		!hasEnvVar(commandInfo.GetEnvironment(), instanceNameEnv) {
+		pelotonEnvs = append(pelotonEnvs, &mesos.Environment_Variable{
+			Name:  &instanceNameEnv,
+			Value: &instanceName,
+		})
+	}
 
 	// Add peloton specific environtment variables.
 	commandInfo.Environment.Variables = append(commandInfo.Environment.Variables, pelotonEnvs...)
@@ -382,6 +420,16 @@ func (tb *Builder) populateCommandInfo(
 	}
 }
 
+// hasEnvVar returns whether an environment has a variable of the name.
+func hasEnvVar(env *mesos.Environment, name string) bool {
+	for _, v := range env.GetVariables() {
+		if v.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
 // populateContainerInfo properly sets up the `ContainerInfo` field of a task.
 // It populates ContainerInfo if custom executor is requested.
 func (tb *Builder) populateContainerInfo(
@@ -464,8 +512,9 @@ func (tb *Builder) populateDiscoveryInfo(
 	mesosTask *mesos.TaskInfo,
 	selectedPorts map[string]uint32,
 	jobID string,
+	instanceName string,
 ) {
-	if len(selectedPorts) == 0 {
+	if len(selectedPorts) == 0 && len(instanceName) == 0 {
 		return
 	}
 
@@ -493,9 +542,38 @@ func (tb *Builder) populateDiscoveryInfo(
 			Ports: portSlice,
 		},
 		// TODO:
-		// 1. add Environment, Location, Version, and Labels;
+		// 1. add Environment, Location and Version;
 		// 2. Determine how to find this in bridge.
 	}
+
+	// Publish the task under its stable instance name, so that peers
+	// can address it deterministically.
+	if len(instanceName) != 0 {
+		mesosTask.Discovery.Name = &instanceName
+		mesosTask.Discovery.Labels = &mesos.Labels{
+			Labels: []*mesos.Label{
+				{
+					Key:   util.PtrPrintf(PelotonJobIDLabelKey),
+					Value: &jobID,
+				},
+				{
+					Key:   util.PtrPrintf(PelotonInstanceNameLabelKey),
+					Value: &instanceName,
+				},
+			},
+		}
+	}
+}
+
+// getInstanceName returns the stable name of the task instance from the
+// task labels, or an empty string if it does not have one.
+func getInstanceName(labels []*peloton.Label) string {
+	for _, label := range labels {
+		if label.GetKey() == PelotonInstanceNameLabelKey {
+			return label.GetValue()
+		}
+	}
+	return ""
 }
 
 // populateHealthCheck properly sets up the health check part of a Mesos task.
@@ -527,23 +605,16 @@ func (tb *Builder) populateHealthCheck(
 		mh.ConsecutiveFailures = &tmp
 	}
 
+	if t := health.GetGracePeriodSecs(); t > 0 {
+		tmp := float64(t)
+		mh.GracePeriodSeconds = &tmp
+	}
+
 	switch health.GetType() {
 	case task.HealthCheckConfig_COMMAND:
-		cc := health.GetCommandCheck()
 		t := mesos.HealthCheck_COMMAND
 		mh.Type = &t
-		shell := true
-		value := cc.GetCommand()
-		cmd := &mesos.CommandInfo{
-			Shell: &shell,
-			Value: &value,
-		}
-		if !cc.GetUnshareEnvironments() {
-			cmd.Environment = proto.Clone(
-				mesosTask.GetCommand().GetEnvironment(),
-			).(*mesos.Environment)
-		}
-		mh.Command = cmd
+		mh.Command = buildHealthCheckCommand(mesosTask, health.GetCommandCheck())
 	case task.HealthCheckConfig_HTTP:
 		cc := health.GetHttpCheck()
 		t := mesos.HealthCheck_HTTP
@@ -557,6 +628,13 @@ func (tb *Builder) populateHealthCheck(
 			Path:   &path,
 		}
 		mh.Http = h
+	case task.HealthCheckConfig_TCP:
+		t := mesos.HealthCheck_TCP
+		mh.Type = &t
+		port := health.GetTcpCheck().GetPort()
+		mh.Tcp = &mesos.HealthCheck_TCPCheckInfo{
+			Port: &port,
+		}
 	default:
 		log.WithField("type", health.GetType()).
 			Warn("Unknown health check type")
@@ -570,6 +648,89 @@ func (tb *Builder) populateHealthCheck(
 	mesosTask.HealthCheck = mh
 }
 
+// populateReadinessCheck sets up the general check of a Mesos task from
+// the readiness check config. Unlike health checks, the result of a Mesos
+// check is only reported and never kills the task.
+func (tb *Builder) populateReadinessCheck(
+	mesosTask *mesos.TaskInfo, readiness *task.HealthCheckConfig) {
+	if readiness == nil || !readiness.GetEnabled() {
+		return
+	}
+
+	mc := &mesos.CheckInfo{}
+
+	if t := readiness.GetInitialIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.DelaySeconds = &tmp
+	}
+
+	if t := readiness.GetIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.IntervalSeconds = &tmp
+	}
+
+	if t := readiness.GetTimeoutSecs(); t > 0 {
+		tmp := float64(t)
+		mc.TimeoutSeconds = &tmp
+	}
+
+	switch readiness.GetType() {
+	case task.HealthCheckConfig_COMMAND:
+		t := mesos.CheckInfo_COMMAND
+		mc.Type = &t
+		mc.Command = &mesos.CheckInfo_Command{
+			Command: buildHealthCheckCommand(
+				mesosTask, readiness.GetCommandCheck()),
+		}
+	case task.HealthCheckConfig_HTTP:
+		t := mesos.CheckInfo_HTTP
+		mc.Type = &t
+		port := readiness.GetHttpCheck().GetPort()
+		path := readiness.GetHttpCheck().GetPath()
+		mc.Http = &mesos.CheckInfo_Http{
+			Port: &port,
+			Path: &path,
+		}
+	case task.HealthCheckConfig_TCP:
+		t := mesos.CheckInfo_TCP
+		mc.Type = &t
+		port := readiness.GetTcpCheck().GetPort()
+		mc.Tcp = &mesos.CheckInfo_Tcp{
+			Port: &port,
+		}
+	default:
+		log.WithField("type", readiness.GetType()).
+			Warn("Unknown readiness check type")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"check": mc,
+		"task":  mesosTask.GetTaskId(),
+	}).Debug("Populated readiness check for mesos task")
+	mesosTask.Check = mc
+}
+
+// buildHealthCheckCommand returns the shell command to run for a command
+// based check, inheriting the task environment unless asked not to.
+func buildHealthCheckCommand(
+	mesosTask *mesos.TaskInfo,
+	cc *task.HealthCheckConfig_CommandCheck,
+) *mesos.CommandInfo {
+	shell := true
+	value := cc.GetCommand()
+	cmd := &mesos.CommandInfo{
+		Shell: &shell,
+		Value: &value,
+	}
+	if !cc.GetUnshareEnvironments() {
+		cmd.Environment = proto.Clone(
+			mesosTask.GetCommand().GetEnvironment(),
+		).(*mesos.Environment)
+	}
+	return cmd
+}
+
 // extractScalarResources takes necessary scalar resources from cached resources
 // of this instance to construct a task, and returns error if not enough
 // resources are left.
@@ -596,12 +757,16 @@ func (tb *Builder) extractScalarResources(
 		if minimum.Empty() {
 			continue
 		}
-		rs := util.CreateMesosScalarResources(map[string]float64{
+		values := map[string]float64{
 			common.MesosCPU:  minimum.CPU,
 			common.MesosMem:  minimum.Mem,
 			common.MesosDisk: minimum.Disk,
 			common.MesosGPU:  minimum.GPU,
-		}, role)
+		}
+		for name, value := range minimum.Custom {
+			values[name] = value
+		}
+		rs := util.CreateMesosScalarResources(values, role)
 
 		launchResources = append(launchResources, rs...)
 
@@ -197,6 +197,59 @@ func (suite *BuilderTestSuite) TestNoPortTasks() {
 	suite.Equal(err, ErrNotEnoughResource)
 }
 
+// TestStableInstanceName tests that the stable name of a task instance
+// is exposed as environment variable and service discovery name.
+func (suite *BuilderTestSuite) TestStableInstanceName() {
+	builder := NewBuilder(suite.getResources(1))
+	tids := suite.createTestTaskIDs(1)
+	config := createTestTaskConfigs(1)[0]
+	config.Labels = []*peloton.Label{
+		{Key: PelotonInstanceNameLabelKey, Value: "test-job-0"},
+	}
+
+	info, err := builder.Build(&hostsvc.LaunchableTask{
+		TaskId: tids[0],
+		Config: config,
+	})
+	suite.NoError(err)
+
+	envMap := make(map[string]string)
+	for _, envVar := range info.GetCommand().GetEnvironment().GetVariables() {
+		envMap[envVar.GetName()] = envVar.GetValue()
+	}
+	suite.Equal(
+		"test-job-0",
+		envMap[hostmgrutil.LabelKeyToEnvVarName(PelotonInstanceNameLabelKey)])
+
+	suite.Equal("test-job-0", info.GetDiscovery().GetName())
+	suite.Empty(info.GetDiscovery().GetPorts().GetPorts())
+	suite.Equal(
+		"test-job-0",
+		info.GetDiscovery().GetLabels().GetLabels()[1].GetValue())
+
+	// The stable name already in the environment is not added again.
+	builder = NewBuilder(suite.getResources(1))
+	name := hostmgrutil.LabelKeyToEnvVarName(PelotonInstanceNameLabelKey)
+	config.Command.Environment = &mesos.Environment{
+		Variables: []*mesos.Environment_Variable{
+			{Name: &name, Value: util.PtrPrintf("test-job-0")},
+		},
+	}
+	info, err = builder.Build(&hostsvc.LaunchableTask{
+		TaskId: tids[0],
+		Config: config,
+	})
+	suite.NoError(err)
+
+	count := 0
+	for _, envVar := range info.GetCommand().GetEnvironment().GetVariables() {
+		if envVar.GetName() == name {
+			count++
+		}
+	}
+	suite.Equal(1, count)
+}
+
 // This tests several tasks requiring ports can be created.
 func (suite *BuilderTestSuite) TestPortTasks() {
 	portToRole := map[uint32]string{
@@ -501,6 +554,15 @@ func (suite *BuilderTestSuite) TestRevocableTask() {
 	suite.True(hc.GetShell())
 	suite.Len(hc.GetEnvironment().GetVariables(), 3)
 
+	var revocableLabel *mesos.Label
+	for _, label := range info.GetLabels().GetLabels() {
+		if label.GetKey() == PelotonRevocableLabelKey {
+			revocableLabel = label
+		}
+	}
+	suite.NotNil(revocableLabel)
+	suite.Equal("true", revocableLabel.GetValue())
+
 	// Revocable resources are not sufficient
 	builder = NewBuilder(nil)
 	_, err = builder.Build(task)
@@ -526,13 +588,16 @@ func (suite *BuilderTestSuite) TestRevocableTask() {
 // This tests various combination of populating health check.
 func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 	cmdType := mesos.HealthCheck_COMMAND
+	tcpType := mesos.HealthCheck_TCP
 	command := "hello world"
 	tmpTrue := true
+	tcpPort := uint32(8080)
 
 	delaySeconds := float64(1)
 	timeoutSeconds := float64(2)
 	intervalSeconds := float64(3)
 	consecutiveFailures := uint32(4)
+	gracePeriodSeconds := float64(5)
 
 	envName := "name"
 	envValue := "value"
@@ -620,6 +685,29 @@ func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 				},
 			},
 		},
+		// tcp health check with grace period
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_TCP,
+				TcpCheck: &task.HealthCheckConfig_TCPCheck{
+					Port: tcpPort,
+				},
+				GracePeriodSecs: uint32(gracePeriodSeconds),
+			},
+			output: &mesos.HealthCheck{
+				Type: &tcpType,
+				Tcp: &mesos.HealthCheck_TCPCheckInfo{
+					Port: &tcpPort,
+				},
+				GracePeriodSeconds: &gracePeriodSeconds,
+			},
+		},
+		// unknown health check type
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_GRPC,
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -637,6 +725,105 @@ func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 	}
 }
 
+// This tests various combination of populating readiness check.
+func (suite *BuilderTestSuite) TestPopulateReadinessCheck() {
+	cmdType := mesos.CheckInfo_COMMAND
+	httpType := mesos.CheckInfo_HTTP
+	tcpType := mesos.CheckInfo_TCP
+	command := "hello world"
+	tmpTrue := true
+	port := uint32(8080)
+	path := "/ready"
+
+	delaySeconds := float64(1)
+	timeoutSeconds := float64(2)
+	intervalSeconds := float64(3)
+
+	var testCases = []struct {
+		input  *task.HealthCheckConfig
+		output *mesos.CheckInfo
+	}{
+		// no readiness check
+		{},
+		// disabled readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_TCP,
+				TcpCheck: &task.HealthCheckConfig_TCPCheck{
+					Port: port,
+				},
+			},
+		},
+		// command readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_COMMAND,
+				CommandCheck: &task.HealthCheckConfig_CommandCheck{
+					Command: command,
+				},
+				InitialIntervalSecs: uint32(delaySeconds),
+				TimeoutSecs:         uint32(timeoutSeconds),
+				IntervalSecs:        uint32(intervalSeconds),
+			},
+			output: &mesos.CheckInfo{
+				Type: &cmdType,
+				Command: &mesos.CheckInfo_Command{
+					Command: &mesos.CommandInfo{
+						Shell: &tmpTrue,
+						Value: &command,
+					},
+				},
+				DelaySeconds:    &delaySeconds,
+				TimeoutSeconds:  &timeoutSeconds,
+				IntervalSeconds: &intervalSeconds,
+			},
+		},
+		// http readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_HTTP,
+				HttpCheck: &task.HealthCheckConfig_HTTPCheck{
+					Scheme: "http",
+					Port:   port,
+					Path:   path,
+				},
+			},
+			output: &mesos.CheckInfo{
+				Type: &httpType,
+				Http: &mesos.CheckInfo_Http{
+					Port: &port,
+					Path: &path,
+				},
+			},
+		},
+		// tcp readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_TCP,
+				TcpCheck: &task.HealthCheckConfig_TCPCheck{
+					Port: port,
+				},
+			},
+			output: &mesos.CheckInfo{
+				Type: &tcpType,
+				Tcp: &mesos.CheckInfo_Tcp{
+					Port: &port,
+				},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		builder := NewBuilder(nil)
+		taskInfo := &mesos.TaskInfo{}
+		builder.populateReadinessCheck(taskInfo, tt.input)
+		suite.Equal(tt.output, taskInfo.GetCheck())
+	}
+}
+
 // TestPopulateLabels tests populateLabels.
 func (suite *BuilderTestSuite) TestPopulateLabels() {
 	jobID := "test-job"
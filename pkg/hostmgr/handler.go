@@ -17,6 +17,7 @@ package hostmgr
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +38,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/config"
 	"github.com/uber/peloton/pkg/hostmgr/goalstate"
 	"github.com/uber/peloton/pkg/hostmgr/host"
+	"github.com/uber/peloton/pkg/hostmgr/hostfilter"
 	"github.com/uber/peloton/pkg/hostmgr/hostpool/manager"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
@@ -46,6 +48,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
+	"github.com/uber/peloton/pkg/hostmgr/reconcile"
 	"github.com/uber/peloton/pkg/hostmgr/reserver"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/summary"
@@ -85,6 +88,7 @@ var (
 // ServiceHandler implements peloton.private.hostmgr.InternalHostService.
 type ServiceHandler struct {
 	schedulerClient       mpb.SchedulerClient
+	schedulerBreaker      *mpb.CircuitBreaker
 	operatorMasterClient  mpb.MasterOperatorClient
 	metrics               *metrics.Metrics
 	offerPool             offerpool.Pool
@@ -101,6 +105,7 @@ type ServiceHandler struct {
 	hostInfoOps           ormobjects.HostInfoOps // DB ops for host_info table
 	hostCache             hostcache.HostCache
 	plugin                plugins.Plugin
+	taskReconciler        reconcile.TaskReconciler
 }
 
 // NewServiceHandler creates a new ServiceHandler.
@@ -108,6 +113,7 @@ func NewServiceHandler(
 	d *yarpc.Dispatcher,
 	metrics *metrics.Metrics,
 	schedulerClient mpb.SchedulerClient,
+	schedulerBreaker *mpb.CircuitBreaker,
 	masterOperatorClient mpb.MasterOperatorClient,
 	frameworkInfoProvider hostmgr_mesos.FrameworkInfoProvider,
 	mesosConfig hostmgr_mesos.Config,
@@ -120,10 +126,12 @@ func NewServiceHandler(
 	hostInfoOps ormobjects.HostInfoOps,
 	hostCache hostcache.HostCache,
 	plugin plugins.Plugin,
+	taskReconciler reconcile.TaskReconciler,
 ) *ServiceHandler {
 
 	handler := &ServiceHandler{
 		schedulerClient:       schedulerClient,
+		schedulerBreaker:      schedulerBreaker,
 		operatorMasterClient:  masterOperatorClient,
 		metrics:               metrics,
 		offerPool:             offer.GetEventHandler().GetOfferPool(),
@@ -137,6 +145,7 @@ func NewServiceHandler(
 		hostInfoOps:           hostInfoOps,
 		hostCache:             hostCache,
 		plugin:                plugin,
+		taskReconciler:        taskReconciler,
 	}
 	// Creating Reserver object for handler
 	handler.reserver = reserver.NewReserver(
@@ -179,12 +188,18 @@ func (h *ServiceHandler) DisableKillTasks(
 	return &hostsvc.DisableKillTasksResponse{}, nil
 }
 
-// GetOutstandingOffers returns all the offers present in offer pool.
+// GetOutstandingOffers returns all the offers present in offer pool, of the
+// hosts satisfying the attribute expression of the request if any.
 func (h *ServiceHandler) GetOutstandingOffers(
 	ctx context.Context,
 	body *hostsvc.GetOutstandingOffersRequest,
 ) (*hostsvc.GetOutstandingOffersResponse, error) {
 
+	expression, err := hostfilter.Compile(body.GetAttributeExpression())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf("%s", err)
+	}
+
 	hostOffers, count := h.offerPool.GetAllOffers()
 	if count == 0 {
 		return &hostsvc.GetOutstandingOffersResponse{
@@ -199,6 +214,16 @@ func (h *ServiceHandler) GetOutstandingOffers(
 	outstandingOffers := make([]*mesos.Offer, 0, count)
 
 	for _, hostOffer := range hostOffers {
+		var attributes []*mesos.Attribute
+		for _, offer := range hostOffer {
+			// All the offers of a host have the same attributes.
+			attributes = offer.GetAttributes()
+			break
+		}
+		if !expression.Evaluate(attributes, scalar.FromOfferMap(hostOffer)) {
+			continue
+		}
+
 		for _, offer := range hostOffer {
 			outstandingOffers = append(outstandingOffers, offer)
 		}
@@ -525,6 +550,22 @@ func (h *ServiceHandler) AcquireHostOffers(
 		}, errors.Wrap(err, "invalid filter")
 	}
 
+	// Offers cannot be launched on while the calls to Mesos master fail
+	// fast, so stop handing them out until the breaker lets calls through.
+	if h.schedulerBreaker.IsOpen() {
+		h.metrics.AcquireHostOffersFail.Inc(1)
+		log.Warn("Mesos master circuit breaker is open, " +
+			"pausing AcquireHostOffers")
+
+		return &hostsvc.AcquireHostOffersResponse{
+			Error: &hostsvc.AcquireHostOffersResponse_Error{
+				Failure: &hostsvc.AcquireHostOffersFailure{
+					Message: mpb.ErrCircuitOpen.Error(),
+				},
+			},
+		}, mpb.ErrCircuitOpen
+	}
+
 	result, resultCount, err := h.offerPool.ClaimForPlace(ctx, body.GetFilter())
 	if err != nil {
 		h.metrics.AcquireHostOffersFail.Inc(1)
@@ -564,11 +605,12 @@ func (h *ServiceHandler) AcquireHostOffers(
 
 		// create the peloton host offer
 		pHostOffer := hostsvc.HostOffer{
-			Hostname:   hostname,
-			AgentId:    offers[0].GetAgentId(),
-			Attributes: offers[0].GetAttributes(),
-			Resources:  resources,
-			Id:         &peloton.HostOfferID{Value: hostOffer.ID},
+			Hostname:       hostname,
+			AgentId:        offers[0].GetAgentId(),
+			Attributes:     offers[0].GetAttributes(),
+			Resources:      resources,
+			Id:             &peloton.HostOfferID{Value: hostOffer.ID},
+			Unavailability: hmutil.GetEarliestUnavailability(offers),
 		}
 
 		response.HostOffers = append(response.HostOffers, &pHostOffer)
@@ -824,19 +866,39 @@ func (h *ServiceHandler) LaunchTasks(
 	h.metrics.LaunchTasks.Inc(int64(len(launchedPods)))
 
 	var taskIDs []string
+	launchCorrelationIDs := make(map[string]string)
 	for _, pod := range launchedPods {
 		taskIDs = append(taskIDs, pod.PodId.GetValue())
+		if id := getLaunchCorrelationID(pod); len(id) != 0 {
+			launchCorrelationIDs[pod.PodId.GetValue()] = id
+		}
 	}
 
 	log.WithFields(log.Fields{
-		"task_ids":      taskIDs,
-		"hostname":      req.GetHostname(),
-		"host_offer_id": req.GetId().GetValue(),
+		"task_ids":               taskIDs,
+		"launch_correlation_ids": launchCorrelationIDs,
+		"hostname":               req.GetHostname(),
+		"host_offer_id":          req.GetId().GetValue(),
 	}).Info("LaunchTasks")
 
 	return &hostsvc.LaunchTasksResponse{}, nil
 }
 
+// getLaunchCorrelationID returns the launch correlation ID carried in the
+// system labels of a launchable pod, or an empty string if there is none.
+func getLaunchCorrelationID(pod *models.LaunchablePod) string {
+	key := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelLaunchCorrelationID)
+	for _, label := range pod.Spec.GetLabels() {
+		if label.GetKey() == key {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
 func validateLaunchTasks(request *hostsvc.LaunchTasksRequest) error {
 	if len(request.Tasks) <= 0 {
 		return errEmptyTaskList
@@ -1236,6 +1298,16 @@ func (h *ServiceHandler) ClusterCapacity(
 		if nonRevocableClusterCapacity.GetGPU() <= 0 {
 			nonRevocableClusterCapacity.GPU = agentMap.Capacity.GetGPU()
 		}
+		for _, name := range agentMap.Capacity.CustomNames() {
+			if nonRevocableClusterCapacity.GetCustom(name) <= 0 {
+				nonRevocableClusterCapacity = nonRevocableClusterCapacity.Add(
+					scalar.Resources{
+						Custom: map[string]float64{
+							name: agentMap.Capacity.GetCustom(name),
+						},
+					})
+			}
+		}
 	}
 
 	revocableAllocated, nonRevocableAllocated := scalar.FilterMesosResources(
@@ -1479,6 +1551,305 @@ func (h *ServiceHandler) GetTasksByHostState(
 	return &hostsvc.GetTasksByHostStateResponse{}, nil
 }
 
+// ReconcileTasks explicitly reconciles the given tasks with Mesos, which
+// sends a status update for each of them.
+func (h *ServiceHandler) ReconcileTasks(
+	ctx context.Context,
+	req *hostsvc.ReconcileTasksRequest,
+) (*hostsvc.ReconcileTasksResponse, error) {
+	if len(req.GetTasks()) == 0 {
+		return &hostsvc.ReconcileTasksResponse{}, nil
+	}
+
+	var tasks []*sched.Call_Reconcile_Task
+	for _, t := range req.GetTasks() {
+		tasks = append(tasks, &sched.Call_Reconcile_Task{
+			TaskId:  t.GetTaskId(),
+			AgentId: t.GetAgentId(),
+		})
+	}
+
+	callType := sched.Call_RECONCILE
+	msg := &sched.Call{
+		FrameworkId: h.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		Reconcile: &sched.Call_Reconcile{
+			Tasks: tasks,
+		},
+	}
+	msid := h.frameworkInfoProvider.GetMesosStreamID(ctx)
+	if err := h.schedulerClient.Call(msid, msg); err != nil {
+		h.metrics.ReconcileTasksFail.Inc(1)
+		log.WithError(err).
+			WithField("num_tasks", len(tasks)).
+			Error("Reconcile tasks failure")
+		return &hostsvc.ReconcileTasksResponse{
+			Error: &hostsvc.ReconcileTasksResponse_Error{
+				Message: err.Error(),
+			},
+		}, nil
+	}
+
+	h.metrics.ReconcileTasks.Inc(int64(len(tasks)))
+	log.WithField("num_tasks", len(tasks)).
+		Info("Reconcile tasks request sent")
+	return &hostsvc.ReconcileTasksResponse{}, nil
+}
+
+// ExplicitReconcile starts an explicit reconciliation with Mesos of the
+// non-terminal tasks of a job, or of the given tasks, whose progress can be
+// queried with GetReconcileStatus.
+func (h *ServiceHandler) ExplicitReconcile(
+	ctx context.Context,
+	req *hostsvc.ExplicitReconcileRequest,
+) (*hostsvc.ExplicitReconcileResponse, error) {
+	id, err := h.taskReconciler.ReconcileTasks(
+		ctx, req.GetJobId(), req.GetTaskIds())
+	if err != nil {
+		h.metrics.ExplicitReconcileFail.Inc(1)
+		log.WithError(err).
+			WithFields(log.Fields{
+				"job_id":   req.GetJobId().GetValue(),
+				"task_ids": req.GetTaskIds(),
+			}).Warn("Explicit reconcile failure")
+
+		respErr := &hostsvc.ExplicitReconcileResponse_Error{}
+		if yarpcerrors.IsInvalidArgument(err) {
+			respErr.InvalidArgument = &hostsvc.InvalidArgument{
+				Message: err.Error(),
+			}
+		} else {
+			respErr.Message = err.Error()
+		}
+		return &hostsvc.ExplicitReconcileResponse{Error: respErr}, nil
+	}
+
+	status, err := h.taskReconciler.GetReconcileStatus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	h.metrics.ExplicitReconcile.Inc(1)
+	return &hostsvc.ExplicitReconcileResponse{
+		ReconcileId: id,
+		NumTasks:    uint32(status.TotalTasks),
+	}, nil
+}
+
+// GetReconcileStatus gets the progress of a reconciliation
+// started by ExplicitReconcile.
+func (h *ServiceHandler) GetReconcileStatus(
+	ctx context.Context,
+	req *hostsvc.GetReconcileStatusRequest,
+) (*hostsvc.GetReconcileStatusResponse, error) {
+	status, err := h.taskReconciler.GetReconcileStatus(req.GetReconcileId())
+	if err != nil {
+		h.metrics.GetReconcileStatusNotFound.Inc(1)
+		return &hostsvc.GetReconcileStatusResponse{
+			Error: &hostsvc.GetReconcileStatusResponse_Error{
+				NotFound: &hostsvc.NotFound{
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	h.metrics.GetReconcileStatus.Inc(1)
+	return &hostsvc.GetReconcileStatusResponse{
+		Status: toReconcileStatusProto(status),
+	}, nil
+}
+
+// toReconcileStatusProto converts the progress of a reconciliation
+// to its protobuf representation.
+func toReconcileStatusProto(
+	status *reconcile.ReconcileStatus) *hostsvc.ReconcileStatus {
+	result := &hostsvc.ReconcileStatus{
+		ReconcileId: status.ID,
+		JobId:       status.JobID,
+		TotalTasks:  uint32(status.TotalTasks),
+		SentTasks:   uint32(status.SentTasks),
+		StartTime:   status.StartTime.Format(time.RFC3339),
+	}
+	switch status.State {
+	case reconcile.ReconcileRunning:
+		result.State = hostsvc.ReconcileState_RECONCILE_STATE_RUNNING
+	case reconcile.ReconcileSucceeded:
+		result.State = hostsvc.ReconcileState_RECONCILE_STATE_SUCCEEDED
+	case reconcile.ReconcileFailed:
+		result.State = hostsvc.ReconcileState_RECONCILE_STATE_FAILED
+	}
+	if !status.CompletionTime.IsZero() {
+		result.CompletionTime = status.CompletionTime.Format(time.RFC3339)
+	}
+	if status.Err != nil {
+		result.Message = status.Err.Error()
+	}
+	return result
+}
+
+// PinHosts pins the given hosts in the offer pool, so that their offers are
+// held for placements which need multiple scheduling rounds.
+func (h *ServiceHandler) PinHosts(
+	ctx context.Context,
+	req *hostsvc.PinHostsRequest,
+) (*hostsvc.PinHostsResponse, error) {
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+
+	var errs []error
+	for _, hostname := range req.GetHostnames() {
+		if err := h.offerPool.PinHost(hostname, ttl); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		h.metrics.PinHostsFail.Inc(1)
+		err := multierr.Combine(errs...)
+		log.WithError(err).
+			WithField("hosts", req.GetHostnames()).
+			Warn("Pin hosts failure")
+		return &hostsvc.PinHostsResponse{
+			Error: &hostsvc.PinHostsResponse_Error{
+				Message: err.Error(),
+			},
+		}, nil
+	}
+
+	h.metrics.PinHosts.Inc(int64(len(req.GetHostnames())))
+	return &hostsvc.PinHostsResponse{}, nil
+}
+
+// UnpinHosts unpins the given hosts pinned by PinHosts.
+func (h *ServiceHandler) UnpinHosts(
+	ctx context.Context,
+	req *hostsvc.UnpinHostsRequest,
+) (*hostsvc.UnpinHostsResponse, error) {
+	for _, hostname := range req.GetHostnames() {
+		h.offerPool.UnpinHost(hostname)
+	}
+
+	h.metrics.UnpinHosts.Inc(int64(len(req.GetHostnames())))
+	return &hostsvc.UnpinHostsResponse{}, nil
+}
+
+// GetQuarantinedHosts returns the hosts quarantined from placement since
+// their offers are rescinded repeatedly.
+func (h *ServiceHandler) GetQuarantinedHosts(
+	ctx context.Context,
+	req *hostsvc.GetQuarantinedHostsRequest,
+) (*hostsvc.GetQuarantinedHostsResponse, error) {
+	var hosts []*hostsvc.QuarantinedHost
+	for hostname, expiration := range h.offerPool.GetQuarantinedHosts() {
+		hosts = append(hosts, &hostsvc.QuarantinedHost{
+			Hostname:   hostname,
+			Expiration: expiration.Format(time.RFC3339),
+		})
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].GetHostname() < hosts[j].GetHostname()
+	})
+
+	h.metrics.GetQuarantinedHosts.Inc(1)
+	return &hostsvc.GetQuarantinedHostsResponse{Hosts: hosts}, nil
+}
+
+// ClearHostQuarantine releases the given hosts, or all the hosts if
+// requested, from quarantine.
+func (h *ServiceHandler) ClearHostQuarantine(
+	ctx context.Context,
+	req *hostsvc.ClearHostQuarantineRequest,
+) (*hostsvc.ClearHostQuarantineResponse, error) {
+	if len(req.GetHostnames()) == 0 && !req.GetAll() {
+		h.metrics.ClearHostQuarantineInvalid.Inc(1)
+		return &hostsvc.ClearHostQuarantineResponse{
+			Error: &hostsvc.ClearHostQuarantineResponse_Error{
+				InvalidArgument: &hostsvc.InvalidArgument{
+					Message: "no hosts to clear quarantine of",
+				},
+			},
+		}, nil
+	}
+
+	cleared := h.offerPool.ClearQuarantine(req.GetHostnames()...)
+	sort.Strings(cleared)
+
+	h.metrics.ClearHostQuarantine.Inc(int64(len(cleared)))
+	log.WithField("hosts", cleared).Info("Host quarantine cleared")
+	return &hostsvc.ClearHostQuarantineResponse{
+		ClearedHostnames: cleared,
+	}, nil
+}
+
+// GetHostResources returns the total capacity of the given hosts, or of
+// all the registered hosts if none are given, along with the resources
+// currently offered and allocated to Peloton on them.
+func (h *ServiceHandler) GetHostResources(
+	ctx context.Context,
+	req *hostsvc.GetHostResourcesRequest,
+) (*hostsvc.GetHostResourcesResponse, error) {
+	agentMap := host.GetAgentMap()
+	if agentMap == nil || len(agentMap.RegisteredAgents) == 0 {
+		h.metrics.GetHostResourcesFail.Inc(1)
+		log.Error("error getting host agentmap")
+		return &hostsvc.GetHostResourcesResponse{
+			Error: &hostsvc.GetHostResourcesResponse_Error{
+				ClusterUnavailable: &hostsvc.ClusterUnavailable{
+					Message: "error getting host agentmap",
+				},
+			},
+		}, nil
+	}
+
+	hostnames := req.GetHostnames()
+	if len(hostnames) == 0 {
+		for hostname := range agentMap.HostCapacities {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	sort.Strings(hostnames)
+
+	hostSummaries := h.offerPool.GetHostOfferIndex()
+	allocations := make(map[string]models.HostResources)
+	for _, s := range h.hostCache.GetSummaries() {
+		allocations[s.GetHostname()] = s.GetAllocated()
+	}
+
+	var hosts []*hostsvc.HostResources
+	for _, hostname := range hostnames {
+		capacity, ok := agentMap.HostCapacities[hostname]
+		if !ok {
+			continue
+		}
+
+		var offered, offeredSlack scalar.Resources
+		if hs, ok := hostSummaries[hostname]; ok {
+			revocable, nonRevocable := scalar.FilterMesosResources(
+				scalar.FromOffersMapToMesosResources(hs.GetOffers(summary.All)),
+				func(r *mesos.Resource) bool {
+					return r.GetRevocable() != nil &&
+						hmutil.IsSlackResourceType(r.GetName(), h.slackResourceTypes)
+				})
+			offered = scalar.FromMesosResources(nonRevocable)
+			offeredSlack = scalar.FromMesosResources(revocable)
+		}
+		allocated := allocations[hostname]
+
+		hosts = append(hosts, &hostsvc.HostResources{
+			Hostname:         hostname,
+			PhysicalCapacity: toHostSvcResources(&capacity.Physical),
+			SlackCapacity:    toHostSvcResources(&capacity.Slack),
+			Offered:          toHostSvcResources(&offered),
+			OfferedSlack:     toHostSvcResources(&offeredSlack),
+			Allocated:        toHostSvcResources(&allocated.NonSlack),
+			AllocatedSlack:   toHostSvcResources(&allocated.Slack),
+		})
+	}
+
+	h.metrics.GetHostResources.Inc(1)
+	return &hostsvc.GetHostResourcesResponse{Hosts: hosts}, nil
+}
+
 func (h *ServiceHandler) releaseHostsHeldForTasks(taskIDs []*peloton.TaskID) error {
 	var errs []error
 	hostHeldForTasks := make(map[string][]*peloton.TaskID)
@@ -1525,8 +1896,9 @@ func (h *ServiceHandler) GetHostPoolCapacity(
 }
 
 // Helper function to convert scalar.Resource into hostsvc format.
+// The custom resources are added with their names as the kind.
 func toHostSvcResources(rs *scalar.Resources) []*hostsvc.Resource {
-	return []*hostsvc.Resource{
+	resources := []*hostsvc.Resource{
 		{
 			Kind:     common.CPU,
 			Capacity: rs.CPU,
@@ -1541,6 +1913,13 @@ func toHostSvcResources(rs *scalar.Resources) []*hostsvc.Resource {
 			Capacity: rs.Mem,
 		},
 	}
+	for _, name := range rs.CustomNames() {
+		resources = append(resources, &hostsvc.Resource{
+			Kind:     name,
+			Capacity: rs.GetCustom(name),
+		})
+	}
+	return resources
 }
 
 // Helper function to convert summary.HostStatus to string
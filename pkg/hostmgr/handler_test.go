@@ -32,11 +32,13 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	halphapb "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	hostsvcmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	cqosmocks "github.com/uber/peloton/.gen/qos/v1alpha1/mocks"
 
+	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
 	bin_packing "github.com/uber/peloton/pkg/hostmgr/binpacking"
 	"github.com/uber/peloton/pkg/hostmgr/config"
@@ -46,12 +48,17 @@ import (
 	hostpool_manager_mocks "github.com/uber/peloton/pkg/hostmgr/hostpool/manager/mocks"
 	hostmgr_hostpool_mocks "github.com/uber/peloton/pkg/hostmgr/hostpool/mocks"
 	hostmgr_mesos_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/mocks"
+	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	mpb_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
 	"github.com/uber/peloton/pkg/hostmgr/metrics"
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	"github.com/uber/peloton/pkg/hostmgr/offer/offerpool"
+	hostsummary "github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	hostsummary_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary/mocks"
 	hostcache_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/mocks"
 	plugins_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/plugins/mocks"
+	"github.com/uber/peloton/pkg/hostmgr/reconcile"
+	reconcile_mocks "github.com/uber/peloton/pkg/hostmgr/reconcile/mocks"
 	"github.com/uber/peloton/pkg/hostmgr/reserver"
 	reserver_mocks "github.com/uber/peloton/pkg/hostmgr/reserver/mocks"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
@@ -218,6 +225,7 @@ type HostMgrHandlerTestSuite struct {
 	mockHostInfoOps        *orm_mocks.MockHostInfoOps
 	mockGoalStateDriver    *goalstate_mocks.MockDriver
 	mockPlugin             *plugins_mocks.MockPlugin
+	mockTaskReconciler     *reconcile_mocks.MockTaskReconciler
 }
 
 func (suite *HostMgrHandlerTestSuite) SetupSuite() {
@@ -244,6 +252,7 @@ func (suite *HostMgrHandlerTestSuite) SetupTest() {
 	suite.mockHostInfoOps = orm_mocks.NewMockHostInfoOps(suite.ctrl)
 	suite.mockGoalStateDriver = goalstate_mocks.NewMockDriver(suite.ctrl)
 	suite.mockPlugin = plugins_mocks.NewMockPlugin(suite.ctrl)
+	suite.mockTaskReconciler = reconcile_mocks.NewMockTaskReconciler(suite.ctrl)
 
 	mockValidValue := new(string)
 	*mockValidValue = _frameworkID
@@ -280,6 +289,7 @@ func (suite *HostMgrHandlerTestSuite) SetupTest() {
 		hostInfoOps:           suite.mockHostInfoOps,
 		hostCache:             suite.hostCache,
 		plugin:                suite.mockPlugin,
+		taskReconciler:        suite.mockTaskReconciler,
 		slackResourceTypes:    slacResourceTypes,
 	}
 	suite.handler.reserver = reserver.NewReserver(
@@ -390,6 +400,34 @@ func (suite *HostMgrHandlerTestSuite) TestGetOutstandingOffers() {
 	suite.pool.AddOffers(context.Background(), generateOffers(numHosts))
 	resp, _ = suite.handler.GetOutstandingOffers(rootCtx, &hostsvc.GetOutstandingOffersRequest{})
 	suite.Equal(len(resp.Offers), numHosts)
+
+	resp, err := suite.handler.GetOutstandingOffers(
+		rootCtx,
+		&hostsvc.GetOutstandingOffersRequest{
+			AttributeExpression: `attribute.name == "hostname-1" || ` +
+				`attribute.name == hostname-3`,
+		})
+	suite.NoError(err)
+	suite.Len(resp.GetOffers(), 2)
+	for _, offer := range resp.GetOffers() {
+		suite.Contains(
+			[]string{"hostname-1", "hostname-3"}, offer.GetHostname())
+	}
+
+	resp, err = suite.handler.GetOutstandingOffers(
+		rootCtx,
+		&hostsvc.GetOutstandingOffersRequest{
+			AttributeExpression: fmt.Sprintf("resource.cpu > %v", _perHostCPU),
+		})
+	suite.NoError(err)
+	suite.Empty(resp.GetOffers())
+
+	_, err = suite.handler.GetOutstandingOffers(
+		rootCtx,
+		&hostsvc.GetOutstandingOffersRequest{
+			AttributeExpression: "attribute.name ==",
+		})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
 }
 
 func (suite *HostMgrHandlerTestSuite) TestGetHostsByQueryNoOffers() {
@@ -1078,6 +1116,162 @@ func (suite *HostMgrHandlerTestSuite) TestShutdownExecutorsFailure() {
 	}
 }
 
+// TestReconcileTasks tests explicitly reconciling tasks with Mesos
+func (suite *HostMgrHandlerTestSuite) TestReconcileTasks() {
+	defer suite.ctrl.Finish()
+
+	t1 := "t1"
+	a1 := "a1"
+	req := &hostsvc.ReconcileTasksRequest{
+		Tasks: []*hostsvc.ReconcileTasksRequest_Task{
+			{
+				TaskId:  &mesos.TaskID{Value: &t1},
+				AgentId: &mesos.AgentID{Value: &a1},
+			},
+		},
+	}
+
+	callType := sched.Call_RECONCILE
+	msg := &sched.Call{
+		FrameworkId: suite.frameworkID,
+		Type:        &callType,
+		Reconcile: &sched.Call_Reconcile{
+			Tasks: []*sched.Call_Reconcile_Task{
+				{
+					TaskId:  &mesos.TaskID{Value: &t1},
+					AgentId: &mesos.AgentID{Value: &a1},
+				},
+			},
+		},
+	}
+
+	// empty request is a no-op
+	resp, err := suite.handler.ReconcileTasks(
+		rootCtx, &hostsvc.ReconcileTasksRequest{})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+
+	// mesos call fails
+	suite.provider.EXPECT().GetFrameworkID(rootCtx).Return(suite.frameworkID)
+	suite.provider.EXPECT().GetMesosStreamID(rootCtx).Return(_streamID)
+	suite.schedulerClient.EXPECT().
+		Call(_streamID, msg).
+		Return(errors.New("mesos call failed"))
+
+	resp, err = suite.handler.ReconcileTasks(rootCtx, req)
+	suite.NoError(err)
+	suite.NotNil(resp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["reconcile_tasks_fail+"].Value())
+
+	// mesos call succeeds
+	suite.provider.EXPECT().GetFrameworkID(rootCtx).Return(suite.frameworkID)
+	suite.provider.EXPECT().GetMesosStreamID(rootCtx).Return(_streamID)
+	suite.schedulerClient.EXPECT().
+		Call(_streamID, msg).
+		Return(nil)
+
+	resp, err = suite.handler.ReconcileTasks(rootCtx, req)
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["reconcile_tasks+"].Value())
+}
+
+// TestExplicitReconcile tests starting an explicit reconciliation
+// of a job and querying its progress
+func (suite *HostMgrHandlerTestSuite) TestExplicitReconcile() {
+	defer suite.ctrl.Finish()
+
+	jobID := &peloton.JobID{Value: uuid.New()}
+	reconcileID := uuid.New()
+	startTime := time.Now()
+
+	// invalid request
+	suite.mockTaskReconciler.EXPECT().
+		ReconcileTasks(rootCtx, nil, nil).
+		Return("", yarpcerrors.InvalidArgumentErrorf("no job"))
+	resp, err := suite.handler.ExplicitReconcile(
+		rootCtx, &hostsvc.ExplicitReconcileRequest{})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidArgument())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["explicit_reconcile_fail+"].Value())
+
+	// task store failure
+	suite.mockTaskReconciler.EXPECT().
+		ReconcileTasks(rootCtx, jobID, nil).
+		Return("", errors.New("store failed"))
+	resp, err = suite.handler.ExplicitReconcile(
+		rootCtx, &hostsvc.ExplicitReconcileRequest{JobId: jobID})
+	suite.NoError(err)
+	suite.Nil(resp.GetError().GetInvalidArgument())
+	suite.NotEmpty(resp.GetError().GetMessage())
+
+	// reconcile started
+	suite.mockTaskReconciler.EXPECT().
+		ReconcileTasks(rootCtx, jobID, nil).
+		Return(reconcileID, nil)
+	suite.mockTaskReconciler.EXPECT().
+		GetReconcileStatus(reconcileID).
+		Return(&reconcile.ReconcileStatus{
+			ID:         reconcileID,
+			JobID:      jobID,
+			State:      reconcile.ReconcileRunning,
+			TotalTasks: 10,
+			StartTime:  startTime,
+		}, nil)
+	resp, err = suite.handler.ExplicitReconcile(
+		rootCtx, &hostsvc.ExplicitReconcileRequest{JobId: jobID})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(reconcileID, resp.GetReconcileId())
+	suite.Equal(uint32(10), resp.GetNumTasks())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["explicit_reconcile+"].Value())
+
+	// reconcile failed
+	suite.mockTaskReconciler.EXPECT().
+		GetReconcileStatus(reconcileID).
+		Return(&reconcile.ReconcileStatus{
+			ID:             reconcileID,
+			JobID:          jobID,
+			State:          reconcile.ReconcileFailed,
+			TotalTasks:     10,
+			SentTasks:      5,
+			StartTime:      startTime,
+			CompletionTime: startTime,
+			Err:            errors.New("mesos call failed"),
+		}, nil)
+	statusResp, err := suite.handler.GetReconcileStatus(
+		rootCtx, &hostsvc.GetReconcileStatusRequest{ReconcileId: reconcileID})
+	suite.NoError(err)
+	suite.Nil(statusResp.GetError())
+	suite.Equal(&hostsvc.ReconcileStatus{
+		ReconcileId:    reconcileID,
+		JobId:          jobID,
+		State:          hostsvc.ReconcileState_RECONCILE_STATE_FAILED,
+		TotalTasks:     10,
+		SentTasks:      5,
+		StartTime:      startTime.Format(time.RFC3339),
+		CompletionTime: startTime.Format(time.RFC3339),
+		Message:        "mesos call failed",
+	}, statusResp.GetStatus())
+
+	// unknown reconcile
+	suite.mockTaskReconciler.EXPECT().
+		GetReconcileStatus("unknown").
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+	statusResp, err = suite.handler.GetReconcileStatus(
+		rootCtx, &hostsvc.GetReconcileStatusRequest{ReconcileId: "unknown"})
+	suite.NoError(err)
+	suite.NotNil(statusResp.GetError().GetNotFound())
+}
+
 // TestKillAndReserveTaskWithoutHostSummary test the case that failing
 // to hold the host on kill should not return an error to user
 func (suite *HostMgrHandlerTestSuite) TestKillAndReserveTaskWithoutHostSummary() {
@@ -1660,6 +1854,31 @@ func (suite *HostMgrHandlerTestSuite) TestServiceHandlerMarkHostDrainedFailure()
 	suite.Nil(resp)
 }
 
+// TestAcquireHostOffersCircuitOpen tests that no offers are handed out
+// while the Mesos master circuit breaker is open.
+func (suite *HostMgrHandlerTestSuite) TestAcquireHostOffersCircuitOpen() {
+	breaker := mpb.NewCircuitBreaker(
+		"scheduler",
+		mpb.BreakerConfig{FailureThreshold: 1, OpenDuration: time.Hour},
+		tally.NoopScope)
+	breaker.Do(func() error {
+		return errors.New("connection error")
+	})
+	suite.handler.schedulerBreaker = breaker
+
+	resp, err := suite.handler.AcquireHostOffers(
+		rootCtx,
+		getAcquireHostOffersRequest(),
+	)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsUnavailable(err))
+	suite.NotNil(resp.GetError().GetFailure())
+	suite.Empty(resp.GetHostOffers())
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["acquire_host_offers_fail+"].Value())
+}
+
 func getAcquireHostOffersRequest() *hostsvc.AcquireHostOffersRequest {
 	return &hostsvc.AcquireHostOffersRequest{
 		Filter: &hostsvc.HostFilter{
@@ -1899,6 +2118,24 @@ func (suite *HostMgrHandlerTestSuite) TestEnqueueReservationError() {
 		errReservationNotFound.Error())
 }
 
+// TestGetLaunchCorrelationID tests reading the launch correlation ID from
+// the labels of a launchable pod.
+func (suite *HostMgrHandlerTestSuite) TestGetLaunchCorrelationID() {
+	pod := &models.LaunchablePod{
+		Spec: &pbpod.PodSpec{
+			Labels: []*v1alphapeloton.Label{
+				{Key: "key", Value: "value"},
+				{Key: "peloton.launch_correlation_id", Value: "id"},
+			},
+		},
+	}
+	suite.Equal("id", getLaunchCorrelationID(pod))
+
+	pod.Spec.Labels = pod.Spec.Labels[:1]
+	suite.Empty(getLaunchCorrelationID(pod))
+	suite.Empty(getLaunchCorrelationID(&models.LaunchablePod{}))
+}
+
 func TestHostManagerTestSuite(t *testing.T) {
 	suite.Run(t, new(HostMgrHandlerTestSuite))
 }
@@ -2201,6 +2438,182 @@ func (suite *HostMgrHandlerTestSuite) TestReleaseHostsHeldForTasks() {
 	suite.Equal(suite.pool.GetHostHeldForTask(tasks[3]), host2)
 }
 
+// TestPinUnpinHosts tests pinning and unpinning hosts in the offer pool.
+func (suite *HostMgrHandlerTestSuite) TestPinUnpinHosts() {
+	defer suite.ctrl.Finish()
+
+	numOffers := 2
+	// set expectation on watch Processor
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any())
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any())
+	offers := suite.pool.AddOffers(context.Background(), generateOffers(numOffers))
+
+	host1 := offers[0].GetHostname()
+	host2 := offers[1].GetHostname()
+
+	resp, err := suite.handler.PinHosts(
+		context.Background(),
+		&hostsvc.PinHostsRequest{
+			Hostnames:  []string{host1, host2},
+			TtlSeconds: 60,
+		},
+	)
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Len(suite.pool.GetPinnedHosts(), 2)
+
+	// Hosts without offers cannot be pinned.
+	resp, err = suite.handler.PinHosts(
+		context.Background(),
+		&hostsvc.PinHostsRequest{
+			Hostnames:  []string{"unknown"},
+			TtlSeconds: 60,
+		},
+	)
+	suite.NoError(err)
+	suite.NotNil(resp.GetError())
+
+	// Hosts cannot be pinned without a duration.
+	resp, err = suite.handler.PinHosts(
+		context.Background(),
+		&hostsvc.PinHostsRequest{Hostnames: []string{host1}},
+	)
+	suite.NoError(err)
+	suite.NotNil(resp.GetError())
+
+	_, err = suite.handler.UnpinHosts(
+		context.Background(),
+		&hostsvc.UnpinHostsRequest{Hostnames: []string{host1}},
+	)
+	suite.NoError(err)
+	pinnedHosts := suite.pool.GetPinnedHosts()
+	suite.Len(pinnedHosts, 1)
+	suite.Contains(pinnedHosts, host2)
+}
+
+// TestGetAndClearQuarantinedHosts tests listing the hosts quarantined since
+// their offers are rescinded repeatedly, and releasing them from quarantine.
+func (suite *HostMgrHandlerTestSuite) TestGetAndClearQuarantinedHosts() {
+	defer suite.ctrl.Finish()
+
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+	suite.pool.SetQuarantineConfig(offerpool.QuarantineConfig{
+		RescindThreshold: 1,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+	offers := suite.pool.AddOffers(context.Background(), generateOffers(2))
+	host1 := offers[0].GetHostname()
+	suite.pool.RescindOffer(offers[0].GetId())
+
+	getResp, err := suite.handler.GetQuarantinedHosts(
+		context.Background(),
+		&hostsvc.GetQuarantinedHostsRequest{},
+	)
+	suite.NoError(err)
+	suite.Len(getResp.GetHosts(), 1)
+	suite.Equal(host1, getResp.GetHosts()[0].GetHostname())
+	suite.NotEmpty(getResp.GetHosts()[0].GetExpiration())
+
+	// Hosts to clear quarantine of must be given, unless all are cleared.
+	clearResp, err := suite.handler.ClearHostQuarantine(
+		context.Background(),
+		&hostsvc.ClearHostQuarantineRequest{},
+	)
+	suite.NoError(err)
+	suite.NotNil(clearResp.GetError().GetInvalidArgument())
+
+	clearResp, err = suite.handler.ClearHostQuarantine(
+		context.Background(),
+		&hostsvc.ClearHostQuarantineRequest{
+			Hostnames: []string{host1, "unknown"},
+		},
+	)
+	suite.NoError(err)
+	suite.Nil(clearResp.GetError())
+	suite.Equal([]string{host1}, clearResp.GetClearedHostnames())
+	suite.Empty(suite.pool.GetQuarantinedHosts())
+}
+
+// TestGetHostResources tests getting the capacity of hosts along with the
+// resources offered and allocated to Peloton on them.
+func (suite *HostMgrHandlerTestSuite) TestGetHostResources() {
+	defer suite.ctrl.Finish()
+
+	getCPU := func(resources []*hostsvc.Resource) float64 {
+		for _, r := range resources {
+			if r.GetKind() == common.CPU {
+				return r.GetCapacity()
+			}
+		}
+		return 0
+	}
+
+	// No host is registered yet.
+	loader := &host.Loader{
+		OperatorClient: suite.masterOperatorClient,
+		Scope:          suite.testScope,
+		HostInfoOps:    suite.mockHostInfoOps,
+	}
+	suite.setupLoaderMocks(&mesos_master.Response_GetAgents{})
+	loader.Load(nil)
+
+	resp, err := suite.handler.GetHostResources(
+		context.Background(),
+		&hostsvc.GetHostResourcesRequest{},
+	)
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetClusterUnavailable())
+
+	suite.setupLoaderMocks(makeAgentsResponse(2))
+	loader.Load(nil)
+
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{
+		generateOfferWithResource("offer-0", "agent-0", "id-0", 0.5, 1, 1, 0),
+	})
+
+	mockSummary := hostsummary_mocks.NewMockHostSummary(suite.ctrl)
+	mockSummary.EXPECT().GetHostname().Return("id-1").AnyTimes()
+	mockSummary.EXPECT().GetAllocated().Return(models.HostResources{
+		NonSlack: scalar.Resources{CPU: 0.25},
+	}).AnyTimes()
+	suite.hostCache.EXPECT().GetSummaries().
+		Return([]hostsummary.HostSummary{mockSummary}).
+		AnyTimes()
+
+	resp, err = suite.handler.GetHostResources(
+		context.Background(),
+		&hostsvc.GetHostResourcesRequest{},
+	)
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Len(resp.GetHosts(), 2)
+
+	host0, host1 := resp.GetHosts()[0], resp.GetHosts()[1]
+	suite.Equal("id-0", host0.GetHostname())
+	suite.Equal(float64(_defaultResourceValue), getCPU(host0.GetPhysicalCapacity()))
+	suite.Equal(0.5, getCPU(host0.GetOffered()))
+	suite.Equal(float64(0), getCPU(host0.GetAllocated()))
+	suite.Equal("id-1", host1.GetHostname())
+	suite.Equal(float64(0), getCPU(host1.GetOffered()))
+	suite.Equal(0.25, getCPU(host1.GetAllocated()))
+
+	// Unknown hosts are skipped.
+	resp, err = suite.handler.GetHostResources(
+		context.Background(),
+		&hostsvc.GetHostResourcesRequest{
+			Hostnames: []string{"id-1", "unknown"},
+		},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetHosts(), 1)
+	suite.Equal("id-1", resp.GetHosts()[0].GetHostname())
+	suite.Equal(
+		int64(2),
+		suite.testScope.Snapshot().Counters()["get_host_resources+"].Value())
+}
+
 // Helper type to implement sorting on the slice
 type AgentSlice []*mesos_master.Response_GetAgents_Agent
 
@@ -0,0 +1,233 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostfilter compiles the attribute expressions of host filters,
+// which select hosts by boolean combinations (AND/OR/NOT) of comparisons
+// on the attributes of the hosts and the resources they offer, e.g.
+//
+//	attribute.zone == "dca1" && !(attribute.rack == r1) &&
+//	  (resource.cpu >= 4 || resource.gpu > 0)
+//
+// An expression is compiled once per request, and the compiled Expression
+// is evaluated against each host.
+package hostfilter
+
+import (
+	"fmt"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+)
+
+const (
+	// _attributePrefix is the prefix of the operands referring to the
+	// attributes of the hosts.
+	_attributePrefix = "attribute."
+
+	// _resourcePrefix is the prefix of the operands referring to the
+	// resources offered by the hosts.
+	_resourcePrefix = "resource."
+)
+
+// Expression is a compiled attribute expression.
+type Expression interface {
+	// Evaluate returns whether a host with the attributes and the offered
+	// resources satisfies the expression.
+	Evaluate(attributes []*mesos.Attribute, offered scalar.Resources) bool
+}
+
+// Compile compiles an attribute expression. An empty expression is
+// satisfied by every host. The error returned for an invalid expression
+// is up to the caller to report as an invalid argument.
+func Compile(expr string) (Expression, error) {
+	if expr == "" {
+		return trueExpression{}, nil
+	}
+
+	p, err := newParser(expr)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid attribute expression %q: %v", expr, err)
+	}
+	e, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf(
+			"invalid attribute expression %q: %v", expr, err)
+	}
+	return e, nil
+}
+
+// comparison operators
+type operator int
+
+const (
+	opEqual operator = iota
+	opNotEqual
+	opLess
+	opLessEqual
+	opGreater
+	opGreaterEqual
+)
+
+// compare returns the result of the comparison of two numbers.
+func (op operator) compare(a, b float64) bool {
+	switch op {
+	case opEqual:
+		return a == b
+	case opNotEqual:
+		return a != b
+	case opLess:
+		return a < b
+	case opLessEqual:
+		return a <= b
+	case opGreater:
+		return a > b
+	case opGreaterEqual:
+		return a >= b
+	}
+	return false
+}
+
+// trueExpression is satisfied by every host.
+type trueExpression struct{}
+
+func (trueExpression) Evaluate([]*mesos.Attribute, scalar.Resources) bool {
+	return true
+}
+
+// andExpression is satisfied if all its operands are.
+type andExpression []Expression
+
+func (e andExpression) Evaluate(
+	attributes []*mesos.Attribute,
+	offered scalar.Resources,
+) bool {
+	for _, operand := range e {
+		if !operand.Evaluate(attributes, offered) {
+			return false
+		}
+	}
+	return true
+}
+
+// orExpression is satisfied if any of its operands is.
+type orExpression []Expression
+
+func (e orExpression) Evaluate(
+	attributes []*mesos.Attribute,
+	offered scalar.Resources,
+) bool {
+	for _, operand := range e {
+		if operand.Evaluate(attributes, offered) {
+			return true
+		}
+	}
+	return false
+}
+
+// notExpression is satisfied if its operand is not.
+type notExpression struct {
+	operand Expression
+}
+
+func (e notExpression) Evaluate(
+	attributes []*mesos.Attribute,
+	offered scalar.Resources,
+) bool {
+	return !e.operand.Evaluate(attributes, offered)
+}
+
+// attributeComparison compares an attribute of the host with a value.
+// Text attributes are compared as strings for equality and as numbers
+// for ordering, scalar attributes as numbers, and set attributes are
+// equal to the value if they contain it. Hosts without the attribute
+// never satisfy the comparison.
+type attributeComparison struct {
+	name  string
+	op    operator
+	value string
+	// number is the value parsed as a number, if it is one.
+	number    float64
+	isNumeric bool
+}
+
+func (e attributeComparison) Evaluate(
+	attributes []*mesos.Attribute,
+	offered scalar.Resources,
+) bool {
+	for _, attribute := range attributes {
+		if attribute.GetName() == e.name {
+			return e.compare(attribute)
+		}
+	}
+	return false
+}
+
+func (e attributeComparison) compare(attribute *mesos.Attribute) bool {
+	switch attribute.GetType() {
+	case mesos.Value_TEXT:
+		text := attribute.GetText().GetValue()
+		switch e.op {
+		case opEqual:
+			return text == e.value
+		case opNotEqual:
+			return text != e.value
+		}
+		number, ok := parseNumber(text)
+		return ok && e.isNumeric && e.op.compare(number, e.number)
+
+	case mesos.Value_SCALAR:
+		return e.isNumeric &&
+			e.op.compare(attribute.GetScalar().GetValue(), e.number)
+
+	case mesos.Value_SET:
+		contains := false
+		for _, item := range attribute.GetSet().GetItem() {
+			if item == e.value {
+				contains = true
+				break
+			}
+		}
+		switch e.op {
+		case opEqual:
+			return contains
+		case opNotEqual:
+			return !contains
+		}
+	}
+	return false
+}
+
+// resourceComparison compares a resource offered by the host with a number.
+type resourceComparison struct {
+	get    func(scalar.Resources) float64
+	op     operator
+	number float64
+}
+
+func (e resourceComparison) Evaluate(
+	attributes []*mesos.Attribute,
+	offered scalar.Resources,
+) bool {
+	return e.op.compare(e.get(offered), e.number)
+}
+
+// resourceGetters are the functions returning the resources which can be
+// compared in expressions, keyed by their names.
+var resourceGetters = map[string]func(scalar.Resources) float64{
+	"cpu":  func(r scalar.Resources) float64 { return r.CPU },
+	"mem":  func(r scalar.Resources) float64 { return r.Mem },
+	"disk": func(r scalar.Resources) float64 { return r.Disk },
+	"gpu":  func(r scalar.Resources) float64 { return r.GPU },
+}
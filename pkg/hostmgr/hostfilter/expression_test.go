@@ -0,0 +1,116 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostfilter
+
+import (
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func textAttribute(name, value string) *mesos.Attribute {
+	return &mesos.Attribute{
+		Name: proto.String(name),
+		Type: mesos.Value_TEXT.Enum(),
+		Text: &mesos.Value_Text{Value: proto.String(value)},
+	}
+}
+
+func scalarAttribute(name string, value float64) *mesos.Attribute {
+	return &mesos.Attribute{
+		Name:   proto.String(name),
+		Type:   mesos.Value_SCALAR.Enum(),
+		Scalar: &mesos.Value_Scalar{Value: proto.Float64(value)},
+	}
+}
+
+func setAttribute(name string, items ...string) *mesos.Attribute {
+	return &mesos.Attribute{
+		Name: proto.String(name),
+		Type: mesos.Value_SET.Enum(),
+		Set:  &mesos.Value_Set{Item: items},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	attributes := []*mesos.Attribute{
+		textAttribute("zone", "dca1"),
+		textAttribute("rack", "r1"),
+		textAttribute("generation", "10"),
+		scalarAttribute("disks", 4),
+		setAttribute("features", "ssd", "10g"),
+	}
+	offered := scalar.Resources{CPU: 8, Mem: 1024, Disk: 100}
+
+	testTable := map[string]bool{
+		``:                                                  true,
+		`attribute.zone == "dca1"`:                          true,
+		`attribute.zone == dca1`:                            true,
+		`attribute.zone != dca1`:                            false,
+		`attribute.zone == "dca2"`:                          false,
+		`attribute.missing == "dca1"`:                       false,
+		`!(attribute.missing == "dca1")`:                    true,
+		`attribute.generation >= 8`:                         true,
+		`attribute.generation < 8`:                          false,
+		`attribute.disks > 2`:                               true,
+		`attribute.disks == 4`:                              true,
+		`attribute.features == ssd`:                         true,
+		`attribute.features != hdd`:                         true,
+		`attribute.features == hdd`:                         false,
+		`resource.cpu >= 4`:                                 true,
+		`resource.cpu>4&&resource.mem>=1024`:                true,
+		`resource.gpu > 0`:                                  false,
+		`resource.gpu > 0 || resource.disk > 50`:            true,
+		`attribute.zone == dca1 && !(attribute.rack == r1)`: false,
+		`attribute.zone == dca1 && (attribute.rack == r2 || resource.cpu > 4)`: true,
+		`!attribute.zone == dca1 || attribute.rack == r1`:                      true,
+		`attribute.zone == dca1 && attribute.rack == r2 || resource.cpu > 4`:   true,
+		`attribute.zone == dca2 || attribute.rack == r1 && resource.cpu > 10`:  false,
+		`attribute.generation < inf`:                                           false,
+		`attribute.generation != nan`:                                          true,
+	}
+
+	for expr, expected := range testTable {
+		e, err := Compile(expr)
+		require.NoError(t, err, expr)
+		require.Equal(t, expected, e.Evaluate(attributes, offered), expr)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		`attribute.zone`,
+		`attribute.zone ==`,
+		`attribute. == dca1`,
+		`resource.cpu == many`,
+		`resource.network > 1`,
+		`hostname == foo`,
+		`(attribute.zone == dca1`,
+		`attribute.zone == dca1)`,
+		`attribute.zone == "dca1`,
+		`attribute.zone == dca1 &&`,
+		`attribute.zone == dca1 & resource.cpu > 1`,
+		`attribute.zone = dca1`,
+		`resource.cpu > inf`,
+		`resource.cpu > NaN`,
+	} {
+		_, err := Compile(expr)
+		require.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostfilter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// token kinds
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+	tokenOperator
+)
+
+// token is a lexical token of an attribute expression.
+type token struct {
+	kind  tokenKind
+	text  string
+	op    operator
+	pos   int
+	value string
+}
+
+// operators are the comparison operators keyed by their text, with the
+// two characters operators first so they are matched before their prefix.
+var operators = []struct {
+	text string
+	op   operator
+}{
+	{"==", opEqual},
+	{"!=", opNotEqual},
+	{"<=", opLessEqual},
+	{">=", opGreaterEqual},
+	{"<", opLess},
+	{">", opGreater},
+}
+
+// tokenize splits an attribute expression into tokens.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{kind: tokenAnd, text: "&&", pos: i})
+			i += 2
+			continue
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{kind: tokenOr, text: "||", pos: i})
+			i += 2
+			continue
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", pos: i})
+			i++
+			continue
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", pos: i})
+			i++
+			continue
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %v", i, err)
+			}
+			tokens = append(tokens, token{
+				kind:  tokenString,
+				text:  expr[i : end+1],
+				pos:   i,
+				value: value,
+			})
+			i = end + 1
+			continue
+		}
+
+		if op, text, ok := matchOperator(expr[i:]); ok {
+			tokens = append(tokens, token{
+				kind: tokenOperator,
+				text: text,
+				op:   op,
+				pos:  i,
+			})
+			i += len(text)
+			continue
+		}
+
+		if c == '!' {
+			tokens = append(tokens, token{kind: tokenNot, text: "!", pos: i})
+			i++
+			continue
+		}
+
+		if !isIdentRune(c) {
+			return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+		end := i
+		for end < len(expr) && isIdentRune(rune(expr[end])) {
+			end++
+		}
+		text := expr[i:end]
+		kind := tokenIdent
+		if _, ok := parseNumber(text); ok {
+			kind = tokenNumber
+		}
+		tokens = append(tokens, token{
+			kind:  kind,
+			text:  text,
+			pos:   i,
+			value: text,
+		})
+		i = end
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+// parseNumber returns the value of a number. Infinities and NaN, which
+// strconv parses from identifiers such as inf and nan, are not numbers.
+func parseNumber(text string) (float64, bool) {
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, false
+	}
+	return number, true
+}
+
+// matchOperator returns the comparison operator the expression starts with.
+func matchOperator(expr string) (operator, string, bool) {
+	for _, o := range operators {
+		if strings.HasPrefix(expr, o.text) {
+			return o.op, o.text, true
+		}
+	}
+	return 0, "", false
+}
+
+// isIdentRune returns whether the rune can be part of an identifier or a
+// number.
+func isIdentRune(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) ||
+		c == '_' || c == '-' || c == '.'
+}
+
+// parser is a recursive descent parser of attribute expressions:
+//
+//	expr       := and ("||" and)*
+//	and        := unary ("&&" unary)*
+//	unary      := "!" unary | "(" expr ")" | comparison
+//	comparison := operand operator value
+//	operand    := "attribute." name | "resource." name
+//	value      := string | number | name
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// newParser returns a parser of the expression.
+func newParser(expr string) (*parser, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens}, nil
+}
+
+// parse parses the whole expression.
+func (p *parser) parse() (Expression, error) {
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return e, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	var operands orExpression
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	var operands andExpression
+	for {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, e)
+		if p.peek().kind != tokenAnd {
+			break
+		}
+		p.next()
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	switch t := p.peek(); t.kind {
+	case tokenNot:
+		p.next()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand: e}, nil
+
+	case tokenLeftParen:
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRightParen {
+			return nil, fmt.Errorf("expected \")\" at %d", t.pos)
+		}
+		return e, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	operand := p.next()
+	if operand.kind != tokenIdent {
+		return nil, fmt.Errorf("expected operand at %d", operand.pos)
+	}
+
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, fmt.Errorf("expected comparison operator at %d", op.pos)
+	}
+
+	value := p.next()
+	if value.kind != tokenIdent &&
+		value.kind != tokenString &&
+		value.kind != tokenNumber {
+		return nil, fmt.Errorf("expected value at %d", value.pos)
+	}
+	number, isNumeric := parseNumber(value.value)
+
+	switch {
+	case strings.HasPrefix(operand.text, _attributePrefix):
+		name := strings.TrimPrefix(operand.text, _attributePrefix)
+		if name == "" {
+			return nil, fmt.Errorf("empty attribute name at %d", operand.pos)
+		}
+		return attributeComparison{
+			name:      name,
+			op:        op.op,
+			value:     value.value,
+			number:    number,
+			isNumeric: isNumeric,
+		}, nil
+
+	case strings.HasPrefix(operand.text, _resourcePrefix):
+		name := strings.TrimPrefix(operand.text, _resourcePrefix)
+		get, ok := resourceGetters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown resource %q at %d", name, operand.pos)
+		}
+		if !isNumeric {
+			return nil, fmt.Errorf(
+				"resource %q compared with non-numeric value at %d",
+				name, value.pos)
+		}
+		return resourceComparison{
+			get:    get,
+			op:     op.op,
+			number: number,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown operand %q at %d", operand.text, operand.pos)
+}
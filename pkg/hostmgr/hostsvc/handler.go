@@ -16,11 +16,13 @@ package hostsvc
 
 import (
 	"context"
+	"sort"
 
 	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/host/drainer"
@@ -135,6 +137,29 @@ func (m *serviceHandler) QueryHosts(
 			}
 		}
 	}
+	// Order the hosts by hostname, so that the result can be paginated
+	sort.Slice(hostInfos, func(i, j int) bool {
+		return hostInfos[i].GetHostname() < hostInfos[j].GetHostname()
+	})
+
+	hostnames := make([]string, 0, len(hostInfos))
+	for _, h := range hostInfos {
+		hostnames = append(hostnames, h.GetHostname())
+	}
+	hostStates := hostStateSet.ToSlice()
+	sort.Strings(hostStates)
+	start, end, nextPageToken, err := pagination.Page(
+		request.GetPageToken(),
+		request.GetPageSize(),
+		pagination.Fingerprint(hostStates...),
+		hostnames,
+	)
+	if err != nil {
+		m.metrics.QueryHostsFail.Inc(1)
+		return nil, err
+	}
+	hostInfos = hostInfos[start:end]
+
 	if m.hostPoolManager != nil {
 		for _, h := range hostInfos {
 			p, err := m.hostPoolManager.GetPoolByHostname(h.GetHostname())
@@ -150,7 +175,8 @@ func (m *serviceHandler) QueryHosts(
 
 	m.metrics.QueryHostsSuccess.Inc(1)
 	return &host_svc.QueryHostsResponse{
-		HostInfos: hostInfos,
+		HostInfos:     hostInfos,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
@@ -168,7 +194,8 @@ func (m *serviceHandler) StartMaintenance(
 	var errs error
 	if len(request.GetHostnames()) != 0 {
 		for _, hostname := range request.GetHostnames() {
-			if err := m.startMaintenance(ctx, hostname); err != nil {
+			if err := m.startMaintenance(
+				ctx, hostname, request.GetReason()); err != nil {
 				// Not error out on 1rst error, continue and aggregate errors
 				errs = multierr.Append(errs, err)
 			}
@@ -179,7 +206,8 @@ func (m *serviceHandler) StartMaintenance(
 		return &host_svc.StartMaintenanceResponse{}, nil
 	}
 	// StartMaintenanceRequest using prefered field `hostname`
-	if err := m.startMaintenance(ctx, request.GetHostname()); err != nil {
+	if err := m.startMaintenance(
+		ctx, request.GetHostname(), request.GetReason()); err != nil {
 		if yarpcerrors.IsStatus(err) {
 			// Allow YARPC NotFound error to be returned as such
 			return nil, err
@@ -194,8 +222,10 @@ func (m *serviceHandler) StartMaintenance(
 func (m *serviceHandler) startMaintenance(
 	ctx context.Context,
 	hostname string,
+	reason string,
 ) error {
 	m.metrics.StartMaintenanceAPI.Inc(1)
+	logAudit(ctx, "start_maintenance", hostname, reason)
 	if err := m.drainer.StartMaintenance(ctx, hostname); err != nil {
 		m.metrics.StartMaintenanceFail.Inc(1)
 		return err
@@ -216,7 +246,8 @@ func (m *serviceHandler) CompleteMaintenance(
 	var errs error
 	if len(request.GetHostnames()) != 0 {
 		for _, hostname := range request.GetHostnames() {
-			if err := m.completeMaintenance(ctx, hostname); err != nil {
+			if err := m.completeMaintenance(
+				ctx, hostname, request.GetReason()); err != nil {
 				// Not error out on 1rst error, continue and aggregate errors
 				errs = multierr.Append(errs, err)
 			}
@@ -227,7 +258,8 @@ func (m *serviceHandler) CompleteMaintenance(
 		return &host_svc.CompleteMaintenanceResponse{}, nil
 	}
 	// CompleteMaintenanceRequest using prefered field `hostname`
-	if err := m.completeMaintenance(ctx, request.GetHostname()); err != nil {
+	if err := m.completeMaintenance(
+		ctx, request.GetHostname(), request.GetReason()); err != nil {
 		if yarpcerrors.IsYARPCError(err) {
 			// Allow YARPC NotFound error to be returned as such
 			return nil, err
@@ -242,8 +274,10 @@ func (m *serviceHandler) CompleteMaintenance(
 func (m *serviceHandler) completeMaintenance(
 	ctx context.Context,
 	hostname string,
+	reason string,
 ) error {
 	m.metrics.CompleteMaintenanceAPI.Inc(1)
+	logAudit(ctx, "complete_maintenance", hostname, reason)
 	if err := m.drainer.CompleteMaintenance(ctx, hostname); err != nil {
 		m.metrics.CompleteMaintenanceFail.Inc(1)
 		return err
@@ -253,6 +287,23 @@ func (m *serviceHandler) completeMaintenance(
 	return nil
 }
 
+// logAudit records a host maintenance request in the audit log along
+// with its caller and the reason given for it.
+func logAudit(
+	ctx context.Context,
+	action string,
+	hostname string,
+	reason string,
+) {
+	log.WithFields(log.Fields{
+		"audit":    true,
+		"action":   action,
+		"hostname": hostname,
+		"reason":   reason,
+		"caller":   yarpc.CallFromContext(ctx).Caller(),
+	}).Info("host maintenance requested")
+}
+
 // List all host pools
 func (m *serviceHandler) ListHostPools(
 	ctx context.Context,
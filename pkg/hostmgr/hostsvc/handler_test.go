@@ -39,6 +39,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type hostSvcHandlerTestSuite struct {
@@ -462,6 +463,52 @@ func (suite *hostSvcHandlerTestSuite) TestQueryHostsError() {
 	suite.NotNil(resp)
 }
 
+// TestQueryHostsPagination tests iterating over the hosts page by page
+func (suite *hostSvcHandlerTestSuite) TestQueryHostsPagination() {
+	suite.handler.hostPoolManager = nil
+
+	hostInfos := []*hpb.HostInfo{
+		{Hostname: "host-c", State: hpb.HostState_HOST_STATE_DRAINING},
+		{Hostname: "host-a", State: hpb.HostState_HOST_STATE_DRAINING},
+		{Hostname: "host-b", State: hpb.HostState_HOST_STATE_DRAINING},
+	}
+	suite.mockDrainer.EXPECT().
+		GetAllDrainingHostInfos().
+		Return(hostInfos, nil).
+		AnyTimes()
+	suite.mockDrainer.EXPECT().
+		GetAllDrainedHostInfos().
+		Return(nil, nil).
+		AnyTimes()
+	suite.mockDrainer.EXPECT().
+		GetAllDownHostInfos().
+		Return(nil, nil).
+		AnyTimes()
+
+	req := &svcpb.QueryHostsRequest{
+		HostStates: []hpb.HostState{hpb.HostState_HOST_STATE_DRAINING},
+		PageSize:   2,
+	}
+	resp, err := suite.handler.QueryHosts(suite.ctx, req)
+	suite.NoError(err)
+	suite.Len(resp.GetHostInfos(), 2)
+	suite.Equal("host-a", resp.GetHostInfos()[0].GetHostname())
+	suite.Equal("host-b", resp.GetHostInfos()[1].GetHostname())
+	suite.NotEmpty(resp.GetNextPageToken())
+
+	req.PageToken = resp.GetNextPageToken()
+	resp, err = suite.handler.QueryHosts(suite.ctx, req)
+	suite.NoError(err)
+	suite.Len(resp.GetHostInfos(), 1)
+	suite.Equal("host-c", resp.GetHostInfos()[0].GetHostname())
+	suite.Empty(resp.GetNextPageToken())
+
+	// the token is only valid for the query it was issued for
+	req.HostStates = []hpb.HostState{hpb.HostState_HOST_STATE_DRAINED}
+	_, err = suite.handler.QueryHosts(suite.ctx, req)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
 func (suite *hostSvcHandlerTestSuite) TestQueryHostsHostPoolsNotEnabled() {
 	suite.handler.hostPoolManager = nil
 	suite.doTestQueryHosts()
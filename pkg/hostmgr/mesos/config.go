@@ -14,11 +14,20 @@
 
 package mesos
 
+import (
+	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
+)
+
 // Config for Mesos specific configuration
 type Config struct {
 	Framework *FrameworkConfig `yaml:"framework"`
 	ZkPath    string           `yaml:"zk_path"`
 	Encoding  string           `yaml:"encoding"`
+
+	// Circuit breakers guarding the calls to the scheduler and the
+	// operator API of Mesos master.
+	SchedulerBreaker mpb.BreakerConfig `yaml:"scheduler_circuit_breaker"`
+	OperatorBreaker  mpb.BreakerConfig `yaml:"operator_circuit_breaker"`
 }
 
 // FrameworkConfig for framework specific configuration
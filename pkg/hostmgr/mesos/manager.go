@@ -96,9 +96,13 @@ func (m *mesosManager) Failure(ctx context.Context, body *sched.Event) error {
 	return nil
 }
 
+// Error is called when the master sends an ERROR event before closing the
+// subscription stream, for example when the framework is removed. The
+// stream is then reconnected by the host manager server, which clears the
+// offers and reconciles the tasks once subscribed again.
 func (m *mesosManager) Error(ctx context.Context, body *sched.Event) error {
 	err := body.GetError()
-	log.WithField("error", err).Debug("mesosManager: error called")
+	log.WithField("error", err).Warn("mesosManager: error called")
 	return nil
 }
 
@@ -0,0 +1,242 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_defaultFailureThreshold = 5
+	_defaultOpenDuration     = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned without calling Mesos master when the
+// circuit breaker guarding the call is open.
+var ErrCircuitOpen = yarpcerrors.UnavailableErrorf(
+	"mesos master circuit breaker is open")
+
+// BreakerConfig is the configuration of a circuit breaker guarding the
+// outbound calls to Mesos master.
+type BreakerConfig struct {
+	// Number of consecutive failed calls after which the breaker opens.
+	// A negative value disables the breaker.
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// Duration for which calls fail fast once the breaker opens, before
+	// a single trial call is let through to probe Mesos master.
+	OpenDuration time.Duration `yaml:"open_duration"`
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// CircuitBreaker fails calls to Mesos master fast after a number of
+// consecutive failures, so that callers do not pile up on a degraded
+// master. All the methods are safe to call on a nil breaker, which
+// lets every call through.
+type CircuitBreaker struct {
+	sync.Mutex
+
+	name     string
+	cfg      BreakerConfig
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// set while the trial call of a half open breaker is in flight
+	trialInFlight bool
+
+	now     func() time.Time
+	metrics *breakerMetrics
+}
+
+type breakerMetrics struct {
+	open     tally.Gauge
+	opened   tally.Counter
+	rejected tally.Counter
+	failures tally.Counter
+}
+
+// NewCircuitBreaker returns a new circuit breaker with the given name.
+// Returns nil if the breaker is disabled by the config.
+func NewCircuitBreaker(
+	name string,
+	cfg BreakerConfig,
+	parent tally.Scope) *CircuitBreaker {
+	if cfg.FailureThreshold < 0 {
+		return nil
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = _defaultFailureThreshold
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = _defaultOpenDuration
+	}
+
+	scope := parent.SubScope("circuit_breaker").
+		Tagged(map[string]string{"breaker": name})
+	b := &CircuitBreaker{
+		name: name,
+		cfg:  cfg,
+		now:  time.Now,
+		metrics: &breakerMetrics{
+			open:     scope.Gauge("open"),
+			opened:   scope.Counter("opened"),
+			rejected: scope.Counter("rejected"),
+			failures: scope.Counter("failures"),
+		},
+	}
+	b.metrics.open.Update(0)
+	return b
+}
+
+// Name returns the name of the breaker.
+func (b *CircuitBreaker) Name() string {
+	if b == nil {
+		return ""
+	}
+	return b.name
+}
+
+// IsOpen returns true if calls guarded by the breaker currently fail
+// fast, i.e. the breaker is open and not yet due for a trial call, or
+// the trial call is still in flight.
+func (b *CircuitBreaker) IsOpen() bool {
+	if b == nil {
+		return false
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		return b.now().Sub(b.openedAt) < b.cfg.OpenDuration
+	case breakerHalfOpen:
+		return b.trialInFlight
+	}
+	return false
+}
+
+// Do calls fn if the breaker allows it and records its result.
+// Returns ErrCircuitOpen without calling fn if the breaker is open.
+func (b *CircuitBreaker) Do(fn func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// allow returns ErrCircuitOpen if a call should not be made.
+func (b *CircuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenDuration {
+			b.metrics.rejected.Inc(1)
+			return ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		b.trialInFlight = true
+	case breakerHalfOpen:
+		if b.trialInFlight {
+			b.metrics.rejected.Inc(1)
+			return ErrCircuitOpen
+		}
+		b.trialInFlight = true
+	}
+	return nil
+}
+
+// record updates the state of the breaker with the result of a call.
+func (b *CircuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.trialInFlight = false
+	if !isBreakerFailure(err) {
+		if b.state != breakerClosed {
+			log.WithField("breaker", b.name).
+				Info("Mesos master circuit breaker closed")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		b.metrics.open.Update(0)
+		return
+	}
+
+	b.metrics.failures.Inc(1)
+	b.failures++
+	if b.state == breakerHalfOpen ||
+		(b.state == breakerClosed && b.failures >= b.cfg.FailureThreshold) {
+		log.WithFields(log.Fields{
+			"breaker":  b.name,
+			"failures": b.failures,
+		}).WithError(err).
+			Warn("Mesos master circuit breaker opened")
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		b.metrics.opened.Inc(1)
+		b.metrics.open.Update(1)
+	}
+}
+
+// isBreakerFailure returns true if the error indicates that Mesos master
+// is unhealthy. Errors caused by the request itself do not count.
+func isBreakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	cause := errors.Cause(err)
+	if !yarpcerrors.IsStatus(cause) {
+		return true
+	}
+
+	switch yarpcerrors.FromError(cause).Code() {
+	case yarpcerrors.CodeInvalidArgument,
+		yarpcerrors.CodeNotFound,
+		yarpcerrors.CodeAlreadyExists,
+		yarpcerrors.CodeFailedPrecondition,
+		yarpcerrors.CodePermissionDenied,
+		yarpcerrors.CodeUnauthenticated,
+		yarpcerrors.CodeOutOfRange:
+		return false
+	}
+	return true
+}
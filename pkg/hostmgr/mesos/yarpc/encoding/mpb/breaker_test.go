@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type circuitBreakerTestSuite struct {
+	suite.Suite
+
+	now     time.Time
+	breaker *CircuitBreaker
+}
+
+func (suite *circuitBreakerTestSuite) SetupTest() {
+	suite.now = time.Now()
+	suite.breaker = NewCircuitBreaker(
+		"test",
+		BreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute},
+		tally.NoopScope)
+	suite.breaker.now = func() time.Time { return suite.now }
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	suite.Run(t, new(circuitBreakerTestSuite))
+}
+
+func (suite *circuitBreakerTestSuite) fail() error {
+	return suite.breaker.Do(func() error {
+		return yarpcerrors.UnavailableErrorf("master unavailable")
+	})
+}
+
+func (suite *circuitBreakerTestSuite) succeed() error {
+	return suite.breaker.Do(func() error { return nil })
+}
+
+// TestNewCircuitBreakerDisabled tests that a negative failure threshold
+// disables the breaker and a nil breaker lets every call through.
+func (suite *circuitBreakerTestSuite) TestNewCircuitBreakerDisabled() {
+	breaker := NewCircuitBreaker(
+		"test",
+		BreakerConfig{FailureThreshold: -1},
+		tally.NoopScope)
+	suite.Nil(breaker)
+
+	called := 0
+	for i := 0; i < 10; i++ {
+		breaker.Do(func() error {
+			called++
+			return errors.New("failed")
+		})
+	}
+	suite.Equal(10, called)
+	suite.False(breaker.IsOpen())
+}
+
+// TestCircuitBreakerOpens tests that the breaker opens after consecutive
+// failures and rejects calls until the open duration expires.
+func (suite *circuitBreakerTestSuite) TestCircuitBreakerOpens() {
+	suite.Error(suite.fail())
+	suite.NoError(suite.succeed())
+	suite.Error(suite.fail())
+	suite.False(suite.breaker.IsOpen())
+
+	suite.Error(suite.fail())
+	suite.True(suite.breaker.IsOpen())
+
+	called := false
+	err := suite.breaker.Do(func() error {
+		called = true
+		return nil
+	})
+	suite.Equal(ErrCircuitOpen, err)
+	suite.True(yarpcerrors.IsUnavailable(err))
+	suite.False(called)
+
+	suite.now = suite.now.Add(time.Minute)
+	suite.False(suite.breaker.IsOpen())
+}
+
+// TestCircuitBreakerTrialCall tests that a single trial call is let
+// through once the open duration expires, which closes the breaker on
+// success and reopens it on failure.
+func (suite *circuitBreakerTestSuite) TestCircuitBreakerTrialCall() {
+	suite.fail()
+	suite.fail()
+	suite.now = suite.now.Add(time.Minute)
+
+	// the trial call fails, the breaker reopens
+	suite.Error(suite.fail())
+	suite.True(suite.breaker.IsOpen())
+	suite.Equal(ErrCircuitOpen, suite.succeed())
+
+	suite.now = suite.now.Add(time.Minute)
+	err := suite.breaker.Do(func() error {
+		// other calls are rejected while the trial is in flight
+		suite.True(suite.breaker.IsOpen())
+		suite.Equal(ErrCircuitOpen, suite.succeed())
+		return nil
+	})
+	suite.NoError(err)
+	suite.False(suite.breaker.IsOpen())
+
+	// failure count is reset once the breaker closes
+	suite.fail()
+	suite.False(suite.breaker.IsOpen())
+}
+
+// TestCircuitBreakerIgnoresCallerErrors tests that errors caused by the
+// request itself do not open the breaker.
+func (suite *circuitBreakerTestSuite) TestCircuitBreakerIgnoresCallerErrors() {
+	for i := 0; i < 5; i++ {
+		suite.Error(suite.breaker.Do(func() error {
+			return errors.Wrap(
+				yarpcerrors.InvalidArgumentErrorf("bad request"),
+				"error making call")
+		}))
+	}
+	suite.False(suite.breaker.IsOpen())
+}
@@ -53,15 +53,20 @@ type MasterOperatorClient interface {
 type masterOperatorClient struct {
 	cfg         transport.ClientConfig
 	contentType string
+	breaker     *CircuitBreaker
 }
 
 // NewMasterOperatorClient builds a new Mesos Master Operator JSON client.
+// Calls fail fast with ErrCircuitOpen while the breaker is open; a nil
+// breaker lets every call through.
 func NewMasterOperatorClient(
 	c transport.ClientConfig,
-	contentType string) MasterOperatorClient {
+	contentType string,
+	breaker *CircuitBreaker) MasterOperatorClient {
 	return &masterOperatorClient{
 		cfg:         c,
 		contentType: contentType,
+		breaker:     breaker,
 	}
 }
 
@@ -92,7 +97,12 @@ func (mo *masterOperatorClient) call(ctx context.Context, msg *mesos_master.Call
 		Body:      strings.NewReader(reqBody),
 	}
 
-	resp, err := mo.cfg.GetUnaryOutbound().Call(ctx, &tReq)
+	var resp *transport.Response
+	err = mo.breaker.Do(func() error {
+		var err error
+		resp, err = mo.cfg.GetUnaryOutbound().Call(ctx, &tReq)
+		return err
+	})
 	if err != nil {
 		errMsg := fmt.Sprintf("error making call %s", _procedure)
 		log.WithError(err).Error(errMsg)
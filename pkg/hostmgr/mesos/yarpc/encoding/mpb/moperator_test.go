@@ -61,7 +61,7 @@ func (suite *masterOperatorClientTestSuite) SetupTest() {
 	mockClientCfg := transport_mocks.NewMockClientConfig(suite.ctrl)
 	suite.mockClientCfg = mockClientCfg
 	suite.defaultEncoding = ContentTypeProtobuf
-	suite.masterOperatorClient = NewMasterOperatorClient(suite.mockClientCfg, suite.defaultEncoding)
+	suite.masterOperatorClient = NewMasterOperatorClient(suite.mockClientCfg, suite.defaultEncoding, nil)
 }
 
 func (suite *masterOperatorClientTestSuite) TearDownTest() {
@@ -244,7 +244,7 @@ func (suite *masterOperatorClientTestSuite) TestMasterOperatorClient_AllocatedRe
 			)
 		}
 
-		mOClient := NewMasterOperatorClient(suite.mockClientCfg, tt.encoding)
+		mOClient := NewMasterOperatorClient(suite.mockClientCfg, tt.encoding, nil)
 		resources, err := mOClient.AllocatedResources(tt.frameworkID)
 
 		if tt.errMsg != "" {
@@ -358,7 +358,7 @@ func (suite *masterOperatorClientTestSuite) TestMasterOperatorClient_TaskAllocat
 		),
 	)
 
-	mOClient := NewMasterOperatorClient(suite.mockClientCfg, suite.defaultEncoding)
+	mOClient := NewMasterOperatorClient(suite.mockClientCfg, suite.defaultEncoding, nil)
 	allocated, offered, err := mOClient.GetTasksAllocation("peloton")
 	suite.Equal(len(allocatedResources), len(allocated))
 	suite.Equal(len(offeredResources), len(offered))
@@ -32,17 +32,24 @@ type SchedulerClient interface {
 	Call(mesosStreamID string, msg *mesos_v1_scheduler.Call) error
 }
 
-// NewSchedulerClient builds a new Mesos Scheduler JSON client.
-func NewSchedulerClient(c transport.ClientConfig, contentType string) SchedulerClient {
+// NewSchedulerClient builds a new Mesos Scheduler JSON client. Calls
+// fail fast with ErrCircuitOpen while the breaker is open; a nil breaker
+// lets every call through.
+func NewSchedulerClient(
+	c transport.ClientConfig,
+	contentType string,
+	breaker *CircuitBreaker) SchedulerClient {
 	return &schedulerClient{
 		cfg:         c,
 		contentType: contentType,
+		breaker:     breaker,
 	}
 }
 
 type schedulerClient struct {
 	cfg         transport.ClientConfig
 	contentType string
+	breaker     *CircuitBreaker
 }
 
 func (c *schedulerClient) Call(mesosStreamID string, msg *mesos_v1_scheduler.Call) error {
@@ -69,9 +76,13 @@ func (c *schedulerClient) Call(mesosStreamID string, msg *mesos_v1_scheduler.Cal
 		Body:      strings.NewReader(body),
 	}
 
-	ctx, _ := context.WithTimeout(context.Background(), 100*1000*time.Millisecond)
-	_, err = c.cfg.GetUnaryOutbound().Call(ctx, &treq)
-
 	// All Mesos calls are one-way so no need to decode response body
-	return err
+	return c.breaker.Do(func() error {
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 100*1000*time.Millisecond)
+		defer cancel()
+
+		_, err := c.cfg.GetUnaryOutbound().Call(ctx, &treq)
+		return err
+	})
 }
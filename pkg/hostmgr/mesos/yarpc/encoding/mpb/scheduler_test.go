@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
@@ -30,6 +31,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 )
 
 type schedulerClientTestSuite struct {
@@ -153,7 +155,7 @@ func (suite *schedulerClientTestSuite) TestSchedulerClient_Call() {
 
 		}
 
-		schedClient := NewSchedulerClient(suite.mockClientCfg, tt.encoding)
+		schedClient := NewSchedulerClient(suite.mockClientCfg, tt.encoding, nil)
 		err := schedClient.Call("123", tt.callMsg)
 		if tt.errMsg != "" {
 			suite.EqualError(err, tt.errMsg)
@@ -164,6 +166,31 @@ func (suite *schedulerClientTestSuite) TestSchedulerClient_Call() {
 	}
 }
 
+// TestSchedulerClientCallCircuitOpen tests that calls fail fast without
+// reaching Mesos master once the circuit breaker opens.
+func (suite *schedulerClientTestSuite) TestSchedulerClientCallCircuitOpen() {
+	breaker := NewCircuitBreaker(
+		"scheduler",
+		BreakerConfig{FailureThreshold: 1, OpenDuration: time.Hour},
+		tally.NoopScope)
+	mockUnaryOutbound := transport_mocks.NewMockUnaryOutbound(suite.ctrl)
+
+	suite.mockClientCfg.EXPECT().Caller().Return("testCall").Times(2)
+	suite.mockClientCfg.EXPECT().Service().Return("testSvc").Times(2)
+	suite.mockClientCfg.EXPECT().GetUnaryOutbound().Return(mockUnaryOutbound)
+	mockUnaryOutbound.EXPECT().Call(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("connection error"))
+
+	schedClient := NewSchedulerClient(
+		suite.mockClientCfg, suite.defaultEncoding, breaker)
+	callType := sched.Call_DECLINE
+	msg := &sched.Call{Type: &callType}
+
+	suite.EqualError(schedClient.Call("123", msg), "connection error")
+	suite.True(breaker.IsOpen())
+	suite.Equal(ErrCircuitOpen, schedClient.Call("123", msg))
+}
+
 func TestSchedulerClientTestSuite(t *testing.T) {
 	suite.Run(t, new(schedulerClientTestSuite))
 }
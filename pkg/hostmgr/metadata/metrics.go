@@ -0,0 +1,44 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the metrics relevant for
+// the task metadata registry.
+type Metrics struct {
+	Registered tally.Gauge
+
+	Get             tally.Counter
+	GetFail         tally.Counter
+	GetUnauthorized tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &Metrics{
+		Registered: scope.Gauge("registered"),
+
+		Get:             successScope.Counter("get"),
+		GetFail:         failScope.Counter("get"),
+		GetUnauthorized: failScope.Counter("get_unauthorized"),
+	}
+}
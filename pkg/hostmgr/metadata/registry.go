@@ -0,0 +1,198 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/util"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// Path is the HTTP path the metadata endpoint is served on.
+	Path = "/task/metadata"
+
+	// TokenEnvVar is the name of the environment variable through which
+	// the metadata token of a task is passed to the task at launch.
+	TokenEnvVar = "PELOTON_METADATA_TOKEN"
+
+	// TokenHeader is the HTTP header in which a task presents its
+	// metadata token to the metadata endpoint.
+	TokenHeader = "X-Peloton-Metadata-Token"
+
+	// _tokenBytes is the number of random bytes in a metadata token.
+	_tokenBytes = 32
+)
+
+// TaskMetadata is the metadata of a task served by the metadata endpoint.
+type TaskMetadata struct {
+	// TaskID is the mesos task ID of the task
+	TaskID string `json:"task_id"`
+	// JobID is the ID of the job the task belongs to
+	JobID string `json:"job_id"`
+	// InstanceID is the instance ID of the task in the job
+	InstanceID uint32 `json:"instance_id"`
+	// Labels are the labels of the task
+	Labels map[string]string `json:"labels,omitempty"`
+	// Ports are the ports allocated to the task keyed by port name
+	Ports map[string]uint32 `json:"ports,omitempty"`
+	// Secrets are the container paths the secrets of the task are
+	// mounted on. The secret data itself is never served.
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// NewTaskMetadata builds the metadata of a task from its config.
+func NewTaskMetadata(
+	mesosTaskID string,
+	config *task.TaskConfig,
+	ports map[string]uint32,
+) (*TaskMetadata, error) {
+	jobID, instanceID, err := util.ParseJobAndInstanceID(mesosTaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &TaskMetadata{
+		TaskID:     mesosTaskID,
+		JobID:      jobID,
+		InstanceID: instanceID,
+		Ports:      ports,
+	}
+
+	if len(config.GetLabels()) > 0 {
+		md.Labels = make(map[string]string)
+		for _, l := range config.GetLabels() {
+			md.Labels[l.GetKey()] = l.GetValue()
+		}
+	}
+
+	for _, v := range config.GetContainer().GetVolumes() {
+		if v.GetSource().GetType() == mesos.Volume_Source_SECRET {
+			md.Secrets = append(md.Secrets, v.GetContainerPath())
+		}
+	}
+	return md, nil
+}
+
+// Registry keeps the metadata of the tasks launched by host manager
+// along with the per-task tokens issued to them at launch, and serves
+// the metadata of a task to the holder of its token. The registry is
+// kept in memory, so tasks launched by a previous host manager leader
+// cannot query their metadata until they are relaunched.
+type Registry struct {
+	sync.RWMutex
+
+	// tokens maps the mesos task ID of a task to its token
+	tokens map[string]string
+	// tasks maps the token of a task to its metadata
+	tasks map[string]*TaskMetadata
+
+	metrics *Metrics
+}
+
+// NewRegistry creates a new metadata Registry.
+func NewRegistry(parent tally.Scope) *Registry {
+	return &Registry{
+		tokens:  make(map[string]string),
+		tasks:   make(map[string]*TaskMetadata),
+		metrics: NewMetrics(parent.SubScope("task_metadata")),
+	}
+}
+
+// Register adds the metadata of a task to be launched, and returns the
+// token the task has to present to query it. A task which is registered
+// again gets a new token and its previous token is revoked.
+func (r *Registry) Register(md *TaskMetadata) (string, error) {
+	b := make([]byte, _tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+
+	r.Lock()
+	defer r.Unlock()
+
+	if prev, ok := r.tokens[md.TaskID]; ok {
+		delete(r.tasks, prev)
+	}
+	r.tokens[md.TaskID] = token
+	r.tasks[token] = md
+	r.metrics.Registered.Update(float64(len(r.tokens)))
+	return token, nil
+}
+
+// Unregister removes the metadata of a task and revokes its token.
+func (r *Registry) Unregister(mesosTaskID string) {
+	r.Lock()
+	defer r.Unlock()
+
+	if token, ok := r.tokens[mesosTaskID]; ok {
+		delete(r.tasks, token)
+		delete(r.tokens, mesosTaskID)
+	}
+	r.metrics.Registered.Update(float64(len(r.tokens)))
+}
+
+// get returns the metadata of the task the token was issued to.
+func (r *Registry) get(token string) (*TaskMetadata, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	md, ok := r.tasks[token]
+	return md, ok
+}
+
+// ServeHTTP serves the metadata of the task the token presented in the
+// request was issued to.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := req.Header.Get(TokenHeader)
+	if token == "" {
+		r.metrics.GetUnauthorized.Inc(1)
+		http.Error(w, "missing metadata token", http.StatusUnauthorized)
+		return
+	}
+
+	md, ok := r.get(token)
+	if !ok {
+		r.metrics.GetUnauthorized.Inc(1)
+		http.Error(w, "invalid metadata token", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(md); err != nil {
+		log.WithError(err).
+			WithField("task_id", md.TaskID).
+			Warn("failed to write task metadata")
+		r.metrics.GetFail.Inc(1)
+		return
+	}
+	r.metrics.Get.Inc(1)
+}
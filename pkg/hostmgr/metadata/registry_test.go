@@ -0,0 +1,129 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+const _testTaskID = "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-2"
+
+type RegistryTestSuite struct {
+	suite.Suite
+
+	registry *Registry
+}
+
+func (suite *RegistryTestSuite) SetupTest() {
+	suite.registry = NewRegistry(tally.NoopScope)
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func (suite *RegistryTestSuite) get(token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	if token != "" {
+		req.Header.Set(TokenHeader, token)
+	}
+	rec := httptest.NewRecorder()
+	suite.registry.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestNewTaskMetadata tests building the metadata of a task from its config
+func (suite *RegistryTestSuite) TestNewTaskMetadata() {
+	secretType := mesos.Volume_Source_SECRET
+	secretPath := "/tmp/secret"
+	config := &task.TaskConfig{
+		Labels: []*peloton.Label{{Key: "team", Value: "compute"}},
+		Container: &mesos.ContainerInfo{
+			Volumes: []*mesos.Volume{
+				{
+					ContainerPath: &secretPath,
+					Source:        &mesos.Volume_Source{Type: &secretType},
+				},
+			},
+		},
+	}
+
+	md, err := NewTaskMetadata(
+		_testTaskID, config, map[string]uint32{"http": 31000})
+	suite.NoError(err)
+	suite.Equal("bca875f5-322a-4439-b0c9-63e3cf9f982e", md.JobID)
+	suite.Equal(uint32(1), md.InstanceID)
+	suite.Equal(map[string]string{"team": "compute"}, md.Labels)
+	suite.Equal(map[string]uint32{"http": 31000}, md.Ports)
+	suite.Equal([]string{secretPath}, md.Secrets)
+
+	_, err = NewTaskMetadata("invalid", config, nil)
+	suite.Error(err)
+}
+
+// TestServeMetadata tests serving the metadata of a task to the holder
+// of its token
+func (suite *RegistryTestSuite) TestServeMetadata() {
+	md, err := NewTaskMetadata(_testTaskID, &task.TaskConfig{}, nil)
+	suite.NoError(err)
+
+	token, err := suite.registry.Register(md)
+	suite.NoError(err)
+	suite.NotEmpty(token)
+
+	rec := suite.get(token)
+	suite.Equal(http.StatusOK, rec.Code)
+	result := &TaskMetadata{}
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), result))
+	suite.Equal(md, result)
+
+	suite.Equal(http.StatusUnauthorized, suite.get("").Code)
+	suite.Equal(http.StatusUnauthorized, suite.get("invalid").Code)
+
+	req := httptest.NewRequest(http.MethodPost, Path, nil)
+	req.Header.Set(TokenHeader, token)
+	rec = httptest.NewRecorder()
+	suite.registry.ServeHTTP(rec, req)
+	suite.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+// TestRegisterRevokesPreviousToken tests that registering a task again
+// revokes the token previously issued to it
+func (suite *RegistryTestSuite) TestRegisterRevokesPreviousToken() {
+	md, err := NewTaskMetadata(_testTaskID, &task.TaskConfig{}, nil)
+	suite.NoError(err)
+
+	token1, err := suite.registry.Register(md)
+	suite.NoError(err)
+	token2, err := suite.registry.Register(md)
+	suite.NoError(err)
+	suite.NotEqual(token1, token2)
+
+	suite.Equal(http.StatusUnauthorized, suite.get(token1).Code)
+	suite.Equal(http.StatusOK, suite.get(token2).Code)
+
+	suite.registry.Unregister(_testTaskID)
+	suite.Equal(http.StatusUnauthorized, suite.get(token2).Code)
+}
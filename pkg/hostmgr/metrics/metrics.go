@@ -44,6 +44,25 @@ type Metrics struct {
 	ShutdownExecutorsInvalid tally.Counter
 	ShutdownExecutorsFail    tally.Counter
 
+	ReconcileTasks     tally.Counter
+	ReconcileTasksFail tally.Counter
+
+	ExplicitReconcile          tally.Counter
+	ExplicitReconcileFail      tally.Counter
+	GetReconcileStatus         tally.Counter
+	GetReconcileStatusNotFound tally.Counter
+
+	PinHosts     tally.Counter
+	PinHostsFail tally.Counter
+	UnpinHosts   tally.Counter
+
+	GetQuarantinedHosts        tally.Counter
+	ClearHostQuarantine        tally.Counter
+	ClearHostQuarantineInvalid tally.Counter
+
+	GetHostResources     tally.Counter
+	GetHostResourcesFail tally.Counter
+
 	ReleaseHostOffers     tally.Counter
 	ReleaseHostOffersFail tally.Counter
 	ReleaseHostsCount     tally.Counter
@@ -107,6 +126,25 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ShutdownExecutorsInvalid: scope.Counter("shutdown_executors_invalid"),
 		ShutdownExecutorsFail:    scope.Counter("shutdown_executors_fail"),
 
+		ReconcileTasks:     scope.Counter("reconcile_tasks"),
+		ReconcileTasksFail: scope.Counter("reconcile_tasks_fail"),
+
+		ExplicitReconcile:          scope.Counter("explicit_reconcile"),
+		ExplicitReconcileFail:      scope.Counter("explicit_reconcile_fail"),
+		GetReconcileStatus:         scope.Counter("get_reconcile_status"),
+		GetReconcileStatusNotFound: scope.Counter("get_reconcile_status_not_found"),
+
+		PinHosts:     scope.Counter("pin_hosts"),
+		PinHostsFail: scope.Counter("pin_hosts_fail"),
+		UnpinHosts:   scope.Counter("unpin_hosts"),
+
+		GetQuarantinedHosts:        scope.Counter("get_quarantined_hosts"),
+		ClearHostQuarantine:        scope.Counter("clear_host_quarantine"),
+		ClearHostQuarantineInvalid: scope.Counter("clear_host_quarantine_invalid"),
+
+		GetHostResources:     scope.Counter("get_host_resources"),
+		GetHostResourcesFail: scope.Counter("get_host_resources_fail"),
+
 		ReleaseHostOffers:     scope.Counter("release_host_offers"),
 		ReleaseHostOffersFail: scope.Counter("release_host_offers_fail"),
 		ReleaseHostsCount:     scope.Counter("release_hosts_count"),
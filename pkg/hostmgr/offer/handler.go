@@ -58,6 +58,10 @@ const (
 	_notifyResourceManagerPeriod   = 10 * time.Second
 )
 
+// TaskUpdateStreamName is the name of the stream of the task status
+// updates, which the cursors of its clients are persisted under.
+const TaskUpdateStreamName = "hostmgr_task_status_update"
+
 // EventHandler defines the interface for offer event handler that is
 // called by leader election callbacks
 type EventHandler interface {
@@ -79,6 +83,13 @@ type EventHandler interface {
 	// SetHostPoolManager set host pool manager in the event handler.
 	// It should be called during event handler initialization.
 	SetHostPoolManager(manager manager.HostPoolManager)
+
+	// GetOfferPruner returns the Pruner of the offers held by the pool.
+	GetOfferPruner() Pruner
+
+	// SetTaskUpdateAckConcurrency changes the number of go routines
+	// acknowledging the task status updates to Mesos.
+	SetTaskUpdateAckConcurrency(concurrency int)
 }
 
 // Singleton event handler for offers and mesos status update events
@@ -94,6 +105,11 @@ type eventHandler struct {
 	offerPool   offerpool.Pool
 	offerPruner Pruner
 
+	// Serializes the changes to the task status update ack workers
+	ackWorkersLock sync.Mutex
+	// Channels to stop each of the running task status update ack workers
+	ackWorkerStopChs []chan struct{}
+
 	updateAckConcurrency int
 
 	// Buffers the mesos task status updates to be acknowledged
@@ -162,6 +178,7 @@ func InitEventHandler(
 		processor,
 		hostPoolManager,
 	)
+	pool.SetQuarantineConfig(hostMgrConfig.HostQuarantine)
 
 	placingHostPruner := prune.NewPlacingHostPruner(
 		pool,
@@ -226,6 +243,7 @@ func InitEventHandler(
 		d,
 		handler,
 		hostMgrConfig.TaskUpdateBufferSize,
+		hostMgrConfig.TaskUpdateStreamClients,
 		parent.SubScope("EventStreamHandler"))
 	initResMgrEventForwarder(
 		handler.eventStreamHandler,
@@ -284,10 +302,12 @@ func initEventStreamHandler(
 	d *yarpc.Dispatcher,
 	purgedEventsProcessor eventstream.PurgedEventsProcessor,
 	bufferSize int,
+	extraClients []string,
 	scope tally.Scope) *eventstream.Handler {
+	clients := []string{common.PelotonJobManager, common.PelotonResourceManager}
 	eventStreamHandler := eventstream.NewEventStreamHandler(
 		bufferSize,
-		[]string{common.PelotonJobManager, common.PelotonResourceManager},
+		append(clients, extraClients...),
 		purgedEventsProcessor,
 		scope,
 	)
@@ -491,23 +511,52 @@ func (h *eventHandler) EventPurged(events []*cirbuf.CircularBufferItem) {
 // startAsyncProcessTaskUpdates concurrently process task status update events
 // ready to ACK iff uuid is not nil.
 func (h *eventHandler) startAsyncProcessTaskUpdates() {
-	for i := 0; i < h.updateAckConcurrency; i++ {
-		go func() {
-			for taskStatus := range h.ackChannel {
-				uid := uuid.UUID(taskStatus.GetUuid()).String()
-				// once acked, delete from map
-				// if ack failed at mesos master then agent will re-send
-				h.ackStatusMap.Delete(uid)
-
-				if err := h.acknowledgeTaskUpdate(
-					context.Background(),
-					taskStatus); err != nil {
-					log.WithField("task_status", *taskStatus).
-						WithError(err).
-						Error("Failed to acknowledgeTaskUpdate")
-				}
+	h.SetTaskUpdateAckConcurrency(h.updateAckConcurrency)
+}
+
+// SetTaskUpdateAckConcurrency starts or stops task status update ack
+// workers until the given number of them is running.
+func (h *eventHandler) SetTaskUpdateAckConcurrency(concurrency int) {
+	h.ackWorkersLock.Lock()
+	defer h.ackWorkersLock.Unlock()
+
+	for len(h.ackWorkerStopChs) < concurrency {
+		stopCh := make(chan struct{})
+		h.ackWorkerStopChs = append(h.ackWorkerStopChs, stopCh)
+		go h.processTaskUpdates(stopCh)
+	}
+	for len(h.ackWorkerStopChs) > concurrency {
+		last := len(h.ackWorkerStopChs) - 1
+		close(h.ackWorkerStopChs[last])
+		h.ackWorkerStopChs = h.ackWorkerStopChs[:last]
+	}
+	h.updateAckConcurrency = concurrency
+}
+
+// processTaskUpdates acknowledges the task status updates buffered in
+// ackChannel until stopCh is closed.
+func (h *eventHandler) processTaskUpdates(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case taskStatus, ok := <-h.ackChannel:
+			if !ok {
+				return
 			}
-		}()
+			uid := uuid.UUID(taskStatus.GetUuid()).String()
+			// once acked, delete from map
+			// if ack failed at mesos master then agent will re-send
+			h.ackStatusMap.Delete(uid)
+
+			if err := h.acknowledgeTaskUpdate(
+				context.Background(),
+				taskStatus); err != nil {
+				log.WithField("task_status", *taskStatus).
+					WithError(err).
+					Error("Failed to acknowledgeTaskUpdate")
+			}
+		}
 	}
 }
 
@@ -554,10 +603,17 @@ func (h *eventHandler) GetOfferPool() offerpool.Pool {
 	return h.offerPool
 }
 
+// GetOfferPruner returns the Pruner of the offers held by the pool.
+func (h *eventHandler) GetOfferPruner() Pruner {
+	return h.offerPruner
+}
+
 // Start runs startup related procedures
 func (h *eventHandler) Start() error {
 	// Start offer pruner
 	h.offerPruner.Start()
+	// temporary measure to recover mesos plugins from master failover
+	h.mesosPlugin.Resubscribed()
 
 	// TODO: add error handling
 	return nil
@@ -567,6 +623,8 @@ func (h *eventHandler) Start() error {
 func (h *eventHandler) Stop() error {
 	// Clean up all existing offers
 	h.offerPool.Clear()
+	// temporary measure to clear the offers of mesos plugins
+	h.mesosPlugin.Disconnected()
 	// Stop offer pruner
 	h.offerPruner.Stop()
 
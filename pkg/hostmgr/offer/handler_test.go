@@ -36,6 +36,7 @@ import (
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	mpb_mocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	mesosmanager "github.com/uber/peloton/pkg/hostmgr/p2k/plugins/mesos"
 	watchmocks "github.com/uber/peloton/pkg/hostmgr/watchevent/mocks"
 	storage_mocks "github.com/uber/peloton/pkg/storage/mocks"
@@ -119,8 +120,8 @@ func (s *HostMgrOfferHandlerTestSuite) SetupSuite() {
 	).(hostmgr_mesos.SchedulerDriver)
 	s.mesosPlugin = mesosmanager.NewMesosManager(
 		s.dispatcher, nil, s.schedulerClient, nil,
-		time.Second, time.Second,
-		tally.NoopScope, nil, nil)
+		time.Second, time.Second, 1, 10, p2kconfig.MesosConfig{},
+		tally.NoopScope, nil, nil, nil)
 
 	hmConfig := config.Config{
 		OfferHoldTimeSec:              60,
@@ -360,6 +361,20 @@ func (s *HostMgrOfferHandlerTestSuite) TestStatusUpdateDedupe() {
 	time.Sleep(500 * time.Millisecond)
 }
 
+// TestSetTaskUpdateAckConcurrency tests starting and stopping task status
+// update ack workers at runtime.
+func (s *HostMgrOfferHandlerTestSuite) TestSetTaskUpdateAckConcurrency() {
+	defer handler.SetTaskUpdateAckConcurrency(1)
+
+	handler.SetTaskUpdateAckConcurrency(3)
+	s.Len(handler.ackWorkerStopChs, 3)
+	s.Equal(3, handler.updateAckConcurrency)
+
+	handler.SetTaskUpdateAckConcurrency(2)
+	s.Len(handler.ackWorkerStopChs, 2)
+	s.Equal(2, handler.updateAckConcurrency)
+}
+
 func createEvent(_uuid string, offset int) *pb_eventstream.Event {
 	state := mesos.TaskState_TASK_STARTING
 	status := &mesos.TaskStatus{
@@ -17,10 +17,14 @@ package offerpool
 import (
 	"math"
 	"strings"
+	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/hostmgr/hostfilter"
 	"github.com/uber/peloton/pkg/hostmgr/hostpool/manager"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/summary"
 
 	log "github.com/sirupsen/logrus"
@@ -40,10 +44,15 @@ func effectiveHostLimit(f *hostsvc.HostFilter) uint32 {
 type Matcher struct {
 	hostFilter *hostsvc.HostFilter
 	evaluator  constraints.Evaluator
+	// expression is the compiled attribute expression of the host filter
+	expression hostfilter.Expression
 	// hostPoolManager is the manager maintains host to host pool map
 	hostPoolManager manager.HostPoolManager
 	// map of hostname to the host offer
 	hostOffers map[string]*summary.Offer
+	// quarantinedHosts are the hosts excluded from matching since
+	// their offers are rescinded repeatedly
+	quarantinedHosts map[string]time.Time
 
 	filterResultCounts map[string]uint32
 }
@@ -78,6 +87,14 @@ func (m *Matcher) tryMatchImpl(
 		return hostsvc.HostFilterResult_MATCH
 	}
 
+	if _, ok := m.quarantinedHosts[hostname]; ok {
+		return hostsvc.HostFilterResult_QUARANTINED
+	}
+
+	if !m.matchExpression(s) {
+		return hostsvc.HostFilterResult_MISMATCH_CONSTRAINTS
+	}
+
 	// Insert host pool into labels for evaluation.
 	var lv constraints.LabelValues
 	var err error
@@ -104,6 +121,23 @@ func (m *Matcher) tryMatchImpl(
 	return match.Result
 }
 
+// matchExpression returns whether the attributes and the offered resources
+// of the host satisfy the attribute expression of the host filter.
+func (m *Matcher) matchExpression(s summary.HostSummary) bool {
+	if m.expression == nil {
+		return true
+	}
+
+	offers := s.GetOffers(summary.All)
+	var attributes []*mesos.Attribute
+	for _, offer := range offers {
+		// All the offers of a host have the same attributes.
+		attributes = offer.GetAttributes()
+		break
+	}
+	return m.expression.Evaluate(attributes, scalar.FromOfferMap(offers))
+}
+
 // HasEnoughHosts returns whether this instance has matched enough hosts based
 // on input HostLimit.
 func (m *Matcher) HasEnoughHosts() bool {
@@ -120,15 +154,18 @@ func (m *Matcher) getHostOffers() (map[string]*summary.Offer, map[string]uint32)
 	return result, resultCount
 }
 
-// NewMatcher returns a new instance of Matcher.
+// NewMatcher returns a new instance of Matcher. The expression is the
+// compiled attribute expression of the host filter, if any.
 func NewMatcher(
 	hostFilter *hostsvc.HostFilter,
 	evaluator constraints.Evaluator,
+	expression hostfilter.Expression,
 	hostPoolManager manager.HostPoolManager,
 ) *Matcher {
 	return &Matcher{
 		hostFilter:         hostFilter,
 		evaluator:          evaluator,
+		expression:         expression,
 		hostPoolManager:    hostPoolManager,
 		hostOffers:         make(map[string]*summary.Offer),
 		filterResultCounts: make(map[string]uint32),
@@ -35,6 +35,13 @@ type Metrics struct {
 	ReturnUnusedHosts        tally.Counter
 	ResetExpiredPlacingHosts tally.Counter
 	ResetExpiredHeldHosts    tally.Counter
+	PinnedHosts              tally.Counter
+	UnpinnedHosts            tally.Counter
+	ExpiredHostPins          tally.Counter
+	QuarantinedHosts         tally.Counter
+	ClearedQuarantines       tally.Counter
+	ExpiredQuarantines       tally.Counter
+	HostsInQuarantine        tally.Gauge
 
 	// metrics for offers
 	UnavailableOffers tally.Counter
@@ -78,5 +85,12 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ReturnUnusedHosts:        hostsScope.Counter("return_unused"),
 		ResetExpiredPlacingHosts: hostsScope.Counter("reset_expired_placing"),
 		ResetExpiredHeldHosts:    hostsScope.Counter("reset_expired_held"),
+		PinnedHosts:              hostsScope.Counter("pinned"),
+		UnpinnedHosts:            hostsScope.Counter("unpinned"),
+		ExpiredHostPins:          hostsScope.Counter("expired_pins"),
+		QuarantinedHosts:         hostsScope.Counter("quarantined"),
+		ClearedQuarantines:       hostsScope.Counter("quarantine_cleared"),
+		ExpiredQuarantines:       hostsScope.Counter("quarantine_expired"),
+		HostsInQuarantine:        hostsScope.Gauge("in_quarantine"),
 	}
 }
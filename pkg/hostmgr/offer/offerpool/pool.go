@@ -18,6 +18,7 @@ import (
 	"context"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -29,6 +30,7 @@ import (
 	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/binpacking"
+	"github.com/uber/peloton/pkg/hostmgr/hostfilter"
 	"github.com/uber/peloton/pkg/hostmgr/hostpool/manager"
 	hostmgr_mesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
@@ -39,6 +41,7 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // Pool caches a set of offers received from Mesos master. It is
@@ -138,6 +141,39 @@ type Pool interface {
 
 	// SetHostPoolManager set host pool manager in the offer pool.
 	SetHostPoolManager(manager manager.HostPoolManager)
+
+	// SetOfferHoldTime changes the time offers added to the pool are held
+	// for. Offers already in the pool keep their expiration.
+	SetOfferHoldTime(offerHoldTime time.Duration)
+
+	// PinHost pins the host for the given duration, during which the
+	// offers of the host are held past the offer hold time and the host
+	// is not reset from PLACING state. Pinning a pinned host overrides
+	// the expiration of its pin.
+	PinHost(hostname string, ttl time.Duration) error
+
+	// UnpinHost unpins the host pinned by PinHost.
+	UnpinHost(hostname string)
+
+	// GetPinnedHosts returns the expiration time of the pin of each
+	// pinned host.
+	GetPinnedHosts() map[string]time.Time
+
+	// SetQuarantineConfig changes the config to quarantine flapping hosts,
+	// whose offers are rescinded repeatedly, from placement.
+	SetQuarantineConfig(config QuarantineConfig)
+
+	// GetQuarantinedHosts returns the expiration time of the quarantine
+	// of each quarantined host.
+	GetQuarantinedHosts() map[string]time.Time
+
+	// ClearQuarantine releases the given hosts from quarantine, or all the
+	// quarantined hosts if none are given, and returns the released hosts.
+	ClearQuarantine(hostnames ...string) []string
+
+	// ResetExpiredQuarantines releases the hosts whose quarantine has
+	// expired at the given time, and returns them.
+	ResetExpiredQuarantines(now time.Time) []string
 }
 
 const (
@@ -145,6 +181,10 @@ const (
 	// starting window.
 	// Mesos Master sets unix nano seconds for unavailability start time.
 	_defaultRejectUnavailableOffer = int64(10800000000000)
+
+	// Maximum duration a host can be pinned for, which bounds how long
+	// the offers of a host are held if its pin is never released.
+	_maxHostPinTTL = 30 * time.Minute
 )
 
 var (
@@ -239,7 +279,8 @@ type offerPool struct {
 	// Used when offer is rescinded or pruned.
 	timedOffers sync.Map
 
-	// Time to hold offer in offer pool
+	// Time to hold offer in offer pool, accessed atomically since it
+	// can be changed at runtime
 	offerHoldTime time.Duration
 
 	// Time to hold host in PLACING state
@@ -256,6 +297,13 @@ type offerPool struct {
 	// value: host held for the task
 	taskHeldIndex sync.Map
 
+	// pinnedHosts --- key: hostname,
+	// value: expiration time of the pin of the host
+	pinnedHosts sync.Map
+
+	// quarantine of the hosts whose offers are rescinded repeatedly
+	quarantine hostQuarantine
+
 	watchProcessor watchevent.WatchProcessor
 
 	hostPoolManager manager.HostPoolManager
@@ -272,13 +320,20 @@ func (p *offerPool) ClaimForPlace(
 	map[string]*summary.Offer,
 	map[string]uint32,
 	error) {
+	expression, err := hostfilter.Compile(hostFilter.GetAttributeExpression())
+	if err != nil {
+		return nil, nil, yarpcerrors.InvalidArgumentErrorf("%s", err)
+	}
+
 	p.RLock()
 	defer p.RUnlock()
 
 	matcher := NewMatcher(
 		hostFilter,
 		constraints.NewEvaluator(task.LabelConstraint_HOST),
+		expression,
 		p.hostPoolManager)
+	matcher.quarantinedHosts = p.getQuarantinedHosts(time.Now())
 
 	// if host hint is provided, try to return the hosts in hints first
 	for _, filterHints := range hostFilter.GetHint().GetHostHint() {
@@ -357,6 +412,26 @@ func (p *offerPool) getRankedHostSummaryList(
 	case hostsvc.FilterHint_FILTER_HINT_RANKING_LOAD_AWARE:
 		// Load aware orders hosts from lowest loaded to highest loaded
 		ranker = binpacking.GetRankerByName(binpacking.LoadAware)
+
+	case hostsvc.FilterHint_FILTER_HINT_RANKING_SPREAD:
+		// Spread orders hosts from most offered resources to least
+		// offered resources
+		ranker = binpacking.GetRankerByName(binpacking.Spread)
+
+	case hostsvc.FilterHint_FILTER_HINT_RANKING_BIN_PACK_MEMORY:
+		// BinPackMemory orders hosts from lowest offered memory to most
+		// offered memory
+		ranker = binpacking.GetRankerByName(binpacking.BinPackMemory)
+
+	case hostsvc.FilterHint_FILTER_HINT_RANKING_CUSTOM:
+		// Custom orders hosts by the configured scorer
+		ranker = binpacking.GetRankerByName(binpacking.Custom)
+	}
+
+	// Fall back to the default ranker if the hinted one is not registered,
+	// such as the custom ranker without a configured scorer.
+	if ranker == nil {
+		ranker = p.binPackingRanker
 	}
 	return ranker.GetRankedHostList(ctx, offerIndex)
 }
@@ -444,7 +519,7 @@ func (p *offerPool) AddOffers(
 		}
 		p.timedOffers.Store(offer.Id.GetValue(), &TimedOffer{
 			Hostname:   offer.GetHostname(),
-			Expiration: time.Now().Add(p.offerHoldTime),
+			Expiration: time.Now().Add(p.getOfferHoldTime()),
 		})
 
 		oldOffers := hostnameToOffers[offer.GetHostname()]
@@ -572,6 +647,9 @@ func (p *offerPool) RescindOffer(offerID *mesos.OfferID) bool {
 
 	oID := *offerID.Value
 	p.metrics.RescindEvents.Inc(1)
+	if offer, ok := p.timedOffers.Load(oID); ok {
+		p.recordRescind(offer.(*TimedOffer).Hostname, time.Now())
+	}
 	p.removeOffer(oID, "offer is rescinded.")
 	return true
 }
@@ -583,9 +661,14 @@ func (p *offerPool) RemoveExpiredOffers() (map[string]*TimedOffer, int) {
 	p.RLock()
 	defer p.RUnlock()
 
+	now := time.Now()
 	offersToDecline := map[string]*TimedOffer{}
 	p.timedOffers.Range(func(offerID, timedOffer interface{}) bool {
-		if time.Now().After(timedOffer.(*TimedOffer).Expiration) {
+		if p.isHostPinned(timedOffer.(*TimedOffer).Hostname, now) {
+			// Offers of pinned hosts are held until the pin expires.
+			return true
+		}
+		if now.After(timedOffer.(*TimedOffer).Expiration) {
 			log.
 				WithField("offer_id", offerID).
 				Info("Removing expired offer from pool.")
@@ -690,6 +773,9 @@ func (p *offerPool) ResetExpiredPlacingHostSummaries(now time.Time) []string {
 	defer p.RUnlock()
 	var resetHostnames []string
 	for hostname, summ := range p.hostOfferIndex {
+		if p.isHostPinned(hostname, now) {
+			continue
+		}
 		if reset, res, taskExpired := summ.ResetExpiredPlacingOfferStatus(now); reset {
 			resetHostnames = append(resetHostnames, hostname)
 			for _, task := range taskExpired {
@@ -899,6 +985,75 @@ func (p *offerPool) SetHostPoolManager(manager manager.HostPoolManager) {
 	p.hostPoolManager = manager
 }
 
+// SetOfferHoldTime changes the time offers added to the pool are held for.
+func (p *offerPool) SetOfferHoldTime(offerHoldTime time.Duration) {
+	atomic.StoreInt64((*int64)(&p.offerHoldTime), int64(offerHoldTime))
+}
+
+// getOfferHoldTime returns the time offers added to the pool are held for.
+func (p *offerPool) getOfferHoldTime() time.Duration {
+	return time.Duration(atomic.LoadInt64((*int64)(&p.offerHoldTime)))
+}
+
+// PinHost pins the host for the given duration, during which the offers of
+// the host are held past the offer hold time and the host is not reset from
+// PLACING state.
+func (p *offerPool) PinHost(hostname string, ttl time.Duration) error {
+	if ttl <= 0 || ttl > _maxHostPinTTL {
+		return errors.Errorf("invalid pin duration %v for host %s, "+
+			"must be positive and at most %v", ttl, hostname, _maxHostPinTTL)
+	}
+
+	if _, err := p.GetHostSummary(hostname); err != nil {
+		return err
+	}
+
+	expiration := time.Now().Add(ttl)
+	p.pinnedHosts.Store(hostname, expiration)
+	p.metrics.PinnedHosts.Inc(1)
+	log.WithFields(log.Fields{
+		"host":       hostname,
+		"expiration": expiration,
+	}).Info("host pinned")
+	return nil
+}
+
+// UnpinHost unpins the host pinned by PinHost.
+func (p *offerPool) UnpinHost(hostname string) {
+	if _, ok := p.pinnedHosts.Load(hostname); !ok {
+		return
+	}
+	p.pinnedHosts.Delete(hostname)
+	p.metrics.UnpinnedHosts.Inc(1)
+	log.WithField("host", hostname).Info("host unpinned")
+}
+
+// GetPinnedHosts returns the expiration time of the pin of each pinned host.
+func (p *offerPool) GetPinnedHosts() map[string]time.Time {
+	pinnedHosts := make(map[string]time.Time)
+	p.pinnedHosts.Range(func(hostname, expiration interface{}) bool {
+		pinnedHosts[hostname.(string)] = expiration.(time.Time)
+		return true
+	})
+	return pinnedHosts
+}
+
+// isHostPinned returns whether the host is pinned at the given time,
+// removing the pin of the host if it has expired.
+func (p *offerPool) isHostPinned(hostname string, now time.Time) bool {
+	expiration, ok := p.pinnedHosts.Load(hostname)
+	if !ok {
+		return false
+	}
+	if now.After(expiration.(time.Time)) {
+		p.pinnedHosts.Delete(hostname)
+		p.metrics.ExpiredHostPins.Inc(1)
+		log.WithField("host", hostname).Info("host pin expired")
+		return false
+	}
+	return true
+}
+
 // addTaskHold update the index when a host is held for a task
 func (p *offerPool) addTaskHold(hostname string, id *peloton.TaskID) {
 	oldHost, loaded := p.taskHeldIndex.LoadOrStore(id.GetValue(), hostname)
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -45,6 +46,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	"go.uber.org/goleak"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 const (
@@ -491,6 +493,86 @@ func (suite *OfferPoolTestSuite) TestRemoveExpiredOffers() {
 	suite.Equal(2, valid)
 }
 
+// TestRemoveExpiredOffersPinnedHost tests that the expired offers of a
+// pinned host are held until the pin of the host expires.
+func (suite *OfferPoolTestSuite) TestRemoveExpiredOffersPinnedHost() {
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+
+	offer1 := suite.agent1Offers[0]
+	offer2 := suite.agent2Offers[0]
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{offer1, offer2})
+
+	// Offers of hosts without any offer cannot be pinned, nor can hosts be
+	// pinned for longer than the maximum duration.
+	suite.Error(suite.pool.PinHost("unknown", time.Minute))
+	suite.Error(suite.pool.PinHost(offer1.GetHostname(), 0))
+	suite.Error(suite.pool.PinHost(offer1.GetHostname(), 2*_maxHostPinTTL))
+	suite.NoError(suite.pool.PinHost(offer1.GetHostname(), time.Minute))
+
+	for _, offer := range []*mesos.Offer{offer1, offer2} {
+		suite.pool.timedOffers.Store(*offer.Id.Value, &TimedOffer{
+			Hostname:   offer.GetHostname(),
+			Expiration: time.Now().Add(-2 * time.Minute),
+		})
+	}
+
+	removed, valid := suite.pool.RemoveExpiredOffers()
+	suite.Len(removed, 1)
+	suite.Contains(removed, *offer2.Id.Value)
+	suite.Equal(1, valid)
+
+	// The offers of the host are removed once its pin expires.
+	suite.pool.pinnedHosts.Store(
+		offer1.GetHostname(), time.Now().Add(-time.Second))
+	removed, valid = suite.pool.RemoveExpiredOffers()
+	suite.Len(removed, 1)
+	suite.Contains(removed, *offer1.Id.Value)
+	suite.Equal(0, valid)
+	suite.Empty(suite.pool.GetPinnedHosts())
+}
+
+// TestSetOfferHoldTime tests that changing the offer hold time applies to
+// the offers added afterwards.
+func (suite *OfferPoolTestSuite) TestSetOfferHoldTime() {
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+
+	offer1 := suite.agent1Offers[0]
+	offer2 := suite.agent2Offers[0]
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{offer1})
+	suite.pool.SetOfferHoldTime(10 * time.Minute)
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{offer2})
+
+	timedOffer1, ok := suite.pool.timedOffers.Load(*offer1.Id.Value)
+	suite.True(ok)
+	timedOffer2, ok := suite.pool.timedOffers.Load(*offer2.Id.Value)
+	suite.True(ok)
+	suite.True(timedOffer1.(*TimedOffer).Expiration.Before(
+		time.Now().Add(time.Minute)))
+	suite.True(timedOffer2.(*TimedOffer).Expiration.After(
+		time.Now().Add(9 * time.Minute)))
+}
+
+// TestResetExpiredPlacingHostSummariesPinnedHost tests that pinned hosts
+// are not reset from PLACING state until they are unpinned.
+func (suite *OfferPoolTestSuite) TestResetExpiredPlacingHostSummariesPinnedHost() {
+	defer suite.ctrl.Finish()
+
+	now := time.Now()
+	mhs := hostmgr_summary_mocks.NewMockHostSummary(suite.ctrl)
+	pool := &offerPool{
+		hostOfferIndex: map[string]summary.HostSummary{"host0": mhs},
+		metrics:        NewMetrics(tally.NoopScope),
+	}
+	suite.NoError(pool.PinHost("host0", time.Minute))
+	suite.Empty(pool.ResetExpiredPlacingHostSummaries(now))
+
+	pool.UnpinHost("host0")
+	mhs.EXPECT().
+		ResetExpiredPlacingOfferStatus(now).
+		Return(true, scalar.Resources{}, nil)
+	suite.Equal([]string{"host0"}, pool.ResetExpiredPlacingHostSummaries(now))
+}
+
 func (suite *OfferPoolTestSuite) TestAddGetRemoveOffers() {
 	defer goleak.VerifyNoLeaks(suite.T())
 	// Add offer concurrently
@@ -1225,6 +1307,73 @@ func (suite *OfferPoolTestSuite) TestClaimForPlaceWithRankHintLoadAware() {
 	suite.NotNil(result[hostName1])
 }
 
+// TestClaimForPlaceWithAttributeExpression tests claiming the hosts which
+// satisfy the attribute expression of the host filter.
+func (suite *OfferPoolTestSuite) TestClaimForPlaceWithAttributeExpression() {
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{
+		suite.createOffer("hostname0", scalar.Resources{CPU: 1, Mem: 1, Disk: 1}),
+		suite.createOffer("hostname1", scalar.Resources{CPU: 2, Mem: 4, Disk: 1}),
+		suite.createOffer("hostname2", scalar.Resources{CPU: 4, Mem: 2, Disk: 1}),
+	})
+
+	filter := &hostsvc.HostFilter{
+		AttributeExpression: "resource.cpu >= 2 && !(resource.mem > 2)",
+	}
+	result, resultCount, err := suite.pool.ClaimForPlace(suite.ctx, filter)
+	suite.NoError(err)
+	suite.Len(result, 1)
+	suite.NotNil(result["hostname2"])
+	suite.Equal(uint32(2), resultCount[strings.ToLower(
+		hostsvc.HostFilterResult_MISMATCH_CONSTRAINTS.String())])
+
+	filter.AttributeExpression = "resource.cpu >="
+	_, _, err = suite.pool.ClaimForPlace(suite.ctx, filter)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestClaimForPlaceWithRankHintSpread tests claiming hosts with the spread
+// ranking, and with the custom ranking which falls back to the default
+// ranking of the pool when no custom scorer is registered.
+func (suite *OfferPoolTestSuite) TestClaimForPlaceWithRankHintSpread() {
+	binpacking.CleanUpRanker()
+	binpacking.Init(nil, nil)
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+
+	smallHost := "hostname0"
+	largeHost := "hostname1"
+	suite.pool.AddOffers(context.Background(), []*mesos.Offer{
+		suite.createOffer(smallHost, scalar.Resources{CPU: 2, Mem: 2, Disk: 2}),
+		suite.createOffer(largeHost, scalar.Resources{CPU: 4, Mem: 4, Disk: 4}),
+	})
+
+	newFilter := func(rankHint hostsvc.FilterHint_Ranking) *hostsvc.HostFilter {
+		return &hostsvc.HostFilter{
+			Hint:     &hostsvc.FilterHint{RankHint: rankHint},
+			Quantity: &hostsvc.QuantityControl{MaxHosts: 1},
+			ResourceConstraint: &hostsvc.ResourceConstraint{
+				Minimum: &task.ResourceConfig{
+					CpuLimit: 1,
+				},
+			},
+		}
+	}
+
+	result, _, err := suite.pool.ClaimForPlace(
+		suite.ctx, newFilter(hostsvc.FilterHint_FILTER_HINT_RANKING_SPREAD))
+	suite.NoError(err)
+	suite.Len(result, 1)
+	suite.NotNil(result[largeHost])
+	suite.NoError(suite.pool.ReturnUnusedOffers(largeHost))
+
+	result, _, err = suite.pool.ClaimForPlace(
+		suite.ctx, newFilter(hostsvc.FilterHint_FILTER_HINT_RANKING_CUSTOM))
+	suite.NoError(err)
+	suite.Len(result, 1)
+	suite.NotNil(result[smallHost])
+}
+
 func TestOfferPoolTestSuite(t *testing.T) {
 	suite.Run(t, new(OfferPoolTestSuite))
 }
@@ -0,0 +1,202 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offerpool
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// QuarantineConfig is the config to quarantine flapping hosts, whose offers
+// are rescinded repeatedly, from placement.
+type QuarantineConfig struct {
+	// Number of rescinds of the offers of a host within the window after
+	// which the host is quarantined. 0 disables quarantine.
+	RescindThreshold int `yaml:"rescind_threshold"`
+
+	// Window within which the rescinds of the offers of a host are counted.
+	Window time.Duration `yaml:"window"`
+
+	// Duration for which a flapping host is quarantined.
+	Cooldown time.Duration `yaml:"cooldown"`
+}
+
+// hostQuarantine tracks the rescinds of the offers of each host, and
+// quarantines the hosts whose offers are rescinded repeatedly.
+type hostQuarantine struct {
+	sync.Mutex
+
+	config QuarantineConfig
+
+	// rescinds --- key: hostname,
+	// value: time of the rescinds of the offers of the host within the window
+	rescinds map[string][]time.Time
+
+	// quarantined --- key: hostname,
+	// value: expiration time of the quarantine of the host
+	quarantined map[string]time.Time
+}
+
+// SetQuarantineConfig changes the config to quarantine flapping hosts.
+// Hosts already quarantined keep their expiration.
+func (p *offerPool) SetQuarantineConfig(config QuarantineConfig) {
+	q := &p.quarantine
+	q.Lock()
+	defer q.Unlock()
+
+	q.config = config
+	q.rescinds = make(map[string][]time.Time)
+	if q.quarantined == nil {
+		q.quarantined = make(map[string]time.Time)
+	}
+}
+
+// recordRescind records the rescind of an offer of the host, and
+// quarantines the host if its offers are rescinded too often.
+func (p *offerPool) recordRescind(hostname string, now time.Time) {
+	q := &p.quarantine
+	q.Lock()
+	defer q.Unlock()
+
+	if q.config.RescindThreshold <= 0 {
+		return
+	}
+	if _, ok := q.quarantined[hostname]; ok {
+		return
+	}
+
+	rescinds := append(
+		recentRescinds(q.rescinds[hostname], now, q.config.Window), now)
+	if len(rescinds) < q.config.RescindThreshold {
+		q.rescinds[hostname] = rescinds
+		return
+	}
+
+	delete(q.rescinds, hostname)
+	expiration := now.Add(q.config.Cooldown)
+	q.quarantined[hostname] = expiration
+	p.metrics.QuarantinedHosts.Inc(1)
+	p.metrics.HostsInQuarantine.Update(float64(len(q.quarantined)))
+	log.WithFields(log.Fields{
+		"host":       hostname,
+		"rescinds":   len(rescinds),
+		"window":     q.config.Window,
+		"expiration": expiration,
+	}).Warn("host quarantined since its offers are rescinded repeatedly")
+}
+
+// GetQuarantinedHosts returns the expiration time of the quarantine of
+// each quarantined host.
+func (p *offerPool) GetQuarantinedHosts() map[string]time.Time {
+	return p.getQuarantinedHosts(time.Now())
+}
+
+// getQuarantinedHosts returns the hosts quarantined at the given time,
+// removing the quarantines which have expired.
+func (p *offerPool) getQuarantinedHosts(now time.Time) map[string]time.Time {
+	q := &p.quarantine
+	q.Lock()
+	defer q.Unlock()
+
+	p.removeExpiredQuarantines(now)
+	quarantined := make(map[string]time.Time, len(q.quarantined))
+	for hostname, expiration := range q.quarantined {
+		quarantined[hostname] = expiration
+	}
+	return quarantined
+}
+
+// ClearQuarantine releases the given hosts from quarantine, or all the
+// quarantined hosts if none are given, and returns the released hosts.
+func (p *offerPool) ClearQuarantine(hostnames ...string) []string {
+	q := &p.quarantine
+	q.Lock()
+	defer q.Unlock()
+
+	if len(hostnames) == 0 {
+		for hostname := range q.quarantined {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	var cleared []string
+	for _, hostname := range hostnames {
+		delete(q.rescinds, hostname)
+		if _, ok := q.quarantined[hostname]; !ok {
+			continue
+		}
+		delete(q.quarantined, hostname)
+		cleared = append(cleared, hostname)
+		log.WithField("host", hostname).Info("host quarantine cleared")
+	}
+	p.metrics.ClearedQuarantines.Inc(int64(len(cleared)))
+	p.metrics.HostsInQuarantine.Update(float64(len(q.quarantined)))
+	return cleared
+}
+
+// ResetExpiredQuarantines releases the hosts whose quarantine has expired
+// at the given time, and drops the rescinds of the offers of hosts which
+// are past the window. It returns the released hosts.
+func (p *offerPool) ResetExpiredQuarantines(now time.Time) []string {
+	q := &p.quarantine
+	q.Lock()
+	defer q.Unlock()
+
+	for hostname, rescinds := range q.rescinds {
+		rescinds = recentRescinds(rescinds, now, q.config.Window)
+		if len(rescinds) == 0 {
+			delete(q.rescinds, hostname)
+		} else {
+			q.rescinds[hostname] = rescinds
+		}
+	}
+	return p.removeExpiredQuarantines(now)
+}
+
+// removeExpiredQuarantines releases the hosts whose quarantine has expired
+// at the given time. It must be called with the quarantine lock held.
+func (p *offerPool) removeExpiredQuarantines(now time.Time) []string {
+	q := &p.quarantine
+
+	var expired []string
+	for hostname, expiration := range q.quarantined {
+		if now.After(expiration) {
+			delete(q.quarantined, hostname)
+			expired = append(expired, hostname)
+			log.WithField("host", hostname).Info("host quarantine expired")
+		}
+	}
+	if len(expired) != 0 {
+		p.metrics.ExpiredQuarantines.Inc(int64(len(expired)))
+		p.metrics.HostsInQuarantine.Update(float64(len(q.quarantined)))
+	}
+	return expired
+}
+
+// recentRescinds returns the rescinds which are within the window
+// ending at the given time.
+func recentRescinds(
+	rescinds []time.Time,
+	now time.Time,
+	window time.Duration) []time.Time {
+	for i, t := range rescinds {
+		if now.Sub(t) <= window {
+			return rescinds[i:]
+		}
+	}
+	return nil
+}
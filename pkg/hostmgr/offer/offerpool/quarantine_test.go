@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offerpool
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+
+	"github.com/golang/mock/gomock"
+)
+
+// TestQuarantineFlappingHost tests that a host whose offers are rescinded
+// repeatedly is quarantined from placement until its quarantine expires
+// or is cleared.
+func (suite *OfferPoolTestSuite) TestQuarantineFlappingHost() {
+	suite.watchProcessor.EXPECT().NotifyEventChange(gomock.Any()).AnyTimes()
+	suite.pool.SetQuarantineConfig(QuarantineConfig{
+		RescindThreshold: 3,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	flappingHost := suite.agent1Offers[0].GetHostname()
+	stableHost := suite.agent2Offers[0].GetHostname()
+	suite.pool.AddOffers(context.Background(), suite.agent1Offers[:3])
+	suite.pool.AddOffers(context.Background(), suite.agent2Offers[:2])
+
+	for _, offer := range suite.agent1Offers[:3] {
+		suite.True(suite.pool.RescindOffer(offer.GetId()))
+	}
+	suite.True(suite.pool.RescindOffer(suite.agent2Offers[0].GetId()))
+	// Rescinding unknown offers is not counted against any host.
+	unknownOfferID := "unknown"
+	suite.pool.RescindOffer(&mesos.OfferID{Value: &unknownOfferID})
+
+	quarantined := suite.pool.GetQuarantinedHosts()
+	suite.Len(quarantined, 1)
+	suite.Contains(quarantined, flappingHost)
+
+	// The quarantined host is not placed on even with new offers.
+	suite.pool.AddOffers(context.Background(), suite.agent1Offers[3:4])
+	filter := &hostsvc.HostFilter{
+		Quantity: &hostsvc.QuantityControl{MaxHosts: 2},
+	}
+	hostOffers, resultCount, err := suite.pool.ClaimForPlace(suite.ctx, filter)
+	suite.NoError(err)
+	suite.Len(hostOffers, 1)
+	suite.Contains(hostOffers, stableHost)
+	suite.Equal(uint32(1), resultCount[strings.ToLower(
+		hostsvc.HostFilterResult_QUARANTINED.String())])
+	suite.NoError(suite.pool.ReturnUnusedOffers(stableHost))
+
+	// The host is placed on once its quarantine is cleared.
+	suite.Empty(suite.pool.ClearQuarantine(stableHost))
+	suite.Equal([]string{flappingHost}, suite.pool.ClearQuarantine())
+	suite.Empty(suite.pool.GetQuarantinedHosts())
+	hostOffers, _, err = suite.pool.ClaimForPlace(suite.ctx, filter)
+	suite.NoError(err)
+	suite.Len(hostOffers, 2)
+}
+
+// TestResetExpiredQuarantines tests that the quarantine of hosts and the
+// rescinds of their offers expire.
+func (suite *OfferPoolTestSuite) TestResetExpiredQuarantines() {
+	suite.pool.SetQuarantineConfig(QuarantineConfig{
+		RescindThreshold: 2,
+		Window:           time.Minute,
+		Cooldown:         time.Minute,
+	})
+
+	now := time.Now()
+	// Rescinds past the window are not counted.
+	suite.pool.recordRescind("host1", now.Add(-2*time.Minute))
+	suite.pool.recordRescind("host1", now)
+	suite.Empty(suite.pool.GetQuarantinedHosts())
+	suite.pool.recordRescind("host1", now)
+	suite.Contains(suite.pool.GetQuarantinedHosts(), "host1")
+
+	suite.pool.recordRescind("host2", now)
+	suite.Empty(suite.pool.ResetExpiredQuarantines(now))
+	suite.Len(suite.pool.quarantine.rescinds, 1)
+
+	suite.Equal(
+		[]string{"host1"},
+		suite.pool.ResetExpiredQuarantines(now.Add(2*time.Minute)))
+	suite.Empty(suite.pool.quarantine.rescinds)
+	suite.Empty(suite.pool.GetQuarantinedHosts())
+
+	// Quarantine is disabled without a rescind threshold.
+	suite.pool.SetQuarantineConfig(QuarantineConfig{})
+	for i := 0; i < 5; i++ {
+		suite.pool.recordRescind("host1", now)
+	}
+	suite.Empty(suite.pool.GetQuarantinedHosts())
+}
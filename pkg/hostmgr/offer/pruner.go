@@ -16,6 +16,7 @@ package offer
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -35,6 +36,10 @@ const (
 type Pruner interface {
 	Start()
 	Stop()
+
+	// SetPruningPeriod changes the period of the pruning loop, starting
+	// from its next iteration.
+	SetPruningPeriod(period time.Duration)
 }
 
 // NewOfferPruner initiates an instance of OfferPruner
@@ -45,7 +50,7 @@ func NewOfferPruner(
 ) Pruner {
 	pruner := &offerPruner{
 		pool:               pool,
-		offerPruningPeriod: offerPruningPeriod,
+		offerPruningPeriod: int64(offerPruningPeriod),
 		metrics:            metrics,
 		lifeCycle:          lifecycle.NewLifeCycle(),
 	}
@@ -55,7 +60,7 @@ func NewOfferPruner(
 // offerPruner implements OfferPruner
 type offerPruner struct {
 	pool               offerpool.Pool
-	offerPruningPeriod time.Duration
+	offerPruningPeriod int64 // time.Duration, accessed atomically
 	metrics            *offerpool.Metrics
 	lifeCycle          lifecycle.LifeCycle // lifecycle manager
 }
@@ -74,13 +79,14 @@ func (p *offerPruner) Start() {
 		close(started)
 
 		for {
-			timer := time.NewTimer(p.offerPruningPeriod)
+			timer := time.NewTimer(p.getPruningPeriod())
 			select {
 			case <-p.lifeCycle.StopCh():
 				log.Info("Exiting the offer pruning loop")
 				return
 			case <-timer.C:
 				log.Debug("Running offer pruning loop")
+				p.pool.ResetExpiredQuarantines(time.Now())
 				expiredOffers, _ := p.pool.RemoveExpiredOffers()
 
 				if len(expiredOffers) != 0 {
@@ -104,6 +110,16 @@ func (p *offerPruner) Start() {
 	<-started
 }
 
+// SetPruningPeriod changes the period of the pruning loop.
+func (p *offerPruner) SetPruningPeriod(period time.Duration) {
+	atomic.StoreInt64(&p.offerPruningPeriod, int64(period))
+}
+
+// getPruningPeriod returns the period of the pruning loop.
+func (p *offerPruner) getPruningPeriod() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.offerPruningPeriod))
+}
+
 // Stop stops offer pruning process
 func (p *offerPruner) Stop() {
 	if !p.lifeCycle.Stop() {
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package offer
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/hostmgr/config"
+
+	"github.com/pkg/errors"
+)
+
+// RegisterConfigReloads registers with the reloader the config fields the
+// event handler applies at runtime.
+func RegisterConfigReloads(reloader *config.Reloader, h EventHandler) {
+	reloader.Register("offer_hold_time_sec", func(cfg *config.Config) error {
+		if cfg.OfferHoldTimeSec <= 0 {
+			return errors.New("offer hold time must be positive")
+		}
+		h.GetOfferPool().SetOfferHoldTime(
+			time.Duration(cfg.OfferHoldTimeSec) * time.Second)
+		return nil
+	})
+
+	reloader.Register("offer_pruning_period_sec", func(cfg *config.Config) error {
+		if cfg.OfferPruningPeriodSec <= 0 {
+			return errors.New("offer pruning period must be positive")
+		}
+		h.GetOfferPruner().SetPruningPeriod(
+			time.Duration(cfg.OfferPruningPeriodSec) * time.Second)
+		return nil
+	})
+
+	reloader.Register("host_quarantine", func(cfg *config.Config) error {
+		h.GetOfferPool().SetQuarantineConfig(cfg.HostQuarantine)
+		return nil
+	})
+
+	reloader.Register("taskupdate_ack_concurrency", func(cfg *config.Config) error {
+		if cfg.TaskUpdateAckConcurrency <= 0 {
+			return errors.New("task update ack concurrency must be positive")
+		}
+		h.SetTaskUpdateAckConcurrency(cfg.TaskUpdateAckConcurrency)
+		return nil
+	})
+}
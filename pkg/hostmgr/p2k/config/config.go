@@ -14,10 +14,129 @@
 
 package p2kconfig
 
+import "time"
+
 // K8sConfig is the Kubernetes P2K specific configuration for Host Manager.
 type K8sConfig struct {
 	Enabled bool `yaml:"enabled"`
 
 	// Kubeconfig is the path to the kubeconfig file on the local filesystem.
 	Kubeconfig string `yaml:"kubeconfig"`
+
+	// ResyncInterval is how often the node and pod informers send update
+	// events for all the nodes and pods they know of, so that missed
+	// events are eventually recovered.
+	ResyncInterval time.Duration `yaml:"resync_interval"`
+
+	// PodGCInterval is how often the pods scheduled by Peloton in the
+	// cluster are compared with the pods known to Peloton, 0 to disable
+	// the garbage collection of orphan pods.
+	PodGCInterval time.Duration `yaml:"pod_gc_interval"`
+
+	// OrphanPodPolicy is what to do with the pods scheduled by Peloton in
+	// the cluster which are unknown to Peloton, either "delete" (default)
+	// or "adopt".
+	OrphanPodPolicy string `yaml:"orphan_pod_policy"`
+}
+
+// FakeConfig is the configuration of the fake P2K plugin, which simulates
+// a cluster in memory for the dev harness and scale tests.
+type FakeConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// NumHosts is the number of hosts in the fake cluster.
+	NumHosts int `yaml:"num_hosts"`
+
+	// HostnamePrefix is the prefix of the fake hostnames, the hosts are
+	// named <prefix>-<index>.
+	HostnamePrefix string `yaml:"hostname_prefix"`
+
+	// Capacity of each fake host.
+	HostCPU    float64 `yaml:"host_cpu"`
+	HostMemMb  float64 `yaml:"host_mem_mb"`
+	HostDiskMb float64 `yaml:"host_disk_mb"`
+	HostGPU    float64 `yaml:"host_gpu"`
+
+	// LaunchLatency is the time taken by the fake cluster to launch pods.
+	LaunchLatency time.Duration `yaml:"launch_latency"`
+
+	// LaunchFailureRate is the fraction of launch calls which fail,
+	// between 0 and 1.
+	LaunchFailureRate float64 `yaml:"launch_failure_rate"`
+
+	// KillFailureRate is the fraction of kill calls which fail,
+	// between 0 and 1.
+	KillFailureRate float64 `yaml:"kill_failure_rate"`
+}
+
+// OversubscriptionConfig is the configuration of the oversubscription of
+// the hosts, which allows revocable pods to use the resources allocated to
+// the non-revocable pods but not used by them.
+type OversubscriptionConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// SlackResourceTypes are the types of resources which are
+	// oversubscribed, e.g. "cpus". Revocable pods use the resources of the
+	// other types like non-revocable pods do. Defaults to cpus.
+	SlackResourceTypes []string `yaml:"slack_resource_types"`
+
+	// OvercommitRatio is the ratio of the allocatable resources of a host
+	// which can be allocated to the revocable and non-revocable pods
+	// together, e.g. 1.5 allows allocating half more than the allocatable
+	// resources.
+	OvercommitRatio float64 `yaml:"overcommit_ratio"`
+
+	// HostOvercommitRatios overrides OvercommitRatio for some hosts, keyed
+	// by hostname.
+	HostOvercommitRatios map[string]float64 `yaml:"host_overcommit_ratios"`
+}
+
+// MesosConfig is the configuration of how the Mesos P2K plugin handles
+// offers.
+type MesosConfig struct {
+	// OfferDeclineRefuse is how long Mesos does not offer the resources of
+	// the offers declined when they expire back to Peloton.
+	OfferDeclineRefuse time.Duration `yaml:"offer_decline_refuse"`
+
+	// MaxHostsWithOffers is the maximum number of hosts whose offers are
+	// held, 0 for no limit. When it is reached, the offers of the other
+	// hosts are declined right away.
+	MaxHostsWithOffers int `yaml:"max_hosts_with_offers"`
+
+	// HostSuppressionRefuse is how long Mesos does not offer the resources
+	// of a host whose offers are declined because MaxHostsWithOffers is
+	// reached.
+	HostSuppressionRefuse time.Duration `yaml:"host_suppression_refuse"`
+
+	// SuppressIdleTimeout is how long the framework goes without pods to
+	// place or launch before it asks Mesos to stop sending offers, 0 to
+	// never suppress offers. Offers are revived as soon as pods are waiting
+	// to be placed again.
+	SuppressIdleTimeout time.Duration `yaml:"suppress_idle_timeout"`
+
+	// Role and Principal are used to reserve resources and create
+	// persistent volumes for stateful pods. They default to the role and
+	// principal of the framework.
+	Role      string `yaml:"role"`
+	Principal string `yaml:"principal"`
+
+	// KillRetryInitialBackoff is how long to wait before sending the kill
+	// of a pod again when no terminal status update is received for it.
+	// The wait doubles with each attempt up to KillRetryMaxBackoff.
+	KillRetryInitialBackoff time.Duration `yaml:"kill_retry_initial_backoff"`
+	KillRetryMaxBackoff     time.Duration `yaml:"kill_retry_max_backoff"`
+
+	// KillRetryMaxAttempts is the number of times the kill of a pod is
+	// sent before giving up on it.
+	KillRetryMaxAttempts int `yaml:"kill_retry_max_attempts"`
+
+	// MaxInFlightLaunchesPerAgent is the maximum number of pods being
+	// launched on an agent, i.e. launched pods the agent has not reported
+	// a status for yet, 0 for no limit. Launches exceeding it are queued
+	// until the agent reports the status of the pods launched before.
+	MaxInFlightLaunchesPerAgent int `yaml:"max_inflight_launches_per_agent"`
+
+	// InFlightLaunchTimeout is how long a pod counts against the limit of
+	// its agent when no status is reported for it.
+	InFlightLaunchTimeout time.Duration `yaml:"inflight_launch_timeout"`
 }
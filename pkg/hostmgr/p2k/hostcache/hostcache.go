@@ -15,6 +15,7 @@
 package hostcache
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -26,8 +27,11 @@ import (
 	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/common/v1alpha/constraints"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
 
@@ -44,8 +48,18 @@ const (
 	_hostCacheMetricsRefreshPeriod = 10 * time.Second
 	_hostCachePruneHeldHosts       = "hostCachePruneHeldHosts"
 	_hostCachePruneHeldHostsPeriod = 180 * time.Second
+	_hostCacheMaintenance          = "hostCacheMaintenance"
+	_hostCacheMaintenancePeriod    = 30 * time.Second
+
+	// _offerReservationTTL is how long the resources offered on a leased
+	// host are pinned for the placement decision to be launched.
+	_offerReservationTTL = 60 * time.Second
 )
 
+// EvictionHook is called with the revocable pods evicted from a host
+// because the non-revocable pods need the slack resources they use.
+type EvictionHook func(hostname string, podIDs []*peloton.PodID)
+
 // HostCache manages cluster resources, and provides necessary abstractions to
 // interact with underlying system.
 type HostCache interface {
@@ -73,6 +87,10 @@ type HostCache interface {
 	// managing.
 	GetSummaries() (summaries []hostsummary.HostSummary)
 
+	// GetPodHostnames returns the hostname of each pod assigned or running
+	// on the hosts, keyed by pod ID.
+	GetPodHostnames() map[string]string
+
 	// HandlePodEvent is called by pod events manager on receiving a pod event.
 	HandlePodEvent(event *scalar.PodEvent)
 
@@ -89,6 +107,15 @@ type HostCache interface {
 	// ReleaseHoldForPods release the hold of host for the pods specified.
 	ReleaseHoldForPods(hostname string, podIDs []*peloton.PodID) error
 
+	// UpdatePodResources updates the resources of a pod running on the host
+	// in place if the host has enough headroom, and returns the previous
+	// spec of the pod.
+	UpdatePodResources(
+		hostname string,
+		podID *peloton.PodID,
+		spec *pbpod.PodSpec,
+	) (*pbpod.PodSpec, error)
+
 	// CompleteLaunchPod is called when a pod is successfully launched.
 	// This is for things like removing pods allocated to the pod
 	// from available ports. This is called after successful launch
@@ -110,6 +137,35 @@ type HostCache interface {
 	// AddPodsToHost is a temporary method to add host entries in host cache.
 	// It would be removed after CompleteLease is called when launching pod.
 	AddPodsToHost(tasks []*hostsvc.LaunchableTask, hostname string)
+
+	// AddEvictionHook adds a hook called after revocable pods are killed
+	// because the non-revocable pods need the slack resources they use.
+	AddEvictionHook(hook EvictionHook)
+
+	// WatchHosts starts a watch on the changes made to the hosts, which
+	// returns the snapshot of the hosts it starts from.
+	WatchHosts() (*HostWatch, error)
+
+	// StopWatch stops a watch on the hosts.
+	StopWatch(watchID string) error
+
+	// StartMaintenance puts a host into maintenance, so that no pod is
+	// placed on it and the pods running on it are relocated.
+	StartMaintenance(hostname string) error
+
+	// CompleteMaintenance brings a DOWN host out of maintenance.
+	CompleteMaintenance(hostname string) error
+
+	// GetPodsOnDrainingHosts returns the pods running on the DRAINING
+	// hosts, which need to be relocated.
+	GetPodsOnDrainingHosts(limit uint32) []*peloton.PodID
+
+	// EvaluatePodConstraint evaluates the pod constraints within given
+	// constraint, such as pod anti-affinity, against the pods on a host.
+	EvaluatePodConstraint(
+		hostname string,
+		constraint *pbpod.Constraint,
+	) (constraints.EvaluateResult, error)
 }
 
 // hostCache is an implementation of HostCache interface.
@@ -122,6 +178,11 @@ type hostCache struct {
 	// Map of podID to host held.
 	podHeldIndex map[string]string
 
+	// Map of hostname to maintenance status of the hosts in maintenance.
+	// It is kept when the hosts are removed, so that they are still in
+	// maintenance when they come back.
+	maintenance map[string]scalar.MaintenanceStatus
+
 	// The event channel on which the underlying cluster manager plugin will send
 	// host events to host cache.
 	hostEventCh chan *scalar.HostEvent
@@ -132,6 +193,27 @@ type hostCache struct {
 	// background manager.
 	backgroundMgr background.Manager
 
+	// The underlying cluster manager plugin, which pins the resources
+	// offered on the hosts leased for placement.
+	plugin plugins.Plugin
+
+	// Configuration of the oversubscription of the hosts.
+	oversubscription p2kconfig.OversubscriptionConfig
+
+	// Hooks called when revocable pods are evicted.
+	evictionHooks []EvictionHook
+
+	// watchMu protects the watches on the hosts and the sequence number of
+	// the changes made to the hosts, which are published with hostCache
+	// read lock held.
+	watchMu sync.Mutex
+
+	// Map of watch ID to watch on the hosts.
+	watches map[string]*HostWatch
+
+	// Sequence number of the last change made to the hosts.
+	sequence uint64
+
 	// Metrics.
 	metrics *Metrics
 }
@@ -140,15 +222,21 @@ type hostCache struct {
 func New(
 	hostEventCh chan *scalar.HostEvent,
 	backgroundMgr background.Manager,
+	plugin plugins.Plugin,
+	oversubscription p2kconfig.OversubscriptionConfig,
 	parent tally.Scope,
 ) HostCache {
 	return &hostCache{
-		hostIndex:     make(map[string]hostsummary.HostSummary),
-		podHeldIndex:  make(map[string]string),
-		hostEventCh:   hostEventCh,
-		lifecycle:     lifecycle.NewLifeCycle(),
-		metrics:       NewMetrics(parent),
-		backgroundMgr: backgroundMgr,
+		hostIndex:        make(map[string]hostsummary.HostSummary),
+		podHeldIndex:     make(map[string]string),
+		maintenance:      make(map[string]scalar.MaintenanceStatus),
+		hostEventCh:      hostEventCh,
+		lifecycle:        lifecycle.NewLifeCycle(),
+		metrics:          NewMetrics(parent),
+		backgroundMgr:    backgroundMgr,
+		plugin:           plugin,
+		oversubscription: oversubscription,
+		watches:          make(map[string]*HostWatch),
 	}
 }
 
@@ -163,6 +251,21 @@ func (c *hostCache) GetSummaries() []hostsummary.HostSummary {
 	return summaries
 }
 
+// GetPodHostnames returns the hostname of each pod assigned or running on
+// the hosts, keyed by pod ID.
+func (c *hostCache) GetPodHostnames() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make(map[string]string)
+	for hostname, summary := range c.hostIndex {
+		for _, podID := range summary.GetPodIDs() {
+			result[podID] = hostname
+		}
+	}
+	return result
+}
+
 // AcquireLeases acquires leases on hosts that match the filter constraints.
 // The lease will be held until Jobmgr actively launches pods using the leaseID.
 // Returns:
@@ -172,6 +275,12 @@ func (c *hostCache) GetSummaries() []hostsummary.HostSummary {
 func (c *hostCache) AcquireLeases(
 	hostFilter *hostmgr.HostFilter,
 ) ([]*hostmgr.HostLease, map[string]uint32) {
+	// Placement is asking for hosts, so there are pods waiting to be
+	// placed, whether or not any host matches.
+	if c.plugin != nil {
+		c.plugin.RecordDemand()
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -200,6 +309,12 @@ func (c *hostCache) AcquireLeases(
 	for _, hostname := range matcher.GetHostNames() {
 		hs := c.hostIndex[hostname]
 		hostLeases = append(hostLeases, hs.GetHostLease())
+
+		// Pin the resources offered on the host, so that they are still
+		// there when the pods placed on the host are launched.
+		if c.plugin != nil {
+			c.plugin.ReserveOffers(hostname, _offerReservationTTL)
+		}
 	}
 
 	if !hostLimitReached {
@@ -236,6 +351,12 @@ func (c *hostCache) TerminateLease(
 		// TODO: metrics
 		return err
 	}
+
+	// The host is not going to be used, so release the offers pinned for
+	// the placement decision.
+	if c.plugin != nil {
+		c.plugin.ReleaseOffers(hostname)
+	}
 	return nil
 }
 
@@ -266,6 +387,8 @@ func (c *hostCache) CompleteLease(
 		// TODO: metrics
 		return err
 	}
+	c.evictPods(hostname, hs.GetPodsToEvict())
+	c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, hs)
 
 	// TODO: remove held hosts.
 	return nil
@@ -358,6 +481,26 @@ func (c *hostCache) ReleaseHoldForPods(hostname string, podIDs []*peloton.PodID)
 	return nil
 }
 
+func (c *hostCache) UpdatePodResources(
+	hostname string,
+	podID *peloton.PodID,
+	spec *pbpod.PodSpec,
+) (*pbpod.PodSpec, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hs, err := c.getSummary(hostname)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := hs.UpdatePodResources(podID, spec)
+	if err != nil {
+		return nil, err
+	}
+	c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, hs)
+	return prev, nil
+}
+
 // RefreshMetrics refreshes the metrics for hosts in ready and placing state.
 func (c *hostCache) RefreshMetrics() {
 	totalAvailable := hmscalar.Resources{}
@@ -420,9 +563,26 @@ func (c *hostCache) CompleteLaunchPod(hostname string, pod *models.LaunchablePod
 		return errors.Wrapf(err, "cannot find host %q", hostname)
 	}
 	hs.CompleteLaunchPod(pod)
+	c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, hs)
 	return nil
 }
 
+// EvaluatePodConstraint evaluates the pod constraints within given
+// constraint, such as pod anti-affinity, against the pods on a host.
+func (c *hostCache) EvaluatePodConstraint(
+	hostname string,
+	constraint *pbpod.Constraint,
+) (constraints.EvaluateResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hs, err := c.getSummary(hostname)
+	if err != nil {
+		return constraints.EvaluateResultNotApplicable, err
+	}
+	return hs.EvaluatePodConstraint(constraint)
+}
+
 // getSummary returns host summary given name. If the host does not exist,
 // return error not found.
 func (c *hostCache) getSummary(hostname string) (hostsummary.HostSummary, error) {
@@ -455,6 +615,7 @@ func (c *hostCache) waitForHostEvents() {
 				c.updateAgent(event)
 			}
 		case <-c.lifecycle.StopCh():
+			c.lifecycle.StopComplete()
 			return
 		}
 	}
@@ -483,6 +644,8 @@ func (c *hostCache) HandlePodEvent(event *scalar.PodEvent) {
 	}
 
 	summary.HandlePodEvent(event)
+	c.evictPods(hostname, summary.GetPodsToEvict())
+	c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, summary)
 }
 
 func (c *hostCache) addHost(event *scalar.HostEvent) {
@@ -514,11 +677,19 @@ func (c *hostCache) addHost(event *scalar.HostEvent) {
 
 	// TODO: figure out how to differemtiate mesos/k8s hosts,
 	// now addHost is only used by k8s hosts
-	c.hostIndex[hostInfo.GetHostName()] = hostsummary.NewKubeletHostSummary(
+	hs := c.newKubeletHostSummary(
 		hostInfo.GetHostName(),
 		capacity,
 		version,
 	)
+	setNodeSpec(hs, hostInfo)
+	c.applyMaintenanceStatus(hostInfo.GetHostName(), hs)
+	c.hostIndex[hostInfo.GetHostName()] = hs
+	c.publishHostDelta(
+		hostmgr.HostDelta_TYPE_HOST_ADDED,
+		hostInfo.GetHostName(),
+		hs,
+	)
 	log.WithFields(log.Fields{
 		"hostname": hostInfo.GetHostName(),
 		"capacity": hostInfo.GetCapacity(),
@@ -564,7 +735,14 @@ func (c *hostCache) updateHostSpec(event *scalar.HostEvent) {
 	}
 
 	hs.SetCapacity(capacity)
+	setNodeSpec(hs, hostInfo)
 	hs.SetVersion(evtVersion)
+	c.evictPods(hostInfo.GetHostName(), hs.GetPodsToEvict())
+	c.publishHostDelta(
+		hostmgr.HostDelta_TYPE_HOST_UPDATED,
+		hostInfo.GetHostName(),
+		hs,
+	)
 	log.WithFields(log.Fields{
 		"hostname": hostInfo.GetHostName(),
 		"capacity": hostInfo.GetCapacity(),
@@ -572,6 +750,76 @@ func (c *hostCache) updateHostSpec(event *scalar.HostEvent) {
 	}).Debug("update host in cache")
 }
 
+// newKubeletHostSummary returns a new host summary for a k8s node, which is
+// oversubscribed if oversubscription is enabled.
+func (c *hostCache) newKubeletHostSummary(
+	hostname string,
+	capacity models.HostResources,
+	version string,
+) hostsummary.HostSummary {
+	hs := hostsummary.NewKubeletHostSummary(hostname, capacity, version)
+	if !c.oversubscription.Enabled {
+		return hs
+	}
+
+	ratio := c.oversubscription.OvercommitRatio
+	if r, ok := c.oversubscription.HostOvercommitRatios[hostname]; ok {
+		ratio = r
+	}
+	hs.SetOversubscriptionPolicy(hostsummary.NewOversubscriptionPolicy(
+		c.oversubscription.SlackResourceTypes,
+		ratio,
+	))
+	return hs
+}
+
+// AddEvictionHook adds a hook called after revocable pods are killed
+// because the non-revocable pods need the slack resources they use.
+func (c *hostCache) AddEvictionHook(hook EvictionHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictionHooks = append(c.evictionHooks, hook)
+}
+
+// evictPods asynchronously kills the revocable pods evicted from a host,
+// and calls the eviction hooks.
+// This function assumes hostCache lock is held before calling.
+func (c *hostCache) evictPods(hostname string, podIDs []*peloton.PodID) {
+	if len(podIDs) == 0 {
+		return
+	}
+
+	hooks := make([]EvictionHook, len(c.evictionHooks))
+	copy(hooks, c.evictionHooks)
+	go func() {
+		for _, podID := range podIDs {
+			err := c.plugin.KillPod(context.Background(), podID.GetValue())
+			if err != nil {
+				c.metrics.EvictPodFail.Inc(1)
+				log.WithFields(log.Fields{
+					"hostname": hostname,
+					"pod_id":   podID.GetValue(),
+				}).WithError(err).Warn("Failed to evict revocable pod")
+				continue
+			}
+			c.metrics.EvictPod.Inc(1)
+		}
+
+		for _, hook := range hooks {
+			hook(hostname, podIDs)
+		}
+	}()
+}
+
+// setNodeSpec sets the labels, taints and allocatable resources of a k8s
+// node on its host summary.
+func setNodeSpec(hs hostsummary.HostSummary, hostInfo *scalar.HostInfo) {
+	hs.SetLabels(hostInfo.GetLabels())
+	hs.SetTaints(hostInfo.GetTaints())
+	hs.SetAllocatable(hostInfo.GetAllocatable())
+}
+
 func (c *hostCache) deleteHost(event *scalar.HostEvent) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -596,7 +844,14 @@ func (c *hostCache) deleteHost(event *scalar.HostEvent) {
 		}
 	}
 
-	delete(c.hostIndex, hostInfo.GetHostName())
+	if _, ok := c.hostIndex[hostInfo.GetHostName()]; ok {
+		delete(c.hostIndex, hostInfo.GetHostName())
+		c.publishHostDelta(
+			hostmgr.HostDelta_TYPE_HOST_REMOVED,
+			hostInfo.GetHostName(),
+			nil,
+		)
+	}
 	log.WithFields(log.Fields{
 		"hostname": hostInfo.GetHostName(),
 		"capacity": hostInfo.GetCapacity(),
@@ -623,11 +878,16 @@ func (c *hostCache) updateAgent(event *scalar.HostEvent) {
 	}
 
 	hs.SetCapacity(hostInfo.GetCapacity())
+	hs.SetLabels(hostInfo.GetLabels())
+	hs.SetMaintenanceStatus(hostInfo.GetMaintenanceStatus())
+	c.applyMaintenanceStatus(hostInfo.GetHostName(), hs)
 	hs.SetVersion(evtVersion)
+	c.publishHostDelta(hostDeltaType(ok), hostInfo.GetHostName(), hs)
 	log.WithFields(log.Fields{
-		"hostname":  hostInfo.GetHostName(),
-		"available": hostInfo.GetAvailable(),
-		"version":   evtVersion,
+		"hostname":    hostInfo.GetHostName(),
+		"available":   hostInfo.GetAvailable(),
+		"maintenance": hostInfo.GetMaintenanceStatus(),
+		"version":     evtVersion,
 	}).Debug("update agent info in host cache")
 }
 
@@ -646,11 +906,13 @@ func (c *hostCache) updateHostAvailable(event *scalar.HostEvent) {
 
 	if !ok {
 		hs = hostsummary.NewMesosHostSummary(hostInfo.GetHostName())
+		c.applyMaintenanceStatus(hostInfo.GetHostName(), hs)
 		c.hostIndex[hostInfo.GetHostName()] = hs
 	}
 
 	hs.SetAvailable(hostInfo.GetAvailable())
 	hs.SetVersion(evtVersion)
+	c.publishHostDelta(hostDeltaType(ok), hostInfo.GetHostName(), hs)
 	log.WithFields(log.Fields{
 		"hostname":  hostInfo.GetHostName(),
 		"available": hostInfo.GetAvailable(),
@@ -684,6 +946,16 @@ func (c *hostCache) Start() {
 		},
 	)
 
+	c.backgroundMgr.RegisterWorks(
+		background.Work{
+			Name: _hostCacheMaintenance,
+			Func: func(_ *uatomic.Bool) {
+				c.reconcileMaintenance()
+			},
+			Period: _hostCacheMaintenancePeriod,
+		},
+	)
+
 	go c.waitForHostEvents()
 
 	log.Warn("hostCache started")
@@ -725,12 +997,23 @@ func (c *hostCache) RecoverPodInfoOnHost(
 			hs = hostsummary.NewMesosHostSummary(hostname)
 		} else {
 			// TODO: populate capacity and version correctly
-			hs = hostsummary.NewKubeletHostSummary(hostname, models.HostResources{}, "")
+			hs = c.newKubeletHostSummary(hostname, models.HostResources{}, "")
 		}
+		c.applyMaintenanceStatus(hostname, hs)
 		c.hostIndex[hostname] = hs
 	}
 
 	hs.RecoverPodInfo(id, state, spec)
+	c.publishHostDelta(hostDeltaType(ok), hostname, hs)
+}
+
+// hostDeltaType returns the type of the change made to a host, depending
+// on whether the host was already in the cache.
+func hostDeltaType(existing bool) hostmgr.HostDelta_Type {
+	if existing {
+		return hostmgr.HostDelta_TYPE_HOST_UPDATED
+	}
+	return hostmgr.HostDelta_TYPE_HOST_ADDED
 }
 
 // AddPodsToHost is a temporary method to add host entries in host cache.
@@ -22,19 +22,33 @@ import (
 	"testing"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/common/v1alpha/constraints"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins/fake"
+	plugins_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/plugins/mocks"
+	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
 
+	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var (
@@ -171,6 +185,35 @@ func (suite *HostCacheTestSuite) TestAcquireLeases() {
 	}
 }
 
+// TestLeaseReservesOffers tests that the offers on the hosts leased for
+// placement are pinned, and released when the lease is terminated.
+func (suite *HostCacheTestSuite) TestLeaseReservesOffers() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+	plugin := plugins_mocks.NewMockPlugin(ctrl)
+
+	hosts := hostsummary.GenerateFakeHostSummaries(1)
+	hc := &hostCache{
+		hostIndex: make(map[string]hostsummary.HostSummary),
+		plugin:    plugin,
+	}
+	for _, s := range hosts {
+		hc.hostIndex[s.GetHostname()] = s
+	}
+	hostname := hosts[0].GetHostname()
+
+	plugin.EXPECT().RecordDemand()
+	plugin.EXPECT().ReserveOffers(hostname, _offerReservationTTL)
+	leases, _ := hc.AcquireLeases(&hostmgr.HostFilter{})
+	suite.Len(leases, 1)
+
+	plugin.EXPECT().ReleaseOffers(hostname)
+	suite.NoError(hc.TerminateLease(
+		hostname,
+		leases[0].GetLeaseId().GetValue(),
+	))
+}
+
 // TestGetClusterCapacity tests the host cache GetClusterCapacity API
 func (suite *HostCacheTestSuite) TestGetClusterCapacity() {
 	hosts := hostsummary.GenerateFakeHostSummaries(10)
@@ -196,6 +239,174 @@ func (suite *HostCacheTestSuite) TestGetClusterCapacity() {
 	suite.Equal(expectedAllocation, allocation)
 }
 
+// TestHostEventsFromFakePlugin tests that the host cache tracks the hosts
+// of a cluster simulated by the fake plugin.
+func (suite *HostCacheTestSuite) TestHostEventsFromFakePlugin() {
+	hostEventCh := make(chan *p2kscalar.HostEvent, 100)
+	podEventCh := make(chan *p2kscalar.PodEvent, 100)
+	plugin := fake.NewFakeManager(
+		p2kconfig.FakeConfig{
+			NumHosts:  3,
+			HostCPU:   4,
+			HostMemMb: 1024,
+		},
+		podEventCh,
+		hostEventCh,
+	)
+	hc := New(
+		hostEventCh,
+		background.NewManager(),
+		plugin,
+		p2kconfig.OversubscriptionConfig{},
+		tally.NoopScope,
+	)
+	hc.Start()
+	defer hc.Stop()
+	suite.NoError(plugin.Start())
+	defer plugin.Stop()
+
+	waitForCapacity := func(cpu, mem float64) {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			capacity, _ := hc.GetClusterCapacity()
+			if capacity.GetCPU() == cpu && capacity.GetMem() == mem {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		capacity, _ := hc.GetClusterCapacity()
+		suite.Equal(cpu, capacity.GetCPU())
+		suite.Equal(mem, capacity.GetMem())
+	}
+
+	waitForCapacity(12, 3072)
+
+	plugin.RemoveHost("fake-host-0")
+	waitForCapacity(8, 2048)
+}
+
+// TestUpdateAgent tests that agent attributes, fault domain and maintenance
+// status are propagated to the host summary, and used in host matching.
+func (suite *HostCacheTestSuite) TestUpdateAgent() {
+	hc := &hostCache{
+		hostIndex: make(map[string]hostsummary.HostSummary),
+	}
+
+	hostname := "agent-host"
+	cpuName := "cpus"
+	cpu := 4.0
+	region := "region1"
+	zone := "zone1"
+	agent := &mesosmaster.Response_GetAgents_Agent{
+		AgentInfo: &mesos.AgentInfo{
+			Hostname: &hostname,
+			Domain: &mesos.DomainInfo{
+				FaultDomain: &mesos.DomainInfo_FaultDomain{
+					Region: &mesos.DomainInfo_FaultDomain_RegionInfo{
+						Name: &region,
+					},
+					Zone: &mesos.DomainInfo_FaultDomain_ZoneInfo{
+						Name: &zone,
+					},
+				},
+			},
+		},
+		TotalResources: []*mesos.Resource{{
+			Name:   &cpuName,
+			Scalar: &mesos.Value_Scalar{Value: &cpu},
+		}},
+	}
+
+	hc.updateAgent(p2kscalar.BuildHostEventFromAgent(
+		agent, p2kscalar.HostDraining, p2kscalar.UpdateAgent))
+
+	hs, ok := hc.hostIndex[hostname]
+	suite.True(ok)
+	suite.Equal(cpu, hs.GetCapacity().NonSlack.CPU)
+	suite.Equal([]*peloton.Label{
+		{Key: common.RegionKey, Value: region},
+		{Key: common.ZoneKey, Value: zone},
+	}, hs.GetHostLease().GetHostSummary().GetLabels())
+
+	// A draining host is not matched.
+	match := hs.TryMatch(&hostmgr.HostFilter{})
+	suite.Equal(hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_STATUS,
+		match.Result)
+
+	// Once the host is up again, it is matched.
+	hc.updateAgent(p2kscalar.BuildHostEventFromAgent(
+		agent, p2kscalar.HostUp, p2kscalar.UpdateAgent))
+	match = hs.TryMatch(&hostmgr.HostFilter{})
+	suite.Equal(hostmgr.HostFilterResult_HOST_FILTER_MATCH, match.Result)
+}
+
+// TestAddNodeWithTaintsAndLabels tests that the taints, labels and
+// allocatable resources of a k8s node are used to match hosts.
+func (suite *HostCacheTestSuite) TestAddNodeWithTaintsAndLabels() {
+	hc := &hostCache{
+		hostIndex: make(map[string]hostsummary.HostSummary),
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "node",
+			ResourceVersion: "1",
+			Labels:          map[string]string{"disk": "ssd"},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{
+				Key:    "dedicated",
+				Value:  "batch",
+				Effect: corev1.TaintEffectNoSchedule,
+			}},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	event, err := p2kscalar.BuildHostEventFromNode(node, p2kscalar.AddHost)
+	suite.NoError(err)
+	hc.addHost(event)
+
+	hs, ok := hc.hostIndex["node"]
+	suite.True(ok)
+	suite.Equal(float64(8), hs.GetCapacity().NonSlack.CPU)
+	suite.Equal(float64(6), hs.GetAvailable().NonSlack.CPU)
+
+	// The taint of the node is not tolerated.
+	match := hs.TryMatch(&hostmgr.HostFilter{})
+	suite.Equal(hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+		match.Result)
+
+	filter := &hostmgr.HostFilter{
+		Tolerations: []*pbpod.Toleration{{
+			Key:   "dedicated",
+			Value: "batch",
+		}},
+		NodeSelector: []*peloton.Label{{Key: "disk", Value: "hdd"}},
+	}
+	match = hs.TryMatch(filter)
+	suite.Equal(hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+		match.Result)
+
+	// Once the node is relabelled, it is matched.
+	node.ResourceVersion = "2"
+	node.Labels["disk"] = "hdd"
+	event, err = p2kscalar.BuildHostEventFromNode(
+		node, p2kscalar.UpdateHostSpec)
+	suite.NoError(err)
+	hc.updateHostSpec(event)
+	match = hs.TryMatch(filter)
+	suite.Equal(hostmgr.HostFilterResult_HOST_FILTER_MATCH, match.Result)
+}
+
 // TestMarshal tests the host cache GetSummaries API.
 func (suite *HostCacheTestSuite) TestGetSummaries() {
 	hosts := hostsummary.GenerateFakeHostSummaries(10)
@@ -527,6 +738,37 @@ func (suite *HostCacheTestSuite) TestRecoverPodInfoOnHostWithTerminalState() {
 	suite.False(ok)
 }
 
+// TestGetPodHostnames tests that the pods recovered on the hosts are
+// returned with their hostname.
+func (suite *HostCacheTestSuite) TestGetPodHostnames() {
+	hc := &hostCache{
+		hostIndex:    map[string]hostsummary.HostSummary{},
+		podHeldIndex: map[string]string{},
+	}
+	suite.Empty(hc.GetPodHostnames())
+
+	podSpec := &pod.PodSpec{
+		PodName: &peloton.PodName{Value: "pod_name"},
+	}
+	hc.RecoverPodInfoOnHost(
+		&peloton.PodID{Value: "pod1"},
+		"hostname1",
+		pod.PodState_POD_STATE_RUNNING,
+		podSpec,
+	)
+	hc.RecoverPodInfoOnHost(
+		&peloton.PodID{Value: "pod2"},
+		"hostname2",
+		pod.PodState_POD_STATE_LAUNCHED,
+		podSpec,
+	)
+
+	suite.Equal(map[string]string{
+		"pod1": "hostname1",
+		"pod2": "hostname2",
+	}, hc.GetPodHostnames())
+}
+
 // TODO: move to use mock after host summary is moved to a different package.
 func TestHoldForPods(t *testing.T) {
 	require := require.New(t)
@@ -596,3 +838,116 @@ func TestResetExpiredHeldHostSummaries(t *testing.T) {
 	require.Equal(hs.GetHostname(), ret[0])
 	require.Empty(hc.podHeldIndex)
 }
+
+// TODO: move to use mock after host summary is moved to a different package.
+func TestUpdatePodResources(t *testing.T) {
+	require := require.New(t)
+	hs := hostsummary.GenerateFakeHostSummaries(1)[0]
+	podID := &peloton.PodID{Value: uuid.New()}
+	hc := &hostCache{
+		hostIndex:    map[string]hostsummary.HostSummary{hs.GetHostname(): hs},
+		podHeldIndex: map[string]string{},
+	}
+	oldSpec := &pod.PodSpec{
+		Containers: []*pod.ContainerSpec{
+			{Resource: &pod.ResourceSpec{CpuLimit: 1.0, MemLimitMb: 10.0}},
+		},
+	}
+	newSpec := &pod.PodSpec{
+		Containers: []*pod.ContainerSpec{
+			{Resource: &pod.ResourceSpec{CpuLimit: 2.0, MemLimitMb: 10.0}},
+		},
+	}
+	hs.RecoverPodInfo(podID, pod.PodState_POD_STATE_RUNNING, oldSpec)
+
+	prev, err := hc.UpdatePodResources(hs.GetHostname(), podID, newSpec)
+	require.NoError(err)
+	require.Equal(oldSpec, prev)
+	_, spec, ok := hs.GetPodInfo(podID)
+	require.True(ok)
+	require.Equal(newSpec, spec)
+
+	_, err = hc.UpdatePodResources("unknown-host", podID, newSpec)
+	require.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestEvictPods tests that the revocable pods evicted from a host are
+// killed, and the eviction hooks are called.
+func TestEvictPods(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	plugin := plugins_mocks.NewMockPlugin(ctrl)
+	scope := tally.NewTestScope("", map[string]string{})
+	hc := &hostCache{
+		plugin:  plugin,
+		metrics: NewMetrics(scope),
+	}
+	podIDs := []*peloton.PodID{{Value: "pod-1"}, {Value: "pod-2"}}
+
+	var evictedHost string
+	evicted := make(chan []*peloton.PodID, 1)
+	hc.AddEvictionHook(func(hostname string, podIDs []*peloton.PodID) {
+		evictedHost = hostname
+		evicted <- podIDs
+	})
+
+	plugin.EXPECT().KillPod(gomock.Any(), "pod-1").Return(nil)
+	plugin.EXPECT().KillPod(gomock.Any(), "pod-2").
+		Return(fmt.Errorf("fake KillPod error"))
+	hc.evictPods(_hostname, nil)
+	hc.evictPods(_hostname, podIDs)
+
+	select {
+	case ids := <-evicted:
+		require.Equal(_hostname, evictedHost)
+		require.Equal(podIDs, ids)
+	case <-time.After(10 * time.Second):
+		require.Fail("eviction hook not called")
+	}
+}
+
+// TestEvaluatePodConstraint tests evaluating pod anti-affinity against the
+// pods on a host.
+func (suite *HostCacheTestSuite) TestEvaluatePodConstraint() {
+	jobID := uuid.New()
+	hs := hostsummary.GenerateFakeHostSummaries(1)[0]
+	hc := &hostCache{
+		hostIndex:    map[string]hostsummary.HostSummary{hs.GetHostname(): hs},
+		podHeldIndex: map[string]string{},
+	}
+	antiAffinity := &pod.Constraint{
+		Type: pod.Constraint_CONSTRAINT_TYPE_LABEL,
+		LabelConstraint: &pod.LabelConstraint{
+			Kind:      pod.LabelConstraint_LABEL_CONSTRAINT_KIND_POD,
+			Condition: pod.LabelConstraint_LABEL_CONSTRAINT_CONDITION_LESS_THAN,
+			Label: &peloton.Label{
+				Key: fmt.Sprintf(
+					common.SystemLabelKeyTemplate,
+					common.SystemLabelPrefix,
+					common.SystemLabelJobID,
+				),
+				Value: jobID,
+			},
+			Requirement: 1,
+		},
+	}
+
+	result, err := hc.EvaluatePodConstraint(hs.GetHostname(), antiAffinity)
+	suite.NoError(err)
+	suite.Equal(constraints.EvaluateResultMatch, result)
+
+	hc.RecoverPodInfoOnHost(
+		&peloton.PodID{Value: fmt.Sprintf("%s-%d-%d", jobID, 0, 1)},
+		hs.GetHostname(),
+		pod.PodState_POD_STATE_RUNNING,
+		&pod.PodSpec{},
+	)
+	result, err = hc.EvaluatePodConstraint(hs.GetHostname(), antiAffinity)
+	suite.NoError(err)
+	suite.Equal(constraints.EvaluateResultMismatch, result)
+
+	_, err = hc.EvaluatePodConstraint("unknown", antiAffinity)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
@@ -72,6 +72,14 @@ type baseHostSummary struct {
 	// Labels on this host.
 	labels []*peloton.Label
 
+	// Maintenance status of this host. Pods are not placed on hosts
+	// in maintenance.
+	maintenance p2kscalar.MaintenanceStatus
+
+	// Taints of this host. Pods are not placed on the host unless they
+	// tolerate its taints.
+	taints []p2kscalar.Taint
+
 	// List of port ranges available for allocation.
 	ports []*pbhost.PortRange
 
@@ -96,6 +104,14 @@ type baseHostSummary struct {
 	// capacity of the host
 	capacity models.HostResources
 
+	// resources of the host which can be allocated to pods. Empty if the
+	// whole capacity can be allocated.
+	allocatable models.HostResources
+
+	// Policy deciding the slack resources of the host which revocable pods
+	// can use. Nil if the host is not oversubscribed.
+	oversubscription *OversubscriptionPolicy
+
 	// Resources allocated on the host. This should always be equal to the sum
 	// of resources in pods.
 	allocated models.HostResources
@@ -140,7 +156,7 @@ func (a *baseHostSummary) TryMatch(
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if a.status != ReadyHost {
+	if a.status != ReadyHost || a.maintenance != p2kscalar.HostUp {
 		return Match{
 			Result: hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_STATUS,
 		}
@@ -296,6 +312,11 @@ func (a *baseHostSummary) GetHostLease() *hostmgr.HostLease {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
+	var slackResources *peloton.Resources
+	if a.oversubscription != nil {
+		slackResources = scalar.ToPelotonResources(a.available.Slack)
+	}
+
 	return &hostmgr.HostLease{
 		LeaseId: &hostmgr.LeaseID{
 			Value: a.leaseID,
@@ -304,6 +325,7 @@ func (a *baseHostSummary) GetHostLease() *hostmgr.HostLease {
 			Hostname: a.hostname,
 			// TODO: replace this with models.HostResources.
 			Resources:      scalar.ToPelotonResources(a.available.NonSlack),
+			SlackResources: slackResources,
 			Labels:         a.labels,
 			AvailablePorts: a.ports,
 		},
@@ -395,6 +417,16 @@ func (a *baseHostSummary) GetHeldPods() []*peloton.PodID {
 	return result
 }
 
+// GetPodIDs returns the IDs of the pods assigned or running on the host.
+func (a *baseHostSummary) GetPodIDs() []string {
+	var result []string
+	a.pods.RangePods(func(id string, p *podInfo) error {
+		result = append(result, id)
+		return nil
+	})
+	return result
+}
+
 // DeleteExpiredHolds deletes expired held pods in a hostSummary, returns
 // whether the hostSummary is free of helds,
 // available resource,
@@ -507,6 +539,77 @@ func (a *baseHostSummary) SetAvailable(r models.HostResources) {
 	a.available = r
 }
 
+// SetLabels sets the labels of the host.
+func (a *baseHostSummary) SetLabels(labels []*peloton.Label) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.labels = labels
+}
+
+// SetMaintenanceStatus sets the maintenance status of the host.
+func (a *baseHostSummary) SetMaintenanceStatus(
+	status p2kscalar.MaintenanceStatus,
+) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.maintenance = status
+}
+
+// GetMaintenanceStatus returns the maintenance status of the host.
+func (a *baseHostSummary) GetMaintenanceStatus() p2kscalar.MaintenanceStatus {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.maintenance
+}
+
+// GetPodLabelValues returns the number of pods on the host with each
+// label, including the ID of the job of the pods.
+func (a *baseHostSummary) GetPodLabelValues() constraints.LabelValues {
+	return a.pods.GetLabelValues()
+}
+
+// EvaluatePodConstraint evaluates the pod constraints within given
+// constraint against the pods on the host.
+func (a *baseHostSummary) EvaluatePodConstraint(
+	constraint *pbpod.Constraint,
+) (constraints.EvaluateResult, error) {
+	evaluator := constraints.NewEvaluator(
+		pbpod.LabelConstraint_LABEL_CONSTRAINT_KIND_POD)
+	return evaluator.Evaluate(constraint, a.pods.GetLabelValues())
+}
+
+// SetTaints sets the taints of the host.
+func (a *baseHostSummary) SetTaints(taints []p2kscalar.Taint) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.taints = taints
+}
+
+// SetAllocatable sets the allocatable resources of the host.
+func (a *baseHostSummary) SetAllocatable(r models.HostResources) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.allocatable = r
+}
+
+// SetOversubscriptionPolicy sets the oversubscription policy of the host.
+func (a *baseHostSummary) SetOversubscriptionPolicy(p *OversubscriptionPolicy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.oversubscription = p
+}
+
+// GetPodsToEvict returns no pod, pods are not evicted by default.
+func (a *baseHostSummary) GetPodsToEvict() []*peloton.PodID {
+	return nil
+}
+
 // casStatus lock-freely sets the status to new value and update lease ID if
 // current value is old, otherwise returns error.
 // This function assumes baseHostSummary lock is held before calling.
@@ -540,7 +643,15 @@ func (a *baseHostSummary) matchHostFilter(
 	if min != nil {
 		// Get min required resources.
 		minRes := scalar.FromResourceSpec(min)
-		if !a.available.NonSlack.Contains(minRes) {
+		if c.GetResourceConstraint().GetRevocable() && a.oversubscription != nil {
+			// Revocable pods use the slack resources for the
+			// oversubscribed resource types.
+			slack, nonSlack := a.oversubscription.splitResources(minRes)
+			if !a.available.Slack.Contains(slack) ||
+				!a.available.NonSlack.Contains(nonSlack) {
+				return hostmgr.HostFilterResult_HOST_FILTER_INSUFFICIENT_RESOURCES
+			}
+		} else if !a.available.NonSlack.Contains(minRes) {
 			return hostmgr.HostFilterResult_HOST_FILTER_INSUFFICIENT_RESOURCES
 		}
 	}
@@ -557,21 +668,65 @@ func (a *baseHostSummary) matchHostFilter(
 		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
 	}
 
+	if !toleratesTaints(c.GetTolerations(), a.taints) {
+		log.WithFields(log.Fields{
+			"hostname":    a.hostname,
+			"taints":      a.taints,
+			"tolerations": c.GetTolerations(),
+		}).Debug("Taints of the host not tolerated")
+		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+	}
+
+	if !matchNodeSelector(c.GetNodeSelector(), a.labels) {
+		log.WithFields(log.Fields{
+			"hostname":      a.hostname,
+			"labels":        a.labels,
+			"node_selector": c.GetNodeSelector(),
+		}).Debug("Labels do not match node selector")
+		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+	}
+
 	if sc == nil {
 		// No scheduling constraint, we have a match.
 		return hostmgr.HostFilterResult_HOST_FILTER_MATCH
 	}
 
-	// Only evaluator based on host constraints is in use.
-	evaluator := constraints.NewEvaluator(
-		pbpod.LabelConstraint_LABEL_CONSTRAINT_KIND_HOST)
+	// Host constraints are evaluated against the labels of the host, and
+	// pod constraints (pod affinity and anti-affinity) against the labels
+	// of the pods placed on the host.
+	if !a.matchConstraint(
+		sc,
+		pbpod.LabelConstraint_LABEL_CONSTRAINT_KIND_HOST,
+		constraints.GetHostLabelValues(a.hostname, a.labels),
+	) {
+		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+	}
+
+	if !a.matchConstraint(
+		sc,
+		pbpod.LabelConstraint_LABEL_CONSTRAINT_KIND_POD,
+		a.pods.GetLabelValues(),
+	) {
+		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+	}
+
+	return hostmgr.HostFilterResult_HOST_FILTER_MATCH
+}
+
+// matchConstraint returns true if the label constraints of given kind
+// within the scheduling constraint match the label values.
+func (a *baseHostSummary) matchConstraint(
+	sc *pbpod.Constraint,
+	kind pbpod.LabelConstraint_Kind,
+	lv constraints.LabelValues,
+) bool {
+	evaluator := constraints.NewEvaluator(kind)
 
-	lv := constraints.GetHostLabelValues(a.hostname, a.labels)
 	result, err := evaluator.Evaluate(sc, lv)
 	if err != nil {
 		log.WithError(err).
 			Error("Evaluating input constraint")
-		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+		return false
 	}
 
 	switch result {
@@ -589,10 +744,67 @@ func (a *baseHostSummary) matchHostFilter(
 			"hostname":   a.hostname,
 			"constraint": sc,
 		}).Debug("Attributes do not match constraint")
-		return hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS
+		return false
 	}
+	return true
+}
 
-	return hostmgr.HostFilterResult_HOST_FILTER_MATCH
+// toleratesTaints returns true if the tolerations tolerate all the taints
+// which prevent pods from being placed on the host. Taints with the
+// PreferNoSchedule effect do not prevent placement.
+func toleratesTaints(
+	tolerations []*pbpod.Toleration,
+	taints []p2kscalar.Taint,
+) bool {
+	for _, taint := range taints {
+		if taint.Effect == p2kscalar.TaintEffectPreferNoSchedule {
+			continue
+		}
+
+		tolerated := false
+		for _, t := range tolerations {
+			if toleratesTaint(t, taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// toleratesTaint returns true if the toleration tolerates the taint,
+// following the k8s matching rules.
+func toleratesTaint(t *pbpod.Toleration, taint p2kscalar.Taint) bool {
+	if t.GetEffect() != "" && t.GetEffect() != taint.Effect {
+		return false
+	}
+
+	if t.GetOperator() == pbpod.Toleration_TOLERATION_OPERATOR_EXISTS {
+		// An empty key with the exists operator matches all the keys.
+		return t.GetKey() == "" || t.GetKey() == taint.Key
+	}
+	return t.GetKey() == taint.Key && t.GetValue() == taint.Value
+}
+
+// matchNodeSelector returns true if the labels contain all the labels of
+// the node selector.
+func matchNodeSelector(selector, labels []*peloton.Label) bool {
+	for _, s := range selector {
+		found := false
+		for _, l := range labels {
+			if l.GetKey() == s.GetKey() && l.GetValue() == s.GetValue() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
 
 func (a *baseHostSummary) CompleteLaunchPod(pod *models.LaunchablePod) {
@@ -622,7 +834,48 @@ func (a *baseHostSummary) RecoverPodInfo(
 	}
 
 	info.state = state
-	info.spec = spec
+	a.pods.SetPodSpec(id.GetValue(), spec)
+}
+
+// UpdatePodResources updates the resources of a pod on the host in place.
+// The difference between the new and the old resources of the pod must fit
+// in the available resources of the host.
+func (a *baseHostSummary) UpdatePodResources(
+	id *peloton.PodID,
+	spec *pbpod.PodSpec,
+) (*pbpod.PodSpec, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, ok := a.pods.GetPodInfo(id.GetValue())
+	if !ok {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"pod %s not found on host %s", id.GetValue(), a.hostname)
+	}
+
+	oldRes := scalar.FromPodSpec(info.spec)
+	newRes := scalar.FromPodSpec(spec)
+	available, ok := a.available.NonSlack.Add(oldRes).TrySubtract(newRes)
+	if !ok {
+		return nil, yarpcerrors.ResourceExhaustedErrorf(
+			"host %s has insufficient resources to resize pod %s",
+			a.hostname, id.GetValue())
+	}
+
+	a.available.NonSlack = available
+	a.allocated.NonSlack = a.allocated.NonSlack.Subtract(oldRes).Add(newRes)
+
+	oldSpec := info.spec
+	a.pods.SetPodSpec(id.GetValue(), spec)
+
+	log.WithFields(log.Fields{
+		"hostname":  a.hostname,
+		"pod_id":    id.GetValue(),
+		"old_res":   oldRes,
+		"new_res":   newRes,
+		"available": a.available,
+	}).Debug("Updated pod resources")
+	return oldSpec, nil
 }
 
 type noopHostStrategy struct{}
@@ -23,6 +23,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/v1alpha/constraints"
 	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
 
@@ -30,6 +32,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 // HostSummaryTestSuite is test suite for p2k host summary package.
@@ -59,6 +62,9 @@ func (suite *HostSummaryTestSuite) TestTryMatchReadyHost() {
 		allocated      scalar.Resources
 		heldPodIDs     map[string]time.Time
 		filter         *hostmgr.HostFilter
+		labels         []*peloton.Label
+		taints         []p2kscalar.Taint
+		maintenance    p2kscalar.MaintenanceStatus
 		beforeStatus   HostStatus
 		afterStatus    HostStatus
 	}{
@@ -140,6 +146,165 @@ func (suite *HostSummaryTestSuite) TestTryMatchReadyHost() {
 			beforeStatus: ReservedHost,
 			afterStatus:  ReservedHost,
 		},
+		"match-fail-status-mismatch-draining": {
+			expectedResult: hostmgr.
+				HostFilterResult_HOST_FILTER_MISMATCH_STATUS,
+			allocated:    CreateResource(1.0, 1.0),
+			heldPodIDs:   nil,
+			filter:       &hostmgr.HostFilter{},
+			maintenance:  p2kscalar.HostDraining,
+			beforeStatus: ReadyHost,
+			afterStatus:  ReadyHost,
+		},
+		"match-success-zone-constraint": {
+			expectedResult: hostmgr.HostFilterResult_HOST_FILTER_MATCH,
+			allocated:      CreateResource(1.0, 1.0),
+			heldPodIDs:     nil,
+			filter: &hostmgr.HostFilter{
+				SchedulingConstraint: &pod.Constraint{
+					Type: pod.Constraint_CONSTRAINT_TYPE_LABEL,
+					LabelConstraint: &pod.LabelConstraint{
+						Kind: pod.LabelConstraint_LABEL_CONSTRAINT_KIND_HOST,
+						Condition: pod.
+							LabelConstraint_LABEL_CONSTRAINT_CONDITION_EQUAL,
+						Label: &peloton.Label{
+							Key:   common.ZoneKey,
+							Value: "zone1",
+						},
+						Requirement: 1,
+					},
+				},
+			},
+			labels: []*peloton.Label{
+				{Key: common.ZoneKey, Value: "zone1"},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  PlacingHost,
+		},
+		"match-fail-zone-constraint": {
+			expectedResult: hostmgr.
+				HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+			allocated:  CreateResource(1.0, 1.0),
+			heldPodIDs: nil,
+			filter: &hostmgr.HostFilter{
+				SchedulingConstraint: &pod.Constraint{
+					Type: pod.Constraint_CONSTRAINT_TYPE_LABEL,
+					LabelConstraint: &pod.LabelConstraint{
+						Kind: pod.LabelConstraint_LABEL_CONSTRAINT_KIND_HOST,
+						Condition: pod.
+							LabelConstraint_LABEL_CONSTRAINT_CONDITION_EQUAL,
+						Label: &peloton.Label{
+							Key:   common.ZoneKey,
+							Value: "zone2",
+						},
+						Requirement: 1,
+					},
+				},
+			},
+			labels: []*peloton.Label{
+				{Key: common.ZoneKey, Value: "zone1"},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  ReadyHost,
+		},
+		"match-success-taint-tolerated": {
+			expectedResult: hostmgr.HostFilterResult_HOST_FILTER_MATCH,
+			allocated:      CreateResource(1.0, 1.0),
+			filter: &hostmgr.HostFilter{
+				Tolerations: []*pod.Toleration{
+					{
+						Key:      "dedicated",
+						Operator: pod.Toleration_TOLERATION_OPERATOR_EQUAL,
+						Value:    "batch",
+						Effect:   p2kscalar.TaintEffectNoSchedule,
+					},
+				},
+			},
+			taints: []p2kscalar.Taint{
+				{
+					Key:    "dedicated",
+					Value:  "batch",
+					Effect: p2kscalar.TaintEffectNoSchedule,
+				},
+				{
+					Key:    "gpu",
+					Effect: p2kscalar.TaintEffectPreferNoSchedule,
+				},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  PlacingHost,
+		},
+		"match-success-taint-tolerated-exists": {
+			expectedResult: hostmgr.HostFilterResult_HOST_FILTER_MATCH,
+			allocated:      CreateResource(1.0, 1.0),
+			filter: &hostmgr.HostFilter{
+				Tolerations: []*pod.Toleration{
+					{Operator: pod.Toleration_TOLERATION_OPERATOR_EXISTS},
+				},
+			},
+			taints: []p2kscalar.Taint{
+				{
+					Key:    "dedicated",
+					Value:  "batch",
+					Effect: p2kscalar.TaintEffectNoExecute,
+				},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  PlacingHost,
+		},
+		"match-fail-taint-not-tolerated": {
+			expectedResult: hostmgr.
+				HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+			allocated: CreateResource(1.0, 1.0),
+			filter: &hostmgr.HostFilter{
+				Tolerations: []*pod.Toleration{
+					{
+						Key:      "dedicated",
+						Operator: pod.Toleration_TOLERATION_OPERATOR_EQUAL,
+						Value:    "stateless",
+					},
+				},
+			},
+			taints: []p2kscalar.Taint{
+				{
+					Key:    "dedicated",
+					Value:  "batch",
+					Effect: p2kscalar.TaintEffectNoSchedule,
+				},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  ReadyHost,
+		},
+		"match-success-node-selector": {
+			expectedResult: hostmgr.HostFilterResult_HOST_FILTER_MATCH,
+			allocated:      CreateResource(1.0, 1.0),
+			filter: &hostmgr.HostFilter{
+				NodeSelector: []*peloton.Label{
+					{Key: "disk", Value: "ssd"},
+				},
+			},
+			labels: []*peloton.Label{
+				{Key: common.ZoneKey, Value: "zone1"},
+				{Key: "disk", Value: "ssd"},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  PlacingHost,
+		},
+		"match-fail-node-selector": {
+			expectedResult: hostmgr.
+				HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+			allocated: CreateResource(1.0, 1.0),
+			filter: &hostmgr.HostFilter{
+				NodeSelector: []*peloton.Label{
+					{Key: "disk", Value: "ssd"},
+				},
+			},
+			labels: []*peloton.Label{
+				{Key: "disk", Value: "hdd"},
+			},
+			beforeStatus: ReadyHost,
+			afterStatus:  ReadyHost,
+		},
 	}
 
 	for ttName, tt := range testTable {
@@ -149,6 +314,9 @@ func (suite *HostSummaryTestSuite) TestTryMatchReadyHost() {
 		s.capacity.NonSlack = _capacity
 		s.available.NonSlack = _capacity.Subtract(tt.allocated)
 		s.heldPodIDs = tt.heldPodIDs
+		s.SetLabels(tt.labels)
+		s.SetTaints(tt.taints)
+		s.SetMaintenanceStatus(tt.maintenance)
 
 		match := s.TryMatch(tt.filter)
 
@@ -467,3 +635,147 @@ func TestDeleteExpiredHolds(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdatePodResources(t *testing.T) {
+	require := require.New(t)
+
+	newSpec := func(cpu, mem float64) *pbpod.PodSpec {
+		return &pbpod.PodSpec{
+			Containers: []*pbpod.ContainerSpec{
+				{Resource: &pbpod.ResourceSpec{
+					CpuLimit:   cpu,
+					MemLimitMb: mem,
+				}},
+			},
+		}
+	}
+
+	s := NewFakeHostSummary(_hostname, _version, _capacity)
+	id := &peloton.PodID{Value: _podID}
+	oldSpec := newSpec(2.0, 20.0)
+	s.RecoverPodInfo(id, pbpod.PodState_POD_STATE_RUNNING, oldSpec)
+	s.SetAllocated(CreateResource(2.0, 20.0))
+	s.available.NonSlack = CreateResource(8.0, 80.0)
+
+	// Grow the pod within the headroom of the host.
+	spec := newSpec(4.0, 40.0)
+	prev, err := s.UpdatePodResources(id, spec)
+	require.NoError(err)
+	require.Equal(oldSpec, prev)
+	require.Equal(CreateResource(4.0, 40.0), s.GetAllocated().NonSlack)
+	require.Equal(CreateResource(6.0, 60.0), s.GetAvailable().NonSlack)
+	_, curSpec, ok := s.GetPodInfo(id)
+	require.True(ok)
+	require.Equal(spec, curSpec)
+
+	// Growing beyond the headroom of the host fails and leaves the pod as is.
+	_, err = s.UpdatePodResources(id, newSpec(12.0, 40.0))
+	require.True(yarpcerrors.IsResourceExhausted(err))
+	require.Equal(CreateResource(6.0, 60.0), s.GetAvailable().NonSlack)
+	_, curSpec, _ = s.GetPodInfo(id)
+	require.Equal(spec, curSpec)
+
+	// Unknown pods cannot be resized.
+	_, err = s.UpdatePodResources(
+		&peloton.PodID{Value: uuid.New()}, newSpec(1.0, 10.0))
+	require.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestPodLabelValues tests that the labels of the pods on the host are
+// counted as the pods are added, updated and removed.
+func TestPodLabelValues(t *testing.T) {
+	require := require.New(t)
+
+	jobID := uuid.New()
+	jobIDKey := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelJobID,
+	)
+	podID1 := &peloton.PodID{Value: fmt.Sprintf("%s-%d-%d", jobID, 0, 1)}
+	podID2 := &peloton.PodID{Value: fmt.Sprintf("%s-%d-%d", jobID, 1, 1)}
+	spec := &pbpod.PodSpec{
+		Labels: []*peloton.Label{{Key: "app", Value: "web"}},
+	}
+
+	s := NewFakeHostSummary(_hostname, _version, _capacity)
+	require.Empty(s.GetPodLabelValues())
+
+	s.RecoverPodInfo(podID1, pbpod.PodState_POD_STATE_RUNNING, spec)
+	s.RecoverPodInfo(podID2, pbpod.PodState_POD_STATE_RUNNING, spec)
+	require.Equal(map[string]map[string]uint32{
+		jobIDKey: {jobID: 2},
+		"app":    {"web": 2},
+	}, map[string]map[string]uint32(s.GetPodLabelValues()))
+
+	// Replacing the spec of a pod replaces its labels.
+	s.RecoverPodInfo(podID2, pbpod.PodState_POD_STATE_RUNNING, &pbpod.PodSpec{
+		Labels: []*peloton.Label{{Key: "app", Value: "db"}},
+	})
+	require.Equal(map[string]map[string]uint32{
+		jobIDKey: {jobID: 2},
+		"app":    {"web": 1, "db": 1},
+	}, map[string]map[string]uint32(s.GetPodLabelValues()))
+
+	// Terminal pods are removed along with their labels.
+	s.RecoverPodInfo(podID1, pbpod.PodState_POD_STATE_KILLED, spec)
+	s.RecoverPodInfo(podID2, pbpod.PodState_POD_STATE_KILLED, spec)
+	require.Empty(s.GetPodLabelValues())
+}
+
+// TestTryMatchPodAntiAffinity tests that a host running an instance of a job
+// does not match a filter keeping the instances of the job apart.
+func TestTryMatchPodAntiAffinity(t *testing.T) {
+	require := require.New(t)
+
+	jobID := uuid.New()
+	antiAffinity := &pbpod.Constraint{
+		Type: pbpod.Constraint_CONSTRAINT_TYPE_LABEL,
+		LabelConstraint: &pbpod.LabelConstraint{
+			Kind:      pbpod.LabelConstraint_LABEL_CONSTRAINT_KIND_POD,
+			Condition: pbpod.LabelConstraint_LABEL_CONSTRAINT_CONDITION_LESS_THAN,
+			Label: &peloton.Label{
+				Key: fmt.Sprintf(
+					common.SystemLabelKeyTemplate,
+					common.SystemLabelPrefix,
+					common.SystemLabelJobID,
+				),
+				Value: jobID,
+			},
+			Requirement: 1,
+		},
+	}
+	filter := &hostmgr.HostFilter{SchedulingConstraint: antiAffinity}
+
+	s := NewFakeHostSummary(_hostname, _version, _capacity)
+	result, err := s.EvaluatePodConstraint(antiAffinity)
+	require.NoError(err)
+	require.Equal(constraints.EvaluateResultMatch, result)
+
+	// Pods of other jobs do not prevent the match.
+	s.RecoverPodInfo(
+		&peloton.PodID{Value: fmt.Sprintf("%s-%d-%d", uuid.New(), 0, 1)},
+		pbpod.PodState_POD_STATE_RUNNING,
+		&pbpod.PodSpec{},
+	)
+	match := s.TryMatch(filter)
+	require.Equal(hostmgr.HostFilterResult_HOST_FILTER_MATCH, match.Result)
+	require.NoError(s.TerminateLease(s.leaseID))
+
+	// A pod of the same job on the host prevents the match.
+	s.RecoverPodInfo(
+		&peloton.PodID{Value: fmt.Sprintf("%s-%d-%d", jobID, 0, 1)},
+		pbpod.PodState_POD_STATE_RUNNING,
+		&pbpod.PodSpec{},
+	)
+	result, err = s.EvaluatePodConstraint(antiAffinity)
+	require.NoError(err)
+	require.Equal(constraints.EvaluateResultMismatch, result)
+
+	match = s.TryMatch(filter)
+	require.Equal(
+		hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_CONSTRAINTS,
+		match.Result,
+	)
+	require.Equal(ReadyHost, s.GetHostStatus())
+}
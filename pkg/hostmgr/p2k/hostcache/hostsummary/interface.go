@@ -20,6 +20,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/common/v1alpha/constraints"
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 )
@@ -51,6 +52,44 @@ type HostSummary interface {
 	// SetAvailable sets the available resource of the host.
 	SetAvailable(r models.HostResources)
 
+	// SetLabels sets the labels of the host, which are used to evaluate
+	// placement constraints.
+	SetLabels(labels []*peloton.Label)
+
+	// SetMaintenanceStatus sets the maintenance status of the host.
+	SetMaintenanceStatus(status p2kscalar.MaintenanceStatus)
+
+	// GetMaintenanceStatus returns the maintenance status of the host.
+	GetMaintenanceStatus() p2kscalar.MaintenanceStatus
+
+	// GetPodLabelValues returns the number of pods on the host with each
+	// label, including the ID of the job of the pods.
+	GetPodLabelValues() constraints.LabelValues
+
+	// EvaluatePodConstraint evaluates the pod constraints within given
+	// constraint against the pods on the host.
+	EvaluatePodConstraint(
+		constraint *pbpod.Constraint,
+	) (constraints.EvaluateResult, error)
+
+	// SetTaints sets the taints of the host. Pods are placed on the host
+	// only if they tolerate its taints.
+	SetTaints(taints []p2kscalar.Taint)
+
+	// SetAllocatable sets the resources of the host which can be allocated
+	// to pods.
+	SetAllocatable(r models.HostResources)
+
+	// SetOversubscriptionPolicy sets the policy deciding the slack
+	// resources of the host which revocable pods can use, nil to disable
+	// oversubscription.
+	SetOversubscriptionPolicy(p *OversubscriptionPolicy)
+
+	// GetPodsToEvict returns the revocable pods which must be evicted
+	// because the non-revocable pods need the slack resources they use.
+	// A pod is returned once.
+	GetPodsToEvict() []*peloton.PodID
+
 	// GetVersion returns the version of the host.
 	GetVersion() string
 
@@ -84,6 +123,9 @@ type HostSummary interface {
 	// GetHeldPods returns a slice of pods that puts the host in held.
 	GetHeldPods() []*peloton.PodID
 
+	// GetPodIDs returns the IDs of the pods assigned or running on the host.
+	GetPodIDs() []string
+
 	// DeleteExpiredHolds deletes expired held pods in a hostSummary, returns
 	// whether the hostSummary is free of helds,
 	// available resource,
@@ -97,6 +139,14 @@ type HostSummary interface {
 	// RecoverPodInfo updates pods info on the host, it is used only
 	// when hostsummary needs to recover the info upon restart
 	RecoverPodInfo(id *peloton.PodID, state pbpod.PodState, spec *pbpod.PodSpec)
+
+	// UpdatePodResources replaces the spec of a pod running on the host
+	// when only its resources change, provided the host has enough headroom
+	// for the new resources. It returns the previous spec of the pod.
+	UpdatePodResources(
+		id *peloton.PodID,
+		spec *pbpod.PodSpec,
+	) (*pbpod.PodSpec, error)
 }
 
 // hostStrategy defines methods that shared by mesos/k8s hosts, but have
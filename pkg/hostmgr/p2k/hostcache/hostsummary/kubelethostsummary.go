@@ -19,6 +19,7 @@ import (
 	"sort"
 
 	pbhost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
@@ -36,6 +37,10 @@ var _ hostStrategy = &kubeletHostSummary{}
 
 type kubeletHostSummary struct {
 	*baseHostSummary
+
+	// Revocable pods which have been returned for eviction, and are not
+	// returned again.
+	evicting map[string]struct{}
 }
 
 // NewKubeletHostSummary returns a zero initialized HostSummary object.
@@ -46,6 +51,7 @@ func NewKubeletHostSummary(
 ) HostSummary {
 	ks := &kubeletHostSummary{
 		baseHostSummary: newBaseHostSummary(hostname, version),
+		evicting:        make(map[string]struct{}),
 	}
 	ks.baseHostSummary.capacity = capacity
 	ks.baseHostSummary.strategy = ks
@@ -100,6 +106,76 @@ func (a *kubeletHostSummary) SetCapacity(r models.HostResources) {
 	a.available = a.calculateAvailable()
 }
 
+// SetAllocatable sets the allocatable resources of the host. Available
+// resources are computed from the allocatable resources rather than the
+// capacity, so they need to be recalculated.
+func (a *kubeletHostSummary) SetAllocatable(r models.HostResources) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.allocatable = r
+	a.available = a.calculateAvailable()
+}
+
+// SetOversubscriptionPolicy sets the oversubscription policy of the host.
+// Revocable pods are accounted for as slack or non-slack depending on the
+// policy, so the allocation needs to be recalculated.
+func (a *kubeletHostSummary) SetOversubscriptionPolicy(
+	p *OversubscriptionPolicy,
+) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.oversubscription = p
+	a.calculateAllocated()
+}
+
+// GetPodsToEvict returns the revocable pods which must be evicted so that
+// the slack resources allocated to revocable pods fit in the slack pool of
+// the host, which shrinks as resources are allocated to non-revocable pods.
+func (a *kubeletHostSummary) GetPodsToEvict() []*peloton.PodID {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.oversubscription == nil {
+		return nil
+	}
+
+	// Forget the pods evicted which are gone.
+	for id := range a.evicting {
+		if _, ok := a.pods.GetPodInfo(id); !ok {
+			delete(a.evicting, id)
+		}
+	}
+
+	revocablePods := make(map[string]scalar.Resources)
+	a.pods.RangePods(func(id string, p *podInfo) error {
+		if _, ok := a.evicting[id]; ok || !p.spec.GetRevocable() {
+			return nil
+		}
+		revocablePods[id], _ = a.oversubscription.podResources(p.spec)
+		return nil
+	})
+
+	pool := a.oversubscription.slackPool(
+		a.getAllocatable().NonSlack,
+		a.allocated.NonSlack,
+	)
+	evicted := a.oversubscription.selectPodsToEvict(pool, revocablePods)
+	for _, id := range evicted {
+		a.evicting[id.GetValue()] = struct{}{}
+	}
+	if len(evicted) > 0 {
+		log.WithFields(log.Fields{
+			"hostname":  a.hostname,
+			"pool":      pool,
+			"allocated": a.allocated,
+			"pods":      evicted,
+		}).Info("Evicting revocable pods")
+	}
+	return evicted
+}
+
 // SetAvailable is noop for k8s agent, since it is calculated on-flight
 func (a *kubeletHostSummary) SetAvailable(r models.HostResources) {
 	a.mu.RLock()
@@ -110,8 +186,24 @@ func (a *kubeletHostSummary) SetAvailable(r models.HostResources) {
 	return
 }
 
+// getAllocatable returns the resources which can be allocated to pods,
+// i.e. the allocatable resources reported by the node if any, or its
+// whole capacity.
+// This function assumes baseHostSummary lock is held before calling.
+func (a *kubeletHostSummary) getAllocatable() models.HostResources {
+	if a.allocatable.NonSlack.Empty() {
+		return a.capacity
+	}
+	return a.allocatable
+}
+
+// calculateAvailable returns the resources of the host which are not
+// allocated to pods. The slack resources are the part of the slack pool of
+// the host which is not allocated to revocable pods.
+// This function assumes baseHostSummary lock is held before calling.
 func (a *kubeletHostSummary) calculateAvailable() models.HostResources {
-	available, ok := a.capacity.TrySubtract(a.allocated)
+	allocatable := a.getAllocatable()
+	nonSlack, ok := allocatable.NonSlack.TrySubtract(a.allocated.NonSlack)
 	if !ok {
 		// Continue with available set to scalar.Resources{}. This would
 		// organically fail in the following steps.
@@ -120,6 +212,7 @@ func (a *kubeletHostSummary) calculateAvailable() models.HostResources {
 				"allocated":    a.allocated,
 				"podToSpecMap": a.getPodToResMap(),
 				"capacity":     a.capacity,
+				"allocatable":  a.allocatable,
 			},
 		).Error("kubeletHostSummary: Allocated more resources than capacity")
 		return models.HostResources{
@@ -127,6 +220,17 @@ func (a *kubeletHostSummary) calculateAvailable() models.HostResources {
 			NonSlack: scalar.Resources{},
 		}
 	}
+
+	available := models.HostResources{NonSlack: nonSlack}
+	if a.oversubscription != nil {
+		// The slack resources are exhausted, and revocable pods are
+		// evicted, if more is allocated than the slack pool.
+		pool := a.oversubscription.slackPool(
+			allocatable.NonSlack,
+			a.allocated.NonSlack,
+		)
+		available.Slack, _ = pool.TrySubtract(a.allocated.Slack)
+	}
 	return available
 }
 
@@ -149,16 +253,12 @@ func (a *kubeletHostSummary) postCompleteLease(podToSpecMap map[string]*pbpod.Po
 func (a *kubeletHostSummary) validateEnoughResToLaunch(
 	podToSpecMap map[string]*pbpod.PodSpec,
 ) error {
-	// TODO validate slack too.
 	var slackNeeded, nonSlackNeeded scalar.Resources
 
-	podToResMap := make(map[string]scalar.Resources)
-	for id, spec := range podToSpecMap {
-		podToResMap[id] = scalar.FromPodSpec(spec)
-	}
-
-	for _, res := range podToResMap {
-		nonSlackNeeded = nonSlackNeeded.Add(res)
+	for _, spec := range podToSpecMap {
+		slack, nonSlack := a.oversubscription.podResources(spec)
+		slackNeeded = slackNeeded.Add(slack)
+		nonSlackNeeded = nonSlackNeeded.Add(nonSlack)
 	}
 
 	if !a.available.Contains(models.HostResources{
@@ -174,37 +274,26 @@ func (a *kubeletHostSummary) validateEnoughResToLaunch(
 // calculates total allocated resources.
 // This function assumes baseHostSummary lock is held before calling.
 func (a *kubeletHostSummary) calculateAllocated() {
-	var slackAllocated, nonSlackallocated scalar.Resources
-	var ok bool
+	var slackAllocated, nonSlackAllocated scalar.Resources
 
-	// calculate current allocation based on the new pods map
-	// TODO: populate slackAllocated from this map.
-	for _, r := range a.getPodToResMap() {
-		nonSlackallocated = nonSlackallocated.Add(r)
-	}
-	a.allocated.NonSlack = nonSlackallocated
-	a.available, ok = a.capacity.TrySubtract(models.HostResources{
-		Slack:    slackAllocated,
-		NonSlack: nonSlackallocated,
+	// calculate current allocation based on the new pods map. Revocable
+	// pods use slack resources if the host is oversubscribed.
+	a.pods.RangePods(func(id string, p *podInfo) error {
+		slack, nonSlack := a.oversubscription.podResources(p.spec)
+		slackAllocated = slackAllocated.Add(slack)
+		nonSlackAllocated = nonSlackAllocated.Add(nonSlack)
+		return nil
 	})
-	if !ok {
-		// continue with available set to scalar.Resources{}. This would
-		// organically fail in the following steps.
-		log.WithFields(
-			log.Fields{
-				"allocated":    a.allocated,
-				"podToSpecMap": a.getPodToResMap(),
-				"capacity":     a.capacity,
-			},
-		).Error("kubeletHostSummary: No enough available resources")
-		// no pod can be launched onto the host due to unexpected shortage
-		// of resources. Set available to be 0, wait it to be updated when
-		// more pod/capacity events come.
-		a.available = models.HostResources{
-			Slack:    scalar.Resources{},
-			NonSlack: scalar.Resources{},
-		}
+	a.allocated = models.HostResources{
+		Slack:    slackAllocated,
+		NonSlack: nonSlackAllocated,
 	}
+
+	// If more resources are allocated than the allocatable ones, no pod
+	// can be launched onto the host due to unexpected shortage of
+	// resources, and available is set to 0 until more pod/capacity events
+	// come.
+	a.available = a.calculateAvailable()
 }
 
 // TODO: do this for both slack and non-slack.
@@ -226,17 +315,23 @@ func (a *kubeletHostSummary) CompleteLaunchPod(pod *models.LaunchablePod) {
 	if len(pod.Ports) == 0 {
 		return
 	}
-	ports := make([]int, 0, len(pod.Ports))
-	for _, v := range pod.Ports {
-		ports = append(ports, int(v))
-	}
-	usedRanges := toPortRanges(ports)
+	usedRanges := portMapToRanges(pod.Ports)
 
 	a.mu.Lock()
 	a.ports = subtractPortRanges(a.ports, usedRanges)
 	a.mu.Unlock()
 }
 
+// portMapToRanges arranges the ports of a pod to a list of PortRange,
+// in order.
+func portMapToRanges(portMap map[string]uint32) []*pbhost.PortRange {
+	ports := make([]int, 0, len(portMap))
+	for _, v := range portMap {
+		ports = append(ports, int(v))
+	}
+	return toPortRanges(ports)
+}
+
 // toPortRanges sorts and arranges ports to a list of PortRange, in order.
 func toPortRanges(ports []int) (all []*pbhost.PortRange) {
 	sort.Ints(ports)
@@ -127,6 +127,40 @@ func (suite *HostSummaryTestSuite) TestKubeletHostSummarySetCapacity() {
 	}
 }
 
+// TestKubeletHostSummarySetAllocatable tests that available resources are
+// calculated from the allocatable resources rather than the capacity.
+func (suite *HostSummaryTestSuite) TestKubeletHostSummarySetAllocatable() {
+	ks := NewKubeletHostSummary(_hostname, models.HostResources{}, _version)
+	ks.(*kubeletHostSummary).allocated.NonSlack = scalar.Resources{
+		CPU: 1.0,
+		Mem: 20.0,
+	}
+	ks.SetCapacity(models.HostResources{
+		NonSlack: scalar.Resources{CPU: 4.0, Mem: 100.0},
+	})
+	suite.Equal(
+		scalar.Resources{CPU: 3.0, Mem: 80.0},
+		ks.GetAvailable().NonSlack,
+	)
+
+	ks.SetAllocatable(models.HostResources{
+		NonSlack: scalar.Resources{CPU: 3.0, Mem: 90.0},
+	})
+	suite.Equal(
+		scalar.Resources{CPU: 2.0, Mem: 70.0},
+		ks.GetAvailable().NonSlack,
+	)
+
+	// Capacity changes keep using the allocatable resources.
+	ks.SetCapacity(models.HostResources{
+		NonSlack: scalar.Resources{CPU: 8.0, Mem: 200.0},
+	})
+	suite.Equal(
+		scalar.Resources{CPU: 2.0, Mem: 70.0},
+		ks.GetAvailable().NonSlack,
+	)
+}
+
 // TestKubeletHostSummarySetAvailable tests set
 // available is noop for k8s host
 func (suite *HostSummaryTestSuite) TestKubeletHostSummarySetAvailable() {
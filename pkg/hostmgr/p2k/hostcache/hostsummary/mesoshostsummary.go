@@ -16,7 +16,9 @@ package hostsummary
 
 import (
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -73,6 +75,50 @@ func (a *mesosHostSummary) SetAvailable(r models.HostResources) {
 	}
 }
 
+// HandlePodEvent updates the state of the pods on the host, and releases
+// the ports of the pods which terminated.
+func (a *mesosHostSummary) HandlePodEvent(event *p2kscalar.PodEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	podID := event.Event.GetPodId().GetValue()
+	if info, ok := a.pods.GetPodInfo(podID); ok &&
+		len(info.ports) != 0 &&
+		isTerminalPodEvent(event) {
+		a.ports = mergePortRanges(a.ports, portMapToRanges(info.ports))
+	}
+
+	a.baseHostSummary.handlePodEvent(event)
+}
+
+// CompleteLaunchPod removes the ports assigned to a launched pod from the
+// ports available on the host, and records them so that they are released
+// when the pod terminates.
+func (a *mesosHostSummary) CompleteLaunchPod(pod *models.LaunchablePod) {
+	if len(pod.Ports) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if info, ok := a.pods.GetPodInfo(pod.PodId.GetValue()); ok {
+		info.ports = pod.Ports
+	}
+	a.ports = subtractPortRanges(a.ports, portMapToRanges(pod.Ports))
+}
+
+// isTerminalPodEvent returns whether the pod of the event has terminated,
+// or been deleted.
+func isTerminalPodEvent(event *p2kscalar.PodEvent) bool {
+	if event.EventType == p2kscalar.DeletePod {
+		return true
+	}
+	podState := pbpod.PodState(
+		pbpod.PodState_value[event.Event.GetActualState()])
+	return util.IsPelotonPodStateTerminal(podState)
+}
+
 // postCompleteLease handles actions after lease is completed
 func (a *mesosHostSummary) postCompleteLease(podToSpecMap map[string]*pbpod.PodSpec) error {
 	// noop for mesos
@@ -15,7 +15,11 @@
 package hostsummary
 
 import (
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
 )
 
@@ -130,3 +134,45 @@ func (suite *HostSummaryTestSuite) TestMesosHostSummarySetAvailable() {
 		suite.Equal(ms.GetAllocated().NonSlack, test.expectedAllocated, msg)
 	}
 }
+
+// TestMesosHostSummaryLaunchPodPorts tests that the ports of a launched pod
+// are removed from the available ports, and released when it terminates.
+func (suite *HostSummaryTestSuite) TestMesosHostSummaryLaunchPodPorts() {
+	ms := NewMesosHostSummary(_hostname).(*mesosHostSummary)
+	ms.ports = []*pbhost.PortRange{{Begin: 31000, End: 31010}}
+	podID := &peloton.PodID{Value: _podID}
+	ms.pods.AddPodSpec(_podID, &pbpod.PodSpec{})
+
+	ms.CompleteLaunchPod(&models.LaunchablePod{
+		PodId: podID,
+		Ports: map[string]uint32{"http": 31000, "grpc": 31001},
+	})
+	suite.Equal(
+		[]*pbhost.PortRange{{Begin: 31002, End: 31010}},
+		ms.ports)
+
+	// a running pod keeps its ports
+	ms.HandlePodEvent(&p2kscalar.PodEvent{
+		EventType: p2kscalar.UpdatePod,
+		Event: &pbpod.PodEvent{
+			PodId:       podID,
+			ActualState: pbpod.PodState_POD_STATE_RUNNING.String(),
+		},
+	})
+	suite.Equal(
+		[]*pbhost.PortRange{{Begin: 31002, End: 31010}},
+		ms.ports)
+
+	ms.HandlePodEvent(&p2kscalar.PodEvent{
+		EventType: p2kscalar.UpdatePod,
+		Event: &pbpod.PodEvent{
+			PodId:       podID,
+			ActualState: pbpod.PodState_POD_STATE_KILLED.String(),
+		},
+	})
+	suite.Equal(
+		[]*pbhost.PortRange{{Begin: 31000, End: 31010}},
+		ms.ports)
+	_, ok := ms.pods.GetPodInfo(_podID)
+	suite.False(ok)
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsummary
+
+import (
+	"math"
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	hmutil "github.com/uber/peloton/pkg/hostmgr/util"
+)
+
+// OversubscriptionPolicy decides which resources of a host can be allocated
+// to revocable pods. Revocable pods use the slack resources of a host for
+// the oversubscribed resource types, which are the allocatable resources
+// times the overcommit ratio minus the resources allocated to the
+// non-revocable pods. When the non-revocable pods need the resources back,
+// revocable pods are evicted.
+type OversubscriptionPolicy struct {
+	// Types of resources which are oversubscribed.
+	slackResourceTypes []string
+
+	// Ratio of the allocatable resources which can be allocated to the
+	// revocable and non-revocable pods together.
+	overcommitRatio float64
+}
+
+// NewOversubscriptionPolicy returns a new oversubscription policy. The CPUs
+// are oversubscribed if no resource type is given.
+func NewOversubscriptionPolicy(
+	slackResourceTypes []string,
+	overcommitRatio float64,
+) *OversubscriptionPolicy {
+	if len(slackResourceTypes) == 0 {
+		slackResourceTypes = []string{common.MesosCPU}
+	}
+	if overcommitRatio < 1 {
+		overcommitRatio = 1
+	}
+	return &OversubscriptionPolicy{
+		slackResourceTypes: slackResourceTypes,
+		overcommitRatio:    overcommitRatio,
+	}
+}
+
+// slackFields returns the fields of the resources of the oversubscribed
+// types.
+func (p *OversubscriptionPolicy) slackFields(r *scalar.Resources) []*float64 {
+	var fields []*float64
+	for _, f := range []struct {
+		name  string
+		value *float64
+	}{
+		{common.MesosCPU, &r.CPU},
+		{common.MesosMem, &r.Mem},
+		{common.MesosDisk, &r.Disk},
+		{common.MesosGPU, &r.GPU},
+	} {
+		if hmutil.IsSlackResourceType(f.name, p.slackResourceTypes) {
+			fields = append(fields, f.value)
+		}
+	}
+	return fields
+}
+
+// splitResources splits the resources of a revocable pod into the slack
+// resources of the oversubscribed types, and the non-slack resources of
+// the other types.
+func (p *OversubscriptionPolicy) splitResources(
+	r scalar.Resources,
+) (slack scalar.Resources, nonSlack scalar.Resources) {
+	nonSlack = r
+	slackFields := p.slackFields(&slack)
+	for i, f := range p.slackFields(&nonSlack) {
+		*slackFields[i] = *f
+		*f = 0
+	}
+	return slack, nonSlack
+}
+
+// slackPool returns the resources of the oversubscribed types which can be
+// allocated to revocable pods on a host with the given allocatable
+// resources and resources allocated to non-revocable pods.
+func (p *OversubscriptionPolicy) slackPool(
+	allocatable scalar.Resources,
+	nonSlackAllocated scalar.Resources,
+) scalar.Resources {
+	var pool scalar.Resources
+	poolFields := p.slackFields(&pool)
+	allocatedFields := p.slackFields(&nonSlackAllocated)
+	for i, f := range p.slackFields(&allocatable) {
+		*poolFields[i] = math.Max(
+			*f*p.overcommitRatio-*allocatedFields[i], 0)
+	}
+	return pool
+}
+
+// podResources returns the slack and non-slack resources of a pod, the
+// resources of non-revocable pods are all non-slack.
+func (p *OversubscriptionPolicy) podResources(
+	spec *pbpod.PodSpec,
+) (slack scalar.Resources, nonSlack scalar.Resources) {
+	if p == nil || !spec.GetRevocable() {
+		return scalar.Resources{}, scalar.FromPodSpec(spec)
+	}
+	return p.splitResources(scalar.FromPodSpec(spec))
+}
+
+// selectPodsToEvict returns the revocable pods to evict so that the slack
+// resources allocated to the revocable pods fit in the slack pool. The pods
+// are evicted in the reverse order of their IDs, so that the pods of the
+// same job are evicted from the highest instance down.
+func (p *OversubscriptionPolicy) selectPodsToEvict(
+	pool scalar.Resources,
+	revocablePods map[string]scalar.Resources,
+) []*peloton.PodID {
+	var allocated scalar.Resources
+	ids := make([]string, 0, len(revocablePods))
+	for id, r := range revocablePods {
+		allocated = allocated.Add(r)
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	var evicted []*peloton.PodID
+	for _, id := range ids {
+		if pool.Contains(allocated) {
+			break
+		}
+		allocated = allocated.Subtract(revocablePods[id])
+		evicted = append(evicted, &peloton.PodID{Value: id})
+	}
+	return evicted
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsummary
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/hostmgr/models"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPodSpec returns a pod spec with one container using the given
+// resources.
+func newTestPodSpec(cpu, mem float64, revocable bool) *pbpod.PodSpec {
+	return &pbpod.PodSpec{
+		Containers: []*pbpod.ContainerSpec{{
+			Resource: &pbpod.ResourceSpec{
+				CpuLimit:   cpu,
+				MemLimitMb: mem,
+			},
+		}},
+		Revocable: revocable,
+	}
+}
+
+// TestOversubscriptionPolicy tests the split of the resources of revocable
+// pods and the slack pool of the hosts.
+func TestOversubscriptionPolicy(t *testing.T) {
+	require := require.New(t)
+
+	// CPUs are oversubscribed by default, and the ratio is at least 1.
+	p := NewOversubscriptionPolicy(nil, 0.5)
+	require.Equal([]string{common.MesosCPU}, p.slackResourceTypes)
+	require.Equal(1.0, p.overcommitRatio)
+
+	p = NewOversubscriptionPolicy(
+		[]string{common.MesosCPU, common.MesosMem}, 1.5)
+	slack, nonSlack := p.splitResources(
+		scalar.Resources{CPU: 2, Mem: 20, Disk: 10})
+	require.Equal(scalar.Resources{CPU: 2, Mem: 20}, slack)
+	require.Equal(scalar.Resources{Disk: 10}, nonSlack)
+
+	require.Equal(
+		scalar.Resources{CPU: 9, Mem: 50},
+		p.slackPool(
+			scalar.Resources{CPU: 10, Mem: 100, Disk: 100},
+			scalar.Resources{CPU: 6, Mem: 100, Disk: 50},
+		),
+	)
+	// The pool is empty when the non-revocable pods use more than the
+	// allocatable resources times the ratio.
+	require.Equal(
+		scalar.Resources{},
+		p.slackPool(
+			scalar.Resources{CPU: 10},
+			scalar.Resources{CPU: 20},
+		),
+	)
+
+	// Non-revocable pods only use non-slack resources, and so do all pods
+	// if the host is not oversubscribed.
+	slack, nonSlack = p.podResources(newTestPodSpec(1, 10, false))
+	require.Equal(scalar.Resources{}, slack)
+	require.Equal(scalar.Resources{CPU: 1, Mem: 10}, nonSlack)
+	var nilPolicy *OversubscriptionPolicy
+	slack, nonSlack = nilPolicy.podResources(newTestPodSpec(1, 10, true))
+	require.Equal(scalar.Resources{}, slack)
+	require.Equal(scalar.Resources{CPU: 1, Mem: 10}, nonSlack)
+}
+
+// TestOversubscriptionSelectPodsToEvict tests that the revocable pods are
+// evicted until their slack resources fit in the pool.
+func TestOversubscriptionSelectPodsToEvict(t *testing.T) {
+	require := require.New(t)
+
+	p := NewOversubscriptionPolicy(nil, 2)
+	pods := map[string]scalar.Resources{
+		"pod-1": {CPU: 2},
+		"pod-2": {CPU: 2},
+		"pod-3": {CPU: 2},
+	}
+
+	require.Empty(p.selectPodsToEvict(scalar.Resources{CPU: 6}, pods))
+	require.Equal(
+		[]*peloton.PodID{{Value: "pod-3"}},
+		p.selectPodsToEvict(scalar.Resources{CPU: 5}, pods),
+	)
+	require.Equal(
+		[]*peloton.PodID{{Value: "pod-3"}, {Value: "pod-2"}, {Value: "pod-1"}},
+		p.selectPodsToEvict(scalar.Resources{}, pods),
+	)
+}
+
+// TestKubeletHostSummaryOversubscription tests that revocable pods are
+// placed on the slack resources of a kubelet host, and evicted when
+// non-revocable pods need them.
+func TestKubeletHostSummaryOversubscription(t *testing.T) {
+	require := require.New(t)
+
+	s := NewKubeletHostSummary(_hostname, models.HostResources{
+		NonSlack: scalar.Resources{CPU: 10, Mem: 100},
+	}, _version).(*kubeletHostSummary)
+	s.SetOversubscriptionPolicy(NewOversubscriptionPolicy(nil, 1.5))
+	require.Equal(scalar.Resources{CPU: 15}, s.GetAvailable().Slack)
+
+	s.pods.AddPodSpec("pod-1", newTestPodSpec(6, 10, false))
+	s.pods.AddPodSpec("pod-2", newTestPodSpec(6, 10, true))
+	s.calculateAllocated()
+	require.Equal(scalar.Resources{CPU: 6}, s.GetAllocated().Slack)
+	require.Equal(
+		scalar.Resources{CPU: 6, Mem: 20}, s.GetAllocated().NonSlack)
+	require.Equal(scalar.Resources{CPU: 3}, s.GetAvailable().Slack)
+	require.Equal(
+		scalar.Resources{CPU: 4, Mem: 80}, s.GetAvailable().NonSlack)
+	require.Empty(s.GetPodsToEvict())
+
+	// A revocable pod needing more CPUs than the non-slack ones available
+	// fits in the slack resources.
+	filter := &hostmgr.HostFilter{
+		ResourceConstraint: &hostmgr.ResourceConstraint{
+			Minimum:   &pbpod.ResourceSpec{CpuLimit: 3, MemLimitMb: 10},
+			Revocable: true,
+		},
+	}
+	match := s.TryMatch(filter)
+	require.Equal(hostmgr.HostFilterResult_HOST_FILTER_MATCH, match.Result)
+	lease := s.GetHostLease()
+	require.Equal(3.0, lease.GetHostSummary().GetSlackResources().GetCpu())
+	require.NoError(s.TerminateLease(lease.GetLeaseId().GetValue()))
+
+	filter.ResourceConstraint.Minimum.CpuLimit = 4
+	match = s.TryMatch(filter)
+	require.Equal(
+		hostmgr.HostFilterResult_HOST_FILTER_INSUFFICIENT_RESOURCES,
+		match.Result,
+	)
+
+	// Once more non-revocable pods are placed, the revocable pod is
+	// evicted, and only once.
+	s.pods.AddPodSpec("pod-3", newTestPodSpec(4, 10, false))
+	s.calculateAllocated()
+	require.Equal(scalar.Resources{}, s.GetAvailable().Slack)
+	require.Equal(
+		[]*peloton.PodID{{Value: "pod-2"}},
+		s.GetPodsToEvict(),
+	)
+	require.Empty(s.GetPodsToEvict())
+
+	// The pod evicted is forgotten once it is gone.
+	s.pods.RemovePod("pod-2")
+	s.calculateAllocated()
+	require.Empty(s.GetPodsToEvict())
+	require.Empty(s.evicting)
+}
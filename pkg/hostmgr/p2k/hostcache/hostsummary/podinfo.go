@@ -15,15 +15,32 @@
 package hostsummary
 
 import (
+	"fmt"
 	"sync"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/common/v1alpha/constraints"
+)
+
+// _jobIDLabelKey is the key of the label of the pods holding their job ID,
+// so that instances of the same job can be kept apart.
+var _jobIDLabelKey = fmt.Sprintf(
+	common.SystemLabelKeyTemplate,
+	common.SystemLabelPrefix,
+	common.SystemLabelJobID,
 )
 
 // podInfo contains pod spec and current state.
 type podInfo struct {
 	spec  *pbpod.PodSpec
 	state pbpod.PodState
+
+	// ports assigned to the pod when it was launched, which are released
+	// when the pod terminates.
+	ports map[string]uint32
 }
 
 // newPodInfo creates new podInfo object with given spec, assuming it's in
@@ -40,12 +57,17 @@ type podInfoMap struct {
 	mu sync.RWMutex
 
 	m map[string]*podInfo
+
+	// Number of pods in the map with each label, which pod affinity and
+	// anti-affinity constraints are evaluated against.
+	labelValues constraints.LabelValues
 }
 
 // newPodInfoMap initializes a new podInfoMap.
 func newPodInfoMap() *podInfoMap {
 	return &podInfoMap{
-		m: make(map[string]*podInfo),
+		m:           make(map[string]*podInfo),
+		labelValues: make(constraints.LabelValues),
 	}
 }
 
@@ -69,7 +91,7 @@ func (pm *podInfoMap) AddPodInfo(id string, info *podInfo) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	pm.m[id] = info
+	pm.add(id, info)
 }
 
 // AddPodSpec constructs a new pod info using given pod spec, assuming the pod
@@ -78,10 +100,7 @@ func (pm *podInfoMap) AddPodSpec(id string, spec *pbpod.PodSpec) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	pm.m[id] = &podInfo{
-		spec:  spec,
-		state: pbpod.PodState_POD_STATE_LAUNCHED,
-	}
+	pm.add(id, newPodInfo(spec))
 }
 
 // AddPodSpecs constructs new pod infos using given pod spec map, assuming all
@@ -91,11 +110,39 @@ func (pm *podInfoMap) AddPodSpecs(podToSpecMap map[string]*pbpod.PodSpec) {
 	defer pm.mu.Unlock()
 
 	for id, spec := range podToSpecMap {
-		pm.m[id] = &podInfo{
-			spec:  spec,
-			state: pbpod.PodState_POD_STATE_LAUNCHED,
+		pm.add(id, newPodInfo(spec))
+	}
+}
+
+// SetPodSpec replaces the spec of the pod with given ID, and returns
+// whether the pod exists.
+func (pm *podInfoMap) SetPodSpec(id string, spec *pbpod.PodSpec) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	info, ok := pm.m[id]
+	if !ok {
+		return false
+	}
+	pm.countLabels(id, info.spec, -1)
+	info.spec = spec
+	pm.countLabels(id, info.spec, 1)
+	return true
+}
+
+// GetLabelValues returns the number of pods in the map with each label.
+func (pm *podInfoMap) GetLabelValues() constraints.LabelValues {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	result := make(constraints.LabelValues, len(pm.labelValues))
+	for key, values := range pm.labelValues {
+		result[key] = make(map[string]uint32, len(values))
+		for value, count := range values {
+			result[key][value] = count
 		}
 	}
+	return result
 }
 
 // RemovePod removes pod info with given id from map.
@@ -103,7 +150,61 @@ func (pm *podInfoMap) RemovePod(id string) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
-	delete(pm.m, id)
+	if info, ok := pm.m[id]; ok {
+		pm.countLabels(id, info.spec, -1)
+		delete(pm.m, id)
+	}
+}
+
+// add adds given pod ID and pod info into the map, replacing the pod info
+// of the pod if any.
+// This function assumes the map lock is held before calling.
+func (pm *podInfoMap) add(id string, info *podInfo) {
+	if old, ok := pm.m[id]; ok {
+		pm.countLabels(id, old.spec, -1)
+	}
+	pm.m[id] = info
+	pm.countLabels(id, info.spec, 1)
+}
+
+// countLabels adds delta to the number of pods with each label of the pod
+// with given ID and spec.
+// This function assumes the map lock is held before calling.
+func (pm *podInfoMap) countLabels(
+	id string,
+	spec *pbpod.PodSpec,
+	delta int,
+) {
+	for _, label := range podLabels(id, spec) {
+		values, ok := pm.labelValues[label.GetKey()]
+		if !ok {
+			values = make(map[string]uint32)
+			pm.labelValues[label.GetKey()] = values
+		}
+		count := int(values[label.GetValue()]) + delta
+		if count > 0 {
+			values[label.GetValue()] = uint32(count)
+			continue
+		}
+		delete(values, label.GetValue())
+		if len(values) == 0 {
+			delete(pm.labelValues, label.GetKey())
+		}
+	}
+}
+
+// podLabels returns the labels of the pod with given ID and spec, which
+// pod constraints are evaluated against: the labels of its spec, and the
+// ID of its job.
+func podLabels(id string, spec *pbpod.PodSpec) []*peloton.Label {
+	labels := spec.GetLabels()
+	if jobID, _, err := util.ParseJobAndInstanceID(id); err == nil {
+		labels = append(
+			append([]*peloton.Label{}, labels...),
+			&peloton.Label{Key: _jobIDLabelKey, Value: jobID},
+		)
+	}
+	return labels
 }
 
 // AnyPodExist returns true if any given pod exists.
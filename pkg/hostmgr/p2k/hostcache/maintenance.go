@@ -0,0 +1,150 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostcache
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// StartMaintenance puts a host into maintenance. The host is DRAINING
+// until the pods running on it are relocated, then DRAINED, and DOWN once
+// it stayed empty.
+func (c *hostCache) StartMaintenance(hostname string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hs, err := c.getSummary(hostname)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.maintenance[hostname]; ok {
+		// The host is already in maintenance.
+		return nil
+	}
+
+	c.setMaintenanceStatus(hostname, hs, scalar.HostDraining)
+	log.WithField("hostname", hostname).Info("start host maintenance")
+	return nil
+}
+
+// CompleteMaintenance brings a DOWN host out of maintenance.
+func (c *hostCache) CompleteMaintenance(hostname string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maintenance[hostname] != scalar.HostDown {
+		return yarpcerrors.NotFoundErrorf("host %s is not DOWN", hostname)
+	}
+
+	delete(c.maintenance, hostname)
+	if hs, ok := c.hostIndex[hostname]; ok {
+		hs.SetMaintenanceStatus(scalar.HostUp)
+		c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, hs)
+	}
+	log.WithField("hostname", hostname).Info("complete host maintenance")
+	return nil
+}
+
+// GetPodsOnDrainingHosts returns the pods running on the DRAINING hosts,
+// which need to be relocated. At most limit pods are returned if limit is
+// positive.
+func (c *hostCache) GetPodsOnDrainingHosts(limit uint32) []*peloton.PodID {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var podIDs []*peloton.PodID
+	for hostname, status := range c.maintenance {
+		hs, ok := c.hostIndex[hostname]
+		if !ok || status != scalar.HostDraining {
+			continue
+		}
+		for _, id := range hs.GetPodIDs() {
+			if limit > 0 && uint32(len(podIDs)) == limit {
+				return podIDs
+			}
+			podIDs = append(podIDs, &peloton.PodID{Value: id})
+		}
+	}
+	return podIDs
+}
+
+// reconcileMaintenance moves the hosts in maintenance forward. DRAINING
+// hosts without pods are DRAINED. DRAINED hosts which stayed empty and are
+// not leased for placement are DOWN, as pods can still be launched on a
+// host leased before it started draining.
+func (c *hostCache) reconcileMaintenance() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[scalar.MaintenanceStatus]int)
+	for hostname, status := range c.maintenance {
+		hs, ok := c.hostIndex[hostname]
+		if ok {
+			empty := len(hs.GetPodIDs()) == 0
+			switch {
+			case status == scalar.HostDraining && empty:
+				status = scalar.HostDrained
+			case status == scalar.HostDrained && !empty:
+				status = scalar.HostDraining
+			case status == scalar.HostDrained &&
+				hs.GetHostStatus() != hostsummary.PlacingHost:
+				status = scalar.HostDown
+			}
+			if status != c.maintenance[hostname] {
+				c.setMaintenanceStatus(hostname, hs, status)
+				log.WithFields(log.Fields{
+					"hostname": hostname,
+					"status":   status,
+				}).Info("host maintenance status changed")
+			}
+		}
+		counts[status]++
+	}
+
+	c.metrics.DrainingHosts.Update(float64(counts[scalar.HostDraining]))
+	c.metrics.DrainedHosts.Update(float64(counts[scalar.HostDrained]))
+	c.metrics.DownHosts.Update(float64(counts[scalar.HostDown]))
+}
+
+// setMaintenanceStatus sets the maintenance status of a host.
+// This function assumes hostCache lock is held before calling.
+func (c *hostCache) setMaintenanceStatus(
+	hostname string,
+	hs hostsummary.HostSummary,
+	status scalar.MaintenanceStatus,
+) {
+	c.maintenance[hostname] = status
+	hs.SetMaintenanceStatus(status)
+	c.publishHostDelta(hostmgr.HostDelta_TYPE_HOST_UPDATED, hostname, hs)
+}
+
+// applyMaintenanceStatus sets the maintenance status of a host on its
+// summary if the host is in maintenance, so that it is kept when the
+// summary is replaced.
+// This function assumes hostCache lock is held before calling.
+func (c *hostCache) applyMaintenanceStatus(
+	hostname string,
+	hs hostsummary.HostSummary,
+) {
+	if status, ok := c.maintenance[hostname]; ok {
+		hs.SetMaintenanceStatus(status)
+	}
+}
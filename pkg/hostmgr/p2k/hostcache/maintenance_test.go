@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostcache
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// TestHostMaintenance tests that a host in maintenance is drained, then
+// down once its pods are gone, and up again when maintenance completes.
+func TestHostMaintenance(t *testing.T) {
+	require := require.New(t)
+
+	hosts := hostsummary.GenerateFakeHostSummaries(2)
+	hc := &hostCache{
+		hostIndex:   make(map[string]hostsummary.HostSummary),
+		maintenance: make(map[string]scalar.MaintenanceStatus),
+		metrics:     NewMetrics(tally.NoopScope),
+	}
+	for _, hs := range hosts {
+		hc.hostIndex[hs.GetHostname()] = hs
+	}
+	hostname := hosts[0].GetHostname()
+	podID := &peloton.PodID{Value: "pod-1"}
+	hosts[0].RecoverPodInfo(podID, pbpod.PodState_POD_STATE_RUNNING, nil)
+
+	require.True(yarpcerrors.IsNotFound(hc.StartMaintenance("unknown")))
+	require.NoError(hc.StartMaintenance(hostname))
+	require.NoError(hc.StartMaintenance(hostname))
+	require.Equal(scalar.HostDraining, hosts[0].GetMaintenanceStatus())
+	require.True(yarpcerrors.IsNotFound(hc.CompleteMaintenance(hostname)))
+
+	// No pod is placed on the host anymore, and its pods are relocated.
+	match := hosts[0].TryMatch(&hostmgr.HostFilter{})
+	require.Equal(
+		hostmgr.HostFilterResult_HOST_FILTER_MISMATCH_STATUS,
+		match.Result,
+	)
+	require.Equal([]*peloton.PodID{podID}, hc.GetPodsOnDrainingHosts(0))
+	require.Equal([]*peloton.PodID{podID}, hc.GetPodsOnDrainingHosts(1))
+
+	hc.reconcileMaintenance()
+	require.Equal(scalar.HostDraining, hosts[0].GetMaintenanceStatus())
+
+	// The host is drained once its pods are gone, and down if it stays
+	// empty.
+	hosts[0].RecoverPodInfo(podID, pbpod.PodState_POD_STATE_KILLED, nil)
+	hc.reconcileMaintenance()
+	require.Equal(scalar.HostDrained, hosts[0].GetMaintenanceStatus())
+	require.Empty(hc.GetPodsOnDrainingHosts(0))
+
+	hc.reconcileMaintenance()
+	require.Equal(scalar.HostDown, hosts[0].GetMaintenanceStatus())
+	require.Equal(scalar.HostUp, hosts[1].GetMaintenanceStatus())
+
+	// The host stays down when its summary is replaced.
+	delete(hc.hostIndex, hostname)
+	hc.RecoverPodInfoOnHost(
+		podID,
+		hostname,
+		pbpod.PodState_POD_STATE_LAUNCHED,
+		&pbpod.PodSpec{},
+	)
+	hs := hc.hostIndex[hostname]
+	require.Equal(scalar.HostDown, hs.GetMaintenanceStatus())
+
+	require.NoError(hc.CompleteMaintenance(hostname))
+	require.Equal(scalar.HostUp, hs.GetMaintenanceStatus())
+	require.Empty(hc.maintenance)
+}
+
+// TestHostMaintenanceDrainedHostReused tests that a drained host which
+// gets pods again, e.g. launched from a lease acquired before the host
+// started draining, is draining again.
+func TestHostMaintenanceDrainedHostReused(t *testing.T) {
+	require := require.New(t)
+
+	hosts := hostsummary.GenerateFakeHostSummaries(1)
+	hostname := hosts[0].GetHostname()
+	hc := &hostCache{
+		hostIndex:   map[string]hostsummary.HostSummary{hostname: hosts[0]},
+		maintenance: make(map[string]scalar.MaintenanceStatus),
+		metrics:     NewMetrics(tally.NoopScope),
+	}
+
+	require.NoError(hc.StartMaintenance(hostname))
+	hc.reconcileMaintenance()
+	require.Equal(scalar.HostDrained, hosts[0].GetMaintenanceStatus())
+
+	hosts[0].RecoverPodInfo(
+		&peloton.PodID{Value: "pod-1"},
+		pbpod.PodState_POD_STATE_LAUNCHED,
+		nil,
+	)
+	hc.reconcileMaintenance()
+	require.Equal(scalar.HostDraining, hosts[0].GetMaintenanceStatus())
+}
@@ -31,6 +31,19 @@ type Metrics struct {
 	PlacingHosts   tally.Gauge
 	HeldHosts      tally.Gauge
 	AvailableHosts tally.Gauge
+
+	// Metrics for number of hosts on each maintenance status.
+	DrainingHosts tally.Gauge
+	DrainedHosts  tally.Gauge
+	DownHosts     tally.Gauge
+
+	// Metrics for revocable pods evicted from oversubscribed hosts.
+	EvictPod     tally.Counter
+	EvictPodFail tally.Counter
+
+	// Metrics for the watches on the hosts.
+	HostWatches       tally.Gauge
+	HostWatchOverflow tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized
@@ -41,13 +54,23 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	// resources in ready & placing host status
 	resourceScope := hostCacheScope.SubScope("resource")
 	hostsScope := hostCacheScope.SubScope("hosts")
+	successScope := hostCacheScope.Tagged(map[string]string{"result": "success"})
+	failScope := hostCacheScope.Tagged(map[string]string{"result": "fail"})
+	watchScope := hostCacheScope.SubScope("watch")
 
 	return &Metrics{
-		Available:      scalar.NewGaugeMaps(resourceScope),
-		Allocated:      scalar.NewGaugeMaps(resourceScope),
-		ReadyHosts:     hostsScope.Gauge("ready"),
-		PlacingHosts:   hostsScope.Gauge("placing"),
-		HeldHosts:      hostsScope.Gauge("held"),
-		AvailableHosts: hostsScope.Gauge("available"),
+		Available:         scalar.NewGaugeMaps(resourceScope),
+		Allocated:         scalar.NewGaugeMaps(resourceScope),
+		ReadyHosts:        hostsScope.Gauge("ready"),
+		PlacingHosts:      hostsScope.Gauge("placing"),
+		HeldHosts:         hostsScope.Gauge("held"),
+		AvailableHosts:    hostsScope.Gauge("available"),
+		DrainingHosts:     hostsScope.Gauge("draining"),
+		DrainedHosts:      hostsScope.Gauge("drained"),
+		DownHosts:         hostsScope.Gauge("down"),
+		EvictPod:          successScope.Counter("evict_pod"),
+		EvictPodFail:      failScope.Counter("evict_pod"),
+		HostWatches:       watchScope.Gauge("watches"),
+		HostWatchOverflow: watchScope.Counter("overflow"),
 	}
 }
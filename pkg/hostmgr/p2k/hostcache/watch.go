@@ -0,0 +1,162 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostcache
+
+import (
+	pbhost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	"github.com/uber/peloton/pkg/hostmgr/watchevent"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _maxHostWatches is the maximum number of concurrent watches on the
+	// hosts.
+	_maxHostWatches = 100
+
+	// _hostWatchBufferSize is the number of changes buffered for each
+	// watch before it is stopped for falling behind.
+	_hostWatchBufferSize = 10000
+
+	// _hostsTopic is the topic of the watches on the hosts, used in their
+	// IDs.
+	_hostsTopic watchevent.Topic = "hosts"
+)
+
+// HostWatch receives the changes made to the hosts in the host cache after
+// its snapshot.
+type HostWatch struct {
+	// ID of the watch.
+	ID string
+
+	// Snapshot of all the hosts when the watch started.
+	Snapshot []*pbhost.HostSummary
+
+	// Sequence number of the last change included in the snapshot.
+	SnapshotSequence uint64
+
+	// Deltas receives the changes made to the hosts after the snapshot, in
+	// sequence order.
+	Deltas chan *hostmgr.HostDelta
+
+	// Signal receives the reason the watch is stopped.
+	Signal chan watchevent.StopSignal
+}
+
+// WatchHosts starts a watch on the changes made to the hosts.
+func (c *hostCache) WatchHosts() (*HostWatch, error) {
+	// Block the changes to the hosts, so that none is missed or sent twice
+	// between the snapshot and the watch.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	if len(c.watches) >= _maxHostWatches {
+		return nil, yarpcerrors.ResourceExhaustedErrorf("max watches reached")
+	}
+
+	w := &HostWatch{
+		ID:               watchevent.NewWatchID(_hostsTopic),
+		SnapshotSequence: c.sequence,
+		Deltas:           make(chan *hostmgr.HostDelta, _hostWatchBufferSize),
+		// Make buffer size 1 so that the sender is not blocked when
+		// sending the signal.
+		Signal: make(chan watchevent.StopSignal, 1),
+	}
+	for _, hs := range c.hostIndex {
+		w.Snapshot = append(w.Snapshot, hs.GetHostLease().GetHostSummary())
+	}
+	c.watches[w.ID] = w
+	c.metrics.HostWatches.Update(float64(len(c.watches)))
+
+	log.WithFields(log.Fields{
+		"watch_id": w.ID,
+		"sequence": w.SnapshotSequence,
+		"hosts":    len(w.Snapshot),
+	}).Info("host watch created")
+	return w, nil
+}
+
+// StopWatch stops a watch on the hosts. Returns "not-found" error if the
+// watch is not found.
+func (c *hostCache) StopWatch(watchID string) error {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	return c.stopWatch(watchID, watchevent.StopSignalCancel)
+}
+
+// stopWatch stops a watch on the hosts with the given signal.
+// This function assumes watch lock is held before calling.
+func (c *hostCache) stopWatch(
+	watchID string,
+	signal watchevent.StopSignal,
+) error {
+	w, ok := c.watches[watchID]
+	if !ok {
+		return yarpcerrors.NotFoundErrorf(
+			"watch_id %s not exist for host watch", watchID)
+	}
+
+	log.WithFields(log.Fields{
+		"watch_id": watchID,
+		"signal":   signal,
+	}).Info("stopping host watch")
+
+	w.Signal <- signal
+	delete(c.watches, watchID)
+	c.metrics.HostWatches.Update(float64(len(c.watches)))
+	return nil
+}
+
+// publishHostDelta assigns the next sequence number to a change made to a
+// host, and sends it to the watches. The summary of the host is nil if it
+// is removed. Watches falling behind are stopped.
+// This function assumes hostCache lock is held before calling.
+func (c *hostCache) publishHostDelta(
+	deltaType hostmgr.HostDelta_Type,
+	hostname string,
+	hs hostsummary.HostSummary,
+) {
+	summary := &pbhost.HostSummary{Hostname: hostname}
+	if hs != nil {
+		summary = hs.GetHostLease().GetHostSummary()
+	}
+
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+
+	c.sequence++
+	delta := &hostmgr.HostDelta{
+		Type:        deltaType,
+		Sequence:    c.sequence,
+		HostSummary: summary,
+	}
+	for watchID, w := range c.watches {
+		select {
+		case w.Deltas <- delta:
+		default:
+			log.WithField("watch_id", watchID).
+				Warn("host delta overflow for host watch")
+			c.metrics.HostWatchOverflow.Inc(1)
+			c.stopWatch(watchID, watchevent.StopSignalOverflow)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostcache
+
+import (
+	"testing"
+
+	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
+	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/watchevent"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newWatchTestHostCache returns a host cache with the given hosts.
+func newWatchTestHostCache(hosts []hostsummary.HostSummary) *hostCache {
+	hc := &hostCache{
+		hostIndex: make(map[string]hostsummary.HostSummary),
+		watches:   make(map[string]*HostWatch),
+		metrics:   NewMetrics(tally.NoopScope),
+	}
+	for _, hs := range hosts {
+		hc.hostIndex[hs.GetHostname()] = hs
+	}
+	return hc
+}
+
+// TestWatchHosts tests that a watch on the hosts starts from the snapshot
+// of the hosts, and receives the changes made after it in order.
+func TestWatchHosts(t *testing.T) {
+	require := require.New(t)
+
+	hosts := hostsummary.GenerateFakeHostSummaries(2)
+	hc := newWatchTestHostCache(hosts)
+	hc.publishHostDelta(
+		hostmgr.HostDelta_TYPE_HOST_UPDATED,
+		hosts[0].GetHostname(),
+		hosts[0],
+	)
+
+	w, err := hc.WatchHosts()
+	require.NoError(err)
+	require.Len(w.Snapshot, 2)
+	require.Equal(uint64(1), w.SnapshotSequence)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "node",
+			ResourceVersion: "1",
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	event, err := p2kscalar.BuildHostEventFromNode(node, p2kscalar.AddHost)
+	require.NoError(err)
+	hc.addHost(event)
+
+	event, err = p2kscalar.BuildHostEventFromNode(node, p2kscalar.DeleteHost)
+	require.NoError(err)
+	hc.deleteHost(event)
+	// Deleting a host not in the cache does not change anything.
+	hc.deleteHost(event)
+
+	delta := <-w.Deltas
+	require.Equal(hostmgr.HostDelta_TYPE_HOST_ADDED, delta.GetType())
+	require.Equal(uint64(2), delta.GetSequence())
+	require.Equal("node", delta.GetHostSummary().GetHostname())
+	require.Equal(float64(8), delta.GetHostSummary().GetResources().GetCpu())
+
+	delta = <-w.Deltas
+	require.Equal(hostmgr.HostDelta_TYPE_HOST_REMOVED, delta.GetType())
+	require.Equal(uint64(3), delta.GetSequence())
+	require.Equal("node", delta.GetHostSummary().GetHostname())
+	require.Empty(w.Deltas)
+
+	require.NoError(hc.StopWatch(w.ID))
+	require.Equal(watchevent.StopSignalCancel, <-w.Signal)
+	require.True(yarpcerrors.IsNotFound(hc.StopWatch(w.ID)))
+}
+
+// TestWatchHostsOverflow tests that the watches falling behind are
+// stopped.
+func TestWatchHostsOverflow(t *testing.T) {
+	require := require.New(t)
+
+	hosts := hostsummary.GenerateFakeHostSummaries(1)
+	hc := newWatchTestHostCache(hosts)
+
+	w, err := hc.WatchHosts()
+	require.NoError(err)
+	for i := 0; i <= _hostWatchBufferSize; i++ {
+		hc.publishHostDelta(
+			hostmgr.HostDelta_TYPE_HOST_UPDATED,
+			hosts[0].GetHostname(),
+			hosts[0],
+		)
+	}
+	require.Equal(watchevent.StopSignalOverflow, <-w.Signal)
+	require.Empty(hc.watches)
+}
+
+// TestWatchHostsMaxWatches tests that the number of concurrent watches is
+// limited.
+func TestWatchHostsMaxWatches(t *testing.T) {
+	require := require.New(t)
+
+	hc := newWatchTestHostCache(nil)
+	for i := 0; i < _maxHostWatches; i++ {
+		_, err := hc.WatchHosts()
+		require.NoError(err)
+	}
+	_, err := hc.WatchHosts()
+	require.True(yarpcerrors.IsResourceExhausted(err))
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/podeventmanager"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/watchevent"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -38,6 +39,10 @@ import (
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
+// _maxDeltasPerWatchResponse is the maximum number of host deltas sent in
+// one message of a WatchHosts stream.
+const _maxDeltasPerWatchResponse = 100
+
 // ServiceHandler implements private.hostmgr.v1alpha.svc.HostManagerService.
 type ServiceHandler struct {
 	// Scheduler plugin.
@@ -303,6 +308,66 @@ func (h *ServiceHandler) KillAndHoldPods(
 	return &svc.KillAndHoldPodsResponse{}, nil
 }
 
+// UpdatePodResources implements HostManagerService.UpdatePodResources.
+func (h *ServiceHandler) UpdatePodResources(
+	ctx context.Context,
+	req *svc.UpdatePodResourcesRequest,
+) (resp *svc.UpdatePodResourcesResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"pod_id":   req.GetPodId().GetValue(),
+				"hostname": req.GetHostname(),
+			}).WithError(err).
+				Warn("HostMgr.UpdatePodResources failed")
+		}
+	}()
+
+	if !h.plugin.SupportsPodResize() {
+		return nil, yarpcerrors.UnimplementedErrorf(
+			"cluster does not support resizing pod %s in place",
+			req.GetPodId().GetValue())
+	}
+
+	// Reserve the new resources on the host first, so that the pod is
+	// only resized if the host has enough headroom.
+	oldSpec, err := h.hostCache.UpdatePodResources(
+		req.GetHostname(),
+		req.GetPodId(),
+		req.GetSpec(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.plugin.UpdatePodResources(
+		ctx,
+		req.GetPodId().GetValue(),
+		req.GetSpec(),
+	); err != nil {
+		// Give back the reserved headroom as the pod keeps its old resources.
+		if _, rerr := h.hostCache.UpdatePodResources(
+			req.GetHostname(),
+			req.GetPodId(),
+			oldSpec,
+		); rerr != nil {
+			log.WithFields(log.Fields{
+				"pod_id":   req.GetPodId().GetValue(),
+				"hostname": req.GetHostname(),
+			}).WithError(rerr).
+				Warn("Failed to revert pod resources in host cache")
+		}
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"pod_id":   req.GetPodId().GetValue(),
+		"hostname": req.GetHostname(),
+	}).Debug("UpdatePodResources success")
+
+	return &svc.UpdatePodResourcesResponse{}, nil
+}
+
 // ClusterCapacity implements HostManagerService.ClusterCapacity.
 func (h *ServiceHandler) ClusterCapacity(
 	ctx context.Context,
@@ -357,8 +422,9 @@ func (h *ServiceHandler) GetHostCache(
 	for _, summary := range h.hostCache.GetSummaries() {
 		allocation, capacity := summary.GetAllocated(), summary.GetCapacity()
 		resp.Summaries = append(resp.Summaries, &svc.GetHostCacheResponse_Summary{
-			Hostname: summary.GetHostname(),
-			Status:   fmt.Sprintf("%v", summary.GetHostStatus()),
+			Hostname:    summary.GetHostname(),
+			Status:      fmt.Sprintf("%v", summary.GetHostStatus()),
+			Maintenance: summary.GetMaintenanceStatus().String(),
 			Allocation: []*v1alpha.Resource{
 				{Kind: "cpu", Capacity: allocation.NonSlack.CPU},
 				{Kind: "mem", Capacity: allocation.NonSlack.Mem},
@@ -376,6 +442,106 @@ func (h *ServiceHandler) GetHostCache(
 	return resp, nil
 }
 
+// WatchHosts implements HostManagerService.WatchHosts.
+func (h *ServiceHandler) WatchHosts(
+	req *svc.WatchHostsRequest,
+	stream svc.HostManagerServiceServiceWatchHostsYARPCServer,
+) error {
+	w, err := h.hostCache.WatchHosts()
+	if err != nil {
+		log.WithError(err).Warn("HostMgr.WatchHosts failed")
+		return err
+	}
+	defer h.hostCache.StopWatch(w.ID)
+
+	if err := stream.Send(&svc.WatchHostsResponse{
+		WatchId:          w.ID,
+		Snapshot:         w.Snapshot,
+		SnapshotSequence: w.SnapshotSequence,
+	}); err != nil {
+		log.WithField("watch_id", w.ID).
+			WithError(err).
+			Warn("failed to send host snapshot for host watch")
+		return err
+	}
+
+	for {
+		select {
+		case delta := <-w.Deltas:
+			resp := &svc.WatchHostsResponse{
+				WatchId: w.ID,
+				Deltas:  []*hostmgr.HostDelta{delta},
+			}
+			// Batch the deltas already pending.
+		drain:
+			for len(resp.Deltas) < _maxDeltasPerWatchResponse {
+				select {
+				case delta := <-w.Deltas:
+					resp.Deltas = append(resp.Deltas, delta)
+				default:
+					break drain
+				}
+			}
+
+			if err := stream.Send(resp); err != nil {
+				log.WithField("watch_id", w.ID).
+					WithError(err).
+					Warn("failed to send host deltas for host watch")
+				return err
+			}
+		case s := <-w.Signal:
+			switch s {
+			case watchevent.StopSignalCancel:
+				return yarpcerrors.CancelledErrorf("watch cancelled: %s", w.ID)
+			case watchevent.StopSignalOverflow:
+				return yarpcerrors.InternalErrorf("event overflow: %s", w.ID)
+			default:
+				return yarpcerrors.InternalErrorf("unexpected signal: %s", s)
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// StartMaintenance implements HostManagerService.StartMaintenance.
+func (h *ServiceHandler) StartMaintenance(
+	ctx context.Context,
+	req *svc.StartMaintenanceRequest,
+) (*svc.StartMaintenanceResponse, error) {
+	if err := h.hostCache.StartMaintenance(req.GetHostname()); err != nil {
+		log.WithField("hostname", req.GetHostname()).
+			WithError(err).
+			Warn("HostMgr.StartMaintenance failed")
+		return nil, err
+	}
+	return &svc.StartMaintenanceResponse{}, nil
+}
+
+// CompleteMaintenance implements HostManagerService.CompleteMaintenance.
+func (h *ServiceHandler) CompleteMaintenance(
+	ctx context.Context,
+	req *svc.CompleteMaintenanceRequest,
+) (*svc.CompleteMaintenanceResponse, error) {
+	if err := h.hostCache.CompleteMaintenance(req.GetHostname()); err != nil {
+		log.WithField("hostname", req.GetHostname()).
+			WithError(err).
+			Warn("HostMgr.CompleteMaintenance failed")
+		return nil, err
+	}
+	return &svc.CompleteMaintenanceResponse{}, nil
+}
+
+// GetPodsOnDrainingHosts implements HostManagerService.GetPodsOnDrainingHosts.
+func (h *ServiceHandler) GetPodsOnDrainingHosts(
+	ctx context.Context,
+	req *svc.GetPodsOnDrainingHostsRequest,
+) (*svc.GetPodsOnDrainingHostsResponse, error) {
+	return &svc.GetPodsOnDrainingHostsResponse{
+		PodIds: h.hostCache.GetPodsOnDrainingHosts(req.GetLimit()),
+	}, nil
+}
+
 // validateLaunchPodsRequest does some sanity checks on launch pods request.
 func validateLaunchPodsRequest(req *svc.LaunchPodsRequest) error {
 	if len(req.Pods) <= 0 {
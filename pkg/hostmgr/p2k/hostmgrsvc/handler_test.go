@@ -24,12 +24,16 @@ import (
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
+	svc_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc/mocks"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary"
 	hostsummary_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/hostsummary/mocks"
 	hostcache_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/hostcache/mocks"
 	plugins_mocks "github.com/uber/peloton/pkg/hostmgr/p2k/plugins/mocks"
+	p2kscalar "github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/hostmgr/watchevent"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -37,6 +41,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 	"golang.org/x/net/context"
 )
 
@@ -131,6 +136,7 @@ func (suite *HostMgrHandlerTestSuite) TestGetHostCache() {
 	summary := hostsummary_mocks.NewMockHostSummary(suite.ctrl)
 	summary.EXPECT().GetHostname().Return("h1")
 	summary.EXPECT().GetHostStatus().Return(hostsummary.ReadyHost)
+	summary.EXPECT().GetMaintenanceStatus().Return(p2kscalar.HostDraining)
 	summary.EXPECT().GetAllocated().Return(models.HostResources{
 		NonSlack: allocated,
 	})
@@ -147,8 +153,9 @@ func (suite *HostMgrHandlerTestSuite) TestGetHostCache() {
 	suite.NotNil(resp)
 	suite.Len(resp.Summaries, 1)
 	suite.Equal(&svc.GetHostCacheResponse_Summary{
-		Hostname: "h1",
-		Status:   "1",
+		Hostname:    "h1",
+		Status:      "1",
+		Maintenance: "draining",
 		Allocation: []*hostmgr.Resource{
 			{Kind: "cpu", Capacity: 1},
 			{Kind: "mem", Capacity: 10},
@@ -422,7 +429,221 @@ func (suite *HostMgrHandlerTestSuite) TestKillAndHoldPods() {
 	suite.Error(err)
 }
 
+// TestUpdatePodResources tests resizing a pod in place.
+func (suite *HostMgrHandlerTestSuite) TestUpdatePodResources() {
+	defer suite.ctrl.Finish()
+
+	podID := &peloton.PodID{Value: uuid.New()}
+	oldSpec := &pbpod.PodSpec{PodName: &peloton.PodName{Value: "old"}}
+	req := &svc.UpdatePodResourcesRequest{
+		PodId:    podID,
+		Hostname: "host1",
+		Spec:     &pbpod.PodSpec{},
+	}
+
+	suite.plugin.EXPECT().SupportsPodResize().Return(true)
+	suite.hostCache.EXPECT().
+		UpdatePodResources("host1", podID, req.GetSpec()).
+		Return(oldSpec, nil)
+	suite.plugin.EXPECT().
+		UpdatePodResources(gomock.Any(), podID.GetValue(), req.GetSpec()).
+		Return(nil)
+
+	_, err := suite.handler.UpdatePodResources(rootCtx, req)
+	suite.NoError(err)
+}
+
+// TestUpdatePodResourcesNoHeadroom tests that the pod is not resized when
+// the host does not have enough headroom.
+func (suite *HostMgrHandlerTestSuite) TestUpdatePodResourcesNoHeadroom() {
+	defer suite.ctrl.Finish()
+
+	req := &svc.UpdatePodResourcesRequest{
+		PodId:    &peloton.PodID{Value: uuid.New()},
+		Hostname: "host1",
+		Spec:     &pbpod.PodSpec{},
+	}
+
+	suite.plugin.EXPECT().SupportsPodResize().Return(true)
+	suite.hostCache.EXPECT().
+		UpdatePodResources("host1", req.GetPodId(), req.GetSpec()).
+		Return(nil, yarpcerrors.ResourceExhaustedErrorf("no headroom"))
+
+	_, err := suite.handler.UpdatePodResources(rootCtx, req)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
+// TestUpdatePodResourcesPluginFailure tests that the host cache is
+// reverted when the plugin fails to resize the pod.
+func (suite *HostMgrHandlerTestSuite) TestUpdatePodResourcesPluginFailure() {
+	defer suite.ctrl.Finish()
+
+	podID := &peloton.PodID{Value: uuid.New()}
+	oldSpec := &pbpod.PodSpec{PodName: &peloton.PodName{Value: "old"}}
+	req := &svc.UpdatePodResourcesRequest{
+		PodId:    podID,
+		Hostname: "host1",
+		Spec:     &pbpod.PodSpec{},
+	}
+
+	gomock.InOrder(
+		suite.plugin.EXPECT().SupportsPodResize().Return(true),
+		suite.hostCache.EXPECT().
+			UpdatePodResources("host1", podID, req.GetSpec()).
+			Return(oldSpec, nil),
+		suite.plugin.EXPECT().
+			UpdatePodResources(gomock.Any(), podID.GetValue(), req.GetSpec()).
+			Return(yarpcerrors.UnimplementedErrorf("not supported")),
+		suite.hostCache.EXPECT().
+			UpdatePodResources("host1", podID, oldSpec).
+			Return(req.GetSpec(), nil),
+	)
+
+	_, err := suite.handler.UpdatePodResources(rootCtx, req)
+	suite.True(yarpcerrors.IsUnimplemented(err))
+}
+
+// TestUpdatePodResourcesNotSupported tests that pods are not resized when
+// the plugin does not support it.
+func (suite *HostMgrHandlerTestSuite) TestUpdatePodResourcesNotSupported() {
+	defer suite.ctrl.Finish()
+
+	req := &svc.UpdatePodResourcesRequest{
+		PodId:    &peloton.PodID{Value: uuid.New()},
+		Hostname: "host1",
+		Spec:     &pbpod.PodSpec{},
+	}
+
+	suite.plugin.EXPECT().SupportsPodResize().Return(false)
+
+	_, err := suite.handler.UpdatePodResources(rootCtx, req)
+	suite.True(yarpcerrors.IsUnimplemented(err))
+}
+
 // TestHostManagerTestSuite runs the HostMgrHandlerTestSuite
 func TestHostManagerTestSuite(t *testing.T) {
 	suite.Run(t, new(HostMgrHandlerTestSuite))
 }
+
+// TestWatchHosts tests that WatchHosts streams the snapshot of the hosts,
+// then the host deltas in batches, until the watch is stopped.
+func (suite *HostMgrHandlerTestSuite) TestWatchHosts() {
+	defer suite.ctrl.Finish()
+
+	stream := svc_mocks.NewMockHostManagerServiceServiceWatchHostsYARPCServer(
+		suite.ctrl)
+	w := &hostcache.HostWatch{
+		ID:               "watch-1",
+		Snapshot:         []*pbhost.HostSummary{{Hostname: "host-1"}},
+		SnapshotSequence: 10,
+		Deltas:           make(chan *hostmgr.HostDelta, 2),
+		Signal:           make(chan watchevent.StopSignal, 1),
+	}
+	deltas := []*hostmgr.HostDelta{
+		{
+			Type:        hostmgr.HostDelta_TYPE_HOST_UPDATED,
+			Sequence:    11,
+			HostSummary: &pbhost.HostSummary{Hostname: "host-1"},
+		},
+		{
+			Type:        hostmgr.HostDelta_TYPE_HOST_ADDED,
+			Sequence:    12,
+			HostSummary: &pbhost.HostSummary{Hostname: "host-2"},
+		},
+	}
+	for _, d := range deltas {
+		w.Deltas <- d
+	}
+
+	suite.hostCache.EXPECT().WatchHosts().Return(w, nil)
+	suite.hostCache.EXPECT().StopWatch(w.ID).Return(nil)
+	stream.EXPECT().Context().Return(rootCtx).AnyTimes()
+	gomock.InOrder(
+		stream.EXPECT().Send(&svc.WatchHostsResponse{
+			WatchId:          w.ID,
+			Snapshot:         w.Snapshot,
+			SnapshotSequence: w.SnapshotSequence,
+		}).Return(nil),
+		stream.EXPECT().Send(&svc.WatchHostsResponse{
+			WatchId: w.ID,
+			Deltas:  deltas,
+		}).Do(func(_ *svc.WatchHostsResponse) {
+			w.Signal <- watchevent.StopSignalCancel
+		}).Return(nil),
+	)
+
+	err := suite.handler.WatchHosts(&svc.WatchHostsRequest{}, stream)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestWatchHostsFailure tests the failures of WatchHosts.
+func (suite *HostMgrHandlerTestSuite) TestWatchHostsFailure() {
+	defer suite.ctrl.Finish()
+
+	stream := svc_mocks.NewMockHostManagerServiceServiceWatchHostsYARPCServer(
+		suite.ctrl)
+
+	// Too many watches.
+	suite.hostCache.EXPECT().WatchHosts().Return(
+		nil, yarpcerrors.ResourceExhaustedErrorf("max watches reached"))
+	err := suite.handler.WatchHosts(&svc.WatchHostsRequest{}, stream)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+
+	// The snapshot cannot be sent.
+	w := &hostcache.HostWatch{
+		ID:     "watch-1",
+		Deltas: make(chan *hostmgr.HostDelta, 1),
+		Signal: make(chan watchevent.StopSignal, 1),
+	}
+	suite.hostCache.EXPECT().WatchHosts().Return(w, nil)
+	suite.hostCache.EXPECT().StopWatch(w.ID).Return(nil)
+	stream.EXPECT().Send(gomock.Any()).Return(errors.New("send failed"))
+	err = suite.handler.WatchHosts(&svc.WatchHostsRequest{}, stream)
+	suite.Error(err)
+
+	// The watcher falls behind.
+	w.Signal <- watchevent.StopSignalOverflow
+	suite.hostCache.EXPECT().WatchHosts().Return(w, nil)
+	suite.hostCache.EXPECT().StopWatch(w.ID).Return(nil)
+	stream.EXPECT().Context().Return(rootCtx).AnyTimes()
+	stream.EXPECT().Send(gomock.Any()).Return(nil)
+	err = suite.handler.WatchHosts(&svc.WatchHostsRequest{}, stream)
+	suite.True(yarpcerrors.IsInternal(err))
+}
+
+// TestMaintenance tests the host maintenance APIs.
+func (suite *HostMgrHandlerTestSuite) TestMaintenance() {
+	defer suite.ctrl.Finish()
+
+	suite.hostCache.EXPECT().StartMaintenance("h1").Return(nil)
+	_, err := suite.handler.StartMaintenance(
+		rootCtx,
+		&svc.StartMaintenanceRequest{Hostname: "h1"},
+	)
+	suite.NoError(err)
+
+	podIDs := []*peloton.PodID{{Value: "pod-1"}}
+	suite.hostCache.EXPECT().GetPodsOnDrainingHosts(uint32(10)).Return(podIDs)
+	resp, err := suite.handler.GetPodsOnDrainingHosts(
+		rootCtx,
+		&svc.GetPodsOnDrainingHostsRequest{Limit: 10},
+	)
+	suite.NoError(err)
+	suite.Equal(podIDs, resp.GetPodIds())
+
+	suite.hostCache.EXPECT().CompleteMaintenance("h1").
+		Return(yarpcerrors.NotFoundErrorf("host h1 is not DOWN"))
+	_, err = suite.handler.CompleteMaintenance(
+		rootCtx,
+		&svc.CompleteMaintenanceRequest{Hostname: "h1"},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	suite.hostCache.EXPECT().StartMaintenance("unknown").
+		Return(yarpcerrors.NotFoundErrorf("cannot find host unknown in cache"))
+	_, err = suite.handler.StartMaintenance(
+		rootCtx,
+		&svc.StartMaintenanceRequest{Hostname: "unknown"},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
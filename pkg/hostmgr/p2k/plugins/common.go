@@ -16,21 +16,39 @@ package plugins
 
 import (
 	"context"
+	"time"
+
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins/fake"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins/k8s"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	"github.com/uber-go/tally"
 )
 
 const EventChanSize = 1000
 
 // NewK8sPlugin returns a new instance of k8s plugin.
 func NewK8sPlugin(
-	configPath string,
+	config p2kconfig.K8sConfig,
+	scope tally.Scope,
+	podEventsCh chan<- *scalar.PodEvent,
+	hostEventCh chan<- *scalar.HostEvent,
+) (*k8s.K8SManager, error) {
+	return k8s.NewK8sManager(config, scope, podEventsCh, hostEventCh)
+}
+
+// NewFakePlugin returns a new instance of the fake plugin, which simulates
+// a cluster in memory.
+func NewFakePlugin(
+	config p2kconfig.FakeConfig,
 	podEventsCh chan<- *scalar.PodEvent,
 	hostEventCh chan<- *scalar.HostEvent,
-) (Plugin, error) {
-	return k8s.NewK8sManager(configPath, podEventsCh, hostEventCh)
+) Plugin {
+	return fake.NewFakeManager(config, podEventsCh, hostEventCh)
 }
 
 func NewNoopPlugin() Plugin {
@@ -61,9 +79,32 @@ func (p *NoopPlugin) KillPod(context context.Context, podID string) error {
 	return nil
 }
 
+// SupportsPodResize returns true as resizing a pod is a noop.
+func (p *NoopPlugin) SupportsPodResize() bool {
+	return true
+}
+
+// UpdatePodResources updates the resources of a running pod in place.
+func (p *NoopPlugin) UpdatePodResources(
+	ctx context.Context,
+	podID string,
+	spec *pbpod.PodSpec,
+) error {
+	return nil
+}
+
 // AckPodEvent is only implemented by mesos plugin. For K8s this is a noop.
 func (p *NoopPlugin) AckPodEvent(event *scalar.PodEvent) {}
 
+// ReserveOffers is only implemented by mesos plugin. For K8s this is a noop.
+func (p *NoopPlugin) ReserveOffers(hostname string, ttl time.Duration) {}
+
+// ReleaseOffers is only implemented by mesos plugin. For K8s this is a noop.
+func (p *NoopPlugin) ReleaseOffers(hostname string) {}
+
+// RecordDemand is only implemented by mesos plugin. For K8s this is a noop.
+func (p *NoopPlugin) RecordDemand() {}
+
 // ReconcileHosts will return the current state of hosts in the cluster.
 func (p *NoopPlugin) ReconcileHosts() ([]*scalar.HostInfo, error) {
 	return nil, nil
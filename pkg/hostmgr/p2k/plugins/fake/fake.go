@@ -0,0 +1,485 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_defaultHostnamePrefix = "fake-host"
+	_defaultHostCPU        = 32
+	_defaultHostMemMb      = 128 * 1024
+	_defaultHostDiskMb     = 1024 * 1024
+)
+
+// fakeHost is a host of the fake cluster.
+type fakeHost struct {
+	capacity models.HostResources
+	// map of pod id to the resources allocated to the pod
+	pods map[string]models.HostResources
+}
+
+// available returns the resources of the host not allocated to pods.
+func (h *fakeHost) available() models.HostResources {
+	available := h.capacity
+	for _, r := range h.pods {
+		available = available.Subtract(r)
+	}
+	return available
+}
+
+// FakeManager implements the plugin interface with an in memory cluster,
+// without depending on a real cluster manager. Its host inventory, launch
+// latency and failure rates are set by config and can be changed at
+// runtime, so that dev and scale tests can script its behavior.
+type FakeManager struct {
+	sync.RWMutex
+
+	// map of hostname to host
+	hosts map[string]*fakeHost
+	// map of pod id to the hostname the pod runs on
+	pods map[string]string
+
+	launchLatency     time.Duration
+	launchFailureRate float64
+	killFailureRate   float64
+	random            *rand.Rand
+
+	// eventID is incremented for each pod event, to allow the consumers
+	// to de-dupe the event stream.
+	eventID uint64
+
+	// Pod events channel.
+	podEventCh chan<- *scalar.PodEvent
+
+	// Host events channel.
+	hostEventCh chan<- *scalar.HostEvent
+
+	// Lifecycle manager.
+	lifecycle lifecycle.LifeCycle
+}
+
+// NewFakeManager returns a new instance of FakeManager with the hosts
+// described in config.
+func NewFakeManager(
+	config p2kconfig.FakeConfig,
+	podEventCh chan<- *scalar.PodEvent,
+	hostEventCh chan<- *scalar.HostEvent,
+) *FakeManager {
+	m := &FakeManager{
+		hosts:             make(map[string]*fakeHost),
+		pods:              make(map[string]string),
+		launchLatency:     config.LaunchLatency,
+		launchFailureRate: config.LaunchFailureRate,
+		killFailureRate:   config.KillFailureRate,
+		random:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		podEventCh:        podEventCh,
+		hostEventCh:       hostEventCh,
+		lifecycle:         lifecycle.NewLifeCycle(),
+	}
+
+	prefix := config.HostnamePrefix
+	if prefix == "" {
+		prefix = _defaultHostnamePrefix
+	}
+	capacity := models.HostResources{
+		NonSlack: hmscalar.Resources{
+			CPU:  config.HostCPU,
+			Mem:  config.HostMemMb,
+			Disk: config.HostDiskMb,
+			GPU:  config.HostGPU,
+		},
+	}
+	if capacity.NonSlack.Empty() {
+		capacity.NonSlack = hmscalar.Resources{
+			CPU:  _defaultHostCPU,
+			Mem:  _defaultHostMemMb,
+			Disk: _defaultHostDiskMb,
+		}
+	}
+	for i := 0; i < config.NumHosts; i++ {
+		m.hosts[fmt.Sprintf("%s-%d", prefix, i)] = &fakeHost{
+			capacity: capacity,
+			pods:     make(map[string]models.HostResources),
+		}
+	}
+	return m
+}
+
+// Start starts the fake manager and sends an add event for each host.
+func (m *FakeManager) Start() error {
+	if !m.lifecycle.Start() {
+		log.Warn("FakeManager is already started")
+		return nil
+	}
+
+	events := m.hostEvents(scalar.AddHost)
+	go func() {
+		for _, evt := range events {
+			if !m.sendHostEvent(evt) {
+				return
+			}
+		}
+	}()
+
+	log.WithField("num_hosts", len(events)).Info("FakeManager started")
+	return nil
+}
+
+// Stop stops the fake manager.
+func (m *FakeManager) Stop() {
+	if !m.lifecycle.Stop() {
+		log.Warn("FakeManager already stopped")
+		return
+	}
+	log.Info("FakeManager stopped")
+}
+
+// LaunchPods launches the pods on a fake host after the launch latency,
+// and sends a running event for each of them.
+func (m *FakeManager) LaunchPods(
+	ctx context.Context,
+	pods []*models.LaunchablePod,
+	hostname string,
+) ([]*models.LaunchablePod, error) {
+	m.RLock()
+	latency := m.launchLatency
+	m.RUnlock()
+
+	if latency > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(latency):
+		}
+	}
+
+	m.Lock()
+	if m.shouldFail(m.launchFailureRate) {
+		m.Unlock()
+		return nil, yarpcerrors.InternalErrorf(
+			"injected failure to launch pods on %s", hostname)
+	}
+
+	host, ok := m.hosts[hostname]
+	if !ok {
+		m.Unlock()
+		return nil, yarpcerrors.NotFoundErrorf("host %s not found", hostname)
+	}
+
+	var podEvents []*scalar.PodEvent
+	for _, pod := range pods {
+		podID := pod.PodId.GetValue()
+		host.pods[podID] = models.HostResources{
+			NonSlack: hmscalar.FromPodSpec(pod.Spec),
+		}
+		m.pods[podID] = hostname
+		podEvents = append(podEvents, m.newPodEvent(
+			podID,
+			hostname,
+			pbpod.PodState_POD_STATE_RUNNING,
+			scalar.AddPod,
+		))
+	}
+	hostEvent := m.hostEvent(hostname, host, scalar.UpdateHostAvailableRes)
+	m.Unlock()
+
+	m.sendHostEvent(hostEvent)
+	for _, evt := range podEvents {
+		m.sendPodEvent(evt)
+	}
+	return pods, nil
+}
+
+// KillPod kills a pod running on a fake host, and sends a killed event.
+func (m *FakeManager) KillPod(ctx context.Context, podID string) error {
+	m.Lock()
+	if m.shouldFail(m.killFailureRate) {
+		m.Unlock()
+		return yarpcerrors.InternalErrorf(
+			"injected failure to kill pod %s", podID)
+	}
+
+	hostname, ok := m.pods[podID]
+	if !ok {
+		m.Unlock()
+		return yarpcerrors.NotFoundErrorf("pod %s not found", podID)
+	}
+
+	delete(m.pods, podID)
+	var hostEvent *scalar.HostEvent
+	if host, ok := m.hosts[hostname]; ok {
+		delete(host.pods, podID)
+		hostEvent = m.hostEvent(hostname, host, scalar.UpdateHostAvailableRes)
+	}
+	podEvent := m.newPodEvent(
+		podID,
+		hostname,
+		pbpod.PodState_POD_STATE_KILLED,
+		scalar.DeletePod,
+	)
+	m.Unlock()
+
+	if hostEvent != nil {
+		m.sendHostEvent(hostEvent)
+	}
+	m.sendPodEvent(podEvent)
+	return nil
+}
+
+// SupportsPodResize returns true as the fake cluster can resize pods.
+func (m *FakeManager) SupportsPodResize() bool {
+	return true
+}
+
+// UpdatePodResources updates the resources allocated to a pod running on
+// a fake host, and sends an event with the new available resources.
+func (m *FakeManager) UpdatePodResources(
+	ctx context.Context,
+	podID string,
+	spec *pbpod.PodSpec,
+) error {
+	m.Lock()
+	hostname, ok := m.pods[podID]
+	if !ok {
+		m.Unlock()
+		return yarpcerrors.NotFoundErrorf("pod %s not found", podID)
+	}
+
+	var hostEvent *scalar.HostEvent
+	if host, ok := m.hosts[hostname]; ok {
+		host.pods[podID] = models.HostResources{
+			NonSlack: hmscalar.FromPodSpec(spec),
+		}
+		hostEvent = m.hostEvent(hostname, host, scalar.UpdateHostAvailableRes)
+	}
+	m.Unlock()
+
+	if hostEvent != nil {
+		m.sendHostEvent(hostEvent)
+	}
+	return nil
+}
+
+// AckPodEvent is a noop for the fake manager.
+func (m *FakeManager) AckPodEvent(event *scalar.PodEvent) {}
+
+// ReserveOffers is a noop for the fake manager.
+func (m *FakeManager) ReserveOffers(hostname string, ttl time.Duration) {}
+
+// ReleaseOffers is a noop for the fake manager.
+func (m *FakeManager) ReleaseOffers(hostname string) {}
+
+// RecordDemand is a noop for the fake manager.
+func (m *FakeManager) RecordDemand() {}
+
+// ReconcileHosts returns the current state of the fake hosts.
+func (m *FakeManager) ReconcileHosts() ([]*scalar.HostInfo, error) {
+	events := m.hostEvents(scalar.AddHost)
+	hostInfos := make([]*scalar.HostInfo, 0, len(events))
+	for _, evt := range events {
+		hostInfos = append(hostInfos, evt.GetHostInfo())
+	}
+	return hostInfos, nil
+}
+
+// AddHost adds a host to the fake cluster, or replaces its capacity
+// if it already exists.
+func (m *FakeManager) AddHost(hostname string, capacity models.HostResources) {
+	m.Lock()
+	eventType := scalar.UpdateHostSpec
+	host, ok := m.hosts[hostname]
+	if !ok {
+		eventType = scalar.AddHost
+		host = &fakeHost{pods: make(map[string]models.HostResources)}
+		m.hosts[hostname] = host
+	}
+	host.capacity = capacity
+	evt := m.hostEvent(hostname, host, eventType)
+	m.Unlock()
+
+	m.sendHostEvent(evt)
+}
+
+// RemoveHost removes a host, and the pods running on it, from the fake
+// cluster. The pods are reported as lost.
+func (m *FakeManager) RemoveHost(hostname string) {
+	m.Lock()
+	host, ok := m.hosts[hostname]
+	if !ok {
+		m.Unlock()
+		return
+	}
+
+	delete(m.hosts, hostname)
+	var podEvents []*scalar.PodEvent
+	for podID := range host.pods {
+		delete(m.pods, podID)
+		podEvents = append(podEvents, m.newPodEvent(
+			podID,
+			hostname,
+			pbpod.PodState_POD_STATE_LOST,
+			scalar.DeletePod,
+		))
+	}
+	evt := m.hostEvent(hostname, host, scalar.DeleteHost)
+	m.Unlock()
+
+	m.sendHostEvent(evt)
+	for _, podEvent := range podEvents {
+		m.sendPodEvent(podEvent)
+	}
+}
+
+// SetLaunchLatency sets the time taken to launch pods.
+func (m *FakeManager) SetLaunchLatency(latency time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.launchLatency = latency
+}
+
+// SetLaunchFailureRate sets the fraction of launch calls which fail.
+func (m *FakeManager) SetLaunchFailureRate(rate float64) {
+	m.Lock()
+	defer m.Unlock()
+	m.launchFailureRate = rate
+}
+
+// SetKillFailureRate sets the fraction of kill calls which fail.
+func (m *FakeManager) SetKillFailureRate(rate float64) {
+	m.Lock()
+	defer m.Unlock()
+	m.killFailureRate = rate
+}
+
+// shouldFail returns true if a call should fail given the failure rate.
+// Must be called with the lock held, as rand.Rand is not thread safe.
+func (m *FakeManager) shouldFail(rate float64) bool {
+	return rate > 0 && m.random.Float64() < rate
+}
+
+// hostEvents returns an event of the given type for each of the hosts,
+// sorted by hostname.
+func (m *FakeManager) hostEvents(
+	eventType scalar.HostEventType,
+) []*scalar.HostEvent {
+	m.RLock()
+	defer m.RUnlock()
+
+	hostnames := make([]string, 0, len(m.hosts))
+	for hostname := range m.hosts {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	events := make([]*scalar.HostEvent, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		events = append(
+			events,
+			m.hostEvent(hostname, m.hosts[hostname], eventType))
+	}
+	return events
+}
+
+// hostEvent builds an event for a host. Must be called with the lock held.
+func (m *FakeManager) hostEvent(
+	hostname string,
+	host *fakeHost,
+	eventType scalar.HostEventType,
+) *scalar.HostEvent {
+	return scalar.BuildHostEventFromResource(
+		hostname,
+		host.available(),
+		host.capacity,
+		eventType,
+	)
+}
+
+// newPodEvent builds an event for a pod. Must be called with the lock held.
+func (m *FakeManager) newPodEvent(
+	podID string,
+	hostname string,
+	state pbpod.PodState,
+	eventType scalar.PodEventType,
+) *scalar.PodEvent {
+	m.eventID++
+	return &scalar.PodEvent{
+		Event: &pbpod.PodEvent{
+			PodId:       &peloton.PodID{Value: podID},
+			ActualState: state.String(),
+			Timestamp:   time.Now().Format(time.RFC3339),
+			AgentId:     hostname,
+			Hostname:    hostname,
+			Message:     "fake cluster",
+		},
+		EventType: eventType,
+		EventID:   strconv.FormatUint(m.eventID, 10),
+	}
+}
+
+// sendHostEvent sends a host event, and returns false if the manager
+// is not running. Events are dropped while the manager is not running.
+func (m *FakeManager) sendHostEvent(evt *scalar.HostEvent) bool {
+	stopCh := m.lifecycle.StopCh()
+	select {
+	case <-stopCh:
+		return false
+	default:
+	}
+
+	select {
+	case m.hostEventCh <- evt:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
+
+// sendPodEvent sends a pod event, and returns false if the manager
+// is not running. Events are dropped while the manager is not running.
+func (m *FakeManager) sendPodEvent(evt *scalar.PodEvent) bool {
+	stopCh := m.lifecycle.StopCh()
+	select {
+	case <-stopCh:
+		return false
+	default:
+	}
+
+	select {
+	case m.podEventCh <- evt:
+		return true
+	case <-stopCh:
+		return false
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins/plugintest"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type FakeManagerTestSuite struct {
+	suite.Suite
+
+	podEventCh  chan *scalar.PodEvent
+	hostEventCh chan *scalar.HostEvent
+	testManager *FakeManager
+}
+
+func (suite *FakeManagerTestSuite) SetupTest() {
+	suite.podEventCh = make(chan *scalar.PodEvent, 1000)
+	suite.hostEventCh = make(chan *scalar.HostEvent, 1000)
+	suite.testManager = NewFakeManager(
+		p2kconfig.FakeConfig{
+			NumHosts:  3,
+			HostCPU:   4,
+			HostMemMb: 1024,
+		},
+		suite.podEventCh,
+		suite.hostEventCh,
+	)
+	suite.NoError(suite.testManager.Start())
+}
+
+func (suite *FakeManagerTestSuite) TearDownTest() {
+	suite.testManager.Stop()
+}
+
+func TestFakeManagerTestSuite(t *testing.T) {
+	suite.Run(t, &FakeManagerTestSuite{})
+}
+
+// TestFakeManagerConformance runs the plugin conformance tests against the
+// fake manager.
+func TestFakeManagerConformance(t *testing.T) {
+	plugintest.RunConformanceTests(t, func(
+		podEventCh chan<- *scalar.PodEvent,
+		hostEventCh chan<- *scalar.HostEvent,
+	) (plugins.Plugin, string) {
+		m := NewFakeManager(
+			p2kconfig.FakeConfig{NumHosts: 1},
+			podEventCh,
+			hostEventCh,
+		)
+		return m, "fake-host-0"
+	})
+}
+
+// receiveHostEvent returns the next host event.
+func (suite *FakeManagerTestSuite) receiveHostEvent() *scalar.HostEvent {
+	select {
+	case evt := <-suite.hostEventCh:
+		return evt
+	case <-time.After(time.Second):
+		suite.FailNow("no host event received")
+	}
+	return nil
+}
+
+// receivePodEvent returns the next pod event.
+func (suite *FakeManagerTestSuite) receivePodEvent() *scalar.PodEvent {
+	select {
+	case evt := <-suite.podEventCh:
+		return evt
+	case <-time.After(time.Second):
+		suite.FailNow("no pod event received")
+	}
+	return nil
+}
+
+func (suite *FakeManagerTestSuite) newLaunchablePod(
+	podID string,
+) *models.LaunchablePod {
+	return &models.LaunchablePod{
+		PodId: &peloton.PodID{Value: podID},
+		Spec: &pbpod.PodSpec{
+			Containers: []*pbpod.ContainerSpec{
+				{
+					Resource: &pbpod.ResourceSpec{
+						CpuLimit:   1,
+						MemLimitMb: 100,
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestStartSendsHostEvents tests that the configured hosts are
+// sent on start and returned by reconcile.
+func (suite *FakeManagerTestSuite) TestStartSendsHostEvents() {
+	for _, hostname := range []string{
+		"fake-host-0",
+		"fake-host-1",
+		"fake-host-2",
+	} {
+		evt := suite.receiveHostEvent()
+		suite.Equal(scalar.AddHost, evt.GetEventType())
+		suite.Equal(hostname, evt.GetHostInfo().GetHostName())
+		suite.Equal(
+			float64(4),
+			evt.GetHostInfo().GetCapacity().NonSlack.GetCPU())
+	}
+
+	hostInfos, err := suite.testManager.ReconcileHosts()
+	suite.NoError(err)
+	suite.Len(hostInfos, 3)
+}
+
+// TestLaunchAndKillPod tests launching and killing a pod.
+func (suite *FakeManagerTestSuite) TestLaunchAndKillPod() {
+	for i := 0; i < 3; i++ {
+		suite.receiveHostEvent()
+	}
+
+	launched, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"fake-host-0",
+	)
+	suite.NoError(err)
+	suite.Len(launched, 1)
+
+	hostEvent := suite.receiveHostEvent()
+	suite.Equal(scalar.UpdateHostAvailableRes, hostEvent.GetEventType())
+	suite.Equal(
+		hmscalar.Resources{CPU: 3, Mem: 924},
+		hostEvent.GetHostInfo().GetAvailable().NonSlack)
+
+	podEvent := suite.receivePodEvent()
+	suite.Equal(scalar.AddPod, podEvent.EventType)
+	suite.Equal("pod-1", podEvent.Event.GetPodId().GetValue())
+	suite.Equal(
+		pbpod.PodState_POD_STATE_RUNNING.String(),
+		podEvent.Event.GetActualState())
+
+	suite.NoError(suite.testManager.KillPod(context.Background(), "pod-1"))
+	hostEvent = suite.receiveHostEvent()
+	suite.Equal(
+		hmscalar.Resources{CPU: 4, Mem: 1024},
+		hostEvent.GetHostInfo().GetAvailable().NonSlack)
+	podEvent = suite.receivePodEvent()
+	suite.Equal(scalar.DeletePod, podEvent.EventType)
+	suite.Equal(
+		pbpod.PodState_POD_STATE_KILLED.String(),
+		podEvent.Event.GetActualState())
+
+	err = suite.testManager.KillPod(context.Background(), "pod-1")
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestUpdatePodResources tests resizing a running pod.
+func (suite *FakeManagerTestSuite) TestUpdatePodResources() {
+	for i := 0; i < 3; i++ {
+		suite.receiveHostEvent()
+	}
+
+	_, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"fake-host-0",
+	)
+	suite.NoError(err)
+	suite.receiveHostEvent()
+	suite.receivePodEvent()
+
+	spec := suite.newLaunchablePod("pod-1").Spec
+	spec.Containers[0].Resource.CpuLimit = 2
+	suite.NoError(suite.testManager.UpdatePodResources(
+		context.Background(), "pod-1", spec))
+	hostEvent := suite.receiveHostEvent()
+	suite.Equal(scalar.UpdateHostAvailableRes, hostEvent.GetEventType())
+	suite.Equal(
+		hmscalar.Resources{CPU: 2, Mem: 924},
+		hostEvent.GetHostInfo().GetAvailable().NonSlack)
+
+	err = suite.testManager.UpdatePodResources(
+		context.Background(), "pod-2", spec)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestLaunchPodsUnknownHost tests launching pods on a host
+// not in the fake cluster.
+func (suite *FakeManagerTestSuite) TestLaunchPodsUnknownHost() {
+	_, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"unknown-host",
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestFailureInjection tests the injected launch and kill failures.
+func (suite *FakeManagerTestSuite) TestFailureInjection() {
+	suite.testManager.SetLaunchFailureRate(1)
+	_, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"fake-host-0",
+	)
+	suite.True(yarpcerrors.IsInternal(err))
+
+	suite.testManager.SetLaunchFailureRate(0)
+	_, err = suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"fake-host-0",
+	)
+	suite.NoError(err)
+
+	suite.testManager.SetKillFailureRate(1)
+	err = suite.testManager.KillPod(context.Background(), "pod-1")
+	suite.True(yarpcerrors.IsInternal(err))
+}
+
+// TestLaunchLatency tests that launches honor the launch latency
+// and the context deadline.
+func (suite *FakeManagerTestSuite) TestLaunchLatency() {
+	suite.testManager.SetLaunchLatency(time.Minute)
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+
+	_, err := suite.testManager.LaunchPods(
+		ctx,
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"fake-host-0",
+	)
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+// TestAddAndRemoveHost tests scripting the host inventory.
+func (suite *FakeManagerTestSuite) TestAddAndRemoveHost() {
+	for i := 0; i < 3; i++ {
+		suite.receiveHostEvent()
+	}
+
+	capacity := models.HostResources{
+		NonSlack: hmscalar.Resources{CPU: 8, Mem: 2048},
+	}
+	suite.testManager.AddHost("new-host", capacity)
+	evt := suite.receiveHostEvent()
+	suite.Equal(scalar.AddHost, evt.GetEventType())
+	suite.Equal(capacity, evt.GetHostInfo().GetCapacity())
+
+	_, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{suite.newLaunchablePod("pod-1")},
+		"new-host",
+	)
+	suite.NoError(err)
+	suite.receiveHostEvent()
+	suite.receivePodEvent()
+
+	suite.testManager.RemoveHost("new-host")
+	evt = suite.receiveHostEvent()
+	suite.Equal(scalar.DeleteHost, evt.GetEventType())
+	podEvent := suite.receivePodEvent()
+	suite.Equal(
+		pbpod.PodState_POD_STATE_LOST.String(),
+		podEvent.Event.GetActualState())
+
+	hostInfos, err := suite.testManager.ReconcileHosts()
+	suite.NoError(err)
+	suite.Len(hostInfos, 3)
+}
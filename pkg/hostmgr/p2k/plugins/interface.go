@@ -16,6 +16,9 @@ package plugins
 
 import (
 	"context"
+	"time"
+
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
@@ -36,9 +39,32 @@ type Plugin interface {
 	// KillPod kills a pod on a host.
 	KillPod(ctx context.Context, podID string) error
 
+	// SupportsPodResize returns true if the plugin can resize a running
+	// pod in place with UpdatePodResources.
+	SupportsPodResize() bool
+
+	// UpdatePodResources updates the resources of a running pod in place,
+	// without restarting it. Plugins which cannot resize a running pod
+	// return an Unimplemented error.
+	UpdatePodResources(ctx context.Context, podID string, spec *pbpod.PodSpec) error
+
 	// AckPodEvent is only implemented by mesos plugin. For K8s this is a noop.
 	AckPodEvent(event *scalar.PodEvent)
 
+	// ReserveOffers pins the resources offered on a host for a placement
+	// decision until the ttl elapses, so that LaunchPods can use them.
+	// It is only implemented by mesos plugin. For K8s this is a noop.
+	ReserveOffers(hostname string, ttl time.Duration)
+
+	// ReleaseOffers releases the resources pinned by ReserveOffers.
+	// It is only implemented by mesos plugin. For K8s this is a noop.
+	ReleaseOffers(hostname string)
+
+	// RecordDemand signals that there are pods waiting to be placed, so
+	// that the plugin keeps receiving resources from the cluster.
+	// It is only implemented by mesos plugin. For K8s this is a noop.
+	RecordDemand()
+
 	// ReconcileHosts will return the current state of hosts in the cluster.
 	ReconcileHosts() ([]*scalar.HostInfo, error)
 }
@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Policies for the pods scheduled by Peloton in the cluster which are
+// unknown to Peloton.
+const (
+	// OrphanPodPolicyDelete deletes the orphan pods from the cluster.
+	OrphanPodPolicyDelete = "delete"
+	// OrphanPodPolicyAdopt adds the orphan pods to the pod cache, so that
+	// their resources are accounted for on their hosts.
+	OrphanPodPolicyAdopt = "adopt"
+)
+
+// PodCache is the cache of the pods known to Peloton, against which the
+// pods in the cluster are garbage collected.
+type PodCache interface {
+	// GetPodHostnames returns the hostname of each pod known to Peloton,
+	// keyed by pod ID.
+	GetPodHostnames() map[string]string
+
+	// RecoverPodInfoOnHost adds a pod running on a host to the cache.
+	RecoverPodInfoOnHost(
+		id *peloton.PodID,
+		hostname string,
+		state pbpod.PodState,
+		spec *pbpod.PodSpec,
+	)
+}
+
+// SetPodCache sets the pod cache against which the pods in the cluster are
+// garbage collected. It must be called before the plugin is started, the
+// pods are not garbage collected until it is.
+func (k *K8SManager) SetPodCache(podCache PodCache) {
+	k.podCache = podCache
+}
+
+// runPodGC periodically garbage collects the orphan pods until the plugin
+// is stopped.
+func (k *K8SManager) runPodGC() {
+	defer k.lifecycle.StopComplete()
+
+	var tick <-chan time.Time
+	if k.config.PodGCInterval > 0 {
+		ticker := time.NewTicker(k.config.PodGCInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-tick:
+			k.collectOrphanPods()
+		case <-k.lifecycle.StopCh():
+			return
+		}
+	}
+}
+
+// collectOrphanPods compares the pods scheduled by Peloton in the cluster
+// with the pods in the pod cache. The pods in the cluster unknown to the
+// pod cache are deleted or adopted as per the orphan pod policy, and the
+// pods in the pod cache which are not in the cluster are reported as lost.
+// A pod has to be found in two consecutive passes to be collected, so that
+// the pods being launched or killed, or whose events are still in flight,
+// are not collected.
+func (k *K8SManager) collectOrphanPods() {
+	if k.podCache == nil {
+		return
+	}
+	k.metrics.PodGC.Inc(1)
+
+	pods, err := k.podLister.List(labels.Everything())
+	if err != nil {
+		log.WithError(err).Warn("Failed to list pods for garbage collection")
+		return
+	}
+	nodes, err := k.listNodes()
+	if err != nil {
+		log.WithError(err).Warn("Failed to list nodes for garbage collection")
+		return
+	}
+
+	known := k.podCache.GetPodHostnames()
+
+	clusterPods := make(map[string]*corev1.Pod)
+	orphans := make(map[string]struct{})
+	for _, pod := range pods {
+		if pod.Spec.SchedulerName != common.PelotonRole {
+			continue
+		}
+		clusterPods[pod.Name] = pod
+		if _, ok := known[pod.Name]; ok {
+			continue
+		}
+		orphans[pod.Name] = struct{}{}
+		if _, ok := k.orphanPods[pod.Name]; ok {
+			k.handleOrphanPod(pod)
+		}
+	}
+
+	// Only the pods on k8s nodes are expected to be in the cluster, the
+	// pod cache may also have pods running on Mesos agents.
+	isNode := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		isNode[node.Name] = true
+	}
+
+	lost := make(map[string]struct{})
+	for podID, hostname := range known {
+		if _, ok := clusterPods[podID]; ok || !isNode[hostname] {
+			continue
+		}
+		lost[podID] = struct{}{}
+		if _, ok := k.lostPods[podID]; ok {
+			k.handleLostPod(podID, hostname)
+		}
+	}
+
+	k.orphanPods = orphans
+	k.lostPods = lost
+}
+
+// handleOrphanPod deletes or adopts a pod scheduled by Peloton in the
+// cluster which is unknown to the pod cache.
+func (k *K8SManager) handleOrphanPod(pod *corev1.Pod) {
+	k.metrics.OrphanPodsFound.Inc(1)
+
+	if k.config.OrphanPodPolicy == OrphanPodPolicyAdopt {
+		evt := scalar.BuildPodEventFromPod(pod, scalar.UpdatePod)
+		k.podCache.RecoverPodInfoOnHost(
+			&peloton.PodID{Value: pod.Name},
+			pod.Spec.NodeName,
+			pbpod.PodState(pbpod.PodState_value[evt.Event.GetActualState()]),
+			toPelotonPodSpec(pod),
+		)
+		k.metrics.OrphanPodsAdopted.Inc(1)
+		log.WithFields(log.Fields{
+			"pod_id":   pod.Name,
+			"hostname": pod.Spec.NodeName,
+		}).Info("Adopted orphan pod")
+		return
+	}
+
+	err := k.kubeClient.CoreV1().
+		Pods(pod.Namespace).
+		Delete(pod.Name, &metav1.DeleteOptions{})
+	if err != nil {
+		k.metrics.OrphanPodsDeleteFail.Inc(1)
+		log.WithField("pod_id", pod.Name).
+			WithError(err).
+			Warn("Failed to delete orphan pod")
+		return
+	}
+	k.metrics.OrphanPodsDeleted.Inc(1)
+	log.WithFields(log.Fields{
+		"pod_id":   pod.Name,
+		"hostname": pod.Spec.NodeName,
+	}).Info("Deleted orphan pod")
+}
+
+// handleLostPod sends a delete event for a pod in the pod cache which is
+// not in the cluster, so that it is removed from the pod cache and
+// reported as lost.
+func (k *K8SManager) handleLostPod(podID string, hostname string) {
+	k.metrics.LostPodsFound.Inc(1)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podID},
+		Spec:       corev1.PodSpec{NodeName: hostname},
+		Status:     corev1.PodStatus{Phase: corev1.PodUnknown},
+	}
+	log.WithFields(log.Fields{
+		"pod_id":   podID,
+		"hostname": hostname,
+	}).Info("Pod not found in the cluster")
+	k.podEventCh <- scalar.BuildPodEventFromPod(pod, scalar.DeletePod)
+}
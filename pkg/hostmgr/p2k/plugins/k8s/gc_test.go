@@ -0,0 +1,193 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	"github.com/uber-go/tally"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakePodCache is a PodCache keeping the pods in memory.
+type fakePodCache struct {
+	sync.Mutex
+
+	pods  map[string]string
+	specs map[string]*pbpod.PodSpec
+}
+
+func newFakePodCache() *fakePodCache {
+	return &fakePodCache{
+		pods:  make(map[string]string),
+		specs: make(map[string]*pbpod.PodSpec),
+	}
+}
+
+func (c *fakePodCache) GetPodHostnames() map[string]string {
+	c.Lock()
+	defer c.Unlock()
+
+	result := make(map[string]string, len(c.pods))
+	for id, hostname := range c.pods {
+		result[id] = hostname
+	}
+	return result
+}
+
+func (c *fakePodCache) RecoverPodInfoOnHost(
+	id *peloton.PodID,
+	hostname string,
+	state pbpod.PodState,
+	spec *pbpod.PodSpec,
+) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.pods[id.GetValue()] = hostname
+	c.specs[id.GetValue()] = spec
+}
+
+// createPelotonPod creates a pod scheduled by Peloton in the cluster, and
+// waits for the informer to see it.
+func (suite *K8SManagerTestSuite) createPelotonPod(
+	podName string,
+	hostname string,
+) {
+	pod := newTestK8sPod(podName)
+	pod.Namespace = "default"
+	pod.Spec.SchedulerName = common.PelotonRole
+	pod.Spec.NodeName = hostname
+	_, err := suite.testKubeClient.CoreV1().Pods("default").Create(pod)
+	suite.NoError(err)
+
+	evt := <-suite.podEventCh
+	suite.Equal(scalar.AddPod, evt.EventType)
+	suite.Equal(podName, evt.Event.GetPodId().GetValue())
+}
+
+// TestCollectOrphanPodsDelete tests that the pods in the cluster unknown to
+// the pod cache are deleted once found by two consecutive passes.
+func (suite *K8SManagerTestSuite) TestCollectOrphanPodsDelete() {
+	testScope := tally.NewTestScope("", map[string]string{})
+	suite.testManager.metrics = newMetrics(testScope)
+	podCache := newFakePodCache()
+	podCache.pods["known_pod"] = "test_host"
+	suite.testManager.SetPodCache(podCache)
+	suite.NoError(suite.testManager.Start())
+	defer suite.testManager.Stop()
+
+	suite.createPelotonPod("known_pod", "test_host")
+	suite.createPelotonPod("orphan_pod", "test_host")
+
+	// A pod not scheduled by Peloton is ignored.
+	otherPod := newTestK8sPod("other_pod")
+	otherPod.Namespace = "default"
+	_, err := suite.testKubeClient.CoreV1().Pods("default").Create(otherPod)
+	suite.NoError(err)
+
+	// The orphan pod is not deleted on the first pass.
+	suite.testManager.collectOrphanPods()
+	_, err = suite.testKubeClient.CoreV1().
+		Pods("default").
+		Get("orphan_pod", metav1.GetOptions{})
+	suite.NoError(err)
+
+	suite.testManager.collectOrphanPods()
+	_, err = suite.testKubeClient.CoreV1().
+		Pods("default").
+		Get("orphan_pod", metav1.GetOptions{})
+	suite.Error(err)
+	_, err = suite.testKubeClient.CoreV1().
+		Pods("default").
+		Get("known_pod", metav1.GetOptions{})
+	suite.NoError(err)
+	_, err = suite.testKubeClient.CoreV1().
+		Pods("default").
+		Get("other_pod", metav1.GetOptions{})
+	suite.NoError(err)
+
+	counters := testScope.Snapshot().Counters()
+	suite.Equal(int64(2), counters["pod_gc+"].Value())
+	suite.Equal(int64(1), counters["orphan_pods_found+"].Value())
+	suite.Equal(
+		int64(1), counters["orphan_pods_deleted+result=success"].Value())
+}
+
+// TestCollectOrphanPodsAdopt tests that the pods in the cluster unknown to
+// the pod cache are added to the pod cache with the adopt policy.
+func (suite *K8SManagerTestSuite) TestCollectOrphanPodsAdopt() {
+	suite.testManager.config.OrphanPodPolicy = OrphanPodPolicyAdopt
+	podCache := newFakePodCache()
+	suite.testManager.SetPodCache(podCache)
+	suite.NoError(suite.testManager.Start())
+	defer suite.testManager.Stop()
+
+	suite.createPelotonPod("orphan_pod", "test_host")
+
+	suite.testManager.collectOrphanPods()
+	suite.testManager.collectOrphanPods()
+
+	suite.Equal(
+		map[string]string{"orphan_pod": "test_host"},
+		podCache.GetPodHostnames(),
+	)
+	spec := podCache.specs["orphan_pod"]
+	suite.Equal(1.0, spec.GetContainers()[0].GetResource().GetCpuLimit())
+	suite.Equal(100.0, spec.GetContainers()[0].GetResource().GetMemLimitMb())
+
+	// The adopted pod is left in the cluster.
+	_, err := suite.testKubeClient.CoreV1().
+		Pods("default").
+		Get("orphan_pod", metav1.GetOptions{})
+	suite.NoError(err)
+}
+
+// TestCollectLostPods tests that a delete event is sent for the pods in
+// the pod cache which are on a node but not in the cluster.
+func (suite *K8SManagerTestSuite) TestCollectLostPods() {
+	podCache := newFakePodCache()
+	podCache.pods["lost_pod"] = "test_host"
+	// Pods on Mesos agents are not in the cluster.
+	podCache.pods["mesos_pod"] = "mesos_host"
+	suite.testManager.SetPodCache(podCache)
+	suite.NoError(suite.testManager.Start())
+	defer suite.testManager.Stop()
+
+	_, err := suite.testKubeClient.CoreV1().
+		Nodes().
+		Create(newTestK8sNode("test_host"))
+	suite.NoError(err)
+	<-suite.hostEventCh
+
+	suite.testManager.collectOrphanPods()
+	suite.Empty(suite.podEventCh)
+
+	suite.testManager.collectOrphanPods()
+	evt := <-suite.podEventCh
+	suite.Equal(scalar.DeletePod, evt.EventType)
+	suite.Equal("lost_pod", evt.Event.GetPodId().GetValue())
+	suite.Equal("test_host", evt.Event.GetHostname())
+	suite.Equal(
+		pbpod.PodState_POD_STATE_LOST.String(),
+		evt.Event.GetActualState(),
+	)
+	suite.Empty(suite.podEventCh)
+}
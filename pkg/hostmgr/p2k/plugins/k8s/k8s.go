@@ -17,15 +17,21 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"time"
+
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
 	"go.uber.org/yarpc/yarpcerrors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
@@ -45,6 +51,20 @@ type K8SManager struct {
 	// functionality.
 	informerFactory informers.SharedInformerFactory
 	nodeLister      corelisters.NodeLister
+	podLister       corelisters.PodLister
+
+	// Configuration of the plugin.
+	config p2kconfig.K8sConfig
+
+	// Cache of the pods known to Peloton, used to garbage collect the
+	// orphan pods.
+	podCache PodCache
+
+	// Pods found by the previous garbage collection pass, in the cluster
+	// but not in the pod cache, and in the pod cache but not in the
+	// cluster respectively.
+	orphanPods map[string]struct{}
+	lostPods   map[string]struct{}
 
 	// Pod events channel.
 	podEventCh chan<- *scalar.PodEvent
@@ -54,17 +74,20 @@ type K8SManager struct {
 
 	// Lifecycle manager.
 	lifecycle lifecycle.LifeCycle
+
+	metrics *metrics
 }
 
 // NewK8sManager returns a new instance of K8SManager
 func NewK8sManager(
-	configPath string,
+	config p2kconfig.K8sConfig,
+	scope tally.Scope,
 	podEventCh chan<- *scalar.PodEvent,
 	hostEventCh chan<- *scalar.HostEvent,
 ) (*K8SManager, error) {
 	// Initialize k8s client.
 	kubeConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: configPath},
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: config.Kubeconfig},
 		&clientcmd.ConfigOverrides{}).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("error creating kube config: %v", err)
@@ -78,6 +101,8 @@ func NewK8sManager(
 
 	return newK8sManagerWithClient(
 		kubeClient,
+		config,
+		scope,
 		podEventCh,
 		hostEventCh,
 	), nil
@@ -87,23 +112,37 @@ func NewK8sManager(
 // client.
 func newK8sManagerWithClient(
 	kubeClient kubernetes.Interface,
+	config p2kconfig.K8sConfig,
+	scope tally.Scope,
 	podEventCh chan<- *scalar.PodEvent,
 	hostEventCh chan<- *scalar.HostEvent,
 ) *K8SManager {
+	resyncInterval := config.ResyncInterval
+	if resyncInterval <= 0 {
+		resyncInterval = _defaultResyncInterval
+	}
+	if config.OrphanPodPolicy == "" {
+		config.OrphanPodPolicy = OrphanPodPolicyDelete
+	}
+
 	// Initialize informers.
 	informerFactory := informers.NewSharedInformerFactory(
 		kubeClient,
-		_defaultResyncInterval,
+		resyncInterval,
 	)
 	nodeLister := informerFactory.Core().V1().Nodes().Lister()
+	podLister := informerFactory.Core().V1().Pods().Lister()
 
 	return &K8SManager{
 		kubeClient:      kubeClient,
 		informerFactory: informerFactory,
 		nodeLister:      nodeLister,
+		podLister:       podLister,
+		config:          config,
 		podEventCh:      podEventCh,
 		hostEventCh:     hostEventCh,
 		lifecycle:       lifecycle.NewLifeCycle(),
+		metrics:         newMetrics(scope.SubScope("k8s_plugin")),
 	}
 }
 
@@ -133,6 +172,9 @@ func (k *K8SManager) Start() error {
 	// Start informers.
 	k.informerFactory.Start(k.lifecycle.StopCh())
 
+	// Start the garbage collection of orphan pods.
+	go k.runPodGC()
+
 	// Wait for all started informers cache were synced before scheduling.
 	if !cache.WaitForCacheSync(
 		k.lifecycle.StopCh(),
@@ -151,7 +193,7 @@ func (k *K8SManager) Stop() {
 		log.Warn("K8SManager already stopped")
 		return
 	}
-	// Wait for drainer to be stopped.
+	// Wait for the pod garbage collection to be stopped.
 	k.lifecycle.Wait()
 	log.Info("K8SManager stopped")
 }
@@ -181,6 +223,16 @@ func (k *K8SManager) ReconcileHosts() ([]*scalar.HostInfo, error) {
 func (k *K8SManager) AckPodEvent(event *scalar.PodEvent) {
 }
 
+// ReserveOffers is relevant to Mesos. K8s has no offers, so this is a noop.
+func (k *K8SManager) ReserveOffers(hostname string, ttl time.Duration) {}
+
+// ReleaseOffers is relevant to Mesos. K8s has no offers, so this is a noop.
+func (k *K8SManager) ReleaseOffers(hostname string) {}
+
+// RecordDemand is relevant to Mesos. K8s does not send offers, so this is
+// a noop.
+func (k *K8SManager) RecordDemand() {}
+
 // K8s Reconcile logic.
 
 // use K8s node lister to get the current list of nodes from K8s API server.
@@ -210,9 +262,13 @@ func (k *K8SManager) addNode(obj interface{}) {
 	k.hostEventCh <- evt
 }
 
-// NodeInformer update function.
+// NodeInformer update function. It is also called for every node when the
+// informer resyncs.
 func (k *K8SManager) updateNode(old interface{}, new interface{}) {
 	node := new.(*corev1.Node)
+	if isResync(old, new) {
+		k.metrics.NodeResync.Inc(1)
+	}
 	evt, err := scalar.BuildHostEventFromNode(node, scalar.UpdateHostSpec)
 	if err != nil {
 		// Drop this error, reconcile will take care of this.
@@ -267,7 +323,9 @@ func (k *K8SManager) addPod(obj interface{}) {
 	k.podEventCh <- evt
 }
 
-// PodInformer update function.
+// PodInformer update function. It is also called for every pod when the
+// informer resyncs, the event is sent anyway so that the pod state is
+// reconciled with the state in the cluster.
 func (k *K8SManager) updatePod(oldObj interface{}, newObj interface{}) {
 	pod := newObj.(*corev1.Pod)
 	if pod.Spec.SchedulerName != common.PelotonRole {
@@ -277,6 +335,9 @@ func (k *K8SManager) updatePod(oldObj interface{}, newObj interface{}) {
 		}).Debug("non-peloton pod")
 		return
 	}
+	if isResync(oldObj, newObj) {
+		k.metrics.PodResync.Inc(1)
+	}
 
 	evt := scalar.BuildPodEventFromPod(pod, scalar.UpdatePod)
 	log.WithFields(log.Fields{
@@ -303,6 +364,21 @@ func (k *K8SManager) deletePod(obj interface{}) {
 	k.podEventCh <- evt
 }
 
+// isResync returns true if an update event is sent by an informer resync
+// rather than by a change of the object, in which case the resource
+// version of the object is unchanged.
+func isResync(oldObj interface{}, newObj interface{}) bool {
+	oldMeta, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldMeta.GetResourceVersion() == newMeta.GetResourceVersion()
+}
+
 // K8S API calls.
 
 // LaunchPods creates a slice of pod objects, binds them to the node specified by hostname.
@@ -346,6 +422,52 @@ func (k *K8SManager) LaunchPods(
 	return launched, nil
 }
 
+// SupportsPodResize returns true as the resource requirements of the
+// containers of a pod can be updated on the API server.
+func (k *K8SManager) SupportsPodResize() bool {
+	return true
+}
+
+// UpdatePodResources resizes the containers of a running pod in place by
+// updating their resource requirements on the API server.
+func (k *K8SManager) UpdatePodResources(
+	ctx context.Context,
+	podID string,
+	spec *pbpod.PodSpec,
+) error {
+	pod, err := k.kubeClient.CoreV1().
+		Pods("default").
+		Get(podID, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if len(pod.Spec.Containers) != len(spec.GetContainers()) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"pod %s has %d containers, spec has %d",
+			podID, len(pod.Spec.Containers), len(spec.GetContainers()))
+	}
+
+	// Only the resources of the containers change. The containers are
+	// matched by name, so a container without a name in the spec, which
+	// got a generated name at launch, cannot be resized.
+	containers := make(map[string]int)
+	for i, c := range pod.Spec.Containers {
+		containers[c.Name] = i
+	}
+	for _, c := range spec.GetContainers() {
+		i, ok := containers[c.GetName()]
+		if !ok || c.GetName() == "" {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"container %q of pod %s not found", c.GetName(), podID)
+		}
+		pod.Spec.Containers[i].Resources = toK8SResourceRequirements(c)
+	}
+
+	_, err = k.kubeClient.CoreV1().Pods("default").Update(pod)
+	return err
+}
+
 // KillPod stops and deletes the given pod
 func (k *K8SManager) KillPod(ctx context.Context, podID string) error {
 	// There is no concept of "stopping" a pod in kubernetes (so nothing like
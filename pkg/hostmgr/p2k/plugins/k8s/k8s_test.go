@@ -20,9 +20,12 @@ import (
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -45,6 +48,8 @@ func (suite *K8SManagerTestSuite) SetupTest() {
 	suite.testKubeClient = testclient.NewSimpleClientset()
 	suite.testManager = newK8sManagerWithClient(
 		suite.testKubeClient,
+		p2kconfig.K8sConfig{},
+		tally.NoopScope,
 		suite.podEventCh,
 		suite.hostEventCh,
 	)
@@ -96,6 +101,58 @@ func (suite *K8SManagerTestSuite) TestLaunchAndKillPod() {
 	suite.True(ok)
 }
 
+func (suite *K8SManagerTestSuite) TestUpdatePodResources() {
+	testPodName := "test_pod"
+	testHostName := "test_host"
+
+	suite.testManager.Start()
+
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+	_, err := suite.testManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+
+	// Resize the pod and verify.
+	newPodSpec := newTestPelotonPodSpec(testPodName)
+	newPodSpec.Containers[0].Resource.CpuLimit = 2.0
+	err = suite.testManager.UpdatePodResources(
+		context.Background(), testPodName, newPodSpec)
+	suite.NoError(err)
+
+	returnedPod, err := suite.
+		testKubeClient.
+		CoreV1().
+		Pods("default").
+		Get(testPodName, metav1.GetOptions{})
+	suite.NoError(err)
+	cpu := returnedPod.Spec.Containers[0].Resources.Limits[corev1.ResourceCPU]
+	suite.Equal(int64(2000), cpu.MilliValue())
+	suite.Equal(testHostName, returnedPod.Spec.NodeName)
+
+	// Resizing a container not in the pod fails.
+	renamedPodSpec := newTestPelotonPodSpec("other_container")
+	err = suite.testManager.UpdatePodResources(
+		context.Background(), testPodName, renamedPodSpec)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// Resizing with a different number of containers fails.
+	newPodSpec.Containers = append(
+		newPodSpec.Containers, newPodSpec.Containers[0])
+	err = suite.testManager.UpdatePodResources(
+		context.Background(), testPodName, newPodSpec)
+	suite.Error(err)
+
+	// Resizing an unknown pod fails.
+	err = suite.testManager.UpdatePodResources(
+		context.Background(), "unknown_pod", newPodSpec)
+	suite.Error(err)
+}
+
 func (suite *K8SManagerTestSuite) TestPodEventHandlers() {
 	testPodName := "test_pod"
 	testHostName := "test_host"
@@ -0,0 +1,47 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import "github.com/uber-go/tally"
+
+type metrics struct {
+	// Informer resync metrics.
+	PodResync  tally.Counter
+	NodeResync tally.Counter
+
+	// Orphan pod garbage collection metrics.
+	PodGC                tally.Counter
+	OrphanPodsFound      tally.Counter
+	OrphanPodsDeleted    tally.Counter
+	OrphanPodsDeleteFail tally.Counter
+	OrphanPodsAdopted    tally.Counter
+	LostPodsFound        tally.Counter
+}
+
+func newMetrics(scope tally.Scope) *metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+
+	return &metrics{
+		PodResync:            scope.Counter("pod_resync"),
+		NodeResync:           scope.Counter("node_resync"),
+		PodGC:                scope.Counter("pod_gc"),
+		OrphanPodsFound:      scope.Counter("orphan_pods_found"),
+		OrphanPodsDeleted:    successScope.Counter("orphan_pods_deleted"),
+		OrphanPodsDeleteFail: failScope.Counter("orphan_pods_deleted"),
+		OrphanPodsAdopted:    scope.Counter("orphan_pods_adopted"),
+		LostPodsFound:        scope.Counter("lost_pods_found"),
+	}
+}
@@ -17,6 +17,7 @@ package k8s
 import (
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
 	"github.com/pborman/uuid"
@@ -34,7 +35,8 @@ const (
 )
 
 // K8S node and pod informers will resync all nodes and pods at this
-// interval. This will be used for reconciliation of pods and hostcache.
+// interval, unless configured otherwise. This will be used for
+// reconciliation of pods and hostcache.
 var _defaultResyncInterval = 30 * time.Second
 
 // Convert peloton container specs to k8s container specs
@@ -78,11 +80,6 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 		cimage = _defaultImageName
 	}
 
-	memMb := c.GetResource().GetMemLimitMb()
-	if memMb < _defaultMinMemMb {
-		memMb = _defaultMinMemMb
-	}
-
 	volumeMounts := []corev1.VolumeMount{}
 	for _, v := range c.GetVolumeMounts() {
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{
@@ -98,28 +95,7 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 		Env:          kEnvs,
 		Ports:        ports,
 		VolumeMounts: volumeMounts,
-		Resources: corev1.ResourceRequirements{
-			Limits: corev1.ResourceList{
-				corev1.ResourceCPU: *resource.NewMilliQuantity(
-					int64(c.GetResource().GetCpuLimit()*1000),
-					resource.DecimalSI,
-				),
-				corev1.ResourceMemory: *resource.NewMilliQuantity(
-					int64(memMb*1000000000),
-					resource.DecimalSI,
-				),
-			},
-			Requests: corev1.ResourceList{
-				corev1.ResourceCPU: *resource.NewMilliQuantity(
-					int64(c.GetResource().GetCpuLimit()*1000),
-					resource.DecimalSI,
-				),
-				corev1.ResourceMemory: *resource.NewMilliQuantity(
-					int64(memMb*1000000000),
-					resource.DecimalSI,
-				),
-			},
-		},
+		Resources:    toK8SResourceRequirements(c),
 	}
 
 	if c.GetEntrypoint().GetValue() != "" {
@@ -130,6 +106,70 @@ func toK8SContainerSpec(c *pbpod.ContainerSpec) corev1.Container {
 	return k8sSpec
 }
 
+// Convert the resources of a peloton container spec to k8s resource
+// requirements.
+func toK8SResourceRequirements(
+	c *pbpod.ContainerSpec,
+) corev1.ResourceRequirements {
+	memMb := c.GetResource().GetMemLimitMb()
+	if memMb < _defaultMinMemMb {
+		memMb = _defaultMinMemMb
+	}
+
+	return corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU: *resource.NewMilliQuantity(
+				int64(c.GetResource().GetCpuLimit()*1000),
+				resource.DecimalSI,
+			),
+			corev1.ResourceMemory: *resource.NewMilliQuantity(
+				int64(memMb*1000000000),
+				resource.DecimalSI,
+			),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU: *resource.NewMilliQuantity(
+				int64(c.GetResource().GetCpuLimit()*1000),
+				resource.DecimalSI,
+			),
+			corev1.ResourceMemory: *resource.NewMilliQuantity(
+				int64(memMb*1000000000),
+				resource.DecimalSI,
+			),
+		},
+	}
+}
+
+// Convert peloton tolerations to k8s tolerations.
+func toK8STolerations(tolerations []*pbpod.Toleration) []corev1.Toleration {
+	var result []corev1.Toleration
+	for _, t := range tolerations {
+		operator := corev1.TolerationOpEqual
+		if t.GetOperator() == pbpod.Toleration_TOLERATION_OPERATOR_EXISTS {
+			operator = corev1.TolerationOpExists
+		}
+		result = append(result, corev1.Toleration{
+			Key:      t.GetKey(),
+			Operator: operator,
+			Value:    t.GetValue(),
+			Effect:   corev1.TaintEffect(t.GetEffect()),
+		})
+	}
+	return result
+}
+
+// Convert peloton node selector labels to k8s node selector.
+func toK8SNodeSelector(labels []*peloton.Label) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for _, l := range labels {
+		result[l.GetKey()] = l.GetValue()
+	}
+	return result
+}
+
 // Convert peloton podspec to k8s podspec.
 func toK8SPodSpec(podSpec *pbpod.PodSpec) *corev1.Pod {
 	// Create pod template spec and apply configurations to spec.
@@ -149,6 +189,8 @@ func toK8SPodSpec(podSpec *pbpod.PodSpec) *corev1.Pod {
 			InitContainers:                toK8SContainerSpecs(podSpec.GetInitContainers()),
 			RestartPolicy:                 "Never",
 			TerminationGracePeriodSeconds: &termGracePeriod,
+			Tolerations:                   toK8STolerations(podSpec.GetTolerations()),
+			NodeSelector:                  toK8SNodeSelector(podSpec.GetNodeSelector()),
 		},
 	}
 
@@ -158,3 +200,36 @@ func toK8SPodSpec(podSpec *pbpod.PodSpec) *corev1.Pod {
 		Spec:       podTemp.Spec,
 	}
 }
+
+// Convert k8s pod to peloton podspec. Only the fields used for the resource
+// accounting of the hosts are converted, this is used to adopt pods which
+// are not launched by this instance of Peloton.
+func toPelotonPodSpec(pod *corev1.Pod) *pbpod.PodSpec {
+	var containers []*pbpod.ContainerSpec
+	for _, c := range pod.Spec.Containers {
+		var ports []*pbpod.PortSpec
+		for _, p := range c.Ports {
+			ports = append(ports, &pbpod.PortSpec{
+				Name:  p.Name,
+				Value: uint32(p.ContainerPort),
+			})
+		}
+
+		containers = append(containers, &pbpod.ContainerSpec{
+			Name:  c.Name,
+			Image: c.Image,
+			Ports: ports,
+			Resource: &pbpod.ResourceSpec{
+				CpuLimit: float64(
+					c.Resources.Limits.Cpu().MilliValue()) / 1000,
+				MemLimitMb: float64(
+					c.Resources.Limits.Memory().MilliValue()) / 1000000000,
+			},
+		})
+	}
+
+	return &pbpod.PodSpec{
+		PodName:    &peloton.PodName{Value: pod.Name},
+		Containers: containers,
+	}
+}
@@ -17,10 +17,12 @@ package k8s
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestToK8SPodSpec(t *testing.T) {
@@ -69,3 +71,53 @@ func TestToK8SPodSpec(t *testing.T) {
 		testPodSpec.Containers[0].VolumeMounts[0].Name,
 	)
 }
+
+// TestToK8SPodSpecTolerationsAndNodeSelector tests that the tolerations
+// and the node selector of a pod are set on the k8s pod spec.
+func TestToK8SPodSpecTolerationsAndNodeSelector(t *testing.T) {
+	require := require.New(t)
+
+	testPodSpec := &pbpod.PodSpec{
+		Containers: []*pbpod.ContainerSpec{
+			{
+				Resource: &pbpod.ResourceSpec{
+					CpuLimit:   1.0,
+					MemLimitMb: 10.0,
+				},
+			},
+		},
+		Tolerations: []*pbpod.Toleration{
+			{
+				Key:      "dedicated",
+				Operator: pbpod.Toleration_TOLERATION_OPERATOR_EQUAL,
+				Value:    "batch",
+				Effect:   "NoSchedule",
+			},
+			{
+				Key:      "gpu",
+				Operator: pbpod.Toleration_TOLERATION_OPERATOR_EXISTS,
+			},
+		},
+		NodeSelector: []*peloton.Label{
+			{Key: "disk", Value: "ssd"},
+		},
+	}
+
+	returnedPod := toK8SPodSpec(testPodSpec)
+	require.Equal([]corev1.Toleration{
+		{
+			Key:      "dedicated",
+			Operator: corev1.TolerationOpEqual,
+			Value:    "batch",
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+		{
+			Key:      "gpu",
+			Operator: corev1.TolerationOpExists,
+		},
+	}, returnedPod.Spec.Tolerations)
+	require.Equal(
+		map[string]string{"disk": "ssd"},
+		returnedPod.Spec.NodeSelector,
+	)
+}
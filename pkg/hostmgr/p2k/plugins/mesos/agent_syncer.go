@@ -20,18 +20,28 @@ import (
 	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 
 	log "github.com/sirupsen/logrus"
 )
 
 const agentChanSize = 10
 
+// agentSnapshot is the agent info synced from mesos in one run.
+type agentSnapshot struct {
+	agents []*mesosmaster.Response_GetAgents_Agent
+
+	// Maintenance status of the hosts in maintenance, keyed by hostname.
+	// Hosts not in the map are up.
+	maintenance map[string]scalar.MaintenanceStatus
+}
+
 // agentSyncer syncs agent info from mesos periodically
 // and send the info via HostEvent through hostEventCh.
 type agentSyncer struct {
 	lf lifecycle.LifeCycle
 
-	agentCh chan []*mesosmaster.Response_GetAgents_Agent
+	agentCh chan *agentSnapshot
 
 	operatorClient  mpb.MasterOperatorClient
 	refreshInterval time.Duration
@@ -45,7 +55,7 @@ func newAgentSyncer(
 		lf:              lifecycle.NewLifeCycle(),
 		operatorClient:  operatorClient,
 		refreshInterval: refreshInterval,
-		agentCh:         make(chan []*mesosmaster.Response_GetAgents_Agent, agentChanSize),
+		agentCh:         make(chan *agentSnapshot, agentChanSize),
 	}
 }
 
@@ -65,7 +75,13 @@ func (a *agentSyncer) Stop() {
 	a.lf.Stop()
 }
 
-func (a *agentSyncer) AgentCh() <-chan []*mesosmaster.Response_GetAgents_Agent {
+// Refresh syncs the agent info from mesos right away, without waiting for
+// the next refresh interval.
+func (a *agentSyncer) Refresh() {
+	a.runOnce()
+}
+
+func (a *agentSyncer) AgentCh() <-chan *agentSnapshot {
 	return a.agentCh
 }
 
@@ -91,8 +107,27 @@ func (a *agentSyncer) runOnce() {
 		return
 	}
 
+	status, err := a.operatorClient.GetMaintenanceStatus()
+	if err != nil {
+		log.WithError(err).Warn("Cannot refresh maintenance status from master")
+		return
+	}
+
+	maintenance := make(map[string]scalar.MaintenanceStatus)
+	for _, machine := range status.GetStatus().GetDrainingMachines() {
+		maintenance[machine.GetId().GetHostname()] = scalar.HostDraining
+	}
+	for _, machine := range status.GetStatus().GetDownMachines() {
+		maintenance[machine.GetHostname()] = scalar.HostDown
+	}
+
+	snapshot := &agentSnapshot{
+		agents:      agents.GetAgents(),
+		maintenance: maintenance,
+	}
+
 	select {
-	case a.agentCh <- agents.GetAgents():
+	case a.agentCh <- snapshot:
 		return
 	default:
 		return
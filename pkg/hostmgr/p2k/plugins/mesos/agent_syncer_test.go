@@ -15,12 +15,15 @@
 package mesos
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
+	mesosmaintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
 	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	mpbmocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
@@ -56,6 +59,11 @@ func (suite *AgentSyncerTestSuite) TestStartStop() {
 		Agents().
 		Return(nil, nil).
 		MinTimes(1)
+	suite.operatorClient.
+		EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, nil).
+		MinTimes(1)
 
 	suite.agentSyncer.Start()
 	suite.agentSyncer.Stop()
@@ -72,6 +80,11 @@ func (suite *AgentSyncerTestSuite) TestMultipleStart() {
 		Agents().
 		Return(nil, nil).
 		Times(1)
+	suite.operatorClient.
+		EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, nil).
+		Times(1)
 
 	suite.agentSyncer.Start()
 	// second call to Start should be a noop
@@ -93,6 +106,11 @@ func (suite *AgentSyncerTestSuite) TestStartForExtendedPeriodOfTime() {
 		Agents().
 		Return(nil, nil).
 		MinTimes(500)
+	suite.operatorClient.
+		EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, nil).
+		MinTimes(500)
 
 	suite.agentSyncer.Start()
 	time.Sleep(1 * time.Second)
@@ -102,6 +120,7 @@ func (suite *AgentSyncerTestSuite) TestStartForExtendedPeriodOfTime() {
 // TestRunOnce tests agent info is sent to channel
 func (suite *AgentSyncerTestSuite) TestRunOnce() {
 	hostname := "hostname1"
+	drainingHostname := "hostname2"
 
 	agent := &mesosmaster.Response_GetAgents{
 		Agents: []*mesosmaster.Response_GetAgents_Agent{
@@ -116,6 +135,15 @@ func (suite *AgentSyncerTestSuite) TestRunOnce() {
 	suite.operatorClient.EXPECT().
 		Agents().
 		Return(agent, nil)
+	suite.operatorClient.EXPECT().
+		GetMaintenanceStatus().
+		Return(&mesosmaster.Response_GetMaintenanceStatus{
+			Status: &mesosmaintenance.ClusterStatus{
+				DrainingMachines: []*mesosmaintenance.ClusterStatus_DrainingMachine{
+					{Id: &mesosv1.MachineID{Hostname: &drainingHostname}},
+				},
+			},
+		}, nil)
 
 	// start lifecycle because runOnce uses
 	// lf.StopCh(), which is initialized when Start()
@@ -123,9 +151,31 @@ func (suite *AgentSyncerTestSuite) TestRunOnce() {
 	suite.agentSyncer.lf.Start()
 	suite.agentSyncer.runOnce()
 
-	agents := <-suite.agentSyncer.AgentCh()
+	snapshot := <-suite.agentSyncer.AgentCh()
+
+	suite.Len(snapshot.agents, 1)
+	suite.Equal(scalar.HostDraining, snapshot.maintenance[drainingHostname])
+	suite.Equal(scalar.HostUp, snapshot.maintenance[hostname])
+}
+
+// TestRunOnceMaintenanceStatusFailure tests agent info is not sent to
+// channel when maintenance status cannot be fetched
+func (suite *AgentSyncerTestSuite) TestRunOnceMaintenanceStatusFailure() {
+	suite.operatorClient.EXPECT().
+		Agents().
+		Return(&mesosmaster.Response_GetAgents{}, nil)
+	suite.operatorClient.EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, errors.New("test error"))
+
+	suite.agentSyncer.lf.Start()
+	suite.agentSyncer.runOnce()
 
-	suite.Len(agents, 1)
+	select {
+	case <-suite.agentSyncer.AgentCh():
+		suite.Fail("no agent info should be sent")
+	default:
+	}
 }
 
 func TestAgentSyncerTestSuite(t *testing.T) {
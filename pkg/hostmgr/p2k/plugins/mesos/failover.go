@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesos
+
+import (
+	"context"
+	"time"
+
+	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+
+	"github.com/uber/peloton/pkg/hostmgr/models"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Disconnected handles the loss of the subscription to the Mesos master,
+// because of a master failover, or because the master sent an ERROR event
+// and closed the stream. The offers held are no longer valid, so they are
+// cleared and the available resources of their hosts are reset.
+func (m *MesosManager) Disconnected() {
+	if !m.disconnected.CAS(false, true) {
+		return
+	}
+	m.metrics.MesosDisconnected.Inc(1)
+
+	hosts := m.offerManager.Clear()
+	for _, host := range hosts {
+		m.hostEventCh <- scalar.BuildHostEventFromResource(
+			host,
+			models.HostResources{},
+			models.HostResources{},
+			scalar.UpdateHostAvailableRes,
+		)
+	}
+
+	log.WithField("hosts_with_offers", len(hosts)).
+		Warn("Disconnected from Mesos master, cleared offers")
+}
+
+// Resubscribed recovers the state of the plugin once the framework is
+// subscribed to a Mesos master again after being disconnected. The agents
+// are synced again, Mesos is asked to send the latest status of all the
+// tasks so that the pods which changed state in the meantime are
+// reconciled, and the offers are revived because a new subscription does
+// not keep the offers suppressed.
+func (m *MesosManager) Resubscribed() {
+	if !m.disconnected.CAS(true, false) {
+		return
+	}
+	m.metrics.MesosFailover.Inc(1)
+
+	m.suppressed.Store(false)
+	m.lastDemand.Store(time.Now().UnixNano())
+
+	m.agentSyncer.Refresh()
+
+	if err := m.reconcileTasks(); err != nil {
+		m.metrics.ReconcileTasksFail.Inc(1)
+		log.WithError(err).
+			Warn("Failed to reconcile tasks after Mesos failover")
+		return
+	}
+	m.metrics.ReconcileTasks.Inc(1)
+
+	log.Info("Resubscribed to Mesos master, reconciling tasks")
+}
+
+// reconcileTasks asks Mesos to send the latest status update of all of the
+// tasks of the framework through implicit reconciliation.
+func (m *MesosManager) reconcileTasks() error {
+	ctx := context.Background()
+	callType := sched.Call_RECONCILE
+	msg := &sched.Call{
+		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		Reconcile:   &sched.Call_Reconcile{},
+	}
+
+	return m.schedulerClient.Call(
+		m.frameworkInfoProvider.GetMesosStreamID(ctx),
+		msg,
+	)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesos
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// killRetryPeriod is how often the kills which are due are sent again.
+const killRetryPeriod = time.Second
+
+// Default backoff of the kill retries, used when they are not configured.
+const (
+	defaultKillRetryInitialBackoff = 5 * time.Second
+	defaultKillRetryMaxBackoff     = 2 * time.Minute
+	defaultKillRetryMaxAttempts    = 10
+)
+
+// killRequest is the kill of a pod which is not confirmed by a terminal
+// status update yet.
+type killRequest struct {
+	// attempts is the number of times the kill has been sent.
+	attempts int
+	// nextAttempt is when the kill is sent again.
+	nextAttempt time.Time
+}
+
+// killQueue keeps the kills of the pods until a terminal status update is
+// received for them, so that kills lost because of transient errors of the
+// scheduler stream, or dropped by Mesos, are sent again. Each pod is in the
+// queue at most once.
+type killQueue struct {
+	sync.Mutex
+
+	requests map[string]*killRequest
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxAttempts    int
+}
+
+// newKillQueue returns a kill queue, using the default backoff for the
+// values which are not set.
+func newKillQueue(
+	initialBackoff time.Duration,
+	maxBackoff time.Duration,
+	maxAttempts int,
+) *killQueue {
+	if initialBackoff <= 0 {
+		initialBackoff = defaultKillRetryInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultKillRetryMaxBackoff
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultKillRetryMaxAttempts
+	}
+	return &killQueue{
+		requests:       make(map[string]*killRequest),
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		maxAttempts:    maxAttempts,
+	}
+}
+
+// add records an attempt to kill a pod, adding the pod to the queue if it
+// is not in it yet.
+func (q *killQueue) add(podID string, now time.Time) {
+	q.Lock()
+	defer q.Unlock()
+
+	r, ok := q.requests[podID]
+	if !ok {
+		r = &killRequest{}
+		q.requests[podID] = r
+	}
+	r.attempts++
+	r.nextAttempt = now.Add(q.backoff(r.attempts))
+}
+
+// remove removes a pod from the queue, and returns whether it was in it.
+func (q *killQueue) remove(podID string) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	_, ok := q.requests[podID]
+	delete(q.requests, podID)
+	return ok
+}
+
+// due returns the pods whose kill is to be sent again, and removes from
+// the queue the pods whose kill has been sent the maximum number of times.
+func (q *killQueue) due(now time.Time) (retry []string, expired []string) {
+	q.Lock()
+	defer q.Unlock()
+
+	for podID, r := range q.requests {
+		if now.Before(r.nextAttempt) {
+			continue
+		}
+		if r.attempts >= q.maxAttempts {
+			delete(q.requests, podID)
+			expired = append(expired, podID)
+			continue
+		}
+		retry = append(retry, podID)
+	}
+	return retry, expired
+}
+
+// size returns the number of pods in the queue.
+func (q *killQueue) size() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return len(q.requests)
+}
+
+// backoff returns how long to wait after the given number of attempts
+// before sending a kill again.
+func (q *killQueue) backoff(attempts int) time.Duration {
+	backoff := q.initialBackoff
+	for i := 1; i < attempts && backoff < q.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > q.maxBackoff {
+		backoff = q.maxBackoff
+	}
+	return backoff
+}
+
+// sendKill sends the kill of a pod to Mesos. The kill is sent to the agent
+// running the pod if it is known, so that Mesos can forward it even if the
+// master lost track of the task.
+func (m *MesosManager) sendKill(ctx context.Context, podID string) error {
+	callType := sched.Call_KILL
+	msg := &sched.Call{
+		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		Kill: &sched.Call_Kill{
+			TaskId: &mesos.TaskID{Value: &podID},
+		},
+	}
+	if agentID, ok := m.podAgents.Load(podID); ok {
+		value := agentID.(string)
+		msg.Kill.AgentId = &mesos.AgentID{Value: &value}
+	}
+
+	return m.schedulerClient.Call(
+		m.frameworkInfoProvider.GetMesosStreamID(ctx),
+		msg,
+	)
+}
+
+// isPodAgentDown returns whether the agent running a pod is down for
+// maintenance. The pods of such an agent cannot be killed, Mesos reports
+// them as gone once the agent is removed.
+func (m *MesosManager) isPodAgentDown(podID string) bool {
+	agentID, ok := m.podAgents.Load(podID)
+	if !ok {
+		return false
+	}
+	hostname, ok := m.agentIDToHostname.Load(agentID)
+	if !ok {
+		return false
+	}
+	status, ok := m.hostMaintenance.Load(hostname)
+	return ok && status.(scalar.MaintenanceStatus) == scalar.HostDown
+}
+
+// startKillRetries periodically sends again the kills which are not
+// confirmed by a terminal status update.
+func (m *MesosManager) startKillRetries() {
+	go func() {
+		ticker := time.NewTicker(killRetryPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.retryKills()
+			case <-m.lf.StopCh():
+				return
+			}
+		}
+	}()
+}
+
+// retryKills sends again the kills which are due, and gives up on the ones
+// which have been sent the maximum number of times.
+func (m *MesosManager) retryKills() {
+	retry, expired := m.killQueue.due(time.Now())
+
+	for _, podID := range expired {
+		m.metrics.KillPodGiveUp.Inc(1)
+		log.WithField("pod_id", podID).
+			Warn("Giving up killing pod, no terminal status update received")
+	}
+
+	for _, podID := range retry {
+		if m.isPodAgentDown(podID) {
+			m.killQueue.remove(podID)
+			m.metrics.KillPodAgentDown.Inc(1)
+			continue
+		}
+
+		m.metrics.KillPodRetry.Inc(1)
+		err := m.sendKill(context.Background(), podID)
+		m.killQueue.add(podID, time.Now())
+		if err != nil {
+			m.metrics.KillPodFail.Inc(1)
+			log.WithError(err).
+				WithField("pod_id", podID).
+				Warn("Failed to retry killing pod")
+			continue
+		}
+		m.metrics.KillPod.Inc(1)
+	}
+
+	m.metrics.KillQueueSize.Update(float64(m.killQueue.size()))
+}
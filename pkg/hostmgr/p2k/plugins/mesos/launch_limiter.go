@@ -0,0 +1,204 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesos
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// defaultInFlightLaunchTimeout is how long a pod counts against the launch
+// limit of its agent when no status is reported for it, used when it is
+// not configured.
+const defaultInFlightLaunchTimeout = 2 * time.Minute
+
+// hostLaunches are the pods being launched on a host.
+type hostLaunches struct {
+	// pods maps the ID of the pods being launched to when they were
+	// launched.
+	pods map[string]time.Time
+	// released is closed when pods stop being launched on the host, to
+	// wake up the launches queued for it.
+	released chan struct{}
+}
+
+// launchLimiter limits the number of pods being launched on each agent,
+// i.e. the pods launched which the agent has not reported a status for
+// yet, so that a burst of launches does not overwhelm an agent and cause
+// its tasks to be lost. Launches exceeding the limit are queued until the
+// agent reports the status of the pods launched before.
+type launchLimiter struct {
+	sync.Mutex
+
+	// maxInFlight is the maximum number of pods being launched on an
+	// agent, 0 for no limit.
+	maxInFlight int
+	// timeout is how long a pod counts against the limit of its agent
+	// when no status is reported for it.
+	timeout time.Duration
+
+	// hosts maps hostnames to the pods being launched on them.
+	hosts map[string]*hostLaunches
+	// podHosts maps the ID of the pods being launched to their hostname.
+	podHosts map[string]string
+
+	metrics *metrics
+}
+
+// newLaunchLimiter returns a launch limiter, using the default timeout if
+// it is not set.
+func newLaunchLimiter(
+	maxInFlight int,
+	timeout time.Duration,
+	metrics *metrics,
+) *launchLimiter {
+	if timeout <= 0 {
+		timeout = defaultInFlightLaunchTimeout
+	}
+	return &launchLimiter{
+		maxInFlight: maxInFlight,
+		timeout:     timeout,
+		hosts:       make(map[string]*hostLaunches),
+		podHosts:    make(map[string]string),
+		metrics:     metrics,
+	}
+}
+
+// acquire waits until the pods can be launched on the host without
+// exceeding the limit, and records them as being launched. A launch of
+// more pods than the limit is let through once no pod is being launched
+// on the host. It returns an error if ctx is done first.
+func (l *launchLimiter) acquire(
+	ctx context.Context,
+	hostname string,
+	podIDs []string,
+) error {
+	if l.maxInFlight <= 0 || len(podIDs) == 0 {
+		return nil
+	}
+
+	var queuedAt time.Time
+	for {
+		now := time.Now()
+
+		l.Lock()
+		h := l.getHost(hostname)
+		nextExpiration := l.expire(h, now)
+		if len(h.pods) == 0 || len(h.pods)+len(podIDs) <= l.maxInFlight {
+			for _, podID := range podIDs {
+				h.pods[podID] = now
+				l.podHosts[podID] = hostname
+			}
+			l.metrics.InFlightLaunches.Update(float64(len(l.podHosts)))
+			l.Unlock()
+
+			if !queuedAt.IsZero() {
+				l.metrics.LaunchPodThrottleWait.Record(now.Sub(queuedAt))
+			}
+			return nil
+		}
+		released := h.released
+		l.Unlock()
+
+		if queuedAt.IsZero() {
+			queuedAt = now
+			l.metrics.LaunchPodThrottled.Inc(1)
+		}
+
+		timer := time.NewTimer(nextExpiration.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.metrics.LaunchPodThrottleFail.Inc(1)
+			return yarpcerrors.ResourceExhaustedErrorf(
+				"too many pods being launched on %s: %v", hostname, ctx.Err())
+		case <-released:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// release stops counting the pods against the limit of their agent, once
+// their launch failed or the agent reported their status.
+func (l *launchLimiter) release(podIDs ...string) {
+	if l.maxInFlight <= 0 {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	for _, podID := range podIDs {
+		hostname, ok := l.podHosts[podID]
+		if !ok {
+			continue
+		}
+		delete(l.podHosts, podID)
+
+		h := l.hosts[hostname]
+		delete(h.pods, podID)
+		l.wake(h)
+		if len(h.pods) == 0 {
+			delete(l.hosts, hostname)
+		}
+	}
+	l.metrics.InFlightLaunches.Update(float64(len(l.podHosts)))
+}
+
+// getHost returns the pods being launched on the host, adding the host if
+// needed. It must be called with the lock held.
+func (l *launchLimiter) getHost(hostname string) *hostLaunches {
+	h, ok := l.hosts[hostname]
+	if !ok {
+		h = &hostLaunches{
+			pods:     make(map[string]time.Time),
+			released: make(chan struct{}),
+		}
+		l.hosts[hostname] = h
+	}
+	return h
+}
+
+// expire stops counting the pods which timed out against the limit of the
+// host, and returns when the next pod being launched on the host times
+// out. It must be called with the lock held.
+func (l *launchLimiter) expire(h *hostLaunches, now time.Time) time.Time {
+	next := now.Add(l.timeout)
+	for podID, launchedAt := range h.pods {
+		expiration := launchedAt.Add(l.timeout)
+		if !now.Before(expiration) {
+			delete(h.pods, podID)
+			delete(l.podHosts, podID)
+			l.metrics.InFlightLaunchesExpired.Inc(1)
+			l.wake(h)
+			continue
+		}
+		if expiration.Before(next) {
+			next = expiration
+		}
+	}
+	return next
+}
+
+// wake wakes up the launches queued for the host. It must be called with
+// the lock held.
+func (l *launchLimiter) wake(h *hostLaunches) {
+	close(h.released)
+	h.released = make(chan struct{})
+}
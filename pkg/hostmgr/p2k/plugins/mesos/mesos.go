@@ -16,13 +16,15 @@ package mesos
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
-	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
 	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
@@ -33,18 +35,63 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/factory/task"
 	hostmgrmesos "github.com/uber/peloton/pkg/hostmgr/mesos"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
+	"github.com/uber/peloton/pkg/hostmgr/metadata"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	uatomic "github.com/uber-go/atomic"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
+// mesosTaskUpdateAckChanSize is the default size of the buffer of the
+// pod events to be acknowledged.
 const mesosTaskUpdateAckChanSize = 1000
 
+// defaultUpdateAckConcurrency is the default number of workers which
+// acknowledge the pod events.
+const defaultUpdateAckConcurrency = 10
+
+// ackMetricsPeriod is how often the depth of the ack queue is reported.
+const ackMetricsPeriod = 10 * time.Second
+
+// Resources of the default executor running the tasks of a pod launched
+// as a task group.
+const (
+	_taskGroupExecutorCPU   = 0.1
+	_taskGroupExecutorMemMb = 32
+)
+
+// _taskGroupExecutorIDSuffix is appended to the ID of a pod launched as a
+// task group to get the ID of its executor.
+const _taskGroupExecutorIDSuffix = "-executor"
+
+// _sidecarTaskIDSeparator separates the ID of a pod and the name of a
+// sidecar in the ID of the Mesos task running the sidecar. Peloton pod IDs
+// never contain it.
+const _sidecarTaskIDSeparator = "."
+
+// suppressCheckPeriod is how often the framework checks whether it has
+// been idle for long enough to suppress offers.
+const suppressCheckPeriod = 10 * time.Second
+
+// offerPruningPeriod is how often the offers held for longer than the
+// offer hold time are declined.
+const offerPruningPeriod = 5 * time.Second
+
+// offerWaitPollPeriod is how often LaunchPods checks for new offers on a
+// host which has none.
+const offerWaitPollPeriod = 100 * time.Millisecond
+
+// offerWaitTimeout is how long LaunchPods waits for the next offer cycle
+// on a host which has no offers and no reservation.
+const offerWaitTimeout = offerPruningPeriod
+
 // MesosManager implements the plugin for the Mesos cluster manager.
 type MesosManager struct {
 	// dispatcher for yarpc
@@ -60,6 +107,13 @@ type MesosManager struct {
 
 	offerManager *offerManager
 
+	// config of how offers are declined
+	config p2kconfig.MesosConfig
+
+	// offerStats accumulates how long offers are held and how much of
+	// them is used
+	offerStats offerStats
+
 	frameworkInfoProvider hostmgrmesos.FrameworkInfoProvider
 
 	schedulerClient mpb.SchedulerClient
@@ -87,6 +141,45 @@ type MesosManager struct {
 	// by digesting host agent info, and looks up corresponding hostname with
 	// the agentID when an event comes in.
 	agentIDToHostname sync.Map
+
+	// metadataRegistry keeps the metadata of the launched tasks served by
+	// the task metadata endpoint. The metadata of the tasks is not kept if
+	// it is nil.
+	metadataRegistry *metadata.Registry
+
+	// lastDemand is the unix nano time at which pods were last waiting to
+	// be placed.
+	lastDemand uatomic.Int64
+
+	// pendingLaunches is the number of LaunchPods calls in progress.
+	pendingLaunches uatomic.Int64
+
+	// suppressed is whether Mesos has been asked to stop sending offers.
+	suppressed uatomic.Bool
+
+	// disconnected is whether the subscription to the Mesos master was
+	// lost, and the plugin has to recover once it is subscribed again.
+	disconnected uatomic.Bool
+
+	// statefulPods is the set of stateful pods launched, keyed by pod ID.
+	// The reservations of the stateful pods which are not in the set are
+	// cleaned up when they are offered.
+	statefulPods sync.Map
+
+	// podAgents maps the ID of the pods launched or running to the ID of
+	// the agent running them, so that kills are sent to the agent.
+	podAgents sync.Map
+
+	// hostMaintenance maps hostnames to their maintenance status.
+	hostMaintenance sync.Map
+
+	// killQueue keeps the kills of the pods until they are confirmed by a
+	// terminal status update.
+	killQueue *killQueue
+
+	// launchLimiter queues the launches on an agent while too many pods
+	// are being launched on it.
+	launchLimiter *launchLimiter
 }
 
 func NewMesosManager(
@@ -96,11 +189,22 @@ func NewMesosManager(
 	operatorClient mpb.MasterOperatorClient,
 	agentInfoRefreshInterval time.Duration,
 	offerHoldTime time.Duration,
+	updateAckConcurrency int,
+	updateAckBufferSize int,
+	config p2kconfig.MesosConfig,
 	scope tally.Scope,
 	podEventCh chan<- *scalar.PodEvent,
 	hostEventCh chan<- *scalar.HostEvent,
+	metadataRegistry *metadata.Registry,
 ) *MesosManager {
-	return &MesosManager{
+	if updateAckConcurrency <= 0 {
+		updateAckConcurrency = defaultUpdateAckConcurrency
+	}
+	if updateAckBufferSize <= 0 {
+		updateAckBufferSize = mesosTaskUpdateAckChanSize
+	}
+
+	m := &MesosManager{
 		d:                     d,
 		lf:                    lifecycle.NewLifeCycle(),
 		metrics:               newMetrics(scope.SubScope("mesos_manager")),
@@ -108,14 +212,29 @@ func NewMesosManager(
 		schedulerClient:       schedulerClient,
 		podEventCh:            podEventCh,
 		hostEventCh:           hostEventCh,
-		offerManager:          newOfferManager(offerHoldTime),
-		ackChannel:            make(chan *scalar.PodEvent, mesosTaskUpdateAckChanSize),
+		offerManager:          newOfferManager(offerHoldTime, config.MaxHostsWithOffers),
+		config:                config,
+		ackChannel:            make(chan *scalar.PodEvent, updateAckBufferSize),
+		updateAckConcurrency:  updateAckConcurrency,
 		once:                  sync.Once{},
 		agentSyncer: newAgentSyncer(
 			operatorClient,
 			agentInfoRefreshInterval,
 		),
+		metadataRegistry: metadataRegistry,
+		killQueue: newKillQueue(
+			config.KillRetryInitialBackoff,
+			config.KillRetryMaxBackoff,
+			config.KillRetryMaxAttempts,
+		),
 	}
+	m.launchLimiter = newLaunchLimiter(
+		config.MaxInFlightLaunchesPerAgent,
+		config.InFlightLaunchTimeout,
+		m.metrics,
+	)
+	m.lastDemand.Store(time.Now().UnixNano())
+	return m
 }
 
 // Start the plugin.
@@ -142,6 +261,9 @@ func (m *MesosManager) Start() error {
 	m.agentSyncer.Start()
 	m.startProcessAgentInfo(m.agentSyncer.AgentCh())
 	m.startAsyncProcessTaskUpdates()
+	m.startOfferPruning()
+	m.startOfferSuppression()
+	m.startKillRetries()
 	return nil
 }
 
@@ -167,16 +289,40 @@ func (m *MesosManager) LaunchPods(
 	var mesosResources []*mesos.Resource
 	var mesosTasks []*mesos.TaskInfo
 	var mesosTaskIds []string
+	var usedResources hmscalar.Resources
 
-	offers := m.offerManager.GetOffers(hostname)
+	m.pendingLaunches.Inc()
+	defer m.pendingLaunches.Dec()
+
+	podIDs := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		podIDs = append(podIDs, pod.PodId.GetValue())
+	}
+	if err := m.launchLimiter.acquire(ctx, hostname, podIDs); err != nil {
+		return nil, err
+	}
+	launched := false
+	defer func() {
+		if !launched {
+			m.launchLimiter.release(podIDs...)
+		}
+	}()
+
+	offers, err := m.waitForOffers(ctx, hostname)
+	if err != nil {
+		m.metrics.LaunchPodNoOffer.Inc(1)
+		return nil, err
+	}
+	offerAges := m.offerManager.GetOfferAges(hostname)
 
 	for _, offer := range offers {
 		offerIds = append(offerIds, offer.GetId())
 		mesosResources = append(mesosResources, offer.GetResources()...)
 	}
 
-	if len(offerIds) == 0 {
-		return nil, yarpcerrors.InternalErrorf("no offer found to launch pods on %s", hostname)
+	if err := assignDynamicPorts(pods, mesosResources); err != nil {
+		return nil, yarpcerrors.ResourceExhaustedErrorf(
+			"cannot launch pods on %s: %v", hostname, err)
 	}
 
 	builder := task.NewBuilder(mesosResources)
@@ -184,41 +330,96 @@ func (m *MesosManager) LaunchPods(
 	// i.e. agentID is the same for all offers from the same host
 	agentID := offers[offerIds[0].GetValue()].GetAgentId()
 
+	var groupOperations, volumeOperations []*mesos.Offer_Operation
+	var statefulPodIDs []string
 	for _, pod := range pods {
-		launchableTask, err := convertPodSpecToLaunchableTask(pod.PodId, pod.Spec, pod.Ports)
-		if err != nil {
-			return nil, err
+		// A pod with sidecars is launched as a task group, so that all of
+		// its containers are launched atomically.
+		if len(pod.Spec.GetContainers()) > 1 {
+			if isStatefulPod(pod.Spec) {
+				m.unregisterMetadata(mesosTaskIds)
+				return nil, yarpcerrors.InvalidArgumentErrorf(
+					"persistent volume is not supported for pod %s with sidecars",
+					pod.PodId.GetValue())
+			}
+			op, err := m.buildLaunchGroupOperation(ctx, builder, pod, agentID)
+			if err != nil {
+				// The token of the main container may have been issued
+				// before a sidecar failed to build.
+				m.unregisterMetadata(append(
+					mesosTaskIds, pod.PodId.GetValue()))
+				return nil, err
+			}
+			groupOperations = append(groupOperations, op)
+			mesosTaskIds = append(mesosTaskIds, pod.PodId.GetValue())
+
+			launchGroup := op.GetLaunchGroup()
+			for _, mesosTask := range launchGroup.GetTaskGroup().GetTasks() {
+				usedResources = usedResources.Add(
+					hmscalar.FromMesosResources(mesosTask.GetResources()))
+			}
+			usedResources = usedResources.Add(hmscalar.FromMesosResources(
+				launchGroup.GetExecutor().GetResources()))
+			continue
 		}
 
-		mesosTask, err := builder.Build(launchableTask)
+		launchableTask, mesosTask, err := buildMesosTask(
+			builder, pod.PodId, pod.Spec, pod.Ports, agentID)
 		if err != nil {
+			m.unregisterMetadata(mesosTaskIds)
+			return nil, err
+		}
+		if err := m.addMetadataToken(launchableTask, mesosTask); err != nil {
+			m.unregisterMetadata(mesosTaskIds)
 			return nil, err
 		}
-		mesosTask.AgentId = agentID
+		// The resources of a stateful pod are reserved, and its persistent
+		// volume created, right before it is launched.
+		if isStatefulPod(pod.Spec) {
+			ops, err := m.buildVolumeOperations(
+				builder, pod.PodId.GetValue(), pod.Spec, mesosTask)
+			if err != nil {
+				m.unregisterMetadata(append(
+					mesosTaskIds, mesosTask.GetTaskId().GetValue()))
+				return nil, yarpcerrors.ResourceExhaustedErrorf(
+					"cannot create persistent volume for pod %s on %s: %v",
+					pod.PodId.GetValue(), hostname, err)
+			}
+			volumeOperations = append(volumeOperations, ops...)
+			statefulPodIDs = append(statefulPodIDs, pod.PodId.GetValue())
+		}
 		mesosTasks = append(mesosTasks, mesosTask)
 		mesosTaskIds = append(mesosTaskIds, mesosTask.GetTaskId().GetValue())
+		usedResources = usedResources.
+			Add(hmscalar.FromMesosResources(mesosTask.GetResources())).
+			Add(hmscalar.FromMesosResources(
+				mesosTask.GetExecutor().GetResources()))
+	}
+
+	operations := volumeOperations
+	if len(mesosTasks) != 0 {
+		opType := mesos.Offer_Operation_LAUNCH
+		operations = append(operations, &mesos.Offer_Operation{
+			Type: &opType,
+			Launch: &mesos.Offer_Operation_Launch{
+				TaskInfos: mesosTasks,
+			},
+		})
 	}
+	operations = append(operations, groupOperations...)
 
 	callType := sched.Call_ACCEPT
-	opType := mesos.Offer_Operation_LAUNCH
 	msg := &sched.Call{
 		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
 		Type:        &callType,
 		Accept: &sched.Call_Accept{
-			OfferIds: offerIds,
-			Operations: []*mesos.Offer_Operation{
-				{
-					Type: &opType,
-					Launch: &mesos.Offer_Operation_Launch{
-						TaskInfos: mesosTasks,
-					},
-				},
-			},
+			OfferIds:   offerIds,
+			Operations: operations,
 		},
 	}
 
 	msid := m.frameworkInfoProvider.GetMesosStreamID(ctx)
-	err := m.schedulerClient.Call(msid, msg)
+	err = m.schedulerClient.Call(msid, msg)
 
 	if err != nil {
 		// Decline offers upon launch failure in a best effort manner,
@@ -230,21 +431,404 @@ func (m *MesosManager) LaunchPods(
 		// It is still a best effort way to clean offers up, peloton still
 		// rely on offer expiration to clean up the offers left behind.
 		m.offerManager.RemoveOfferForHost(hostname)
-		m.declineOffers(ctx, offerIds)
+		m.declineOffers(ctx, offerIds, 0)
+		m.unregisterMetadata(mesosTaskIds)
 		m.metrics.LaunchPodFail.Inc(1)
 		return nil, err
 	}
 	// call to mesos is successful,
 	// remove the offers so no new task would be placed
 	m.offerManager.RemoveOfferForHost(hostname)
+	for _, podID := range statefulPodIDs {
+		m.statefulPods.Store(podID, hostname)
+	}
+	for _, pod := range pods {
+		m.podAgents.Store(pod.PodId.GetValue(), agentID.GetValue())
+	}
+	launched = true
+	m.metrics.LaunchStatefulPod.Inc(int64(len(statefulPodIDs)))
 	m.metrics.LaunchPod.Inc(1)
+	m.recordOfferUsage(
+		offerAges,
+		hmscalar.FromMesosResources(mesosResources),
+		usedResources)
 	return pods, nil
 }
 
+// buildLaunchGroupOperation builds the operation launching a pod with
+// sidecars as a Mesos task group run by the default executor. The main
+// container is launched as the task with the ID of the pod, and each
+// sidecar as a task with an ID derived from the ID of the pod. The ports
+// of the pod are assigned to the main container.
+func (m *MesosManager) buildLaunchGroupOperation(
+	ctx context.Context,
+	builder *task.Builder,
+	pod *models.LaunchablePod,
+	agentID *mesos.AgentID,
+) (*mesos.Offer_Operation, error) {
+	executorResources, err := builder.ExtractResources(
+		&pbtask.ResourceConfig{
+			CpuLimit:   _taskGroupExecutorCPU,
+			MemLimitMb: _taskGroupExecutorMemMb,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var mesosTasks []*mesos.TaskInfo
+	for i, container := range pod.Spec.GetContainers() {
+		spec := *pod.Spec
+		spec.Containers = []*pbpod.ContainerSpec{container}
+		spec.InitContainers = nil
+
+		ports := pod.Ports
+		if i != 0 {
+			ports = nil
+		}
+
+		launchableTask, mesosTask, err := buildMesosTask(
+			builder, pod.PodId, &spec, ports, agentID)
+		if err != nil {
+			return nil, err
+		}
+		// The tasks of a task group are run by the executor of the group.
+		mesosTask.Executor = nil
+		if i == 0 {
+			if err := m.addMetadataToken(launchableTask, mesosTask); err != nil {
+				return nil, err
+			}
+		} else {
+			sidecarID := getSidecarTaskID(
+				pod.PodId.GetValue(), container.GetName())
+			mesosTask.TaskId = &mesos.TaskID{Value: &sidecarID}
+		}
+		mesosTasks = append(mesosTasks, mesosTask)
+	}
+
+	executorType := mesos.ExecutorInfo_DEFAULT
+	executorID := pod.PodId.GetValue() + _taskGroupExecutorIDSuffix
+	opType := mesos.Offer_Operation_LAUNCH_GROUP
+	return &mesos.Offer_Operation{
+		Type: &opType,
+		LaunchGroup: &mesos.Offer_Operation_LaunchGroup{
+			Executor: &mesos.ExecutorInfo{
+				Type:        &executorType,
+				ExecutorId:  &mesos.ExecutorID{Value: &executorID},
+				FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
+				Resources:   executorResources,
+			},
+			TaskGroup: &mesos.TaskGroupInfo{
+				Tasks: mesosTasks,
+			},
+		},
+	}, nil
+}
+
+// buildMesosTask builds the Mesos task launching a pod on an agent.
+func buildMesosTask(
+	builder *task.Builder,
+	id *peloton.PodID,
+	spec *pbpod.PodSpec,
+	ports map[string]uint32,
+	agentID *mesos.AgentID,
+) (*hostsvc.LaunchableTask, *mesos.TaskInfo, error) {
+	launchableTask, err := convertPodSpecToLaunchableTask(id, spec, ports)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mesosTask, err := builder.Build(launchableTask)
+	if err != nil {
+		return nil, nil, err
+	}
+	mesosTask.AgentId = agentID
+	return launchableTask, mesosTask, nil
+}
+
+// getSidecarTaskID returns the ID of the Mesos task running a sidecar of
+// a pod launched as a task group.
+func getSidecarTaskID(podID string, containerName string) string {
+	return podID + _sidecarTaskIDSeparator + containerName
+}
+
+// isSidecarTaskID returns whether a Mesos task runs a sidecar of a pod,
+// rather than its main container.
+func isSidecarTaskID(taskID string) bool {
+	return strings.Contains(taskID, _sidecarTaskIDSeparator)
+}
+
+// ReserveOffers pins the offers on a host for a placement decision, so
+// that they are not declined before the pods placed on the host are
+// launched. The reservation is released when the pods are launched, or
+// when the ttl elapses.
+func (m *MesosManager) ReserveOffers(hostname string, ttl time.Duration) {
+	m.offerManager.ReserveOffers(hostname, ttl)
+}
+
+// ReleaseOffers releases the reservation pinning the offers on a host.
+func (m *MesosManager) ReleaseOffers(hostname string) {
+	m.offerManager.ReleaseReservation(hostname)
+}
+
+// RecordDemand records that there are pods waiting to be placed, and
+// revives offers if they were suppressed.
+func (m *MesosManager) RecordDemand() {
+	m.lastDemand.Store(time.Now().UnixNano())
+
+	if m.suppressed.CAS(true, false) {
+		if err := m.callOffers(sched.Call_REVIVE); err != nil {
+			// try again on the next demand
+			m.suppressed.Store(true)
+			m.metrics.ReviveOffersFail.Inc(1)
+			return
+		}
+		m.metrics.ReviveOffers.Inc(1)
+	}
+}
+
+// startOfferSuppression periodically asks Mesos to stop sending offers
+// once the framework has had no pods to place or launch for the suppress
+// idle timeout.
+func (m *MesosManager) startOfferSuppression() {
+	if m.config.SuppressIdleTimeout <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(suppressCheckPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.suppressIfIdle()
+			case <-m.lf.StopCh():
+				return
+			}
+		}
+	}()
+}
+
+// suppressIfIdle suppresses offers if there are no pods being launched
+// and no pods have been waiting to be placed for the suppress idle timeout.
+func (m *MesosManager) suppressIfIdle() {
+	if m.pendingLaunches.Load() > 0 {
+		return
+	}
+
+	idle := time.Since(time.Unix(0, m.lastDemand.Load()))
+	if idle < m.config.SuppressIdleTimeout {
+		return
+	}
+
+	if m.suppressed.CAS(false, true) {
+		if err := m.callOffers(sched.Call_SUPPRESS); err != nil {
+			// try again on the next check
+			m.suppressed.Store(false)
+			m.metrics.SuppressOffersFail.Inc(1)
+			return
+		}
+		m.metrics.SuppressOffers.Inc(1)
+	}
+}
+
+// callOffers sends a SUPPRESS or REVIVE call to Mesos.
+func (m *MesosManager) callOffers(callType sched.Call_Type) error {
+	ctx := context.Background()
+	msg := &sched.Call{
+		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+	}
+	switch callType {
+	case sched.Call_SUPPRESS:
+		msg.Suppress = &sched.Call_Suppress{}
+	case sched.Call_REVIVE:
+		msg.Revive = &sched.Call_Revive{}
+	}
+
+	msid := m.frameworkInfoProvider.GetMesosStreamID(ctx)
+	if err := m.schedulerClient.Call(msid, msg); err != nil {
+		log.WithError(err).
+			WithField("call_type", callType.String()).
+			Warn("Failed to suppress or revive offers")
+		return err
+	}
+
+	log.WithField("call_type", callType.String()).
+		Info("Suppressed or revived offers")
+	return nil
+}
+
+// waitForOffers returns the offers on a host. If the host has no offers,
+// because they were rescinded or used after the placement decision, it
+// waits for the next offer cycle rather than failing the launch. The wait
+// is bounded by the reservation on the host, or by offerWaitTimeout if the
+// host is not reserved, and by the deadline of the context.
+func (m *MesosManager) waitForOffers(
+	ctx context.Context,
+	hostname string,
+) (map[string]*mesos.Offer, error) {
+	if offers := m.offerManager.GetOffers(hostname); len(offers) != 0 {
+		return offers, nil
+	}
+
+	m.metrics.LaunchPodOfferWait.Inc(1)
+	deadline, ok := m.offerManager.GetReservation(hostname)
+	if !ok {
+		deadline = time.Now().Add(offerWaitTimeout)
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	ticker := time.NewTicker(offerWaitPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if offers := m.offerManager.GetOffers(hostname); len(offers) != 0 {
+				return offers, nil
+			}
+		case <-timer.C:
+			return nil, yarpcerrors.UnavailableErrorf(
+				"no offer found to launch pods on %s", hostname)
+		case <-ctx.Done():
+			return nil, yarpcerrors.UnavailableErrorf(
+				"no offer found to launch pods on %s: %v", hostname, ctx.Err())
+		}
+	}
+}
+
+// recordOfferUsage records how long the offers used by a launch were held
+// for, and the fraction of their resources the launch used.
+func (m *MesosManager) recordOfferUsage(
+	offerAges []time.Duration,
+	offered hmscalar.Resources,
+	used hmscalar.Resources,
+) {
+	for _, age := range offerAges {
+		m.metrics.OfferAgeAtLaunch.Record(age)
+	}
+
+	utilization := getUtilization(offered, used)
+	for kind, fraction := range utilization {
+		m.metrics.offerUtilization(kind).RecordValue(fraction)
+	}
+	m.offerStats.recordLaunch(offerAges, utilization)
+}
+
+// startOfferPruning periodically declines the offers which have been held
+// for longer than the offer hold time without being used.
+func (m *MesosManager) startOfferPruning() {
+	if m.offerManager.offerHoldTime <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(offerPruningPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.pruneExpiredOffers()
+			case <-m.lf.StopCh():
+				return
+			}
+		}
+	}()
+}
+
+// pruneExpiredOffers declines the offers which have been held for longer
+// than the offer hold time, and updates the resources of their hosts.
+func (m *MesosManager) pruneExpiredOffers() {
+	offerIDs, hosts := m.offerManager.RemoveExpiredOffers()
+	if len(offerIDs) == 0 {
+		return
+	}
+
+	m.metrics.OffersExpired.Inc(int64(len(offerIDs)))
+	m.offerStats.recordExpired(len(offerIDs))
+	m.declineOffers(
+		context.Background(),
+		offerIDs,
+		m.config.OfferDeclineRefuse,
+	)
+
+	for host := range hosts {
+		availableResources := models.HostResources{
+			NonSlack: m.offerManager.GetResources(host),
+		}
+		m.hostEventCh <- scalar.BuildHostEventFromResource(
+			host,
+			availableResources,
+			models.HostResources{},
+			scalar.UpdateHostAvailableRes,
+		)
+	}
+}
+
+// addMetadataToken registers the metadata of a task to be launched with
+// the metadata registry, and passes the token issued to the task through
+// its environment. No token is issued to a task without a command, since
+// the token could not be passed to it.
+func (m *MesosManager) addMetadataToken(
+	launchableTask *hostsvc.LaunchableTask,
+	mesosTask *mesos.TaskInfo,
+) error {
+	if m.metadataRegistry == nil {
+		return nil
+	}
+
+	command := mesosTask.GetCommand()
+	if mesosTask.GetExecutor() != nil {
+		command = mesosTask.GetExecutor().GetCommand()
+	}
+	if command == nil {
+		return nil
+	}
+
+	md, err := metadata.NewTaskMetadata(
+		mesosTask.GetTaskId().GetValue(),
+		launchableTask.GetConfig(),
+		launchableTask.GetPorts(),
+	)
+	if err != nil {
+		return err
+	}
+
+	token, err := m.metadataRegistry.Register(md)
+	if err != nil {
+		return err
+	}
+
+	if command.Environment == nil {
+		command.Environment = &mesos.Environment{}
+	}
+	command.Environment.Variables = append(
+		command.Environment.Variables,
+		&mesos.Environment_Variable{
+			Name:  util.PtrPrintf(metadata.TokenEnvVar),
+			Value: &token,
+		})
+	return nil
+}
+
+// unregisterMetadata removes the metadata of tasks from the metadata registry.
+func (m *MesosManager) unregisterMetadata(mesosTaskIDs []string) {
+	if m.metadataRegistry == nil {
+		return
+	}
+
+	for _, id := range mesosTaskIDs {
+		m.metadataRegistry.Unregister(id)
+	}
+}
+
 // declineOffers calls mesos master to decline list of offers
 func (m *MesosManager) declineOffers(
 	ctx context.Context,
-	offerIDs []*mesos.OfferID) error {
+	offerIDs []*mesos.OfferID,
+	refuse time.Duration) error {
 
 	callType := sched.Call_DECLINE
 	msg := &sched.Call{
@@ -254,6 +838,12 @@ func (m *MesosManager) declineOffers(
 			OfferIds: offerIDs,
 		},
 	}
+	// Mesos does not offer the declined resources back for refuse seconds,
+	// or its default of 5 seconds if they are not set.
+	if refuse > 0 {
+		refuseSeconds := refuse.Seconds()
+		msg.Decline.Filters = &mesos.Filters{RefuseSeconds: &refuseSeconds}
+	}
 	msid := m.frameworkInfoProvider.GetMesosStreamID(ctx)
 	err := m.schedulerClient.Call(msid, msg)
 	if err != nil {
@@ -272,21 +862,12 @@ func (m *MesosManager) declineOffers(
 	return nil
 }
 
-// KillPod kills a pod on a host.
+// KillPod kills a pod on a host. The kill is sent again with exponential
+// backoff until a terminal status update is received for the pod, even if
+// the first attempt fails, so that the pod is not left running.
 func (m *MesosManager) KillPod(ctx context.Context, podID string) error {
-	callType := sched.Call_KILL
-	msg := &sched.Call{
-		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
-		Type:        &callType,
-		Kill: &sched.Call_Kill{
-			TaskId: &mesos.TaskID{Value: &podID},
-		},
-	}
-
-	err := m.schedulerClient.Call(
-		m.frameworkInfoProvider.GetMesosStreamID(ctx),
-		msg,
-	)
+	err := m.sendKill(ctx, podID)
+	m.killQueue.add(podID, time.Now())
 
 	if err != nil {
 		m.metrics.KillPodFail.Inc(1)
@@ -297,12 +878,38 @@ func (m *MesosManager) KillPod(ctx context.Context, podID string) error {
 	return err
 }
 
+// SupportsPodResize returns false as mesos cannot change the resources
+// of a running task.
+func (m *MesosManager) SupportsPodResize() bool {
+	return false
+}
+
+// UpdatePodResources is not supported by mesos, which cannot change the
+// resources of a running task. Callers fall back to restarting the pod.
+func (m *MesosManager) UpdatePodResources(
+	ctx context.Context,
+	podID string,
+	spec *pbpod.PodSpec,
+) error {
+	return yarpcerrors.UnimplementedErrorf(
+		"mesos does not support updating resources of pod %s in place", podID)
+}
+
 // AckPodEvent is only implemented by mesos plugin. For K8s this is a noop.
 func (m *MesosManager) AckPodEvent(
 	event *scalar.PodEvent,
 ) {
-	// Add this to the mesos task status update ack channel and handle it asynchronously.
-	m.ackChannel <- event
+	// Add this to the mesos task status update ack channel and handle it
+	// asynchronously. If the channel is full, drop the ack rather than
+	// blocking the pod event stream, mesos resends the status updates which
+	// are not acknowledged.
+	select {
+	case m.ackChannel <- event:
+	default:
+		m.metrics.TaskUpdateAckDropped.Inc(1)
+		log.WithField("event_id", event.EventID).
+			Debug("Ack channel is full, dropping the ack")
+	}
 }
 
 // startAsyncProcessTaskUpdates concurrently process task status update events
@@ -311,6 +918,32 @@ func (m *MesosManager) startAsyncProcessTaskUpdates() {
 	for i := 0; i < m.updateAckConcurrency; i++ {
 		go m.ackPodEventWorker()
 	}
+
+	go func() {
+		ticker := time.NewTicker(ackMetricsPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.updateAckMetrics()
+			case <-m.lf.StopCh():
+				return
+			}
+		}
+	}()
+}
+
+// updateAckMetrics reports the number of pod events waiting to be
+// acknowledged, and the number being acknowledged.
+func (m *MesosManager) updateAckMetrics() {
+	m.metrics.TaskAckChannelSize.Update(float64(len(m.ackChannel)))
+	var length float64
+	m.ackStatusMap.Range(func(key, _ interface{}) bool {
+		length++
+		return true
+	})
+	m.metrics.TaskAckMapSize.Update(length)
 }
 
 func (m *MesosManager) ackPodEventWorker() {
@@ -373,7 +1006,7 @@ func (m *MesosManager) acknowledgeTaskUpdate(
 }
 
 func (m *MesosManager) startProcessAgentInfo(
-	agentCh <-chan []*mesosmaster.Response_GetAgents_Agent,
+	agentCh <-chan *agentSnapshot,
 ) {
 	// The first batch needs to be populated in sync,
 	// so after MesosManager starts and begins to receive mesos events,
@@ -383,8 +1016,8 @@ func (m *MesosManager) startProcessAgentInfo(
 	go func() {
 		for {
 			select {
-			case agents := <-agentCh:
-				m.processAgentHostMap(agents)
+			case snapshot := <-agentCh:
+				m.processAgentHostMap(snapshot)
 			case <-m.lf.StopCh():
 				return
 			}
@@ -392,24 +1025,20 @@ func (m *MesosManager) startProcessAgentInfo(
 	}()
 }
 
-func (m *MesosManager) processAgentHostMap(
-	agents []*mesosmaster.Response_GetAgents_Agent,
-) {
-	for _, agent := range agents {
+func (m *MesosManager) processAgentHostMap(snapshot *agentSnapshot) {
+	for _, agent := range snapshot.agents {
 		agentID := agent.GetAgentInfo().GetId().GetValue()
 		hostname := agent.GetAgentInfo().GetHostname()
 		m.agentIDToHostname.Store(agentID, hostname)
-		for _, agent := range agents {
-			capacity := models.HostResources{
-				NonSlack: hmscalar.FromMesosResources(agent.GetTotalResources()),
-			}
-			m.hostEventCh <- scalar.BuildHostEventFromResource(
-				hostname,
-				models.HostResources{},
-				capacity,
-				scalar.UpdateAgent,
-			)
-		}
+		m.hostMaintenance.Store(hostname, snapshot.maintenance[hostname])
+		// Agent attributes, fault domain and maintenance status are sent
+		// along with the capacity, so that placement constraints can be
+		// evaluated by the host cache.
+		m.hostEventCh <- scalar.BuildHostEventFromAgent(
+			agent,
+			snapshot.maintenance[hostname],
+			scalar.UpdateAgent,
+		)
 	}
 }
 
@@ -425,7 +1054,16 @@ func (m *MesosManager) Offers(ctx context.Context, body *sched.Event) error {
 	event := body.GetOffers()
 	log.WithField("event", event).Info("MesosManager: processing Offer event")
 
-	hosts := m.offerManager.AddOffers(event.Offers)
+	offers := m.cleanupOrphanedReservations(ctx, event.Offers)
+	hosts, suppressed := m.offerManager.AddOffers(offers)
+	if len(suppressed) != 0 {
+		// The offers of the hosts beyond the maximum number of hosts
+		// holding offers are declined right away, and Mesos is asked not
+		// to offer these hosts again for a while, rather than holding the
+		// offers until they expire.
+		m.metrics.OffersSuppressed.Inc(int64(len(suppressed)))
+		m.declineOffers(ctx, suppressed, m.config.HostSuppressionRefuse)
+	}
 	for host := range hosts {
 		// TODO: extract slack and non slack resources from offer manager.
 		availableResources := models.HostResources{
@@ -484,6 +1122,34 @@ func (m *MesosManager) Update(ctx context.Context, body *sched.Event) error {
 		return nil
 	}
 
+	// The state of a pod launched as a task group follows its main
+	// container, so the updates of its sidecars are only acknowledged.
+	if isSidecarTaskID(taskUpdate.GetStatus().GetTaskId().GetValue()) {
+		m.AckPodEvent(
+			buildPodEventFromMesosTaskStatus(taskUpdate, hostname.(string)))
+		return nil
+	}
+
+	// Any status reported by the agent means the pod is no longer being
+	// launched.
+	m.launchLimiter.release(taskUpdate.GetStatus().GetTaskId().GetValue())
+
+	if util.IsPelotonStateTerminal(
+		util.MesosStateToPelotonState(taskUpdate.GetStatus().GetState())) {
+		m.unregisterMetadata(
+			[]string{taskUpdate.GetStatus().GetTaskId().GetValue()})
+		// The reservation of a terminated stateful pod is cleaned up
+		// when its resources are offered back.
+		m.statefulPods.Delete(taskUpdate.GetStatus().GetTaskId().GetValue())
+		m.podAgents.Delete(taskUpdate.GetStatus().GetTaskId().GetValue())
+		// A terminal status update confirms the kill of the pod, if any.
+		m.killQueue.remove(taskUpdate.GetStatus().GetTaskId().GetValue())
+	} else {
+		m.podAgents.Store(
+			taskUpdate.GetStatus().GetTaskId().GetValue(),
+			taskUpdate.GetStatus().GetAgentId().GetValue())
+	}
+
 	// Update the metrics in go routine to unblock API callback
 	m.podEventCh <- buildPodEventFromMesosTaskStatus(taskUpdate, hostname.(string))
 	m.metrics.TaskUpdateCounter.Inc(1)
@@ -493,6 +1159,64 @@ func (m *MesosManager) Update(ctx context.Context, body *sched.Event) error {
 	return nil
 }
 
+// assignDynamicPorts selects ports from the offered resources for the
+// dynamic ports of the pods which are not assigned yet, in ascending order
+// as the v0 placement engine does. The selected ports are added to the
+// ports of the pods, so that they are passed to the pods through their
+// environment, and removed from the ports available on the host once the
+// pods are launched.
+func assignDynamicPorts(
+	pods []*models.LaunchablePod,
+	offered []*mesos.Resource,
+) error {
+	available := util.GetPortsSetFromResources(offered)
+	for _, pod := range pods {
+		for _, port := range pod.Ports {
+			delete(available, port)
+		}
+		for _, ps := range getPortSpecs(pod.Spec) {
+			delete(available, ps.GetValue())
+		}
+	}
+
+	free := make([]int, 0, len(available))
+	for port := range available {
+		free = append(free, int(port))
+	}
+	sort.Ints(free)
+
+	for _, pod := range pods {
+		for _, ps := range getPortSpecs(pod.Spec) {
+			if ps.GetValue() != 0 {
+				continue
+			}
+			if _, ok := pod.Ports[ps.GetName()]; ok {
+				continue
+			}
+			if len(free) == 0 {
+				return errors.Errorf(
+					"not enough ports offered for port %s of pod %s",
+					ps.GetName(), pod.PodId.GetValue())
+			}
+			if pod.Ports == nil {
+				pod.Ports = make(map[string]uint32)
+			}
+			pod.Ports[ps.GetName()] = uint32(free[0])
+			free = free[1:]
+		}
+	}
+	return nil
+}
+
+// getPortSpecs returns the ports of the main container of a pod, which are
+// the ports launched with the pod.
+func getPortSpecs(spec *pbpod.PodSpec) []*pbpod.PortSpec {
+	if len(spec.GetContainers()) == 0 {
+		return nil
+	}
+	return spec.GetContainers()[0].GetPorts()
+}
+
 func convertPodSpecToLaunchableTask(
 	id *peloton.PodID,
 	spec *pbpod.PodSpec,
@@ -16,6 +16,9 @@ package mesos
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -28,7 +31,9 @@ import (
 	"github.com/uber/peloton/pkg/common/util"
 	hostmgrmesosmocks "github.com/uber/peloton/pkg/hostmgr/mesos/mocks"
 	mpbmocks "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
+	"github.com/uber/peloton/pkg/hostmgr/metadata"
 	"github.com/uber/peloton/pkg/hostmgr/models"
+	p2kconfig "github.com/uber/peloton/pkg/hostmgr/p2k/config"
 	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
 	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
 
@@ -38,6 +43,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type MesosManagerTestSuite struct {
@@ -49,6 +55,7 @@ type MesosManagerTestSuite struct {
 	provider        *hostmgrmesosmocks.MockFrameworkInfoProvider
 	schedulerClient *mpbmocks.MockSchedulerClient
 	operatorClient  *mpbmocks.MockMasterOperatorClient
+	registry        *metadata.Registry
 	mesosManager    *MesosManager
 }
 
@@ -58,6 +65,7 @@ func (suite *MesosManagerTestSuite) SetupTest() {
 	suite.schedulerClient = mpbmocks.NewMockSchedulerClient(suite.ctrl)
 	suite.operatorClient = mpbmocks.NewMockMasterOperatorClient(suite.ctrl)
 	suite.podEventCh = make(chan *scalar.PodEvent, 1000)
+	suite.registry = metadata.NewRegistry(tally.NoopScope)
 	suite.hostEventCh = make(chan *scalar.HostEvent, 1000)
 	d := yarpc.NewDispatcher(yarpc.Config{
 		Name: common.PelotonHostManager,
@@ -69,9 +77,13 @@ func (suite *MesosManagerTestSuite) SetupTest() {
 		suite.operatorClient,
 		10*time.Second,
 		60*time.Second,
+		1,
+		10,
+		p2kconfig.MesosConfig{},
 		tally.NoopScope,
 		suite.podEventCh,
 		suite.hostEventCh,
+		suite.registry,
 	)
 }
 
@@ -90,6 +102,11 @@ func (suite *MesosManagerTestSuite) TestMesosManagerStartStop() {
 		Agents().
 		Return(nil, nil).
 		MinTimes(1)
+	suite.operatorClient.
+		EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, nil).
+		MinTimes(1)
 
 	suite.mesosManager.Start()
 	suite.mesosManager.Stop()
@@ -100,14 +117,87 @@ func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodNoOffer() {
 	testHostName := "test_host"
 
 	testPodSpec := newTestPelotonPodSpec(testPodName)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
 	_, err := suite.mesosManager.LaunchPods(
-		context.Background(),
+		ctx,
 		[]*models.LaunchablePod{
 			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
 		},
 		testHostName,
 	)
 	suite.Error(err)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestMesosManagerLaunchPodWaitForOffers tests that launching pods on a
+// reserved host which has no offers waits for the next offer cycle.
+func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodWaitForOffers() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+
+	suite.mesosManager.ReserveOffers(testHostName, time.Minute)
+
+	// the offers arrive after the launch started waiting for them
+	go func() {
+		time.Sleep(2 * offerWaitPollPeriod)
+		suite.mesosManager.Offers(context.Background(), &sched.Event{
+			Offers: &sched.Event_Offers{
+				Offers: []*mesos.Offer{
+					{Resources: []*mesos.Resource{
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosCPU).
+							WithValue(1.0).
+							Build(),
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosMem).
+							WithValue(100.0).
+							Build(),
+					},
+						Hostname: &testHostName,
+						Id:       &mesos.OfferID{Value: &uuid1},
+					},
+				},
+			},
+		})
+	}()
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_ACCEPT, call.GetType())
+			suite.Equal(uuid1, call.GetAccept().GetOfferIds()[0].GetValue())
+		}).
+		Return(nil)
+
+	launched, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.Equal(1, len(launched))
+
+	// the reservation is consumed by the launch
+	_, reserved := suite.mesosManager.offerManager.GetReservation(testHostName)
+	suite.False(reserved)
 }
 
 func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodSuccess() {
@@ -168,6 +258,432 @@ func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodSuccess() {
 	suite.Equal(1, len(launched))
 }
 
+// TestMesosManagerLaunchPodDynamicPorts tests that the dynamic ports of
+// the pods are selected from the offered ports.
+func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodDynamicPorts() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+	begin, end := uint64(31000), uint64(31001)
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+	testPodSpec.Containers[0].Ports = append(
+		testPodSpec.Containers[0].Ports,
+		&pbpod.PortSpec{Name: "dynamic", EnvName: "PORT_DYNAMIC"},
+	)
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(1.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosMem).
+						WithValue(100.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName("ports").
+						WithType(mesos.Value_RANGES).
+						WithRanges(&mesos.Value_Ranges{
+							Range: []*mesos.Value_Range{
+								{Begin: &begin, End: &end},
+							},
+						}).
+						Build(),
+				},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			taskInfo := call.GetAccept().GetOperations()[0].
+				GetLaunch().GetTaskInfos()[0]
+			suite.Equal(
+				map[uint32]bool{31000: true},
+				util.GetPortsSetFromResources(taskInfo.GetResources()))
+		}).
+		Return(nil)
+
+	launched, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.Equal(map[string]uint32{"dynamic": 31000}, launched[0].Ports)
+}
+
+// TestMesosManagerLaunchPodTaskGroup tests that a pod with sidecars is
+// launched as a Mesos task group.
+func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodTaskGroup() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+	testPodSpec.Containers = append(testPodSpec.Containers, &pbpod.ContainerSpec{
+		Name: "sidecar",
+		Resource: &pbpod.ResourceSpec{
+			CpuLimit:   0.5,
+			MemLimitMb: 50.0,
+		},
+		Image: "sidecar_image",
+	})
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(2.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosMem).
+						WithValue(200.0).
+						Build(),
+				},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		}).
+		Times(2)
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_ACCEPT, call.GetType())
+			ops := call.GetAccept().GetOperations()
+			suite.Len(ops, 1)
+			suite.Equal(mesos.Offer_Operation_LAUNCH_GROUP, ops[0].GetType())
+
+			executor := ops[0].GetLaunchGroup().GetExecutor()
+			suite.Equal(mesos.ExecutorInfo_DEFAULT, executor.GetType())
+			suite.NotEmpty(executor.GetResources())
+
+			tasks := ops[0].GetLaunchGroup().GetTaskGroup().GetTasks()
+			suite.Len(tasks, 2)
+			suite.Equal(testPodName, tasks[0].GetTaskId().GetValue())
+			suite.Equal(
+				testPodName+".sidecar",
+				tasks[1].GetTaskId().GetValue())
+			suite.True(isSidecarTaskID(tasks[1].GetTaskId().GetValue()))
+			for _, t := range tasks {
+				suite.Nil(t.GetExecutor())
+			}
+		}).
+		Return(nil)
+
+	launched, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.Equal(1, len(launched))
+	suite.False(isSidecarTaskID(testPodName))
+}
+
+// TestMesosManagerLaunchStatefulPod tests that the resources of a stateful
+// pod are reserved, and its persistent volume created, when it is launched.
+func (suite *MesosManagerTestSuite) TestMesosManagerLaunchStatefulPod() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+	testPodSpec.Volume = &pbpod.PersistentVolumeSpec{
+		ContainerPath: "/data",
+		SizeMb:        100,
+	}
+
+	suite.mesosManager.config.Role = "peloton"
+	suite.mesosManager.config.Principal = "peloton"
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(2.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosMem).
+						WithValue(200.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosDisk).
+						WithValue(200.0).
+						Build(),
+				},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_ACCEPT, call.GetType())
+			ops := call.GetAccept().GetOperations()
+			suite.Len(ops, 3)
+			suite.Equal(mesos.Offer_Operation_RESERVE, ops[0].GetType())
+			suite.Equal(mesos.Offer_Operation_CREATE, ops[1].GetType())
+			suite.Equal(mesos.Offer_Operation_LAUNCH, ops[2].GetType())
+
+			for _, r := range ops[0].GetReserve().GetResources() {
+				suite.Equal("peloton", r.GetRole())
+				suite.Equal(testPodName, getReservationPodID(r))
+			}
+
+			volumes := ops[1].GetCreate().GetVolumes()
+			suite.Len(volumes, 1)
+			suite.Equal(common.MesosDisk, volumes[0].GetName())
+			suite.Equal(float64(100), volumes[0].GetScalar().GetValue())
+			suite.Equal(
+				testPodName+"-volume",
+				volumes[0].GetDisk().GetPersistence().GetId())
+			suite.Equal(
+				"/data",
+				volumes[0].GetDisk().GetVolume().GetContainerPath())
+
+			// the task is launched with the reserved resources and
+			// the volume
+			tasks := ops[2].GetLaunch().GetTaskInfos()
+			suite.Len(tasks, 1)
+			for _, r := range tasks[0].GetResources() {
+				if r.GetName() == common.MesosPorts {
+					continue
+				}
+				suite.Equal(testPodName, getReservationPodID(r))
+			}
+		}).
+		Return(nil)
+
+	launched, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.Equal(1, len(launched))
+
+	_, ok := suite.mesosManager.statefulPods.Load(testPodName)
+	suite.True(ok)
+}
+
+// TestMesosManagerCleanupOrphanedReservations tests that the reservations
+// of unknown stateful pods are cleaned up when they are offered, and the
+// reservations of known stateful pods are not available to other pods.
+func (suite *MesosManagerTestSuite) TestMesosManagerCleanupOrphanedReservations() {
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	knownPodID := "known-pod"
+	orphanPodID := "orphan-pod"
+	offerID1 := uuid.New()
+	offerID2 := uuid.New()
+	volumeID := orphanPodID + "-volume"
+
+	suite.mesosManager.statefulPods.Store(knownPodID, testHostName)
+
+	reservation := func(podID string) *mesos.Resource_ReservationInfo {
+		key := _reservationPodIDLabel
+		return &mesos.Resource_ReservationInfo{
+			Labels: &mesos.Labels{
+				Labels: []*mesos.Label{{Key: &key, Value: &podID}},
+			},
+		}
+	}
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_ACCEPT, call.GetType())
+			suite.Equal(
+				offerID2,
+				call.GetAccept().GetOfferIds()[0].GetValue())
+			ops := call.GetAccept().GetOperations()
+			suite.Len(ops, 2)
+			suite.Equal(mesos.Offer_Operation_DESTROY, ops[0].GetType())
+			suite.Len(ops[0].GetDestroy().GetVolumes(), 1)
+			suite.Equal(mesos.Offer_Operation_UNRESERVE, ops[1].GetType())
+			unreserved := ops[1].GetUnreserve().GetResources()
+			suite.Len(unreserved, 2)
+			for _, r := range unreserved {
+				suite.Nil(r.GetDisk())
+			}
+		}).
+		Return(nil)
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{
+					Resources: []*mesos.Resource{
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosCPU).
+							WithValue(1.0).
+							Build(),
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosCPU).
+							WithValue(2.0).
+							WithRole("peloton").
+							WithReservation(reservation(knownPodID)).
+							Build(),
+					},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &offerID1},
+				},
+				{
+					Resources: []*mesos.Resource{
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosCPU).
+							WithValue(2.0).
+							WithRole("peloton").
+							WithReservation(reservation(orphanPodID)).
+							Build(),
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosDisk).
+							WithValue(100.0).
+							WithRole("peloton").
+							WithReservation(reservation(orphanPodID)).
+							WithDisk(&mesos.Resource_DiskInfo{
+								Persistence: &mesos.Resource_DiskInfo_Persistence{
+									Id: &volumeID,
+								},
+							}).
+							Build(),
+					},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &offerID2},
+				},
+			},
+		},
+	})
+
+	// the offer used for the cleanup is not held, and the reserved
+	// resources of the known pod are not available
+	offers := suite.mesosManager.offerManager.GetOffers(testHostName)
+	suite.Len(offers, 1)
+	suite.Contains(offers, offerID1)
+	suite.Equal(
+		float64(1),
+		suite.mesosManager.offerManager.GetResources(testHostName).CPU)
+}
+
+// TestAssignDynamicPorts tests selecting the dynamic ports of pods from
+// the offered ports.
+func (suite *MesosManagerTestSuite) TestAssignDynamicPorts() {
+	begin, end := uint64(31000), uint64(31002)
+	offered := []*mesos.Resource{
+		util.NewMesosResourceBuilder().
+			WithName("ports").
+			WithType(mesos.Value_RANGES).
+			WithRanges(&mesos.Value_Ranges{
+				Range: []*mesos.Value_Range{
+					{Begin: &begin, End: &end},
+				},
+			}).
+			Build(),
+	}
+	newPod := func(id string, ports map[string]uint32) *models.LaunchablePod {
+		spec := newTestPelotonPodSpec(id)
+		spec.Containers[0].Ports = []*pbpod.PortSpec{
+			{Name: "static", Value: 31000},
+			{Name: "dynamic"},
+		}
+		return &models.LaunchablePod{
+			PodId: &peloton.PodID{Value: id},
+			Spec:  spec,
+			Ports: ports,
+		}
+	}
+
+	// ports already assigned and static ports are not selected again
+	pods := []*models.LaunchablePod{
+		newPod("pod1", map[string]uint32{"dynamic": 31001}),
+		newPod("pod2", nil),
+	}
+	suite.NoError(assignDynamicPorts(pods, offered))
+	suite.Equal(map[string]uint32{"dynamic": 31001}, pods[0].Ports)
+	suite.Equal(map[string]uint32{"dynamic": 31002}, pods[1].Ports)
+
+	// not enough ports offered
+	pods = []*models.LaunchablePod{
+		newPod("pod1", nil),
+		newPod("pod2", nil),
+		newPod("pod3", nil),
+	}
+	suite.Error(assignDynamicPorts(pods, offered))
+}
+
 func (suite *MesosManagerTestSuite) TestMesosManagerKillPodSuccess() {
 	podID := "test_pod"
 	streamID := "streamID"
@@ -194,6 +710,13 @@ func (suite *MesosManagerTestSuite) TestMesosManagerKillPodSuccess() {
 	suite.NoError(suite.mesosManager.KillPod(context.Background(), podID))
 }
 
+func (suite *MesosManagerTestSuite) TestMesosManagerUpdatePodResources() {
+	suite.False(suite.mesosManager.SupportsPodResize())
+	err := suite.mesosManager.UpdatePodResources(
+		context.Background(), "test_pod", &pbpod.PodSpec{})
+	suite.True(yarpcerrors.IsUnimplemented(err))
+}
+
 func (suite *MesosManagerTestSuite) TestAckPodEvents() {
 	expectedPodEvent := &scalar.PodEvent{
 		Event:     &pbpod.PodEvent{},
@@ -207,6 +730,30 @@ func (suite *MesosManagerTestSuite) TestAckPodEvents() {
 	suite.Equal(pe.EventID, expectedPodEvent.EventID)
 }
 
+// TestAckPodEventsChannelFull tests that acks are dropped rather than
+// blocking when the ack channel is full.
+func (suite *MesosManagerTestSuite) TestAckPodEventsChannelFull() {
+	scope := tally.NewTestScope("", nil)
+	suite.mesosManager.metrics = newMetrics(scope)
+
+	for i := 0; i < cap(suite.mesosManager.ackChannel)+1; i++ {
+		suite.mesosManager.AckPodEvent(&scalar.PodEvent{
+			Event:   &pbpod.PodEvent{},
+			EventID: uuid.New(),
+		})
+	}
+	suite.Len(suite.mesosManager.ackChannel, 10)
+
+	suite.mesosManager.updateAckMetrics()
+	snapshot := scope.Snapshot()
+	suite.Equal(
+		int64(1),
+		snapshot.Counters()["task_update_ack_dropped+result=fail"].Value())
+	suite.Equal(
+		float64(10),
+		snapshot.Gauges()["task_ack_channel_size+"].Value())
+}
+
 func (suite *MesosManagerTestSuite) TestMesosManagerKillPodFail() {
 	podID := "test_pod"
 	streamID := "streamID"
@@ -226,15 +773,268 @@ func (suite *MesosManagerTestSuite) TestMesosManagerKillPodFail() {
 		EXPECT().
 		Call(streamID, gomock.Any()).
 		Do(func(mesosStreamID string, call *sched.Call) {
-			suite.Equal(call.GetType(), sched.Call_KILL)
+			suite.Equal(call.GetType(), sched.Call_KILL)
+		}).
+		Return(errors.New("test error"))
+
+	suite.Error(suite.mesosManager.KillPod(context.Background(), podID))
+	// the failed kill is kept to be retried
+	suite.Equal(1, suite.mesosManager.killQueue.size())
+}
+
+// TestKillQueue tests the deduplication and the exponential backoff of
+// the kill retry queue.
+func (suite *MesosManagerTestSuite) TestKillQueue() {
+	q := newKillQueue(time.Second, 3*time.Second, 3)
+	now := time.Now()
+
+	suite.Equal(time.Second, q.backoff(1))
+	suite.Equal(2*time.Second, q.backoff(2))
+	suite.Equal(3*time.Second, q.backoff(3))
+
+	q.add("pod1", now)
+	q.add("pod1", now)
+	q.add("pod2", now)
+	suite.Equal(2, q.size())
+
+	// pod1 has been sent twice and waits for longer than pod2
+	retry, expired := q.due(now.Add(time.Second))
+	suite.Equal([]string{"pod2"}, retry)
+	suite.Empty(expired)
+
+	q.add("pod1", now)
+	retry, expired = q.due(now.Add(3 * time.Second))
+	suite.Equal([]string{"pod2"}, retry)
+	suite.Equal([]string{"pod1"}, expired)
+	suite.Equal(1, q.size())
+
+	suite.True(q.remove("pod2"))
+	suite.False(q.remove("pod2"))
+	suite.Equal(0, q.size())
+}
+
+// TestLaunchLimiter tests that launches on an agent are queued while too
+// many pods are being launched on it.
+func (suite *MesosManagerTestSuite) TestLaunchLimiter() {
+	l := newLaunchLimiter(2, time.Minute, suite.mesosManager.metrics)
+	ctx := context.Background()
+
+	suite.NoError(l.acquire(ctx, "host1", []string{"pod1", "pod2"}))
+	// launches on the other hosts are not limited
+	suite.NoError(l.acquire(ctx, "host2", []string{"pod3"}))
+
+	// the launch is queued until the status of a pod is reported
+	acquired := make(chan error, 1)
+	go func() {
+		acquired <- l.acquire(ctx, "host1", []string{"pod4"})
+	}()
+	select {
+	case <-acquired:
+		suite.Fail("launch should be queued")
+	case <-time.After(50 * time.Millisecond):
+	}
+	l.release("pod1")
+	suite.NoError(<-acquired)
+
+	// the launch fails if it is still queued when its context is done
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := l.acquire(timeoutCtx, "host1", []string{"pod5"})
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+
+	// a launch of more pods than the limit is let through once no pod is
+	// being launched on the host
+	l.release("pod2", "pod4", "unknown")
+	suite.NoError(l.acquire(ctx, "host1", []string{"pod5", "pod6", "pod7"}))
+	suite.Len(l.hosts["host1"].pods, 3)
+}
+
+// TestLaunchLimiterExpire tests that the pods no status is reported for
+// stop counting against the limit of their agent once they time out.
+func (suite *MesosManagerTestSuite) TestLaunchLimiterExpire() {
+	l := newLaunchLimiter(1, 20*time.Millisecond, suite.mesosManager.metrics)
+	ctx := context.Background()
+
+	suite.NoError(l.acquire(ctx, "host1", []string{"pod1"}))
+	suite.NoError(l.acquire(ctx, "host1", []string{"pod2"}))
+	suite.Len(l.hosts["host1"].pods, 1)
+	suite.Len(l.podHosts, 1)
+	suite.Contains(l.podHosts, "pod2")
+}
+
+// TestMesosManagerKillPodRetry tests that a kill is sent again to the
+// agent running the pod until a terminal status update is received.
+func (suite *MesosManagerTestSuite) TestMesosManagerKillPodRetry() {
+	podID := "test_pod"
+	agentID := "agent"
+	streamID := "streamID"
+	frameID := "frameID"
+
+	suite.mesosManager.killQueue = newKillQueue(time.Nanosecond, time.Nanosecond, 10)
+	suite.mesosManager.agentIDToHostname.Store(agentID, "hostname")
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		}).
+		Times(2)
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID).
+		Times(2)
+	gomock.InOrder(
+		suite.schedulerClient.
+			EXPECT().
+			Call(streamID, gomock.Any()).
+			Return(errors.New("test error")),
+		suite.schedulerClient.
+			EXPECT().
+			Call(streamID, gomock.Any()).
+			Do(func(mesosStreamID string, call *sched.Call) {
+				suite.Equal(sched.Call_KILL, call.GetType())
+				suite.Equal(podID, call.GetKill().GetTaskId().GetValue())
+				suite.Equal(agentID, call.GetKill().GetAgentId().GetValue())
+			}).
+			Return(nil),
+	)
+
+	state := mesos.TaskState_TASK_RUNNING
+	suite.mesosManager.Update(context.Background(), &sched.Event{
+		Update: &sched.Event_Update{
+			Status: &mesos.TaskStatus{
+				TaskId:  &mesos.TaskID{Value: &podID},
+				State:   &state,
+				AgentId: &mesos.AgentID{Value: &agentID},
+			},
+		},
+	})
+	<-suite.podEventCh
+
+	suite.Error(suite.mesosManager.KillPod(context.Background(), podID))
+	time.Sleep(time.Millisecond)
+	suite.mesosManager.retryKills()
+	suite.Equal(1, suite.mesosManager.killQueue.size())
+
+	state = mesos.TaskState_TASK_KILLED
+	suite.mesosManager.Update(context.Background(), &sched.Event{
+		Update: &sched.Event_Update{
+			Status: &mesos.TaskStatus{
+				TaskId:  &mesos.TaskID{Value: &podID},
+				State:   &state,
+				AgentId: &mesos.AgentID{Value: &agentID},
+			},
+		},
+	})
+	<-suite.podEventCh
+
+	// the terminal status update confirms the kill
+	suite.Equal(0, suite.mesosManager.killQueue.size())
+	suite.mesosManager.retryKills()
+}
+
+// TestMesosManagerKillPodAgentDown tests that the kill of a pod on an
+// agent which is down for maintenance is not retried.
+func (suite *MesosManagerTestSuite) TestMesosManagerKillPodAgentDown() {
+	podID := "test_pod"
+	agentID := "agent"
+	hostname := "hostname"
+
+	suite.mesosManager.killQueue = newKillQueue(time.Nanosecond, time.Nanosecond, 10)
+	suite.mesosManager.podAgents.Store(podID, agentID)
+	suite.mesosManager.agentIDToHostname.Store(agentID, hostname)
+	suite.mesosManager.hostMaintenance.Store(hostname, scalar.HostDown)
+	suite.mesosManager.killQueue.add(podID, time.Now())
+
+	time.Sleep(time.Millisecond)
+	suite.mesosManager.retryKills()
+	suite.Equal(0, suite.mesosManager.killQueue.size())
+}
+
+func (suite *MesosManagerTestSuite) TestMesosManagerReoncileHosts() {
+	suite.mesosManager.ReconcileHosts()
+}
+
+// TestMesosManagerFailover tests that the offers are cleared when the
+// subscription to the Mesos master is lost, and that the agents are synced
+// and the tasks reconciled once subscribed again.
+func (suite *MesosManagerTestSuite) TestMesosManagerFailover() {
+	host := "hostname1"
+	offerID := uuid.New()
+	streamID := "streamID"
+	frameID := "frameID"
+
+	scope := tally.NewTestScope("", nil)
+	suite.mesosManager.metrics = newMetrics(scope)
+
+	// resubscribing without being disconnected is a noop
+	suite.mesosManager.Resubscribed()
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{
+					Resources: []*mesos.Resource{
+						util.NewMesosResourceBuilder().
+							WithName(common.MesosCPU).
+							WithValue(1.0).
+							Build(),
+					},
+					Hostname: &host,
+					Id:       &mesos.OfferID{Value: &offerID},
+				},
+			},
+		},
+	})
+	<-suite.hostEventCh
+	suite.mesosManager.suppressed.Store(true)
+
+	suite.mesosManager.Disconnected()
+	suite.mesosManager.Disconnected()
+	suite.Empty(suite.mesosManager.offerManager.GetOffers(host))
+	he := <-suite.hostEventCh
+	suite.Equal(scalar.UpdateHostAvailableRes, he.GetEventType())
+	suite.Equal(host, he.GetHostInfo().GetHostName())
+	suite.Equal(hmscalar.Resources{}, he.GetHostInfo().GetAvailable().NonSlack)
+	suite.Empty(suite.hostEventCh)
+
+	suite.operatorClient.
+		EXPECT().
+		Agents().
+		Return(nil, nil)
+	suite.operatorClient.
+		EXPECT().
+		GetMaintenanceStatus().
+		Return(nil, nil)
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_RECONCILE, call.GetType())
+			suite.Empty(call.GetReconcile().GetTasks())
 		}).
-		Return(errors.New("test error"))
+		Return(nil)
 
-	suite.Error(suite.mesosManager.KillPod(context.Background(), podID))
-}
+	suite.mesosManager.Resubscribed()
+	suite.False(suite.mesosManager.suppressed.Load())
 
-func (suite *MesosManagerTestSuite) TestMesosManagerReoncileHosts() {
-	suite.mesosManager.ReconcileHosts()
+	snapshot := scope.Snapshot()
+	suite.Equal(int64(1), snapshot.Counters()["mesos_disconnected+"].Value())
+	suite.Equal(int64(1), snapshot.Counters()["mesos_failover+"].Value())
+	suite.Equal(int64(1),
+		snapshot.Counters()["reconcile_tasks+result=success"].Value())
 }
 
 // TestNewMesosManagerOffersSingleOffer tests
@@ -444,6 +1244,305 @@ func (suite *MesosManagerTestSuite) TestNewMesosManagerRescindOffer() {
 	suite.Equal(he.GetHostInfo().GetHostName(), host)
 }
 
+// TestMesosManagerPruneExpiredOffers tests declining the offers held
+// for longer than the offer hold time
+func (suite *MesosManagerTestSuite) TestMesosManagerPruneExpiredOffers() {
+	host := "hostname1"
+	uuid1 := uuid.New()
+	streamID := "streamID"
+	frameID := "frameID"
+
+	// offers expire as soon as they are received
+	suite.mesosManager.offerManager.offerHoldTime = 0
+	suite.mesosManager.config.OfferDeclineRefuse = 30 * time.Second
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(1.0).
+						Build(),
+				},
+					Hostname: &host,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+	<-suite.hostEventCh
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_DECLINE, call.GetType())
+			suite.Equal(uuid1, call.GetDecline().GetOfferIds()[0].GetValue())
+			suite.Equal(
+				float64(30),
+				call.GetDecline().GetFilters().GetRefuseSeconds())
+		}).
+		Return(nil)
+
+	suite.mesosManager.pruneExpiredOffers()
+	he := <-suite.hostEventCh
+	suite.Equal(he.GetEventType(), scalar.UpdateHostAvailableRes)
+	suite.Equal(hmscalar.Resources{}, he.GetHostInfo().GetAvailable().NonSlack)
+	suite.Nil(suite.mesosManager.offerManager.GetOffers(host))
+	suite.Equal(uint64(1), suite.mesosManager.offerStats.get().ExpiredOffers)
+
+	// nothing left to prune
+	suite.mesosManager.pruneExpiredOffers()
+}
+
+// TestMesosManagerSuppressHosts tests that once offers are held for the
+// maximum number of hosts, the offers of other hosts are declined right
+// away with the host suppression refuse duration.
+func (suite *MesosManagerTestSuite) TestMesosManagerSuppressHosts() {
+	host1 := "hostname1"
+	host2 := "hostname2"
+	uuid1 := uuid.New()
+	uuid2 := uuid.New()
+	uuid3 := uuid.New()
+	streamID := "streamID"
+	frameID := "frameID"
+
+	suite.mesosManager.offerManager.maxHosts = 1
+	suite.mesosManager.config.HostSuppressionRefuse = time.Minute
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			suite.Equal(sched.Call_DECLINE, call.GetType())
+			suite.Len(call.GetDecline().GetOfferIds(), 1)
+			suite.Equal(uuid2, call.GetDecline().GetOfferIds()[0].GetValue())
+			suite.Equal(
+				float64(60),
+				call.GetDecline().GetFilters().GetRefuseSeconds())
+		}).
+		Return(nil)
+
+	newOffer := func(host, id string) *mesos.Offer {
+		return &mesos.Offer{
+			Resources: []*mesos.Resource{
+				util.NewMesosResourceBuilder().
+					WithName(common.MesosCPU).
+					WithValue(1.0).
+					Build(),
+			},
+			Hostname: &host,
+			Id:       &mesos.OfferID{Value: &id},
+		}
+	}
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				newOffer(host1, uuid1),
+				newOffer(host2, uuid2),
+				// more offers of a host holding offers are still added
+				newOffer(host1, uuid3),
+			},
+		},
+	})
+
+	he := <-suite.hostEventCh
+	suite.Equal(host1, he.GetHostInfo().GetHostName())
+	suite.Len(suite.mesosManager.offerManager.GetOffers(host1), 2)
+	suite.Nil(suite.mesosManager.offerManager.GetOffers(host2))
+	suite.Len(suite.hostEventCh, 0)
+}
+
+// TestMesosManagerSuppressRevive tests that offers are suppressed once
+// the framework is idle, and revived when pods wait to be placed again.
+func (suite *MesosManagerTestSuite) TestMesosManagerSuppressRevive() {
+	streamID := "streamID"
+	frameID := "frameID"
+
+	suite.mesosManager.config.SuppressIdleTimeout = time.Minute
+
+	// not idle for long enough
+	suite.mesosManager.suppressIfIdle()
+	suite.False(suite.mesosManager.suppressed.Load())
+
+	// launching pods
+	suite.mesosManager.lastDemand.Store(
+		time.Now().Add(-2 * time.Minute).UnixNano())
+	suite.mesosManager.pendingLaunches.Inc()
+	suite.mesosManager.suppressIfIdle()
+	suite.False(suite.mesosManager.suppressed.Load())
+	suite.mesosManager.pendingLaunches.Dec()
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		}).
+		Times(2)
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID).
+		Times(2)
+	gomock.InOrder(
+		suite.schedulerClient.
+			EXPECT().
+			Call(streamID, gomock.Any()).
+			Do(func(mesosStreamID string, call *sched.Call) {
+				suite.Equal(sched.Call_SUPPRESS, call.GetType())
+			}).
+			Return(nil),
+		suite.schedulerClient.
+			EXPECT().
+			Call(streamID, gomock.Any()).
+			Do(func(mesosStreamID string, call *sched.Call) {
+				suite.Equal(sched.Call_REVIVE, call.GetType())
+			}).
+			Return(nil),
+	)
+
+	suite.mesosManager.suppressIfIdle()
+	suite.True(suite.mesosManager.suppressed.Load())
+
+	// already suppressed
+	suite.mesosManager.suppressIfIdle()
+
+	suite.mesosManager.RecordDemand()
+	suite.False(suite.mesosManager.suppressed.Load())
+
+	// already revived
+	suite.mesosManager.RecordDemand()
+}
+
+// TestMesosManagerPruneReservedOffers tests that the offers pinned by a
+// reservation are not declined until the reservation is released.
+func (suite *MesosManagerTestSuite) TestMesosManagerPruneReservedOffers() {
+	host := "hostname1"
+	uuid1 := uuid.New()
+
+	// offers expire as soon as they are received
+	suite.mesosManager.offerManager.offerHoldTime = 0
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(1.0).
+						Build(),
+				},
+					Hostname: &host,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+	<-suite.hostEventCh
+
+	suite.mesosManager.ReserveOffers(host, time.Minute)
+	suite.mesosManager.pruneExpiredOffers()
+	suite.Len(suite.mesosManager.offerManager.GetOffers(host), 1)
+
+	suite.mesosManager.ReleaseOffers(host)
+	offerIDs, hosts := suite.mesosManager.offerManager.RemoveExpiredOffers()
+	suite.Len(offerIDs, 1)
+	suite.Contains(hosts, host)
+	suite.Nil(suite.mesosManager.offerManager.GetOffers(host))
+}
+
+// TestMesosManagerOfferStats tests tracking the age and the utilization
+// of the offers pods are launched on
+func (suite *MesosManagerTestSuite) TestMesosManagerOfferStats() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(2.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosMem).
+						WithValue(400.0).
+						Build(),
+				},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &uuid1},
+				},
+			},
+		},
+	})
+
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Return(nil)
+
+	_, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{
+				PodId: &peloton.PodID{Value: testPodName},
+				Spec:  newTestPelotonPodSpec(testPodName),
+			},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+
+	req := httptest.NewRequest(http.MethodGet, OfferStatsPath, nil)
+	rec := httptest.NewRecorder()
+	suite.mesosManager.OfferStatsHandler(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	stats := &OfferStats{}
+	suite.NoError(json.Unmarshal(rec.Body.Bytes(), stats))
+	suite.Equal(0, stats.HeldOffers)
+	suite.Equal(uint64(1), stats.LaunchedOffers)
+	suite.Equal(uint64(0), stats.ExpiredOffers)
+	suite.Equal(map[string]float64{
+		common.CPU:    0.5,
+		common.MEMORY: 0.25,
+	}, stats.MeanUtilization)
+}
+
 // TestNewMesosManagerRescindOffer tests rescinding nonexistent offers
 func (suite *MesosManagerTestSuite) TestNewMesosManagerRescindNonexistentOffer() {
 	// First, add offers to the host
@@ -522,6 +1621,125 @@ func (suite *MesosManagerTestSuite) TestNewMesosManagerRescindNonexistentOffer()
 	}
 }
 
+// TestMesosManagerLaunchPodMetadataToken tests that a launched pod gets a
+// metadata token in its environment, which is revoked once it terminates
+func (suite *MesosManagerTestSuite) TestMesosManagerLaunchPodMetadataToken() {
+	testPodName := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	testHostName := "test_host"
+	agentID := "agent"
+	streamID := "streamID"
+	frameID := "frameID"
+	uuid1 := uuid.New()
+	testPodSpec := newTestPelotonPodSpec(testPodName)
+
+	suite.mesosManager.Offers(context.Background(), &sched.Event{
+		Offers: &sched.Event_Offers{
+			Offers: []*mesos.Offer{
+				{Resources: []*mesos.Resource{
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosCPU).
+						WithValue(1.0).
+						Build(),
+					util.NewMesosResourceBuilder().
+						WithName(common.MesosMem).
+						WithValue(100.0).
+						Build(),
+				},
+					Hostname: &testHostName,
+					Id:       &mesos.OfferID{Value: &uuid1},
+					AgentId:  &mesos.AgentID{Value: &agentID},
+				},
+			},
+		},
+	})
+
+	var token string
+	suite.provider.
+		EXPECT().
+		GetFrameworkID(gomock.Any()).
+		Return(&mesos.FrameworkID{
+			Value: &frameID,
+		})
+	suite.provider.
+		EXPECT().
+		GetMesosStreamID(gomock.Any()).
+		Return(streamID)
+	suite.schedulerClient.
+		EXPECT().
+		Call(streamID, gomock.Any()).
+		Do(func(mesosStreamID string, call *sched.Call) {
+			taskInfos := call.GetAccept().GetOperations()[0].
+				GetLaunch().GetTaskInfos()
+			suite.Len(taskInfos, 1)
+			for _, v := range taskInfos[0].GetCommand().
+				GetEnvironment().GetVariables() {
+				if v.GetName() == metadata.TokenEnvVar {
+					token = v.GetValue()
+				}
+			}
+		}).
+		Return(nil)
+
+	_, err := suite.mesosManager.LaunchPods(
+		context.Background(),
+		[]*models.LaunchablePod{
+			{PodId: &peloton.PodID{Value: testPodName}, Spec: testPodSpec},
+		},
+		testHostName,
+	)
+	suite.NoError(err)
+	suite.NotEmpty(token)
+
+	req := httptest.NewRequest(http.MethodGet, metadata.Path, nil)
+	req.Header.Set(metadata.TokenHeader, token)
+	rec := httptest.NewRecorder()
+	suite.registry.ServeHTTP(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	state := mesos.TaskState_TASK_FINISHED
+	suite.mesosManager.agentIDToHostname.Store(agentID, testHostName)
+	suite.mesosManager.Update(context.Background(), &sched.Event{
+		Update: &sched.Event_Update{
+			Status: &mesos.TaskStatus{
+				TaskId:  &mesos.TaskID{Value: &testPodName},
+				State:   &state,
+				AgentId: &mesos.AgentID{Value: &agentID},
+			},
+		},
+	})
+	<-suite.podEventCh
+
+	rec = httptest.NewRecorder()
+	suite.registry.ServeHTTP(rec, req)
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+// TestAddMetadataTokenNoCommand tests that no metadata token is issued to
+// a task without a command
+func (suite *MesosManagerTestSuite) TestAddMetadataTokenNoCommand() {
+	testScope := tally.NewTestScope("", map[string]string{})
+	suite.mesosManager.metadataRegistry = metadata.NewRegistry(testScope)
+
+	taskID := "bca875f5-322a-4439-b0c9-63e3cf9f982e-1-1"
+	launchableTask, err := convertPodSpecToLaunchableTask(
+		&peloton.PodID{Value: taskID}, newTestPelotonPodSpec(taskID), nil)
+	suite.NoError(err)
+
+	mesosTask := &mesos.TaskInfo{TaskId: &mesos.TaskID{Value: &taskID}}
+	suite.NoError(
+		suite.mesosManager.addMetadataToken(launchableTask, mesosTask))
+	suite.Nil(mesosTask.GetCommand())
+	_, ok := testScope.Snapshot().Gauges()["task_metadata.registered+"]
+	suite.False(ok)
+
+	mesosTask.Command = &mesos.CommandInfo{}
+	suite.NoError(
+		suite.mesosManager.addMetadataToken(launchableTask, mesosTask))
+	suite.Len(mesosTask.GetCommand().GetEnvironment().GetVariables(), 1)
+	suite.Equal(float64(1),
+		testScope.Snapshot().Gauges()["task_metadata.registered+"].Value())
+}
+
 // TestNewMesosManagerStatusUpdates tests receiving task status update events.
 func (suite *MesosManagerTestSuite) TestNewMesosManagerStatusUpdates() {
 	hostname1 := "hostname1"
@@ -609,14 +1827,23 @@ func (suite *MesosManagerTestSuite) TestNewMesosManagerStartProcessingAgentInfo(
 	agentID2 := uuid.New()
 	cpu2 := 8.0
 
+	zoneName := "zone"
+	zone := "zone1"
+	textType := mesos.Value_TEXT
+
 	suite.mesosManager.lf.Start()
 
-	agentCh := make(chan []*mesosmaster.Response_GetAgents_Agent, 2)
-	agentCh <- []*mesosmaster.Response_GetAgents_Agent{
+	agentCh := make(chan *agentSnapshot, 2)
+	agentCh <- &agentSnapshot{agents: []*mesosmaster.Response_GetAgents_Agent{
 		{
 			AgentInfo: &mesos.AgentInfo{
 				Hostname: &hostname1,
 				Id:       &mesos.AgentID{Value: &agentID1},
+				Attributes: []*mesos.Attribute{{
+					Name: &zoneName,
+					Type: &textType,
+					Text: &mesos.Value_Text{Value: &zone},
+				}},
 			},
 			TotalResources: []*mesos.Resource{{
 				Name:   &cpuName,
@@ -627,7 +1854,9 @@ func (suite *MesosManagerTestSuite) TestNewMesosManagerStartProcessingAgentInfo(
 			AgentInfo:      &mesos.AgentInfo{Hostname: &hostname2, Id: &mesos.AgentID{Value: &agentID2}},
 			TotalResources: []*mesos.Resource{{Name: &cpuName, Scalar: &mesos.Value_Scalar{Value: &cpu2}}},
 		},
-	}
+	}, maintenance: map[string]scalar.MaintenanceStatus{
+		hostname2: scalar.HostDraining,
+	}}
 	suite.mesosManager.startProcessAgentInfo(agentCh)
 
 	suite.mesosManager.lf.Stop()
@@ -641,9 +1870,20 @@ func (suite *MesosManagerTestSuite) TestNewMesosManagerStartProcessingAgentInfo(
 	suite.Equal(hostname2, hs2)
 
 	he1 := <-suite.hostEventCh
+	suite.Equal(hostname1, he1.GetHostInfo().GetHostName())
 	suite.Equal(he1.GetHostInfo().GetCapacity().NonSlack.CPU, cpu1)
+	suite.Equal(zone, he1.GetHostInfo().GetAttributes()[0].GetText().GetValue())
+	suite.Equal(scalar.HostUp, he1.GetHostInfo().GetMaintenanceStatus())
 	he2 := <-suite.hostEventCh
+	suite.Equal(hostname2, he2.GetHostInfo().GetHostName())
 	suite.Equal(he2.GetHostInfo().GetCapacity().NonSlack.CPU, cpu2)
+	suite.Equal(scalar.HostDraining, he2.GetHostInfo().GetMaintenanceStatus())
+
+	select {
+	case <-suite.hostEventCh:
+		suite.Fail("one event should be sent per agent")
+	default:
+	}
 }
 
 func newTestPelotonPodSpec(podName string) *pbpod.PodSpec {
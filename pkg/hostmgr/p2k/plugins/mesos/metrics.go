@@ -16,15 +16,38 @@ package mesos
 
 import "github.com/uber-go/tally"
 
+// buckets of the fraction of the offered resources used by a launch
+var _utilizationBuckets = tally.MustMakeLinearValueBuckets(0, 0.1, 11)
+
 type metrics struct {
 	scope tally.Scope
 
 	KillPod     tally.Counter
 	KillPodFail tally.Counter
 
+	// Kill retry queue metrics.
+	KillPodRetry     tally.Counter
+	KillPodGiveUp    tally.Counter
+	KillPodAgentDown tally.Counter
+	KillQueueSize    tally.Gauge
+
 	LaunchPod     tally.Counter
 	LaunchPodFail tally.Counter
 
+	// LaunchPodOfferWait counts the launches which waited for offers, and
+	// LaunchPodNoOffer the ones which got none before timing out.
+	LaunchPodOfferWait tally.Counter
+	LaunchPodNoOffer   tally.Counter
+
+	// Per-agent launch throttling metrics. LaunchPodThrottled counts the
+	// launches queued because too many pods were being launched on their
+	// agent, and LaunchPodThrottleFail the ones which timed out queued.
+	LaunchPodThrottled      tally.Counter
+	LaunchPodThrottleFail   tally.Counter
+	LaunchPodThrottleWait   tally.Timer
+	InFlightLaunches        tally.Gauge
+	InFlightLaunchesExpired tally.Counter
+
 	DeclineOffers     tally.Counter
 	DeclineOffersFail tally.Counter
 
@@ -33,7 +56,34 @@ type metrics struct {
 	TaskUpdateAck       tally.Counter
 	TaskUpdateAckDeDupe tally.Counter
 
+	// Ack queue metrics.
+	TaskUpdateAckDropped tally.Counter
+	TaskAckChannelSize   tally.Gauge
+	TaskAckMapSize       tally.Gauge
+
 	AgentIDToHostnameMissing tally.Counter
+
+	// Offer usage metrics.
+	OffersExpired    tally.Counter
+	OffersSuppressed tally.Counter
+	OfferAgeAtLaunch tally.Timer
+
+	// Framework offer suppression metrics.
+	SuppressOffers     tally.Counter
+	SuppressOffersFail tally.Counter
+	ReviveOffers       tally.Counter
+	ReviveOffersFail   tally.Counter
+
+	// Mesos master failover metrics.
+	MesosDisconnected  tally.Counter
+	MesosFailover      tally.Counter
+	ReconcileTasks     tally.Counter
+	ReconcileTasksFail tally.Counter
+
+	// Persistent volume metrics.
+	LaunchStatefulPod       tally.Counter
+	CleanupReservations     tally.Counter
+	CleanupReservationsFail tally.Counter
 }
 
 func newMetrics(scope tally.Scope) *metrics {
@@ -45,13 +95,48 @@ func newMetrics(scope tally.Scope) *metrics {
 
 		KillPod:                  successScope.Counter("kill_pod"),
 		KillPodFail:              failScope.Counter("kill_pod"),
+		KillPodRetry:             scope.Counter("kill_pod_retry"),
+		KillPodGiveUp:            failScope.Counter("kill_pod_give_up"),
+		KillPodAgentDown:         scope.Counter("kill_pod_agent_down"),
+		KillQueueSize:            scope.Gauge("kill_queue_size"),
 		LaunchPod:                successScope.Counter("launch_pod"),
 		LaunchPodFail:            failScope.Counter("launch_pod"),
+		LaunchPodOfferWait:       scope.Counter("launch_pod_offer_wait"),
+		LaunchPodNoOffer:         failScope.Counter("launch_pod_no_offer"),
+		LaunchPodThrottled:       scope.Counter("launch_pod_throttled"),
+		LaunchPodThrottleFail:    failScope.Counter("launch_pod_throttle"),
+		LaunchPodThrottleWait:    scope.Timer("launch_pod_throttle_wait"),
+		InFlightLaunches:         scope.Gauge("inflight_launches"),
+		InFlightLaunchesExpired:  scope.Counter("inflight_launches_expired"),
 		TaskUpdateAck:            successScope.Counter("task_update_ack"),
 		TaskUpdateAckDeDupe:      successScope.Counter("task_update_ack_dedupe"),
+		TaskUpdateAckDropped:     failScope.Counter("task_update_ack_dropped"),
+		TaskAckChannelSize:       scope.Gauge("task_ack_channel_size"),
+		TaskAckMapSize:           scope.Gauge("task_ack_map_size"),
 		DeclineOffers:            successScope.Counter("decline_offers"),
 		DeclineOffersFail:        failScope.Counter("decline_offers"),
 		TaskUpdateCounter:        scope.Counter("task_update"),
 		AgentIDToHostnameMissing: scope.Counter("agent_id_to_hostname_missing"),
+		OffersExpired:            scope.Counter("offers_expired"),
+		OffersSuppressed:         scope.Counter("offers_suppressed"),
+		SuppressOffers:           successScope.Counter("suppress_offers"),
+		SuppressOffersFail:       failScope.Counter("suppress_offers"),
+		ReviveOffers:             successScope.Counter("revive_offers"),
+		ReviveOffersFail:         failScope.Counter("revive_offers"),
+		OfferAgeAtLaunch:         scope.Timer("offer_age_at_launch"),
+		MesosDisconnected:        scope.Counter("mesos_disconnected"),
+		MesosFailover:            scope.Counter("mesos_failover"),
+		ReconcileTasks:           successScope.Counter("reconcile_tasks"),
+		ReconcileTasksFail:       failScope.Counter("reconcile_tasks"),
+		LaunchStatefulPod:        successScope.Counter("launch_stateful_pod"),
+		CleanupReservations:      successScope.Counter("cleanup_reservations"),
+		CleanupReservationsFail:  failScope.Counter("cleanup_reservations"),
 	}
 }
+
+// offerUtilization returns the histogram of the fraction of a kind of
+// offered resource used by each launch.
+func (m *metrics) offerUtilization(kind string) tally.Histogram {
+	return m.scope.Tagged(map[string]string{"resource": kind}).
+		Histogram("offer_utilization", _utilizationBuckets)
+}
@@ -39,13 +39,22 @@ type offerManager struct {
 
 	// Time to hold offer in offer manager
 	offerHoldTime time.Duration
+
+	// map hostname -> expiration of the reservation pinning the offers on
+	// the host for a placement decision
+	reservations map[string]time.Time
+
+	// Maximum number of hosts whose offers are held, 0 for no limit
+	maxHosts int
 }
 
-func newOfferManager(offerHoldTime time.Duration) *offerManager {
+func newOfferManager(offerHoldTime time.Duration, maxHosts int) *offerManager {
 	return &offerManager{
 		hostToOffers:  make(map[string]*mesosOffers),
 		offers:        make(map[string]*timedOffer),
 		offerHoldTime: offerHoldTime,
+		reservations:  make(map[string]time.Time),
+		maxHosts:      maxHosts,
 	}
 }
 
@@ -55,18 +64,32 @@ type mesosOffers struct {
 }
 
 type timedOffer struct {
+	received   time.Time
 	expiration time.Time
 	hostname   string
 }
 
 // AddOffers add hostToOffers into offerManager.hostToOffers, and returns the set
-// of hosts updated
-func (m *offerManager) AddOffers(offers []*mesos.Offer) map[string]struct{} {
+// of hosts updated. Once offers are held for the maximum number of hosts,
+// the offers of other hosts are not added, and their IDs are returned to
+// be declined.
+func (m *offerManager) AddOffers(offers []*mesos.Offer) (
+	map[string]struct{},
+	[]*mesos.OfferID,
+) {
 	m.Lock()
 	defer m.Unlock()
 
 	hostUpdated := make(map[string]struct{})
+	var suppressed []*mesos.OfferID
 	for _, offer := range offers {
+		if _, ok := m.hostToOffers[offer.GetHostname()]; !ok {
+			if m.maxHosts > 0 && len(m.hostToOffers) >= m.maxHosts {
+				suppressed = append(suppressed, offer.GetId())
+				continue
+			}
+		}
+
 		hostUpdated[offer.GetHostname()] = struct{}{}
 
 		if _, ok := m.hostToOffers[offer.GetHostname()]; !ok {
@@ -82,6 +105,11 @@ func (m *offerManager) AddOffers(offers []*mesos.Offer) map[string]struct{} {
 		offer.Resources, _ = hostmgrscalar.FilterMesosResources(
 			offer.Resources,
 			func(r *mesos.Resource) bool {
+				// The resources reserved for stateful pods are not
+				// available to other pods.
+				if getReservationPodID(r) != "" {
+					return false
+				}
 				if r.GetRevocable() == nil {
 					return true
 				}
@@ -91,13 +119,15 @@ func (m *offerManager) AddOffers(offers []*mesos.Offer) map[string]struct{} {
 		mesosOffers.unreservedOffers[offerID] = offer
 
 		// add the offer to the timedOffer map
+		now := time.Now()
 		m.offers[offerID] = &timedOffer{
 			hostname:   offer.GetHostname(),
-			expiration: time.Now().Add(m.offerHoldTime),
+			received:   now,
+			expiration: now.Add(m.offerHoldTime),
 		}
 	}
 
-	return hostUpdated
+	return hostUpdated, suppressed
 }
 
 // RemoveOffer remove the offer specified with offerID in the
@@ -156,10 +186,124 @@ func (m *offerManager) GetOffers(hostname string) map[string]*mesos.Offer {
 	return mesosOffers.unreservedOffers
 }
 
+// GetOfferAges returns how long each of the offers on a host has been
+// held for.
+func (m *offerManager) GetOfferAges(hostname string) []time.Duration {
+	m.RLock()
+	defer m.RUnlock()
+
+	mesosOffers, ok := m.hostToOffers[hostname]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	var ages []time.Duration
+	for offerID := range mesosOffers.unreservedOffers {
+		if timedOffer, ok := m.offers[offerID]; ok {
+			ages = append(ages, now.Sub(timedOffer.received))
+		}
+	}
+	return ages
+}
+
+// GetOldestOfferAge returns the number of offers held, and how long the
+// oldest of them has been held for.
+func (m *offerManager) GetOldestOfferAge() (int, time.Duration) {
+	m.RLock()
+	defer m.RUnlock()
+
+	now := time.Now()
+	var oldest time.Duration
+	for _, timedOffer := range m.offers {
+		if age := now.Sub(timedOffer.received); age > oldest {
+			oldest = age
+		}
+	}
+	return len(m.offers), oldest
+}
+
+// ReserveOffers pins the offers on a host for a placement decision until
+// the ttl elapses, the reservation is released, or the offers are used.
+// Pinned offers are not removed when they expire.
+func (m *offerManager) ReserveOffers(hostname string, ttl time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.reservations[hostname] = time.Now().Add(ttl)
+}
+
+// ReleaseReservation unpins the offers on a host.
+func (m *offerManager) ReleaseReservation(hostname string) {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.reservations, hostname)
+}
+
+// GetReservation returns the expiration of the reservation on a host, and
+// whether the host has a reservation which has not expired yet.
+func (m *offerManager) GetReservation(hostname string) (time.Time, bool) {
+	m.RLock()
+	defer m.RUnlock()
+
+	expiration, ok := m.reservations[hostname]
+	if !ok || !time.Now().Before(expiration) {
+		return time.Time{}, false
+	}
+	return expiration, true
+}
+
+// RemoveExpiredOffers removes the offers which have been held for longer
+// than the offer hold time without being used, unless they are pinned by a
+// reservation. It returns the IDs of the offers removed and the set of
+// hosts updated.
+func (m *offerManager) RemoveExpiredOffers() (
+	[]*mesos.OfferID,
+	map[string]struct{},
+) {
+	m.Lock()
+	defer m.Unlock()
+
+	now := time.Now()
+	for hostname, expiration := range m.reservations {
+		if !now.Before(expiration) {
+			delete(m.reservations, hostname)
+		}
+	}
+
+	var offerIDs []*mesos.OfferID
+	hostUpdated := make(map[string]struct{})
+	for offerID, timedOffer := range m.offers {
+		if now.Before(timedOffer.expiration) {
+			continue
+		}
+		if _, ok := m.reservations[timedOffer.hostname]; ok {
+			continue
+		}
+
+		id := offerID
+		offerIDs = append(offerIDs, &mesos.OfferID{Value: &id})
+		hostUpdated[timedOffer.hostname] = struct{}{}
+		delete(m.offers, offerID)
+
+		if mesosOffers, ok := m.hostToOffers[timedOffer.hostname]; ok {
+			delete(mesosOffers.unreservedOffers, offerID)
+			if len(mesosOffers.unreservedOffers) == 0 {
+				delete(m.hostToOffers, timedOffer.hostname)
+			}
+		}
+	}
+	return offerIDs, hostUpdated
+}
+
+// RemoveOfferForHost removes all of the offers on a host, and releases the
+// reservation pinning them.
 func (m *offerManager) RemoveOfferForHost(hostname string) {
 	m.Lock()
 	defer m.Unlock()
 
+	delete(m.reservations, hostname)
 	if mesosOffer, ok := m.hostToOffers[hostname]; ok {
 		for offerID := range mesosOffer.unreservedOffers {
 			delete(m.offers, offerID)
@@ -182,10 +326,21 @@ func (m *offerManager) GetResources(hostname string) hostmgrscalar.Resources {
 	return hmutil.GetResourcesFromOffers(mesosOffers.unreservedOffers)
 }
 
-func (m *offerManager) Clear() {
+// Clear removes all of the offers and reservations, and returns the hosts
+// which had offers.
+func (m *offerManager) Clear() []string {
 	m.Lock()
-	m.Unlock()
+	defer m.Unlock()
+
+	var hosts []string
+	for hostname, mesosOffers := range m.hostToOffers {
+		if len(mesosOffers.unreservedOffers) != 0 {
+			hosts = append(hosts, hostname)
+		}
+	}
 
 	m.hostToOffers = make(map[string]*mesosOffers)
 	m.offers = make(map[string]*timedOffer)
+	m.reservations = make(map[string]time.Time)
+	return hosts
 }
@@ -0,0 +1,146 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesos
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common"
+	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
+)
+
+// OfferStatsPath is the endpoint reporting how long the offers are held
+// by the mesos plugin and how much of them is used.
+const OfferStatsPath = "/debug/offers"
+
+// OfferStats summarizes the usage of the offers received by the mesos
+// plugin, to tune the offer hold time and the placement batch sizes.
+type OfferStats struct {
+	// Number of offers currently held
+	HeldOffers int `json:"held_offers"`
+	// How long the oldest offer currently held has been held for
+	OldestHeldOfferAgeSeconds float64 `json:"oldest_held_offer_age_seconds"`
+	// Number of offers pods were launched on
+	LaunchedOffers uint64 `json:"launched_offers"`
+	// Number of offers which expired without being used
+	ExpiredOffers uint64 `json:"expired_offers"`
+	// Mean time the offers pods were launched on were held for
+	MeanOfferAgeAtLaunchSeconds float64 `json:"mean_offer_age_at_launch_seconds"`
+	// Mean fraction of the offered resources used by each launch, keyed
+	// by resource kind
+	MeanUtilization map[string]float64 `json:"mean_utilization"`
+}
+
+// offerStats accumulates the usage of the offers since the plugin started.
+// The zero value is ready to use.
+type offerStats struct {
+	sync.Mutex
+
+	launches         uint64
+	launchedOffers   uint64
+	expiredOffers    uint64
+	totalAgeAtLaunch time.Duration
+	totalUtilization map[string]float64
+}
+
+// getUtilization returns the fraction of each kind of offered resource
+// used, for the kinds of resources offered.
+func getUtilization(offered, used hmscalar.Resources) map[string]float64 {
+	utilization := make(map[string]float64)
+	for kind, values := range map[string][2]float64{
+		common.CPU:    {offered.GetCPU(), used.GetCPU()},
+		common.MEMORY: {offered.GetMem(), used.GetMem()},
+		common.DISK:   {offered.GetDisk(), used.GetDisk()},
+		common.GPU:    {offered.GetGPU(), used.GetGPU()},
+	} {
+		if values[0] > 0 {
+			utilization[kind] = values[1] / values[0]
+		}
+	}
+	return utilization
+}
+
+// recordLaunch records the ages of the offers a launch used, and the
+// fraction of their resources it used.
+func (s *offerStats) recordLaunch(
+	ages []time.Duration,
+	utilization map[string]float64,
+) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.launches++
+	s.launchedOffers += uint64(len(ages))
+	for _, age := range ages {
+		s.totalAgeAtLaunch += age
+	}
+	if s.totalUtilization == nil {
+		s.totalUtilization = make(map[string]float64)
+	}
+	for kind, fraction := range utilization {
+		s.totalUtilization[kind] += fraction
+	}
+}
+
+// recordExpired records offers which expired without being used.
+func (s *offerStats) recordExpired(count int) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.expiredOffers += uint64(count)
+}
+
+// get returns the summary of the usage of the offers.
+func (s *offerStats) get() *OfferStats {
+	s.Lock()
+	defer s.Unlock()
+
+	stats := &OfferStats{
+		LaunchedOffers:  s.launchedOffers,
+		ExpiredOffers:   s.expiredOffers,
+		MeanUtilization: make(map[string]float64),
+	}
+	if s.launchedOffers > 0 {
+		stats.MeanOfferAgeAtLaunchSeconds =
+			(s.totalAgeAtLaunch / time.Duration(s.launchedOffers)).Seconds()
+	}
+	if s.launches > 0 {
+		for kind, total := range s.totalUtilization {
+			stats.MeanUtilization[kind] = total / float64(s.launches)
+		}
+	}
+	return stats
+}
+
+// OfferStatsHandler reports the usage of the offers received by the
+// plugin as a JSON object.
+func (m *MesosManager) OfferStatsHandler(w http.ResponseWriter, _ *http.Request) {
+	stats := m.offerStats.get()
+	heldOffers, oldest := m.offerManager.GetOldestOfferAge()
+	stats.HeldOffers = heldOffers
+	stats.OldestHeldOfferAgeSeconds = oldest.Seconds()
+
+	body, err := json.Marshal(stats)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
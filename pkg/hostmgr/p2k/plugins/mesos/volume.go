@@ -0,0 +1,222 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesos
+
+import (
+	"context"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/hostmgr/factory/task"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+)
+
+// _reservationPodIDLabel is the label of the resources reserved for a
+// stateful pod, which holds the ID of the pod.
+const _reservationPodIDLabel = "peloton.pod_id"
+
+// _volumeIDSuffix is appended to the ID of a stateful pod to get the ID
+// of its persistent volume.
+const _volumeIDSuffix = "-volume"
+
+// isStatefulPod returns whether a pod needs a persistent volume.
+func isStatefulPod(spec *pbpod.PodSpec) bool {
+	return spec.GetVolume().GetSizeMb() > 0
+}
+
+// getReservationPodID returns the ID of the stateful pod for which a
+// resource is reserved, or an empty string if the resource is not
+// reserved for a stateful pod.
+func getReservationPodID(r *mesos.Resource) string {
+	for _, label := range r.GetReservation().GetLabels().GetLabels() {
+		if label.GetKey() == _reservationPodIDLabel {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+// buildVolumeOperations builds the operations dynamically reserving the
+// resources of the Mesos task launching a stateful pod, and creating its
+// persistent volume from disk taken from the offers. The resources of the
+// task are replaced with the reserved resources and the volume, so that
+// the task is launched with them in the same ACCEPT call.
+func (m *MesosManager) buildVolumeOperations(
+	builder *task.Builder,
+	podID string,
+	spec *pbpod.PodSpec,
+	mesosTask *mesos.TaskInfo,
+) ([]*mesos.Offer_Operation, error) {
+	disk, err := builder.ExtractResources(&pbtask.ResourceConfig{
+		DiskLimitMb: float64(spec.GetVolume().GetSizeMb()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labelKey := _reservationPodIDLabel
+	reservation := &mesos.Resource_ReservationInfo{
+		Principal: &m.config.Principal,
+		Labels: &mesos.Labels{
+			Labels: []*mesos.Label{{Key: &labelKey, Value: &podID}},
+		},
+	}
+	reserve := func(r *mesos.Resource) *mesos.Resource {
+		reserved := proto.Clone(r).(*mesos.Resource)
+		reserved.Role = &m.config.Role
+		reserved.Reservation = reservation
+		return reserved
+	}
+
+	var reserved, taskResources []*mesos.Resource
+	for _, r := range mesosTask.GetResources() {
+		// Ports are not reserved, they are assigned on each launch.
+		if r.GetName() == common.MesosPorts {
+			taskResources = append(taskResources, r)
+			continue
+		}
+		reserved = append(reserved, reserve(r))
+	}
+	taskResources = append(taskResources, reserved...)
+
+	var volumes []*mesos.Resource
+	volumeID := podID + _volumeIDSuffix
+	mode := mesos.Volume_RW
+	for _, r := range disk {
+		reservedDisk := reserve(r)
+		reserved = append(reserved, reservedDisk)
+
+		volume := proto.Clone(reservedDisk).(*mesos.Resource)
+		volume.Disk = &mesos.Resource_DiskInfo{
+			Persistence: &mesos.Resource_DiskInfo_Persistence{
+				Id:        &volumeID,
+				Principal: &m.config.Principal,
+			},
+			Volume: &mesos.Volume{
+				ContainerPath: &spec.GetVolume().ContainerPath,
+				Mode:          &mode,
+			},
+		}
+		volumes = append(volumes, volume)
+	}
+	mesosTask.Resources = append(taskResources, volumes...)
+
+	reserveType := mesos.Offer_Operation_RESERVE
+	createType := mesos.Offer_Operation_CREATE
+	return []*mesos.Offer_Operation{
+		{
+			Type:    &reserveType,
+			Reserve: &mesos.Offer_Operation_Reserve{Resources: reserved},
+		},
+		{
+			Type:   &createType,
+			Create: &mesos.Offer_Operation_Create{Volumes: volumes},
+		},
+	}, nil
+}
+
+// cleanupOrphanedReservations reconciles the resources reserved for
+// stateful pods in the offers against the stateful pods launched by the
+// manager. Reserved resources are only offered when they are not used, so
+// the persistent volumes and reservations of the pods which are not known,
+// for example because they are terminated, are destroyed and unreserved.
+// It returns the offers which are not consumed by the cleanup.
+func (m *MesosManager) cleanupOrphanedReservations(
+	ctx context.Context,
+	offers []*mesos.Offer,
+) []*mesos.Offer {
+	var result []*mesos.Offer
+	for _, offer := range offers {
+		var volumes, reserved []*mesos.Resource
+		for _, r := range offer.GetResources() {
+			podID := getReservationPodID(r)
+			if podID == "" {
+				continue
+			}
+			if _, ok := m.statefulPods.Load(podID); ok {
+				continue
+			}
+
+			if r.GetDisk().GetPersistence() != nil {
+				volumes = append(volumes, r)
+				// The disk of the volume is unreserved once the volume
+				// is destroyed.
+				r = proto.Clone(r).(*mesos.Resource)
+				r.Disk = nil
+			}
+			reserved = append(reserved, r)
+		}
+
+		if len(reserved) == 0 {
+			result = append(result, offer)
+			continue
+		}
+
+		if err := m.acceptCleanupOperations(
+			ctx, offer.GetId(), volumes, reserved); err != nil {
+			log.WithError(err).
+				WithField("offer_id", offer.GetId().GetValue()).
+				Warn("Failed to clean up orphaned reservations")
+			m.metrics.CleanupReservationsFail.Inc(1)
+			// The offer is declined once it expires, and the
+			// reservations are cleaned up when offered again.
+			result = append(result, offer)
+			continue
+		}
+		m.metrics.CleanupReservations.Inc(1)
+	}
+	return result
+}
+
+// acceptCleanupOperations accepts an offer to destroy the given persistent
+// volumes, and unreserve the given resources.
+func (m *MesosManager) acceptCleanupOperations(
+	ctx context.Context,
+	offerID *mesos.OfferID,
+	volumes []*mesos.Resource,
+	reserved []*mesos.Resource,
+) error {
+	var operations []*mesos.Offer_Operation
+	if len(volumes) != 0 {
+		destroyType := mesos.Offer_Operation_DESTROY
+		operations = append(operations, &mesos.Offer_Operation{
+			Type:    &destroyType,
+			Destroy: &mesos.Offer_Operation_Destroy{Volumes: volumes},
+		})
+	}
+	unreserveType := mesos.Offer_Operation_UNRESERVE
+	operations = append(operations, &mesos.Offer_Operation{
+		Type:      &unreserveType,
+		Unreserve: &mesos.Offer_Operation_Unreserve{Resources: reserved},
+	})
+
+	callType := sched.Call_ACCEPT
+	msg := &sched.Call{
+		FrameworkId: m.frameworkInfoProvider.GetFrameworkID(ctx),
+		Type:        &callType,
+		Accept: &sched.Call_Accept{
+			OfferIds:   []*mesos.OfferID{offerID},
+			Operations: operations,
+		},
+	}
+	msid := m.frameworkInfoProvider.GetMesosStreamID(ctx)
+	return m.schedulerClient.Call(msid, msg)
+}
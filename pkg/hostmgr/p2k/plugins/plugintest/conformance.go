@@ -0,0 +1,248 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugintest provides a conformance test suite for the
+// implementations of the p2k plugin interface, so that a new cluster
+// manager plugin can be validated against the behavior host manager
+// expects from all of them.
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/hostmgr/models"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/plugins"
+	"github.com/uber/peloton/pkg/hostmgr/p2k/scalar"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// _eventTimeout is how long to wait for an event from the plugin.
+const _eventTimeout = 5 * time.Second
+
+// _eventChanSize is the size of the event channels given to the plugin.
+const _eventChanSize = 1000
+
+// PluginFactory creates the plugin under test, which sends its events to
+// the given channels, and returns the name of a host of its cluster on
+// which the test pods can be launched. It is called once per test.
+type PluginFactory func(
+	podEventCh chan<- *scalar.PodEvent,
+	hostEventCh chan<- *scalar.HostEvent,
+) (plugin plugins.Plugin, hostname string)
+
+// RunConformanceTests runs the conformance test suite against the plugins
+// created by factory.
+func RunConformanceTests(t *testing.T, factory PluginFactory) {
+	suite.Run(t, &conformanceSuite{factory: factory})
+}
+
+type conformanceSuite struct {
+	suite.Suite
+
+	factory PluginFactory
+
+	podEventCh  chan *scalar.PodEvent
+	hostEventCh chan *scalar.HostEvent
+	plugin      plugins.Plugin
+	hostname    string
+}
+
+func (suite *conformanceSuite) SetupTest() {
+	suite.podEventCh = make(chan *scalar.PodEvent, _eventChanSize)
+	suite.hostEventCh = make(chan *scalar.HostEvent, _eventChanSize)
+	suite.plugin, suite.hostname = suite.factory(
+		suite.podEventCh,
+		suite.hostEventCh,
+	)
+	suite.NoError(suite.plugin.Start())
+}
+
+func (suite *conformanceSuite) TearDownTest() {
+	suite.plugin.Stop()
+}
+
+// waitForHostEvent waits for an event of the test host, and returns it.
+func (suite *conformanceSuite) waitForHostEvent() *scalar.HostEvent {
+	timeout := time.After(_eventTimeout)
+	for {
+		select {
+		case evt := <-suite.hostEventCh:
+			if evt.GetHostInfo().GetHostName() == suite.hostname {
+				return evt
+			}
+		case <-timeout:
+			suite.FailNow("no event received for host " + suite.hostname)
+			return nil
+		}
+	}
+}
+
+// waitForPodEvent waits for an event of a pod whose state is terminal or
+// not, as requested, and returns it. The events of the other pods and the
+// host events are skipped.
+func (suite *conformanceSuite) waitForPodEvent(
+	podID string,
+	terminal bool,
+) *scalar.PodEvent {
+	timeout := time.After(_eventTimeout)
+	for {
+		select {
+		case evt := <-suite.podEventCh:
+			suite.plugin.AckPodEvent(evt)
+			if evt.Event.GetPodId().GetValue() != podID {
+				continue
+			}
+			state := pbpod.PodState(
+				pbpod.PodState_value[evt.Event.GetActualState()])
+			if util.IsPelotonPodStateTerminal(state) == terminal {
+				return evt
+			}
+		case <-suite.hostEventCh:
+		case <-timeout:
+			suite.FailNow("no event received for pod " + podID)
+			return nil
+		}
+	}
+}
+
+// newLaunchablePods returns pods with small resources to launch on the
+// test host.
+func (suite *conformanceSuite) newLaunchablePods(
+	num int,
+) []*models.LaunchablePod {
+	var pods []*models.LaunchablePod
+	for i := 0; i < num; i++ {
+		pods = append(pods, &models.LaunchablePod{
+			PodId: &peloton.PodID{
+				Value: fmt.Sprintf("conformance-%d-%d", time.Now().UnixNano(), i),
+			},
+			Spec: &pbpod.PodSpec{
+				Containers: []*pbpod.ContainerSpec{
+					{
+						Name: "conformance",
+						Resource: &pbpod.ResourceSpec{
+							CpuLimit:   0.1,
+							MemLimitMb: 32,
+						},
+					},
+				},
+			},
+		})
+	}
+	return pods
+}
+
+// launchPods launches pods on the test host and waits for them to run.
+func (suite *conformanceSuite) launchPods(num int) []*models.LaunchablePod {
+	pods := suite.newLaunchablePods(num)
+	launched, err := suite.plugin.LaunchPods(
+		context.Background(),
+		pods,
+		suite.hostname,
+	)
+	suite.Require().NoError(err)
+	suite.Require().Len(launched, num)
+
+	for _, pod := range pods {
+		evt := suite.waitForPodEvent(pod.PodId.GetValue(), false)
+		suite.Equal(suite.hostname, evt.Event.GetHostname())
+	}
+	return pods
+}
+
+// TestStartStop tests that starting and stopping the plugin more than once
+// is safe.
+func (suite *conformanceSuite) TestStartStop() {
+	suite.NoError(suite.plugin.Start())
+	suite.plugin.Stop()
+	suite.plugin.Stop()
+}
+
+// TestHostEvents tests that the plugin sends the events of its hosts.
+func (suite *conformanceSuite) TestHostEvents() {
+	evt := suite.waitForHostEvent()
+	suite.Equal(suite.hostname, evt.GetHostInfo().GetHostName())
+}
+
+// TestReconcileHosts tests that the plugin returns the state of its hosts.
+func (suite *conformanceSuite) TestReconcileHosts() {
+	hostInfos, err := suite.plugin.ReconcileHosts()
+	suite.NoError(err)
+
+	var found bool
+	for _, hostInfo := range hostInfos {
+		if hostInfo.GetHostName() == suite.hostname {
+			found = true
+		}
+	}
+	suite.True(found, "host %s not reconciled", suite.hostname)
+}
+
+// TestLaunchPods tests that launched pods are reported as running on the
+// host they were launched on.
+func (suite *conformanceSuite) TestLaunchPods() {
+	suite.launchPods(2)
+}
+
+// TestLaunchPodsUnknownHost tests that launching pods on a host which is
+// not part of the cluster fails.
+func (suite *conformanceSuite) TestLaunchPodsUnknownHost() {
+	ctx, cancel := context.WithTimeout(context.Background(), _eventTimeout)
+	defer cancel()
+
+	_, err := suite.plugin.LaunchPods(
+		ctx,
+		suite.newLaunchablePods(1),
+		"conformance-unknown-host",
+	)
+	suite.Error(err)
+}
+
+// TestKillPod tests that killed pods are reported as terminated.
+func (suite *conformanceSuite) TestKillPod() {
+	pods := suite.launchPods(1)
+	podID := pods[0].PodId.GetValue()
+
+	suite.NoError(suite.plugin.KillPod(context.Background(), podID))
+	suite.waitForPodEvent(podID, true)
+}
+
+// TestUpdatePodResources tests that the resources of a running pod are
+// updated in place by plugins which support it, and that other plugins
+// report it is not supported.
+func (suite *conformanceSuite) TestUpdatePodResources() {
+	pods := suite.launchPods(1)
+	spec := pods[0].Spec
+	spec.Containers[0].Resource.CpuLimit = 0.2
+
+	err := suite.plugin.UpdatePodResources(
+		context.Background(),
+		pods[0].PodId.GetValue(),
+		spec,
+	)
+	if suite.plugin.SupportsPodResize() {
+		suite.NoError(err)
+	} else {
+		suite.True(yarpcerrors.IsUnimplemented(err))
+	}
+}
@@ -1,8 +1,13 @@
 package scalar
 
 import (
+	"sort"
 	"strconv"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
 
@@ -27,6 +32,61 @@ const (
 	UpdateAgent
 )
 
+// MaintenanceStatus describes the maintenance status of a host.
+type MaintenanceStatus int
+
+const (
+	// HostUp means the host is not in maintenance.
+	HostUp MaintenanceStatus = iota
+	// HostDraining means the host is scheduled for maintenance, and pods
+	// should not be placed on it anymore.
+	HostDraining
+	// HostDrained means the host is scheduled for maintenance, and no pod
+	// runs on it anymore.
+	HostDrained
+	// HostDown means the host is in maintenance.
+	HostDown
+)
+
+// String returns a user-friendly name for the maintenance status.
+func (s MaintenanceStatus) String() string {
+	switch s {
+	case HostUp:
+		return "up"
+	case HostDraining:
+		return "draining"
+	case HostDrained:
+		return "drained"
+	case HostDown:
+		return "down"
+	default:
+		return "unknown"
+	}
+}
+
+// Effects of a taint on the pods not tolerating it.
+const (
+	// TaintEffectNoSchedule means pods are not placed on the host.
+	TaintEffectNoSchedule = string(corev1.TaintEffectNoSchedule)
+	// TaintEffectPreferNoSchedule means pods should preferably not be
+	// placed on the host.
+	TaintEffectPreferNoSchedule = string(corev1.TaintEffectPreferNoSchedule)
+	// TaintEffectNoExecute means pods are not placed on the host, and
+	// running pods are evicted.
+	TaintEffectNoExecute = string(corev1.TaintEffectNoExecute)
+)
+
+// Taint of a host, which repels the pods not tolerating it.
+// This is k8s specific.
+type Taint struct {
+	// Key of the taint.
+	Key string
+	// Value of the taint.
+	Value string
+	// Effect of the taint on the pods not tolerating it.
+	Effect string
+}
+
 // HostEvent contains information about the host, event type and resource
 // version for that event.
 type HostEvent struct {
@@ -59,6 +119,22 @@ type HostInfo struct {
 	available models.HostResources
 	// Resource version for this host. This is k8s specific.
 	resourceVersion string
+	// Attributes of the host. This is mesos specific.
+	attributes []*mesos.Attribute
+	// Region of the fault domain of the host. This is mesos specific.
+	region string
+	// Zone of the fault domain of the host. This is mesos specific.
+	zone string
+	// Maintenance status of the host. This is mesos specific.
+	maintenance MaintenanceStatus
+	// Labels of the node. This is k8s specific.
+	nodeLabels map[string]string
+	// Taints of the node. This is k8s specific.
+	taints []Taint
+	// Resources of the host which can be allocated to pods, i.e. the
+	// capacity minus the resources reserved for the system. This is k8s
+	// specific.
+	allocatable models.HostResources
 }
 
 // GetHostName is helper function to get name of the host.
@@ -86,6 +162,94 @@ func (h *HostInfo) GetResourceVersion() string {
 	return h.resourceVersion
 }
 
+// GetAttributes is helper function to get attributes of the host.
+func (h *HostInfo) GetAttributes() []*mesos.Attribute {
+	return h.attributes
+}
+
+// GetRegion is helper function to get fault domain region of the host.
+func (h *HostInfo) GetRegion() string {
+	return h.region
+}
+
+// GetZone is helper function to get fault domain zone of the host.
+func (h *HostInfo) GetZone() string {
+	return h.zone
+}
+
+// GetMaintenanceStatus is helper function to get maintenance status of
+// the host.
+func (h *HostInfo) GetMaintenanceStatus() MaintenanceStatus {
+	return h.maintenance
+}
+
+// GetTaints is helper function to get taints of the host.
+func (h *HostInfo) GetTaints() []Taint {
+	return h.taints
+}
+
+// GetAllocatable is helper function to get allocatable resources of the
+// host.
+func (h *HostInfo) GetAllocatable() models.HostResources {
+	return h.allocatable
+}
+
+// GetLabels returns the attributes, the fault domain and the node labels
+// of the host as labels, which can be used to evaluate placement
+// constraints. Range attributes are not supported and are skipped.
+func (h *HostInfo) GetLabels() []*peloton.Label {
+	var labels []*peloton.Label
+	for _, attr := range h.attributes {
+		key := attr.GetName()
+		switch attr.GetType() {
+		case mesos.Value_TEXT:
+			labels = append(labels, &peloton.Label{
+				Key:   key,
+				Value: attr.GetText().GetValue(),
+			})
+		case mesos.Value_SCALAR:
+			labels = append(labels, &peloton.Label{
+				Key: key,
+				Value: strconv.FormatFloat(
+					attr.GetScalar().GetValue(), 'f', 6, 64),
+			})
+		case mesos.Value_SET:
+			for _, item := range attr.GetSet().GetItem() {
+				labels = append(labels, &peloton.Label{Key: key, Value: item})
+			}
+		}
+	}
+
+	if h.region != "" {
+		labels = append(labels, &peloton.Label{
+			Key:   common.RegionKey,
+			Value: h.region,
+		})
+	}
+	if h.zone != "" {
+		labels = append(labels, &peloton.Label{
+			Key:   common.ZoneKey,
+			Value: h.zone,
+		})
+	}
+
+	keys := make([]string, 0, len(h.nodeLabels))
+	for key := range h.nodeLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		labels = append(labels, &peloton.Label{
+			Key:   key,
+			Value: h.nodeLabels[key],
+		})
+	}
+	return labels
+}
+
+// gpuResourceName is the name of the k8s extended resource for GPUs.
+const gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
 // Initialize each host disk capacity to 1T by default for k8s.
 // This is because k8s does not have concept of disk resource.
 func getDefaultDiskMbPerHost() float64 {
@@ -105,13 +269,23 @@ func BuildHostEventFromNode(
 		return nil, err
 	}
 
-	nonSlackCap := hmscalar.Resources{
-		CPU: float64(
-			node.Status.Capacity.Cpu().MilliValue()) / 1000,
-		Mem: float64(
-			node.Status.Capacity.Memory().MilliValue()) / 1000000000,
-		Disk: getDefaultDiskMbPerHost(),
-		GPU:  0,
+	var allocatable models.HostResources
+	if len(node.Status.Allocatable) > 0 {
+		allocatable.NonSlack = toScalarResources(node.Status.Allocatable)
+	}
+
+	var taints []Taint
+	for _, t := range node.Spec.Taints {
+		taints = append(taints, Taint{
+			Key:    t.Key,
+			Value:  t.Value,
+			Effect: string(t.Effect),
+		})
+	}
+
+	nodeLabels := make(map[string]string, len(node.Labels))
+	for k, v := range node.Labels {
+		nodeLabels[k] = v
 	}
 
 	return &HostEvent{
@@ -120,14 +294,32 @@ func BuildHostEventFromNode(
 			podMap:   podMap,
 			capacity: models.HostResources{
 				Slack:    hmscalar.Resources{},
-				NonSlack: nonSlackCap,
+				NonSlack: toScalarResources(node.Status.Capacity),
 			},
 			resourceVersion: rv,
+			nodeLabels:      nodeLabels,
+			taints:          taints,
+			allocatable:     allocatable,
 		},
 		eventType: e,
 	}, nil
 }
 
+// toScalarResources converts a k8s resource list of a node into
+// scalar resources.
+func toScalarResources(list corev1.ResourceList) hmscalar.Resources {
+	res := hmscalar.Resources{
+		CPU:  float64(list.Cpu().MilliValue()) / 1000,
+		Mem:  float64(list.Memory().MilliValue()) / 1000000000,
+		Disk: getDefaultDiskMbPerHost(),
+		GPU:  0,
+	}
+	if gpu, ok := list[gpuResourceName]; ok {
+		res.GPU = float64(gpu.Value())
+	}
+	return res
+}
+
 // BuildHostEventFromResource builds a host event from underlying resource
 func BuildHostEventFromResource(
 	hostname string,
@@ -147,6 +339,31 @@ func BuildHostEventFromResource(
 	}
 }
 
+// BuildHostEventFromAgent builds a host event from mesos agent info,
+// including the agent attributes, fault domain and maintenance status.
+func BuildHostEventFromAgent(
+	agent *mesosmaster.Response_GetAgents_Agent,
+	maintenance MaintenanceStatus,
+	e HostEventType,
+) *HostEvent {
+	agentInfo := agent.GetAgentInfo()
+	faultDomain := agentInfo.GetDomain().GetFaultDomain()
+	return &HostEvent{
+		hostInfo: &HostInfo{
+			hostname: agentInfo.GetHostname(),
+			podMap:   make(map[string]models.HostResources),
+			capacity: models.HostResources{
+				NonSlack: hmscalar.FromMesosResources(agent.GetTotalResources()),
+			},
+			attributes:  agentInfo.GetAttributes(),
+			region:      faultDomain.GetRegion().GetName(),
+			zone:        faultDomain.GetZone().GetName(),
+			maintenance: maintenance,
+		},
+		eventType: e,
+	}
+}
+
 // IsOldVersion is a very k8s specific check.
 // TODO: make this an interface with a noop impl for Mesos.
 // Check if the event has already been received. When we start k8s node
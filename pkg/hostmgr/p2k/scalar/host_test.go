@@ -5,6 +5,10 @@ import (
 	"testing"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/hostmgr/models"
 	hmscalar "github.com/uber/peloton/pkg/hostmgr/scalar"
 
@@ -52,6 +56,16 @@ func TestBuildHostEventFromNode(t *testing.T) {
 					GPU:  0,
 				},
 			},
+			nodeLabels: map[string]string{},
+			allocatable: models.HostResources{
+				NonSlack: hmscalar.Resources{
+					CPU: float64(32),
+					Mem: float64(
+						node.Status.Capacity.Memory().MilliValue()) / 1000000000,
+					Disk: getDefaultDiskMbPerHost(),
+					GPU:  0,
+				},
+			},
 		},
 		eventType: AddHost,
 	}
@@ -60,3 +74,173 @@ func TestBuildHostEventFromNode(t *testing.T) {
 	require.Nil(err)
 	require.True(reflect.DeepEqual(expectedHostEvent, hostEvent))
 }
+
+func TestBuildHostEventFromNodeWithGPU(t *testing.T) {
+	require := require.New(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-gpu-node",
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("32"),
+				corev1.ResourceMemory: resource.MustParse("96Gi"),
+				gpuResourceName:       resource.MustParse("4"),
+			},
+		},
+	}
+
+	hostEvent, err := BuildHostEventFromNode(node, AddHost)
+	require.NoError(err)
+	require.Equal(
+		float64(4),
+		hostEvent.GetHostInfo().GetCapacity().NonSlack.GPU,
+	)
+}
+
+// TestBuildHostEventFromNodeWithLabelsAndTaints tests that the labels,
+// taints and allocatable resources of a node are added to the host info.
+func TestBuildHostEventFromNodeWithLabelsAndTaints(t *testing.T) {
+	require := require.New(t)
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Labels: map[string]string{
+				"zone": "us-west",
+				"disk": "ssd",
+			},
+		},
+		Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{
+				{
+					Key:    "dedicated",
+					Value:  "batch",
+					Effect: corev1.TaintEffectNoSchedule,
+				},
+			},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("32"),
+				corev1.ResourceMemory: resource.MustParse("96Gi"),
+			},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("30"),
+				corev1.ResourceMemory: resource.MustParse("90Gi"),
+			},
+		},
+	}
+
+	hostEvent, err := BuildHostEventFromNode(node, AddHost)
+	require.NoError(err)
+
+	hostInfo := hostEvent.GetHostInfo()
+	require.Equal([]Taint{
+		{Key: "dedicated", Value: "batch", Effect: TaintEffectNoSchedule},
+	}, hostInfo.GetTaints())
+	require.Equal([]*peloton.Label{
+		{Key: "disk", Value: "ssd"},
+		{Key: "zone", Value: "us-west"},
+	}, hostInfo.GetLabels())
+	require.Equal(float64(32), hostInfo.GetCapacity().NonSlack.CPU)
+	require.Equal(float64(30), hostInfo.GetAllocatable().NonSlack.CPU)
+	require.Equal(
+		float64(node.Status.Allocatable.Memory().MilliValue())/1000000000,
+		hostInfo.GetAllocatable().NonSlack.Mem,
+	)
+}
+
+func TestBuildHostEventFromAgent(t *testing.T) {
+	require := require.New(t)
+
+	hostname := "test-agent"
+	cpuName := "cpus"
+	cpu := 8.0
+	rackName := "rack"
+	rack := "rack1"
+	textType := mesos.Value_TEXT
+	region := "region1"
+	zone := "zone1"
+
+	agent := &mesosmaster.Response_GetAgents_Agent{
+		AgentInfo: &mesos.AgentInfo{
+			Hostname: &hostname,
+			Attributes: []*mesos.Attribute{{
+				Name: &rackName,
+				Type: &textType,
+				Text: &mesos.Value_Text{Value: &rack},
+			}},
+			Domain: &mesos.DomainInfo{
+				FaultDomain: &mesos.DomainInfo_FaultDomain{
+					Region: &mesos.DomainInfo_FaultDomain_RegionInfo{
+						Name: &region,
+					},
+					Zone: &mesos.DomainInfo_FaultDomain_ZoneInfo{
+						Name: &zone,
+					},
+				},
+			},
+		},
+		TotalResources: []*mesos.Resource{{
+			Name:   &cpuName,
+			Scalar: &mesos.Value_Scalar{Value: &cpu},
+		}},
+	}
+
+	hostEvent := BuildHostEventFromAgent(agent, HostDraining, UpdateAgent)
+	require.Equal(UpdateAgent, hostEvent.GetEventType())
+
+	hostInfo := hostEvent.GetHostInfo()
+	require.Equal(hostname, hostInfo.GetHostName())
+	require.Equal(cpu, hostInfo.GetCapacity().NonSlack.CPU)
+	require.Len(hostInfo.GetAttributes(), 1)
+	require.Equal(rack, hostInfo.GetAttributes()[0].GetText().GetValue())
+	require.Equal(region, hostInfo.GetRegion())
+	require.Equal(zone, hostInfo.GetZone())
+	require.Equal(HostDraining, hostInfo.GetMaintenanceStatus())
+
+	require.Equal([]*peloton.Label{
+		{Key: rackName, Value: rack},
+		{Key: common.RegionKey, Value: region},
+		{Key: common.ZoneKey, Value: zone},
+	}, hostInfo.GetLabels())
+}
+
+func TestHostInfoGetLabels(t *testing.T) {
+	require := require.New(t)
+
+	scalarName := "cores"
+	scalarType := mesos.Value_SCALAR
+	scalarValue := 2.0
+	setName := "tags"
+	setType := mesos.Value_SET
+	rangesName := "ranges"
+	rangesType := mesos.Value_RANGES
+
+	hostInfo := &HostInfo{
+		attributes: []*mesos.Attribute{
+			{
+				Name:   &scalarName,
+				Type:   &scalarType,
+				Scalar: &mesos.Value_Scalar{Value: &scalarValue},
+			},
+			{
+				Name: &setName,
+				Type: &setType,
+				Set:  &mesos.Value_Set{Item: []string{"a", "b"}},
+			},
+			{
+				Name: &rangesName,
+				Type: &rangesType,
+			},
+		},
+	}
+
+	require.Equal([]*peloton.Label{
+		{Key: scalarName, Value: "2.000000"},
+		{Key: setName, Value: "a"},
+		{Key: setName, Value: "b"},
+	}, hostInfo.GetLabels())
+}
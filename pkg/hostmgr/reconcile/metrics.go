@@ -27,6 +27,8 @@ type Metrics struct {
 	ReconcileExplicitlyAbort tally.Counter
 	ReconcileExplicitlyFail  tally.Counter
 	ReconcileGetTasksFail    tally.Counter
+	ReconcileOnDemand        tally.Counter
+	ReconcileOnDemandFail    tally.Counter
 
 	ExplicitTasksPerRun tally.Gauge
 }
@@ -42,6 +44,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ReconcileExplicitlyAbort: failScope.Counter("explicitly_abort_total"),
 		ReconcileExplicitlyFail:  failScope.Counter("explicitly_total"),
 		ReconcileGetTasksFail:    failScope.Counter("explicitly_gettasks_total"),
+		ReconcileOnDemand:        successScope.Counter("on_demand_total"),
+		ReconcileOnDemandFail:    failScope.Counter("on_demand_total"),
 
 		ExplicitTasksPerRun: scope.Gauge("explicit_tasks_per_run"),
 	}
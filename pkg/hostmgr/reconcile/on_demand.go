@@ -0,0 +1,243 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+
+	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/common/util"
+)
+
+// How long the status of a completed reconciliation is kept around.
+const _reconcileStatusRetention = time.Hour
+
+// ReconcileState is the state of a reconciliation started by ReconcileTasks.
+type ReconcileState int
+
+const (
+	// ReconcileRunning means reconcile calls are still being sent to Mesos.
+	ReconcileRunning ReconcileState = iota + 1
+	// ReconcileSucceeded means reconcile calls for all the tasks were sent
+	// to Mesos, which sends the status updates of the tasks asynchronously.
+	ReconcileSucceeded
+	// ReconcileFailed means a reconcile call to Mesos failed, and the
+	// remaining tasks were not reconciled.
+	ReconcileFailed
+)
+
+// ReconcileStatus is the progress of a reconciliation
+// started by ReconcileTasks.
+type ReconcileStatus struct {
+	ID    string
+	JobID *peloton.JobID
+	State ReconcileState
+
+	// Number of tasks to reconcile, and of tasks for which
+	// reconcile calls were sent to Mesos so far.
+	TotalTasks int
+	SentTasks  int
+
+	StartTime      time.Time
+	CompletionTime time.Time
+
+	// Error which failed the reconciliation.
+	Err error
+}
+
+// ReconcileTasks starts an explicit reconciliation of the given tasks, or of
+// all the non-terminal tasks of the job if no tasks are given. The reconcile
+// calls are sent to Mesos in batches in the background, and the returned ID
+// can be used to query their progress with GetReconcileStatus.
+func (r *taskReconciler) ReconcileTasks(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	taskIDs []*peloton.TaskID) (string, error) {
+	var reconcileTasks []*sched.Call_Reconcile_Task
+	var err error
+
+	switch {
+	case len(taskIDs) != 0:
+		reconcileTasks, err = r.getReconcileTasksByID(ctx, jobID, taskIDs)
+	case jobID.GetValue() != "":
+		reconcileTasks, err = r.getReconcileTasksForJob(ctx, jobID)
+	default:
+		err = yarpcerrors.InvalidArgumentErrorf("no job or tasks to reconcile")
+	}
+	if err != nil {
+		r.metrics.ReconcileOnDemandFail.Inc(1)
+		return "", err
+	}
+
+	status := &ReconcileStatus{
+		ID:         uuid.New(),
+		JobID:      jobID,
+		State:      ReconcileRunning,
+		TotalTasks: len(reconcileTasks),
+		StartTime:  time.Now(),
+	}
+	r.addReconcileStatus(status)
+
+	log.WithFields(log.Fields{
+		"reconcile_id": status.ID,
+		"job_id":       jobID.GetValue(),
+		"num_tasks":    len(reconcileTasks),
+	}).Info("Reconcile tasks on demand called.")
+
+	go r.reconcileOnDemand(status.ID, reconcileTasks)
+	return status.ID, nil
+}
+
+// GetReconcileStatus returns the progress of a reconciliation
+// started by ReconcileTasks.
+func (r *taskReconciler) GetReconcileStatus(id string) (*ReconcileStatus, error) {
+	r.statusLock.RLock()
+	defer r.statusLock.RUnlock()
+
+	status, ok := r.statuses[id]
+	if !ok {
+		return nil, yarpcerrors.NotFoundErrorf("reconcile %s not found", id)
+	}
+	statusCopy := *status
+	return &statusCopy, nil
+}
+
+// getReconcileTasksByID queries datastore and get the given tasks,
+// which must belong to the job if one is given.
+func (r *taskReconciler) getReconcileTasksByID(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	taskIDs []*peloton.TaskID) ([]*sched.Call_Reconcile_Task, error) {
+	var reconcileTasks []*sched.Call_Reconcile_Task
+	for _, taskID := range taskIDs {
+		taskJobID, instanceID, err := util.ParseTaskID(taskID.GetValue())
+		if err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid task id %s", taskID.GetValue())
+		}
+		if jobID.GetValue() != "" && jobID.GetValue() != taskJobID {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"task %s does not belong to job %s",
+				taskID.GetValue(), jobID.GetValue())
+		}
+
+		runtime, err := r.taskStore.GetTaskRuntime(
+			ctx, &peloton.JobID{Value: taskJobID}, instanceID)
+		if err != nil {
+			return nil, err
+		}
+		// Tasks which were never launched are unknown to Mesos.
+		if runtime.GetMesosTaskId() == nil {
+			continue
+		}
+		reconcileTasks = append(
+			reconcileTasks,
+			&sched.Call_Reconcile_Task{
+				TaskId:  runtime.GetMesosTaskId(),
+				AgentId: runtime.GetAgentID(),
+			},
+		)
+	}
+	return reconcileTasks, nil
+}
+
+// reconcileOnDemand sends the reconcile calls of a reconciliation started
+// by ReconcileTasks in batches, and records its progress.
+func (r *taskReconciler) reconcileOnDemand(
+	id string,
+	reconcileTasks []*sched.Call_Reconcile_Task) {
+	ctx := context.Background()
+	frameworkID := r.frameworkInfoProvider.GetFrameworkID(ctx)
+	streamID := r.frameworkInfoProvider.GetMesosStreamID(ctx)
+	callType := sched.Call_RECONCILE
+
+	for i := 0; i < len(reconcileTasks); i += r.explicitReconcileBatchSize {
+		if i != 0 {
+			time.Sleep(r.explicitReconcileBatchInterval)
+		}
+
+		end := i + r.explicitReconcileBatchSize
+		if end > len(reconcileTasks) {
+			end = len(reconcileTasks)
+		}
+		msg := &sched.Call{
+			FrameworkId: frameworkID,
+			Type:        &callType,
+			Reconcile: &sched.Call_Reconcile{
+				Tasks: reconcileTasks[i:end],
+			},
+		}
+		if err := r.schedulerClient.Call(streamID, msg); err != nil {
+			r.metrics.ReconcileOnDemandFail.Inc(1)
+			log.WithError(err).
+				WithField("reconcile_id", id).
+				Error("Abort reconcile on demand due to mesos CALL failed.")
+			r.completeReconcile(id, i, err)
+			return
+		}
+		r.updateReconcileProgress(id, end)
+	}
+
+	r.metrics.ReconcileOnDemand.Inc(1)
+	r.completeReconcile(id, len(reconcileTasks), nil)
+	log.WithField("reconcile_id", id).
+		Info("Reconcile tasks on demand returned.")
+}
+
+// addReconcileStatus records the status of a new reconciliation, and
+// drops the statuses of reconciliations completed a while ago.
+func (r *taskReconciler) addReconcileStatus(status *ReconcileStatus) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	for id, s := range r.statuses {
+		if s.State != ReconcileRunning &&
+			time.Since(s.CompletionTime) > _reconcileStatusRetention {
+			delete(r.statuses, id)
+		}
+	}
+	r.statuses[status.ID] = status
+}
+
+func (r *taskReconciler) updateReconcileProgress(id string, sentTasks int) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	r.statuses[id].SentTasks = sentTasks
+}
+
+func (r *taskReconciler) completeReconcile(
+	id string,
+	sentTasks int,
+	err error) {
+	r.statusLock.Lock()
+	defer r.statusLock.Unlock()
+
+	status := r.statuses[id]
+	status.SentTasks = sentTasks
+	status.CompletionTime = time.Now()
+	status.State = ReconcileSucceeded
+	if err != nil {
+		status.State = ReconcileFailed
+		status.Err = err
+	}
+}
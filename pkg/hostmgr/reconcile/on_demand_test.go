@@ -0,0 +1,171 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"go.uber.org/yarpc/yarpcerrors"
+
+	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+)
+
+// TestReconcileTasksForJob tests reconciling the non-terminal
+// tasks of a job on demand.
+func (suite *TaskReconcilerTestSuite) TestReconcileTasksForJob() {
+	var sent int
+	gomock.InOrder(
+		suite.mockTaskStore.EXPECT().
+			GetTasksForJobAndStates(
+				context.Background(),
+				suite.testJobID,
+				[]task.TaskState{
+					task.TaskState_LAUNCHED,
+					task.TaskState_STARTING,
+					task.TaskState_RUNNING,
+					task.TaskState_KILLING,
+				}).
+			Return(suite.taskInfos, nil),
+		suite.schedulerClient.EXPECT().
+			Call(gomock.Eq(streamID), gomock.Any()).
+			Do(func(_ string, msg proto.Message) {
+				call := msg.(*sched.Call)
+				suite.Equal(sched.Call_RECONCILE, call.GetType())
+				sent += len(call.GetReconcile().GetTasks())
+			}).
+			Return(nil).
+			Times(2),
+	)
+
+	id, err := suite.reconciler.ReconcileTasks(
+		context.Background(), suite.testJobID, nil)
+	suite.NoError(err)
+	suite.NotEmpty(id)
+
+	status, err := suite.reconciler.GetReconcileStatus(id)
+	suite.NoError(err)
+	suite.Equal(testInstanceCount, status.TotalTasks)
+
+	time.Sleep(oneExplicitReconcileRunDelay)
+	status, err = suite.reconciler.GetReconcileStatus(id)
+	suite.NoError(err)
+	suite.Equal(ReconcileSucceeded, status.State)
+	suite.Equal(testInstanceCount, status.SentTasks)
+	suite.Equal(testInstanceCount, sent)
+	suite.Equal(suite.testJobID, status.JobID)
+	suite.NoError(status.Err)
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["on_demand_total+result=success"].Value())
+}
+
+// TestReconcileTasksByID tests reconciling the given tasks on demand,
+// and failing the reconciliation if the call to Mesos fails.
+func (suite *TaskReconcilerTestSuite) TestReconcileTasksByID() {
+	jobID := &peloton.JobID{Value: uuid.New()}
+	taskIDs := []*peloton.TaskID{
+		{Value: fmt.Sprintf("%s-%d", jobID.GetValue(), 0)},
+		{Value: fmt.Sprintf("%s-%d", jobID.GetValue(), 1)},
+	}
+	suite.mockTaskStore.EXPECT().
+		GetTaskRuntime(context.Background(), jobID, uint32(0)).
+		Return(suite.taskInfos[0].GetRuntime(), nil)
+	// Task which was never launched is skipped.
+	suite.mockTaskStore.EXPECT().
+		GetTaskRuntime(context.Background(), jobID, uint32(1)).
+		Return(&task.RuntimeInfo{State: task.TaskState_PENDING}, nil)
+	suite.schedulerClient.EXPECT().
+		Call(gomock.Eq(streamID), gomock.Any()).
+		Do(func(_ string, msg proto.Message) {
+			call := msg.(*sched.Call)
+			suite.Equal(
+				[]*sched.Call_Reconcile_Task{{
+					TaskId:  suite.taskInfos[0].GetRuntime().GetMesosTaskId(),
+					AgentId: suite.taskInfos[0].GetRuntime().GetAgentID(),
+				}},
+				call.GetReconcile().GetTasks())
+		}).
+		Return(fmt.Errorf("fake Call error"))
+
+	id, err := suite.reconciler.ReconcileTasks(
+		context.Background(), nil, taskIDs)
+	suite.NoError(err)
+
+	time.Sleep(oneExplicitReconcileRunDelay)
+	status, err := suite.reconciler.GetReconcileStatus(id)
+	suite.NoError(err)
+	suite.Equal(ReconcileFailed, status.State)
+	suite.Equal(1, status.TotalTasks)
+	suite.Equal(0, status.SentTasks)
+	suite.Error(status.Err)
+}
+
+// TestReconcileTasksErrors tests the errors of starting and
+// querying reconciliations on demand.
+func (suite *TaskReconcilerTestSuite) TestReconcileTasksErrors() {
+	ctx := context.Background()
+
+	_, err := suite.reconciler.ReconcileTasks(ctx, nil, nil)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	_, err = suite.reconciler.ReconcileTasks(
+		ctx, nil, []*peloton.TaskID{{Value: "bad"}})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// Task of another job.
+	_, err = suite.reconciler.ReconcileTasks(
+		ctx,
+		suite.testJobID,
+		[]*peloton.TaskID{{Value: uuid.New() + "-0"}})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	suite.mockTaskStore.EXPECT().
+		GetTasksForJobAndStates(ctx, suite.testJobID, gomock.Any()).
+		Return(nil, fmt.Errorf("fake GetTasksForJobAndStates error"))
+	_, err = suite.reconciler.ReconcileTasks(ctx, suite.testJobID, nil)
+	suite.Error(err)
+
+	_, err = suite.reconciler.GetReconcileStatus("unknown")
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestReconcileStatusRetention tests that the statuses of reconciliations
+// completed a while ago are dropped.
+func (suite *TaskReconcilerTestSuite) TestReconcileStatusRetention() {
+	suite.reconciler.statuses["old"] = &ReconcileStatus{
+		ID:             "old",
+		State:          ReconcileSucceeded,
+		CompletionTime: time.Now().Add(-2 * _reconcileStatusRetention),
+	}
+	suite.reconciler.statuses["running"] = &ReconcileStatus{
+		ID:    "running",
+		State: ReconcileRunning,
+	}
+
+	suite.reconciler.addReconcileStatus(&ReconcileStatus{ID: "new"})
+	_, err := suite.reconciler.GetReconcileStatus("old")
+	suite.True(yarpcerrors.IsNotFound(err))
+	_, err = suite.reconciler.GetReconcileStatus("running")
+	suite.NoError(err)
+	_, err = suite.reconciler.GetReconcileStatus("new")
+	suite.NoError(err)
+}
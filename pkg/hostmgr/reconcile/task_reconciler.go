@@ -16,6 +16,7 @@ package reconcile
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -23,6 +24,7 @@ import (
 	"github.com/uber-go/tally"
 
 	sched "github.com/uber/peloton/.gen/mesos/v1/scheduler"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
 	"github.com/uber/peloton/pkg/common/util"
@@ -36,6 +38,18 @@ import (
 type TaskReconciler interface {
 	Reconcile(running *atomic.Bool)
 	SetExplicitReconcileTurn(flag bool)
+
+	// ReconcileTasks starts an explicit reconciliation of the given tasks,
+	// or of all the non-terminal tasks of the job if no tasks are given,
+	// and returns the ID of the reconciliation.
+	ReconcileTasks(
+		ctx context.Context,
+		jobID *peloton.JobID,
+		taskIDs []*peloton.TaskID) (string, error)
+
+	// GetReconcileStatus returns the progress of a reconciliation
+	// started by ReconcileTasks.
+	GetReconcileStatus(id string) (*ReconcileStatus, error)
 }
 
 // taskReconciler implements TaskReconciler.
@@ -53,6 +67,10 @@ type taskReconciler struct {
 	isExplicitReconcileRunning atomic.Bool
 	// Run explicit reconcile if True, otherwise run implicit reconcile.
 	isExplicitReconcileTurn atomic.Bool
+
+	// Status of the reconciliations started by ReconcileTasks, by ID.
+	statusLock sync.RWMutex
+	statuses   map[string]*ReconcileStatus
 }
 
 // NewTaskReconciler initialize the task reconciler.
@@ -73,6 +91,7 @@ func NewTaskReconciler(
 		explicitReconcileBatchInterval: time.Duration(
 			cfg.ExplicitReconcileBatchIntervalSec) * time.Second,
 		explicitReconcileBatchSize: cfg.ExplicitReconcileBatchSize,
+		statuses:                   make(map[string]*ReconcileStatus),
 	}
 	reconciler.isExplicitReconcileTurn.Store(true)
 	return reconciler
@@ -200,17 +219,7 @@ func (r *taskReconciler) getReconcileTasks(ctx context.Context) (
 	log.WithField("job_ids", jobIDs).Info("explicit reconcile job ids.")
 
 	for _, jobID := range jobIDs {
-		// Mesos TaskState: TASK_STAGING -> Peloton: TaskState_LAUNCHED
-		nonTerminalTasks, getTasksErr := r.taskStore.GetTasksForJobAndStates(
-			ctx,
-			&jobID,
-			[]task.TaskState{
-				task.TaskState_LAUNCHED,
-				task.TaskState_STARTING,
-				task.TaskState_RUNNING,
-				task.TaskState_KILLING,
-			},
-		)
+		jobTasks, getTasksErr := r.getReconcileTasksForJob(ctx, &jobID)
 		if getTasksErr != nil {
 			log.WithError(getTasksErr).WithFields(log.Fields{
 				"job": jobID,
@@ -218,17 +227,40 @@ func (r *taskReconciler) getReconcileTasks(ctx context.Context) (
 			r.metrics.ReconcileGetTasksFail.Inc(1)
 			continue
 		}
-		if len(nonTerminalTasks) != 0 {
-			for _, taskInfo := range nonTerminalTasks {
-				reconcileTasks = append(
-					reconcileTasks,
-					&sched.Call_Reconcile_Task{
-						TaskId:  taskInfo.GetRuntime().GetMesosTaskId(),
-						AgentId: taskInfo.GetRuntime().GetAgentID(),
-					},
-				)
-			}
-		}
+		reconcileTasks = append(reconcileTasks, jobTasks...)
+	}
+	return reconcileTasks, nil
+}
+
+// getReconcileTasksForJob queries datastore and get
+// the non-terminal tasks of a job in Mesos.
+func (r *taskReconciler) getReconcileTasksForJob(
+	ctx context.Context,
+	jobID *peloton.JobID) ([]*sched.Call_Reconcile_Task, error) {
+	// Mesos TaskState: TASK_STAGING -> Peloton: TaskState_LAUNCHED
+	nonTerminalTasks, err := r.taskStore.GetTasksForJobAndStates(
+		ctx,
+		jobID,
+		[]task.TaskState{
+			task.TaskState_LAUNCHED,
+			task.TaskState_STARTING,
+			task.TaskState_RUNNING,
+			task.TaskState_KILLING,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var reconcileTasks []*sched.Call_Reconcile_Task
+	for _, taskInfo := range nonTerminalTasks {
+		reconcileTasks = append(
+			reconcileTasks,
+			&sched.Call_Reconcile_Task{
+				TaskId:  taskInfo.GetRuntime().GetMesosTaskId(),
+				AgentId: taskInfo.GetRuntime().GetAgentID(),
+			},
+		)
 	}
 	return reconcileTasks, nil
 }
@@ -120,6 +120,7 @@ func (suite *TaskReconcilerTestSuite) SetupTest() {
 		taskStore:                      suite.mockTaskStore,
 		explicitReconcileBatchInterval: explicitReconcileBatchInterval,
 		explicitReconcileBatchSize:     testBatchSize,
+		statuses:                       make(map[string]*ReconcileStatus),
 	}
 	suite.reconciler.isExplicitReconcileTurn.Store(true)
 }
@@ -17,6 +17,8 @@ package scalar
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"sync"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -24,6 +26,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 
+	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
 )
 
@@ -33,6 +36,11 @@ type Resources struct {
 	Mem  float64
 	Disk float64
 	GPU  float64
+
+	// Custom holds named scalar resources other than cpus, mem, disk and
+	// gpus, such as network bandwidth, keyed by Mesos resource name.
+	// It is nil when there is no custom resource.
+	Custom map[string]float64
 }
 
 // a safe less than or equal to comparator which takes epsilon into consideration.
@@ -74,6 +82,51 @@ func (r Resources) GetGPU() float64 {
 	return r.GPU
 }
 
+// GetCustom returns the custom scalar resource of the given name.
+func (r Resources) GetCustom(name string) float64 {
+	return r.Custom[name]
+}
+
+// CustomNames returns the sorted names of the custom resources in r.
+func (r Resources) CustomNames() []string {
+	var names []string
+	for name := range r.Custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeCustom merges the custom resources of r1 and r2 with given function,
+// dropping resources which end up empty.
+func mergeCustom(
+	r1, r2 map[string]float64,
+	merge func(v1, v2 float64) float64,
+) map[string]float64 {
+	if len(r1) == 0 && len(r2) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64)
+	for name, v := range r1 {
+		result[name] = merge(v, r2[name])
+	}
+	for name, v := range r2 {
+		if _, ok := r1[name]; !ok {
+			result[name] = merge(0, v)
+		}
+	}
+	for name, v := range result {
+		if math.Abs(v) < util.ResourceEpsilon {
+			delete(result, name)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
 // HasGPU is a special condition to ensure exclusive protection for GPU.
 func (r Resources) HasGPU() bool {
 	return math.Abs(r.GPU) > util.ResourceEpsilon
@@ -82,6 +135,11 @@ func (r Resources) HasGPU() bool {
 // Contains determines whether current Resources is large enough to contain
 // the other one.
 func (r Resources) Contains(other Resources) bool {
+	for name, v := range other.Custom {
+		if !lessThanOrEqual(v, r.Custom[name]) {
+			return false
+		}
+	}
 	return lessThanOrEqual(other.CPU, r.CPU) &&
 		lessThanOrEqual(other.Mem, r.Mem) &&
 		lessThanOrEqual(other.Disk, r.Disk) &&
@@ -117,6 +175,11 @@ func (r Resources) Compare(other Resources, cmpLess bool) bool {
 	if other.Disk > 0 && lessThan(r.Disk, other.Disk) != cmpLess {
 		return false
 	}
+	for name, v := range other.Custom {
+		if v > 0 && lessThan(r.Custom[name], v) != cmpLess {
+			return false
+		}
+	}
 	return true
 }
 
@@ -127,6 +190,9 @@ func (r Resources) Add(other Resources) Resources {
 		Mem:  r.Mem + other.Mem,
 		Disk: r.Disk + other.Disk,
 		GPU:  r.GPU + other.GPU,
+		Custom: mergeCustom(r.Custom, other.Custom, func(v1, v2 float64) float64 {
+			return v1 + v2
+		}),
 	}
 }
 
@@ -146,6 +212,9 @@ func (r Resources) Subtract(other Resources) Resources {
 		Mem:  r.Mem - other.Mem,
 		Disk: r.Disk - other.Disk,
 		GPU:  r.GPU - other.GPU,
+		Custom: mergeCustom(r.Custom, other.Custom, func(v1, v2 float64) float64 {
+			return v1 - v2
+		}),
 	}
 }
 
@@ -164,6 +233,11 @@ func (r Resources) NonEmptyFields() []string {
 	if math.Abs(r.GPU) > util.ResourceEpsilon {
 		nonEmptyFields = append(nonEmptyFields, "gpus")
 	}
+	for _, name := range r.CustomNames() {
+		if math.Abs(r.Custom[name]) > util.ResourceEpsilon {
+			nonEmptyFields = append(nonEmptyFields, name)
+		}
+	}
 
 	return nonEmptyFields
 }
@@ -175,8 +249,16 @@ func (r Resources) Empty() bool {
 
 // String returns a formatted string for scalar resources
 func (r Resources) String() string {
-	return fmt.Sprintf("CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
+	s := fmt.Sprintf("CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
 		r.GetCPU(), r.GetMem(), r.GetDisk(), r.GetGPU())
+	var custom []string
+	for _, name := range r.CustomNames() {
+		custom = append(custom, fmt.Sprintf("%s:%.2f", name, r.Custom[name]))
+	}
+	if len(custom) != 0 {
+		s += " " + strings.Join(custom, " ")
+	}
+	return s
 }
 
 // HasResourceType validates requested resource type is present agent resource type.
@@ -219,6 +301,9 @@ func FromResourceConfig(rc *task.ResourceConfig) (r Resources) {
 	r.Mem = rc.GetMemLimitMb()
 	r.Disk = rc.GetDiskLimitMb()
 	r.GPU = rc.GetGpuLimit()
+	r.Custom = mergeCustom(rc.GetCustomLimits(), nil, func(v, _ float64) float64 {
+		return v
+	})
 	return r
 }
 
@@ -281,6 +366,12 @@ func FromMesosResource(resource *mesos.Resource) (r Resources) {
 		r.Disk += value
 	case "gpus":
 		r.GPU += value
+	case common.MesosPorts:
+		// Ports are range resources, which are tracked separately.
+	default:
+		if resource.GetType() == mesos.Value_SCALAR && value != 0 {
+			r.Custom = map[string]float64{name: value}
+		}
 	}
 	return r
 }
@@ -351,6 +442,7 @@ func Minimum(r1, r2 Resources) (m Resources) {
 	m.Mem = math.Min(r1.Mem, r2.Mem)
 	m.Disk = math.Min(r1.Disk, r2.Disk)
 	m.GPU = math.Min(r1.GPU, r2.GPU)
+	m.Custom = mergeCustom(r1.Custom, r2.Custom, math.Min)
 	return m
 }
 
@@ -300,6 +300,67 @@ func TestAdd(t *testing.T) {
 	assert.InDelta(t, 1.0, result.GPU, _zeroDelta)
 }
 
+func TestCustomResources(t *testing.T) {
+	r1 := Resources{
+		CPU:    1.0,
+		Custom: map[string]float64{"network": 100.0},
+	}
+	r2 := Resources{
+		CPU:    1.0,
+		Custom: map[string]float64{"network": 50.0, "fpga": 1.0},
+	}
+
+	sum := r1.Add(r2)
+	assert.InDelta(t, 2.0, sum.CPU, _zeroDelta)
+	assert.InDelta(t, 150.0, sum.GetCustom("network"), _zeroDelta)
+	assert.InDelta(t, 1.0, sum.GetCustom("fpga"), _zeroDelta)
+	assert.Equal(t, []string{"cpus", "fpga", "network"}, sum.NonEmptyFields())
+	assert.Equal(t,
+		"CPU:2.00 MEM:0.00 DISK:0.00 GPU:0.00 fpga:1.00 network:150.00",
+		sum.String())
+
+	// custom resources are taken into account when comparing resources
+	assert.True(t, sum.Contains(r2))
+	assert.False(t, r1.Contains(r2))
+	assert.True(t, sum.Compare(r2, false))
+	assert.False(t, r1.Compare(r2, false))
+
+	_, ok := r1.TrySubtract(r2)
+	assert.False(t, ok)
+	diff, ok := sum.TrySubtract(r2)
+	assert.True(t, ok)
+	assert.Equal(t, r1, diff)
+
+	// custom resources which become empty are dropped
+	empty := r1.Subtract(r1)
+	assert.Nil(t, empty.Custom)
+	assert.True(t, empty.Empty())
+
+	min := Minimum(r1, r2)
+	assert.InDelta(t, 50.0, min.GetCustom("network"), _zeroDelta)
+	assert.InDelta(t, 0.0, min.GetCustom("fpga"), _zeroDelta)
+}
+
+func TestFromMesosResourceCustom(t *testing.T) {
+	r := FromMesosResources([]*mesos.Resource{
+		util.NewMesosResourceBuilder().
+			WithName("network").
+			WithValue(10.0).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName("network").
+			WithValue(5.0).
+			Build(),
+		util.NewMesosResourceBuilder().
+			WithName(common.MesosPorts).
+			WithType(mesos.Value_RANGES).
+			Build(),
+	})
+	assert.Equal(t, Resources{
+		Custom: map[string]float64{"network": 15.0},
+	}, r)
+}
+
 func TestTrySubtract(t *testing.T) {
 	empty := Resources{}
 	r1 := Resources{
@@ -370,6 +431,7 @@ func TestFromOfferMap(t *testing.T) {
 	assert.InDelta(t, 2.0, result.Mem, _zeroDelta)
 	assert.InDelta(t, 3.0, result.Disk, _zeroDelta)
 	assert.InDelta(t, 4.0, result.GPU, _zeroDelta)
+	assert.InDelta(t, 5.0, result.GetCustom("custom"), _zeroDelta)
 
 	result = FromOfferMap(map[string]*mesos.Offer{
 		"o1": &offer,
@@ -379,6 +441,7 @@ func TestFromOfferMap(t *testing.T) {
 	assert.InDelta(t, 4.0, result.Mem, _zeroDelta)
 	assert.InDelta(t, 6.0, result.Disk, _zeroDelta)
 	assert.InDelta(t, 8.0, result.GPU, _zeroDelta)
+	assert.InDelta(t, 10.0, result.GetCustom("custom"), _zeroDelta)
 }
 
 func TestFromOffers(t *testing.T) {
@@ -416,11 +479,15 @@ func TestFromResourceConfig(t *testing.T) {
 		MemLimitMb:  2.0,
 		DiskLimitMb: 3.0,
 		GpuLimit:    4.0,
+		CustomLimits: map[string]float64{
+			"fpgas": 5.0,
+		},
 	})
 	assert.InDelta(t, 1.0, result.CPU, _zeroDelta)
 	assert.InDelta(t, 2.0, result.Mem, _zeroDelta)
 	assert.InDelta(t, 3.0, result.Disk, _zeroDelta)
 	assert.InDelta(t, 4.0, result.GPU, _zeroDelta)
+	assert.InDelta(t, 5.0, result.Custom["fpgas"], _zeroDelta)
 }
 
 func TestMinimum(t *testing.T) {
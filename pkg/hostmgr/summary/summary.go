@@ -26,6 +26,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	halphapb "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/host"
@@ -440,6 +441,16 @@ func matchHostFilter(
 		break
 	}
 
+	if volume := c.GetResourceConstraint().GetVolume(); volume != nil {
+		if !matchVolumeConstraint(
+			offerMap,
+			firstOffer.GetAttributes(),
+			volume,
+			min.GetDiskLimitMb()) {
+			return hostsvc.HostFilterResult_MISMATCH_VOLUME
+		}
+	}
+
 	hostname := firstOffer.GetHostname()
 	hc := c.GetSchedulingConstraint()
 
@@ -447,6 +458,54 @@ func matchHostFilter(
 		hostname, labelValues, firstOffer.GetAttributes(), hc, evaluator)
 }
 
+// matchVolumeConstraint returns whether the host has a disk of the type of
+// the volume constraint, and offers enough disk of it for the volume. A
+// mount volume needs a mount-point disk of at least its size, while other
+// volumes need the root disk offered to fit both the volume and minDiskMb.
+func matchVolumeConstraint(
+	offerMap map[string]*mesos.Offer,
+	attributes []*mesos.Attribute,
+	volume *hostsvc.VolumeConstraint,
+	minDiskMb float64) bool {
+	if volume.GetDiskType() != "" {
+		var diskType string
+		for _, attribute := range attributes {
+			if attribute.GetName() == common.DiskTypeAttribute {
+				diskType = attribute.GetText().GetValue()
+				break
+			}
+		}
+		if !strings.EqualFold(diskType, volume.GetDiskType()) {
+			return false
+		}
+	}
+
+	_, nonRevocable := scalar.FilterRevocableMesosResources(
+		scalar.FromOffersMapToMesosResources(offerMap))
+	sizeMb := float64(volume.GetSizeMb())
+	var rootDiskMb float64
+	for _, resource := range nonRevocable {
+		if resource.GetName() != common.MesosDisk {
+			continue
+		}
+		if resource.GetDisk().GetSource().GetType() ==
+			mesos.Resource_DiskInfo_Source_MOUNT {
+			// A mount disk is offered whole, and cannot be shared
+			// between volumes.
+			if volume.GetMount() && resource.GetScalar().GetValue() >= sizeMb {
+				return true
+			}
+			continue
+		}
+		rootDiskMb += resource.GetScalar().GetValue()
+	}
+
+	if volume.GetMount() {
+		return false
+	}
+	return rootDiskMb >= minDiskMb+sizeMb
+}
+
 // TryMatch atomically tries to match offers from the current host with given
 // HostFilter.
 // If current hostSummary is matched by given HostFilter, the first return
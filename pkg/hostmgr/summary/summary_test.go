@@ -532,6 +532,110 @@ func (suite *HostOfferSummaryTestSuite) TestScarceResourcesConstraint() {
 	}
 }
 
+func (suite *HostOfferSummaryTestSuite) TestMatchVolumeConstraint() {
+	mountType := mesos.Resource_DiskInfo_Source_MOUNT
+	textType := mesos.Value_TEXT
+	attrName := common.DiskTypeAttribute
+	attrValue := "SSD"
+	ssdAttribute := &mesos.Attribute{
+		Name: &attrName,
+		Type: &textType,
+		Text: &mesos.Value_Text{Value: &attrValue},
+	}
+
+	rootDisk := util.NewMesosResourceBuilder().
+		WithName(common.MesosDisk).
+		WithValue(100).
+		Build()
+	mountDisk := func(size float64) *mesos.Resource {
+		return util.NewMesosResourceBuilder().
+			WithName(common.MesosDisk).
+			WithValue(size).
+			WithDisk(&mesos.Resource_DiskInfo{
+				Source: &mesos.Resource_DiskInfo_Source{Type: &mountType},
+			}).
+			Build()
+	}
+
+	testTable := []struct {
+		msg        string
+		resources  []*mesos.Resource
+		attributes []*mesos.Attribute
+		volume     *hostsvc.VolumeConstraint
+		minDiskMb  float64
+		expected   bool
+	}{
+		{
+			msg:        "disk type matches case insensitively",
+			resources:  []*mesos.Resource{rootDisk},
+			attributes: []*mesos.Attribute{ssdAttribute},
+			volume:     &hostsvc.VolumeConstraint{DiskType: "ssd"},
+			expected:   true,
+		},
+		{
+			msg:        "disk type mismatch",
+			resources:  []*mesos.Resource{rootDisk},
+			attributes: []*mesos.Attribute{ssdAttribute},
+			volume:     &hostsvc.VolumeConstraint{DiskType: "hdd"},
+			expected:   false,
+		},
+		{
+			msg:       "disk type missing on host",
+			resources: []*mesos.Resource{rootDisk},
+			volume:    &hostsvc.VolumeConstraint{DiskType: "ssd"},
+			expected:  false,
+		},
+		{
+			msg:       "root volume fits next to task disk",
+			resources: []*mesos.Resource{rootDisk},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 60},
+			minDiskMb: 40,
+			expected:  true,
+		},
+		{
+			msg:       "root volume does not fit next to task disk",
+			resources: []*mesos.Resource{rootDisk},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 61},
+			minDiskMb: 40,
+			expected:  false,
+		},
+		{
+			msg:       "root volume ignores mount disks",
+			resources: []*mesos.Resource{rootDisk, mountDisk(500)},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 200},
+			expected:  false,
+		},
+		{
+			msg:       "mount volume on large enough mount disk",
+			resources: []*mesos.Resource{rootDisk, mountDisk(50), mountDisk(500)},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 200, Mount: true},
+			expected:  true,
+		},
+		{
+			msg:       "mount volume on too small mount disk",
+			resources: []*mesos.Resource{rootDisk, mountDisk(50)},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 60, Mount: true},
+			expected:  false,
+		},
+		{
+			msg:       "mount volume without mount disk",
+			resources: []*mesos.Resource{rootDisk},
+			volume:    &hostsvc.VolumeConstraint{SizeMb: 10, Mount: true},
+			expected:  false,
+		},
+	}
+
+	for _, tt := range testTable {
+		offerMap := map[string]*mesos.Offer{
+			_dummyOfferID: {Resources: tt.resources},
+		}
+		suite.Equal(
+			tt.expected,
+			matchVolumeConstraint(offerMap, tt.attributes, tt.volume, tt.minDiskMb),
+			tt.msg)
+	}
+}
+
 func (suite *HostOfferSummaryTestSuite) TestSlackResourcesConstraint() {
 	defer suite.ctrl.Finish()
 
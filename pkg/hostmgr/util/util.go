@@ -48,16 +48,36 @@ func MesosOffersToHostOffer(hostOfferID string, mesosOffers []*mesos.Offer) *hos
 	}
 
 	var hostOffer = hostsvc.HostOffer{
-		Id:         &peloton.HostOfferID{Value: hostOfferID},
-		Hostname:   *mesosOffers[0].Hostname,
-		AgentId:    mesosOffers[0].AgentId,
-		Attributes: attributes,
-		Resources:  resources,
+		Id:             &peloton.HostOfferID{Value: hostOfferID},
+		Hostname:       *mesosOffers[0].Hostname,
+		AgentId:        mesosOffers[0].AgentId,
+		Attributes:     attributes,
+		Resources:      resources,
+		Unavailability: GetEarliestUnavailability(mesosOffers),
 	}
 
 	return &hostOffer
 }
 
+// GetEarliestUnavailability returns the earliest unavailability, that is the
+// maintenance window of the host, carried by the given offers, or nil if
+// none of the offers carries one.
+func GetEarliestUnavailability(mesosOffers []*mesos.Offer) *mesos.Unavailability {
+	var earliest *mesos.Unavailability
+	for _, offer := range mesosOffers {
+		unavailability := offer.GetUnavailability()
+		if unavailability == nil {
+			continue
+		}
+		if earliest == nil ||
+			unavailability.GetStart().GetNanoseconds() <
+				earliest.GetStart().GetNanoseconds() {
+			earliest = unavailability
+		}
+	}
+	return earliest
+}
+
 // IsSlackResourceType validates is given resource type is supported slack resource.
 func IsSlackResourceType(resourceType string, slackResourceTypes []string) bool {
 	for _, rType := range slackResourceTypes {
@@ -74,6 +74,35 @@ func TestMesosOffersToHostOffers(t *testing.T) {
 	}
 	hostOffer = MesosOffersToHostOffer(hostOfferID, offerList)
 	assert.NotNil(t, hostOffer)
+	assert.Nil(t, hostOffer.GetUnavailability())
+
+	start := int64(1000)
+	offerList[0].Unavailability = &mesos.Unavailability{
+		Start: &mesos.TimeInfo{Nanoseconds: &start},
+	}
+	hostOffer = MesosOffersToHostOffer(hostOfferID, offerList)
+	assert.Equal(t, start, hostOffer.GetUnavailability().GetStart().GetNanoseconds())
+}
+
+// TestGetEarliestUnavailability tests getting the earliest unavailability
+// carried by offers.
+func TestGetEarliestUnavailability(t *testing.T) {
+	assert.Nil(t, GetEarliestUnavailability(nil))
+
+	newOffer := func(start int64) *mesos.Offer {
+		return &mesos.Offer{
+			Unavailability: &mesos.Unavailability{
+				Start: &mesos.TimeInfo{Nanoseconds: &start},
+			},
+		}
+	}
+	unavailability := GetEarliestUnavailability([]*mesos.Offer{
+		{},
+		newOffer(2000),
+		newOffer(1000),
+		newOffer(3000),
+	})
+	assert.Equal(t, int64(1000), unavailability.GetStart().GetNanoseconds())
 }
 
 func TestIsSlackResourceType(t *testing.T) {
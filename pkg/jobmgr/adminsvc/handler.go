@@ -16,11 +16,18 @@ package adminsvc
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/pkg/common/freeze"
 	yarpcutil "github.com/uber/peloton/pkg/common/util/yarpc"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/middleware/inbound"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc"
@@ -32,13 +39,30 @@ func InitServiceHandler(
 	d *yarpc.Dispatcher,
 	goalStateDriver goalstate.Driver,
 	apiLock inbound.APILockInterface,
+	freezeTracker *freeze.Tracker,
+	jobFactory cached.JobFactory,
 ) {
-	handler := createServiceHandler(goalStateDriver, apiLock)
+	handler := createServiceHandler(
+		goalStateDriver,
+		apiLock,
+		freezeTracker,
+		jobFactory,
+	)
 	d.Register(adminsvc.BuildAdminServiceYARPCProcedures(handler))
 }
 
-func createServiceHandler(goalStateDriver goalstate.Driver, apiLock inbound.APILockInterface) *serviceHandler {
-	handler := &serviceHandler{components: make(map[adminsvc.Component]lockableComponent)}
+func createServiceHandler(
+	goalStateDriver goalstate.Driver,
+	apiLock inbound.APILockInterface,
+	freezeTracker *freeze.Tracker,
+	jobFactory cached.JobFactory,
+) *serviceHandler {
+	handler := &serviceHandler{
+		goalStateDriver: goalStateDriver,
+		components:      make(map[adminsvc.Component]lockableComponent),
+		freezeTracker:   freezeTracker,
+		jobFactory:      jobFactory,
+	}
 
 	for _, component := range createLockableComponents(goalStateDriver, apiLock) {
 		handler.components[component.component()] = component
@@ -50,6 +74,8 @@ func createServiceHandler(goalStateDriver goalstate.Driver, apiLock inbound.APIL
 type serviceHandler struct {
 	goalStateDriver goalstate.Driver
 	components      map[adminsvc.Component]lockableComponent
+	freezeTracker   *freeze.Tracker
+	jobFactory      cached.JobFactory
 }
 
 // Lockdown locks the components requested in LockdownRequest
@@ -148,6 +174,193 @@ func (h *serviceHandler) RemoveLockdown(
 	return
 }
 
+// Freeze freezes the cluster, halting non-essential automatic actions
+func (h *serviceHandler) Freeze(
+	ctx context.Context,
+	request *adminsvc.FreezeRequest,
+) (response *adminsvc.FreezeResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			err = yarpcutil.ConvertToYARPCError(err)
+			log.WithField("headers", headers).
+				WithField("request", request).
+				WithError(err).
+				Warn("AdminService.Freeze failed")
+			return
+		}
+
+		log.WithField("headers", headers).
+			WithField("request", request).
+			Info("AdminService.Freeze succeeded")
+	}()
+
+	if len(request.GetReason()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("reason is required to freeze the cluster")
+	}
+
+	if err = h.freezeTracker.Freeze(ctx, request.GetReason(), request.GetOwner()); err != nil {
+		return nil, err
+	}
+
+	status, err := h.freezeTracker.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminsvc.FreezeResponse{Status: toFreezeStatus(status)}, nil
+}
+
+// Unfreeze lifts the freeze on the cluster
+func (h *serviceHandler) Unfreeze(
+	ctx context.Context,
+	request *adminsvc.UnfreezeRequest,
+) (response *adminsvc.UnfreezeResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			err = yarpcutil.ConvertToYARPCError(err)
+			log.WithField("headers", headers).
+				WithField("request", request).
+				WithError(err).
+				Warn("AdminService.Unfreeze failed")
+			return
+		}
+
+		log.WithField("headers", headers).
+			WithField("request", request).
+			Info("AdminService.Unfreeze succeeded")
+	}()
+
+	if err = h.freezeTracker.Unfreeze(ctx, request.GetOwner()); err != nil {
+		return nil, err
+	}
+
+	status, err := h.freezeTracker.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminsvc.UnfreezeResponse{Status: toFreezeStatus(status)}, nil
+}
+
+// GetFreezeStatus returns the freeze state of the cluster
+func (h *serviceHandler) GetFreezeStatus(
+	ctx context.Context,
+	request *adminsvc.GetFreezeStatusRequest,
+) (response *adminsvc.GetFreezeStatusResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			err = yarpcutil.ConvertToYARPCError(err)
+			log.WithField("headers", headers).
+				WithError(err).
+				Warn("AdminService.GetFreezeStatus failed")
+			return
+		}
+
+		log.WithField("headers", headers).
+			Debug("AdminService.GetFreezeStatus succeeded")
+	}()
+
+	status, err := h.freezeTracker.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminsvc.GetFreezeStatusResponse{Status: toFreezeStatus(status)}, nil
+}
+
+// GetJobCacheSnapshot returns the state of a job cached in job manager
+// in JSON format, without loading any missing state from DB.
+func (h *serviceHandler) GetJobCacheSnapshot(
+	ctx context.Context,
+	request *adminsvc.GetJobCacheSnapshotRequest,
+) (response *adminsvc.GetJobCacheSnapshotResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+
+		if err != nil {
+			err = yarpcutil.ConvertToYARPCError(err)
+			log.WithField("headers", headers).
+				WithField("request", request).
+				WithError(err).
+				Warn("AdminService.GetJobCacheSnapshot failed")
+			return
+		}
+
+		log.WithField("headers", headers).
+			WithField("request", request).
+			Debug("AdminService.GetJobCacheSnapshot succeeded")
+	}()
+
+	if len(request.GetJobId().GetValue()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("job id is required")
+	}
+
+	cachedJob := h.jobFactory.GetJob(
+		&peloton.JobID{Value: request.GetJobId().GetValue()})
+	if cachedJob == nil {
+		return nil, yarpcerrors.NotFoundErrorf("job not found in cache")
+	}
+
+	snapshot, err := json.Marshal(newJobCacheSnapshot(cachedJob))
+	if err != nil {
+		return nil, err
+	}
+
+	return &adminsvc.GetJobCacheSnapshotResponse{Snapshot: string(snapshot)}, nil
+}
+
+// GetStuckGoalStateEntities returns the jobs, tasks and updates found
+// stuck in goal state by the watchdogs of the goal state engines.
+func (h *serviceHandler) GetStuckGoalStateEntities(
+	ctx context.Context,
+	request *adminsvc.GetStuckGoalStateEntitiesRequest,
+) (response *adminsvc.GetStuckGoalStateEntitiesResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		log.WithField("headers", headers).
+			WithField("num_entities", len(response.GetEntities())).
+			Debug("AdminService.GetStuckGoalStateEntities succeeded")
+	}()
+
+	response = &adminsvc.GetStuckGoalStateEntitiesResponse{}
+	for _, stuck := range h.goalStateDriver.GetStuckEntities() {
+		entity := &adminsvc.StuckGoalStateEntity{
+			Kind:         stuck.Kind,
+			JobId:        &v1alphapeloton.JobID{Value: stuck.JobID.GetValue()},
+			InstanceId:   stuck.InstanceID,
+			UpdateId:     stuck.UpdateID.GetValue(),
+			Failures:     uint32(stuck.Failures),
+			LastError:    stuck.LastError,
+			Remediations: stuck.Remediations,
+			DeadLettered: stuck.DeadLettered,
+		}
+		if !stuck.StuckSince.IsZero() {
+			entity.StuckSince = stuck.StuckSince.UTC().Format(time.RFC3339)
+		}
+		response.Entities = append(response.Entities, entity)
+	}
+	return response, nil
+}
+
+// toFreezeStatus converts the freeze state stored in DB to its API form
+func toFreezeStatus(obj *ormobjects.ClusterFreezeObject) *adminsvc.FreezeStatus {
+	status := &adminsvc.FreezeStatus{
+		Frozen: obj.Frozen,
+		Reason: obj.Reason,
+		Owner:  obj.Owner,
+	}
+	if !obj.UpdateTime.IsZero() {
+		status.UpdateTime = obj.UpdateTime.Format(time.RFC3339)
+	}
+	return status
+}
+
 func (h *serviceHandler) getLockableComponents(components []adminsvc.Component) ([]lockableComponent, error) {
 	var result []lockableComponent
 
@@ -16,15 +16,30 @@ package adminsvc
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	adminsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/admin/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 
+	"github.com/uber/peloton/pkg/common/freeze"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
 	lifecyclemgrmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type adminServiceHandlerTestSuite struct {
@@ -34,6 +49,9 @@ type adminServiceHandlerTestSuite struct {
 
 	ctrl            *gomock.Controller
 	goalStateDriver *goalstatemocks.MockDriver
+	freezeOps       *objectmocks.MockClusterFreezeOps
+	freezeTracker   *freeze.Tracker
+	jobFactory      *cachedmocks.MockJobFactory
 }
 
 func (suite *adminServiceHandlerTestSuite) SetupTest() {
@@ -41,7 +59,19 @@ func (suite *adminServiceHandlerTestSuite) SetupTest() {
 	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
 	lockable := lifecyclemgrmocks.NewMockLockable(suite.ctrl)
 	suite.goalStateDriver.EXPECT().GetLockable().Return(lockable).AnyTimes()
-	suite.handler = createServiceHandler(suite.goalStateDriver, nil)
+	suite.freezeOps = objectmocks.NewMockClusterFreezeOps(suite.ctrl)
+	suite.freezeTracker = freeze.NewTracker(
+		suite.freezeOps,
+		freeze.Config{},
+		tally.NoopScope,
+	)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.handler = createServiceHandler(
+		suite.goalStateDriver,
+		nil,
+		suite.freezeTracker,
+		suite.jobFactory,
+	)
 }
 
 func (suite *adminServiceHandlerTestSuite) TearDownTest() {
@@ -89,3 +119,258 @@ func (suite *adminServiceHandlerTestSuite) TestRemoveLockdownFailDueToUnknownCom
 	suite.Error(err)
 	suite.Empty(resp.GetSuccesses())
 }
+
+// TestFreezeSuccess tests freezing the cluster
+func (suite *adminServiceHandlerTestSuite) TestFreezeSuccess() {
+	now := time.Now().UTC()
+	gomock.InOrder(
+		suite.freezeOps.EXPECT().
+			Set(gomock.Any(), true, "incident", "operator").
+			Return(nil),
+		suite.freezeOps.EXPECT().
+			Get(gomock.Any()).
+			Return(&ormobjects.ClusterFreezeObject{
+				Frozen:     true,
+				Reason:     "incident",
+				Owner:      "operator",
+				UpdateTime: now,
+			}, nil),
+	)
+
+	resp, err := suite.handler.Freeze(context.Background(), &adminsvc.FreezeRequest{
+		Reason: "incident",
+		Owner:  "operator",
+	})
+	suite.NoError(err)
+	suite.True(resp.GetStatus().GetFrozen())
+	suite.Equal("incident", resp.GetStatus().GetReason())
+	suite.Equal("operator", resp.GetStatus().GetOwner())
+	suite.Equal(now.Format(time.RFC3339), resp.GetStatus().GetUpdateTime())
+	suite.True(suite.freezeTracker.IsFrozen())
+}
+
+// TestFreezeWithoutReason tests freezing the cluster without a reason
+func (suite *adminServiceHandlerTestSuite) TestFreezeWithoutReason() {
+	_, err := suite.handler.Freeze(context.Background(), &adminsvc.FreezeRequest{
+		Owner: "operator",
+	})
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+	suite.False(suite.freezeTracker.IsFrozen())
+}
+
+// TestFreezeDBError tests failure to persist the freeze state
+func (suite *adminServiceHandlerTestSuite) TestFreezeDBError() {
+	suite.freezeOps.EXPECT().
+		Set(gomock.Any(), true, "incident", "operator").
+		Return(errors.New("db error"))
+
+	_, err := suite.handler.Freeze(context.Background(), &adminsvc.FreezeRequest{
+		Reason: "incident",
+		Owner:  "operator",
+	})
+	suite.Error(err)
+	suite.False(suite.freezeTracker.IsFrozen())
+}
+
+// TestUnfreezeSuccess tests lifting the freeze on the cluster
+func (suite *adminServiceHandlerTestSuite) TestUnfreezeSuccess() {
+	gomock.InOrder(
+		suite.freezeOps.EXPECT().
+			Set(gomock.Any(), true, "incident", "operator").
+			Return(nil),
+		suite.freezeOps.EXPECT().
+			Set(gomock.Any(), false, "", "operator").
+			Return(nil),
+		suite.freezeOps.EXPECT().
+			Get(gomock.Any()).
+			Return(&ormobjects.ClusterFreezeObject{
+				Owner: "operator",
+			}, nil),
+	)
+
+	suite.NoError(suite.freezeTracker.Freeze(
+		context.Background(), "incident", "operator"))
+
+	resp, err := suite.handler.Unfreeze(context.Background(), &adminsvc.UnfreezeRequest{
+		Owner: "operator",
+	})
+	suite.NoError(err)
+	suite.False(resp.GetStatus().GetFrozen())
+	suite.Empty(resp.GetStatus().GetUpdateTime())
+	suite.False(suite.freezeTracker.IsFrozen())
+}
+
+// TestGetFreezeStatusDBError tests failure to read the freeze state
+func (suite *adminServiceHandlerTestSuite) TestGetFreezeStatusDBError() {
+	suite.freezeOps.EXPECT().
+		Get(gomock.Any()).
+		Return(nil, errors.New("db error"))
+
+	_, err := suite.handler.GetFreezeStatus(
+		context.Background(),
+		&adminsvc.GetFreezeStatusRequest{},
+	)
+	suite.Error(err)
+}
+
+// TestGetJobCacheSnapshot tests getting the cached state of a job
+func (suite *adminServiceHandlerTestSuite) TestGetJobCacheSnapshot() {
+	jobID := &peloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"}
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+	cachedConfig := cachedmocks.NewMockJobConfigCache(suite.ctrl)
+	runtimeTask := cachedmocks.NewMockTask(suite.ctrl)
+	stateTask := cachedmocks.NewMockTask(suite.ctrl)
+	mesosTaskID := "b64fd26b-0e39-41b7-b22a-205b69f247bd-1-2"
+
+	suite.jobFactory.EXPECT().GetJob(jobID).Return(cachedJob)
+	cachedJob.EXPECT().ID().Return(jobID)
+	cachedJob.EXPECT().GetJobType().Return(pbjob.JobType_SERVICE)
+	cachedJob.EXPECT().CurrentState().Return(cached.JobStateVector{
+		State:        pbjob.JobState_RUNNING,
+		StateVersion: 2,
+	})
+	cachedJob.EXPECT().GoalState().Return(cached.JobStateVector{
+		State:        pbjob.JobState_RUNNING,
+		StateVersion: 3,
+	})
+	cachedJob.EXPECT().GetFirstTaskUpdateTime().Return(float64(1500000000))
+	cachedJob.EXPECT().GetLastTaskUpdateTime().Return(float64(0))
+	cachedJob.EXPECT().GetCachedConfig().Return(cachedConfig)
+	cachedConfig.EXPECT().GetChangeLog().Return(&peloton.ChangeLog{Version: 4})
+	cachedJob.EXPECT().GetAllTasks().Return(map[uint32]cached.Task{
+		0: stateTask,
+		1: runtimeTask,
+	})
+
+	runtimeTask.EXPECT().ID().Return(uint32(1))
+	runtimeTask.EXPECT().GetCacheRuntime().Return(&pbtask.RuntimeInfo{
+		State:                pbtask.TaskState_RUNNING,
+		GoalState:            pbtask.TaskState_RUNNING,
+		ConfigVersion:        4,
+		DesiredConfigVersion: 4,
+		MesosTaskId:          &mesos.TaskID{Value: &mesosTaskID},
+		Revision: &peloton.ChangeLog{
+			Version:   5,
+			UpdatedAt: 1500000000000,
+		},
+	})
+
+	stateTask.EXPECT().ID().Return(uint32(0))
+	stateTask.EXPECT().GetCacheRuntime().Return(nil)
+	stateTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+		State:         pbtask.TaskState_PENDING,
+		ConfigVersion: 3,
+	})
+	stateTask.EXPECT().GoalState().Return(cached.TaskStateVector{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 4,
+	})
+
+	resp, err := suite.handler.GetJobCacheSnapshot(
+		context.Background(),
+		&adminsvc.GetJobCacheSnapshotRequest{
+			JobId: &v1alphapeloton.JobID{Value: jobID.GetValue()},
+		})
+	suite.NoError(err)
+	suite.JSONEq(`{
+		"job_id": "b64fd26b-0e39-41b7-b22a-205b69f247bd",
+		"job_type": "SERVICE",
+		"state": "RUNNING",
+		"state_version": 2,
+		"goal_state": "RUNNING",
+		"desired_state_version": 3,
+		"config_version": 4,
+		"first_task_update_time": "2017-07-14T02:40:00Z",
+		"tasks": [
+			{
+				"instance_id": 0,
+				"runtime_cached": false,
+				"state": "PENDING",
+				"goal_state": "RUNNING",
+				"config_version": 3,
+				"desired_config_version": 4
+			},
+			{
+				"instance_id": 1,
+				"runtime_cached": true,
+				"state": "RUNNING",
+				"goal_state": "RUNNING",
+				"config_version": 4,
+				"desired_config_version": 4,
+				"mesos_task_id": "b64fd26b-0e39-41b7-b22a-205b69f247bd-1-2",
+				"healthy": "INVALID",
+				"revision_version": 5,
+				"last_update_time": "2017-07-14T02:40:00Z"
+			}
+		]
+	}`, resp.GetSnapshot())
+}
+
+// TestGetJobCacheSnapshotErrors tests the failures to get the cached
+// state of a job
+func (suite *adminServiceHandlerTestSuite) TestGetJobCacheSnapshotErrors() {
+	_, err := suite.handler.GetJobCacheSnapshot(
+		context.Background(),
+		&adminsvc.GetJobCacheSnapshotRequest{},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	jobID := &peloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"}
+	suite.jobFactory.EXPECT().GetJob(jobID).Return(nil)
+	_, err = suite.handler.GetJobCacheSnapshot(
+		context.Background(),
+		&adminsvc.GetJobCacheSnapshotRequest{
+			JobId: &v1alphapeloton.JobID{Value: jobID.GetValue()},
+		})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetStuckGoalStateEntities tests getting the entities stuck in goal state
+func (suite *adminServiceHandlerTestSuite) TestGetStuckGoalStateEntities() {
+	jobID := &peloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"}
+	updateID := &peloton.UpdateID{Value: "941ff353-ba82-49fe-8f80-fb5bc649b04d"}
+	stuckSince := time.Date(2017, 7, 14, 2, 40, 0, 0, time.UTC)
+
+	taskEntity := goalstate.StuckEntity{
+		Kind:       "task",
+		JobID:      jobID,
+		InstanceID: 1,
+	}
+	taskEntity.StuckSince = stuckSince
+	taskEntity.Failures = 3
+	taskEntity.LastError = "fake error"
+	taskEntity.Remediations = []string{"reconcile"}
+
+	updateEntity := goalstate.StuckEntity{
+		Kind:     "update",
+		JobID:    jobID,
+		UpdateID: updateID,
+	}
+	updateEntity.DeadLettered = true
+
+	suite.goalStateDriver.EXPECT().GetStuckEntities().
+		Return([]*goalstate.StuckEntity{&taskEntity, &updateEntity})
+
+	resp, err := suite.handler.GetStuckGoalStateEntities(
+		context.Background(),
+		&adminsvc.GetStuckGoalStateEntitiesRequest{})
+	suite.NoError(err)
+	suite.Equal([]*adminsvc.StuckGoalStateEntity{
+		{
+			Kind:         "task",
+			JobId:        &v1alphapeloton.JobID{Value: jobID.GetValue()},
+			InstanceId:   1,
+			StuckSince:   "2017-07-14T02:40:00Z",
+			Failures:     3,
+			LastError:    "fake error",
+			Remediations: []string{"reconcile"},
+		},
+		{
+			Kind:         "update",
+			JobId:        &v1alphapeloton.JobID{Value: jobID.GetValue()},
+			UpdateId:     updateID.GetValue(),
+			DeadLettered: true,
+		},
+	}, resp.GetEntities())
+}
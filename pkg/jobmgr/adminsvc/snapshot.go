@@ -0,0 +1,134 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adminsvc
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+)
+
+// jobCacheSnapshot is the state of a job cached in job manager
+type jobCacheSnapshot struct {
+	JobID               string `json:"job_id"`
+	JobType             string `json:"job_type"`
+	State               string `json:"state"`
+	StateVersion        uint64 `json:"state_version"`
+	GoalState           string `json:"goal_state"`
+	DesiredStateVersion uint64 `json:"desired_state_version"`
+	// Version of the cached job config, absent if the config is not cached
+	ConfigVersion       *uint64 `json:"config_version,omitempty"`
+	FirstTaskUpdateTime string  `json:"first_task_update_time,omitempty"`
+	LastTaskUpdateTime  string  `json:"last_task_update_time,omitempty"`
+
+	Tasks []*taskCacheSnapshot `json:"tasks"`
+}
+
+// taskCacheSnapshot is the state of a task cached in job manager
+type taskCacheSnapshot struct {
+	InstanceID uint32 `json:"instance_id"`
+	// Whether the task runtime is cached. Only the state vectors are
+	// known for a task whose runtime is not cached.
+	RuntimeCached        bool   `json:"runtime_cached"`
+	State                string `json:"state"`
+	GoalState            string `json:"goal_state"`
+	ConfigVersion        uint64 `json:"config_version"`
+	DesiredConfigVersion uint64 `json:"desired_config_version"`
+	MesosTaskID          string `json:"mesos_task_id,omitempty"`
+	Healthy              string `json:"healthy,omitempty"`
+	RevisionVersion      uint64 `json:"revision_version,omitempty"`
+	LastUpdateTime       string `json:"last_update_time,omitempty"`
+}
+
+// newJobCacheSnapshot returns a snapshot of the state of the cached job.
+// It only reads the cache and never loads missing state from DB.
+func newJobCacheSnapshot(cachedJob cached.Job) *jobCacheSnapshot {
+	currentState := cachedJob.CurrentState()
+	goalState := cachedJob.GoalState()
+
+	snapshot := &jobCacheSnapshot{
+		JobID:               cachedJob.ID().GetValue(),
+		JobType:             cachedJob.GetJobType().String(),
+		State:               currentState.State.String(),
+		StateVersion:        currentState.StateVersion,
+		GoalState:           goalState.State.String(),
+		DesiredStateVersion: goalState.StateVersion,
+		FirstTaskUpdateTime: formatTaskUpdateTime(cachedJob.GetFirstTaskUpdateTime()),
+		LastTaskUpdateTime:  formatTaskUpdateTime(cachedJob.GetLastTaskUpdateTime()),
+		Tasks:               []*taskCacheSnapshot{},
+	}
+
+	if config := cachedJob.GetCachedConfig(); config != nil {
+		version := config.GetChangeLog().GetVersion()
+		snapshot.ConfigVersion = &version
+	}
+
+	for _, cachedTask := range cachedJob.GetAllTasks() {
+		snapshot.Tasks = append(snapshot.Tasks, newTaskCacheSnapshot(cachedTask))
+	}
+	sort.Slice(snapshot.Tasks, func(i, j int) bool {
+		return snapshot.Tasks[i].InstanceID < snapshot.Tasks[j].InstanceID
+	})
+
+	return snapshot
+}
+
+// newTaskCacheSnapshot returns a snapshot of the state of the cached task
+func newTaskCacheSnapshot(cachedTask cached.Task) *taskCacheSnapshot {
+	runtime := cachedTask.GetCacheRuntime()
+	if runtime == nil {
+		currentState := cachedTask.CurrentState()
+		goalState := cachedTask.GoalState()
+		return &taskCacheSnapshot{
+			InstanceID:           cachedTask.ID(),
+			State:                currentState.State.String(),
+			GoalState:            goalState.State.String(),
+			ConfigVersion:        currentState.ConfigVersion,
+			DesiredConfigVersion: goalState.ConfigVersion,
+			MesosTaskID:          currentState.MesosTaskID.GetValue(),
+		}
+	}
+
+	snapshot := &taskCacheSnapshot{
+		InstanceID:           cachedTask.ID(),
+		RuntimeCached:        true,
+		State:                runtime.GetState().String(),
+		GoalState:            runtime.GetGoalState().String(),
+		ConfigVersion:        runtime.GetConfigVersion(),
+		DesiredConfigVersion: runtime.GetDesiredConfigVersion(),
+		MesosTaskID:          runtime.GetMesosTaskId().GetValue(),
+		Healthy:              runtime.GetHealthy().String(),
+		RevisionVersion:      runtime.GetRevision().GetVersion(),
+	}
+	if updatedAt := runtime.GetRevision().GetUpdatedAt(); updatedAt != 0 {
+		snapshot.LastUpdateTime = time.Unix(0, int64(updatedAt)*int64(time.Millisecond)).
+			UTC().Format(time.RFC3339Nano)
+	}
+	return snapshot
+}
+
+// formatTaskUpdateTime formats a task update time of a cached job, in
+// seconds since epoch, in RFC3339 format. Returns an empty string if the
+// time is not set.
+func formatTaskUpdateTime(t float64) string {
+	if t == 0 {
+		return ""
+	}
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).
+		UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cached
+
+import (
+	"time"
+)
+
+const (
+	// _defaultEvictionPeriod is the default period at which the cache
+	// is checked against its budget
+	_defaultEvictionPeriod = 1 * time.Minute
+
+	// _defaultMissingTaskTTL is the default time for which a task
+	// not found in the DB is remembered as missing
+	_defaultMissingTaskTTL = 10 * time.Second
+)
+
+// Config is the configuration of the job cache
+type Config struct {
+	// MaxJobs is the budget of jobs kept in cache.
+	// 0 means the number of jobs is not bounded.
+	MaxJobs int `yaml:"max_jobs"`
+
+	// MaxTasks is the budget of tasks, summed across all the jobs,
+	// kept in cache. Tasks account for most of the memory used by
+	// the cache. 0 means the number of tasks is not bounded.
+	MaxTasks int `yaml:"max_tasks"`
+
+	// EvictionPeriod is the period at which the cache is checked
+	// against its budget, and jobs are evicted if it is exceeded.
+	EvictionPeriod time.Duration `yaml:"eviction_period"`
+
+	// MissingTaskTTL is the time for which a task not found in the DB
+	// is remembered as missing by the job, so that repeated lookups of
+	// the task do not hit the DB. A negative value disables caching of
+	// missing tasks.
+	MissingTaskTTL time.Duration `yaml:"missing_task_ttl"`
+}
+
+// normalize sets the default values of the config
+func (c *Config) normalize() {
+	if c.EvictionPeriod == 0 {
+		c.EvictionPeriod = _defaultEvictionPeriod
+	}
+	if c.MissingTaskTTL == 0 {
+		c.MissingTaskTTL = _defaultMissingTaskTTL
+	}
+}
+
+// hasBudget returns true if the cache is bounded
+func (c *Config) hasBudget() bool {
+	return c.MaxJobs > 0 || c.MaxTasks > 0
+}
+
+// exceeds returns true if the given number of jobs and tasks
+// exceeds the budget of the cache
+func (c *Config) exceeds(jobs int, tasks int) bool {
+	return (c.MaxJobs > 0 && jobs > c.MaxJobs) ||
+		(c.MaxTasks > 0 && tasks > c.MaxTasks)
+}
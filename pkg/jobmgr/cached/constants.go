@@ -32,6 +32,10 @@ const (
 	// be run to create/update task runtimes of a job
 	_defaultMaxParallelBatches = 1000
 
+	// _patchTasksBatchSize is the maximum number of task runtimes which
+	// are locked and written to DB together when patching multiple tasks
+	_patchTasksBatchSize = 50
+
 	// time duration at which cache metrics are computed
 	_defaultMetricsUpdateTick = 1 * time.Minute
 
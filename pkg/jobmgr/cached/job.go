@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -43,6 +44,8 @@ import (
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"go.uber.org/multierr"
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
@@ -344,6 +347,24 @@ type WorkflowOps interface {
 type JobConfigCache interface {
 	jobmgrcommon.JobConfig
 	HasControllerTask() bool
+	// GetControllerInstanceIDs returns the instance IDs of the controller
+	// tasks in increasing order.
+	GetControllerInstanceIDs() []uint32
+	// GetControllerPolicy returns the policy deriving the job state from
+	// the states of the controller tasks.
+	GetControllerPolicy() pbjob.ControllerPolicy
+	// GetArrayConfig returns the array config of the job, which is nil
+	// if the job is not a job array.
+	GetArrayConfig() *pbjob.JobArrayConfig
+	// GetMaxCompletedJobTtl returns the time in seconds after completion at
+	// which the job is deleted, zero if the job is never deleted.
+	GetMaxCompletedJobTtl() uint32
+	// GetMaxCompletionTime returns the time in seconds after its start by
+	// which the job must complete, zero if the job has no deadline.
+	GetMaxCompletionTime() uint32
+	// GetCronConfig returns the cron config of the job, which is nil if
+	// the job is not a cron job.
+	GetCronConfig() *pbjob.CronConfig
 }
 
 // JobStateVector defines the state of a job.
@@ -369,17 +390,28 @@ func newJob(id *peloton.JobID, jobFactory *jobFactory) *job {
 
 // cachedConfig structure holds the config fields need to be cached
 type cachedConfig struct {
-	instanceCount     uint32                  // Instance count in the job configuration
-	sla               *pbjob.SlaConfig        // SLA configuration in the job configuration
-	jobType           pbjob.JobType           // Job type (batch or service) in the job configuration
-	changeLog         *peloton.ChangeLog      // ChangeLog in the job configuration
-	respoolID         *peloton.ResourcePoolID // Resource Pool ID in the job configuration
-	hasControllerTask bool                    // if the job contains any task which is controller task
-	labels            []*peloton.Label        // Label of the job
-	name              string                  // Name of the job
-	placementStrategy pbjob.PlacementStrategy // Placement strategy
-	owner             string                  // Owner of the job in the job configuration
-	owningTeam        string                  // Owning team of the job in the job configuration
+	instanceCount         uint32                             // Instance count in the job configuration
+	sla                   *pbjob.SlaConfig                   // SLA configuration in the job configuration
+	jobType               pbjob.JobType                      // Job type (batch or service) in the job configuration
+	changeLog             *peloton.ChangeLog                 // ChangeLog in the job configuration
+	respoolID             *peloton.ResourcePoolID            // Resource Pool ID in the job configuration
+	hasControllerTask     bool                               // if the job contains any task which is controller task
+	controllerInstanceIDs []uint32                           // Instance IDs of the controller tasks
+	controllerTaskName    string                             // Name of the controller tasks in the job configuration
+	mergeStrategy         *pbjob.InstanceConfigMergeStrategy // Strategy merging the instance configs into the default config
+	controllerPolicy      pbjob.ControllerPolicy             // Policy deriving the job state from the controller tasks
+	arrayConfig           *pbjob.JobArrayConfig              // Array config if the job is a job array
+	maxCompletedJobTTL    uint32                             // Seconds after completion at which the job is deleted
+	maxCompletionTime     uint32                             // Seconds after start by which the job must complete
+	cronConfig            *pbjob.CronConfig                  // Cron config if the job is a cron job
+	labels                []*peloton.Label                   // Label of the job
+	name                  string                             // Name of the job
+	placementStrategy     pbjob.PlacementStrategy            // Placement strategy
+	placementRelaxation   *pbjob.PlacementRelaxationPolicy   // Placement relaxation policy
+	preemptionPolicy      *pbjob.PreemptionPolicy            // Preemption policy
+	owner                 string                             // Owner of the job in the job configuration
+	owningTeam            string                             // Owning team of the job in the job configuration
+	configHash            string                             // Checksum of the job configuration
 }
 
 // job structure holds the information about a given active job
@@ -403,6 +435,10 @@ type job struct {
 
 	tasks map[uint32]*task // map of all job tasks
 
+	// tasks recently found to be missing in the DB, along with
+	// the error returned for them. Created on first use.
+	missingTasks map[uint32]*missingTask
+
 	// time at which the first mesos task update was received (indicates when a job starts running)
 	firstTaskUpdateTime float64
 	// time at which the last mesos task update was received (helps determine when job completes)
@@ -423,6 +459,18 @@ type job struct {
 	// workflow in memory before clearing from workflows map.
 	prevUpdateID string
 	prevWorkflow *update
+
+	// time, in unix nanoseconds, at which the job was last looked up
+	// in the job factory. Used to evict the least recently used jobs.
+	lastAccessTime atomic.Int64
+}
+
+// missingTask is a negative cache entry of a task not found in the DB
+type missingTask struct {
+	// err is the error returned when the task was looked up
+	err error
+	// expiry is the time after which the task is looked up again
+	expiry time.Time
 }
 
 // instanceAvailabilityInfo holds the instance availability information of the job
@@ -454,6 +502,11 @@ func (j *job) AddTask(
 
 	t, ok := j.tasks[id]
 	if !ok {
+		// the task was recently found to be missing in the DB
+		if err := j.getMissingTaskError(id); err != nil {
+			return nil, err
+		}
+
 		t = newTask(j.ID(), id, j.jobFactory, j.jobType)
 
 		// first fetch the runtime of the task
@@ -472,8 +525,9 @@ func (j *job) AddTask(
 			}
 
 			if j.config.GetInstanceCount() <= id {
-				return nil, InstanceIDExceedsInstanceCountError
+				err = InstanceIDExceedsInstanceCountError
 			}
+			j.addMissingTask(id, err)
 			return nil, err
 		}
 		// store the task with the job
@@ -519,7 +573,10 @@ func (j *job) CreateTaskConfigs(
 				"failed to get instance config for instance %v", id,
 			)
 		}
-		taskConfig := taskconfig.Merge(jobConfig.GetDefaultConfig(), cfg)
+		taskConfig := taskconfig.MergeWithStrategy(
+			jobConfig.GetDefaultConfig(),
+			cfg,
+			jobConfig.GetInstanceConfigMergeStrategy())
 
 		if spec != nil {
 			// The assumption here is that if the spec is present, it has
@@ -606,6 +663,16 @@ func (j *job) PatchTasks(
 
 	instancesSucceeded = getIdsFromDiffs(runtimesToPatch)
 
+	// multiple tasks are patched with batched writes to DB
+	if len(instancesSucceeded) > 1 {
+		instancesSucceeded, err = j.patchTasksInBatch(
+			ctx,
+			instancesSucceeded,
+			runtimeDiffs,
+		)
+		return instancesSucceeded, instancesToBeRetried, err
+	}
+
 	patchSingleTask := func(id uint32) error {
 		t, err := j.AddTask(ctx, id)
 		if err != nil {
@@ -622,6 +689,150 @@ func (j *job) PatchTasks(
 	return instancesSucceeded, instancesToBeRetried, err
 }
 
+// patchTasksInBatch patches the runtime diffs of the given instances and
+// persists the patched runtimes in batched writes, instead of one write
+// per task. The instances are patched in batches of _patchTasksBatchSize
+// in the order of instance id. The locks of the tasks in a batch are held
+// only until that batch is written. On failure, the instances of the
+// batches written before the failure are returned along with the error.
+func (j *job) patchTasksInBatch(
+	ctx context.Context,
+	instances []uint32,
+	runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+) ([]uint32, error) {
+	for _, id := range instances {
+		if err := validateRuntimeDiff(runtimeDiffs[id]); err != nil {
+			return nil, err
+		}
+	}
+
+	// add the tasks to cache first, since that may need to
+	// read the task runtimes from DB
+	var tasksLock sync.Mutex
+	tasks := make(map[uint32]*task)
+	addSingleTask := func(id uint32) error {
+		t, err := j.AddTask(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		tasksLock.Lock()
+		defer tasksLock.Unlock()
+		tasks[id] = t.(*task)
+		return nil
+	}
+
+	if err := util.RunInParallel(
+		j.ID().GetValue(),
+		instances,
+		addSingleTask); err != nil {
+		return nil, err
+	}
+
+	sortedInstances := make([]uint32, len(instances))
+	copy(sortedInstances, instances)
+	sort.Slice(sortedInstances, func(i, k int) bool {
+		return sortedInstances[i] < sortedInstances[k]
+	})
+
+	var instancesPatched []uint32
+	var errs []error
+	for start := 0; start < len(sortedInstances); start += _patchTasksBatchSize {
+		end := start + _patchTasksBatchSize
+		if end > len(sortedInstances) {
+			end = len(sortedInstances)
+		}
+
+		batch := sortedInstances[start:end]
+		written, err := j.patchTasksBatch(ctx, batch, tasks, runtimeDiffs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if !written {
+			return instancesPatched, multierr.Combine(errs...)
+		}
+		instancesPatched = append(instancesPatched, batch...)
+	}
+	return instancesPatched, multierr.Combine(errs...)
+}
+
+// patchTasksBatch locks the tasks of one batch, patches their runtimes and
+// writes them to DB in a single call. The listeners are notified after the
+// locks are dropped. It returns whether the batch was written to DB, which
+// can be true along with an error if the patched runtimes fail to be
+// committed to cache.
+func (j *job) patchTasksBatch(
+	ctx context.Context,
+	batch []uint32,
+	tasks map[uint32]*task,
+	runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+) (bool, error) {
+	runtimeCopies := make(map[uint32]*pbtask.RuntimeInfo)
+	labelsCopies := make(map[uint32][]*peloton.Label)
+
+	// notify listeners after dropping the locks
+	defer func() {
+		for _, id := range batch {
+			j.jobFactory.notifyTaskRuntimeChanged(
+				j.ID(),
+				id,
+				j.jobType,
+				runtimeCopies[id],
+				labelsCopies[id],
+			)
+		}
+	}()
+
+	for _, id := range batch {
+		tasks[id].Lock()
+		defer tasks[id].Unlock()
+	}
+
+	newRuntimes := make(map[uint32]*pbtask.RuntimeInfo)
+	for _, id := range batch {
+		newRuntime, err := tasks[id].preparePatch(ctx, runtimeDiffs[id])
+		if err != nil {
+			return false, err
+		}
+		if newRuntime != nil {
+			newRuntimes[id] = newRuntime
+		}
+	}
+
+	if len(newRuntimes) == 0 {
+		return true, nil
+	}
+
+	if err := j.jobFactory.taskStore.UpdateTaskRuntimes(
+		ctx,
+		j.ID(),
+		newRuntimes,
+		j.jobType); err != nil {
+		// clean the runtimes in cache on DB write failure
+		for id := range newRuntimes {
+			tasks[id].cleanTaskCache()
+		}
+		return false, err
+	}
+
+	var errs []error
+	for _, id := range batch {
+		newRuntime, ok := newRuntimes[id]
+		if !ok {
+			continue
+		}
+
+		runtimeCopy, labelsCopy, err := tasks[id].commitPatch(ctx, newRuntime)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		runtimeCopies[id] = runtimeCopy
+		labelsCopies[id] = labelsCopy
+	}
+	return true, multierr.Combine(errs...)
+}
+
 func (j *job) ReplaceTasks(
 	taskInfos map[uint32]*pbtask.TaskInfo,
 	forceReplace bool) (err error) {
@@ -1300,6 +1511,44 @@ func (j *job) GetCachedConfig() jobmgrcommon.JobConfig {
 	return j.config
 }
 
+// touch records that the job has been looked up in the job factory
+func (j *job) touch() {
+	j.lastAccessTime.Store(time.Now().UnixNano())
+}
+
+// taskCount returns the number of tasks of the job in cache
+func (j *job) taskCount() int {
+	j.RLock()
+	defer j.RUnlock()
+
+	return len(j.tasks)
+}
+
+// isEvictable returns true if the job can be evicted from cache, which
+// is the case if both its state and goal state are terminal and it has
+// no active workflow. The job is loaded back from DB when needed again.
+func (j *job) isEvictable() bool {
+	j.RLock()
+	if j.runtime == nil ||
+		!util.IsPelotonJobStateTerminal(j.runtime.GetState()) ||
+		!util.IsPelotonJobStateTerminal(j.runtime.GetGoalState()) {
+		j.RUnlock()
+		return false
+	}
+	workflows := make([]*update, 0, len(j.workflows))
+	for _, w := range j.workflows {
+		workflows = append(workflows, w)
+	}
+	j.RUnlock()
+
+	for _, w := range workflows {
+		if IsUpdateStateActive(w.GetState().State) {
+			return false
+		}
+	}
+	return true
+}
+
 // RepopulateInstanceAvailabilityInfo repopulates the instance availability information in the job cache
 func (j *job) RepopulateInstanceAvailabilityInfo(ctx context.Context) error {
 	if j.jobType != pbjob.JobType_SERVICE {
@@ -1404,9 +1653,42 @@ func (j *job) addTaskToJobMap(id uint32) *task {
 	}
 
 	j.tasks[id] = t
+	delete(j.missingTasks, id)
 	return t
 }
 
+// getMissingTaskError returns the error returned when the task was last
+// looked up, if the task was found to be missing in the DB within the
+// missing task TTL. The caller should have the job lock.
+func (j *job) getMissingTaskError(id uint32) error {
+	m, ok := j.missingTasks[id]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(m.expiry) {
+		delete(j.missingTasks, id)
+		return nil
+	}
+	j.jobFactory.mtx.MissingTaskCacheHits.Inc(1)
+	return m.err
+}
+
+// addMissingTask remembers that a task is missing in the DB for the
+// missing task TTL. The caller should have the job lock.
+func (j *job) addMissingTask(id uint32, err error) {
+	ttl := j.jobFactory.cfg.MissingTaskTTL
+	if ttl <= 0 {
+		return
+	}
+	if j.missingTasks == nil {
+		j.missingTasks = make(map[uint32]*missingTask)
+	}
+	j.missingTasks[id] = &missingTask{
+		err:    err,
+		expiry: time.Now().Add(ttl),
+	}
+}
+
 // createJobConfig creates job config in db and cache
 func (j *job) createJobConfig(
 	ctx context.Context,
@@ -1826,6 +2108,10 @@ func (j *job) populateJobConfigCache(config *pbjob.JobConfig) {
 		j.config = &cachedConfig{}
 	}
 
+	if j.config.instanceCount != config.GetInstanceCount() {
+		// instances may have been added to the job
+		j.missingTasks = nil
+	}
 	j.config.instanceCount = config.GetInstanceCount()
 
 	if config.GetSLA() != nil {
@@ -1850,13 +2136,31 @@ func (j *job) populateJobConfigCache(config *pbjob.JobConfig) {
 
 	j.config.name = config.GetName()
 
-	j.config.hasControllerTask = hasControllerTask(config)
+	j.config.controllerInstanceIDs = taskconfig.ControllerInstanceIDs(config)
+	j.config.controllerTaskName = config.GetControllerTaskName()
+	j.config.mergeStrategy = config.GetInstanceConfigMergeStrategy()
+	j.config.hasControllerTask = len(j.config.controllerInstanceIDs) > 0
+	j.config.controllerPolicy = config.GetControllerPolicy()
+	j.config.arrayConfig = config.GetArrayConfig()
+	j.config.maxCompletedJobTTL = config.GetMaxCompletedJobTtl()
+	j.config.maxCompletionTime = config.GetSLA().GetMaxCompletionTime()
+	j.config.cronConfig = config.GetCronConfig()
 
 	j.config.jobType = config.GetType()
 	j.jobType = j.config.jobType
 	j.config.placementStrategy = config.GetPlacementStrategy()
+	j.config.placementRelaxation = config.GetPlacementRelaxationPolicy()
+	j.config.preemptionPolicy = config.GetPreemptionPolicy()
 	j.config.owner = config.GetOwner()
 	j.config.owningTeam = config.GetOwningTeam()
+
+	configHash, err := taskconfig.HashJobConfig(config)
+	if err != nil {
+		log.WithError(err).
+			WithField("job_id", j.id.GetValue()).
+			Warn("failed to compute job config hash")
+	}
+	j.config.configHash = configHash
 }
 
 // getUpdatedJobRuntimeCache validates the runtime input and
@@ -1967,6 +2271,26 @@ func (j *job) mergeRuntime(newRuntime *pbjob.RuntimeInfo) *pbjob.RuntimeInfo {
 		runtime.ResourceUsage = newRuntime.GetResourceUsage()
 	}
 
+	if newRuntime.GetResourceBudget() != nil {
+		runtime.ResourceBudget = newRuntime.GetResourceBudget()
+	}
+
+	if newRuntime.GetArrayStatus() != nil {
+		runtime.ArrayStatus = newRuntime.GetArrayStatus()
+	}
+
+	if stringsutil.ValidateString(newRuntime.GetDeadlineExceededTime()) {
+		runtime.DeadlineExceededTime = newRuntime.GetDeadlineExceededTime()
+	}
+
+	if newRuntime.GetCronStatus() != nil {
+		runtime.CronStatus = newRuntime.GetCronStatus()
+	}
+
+	if newRuntime.GetTaskFailureStats() != nil {
+		runtime.TaskFailureStats = newRuntime.GetTaskFailureStats()
+	}
+
 	if newRuntime.GetConfigVersion() > 0 {
 		runtime.ConfigVersion = newRuntime.GetConfigVersion()
 	}
@@ -2019,6 +2343,7 @@ func (j *job) mergeRuntime(newRuntime *pbjob.RuntimeInfo) *pbjob.RuntimeInfo {
 func (j *job) invalidateCache() {
 	j.runtime = nil
 	j.config = nil
+	j.missingTasks = nil
 }
 
 // getInstanceAvailabilityInfo returns the instance availability info of the job.
@@ -2534,7 +2859,7 @@ func (j *job) isWorkflowNoop(
 		return false
 	}
 
-	if !isJobConfigEqual(prevJobConfig, targetJobConfig) {
+	if !j.isJobConfigEqual(prevJobConfig, targetJobConfig) {
 		return false
 	}
 
@@ -2545,10 +2870,29 @@ func (j *job) isWorkflowNoop(
 	return true
 }
 
-func isJobConfigEqual(
+// getConfigHash returns the checksum of the job config, which is read from
+// the cache if the config is the cached config version.
+func (j *job) getConfigHash(config *pbjob.JobConfig) (string, error) {
+	if j.config != nil && len(j.config.configHash) != 0 &&
+		j.config.changeLog.GetVersion() == config.GetChangeLog().GetVersion() {
+		return j.config.configHash, nil
+	}
+	return taskconfig.HashJobConfig(config)
+}
+
+func (j *job) isJobConfigEqual(
 	prevJobConfig *pbjob.JobConfig,
 	targetJobConfig *pbjob.JobConfig,
 ) bool {
+	// the checksums of the configs are the same iff the configs are
+	// semantically the same, the configs are only compared field by
+	// field if they cannot be hashed
+	prevHash, prevErr := j.getConfigHash(prevJobConfig)
+	targetHash, targetErr := taskconfig.HashJobConfig(targetJobConfig)
+	if prevErr == nil && targetErr == nil {
+		return prevHash == targetHash
+	}
+
 	if prevJobConfig.GetInstanceCount() != targetJobConfig.GetInstanceCount() {
 		return false
 	}
@@ -2558,12 +2902,14 @@ func isJobConfigEqual(
 	}
 
 	for i := uint32(0); i < prevJobConfig.GetInstanceCount(); i++ {
-		prevTaskConfig := taskconfig.Merge(
+		prevTaskConfig := taskconfig.MergeWithStrategy(
 			prevJobConfig.GetDefaultConfig(),
-			prevJobConfig.GetInstanceConfig()[i])
-		targetTaskConfig := taskconfig.Merge(
+			prevJobConfig.GetInstanceConfig()[i],
+			prevJobConfig.GetInstanceConfigMergeStrategy())
+		targetTaskConfig := taskconfig.MergeWithStrategy(
 			targetJobConfig.GetDefaultConfig(),
-			targetJobConfig.GetInstanceConfig()[i])
+			targetJobConfig.GetInstanceConfig()[i],
+			targetJobConfig.GetInstanceConfigMergeStrategy())
 		if taskconfig.HasTaskConfigChanged(prevTaskConfig, targetTaskConfig) {
 			return false
 		}
@@ -3198,6 +3544,38 @@ func (c *cachedConfig) HasControllerTask() bool {
 	return c.hasControllerTask
 }
 
+func (c *cachedConfig) GetControllerInstanceIDs() []uint32 {
+	return c.controllerInstanceIDs
+}
+
+func (c *cachedConfig) GetControllerTaskName() string {
+	return c.controllerTaskName
+}
+
+func (c *cachedConfig) GetInstanceConfigMergeStrategy() *pbjob.InstanceConfigMergeStrategy {
+	return c.mergeStrategy
+}
+
+func (c *cachedConfig) GetControllerPolicy() pbjob.ControllerPolicy {
+	return c.controllerPolicy
+}
+
+func (c *cachedConfig) GetArrayConfig() *pbjob.JobArrayConfig {
+	return c.arrayConfig
+}
+
+func (c *cachedConfig) GetMaxCompletedJobTtl() uint32 {
+	return c.maxCompletedJobTTL
+}
+
+func (c *cachedConfig) GetMaxCompletionTime() uint32 {
+	return c.maxCompletionTime
+}
+
+func (c *cachedConfig) GetCronConfig() *pbjob.CronConfig {
+	return c.cronConfig
+}
+
 func (c *cachedConfig) GetLabels() []*peloton.Label {
 	return c.labels
 }
@@ -3210,6 +3588,14 @@ func (c *cachedConfig) GetPlacementStrategy() pbjob.PlacementStrategy {
 	return c.placementStrategy
 }
 
+func (c *cachedConfig) GetPlacementRelaxationPolicy() *pbjob.PlacementRelaxationPolicy {
+	return c.placementRelaxation
+}
+
+func (c *cachedConfig) GetPreemptionPolicy() *pbjob.PreemptionPolicy {
+	return c.preemptionPolicy
+}
+
 func (c *cachedConfig) GetOwner() string {
 	return c.owner
 }
@@ -3225,13 +3611,80 @@ func HasControllerTask(config jobmgrcommon.JobConfig) bool {
 		return castedCachedConfig.HasControllerTask()
 	}
 
-	return hasControllerTask(config.(*pbjob.JobConfig))
+	return len(taskconfig.ControllerInstanceIDs(config.(*pbjob.JobConfig))) > 0
 }
 
-func hasControllerTask(config *pbjob.JobConfig) bool {
-	return taskconfig.Merge(
-		config.GetDefaultConfig(),
-		config.GetInstanceConfig()[0]).GetController()
+// GetControllerInstanceIDs returns the instance IDs of the controller tasks
+// of a job in increasing order, it can accept both cachedConfig and full
+// JobConfig.
+func GetControllerInstanceIDs(config jobmgrcommon.JobConfig) []uint32 {
+	if castedCachedConfig, ok := config.(JobConfigCache); ok {
+		return castedCachedConfig.GetControllerInstanceIDs()
+	}
+
+	return taskconfig.ControllerInstanceIDs(config.(*pbjob.JobConfig))
+}
+
+// GetControllerPolicy returns the controller policy of a job, it can accept
+// both cachedConfig and full JobConfig.
+func GetControllerPolicy(config jobmgrcommon.JobConfig) pbjob.ControllerPolicy {
+	if castedCachedConfig, ok := config.(JobConfigCache); ok {
+		return castedCachedConfig.GetControllerPolicy()
+	}
+
+	return config.(*pbjob.JobConfig).GetControllerPolicy()
+}
+
+// GetArrayConfig returns the array config of a job, or nil if the job is
+// not a job array. It can accept both cachedConfig and full JobConfig.
+func GetArrayConfig(config jobmgrcommon.JobConfig) *pbjob.JobArrayConfig {
+	switch c := config.(type) {
+	case JobConfigCache:
+		return c.GetArrayConfig()
+	case *pbjob.JobConfig:
+		return c.GetArrayConfig()
+	}
+	return nil
+}
+
+// GetMaxCompletedJobTTL returns the time after completion at which a job
+// is deleted, or zero if the job is never deleted automatically. It can
+// accept both cachedConfig and full JobConfig.
+func GetMaxCompletedJobTTL(config jobmgrcommon.JobConfig) time.Duration {
+	var ttl uint32
+	switch c := config.(type) {
+	case JobConfigCache:
+		ttl = c.GetMaxCompletedJobTtl()
+	case *pbjob.JobConfig:
+		ttl = c.GetMaxCompletedJobTtl()
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// GetMaxCompletionTime returns the time after its start by which a job
+// must complete, or zero if the job has no deadline. It can accept both
+// cachedConfig and full JobConfig.
+func GetMaxCompletionTime(config jobmgrcommon.JobConfig) time.Duration {
+	var maxCompletionTime uint32
+	switch c := config.(type) {
+	case JobConfigCache:
+		maxCompletionTime = c.GetMaxCompletionTime()
+	case *pbjob.JobConfig:
+		maxCompletionTime = c.GetSLA().GetMaxCompletionTime()
+	}
+	return time.Duration(maxCompletionTime) * time.Second
+}
+
+// GetCronConfig returns the cron config of a job, or nil if the job is
+// not a cron job. It can accept both cachedConfig and full JobConfig.
+func GetCronConfig(config jobmgrcommon.JobConfig) *pbjob.CronConfig {
+	switch c := config.(type) {
+	case JobConfigCache:
+		return c.GetCronConfig()
+	case *pbjob.JobConfig:
+		return c.GetCronConfig()
+	}
+	return nil
 }
 
 func getIdsFromRuntimeMap(input map[uint32]*pbtask.RuntimeInfo) []uint32 {
@@ -15,6 +15,7 @@
 package cached
 
 import (
+	"sort"
 	"sync"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
 	"github.com/uber-go/tally"
 )
 
@@ -52,6 +54,10 @@ type JobFactory interface {
 	// GetAllJobs returns the list of all jobs in cache.
 	GetAllJobs() map[string]Job
 
+	// SetEvictionHook sets the hook run for each job before it is
+	// evicted from the cache.
+	SetEvictionHook(hook JobEvictionHook)
+
 	// Start emitting metrics.
 	Start()
 
@@ -59,6 +65,11 @@ type JobFactory interface {
 	Stop()
 }
 
+// JobEvictionHook is run for a job before it is evicted from the cache,
+// so that the job, its tasks and its workflows are untracked from the goal
+// state engines, which would otherwise keep evaluating them.
+type JobEvictionHook func(j Job)
+
 type jobFactory struct {
 	sync.RWMutex //  Mutex to acquire before accessing any variables in the job factory object
 
@@ -83,6 +94,15 @@ type jobFactory struct {
 	listeners []JobTaskListener
 	// channel to indicate that the job factory needs to stop
 	stopChan chan struct{}
+
+	// budget of the cache
+	cfg Config
+	// hook run for each job before it is evicted from the cache
+	evictionHook JobEvictionHook
+	// number of job lookups which found or missed the job in cache,
+	// since the last time metrics were published
+	hits   atomic.Int64
+	misses atomic.Int64
 }
 
 // InitJobFactory initializes the job factory object.
@@ -94,7 +114,9 @@ func InitJobFactory(
 	ormStore *ormobjects.Store,
 	parentScope tally.Scope,
 	listeners []JobTaskListener,
+	cfg Config,
 ) JobFactory {
+	cfg.normalize()
 	return &jobFactory{
 		jobs:               map[string]*job{},
 		jobStore:           jobStore,
@@ -111,6 +133,7 @@ func InitJobFactory(
 		mtx:                NewMetrics(parentScope.SubScope("cache")),
 		taskMetrics:        NewTaskMetrics(parentScope.SubScope("task")),
 		listeners:          listeners,
+		cfg:                cfg,
 	}
 }
 
@@ -126,6 +149,7 @@ func (f *jobFactory) AddJob(id *peloton.JobID) Job {
 	j, ok := f.jobs[id.GetValue()]
 	if !ok {
 		j = newJob(id, f)
+		j.touch()
 		f.jobs[id.GetValue()] = j
 	}
 
@@ -149,9 +173,12 @@ func (f *jobFactory) GetJob(id *peloton.JobID) Job {
 	defer f.RUnlock()
 
 	if j, ok := f.jobs[id.GetValue()]; ok {
+		f.hits.Inc()
+		j.touch()
 		return j
 	}
 
+	f.misses.Inc()
 	return nil
 }
 
@@ -166,6 +193,15 @@ func (f *jobFactory) GetAllJobs() map[string]Job {
 	return jobMap
 }
 
+// SetEvictionHook sets the hook run for each job before it is evicted
+// from the cache.
+func (f *jobFactory) SetEvictionHook(hook JobEvictionHook) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.evictionHook = hook
+}
+
 // Start the job factory, starts emitting metrics.
 func (f *jobFactory) Start() {
 	f.Lock()
@@ -178,6 +214,9 @@ func (f *jobFactory) Start() {
 
 	f.stopChan = make(chan struct{})
 	go f.runPublishMetrics(f.stopChan)
+	if f.cfg.hasBudget() {
+		go f.runEviction(f.stopChan)
+	}
 	log.Info("job factory started")
 }
 
@@ -216,6 +255,119 @@ func (f *jobFactory) runPublishMetrics(stopChan <-chan struct{}) {
 	}
 }
 
+// runEviction is the entrypoint to start and stop evicting jobs
+// to keep the cache within its budget
+func (f *jobFactory) runEviction(stopChan <-chan struct{}) {
+	ticker := time.NewTicker(f.cfg.EvictionPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.evictJobs()
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// evictJobs evicts the least recently used terminal jobs from cache
+// until the cache is within its budget. Non-terminal jobs are never
+// evicted, since the goal state engine relies on them being in cache.
+// Returns the number of jobs evicted for test purpose.
+func (f *jobFactory) evictJobs() int {
+	type candidate struct {
+		j          *job
+		tasks      int
+		accessTime int64
+	}
+
+	f.RLock()
+	jobs := make([]*job, 0, len(f.jobs))
+	for _, j := range f.jobs {
+		jobs = append(jobs, j)
+	}
+	f.RUnlock()
+
+	var (
+		totalTasks int
+		candidates []candidate
+	)
+	for _, j := range jobs {
+		tasks := j.taskCount()
+		totalTasks += tasks
+		if j.isEvictable() {
+			candidates = append(candidates, candidate{
+				j:          j,
+				tasks:      tasks,
+				accessTime: j.lastAccessTime.Load(),
+			})
+		}
+	}
+	totalJobs := len(jobs)
+
+	if !f.cfg.exceeds(totalJobs, totalTasks) {
+		f.mtx.CacheOverBudget.Update(0)
+		return 0
+	}
+
+	sort.Slice(candidates, func(i, k int) bool {
+		return candidates[i].accessTime < candidates[k].accessTime
+	})
+
+	evicted := 0
+	for _, c := range candidates {
+		if !f.cfg.exceeds(totalJobs, totalTasks) {
+			break
+		}
+
+		// skip the job if it has been cleared, replaced or
+		// looked up since the candidates were collected
+		f.RLock()
+		cur, ok := f.jobs[c.j.ID().GetValue()]
+		hook := f.evictionHook
+		f.RUnlock()
+		if !ok || cur != c.j || c.j.lastAccessTime.Load() != c.accessTime {
+			continue
+		}
+
+		// untrack the job before evicting it, the hook is run without
+		// holding the factory lock since it calls into the goal state
+		if hook != nil {
+			hook(c.j)
+		}
+
+		f.Lock()
+		if cur, ok := f.jobs[c.j.ID().GetValue()]; !ok || cur != c.j {
+			f.Unlock()
+			continue
+		}
+		delete(f.jobs, c.j.ID().GetValue())
+		f.Unlock()
+
+		totalJobs--
+		totalTasks -= c.tasks
+		evicted++
+	}
+
+	f.mtx.JobsEvicted.Inc(int64(evicted))
+	if f.cfg.exceeds(totalJobs, totalTasks) {
+		f.mtx.CacheOverBudget.Update(1)
+		log.WithFields(log.Fields{
+			"cached_jobs":  totalJobs,
+			"cached_tasks": totalTasks,
+			"max_jobs":     f.cfg.MaxJobs,
+			"max_tasks":    f.cfg.MaxTasks,
+		}).Warn("job cache is over budget after evicting terminal jobs")
+	} else {
+		f.mtx.CacheOverBudget.Update(0)
+	}
+
+	log.WithField("jobs_evicted", evicted).
+		Debug("evicted terminal jobs from cache")
+	return evicted
+}
+
 // publishMetrics is the routine which publishes cache metrics to M3
 // return state count for test purpose
 func (f *jobFactory) publishMetrics() map[pbtask.TaskState]map[pbtask.TaskState]int {
@@ -241,6 +393,7 @@ func (f *jobFactory) publishMetrics() map[pbtask.TaskState]map[pbtask.TaskState]
 	jobs := f.GetAllJobs()
 	var (
 		totalThrottledTasks int
+		totalTasks          int
 		spreadQuotientSum   float64
 		spreadQuotientCount int64
 		slaViolatedJobIDs   []string
@@ -256,6 +409,7 @@ func (f *jobFactory) publishMetrics() map[pbtask.TaskState]map[pbtask.TaskState]
 			healthState := stateSummary.HealthState
 
 			tCount[currentState][goalState] += count
+			totalTasks += count
 
 			if currentState == pbtask.TaskState_UNKNOWN ||
 				goalState == pbtask.TaskState_UNKNOWN {
@@ -332,6 +486,11 @@ func (f *jobFactory) publishMetrics() map[pbtask.TaskState]map[pbtask.TaskState]
 	f.mtx.scope.Gauge("jobs_count").Update(float64(len(jobs)))
 	f.mtx.scope.Gauge("sla_violated_jobs").Update(float64(len(slaViolatedJobIDs)))
 	f.mtx.scope.Gauge("throttled_tasks").Update(float64(totalThrottledTasks))
+	f.mtx.CachedTasks.Update(float64(totalTasks))
+	hits, misses := f.hits.Swap(0), f.misses.Swap(0)
+	if hits+misses > 0 {
+		f.mtx.CacheHitRate.Update(float64(hits) / float64(hits+misses))
+	}
 	if spreadQuotientCount > 0 {
 		f.taskMetrics.MeanSpreadQuotient.
 			Update(spreadQuotientSum / float64(spreadQuotientCount))
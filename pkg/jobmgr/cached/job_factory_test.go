@@ -34,7 +34,7 @@ import (
 
 // TestInitJobFactory tests initialization of the job factory
 func TestInitJobFactory(t *testing.T) {
-	f := InitJobFactory(nil, nil, nil, nil, nil, tally.NoopScope, nil)
+	f := InitJobFactory(nil, nil, nil, nil, nil, tally.NoopScope, nil, Config{})
 	assert.NotNil(t, f)
 }
 
@@ -90,6 +90,135 @@ func TestStartStop(t *testing.T) {
 	assert.Nil(t, f.GetJob(jobID))
 }
 
+// TestEvictJobs tests evicting the least recently used terminal
+// jobs to keep the cache within its budget.
+func TestEvictJobs(t *testing.T) {
+	f := &jobFactory{
+		jobs:    map[string]*job{},
+		mtx:     NewMetrics(tally.NoopScope),
+		running: true,
+		cfg:     Config{MaxJobs: 2},
+	}
+
+	addJob := func(state pbjob.JobState, goalState pbjob.JobState) *peloton.JobID {
+		jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+		j := f.AddJob(jobID).(*job)
+		j.runtime = &pbjob.RuntimeInfo{
+			State:     state,
+			GoalState: goalState,
+		}
+		return jobID
+	}
+
+	runningJobID := addJob(pbjob.JobState_RUNNING, pbjob.JobState_SUCCEEDED)
+	oldTerminalJobID := addJob(pbjob.JobState_SUCCEEDED, pbjob.JobState_SUCCEEDED)
+	newTerminalJobID := addJob(pbjob.JobState_KILLED, pbjob.JobState_KILLED)
+	f.jobs[oldTerminalJobID.GetValue()].lastAccessTime.Store(1)
+	f.jobs[newTerminalJobID.GetValue()].lastAccessTime.Store(2)
+	f.jobs[runningJobID.GetValue()].lastAccessTime.Store(0)
+
+	// only the least recently used terminal job is evicted
+	assert.Equal(t, 1, f.evictJobs())
+	assert.Equal(t, 2, len(f.GetAllJobs()))
+	assert.NotNil(t, f.GetJob(runningJobID))
+	assert.NotNil(t, f.GetJob(newTerminalJobID))
+	assert.Nil(t, f.GetJob(oldTerminalJobID))
+
+	// the cache is within budget
+	assert.Equal(t, 0, f.evictJobs())
+
+	// running jobs are never evicted, even if the cache is over budget
+	f.cfg.MaxJobs = 1
+	f.jobs[newTerminalJobID.GetValue()].lastAccessTime.Store(2)
+	assert.Equal(t, 1, f.evictJobs())
+	addJob(pbjob.JobState_PENDING, pbjob.JobState_SUCCEEDED)
+	assert.Equal(t, 0, f.evictJobs())
+	assert.Equal(t, 2, len(f.GetAllJobs()))
+	assert.NotNil(t, f.GetJob(runningJobID))
+
+	// the job is loaded back on demand
+	assert.NotNil(t, f.AddJob(oldTerminalJobID))
+}
+
+// TestEvictJobsUntrack tests that the eviction hook is run for a job
+// before it is evicted from the cache.
+func TestEvictJobsUntrack(t *testing.T) {
+	f := &jobFactory{
+		jobs:    map[string]*job{},
+		mtx:     NewMetrics(tally.NoopScope),
+		running: true,
+		cfg:     Config{MaxJobs: 1},
+	}
+
+	var untracked []string
+	f.SetEvictionHook(func(j Job) {
+		// the job is still in cache when it is untracked
+		assert.NotNil(t, f.jobs[j.ID().GetValue()])
+		untracked = append(untracked, j.ID().GetValue())
+	})
+
+	for i := 0; i < 2; i++ {
+		jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+		j := f.AddJob(jobID).(*job)
+		j.runtime = &pbjob.RuntimeInfo{
+			State:     pbjob.JobState_SUCCEEDED,
+			GoalState: pbjob.JobState_SUCCEEDED,
+		}
+		j.lastAccessTime.Store(int64(i))
+	}
+
+	assert.Equal(t, 1, f.evictJobs())
+	assert.Len(t, untracked, 1)
+	assert.Nil(t, f.GetJob(&peloton.JobID{Value: untracked[0]}))
+}
+
+// TestEvictJobsByTaskCount tests evicting terminal jobs to keep the
+// number of tasks in cache within the budget.
+func TestEvictJobsByTaskCount(t *testing.T) {
+	f := &jobFactory{
+		jobs:    map[string]*job{},
+		mtx:     NewMetrics(tally.NoopScope),
+		running: true,
+		cfg:     Config{MaxTasks: 2},
+	}
+
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	j := f.AddJob(jobID)
+	j.(*job).runtime = &pbjob.RuntimeInfo{
+		State:     pbjob.JobState_FAILED,
+		GoalState: pbjob.JobState_SUCCEEDED,
+	}
+	j.ReplaceTasks(map[uint32]*pbtask.TaskInfo{
+		0: {},
+		1: {},
+		2: {},
+	}, false)
+
+	assert.Equal(t, 1, f.evictJobs())
+	assert.Nil(t, f.GetJob(jobID))
+}
+
+// TestCacheHitRate tests publishing the hit rate of the job cache.
+func TestCacheHitRate(t *testing.T) {
+	testScope := tally.NewTestScope("", nil)
+	f := &jobFactory{
+		jobs:        map[string]*job{},
+		mtx:         NewMetrics(testScope),
+		running:     true,
+		taskMetrics: NewTaskMetrics(testScope),
+	}
+
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	// one miss when the job is added, followed by three hits
+	f.AddJob(jobID)
+	f.GetJob(jobID)
+	f.GetJob(jobID)
+	f.GetJob(jobID)
+
+	f.publishMetrics()
+	assert.Equal(t, 0.75, testScope.Snapshot().Gauges()["hit_rate+"].Value())
+}
+
 // TestPublishMetrics tests publishing metrics from the job factory.
 func TestPublishMetrics(t *testing.T) {
 	testScope := tally.NewTestScope("", nil)
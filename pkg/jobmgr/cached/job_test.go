@@ -32,6 +32,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
@@ -335,6 +336,60 @@ func (suite *jobTestSuite) TestJobAddTaskJobConfigGetError() {
 	suite.Nil(t)
 }
 
+// TestJobAddTaskMissingCached tests that a task not found in DB is
+// not looked up again until the missing task TTL expires
+func (suite *jobTestSuite) TestJobAddTaskMissingCached() {
+	instID := uint32(1)
+	suite.job.config.instanceCount = 10
+	suite.job.jobFactory.cfg.MissingTaskTTL = time.Minute
+
+	suite.taskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.jobID, instID).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found")).
+		Times(2)
+
+	t, err := suite.job.AddTask(context.Background(), instID)
+	suite.True(yarpcerrors.IsNotFound(err))
+	suite.Nil(t)
+
+	// served from the negative cache
+	t, err = suite.job.AddTask(context.Background(), instID)
+	suite.True(yarpcerrors.IsNotFound(err))
+	suite.Nil(t)
+
+	// looked up again after the entry expires
+	suite.job.missingTasks[instID].expiry = time.Now().Add(-time.Second)
+	t, err = suite.job.AddTask(context.Background(), instID)
+	suite.True(yarpcerrors.IsNotFound(err))
+	suite.Nil(t)
+	suite.Contains(suite.job.missingTasks, instID)
+
+	// the entry is dropped once the task is created
+	suite.job.addTaskToJobMap(instID)
+	suite.NotContains(suite.job.missingTasks, instID)
+	t, err = suite.job.AddTask(context.Background(), instID)
+	suite.NoError(err)
+	suite.NotNil(t)
+}
+
+// TestJobAddTaskMissingNotCached tests that missing tasks are looked up
+// in DB every time when the missing task TTL is not set
+func (suite *jobTestSuite) TestJobAddTaskMissingNotCached() {
+	instID := uint32(1)
+	suite.job.config.instanceCount = 10
+
+	suite.taskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.jobID, instID).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found")).
+		Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := suite.job.AddTask(context.Background(), instID)
+		suite.True(yarpcerrors.IsNotFound(err))
+	}
+	suite.Empty(suite.job.missingTasks)
+}
+
 // TestJobSetAndFetchConfigAndRuntime tests setting and fetching
 // job configuration and runtime.
 func (suite *jobTestSuite) TestJobSetAndFetchConfigAndRuntime() {
@@ -387,6 +442,52 @@ func (suite *jobTestSuite) TestJobSetAndFetchConfigAndRuntime() {
 	suite.checkListenersNotCalled()
 }
 
+// TestIsJobConfigEqual tests comparing job configs by their checksum
+func (suite *jobTestSuite) TestIsJobConfigEqual() {
+	command := "ls"
+	newConfig := func() *pbjob.JobConfig {
+		return &pbjob.JobConfig{
+			InstanceCount: 2,
+			ChangeLog:     &peloton.ChangeLog{Version: 1},
+			Labels: []*peloton.Label{
+				{Key: "k1", Value: "v1"},
+				{Key: "k2", Value: "v2"},
+			},
+			DefaultConfig: &pbtask.TaskConfig{
+				Command: &mesos.CommandInfo{Value: &command},
+			},
+		}
+	}
+
+	// the change log and the ordering of the labels are ignored
+	targetConfig := newConfig()
+	targetConfig.ChangeLog = &peloton.ChangeLog{Version: 2}
+	targetConfig.Labels[0], targetConfig.Labels[1] =
+		targetConfig.Labels[1], targetConfig.Labels[0]
+	suite.True(suite.job.isJobConfigEqual(newConfig(), targetConfig))
+
+	targetConfig = newConfig()
+	targetConfig.InstanceCount = 3
+	suite.False(suite.job.isJobConfigEqual(newConfig(), targetConfig))
+
+	// the checksum of the cached config version is read from the cache
+	suite.job.config = &cachedConfig{
+		changeLog:  &peloton.ChangeLog{Version: 1},
+		configHash: "cached-hash",
+	}
+	hash, err := suite.job.getConfigHash(newConfig())
+	suite.NoError(err)
+	suite.Equal("cached-hash", hash)
+
+	targetConfig = newConfig()
+	targetConfig.ChangeLog = &peloton.ChangeLog{Version: 2}
+	hash, err = suite.job.getConfigHash(targetConfig)
+	suite.NoError(err)
+	expectedHash, err := taskconfig.HashJobConfig(targetConfig)
+	suite.NoError(err)
+	suite.Equal(expectedHash, hash)
+}
+
 // TestJobDBError tests DB errors during job operations.
 func (suite *jobTestSuite) TestJobDBError() {
 	jobRuntime := &pbjob.RuntimeInfo{
@@ -1938,18 +2039,31 @@ func (suite *jobTestSuite) TestPatchTasksSetGetTasksMultiple() {
 		oldRuntime := initializeCurrentRuntime(pbtask.TaskState_LAUNCHED)
 		suite.taskStore.EXPECT().
 			GetTaskRuntime(gomock.Any(), suite.jobID, i).Return(oldRuntime, nil)
-		suite.taskStore.EXPECT().
-			UpdateTaskRuntime(
-				gomock.Any(),
-				suite.jobID,
-				i,
-				gomock.Any(),
-				gomock.Any()).Return(nil)
 		suite.taskConfigV2Ops.EXPECT().
 			GetTaskConfig(gomock.Any(), suite.jobID, i, gomock.Any()).
 			Return(nil, nil, nil)
 	}
 
+	// all the runtimes are written in one batch
+	suite.taskStore.EXPECT().
+		UpdateTaskRuntimes(
+			gomock.Any(),
+			suite.jobID,
+			gomock.Any(),
+			gomock.Any()).
+		Do(func(
+			ctx context.Context,
+			jobID *peloton.JobID,
+			runtimes map[uint32]*pbtask.RuntimeInfo,
+			jobType pbjob.JobType) {
+			suite.Len(runtimes, int(instanceCount))
+			for _, runtime := range runtimes {
+				suite.Equal(pbtask.TaskState_RUNNING, runtime.GetState())
+				suite.Equal(uint64(2), runtime.GetRevision().GetVersion())
+			}
+		}).
+		Return(nil)
+
 	_, _, err := suite.job.PatchTasks(context.Background(), diffs, false)
 	suite.NoError(err)
 
@@ -1974,18 +2088,83 @@ func (suite *jobTestSuite) TestPatchTasksDBError() {
 		tt.runtime = &pbtask.RuntimeInfo{
 			State: pbtask.TaskState_LAUNCHED,
 		}
-		// Simulate fake DB error
+	}
+	// Simulate fake DB error
+	suite.taskStore.EXPECT().
+		UpdateTaskRuntimes(
+			gomock.Any(),
+			suite.jobID,
+			gomock.Any(),
+			gomock.Any()).
+		Return(dbError)
+	_, _, err := suite.job.PatchTasks(context.Background(), diffs, false)
+	suite.Error(err)
+
+	// the runtimes in cache are invalidated on DB write failure
+	for i := uint32(0); i < instanceCount; i++ {
+		suite.Nil(suite.job.GetTask(i).(*task).runtime)
+	}
+}
+
+// TestPatchTasksLaterBatchDBError tests getting DB error while writing a
+// later batch of task runtimes, which returns only the instances of the
+// batches written before the failure.
+func (suite *jobTestSuite) TestPatchTasksLaterBatchDBError() {
+	instanceCount := uint32(_patchTasksBatchSize + 1)
+	diffs := initializeDiffs(instanceCount, pbtask.TaskState_RUNNING)
+
+	for i := uint32(0); i < instanceCount; i++ {
+		tt := suite.job.addTaskToJobMap(i)
+		tt.runtime = initializeCurrentRuntime(pbtask.TaskState_LAUNCHED)
+		tt.config = &taskConfigCache{}
+	}
+
+	gomock.InOrder(
 		suite.taskStore.EXPECT().
-			UpdateTaskRuntime(
+			UpdateTaskRuntimes(
 				gomock.Any(),
 				suite.jobID,
-				i,
 				gomock.Any(),
 				gomock.Any()).
-			Return(dbError)
-	}
-	_, _, err := suite.job.PatchTasks(context.Background(), diffs, false)
+			Do(func(
+				ctx context.Context,
+				jobID *peloton.JobID,
+				runtimes map[uint32]*pbtask.RuntimeInfo,
+				jobType pbjob.JobType) {
+				suite.Len(runtimes, _patchTasksBatchSize)
+			}).
+			Return(nil),
+		suite.taskStore.EXPECT().
+			UpdateTaskRuntimes(
+				gomock.Any(),
+				suite.jobID,
+				gomock.Any(),
+				gomock.Any()).
+			Do(func(
+				ctx context.Context,
+				jobID *peloton.JobID,
+				runtimes map[uint32]*pbtask.RuntimeInfo,
+				jobType pbjob.JobType) {
+				suite.Len(runtimes, 1)
+				suite.NotNil(runtimes[instanceCount-1])
+			}).
+			Return(dbError),
+	)
+
+	instancesSucceeded, _, err := suite.job.PatchTasks(
+		context.Background(),
+		diffs,
+		false,
+	)
 	suite.Error(err)
+	suite.Len(instancesSucceeded, _patchTasksBatchSize)
+	for i := uint32(0); i < uint32(_patchTasksBatchSize); i++ {
+		suite.Contains(instancesSucceeded, i)
+		suite.Equal(pbtask.TaskState_RUNNING,
+			suite.job.GetTask(i).(*task).runtime.GetState())
+	}
+	suite.NotContains(instancesSucceeded, instanceCount-1)
+	suite.Nil(suite.job.GetTask(instanceCount - 1).(*task).runtime)
 }
 
 // TestPatchTasks_SingleTask tests updating task runtime of a single task in DB.
@@ -21,6 +21,21 @@ import (
 // Metrics is the struct containing all the counters that track internal state of the cache.
 type Metrics struct {
 	scope tally.Scope
+
+	// number of tasks in cache, summed across all the jobs
+	CachedTasks tally.Gauge
+
+	// ratio of lookups which found the job in cache
+	CacheHitRate tally.Gauge
+
+	// jobs evicted to keep the cache within its budget
+	JobsEvicted tally.Counter
+	// set to 1 if the cache is over budget after eviction,
+	// since only terminal jobs can be evicted
+	CacheOverBudget tally.Gauge
+
+	// task lookups answered from the cache of tasks missing in the DB
+	MissingTaskCacheHits tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -28,6 +43,15 @@ type Metrics struct {
 func NewMetrics(scope tally.Scope) *Metrics {
 	return &Metrics{
 		scope: scope,
+
+		CachedTasks: scope.Gauge("cached_tasks"),
+
+		CacheHitRate: scope.Gauge("hit_rate"),
+
+		JobsEvicted:     scope.Counter("jobs_evicted"),
+		CacheOverBudget: scope.Gauge("over_budget"),
+
+		MissingTaskCacheHits: scope.Counter("missing_task_cache_hits"),
 	}
 }
 
@@ -92,6 +92,11 @@ type TaskStateVector struct {
 	State         pbtask.TaskState
 	ConfigVersion uint64
 	MesosTaskID   *mesos.TaskID
+	// Reason, Message and ExitCode describe why the task reached its
+	// current state, they are only set for the current state
+	Reason   string
+	Message  string
+	ExitCode uint32
 }
 
 type TaskStateSummary struct {
@@ -347,14 +352,8 @@ func (t *task) createTask(ctx context.Context, runtime *pbtask.RuntimeInfo, owne
 // patchTask patches diff to the existing runtime cache
 // in task and persists to DB.
 func (t *task) patchTask(ctx context.Context, diff jobmgrcommon.RuntimeDiff) error {
-	if diff == nil {
-		return yarpcerrors.InvalidArgumentErrorf(
-			"unexpected nil diff")
-	}
-
-	if _, ok := diff[jobmgrcommon.RevisionField]; ok {
-		return yarpcerrors.InvalidArgumentErrorf(
-			"unexpected Revision field in diff")
+	if err := validateRuntimeDiff(diff); err != nil {
+		return err
 	}
 
 	var runtimeCopy *pbtask.RuntimeInfo
@@ -373,12 +372,54 @@ func (t *task) patchTask(ctx context.Context, diff jobmgrcommon.RuntimeDiff) err
 	t.Lock()
 	defer t.Unlock()
 
+	newRuntimePtr, err := t.preparePatch(ctx, diff)
+	if err != nil || newRuntimePtr == nil {
+		return err
+	}
+
+	err = t.jobFactory.taskStore.UpdateTaskRuntime(
+		ctx,
+		t.jobID,
+		t.id,
+		newRuntimePtr,
+		t.jobType)
+	if err != nil {
+		// clean the runtime in cache on DB write failure
+		t.cleanTaskCache()
+		return err
+	}
+
+	runtimeCopy, labelsCopy, err = t.commitPatch(ctx, newRuntimePtr)
+	return err
+}
+
+// validateRuntimeDiff validates that a runtime diff can be patched.
+func validateRuntimeDiff(diff jobmgrcommon.RuntimeDiff) error {
+	if diff == nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"unexpected nil diff")
+	}
+
+	if _, ok := diff[jobmgrcommon.RevisionField]; ok {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"unexpected Revision field in diff")
+	}
+	return nil
+}
+
+// preparePatch patches diff to a copy of the runtime in cache and bumps
+// its revision. It returns nil if the patched runtime is no longer valid.
+// The caller must hold the task lock.
+func (t *task) preparePatch(
+	ctx context.Context,
+	diff jobmgrcommon.RuntimeDiff,
+) (*pbtask.RuntimeInfo, error) {
 	// reload cache if there is none
 	if t.runtime == nil {
 		// fetch runtime from db if not present in cache
 		err := t.updateRuntimeFromDB(ctx)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -386,42 +427,39 @@ func (t *task) patchTask(ctx context.Context, diff jobmgrcommon.RuntimeDiff) err
 	newRuntime := *t.runtime
 	newRuntimePtr := &newRuntime
 	if err := patch(newRuntimePtr, diff); err != nil {
-		return err
+		return nil, err
 	}
 
 	// validate if the patched runtime is valid,
 	// if not ignore the diff, since the runtime has already been updated by
 	// other threads and the change in diff is no longer valid
 	if !t.validateState(newRuntimePtr) {
-		return nil
+		return nil, nil
 	}
 
 	t.updateRevision(newRuntimePtr)
+	return newRuntimePtr, nil
+}
 
-	err := t.jobFactory.taskStore.UpdateTaskRuntime(
-		ctx,
-		t.jobID,
-		t.id,
-		newRuntimePtr,
-		t.jobType)
-	if err != nil {
-		// clean the runtime in cache on DB write failure
-		t.cleanTaskCache()
-		return err
-	}
-
-	err = t.updateConfig(ctx, newRuntimePtr.GetConfigVersion())
+// commitPatch stores a patched runtime, which has been persisted to DB,
+// in cache. It returns copies of the runtime and labels in cache to
+// notify the listeners with. The caller must hold the task lock.
+func (t *task) commitPatch(
+	ctx context.Context,
+	newRuntime *pbtask.RuntimeInfo,
+) (*pbtask.RuntimeInfo, []*peloton.Label, error) {
+	err := t.updateConfig(ctx, newRuntime.GetConfigVersion())
 	if err != nil {
 		t.cleanTaskCache()
-		return err
+		return nil, nil, err
 	}
-	t.logStateTransitionMetrics(newRuntimePtr)
+	t.logStateTransitionMetrics(newRuntime)
 
 	// Store the new runtime in cache
-	t.runtime = newRuntimePtr
-	runtimeCopy = proto.Clone(t.runtime).(*pbtask.RuntimeInfo)
-	labelsCopy = t.copyLabelsInCache()
-	return nil
+	t.runtime = newRuntime
+	return proto.Clone(t.runtime).(*pbtask.RuntimeInfo),
+		t.copyLabelsInCache(),
+		nil
 }
 
 // compareAndSetTask replaces the existing task runtime in DB and cache.
@@ -702,6 +740,9 @@ func (t *task) CurrentState() TaskStateVector {
 		State:         t.runtime.GetState(),
 		ConfigVersion: t.runtime.GetConfigVersion(),
 		MesosTaskID:   t.runtime.GetMesosTaskId(),
+		Reason:        t.runtime.GetReason(),
+		Message:       t.runtime.GetMessage(),
+		ExitCode:      t.runtime.GetTerminationStatus().GetExitCode(),
 	}
 }
 
@@ -102,6 +102,14 @@ type Update interface {
 		targetConfig *pbjob.JobConfig,
 	) error
 
+	// PromoteCanary marks the canaries of the update as promoted,
+	// so that the update goes on to the remaining instances
+	PromoteCanary(ctx context.Context) error
+
+	// TripHealthGate records the reason the health gate of the
+	// update tripped
+	TripHealthGate(ctx context.Context, reason string) error
+
 	// GetState returns the state of the update
 	GetState() *UpdateStateVector
 
@@ -151,6 +159,14 @@ type Update interface {
 
 	// GetLastUpdateTime return the last update time of update object
 	GetLastUpdateTime() time.Time
+
+	// IsCanaryPromoted returns true if the canaries of the update
+	// have been promoted
+	IsCanaryPromoted() bool
+
+	// GetGateTripReason returns the reason the health gate of the
+	// update tripped, empty if it has not tripped
+	GetGateTripReason() string
 }
 
 // UpdateStateVector is used to the represent the state and goal state
@@ -238,6 +254,9 @@ type update struct {
 	jobVersion     uint64 // job configuration version
 	jobPrevVersion uint64 // previous job configuration version
 
+	canaryPromoted bool   // whether the canaries have been promoted
+	gateTripReason string // reason the health gate tripped
+
 	lastUpdateTime time.Time // last update time of update object
 }
 
@@ -749,6 +768,68 @@ func (u *update) Rollback(
 	return nil
 }
 
+// PromoteCanary marks the canaries of the update as promoted.
+func (u *update) PromoteCanary(ctx context.Context) error {
+	u.Lock()
+	defer u.Unlock()
+
+	// TODO: do recovery automatically when read state
+	if err := u.recover(ctx); err != nil {
+		return err
+	}
+
+	if u.canaryPromoted {
+		return nil
+	}
+
+	now := time.Now()
+	if err := u.jobFactory.updateStore.WriteUpdateProgress(
+		ctx,
+		&models.UpdateModel{
+			UpdateID:       u.id,
+			CanaryPromoted: true,
+			UpdateTime:     now.Format(time.RFC3339Nano),
+		}); err != nil {
+		u.clearCache()
+		return err
+	}
+
+	u.canaryPromoted = true
+	u.lastUpdateTime = now
+	return nil
+}
+
+// TripHealthGate records the reason the health gate of the update tripped.
+func (u *update) TripHealthGate(ctx context.Context, reason string) error {
+	u.Lock()
+	defer u.Unlock()
+
+	// TODO: do recovery automatically when read state
+	if err := u.recover(ctx); err != nil {
+		return err
+	}
+
+	if len(u.gateTripReason) != 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if err := u.jobFactory.updateStore.WriteUpdateProgress(
+		ctx,
+		&models.UpdateModel{
+			UpdateID:       u.id,
+			GateTripReason: reason,
+			UpdateTime:     now.Format(time.RFC3339Nano),
+		}); err != nil {
+		u.clearCache()
+		return err
+	}
+
+	u.gateTripReason = reason
+	u.lastUpdateTime = now
+	return nil
+}
+
 func (u *update) GetState() *UpdateStateVector {
 	u.RLock()
 	defer u.RUnlock()
@@ -872,6 +953,20 @@ func (u *update) GetJobPrevVersion() uint64 {
 	return u.jobPrevVersion
 }
 
+func (u *update) IsCanaryPromoted() bool {
+	u.RLock()
+	defer u.RUnlock()
+
+	return u.canaryPromoted
+}
+
+func (u *update) GetGateTripReason() string {
+	u.RLock()
+	defer u.RUnlock()
+
+	return u.gateTripReason
+}
+
 // IsTaskInUpdateProgress returns true if a given task is
 // in progress for the given update, else returns false
 func (u *update) IsTaskInUpdateProgress(instanceID uint32) bool {
@@ -903,6 +998,14 @@ func (u *update) populateCache(updateModel *models.UpdateModel) {
 	if updateModel.GetJobID() != nil {
 		u.jobID = updateModel.GetJobID()
 	}
+	// models written on rollback do not carry these fields, so that
+	// they are not reset
+	if updateModel.GetCanaryPromoted() {
+		u.canaryPromoted = true
+	}
+	if len(updateModel.GetGateTripReason()) != 0 {
+		u.gateTripReason = updateModel.GetGateTripReason()
+	}
 
 	u.state = updateModel.GetState()
 	u.prevState = updateModel.GetPrevState()
@@ -921,6 +1024,8 @@ func (u *update) populateCache(updateModel *models.UpdateModel) {
 func (u *update) clearCache() {
 	u.state = pbupdate.State_INVALID
 	u.prevState = pbupdate.State_INVALID
+	u.canaryPromoted = false
+	u.gateTripReason = ""
 	u.instancesTotal = nil
 	u.instancesDone = nil
 	u.instancesFailed = nil
@@ -1021,9 +1126,10 @@ func hasInstanceConfigChanged(
 		return false, err
 	}
 
-	newTaskConfig := taskconfig.Merge(
+	newTaskConfig := taskconfig.MergeWithStrategy(
 		newJobConfig.GetDefaultConfig(),
-		newJobConfig.GetInstanceConfig()[instID])
+		newJobConfig.GetInstanceConfig()[instID],
+		newJobConfig.GetInstanceConfigMergeStrategy())
 	return taskconfig.HasTaskConfigChanged(prevTaskConfig, newTaskConfig), nil
 }
 
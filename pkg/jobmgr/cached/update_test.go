@@ -755,6 +755,72 @@ func (suite *UpdateTestSuite) TestCancelTerminatedUpdate() {
 	suite.NoError(err)
 }
 
+// TestPromoteCanary tests promoting the canaries of an update
+func (suite *UpdateTestSuite) TestPromoteCanary() {
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, updateModel *models.UpdateModel) {
+			suite.Equal(suite.updateID, updateModel.UpdateID)
+			suite.True(updateModel.GetCanaryPromoted())
+			suite.Equal(pbupdate.State_INVALID, updateModel.GetState())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.update.PromoteCanary(context.Background()))
+	suite.True(suite.update.IsCanaryPromoted())
+
+	// promoting again is a noop
+	suite.NoError(suite.update.PromoteCanary(context.Background()))
+}
+
+// TestPromoteCanaryDBError tests the failure to promote the canaries
+// of an update due to a DB error
+func (suite *UpdateTestSuite) TestPromoteCanaryDBError() {
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.UnavailableErrorf("test error"))
+
+	suite.Error(suite.update.PromoteCanary(context.Background()))
+	suite.False(suite.update.IsCanaryPromoted())
+	suite.Equal(pbupdate.State_INVALID, suite.update.GetState().State)
+}
+
+// TestTripHealthGate tests recording the trip of the health gate
+// of an update
+func (suite *UpdateTestSuite) TestTripHealthGate() {
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+	reason := "2 instances unavailable, limit is 1"
+
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, updateModel *models.UpdateModel) {
+			suite.Equal(suite.updateID, updateModel.UpdateID)
+			suite.Equal(reason, updateModel.GetGateTripReason())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.update.TripHealthGate(context.Background(), reason))
+	suite.Equal(reason, suite.update.GetGateTripReason())
+
+	// the first reason is kept
+	suite.NoError(suite.update.TripHealthGate(context.Background(), "other"))
+	suite.Equal(reason, suite.update.GetGateTripReason())
+}
+
+// TestTripHealthGateRecoverFail tests the failure to record the trip of
+// the health gate of an update due to recover failure
+func (suite *UpdateTestSuite) TestTripHealthGateRecoverFail() {
+	suite.updateStore.EXPECT().
+		GetUpdate(gomock.Any(), suite.updateID).
+		Return(nil, yarpcerrors.InternalErrorf("test error"))
+
+	suite.Error(suite.update.TripHealthGate(context.Background(), "reason"))
+}
+
 // TestUpdateGetState tests getting state of a job update
 func (suite *UpdateTestSuite) TestUpdateGetState() {
 	suite.update.instancesDone = []uint32{1, 2, 3, 4, 5}
@@ -42,6 +42,9 @@ func TestTaskRuntimeInfoFieldNames(t *testing.T) {
 		ConfigVersionField,
 		DesiredConfigVersionField,
 		HealthyField,
+		ConsecutiveFailureCountField,
+		RestartBackoffUntilField,
+		LaunchCorrelationIDField,
 	}
 
 	taskRuntimeType := reflect.TypeOf(pbtask.RuntimeInfo{})
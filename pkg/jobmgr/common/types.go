@@ -73,6 +73,18 @@ type JobConfig interface {
 	GetName() string
 	// GetPlacementStrategy returns the placement strategy
 	GetPlacementStrategy() pbjob.PlacementStrategy
+	// GetPlacementRelaxationPolicy returns the placement relaxation policy
+	GetPlacementRelaxationPolicy() *pbjob.PlacementRelaxationPolicy
+	// GetPreemptionPolicy returns the preemption policy of the job
+	GetPreemptionPolicy() *pbjob.PreemptionPolicy
+	// GetOwner returns the owner of the job stored in the cache
+	GetOwner() string
+	// GetControllerTaskName returns the name of the controller tasks
+	// of the job stored in the cache
+	GetControllerTaskName() string
+	// GetInstanceConfigMergeStrategy returns the strategy merging the
+	// instance configs of the job stored in the cache into its default config
+	GetInstanceConfigMergeStrategy() *pbjob.InstanceConfigMergeStrategy
 }
 
 // RuntimeDiff to be applied to the runtime struct.
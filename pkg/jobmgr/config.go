@@ -19,9 +19,13 @@ import (
 
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	"github.com/uber/peloton/pkg/jobmgr/podevents"
+	"github.com/uber/peloton/pkg/jobmgr/replication"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
+	"github.com/uber/peloton/pkg/jobmgr/task/event"
 	"github.com/uber/peloton/pkg/jobmgr/task/evictor"
 	"github.com/uber/peloton/pkg/jobmgr/task/placement"
 	"github.com/uber/peloton/pkg/jobmgr/watchsvc"
@@ -53,15 +57,27 @@ type Config struct {
 
 	Deadline deadline.Config `yaml:"deadline"`
 
+	// Task status update event processing configuration
+	TaskEvents event.Config `yaml:"task_events"`
+
 	// Job service specific configuration
 	JobSvcCfg jobsvc.Config `yaml:"job_service"`
 
+	// Memory budget of the job cache
+	JobCache cached.Config `yaml:"job_cache"`
+
 	// Watch API specific configuration
 	Watch watchsvc.Config `yaml:"watch"`
 
 	// WorkflowProgressCheck specific configuration
 	WorkflowProgressCheck progress.Config `yaml:"workflow_progress_check"`
 
+	// Replication of job summaries to a read-only mirror cluster
+	Replication replication.Config `yaml:"replication"`
+
+	// Retention policy of pod events enforced by a background compactor
+	PodEventsRetention podevents.Config `yaml:"pod_events_retention"`
+
 	// Period in sec for updating active cache
 	ActiveTaskUpdatePeriod time.Duration `yaml:"active_task_update_period"`
 
@@ -0,0 +1,213 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_agent "github.com/uber/peloton/.gen/mesos/v1/agent"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// _agentOperatorAPIPath is the path of the operator API of Mesos agents
+	_agentOperatorAPIPath = "/api/v1"
+
+	_contentTypeProtobuf = "application/x-protobuf"
+	_contentTypeRecordIO = "application/recordio"
+)
+
+// commandExecutor runs a command in the container of a running task.
+type commandExecutor interface {
+	// Exec runs the command in the container of the given mesos task on
+	// the given host, and returns the wait status of the command.
+	Exec(
+		ctx context.Context,
+		hostname string,
+		mesosTaskID string,
+		command string,
+	) (int32, error)
+}
+
+// agentCommandExecutor runs commands in the containers of tasks through
+// the nested container sessions of the Mesos agent operator API.
+type agentCommandExecutor struct {
+	client    *http.Client
+	agentPort int
+}
+
+// newAgentCommandExecutor creates an agentCommandExecutor which calls
+// the operator API of the agents on the given port.
+func newAgentCommandExecutor(agentPort int) *agentCommandExecutor {
+	return &agentCommandExecutor{
+		client:    &http.Client{},
+		agentPort: agentPort,
+	}
+}
+
+// Exec launches the command in a nested container of the container of
+// the task, waits for the session to end and returns the wait status of
+// the nested container.
+func (e *agentCommandExecutor) Exec(
+	ctx context.Context,
+	hostname string,
+	mesosTaskID string,
+	command string,
+) (int32, error) {
+	parentID, err := e.getContainerID(ctx, hostname, mesosTaskID)
+	if err != nil {
+		return 0, err
+	}
+
+	containerIDValue := uuid.New()
+	containerID := &mesos.ContainerID{
+		Value:  &containerIDValue,
+		Parent: parentID,
+	}
+
+	launchType := mesos_agent.Call_LAUNCH_NESTED_CONTAINER_SESSION
+	shell := true
+	body, err := e.call(ctx, hostname, &mesos_agent.Call{
+		Type: &launchType,
+		LaunchNestedContainerSession: &mesos_agent.Call_LaunchNestedContainerSession{
+			ContainerId: containerID,
+			Command: &mesos.CommandInfo{
+				Shell: &shell,
+				Value: &command,
+			},
+		},
+	}, _contentTypeRecordIO)
+	if err != nil {
+		return 0, err
+	}
+	// the session streams the output of the command until it exits,
+	// which is not needed for the verification
+	_, err = io.Copy(ioutil.Discard, body)
+	body.Close()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read nested container session")
+	}
+
+	waitType := mesos_agent.Call_WAIT_CONTAINER
+	resp, err := e.callAndDecode(ctx, hostname, &mesos_agent.Call{
+		Type: &waitType,
+		WaitContainer: &mesos_agent.Call_WaitContainer{
+			ContainerId: containerID,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.GetWaitContainer().GetExitStatus(), nil
+}
+
+// getContainerID returns the ID of the container running the given
+// mesos task on the agent. The executor of a task started by the command
+// executor has the same ID as the task, while custom executors have a
+// prefix in front of it.
+func (e *agentCommandExecutor) getContainerID(
+	ctx context.Context,
+	hostname string,
+	mesosTaskID string,
+) (*mesos.ContainerID, error) {
+	callType := mesos_agent.Call_GET_CONTAINERS
+	resp, err := e.callAndDecode(ctx, hostname, &mesos_agent.Call{
+		Type: &callType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, container := range resp.GetGetContainers().GetContainers() {
+		if strings.HasSuffix(
+			container.GetExecutorId().GetValue(), mesosTaskID) {
+			return container.GetContainerId(), nil
+		}
+	}
+	return nil, errors.Errorf(
+		"container of task %s not found on %s", mesosTaskID, hostname)
+}
+
+// callAndDecode makes a call to the operator API of the agent and
+// decodes its response.
+func (e *agentCommandExecutor) callAndDecode(
+	ctx context.Context,
+	hostname string,
+	call *mesos_agent.Call,
+) (*mesos_agent.Response, error) {
+	body, err := e.call(ctx, hostname, call, _contentTypeProtobuf)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s response", call.GetType())
+	}
+
+	resp := &mesos_agent.Response{}
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s response", call.GetType())
+	}
+	return resp, nil
+}
+
+// call makes a call to the operator API of the agent, and returns the
+// body of the response, which must be closed by the caller.
+func (e *agentCommandExecutor) call(
+	ctx context.Context,
+	hostname string,
+	call *mesos_agent.Call,
+	accept string,
+) (io.ReadCloser, error) {
+	data, err := proto.Marshal(call)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode %s call", call.GetType())
+	}
+
+	url := fmt.Sprintf("http://%s:%d%s", hostname, e.agentPort, _agentOperatorAPIPath)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", _contentTypeProtobuf)
+	req.Header.Set("Accept", accept)
+	if accept == _contentTypeRecordIO {
+		req.Header.Set("Message-Accept", _contentTypeProtobuf)
+	}
+
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to make %s call", call.GetType())
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf(
+			"%s call to %s failed with status %s",
+			call.GetType(), hostname, resp.Status)
+	}
+	return resp.Body, nil
+}
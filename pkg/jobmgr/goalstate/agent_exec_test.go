@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesos_agent "github.com/uber/peloton/.gen/mesos/v1/agent"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeAgent starts an agent operator API running the task with the
+// given executor ID in a container, and whose nested containers exit
+// with the given status
+func newFakeAgent(
+	t *testing.T,
+	executorID string,
+	exitStatus int32,
+) (*httptest.Server, *agentCommandExecutor) {
+	parentID := "parent-container"
+	var launched *mesos.ContainerID
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			data, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			call := &mesos_agent.Call{}
+			assert.NoError(t, proto.Unmarshal(data, call))
+
+			resp := &mesos_agent.Response{}
+			switch call.GetType() {
+			case mesos_agent.Call_GET_CONTAINERS:
+				resp.GetContainers = &mesos_agent.Response_GetContainers{
+					Containers: []*mesos_agent.Response_GetContainers_Container{{
+						ExecutorId:  &mesos.ExecutorID{Value: &executorID},
+						ContainerId: &mesos.ContainerID{Value: &parentID},
+					}},
+				}
+			case mesos_agent.Call_LAUNCH_NESTED_CONTAINER_SESSION:
+				assert.Equal(t, _contentTypeRecordIO, r.Header.Get("Accept"))
+				launched = call.GetLaunchNestedContainerSession().GetContainerId()
+				assert.Equal(t, parentID, launched.GetParent().GetValue())
+				assert.Equal(t, "./check.sh",
+					call.GetLaunchNestedContainerSession().GetCommand().GetValue())
+				return
+			case mesos_agent.Call_WAIT_CONTAINER:
+				assert.Equal(t, launched.GetValue(),
+					call.GetWaitContainer().GetContainerId().GetValue())
+				resp.WaitContainer = &mesos_agent.Response_WaitContainer{
+					ExitStatus: &exitStatus,
+				}
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			body, err := proto.Marshal(resp)
+			assert.NoError(t, err)
+			w.Write(body)
+		}))
+
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	assert.NoError(t, err)
+	return server, newAgentCommandExecutor(port)
+}
+
+// TestAgentCommandExecutor tests running a command in the container
+// of a task through the agent operator API
+func TestAgentCommandExecutor(t *testing.T) {
+	server, executor := newFakeAgent(t, "thermos-mesos-task-0", 256)
+	defer server.Close()
+
+	status, err := executor.Exec(
+		context.Background(), "127.0.0.1", "mesos-task-0", "./check.sh")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(256), status)
+}
+
+// TestAgentCommandExecutorNoContainer tests running a command for
+// a task which does not run on the agent
+func TestAgentCommandExecutorNoContainer(t *testing.T) {
+	server, executor := newFakeAgent(t, "mesos-task-1", 0)
+	defer server.Close()
+
+	_, err := executor.Exec(
+		context.Background(), "127.0.0.1", "mesos-task-0", "./check.sh")
+	assert.Error(t, err)
+}
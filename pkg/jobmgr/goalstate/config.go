@@ -17,6 +17,9 @@ package goalstate
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/common/goalstate"
+	jobmgr_task "github.com/uber/peloton/pkg/jobmgr/task"
+
 	"golang.org/x/time/rate"
 )
 
@@ -28,6 +31,16 @@ const (
 	_defaultJobRuntimeUpdateInterval = 1 * time.Second
 	_defaultInitialTaskBackoff       = 30 * time.Second
 	_defaultMaxTaskBackoff           = 60 * time.Minute
+	_defaultKillGracePeriodBuffer    = 1 * time.Minute
+	_defaultFrozenRetryDelay         = 30 * time.Second
+	_defaultCacheCheckPeriod         = 30 * time.Minute
+	_defaultMesosAgentPort           = 5051
+	_defaultStuckTaskCheckPeriod     = 5 * time.Minute
+	_defaultStuckLaunchingThreshold  = 30 * time.Minute
+	_defaultStuckStartingThreshold   = 240 * time.Minute
+	_defaultStuckKillingThreshold    = 60 * time.Minute
+	_defaultWatchdogCheckPeriod      = 5 * time.Minute
+	_defaultWatchdogStuckThreshold   = 60 * time.Minute
 
 	// Job worker threads should be small because job create and job kill
 	// actions create 1000 parallel threads to update the DB, and if too
@@ -50,7 +63,8 @@ type Config struct {
 	// will be applied for up to MaxRetryDelay.
 	FailureRetryDelay time.Duration `yaml:"failure_retry_delay"`
 
-	// LaunchTimeout is the timeout value for the LAUNCHED state.
+	// LaunchTimeout is the timeout value for the LAUNCHING and LAUNCHED
+	// states.
 	// If no update is received from Mesos within this timeout value,
 	// the task will be re-queued to the resource manager for placement
 	// with a new mesos task id.
@@ -92,8 +106,79 @@ type Config struct {
 	// Default to 1h.
 	MaxTaskBackoff time.Duration `yaml:"max_task_backoff"`
 
+	// KillGracePeriodBuffer is added to the kill grace period of a task
+	// to decide when a task which has not terminated after being killed
+	// is force killed by shutting down its executor. Tasks without a
+	// kill grace period use the default shutdown executor timeout.
+	// Default to 1m.
+	KillGracePeriodBuffer time.Duration `yaml:"kill_grace_period_buffer"`
+
+	// FrozenRetryDelay is the delay after which the automated actions
+	// halted while the cluster is frozen, such as updates, task restarts,
+	// job kills on deadline or budget, TTL deletions and cron runs, are
+	// evaluated again. Default to 30s.
+	FrozenRetryDelay time.Duration `yaml:"frozen_retry_delay"`
+
+	// CacheConsistencyCheckPeriod is the period at which the job and task
+	// runtimes in the cache are validated against the DB for each job.
+	// A negative value disables the check. Default to 30m.
+	CacheConsistencyCheckPeriod time.Duration `yaml:"cache_consistency_check_period"`
+
+	// MesosAgentPort is the port of the operator API of the Mesos agents,
+	// used to run the commands of update verification hooks in the
+	// containers of the updated instances. Default to 5051.
+	MesosAgentPort int `yaml:"mesos_agent_port"`
+
+	// Enqueue controls the timeout and retries of enqueuing tasks
+	// to resource manager.
+	Enqueue jobmgr_task.EnqueueConfig `yaml:"enqueue"`
+
+	// StuckTaskDetector controls the detection and recovery of tasks
+	// stuck in transient states.
+	StuckTaskDetector StuckTaskDetectorConfig `yaml:"stuck_task_detector"`
+
+	// JobWatchdog, TaskWatchdog and UpdateWatchdog control the detection
+	// of the jobs, tasks and updates stuck in goal state, and the
+	// remediations applied to them. The remediations are refresh_cache
+	// for jobs and tasks, reconcile for tasks, and dead_letter.
+	// A negative check period disables a watchdog. Default to a check
+	// every 5m, a stuck threshold of 1h and no remediation.
+	JobWatchdog    goalstate.WatchdogConfig `yaml:"job_watchdog"`
+	TaskWatchdog   goalstate.WatchdogConfig `yaml:"task_watchdog"`
+	UpdateWatchdog goalstate.WatchdogConfig `yaml:"update_watchdog"`
+
 	// RateLimiterConfig defines rate limiter config
 	RateLimiterConfig RateLimiterConfig `yaml:"rate_limit"`
+
+	// EnablePodResize resizes running tasks in place, instead of restarting
+	// them, when only their cpu and memory limits change. It should only be
+	// set when the host manager runs a plugin which supports it, like k8s.
+	EnablePodResize bool `yaml:"enable_pod_resize"`
+}
+
+// StuckTaskDetectorConfig is the config of the detector of tasks stuck in
+// the transient LAUNCHING, STARTING and KILLING states.
+type StuckTaskDetectorConfig struct {
+	// CheckPeriod is the period at which the tasks in the cache are
+	// checked. A negative value disables the detector. Default to 5m.
+	CheckPeriod time.Duration `yaml:"check_period"`
+
+	// LaunchingThreshold is the time after which a task in LAUNCHING
+	// state is stuck. Such tasks are evaluated again by the task goal
+	// state engine, which re-enqueues them to the resource manager with a
+	// new mesos task id on launch timeout. Default to 30m.
+	LaunchingThreshold time.Duration `yaml:"launching_threshold"`
+
+	// StartingThreshold is the time after which a task in STARTING state
+	// is stuck. Such tasks are force reconciled with the host manager.
+	// It is larger than the start timeout of batch tasks, since service
+	// tasks do not time out in STARTING state. Default to 4h.
+	StartingThreshold time.Duration `yaml:"starting_threshold"`
+
+	// KillingThreshold is the time after which a task in KILLING state
+	// is stuck. Such tasks are force reconciled with the host manager.
+	// Default to 1h.
+	KillingThreshold time.Duration `yaml:"killing_threshold"`
 }
 
 type RateLimiterConfig struct {
@@ -155,6 +240,45 @@ func (c *Config) normalize() {
 		c.MaxTaskBackoff = _defaultMaxTaskBackoff
 	}
 
+	if c.KillGracePeriodBuffer == 0 {
+		c.KillGracePeriodBuffer = _defaultKillGracePeriodBuffer
+	}
+
+	if c.FrozenRetryDelay == 0 {
+		c.FrozenRetryDelay = _defaultFrozenRetryDelay
+	}
+
+	if c.CacheConsistencyCheckPeriod == 0 {
+		c.CacheConsistencyCheckPeriod = _defaultCacheCheckPeriod
+	}
+
+	if c.StuckTaskDetector.CheckPeriod == 0 {
+		c.StuckTaskDetector.CheckPeriod = _defaultStuckTaskCheckPeriod
+	}
+	if c.StuckTaskDetector.LaunchingThreshold == 0 {
+		c.StuckTaskDetector.LaunchingThreshold = _defaultStuckLaunchingThreshold
+	}
+	if c.StuckTaskDetector.StartingThreshold == 0 {
+		c.StuckTaskDetector.StartingThreshold = _defaultStuckStartingThreshold
+	}
+	if c.StuckTaskDetector.KillingThreshold == 0 {
+		c.StuckTaskDetector.KillingThreshold = _defaultStuckKillingThreshold
+	}
+
+	for _, watchdog := range []*goalstate.WatchdogConfig{
+		&c.JobWatchdog, &c.TaskWatchdog, &c.UpdateWatchdog} {
+		if watchdog.CheckPeriod == 0 {
+			watchdog.CheckPeriod = _defaultWatchdogCheckPeriod
+		}
+		if watchdog.StuckThreshold == 0 {
+			watchdog.StuckThreshold = _defaultWatchdogStuckThreshold
+		}
+	}
+
+	if c.MesosAgentPort == 0 {
+		c.MesosAgentPort = _defaultMesosAgentPort
+	}
+
 	if c.RateLimiterConfig.TaskKill.Rate <= 0 || c.RateLimiterConfig.TaskKill.Burst <= 0 {
 		c.RateLimiterConfig.TaskKill.Rate = rate.Inf
 	}
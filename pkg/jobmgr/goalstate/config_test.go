@@ -34,4 +34,12 @@ func TestConfigNormalize(t *testing.T) {
 	assert.Equal(t, _defaultJobWorkerThreads, c.NumWorkerJobThreads)
 	assert.Equal(t, _defaultTaskWorkerThreads, c.NumWorkerTaskThreads)
 	assert.Equal(t, _defaultUpdateWorkerThreads, c.NumWorkerUpdateThreads)
+	assert.Equal(t, _defaultStuckTaskCheckPeriod, c.StuckTaskDetector.CheckPeriod)
+	assert.Equal(t, _defaultStuckLaunchingThreshold, c.StuckTaskDetector.LaunchingThreshold)
+	assert.Equal(t, _defaultStuckStartingThreshold, c.StuckTaskDetector.StartingThreshold)
+	assert.Equal(t, _defaultStuckKillingThreshold, c.StuckTaskDetector.KillingThreshold)
+	assert.Equal(t, _defaultWatchdogCheckPeriod, c.TaskWatchdog.CheckPeriod)
+	assert.Equal(t, _defaultWatchdogStuckThreshold, c.JobWatchdog.StuckThreshold)
+	assert.Equal(t, _defaultWatchdogStuckThreshold, c.UpdateWatchdog.StuckThreshold)
+	assert.Empty(t, c.TaskWatchdog.Remediations)
 }
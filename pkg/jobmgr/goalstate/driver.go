@@ -23,10 +23,12 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/goalstate"
 	"github.com/uber/peloton/pkg/common/recovery"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
@@ -105,6 +107,9 @@ type Driver interface {
 	Started() bool
 	// GetLockable returns an interface which controls lock/unlock operations in goal state engine
 	GetLockable() lifecyclemgr.Lockable
+	// GetStuckEntities returns the jobs, tasks and updates found stuck
+	// in goal state by the last check of the watchdogs of the engines.
+	GetStuckEntities() []*StuckEntity
 }
 
 // NewDriver returns a new goal state driver object.
@@ -120,6 +125,7 @@ func NewDriver(
 	parentScope tally.Scope,
 	cfg Config,
 	hmVersion api.Version,
+	freezeState freeze.State,
 ) Driver {
 	cfg.normalize()
 	scope := parentScope.SubScope("goalstate")
@@ -146,6 +152,9 @@ func NewDriver(
 		lm: lifecyclemgr.New(hmVersion, d, scope),
 		resmgrClient: resmgrsvc.NewResourceManagerServiceYARPCClient(
 			d.ClientConfig(common.PelotonResourceManager)),
+		hostTopology: newHostTopology(
+			hostsvc.NewInternalHostServiceYARPCClient(
+				d.ClientConfig(common.PelotonHostManager))),
 		jobStore:        jobStore,
 		taskStore:       taskStore,
 		volumeStore:     volumeStore,
@@ -161,6 +170,10 @@ func NewDriver(
 		cfg:             &cfg,
 		jobType:         jobType,
 		jobScope:        jobScope,
+		enqueueScope:    scope.SubScope("enqueue"),
+		freezeState:     freezeState,
+
+		instanceOverrideOps: ormobjects.NewInstanceOverrideOps(ormStore),
 		taskKillRateLimiter: rate.NewLimiter(
 			cfg.RateLimiterConfig.TaskKill.Rate,
 			cfg.RateLimiterConfig.TaskKill.Burst),
@@ -169,6 +182,37 @@ func NewDriver(
 			cfg.RateLimiterConfig.ExecutorShutdown.Burst),
 	}
 
+	driver.updateVerifier = newUpdateVerifier(
+		newAgentCommandExecutor(cfg.MesosAgentPort),
+		driver.mtx.updateMetrics)
+	driver.stuckTaskDetector = newStuckTaskDetector(
+		driver,
+		&driver.cfg.StuckTaskDetector,
+		taskScope)
+	driver.jobWatchdog = goalstate.NewWatchdog(
+		driver.jobEngine,
+		cfg.JobWatchdog,
+		[]goalstate.Action{
+			{Name: _refreshCacheRemediation, Execute: JobRefreshCache},
+		},
+		jobScope)
+	driver.taskWatchdog = goalstate.NewWatchdog(
+		driver.taskEngine,
+		cfg.TaskWatchdog,
+		[]goalstate.Action{
+			{Name: _refreshCacheRemediation, Execute: TaskRefreshCache},
+			{Name: _reconcileRemediation, Execute: TaskReconcile},
+		},
+		taskScope)
+	driver.updateWatchdog = goalstate.NewWatchdog(
+		driver.updateEngine,
+		cfg.UpdateWatchdog,
+		nil,
+		workflowScope)
+
+	// jobs evicted from the cache are untracked from the goal state engines
+	jobFactory.SetEvictionHook(driver.untrackJob)
+
 	driver.setState(stopped)
 	driver.setCacheState(cleaned)
 	return driver
@@ -209,6 +253,27 @@ type driver struct {
 	lm           lifecyclemgr.Manager
 	resmgrClient resmgrsvc.ResourceManagerServiceYARPCClient
 
+	// hostTopology provides the failure domains of the hosts, used to
+	// batch updates along failure domains
+	hostTopology *hostTopology
+
+	// updateVerifier runs the verification hooks of updates against
+	// the updated instances
+	updateVerifier *updateVerifier
+
+	// healthGates tracks the outcome of the instances of updates with
+	// a health gate or a canary phase
+	healthGates healthGateTracker
+
+	// stuckTaskDetector recovers the tasks stuck in transient states
+	stuckTaskDetector *stuckTaskDetector
+
+	// jobWatchdog, taskWatchdog and updateWatchdog alert on and remediate
+	// the entities stuck in the job, task and update engines
+	jobWatchdog    goalstate.Watchdog
+	taskWatchdog   goalstate.Watchdog
+	updateWatchdog goalstate.Watchdog
+
 	// jobStore, taskStore and volumeStore are the objects to the storage interface.
 	jobStore        storage.JobStore
 	taskStore       storage.TaskStore
@@ -220,6 +285,8 @@ type driver struct {
 	jobRuntimeOps   ormobjects.JobRuntimeOps   // DB ops for job_runtime table
 	jobIndexOps     ormobjects.JobIndexOps     // DB ops for job_index table
 	taskConfigV2Ops ormobjects.TaskConfigV2Ops // DB ops for task_config_v2_table
+	// DB ops for instance_overrides table
+	instanceOverrideOps ormobjects.InstanceOverrideOps
 
 	// jobFactory is the in-memory cache object fpr jobs and tasks
 	jobFactory cached.JobFactory
@@ -232,10 +299,23 @@ type driver struct {
 	jobType job.JobType // the type of the job for the driver
 	// job scope for goalstate driver
 	jobScope tally.Scope
+	// scope for the metrics of enqueuing tasks to resource manager
+	enqueueScope tally.Scope
+
+	// freezeState provides whether the cluster is frozen, during which
+	// updates and task restarts are halted
+	freezeState freeze.State
 
 	// rate limiter for goal state engine initiated task stop
 	taskKillRateLimiter *rate.Limiter
 
+	// cacheChecks stores the time of the last cache consistency
+	// check of each job, keyed by job identifier
+	cacheChecks sync.Map
+
+	// dependencies tracks the blocked jobs waiting on each upstream job
+	dependencies dependencyTracker
+
 	//  rate limiter for goal state engine initiated executor shutdown
 	executorShutShutdownRateLimiter *rate.Limiter
 }
@@ -272,6 +352,7 @@ func (d *driver) EnqueueUpdate(
 
 func (d *driver) DeleteJob(jobID *peloton.JobID) {
 	jobEntity := NewJobEntity(jobID, d)
+	d.cacheChecks.Delete(jobID.GetValue())
 
 	d.RLock()
 	defer d.RUnlock()
@@ -288,6 +369,18 @@ func (d *driver) DeleteTask(jobID *peloton.JobID, instanceID uint32) {
 	d.taskEngine.Delete(taskEntity)
 }
 
+// untrackJob deletes a job, along with its tasks and workflows, from the
+// goal state engines. It is run before the job is evicted from the cache.
+func (d *driver) untrackJob(cachedJob cached.Job) {
+	for instID := range cachedJob.GetAllTasks() {
+		d.DeleteTask(cachedJob.ID(), instID)
+	}
+	for _, u := range cachedJob.GetAllWorkflows() {
+		d.DeleteUpdate(cachedJob.ID(), u.ID())
+	}
+	d.DeleteJob(cachedJob.ID())
+}
+
 func (d *driver) DeleteUpdate(jobID *peloton.JobID, updateID *peloton.UpdateID) {
 	updateEntity := NewUpdateEntity(updateID, jobID, d)
 
@@ -456,6 +549,11 @@ func (d *driver) Start() {
 	d.updateEngine.Start()
 	d.Unlock()
 
+	d.stuckTaskDetector.Start()
+	d.jobWatchdog.Start()
+	d.taskWatchdog.Start()
+	d.updateWatchdog.Start()
+
 	d.setState(started)
 	log.Info("goalstate driver started")
 }
@@ -484,6 +582,13 @@ func (d *driver) Stop(cleanUpCache bool) {
 		}
 	}
 
+	// stop the stuck task detector and the watchdogs before the engines,
+	// since they enqueue the stuck entities into the engines
+	d.stuckTaskDetector.Stop()
+	d.updateWatchdog.Stop()
+	d.taskWatchdog.Stop()
+	d.jobWatchdog.Stop()
+
 	d.Lock()
 	d.updateEngine.Stop()
 	d.taskEngine.Stop()
@@ -550,3 +655,8 @@ func (d *driver) setCacheState(state driverCacheState) {
 func (d *driver) getCacheState() driverCacheState {
 	return driverCacheState(atomic.LoadInt32(&d.cacheState))
 }
+
+// isFrozen returns true if the cluster is frozen
+func (d *driver) isFrozen() bool {
+	return d.freezeState != nil && d.freezeState.IsFrozen()
+}
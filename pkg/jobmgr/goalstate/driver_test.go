@@ -102,6 +102,25 @@ func (suite *DriverTestSuite) SetupTest() {
 		lm:            lmMock,
 	}
 	suite.goalStateDriver.cfg.normalize()
+	suite.goalStateDriver.stuckTaskDetector = newStuckTaskDetector(
+		suite.goalStateDriver,
+		&suite.goalStateDriver.cfg.StuckTaskDetector,
+		tally.NoopScope)
+	suite.goalStateDriver.jobWatchdog = goalstate.NewWatchdog(
+		suite.jobGoalStateEngine,
+		suite.goalStateDriver.cfg.JobWatchdog,
+		nil,
+		tally.NoopScope)
+	suite.goalStateDriver.taskWatchdog = goalstate.NewWatchdog(
+		suite.taskGoalStateEngine,
+		suite.goalStateDriver.cfg.TaskWatchdog,
+		nil,
+		tally.NoopScope)
+	suite.goalStateDriver.updateWatchdog = goalstate.NewWatchdog(
+		suite.updateGoalStateEngine,
+		suite.goalStateDriver.cfg.UpdateWatchdog,
+		nil,
+		tally.NoopScope)
 	suite.goalStateDriver.setState(stopped)
 	suite.goalStateDriver.setCacheState(cleaned)
 	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
@@ -130,6 +149,9 @@ func (suite *DriverTestSuite) TestNewDriver() {
 		NumWorkerTaskThreads:   5,
 		NumWorkerUpdateThreads: 6,
 	}
+	suite.jobFactory.EXPECT().
+		SetEvictionHook(gomock.Any()).
+		Times(2)
 	dr := NewDriver(
 		dispatcher,
 		suite.jobStore,
@@ -142,6 +164,7 @@ func (suite *DriverTestSuite) TestNewDriver() {
 		tally.NoopScope,
 		config,
 		api.V0,
+		nil,
 	)
 	suite.NotNil(dr)
 	suite.Equal(dr.(*driver).jobType, job.JobType_SERVICE)
@@ -158,6 +181,7 @@ func (suite *DriverTestSuite) TestNewDriver() {
 		tally.NoopScope,
 		config,
 		api.V1Alpha,
+		nil,
 	)
 	suite.NotNil(dr)
 	suite.Equal(dr.(*driver).jobType, job.JobType_SERVICE)
@@ -228,6 +252,46 @@ func (suite *DriverTestSuite) TestDeleteTask() {
 	suite.goalStateDriver.DeleteTask(suite.jobID, suite.instanceID)
 }
 
+// TestUntrackJob tests deleting a job evicted from the cache, along with
+// its tasks and workflows, from the goal state engines.
+func (suite *DriverTestSuite) TestUntrackJob() {
+	cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+	cachedUpdate := cachedmocks.NewMockUpdate(suite.ctrl)
+
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(suite.jobID).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{suite.instanceID: cachedTask})
+	suite.cachedJob.EXPECT().
+		GetAllWorkflows().
+		Return(map[string]cached.Update{
+			suite.updateID.GetValue(): cachedUpdate,
+		})
+	cachedUpdate.EXPECT().
+		ID().
+		Return(suite.updateID)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Do(func(taskEntity goalstate.Entity) {
+			suite.Equal(
+				fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID),
+				taskEntity.GetID())
+		})
+	suite.updateGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+	suite.jobGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Do(func(jobEntity goalstate.Entity) {
+			suite.Equal(suite.jobID.GetValue(), jobEntity.GetID())
+		})
+
+	suite.goalStateDriver.untrackJob(suite.cachedJob)
+}
+
 // TestDeleteUpdate tests deleting a job update from goal state engine.
 func (suite *DriverTestSuite) TestDeleteUpdate() {
 	suite.updateGoalStateEngine.EXPECT().
@@ -830,3 +894,52 @@ func (suite *DriverTestSuite) TestEngineStopStoppedDriverWithCleanUpCache() {
 func (suite *DriverTestSuite) TestDriverGetLockable() {
 	suite.NotNil(suite.goalStateDriver.GetLockable())
 }
+
+// TestDriverGetStuckEntities tests getting the jobs, tasks and updates
+// found stuck by the watchdogs of the engines
+func (suite *DriverTestSuite) TestDriverGetStuckEntities() {
+	jobWatchdog := goalstatemocks.NewMockWatchdog(suite.ctrl)
+	taskWatchdog := goalstatemocks.NewMockWatchdog(suite.ctrl)
+	updateWatchdog := goalstatemocks.NewMockWatchdog(suite.ctrl)
+	suite.goalStateDriver.jobWatchdog = jobWatchdog
+	suite.goalStateDriver.taskWatchdog = taskWatchdog
+	suite.goalStateDriver.updateWatchdog = updateWatchdog
+
+	stuckSince := time.Now().Add(-2 * time.Hour)
+	jobWatchdog.EXPECT().GetStuckEntities().Return(nil)
+	taskWatchdog.EXPECT().GetStuckEntities().Return([]goalstate.StuckEntity{
+		{
+			Entity: NewTaskEntity(
+				suite.jobID, suite.instanceID, suite.goalStateDriver),
+			StuckSince:   stuckSince,
+			Failures:     3,
+			LastError:    "fake error",
+			Remediations: []string{_reconcileRemediation},
+		},
+	})
+	updateWatchdog.EXPECT().GetStuckEntities().Return([]goalstate.StuckEntity{
+		{
+			Entity: NewUpdateEntity(
+				suite.updateID, suite.jobID, suite.goalStateDriver),
+			StuckSince:   stuckSince,
+			DeadLettered: true,
+		},
+	})
+
+	stuckEntities := suite.goalStateDriver.GetStuckEntities()
+	suite.Len(stuckEntities, 2)
+
+	suite.Equal(taskEntityKind, stuckEntities[0].Kind)
+	suite.Equal(suite.jobID, stuckEntities[0].JobID)
+	suite.Equal(suite.instanceID, stuckEntities[0].InstanceID)
+	suite.Nil(stuckEntities[0].UpdateID)
+	suite.Equal(stuckSince, stuckEntities[0].StuckSince)
+	suite.Equal(3, stuckEntities[0].Failures)
+	suite.Equal("fake error", stuckEntities[0].LastError)
+	suite.Equal([]string{_reconcileRemediation}, stuckEntities[0].Remediations)
+
+	suite.Equal(updateEntityKind, stuckEntities[1].Kind)
+	suite.Equal(suite.jobID, stuckEntities[1].JobID)
+	suite.Equal(suite.updateID, stuckEntities[1].UpdateID)
+	suite.True(stuckEntities[1].DeadLettered)
+}
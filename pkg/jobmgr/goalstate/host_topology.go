@@ -0,0 +1,123 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+
+	"github.com/uber/peloton/pkg/common/constraints"
+
+	"github.com/pkg/errors"
+)
+
+// _hostTopologyRefreshInterval is the interval at which the attributes
+// of the hosts in the cluster are refreshed from host manager.
+const _hostTopologyRefreshInterval = 5 * time.Minute
+
+// hostTopology caches the attributes of the hosts in the cluster, which
+// define the failure domains the hosts belong to.
+type hostTopology struct {
+	sync.Mutex
+
+	hostMgrClient hostsvc.InternalHostServiceYARPCClient
+
+	// attributes maps a hostname to the values of its attributes
+	attributes  map[string]constraints.LabelValues
+	lastRefresh time.Time
+}
+
+// newHostTopology creates a hostTopology which fetches the host
+// attributes from host manager.
+func newHostTopology(
+	hostMgrClient hostsvc.InternalHostServiceYARPCClient,
+) *hostTopology {
+	return &hostTopology{
+		hostMgrClient: hostMgrClient,
+		attributes:    make(map[string]constraints.LabelValues),
+	}
+}
+
+// refresh fetches the attributes of all the hosts from host manager
+// if they have not been fetched recently.
+func (t *hostTopology) refresh(ctx context.Context) error {
+	if time.Since(t.lastRefresh) < _hostTopologyRefreshInterval {
+		return nil
+	}
+
+	resp, err := t.hostMgrClient.GetMesosAgentInfo(
+		ctx,
+		&hostsvc.GetMesosAgentInfoRequest{},
+	)
+	if err != nil {
+		return err
+	}
+	if resp.GetError() != nil {
+		return errors.New(resp.GetError().GetHostNotFound().GetMessage())
+	}
+
+	attributes := make(map[string]constraints.LabelValues)
+	for _, agent := range resp.GetAgents() {
+		hostname := agent.GetAgentInfo().GetHostname()
+		attributes[hostname] = constraints.GetHostLabelValues(
+			hostname,
+			agent.GetAgentInfo().GetAttributes(),
+		)
+	}
+	t.attributes = attributes
+	t.lastRefresh = time.Now()
+	return nil
+}
+
+// getFailureDomains returns the failure domain of each of the given hosts,
+// which is the value of the given attribute of the host. Hosts which are
+// not known or do not have the attribute are not in the result.
+func (t *hostTopology) getFailureDomains(
+	ctx context.Context,
+	attribute string,
+	hostnames []string,
+) (map[string]string, error) {
+	if t == nil {
+		return nil, errors.New("host topology is not available")
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if err := t.refresh(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to get host attributes")
+	}
+
+	domains := make(map[string]string)
+	for _, hostname := range hostnames {
+		values := t.attributes[hostname][attribute]
+		if len(values) == 0 {
+			continue
+		}
+
+		var domain []string
+		for v := range values {
+			domain = append(domain, v)
+		}
+		sort.Strings(domain)
+		domains[hostname] = strings.Join(domain, ",")
+	}
+	return domains, nil
+}
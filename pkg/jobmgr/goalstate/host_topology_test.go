@@ -0,0 +1,247 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type HostTopologyTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	hostMgrClient   *hostmocks.MockInternalHostServiceYARPCClient
+	cachedJob       *cachedmocks.MockJob
+	goalStateDriver *driver
+}
+
+func TestHostTopology(t *testing.T) {
+	suite.Run(t, new(HostTopologyTestSuite))
+}
+
+func (suite *HostTopologyTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.hostMgrClient = hostmocks.NewMockInternalHostServiceYARPCClient(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.goalStateDriver = &driver{
+		hostTopology: newHostTopology(suite.hostMgrClient),
+	}
+}
+
+func (suite *HostTopologyTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// makeAgent creates a mesos agent on the given host in the given zone
+func makeAgent(hostname string, zone string) *mesosmaster.Response_GetAgents_Agent {
+	attributes := []*mesos.Attribute{}
+	if len(zone) != 0 {
+		name := "zone"
+		textType := mesos.Value_TEXT
+		attributes = append(attributes, &mesos.Attribute{
+			Name: &name,
+			Type: &textType,
+			Text: &mesos.Value_Text{Value: &zone},
+		})
+	}
+	return &mesosmaster.Response_GetAgents_Agent{
+		AgentInfo: &mesos.AgentInfo{
+			Hostname:   &hostname,
+			Attributes: attributes,
+		},
+	}
+}
+
+// expectAgents sets up host manager to return hosts host0..host5,
+// where host0-1 are in zone b, host2-3 are in zone a and host4-5
+// have no zone
+func (suite *HostTopologyTestSuite) expectAgents() {
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), &hostsvc.GetMesosAgentInfoRequest{}).
+		Return(&hostsvc.GetMesosAgentInfoResponse{
+			Agents: []*mesosmaster.Response_GetAgents_Agent{
+				makeAgent("host0", "b"),
+				makeAgent("host1", "b"),
+				makeAgent("host2", "a"),
+				makeAgent("host3", "a"),
+				makeAgent("host4", ""),
+				makeAgent("host5", ""),
+			},
+		}, nil)
+}
+
+// expectHosts sets up each instance i to run on host<i>
+func (suite *HostTopologyTestSuite) expectHosts(instances []uint32) {
+	for _, instID := range instances {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		suite.cachedJob.EXPECT().
+			GetTask(instID).
+			Return(cachedTask)
+		cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&task.RuntimeInfo{
+				Host: fmt.Sprintf("host%d", instID),
+			}, nil)
+	}
+}
+
+// TestGetFailureDomains tests getting the failure domains of hosts,
+// and that the host attributes are cached
+func (suite *HostTopologyTestSuite) TestGetFailureDomains() {
+	suite.expectAgents()
+
+	domains, err := suite.goalStateDriver.hostTopology.getFailureDomains(
+		context.Background(),
+		"zone",
+		[]string{"host0", "host2", "host4", "host6"},
+	)
+	suite.NoError(err)
+	suite.Equal(map[string]string{"host0": "b", "host2": "a"}, domains)
+
+	domains, err = suite.goalStateDriver.hostTopology.getFailureDomains(
+		context.Background(),
+		"zone",
+		[]string{"host1"},
+	)
+	suite.NoError(err)
+	suite.Equal(map[string]string{"host1": "b"}, domains)
+}
+
+// TestGetFailureDomainsHostMgrError tests getting the failure domains
+// when host manager returns an error
+func (suite *HostTopologyTestSuite) TestGetFailureDomainsHostMgrError() {
+	suite.hostMgrClient.EXPECT().
+		GetMesosAgentInfo(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("test error"))
+
+	_, err := suite.goalStateDriver.hostTopology.getFailureDomains(
+		context.Background(),
+		"zone",
+		[]string{"host0"},
+	)
+	suite.Error(err)
+}
+
+// TestGetFailureDomainsNoTopology tests getting the failure domains
+// when the driver does not have a host topology
+func (suite *HostTopologyTestSuite) TestGetFailureDomainsNoTopology() {
+	var topology *hostTopology
+	_, err := topology.getFailureDomains(
+		context.Background(),
+		"zone",
+		[]string{"host0"},
+	)
+	suite.Error(err)
+}
+
+// TestGetInstancesInNextFailureDomain tests that the instances in the
+// first failure domain in sorted order are picked
+func (suite *HostTopologyTestSuite) TestGetInstancesInNextFailureDomain() {
+	instancesToUpdate := []uint32{0, 4, 3, 1, 2}
+	suite.expectAgents()
+	suite.expectHosts(instancesToUpdate)
+
+	instances, err := getInstancesInNextFailureDomain(
+		context.Background(),
+		suite.cachedJob,
+		"zone",
+		nil,
+		instancesToUpdate,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.Equal([]uint32{3, 2}, instances)
+}
+
+// TestGetInstancesInNextFailureDomainWaitForCurrent tests that no
+// instance is picked while instances in another failure domain are
+// being updated
+func (suite *HostTopologyTestSuite) TestGetInstancesInNextFailureDomainWaitForCurrent() {
+	instancesCurrent := []uint32{0}
+	instancesToUpdate := []uint32{1, 2}
+	suite.expectAgents()
+	suite.expectHosts(append(instancesCurrent, instancesToUpdate...))
+
+	instances, err := getInstancesInNextFailureDomain(
+		context.Background(),
+		suite.cachedJob,
+		"zone",
+		instancesCurrent,
+		instancesToUpdate,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.Empty(instances)
+}
+
+// TestGetInstancesInNextFailureDomainCurrentNoHost tests that the
+// instances being updated which have no host do not block the instances
+// in the next failure domain
+func (suite *HostTopologyTestSuite) TestGetInstancesInNextFailureDomainCurrentNoHost() {
+	instancesToUpdate := []uint32{3, 2}
+	suite.expectAgents()
+	cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(6)).
+		Return(cachedTask)
+	cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&task.RuntimeInfo{}, nil)
+	suite.expectHosts(instancesToUpdate)
+
+	instances, err := getInstancesInNextFailureDomain(
+		context.Background(),
+		suite.cachedJob,
+		"zone",
+		[]uint32{6},
+		instancesToUpdate,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.Equal([]uint32{3, 2}, instances)
+}
+
+// TestGetInstancesInNextFailureDomainUnknown tests that instances
+// whose failure domain is not known are picked last
+func (suite *HostTopologyTestSuite) TestGetInstancesInNextFailureDomainUnknown() {
+	instancesCurrent := []uint32{1}
+	instancesToUpdate := []uint32{4, 0, 5}
+	suite.expectAgents()
+	suite.expectHosts(append(instancesCurrent, instancesToUpdate...))
+
+	instances, err := getInstancesInNextFailureDomain(
+		context.Background(),
+		suite.cachedJob,
+		"zone",
+		instancesCurrent,
+		instancesToUpdate,
+		suite.goalStateDriver,
+	)
+	suite.NoError(err)
+	suite.Equal([]uint32{0}, instances)
+}
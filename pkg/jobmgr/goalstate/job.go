@@ -60,6 +60,8 @@ const (
 	ReloadRuntimeAction JobAction = "reload"
 	// KillAndDeleteJobAction kills a job and deletes it if possible
 	KillAndDeleteJobAction JobAction = "kill_and_delete"
+	// CheckCacheConsistencyAction validates the job cache against the DB
+	CheckCacheConsistencyAction JobAction = "check_cache_consistency"
 )
 
 // _jobActionsMaps maps the JobAction string to the Action function.
@@ -90,6 +92,7 @@ var (
 		},
 		job.JobState_SUCCEEDED: {
 			job.JobState_INITIALIZED:   CreateTasksAction,
+			job.JobState_BLOCKED:       CreateTasksAction,
 			job.JobState_SUCCEEDED:     UntrackAction,
 			job.JobState_FAILED:        UntrackAction,
 			job.JobState_KILLED:        UntrackAction,
@@ -104,11 +107,13 @@ var (
 			// TODO: revisit the rules after new job kill
 			// code is checked in
 			job.JobState_INITIALIZED: KillAction,
+			job.JobState_BLOCKED:     KillAction,
 			job.JobState_PENDING:     KillAction,
 			job.JobState_RUNNING:     KillAction,
 		},
 		job.JobState_FAILED: {
 			job.JobState_INITIALIZED:   JobStateInvalidAction,
+			job.JobState_BLOCKED:       JobStateInvalidAction,
 			job.JobState_PENDING:       JobStateInvalidAction,
 			job.JobState_RUNNING:       JobStateInvalidAction,
 			job.JobState_SUCCEEDED:     JobStateInvalidAction,
@@ -120,6 +125,7 @@ var (
 		job.JobState_DELETED: {
 			job.JobState_UNINITIALIZED: DeleteJobAction,
 			job.JobState_INITIALIZED:   KillAction,
+			job.JobState_BLOCKED:       KillAction,
 			job.JobState_PENDING:       KillAction,
 			job.JobState_RUNNING:       KillAction,
 			job.JobState_KILLING:       KillAction,
@@ -227,11 +233,29 @@ func (j *jobEntity) GetActionList(
 			Name:    string(EvaluateSLAAction),
 			Execute: JobEvaluateMaxRunningInstancesSLA,
 		})
+
+		actions = append(actions, goalstate.Action{
+			Name:    string(CheckCacheConsistencyAction),
+			Execute: JobCheckCacheConsistency,
+		})
 	}
 
 	return context.Background(), nil, actions
 }
 
+// IsConverged returns true if the job has no action to run to reach its
+// goal state, the actions which are always run notwithstanding.
+func (j *jobEntity) IsConverged(state interface{}, goalState interface{}) bool {
+	jobState := state.(cached.JobStateVector)
+	jobGoalState := goalState.(cached.JobStateVector)
+
+	if jobState.State == job.JobState_UNKNOWN ||
+		jobGoalState.State == job.JobState_UNKNOWN {
+		return false
+	}
+	return j.suggestJobAction(jobState, jobGoalState) == NoJobAction
+}
+
 // suggestJobAction provides the job action for a given state and goal state
 func (j *jobEntity) suggestJobAction(state cached.JobStateVector, goalstate cached.JobStateVector) JobAction {
 	if state.StateVersion < goalstate.StateVersion {
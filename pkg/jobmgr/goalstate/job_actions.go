@@ -46,16 +46,31 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 	}
 
 	jobConfig, err := cachedJob.GetConfig(ctx)
-	if err != nil {
-		if !yarpcerrors.IsNotFound(err) {
-			// if config is not found, untrack the job from cache
+	if err != nil && !yarpcerrors.IsNotFound(err) {
+		// if config is not found, untrack the job from cache
+		return err
+	}
+
+	if err == nil {
+		if jobConfig.GetType() == job.JobType_SERVICE {
+			// service jobs are always active and never untracked.
+			// Call runtime updater, because job runtime can change
+			// when an update is running on the job.
+			return JobRuntimeUpdater(ctx, entity)
+		}
+
+		expiry, ok, err := completedJobExpiry(ctx, cachedJob, jobConfig)
+		if err != nil && !yarpcerrors.IsNotFound(err) {
 			return err
 		}
-	} else if jobConfig.GetType() == job.JobType_SERVICE {
-		// service jobs are always active and never untracked.
-		// Call runtime updater, because job runtime can change
-		// when an update is running on the job.
-		return JobRuntimeUpdater(ctx, entity)
+		if ok && !time.Now().Before(expiry) {
+			return jobDeleteExpired(ctx, entity, jobConfig)
+		}
+		if ok {
+			// evaluate the job again once its TTL expires, after it
+			// has been untracked
+			defer goalStateDriver.EnqueueJob(jobEnt.id, expiry)
+		}
 	}
 
 	// First clean from goal state
@@ -70,6 +85,73 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 	return nil
 }
 
+// completedJobExpiry returns the time at which a completed batch job is
+// garbage collected. It returns false if the job has no TTL or has not
+// completed yet.
+func completedJobExpiry(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig jobmgrcommon.JobConfig,
+) (time.Time, bool, error) {
+	ttl := cached.GetMaxCompletedJobTTL(jobConfig)
+	if ttl == 0 {
+		return time.Time{}, false, nil
+	}
+
+	jobRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if !util.IsPelotonJobStateTerminal(jobRuntime.GetState()) ||
+		len(jobRuntime.GetCompletionTime()) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	completionTime, err := time.Parse(
+		time.RFC3339Nano, jobRuntime.GetCompletionTime())
+	if err != nil {
+		log.WithError(err).
+			WithField("job_id", cachedJob.ID().GetValue()).
+			WithField("completion_time", jobRuntime.GetCompletionTime()).
+			Warn("failed to parse job completion time")
+		return time.Time{}, false, nil
+	}
+	return completionTime.Add(ttl), true, nil
+}
+
+// jobDeleteExpired deletes a completed batch job whose TTL has expired,
+// along with its task runtimes and pod events.
+func jobDeleteExpired(
+	ctx context.Context,
+	entity goalstate.Entity,
+	jobConfig jobmgrcommon.JobConfig,
+) error {
+	jobEnt := entity.(*jobEntity)
+	goalStateDriver := jobEnt.driver
+
+	if goalStateDriver.isFrozen() {
+		// the job is deleted once the cluster is unfrozen
+		goalStateDriver.mtx.jobMetrics.JobTTLDeleteFrozen.Inc(1)
+		goalStateDriver.EnqueueJob(
+			jobEnt.id,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		return nil
+	}
+
+	if err := JobDelete(ctx, entity); err != nil {
+		return err
+	}
+
+	log.WithField("job_id", jobEnt.GetID()).
+		WithField("instance_count", jobConfig.GetInstanceCount()).
+		Info("deleted completed job after its TTL expired")
+	goalStateDriver.mtx.jobMetrics.JobTTLDeleted.Inc(1)
+	goalStateDriver.mtx.jobMetrics.JobTTLReclaimedTasks.Inc(
+		int64(jobConfig.GetInstanceCount()))
+	return nil
+}
+
 // JobStateInvalid dumps a sentry error to indicate that the
 // job goal state, state combination is not valid
 func JobStateInvalid(ctx context.Context, entity goalstate.Entity) error {
@@ -205,9 +287,11 @@ func DeleteJobFromActiveJobs(
 		return err
 	}
 
-	// delete a terminal batch job from the active jobs table
+	// delete a terminal batch job from the active jobs table, unless it
+	// still has to be garbage collected once its TTL expires
 	if cfg.GetType() == job.JobType_BATCH &&
-		util.IsPelotonJobStateTerminal(runtime.GetState()) {
+		util.IsPelotonJobStateTerminal(runtime.GetState()) &&
+		cached.GetMaxCompletedJobTTL(cfg) == 0 {
 		if err := goalStateDriver.activeJobsOps.Delete(
 			ctx, jobEnt.id); err != nil {
 			return err
@@ -289,6 +373,12 @@ func JobDelete(
 		return nil
 	}
 
+	// the instance overrides are kept outside of the job config, so
+	// they are deleted separately, before the job is dropped from the cache
+	if err := deleteInstanceOverrides(ctx, goalStateDriver, jobEnt.id); err != nil {
+		return errors.Wrap(err, "failed to delete instance overrides from store")
+	}
+
 	err := cachedJob.Delete(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete job from store")
@@ -305,6 +395,26 @@ func JobDelete(
 	return nil
 }
 
+// deleteInstanceOverrides deletes the instance overrides of a job.
+func deleteInstanceOverrides(
+	ctx context.Context,
+	goalStateDriver *driver,
+	jobID *peloton.JobID,
+) error {
+	overrides, err := goalStateDriver.instanceOverrideOps.GetAll(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	for instanceID := range overrides {
+		if err := goalStateDriver.instanceOverrideOps.Delete(
+			ctx, jobID, instanceID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // JobReloadRuntime reloads the job runtime into the cache
 func JobReloadRuntime(
 	ctx context.Context,
@@ -432,8 +542,9 @@ func JobKillAndUntrack(ctx context.Context, entity goalstate.Entity) error {
 		return nil
 	}
 
+	// the job is already terminal, so its tasks are stopped as on request
 	runtimeDiffNonTerminatedTasks, _, err :=
-		stopTasks(ctx, cachedJob, goalStateDriver)
+		stopTasks(ctx, cachedJob, nil, goalStateDriver)
 	if err != nil {
 		return err
 	}
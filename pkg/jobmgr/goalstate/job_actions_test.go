@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -27,6 +28,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/common/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 
@@ -61,6 +63,7 @@ type jobActionsTestSuite struct {
 	activeJobsOps         *ormmocks.MockActiveJobsOps
 	jobIndexOps           *ormmocks.MockJobIndexOps
 	jobRuntimeOps         *ormmocks.MockJobRuntimeOps
+	overrideOps           *ormmocks.MockInstanceOverrideOps
 }
 
 func (suite *jobActionsTestSuite) SetupTest() {
@@ -75,6 +78,7 @@ func (suite *jobActionsTestSuite) SetupTest() {
 	suite.activeJobsOps = ormmocks.NewMockActiveJobsOps(suite.ctrl)
 	suite.jobIndexOps = ormmocks.NewMockJobIndexOps(suite.ctrl)
 	suite.jobRuntimeOps = ormmocks.NewMockJobRuntimeOps(suite.ctrl)
+	suite.overrideOps = ormmocks.NewMockInstanceOverrideOps(suite.ctrl)
 
 	suite.goalStateDriver = &driver{
 		updateStore:   suite.updateStore,
@@ -88,6 +92,8 @@ func (suite *jobActionsTestSuite) SetupTest() {
 		jobRuntimeOps: suite.jobRuntimeOps,
 		mtx:           NewMetrics(tally.NoopScope),
 		cfg:           &Config{},
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 	suite.goalStateDriver.cfg.normalize()
 
@@ -138,6 +144,140 @@ func (suite *jobActionsTestSuite) TestUntrackJobBatch() {
 	suite.NoError(err)
 }
 
+// TestUntrackJobBatchTTLExpired tests that a completed batch job is
+// deleted when its TTL has expired
+func (suite *jobActionsTestSuite) TestUntrackJobBatchTTLExpired() {
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob).
+		Times(2)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type:               job.JobType_BATCH,
+			InstanceCount:      1,
+			MaxCompletedJobTtl: 60,
+		}, nil)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State: job.JobState_SUCCEEDED,
+			CompletionTime: time.Now().Add(-2 * time.Minute).
+				UTC().Format(time.RFC3339Nano),
+		}, nil)
+
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), suite.jobID).
+		Return(map[uint32]*task.TaskConfig{0: {}}, nil)
+
+	suite.overrideOps.EXPECT().
+		Delete(gomock.Any(), suite.jobID, uint32(0)).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		Delete(gomock.Any()).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: suite.cachedTask})
+
+	suite.taskGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+
+	suite.jobGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+
+	suite.jobFactory.EXPECT().
+		ClearJob(suite.jobID)
+
+	suite.NoError(JobUntrack(context.Background(), suite.jobEnt))
+}
+
+// TestUntrackJobBatchTTLNotExpired tests that a completed batch job whose
+// TTL has not expired is untracked and evaluated again at expiry
+func (suite *jobActionsTestSuite) TestUntrackJobBatchTTLNotExpired() {
+	completionTime := time.Now().UTC()
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type:               job.JobType_BATCH,
+			MaxCompletedJobTtl: 3600,
+		}, nil)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:          job.JobState_KILLED,
+			CompletionTime: completionTime.Format(time.RFC3339Nano),
+		}, nil)
+
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: suite.cachedTask})
+
+	gomock.InOrder(
+		suite.jobGoalStateEngine.EXPECT().
+			Delete(gomock.Any()),
+		suite.jobGoalStateEngine.EXPECT().
+			Enqueue(gomock.Any(), gomock.Any()).
+			Do(func(_ goalstate.Entity, deadline time.Time) {
+				suite.True(deadline.Equal(completionTime.Add(time.Hour)))
+			}),
+	)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+
+	suite.jobFactory.EXPECT().
+		ClearJob(suite.jobID)
+
+	suite.NoError(JobUntrack(context.Background(), suite.jobEnt))
+}
+
+// TestUntrackJobBatchTTLExpiredFrozen tests that a completed batch job
+// whose TTL has expired is not deleted, but evaluated again later, while
+// the cluster is frozen
+func (suite *jobActionsTestSuite) TestUntrackJobBatchTTLExpiredFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			Type:               job.JobType_BATCH,
+			MaxCompletedJobTtl: 60,
+		}, nil)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State: job.JobState_SUCCEEDED,
+			CompletionTime: time.Now().Add(-2 * time.Minute).
+				UTC().Format(time.RFC3339Nano),
+		}, nil)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.After(time.Now().Add(20 * time.Second)))
+		})
+
+	suite.NoError(JobUntrack(context.Background(), suite.jobEnt))
+}
+
 func (suite *jobActionsTestSuite) TestUntrackJobStateless() {
 	suite.jobFactory.EXPECT().
 		GetJob(suite.jobID).
@@ -532,6 +672,14 @@ func (suite *jobActionsTestSuite) TestJobDeleteSuccess() {
 			GetJob(suite.jobID).
 			Return(suite.cachedJob),
 
+		suite.overrideOps.EXPECT().
+			GetAll(gomock.Any(), suite.jobID).
+			Return(map[uint32]*task.TaskConfig{1: {}}, nil),
+
+		suite.overrideOps.EXPECT().
+			Delete(gomock.Any(), suite.jobID, uint32(1)).
+			Return(nil),
+
 		suite.cachedJob.EXPECT().
 			Delete(gomock.Any()).
 			Return(nil),
@@ -572,6 +720,9 @@ func (suite *jobActionsTestSuite) TestJobDeleteStoreError() {
 		suite.jobFactory.EXPECT().
 			GetJob(suite.jobID).
 			Return(suite.cachedJob),
+		suite.overrideOps.EXPECT().
+			GetAll(gomock.Any(), suite.jobID).
+			Return(nil, nil),
 		suite.cachedJob.EXPECT().
 			Delete(gomock.Any()).
 			Return(yarpcerrors.InternalErrorf("test error")),
@@ -580,6 +731,25 @@ func (suite *jobActionsTestSuite) TestJobDeleteStoreError() {
 	suite.Error(JobDelete(context.Background(), suite.jobEnt))
 }
 
+// TestJobDeleteOverrideError tests the failure case of deleting job due
+// to error while deleting its instance overrides, in which case the job
+// is not deleted
+func (suite *jobActionsTestSuite) TestJobDeleteOverrideError() {
+	gomock.InOrder(
+		suite.jobFactory.EXPECT().
+			GetJob(suite.jobID).
+			Return(suite.cachedJob),
+		suite.overrideOps.EXPECT().
+			GetAll(gomock.Any(), suite.jobID).
+			Return(map[uint32]*task.TaskConfig{1: {}}, nil),
+		suite.overrideOps.EXPECT().
+			Delete(gomock.Any(), suite.jobID, uint32(1)).
+			Return(yarpcerrors.InternalErrorf("test error")),
+	)
+
+	suite.Error(JobDelete(context.Background(), suite.jobEnt))
+}
+
 // TestJobReloadRuntimeSuccess tests the success
 // case of reloading job runtime into cache
 func (suite *jobActionsTestSuite) TestJobReloadRuntimeSuccess() {
@@ -0,0 +1,255 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// JobCheckCacheConsistency validates the job runtime and the task runtimes
+// in the cache against the DB, at most once per cache consistency check
+// period for each job, to catch the cache drifting from the DB, e.g. after
+// partial write failures. Runtimes which are stale in the cache, and tasks
+// missing from the cache, are reloaded from the DB. The job is evaluated
+// again if the task state counts of its runtime do not match the states
+// of its tasks in the DB, so that they are recomputed.
+func JobCheckCacheConsistency(ctx context.Context, entity goalstate.Entity) error {
+	jobEnt := entity.(*jobEntity)
+	goalStateDriver := jobEnt.driver
+
+	if !goalStateDriver.isCacheCheckDue(jobEnt.id) {
+		return nil
+	}
+
+	cachedJob := goalStateDriver.jobFactory.GetJob(jobEnt.id)
+	if cachedJob == nil {
+		return nil
+	}
+	goalStateDriver.mtx.jobMetrics.JobCacheCheck.Inc(1)
+
+	jobRuntime, err := checkJobRuntimeConsistency(
+		ctx, cachedJob, goalStateDriver)
+	if err != nil {
+		return err
+	}
+	taskRuntimes, err := checkTaskRuntimesConsistency(
+		ctx, cachedJob, goalStateDriver)
+	if err != nil {
+		return err
+	}
+	checkTaskStatsConsistency(
+		cachedJob, jobRuntime, taskRuntimes, goalStateDriver)
+	return nil
+}
+
+// isCacheCheckDue returns true if the cache consistency check of a job is
+// due, and records the time of the check. The first check of a job happens
+// one period after the job is first evaluated, since the job is loaded from
+// the DB into the cache when it is recovered.
+func (d *driver) isCacheCheckDue(jobID *peloton.JobID) bool {
+	period := d.cfg.CacheConsistencyCheckPeriod
+	if period <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	lastCheck, ok := d.cacheChecks.LoadOrStore(jobID.GetValue(), now)
+	if !ok || now.Sub(lastCheck.(time.Time)) < period {
+		return false
+	}
+	d.cacheChecks.Store(jobID.GetValue(), now)
+	return true
+}
+
+// checkJobRuntimeConsistency reloads the job runtime from the DB if the
+// runtime in the cache is older, and returns the job runtime in the cache.
+// The job runtime is written to the DB before the cache, and the cache is
+// read before the DB, so a runtime in the cache newer than the one in the
+// DB means that the DB lost a write. It is only reported, since the DB
+// catches up on the next runtime update of the job.
+func checkJobRuntimeConsistency(
+	ctx context.Context,
+	cachedJob cached.Job,
+	goalStateDriver *driver,
+) (*pbjob.RuntimeInfo, error) {
+	cacheRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dbRuntime, err := goalStateDriver.jobRuntimeOps.Get(ctx, cachedJob.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	cacheVersion := cacheRuntime.GetRevision().GetVersion()
+	dbVersion := dbRuntime.GetRevision().GetVersion()
+	if cacheVersion == dbVersion {
+		return cacheRuntime, nil
+	}
+
+	goalStateDriver.mtx.jobMetrics.JobCacheRuntimeMismatch.Inc(1)
+	log.WithFields(log.Fields{
+		"job_id":        cachedJob.ID().GetValue(),
+		"cache_version": cacheVersion,
+		"db_version":    dbVersion,
+	}).Warn("job runtime in cache diverges from DB")
+
+	if cacheVersion > dbVersion {
+		return cacheRuntime, nil
+	}
+
+	if err := cachedJob.Update(
+		ctx,
+		&pbjob.JobInfo{Runtime: dbRuntime},
+		nil,
+		nil,
+		cached.UpdateCacheOnly,
+	); err != nil {
+		return nil, err
+	}
+	goalStateDriver.mtx.jobMetrics.JobCacheRepaired.Inc(1)
+	return dbRuntime, nil
+}
+
+// checkTaskRuntimesConsistency reloads the tasks which are missing from
+// the cache, or whose runtime in the cache is older than in the DB, and
+// returns the task runtimes in the DB. Tasks in the cache but not in the
+// DB are only reported, since they may be in the middle of being created.
+func checkTaskRuntimesConsistency(
+	ctx context.Context,
+	cachedJob cached.Job,
+	goalStateDriver *driver,
+) (map[uint32]*pbtask.RuntimeInfo, error) {
+	// read the cache before the DB, since task runtimes are written
+	// to the DB before the cache
+	cachedTasks := cachedJob.GetAllTasks()
+
+	dbRuntimes, err := goalStateDriver.taskStore.GetTaskRuntimesForJobByRange(
+		ctx, cachedJob.ID(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var staleTasks []uint32
+	for instanceID, dbRuntime := range dbRuntimes {
+		cachedTask, ok := cachedTasks[instanceID]
+		if !ok {
+			staleTasks = append(staleTasks, instanceID)
+			continue
+		}
+
+		cacheRuntime := cachedTask.GetCacheRuntime()
+		if cacheRuntime == nil {
+			// the runtime is loaded from the DB on first use
+			continue
+		}
+		if cacheRuntime.GetRevision().GetVersion() <
+			dbRuntime.GetRevision().GetVersion() {
+			staleTasks = append(staleTasks, instanceID)
+		}
+	}
+
+	for instanceID := range cachedTasks {
+		if _, ok := dbRuntimes[instanceID]; !ok {
+			goalStateDriver.mtx.jobMetrics.JobCacheTaskExtra.Inc(1)
+			log.WithFields(log.Fields{
+				"job_id":      cachedJob.ID().GetValue(),
+				"instance_id": instanceID,
+			}).Warn("task in cache is not found in DB")
+		}
+	}
+
+	if len(staleTasks) == 0 {
+		return dbRuntimes, nil
+	}
+
+	goalStateDriver.mtx.jobMetrics.JobCacheTaskMismatch.Inc(int64(len(staleTasks)))
+	log.WithFields(log.Fields{
+		"job_id":    cachedJob.ID().GetValue(),
+		"instances": staleTasks,
+	}).Warn("tasks in cache diverge from DB")
+
+	taskInfos := make(map[uint32]*pbtask.TaskInfo)
+	for _, instanceID := range staleTasks {
+		taskInfo, err := goalStateDriver.taskStore.GetTaskByID(
+			ctx, util.CreatePelotonTaskID(cachedJob.ID().GetValue(), instanceID))
+		if err != nil {
+			return nil, err
+		}
+		taskInfos[instanceID] = taskInfo
+	}
+
+	// replace only the tasks which are still older in the
+	// cache, to not overwrite concurrent updates
+	if err := cachedJob.ReplaceTasks(taskInfos, false); err != nil {
+		return nil, err
+	}
+	for _, instanceID := range staleTasks {
+		goalStateDriver.EnqueueTask(cachedJob.ID(), instanceID, time.Now())
+	}
+	goalStateDriver.mtx.jobMetrics.JobCacheRepaired.Inc(1)
+	return dbRuntimes, nil
+}
+
+// checkTaskStatsConsistency compares the task state counts of the job
+// runtime in the cache with the states of the tasks in the DB, and
+// enqueues the job if they differ, so that the job runtime updater
+// recomputes them from the tasks in the cache. A task changing state
+// between the reads only causes an extra evaluation of the job.
+func checkTaskStatsConsistency(
+	cachedJob cached.Job,
+	jobRuntime *pbjob.RuntimeInfo,
+	taskRuntimes map[uint32]*pbtask.RuntimeInfo,
+	goalStateDriver *driver,
+) {
+	dbStats := make(map[string]uint32)
+	for _, runtime := range taskRuntimes {
+		dbStats[runtime.GetState().String()]++
+	}
+
+	consistent := true
+	for state, count := range jobRuntime.GetTaskStats() {
+		if count != dbStats[state] {
+			consistent = false
+		}
+	}
+	for state, count := range dbStats {
+		if count != jobRuntime.GetTaskStats()[state] {
+			consistent = false
+		}
+	}
+	if consistent {
+		return
+	}
+
+	goalStateDriver.mtx.jobMetrics.JobCacheTaskStatsMismatch.Inc(1)
+	log.WithFields(log.Fields{
+		"job_id":      cachedJob.ID().GetValue(),
+		"cache_stats": jobRuntime.GetTaskStats(),
+		"db_stats":    dbStats,
+	}).Warn("task state counts in cache diverge from DB")
+	goalStateDriver.EnqueueJob(cachedJob.ID(), time.Now())
+}
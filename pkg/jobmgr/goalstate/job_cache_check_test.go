@@ -0,0 +1,291 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	ormmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type jobCacheCheckTestSuite struct {
+	suite.Suite
+
+	ctrl                *gomock.Controller
+	jobGoalStateEngine  *goalstatemocks.MockEngine
+	taskGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory          *cachedmocks.MockJobFactory
+	cachedJob           *cachedmocks.MockJob
+	cachedTask          *cachedmocks.MockTask
+	taskStore           *storemocks.MockTaskStore
+	jobRuntimeOps       *ormmocks.MockJobRuntimeOps
+	goalStateDriver     *driver
+	jobID               *peloton.JobID
+	jobEnt              *jobEntity
+}
+
+func TestJobCacheCheck(t *testing.T) {
+	suite.Run(t, new(jobCacheCheckTestSuite))
+}
+
+func (suite *jobCacheCheckTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+	suite.taskStore = storemocks.NewMockTaskStore(suite.ctrl)
+	suite.jobRuntimeOps = ormmocks.NewMockJobRuntimeOps(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		jobEngine:     suite.jobGoalStateEngine,
+		taskEngine:    suite.taskGoalStateEngine,
+		jobFactory:    suite.jobFactory,
+		taskStore:     suite.taskStore,
+		jobRuntimeOps: suite.jobRuntimeOps,
+		mtx:           NewMetrics(tally.NoopScope),
+		cfg:           &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.jobEnt = &jobEntity{
+		id:     suite.jobID,
+		driver: suite.goalStateDriver,
+	}
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+
+	// make the check due for the job
+	suite.goalStateDriver.cacheChecks.Store(
+		suite.jobID.GetValue(),
+		time.Now().Add(-2*suite.goalStateDriver.cfg.CacheConsistencyCheckPeriod))
+}
+
+func (suite *jobCacheCheckTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// newTaskRuntime returns a task runtime with the given revision
+func newTaskRuntime(version uint64) *pbtask.RuntimeInfo {
+	return &pbtask.RuntimeInfo{
+		State:    pbtask.TaskState_RUNNING,
+		Revision: &peloton.ChangeLog{Version: version},
+	}
+}
+
+// TestIsCacheCheckDue tests that the cache check of a
+// job runs at most once per period
+func (suite *jobCacheCheckTestSuite) TestIsCacheCheckDue() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+
+	// first evaluation of the job
+	suite.False(suite.goalStateDriver.isCacheCheckDue(jobID))
+	suite.False(suite.goalStateDriver.isCacheCheckDue(jobID))
+
+	suite.True(suite.goalStateDriver.isCacheCheckDue(suite.jobID))
+	suite.False(suite.goalStateDriver.isCacheCheckDue(suite.jobID))
+
+	// the check is disabled
+	suite.goalStateDriver.cfg.CacheConsistencyCheckPeriod = -1
+	suite.goalStateDriver.cacheChecks.Store(
+		suite.jobID.GetValue(), time.Time{})
+	suite.False(suite.goalStateDriver.isCacheCheckDue(suite.jobID))
+}
+
+// TestCacheCheckConsistent tests checking a job whose
+// cache is consistent with the DB
+func (suite *jobCacheCheckTestSuite) TestCacheCheckConsistent() {
+	jobRuntime := &pbjob.RuntimeInfo{
+		State:     pbjob.JobState_RUNNING,
+		Revision:  &peloton.ChangeLog{Version: 2},
+		TaskStats: map[string]uint32{"RUNNING": 1},
+	}
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).
+		Return(jobRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(jobRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: suite.cachedTask})
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), suite.jobID, nil).
+		Return(map[uint32]*pbtask.RuntimeInfo{0: newTaskRuntime(3)}, nil)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(newTaskRuntime(3))
+
+	suite.NoError(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
+
+// TestCacheCheckRepair tests reloading a stale job runtime and
+// stale and missing tasks from the DB into the cache
+func (suite *jobCacheCheckTestSuite) TestCacheCheckRepair() {
+	cacheRuntime := &pbjob.RuntimeInfo{
+		State:    pbjob.JobState_PENDING,
+		Revision: &peloton.ChangeLog{Version: 1},
+	}
+	dbRuntime := &pbjob.RuntimeInfo{
+		State:     pbjob.JobState_RUNNING,
+		Revision:  &peloton.ChangeLog{Version: 2},
+		TaskStats: map[string]uint32{"RUNNING": 2},
+	}
+	extraTask := cachedmocks.NewMockTask(suite.ctrl)
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).
+		Return(dbRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(cacheRuntime, nil)
+	suite.cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			&pbjob.JobInfo{Runtime: dbRuntime},
+			nil,
+			nil,
+			cached.UpdateCacheOnly).
+		Return(nil)
+
+	// instance 0 is stale, 1 is missing and 2 is extra in cache
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{
+			0: suite.cachedTask,
+			2: extraTask,
+		})
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), suite.jobID, nil).
+		Return(map[uint32]*pbtask.RuntimeInfo{
+			0: newTaskRuntime(3),
+			1: newTaskRuntime(1),
+		}, nil)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(newTaskRuntime(2))
+
+	taskInfos := make(map[uint32]*pbtask.TaskInfo)
+	for _, instanceID := range []uint32{0, 1} {
+		taskInfo := &pbtask.TaskInfo{
+			JobId:      suite.jobID,
+			InstanceId: instanceID,
+			Runtime:    newTaskRuntime(3),
+		}
+		taskInfos[instanceID] = taskInfo
+		suite.taskStore.EXPECT().
+			GetTaskByID(
+				gomock.Any(),
+				fmt.Sprintf("%s-%d", suite.jobID.GetValue(), instanceID)).
+			Return(taskInfo, nil)
+	}
+	suite.cachedJob.EXPECT().ReplaceTasks(taskInfos, false).Return(nil)
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(2)
+
+	suite.NoError(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
+
+// TestCacheCheckCacheNewer tests that a job runtime which is
+// newer in the cache than in the DB is not reloaded
+func (suite *jobCacheCheckTestSuite) TestCacheCheckCacheNewer() {
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).
+		Return(&pbjob.RuntimeInfo{
+			Revision: &peloton.ChangeLog{Version: 1},
+		}, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbjob.RuntimeInfo{
+			Revision: &peloton.ChangeLog{Version: 2},
+		}, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{})
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), suite.jobID, nil).
+		Return(map[uint32]*pbtask.RuntimeInfo{}, nil)
+
+	suite.NoError(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
+
+// TestCacheCheckTaskStatsMismatch tests that a job whose task state
+// counts in the cache do not match its tasks in the DB is enqueued
+func (suite *jobCacheCheckTestSuite) TestCacheCheckTaskStatsMismatch() {
+	jobRuntime := &pbjob.RuntimeInfo{
+		State:     pbjob.JobState_RUNNING,
+		Revision:  &peloton.ChangeLog{Version: 2},
+		TaskStats: map[string]uint32{"PENDING": 1},
+	}
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).
+		Return(jobRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(jobRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: suite.cachedTask})
+	suite.taskStore.EXPECT().
+		GetTaskRuntimesForJobByRange(gomock.Any(), suite.jobID, nil).
+		Return(map[uint32]*pbtask.RuntimeInfo{0: newTaskRuntime(3)}, nil)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(newTaskRuntime(3))
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
+
+// TestCacheCheckDBError tests failing to read the job runtime from DB
+func (suite *jobCacheCheckTestSuite) TestCacheCheckDBError() {
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbjob.RuntimeInfo{}, nil)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.jobID).
+		Return(nil, fmt.Errorf("fake db error"))
+
+	suite.Error(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
+
+// TestCacheCheckJobNotInCache tests skipping the check
+// of a job which is not in cache
+func (suite *jobCacheCheckTestSuite) TestCacheCheckJobNotInCache() {
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(nil)
+
+	suite.NoError(JobCheckCacheConsistency(context.Background(), suite.jobEnt))
+}
@@ -72,6 +72,20 @@ func JobCreateTasks(ctx context.Context, entity goalstate.Entity) error {
 		return yarpcerrors.AbortedErrorf("failed to get job from cache")
 	}
 
+	// a cron job creates new jobs on its schedule instead of tasks
+	if jobConfig.GetCronConfig() != nil {
+		return jobCron(ctx, cachedJob, jobConfig, configAddOn, goalStateDriver)
+	}
+
+	// the tasks are only created once the jobs the job depends on succeed
+	if len(jobConfig.GetDependencies()) > 0 {
+		blocked, err := checkJobDependencies(
+			ctx, cachedJob, jobConfig, goalStateDriver)
+		if err != nil || blocked {
+			return err
+		}
+	}
+
 	// First create task configs
 	if err = cachedJob.CreateTaskConfigs(
 		ctx,
@@ -146,12 +160,36 @@ func sendTasksToResMgr(
 	if len(tasks) == 0 {
 		return nil
 	}
+
+	if err := jobmgr_task.ApplyInstanceOverrides(
+		ctx,
+		goalStateDriver.instanceOverrideOps,
+		jobID,
+		jobConfig.GetInstanceConfigMergeStrategy(),
+		tasks); err != nil {
+		log.WithError(err).
+			WithField("job_id", jobID.GetValue()).
+			Error("failed to get instance overrides")
+		return err
+	}
+
+	tasks, correlationIDs := jobmgr_task.SetLaunchCorrelationIDs(tasks)
+	for _, t := range tasks {
+		log.WithField("job_id", jobID.GetValue()).
+			WithField("instance_id", t.GetInstanceId()).
+			WithField("mesos_task_id", t.GetRuntime().GetMesosTaskId().GetValue()).
+			WithField("launch_correlation_id", t.GetRuntime().GetLaunchCorrelationId()).
+			Info("sending task for placement")
+	}
+
 	// Send tasks to resource manager
 	response, err := jobmgr_task.EnqueueGangs(
 		ctx,
 		tasks,
 		jobConfig,
-		goalStateDriver.resmgrClient)
+		goalStateDriver.resmgrClient,
+		&goalStateDriver.cfg.Enqueue,
+		goalStateDriver.enqueueScope)
 
 	if err != nil {
 		log.WithError(err).
@@ -169,7 +207,8 @@ func sendTasksToResMgr(
 		log.WithField("job_id", jobID.GetValue()).
 			WithField("count", len(tasks)).
 			Debug("Enqueued tasks as gangs to Resource Manager")
-		return transitTasksToPending(ctx, jobID, requestedIDs, goalStateDriver)
+		return transitTasksToPending(
+			ctx, jobID, requestedIDs, correlationIDs, goalStateDriver)
 	}
 
 	if response.GetError().GetFailure() == nil {
@@ -209,7 +248,8 @@ func sendTasksToResMgr(
 
 	// EnqueueGangs failed tasks are all previously enqueued tasks, meaning all gangs have been enqueued
 	if len(unenquedInstIDs) == 0 {
-		return transitTasksToPending(ctx, jobID, requestedIDs, goalStateDriver)
+		return transitTasksToPending(
+			ctx, jobID, requestedIDs, correlationIDs, goalStateDriver)
 	}
 
 	// EnqueueGangs partially failed, but transit enqueued tasks to PENDING
@@ -227,7 +267,8 @@ func sendTasksToResMgr(
 		"exist_count":   len(existInstIDs),
 	}).Info("Resource manager enqueued tasks with failures")
 
-	_ = transitTasksToPending(ctx, jobID, enquedIDs, goalStateDriver)
+	_ = transitTasksToPending(
+		ctx, jobID, enquedIDs, correlationIDs, goalStateDriver)
 	return yarpcerrors.InternalErrorf("resource manager enqueue gang failed tasks %v", len(unenquedInstIDs))
 }
 
@@ -249,7 +290,10 @@ func recoverTasks(
 				JobId:      jobID,
 				InstanceId: i,
 				Runtime:    taskInfos[i].GetRuntime(),
-				Config:     taskconfig.Merge(jobConfig.GetDefaultConfig(), jobConfig.GetInstanceConfig()[i]),
+				Config: taskconfig.MergeWithStrategy(
+					jobConfig.GetDefaultConfig(),
+					jobConfig.GetInstanceConfig()[i],
+					jobConfig.GetInstanceConfigMergeStrategy()),
 			}
 
 			if taskInfos[i].GetRuntime().GetState() == task.TaskState_INITIALIZED {
@@ -291,7 +335,10 @@ func recoverTasks(
 				JobId:      jobID,
 				InstanceId: i,
 				Runtime:    runtime,
-				Config:     taskconfig.Merge(jobConfig.GetDefaultConfig(), jobConfig.GetInstanceConfig()[i]),
+				Config: taskconfig.MergeWithStrategy(
+					jobConfig.GetDefaultConfig(),
+					jobConfig.GetInstanceConfig()[i],
+					jobConfig.GetInstanceConfigMergeStrategy()),
 			}
 			tasks = append(tasks, taskInfo)
 		}
@@ -332,7 +379,10 @@ func createAndEnqueueTasks(
 			JobId:      jobID,
 			InstanceId: i,
 			Runtime:    runtime,
-			Config:     taskconfig.Merge(jobConfig.GetDefaultConfig(), jobConfig.GetInstanceConfig()[i]),
+			Config: taskconfig.MergeWithStrategy(
+				jobConfig.GetDefaultConfig(),
+				jobConfig.GetInstanceConfig()[i],
+				jobConfig.GetInstanceConfigMergeStrategy()),
 		}
 	}
 
@@ -361,11 +411,13 @@ func createAndEnqueueTasks(
 	return sendTasksToResMgr(ctx, jobID, tasks, jobConfig, goalStateDriver)
 }
 
-// transitTasksToPending moves tasks state to PENDING
+// transitTasksToPending moves tasks state to PENDING, along with
+// persisting the launch correlation IDs generated for the tasks.
 func transitTasksToPending(
 	ctx context.Context,
 	jobID *peloton.JobID,
 	instanceIDs []uint32,
+	correlationIDs map[uint32]string,
 	goalStateDriver *driver) error {
 	if len(instanceIDs) == 0 {
 		return nil
@@ -377,6 +429,9 @@ func transitTasksToPending(
 			jobmgrcommon.StateField:   task.TaskState_PENDING,
 			jobmgrcommon.MessageField: "Task sent for placement",
 		}
+		if id, ok := correlationIDs[instID]; ok {
+			runtimeDiff[jobmgrcommon.LaunchCorrelationIDField] = id
+		}
 		runtimeDiffs[instID] = runtimeDiff
 	}
 
@@ -61,6 +61,7 @@ type JobCreateTestSuite struct {
 	instanceCount       uint32
 	jobConfig           *pbjob.JobConfig
 	jobConfigOps        *objectmocks.MockJobConfigOps
+	overrideOps         *objectmocks.MockInstanceOverrideOps
 }
 
 func TestJobCreateRun(t *testing.T) {
@@ -75,6 +76,12 @@ func (suite *JobCreateTestSuite) SetupTest() {
 	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
 	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
 	suite.resmgrClient = resmocks.NewMockResourceManagerServiceYARPCClient(suite.ctrl)
+	// most tests do not care about instance overrides
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
 	suite.goalStateDriver = &driver{
 		jobFactory:   suite.jobFactory,
 		jobEngine:    suite.jobGoalStateEngine,
@@ -84,6 +91,8 @@ func (suite *JobCreateTestSuite) SetupTest() {
 		cfg:          &Config{},
 		resmgrClient: suite.resmgrClient,
 		jobConfigOps: suite.jobConfigOps,
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 	suite.goalStateDriver.cfg.normalize()
 
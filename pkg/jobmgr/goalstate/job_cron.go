@@ -0,0 +1,355 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/cron"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// _defaultCronHistoryLimit is the number of runs kept for a cron job
+// which does not set a history limit.
+const _defaultCronHistoryLimit = 10
+
+// jobCron evaluates a cron job. Instead of creating tasks, a cron job
+// creates a new batch job at each time of its schedule, subject to its
+// concurrency policy, and deletes its oldest terminated runs beyond its
+// history limit. The job stays in INITIALIZED state and is evaluated
+// again at the next time of its schedule.
+func jobCron(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig *job.JobConfig,
+	configAddOn *models.ConfigAddOn,
+	goalStateDriver *driver,
+) error {
+	jobID := cachedJob.ID()
+
+	schedule, err := cron.Parse(jobConfig.GetCronConfig().GetSchedule())
+	if err != nil {
+		// the schedule is validated when the job is created or updated,
+		// evaluating the job again would not help
+		goalStateDriver.mtx.jobMetrics.JobCronRunFailed.Inc(1)
+		log.WithError(err).
+			WithField("job_id", jobID.GetValue()).
+			Error("invalid schedule of cron job")
+		return nil
+	}
+
+	jobRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return err
+	}
+
+	due, next := getCronRunTimes(schedule, jobRuntime, time.Now())
+	if !due.IsZero() && goalStateDriver.isFrozen() {
+		// the run stays due until its last schedule time is recorded,
+		// so it is created once the cluster is unfrozen
+		goalStateDriver.mtx.jobMetrics.JobCronRunFrozen.Inc(1)
+		goalStateDriver.EnqueueJob(
+			jobID,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		return nil
+	}
+
+	if !due.IsZero() {
+		if err := runCronJob(
+			ctx,
+			cachedJob,
+			jobConfig,
+			configAddOn,
+			jobRuntime.GetCronStatus(),
+			due,
+			goalStateDriver,
+		); err != nil {
+			goalStateDriver.mtx.jobMetrics.JobCronRunFailed.Inc(1)
+			log.WithError(err).
+				WithField("job_id", jobID.GetValue()).
+				WithField("scheduled_time", due).
+				Error("failed to run cron job")
+			return err
+		}
+	}
+
+	if !next.IsZero() {
+		goalStateDriver.EnqueueJob(jobID, next)
+	}
+	return nil
+}
+
+// getCronRunTimes returns the scheduled time of the run which is due at
+// the given time, if any, and the next scheduled time after it. Runs
+// missed while no job manager was leader are not caught up, only the
+// most recent one is returned.
+func getCronRunTimes(
+	schedule *cron.Schedule,
+	jobRuntime *job.RuntimeInfo,
+	now time.Time,
+) (due time.Time, next time.Time) {
+	// the first run is scheduled after the creation of the job
+	last, err := time.Parse(
+		time.RFC3339Nano, jobRuntime.GetCronStatus().GetLastScheduleTime())
+	if err != nil {
+		last, err = time.Parse(time.RFC3339Nano, jobRuntime.GetCreationTime())
+		if err != nil {
+			last = now
+		}
+	}
+
+	next = schedule.Next(last)
+	for !next.IsZero() && !next.After(now) {
+		due = next
+		next = schedule.Next(next)
+	}
+	return due, next
+}
+
+// runCronJob handles a scheduled time of a cron job: it applies the
+// concurrency policy of the job, creates the run, deletes the runs beyond
+// the history limit and records the new status of the runs in the job
+// runtime.
+func runCronJob(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig *job.JobConfig,
+	configAddOn *models.ConfigAddOn,
+	cronStatus *job.CronStatus,
+	scheduledTime time.Time,
+	goalStateDriver *driver,
+) error {
+	cronConfig := jobConfig.GetCronConfig()
+	newStatus := &job.CronStatus{
+		LastScheduleTime: scheduledTime.Format(time.RFC3339),
+		SkippedRuns:      cronStatus.GetSkippedRuns(),
+	}
+
+	var activeRuns []*peloton.JobID
+	terminatedRuns := make(map[string]bool)
+	for _, run := range cronStatus.GetRuns() {
+		runRuntime, err := handlerutil.GetJobRuntimeWithoutFillingCache(
+			ctx,
+			run.GetJobId(),
+			goalStateDriver.jobFactory,
+			goalStateDriver.jobRuntimeOps,
+		)
+		if yarpcerrors.IsNotFound(err) {
+			// the run has been deleted
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		newStatus.Runs = append(newStatus.Runs, run)
+		if util.IsPelotonJobStateTerminal(runRuntime.GetState()) {
+			terminatedRuns[run.GetJobId().GetValue()] = true
+		} else {
+			activeRuns = append(activeRuns, run.GetJobId())
+		}
+	}
+
+	if len(activeRuns) > 0 && cronConfig.GetConcurrencyPolicy() ==
+		job.CronConcurrencyPolicy_CRON_CONCURRENCY_POLICY_FORBID {
+		newStatus.SkippedRuns++
+		goalStateDriver.mtx.jobMetrics.JobCronRunSkipped.Inc(1)
+		log.WithField("job_id", cachedJob.ID().GetValue()).
+			WithField("scheduled_time", scheduledTime).
+			WithField("active_runs", len(activeRuns)).
+			Info("skipped cron run since previous runs are still active")
+	} else {
+		if cronConfig.GetConcurrencyPolicy() ==
+			job.CronConcurrencyPolicy_CRON_CONCURRENCY_POLICY_REPLACE {
+			for _, runID := range activeRuns {
+				if err := killCronRun(ctx, runID, goalStateDriver); err != nil {
+					return err
+				}
+			}
+		}
+
+		runID, err := createCronRun(
+			ctx,
+			cachedJob.ID(),
+			jobConfig,
+			configAddOn,
+			scheduledTime,
+			goalStateDriver,
+		)
+		if err != nil {
+			return err
+		}
+		newStatus.Runs = append(newStatus.Runs, &job.CronRun{
+			JobId:         runID,
+			ScheduledTime: scheduledTime.Format(time.RFC3339),
+		})
+	}
+
+	historyLimit := int(cronConfig.GetHistoryLimit())
+	if historyLimit == 0 {
+		historyLimit = _defaultCronHistoryLimit
+	}
+
+	// delete the oldest terminated runs beyond the history limit, the
+	// active ones are kept until a later evaluation
+	excess := len(newStatus.GetRuns()) - historyLimit
+	runs := newStatus.GetRuns()
+	newStatus.Runs = nil
+	for _, run := range runs {
+		if excess > 0 && terminatedRuns[run.GetJobId().GetValue()] {
+			if err := deleteCronRun(ctx, run.GetJobId(), goalStateDriver); err != nil {
+				return err
+			}
+			excess--
+			continue
+		}
+		newStatus.Runs = append(newStatus.Runs, run)
+	}
+
+	return cachedJob.Update(ctx, &job.JobInfo{
+		Runtime: &job.RuntimeInfo{CronStatus: newStatus},
+	}, nil,
+		nil,
+		cached.UpdateCacheAndDB)
+}
+
+// createCronRun creates the batch job run of a cron job for a scheduled
+// time, and returns its ID. The ID of a run is derived from the cron job
+// ID and the scheduled time, so a run which was created by a previous
+// evaluation that failed to record it is not created twice.
+func createCronRun(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	jobConfig *job.JobConfig,
+	configAddOn *models.ConfigAddOn,
+	scheduledTime time.Time,
+	goalStateDriver *driver,
+) (*peloton.JobID, error) {
+	runID := &peloton.JobID{
+		Value: uuid.NewSHA1(
+			uuid.Parse(jobID.GetValue()),
+			[]byte(scheduledTime.Format(time.RFC3339)),
+		).String(),
+	}
+
+	_, err := handlerutil.GetJobRuntimeWithoutFillingCache(
+		ctx, runID, goalStateDriver.jobFactory, goalStateDriver.jobRuntimeOps)
+	if err == nil {
+		return runID, nil
+	}
+	if !yarpcerrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	runConfig := proto.Clone(jobConfig).(*job.JobConfig)
+	runConfig.CronConfig = nil
+	runConfig.ChangeLog = nil
+
+	runConfigAddOn := &models.ConfigAddOn{}
+	if configAddOn != nil {
+		runConfigAddOn = proto.Clone(configAddOn).(*models.ConfigAddOn)
+	}
+	runConfigAddOn.SystemLabels = append(
+		runConfigAddOn.GetSystemLabels(),
+		jobutil.CronJobLabel(jobID.GetValue()),
+	)
+
+	runJob := goalStateDriver.jobFactory.AddJob(runID)
+	if err := runJob.Create(ctx, runConfig, runConfigAddOn, nil); err != nil {
+		return nil, err
+	}
+	goalStateDriver.EnqueueJob(runID, time.Now())
+
+	goalStateDriver.mtx.jobMetrics.JobCronRun.Inc(1)
+	log.WithField("job_id", jobID.GetValue()).
+		WithField("run_id", runID.GetValue()).
+		WithField("scheduled_time", scheduledTime).
+		Info("created cron run")
+	return runID, nil
+}
+
+// killCronRun sets the goal state of an active run of a cron job to
+// KILLED.
+func killCronRun(
+	ctx context.Context,
+	runID *peloton.JobID,
+	goalStateDriver *driver,
+) error {
+	runJob := goalStateDriver.jobFactory.AddJob(runID)
+	for count := 0; ; count++ {
+		runRuntime, err := runJob.GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+
+		if runRuntime.GetGoalState() == job.JobState_KILLED {
+			return nil
+		}
+
+		runRuntime.DesiredStateVersion++
+		runRuntime.GoalState = job.JobState_KILLED
+
+		_, err = runJob.CompareAndSetRuntime(ctx, runRuntime)
+		if err == nil {
+			break
+		}
+		if err != jobmgrcommon.UnexpectedVersionError ||
+			count+1 >= jobmgrcommon.MaxConcurrencyErrorRetry {
+			return err
+		}
+	}
+	goalStateDriver.EnqueueJob(runID, time.Now())
+
+	goalStateDriver.mtx.jobMetrics.JobCronRunKilled.Inc(1)
+	log.WithField("run_id", runID.GetValue()).
+		Info("killed cron run replaced by a new run")
+	return nil
+}
+
+// deleteCronRun deletes a terminated run of a cron job from the cache,
+// the goal state engine and the DB.
+func deleteCronRun(
+	ctx context.Context,
+	runID *peloton.JobID,
+	goalStateDriver *driver,
+) error {
+	runJob := goalStateDriver.jobFactory.AddJob(runID)
+	if err := runJob.Delete(ctx); err != nil {
+		return err
+	}
+
+	for instID := range runJob.GetAllTasks() {
+		goalStateDriver.DeleteTask(runID, instID)
+	}
+	goalStateDriver.DeleteJob(runID)
+	goalStateDriver.jobFactory.ClearJob(runID)
+
+	goalStateDriver.mtx.jobMetrics.JobCronRunDeleted.Inc(1)
+	return nil
+}
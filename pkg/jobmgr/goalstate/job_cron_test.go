@@ -0,0 +1,444 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/cron"
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	ormmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type jobCronTestSuite struct {
+	suite.Suite
+
+	ctrl                *gomock.Controller
+	jobGoalStateEngine  *goalstatemocks.MockEngine
+	taskGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory          *cachedmocks.MockJobFactory
+	jobConfigOps        *ormmocks.MockJobConfigOps
+	jobRuntimeOps       *ormmocks.MockJobRuntimeOps
+	goalStateDriver     *driver
+	jobID               *peloton.JobID
+	jobEnt              *jobEntity
+	cachedJob           *cachedmocks.MockJob
+	runJob              *cachedmocks.MockJob
+	jobConfig           *job.JobConfig
+}
+
+func TestJobCron(t *testing.T) {
+	suite.Run(t, new(jobCronTestSuite))
+}
+
+func (suite *jobCronTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.jobConfigOps = ormmocks.NewMockJobConfigOps(suite.ctrl)
+	suite.jobRuntimeOps = ormmocks.NewMockJobRuntimeOps(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		jobEngine:     suite.jobGoalStateEngine,
+		taskEngine:    suite.taskGoalStateEngine,
+		jobFactory:    suite.jobFactory,
+		jobConfigOps:  suite.jobConfigOps,
+		jobRuntimeOps: suite.jobRuntimeOps,
+		mtx:           NewMetrics(tally.NoopScope),
+		cfg:           &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.jobEnt = &jobEntity{
+		id:     suite.jobID,
+		driver: suite.goalStateDriver,
+	}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.runJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.jobConfig = &job.JobConfig{
+		Name:          "cron-job",
+		Type:          job.JobType_BATCH,
+		InstanceCount: 1,
+		CronConfig: &job.CronConfig{
+			Schedule: "@hourly",
+		},
+	}
+
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	// runs are never in the cache, their runtime is read from the DB
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob).
+		AnyTimes()
+}
+
+func (suite *jobCronTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectJobCreateTasks sets up the expectations to evaluate the cron job
+// through JobCreateTasks, given its runtime.
+func (suite *jobCronTestSuite) expectJobCreateTasks(runtime *job.RuntimeInfo) {
+	suite.jobConfigOps.EXPECT().
+		GetResultCurrentVersion(gomock.Any(), suite.jobID).
+		Return(&ormobjects.JobConfigOpsResult{
+			JobConfig:   suite.jobConfig,
+			ConfigAddOn: &models.ConfigAddOn{},
+		}, nil)
+
+	suite.jobFactory.EXPECT().
+		GetJob(gomock.Not(suite.jobID)).
+		Return(nil).
+		AnyTimes()
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(runtime, nil)
+}
+
+// expectRunState sets up the state of an existing run in the DB.
+func (suite *jobCronTestSuite) expectRunState(
+	runID *peloton.JobID,
+	state job.JobState,
+) {
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), runID).
+		Return(&job.RuntimeInfo{State: state}, nil)
+}
+
+// expectRunCreated sets up the expectations to create a new run.
+func (suite *jobCronTestSuite) expectRunCreated() {
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+
+	suite.jobFactory.EXPECT().
+		AddJob(gomock.Any()).
+		Return(suite.runJob)
+
+	suite.runJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any(), nil).
+		Do(func(
+			_ context.Context,
+			config *job.JobConfig,
+			configAddOn *models.ConfigAddOn,
+			_ interface{}) {
+			suite.Nil(config.GetCronConfig())
+			suite.Equal(suite.jobConfig.GetName(), config.GetName())
+			suite.Equal(
+				[]*peloton.Label{jobutil.CronJobLabel(suite.jobID.GetValue())},
+				configAddOn.GetSystemLabels())
+		}).
+		Return(nil)
+}
+
+// expectCronStatus checks the cron status written to the job runtime.
+func (suite *jobCronTestSuite) expectCronStatus(
+	check func(status *job.CronStatus),
+) {
+	suite.cachedJob.EXPECT().
+		Update(gomock.Any(), gomock.Any(), nil, nil, cached.UpdateCacheAndDB).
+		Do(func(
+			_ context.Context,
+			jobInfo *job.JobInfo,
+			_ *models.ConfigAddOn,
+			_ interface{},
+			_ cached.UpdateRequest) {
+			check(jobInfo.GetRuntime().GetCronStatus())
+		}).
+		Return(nil)
+}
+
+// TestJobCronNotDue tests that a cron job whose next run is not due yet
+// is only evaluated again at its next scheduled time
+func (suite *jobCronTestSuite) TestJobCronNotDue() {
+	creationTime := time.Now().UTC()
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:        job.JobState_INITIALIZED,
+		GoalState:    job.JobState_SUCCEEDED,
+		CreationTime: creationTime.Format(time.RFC3339Nano),
+	})
+
+	schedule, err := cron.Parse("@hourly")
+	suite.NoError(err)
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), schedule.Next(creationTime))
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronCreateRun tests that a due cron job creates a new run
+func (suite *jobCronTestSuite) TestJobCronCreateRun() {
+	oldRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+			Runs: []*job.CronRun{{JobId: oldRunID}},
+		},
+	})
+	suite.expectRunState(oldRunID, job.JobState_SUCCEEDED)
+	suite.expectRunCreated()
+
+	// the new run and the cron job are both enqueued
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(2)
+
+	suite.expectCronStatus(func(status *job.CronStatus) {
+		suite.Len(status.GetRuns(), 2)
+		suite.Equal(oldRunID, status.GetRuns()[0].GetJobId())
+		suite.Equal(
+			status.GetLastScheduleTime(),
+			status.GetRuns()[1].GetScheduledTime())
+		suite.Zero(status.GetSkippedRuns())
+	})
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronFrozen tests that a due cron job does not create a new run,
+// but is evaluated again later, while the cluster is frozen
+func (suite *jobCronTestSuite) TestJobCronFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+		},
+	})
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.After(time.Now().Add(20 * time.Second)))
+		})
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronForbid tests that a run is skipped while a previous run is
+// active if the concurrency policy forbids it
+func (suite *jobCronTestSuite) TestJobCronForbid() {
+	suite.jobConfig.GetCronConfig().ConcurrencyPolicy =
+		job.CronConcurrencyPolicy_CRON_CONCURRENCY_POLICY_FORBID
+
+	activeRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+			Runs: []*job.CronRun{{JobId: activeRunID}},
+		},
+	})
+	suite.expectRunState(activeRunID, job.JobState_RUNNING)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.expectCronStatus(func(status *job.CronStatus) {
+		suite.Len(status.GetRuns(), 1)
+		suite.Equal(uint32(1), status.GetSkippedRuns())
+	})
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronReplace tests that the active runs are killed when a new run
+// is created if the concurrency policy is replace
+func (suite *jobCronTestSuite) TestJobCronReplace() {
+	suite.jobConfig.GetCronConfig().ConcurrencyPolicy =
+		job.CronConcurrencyPolicy_CRON_CONCURRENCY_POLICY_REPLACE
+
+	activeRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	activeRunJob := cachedmocks.NewMockJob(suite.ctrl)
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+			Runs: []*job.CronRun{{JobId: activeRunID}},
+		},
+	})
+	suite.expectRunState(activeRunID, job.JobState_RUNNING)
+
+	suite.jobFactory.EXPECT().
+		AddJob(activeRunID).
+		Return(activeRunJob)
+	activeRunJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:               job.JobState_RUNNING,
+			GoalState:           job.JobState_SUCCEEDED,
+			DesiredStateVersion: 1,
+		}, nil)
+	activeRunJob.EXPECT().
+		CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, runtime *job.RuntimeInfo) {
+			suite.Equal(job.JobState_KILLED, runtime.GetGoalState())
+			suite.Equal(uint64(2), runtime.GetDesiredStateVersion())
+		}).
+		Return(nil, nil)
+
+	suite.expectRunCreated()
+
+	// the killed run, the new run and the cron job are enqueued
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(3)
+
+	suite.expectCronStatus(func(status *job.CronStatus) {
+		suite.Len(status.GetRuns(), 2)
+	})
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronHistoryLimit tests that the oldest terminated runs beyond the
+// history limit are deleted, while active runs are kept
+func (suite *jobCronTestSuite) TestJobCronHistoryLimit() {
+	suite.jobConfig.GetCronConfig().HistoryLimit = 2
+
+	activeRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	oldRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	deletedRunID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	oldRunJob := cachedmocks.NewMockJob(suite.ctrl)
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+			Runs: []*job.CronRun{
+				{JobId: activeRunID},
+				{JobId: deletedRunID},
+				{JobId: oldRunID},
+			},
+		},
+	})
+	suite.expectRunState(activeRunID, job.JobState_RUNNING)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), deletedRunID).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+	suite.expectRunState(oldRunID, job.JobState_FAILED)
+	suite.expectRunCreated()
+
+	suite.jobFactory.EXPECT().
+		AddJob(oldRunID).
+		Return(oldRunJob)
+	oldRunJob.EXPECT().
+		Delete(gomock.Any()).
+		Return(nil)
+	oldRunJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: cachedmocks.NewMockTask(suite.ctrl)})
+	suite.taskGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+	suite.jobGoalStateEngine.EXPECT().
+		Delete(gomock.Any())
+	suite.jobFactory.EXPECT().
+		ClearJob(oldRunID)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(2)
+
+	suite.expectCronStatus(func(status *job.CronStatus) {
+		suite.Len(status.GetRuns(), 2)
+		suite.Equal(activeRunID, status.GetRuns()[0].GetJobId())
+	})
+
+	suite.NoError(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestJobCronUpdateFailure tests that the cron job is evaluated again if
+// its runtime cannot be updated
+func (suite *jobCronTestSuite) TestJobCronUpdateFailure() {
+	suite.expectJobCreateTasks(&job.RuntimeInfo{
+		State:     job.JobState_INITIALIZED,
+		GoalState: job.JobState_SUCCEEDED,
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: time.Now().Add(-2 * time.Hour).
+				UTC().Format(time.RFC3339),
+		},
+	})
+	suite.expectRunCreated()
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.cachedJob.EXPECT().
+		Update(gomock.Any(), gomock.Any(), nil, nil, cached.UpdateCacheAndDB).
+		Return(yarpcerrors.UnavailableErrorf("test error"))
+
+	suite.Error(JobCreateTasks(context.Background(), suite.jobEnt))
+}
+
+// TestGetCronRunTimes tests the computation of the due and next scheduled
+// times of a cron job
+func (suite *jobCronTestSuite) TestGetCronRunTimes() {
+	schedule, err := cron.Parse("0 * * * *")
+	suite.NoError(err)
+
+	now := time.Date(2019, 1, 1, 10, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time {
+		return time.Date(2019, 1, 1, h, 0, 0, 0, time.UTC)
+	}
+
+	// first run after creation
+	due, next := getCronRunTimes(schedule, &job.RuntimeInfo{
+		CreationTime: hour(10).Add(time.Minute).Format(time.RFC3339Nano),
+	}, now)
+	suite.True(due.IsZero())
+	suite.Equal(hour(11), next)
+
+	// only the most recent of the missed runs is due
+	due, next = getCronRunTimes(schedule, &job.RuntimeInfo{
+		CreationTime: hour(1).Format(time.RFC3339Nano),
+		CronStatus: &job.CronStatus{
+			LastScheduleTime: hour(7).Format(time.RFC3339),
+		},
+	}, now)
+	suite.Equal(hour(10), due)
+	suite.Equal(hour(11), next)
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// dependencyTracker tracks the jobs which are blocked on the jobs they
+// depend on, so that they are evaluated again as soon as one of their
+// dependencies terminates. It is only kept in memory: blocked jobs are
+// evaluated again after a leader change and register themselves again.
+// The zero value is ready to use.
+type dependencyTracker struct {
+	sync.Mutex
+
+	// dependents maps the ID of a job to the IDs of the jobs blocked on it
+	dependents map[string]map[string]struct{}
+}
+
+// add registers a job as blocked on its dependencies.
+func (t *dependencyTracker) add(jobID string, dependencies []*peloton.JobID) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.dependents == nil {
+		t.dependents = make(map[string]map[string]struct{})
+	}
+	for _, dependency := range dependencies {
+		dependents, ok := t.dependents[dependency.GetValue()]
+		if !ok {
+			dependents = make(map[string]struct{})
+			t.dependents[dependency.GetValue()] = dependents
+		}
+		dependents[jobID] = struct{}{}
+	}
+}
+
+// remove unregisters a job which is not blocked anymore.
+func (t *dependencyTracker) remove(jobID string, dependencies []*peloton.JobID) {
+	t.Lock()
+	defer t.Unlock()
+
+	for _, dependency := range dependencies {
+		dependents := t.dependents[dependency.GetValue()]
+		delete(dependents, jobID)
+		if len(dependents) == 0 {
+			delete(t.dependents, dependency.GetValue())
+		}
+	}
+}
+
+// release returns the IDs of the jobs blocked on a job which has
+// terminated, and stops tracking them for that job.
+func (t *dependencyTracker) release(jobID string) []string {
+	t.Lock()
+	defer t.Unlock()
+
+	var released []string
+	for dependent := range t.dependents[jobID] {
+		released = append(released, dependent)
+	}
+	delete(t.dependents, jobID)
+	return released
+}
+
+// releaseDependents enqueues the jobs blocked on a job which has reached
+// a terminal state, so that they check their dependencies again.
+func (d *driver) releaseDependents(jobID *peloton.JobID) {
+	for _, dependent := range d.dependencies.release(jobID.GetValue()) {
+		d.EnqueueJob(&peloton.JobID{Value: dependent}, time.Now())
+	}
+}
+
+// checkJobDependencies checks whether the jobs which a job depends on have
+// all succeeded, in which case the tasks of the job can be created. While
+// any of them is active, the job is moved to BLOCKED state and evaluated
+// again when they terminate. If any of them terminated without succeeding,
+// the job can never run and is killed. It returns true if the tasks of the
+// job must not be created yet.
+func checkJobDependencies(
+	ctx context.Context,
+	cachedJob cached.Job,
+	jobConfig *job.JobConfig,
+	goalStateDriver *driver,
+) (bool, error) {
+	jobID := cachedJob.ID()
+	dependencies := jobConfig.GetDependencies()
+
+	// register the job before checking its dependencies, so that a
+	// dependency terminating in between is not missed
+	goalStateDriver.dependencies.add(jobID.GetValue(), dependencies)
+
+	var failed, pending []string
+	for _, dependency := range dependencies {
+		runtime, err := handlerutil.GetJobRuntimeWithoutFillingCache(
+			ctx,
+			dependency,
+			goalStateDriver.jobFactory,
+			goalStateDriver.jobRuntimeOps,
+		)
+		if yarpcerrors.IsNotFound(err) {
+			// the dependency has been deleted, it can't succeed anymore
+			failed = append(failed, dependency.GetValue())
+			continue
+		}
+		if err != nil {
+			return true, err
+		}
+
+		switch state := runtime.GetState(); {
+		case state == job.JobState_SUCCEEDED:
+		case util.IsPelotonJobStateTerminal(state):
+			failed = append(failed, dependency.GetValue())
+		default:
+			pending = append(pending, dependency.GetValue())
+		}
+	}
+
+	if len(failed) == 0 && len(pending) == 0 {
+		goalStateDriver.dependencies.remove(jobID.GetValue(), dependencies)
+		return false, nil
+	}
+
+	jobRuntime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return true, err
+	}
+
+	if len(failed) > 0 {
+		goalStateDriver.dependencies.remove(jobID.GetValue(), dependencies)
+		if err := cachedJob.Update(ctx, &job.JobInfo{
+			Runtime: &job.RuntimeInfo{
+				GoalState:           job.JobState_KILLED,
+				DesiredStateVersion: jobRuntime.GetDesiredStateVersion() + 1,
+			},
+		}, nil,
+			nil,
+			cached.UpdateCacheAndDB); err != nil {
+			return true, err
+		}
+		goalStateDriver.EnqueueJob(jobID, time.Now())
+
+		goalStateDriver.mtx.jobMetrics.JobDependencyFailed.Inc(1)
+		log.WithField("job_id", jobID.GetValue()).
+			WithField("failed_dependencies", failed).
+			Info("killing job since some of its dependencies did not succeed")
+		return true, nil
+	}
+
+	if jobRuntime.GetState() != job.JobState_BLOCKED {
+		if err := cachedJob.Update(ctx, &job.JobInfo{
+			Runtime: &job.RuntimeInfo{
+				State: job.JobState_BLOCKED,
+			},
+		}, nil,
+			nil,
+			cached.UpdateCacheAndDB); err != nil {
+			return true, err
+		}
+
+		goalStateDriver.mtx.jobMetrics.JobBlocked.Inc(1)
+		log.WithField("job_id", jobID.GetValue()).
+			WithField("pending_dependencies", pending).
+			Info("job blocked on its dependencies")
+	}
+	return true, nil
+}
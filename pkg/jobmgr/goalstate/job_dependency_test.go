@@ -0,0 +1,238 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	ormmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type jobDependencyTestSuite struct {
+	suite.Suite
+
+	ctrl               *gomock.Controller
+	jobGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory         *cachedmocks.MockJobFactory
+	jobRuntimeOps      *ormmocks.MockJobRuntimeOps
+	goalStateDriver    *driver
+	jobID              *peloton.JobID
+	upstreamIDs        []*peloton.JobID
+	cachedJob          *cachedmocks.MockJob
+	jobConfig          *job.JobConfig
+}
+
+func TestJobDependency(t *testing.T) {
+	suite.Run(t, new(jobDependencyTestSuite))
+}
+
+func (suite *jobDependencyTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.jobRuntimeOps = ormmocks.NewMockJobRuntimeOps(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		jobEngine:     suite.jobGoalStateEngine,
+		jobFactory:    suite.jobFactory,
+		jobRuntimeOps: suite.jobRuntimeOps,
+		mtx:           NewMetrics(tally.NoopScope),
+		cfg:           &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.upstreamIDs = []*peloton.JobID{
+		{Value: uuid.NewRandom().String()},
+		{Value: uuid.NewRandom().String()},
+	}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.jobConfig = &job.JobConfig{
+		Type:          job.JobType_BATCH,
+		InstanceCount: 1,
+		Dependencies:  suite.upstreamIDs,
+	}
+
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	// the upstream jobs are not in the cache
+	suite.jobFactory.EXPECT().GetJob(gomock.Any()).Return(nil).AnyTimes()
+}
+
+func (suite *jobDependencyTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectUpstreamStates sets up the states of the upstream jobs in the DB.
+func (suite *jobDependencyTestSuite) expectUpstreamStates(
+	states ...job.JobState) {
+	for i, state := range states {
+		suite.jobRuntimeOps.EXPECT().
+			Get(gomock.Any(), suite.upstreamIDs[i]).
+			Return(&job.RuntimeInfo{State: state}, nil)
+	}
+}
+
+// TestDependencyTracker tests registering and releasing blocked jobs.
+func (suite *jobDependencyTestSuite) TestDependencyTracker() {
+	var tracker dependencyTracker
+
+	tracker.add("job-1", suite.upstreamIDs)
+	tracker.add("job-2", suite.upstreamIDs[:1])
+	suite.ElementsMatch(
+		[]string{"job-1", "job-2"},
+		tracker.release(suite.upstreamIDs[0].GetValue()))
+	suite.Empty(tracker.release(suite.upstreamIDs[0].GetValue()))
+
+	tracker.remove("job-1", suite.upstreamIDs)
+	suite.Empty(tracker.release(suite.upstreamIDs[1].GetValue()))
+	suite.Empty(tracker.dependents)
+}
+
+// TestDependenciesSucceeded tests that the tasks of a job are created once
+// all its dependencies have succeeded.
+func (suite *jobDependencyTestSuite) TestDependenciesSucceeded() {
+	suite.expectUpstreamStates(job.JobState_SUCCEEDED, job.JobState_SUCCEEDED)
+
+	blocked, err := checkJobDependencies(
+		context.Background(),
+		suite.cachedJob,
+		suite.jobConfig,
+		suite.goalStateDriver)
+	suite.NoError(err)
+	suite.False(blocked)
+	suite.Empty(suite.goalStateDriver.dependencies.dependents)
+}
+
+// TestDependenciesPending tests that a job is blocked while any of its
+// dependencies is active, and enqueued once it terminates.
+func (suite *jobDependencyTestSuite) TestDependenciesPending() {
+	suite.expectUpstreamStates(job.JobState_SUCCEEDED, job.JobState_RUNNING)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_INITIALIZED}, nil)
+	suite.cachedJob.EXPECT().
+		Update(gomock.Any(), gomock.Any(), nil, nil, cached.UpdateCacheAndDB).
+		Do(func(
+			_ context.Context,
+			jobInfo *job.JobInfo,
+			_ *models.ConfigAddOn,
+			_ interface{},
+			_ cached.UpdateRequest) {
+			suite.Equal(job.JobState_BLOCKED, jobInfo.GetRuntime().GetState())
+		}).
+		Return(nil)
+
+	blocked, err := checkJobDependencies(
+		context.Background(),
+		suite.cachedJob,
+		suite.jobConfig,
+		suite.goalStateDriver)
+	suite.NoError(err)
+	suite.True(blocked)
+
+	// the job is enqueued when the running dependency terminates
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(entity interface{}, _ interface{}) {
+			suite.Equal(suite.jobID.GetValue(), entity.(*jobEntity).id.GetValue())
+		})
+	suite.goalStateDriver.releaseDependents(suite.upstreamIDs[1])
+}
+
+// TestDependenciesPendingAlreadyBlocked tests that a blocked job is not
+// updated again while its dependencies are active.
+func (suite *jobDependencyTestSuite) TestDependenciesPendingAlreadyBlocked() {
+	suite.expectUpstreamStates(job.JobState_PENDING, job.JobState_RUNNING)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{State: job.JobState_BLOCKED}, nil)
+
+	blocked, err := checkJobDependencies(
+		context.Background(),
+		suite.cachedJob,
+		suite.jobConfig,
+		suite.goalStateDriver)
+	suite.NoError(err)
+	suite.True(blocked)
+}
+
+// TestDependenciesFailed tests that a job is killed if any of its
+// dependencies terminated without succeeding, or has been deleted.
+func (suite *jobDependencyTestSuite) TestDependenciesFailed() {
+	suite.expectUpstreamStates(job.JobState_FAILED)
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.upstreamIDs[1]).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found"))
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:               job.JobState_BLOCKED,
+			DesiredStateVersion: 1,
+		}, nil)
+	suite.cachedJob.EXPECT().
+		Update(gomock.Any(), gomock.Any(), nil, nil, cached.UpdateCacheAndDB).
+		Do(func(
+			_ context.Context,
+			jobInfo *job.JobInfo,
+			_ *models.ConfigAddOn,
+			_ interface{},
+			_ cached.UpdateRequest) {
+			suite.Equal(job.JobState_KILLED, jobInfo.GetRuntime().GetGoalState())
+			suite.Equal(uint64(2), jobInfo.GetRuntime().GetDesiredStateVersion())
+		}).
+		Return(nil)
+	suite.jobGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	blocked, err := checkJobDependencies(
+		context.Background(),
+		suite.cachedJob,
+		suite.jobConfig,
+		suite.goalStateDriver)
+	suite.NoError(err)
+	suite.True(blocked)
+	suite.Empty(suite.goalStateDriver.dependencies.dependents)
+}
+
+// TestDependenciesRuntimeError tests that an error reading the runtime of
+// a dependency is returned.
+func (suite *jobDependencyTestSuite) TestDependenciesRuntimeError() {
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), suite.upstreamIDs[0]).
+		Return(nil, yarpcerrors.InternalErrorf("test error"))
+
+	blocked, err := checkJobDependencies(
+		context.Background(),
+		suite.cachedJob,
+		suite.jobConfig,
+		suite.goalStateDriver)
+	suite.Error(err)
+	suite.True(blocked)
+}
@@ -56,12 +56,22 @@ func JobKill(ctx context.Context, entity goalstate.Entity) error {
 		EnqueueJobWithDefaultDelay(jobID, goalStateDriver, cachedJob)
 	}
 
+	if util.IsPelotonJobStateTerminal(jobState) {
+		goalStateDriver.releaseDependents(jobID)
+	}
+
 	log.WithField("job_id", id).
 		Info("initiated kill of all tasks in the job")
 	return nil
 }
 
+// _jobDeadlineExceededMessage is the message of the tasks killed because
+// their job exceeded its max completion time.
+const _jobDeadlineExceededMessage = "Job exceeded its max completion time"
+
 // createRuntimeDiffForKill creates the runtime diffs to kill the tasks in job.
+// The tasks of a job which exceeded its deadline are killed with a distinct
+// message and termination reason; jobRuntime may be nil otherwise.
 // it returns:
 // runtimeDiffNonTerminatedTasks which is used to kill non-terminated tasks,
 // runtimeDiffTerminatedTasks which is used to kill tasks already terminal
@@ -71,6 +81,7 @@ func JobKill(ctx context.Context, entity goalstate.Entity) error {
 func createRuntimeDiffForKill(
 	ctx context.Context,
 	cachedJob cached.Job,
+	jobRuntime *job.RuntimeInfo,
 ) (
 	runtimeDiffNonTerminatedTasks map[uint32]jobmgrcommon.RuntimeDiff,
 	runtimeDiffTerminatedTasks map[uint32]jobmgrcommon.RuntimeDiff,
@@ -81,6 +92,13 @@ func createRuntimeDiffForKill(
 	runtimeDiffTerminatedTasks = make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffAll = make(map[uint32]jobmgrcommon.RuntimeDiff)
 
+	message := "Task stop API request"
+	reason := task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_ON_REQUEST
+	if len(jobRuntime.GetDeadlineExceededTime()) != 0 {
+		message = _jobDeadlineExceededMessage
+		reason = task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED
+	}
+
 	tasks := cachedJob.GetAllTasks()
 	for instanceID, cachedTask := range tasks {
 		runtime, err := cachedTask.GetRuntime(ctx)
@@ -108,10 +126,10 @@ func createRuntimeDiffForKill(
 
 		runtimeDiff := jobmgrcommon.RuntimeDiff{
 			jobmgrcommon.GoalStateField: task.TaskState_KILLED,
-			jobmgrcommon.MessageField:   "Task stop API request",
+			jobmgrcommon.MessageField:   message,
 			jobmgrcommon.ReasonField:    "",
 			jobmgrcommon.TerminationStatusField: &task.TerminationStatus{
-				Reason: task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_ON_REQUEST,
+				Reason: reason,
 			},
 			jobmgrcommon.DesiredHostField: "",
 		}
@@ -136,7 +154,8 @@ func calculateJobState(
 	// should directly enter KILLED state
 	if len(runtimeDiffNonTerminatedTasks) == 0 {
 		if cachedJob.GetJobType() == job.JobType_BATCH &&
-			jobRuntime.GetState() != job.JobState_INITIALIZED {
+			jobRuntime.GetState() != job.JobState_INITIALIZED &&
+			jobRuntime.GetState() != job.JobState_BLOCKED {
 			return job.JobState_KILLING
 		}
 
@@ -167,7 +186,7 @@ func killJob(
 		return
 	}
 
-	runtimeDiffNonTerminatedTasks, allTasksMarked, err := stopTasks(ctx, cachedJob, goalStateDriver)
+	runtimeDiffNonTerminatedTasks, allTasksMarked, err := stopTasks(ctx, cachedJob, jobRuntime, goalStateDriver)
 	if err != nil {
 		err = errors.Wrap(err, "failed to update task runtimes to kill a job")
 		return
@@ -217,6 +236,7 @@ func killJob(
 func stopTasks(
 	ctx context.Context,
 	cachedJob cached.Job,
+	jobRuntime *job.RuntimeInfo,
 	goalStateDriver Driver,
 ) (
 	runtimeDiffNonTerminatedTasks map[uint32]jobmgrcommon.RuntimeDiff,
@@ -225,7 +245,7 @@ func stopTasks(
 ) {
 	// Update task runtimes in DB and cache to kill task
 	runtimeDiffNonTerminatedTasks, _, runtimeDiffAll, err :=
-		createRuntimeDiffForKill(ctx, cachedJob)
+		createRuntimeDiffForKill(ctx, cachedJob, jobRuntime)
 	if err != nil {
 		return nil, false, err
 	}
@@ -17,8 +17,11 @@ package goalstate
 import (
 	"context"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -48,6 +51,10 @@ const (
 	transitionTypeTerminalActive transitionType = 3
 )
 
+// Mesos event message that indicates the artifacts of a task
+// could not be fetched
+const _msgMesosFetchFailure = "Failed to fetch"
+
 // taskStatesAfterStart is the set of Peloton task states which
 // indicate a task is being or has already been started.
 var taskStatesAfterStart = []task.TaskState{
@@ -199,7 +206,10 @@ func JobEvaluateMaxRunningInstancesSLA(ctx context.Context, entity goalstate.Ent
 			JobId:      jobID,
 			InstanceId: instID,
 			Runtime:    taskRuntime,
-			Config:     taskconfig.Merge(jobConfig.GetDefaultConfig(), jobConfig.GetInstanceConfig()[instID]),
+			Config: taskconfig.MergeWithStrategy(
+				jobConfig.GetDefaultConfig(),
+				jobConfig.GetInstanceConfig()[instID],
+				jobConfig.GetInstanceConfigMergeStrategy()),
 		}
 
 		if goalStateDriver.IsScheduledTask(jobID, instID) {
@@ -376,18 +386,23 @@ func newControllerTaskJobStateDeterminer(
 	config jobmgrcommon.JobConfig,
 ) *controllerTaskJobStateDeterminer {
 	return &controllerTaskJobStateDeterminer{
-		cachedJob:       cachedJob,
-		batchDeterminer: newJobStateDeterminer(stateCounts, config),
+		cachedJob:             cachedJob,
+		batchDeterminer:       newJobStateDeterminer(stateCounts, config),
+		controllerInstanceIDs: cached.GetControllerInstanceIDs(config),
+		policy:                cached.GetControllerPolicy(config),
 	}
 }
 
 type controllerTaskJobStateDeterminer struct {
-	cachedJob       cached.Job
-	batchDeterminer *jobStateDeterminer
+	cachedJob             cached.Job
+	batchDeterminer       *jobStateDeterminer
+	controllerInstanceIDs []uint32
+	policy                job.ControllerPolicy
 }
 
 // If the job will be in terminal state, state of task would be determined by
-// controller task. Otherwise it would be de
+// the controller tasks according to the controller policy of the job.
+// Otherwise it would be determined by the batch job state determiner.
 func (d *controllerTaskJobStateDeterminer) getState(
 	ctx context.Context,
 	jobRuntime *job.RuntimeInfo,
@@ -400,29 +415,68 @@ func (d *controllerTaskJobStateDeterminer) getState(
 		return jobState, nil
 	}
 
-	// In job config validation, it makes sure controller
-	// task would be the first task
-	controllerTask, err := d.cachedJob.AddTask(ctx, 0)
-	if err != nil {
-		return job.JobState_UNKNOWN, err
-	}
+	var succeeded, failed uint32
+	for _, instanceID := range d.controllerInstanceIDs {
+		controllerTask, err := d.cachedJob.AddTask(ctx, instanceID)
+		if err != nil {
+			return job.JobState_UNKNOWN, err
+		}
 
-	controllerTaskRuntime, err := controllerTask.GetRuntime(ctx)
-	if err != nil {
-		return job.JobState_UNKNOWN, err
+		controllerTaskRuntime, err := controllerTask.GetRuntime(ctx)
+		if err != nil {
+			return job.JobState_UNKNOWN, err
+		}
+		// only terminal states are expected here, so a controller
+		// task which neither succeeded nor failed was killed
+		switch controllerTaskRuntime.GetState() {
+		case task.TaskState_SUCCEEDED:
+			succeeded++
+		case task.TaskState_LOST, task.TaskState_FAILED:
+			failed++
+		}
 	}
-	switch controllerTaskRuntime.GetState() {
-	case task.TaskState_SUCCEEDED:
-		return job.JobState_SUCCEEDED, nil
-	case task.TaskState_LOST:
-		fallthrough
-	case task.TaskState_FAILED:
-		return job.JobState_FAILED, nil
+
+	return getControllerJobState(
+		d.policy,
+		uint32(len(d.controllerInstanceIDs)),
+		succeeded,
+		failed,
+	), nil
+}
+
+// getControllerJobState returns the terminal state of a job given the
+// number of its controller tasks which succeeded and failed. The job is
+// killed if the policy neither makes it succeed nor fail.
+func getControllerJobState(
+	policy job.ControllerPolicy,
+	total uint32,
+	succeeded uint32,
+	failed uint32,
+) job.JobState {
+	switch policy {
+	case job.ControllerPolicy_CONTROLLER_POLICY_ANY_FAIL:
+		if failed > 0 {
+			return job.JobState_FAILED
+		}
+		if succeeded > 0 {
+			return job.JobState_SUCCEEDED
+		}
+	case job.ControllerPolicy_CONTROLLER_POLICY_MAJORITY:
+		if succeeded*2 > total {
+			return job.JobState_SUCCEEDED
+		}
+		if failed > 0 {
+			return job.JobState_FAILED
+		}
 	default:
-		// only terminal state would enter switch statement,
-		// so the state left must be KILLED
-		return job.JobState_KILLED, nil
+		if total > 0 && succeeded == total {
+			return job.JobState_SUCCEEDED
+		}
+		if failed > 0 {
+			return job.JobState_FAILED
+		}
 	}
+	return job.JobState_KILLED
 }
 
 // getTransitionType returns the type of state transition for this job.
@@ -502,6 +556,11 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	// a blocked job has no tasks until its dependencies succeed
+	if jobRuntime.GetState() == job.JobState_BLOCKED {
+		return nil
+	}
+
 	config, err := cachedJob.GetConfig(ctx)
 	if err != nil {
 		log.WithError(err).
@@ -511,6 +570,12 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	// a cron job has no tasks, its runs are separate jobs whose
+	// runtime is updated on their own
+	if cached.GetCronConfig(config) != nil {
+		return nil
+	}
+
 	err = cachedJob.RepopulateInstanceAvailabilityInfo(ctx)
 	if err != nil {
 		log.WithError(err).
@@ -520,7 +585,7 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
-	stateCounts, configVersionStateStats,
+	stateCounts, configVersionStateStats, failureStats,
 		err := getTaskStateSummaryForJobInCache(ctx, cachedJob, config)
 
 	var jobState job.JobState
@@ -545,11 +610,53 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	arrayStatus := getArrayStatus(cachedJob, config)
+
+	// kill the job if it has not completed by its deadline, otherwise
+	// evaluate it again once the deadline passes
+	deadlineExceeded := false
+	if deadline, ok := getJobDeadline(config, jobRuntime.GetStartTime()); ok &&
+		len(jobRuntime.GetDeadlineExceededTime()) == 0 &&
+		jobRuntime.GetGoalState() != job.JobState_KILLED &&
+		!util.IsPelotonJobStateTerminal(jobState) {
+		if time.Now().Before(deadline) {
+			goalStateDriver.EnqueueJob(jobID, deadline)
+		} else if goalStateDriver.isFrozen() {
+			// the job is killed once the cluster is unfrozen
+			goalStateDriver.mtx.jobMetrics.JobKillFrozen.Inc(1)
+			goalStateDriver.EnqueueJob(
+				jobID,
+				time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		} else {
+			deadlineExceeded = true
+		}
+	}
+
+	// kill the job if it has used up its resource usage budget
+	resourceUsage := cachedJob.GetResourceUsage()
+	budgetStatus, exceededResources := getResourceBudgetStatus(
+		config, jobRuntime, resourceUsage)
+	budgetExceeded := len(exceededResources) > 0 &&
+		budgetStatus.GetExceededTime() == "" &&
+		jobRuntime.GetGoalState() != job.JobState_KILLED &&
+		!util.IsPelotonJobStateTerminal(jobState)
+	if budgetExceeded && goalStateDriver.isFrozen() {
+		// the job is killed once the cluster is unfrozen
+		goalStateDriver.mtx.jobMetrics.JobKillFrozen.Inc(1)
+		goalStateDriver.EnqueueJob(
+			jobID,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		budgetExceeded = false
+	}
+
 	if jobRuntime.GetTaskStats() != nil &&
 		jobRuntime.GetTaskStatsByConfigurationVersion() != nil &&
 		reflect.DeepEqual(stateCounts, jobRuntime.GetTaskStats()) &&
 		reflect.DeepEqual(configVersionStateStats, jobRuntime.GetTaskStatsByConfigurationVersion()) &&
-		jobRuntime.GetState() == jobState {
+		reflect.DeepEqual(arrayStatus, jobRuntime.GetArrayStatus()) &&
+		reflect.DeepEqual(failureStats, jobRuntime.GetTaskFailureStats()) &&
+		jobRuntime.GetState() == jobState &&
+		!deadlineExceeded && !budgetExceeded {
 		log.WithField("job_id", id).
 			WithField("task_stats", stateCounts).
 			WithField("task_stats_by_configurationVersion", configVersionStateStats).
@@ -575,10 +682,39 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 
 	jobRuntimeUpdate.TaskStats = stateCounts
 
-	jobRuntimeUpdate.ResourceUsage = cachedJob.GetResourceUsage()
+	jobRuntimeUpdate.TaskFailureStats = failureStats
+
+	jobRuntimeUpdate.ResourceUsage = resourceUsage
+
+	if budgetExceeded {
+		log.WithField("job_id", id).
+			WithField("resource_usage", resourceUsage).
+			WithField("resource_usage_budget", config.GetSLA().GetResourceUsageBudget()).
+			WithField("exceeded_resources", exceededResources).
+			Info("job exceeded its resource usage budget, killing the job")
+		budgetStatus.ExceededTime = time.Now().UTC().Format(time.RFC3339Nano)
+		budgetStatus.ExceededResources = exceededResources
+		jobRuntimeUpdate.GoalState = job.JobState_KILLED
+		jobRuntimeUpdate.DesiredStateVersion = jobRuntime.GetDesiredStateVersion() + 1
+		goalStateDriver.mtx.jobMetrics.JobResourceBudgetExceeded.Inc(1)
+	}
+	jobRuntimeUpdate.ResourceBudget = budgetStatus
+
+	if deadlineExceeded {
+		log.WithField("job_id", id).
+			WithField("start_time", jobRuntime.GetStartTime()).
+			WithField("max_completion_time", cached.GetMaxCompletionTime(config)).
+			Info("job exceeded its max completion time, killing the job")
+		jobRuntimeUpdate.DeadlineExceededTime = time.Now().UTC().Format(time.RFC3339Nano)
+		jobRuntimeUpdate.GoalState = job.JobState_KILLED
+		jobRuntimeUpdate.DesiredStateVersion = jobRuntime.GetDesiredStateVersion() + 1
+		goalStateDriver.mtx.jobMetrics.JobDeadlineExceeded.Inc(1)
+	}
 
 	jobRuntimeUpdate.TaskStatsByConfigurationVersion = configVersionStateStats
 
+	jobRuntimeUpdate.ArrayStatus = arrayStatus
+
 	// add to active jobs list BEFORE writing state to job runtime table.
 	// Also write to active jobs list only when the job is being transitioned
 	// from a terminal to active state. For active to active transitions, we
@@ -610,13 +746,20 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 	// 1. job state is terminal and no more task updates will arrive, or
 	// 2. job is partially created and need to create additional tasks
 	// (we may have no additional tasks coming in when job is
-	// partially created)
+	// partially created), or
+	// 3. job has exceeded its resource usage budget or its deadline and
+	// needs to be killed
 	if util.IsPelotonJobStateTerminal(jobRuntimeUpdate.GetState()) ||
 		(cachedJob.IsPartiallyCreated(config) &&
-			!updateutil.HasUpdate(jobRuntime)) {
+			!updateutil.HasUpdate(jobRuntime)) ||
+		budgetExceeded || deadlineExceeded {
 		goalStateDriver.EnqueueJob(jobID, time.Now())
 	}
 
+	if util.IsPelotonJobStateTerminal(jobRuntimeUpdate.GetState()) {
+		goalStateDriver.releaseDependents(jobID)
+	}
+
 	log.WithField("job_id", id).
 		WithField("updated_state", jobState.String()).
 		Info("job runtime updater completed")
@@ -625,6 +768,87 @@ func JobRuntimeUpdater(ctx context.Context, entity goalstate.Entity) error {
 	return nil
 }
 
+// getResourceBudgetStatus returns the resource budget status of a job
+// given its current resource usage, along with the sorted list of resource
+// kinds whose usage exceeds the budget. It returns nil if the job does not
+// have a resource usage budget.
+func getResourceBudgetStatus(
+	config jobmgrcommon.JobConfig,
+	jobRuntime *job.RuntimeInfo,
+	resourceUsage map[string]float64,
+) (*job.ResourceBudgetStatus, []string) {
+	budget := config.GetSLA().GetResourceUsageBudget()
+	if len(budget) == 0 {
+		return nil, nil
+	}
+
+	status := &job.ResourceBudgetStatus{
+		Remaining:         make(map[string]float64),
+		ExceededTime:      jobRuntime.GetResourceBudget().GetExceededTime(),
+		ExceededResources: jobRuntime.GetResourceBudget().GetExceededResources(),
+	}
+	var exceededResources []string
+	for kind, limit := range budget {
+		remaining := limit - resourceUsage[kind]
+		if remaining < 0 {
+			exceededResources = append(exceededResources, kind)
+			remaining = 0
+		}
+		status.Remaining[kind] = remaining
+	}
+	sort.Strings(exceededResources)
+	return status, exceededResources
+}
+
+// getJobDeadline returns the time by which a job must complete, given
+// its start time. It returns false if the job has no max completion time
+// or has not started yet.
+func getJobDeadline(
+	config jobmgrcommon.JobConfig,
+	startTime string,
+) (time.Time, bool) {
+	maxCompletionTime := cached.GetMaxCompletionTime(config)
+	if maxCompletionTime == 0 || len(startTime) == 0 {
+		return time.Time{}, false
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, startTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start.Add(maxCompletionTime), true
+}
+
+// getArrayStatus returns the status of the elements of a job array
+// from the tasks in cache. It returns nil if the job is not a job array.
+func getArrayStatus(
+	cachedJob cached.Job,
+	config jobmgrcommon.JobConfig,
+) *job.JobArrayStatus {
+	if cached.GetArrayConfig(config) == nil {
+		return nil
+	}
+
+	status := &job.JobArrayStatus{}
+	for instanceID, taskInCache := range cachedJob.GetAllTasks() {
+		switch taskInCache.CurrentState().State {
+		case task.TaskState_SUCCEEDED:
+			status.Succeeded++
+		case task.TaskState_FAILED, task.TaskState_LOST:
+			status.Failed++
+			status.FailedElements = append(status.FailedElements, instanceID)
+		case task.TaskState_KILLED:
+			status.Killed++
+		default:
+			status.Active++
+		}
+	}
+	sort.Slice(status.FailedElements, func(i, j int) bool {
+		return status.FailedElements[i] < status.FailedElements[j]
+	})
+	return status
+}
+
 func getTotalInstanceCount(stateCounts map[string]uint32) uint32 {
 	totalInstanceCount := uint32(0)
 	for _, state := range task.TaskState_name {
@@ -679,13 +903,17 @@ func setCompletionTime(
 }
 
 // getTaskStateSummaryForJobInCache loop through tasks in cache one by one
-// to calculate the task states summary
+// to calculate the task states summary and the failure reasons summary,
 // and update the configuration version state map for stateless jobs
 func getTaskStateSummaryForJobInCache(ctx context.Context,
 	cachedJob cached.Job,
 	config jobmgrcommon.JobConfig,
-) (map[string]uint32, map[uint64]*job.RuntimeInfo_TaskStateStats, error) {
+) (map[string]uint32,
+	map[uint64]*job.RuntimeInfo_TaskStateStats,
+	*job.TaskFailureStats,
+	error) {
 	stateCounts := make(map[string]uint32)
+	failureStats := &job.TaskFailureStats{}
 	configVersionStateStats := make(map[uint64]*job.
 		RuntimeInfo_TaskStateStats)
 	for _, taskStatus := range task.TaskState_name {
@@ -693,12 +921,14 @@ func getTaskStateSummaryForJobInCache(ctx context.Context,
 	}
 
 	for _, taskinCache := range cachedJob.GetAllTasks() {
-		stateCounts[taskinCache.CurrentState().State.String()]++
+		currentState := taskinCache.CurrentState()
+		stateCounts[currentState.State.String()]++
+		addTaskFailure(failureStats, currentState)
 		// update the configuration version state map for stateless jobs
 		if config.GetType() == job.JobType_SERVICE {
 			runtime, err := taskinCache.GetRuntime(ctx)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 			if _, ok := configVersionStateStats[runtime.GetConfigVersion()]; !ok {
 				configVersionStateStats[runtime.GetConfigVersion()] = &job.RuntimeInfo_TaskStateStats{
@@ -708,5 +938,29 @@ func getTaskStateSummaryForJobInCache(ctx context.Context,
 			configVersionStateStats[runtime.GetConfigVersion()].StateStats[runtime.GetState().String()]++
 		}
 	}
-	return stateCounts, configVersionStateStats, nil
+	return stateCounts, configVersionStateStats, failureStats, nil
+}
+
+// addTaskFailure counts a failed or lost task in the failure reasons
+// summary of its job.
+func addTaskFailure(
+	failureStats *job.TaskFailureStats,
+	currentState cached.TaskStateVector,
+) {
+	switch currentState.State {
+	case task.TaskState_LOST:
+		failureStats.Lost++
+	case task.TaskState_FAILED:
+		switch {
+		case currentState.Reason ==
+			mesos.TaskStatus_REASON_CONTAINER_LIMITATION_MEMORY.String():
+			failureStats.Oom++
+		case strings.Contains(currentState.Message, _msgMesosFetchFailure):
+			failureStats.FetchFailure++
+		case currentState.ExitCode != 0:
+			failureStats.NonZeroExitCode++
+		default:
+			failureStats.Other++
+		}
+	}
 }
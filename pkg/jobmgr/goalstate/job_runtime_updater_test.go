@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -36,6 +37,8 @@ import (
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
 	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 
@@ -71,6 +74,7 @@ type JobRuntimeUpdaterTestSuite struct {
 	jobID                 *peloton.JobID
 	jobEnt                *jobEntity
 	lastUpdateTs          float64
+	overrideOps           *objectmocks.MockInstanceOverrideOps
 }
 
 func TestJobRuntimeUpdater(t *testing.T) {
@@ -92,6 +96,12 @@ func (suite *JobRuntimeUpdaterTestSuite) SetupTest() {
 	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
 	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
 	suite.cachedConfig = cachedmocks.NewMockJobConfigCache(suite.ctrl)
+	// most tests do not care about instance overrides
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
 	suite.goalStateDriver = &driver{
 		jobEngine:    suite.jobGoalStateEngine,
 		taskEngine:   suite.taskGoalStateEngine,
@@ -104,6 +114,8 @@ func (suite *JobRuntimeUpdaterTestSuite) SetupTest() {
 		resmgrClient: suite.resmgrClient,
 		mtx:          NewMetrics(tally.NoopScope),
 		cfg:          &Config{},
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
 	suite.jobEnt = &jobEntity{
@@ -116,6 +128,16 @@ func (suite *JobRuntimeUpdaterTestSuite) SetupTest() {
 	suite.cachedJob.EXPECT().
 		GetResourceUsage().Return(
 		jobmgrtask.CreateEmptyResourceUsageMap()).AnyTimes()
+	suite.cachedConfig.EXPECT().
+		GetArrayConfig().Return(nil).AnyTimes()
+	suite.cachedConfig.EXPECT().
+		GetControllerPolicy().
+		Return(pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED).
+		AnyTimes()
+	suite.cachedConfig.EXPECT().
+		GetMaxCompletionTime().Return(uint32(0)).AnyTimes()
+	suite.cachedConfig.EXPECT().
+		GetCronConfig().Return(nil).AnyTimes()
 }
 
 func (suite *JobRuntimeUpdaterTestSuite) TearDownTest() {
@@ -165,6 +187,31 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdaterNoConfig() {
 	suite.Error(err)
 }
 
+// TestJobRuntimeUpdaterCronJob tests that the runtime of a cron job,
+// which has no tasks, is not updated
+func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdaterCronJob() {
+	jobRuntime := pbjob.RuntimeInfo{
+		State:     pbjob.JobState_INITIALIZED,
+		GoalState: pbjob.JobState_SUCCEEDED,
+	}
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{
+			Type: pbjob.JobType_BATCH,
+			CronConfig: &pbjob.CronConfig{
+				Schedule: "@hourly",
+			},
+		}, nil)
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
 // Verify that completion time of a completed job shouldn't be empty.
 func (suite *JobRuntimeUpdaterTestSuite) TestJobCompletionTimeNotEmpty() {
 	instanceCount := uint32(100)
@@ -233,6 +280,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobCompletionTimeNotEmpty() {
 		GetRuntime(gomock.Any()).
 		Return(&jobRuntime, nil)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -321,45 +372,594 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_RUNNING() {
 		Return(suite.cachedConfig, nil)
 
 	suite.cachedConfig.EXPECT().
-		GetType().
-		Return(pbjob.JobType_BATCH).
-		Times(int(instanceCount))
+		GetType().
+		Return(pbjob.JobType_BATCH).
+		Times(int(instanceCount))
+
+	suite.cachedJob.EXPECT().
+		RepopulateInstanceAvailabilityInfo(gomock.Any()).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		GetFirstTaskUpdateTime().
+		Return(float64(0))
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			cached.UpdateCacheAndDB).
+		Do(func(_ context.Context,
+			jobInfo *pbjob.JobInfo,
+			_ *models.ConfigAddOn,
+			_ *stateless.JobSpec,
+			_ cached.UpdateRequest) {
+			instanceCount := uint32(100)
+			suite.Equal(jobInfo.Runtime.State, pbjob.JobState_RUNNING)
+			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_PENDING.String()], instanceCount/4)
+			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_RUNNING.String()], instanceCount/4)
+			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_LAUNCHED.String()], instanceCount/4)
+			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_SUCCEEDED.String()], instanceCount/4)
+		}).
+		Return(nil)
+
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
+// TestJobRuntimeUpdater_ResourceBudgetExceeded tests that a batch job which
+// has used up its resource usage budget is killed
+func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ResourceBudgetExceeded() {
+	instanceCount := uint32(4)
+	jobRuntime := pbjob.RuntimeInfo{
+		State:               pbjob.JobState_RUNNING,
+		GoalState:           pbjob.JobState_SUCCEEDED,
+		DesiredStateVersion: 1,
+	}
+	sla := &pbjob.SlaConfig{
+		ResourceUsageBudget: map[string]float64{
+			common.CPU:    100,
+			common.MEMORY: 1000,
+		},
+	}
+	resourceUsage := map[string]float64{
+		common.CPU:    150,
+		common.GPU:    0,
+		common.MEMORY: 400,
+	}
+
+	// the suite job mock always returns an empty resource usage
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+	suite.cachedConfig.EXPECT().
+		GetInstanceCount().
+		Return(instanceCount).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		HasControllerTask().
+		Return(false)
+
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(sla).
+		AnyTimes()
+
+	cachedTasks := make(map[uint32]cached.Task)
+	for i := uint32(0); i < instanceCount; i++ {
+		cachedTasks[i] = suite.cachedTask
+	}
+	cachedJob.EXPECT().
+		GetAllTasks().
+		Return(cachedTasks).Times(2)
+
+	for i := uint32(0); i < instanceCount/2; i++ {
+		suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+			State: pbtask.TaskState_RUNNING,
+		})
+	}
+	for i := uint32(0); i < instanceCount/2; i++ {
+		suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+			State: pbtask.TaskState_SUCCEEDED,
+		})
+	}
+
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(cachedJob)
+
+	cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(suite.cachedConfig, nil)
+
+	suite.cachedConfig.EXPECT().
+		GetType().
+		Return(pbjob.JobType_BATCH).
+		Times(int(instanceCount))
+
+	cachedJob.EXPECT().
+		RepopulateInstanceAvailabilityInfo(gomock.Any()).
+		Return(nil)
+
+	cachedJob.EXPECT().
+		GetFirstTaskUpdateTime().
+		Return(float64(0))
+
+	cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+
+	cachedJob.EXPECT().
+		GetResourceUsage().
+		Return(resourceUsage)
+
+	cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			cached.UpdateCacheAndDB).
+		Do(func(_ context.Context,
+			jobInfo *pbjob.JobInfo,
+			_ *models.ConfigAddOn,
+			_ *stateless.JobSpec,
+			_ cached.UpdateRequest) {
+			suite.Equal(pbjob.JobState_RUNNING, jobInfo.Runtime.GetState())
+			suite.Equal(pbjob.JobState_KILLED, jobInfo.Runtime.GetGoalState())
+			suite.Equal(uint64(2), jobInfo.Runtime.GetDesiredStateVersion())
+
+			budget := jobInfo.Runtime.GetResourceBudget()
+			suite.Equal(map[string]float64{
+				common.CPU:    0,
+				common.MEMORY: 600,
+			}, budget.GetRemaining())
+			suite.Equal([]string{common.CPU}, budget.GetExceededResources())
+			suite.NotEmpty(budget.GetExceededTime())
+		}).
+		Return(nil)
+
+	cachedJob.EXPECT().
+		IsPartiallyCreated(gomock.Any()).
+		Return(false)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
+// TestJobRuntimeUpdater_DeadlineExceeded tests that a batch job which
+// has not completed by its max completion time is killed
+func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_DeadlineExceeded() {
+	instanceCount := uint32(4)
+	jobRuntime := pbjob.RuntimeInfo{
+		State:               pbjob.JobState_RUNNING,
+		GoalState:           pbjob.JobState_SUCCEEDED,
+		DesiredStateVersion: 1,
+		StartTime: time.Now().Add(-2 * time.Minute).
+			UTC().Format(time.RFC3339Nano),
+	}
+
+	// the suite config mock always returns no max completion time
+	cachedConfig := cachedmocks.NewMockJobConfigCache(suite.ctrl)
+
+	cachedConfig.EXPECT().
+		GetMaxCompletionTime().
+		Return(uint32(60)).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetCronConfig().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetArrayConfig().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetControllerPolicy().
+		Return(pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetInstanceCount().
+		Return(instanceCount).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		HasControllerTask().
+		Return(false)
+
+	cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetType().
+		Return(pbjob.JobType_BATCH).
+		AnyTimes()
+
+	cachedTasks := make(map[uint32]cached.Task)
+	for i := uint32(0); i < instanceCount; i++ {
+		cachedTasks[i] = suite.cachedTask
+	}
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(cachedTasks).Times(2)
+
+	suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+		State: pbtask.TaskState_RUNNING,
+	}).Times(int(instanceCount))
+
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(cachedConfig, nil)
+
+	suite.cachedJob.EXPECT().
+		RepopulateInstanceAvailabilityInfo(gomock.Any()).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		GetFirstTaskUpdateTime().
+		Return(float64(0))
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+
+	suite.cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			cached.UpdateCacheAndDB).
+		Do(func(_ context.Context,
+			jobInfo *pbjob.JobInfo,
+			_ *models.ConfigAddOn,
+			_ *stateless.JobSpec,
+			_ cached.UpdateRequest) {
+			suite.Equal(pbjob.JobState_RUNNING, jobInfo.Runtime.GetState())
+			suite.Equal(pbjob.JobState_KILLED, jobInfo.Runtime.GetGoalState())
+			suite.Equal(uint64(2), jobInfo.Runtime.GetDesiredStateVersion())
+			suite.NotEmpty(jobInfo.Runtime.GetDeadlineExceededTime())
+		}).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		IsPartiallyCreated(gomock.Any()).
+		Return(false)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
+// TestJobRuntimeUpdater_DeadlineExceededFrozen tests that a batch job
+// which has not completed by its max completion time is not killed, but
+// evaluated again later, while the cluster is frozen
+func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_DeadlineExceededFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+
+	instanceCount := uint32(4)
+	jobRuntime := pbjob.RuntimeInfo{
+		State:               pbjob.JobState_RUNNING,
+		GoalState:           pbjob.JobState_SUCCEEDED,
+		DesiredStateVersion: 1,
+		StartTime: time.Now().Add(-2 * time.Minute).
+			UTC().Format(time.RFC3339Nano),
+	}
+
+	// the suite config mock always returns no max completion time
+	cachedConfig := cachedmocks.NewMockJobConfigCache(suite.ctrl)
+
+	cachedConfig.EXPECT().
+		GetMaxCompletionTime().
+		Return(uint32(60)).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetCronConfig().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetArrayConfig().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetControllerPolicy().
+		Return(pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetInstanceCount().
+		Return(instanceCount).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		HasControllerTask().
+		Return(false)
+
+	cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil).
+		AnyTimes()
+
+	cachedConfig.EXPECT().
+		GetType().
+		Return(pbjob.JobType_BATCH).
+		AnyTimes()
+
+	cachedTasks := make(map[uint32]cached.Task)
+	for i := uint32(0); i < instanceCount; i++ {
+		cachedTasks[i] = suite.cachedTask
+	}
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(cachedTasks).Times(2)
+
+	suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+		State: pbtask.TaskState_RUNNING,
+	}).Times(int(instanceCount))
+
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(cachedConfig, nil)
+
+	suite.cachedJob.EXPECT().
+		RepopulateInstanceAvailabilityInfo(gomock.Any()).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		GetFirstTaskUpdateTime().
+		Return(float64(0))
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+
+	suite.cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			cached.UpdateCacheAndDB).
+		Do(func(_ context.Context,
+			jobInfo *pbjob.JobInfo,
+			_ *models.ConfigAddOn,
+			_ *stateless.JobSpec,
+			_ cached.UpdateRequest) {
+			suite.Equal(pbjob.JobState_RUNNING, jobInfo.Runtime.GetState())
+			suite.Empty(jobInfo.Runtime.GetGoalState())
+			suite.Empty(jobInfo.Runtime.GetDeadlineExceededTime())
+		}).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		IsPartiallyCreated(gomock.Any()).
+		Return(false)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.After(time.Now().Add(20 * time.Second)))
+		})
+
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
+// TestGetJobDeadline tests computing the time by which a job must complete
+func (suite *JobRuntimeUpdaterTestSuite) TestGetJobDeadline() {
+	startTime := time.Now().UTC()
+	config := &pbjob.JobConfig{
+		SLA: &pbjob.SlaConfig{MaxCompletionTime: 60},
+	}
+
+	// no max completion time configured
+	_, ok := getJobDeadline(&pbjob.JobConfig{},
+		startTime.Format(time.RFC3339Nano))
+	suite.False(ok)
+
+	// job has not started yet
+	_, ok = getJobDeadline(config, "")
+	suite.False(ok)
+
+	deadline, ok := getJobDeadline(config, startTime.Format(time.RFC3339Nano))
+	suite.True(ok)
+	suite.True(startTime.Add(time.Minute).Equal(deadline))
+}
+
+// TestGetResourceBudgetStatus tests computing the resource budget status
+// of a job from its resource usage
+func (suite *JobRuntimeUpdaterTestSuite) TestGetResourceBudgetStatus() {
+	// no budget configured
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(&pbjob.SlaConfig{})
+	status, exceeded := getResourceBudgetStatus(
+		suite.cachedConfig,
+		&pbjob.RuntimeInfo{},
+		map[string]float64{common.CPU: 10},
+	)
+	suite.Nil(status)
+	suite.Empty(exceeded)
+
+	// budget already enforced, exceeded time is preserved
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(&pbjob.SlaConfig{
+			ResourceUsageBudget: map[string]float64{
+				common.CPU: 10,
+				common.GPU: 5,
+			},
+		})
+	status, exceeded = getResourceBudgetStatus(
+		suite.cachedConfig,
+		&pbjob.RuntimeInfo{
+			ResourceBudget: &pbjob.ResourceBudgetStatus{
+				ExceededTime:      jobCompletionTime,
+				ExceededResources: []string{common.CPU},
+			},
+		},
+		map[string]float64{common.CPU: 12, common.GPU: 6},
+	)
+	suite.Equal(map[string]float64{common.CPU: 0, common.GPU: 0},
+		status.GetRemaining())
+	suite.Equal(jobCompletionTime, status.GetExceededTime())
+	suite.Equal([]string{common.CPU}, status.GetExceededResources())
+	suite.Equal([]string{common.CPU, common.GPU}, exceeded)
+}
 
-	suite.cachedJob.EXPECT().
-		RepopulateInstanceAvailabilityInfo(gomock.Any()).
-		Return(nil)
+// TestGetControllerJobState tests deriving the terminal state of a job
+// from the states of its controller tasks for each controller policy
+func (suite *JobRuntimeUpdaterTestSuite) TestGetControllerJobState() {
+	tests := []struct {
+		policy    pbjob.ControllerPolicy
+		total     uint32
+		succeeded uint32
+		failed    uint32
+		expected  pbjob.JobState
+	}{
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED, 1, 1, 0, pbjob.JobState_SUCCEEDED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED, 1, 0, 1, pbjob.JobState_FAILED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED, 1, 0, 0, pbjob.JobState_KILLED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED, 3, 2, 0, pbjob.JobState_KILLED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ALL_SUCCEED, 3, 2, 1, pbjob.JobState_FAILED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ANY_FAIL, 3, 2, 1, pbjob.JobState_FAILED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ANY_FAIL, 3, 1, 0, pbjob.JobState_SUCCEEDED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_ANY_FAIL, 3, 0, 0, pbjob.JobState_KILLED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_MAJORITY, 3, 2, 1, pbjob.JobState_SUCCEEDED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_MAJORITY, 4, 2, 1, pbjob.JobState_FAILED},
+		{pbjob.ControllerPolicy_CONTROLLER_POLICY_MAJORITY, 4, 2, 0, pbjob.JobState_KILLED},
+	}
+
+	for i, test := range tests {
+		suite.Equal(test.expected, getControllerJobState(
+			test.policy, test.total, test.succeeded, test.failed),
+			"test %d", i)
+	}
+}
 
-	suite.cachedJob.EXPECT().
-		GetFirstTaskUpdateTime().
-		Return(float64(0))
+// TestGetArrayStatus tests summarizing the status of the elements of
+// a job array from the tasks in cache
+func (suite *JobRuntimeUpdaterTestSuite) TestGetArrayStatus() {
+	// not a job array
+	suite.Nil(getArrayStatus(suite.cachedJob, &pbjob.JobConfig{}))
 
+	states := []pbtask.TaskState{
+		pbtask.TaskState_SUCCEEDED,
+		pbtask.TaskState_LOST,
+		pbtask.TaskState_RUNNING,
+		pbtask.TaskState_KILLED,
+		pbtask.TaskState_FAILED,
+		pbtask.TaskState_PENDING,
+	}
+	cachedTasks := make(map[uint32]cached.Task)
+	for i, state := range states {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().
+			CurrentState().
+			Return(cached.TaskStateVector{State: state})
+		cachedTasks[uint32(i)] = cachedTask
+	}
 	suite.cachedJob.EXPECT().
-		GetRuntime(gomock.Any()).
-		Return(&jobRuntime, nil)
+		GetAllTasks().
+		Return(cachedTasks)
 
+	status := getArrayStatus(suite.cachedJob, &pbjob.JobConfig{
+		ArrayConfig: &pbjob.JobArrayConfig{
+			ParameterFile: "http://host/params.txt",
+		},
+	})
+	suite.Equal(&pbjob.JobArrayStatus{
+		Succeeded:      1,
+		Failed:         2,
+		Killed:         1,
+		Active:         2,
+		FailedElements: []uint32{1, 4},
+	}, status)
+}
+
+// TestGetTaskFailureStats tests summarizing the reasons the tasks of a
+// job failed for from the tasks in cache
+func (suite *JobRuntimeUpdaterTestSuite) TestGetTaskFailureStats() {
+	states := []cached.TaskStateVector{
+		{State: pbtask.TaskState_SUCCEEDED},
+		{State: pbtask.TaskState_RUNNING},
+		{State: pbtask.TaskState_LOST},
+		{
+			State:  pbtask.TaskState_FAILED,
+			Reason: mesos.TaskStatus_REASON_CONTAINER_LIMITATION_MEMORY.String(),
+		},
+		{
+			State:   pbtask.TaskState_FAILED,
+			Reason:  mesos.TaskStatus_REASON_CONTAINER_LAUNCH_FAILED.String(),
+			Message: "Failed to fetch all URIs for container",
+		},
+		{
+			State:    pbtask.TaskState_FAILED,
+			Reason:   mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			ExitCode: 1,
+		},
+		{
+			State:    pbtask.TaskState_FAILED,
+			Reason:   mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED.String(),
+			ExitCode: 2,
+		},
+		{State: pbtask.TaskState_FAILED},
+	}
+	cachedTasks := make(map[uint32]cached.Task)
+	for i, state := range states {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().
+			CurrentState().
+			Return(state)
+		cachedTasks[uint32(i)] = cachedTask
+	}
 	suite.cachedJob.EXPECT().
-		Update(
-			gomock.Any(),
-			gomock.Any(),
-			gomock.Any(),
-			nil,
-			cached.UpdateCacheAndDB).
-		Do(func(_ context.Context,
-			jobInfo *pbjob.JobInfo,
-			_ *models.ConfigAddOn,
-			_ *stateless.JobSpec,
-			_ cached.UpdateRequest) {
-			instanceCount := uint32(100)
-			suite.Equal(jobInfo.Runtime.State, pbjob.JobState_RUNNING)
-			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_PENDING.String()], instanceCount/4)
-			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_RUNNING.String()], instanceCount/4)
-			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_LAUNCHED.String()], instanceCount/4)
-			suite.Equal(jobInfo.Runtime.TaskStats[pbtask.TaskState_SUCCEEDED.String()], instanceCount/4)
-		}).
-		Return(nil)
+		GetAllTasks().
+		Return(cachedTasks)
 
-	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	stateCounts, _, failureStats, err := getTaskStateSummaryForJobInCache(
+		context.Background(), suite.cachedJob, &pbjob.JobConfig{})
 	suite.NoError(err)
+	suite.Equal(uint32(5), stateCounts[pbtask.TaskState_FAILED.String()])
+	suite.Equal(&pbjob.TaskFailureStats{
+		Oom:             1,
+		NonZeroExitCode: 2,
+		FetchFailure:    1,
+		Lost:            1,
+		Other:           1,
+	}, failureStats)
 }
 
 // TestJobRuntimeUpdater_Batch_RUNNING tests updating a SUCCEED batch job
@@ -433,6 +1033,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_SUCCEED() {
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -533,6 +1137,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_PENDING() {
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -628,6 +1236,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_FAILED() {
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -727,6 +1339,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_LOST() {
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -830,6 +1446,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_KILLING() {
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -939,6 +1559,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_Batch_KILLED() {
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1038,6 +1662,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_DBError() {
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1120,6 +1748,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_IncorrectState()
 		GetLastTaskUpdateTime().
 		Return(endTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1258,6 +1890,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_PartiallyCreatedJ
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1357,6 +1993,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_InitializedJobWit
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1455,6 +2095,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_PendingJobWithMor
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1536,6 +2180,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskSuc
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -1564,6 +2212,127 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskSuc
 		Return(float64(0))
 
 		// as long as controller task succeeds, job state is succeeded
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		Update(
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+			nil,
+			cached.UpdateCacheAndDB).
+		Do(func(_ context.Context,
+			jobInfo *pbjob.JobInfo,
+			_ *models.ConfigAddOn,
+			_ *stateless.JobSpec,
+			_ cached.UpdateRequest) {
+			suite.Equal(jobInfo.Runtime.State, pbjob.JobState_SUCCEEDED)
+		}).Return(nil)
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := JobRuntimeUpdater(context.Background(), suite.jobEnt)
+	suite.NoError(err)
+}
+
+// TestJobRuntimeUpdaterControllerTaskNotFirstInstance tests updating
+// a job whose controller task is not instance 0
+func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdaterControllerTaskNotFirstInstance() {
+	instanceCount := uint32(100)
+	suite.cachedConfig.EXPECT().
+		GetInstanceCount().
+		Return(instanceCount).
+		AnyTimes()
+
+	startTime, _ := time.Parse(time.RFC3339Nano, jobStartTime)
+	startTimeUnix := float64(startTime.UnixNano()) / float64(time.Second/time.Nanosecond)
+
+	stateCounts := make(map[string]uint32)
+	stateCounts[pbtask.TaskState_FAILED.String()] = instanceCount / 2
+	stateCounts[pbtask.TaskState_SUCCEEDED.String()] = instanceCount / 2
+
+	jobRuntime := pbjob.RuntimeInfo{
+		State:     pbjob.JobState_INITIALIZED,
+		GoalState: pbjob.JobState_SUCCEEDED,
+		TaskStats: stateCounts,
+	}
+	cachedTasks := make(map[uint32]cached.Task)
+	for i := uint32(0); i < instanceCount; i++ {
+		cachedTasks[i] = suite.cachedTask
+	}
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(cachedTasks).Times(2)
+
+	for i := uint32(0); i < instanceCount/2; i++ {
+		suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+			State: pbtask.TaskState_FAILED,
+		})
+	}
+	for i := uint32(0); i < instanceCount/2; i++ {
+		suite.cachedTask.EXPECT().CurrentState().Return(cached.TaskStateVector{
+			State: pbtask.TaskState_SUCCEEDED,
+		})
+	}
+
+	suite.jobFactory.EXPECT().
+		AddJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&jobRuntime, nil)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(suite.cachedConfig, nil)
+
+	suite.cachedConfig.EXPECT().GetType().Return(pbjob.JobType_BATCH).Times(100)
+
+	suite.cachedConfig.EXPECT().
+		HasControllerTask().
+		Return(true)
+
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{3})
+
+	suite.cachedJob.EXPECT().
+		RepopulateInstanceAvailabilityInfo(gomock.Any()).
+		Return(nil)
+
+	suite.cachedJob.EXPECT().
+		IsPartiallyCreated(gomock.Any()).
+		Return(false).
+		AnyTimes()
+
+	suite.cachedJob.EXPECT().
+		AddTask(gomock.Any(), uint32(3)).
+		Return(suite.cachedTask, nil)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbtask.RuntimeInfo{
+			State: pbtask.TaskState_SUCCEEDED,
+		}, nil)
+
+	suite.cachedJob.EXPECT().
+		GetFirstTaskUpdateTime().
+		Return(startTimeUnix)
+
+	suite.cachedJob.EXPECT().
+		GetLastTaskUpdateTime().
+		Return(float64(0))
+
+		// as long as controller task succeeds, job state is succeeded
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1644,6 +2413,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdaterControllerTaskFail
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -1719,6 +2492,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdaterControllerTaskFail
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -1798,6 +2575,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskFai
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -1826,6 +2607,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskFai
 		Return(suite.lastUpdateTs)
 
 		// as long as controller task failed, job state is failed
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -1907,6 +2692,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskLos
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -1935,6 +2724,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskLos
 		Return(suite.lastUpdateTs)
 
 		// as long as controller task failed, job state is failed
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -2018,6 +2811,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskRun
 		HasControllerTask().
 		Return(true)
 
+	suite.cachedConfig.EXPECT().
+		GetControllerInstanceIDs().
+		Return([]uint32{0})
+
 	suite.cachedJob.EXPECT().
 		RepopulateInstanceAvailabilityInfo(gomock.Any()).
 		Return(nil)
@@ -2033,6 +2830,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_ControllerTaskRun
 
 	// even if controller task finishes, still wait for all tasks
 	// finish before entering terminal state
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
@@ -2126,6 +2927,10 @@ func (suite *JobRuntimeUpdaterTestSuite) TestJobRuntimeUpdater_UpdateAddingInsta
 		GetFirstTaskUpdateTime().
 		Return(startTimeUnix)
 
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(nil)
+
 	suite.cachedJob.EXPECT().
 		Update(
 			gomock.Any(),
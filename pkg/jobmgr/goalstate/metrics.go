@@ -30,11 +30,35 @@ type JobMetrics struct {
 	JobDeleted      tally.Counter
 	JobInvalidState tally.Counter
 
+	JobTTLDeleted        tally.Counter
+	JobTTLReclaimedTasks tally.Counter
+	JobTTLDeleteFrozen   tally.Counter
+
+	JobCronRun        tally.Counter
+	JobCronRunSkipped tally.Counter
+	JobCronRunKilled  tally.Counter
+	JobCronRunDeleted tally.Counter
+	JobCronRunFailed  tally.Counter
+	JobCronRunFrozen  tally.Counter
+
+	JobBlocked          tally.Counter
+	JobDependencyFailed tally.Counter
+
 	JobRuntimeUpdated               tally.Counter
 	JobRuntimeUpdateFailed          tally.Counter
 	JobMaxRunningInstancesExceeding tally.Counter
+	JobResourceBudgetExceeded       tally.Counter
+	JobDeadlineExceeded             tally.Counter
+	JobKillFrozen                   tally.Counter
 
 	JobRecalculateFromCache tally.Counter
+
+	JobCacheCheck             tally.Counter
+	JobCacheRuntimeMismatch   tally.Counter
+	JobCacheTaskMismatch      tally.Counter
+	JobCacheTaskExtra         tally.Counter
+	JobCacheTaskStatsMismatch tally.Counter
+	JobCacheRepaired          tally.Counter
 }
 
 // TaskMetrics contains all counters to track task metrics in goal state.
@@ -49,6 +73,10 @@ type TaskMetrics struct {
 	RetryFailedLaunchTotal tally.Counter
 	RetryFailedTasksTotal  tally.Counter
 	RetryLostTasksTotal    tally.Counter
+	TaskRestartFrozen      tally.Counter
+	TaskRestartBackoff     tally.Counter
+	StuckTaskReconcile     tally.Counter
+	TaskReconcileFrozen    tally.Counter
 }
 
 // UpdateMetrics contains all counters to track
@@ -64,6 +92,13 @@ type UpdateMetrics struct {
 	UpdateRunFail           tally.Counter
 	UpdateWriteProgress     tally.Counter
 	UpdateWriteProgressFail tally.Counter
+	UpdateRunFrozen         tally.Counter
+	UpdateVerifyPass        tally.Counter
+	UpdateVerifyFail        tally.Counter
+	UpdateGateTripped       tally.Counter
+	UpdateCanaryPromoted    tally.Counter
+	UpdateRollbackFrozen    tally.Counter
+	UpdatePromoteFrozen     tally.Counter
 }
 
 // Metrics is the struct containing all the counters that track job and task
@@ -90,11 +125,31 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		JobFailed:                       jobScope.Counter("job_failed"),
 		JobDeleted:                      jobScope.Counter("job_deleted"),
 		JobInvalidState:                 jobScope.Counter("invalid_state"),
+		JobTTLDeleted:                   jobScope.Counter("ttl_deleted"),
+		JobTTLReclaimedTasks:            jobScope.Counter("ttl_reclaimed_tasks"),
+		JobTTLDeleteFrozen:              jobScope.Counter("ttl_delete_frozen"),
+		JobCronRun:                      jobScope.Counter("cron_run"),
+		JobCronRunSkipped:               jobScope.Counter("cron_run_skipped"),
+		JobCronRunKilled:                jobScope.Counter("cron_run_killed"),
+		JobCronRunDeleted:               jobScope.Counter("cron_run_deleted"),
+		JobCronRunFailed:                jobScope.Counter("cron_run_failed"),
+		JobCronRunFrozen:                jobScope.Counter("cron_run_frozen"),
+		JobBlocked:                      jobScope.Counter("blocked"),
+		JobDependencyFailed:             jobScope.Counter("dependency_failed"),
 		JobRuntimeUpdated:               jobScope.Counter("runtime_update_success"),
 		JobRuntimeUpdateFailed:          jobScope.Counter("runtime_update_fail"),
 		JobMaxRunningInstancesExceeding: jobScope.Counter("max_running_instances_exceeded"),
+		JobResourceBudgetExceeded:       jobScope.Counter("resource_budget_exceeded"),
+		JobDeadlineExceeded:             jobScope.Counter("deadline_exceeded"),
+		JobKillFrozen:                   jobScope.Counter("kill_frozen"),
 		JobRecalculateFromCache: jobScope.Counter(
 			"job_recalculate_from_cache"),
+		JobCacheCheck:             jobScope.Counter("cache_check"),
+		JobCacheRuntimeMismatch:   jobScope.Counter("cache_runtime_mismatch"),
+		JobCacheTaskMismatch:      jobScope.Counter("cache_task_mismatch"),
+		JobCacheTaskExtra:         jobScope.Counter("cache_task_extra"),
+		JobCacheTaskStatsMismatch: jobScope.Counter("cache_task_stats_mismatch"),
+		JobCacheRepaired:          jobScope.Counter("cache_repaired"),
 	}
 
 	taskMetrics := &TaskMetrics{
@@ -108,6 +163,10 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		RetryFailedLaunchTotal: taskScope.Counter("retry_system_failure_total"),
 		RetryFailedTasksTotal:  taskScope.Counter("retry_failed_total"),
 		RetryLostTasksTotal:    taskScope.Counter("retry_lost_total"),
+		TaskRestartFrozen:      taskScope.Counter("restart_frozen"),
+		TaskRestartBackoff:     taskScope.Counter("restart_backoff"),
+		StuckTaskReconcile:     taskScope.Counter("stuck_reconcile"),
+		TaskReconcileFrozen:    taskScope.Counter("reconcile_frozen"),
 	}
 
 	updateMetrics := &UpdateMetrics{
@@ -121,6 +180,13 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		UpdateRunFail:           updateScope.Counter("run_fail"),
 		UpdateWriteProgress:     updateScope.Counter("write_progress"),
 		UpdateWriteProgressFail: updateScope.Counter("write_progress_fail"),
+		UpdateRunFrozen:         updateScope.Counter("run_frozen"),
+		UpdateVerifyPass:        updateScope.Counter("verify_pass"),
+		UpdateVerifyFail:        updateScope.Counter("verify_fail"),
+		UpdateGateTripped:       updateScope.Counter("gate_tripped"),
+		UpdateCanaryPromoted:    updateScope.Counter("canary_promoted"),
+		UpdateRollbackFrozen:    updateScope.Counter("rollback_frozen"),
+		UpdatePromoteFrozen:     updateScope.Counter("promote_frozen"),
 	}
 
 	return &Metrics{
@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/lifecycle"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+// stuckTaskDetector periodically checks the tasks in the cache for tasks
+// which have been in a transient state for longer than the threshold of
+// the state, and exports the number of such stuck tasks for each state.
+// The stuck tasks are recovered by the task goal state engine, so that
+// the recovery does not race with the actions of the tasks: tasks stuck
+// in LAUNCHING state are evaluated again to be relaunched on launch
+// timeout, and tasks stuck in STARTING or KILLING state are force
+// reconciled with the host manager by the reconcile remediation, which
+// is also applied by the task watchdog.
+type stuckTaskDetector struct {
+	driver     *driver
+	cfg        *StuckTaskDetectorConfig
+	thresholds map[task.TaskState]time.Duration
+	// stuckTasks is the gauge of the number of stuck tasks for each state
+	stuckTasks map[task.TaskState]tally.Gauge
+	lifeCycle  lifecycle.LifeCycle
+}
+
+// newStuckTaskDetector returns a new stuck task detector of the driver.
+func newStuckTaskDetector(
+	d *driver,
+	cfg *StuckTaskDetectorConfig,
+	scope tally.Scope,
+) *stuckTaskDetector {
+	thresholds := map[task.TaskState]time.Duration{
+		task.TaskState_LAUNCHING: cfg.LaunchingThreshold,
+		task.TaskState_STARTING:  cfg.StartingThreshold,
+		task.TaskState_KILLING:   cfg.KillingThreshold,
+	}
+
+	stuckTasks := make(map[task.TaskState]tally.Gauge)
+	for state := range thresholds {
+		stuckTasks[state] = scope.Tagged(map[string]string{
+			"state": strings.ToLower(state.String()),
+		}).Gauge("stuck_tasks")
+	}
+
+	return &stuckTaskDetector{
+		driver:     d,
+		cfg:        cfg,
+		thresholds: thresholds,
+		stuckTasks: stuckTasks,
+		lifeCycle:  lifecycle.NewLifeCycle(),
+	}
+}
+
+// Start starts checking for stuck tasks periodically.
+func (s *stuckTaskDetector) Start() {
+	if s.cfg.CheckPeriod <= 0 {
+		return
+	}
+
+	if s.lifeCycle.Start() {
+		go func() {
+			defer s.lifeCycle.StopComplete()
+
+			ticker := time.NewTicker(s.cfg.CheckPeriod)
+			defer ticker.Stop()
+
+			log.Info("stuck task detector started")
+
+			for {
+				select {
+				case <-s.lifeCycle.StopCh():
+					log.Info("stuck task detector stopped")
+					return
+				case <-ticker.C:
+					s.detectStuckTasks()
+				}
+			}
+		}()
+	}
+}
+
+// Stop stops checking for stuck tasks, and waits for the ongoing check
+// to complete.
+func (s *stuckTaskDetector) Stop() {
+	if !s.lifeCycle.Stop() {
+		return
+	}
+	s.lifeCycle.Wait()
+}
+
+// detectStuckTasks checks all the tasks in the cache once, and recovers
+// the stuck tasks.
+func (s *stuckTaskDetector) detectStuckTasks() {
+	now := time.Now()
+	stuckCount := make(map[task.TaskState]int)
+	for id, cachedJob := range s.driver.jobFactory.GetAllJobs() {
+		jobID := &peloton.JobID{Value: id}
+		for instanceID, cachedTask := range cachedJob.GetAllTasks() {
+			// only the runtimes already in the cache are checked, the
+			// runtimes not loaded yet are evaluated when they are loaded
+			runtime := cachedTask.GetCacheRuntime()
+			if runtime == nil {
+				continue
+			}
+
+			threshold, ok := s.thresholds[runtime.GetState()]
+			if !ok {
+				continue
+			}
+
+			updatedAt := time.Unix(
+				0, int64(runtime.GetRevision().GetUpdatedAt()))
+			if now.Sub(updatedAt) < threshold {
+				continue
+			}
+
+			stuckCount[runtime.GetState()]++
+			s.recoverStuckTask(jobID, instanceID, runtime)
+		}
+	}
+
+	for state, gauge := range s.stuckTasks {
+		gauge.Update(float64(stuckCount[state]))
+	}
+}
+
+// recoverStuckTask hands a task stuck in a transient state over to the
+// task goal state engine for recovery.
+func (s *stuckTaskDetector) recoverStuckTask(
+	jobID *peloton.JobID,
+	instanceID uint32,
+	runtime *task.RuntimeInfo,
+) {
+	log.WithFields(log.Fields{
+		"job_id":      jobID.GetValue(),
+		"instance_id": instanceID,
+		"mesos_id":    runtime.GetMesosTaskId().GetValue(),
+		"state":       runtime.GetState().String(),
+	}).Warn("task stuck in transient state")
+
+	// evaluate the task again, in case the task lost its timeout
+	s.driver.EnqueueTask(jobID, instanceID, time.Now())
+
+	// the launch timeout of a task stuck in LAUNCHING state is handled
+	// by its goal state actions
+	if runtime.GetState() == task.TaskState_LAUNCHING {
+		return
+	}
+
+	s.driver.mtx.taskMetrics.StuckTaskReconcile.Inc(1)
+	s.driver.taskEngine.Remediate(
+		NewTaskEntity(jobID, instanceID, s.driver),
+		goalstate.Action{Name: _reconcileRemediation, Execute: TaskReconcile},
+	)
+}
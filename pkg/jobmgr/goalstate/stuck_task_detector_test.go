@@ -0,0 +1,209 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type stuckTaskDetectorTestSuite struct {
+	suite.Suite
+
+	ctrl                *gomock.Controller
+	testScope           tally.TestScope
+	jobGoalStateEngine  *goalstatemocks.MockEngine
+	taskGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory          *cachedmocks.MockJobFactory
+	cachedJob           *cachedmocks.MockJob
+	taskConfigV2Ops     *objectmocks.MockTaskConfigV2Ops
+	lm                  *lmmocks.MockManager
+	goalStateDriver     *driver
+	detector            *stuckTaskDetector
+	jobID               *peloton.JobID
+}
+
+func TestStuckTaskDetector(t *testing.T) {
+	suite.Run(t, new(stuckTaskDetectorTestSuite))
+}
+
+func (suite *stuckTaskDetectorTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.testScope = tally.NewTestScope("", map[string]string{})
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.taskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
+	suite.lm = lmmocks.NewMockManager(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		jobEngine:       suite.jobGoalStateEngine,
+		taskEngine:      suite.taskGoalStateEngine,
+		jobFactory:      suite.jobFactory,
+		taskConfigV2Ops: suite.taskConfigV2Ops,
+		lm:              suite.lm,
+		mtx:             NewMetrics(suite.testScope),
+		cfg:             &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+	suite.detector = newStuckTaskDetector(
+		suite.goalStateDriver,
+		&suite.goalStateDriver.cfg.StuckTaskDetector,
+		suite.testScope)
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+}
+
+func (suite *stuckTaskDetectorTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// newCachedTask returns a cached task with a runtime in the given state,
+// last updated the given duration ago
+func (suite *stuckTaskDetectorTestSuite) newCachedTask(
+	state pbtask.TaskState,
+	age time.Duration,
+) (*cachedmocks.MockTask, *pbtask.RuntimeInfo) {
+	mesosTaskID := uuid.New()
+	agentID := "agent-1"
+	runtime := &pbtask.RuntimeInfo{
+		State:       state,
+		MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+		AgentID:     &mesos.AgentID{Value: &agentID},
+		Revision: &peloton.ChangeLog{
+			UpdatedAt: uint64(time.Now().Add(-age).UnixNano()),
+		},
+	}
+	cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+	cachedTask.EXPECT().GetCacheRuntime().Return(runtime).AnyTimes()
+	return cachedTask, runtime
+}
+
+// TestDetectStuckTasks tests that the tasks stuck in STARTING and KILLING
+// states are evaluated again and scheduled to be reconciled by the task
+// goal state engine, and the stuck tasks are counted for each state
+func (suite *stuckTaskDetectorTestSuite) TestDetectStuckTasks() {
+	stuckStarting, _ := suite.newCachedTask(
+		pbtask.TaskState_STARTING, 5*time.Hour)
+	stuckKilling, _ := suite.newCachedTask(
+		pbtask.TaskState_KILLING, 2*time.Hour)
+	killing, _ := suite.newCachedTask(
+		pbtask.TaskState_KILLING, time.Minute)
+	running, _ := suite.newCachedTask(
+		pbtask.TaskState_RUNNING, 10*time.Hour)
+	notLoaded := cachedmocks.NewMockTask(suite.ctrl)
+	notLoaded.EXPECT().GetCacheRuntime().Return(nil)
+
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{
+			0: stuckStarting,
+			1: stuckKilling,
+			2: killing,
+			3: running,
+			4: notLoaded,
+		})
+
+	// the tasks are not reconciled by the detector itself
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Times(2)
+	var remediated []uint32
+	suite.taskGoalStateEngine.EXPECT().
+		Remediate(gomock.Any(), gomock.Any()).
+		Do(func(entity goalstate.Entity, remediation goalstate.Action) {
+			suite.Equal(_reconcileRemediation, remediation.Name)
+			remediated = append(remediated, entity.(*taskEntity).instanceID)
+		}).
+		Times(2)
+
+	suite.detector.detectStuckTasks()
+	suite.ElementsMatch([]uint32{0, 1}, remediated)
+
+	gauges := suite.testScope.Snapshot().Gauges()
+	suite.Equal(float64(1), gauges["stuck_tasks+state=starting"].Value())
+	suite.Equal(float64(1), gauges["stuck_tasks+state=killing"].Value())
+	suite.Equal(float64(0), gauges["stuck_tasks+state=launching"].Value())
+
+	counters := suite.testScope.Snapshot().Counters()
+	suite.Equal(int64(2), counters["task.stuck_reconcile+"].Value())
+}
+
+// TestDetectStuckLaunchingTask tests that a task stuck in LAUNCHING state
+// is evaluated again, to be relaunched on launch timeout by its goal
+// state actions
+func (suite *stuckTaskDetectorTestSuite) TestDetectStuckLaunchingTask() {
+	stuckLaunching, _ := suite.newCachedTask(
+		pbtask.TaskState_LAUNCHING, time.Hour)
+
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{suite.jobID.GetValue(): suite.cachedJob})
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: stuckLaunching})
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.detector.detectStuckTasks()
+
+	suite.Equal(
+		float64(1),
+		suite.testScope.Snapshot().
+			Gauges()["stuck_tasks+state=launching"].Value())
+}
+
+// TestStartStop tests starting and stopping the detector
+func (suite *stuckTaskDetectorTestSuite) TestStartStop() {
+	suite.detector.cfg.CheckPeriod = time.Millisecond
+	suite.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{}).
+		MinTimes(1)
+
+	suite.detector.Start()
+	time.Sleep(10 * time.Millisecond)
+	suite.detector.Stop()
+
+	// stopping a stopped detector is a no-op
+	suite.detector.Stop()
+}
+
+// TestStartDisabled tests that the detector is not started when disabled
+func (suite *stuckTaskDetectorTestSuite) TestStartDisabled() {
+	suite.detector.cfg.CheckPeriod = -1
+	suite.detector.Start()
+	time.Sleep(10 * time.Millisecond)
+	suite.detector.Stop()
+}
@@ -44,6 +44,9 @@ const (
 	StartAction TaskAction = "start_task"
 	// StopAction kills the task
 	StopAction TaskAction = "stop_task"
+	// ResizeAction updates the resources of a running task in place, and
+	// falls back to StopAction if the task cannot be resized
+	ResizeAction TaskAction = "resize_task"
 	// ExecutorShutdownAction shuts down executor directly after StopAction timeout
 	ExecutorShutdownAction TaskAction = "executor_shutdown"
 	// InitializeAction re-initializes the task and regenerates the mesos task id
@@ -71,6 +74,7 @@ var (
 		NoTaskAction:           nil,
 		StartAction:            TaskStart,
 		StopAction:             TaskStop,
+		ResizeAction:           TaskResize,
 		InitializeAction:       TaskInitialize,
 		ReloadTaskRuntime:      TaskReloadRuntime,
 		LaunchRetryAction:      TaskLaunchRetry,
@@ -93,6 +97,7 @@ var (
 		},
 		task.TaskState_RUNNING: {
 			task.TaskState_INITIALIZED: StartAction,
+			task.TaskState_LAUNCHING:   LaunchRetryAction,
 			task.TaskState_LAUNCHED:    LaunchRetryAction,
 			task.TaskState_STARTING:    LaunchRetryAction,
 			task.TaskState_SUCCEEDED:   TerminatedRetryAction,
@@ -103,6 +108,7 @@ var (
 		},
 		task.TaskState_SUCCEEDED: {
 			task.TaskState_INITIALIZED: StartAction,
+			task.TaskState_LAUNCHING:   LaunchRetryAction,
 			task.TaskState_LAUNCHED:    LaunchRetryAction,
 			task.TaskState_STARTING:    LaunchRetryAction,
 			task.TaskState_FAILED:      FailRetryAction,
@@ -180,6 +186,19 @@ func (t *taskEntity) GetID() string {
 	return taskID
 }
 
+// GetPrerequisites returns the job of the task, so that the task actions
+// run after a pending evaluation of the job, which syncs the job config
+// and runtime in the cache.
+func (t *taskEntity) GetPrerequisites() []goalstate.Prerequisite {
+	t.driver.RLock()
+	defer t.driver.RUnlock()
+
+	return []goalstate.Prerequisite{{
+		Engine: t.driver.jobEngine,
+		Entity: NewJobEntity(t.jobID, t.driver),
+	}}
+}
+
 func (t *taskEntity) GetState() interface{} {
 	cachedJob := t.driver.jobFactory.AddJob(t.jobID)
 	cachedTask := cachedJob.GetTask(t.instanceID)
@@ -227,6 +246,10 @@ func (t *taskEntity) GetActionList(
 	}
 
 	actionStr := t.suggestTaskAction(taskState, taskGoalState)
+	if actionStr == StopAction && t.canResize(taskState, taskGoalState) {
+		// Only the config changed, try to apply it without a restart.
+		actionStr = ResizeAction
+	}
 	action := _taskActionsMaps[actionStr]
 
 	log.WithField("job_id", t.jobID.GetValue()).
@@ -250,6 +273,33 @@ func (t *taskEntity) GetActionList(
 	return ctx, cancel, actions
 }
 
+// IsConverged returns true if the task has no action to run to reach its
+// goal state
+func (t *taskEntity) IsConverged(state interface{}, goalState interface{}) bool {
+	taskState := state.(cached.TaskStateVector)
+	taskGoalState := goalState.(cached.TaskStateVector)
+
+	if taskState.State == task.TaskState_UNKNOWN ||
+		taskGoalState.State == task.TaskState_UNKNOWN {
+		return false
+	}
+	return t.suggestTaskAction(taskState, taskGoalState) == NoTaskAction
+}
+
+// canResize returns true if the config of a running task changed, and the
+// cluster can resize the task in place instead of restarting it. TaskResize
+// still restarts the task if its config changed beyond its resources.
+func (t *taskEntity) canResize(
+	currentState cached.TaskStateVector,
+	goalState cached.TaskStateVector) bool {
+	return currentState.State == task.TaskState_RUNNING &&
+		goalState.State == task.TaskState_RUNNING &&
+		requireUpdate(currentState, goalState) &&
+		!requireRestart(currentState, goalState) &&
+		t.driver.cfg.EnablePodResize &&
+		t.driver.lm.SupportsPodResize()
+}
+
 // suggestTaskAction provides the task action for a given state and goal state
 func (t *taskEntity) suggestTaskAction(
 	currentState cached.TaskStateVector,
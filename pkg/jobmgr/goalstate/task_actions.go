@@ -28,7 +28,17 @@ import (
 
 // TaskReloadRuntime reloads task runtime into cache.
 func TaskReloadRuntime(ctx context.Context, entity goalstate.Entity) error {
-	taskEnt := entity.(*taskEntity)
+	return reloadTaskRuntime(ctx, entity.(*taskEntity), false)
+}
+
+// reloadTaskRuntime reloads the task runtime from the DB into the cache,
+// replacing the runtime in the cache regardless of its version if
+// forceReplace is set, and re-enqueues the task into the goal state engine.
+func reloadTaskRuntime(
+	ctx context.Context,
+	taskEnt *taskEntity,
+	forceReplace bool,
+) error {
 	goalStateDriver := taskEnt.driver
 	cachedJob := goalStateDriver.jobFactory.GetJob(taskEnt.jobID)
 	if cachedJob == nil {
@@ -67,9 +77,8 @@ func TaskReloadRuntime(ctx context.Context, entity goalstate.Entity) error {
 
 	cachedJob.ReplaceTasks(map[uint32]*task.TaskInfo{
 		taskEnt.instanceID: taskInfo,
-	}, false)
+	}, forceReplace)
 
-	// This function is called when the runtime in cache is nil.
 	// The task needs to re-enqueued into the goal state engine
 	// so that it the corresponding action can be executed.
 	goalStateDriver.EnqueueTask(taskEnt.jobID, taskEnt.instanceID, time.Now())
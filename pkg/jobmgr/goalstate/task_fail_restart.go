@@ -72,6 +72,9 @@ func rescheduleTask(
 			taskRuntime,
 			healthState)
 		runtimeDiff[jobmgrcommon.MessageField] = _rescheduleMessage
+		if len(taskRuntime.GetRestartBackoffUntil()) != 0 {
+			runtimeDiff[jobmgrcommon.RestartBackoffUntilField] = ""
+		}
 		log.WithField("job_id", jobID).
 			WithField("instance_id", instanceID).
 			Debug("restarting terminated task")
@@ -126,13 +129,45 @@ func getBackoff(
 	taskRuntime *task.RuntimeInfo,
 	initialTaskBackOff time.Duration,
 	maxTaskBackOff time.Duration) time.Duration {
-	if taskRuntime.GetFailureCount() == 0 {
+	return getExponentialBackoff(
+		taskRuntime.GetFailureCount(),
+		initialTaskBackOff,
+		maxTaskBackOff,
+	)
+}
+
+// getRestartBackoff returns how long the restart of a failed task is
+// delayed by, as configured in its restart policy. The backoff doubles
+// with each consecutive failure of the task.
+func getRestartBackoff(
+	taskRuntime *task.RuntimeInfo,
+	restartPolicy *task.RestartPolicy) time.Duration {
+	initialBackOff := time.Duration(restartPolicy.GetInitialBackoffSec()) *
+		time.Second
+	maxBackOff := time.Duration(restartPolicy.GetMaxBackoffSec()) *
+		time.Second
+	if maxBackOff < initialBackOff {
+		maxBackOff = initialBackOff
+	}
+
+	return getExponentialBackoff(
+		taskRuntime.GetConsecutiveFailureCount(),
+		initialBackOff,
+		maxBackOff,
+	)
+}
+
+func getExponentialBackoff(
+	failureCount uint32,
+	initialTaskBackOff time.Duration,
+	maxTaskBackOff time.Duration) time.Duration {
+	if failureCount == 0 {
 		return time.Duration(0)
 	}
 
 	// rawBackOff = _initialTaskBackOff * 2 ^ (failureCount - 1)
 	rawBackOff := float64(initialTaskBackOff.Nanoseconds()) *
-		math.Pow(2, float64(taskRuntime.GetFailureCount()-1))
+		math.Pow(2, float64(failureCount-1))
 
 	// type time.Duration is internally int64,
 	// have to make sure rawBackOff does not overflow when
@@ -193,6 +228,31 @@ func TaskFailRetry(ctx context.Context, entity goalstate.Entity) error {
 		return nil
 	}
 
+	// do not restart the task while the cluster is frozen, and
+	// evaluate it again later
+	if goalStateDriver.isFrozen() {
+		goalStateDriver.mtx.taskMetrics.TaskRestartFrozen.Inc(1)
+		goalStateDriver.EnqueueTask(
+			taskEnt.jobID,
+			taskEnt.instanceID,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		return nil
+	}
+
+	if backOff := getRestartBackoff(
+		runtime, taskConfig.GetRestartPolicy()); backOff > 0 {
+		backedOff, err := backOffTaskRestart(
+			ctx,
+			cachedJob,
+			taskEnt.instanceID,
+			runtime,
+			backOff,
+			goalStateDriver)
+		if err != nil || backedOff {
+			return err
+		}
+	}
+
 	return rescheduleTask(
 		ctx,
 		cachedJob,
@@ -202,3 +262,50 @@ func TaskFailRetry(ctx context.Context, entity goalstate.Entity) error {
 		goalStateDriver,
 		false)
 }
+
+// backOffTaskRestart delays the restart of a failed task by its restart
+// backoff, the time until which the restart is delayed is kept in the
+// task runtime. It returns true if the task must not be restarted yet.
+func backOffTaskRestart(
+	ctx context.Context,
+	cachedJob cached.Job,
+	instanceID uint32,
+	taskRuntime *task.RuntimeInfo,
+	backOff time.Duration,
+	goalStateDriver *driver) (bool, error) {
+	jobID := cachedJob.ID()
+
+	if len(taskRuntime.GetRestartBackoffUntil()) != 0 {
+		backOffUntil, err := time.Parse(
+			time.RFC3339Nano, taskRuntime.GetRestartBackoffUntil())
+		if err != nil || !time.Now().Before(backOffUntil) {
+			return false, nil
+		}
+		goalStateDriver.EnqueueTask(jobID, instanceID, backOffUntil)
+		return true, nil
+	}
+
+	backOffUntil := time.Now().Add(backOff)
+	_, _, err := cachedJob.PatchTasks(ctx,
+		map[uint32]jobmgrcommon.RuntimeDiff{
+			instanceID: {
+				jobmgrcommon.RestartBackoffUntilField: backOffUntil.UTC().
+					Format(time.RFC3339Nano),
+				jobmgrcommon.MessageField: common.TaskThrottleMessage,
+			},
+		},
+		false,
+	)
+	if err != nil {
+		return true, err
+	}
+
+	log.WithField("job_id", jobID.GetValue()).
+		WithField("instance_id", instanceID).
+		WithField("consecutive_failures", taskRuntime.GetConsecutiveFailureCount()).
+		WithField("backoff", backOff).
+		Info("backing off the restart of failed task")
+	goalStateDriver.mtx.taskMetrics.TaskRestartBackoff.Inc(1)
+	goalStateDriver.EnqueueTask(jobID, instanceID, backOffUntil)
+	return true, nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	mesosv1 "github.com/uber/peloton/.gen/mesos/v1"
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -208,6 +209,177 @@ func (suite *TaskFailRetryTestSuite) TestTaskFailRetry() {
 	suite.NoError(err)
 }
 
+// frozenState is a freeze.State which is always frozen
+type frozenState struct{}
+
+func (frozenState) IsFrozen() bool { return true }
+
+// TestTaskFailRetryFrozen tests that a failed task is not restarted,
+// but evaluated again later, while the cluster is frozen
+func (suite *TaskFailRetryTestSuite) TestTaskFailRetryFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+
+	taskConfig := pbtask.TaskConfig{
+		RestartPolicy: &pbtask.RestartPolicy{
+			MaxFailures: 3,
+		},
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.taskRuntime, nil)
+
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, gomock.Any()).
+		Return(&taskConfig, &models.ConfigAddOn{}, nil)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := TaskFailRetry(context.Background(), suite.taskEnt)
+	suite.NoError(err)
+}
+
+// TestTaskFailRetryBackoff tests that the restart of a task failing
+// repeatedly is delayed by its restart backoff
+func (suite *TaskFailRetryTestSuite) TestTaskFailRetryBackoff() {
+	taskConfig := pbtask.TaskConfig{
+		RestartPolicy: &pbtask.RestartPolicy{
+			MaxFailures:       5,
+			InitialBackoffSec: 10,
+			MaxBackoffSec:     60,
+		},
+	}
+	suite.taskRuntime.FailureCount = 2
+	suite.taskRuntime.ConsecutiveFailureCount = 2
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask)
+
+	suite.cachedJob.EXPECT().
+		ID().Return(suite.jobID)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.taskRuntime, nil)
+
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, gomock.Any()).
+		Return(&taskConfig, &models.ConfigAddOn{}, nil)
+
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Do(func(ctx context.Context,
+			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+			_ bool) {
+			runtimeDiff := runtimeDiffs[suite.instanceID]
+			backOffUntil, err := time.Parse(
+				time.RFC3339Nano,
+				runtimeDiff[jobmgrcommon.RestartBackoffUntilField].(string))
+			suite.NoError(err)
+			// the backoff doubles with the second consecutive failure
+			suite.True(backOffUntil.After(time.Now().Add(15 * time.Second)))
+			suite.True(backOffUntil.Before(time.Now().Add(25 * time.Second)))
+			suite.Nil(runtimeDiff[jobmgrcommon.StateField])
+		}).Return(nil, nil, nil)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := TaskFailRetry(context.Background(), suite.taskEnt)
+	suite.NoError(err)
+}
+
+// TestTaskFailRetryBackoffElapsed tests that a failed task is restarted
+// once its restart backoff has elapsed
+func (suite *TaskFailRetryTestSuite) TestTaskFailRetryBackoffElapsed() {
+	taskConfig := pbtask.TaskConfig{
+		RestartPolicy: &pbtask.RestartPolicy{
+			MaxFailures:       5,
+			InitialBackoffSec: 10,
+		},
+	}
+	suite.taskRuntime.FailureCount = 1
+	suite.taskRuntime.ConsecutiveFailureCount = 1
+	suite.taskRuntime.RestartBackoffUntil = time.Now().Add(-time.Second).
+		UTC().Format(time.RFC3339Nano)
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask)
+
+	suite.cachedJob.EXPECT().
+		ID().Return(suite.jobID).Times(2)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.taskRuntime, nil)
+
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, gomock.Any()).
+		Return(&taskConfig, &models.ConfigAddOn{}, nil)
+
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Do(func(ctx context.Context,
+			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
+			_ bool) {
+			runtimeDiff := runtimeDiffs[suite.instanceID]
+			suite.Equal(pbtask.TaskState_INITIALIZED,
+				runtimeDiff[jobmgrcommon.StateField])
+			suite.Equal("", runtimeDiff[jobmgrcommon.RestartBackoffUntilField])
+		}).Return(nil, nil, nil)
+
+	suite.cachedJob.EXPECT().
+		GetJobType().Return(pbjob.JobType_BATCH)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := TaskFailRetry(context.Background(), suite.taskEnt)
+	suite.NoError(err)
+}
+
+// TestGetRestartBackoff tests computing the restart backoff of a task
+// from its restart policy
+func (suite *TaskFailRetryTestSuite) TestGetRestartBackoff() {
+	policy := &pbtask.RestartPolicy{
+		InitialBackoffSec: 10,
+		MaxBackoffSec:     30,
+	}
+	testTable := map[uint32]time.Duration{
+		0: 0,
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 30 * time.Second,
+		9: 30 * time.Second,
+	}
+	for failures, backOff := range testTable {
+		suite.Equal(backOff, getRestartBackoff(
+			&pbtask.RuntimeInfo{ConsecutiveFailureCount: failures}, policy))
+	}
+
+	// no backoff configured
+	suite.Equal(time.Duration(0), getRestartBackoff(
+		&pbtask.RuntimeInfo{ConsecutiveFailureCount: 3},
+		&pbtask.RestartPolicy{MaxFailures: 5}))
+}
+
 // TestLostTaskRetry tests retry for lost task
 func (suite *TaskFailRetryTestSuite) TestLostTaskRetry() {
 	taskConfig := pbtask.TaskConfig{
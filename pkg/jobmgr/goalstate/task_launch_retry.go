@@ -88,6 +88,18 @@ func TaskLaunchRetry(ctx context.Context, entity goalstate.Entity) error {
 	}
 
 	switch cachedRuntime.State {
+	case task.TaskState_LAUNCHING:
+		// the launch of the task may have been interrupted before it
+		// reached LAUNCHED state, evaluate the task again on launch timeout
+		launchDeadline := time.Unix(
+			0, int64(cachedRuntime.GetRevision().GetUpdatedAt())).
+			Add(goalStateDriver.cfg.LaunchTimeout)
+		if time.Now().Before(launchDeadline) {
+			goalStateDriver.EnqueueTask(
+				taskEnt.jobID, taskEnt.instanceID, launchDeadline)
+			return nil
+		}
+		goalStateDriver.mtx.taskMetrics.TaskLaunchTimeout.Inc(1)
 	case task.TaskState_LAUNCHED:
 		if time.Now().Sub(
 			time.Unix(0, int64(cachedRuntime.GetRevision().GetUpdatedAt())),
@@ -124,7 +136,7 @@ func TaskLaunchRetry(ctx context.Context, entity goalstate.Entity) error {
 			"job_id":      taskEnt.jobID.GetValue(),
 			"instance_id": taskEnt.instanceID,
 			"state":       cachedRuntime.State,
-		}).Error("unexpected task state, expecting LAUNCHED or STARTING state")
+		}).Error("unexpected task state, expecting LAUNCHING, LAUNCHED or STARTING state")
 		goalStateDriver.EnqueueTask(taskEnt.jobID, taskEnt.instanceID, time.Now())
 		return nil
 	}
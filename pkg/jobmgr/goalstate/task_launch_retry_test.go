@@ -27,6 +27,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 	res_mocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+	"github.com/uber/peloton/pkg/common/goalstate"
 	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
@@ -386,6 +387,35 @@ func (suite *TestTaskLaunchRetrySuite) TestStartingTaskReenqueue() {
 		suite.getTaskEntity(suite.jobID, suite.instanceID)))
 }
 
+// TestLaunchingTaskReenqueue tests that a task in LAUNCHING state which
+// has not timed out is evaluated again on launch timeout
+func (suite *TestTaskLaunchRetrySuite) TestLaunchingTaskReenqueue() {
+	runtime := suite.getRunTime(
+		pb_task.TaskState_LAUNCHING,
+		pb_task.TaskState_SUCCEEDED,
+		nil)
+	launchDeadline := time.Unix(0, int64(runtime.GetRevision().GetUpdatedAt())).
+		Add(suite.goalStateDriver.cfg.LaunchTimeout)
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask)
+
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(runtime, nil)
+
+	suite.taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.Equal(launchDeadline))
+		})
+
+	suite.NoError(TaskLaunchRetry(context.Background(),
+		suite.getTaskEntity(suite.jobID, suite.instanceID)))
+}
+
 func (suite *TestTaskLaunchRetrySuite) TestTaskWithUnexpectedStateReenqueue() {
 	runtime := suite.getRunTime(
 		pb_task.TaskState_RUNNING,
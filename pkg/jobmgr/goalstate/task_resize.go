@@ -0,0 +1,158 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/taskconfig"
+	"github.com/uber/peloton/pkg/common/util"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TaskResize updates a running task to its desired config in place, when
+// the only change between the current and the desired config is the cpu
+// and memory limits of the task. If the config has other changes, or the
+// host cannot resize the task, it falls back to TaskStop, and the task is
+// restarted with the desired config. Once resized, the allocation of the
+// task in resource manager is updated and the task is available again.
+func TaskResize(ctx context.Context, entity goalstate.Entity) error {
+	taskEnt := entity.(*taskEntity)
+	goalStateDriver := taskEnt.driver
+	cachedJob := goalStateDriver.jobFactory.GetJob(taskEnt.jobID)
+	if cachedJob == nil {
+		return nil
+	}
+	cachedTask := cachedJob.GetTask(taskEnt.instanceID)
+	if cachedTask == nil {
+		log.WithFields(log.Fields{
+			"job_id":      taskEnt.jobID.GetValue(),
+			"instance_id": taskEnt.instanceID,
+		}).Error("task is nil in cache with valid job")
+		return nil
+	}
+	runtime, err := cachedTask.GetRuntime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if runtime.GetState() != task.TaskState_RUNNING {
+		return TaskStop(ctx, entity)
+	}
+
+	newConfig := getResizedTaskConfig(ctx, taskEnt, runtime)
+	if newConfig == nil {
+		return TaskStop(ctx, entity)
+	}
+
+	spec, err := goalStateDriver.taskConfigV2Ops.GetPodSpec(
+		ctx,
+		taskEnt.jobID,
+		taskEnt.instanceID,
+		runtime.GetDesiredConfigVersion())
+	if err != nil || spec == nil {
+		return TaskStop(ctx, entity)
+	}
+
+	err = goalStateDriver.lm.UpdatePodResources(
+		ctx,
+		runtime.GetMesosTaskId().GetValue(),
+		runtime.GetHost(),
+		spec,
+	)
+	if err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"job_id":      taskEnt.jobID.GetValue(),
+				"instance_id": taskEnt.instanceID,
+				"host":        runtime.GetHost(),
+			}).Info("failed to resize task in place, restarting it")
+		return TaskStop(ctx, entity)
+	}
+
+	// The task keeps running on the same host, so only its allocation in
+	// resource manager changes. Retry the action on failure, resizing the
+	// task again to the same resources is a noop on the host.
+	_, err = goalStateDriver.resmgrClient.UpdateTaskResources(
+		ctx,
+		&resmgrsvc.UpdateTaskResourcesRequest{
+			Task: &peloton.TaskID{
+				Value: util.CreatePelotonTaskID(
+					taskEnt.jobID.GetValue(), taskEnt.instanceID),
+			},
+			MesosTaskId: runtime.GetMesosTaskId(),
+			Resource:    newConfig.GetResource(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Clear the termination status set by the update, so that the task is
+	// available again as it was not restarted.
+	runtimeDiff := jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.ConfigVersionField:     runtime.GetDesiredConfigVersion(),
+		jobmgrcommon.TerminationStatusField: nil,
+		jobmgrcommon.MessageField:           "Task resized in place",
+		jobmgrcommon.ReasonField:            "",
+	}
+	_, _, err = cachedJob.PatchTasks(
+		ctx,
+		map[uint32]jobmgrcommon.RuntimeDiff{taskEnt.instanceID: runtimeDiff},
+		false,
+	)
+	if err == nil {
+		goalStateDriver.EnqueueTask(taskEnt.jobID, taskEnt.instanceID, time.Now())
+		EnqueueJobWithDefaultDelay(taskEnt.jobID, goalStateDriver, cachedJob)
+	}
+	return err
+}
+
+// getResizedTaskConfig returns the desired config of the task if it
+// differs from the current config only in its cpu and memory limits, and
+// nil otherwise.
+func getResizedTaskConfig(
+	ctx context.Context,
+	taskEnt *taskEntity,
+	runtime *task.RuntimeInfo) *task.TaskConfig {
+	goalStateDriver := taskEnt.driver
+	prevConfig, _, err := goalStateDriver.taskConfigV2Ops.GetTaskConfig(
+		ctx,
+		taskEnt.jobID,
+		taskEnt.instanceID,
+		runtime.GetConfigVersion())
+	if err != nil {
+		return nil
+	}
+
+	newConfig, _, err := goalStateDriver.taskConfigV2Ops.GetTaskConfig(
+		ctx,
+		taskEnt.jobID,
+		taskEnt.instanceID,
+		runtime.GetDesiredConfigVersion())
+	if err != nil ||
+		!taskconfig.HasOnlyResourceLimitsChanged(prevConfig, newConfig) {
+		return nil
+	}
+	return newConfig
+}
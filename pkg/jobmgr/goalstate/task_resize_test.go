@@ -0,0 +1,285 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type taskResizeTestSuite struct {
+	suite.Suite
+
+	ctrl                *gomock.Controller
+	jobGoalStateEngine  *goalstatemocks.MockEngine
+	taskGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory          *cachedmocks.MockJobFactory
+	cachedJob           *cachedmocks.MockJob
+	cachedTask          *cachedmocks.MockTask
+	lmMock              *lmmocks.MockManager
+	resmgrClient        *resmocks.MockResourceManagerServiceYARPCClient
+	taskConfigV2Ops     *objectmocks.MockTaskConfigV2Ops
+
+	jobID      *peloton.JobID
+	instanceID uint32
+	taskEnt    *taskEntity
+	runtime    *pbtask.RuntimeInfo
+	spec       *pbpod.PodSpec
+}
+
+func TestTaskResize(t *testing.T) {
+	suite.Run(t, new(taskResizeTestSuite))
+}
+
+func (suite *taskResizeTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+	suite.lmMock = lmmocks.NewMockManager(suite.ctrl)
+	suite.resmgrClient = resmocks.NewMockResourceManagerServiceYARPCClient(suite.ctrl)
+	suite.taskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
+
+	goalStateDriver := &driver{
+		jobEngine:       suite.jobGoalStateEngine,
+		taskEngine:      suite.taskGoalStateEngine,
+		jobFactory:      suite.jobFactory,
+		lm:              suite.lmMock,
+		resmgrClient:    suite.resmgrClient,
+		taskConfigV2Ops: suite.taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
+	}
+	goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.instanceID = uint32(0)
+	suite.taskEnt = &taskEntity{
+		jobID:      suite.jobID,
+		instanceID: suite.instanceID,
+		driver:     goalStateDriver,
+	}
+
+	mesosTaskID := suite.jobID.GetValue() + "-0-1"
+	suite.runtime = &pbtask.RuntimeInfo{
+		State:                pbtask.TaskState_RUNNING,
+		MesosTaskId:          &mesos_v1.TaskID{Value: &mesosTaskID},
+		Host:                 "host-0",
+		ConfigVersion:        1,
+		DesiredConfigVersion: 2,
+	}
+	suite.spec = &pbpod.PodSpec{
+		Containers: []*pbpod.ContainerSpec{
+			{Resource: &pbpod.ResourceSpec{CpuLimit: 2, MemLimitMb: 200}},
+		},
+	}
+}
+
+func (suite *taskResizeTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectTaskConfigs sets up the current and the desired config of the
+// task, with the desired config having the given command.
+func (suite *taskResizeTestSuite) expectTaskConfigs(command string) {
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, uint64(1)).
+		Return(&pbtask.TaskConfig{
+			Resource: &pbtask.ResourceConfig{CpuLimit: 1, MemLimitMb: 100},
+			Command:  &mesos_v1.CommandInfo{Value: &[]string{"echo"}[0]},
+		}, nil, nil)
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(&pbtask.TaskConfig{
+			Resource: &pbtask.ResourceConfig{CpuLimit: 2, MemLimitMb: 200},
+			Command:  &mesos_v1.CommandInfo{Value: &command},
+		}, nil, nil)
+}
+
+// expectTaskStop sets up the calls made when the task is restarted by
+// TaskStop instead of being resized.
+func (suite *taskResizeTestSuite) expectTaskStop() {
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob).Times(2)
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask).Times(2)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.runtime, nil)
+	suite.lmMock.EXPECT().
+		Kill(
+			gomock.Any(),
+			suite.runtime.GetMesosTaskId().GetValue(),
+			"",
+			nil,
+		).Return(nil)
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), map[uint32]jobmgrcommon.RuntimeDiff{
+			suite.instanceID: {
+				jobmgrcommon.StateField:   pbtask.TaskState_KILLING,
+				jobmgrcommon.MessageField: "Killing the task",
+				jobmgrcommon.ReasonField:  "",
+			},
+		}, false).
+		Return(nil, nil, nil)
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, uint64(1)).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+	suite.taskGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+	suite.cachedJob.EXPECT().GetJobType().Return(pbjob.JobType_SERVICE)
+	suite.jobGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+}
+
+// expectGetRuntime sets up the lookup of the task runtime by TaskResize.
+func (suite *taskResizeTestSuite) expectGetRuntime() {
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetTask(suite.instanceID).Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(suite.runtime, nil)
+}
+
+// TestTaskResize tests resizing a running task in place.
+func (suite *taskResizeTestSuite) TestTaskResize() {
+	suite.expectGetRuntime()
+	suite.expectTaskConfigs("echo")
+	suite.taskConfigV2Ops.EXPECT().
+		GetPodSpec(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(suite.spec, nil)
+	suite.lmMock.EXPECT().
+		UpdatePodResources(
+			gomock.Any(),
+			suite.runtime.GetMesosTaskId().GetValue(),
+			"host-0",
+			suite.spec,
+		).Return(nil)
+	suite.resmgrClient.EXPECT().
+		UpdateTaskResources(gomock.Any(), &resmgrsvc.UpdateTaskResourcesRequest{
+			Task:        &peloton.TaskID{Value: suite.jobID.GetValue() + "-0"},
+			MesosTaskId: suite.runtime.GetMesosTaskId(),
+			Resource:    &pbtask.ResourceConfig{CpuLimit: 2, MemLimitMb: 200},
+		}).
+		Return(&resmgrsvc.UpdateTaskResourcesResponse{}, nil)
+	suite.cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), map[uint32]jobmgrcommon.RuntimeDiff{
+			suite.instanceID: {
+				jobmgrcommon.ConfigVersionField:     uint64(2),
+				jobmgrcommon.TerminationStatusField: nil,
+				jobmgrcommon.MessageField:           "Task resized in place",
+				jobmgrcommon.ReasonField:            "",
+			},
+		}, false).
+		Return(nil, nil, nil)
+	suite.taskGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+	suite.cachedJob.EXPECT().GetJobType().Return(pbjob.JobType_SERVICE)
+	suite.jobGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(TaskResize(context.Background(), suite.taskEnt))
+}
+
+// TestTaskResizeConfigChanged tests that a task whose config has changed
+// beyond its resources is restarted.
+func (suite *taskResizeTestSuite) TestTaskResizeConfigChanged() {
+	suite.expectGetRuntime()
+	suite.expectTaskConfigs("ls")
+	suite.expectTaskStop()
+
+	suite.NoError(TaskResize(context.Background(), suite.taskEnt))
+}
+
+// TestTaskResizeNoPodSpec tests that a task without a pod spec is
+// restarted.
+func (suite *taskResizeTestSuite) TestTaskResizeNoPodSpec() {
+	suite.expectGetRuntime()
+	suite.expectTaskConfigs("echo")
+	suite.taskConfigV2Ops.EXPECT().
+		GetPodSpec(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(nil, nil)
+	suite.expectTaskStop()
+
+	suite.NoError(TaskResize(context.Background(), suite.taskEnt))
+}
+
+// TestTaskResizeFailure tests that a task which cannot be resized by the
+// host is restarted.
+func (suite *taskResizeTestSuite) TestTaskResizeFailure() {
+	suite.expectGetRuntime()
+	suite.expectTaskConfigs("echo")
+	suite.taskConfigV2Ops.EXPECT().
+		GetPodSpec(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(suite.spec, nil)
+	suite.lmMock.EXPECT().
+		UpdatePodResources(
+			gomock.Any(),
+			suite.runtime.GetMesosTaskId().GetValue(),
+			"host-0",
+			suite.spec,
+		).Return(yarpcerrors.ResourceExhaustedErrorf("no headroom"))
+	suite.expectTaskStop()
+
+	suite.NoError(TaskResize(context.Background(), suite.taskEnt))
+}
+
+// TestTaskResizeResmgrFailure tests that the resize is retried when the
+// allocation of the task cannot be updated in resource manager.
+func (suite *taskResizeTestSuite) TestTaskResizeResmgrFailure() {
+	suite.expectGetRuntime()
+	suite.expectTaskConfigs("echo")
+	suite.taskConfigV2Ops.EXPECT().
+		GetPodSpec(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(suite.spec, nil)
+	suite.lmMock.EXPECT().
+		UpdatePodResources(
+			gomock.Any(),
+			suite.runtime.GetMesosTaskId().GetValue(),
+			"host-0",
+			suite.spec,
+		).Return(nil)
+	suite.resmgrClient.EXPECT().
+		UpdateTaskResources(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("resmgr unavailable"))
+
+	suite.Error(TaskResize(context.Background(), suite.taskEnt))
+}
+
+// TestTaskResizeNoJob tests that TaskResize is a noop for a job
+// not in the cache.
+func (suite *taskResizeTestSuite) TestTaskResizeNoJob() {
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(nil)
+
+	suite.NoError(TaskResize(context.Background(), suite.taskEnt))
+}
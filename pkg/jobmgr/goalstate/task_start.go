@@ -84,12 +84,35 @@ func TaskStart(ctx context.Context, entity goalstate.Entity) error {
 		return fmt.Errorf("task info not found for %v", taskID)
 	}
 
+	if err := jobmgr_task.ApplyInstanceOverrides(
+		ctx,
+		goalStateDriver.instanceOverrideOps,
+		taskEnt.jobID,
+		cachedConfig.GetInstanceConfigMergeStrategy(),
+		[]*task.TaskInfo{taskInfo}); err != nil {
+		log.WithError(err).
+			WithField("job_id", taskEnt.jobID).
+			WithField("instance_id", taskEnt.instanceID).
+			Error("failed to get instance overrides in task start")
+		return err
+	}
+
+	tasks, correlationIDs := jobmgr_task.SetLaunchCorrelationIDs(
+		[]*task.TaskInfo{taskInfo})
+	taskInfo = tasks[0]
+	log.WithField("task_id", taskID).
+		WithField("mesos_task_id", taskInfo.GetRuntime().GetMesosTaskId().GetValue()).
+		WithField("launch_correlation_id", taskInfo.GetRuntime().GetLaunchCorrelationId()).
+		Info("sending task for placement")
+
 	// TODO: Investigate how to create proper gangs for scheduling (currently, task are treat independently)
 	response, err := jobmgr_task.EnqueueGangs(
 		ctx,
-		[]*task.TaskInfo{taskInfo},
+		tasks,
 		cachedConfig,
-		goalStateDriver.resmgrClient)
+		goalStateDriver.resmgrClient,
+		&goalStateDriver.cfg.Enqueue,
+		goalStateDriver.enqueueScope)
 
 	// Parse the EnqueueGangs response to determine if the task is successfully enqueued
 	// or has been previously enqueued, and should transition to PENDING state.
@@ -115,14 +138,20 @@ func TaskStart(ctx context.Context, entity goalstate.Entity) error {
 		return yarpcerrors.InternalErrorf("failed to enqueue task into resource manager %v", taskID)
 	}
 
-	// Update task state to PENDING
+	// Update task state to PENDING, and persist the launch correlation ID
+	// if it was generated for this enqueue.
 	runtime := taskInfo.GetRuntime()
+	runtimeDiff := jobmgrcommon.RuntimeDiff{}
 	if runtime.GetState() != task.TaskState_PENDING {
+		runtimeDiff[jobmgrcommon.StateField] = task.TaskState_PENDING
+		runtimeDiff[jobmgrcommon.MessageField] = "Task sent for placement"
+	}
+	if len(correlationIDs) != 0 {
+		runtimeDiff[jobmgrcommon.LaunchCorrelationIDField] =
+			runtime.GetLaunchCorrelationId()
+	}
+	if len(runtimeDiff) != 0 {
 		var instancesToRetry []uint32
-		runtimeDiff := jobmgrcommon.RuntimeDiff{
-			jobmgrcommon.StateField:   task.TaskState_PENDING,
-			jobmgrcommon.MessageField: "Task sent for placement",
-		}
 		_, instancesToRetry, err = cachedJob.PatchTasks(
 			ctx,
 			map[uint32]jobmgrcommon.RuntimeDiff{taskEnt.instanceID: runtimeDiff},
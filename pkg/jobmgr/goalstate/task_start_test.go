@@ -32,7 +32,9 @@ import (
 	taskutil "github.com/uber/peloton/pkg/common/util/task"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	jobmgrtask "github.com/uber/peloton/pkg/jobmgr/task"
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -58,6 +60,7 @@ type TaskStartTestSuite struct {
 	jobID               *peloton.JobID
 	instanceID          uint32
 	taskEnt             *taskEntity
+	overrideOps         *objectmocks.MockInstanceOverrideOps
 }
 
 func TestTaskStart(t *testing.T) {
@@ -78,6 +81,16 @@ func (suite *TaskStartTestSuite) SetupTest() {
 	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
 	suite.mockVolumeStore = storemocks.NewMockPersistentVolumeStore(suite.ctrl)
 
+	// most tests do not care about instance overrides
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
+	suite.cachedConfig.EXPECT().
+		GetInstanceConfigMergeStrategy().
+		Return(nil).
+		AnyTimes()
 	suite.goalStateDriver = &driver{
 		jobEngine:    suite.jobGoalStateEngine,
 		taskEngine:   suite.taskGoalStateEngine,
@@ -88,6 +101,8 @@ func (suite *TaskStartTestSuite) SetupTest() {
 		volumeStore:  suite.mockVolumeStore,
 		mtx:          NewMetrics(tally.NoopScope),
 		cfg:          &Config{},
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
 	suite.instanceID = 0
@@ -144,17 +159,32 @@ func (suite *TaskStartTestSuite) TestTaskStartStateless() {
 		GetPlacementStrategy().
 		Return(job2.PlacementStrategy_PLACEMENT_STRATEGY_INVALID)
 
+	suite.cachedConfig.EXPECT().
+		GetOwner().
+		Return("")
+
+	suite.cachedConfig.EXPECT().
+		GetPlacementRelaxationPolicy().
+		Return(nil)
+
+	suite.cachedConfig.EXPECT().
+		GetPreemptionPolicy().
+		Return(nil)
+
 	suite.taskStore.EXPECT().
 		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
 		Return(taskInfo, nil)
 
-	request := &resmgrsvc.EnqueueGangsRequest{
-		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig),
-		ResPool: jobConfig.RespoolID,
-	}
-
+	var correlationID string
 	suite.resmgrClient.EXPECT().
-		EnqueueGangs(gomock.Any(), request).
+		EnqueueGangs(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, request *resmgrsvc.EnqueueGangsRequest) {
+			tasks := request.GetGangs()[0].GetTasks()
+			suite.Len(tasks, 1)
+			correlationID = tasks[0].GetLaunchCorrelationId()
+			suite.NotEmpty(correlationID)
+			suite.Equal(jobConfig.RespoolID, request.GetResPool())
+		}).
 		Return(nil, nil)
 
 	suite.cachedJob.EXPECT().
@@ -163,13 +193,16 @@ func (suite *TaskStartTestSuite) TestTaskStartStateless() {
 			runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff,
 			_ bool) {
 			suite.Equal(runtimeDiffs[suite.instanceID], jobmgrcommon.RuntimeDiff{
-				jobmgrcommon.StateField:   pbtask.TaskState_PENDING,
-				jobmgrcommon.MessageField: "Task sent for placement",
+				jobmgrcommon.StateField:               pbtask.TaskState_PENDING,
+				jobmgrcommon.MessageField:             "Task sent for placement",
+				jobmgrcommon.LaunchCorrelationIDField: correlationID,
 			})
 		}).Return(nil, nil, nil)
 
 	err := TaskStart(context.Background(), suite.taskEnt)
 	suite.NoError(err)
+	// the task info read from the store is not modified
+	suite.Empty(taskInfo.GetRuntime().GetLaunchCorrelationId())
 }
 
 func (suite *TaskStartTestSuite) TestTaskStartWithSlaMaxRunningInstances() {
@@ -297,6 +330,36 @@ func (suite *TaskStartTestSuite) TestTaskStartNoTaskInfo() {
 
 }
 
+// TestTaskStartInstanceOverrideError tests that the task is not enqueued
+// if the instance overrides of the job can't be read
+func (suite *TaskStartTestSuite) TestTaskStartInstanceOverrideError() {
+	overrideOps := objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.goalStateDriver.instanceOverrideOps = overrideOps
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob)
+
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(suite.cachedConfig, nil)
+
+	suite.cachedConfig.EXPECT().
+		GetSLA().
+		Return(&job2.SlaConfig{})
+
+	suite.taskStore.EXPECT().
+		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
+		Return(&pbtask.TaskInfo{InstanceId: suite.instanceID}, nil)
+
+	overrideOps.EXPECT().
+		GetAll(gomock.Any(), suite.jobID).
+		Return(nil, errors.New("db unavailable"))
+
+	err := TaskStart(context.Background(), suite.taskEnt)
+	suite.Error(err)
+}
+
 func (suite *TaskStartTestSuite) TestTaskStartEnqueueExist() {
 	jobConfig := &job2.JobConfig{
 		RespoolID: &peloton.ResourcePoolID{
@@ -308,7 +371,9 @@ func (suite *TaskStartTestSuite) TestTaskStartEnqueueExist() {
 		Config: &pbtask.TaskConfig{
 			Volume: &pbtask.PersistentVolumeConfig{},
 		},
-		Runtime: &pbtask.RuntimeInfo{},
+		Runtime: &pbtask.RuntimeInfo{
+			LaunchCorrelationId: "launch-correlation-id",
+		},
 	}
 	resmgrTask := taskutil.ConvertTaskToResMgrTask(taskInfo, jobConfig)
 	resmgrEnqueueFailures := map[string]*resmgrsvc.EnqueueGangsResponse{
@@ -370,6 +435,21 @@ func (suite *TaskStartTestSuite) TestTaskStartEnqueueExist() {
 		Return(job2.PlacementStrategy_PLACEMENT_STRATEGY_INVALID).
 		AnyTimes()
 
+	suite.cachedConfig.EXPECT().
+		GetOwner().
+		Return("").
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetPlacementRelaxationPolicy().
+		Return(nil).
+		AnyTimes()
+
+	suite.cachedConfig.EXPECT().
+		GetPreemptionPolicy().
+		Return(nil).
+		AnyTimes()
+
 	suite.taskStore.EXPECT().
 		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
 		Return(taskInfo, nil).
@@ -414,7 +494,9 @@ func (suite *TaskStartTestSuite) TestTaskStartTaskNotInCache() {
 		Config: &pbtask.TaskConfig{
 			Volume: &pbtask.PersistentVolumeConfig{},
 		},
-		Runtime: &pbtask.RuntimeInfo{},
+		Runtime: &pbtask.RuntimeInfo{
+			LaunchCorrelationId: "launch-correlation-id",
+		},
 	}
 
 	suite.jobFactory.EXPECT().
@@ -443,6 +525,18 @@ func (suite *TaskStartTestSuite) TestTaskStartTaskNotInCache() {
 		GetPlacementStrategy().
 		Return(job2.PlacementStrategy_PLACEMENT_STRATEGY_INVALID)
 
+	suite.cachedConfig.EXPECT().
+		GetOwner().
+		Return("")
+
+	suite.cachedConfig.EXPECT().
+		GetPlacementRelaxationPolicy().
+		Return(nil)
+
+	suite.cachedConfig.EXPECT().
+		GetPreemptionPolicy().
+		Return(nil)
+
 	suite.taskStore.EXPECT().
 		GetTaskByID(gomock.Any(), fmt.Sprintf("%s-%d", suite.jobID.GetValue(), suite.instanceID)).
 		Return(taskInfo, nil)
@@ -450,6 +544,8 @@ func (suite *TaskStartTestSuite) TestTaskStartTaskNotInCache() {
 	request := &resmgrsvc.EnqueueGangsRequest{
 		Gangs:   taskutil.ConvertToResMgrGangs([]*pbtask.TaskInfo{taskInfo}, jobConfig),
 		ResPool: jobConfig.RespoolID,
+		IdempotencyToken: jobmgrtask.GetEnqueueGangsToken(
+			[]*pbtask.TaskInfo{taskInfo}),
 	}
 
 	suite.resmgrClient.EXPECT().
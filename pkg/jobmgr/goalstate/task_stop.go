@@ -141,6 +141,7 @@ func stopInitializedTask(ctx context.Context, taskEnt *taskEntity) error {
 	if runtime.GetConfigVersion() != runtime.GetDesiredConfigVersion() {
 		// Kill is due to update, reset failure count
 		runtimeDiff[jobmgrcommon.FailureCountField] = uint32(0)
+		runtimeDiff[jobmgrcommon.ConsecutiveFailureCountField] = uint32(0)
 	}
 
 	// we do not need to handle `instancesToBeRetried` here since the task
@@ -203,8 +204,41 @@ func stopMesosTask(ctx context.Context, taskEnt *taskEntity, runtime *task.Runti
 	if err == nil {
 		// timeout for task kill
 		goalStateDriver.EnqueueTask(taskEnt.jobID, taskEnt.instanceID,
-			time.Now().Add(_defaultShutdownExecutorTimeout))
+			time.Now().Add(getShutdownExecutorTimeout(ctx, taskEnt, runtime)))
 		EnqueueJobWithDefaultDelay(taskEnt.jobID, goalStateDriver, cachedJob)
 	}
 	return err
 }
+
+// getShutdownExecutorTimeout returns the duration to wait after a kill is
+// sent to a task before it is force killed by shutting down its executor.
+// If the task config specifies a kill grace period, the executor is
+// expected to have terminated the task within that period, so the
+// timeout is the grace period plus a buffer. Otherwise, or if the task
+// config cannot be read, the default shutdown executor timeout is used.
+func getShutdownExecutorTimeout(
+	ctx context.Context,
+	taskEnt *taskEntity,
+	runtime *task.RuntimeInfo) time.Duration {
+	goalStateDriver := taskEnt.driver
+	taskConfig, _, err := goalStateDriver.taskConfigV2Ops.GetTaskConfig(
+		ctx,
+		taskEnt.jobID,
+		taskEnt.instanceID,
+		runtime.GetConfigVersion())
+	if err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"job_id":      taskEnt.jobID.GetValue(),
+				"instance_id": taskEnt.instanceID,
+			}).Info("failed to get task config, use default shutdown executor timeout")
+		return _defaultShutdownExecutorTimeout
+	}
+
+	gracePeriod := taskConfig.GetKillGracePeriodSeconds()
+	if gracePeriod == 0 {
+		return _defaultShutdownExecutorTimeout
+	}
+	return time.Duration(gracePeriod)*time.Second +
+		goalStateDriver.cfg.KillGracePeriodBuffer
+}
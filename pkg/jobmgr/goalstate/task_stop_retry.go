@@ -38,8 +38,9 @@ func TaskExecutorShutdown(ctx context.Context, entity goalstate.Entity) error {
 
 	// It is possible that jobmgr crashes or leader election changes when the task waiting on timeout
 	// Need to reenqueue the task after jobmgr recovers.
-	if time.Now().Sub(time.Unix(0, int64(runtime.GetRevision().GetUpdatedAt()))) < _defaultShutdownExecutorTimeout {
-		goalStateDriver.EnqueueTask(cachedTask.JobID(), cachedTask.ID(), time.Now().Add(_defaultShutdownExecutorTimeout))
+	timeout := getShutdownExecutorTimeout(ctx, taskEnt, runtime)
+	if time.Now().Sub(time.Unix(0, int64(runtime.GetRevision().GetUpdatedAt()))) < timeout {
+		goalStateDriver.EnqueueTask(cachedTask.JobID(), cachedTask.ID(), time.Now().Add(timeout))
 		return nil
 	}
 
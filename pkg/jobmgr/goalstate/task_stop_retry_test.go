@@ -16,6 +16,7 @@ package goalstate
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -27,6 +28,7 @@ import (
 	"github.com/uber/peloton/pkg/common/util"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -45,14 +47,16 @@ func TestTaskStopShutdownExecutor(t *testing.T) {
 	cachedJob := cachedmocks.NewMockJob(ctrl)
 	cachedTask := cachedmocks.NewMockTask(ctrl)
 	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
 
 	goalStateDriver := &driver{
-		jobEngine:  jobGoalStateEngine,
-		taskEngine: taskGoalStateEngine,
-		jobFactory: jobFactory,
-		lm:         lmMock,
-		mtx:        NewMetrics(tally.NoopScope),
-		cfg:        &Config{},
+		jobEngine:       jobGoalStateEngine,
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
 	}
 	goalStateDriver.cfg.normalize()
 
@@ -91,6 +95,10 @@ func TestTaskStopShutdownExecutor(t *testing.T) {
 	cachedTask.EXPECT().
 		GetRuntime(gomock.Any()).Return(runtime, nil)
 
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+
 	lmMock.EXPECT().
 		ShutdownExecutor(
 			gomock.Any(),
@@ -121,14 +129,16 @@ func TestTaskStopNoTimeout(t *testing.T) {
 	cachedJob := cachedmocks.NewMockJob(ctrl)
 	cachedTask := cachedmocks.NewMockTask(ctrl)
 	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
 
 	goalStateDriver := &driver{
-		jobEngine:  jobGoalStateEngine,
-		taskEngine: taskGoalStateEngine,
-		jobFactory: jobFactory,
-		lm:         lmMock,
-		mtx:        NewMetrics(tally.NoopScope),
-		cfg:        &Config{},
+		jobEngine:       jobGoalStateEngine,
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
 	}
 	goalStateDriver.cfg.normalize()
 
@@ -167,6 +177,10 @@ func TestTaskStopNoTimeout(t *testing.T) {
 	cachedTask.EXPECT().
 		GetRuntime(gomock.Any()).Return(runtime, nil)
 
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+
 	cachedTask.EXPECT().
 		JobID().Return(jobID)
 
@@ -180,3 +194,97 @@ func TestTaskStopNoTimeout(t *testing.T) {
 	err := TaskExecutorShutdown(context.Background(), taskEnt)
 	assert.NoError(t, err)
 }
+
+// TestTaskStopShutdownExecutorAfterKillGracePeriod tests that the executor
+// is shutdown once the kill grace period of the task plus the configured
+// buffer has elapsed, and not before when the task config cannot be read.
+func TestTaskStopShutdownExecutorAfterKillGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskGoalStateEngine := goalstatemocks.NewMockEngine(ctrl)
+	jobFactory := cachedmocks.NewMockJobFactory(ctrl)
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	cachedTask := cachedmocks.NewMockTask(ctrl)
+	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
+
+	goalStateDriver := &driver{
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
+	}
+	goalStateDriver.cfg.normalize()
+
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	instanceID := uint32(0)
+
+	taskEnt := &taskEntity{
+		jobID:      jobID,
+		instanceID: instanceID,
+		driver:     goalStateDriver,
+	}
+
+	taskID := &mesos_v1.TaskID{
+		Value: &[]string{"3c8a3c3e-71e3-49c5-9aed-2929823f595c-1-3c8a3c3e-71e3-49c5-9aed-2929823f5957"}[0],
+	}
+
+	agentID := &mesos_v1.AgentID{
+		Value: util.PtrPrintf("host-agent-0"),
+	}
+
+	runtime := &pbtask.RuntimeInfo{
+		State:       pbtask.TaskState_KILLING,
+		MesosTaskId: taskID,
+		AgentID:     agentID,
+		Revision: &peloton.ChangeLog{
+			UpdatedAt: uint64(time.Now().Add(-2 * time.Minute).UnixNano()),
+		},
+	}
+
+	jobFactory.EXPECT().
+		GetJob(jobID).Return(cachedJob).Times(2)
+
+	cachedJob.EXPECT().
+		GetTask(instanceID).Return(cachedTask).Times(2)
+
+	cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(runtime, nil).Times(2)
+
+	// kill grace period and buffer have elapsed
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(&pbtask.TaskConfig{KillGracePeriodSeconds: 30}, nil, nil)
+
+	lmMock.EXPECT().
+		ShutdownExecutor(
+			gomock.Any(),
+			taskID.GetValue(),
+			agentID.GetValue(),
+			nil).
+		Return(nil)
+
+	err := TaskExecutorShutdown(context.Background(), taskEnt)
+	assert.NoError(t, err)
+
+	// task config cannot be read, the default timeout is used
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(nil, nil, fmt.Errorf("fake db error"))
+
+	cachedTask.EXPECT().
+		JobID().Return(jobID)
+
+	cachedTask.EXPECT().
+		ID().Return(instanceID)
+
+	taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err = TaskExecutorShutdown(context.Background(), taskEnt)
+	assert.NoError(t, err)
+}
@@ -32,6 +32,7 @@ import (
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 
@@ -51,14 +52,16 @@ func TestTaskStop(t *testing.T) {
 	cachedJob := cachedmocks.NewMockJob(ctrl)
 	cachedTask := cachedmocks.NewMockTask(ctrl)
 	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
 
 	goalStateDriver := &driver{
-		jobEngine:  jobGoalStateEngine,
-		taskEngine: taskGoalStateEngine,
-		jobFactory: jobFactory,
-		lm:         lmMock,
-		mtx:        NewMetrics(tally.NoopScope),
-		cfg:        &Config{},
+		jobEngine:       jobGoalStateEngine,
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
 	}
 	goalStateDriver.cfg.normalize()
 
@@ -103,6 +106,10 @@ func TestTaskStop(t *testing.T) {
 		}, false,
 		)
 
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+
 	lmMock.EXPECT().Kill(
 		gomock.Any(),
 		taskID.GetValue(),
@@ -152,14 +159,16 @@ func TestTaskStopForInPlaceUpdate(t *testing.T) {
 	cachedJob := cachedmocks.NewMockJob(ctrl)
 	cachedTask := cachedmocks.NewMockTask(ctrl)
 	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
 
 	goalStateDriver := &driver{
-		jobEngine:  jobGoalStateEngine,
-		taskEngine: taskGoalStateEngine,
-		jobFactory: jobFactory,
-		lm:         lmMock,
-		mtx:        NewMetrics(tally.NoopScope),
-		cfg:        &Config{},
+		jobEngine:       jobGoalStateEngine,
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
 	}
 	goalStateDriver.cfg.normalize()
 
@@ -205,6 +214,10 @@ func TestTaskStopForInPlaceUpdate(t *testing.T) {
 		}, false,
 		)
 
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, gomock.Any()).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+
 	lmMock.EXPECT().Kill(
 		gomock.Any(),
 		taskID.GetValue(),
@@ -419,3 +432,91 @@ func TestTaskStopIfPendingCallsKillOnResmgr(t *testing.T) {
 	err := TaskStop(context.Background(), taskEnt)
 	assert.NoError(t, err)
 }
+
+// TestTaskStopWithKillGracePeriod tests that the task is enqueued to be
+// force killed after its kill grace period plus the configured buffer.
+func TestTaskStopWithKillGracePeriod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobGoalStateEngine := goalstatemocks.NewMockEngine(ctrl)
+	taskGoalStateEngine := goalstatemocks.NewMockEngine(ctrl)
+	jobFactory := cachedmocks.NewMockJobFactory(ctrl)
+	cachedJob := cachedmocks.NewMockJob(ctrl)
+	cachedTask := cachedmocks.NewMockTask(ctrl)
+	lmMock := lmmocks.NewMockManager(ctrl)
+	taskConfigV2Ops := objectmocks.NewMockTaskConfigV2Ops(ctrl)
+
+	goalStateDriver := &driver{
+		jobEngine:       jobGoalStateEngine,
+		taskEngine:      taskGoalStateEngine,
+		jobFactory:      jobFactory,
+		lm:              lmMock,
+		taskConfigV2Ops: taskConfigV2Ops,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
+	}
+	goalStateDriver.cfg.normalize()
+
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	instanceID := uint32(0)
+
+	taskEnt := &taskEntity{
+		jobID:      jobID,
+		instanceID: instanceID,
+		driver:     goalStateDriver,
+	}
+
+	taskID := &mesos_v1.TaskID{
+		Value: &[]string{"3c8a3c3e-71e3-49c5-9aed-2929823f595c-1-3c8a3c3e-71e3-49c5-9aed-2929823f5957"}[0],
+	}
+
+	runtime := &pbtask.RuntimeInfo{
+		State:         pbtask.TaskState_RUNNING,
+		MesosTaskId:   taskID,
+		ConfigVersion: 2,
+	}
+
+	jobFactory.EXPECT().
+		GetJob(jobID).Return(cachedJob).Times(2)
+
+	cachedJob.EXPECT().
+		GetTask(instanceID).Return(cachedTask).Times(2)
+
+	cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(runtime, nil)
+
+	lmMock.EXPECT().Kill(
+		gomock.Any(),
+		taskID.GetValue(),
+		"",
+		nil,
+	).Return(nil)
+
+	cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false)
+
+	taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), jobID, instanceID, uint64(2)).
+		Return(&pbtask.TaskConfig{KillGracePeriodSeconds: 60}, nil, nil)
+
+	cachedJob.EXPECT().
+		GetJobType().Return(pbjob.JobType_BATCH)
+
+	taskGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(entity goalstate.Entity, deadline time.Time) {
+			// The test should not take more than one min
+			assert.Equal(t,
+				time.Minute+_defaultKillGracePeriodBuffer,
+				deadline.Sub(time.Now()).Round(time.Minute))
+		}).
+		Return()
+
+	jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	err := TaskStop(context.Background(), taskEnt)
+	assert.NoError(t, err)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -68,6 +69,11 @@ func TestTaskStateAndGoalState(t *testing.T) {
 	// Test fetching the entity ID
 	assert.Equal(t, taskID, taskEnt.GetID())
 
+	// Test fetching the prerequisites of the entity
+	prerequisites := taskEnt.GetPrerequisites()
+	assert.Len(t, prerequisites, 1)
+	assert.Equal(t, jobID.GetValue(), prerequisites[0].Entity.GetID())
+
 	// Test fetching the entity state
 	jobFactory.EXPECT().
 		AddJob(jobID).
@@ -162,6 +168,53 @@ func TestTaskActionList(t *testing.T) {
 	}
 }
 
+// TestTaskActionListResize tests that the config of a running task is
+// changed by resizing the task only when the cluster supports it
+func TestTaskActionListResize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lmMock := lmmocks.NewMockManager(ctrl)
+	taskEnt := &taskEntity{
+		jobID:      &peloton.JobID{Value: uuid.NewRandom().String()},
+		instanceID: uint32(0),
+		driver: &driver{
+			lm:  lmMock,
+			cfg: &Config{EnablePodResize: true},
+		},
+	}
+	taskState := cached.TaskStateVector{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 1,
+	}
+	taskGoalState := cached.TaskStateVector{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 2,
+	}
+
+	lmMock.EXPECT().SupportsPodResize().Return(true)
+	_, _, actions := taskEnt.GetActionList(taskState, taskGoalState)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, string(ResizeAction), actions[0].Name)
+
+	lmMock.EXPECT().SupportsPodResize().Return(false)
+	_, _, actions = taskEnt.GetActionList(taskState, taskGoalState)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, string(StopAction), actions[0].Name)
+
+	taskEnt.driver.cfg.EnablePodResize = false
+	_, _, actions = taskEnt.GetActionList(taskState, taskGoalState)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, string(StopAction), actions[0].Name)
+
+	// a task starting with the old config is restarted
+	taskEnt.driver.cfg.EnablePodResize = true
+	taskState.State = pbtask.TaskState_STARTING
+	_, _, actions = taskEnt.GetActionList(taskState, taskGoalState)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, string(StopAction), actions[0].Name)
+}
+
 func TestEngineSuggestActionGoalKilled(t *testing.T) {
 	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
 	instanceID := uint32(0)
@@ -272,6 +325,12 @@ func TestEngineSuggestActionGoalRunning(t *testing.T) {
 			desiredConfigVersion: 1,
 			action:               StopAction,
 		},
+		{
+			currentState:         pbtask.TaskState_STARTING,
+			configVersion:        0,
+			desiredConfigVersion: 1,
+			action:               StopAction,
+		},
 		{
 			currentState:         pbtask.TaskState_KILLED,
 			configVersion:        0,
@@ -46,6 +46,11 @@ const (
 	CheckForAbortAction UpdateAction = "check_for_abort"
 	// WriteProgressUpdateAction writes the latest update progress
 	WriteProgressUpdateAction UpdateAction = "write_progress"
+	// CheckHealthGateAction rolls the update back if its health gate trips
+	CheckHealthGateAction UpdateAction = "check_health_gate"
+	// PromoteCanaryAction promotes the update to the remaining instances
+	// once its canaries are healthy
+	PromoteCanaryAction UpdateAction = "promote_canary"
 )
 
 // _updateActionsMaps maps the UpdateAction string to the Action function.
@@ -105,6 +110,19 @@ func (u *updateEntity) GetID() string {
 	return u.jobID.GetValue()
 }
 
+// GetPrerequisites returns the job of the update, so that the update
+// actions run after a pending evaluation of the job, which refreshes
+// the job runtime.
+func (u *updateEntity) GetPrerequisites() []goalstate.Prerequisite {
+	u.driver.RLock()
+	defer u.driver.RUnlock()
+
+	return []goalstate.Prerequisite{{
+		Engine: u.driver.jobEngine,
+		Entity: NewJobEntity(u.jobID, u.driver),
+	}}
+}
+
 func (u *updateEntity) GetState() interface{} {
 	cachedJob := u.driver.jobFactory.AddJob(u.jobID)
 	return cachedJob.AddWorkflow(u.id).GetState()
@@ -147,6 +165,24 @@ func (u *updateEntity) GetActionList(
 		})
 	}
 
+	// the health gate and the canaries are checked before rolling
+	// forward any further
+	if actionStr == RunUpdateAction &&
+		updateState.State == update.State_ROLLING_FORWARD {
+		// the health gate may roll the update back, in which case it
+		// must not be run forward by the rest of the action list
+		action = UpdateRunIfRollingForward
+		actions = append(actions,
+			goalstate.Action{
+				Name:    string(CheckHealthGateAction),
+				Execute: UpdateCheckHealthGate,
+			},
+			goalstate.Action{
+				Name:    string(PromoteCanaryAction),
+				Execute: UpdatePromoteCanary,
+			})
+	}
+
 	if action != nil {
 		actions = append(actions, goalstate.Action{
 			Name:    string(actionStr),
@@ -160,6 +160,7 @@ func UpdateUntrack(ctx context.Context, entity goalstate.Entity) error {
 	// clean up the update from cache and goal state
 	goalStateDriver.DeleteUpdate(jobID, updateEnt.id)
 	cachedJob.ClearWorkflow(updateEnt.id)
+	goalStateDriver.healthGates.remove(updateEnt.id.GetValue())
 	goalStateDriver.mtx.updateMetrics.UpdateUntrack.Inc(1)
 
 	// check if we have another job update to run
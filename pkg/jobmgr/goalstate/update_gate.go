@@ -0,0 +1,373 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// updateHealth is the health of the instances of an update.
+type updateHealth struct {
+	// done and failed map the instances which have been updated
+	// successfully or have failed to the time they were first seen so
+	done   map[uint32]time.Time
+	failed map[uint32]time.Time
+	// time at which all the canaries were first seen updated
+	canariesCompleted time.Time
+}
+
+// healthGateTracker tracks the outcome of the instances of the updates
+// rolling forward with a health gate or a canary phase, to compute
+// their failure rate over the window of the gate and the bake time of
+// the canaries. It is only kept in memory: after a leader change, the
+// failure rate window and the bake time of the canaries start over.
+// The zero value is ready to use.
+type healthGateTracker struct {
+	sync.Mutex
+
+	// updates maps the ID of an update to its health
+	updates map[string]*updateHealth
+}
+
+// get returns the health of an update, it must be called with the
+// lock held.
+func (t *healthGateTracker) get(updateID string) *updateHealth {
+	if t.updates == nil {
+		t.updates = make(map[string]*updateHealth)
+	}
+	health, ok := t.updates[updateID]
+	if !ok {
+		health = &updateHealth{
+			done:   make(map[uint32]time.Time),
+			failed: make(map[uint32]time.Time),
+		}
+		t.updates[updateID] = health
+	}
+	return health
+}
+
+// record records the instances of an update which have been updated
+// successfully or have failed, and returns how many of each were first
+// seen within the window. A zero window covers the whole update.
+func (t *healthGateTracker) record(
+	updateID string,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	window time.Duration,
+) (numDone int, numFailed int) {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	health := t.get(updateID)
+	count := func(seen map[uint32]time.Time, instances []uint32) int {
+		n := 0
+		for _, instID := range instances {
+			firstSeen, ok := seen[instID]
+			if !ok {
+				firstSeen = now
+				seen[instID] = now
+			}
+			if window == 0 || now.Sub(firstSeen) <= window {
+				n++
+			}
+		}
+		return n
+	}
+	return count(health.done, instancesDone),
+		count(health.failed, instancesFailed)
+}
+
+// canariesCompleted returns the time at which all the canaries of an
+// update were first seen updated.
+func (t *healthGateTracker) canariesCompleted(updateID string) time.Time {
+	t.Lock()
+	defer t.Unlock()
+
+	health := t.get(updateID)
+	if health.canariesCompleted.IsZero() {
+		health.canariesCompleted = time.Now()
+	}
+	return health.canariesCompleted
+}
+
+// remove stops tracking an update.
+func (t *healthGateTracker) remove(updateID string) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.updates, updateID)
+}
+
+// UpdateCheckHealthGate checks the health gate of an update rolling
+// forward, and rolls the update back if the gate trips.
+func UpdateCheckHealthGate(ctx context.Context, entity goalstate.Entity) error {
+	updateEnt := entity.(*updateEntity)
+	goalStateDriver := updateEnt.driver
+
+	cachedWorkflow, cachedJob, err := fetchWorkflowAndJobFromCache(
+		ctx, updateEnt.jobID, updateEnt.id, goalStateDriver)
+	if err != nil || cachedWorkflow == nil || cachedJob == nil {
+		return err
+	}
+
+	gate := cachedWorkflow.GetUpdateConfig().GetHealthGate()
+	if gate == nil ||
+		cachedWorkflow.GetState().State != pbupdate.State_ROLLING_FORWARD {
+		return nil
+	}
+
+	reason := checkHealthGate(
+		cachedJob,
+		cachedWorkflow,
+		gate,
+		&goalStateDriver.healthGates,
+	)
+	if len(reason) == 0 {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": updateEnt.id.GetValue(),
+		"job_id":    updateEnt.jobID.GetValue(),
+		"reason":    reason,
+	}).Info("update health gate tripped")
+	goalStateDriver.mtx.updateMetrics.UpdateGateTripped.Inc(1)
+
+	if goalStateDriver.isFrozen() {
+		// the update is rolled back once the cluster is unfrozen, it
+		// does not roll forward in the meantime since update runs are
+		// halted too
+		goalStateDriver.mtx.updateMetrics.UpdateRollbackFrozen.Inc(1)
+		goalStateDriver.EnqueueUpdate(
+			updateEnt.jobID,
+			updateEnt.id,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		return nil
+	}
+
+	if err := cachedWorkflow.TripHealthGate(ctx, reason); err != nil {
+		return err
+	}
+
+	if err := rollbackUpdate(
+		ctx,
+		cachedJob,
+		cachedWorkflow,
+		cachedWorkflow.GetInstancesDone(),
+		cachedWorkflow.GetInstancesFailed(),
+		cachedWorkflow.GetInstancesCurrent(),
+	); err != nil {
+		return err
+	}
+	// the update is no longer rolling forward, so the run action which
+	// follows in the action list does not run it
+	goalStateDriver.EnqueueUpdate(updateEnt.jobID, updateEnt.id, time.Now())
+	return nil
+}
+
+// UpdateRunIfRollingForward runs an update which is still rolling
+// forward. It follows the check of the health gate of the update in
+// the action list, and skips the run once the gate has rolled the
+// update back; the update is enqueued again by the rollback.
+func UpdateRunIfRollingForward(ctx context.Context, entity goalstate.Entity) error {
+	updateEnt := entity.(*updateEntity)
+
+	cachedWorkflow, cachedJob, err := fetchWorkflowAndJobFromCache(
+		ctx, updateEnt.jobID, updateEnt.id, updateEnt.driver)
+	if err != nil || cachedWorkflow == nil || cachedJob == nil {
+		return err
+	}
+
+	if cachedWorkflow.GetState().State != pbupdate.State_ROLLING_FORWARD {
+		return nil
+	}
+	return UpdateRun(ctx, entity)
+}
+
+// checkHealthGate returns the reason the health gate of an update
+// trips, empty if the gate passes.
+func checkHealthGate(
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	gate *pbupdate.HealthGate,
+	tracker *healthGateTracker,
+) string {
+	if maxUnavailable := gate.GetMaxUnavailableInstances(); maxUnavailable != 0 {
+		if unavailable := getNumUnavailableInstances(cachedJob, cachedUpdate); unavailable > maxUnavailable {
+			return fmt.Sprintf("%d instances unavailable, limit is %d",
+				unavailable, maxUnavailable)
+		}
+	}
+
+	if maxFailureRate := gate.GetMaxFailureRate(); maxFailureRate != 0 {
+		numDone, numFailed := tracker.record(
+			cachedUpdate.ID().GetValue(),
+			cachedUpdate.GetInstancesDone(),
+			cachedUpdate.GetInstancesFailed(),
+			time.Duration(gate.GetWindowSecs())*time.Second,
+		)
+		if numDone+numFailed == 0 {
+			return ""
+		}
+		failureRate := float64(numFailed) / float64(numDone+numFailed)
+		if failureRate > maxFailureRate {
+			return fmt.Sprintf("%d of %d instances failed, limit is %.2f",
+				numFailed, numDone+numFailed, maxFailureRate)
+		}
+	}
+	return ""
+}
+
+// getNumUnavailableInstances returns the number of instances processed
+// by an update which are not running while their goal state is running.
+// The instances the update has not reached yet are not counted.
+func getNumUnavailableInstances(
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+) uint32 {
+	var unavailable uint32
+	for _, instances := range [][]uint32{
+		cachedUpdate.GetInstancesDone(),
+		cachedUpdate.GetInstancesFailed(),
+		cachedUpdate.GetInstancesCurrent(),
+	} {
+		for _, instID := range instances {
+			cachedTask := cachedJob.GetTask(instID)
+			if cachedTask == nil {
+				continue
+			}
+			if cachedTask.GoalState().State == pbtask.TaskState_RUNNING &&
+				cachedTask.CurrentState().State != pbtask.TaskState_RUNNING {
+				unavailable++
+			}
+		}
+	}
+	return unavailable
+}
+
+// UpdatePromoteCanary promotes an update to its remaining instances
+// once all its canaries have been updated and have baked. It runs
+// after the health gate of the update has been checked, so the canaries
+// are healthy when the update is promoted.
+func UpdatePromoteCanary(ctx context.Context, entity goalstate.Entity) error {
+	updateEnt := entity.(*updateEntity)
+	goalStateDriver := updateEnt.driver
+
+	cachedWorkflow, cachedJob, err := fetchWorkflowAndJobFromCache(
+		ctx, updateEnt.jobID, updateEnt.id, goalStateDriver)
+	if err != nil || cachedWorkflow == nil || cachedJob == nil {
+		return err
+	}
+
+	updateConfig := cachedWorkflow.GetUpdateConfig()
+	if !isCanaryPending(cachedWorkflow, updateConfig) ||
+		cachedWorkflow.GetState().State != pbupdate.State_ROLLING_FORWARD {
+		return nil
+	}
+
+	numProcessed := len(cachedWorkflow.GetInstancesDone()) +
+		len(cachedWorkflow.GetInstancesFailed())
+	if len(cachedWorkflow.GetInstancesCurrent()) != 0 ||
+		numProcessed < getNumCanaries(cachedWorkflow, updateConfig) {
+		// the canaries are still being updated
+		return nil
+	}
+
+	promoteAt := goalStateDriver.healthGates.
+		canariesCompleted(updateEnt.id.GetValue()).
+		Add(time.Duration(updateConfig.GetCanary().GetBakeTimeSecs()) * time.Second)
+	if time.Now().Before(promoteAt) {
+		goalStateDriver.EnqueueUpdate(updateEnt.jobID, updateEnt.id, promoteAt)
+		return nil
+	}
+
+	if goalStateDriver.isFrozen() {
+		goalStateDriver.mtx.updateMetrics.UpdatePromoteFrozen.Inc(1)
+		goalStateDriver.EnqueueUpdate(
+			updateEnt.jobID,
+			updateEnt.id,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+		return nil
+	}
+
+	if err := cachedWorkflow.PromoteCanary(ctx); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": updateEnt.id.GetValue(),
+		"job_id":    updateEnt.jobID.GetValue(),
+		"canaries":  numProcessed,
+	}).Info("update canaries promoted")
+	goalStateDriver.mtx.updateMetrics.UpdateCanaryPromoted.Inc(1)
+	return nil
+}
+
+// isCanaryPending returns true if an update has a canary phase, and its
+// canaries have not been promoted yet.
+func isCanaryPending(
+	cachedUpdate cached.Update,
+	updateConfig *pbupdate.UpdateConfig,
+) bool {
+	return updateConfig.GetCanary().GetInstances() != 0 &&
+		!isUpdateRollback(cachedUpdate) &&
+		!cachedUpdate.IsCanaryPromoted()
+}
+
+// getNumCanaries returns the number of canaries of an update.
+func getNumCanaries(
+	cachedUpdate cached.Update,
+	updateConfig *pbupdate.UpdateConfig,
+) int {
+	numCanaries := int(updateConfig.GetCanary().GetInstances())
+	if numInstances := len(cachedUpdate.GetGoalState().Instances); numInstances < numCanaries {
+		return numInstances
+	}
+	return numCanaries
+}
+
+// limitInstancesToProcess returns at most limit instances to process,
+// taking the instances to add first, then the instances to update and
+// finally the instances to remove.
+func limitInstancesToProcess(
+	limit int,
+	instancesToAdd []uint32,
+	instancesToUpdate []uint32,
+	instancesToRemove []uint32,
+) ([]uint32, []uint32, []uint32) {
+	take := func(instances []uint32) []uint32 {
+		if limit <= 0 {
+			return nil
+		}
+		if len(instances) > limit {
+			instances = instances[:limit]
+		}
+		limit -= len(instances)
+		return instances
+	}
+	return take(instancesToAdd), take(instancesToUpdate), take(instancesToRemove)
+}
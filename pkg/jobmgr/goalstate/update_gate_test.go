@@ -0,0 +1,443 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type UpdateGateTestSuite struct {
+	suite.Suite
+
+	ctrl                  *gomock.Controller
+	jobFactory            *cachedmocks.MockJobFactory
+	cachedJob             *cachedmocks.MockJob
+	cachedUpdate          *cachedmocks.MockUpdate
+	updateGoalStateEngine *goalstatemocks.MockEngine
+	goalStateDriver       *driver
+	jobID                 *peloton.JobID
+	updateID              *peloton.UpdateID
+	updateEnt             *updateEntity
+}
+
+func TestUpdateGate(t *testing.T) {
+	suite.Run(t, new(UpdateGateTestSuite))
+}
+
+func (suite *UpdateGateTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedUpdate = cachedmocks.NewMockUpdate(suite.ctrl)
+	suite.updateGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.goalStateDriver = &driver{
+		jobFactory:   suite.jobFactory,
+		updateEngine: suite.updateGoalStateEngine,
+		mtx:          NewMetrics(tally.NoopScope),
+		cfg:          &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.updateID = &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.updateEnt = &updateEntity{
+		id:     suite.updateID,
+		jobID:  suite.jobID,
+		driver: suite.goalStateDriver,
+	}
+
+	suite.jobFactory.EXPECT().
+		GetJob(suite.jobID).
+		Return(suite.cachedJob).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		AddWorkflow(suite.updateID).
+		Return(suite.cachedUpdate).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(suite.jobID).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		ID().
+		Return(suite.updateID).
+		AnyTimes()
+}
+
+func (suite *UpdateGateTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectUpdate sets up the cached update with the given config and progress
+func (suite *UpdateGateTestSuite) expectUpdate(
+	updateConfig *pbupdate.UpdateConfig,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+) {
+	suite.cachedUpdate.EXPECT().
+		GetUpdateConfig().
+		Return(updateConfig).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_FORWARD,
+		}).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetGoalState().
+		Return(&cached.UpdateStateVector{
+			Instances: []uint32{0, 1, 2, 3, 4},
+		}).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetInstancesDone().
+		Return(instancesDone).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetInstancesFailed().
+		Return(instancesFailed).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetInstancesCurrent().
+		Return(instancesCurrent).
+		AnyTimes()
+}
+
+// TestHealthGateTrackerRecord tests computing the outcome of the
+// instances of an update over a window
+func (suite *UpdateGateTestSuite) TestHealthGateTrackerRecord() {
+	tracker := &healthGateTracker{}
+	updateID := suite.updateID.GetValue()
+
+	numDone, numFailed := tracker.record(
+		updateID, []uint32{0, 1}, []uint32{2}, time.Minute)
+	suite.Equal(2, numDone)
+	suite.Equal(1, numFailed)
+
+	// instances first seen before the window are not counted
+	tracker.updates[updateID].done[0] = time.Now().Add(-2 * time.Minute)
+	tracker.updates[updateID].failed[2] = time.Now().Add(-2 * time.Minute)
+	numDone, numFailed = tracker.record(
+		updateID, []uint32{0, 1, 3}, []uint32{2}, time.Minute)
+	suite.Equal(2, numDone)
+	suite.Equal(0, numFailed)
+
+	// a zero window covers the whole update
+	numDone, numFailed = tracker.record(
+		updateID, []uint32{0, 1, 3}, []uint32{2}, 0)
+	suite.Equal(3, numDone)
+	suite.Equal(1, numFailed)
+
+	// the completion time of the canaries is the first one seen
+	completed := tracker.canariesCompleted(updateID)
+	suite.Equal(completed, tracker.canariesCompleted(updateID))
+
+	tracker.remove(updateID)
+	suite.Empty(tracker.updates)
+}
+
+// TestLimitInstancesToProcess tests limiting the instances processed
+// in an update run
+func (suite *UpdateGateTestSuite) TestLimitInstancesToProcess() {
+	toAdd, toUpdate, toRemove := limitInstancesToProcess(
+		3, []uint32{0}, []uint32{1, 2, 3}, []uint32{4})
+	suite.Equal([]uint32{0}, toAdd)
+	suite.Equal([]uint32{1, 2}, toUpdate)
+	suite.Empty(toRemove)
+
+	toAdd, toUpdate, toRemove = limitInstancesToProcess(
+		-1, []uint32{0}, []uint32{1, 2, 3}, []uint32{4})
+	suite.Empty(toAdd)
+	suite.Empty(toUpdate)
+	suite.Empty(toRemove)
+
+	toAdd, toUpdate, toRemove = limitInstancesToProcess(
+		10, []uint32{0}, []uint32{1, 2, 3}, []uint32{4})
+	suite.Equal([]uint32{0}, toAdd)
+	suite.Equal([]uint32{1, 2, 3}, toUpdate)
+	suite.Equal([]uint32{4}, toRemove)
+}
+
+// TestUpdateCheckHealthGateNoGate tests checking an update without
+// a health gate
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGateNoGate() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{}, nil, nil, nil)
+
+	suite.NoError(UpdateCheckHealthGate(context.Background(), suite.updateEnt))
+}
+
+// TestUpdateCheckHealthGatePass tests checking the health gate of an
+// update which passes
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGatePass() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		BatchSize: 1,
+		HealthGate: &pbupdate.HealthGate{
+			MaxUnavailableInstances: 1,
+			MaxFailureRate:          0.5,
+		},
+	}, []uint32{0, 1}, []uint32{2}, []uint32{3})
+
+	// the instance 4 is not processed by the update yet
+	suite.expectTasks(pbtask.TaskState_RUNNING, pbtask.TaskState_KILLING)
+
+	suite.NoError(UpdateCheckHealthGate(context.Background(), suite.updateEnt))
+}
+
+// TestUpdateCheckHealthGateUnavailable tests rolling back an update
+// with too many unavailable instances
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGateUnavailable() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		BatchSize: 1,
+		HealthGate: &pbupdate.HealthGate{
+			MaxUnavailableInstances: 1,
+		},
+	}, []uint32{0, 1, 2, 3, 4}, nil, nil)
+
+	suite.expectTasks(
+		pbtask.TaskState_RUNNING,
+		pbtask.TaskState_FAILED,
+		pbtask.TaskState_PENDING)
+
+	suite.cachedUpdate.EXPECT().
+		TripHealthGate(gomock.Any(), "2 instances unavailable, limit is 1").
+		Return(nil)
+	suite.expectRollback()
+
+	suite.NoError(UpdateCheckHealthGate(context.Background(), suite.updateEnt))
+}
+
+// TestGetNumUnavailableInstances tests that only the instances
+// processed by an update are counted as unavailable
+func (suite *UpdateGateTestSuite) TestGetNumUnavailableInstances() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{}, []uint32{0}, nil, []uint32{1})
+	suite.expectTasks(pbtask.TaskState_RUNNING, pbtask.TaskState_KILLING)
+
+	suite.Equal(uint32(1),
+		getNumUnavailableInstances(suite.cachedJob, suite.cachedUpdate))
+}
+
+// TestUpdateRunIfRollingForwardRolledBack tests that an update rolled
+// back by its health gate is not run forward
+func (suite *UpdateGateTestSuite) TestUpdateRunIfRollingForwardRolledBack() {
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_BACKWARD,
+		})
+
+	suite.NoError(UpdateRunIfRollingForward(context.Background(), suite.updateEnt))
+}
+
+// TestUpdateCheckHealthGateFrozen tests that an update whose health
+// gate trips is not rolled back, but evaluated again later, while the
+// cluster is frozen
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGateFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		HealthGate: &pbupdate.HealthGate{
+			MaxFailureRate: 0.2,
+		},
+	}, []uint32{0}, []uint32{3}, nil)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.After(time.Now().Add(20 * time.Second)))
+		})
+
+	suite.NoError(UpdateCheckHealthGate(context.Background(), suite.updateEnt))
+}
+
+// TestUpdateCheckHealthGateFailureRate tests rolling back an update
+// with a failure rate over the limit
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGateFailureRate() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		HealthGate: &pbupdate.HealthGate{
+			MaxFailureRate: 0.2,
+			WindowSecs:     60,
+		},
+	}, []uint32{0, 1, 2}, []uint32{3}, nil)
+
+	suite.cachedUpdate.EXPECT().
+		TripHealthGate(gomock.Any(), "1 of 4 instances failed, limit is 0.20").
+		Return(nil)
+	suite.expectRollback()
+
+	suite.NoError(UpdateCheckHealthGate(context.Background(), suite.updateEnt))
+}
+
+// TestUpdateCheckHealthGateTripError tests the failure to record the
+// trip of the health gate of an update
+func (suite *UpdateGateTestSuite) TestUpdateCheckHealthGateTripError() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		HealthGate: &pbupdate.HealthGate{
+			MaxFailureRate: 0.2,
+		},
+	}, []uint32{0}, []uint32{3}, nil)
+
+	suite.cachedUpdate.EXPECT().
+		TripHealthGate(gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.UnavailableErrorf("test error"))
+
+	err := UpdateCheckHealthGate(context.Background(), suite.updateEnt)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestUpdatePromoteCanaryInProgress tests that an update is not
+// promoted while its canaries are being updated
+func (suite *UpdateGateTestSuite) TestUpdatePromoteCanaryInProgress() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{Instances: 2},
+	}, []uint32{0}, nil, []uint32{1})
+	suite.cachedUpdate.EXPECT().
+		IsCanaryPromoted().
+		Return(false)
+
+	suite.NoError(UpdatePromoteCanary(context.Background(), suite.updateEnt))
+}
+
+// TestUpdatePromoteCanaryBaking tests that an update is evaluated again
+// once its canaries have baked
+func (suite *UpdateGateTestSuite) TestUpdatePromoteCanaryBaking() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{Instances: 2, BakeTimeSecs: 60},
+	}, []uint32{0, 1}, nil, nil)
+	suite.cachedUpdate.EXPECT().
+		IsCanaryPromoted().
+		Return(false)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(_ goalstate.Entity, deadline time.Time) {
+			suite.True(deadline.After(time.Now().Add(50 * time.Second)))
+		})
+
+	suite.NoError(UpdatePromoteCanary(context.Background(), suite.updateEnt))
+}
+
+// TestUpdatePromoteCanary tests promoting an update once its canaries
+// have baked
+func (suite *UpdateGateTestSuite) TestUpdatePromoteCanary() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{Instances: 2, BakeTimeSecs: 60},
+	}, []uint32{0}, []uint32{1}, nil)
+	suite.cachedUpdate.EXPECT().
+		IsCanaryPromoted().
+		Return(false)
+	suite.goalStateDriver.healthGates.get(suite.updateID.GetValue()).
+		canariesCompleted = time.Now().Add(-2 * time.Minute)
+
+	suite.cachedUpdate.EXPECT().
+		PromoteCanary(gomock.Any()).
+		Return(nil)
+
+	suite.NoError(UpdatePromoteCanary(context.Background(), suite.updateEnt))
+}
+
+// TestUpdatePromoteCanaryFrozen tests that an update is not promoted
+// while the cluster is frozen
+func (suite *UpdateGateTestSuite) TestUpdatePromoteCanaryFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{Instances: 2},
+	}, []uint32{0, 1}, nil, nil)
+	suite.cachedUpdate.EXPECT().
+		IsCanaryPromoted().
+		Return(false)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(UpdatePromoteCanary(context.Background(), suite.updateEnt))
+}
+
+// TestUpdatePromoteCanaryPromoted tests that an update already
+// promoted is not promoted again
+func (suite *UpdateGateTestSuite) TestUpdatePromoteCanaryPromoted() {
+	suite.expectUpdate(&pbupdate.UpdateConfig{
+		Canary: &pbupdate.CanaryConfig{Instances: 2},
+	}, []uint32{0, 1}, nil, nil)
+	suite.cachedUpdate.EXPECT().
+		IsCanaryPromoted().
+		Return(true)
+
+	suite.NoError(UpdatePromoteCanary(context.Background(), suite.updateEnt))
+}
+
+// expectTasks sets up the job with cached tasks with goal state RUNNING
+// in the given states, the other instances of the job have no task
+func (suite *UpdateGateTestSuite) expectTasks(states ...pbtask.TaskState) {
+	for i, state := range states {
+		cachedTask := cachedmocks.NewMockTask(suite.ctrl)
+		cachedTask.EXPECT().
+			GoalState().
+			Return(cached.TaskStateVector{State: pbtask.TaskState_RUNNING})
+		cachedTask.EXPECT().
+			CurrentState().
+			Return(cached.TaskStateVector{State: state})
+		suite.cachedJob.EXPECT().
+			GetTask(uint32(i)).
+			Return(cachedTask)
+	}
+	suite.cachedJob.EXPECT().
+		GetTask(gomock.Any()).
+		Return(nil).
+		AnyTimes()
+}
+
+// expectRollback sets up the rollback of the update
+func (suite *UpdateGateTestSuite) expectRollback() {
+	suite.cachedJob.EXPECT().
+		WriteWorkflowProgress(
+			gomock.Any(),
+			suite.updateID,
+			pbupdate.State_ROLLING_FORWARD,
+			gomock.Any(),
+			gomock.Any(),
+			gomock.Any(),
+		).Return(nil)
+	suite.cachedJob.EXPECT().
+		RollbackWorkflow(gomock.Any()).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{InstanceCount: 5}, nil)
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+}
@@ -71,6 +71,34 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	updateConfig := cachedWorkflow.GetUpdateConfig()
+
+	// the instances updated since the last run are only done once they
+	// pass the verification hook; the ones still being verified keep
+	// their slot in the current batch, and the ones which fail the
+	// verification count towards the failed instances.
+	// Rollbacks are not verified, since they go back to a configuration
+	// which was running before the update.
+	if hook := updateConfig.GetVerificationHook(); hook != nil &&
+		!isUpdateRollback(cachedWorkflow) {
+		var instancesVerifyFailed, instancesVerifying []uint32
+		var retryAt time.Time
+		instancesDoneFromLastRun, instancesVerifyFailed, instancesVerifying, retryAt =
+			goalStateDriver.updateVerifier.verifyInstances(
+				ctx,
+				cachedJob,
+				updateEnt.id,
+				hook,
+				instancesDoneFromLastRun,
+			)
+		instancesFailedFromLastRun = append(
+			instancesFailedFromLastRun, instancesVerifyFailed...)
+		instancesCurrent = append(instancesCurrent, instancesVerifying...)
+		if len(instancesVerifying) != 0 {
+			goalStateDriver.EnqueueUpdate(cachedJob.ID(), updateEnt.id, retryAt)
+		}
+	}
+
 	instancesFailed := append(
 		cachedWorkflow.GetInstancesFailed(),
 		instancesFailedFromLastRun...)
@@ -82,9 +110,9 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 	// max instance retries is set, process the failed workflow and
 	// return directly
 	// TODO: use job SLA if GetMaxFailureInstances is not set
-	if cachedWorkflow.GetUpdateConfig().GetMaxFailureInstances() != 0 &&
+	if updateConfig.GetMaxFailureInstances() != 0 &&
 		uint32(len(instancesFailed)) >=
-			cachedWorkflow.GetUpdateConfig().GetMaxFailureInstances() {
+			updateConfig.GetMaxFailureInstances() {
 		err := processFailedUpdate(
 			ctx,
 			cachedJob,
@@ -100,7 +128,7 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
-	instancesToAdd, instancesToUpdate, instancesToRemove :=
+	instancesToAdd, instancesToUpdate, instancesToRemove, err :=
 		getInstancesForUpdateRun(
 			ctx,
 			cachedJob,
@@ -108,7 +136,51 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 			instancesCurrent,
 			instancesDone,
 			instancesFailed,
+			goalStateDriver,
 		)
+	if err != nil {
+		goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+		return err
+	}
+
+	// until its canaries are promoted, only the canaries of the update
+	// are updated
+	if isCanaryPending(cachedWorkflow, updateConfig) {
+		numCanaries := getNumCanaries(cachedWorkflow, updateConfig)
+		numProcessed :=
+			len(instancesCurrent) + len(instancesDone) + len(instancesFailed)
+		instancesToAdd, instancesToUpdate, instancesToRemove =
+			limitInstancesToProcess(
+				numCanaries-numProcessed,
+				instancesToAdd,
+				instancesToUpdate,
+				instancesToRemove,
+			)
+
+		// all the canaries have been updated, evaluate the update
+		// again once they have baked to promote it
+		if len(instancesCurrent) == 0 && numProcessed >= numCanaries {
+			bakeTime := time.Duration(
+				updateConfig.GetCanary().GetBakeTimeSecs()) * time.Second
+			goalStateDriver.EnqueueUpdate(
+				cachedJob.ID(),
+				updateEnt.id,
+				goalStateDriver.healthGates.
+					canariesCompleted(updateEnt.id.GetValue()).
+					Add(bakeTime))
+		}
+	}
+
+	// while the cluster is frozen, keep tracking the progress of the
+	// instances being updated, but do not start updating new instances
+	if goalStateDriver.isFrozen() {
+		goalStateDriver.mtx.updateMetrics.UpdateRunFrozen.Inc(1)
+		instancesToAdd, instancesToUpdate, instancesToRemove = nil, nil, nil
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(),
+			updateEnt.id,
+			time.Now().Add(goalStateDriver.cfg.FrozenRetryDelay))
+	}
 
 	instancesToAdd, instancesToUpdate, instancesToRemove, instancesRemovedDone, err :=
 		confirmInstancesStatus(
@@ -203,54 +275,16 @@ func processFailedUpdate(
 	// the update itself is not a rollback
 	if cachedUpdate.GetUpdateConfig().RollbackOnFailure &&
 		!isUpdateRollback(cachedUpdate) {
-		// write the progress first, because when rollback happens,
-		// workflow does not know the newly finished/failed instances.
-		cachedJob.WriteWorkflowProgress(
+		if err := rollbackUpdate(
 			ctx,
-			cachedUpdate.ID(),
-			cachedUpdate.GetState().State,
+			cachedJob,
+			cachedUpdate,
 			instancesDone,
 			instancesFailed,
 			instancesCurrent,
-		)
-
-		if err := cachedJob.RollbackWorkflow(ctx); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to rollback update")
-			return err
-		}
-
-		cachedConfig, err := cachedJob.GetConfig(ctx)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to get job config to rollback update")
-			return err
-		}
-
-		if err := handleUnchangedInstancesInUpdate(
-			ctx,
-			cachedUpdate,
-			cachedJob,
-			cachedConfig,
 		); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to update unchanged instances to rollback update")
 			return err
 		}
-
-		log.WithFields(log.Fields{
-			"update_id": cachedUpdate.ID().GetValue(),
-			"job_id":    cachedJob.ID().GetValue(),
-		}).Info("update rolling back")
 	} else {
 		if err := cachedJob.WriteWorkflowProgress(
 			ctx,
@@ -268,6 +302,67 @@ func processFailedUpdate(
 	return nil
 }
 
+// rollbackUpdate rolls an update back to the previous job
+// configuration version.
+func rollbackUpdate(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+	instancesCurrent []uint32,
+) error {
+	// write the progress first, because when rollback happens,
+	// workflow does not know the newly finished/failed instances.
+	cachedJob.WriteWorkflowProgress(
+		ctx,
+		cachedUpdate.ID(),
+		cachedUpdate.GetState().State,
+		instancesDone,
+		instancesFailed,
+		instancesCurrent,
+	)
+
+	if err := cachedJob.RollbackWorkflow(ctx); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to rollback update")
+		return err
+	}
+
+	cachedConfig, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to get job config to rollback update")
+		return err
+	}
+
+	if err := handleUnchangedInstancesInUpdate(
+		ctx,
+		cachedUpdate,
+		cachedJob,
+		cachedConfig,
+	); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to update unchanged instances to rollback update")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": cachedUpdate.ID().GetValue(),
+		"job_id":    cachedJob.ID().GetValue(),
+	}).Info("update rolling back")
+	return nil
+}
+
 // isUpdateRollback returns if an update is a rolling back to a
 // previous version
 func isUpdateRollback(cachedUpdate cached.Update) bool {
@@ -467,9 +562,10 @@ func addInstancesInUpdate(
 					JobId:      cachedJob.ID(),
 					InstanceId: instID,
 					Runtime:    runtime,
-					Config: taskconfig.Merge(
+					Config: taskconfig.MergeWithStrategy(
 						jobConfig.GetDefaultConfig(),
-						jobConfig.GetInstanceConfig()[instID]),
+						jobConfig.GetInstanceConfig()[instID],
+						jobConfig.GetInstanceConfigMergeStrategy()),
 				}
 				tasks = append(tasks, taskInfo)
 			} else {
@@ -509,9 +605,10 @@ func addInstancesInUpdate(
 					JobId:      cachedJob.ID(),
 					InstanceId: instID,
 					Runtime:    runtime,
-					Config: taskconfig.Merge(
+					Config: taskconfig.MergeWithStrategy(
 						jobConfig.GetDefaultConfig(),
-						jobConfig.GetInstanceConfig()[instID]),
+						jobConfig.GetInstanceConfig()[instID],
+						jobConfig.GetInstanceConfigMergeStrategy()),
 				}
 				tasks = append(tasks, taskInfo)
 			}
@@ -784,11 +881,14 @@ func getInstancesForUpdateRun(
 	instancesCurrent []uint32,
 	instancesDone []uint32,
 	instancesFailed []uint32,
+	goalStateDriver *driver,
 ) (
 	instancesToAdd []uint32,
 	instancesToUpdate []uint32,
 	instancesToRemove []uint32,
+	err error,
 ) {
+	updateConfig := update.GetUpdateConfig()
 
 	unprocessedInstancesToAdd,
 		unprocessedInstancesToUpdate,
@@ -805,20 +905,37 @@ func getInstancesForUpdateRun(
 			cachedJob,
 			unprocessedInstancesToUpdate,
 		)
+
+		// if the update is batched along failure domains, only update
+		// the instances in the next failure domain
+		if attribute := updateConfig.GetFailureDomainAttribute(); len(attribute) != 0 {
+			unprocessedInstancesToUpdate, err = getInstancesInNextFailureDomain(
+				ctx,
+				cachedJob,
+				attribute,
+				instancesCurrent,
+				unprocessedInstancesToUpdate,
+				goalStateDriver,
+			)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
 	}
 
 	// if batch size is 0 or updateConfig is nil, update all of the instances
-	if update.GetUpdateConfig().GetBatchSize() == 0 {
+	if updateConfig.GetBatchSize() == 0 {
 		return unprocessedInstancesToAdd,
 			unprocessedInstancesToUpdate,
-			unprocessedInstancesToRemove
+			unprocessedInstancesToRemove,
+			nil
 	}
 
 	maxNumOfInstancesToProcess :=
-		int(update.GetUpdateConfig().GetBatchSize()) - len(instancesCurrent)
+		int(updateConfig.GetBatchSize()) - len(instancesCurrent)
 	// if instances being updated are more than batch size, do not update anything
 	if maxNumOfInstancesToProcess <= 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 
 	// if can process all of the remaining instances
@@ -827,7 +944,8 @@ func getInstancesForUpdateRun(
 			len(unprocessedInstancesToRemove) {
 		return unprocessedInstancesToAdd,
 			unprocessedInstancesToUpdate,
-			unprocessedInstancesToRemove
+			unprocessedInstancesToRemove,
+			nil
 	}
 
 	// if can process all of the instances to add, update
@@ -838,8 +956,8 @@ func getInstancesForUpdateRun(
 			unprocessedInstancesToUpdate,
 			unprocessedInstancesToRemove[:maxNumOfInstancesToProcess-
 				len(unprocessedInstancesToAdd)-
-				len(unprocessedInstancesToUpdate)]
-
+				len(unprocessedInstancesToUpdate)],
+			nil
 	}
 
 	// if can process all of the instances to add,
@@ -847,11 +965,91 @@ func getInstancesForUpdateRun(
 	if maxNumOfInstancesToProcess > len(unprocessedInstancesToAdd) {
 		return unprocessedInstancesToAdd,
 			unprocessedInstancesToUpdate[:maxNumOfInstancesToProcess-len(unprocessedInstancesToAdd)],
+			nil,
 			nil
 	}
 
 	// if can process part of the instances to add
-	return unprocessedInstancesToAdd[:maxNumOfInstancesToProcess], nil, nil
+	return unprocessedInstancesToAdd[:maxNumOfInstancesToProcess], nil, nil, nil
+}
+
+// getInstancesInNextFailureDomain filters the instances to update down
+// to the ones running on hosts in the next failure domain, so that the
+// update finishes one failure domain (e.g. a zone) before moving to the
+// next. Failure domains are updated in sorted order, and instances whose
+// failure domain is not known are updated last. If instances placed in
+// another failure domain are still being updated, no new instance is
+// returned. Instances being updated which have no host, e.g. because they
+// are killed or pending, do not block any failure domain.
+func getInstancesInNextFailureDomain(
+	ctx context.Context,
+	cachedJob cached.Job,
+	attribute string,
+	instancesCurrent []uint32,
+	instancesToUpdate []uint32,
+	goalStateDriver *driver,
+) ([]uint32, error) {
+	hosts := make(map[uint32]string)
+	var hostnames []string
+	for _, instances := range [][]uint32{instancesCurrent, instancesToUpdate} {
+		for _, instID := range instances {
+			cachedTask := cachedJob.GetTask(instID)
+			if cachedTask == nil {
+				continue
+			}
+			runtime, err := cachedTask.GetRuntime(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if len(runtime.GetHost()) == 0 {
+				continue
+			}
+			hosts[instID] = runtime.GetHost()
+			hostnames = append(hostnames, runtime.GetHost())
+		}
+	}
+
+	domains, err := goalStateDriver.hostTopology.getFailureDomains(
+		ctx,
+		attribute,
+		hostnames,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// instances with an unknown failure domain map to the empty
+	// domain, which is only picked once all other domains are done
+	domainOf := func(instID uint32) string {
+		return domains[hosts[instID]]
+	}
+
+	nextDomain := domainOf(instancesToUpdate[0])
+	for _, instID := range instancesToUpdate[1:] {
+		domain := domainOf(instID)
+		if len(nextDomain) == 0 ||
+			(len(domain) != 0 && domain < nextDomain) {
+			nextDomain = domain
+		}
+	}
+
+	// wait for the instances in other failure domains to finish
+	for _, instID := range instancesCurrent {
+		if _, ok := hosts[instID]; !ok {
+			continue
+		}
+		if domainOf(instID) != nextDomain {
+			return nil, nil
+		}
+	}
+
+	var result []uint32
+	for _, instID := range instancesToUpdate {
+		if domainOf(instID) == nextDomain {
+			result = append(result, instID)
+		}
+	}
+	return result, nil
 }
 
 // sortInstancesByAvailability sorts the instances of the job by its availability.
@@ -63,6 +63,7 @@ type UpdateRunTestSuite struct {
 	mockedPodEventsOps    *objectmocks.MockPodEventsOps
 	jobConfigOps          *objectmocks.MockJobConfigOps
 	resmgrClient          *resmocks.MockResourceManagerServiceYARPCClient
+	overrideOps           *objectmocks.MockInstanceOverrideOps
 }
 
 func TestUpdateRun(t *testing.T) {
@@ -81,6 +82,12 @@ func (suite *UpdateRunTestSuite) SetupTest() {
 	suite.resmgrClient = resmocks.NewMockResourceManagerServiceYARPCClient(suite.ctrl)
 
 	suite.mockedPodEventsOps = objectmocks.NewMockPodEventsOps(suite.ctrl)
+	// most tests do not care about instance overrides
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
 	suite.goalStateDriver = &driver{
 		jobFactory:   suite.jobFactory,
 		updateEngine: suite.updateGoalStateEngine,
@@ -93,6 +100,8 @@ func (suite *UpdateRunTestSuite) SetupTest() {
 		mtx:          NewMetrics(tally.NoopScope),
 		cfg:          &Config{},
 		resmgrClient: suite.resmgrClient,
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 	suite.goalStateDriver.cfg.normalize()
 
@@ -2754,7 +2763,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateFailed() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
@@ -2891,7 +2900,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBack() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range totalInstancesToUpdate {
 		if uint32(i) < failedInstances {
@@ -3057,7 +3066,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBackFail() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range totalInstancesToUpdate {
 		if uint32(i) < failedInstances {
@@ -3200,7 +3209,7 @@ func (suite *UpdateRunTestSuite) TestUpdateRollingBackFailed() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
@@ -3360,7 +3369,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdatePartialFailure() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(3)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
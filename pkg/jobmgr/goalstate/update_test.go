@@ -75,6 +75,11 @@ func (suite *UpdateGoalStateTestSuite) TestUpdateStateAndGoalState() {
 	// Test fetching the entity ID
 	suite.Equal(suite.jobID.GetValue(), suite.updateEnt.GetID())
 
+	// Test fetching the prerequisites of the entity
+	prerequisites := suite.updateEnt.GetPrerequisites()
+	suite.Len(prerequisites, 1)
+	suite.Equal(suite.jobID.GetValue(), prerequisites[0].Entity.GetID())
+
 	// Test fetching the entity state
 	updateState := &cached.UpdateStateVector{
 		State:     update.State_ROLLING_FORWARD,
@@ -138,6 +143,10 @@ func (suite *UpdateGoalStateTestSuite) TestUpdateGetActionList() {
 	}{
 		{
 			state:        update.State_ROLLING_FORWARD,
+			lengthAction: 4,
+		},
+		{
+			state:        update.State_ROLLING_BACKWARD,
 			lengthAction: 2,
 		},
 		{
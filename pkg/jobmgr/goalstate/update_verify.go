@@ -0,0 +1,285 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_defaultVerificationTimeout     = 10 * time.Second
+	_defaultVerificationInterval    = 10 * time.Second
+	_defaultVerificationMaxAttempts = 3
+
+	// _verificationStateTTL is the time after which the verification
+	// state of an instance which has not been verified again, e.g.
+	// because its update was aborted, is dropped.
+	_verificationStateTTL = time.Hour
+)
+
+// instanceVerification is the verification state of an updated instance.
+type instanceVerification struct {
+	// number of failed verification attempts
+	failedAttempts uint32
+	// time at which the instance can be verified again
+	nextAttempt time.Time
+}
+
+// updateVerifier runs the verification hook of updates against the
+// instances once they have been updated. The verification state of the
+// instances is kept in memory only, so the verification of an instance
+// starts over if the job manager leader changes.
+type updateVerifier struct {
+	sync.Mutex
+
+	httpClient *http.Client
+	executor   commandExecutor
+	metrics    *UpdateMetrics
+
+	// verifications maps an instance of an update to its verification state
+	verifications map[string]*instanceVerification
+}
+
+// newUpdateVerifier creates an updateVerifier which runs commands of
+// the verification hooks with the given executor.
+func newUpdateVerifier(
+	executor commandExecutor,
+	metrics *UpdateMetrics,
+) *updateVerifier {
+	return &updateVerifier{
+		httpClient:    &http.Client{},
+		executor:      executor,
+		metrics:       metrics,
+		verifications: make(map[string]*instanceVerification),
+	}
+}
+
+// verifyInstances runs the verification hook against the given instances
+// which have been updated. It returns the instances which passed the
+// verification, the ones which failed all their verification attempts
+// and the ones which need to be verified again, along with the time at
+// which the verification should be retried. Instances which are not
+// running, e.g. killed or removed instances, pass the verification.
+func (v *updateVerifier) verifyInstances(
+	ctx context.Context,
+	cachedJob cached.Job,
+	updateID *peloton.UpdateID,
+	hook *pbupdate.VerificationHook,
+	instances []uint32,
+) (passed []uint32, failed []uint32, pending []uint32, retryAt time.Time) {
+	var wg sync.WaitGroup
+	retryAt = time.Now().Add(getVerificationInterval(hook))
+
+	v.Lock()
+	v.purge()
+	for _, instID := range instances {
+		key := verificationKey(updateID, instID)
+		state, ok := v.verifications[key]
+		if !ok {
+			state = &instanceVerification{}
+			v.verifications[key] = state
+		}
+
+		// the last attempt was too recent, retry later
+		if time.Now().Before(state.nextAttempt) {
+			pending = append(pending, instID)
+			if state.nextAttempt.Before(retryAt) {
+				retryAt = state.nextAttempt
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(instID uint32, state *instanceVerification) {
+			defer wg.Done()
+
+			err := v.verifyInstance(ctx, cachedJob, hook, instID)
+
+			v.Lock()
+			defer v.Unlock()
+			if err == nil {
+				passed = append(passed, instID)
+				delete(v.verifications, verificationKey(updateID, instID))
+				return
+			}
+
+			state.failedAttempts++
+			log.WithFields(log.Fields{
+				"job_id":      cachedJob.ID().GetValue(),
+				"update_id":   updateID.GetValue(),
+				"instance_id": instID,
+				"attempts":    state.failedAttempts,
+			}).WithError(err).Info("instance failed update verification")
+			if state.failedAttempts >= getVerificationMaxAttempts(hook) {
+				failed = append(failed, instID)
+				delete(v.verifications, verificationKey(updateID, instID))
+				return
+			}
+			state.nextAttempt = time.Now().Add(getVerificationInterval(hook))
+			pending = append(pending, instID)
+		}(instID, state)
+	}
+	// checks run without holding the lock, so that slow instances do not
+	// hold up the verification of other updates
+	v.Unlock()
+	wg.Wait()
+
+	v.metrics.UpdateVerifyPass.Inc(int64(len(passed)))
+	v.metrics.UpdateVerifyFail.Inc(int64(len(failed)))
+	return passed, failed, pending, retryAt
+}
+
+// verificationKey returns the key of the verification state of an
+// instance of an update.
+func verificationKey(updateID *peloton.UpdateID, instID uint32) string {
+	return fmt.Sprintf("%s-%d", updateID.GetValue(), instID)
+}
+
+// purge drops the verification state of the instances which have not
+// been verified for a long time. It must be called with the lock held.
+func (v *updateVerifier) purge() {
+	for key, state := range v.verifications {
+		if time.Since(state.nextAttempt) > _verificationStateTTL {
+			delete(v.verifications, key)
+		}
+	}
+}
+
+// verifyInstance runs one attempt of the verification hook against
+// an instance, and returns an error if the verification fails.
+func (v *updateVerifier) verifyInstance(
+	ctx context.Context,
+	cachedJob cached.Job,
+	hook *pbupdate.VerificationHook,
+	instID uint32,
+) error {
+	cachedTask := cachedJob.GetTask(instID)
+	if cachedTask == nil {
+		// instance has been removed
+		return nil
+	}
+
+	runtime, err := cachedTask.GetRuntime(ctx)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if runtime.GetState() != pbtask.TaskState_RUNNING {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, getVerificationTimeout(hook))
+	defer cancel()
+
+	switch hook.GetType() {
+	case pbupdate.VerificationHook_COMMAND:
+		status, err := v.executor.Exec(
+			ctx,
+			runtime.GetHost(),
+			runtime.GetMesosTaskId().GetValue(),
+			hook.GetCommandCheck().GetCommand(),
+		)
+		if err != nil {
+			return err
+		}
+		if status != 0 {
+			return errors.Errorf("command exited with status %d", status)
+		}
+		return nil
+	case pbupdate.VerificationHook_HTTP:
+		return v.checkHTTP(ctx, hook.GetHttpCheck(), runtime)
+	}
+	return errors.Errorf("unsupported verification hook type %s", hook.GetType())
+}
+
+// checkHTTP sends a GET request to the http endpoint of an instance,
+// and returns an error unless the response has a 2xx status code.
+func (v *updateVerifier) checkHTTP(
+	ctx context.Context,
+	check *pbupdate.VerificationHook_HTTPCheck,
+	runtime *pbtask.RuntimeInfo,
+) error {
+	port := check.GetPort()
+	if name := check.GetPortName(); len(name) != 0 {
+		var ok bool
+		if port, ok = runtime.GetPorts()[name]; !ok {
+			return errors.Errorf("port %s not found", name)
+		}
+	}
+
+	scheme := check.GetScheme()
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+	path := check.GetPath()
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	url := fmt.Sprintf("%s://%s:%d%s", scheme, runtime.GetHost(), port, path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK ||
+		resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("%s returned status %s", url, resp.Status)
+	}
+	return nil
+}
+
+func getVerificationTimeout(hook *pbupdate.VerificationHook) time.Duration {
+	if hook.GetTimeoutSecs() == 0 {
+		return _defaultVerificationTimeout
+	}
+	return time.Duration(hook.GetTimeoutSecs()) * time.Second
+}
+
+func getVerificationInterval(hook *pbupdate.VerificationHook) time.Duration {
+	if hook.GetIntervalSecs() == 0 {
+		return _defaultVerificationInterval
+	}
+	return time.Duration(hook.GetIntervalSecs()) * time.Second
+}
+
+func getVerificationMaxAttempts(hook *pbupdate.VerificationHook) uint32 {
+	if hook.GetMaxAttempts() == 0 {
+		return _defaultVerificationMaxAttempts
+	}
+	return hook.GetMaxAttempts()
+}
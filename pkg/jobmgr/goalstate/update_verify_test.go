@@ -0,0 +1,301 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+// fakeCommandExecutor returns the given status for every command
+type fakeCommandExecutor struct {
+	status int32
+	err    error
+	calls  int
+}
+
+func (e *fakeCommandExecutor) Exec(
+	ctx context.Context,
+	hostname string,
+	mesosTaskID string,
+	command string,
+) (int32, error) {
+	e.calls++
+	return e.status, e.err
+}
+
+type UpdateVerifyTestSuite struct {
+	suite.Suite
+
+	ctrl       *gomock.Controller
+	cachedJob  *cachedmocks.MockJob
+	cachedTask *cachedmocks.MockTask
+	executor   *fakeCommandExecutor
+	verifier   *updateVerifier
+	jobID      *peloton.JobID
+	updateID   *peloton.UpdateID
+}
+
+func TestUpdateVerify(t *testing.T) {
+	suite.Run(t, new(UpdateVerifyTestSuite))
+}
+
+func (suite *UpdateVerifyTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+	suite.executor = &fakeCommandExecutor{}
+	suite.verifier = newUpdateVerifier(
+		suite.executor,
+		NewMetrics(tally.NoopScope).updateMetrics)
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.updateID = &peloton.UpdateID{Value: uuid.NewRandom().String()}
+
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(suite.jobID).
+		AnyTimes()
+}
+
+func (suite *UpdateVerifyTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectRuntime sets up the task of instance 0 to have the given runtime
+func (suite *UpdateVerifyTestSuite) expectRuntime(runtime *pbtask.RuntimeInfo) {
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(0)).
+		Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(runtime, nil)
+}
+
+func (suite *UpdateVerifyTestSuite) runningRuntime() *pbtask.RuntimeInfo {
+	mesosTaskID := "mesos-task-0"
+	return &pbtask.RuntimeInfo{
+		State:       pbtask.TaskState_RUNNING,
+		Host:        "127.0.0.1",
+		MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+	}
+}
+
+// TestVerifyHTTP tests verifying an instance with an HTTP check
+// on a named port of the instance
+func (suite *UpdateVerifyTestSuite) TestVerifyHTTP() {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+	defer server.Close()
+
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	suite.NoError(err)
+	port, err := strconv.Atoi(portStr)
+	suite.NoError(err)
+
+	runtime := suite.runningRuntime()
+	runtime.Ports = map[string]uint32{"http": uint32(port)}
+
+	hook := &pbupdate.VerificationHook{
+		Type: pbupdate.VerificationHook_HTTP,
+		HttpCheck: &pbupdate.VerificationHook_HTTPCheck{
+			PortName: "http",
+			Path:     "health",
+		},
+	}
+	suite.expectRuntime(runtime)
+	passed, failed, pending, _ := suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Equal([]uint32{0}, passed)
+	suite.Empty(failed)
+	suite.Empty(pending)
+
+	// unexpected status code fails the check
+	hook.HttpCheck.Path = "/ready"
+	hook.MaxAttempts = 1
+	suite.expectRuntime(runtime)
+	passed, failed, pending, _ = suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Empty(passed)
+	suite.Equal([]uint32{0}, failed)
+	suite.Empty(pending)
+
+	// unknown port fails the check
+	hook.HttpCheck.PortName = "grpc"
+	suite.expectRuntime(runtime)
+	_, failed, _, _ = suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Equal([]uint32{0}, failed)
+}
+
+// TestVerifyCommandRetries tests that an instance failing the command
+// check is retried after the interval, and fails once it has used up
+// all its attempts
+func (suite *UpdateVerifyTestSuite) TestVerifyCommandRetries() {
+	suite.executor.status = 1
+	hook := &pbupdate.VerificationHook{
+		Type: pbupdate.VerificationHook_COMMAND,
+		CommandCheck: &pbupdate.VerificationHook_CommandCheck{
+			Command: "./check.sh",
+		},
+		MaxAttempts:  2,
+		IntervalSecs: 30,
+	}
+
+	suite.expectRuntime(suite.runningRuntime())
+	passed, failed, pending, retryAt := suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Empty(passed)
+	suite.Empty(failed)
+	suite.Equal([]uint32{0}, pending)
+	suite.True(retryAt.After(time.Now().Add(20 * time.Second)))
+	suite.Equal(1, suite.executor.calls)
+
+	// not retried before the interval
+	passed, failed, pending, _ = suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Empty(passed)
+	suite.Empty(failed)
+	suite.Equal([]uint32{0}, pending)
+	suite.Equal(1, suite.executor.calls)
+
+	// retried after the interval, and fails on the last attempt
+	suite.verifier.verifications[verificationKey(suite.updateID, 0)].nextAttempt =
+		time.Now()
+	suite.expectRuntime(suite.runningRuntime())
+	passed, failed, pending, _ = suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Empty(passed)
+	suite.Equal([]uint32{0}, failed)
+	suite.Empty(pending)
+	suite.Equal(2, suite.executor.calls)
+	suite.Empty(suite.verifier.verifications)
+}
+
+// TestVerifyCommandPasses tests that an instance passes the command check
+// if the command exits with status 0
+func (suite *UpdateVerifyTestSuite) TestVerifyCommandPasses() {
+	hook := &pbupdate.VerificationHook{
+		Type: pbupdate.VerificationHook_COMMAND,
+		CommandCheck: &pbupdate.VerificationHook_CommandCheck{
+			Command: "./check.sh",
+		},
+	}
+
+	suite.expectRuntime(suite.runningRuntime())
+	passed, failed, pending, _ := suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Equal([]uint32{0}, passed)
+	suite.Empty(failed)
+	suite.Empty(pending)
+
+	// exec errors count as a failed attempt
+	suite.executor.err = errors.New("agent unavailable")
+	suite.expectRuntime(suite.runningRuntime())
+	_, _, pending, _ = suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0},
+	)
+	suite.Equal([]uint32{0}, pending)
+}
+
+// TestVerifyInstancesNotRunning tests that instances which are not
+// running or have been removed pass the verification without a check
+func (suite *UpdateVerifyTestSuite) TestVerifyInstancesNotRunning() {
+	hook := &pbupdate.VerificationHook{
+		Type: pbupdate.VerificationHook_COMMAND,
+		CommandCheck: &pbupdate.VerificationHook_CommandCheck{
+			Command: "./check.sh",
+		},
+	}
+
+	suite.expectRuntime(&pbtask.RuntimeInfo{
+		State: pbtask.TaskState_KILLED,
+	})
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(1)).
+		Return(nil)
+
+	passed, failed, pending, _ := suite.verifier.verifyInstances(
+		context.Background(),
+		suite.cachedJob,
+		suite.updateID,
+		hook,
+		[]uint32{0, 1},
+	)
+	suite.ElementsMatch([]uint32{0, 1}, passed)
+	suite.Empty(failed)
+	suite.Empty(pending)
+	suite.Equal(0, suite.executor.calls)
+}
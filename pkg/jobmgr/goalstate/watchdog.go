@@ -0,0 +1,142 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+)
+
+const (
+	// _reconcileRemediation is the remediation of stuck tasks which
+	// force reconciles the task with the host manager.
+	_reconcileRemediation = "reconcile"
+	// _refreshCacheRemediation is the remediation of stuck jobs and tasks
+	// which reloads their runtimes from the DB into the cache.
+	_refreshCacheRemediation = "refresh_cache"
+)
+
+// Kinds of the entities stuck in goal state
+const (
+	jobEntityKind    = "job"
+	taskEntityKind   = "task"
+	updateEntityKind = "update"
+)
+
+// StuckEntity is a job, task or update stuck in goal state.
+type StuckEntity struct {
+	goalstate.StuckEntity
+
+	// Kind is the kind of the entity, one of job, task or update.
+	Kind string
+	// JobID is the job of the entity.
+	JobID *peloton.JobID
+	// InstanceID is the instance of the task, for tasks only.
+	InstanceID uint32
+	// UpdateID is the identifier of the update, for updates only.
+	UpdateID *peloton.UpdateID
+}
+
+// newStuckEntity returns the StuckEntity of an entity of the
+// goal state engines of the driver.
+func newStuckEntity(stuck goalstate.StuckEntity) *StuckEntity {
+	stuckEntity := &StuckEntity{StuckEntity: stuck}
+	switch entity := stuck.Entity.(type) {
+	case *jobEntity:
+		stuckEntity.Kind = jobEntityKind
+		stuckEntity.JobID = entity.id
+	case *taskEntity:
+		stuckEntity.Kind = taskEntityKind
+		stuckEntity.JobID = entity.jobID
+		stuckEntity.InstanceID = entity.instanceID
+	case *updateEntity:
+		stuckEntity.Kind = updateEntityKind
+		stuckEntity.JobID = entity.jobID
+		stuckEntity.UpdateID = entity.id
+	}
+	return stuckEntity
+}
+
+func (d *driver) GetStuckEntities() []*StuckEntity {
+	var stuckEntities []*StuckEntity
+	for _, watchdog := range []goalstate.Watchdog{
+		d.jobWatchdog, d.taskWatchdog, d.updateWatchdog} {
+		for _, stuck := range watchdog.GetStuckEntities() {
+			stuckEntities = append(stuckEntities, newStuckEntity(stuck))
+		}
+	}
+	return stuckEntities
+}
+
+// JobRefreshCache reloads the runtimes of the job and its tasks which are
+// stale in the cache from the DB. It is run by the job goal state engine,
+// which evaluates the job right after.
+func JobRefreshCache(ctx context.Context, entity goalstate.Entity) error {
+	jobEnt := entity.(*jobEntity)
+	goalStateDriver := jobEnt.driver
+	cachedJob := goalStateDriver.jobFactory.GetJob(jobEnt.id)
+	if cachedJob == nil {
+		return nil
+	}
+
+	if err := checkJobRuntimeConsistency(
+		ctx, cachedJob, goalStateDriver); err != nil {
+		return err
+	}
+	return checkTaskRuntimesConsistency(ctx, cachedJob, goalStateDriver)
+}
+
+// TaskRefreshCache replaces the task runtime in the cache with the one
+// in the DB, and evaluates the task again.
+func TaskRefreshCache(ctx context.Context, entity goalstate.Entity) error {
+	return reloadTaskRuntime(ctx, entity.(*taskEntity), true)
+}
+
+// TaskReconcile force reconciles the task in the cache with the host
+// manager. It is run by the task goal state engine, which evaluates the
+// task right after. Tasks are not reconciled while the cluster is
+// frozen, the stuck task detector and the task watchdog remediate them
+// again once it is unfrozen.
+func TaskReconcile(ctx context.Context, entity goalstate.Entity) error {
+	taskEnt := entity.(*taskEntity)
+	goalStateDriver := taskEnt.driver
+	if goalStateDriver.isFrozen() {
+		goalStateDriver.mtx.taskMetrics.TaskReconcileFrozen.Inc(1)
+		return nil
+	}
+	cachedJob := goalStateDriver.jobFactory.GetJob(taskEnt.jobID)
+	if cachedJob == nil {
+		return nil
+	}
+
+	cachedTask := cachedJob.GetTask(taskEnt.instanceID)
+	if cachedTask == nil {
+		return nil
+	}
+
+	runtime := cachedTask.GetCacheRuntime()
+	if runtime.GetMesosTaskId() == nil {
+		return nil
+	}
+
+	return goalStateDriver.lm.ReconcileTask(
+		ctx,
+		runtime.GetMesosTaskId().GetValue(),
+		runtime.GetAgentID().GetValue(),
+	)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type watchdogTestSuite struct {
+	suite.Suite
+
+	ctrl                *gomock.Controller
+	taskStore           *storemocks.MockTaskStore
+	taskConfigV2Ops     *objectmocks.MockTaskConfigV2Ops
+	taskGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory          *cachedmocks.MockJobFactory
+	cachedJob           *cachedmocks.MockJob
+	cachedTask          *cachedmocks.MockTask
+	lm                  *lmmocks.MockManager
+	goalStateDriver     *driver
+	taskEnt             *taskEntity
+	jobID               *peloton.JobID
+	instanceID          uint32
+}
+
+func TestWatchdog(t *testing.T) {
+	suite.Run(t, new(watchdogTestSuite))
+}
+
+func (suite *watchdogTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.taskStore = storemocks.NewMockTaskStore(suite.ctrl)
+	suite.taskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
+	suite.taskGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+	suite.lm = lmmocks.NewMockManager(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		taskStore:       suite.taskStore,
+		taskConfigV2Ops: suite.taskConfigV2Ops,
+		taskEngine:      suite.taskGoalStateEngine,
+		jobFactory:      suite.jobFactory,
+		lm:              suite.lm,
+		mtx:             NewMetrics(tally.NoopScope),
+		cfg:             &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.instanceID = uint32(1)
+	suite.taskEnt = &taskEntity{
+		jobID:      suite.jobID,
+		instanceID: suite.instanceID,
+		driver:     suite.goalStateDriver,
+	}
+}
+
+func (suite *watchdogTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// TestTaskRefreshCache tests replacing the task runtime in the cache
+// with the one in the DB regardless of its version
+func (suite *watchdogTestSuite) TestTaskRefreshCache() {
+	runtime := &pbtask.RuntimeInfo{
+		State:         pbtask.TaskState_RUNNING,
+		ConfigVersion: 2,
+	}
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.taskStore.EXPECT().
+		GetTaskRuntime(gomock.Any(), suite.jobID, suite.instanceID).
+		Return(runtime, nil)
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), suite.jobID, suite.instanceID, uint64(2)).
+		Return(&pbtask.TaskConfig{}, nil, nil)
+	suite.cachedJob.EXPECT().
+		ReplaceTasks(gomock.Any(), true).
+		Do(func(taskInfos map[uint32]*pbtask.TaskInfo, _ bool) {
+			suite.Equal(runtime, taskInfos[suite.instanceID].GetRuntime())
+		})
+	suite.taskGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(TaskRefreshCache(context.Background(), suite.taskEnt))
+}
+
+// TestTaskReconcile tests force reconciling a stuck task
+func (suite *watchdogTestSuite) TestTaskReconcile() {
+	mesosTaskID := fmt.Sprintf("%s-%d-%d", suite.jobID.GetValue(), suite.instanceID, 3)
+	agentID := "agent"
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().GetTask(suite.instanceID).Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(&pbtask.RuntimeInfo{
+		State:       pbtask.TaskState_LAUNCHED,
+		MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+		AgentID:     &mesos.AgentID{Value: &agentID},
+	})
+	suite.lm.EXPECT().
+		ReconcileTask(gomock.Any(), mesosTaskID, agentID).
+		Return(nil)
+
+	suite.NoError(TaskReconcile(context.Background(), suite.taskEnt))
+}
+
+// TestTaskReconcileFailure tests failing to force reconcile a stuck task
+func (suite *watchdogTestSuite) TestTaskReconcileFailure() {
+	mesosTaskID := fmt.Sprintf("%s-%d-%d", suite.jobID.GetValue(), suite.instanceID, 3)
+
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().GetTask(suite.instanceID).Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(&pbtask.RuntimeInfo{
+		State:       pbtask.TaskState_LAUNCHED,
+		MesosTaskId: &mesos.TaskID{Value: &mesosTaskID},
+	})
+	suite.lm.EXPECT().
+		ReconcileTask(gomock.Any(), mesosTaskID, "").
+		Return(fmt.Errorf("fake error"))
+
+	suite.Error(TaskReconcile(context.Background(), suite.taskEnt))
+}
+
+// TestTaskReconcileFrozen tests that a stuck task is not reconciled
+// while the cluster is frozen
+func (suite *watchdogTestSuite) TestTaskReconcileFrozen() {
+	suite.goalStateDriver.freezeState = frozenState{}
+
+	suite.NoError(TaskReconcile(context.Background(), suite.taskEnt))
+}
+
+// TestTaskReconcileNoRuntime tests that a task without a runtime
+// in the cache is not reconciled
+func (suite *watchdogTestSuite) TestTaskReconcileNoRuntime() {
+	suite.jobFactory.EXPECT().GetJob(suite.jobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().GetTask(suite.instanceID).Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().GetCacheRuntime().Return(nil)
+
+	suite.NoError(TaskReconcile(context.Background(), suite.taskEnt))
+}
+
+// TestIsConverged tests whether jobs and tasks have converged
+// to their goal state
+func (suite *watchdogTestSuite) TestIsConverged() {
+	suite.True(suite.taskEnt.IsConverged(
+		cached.TaskStateVector{State: pbtask.TaskState_RUNNING},
+		cached.TaskStateVector{State: pbtask.TaskState_RUNNING}))
+	suite.False(suite.taskEnt.IsConverged(
+		cached.TaskStateVector{State: pbtask.TaskState_LAUNCHED},
+		cached.TaskStateVector{State: pbtask.TaskState_RUNNING}))
+	suite.False(suite.taskEnt.IsConverged(
+		cached.TaskStateVector{State: pbtask.TaskState_UNKNOWN},
+		cached.TaskStateVector{State: pbtask.TaskState_RUNNING}))
+
+	jobEnt := &jobEntity{id: suite.jobID, driver: suite.goalStateDriver}
+	suite.True(jobEnt.IsConverged(
+		cached.JobStateVector{State: pbjob.JobState_RUNNING},
+		cached.JobStateVector{State: pbjob.JobState_SUCCEEDED}))
+	suite.False(jobEnt.IsConverged(
+		cached.JobStateVector{State: pbjob.JobState_RUNNING, StateVersion: 1},
+		cached.JobStateVector{State: pbjob.JobState_RUNNING, StateVersion: 2}))
+	suite.False(jobEnt.IsConverged(
+		cached.JobStateVector{State: pbjob.JobState_UNKNOWN},
+		cached.JobStateVector{State: pbjob.JobState_RUNNING}))
+}
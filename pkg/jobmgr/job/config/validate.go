@@ -18,12 +18,16 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/cron"
 	"github.com/uber/peloton/pkg/common/taskconfig"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 
 	"github.com/hashicorp/go-multierror"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -33,6 +37,8 @@ const (
 	_updateNotSupported = "updating %s not supported"
 	// Max retries on task failures.
 	_maxTaskRetries = 100
+	// Max length of a DNS label
+	_maxDNSLabelLength = 63
 )
 
 var (
@@ -50,6 +56,10 @@ var (
 		"MinimumRunningInstances should be 0 for stateless job")
 	errIncorrectMaxRunningTimeSLA = yarpcerrors.InvalidArgumentErrorf(
 		"MaxRunningTime should be 0 for stateless job")
+	errIncorrectResourceUsageBudgetSLA = yarpcerrors.InvalidArgumentErrorf(
+		"ResourceUsageBudget should not be set for stateless job")
+	errIncorrectMaxCompletionTimeSLA = yarpcerrors.InvalidArgumentErrorf(
+		"MaxCompletionTime should be 0 for stateless job")
 	errKillOnPreemptNotFalse = yarpcerrors.InvalidArgumentErrorf(
 		"Task preemption policy should be false for stateless job")
 	errIncorrectHealthCheck = yarpcerrors.InvalidArgumentErrorf(
@@ -66,6 +76,19 @@ var (
 		"can't override the preemption policy of a task" +
 			" which is going to be a part of a gang having tasks with" +
 			" a different preemption policy")
+	errArrayConfigNotBatch = yarpcerrors.InvalidArgumentErrorf(
+		"array config is only supported for batch jobs")
+	errArrayParametersAndFile = yarpcerrors.InvalidArgumentErrorf(
+		"job array can't have both parameters and a parameter file")
+	errCompletedJobTTLNotBatch = yarpcerrors.InvalidArgumentErrorf(
+		"max completed job ttl is only supported for batch jobs")
+	errCronConfigNotBatch = yarpcerrors.InvalidArgumentErrorf(
+		"cron config is only supported for batch jobs")
+	errDependenciesNotBatch = yarpcerrors.InvalidArgumentErrorf(
+		"job dependencies are only supported for batch jobs")
+
+	// a DNS label as defined in RFC 1123
+	_dnsLabelRegexp = regexp.MustCompile("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$")
 
 	_jobTypeTaskValidate = map[job.JobType]func(*task.TaskConfig) error{
 		job.JobType_BATCH:   validateBatchTaskConfig,
@@ -129,6 +152,41 @@ func ValidateUpdatedConfig(oldConfig *job.JobConfig,
 			fmt.Errorf(_updateNotSupported, "DefaultConfig"))
 	}
 
+	if oldConfig.ControllerTaskName != newConfig.ControllerTaskName {
+		errs = multierror.Append(errs,
+			fmt.Errorf(_updateNotSupported, "ControllerTaskName"))
+	}
+
+	if oldConfig.ControllerPolicy != newConfig.ControllerPolicy {
+		errs = multierror.Append(errs,
+			fmt.Errorf(_updateNotSupported, "ControllerPolicy"))
+	}
+
+	if oldConfig.StableInstanceNames != newConfig.StableInstanceNames {
+		errs = multierror.Append(errs,
+			fmt.Errorf(_updateNotSupported, "StableInstanceNames"))
+	}
+
+	// parameters of existing array elements should not be updated
+	if !isArrayConfigExtended(oldConfig.GetArrayConfig(),
+		newConfig.GetArrayConfig()) {
+		errs = multierror.Append(errs,
+			errors.New("existing array config can't be updated"))
+	}
+
+	// a job can't be turned into a cron job or back, its schedule and
+	// policies can be updated
+	if (oldConfig.GetCronConfig() == nil) != (newConfig.GetCronConfig() == nil) {
+		errs = multierror.Append(errs,
+			errors.New("cron config can't be added or removed"))
+	}
+
+	if !reflect.DeepEqual(oldConfig.GetDependencies(),
+		newConfig.GetDependencies()) {
+		errs = multierror.Append(errs,
+			fmt.Errorf(_updateNotSupported, "Dependencies"))
+	}
+
 	if newConfig.InstanceCount < oldConfig.InstanceCount {
 		errs = multierror.Append(errs,
 			errors.New("new instance count can't be less"))
@@ -160,6 +218,23 @@ func ValidateUpdatedConfig(oldConfig *job.JobConfig,
 	return errs.ErrorOrNil()
 }
 
+// isArrayConfigExtended returns true if the new array config only adds
+// parameters for new array elements to the old one.
+func isArrayConfigExtended(oldConfig, newConfig *job.JobArrayConfig) bool {
+	if (oldConfig == nil) != (newConfig == nil) ||
+		oldConfig.GetParameterFile() != newConfig.GetParameterFile() ||
+		len(newConfig.GetParameters()) < len(oldConfig.GetParameters()) {
+		return false
+	}
+
+	for i, parameter := range oldConfig.GetParameters() {
+		if newConfig.GetParameters()[i] != parameter {
+			return false
+		}
+	}
+	return true
+}
+
 // validateTaskConfigWithRange validates jobConfig with instancesNumber within [from, to)
 func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32, from uint32, to uint32) error {
 
@@ -196,10 +271,47 @@ func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32
 		return err
 	}
 
+	if err := validateInstanceNames(jobConfig); err != nil {
+		return err
+	}
+
+	if err := validateArrayConfig(jobConfig); err != nil {
+		return err
+	}
+
+	if err := validateCronConfig(jobConfig); err != nil {
+		return err
+	}
+
+	if err := validateDependencies(jobConfig); err != nil {
+		return err
+	}
+
+	if err := validatePlacementRelaxationPolicy(jobConfig); err != nil {
+		return err
+	}
+
+	if jobConfig.GetMaxCompletedJobTtl() != 0 &&
+		jobConfig.GetType() != job.JobType_BATCH {
+		return errCompletedJobTTLNotBatch
+	}
+
+	controllerIDs := taskconfig.ControllerInstanceIDs(jobConfig)
+	if len(jobConfig.GetControllerTaskName()) != 0 && len(controllerIDs) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"controller task %s not found", jobConfig.GetControllerTaskName())
+	}
+	isController := make(map[uint32]bool)
+	for _, id := range controllerIDs {
+		isController[id] = true
+	}
+
 	// validate task config
 	for i := from; i < to; i++ {
-		taskConfig := taskconfig.Merge(
-			defaultConfig, jobConfig.GetInstanceConfig()[i])
+		taskConfig := taskconfig.MergeWithStrategy(
+			defaultConfig,
+			jobConfig.GetInstanceConfig()[i],
+			jobConfig.GetInstanceConfigMergeStrategy())
 		if taskConfig == nil {
 			return yarpcerrors.InvalidArgumentErrorf(
 				"missing task config for instance %v", i)
@@ -217,29 +329,9 @@ func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32
 		//	}
 		//}
 
-		restartPolicy := taskConfig.GetRestartPolicy()
-		if restartPolicy.GetMaxFailures() > _maxTaskRetries {
-			restartPolicy.MaxFailures = _maxTaskRetries
-		}
-
-		if err := validatePortConfig(taskConfig); err != nil {
-			return errInvalidTaskConfig(i, err)
-		}
-
-		if taskConfig.GetCommand() == nil {
-			return yarpcerrors.InvalidArgumentErrorf("missing command info for instance %v", i)
-		}
-
-		if taskConfig.GetController() && i != 0 {
-			return yarpcerrors.InvalidArgumentErrorf("only task 0 can be controller task")
-		}
-
-		if err := _jobTypeTaskValidate[jobType](taskConfig); err != nil {
-			return errInvalidTaskConfig(i, err)
-		}
-
-		if err := validatePreemptionPolicy(i, taskConfig, jobConfig); err != nil {
-			return errInvalidTaskConfig(i, err)
+		if err := validateInstanceTaskConfig(
+			jobConfig, i, taskConfig, isController[i]); err != nil {
+			return err
 		}
 	}
 
@@ -259,6 +351,91 @@ func validateTaskConfigWithRange(jobConfig *job.JobConfig, maxTasksPerJob uint32
 	return nil
 }
 
+// ValidateInstanceOverride validates the task config of an instance of a
+// job with the given override merged on top of it, so that an override
+// which would make the instance fail to launch is rejected when it is set.
+func ValidateInstanceOverride(
+	jobConfig *job.JobConfig,
+	instanceID uint32,
+	override *task.TaskConfig) error {
+	if override == nil {
+		return yarpcerrors.InvalidArgumentErrorf("override is not set")
+	}
+	if _, ok := _jobTypeTaskValidate[jobConfig.GetType()]; !ok {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"invalid job type: %v", jobConfig.GetType())
+	}
+	if instanceID >= jobConfig.GetInstanceCount() {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"instance %d is out of range, job has %d instances",
+			instanceID, jobConfig.GetInstanceCount())
+	}
+
+	isController := false
+	for _, id := range taskconfig.ControllerInstanceIDs(jobConfig) {
+		if id == instanceID {
+			isController = true
+		}
+	}
+
+	taskConfig := taskconfig.Merge(
+		taskconfig.MergeWithStrategy(
+			jobConfig.GetDefaultConfig(),
+			jobConfig.GetInstanceConfig()[instanceID],
+			jobConfig.GetInstanceConfigMergeStrategy()),
+		override)
+	if taskConfig == nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"missing task config for instance %v", instanceID)
+	}
+	return validateInstanceTaskConfig(
+		jobConfig, instanceID, taskConfig, isController)
+}
+
+// validateInstanceTaskConfig validates the merged task config of an
+// instance of a job.
+func validateInstanceTaskConfig(
+	jobConfig *job.JobConfig,
+	instanceID uint32,
+	taskConfig *task.TaskConfig,
+	isController bool) error {
+	restartPolicy := taskConfig.GetRestartPolicy()
+	if restartPolicy.GetMaxFailures() > _maxTaskRetries {
+		restartPolicy.MaxFailures = _maxTaskRetries
+	}
+
+	if err := validatePortConfig(taskConfig); err != nil {
+		return errInvalidTaskConfig(instanceID, err)
+	}
+
+	if taskConfig.GetCommand() == nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"missing command info for instance %v", instanceID)
+	}
+
+	if taskConfig.GetController() && !isController {
+		if len(jobConfig.GetControllerTaskName()) != 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"task %v can't be controller task, only tasks named %s can",
+				instanceID, jobConfig.GetControllerTaskName())
+		}
+		return yarpcerrors.InvalidArgumentErrorf(
+			"task %v can't be controller task, the controller flag of "+
+				"the default config only applies to its lowest instance",
+			instanceID)
+	}
+
+	if err := _jobTypeTaskValidate[jobConfig.GetType()](taskConfig); err != nil {
+		return errInvalidTaskConfig(instanceID, err)
+	}
+
+	if err := validatePreemptionPolicy(
+		instanceID, taskConfig, jobConfig); err != nil {
+		return errInvalidTaskConfig(instanceID, err)
+	}
+	return nil
+}
+
 func errInvalidTaskConfig(instanceID uint32, err error) error {
 	return yarpcerrors.InvalidArgumentErrorf(
 		"Invalid config for instance %v, %v", instanceID, err)
@@ -293,6 +470,128 @@ func validatePreemptionPolicy(instanceID uint32, taskConfig *task.TaskConfig,
 	return nil
 }
 
+// validateInstanceNames validates that the stable names of all the
+// instances of a job are valid DNS labels, if the job has them.
+func validateInstanceNames(jobConfig *job.JobConfig) error {
+	if !jobConfig.GetStableInstanceNames() {
+		return nil
+	}
+
+	if !_dnsLabelRegexp.MatchString(jobConfig.GetName()) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"job name %q is not a valid DNS label, required for "+
+				"stable instance names", jobConfig.GetName())
+	}
+
+	var lastInstanceID uint32
+	if jobConfig.GetInstanceCount() > 0 {
+		lastInstanceID = jobConfig.GetInstanceCount() - 1
+	}
+	if name := jobutil.InstanceName(
+		jobConfig.GetName(), lastInstanceID); len(name) > _maxDNSLabelLength {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"instance name %q is longer than %d characters",
+			name, _maxDNSLabelLength)
+	}
+	return nil
+}
+
+// validateArrayConfig validates the array config of a job, if the job
+// is a job array.
+func validateArrayConfig(jobConfig *job.JobConfig) error {
+	arrayConfig := jobConfig.GetArrayConfig()
+	if arrayConfig == nil {
+		return nil
+	}
+
+	if jobConfig.GetType() != job.JobType_BATCH {
+		return errArrayConfigNotBatch
+	}
+
+	if len(arrayConfig.GetParameters()) != 0 &&
+		len(arrayConfig.GetParameterFile()) != 0 {
+		return errArrayParametersAndFile
+	}
+
+	if len(arrayConfig.GetParameters()) != 0 &&
+		uint32(len(arrayConfig.GetParameters())) != jobConfig.GetInstanceCount() {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"job array has %d parameters, which does not match "+
+				"instance count %d",
+			len(arrayConfig.GetParameters()), jobConfig.GetInstanceCount())
+	}
+	return nil
+}
+
+// validateCronConfig validates the cron config of a job, if the job is
+// a cron job.
+func validateCronConfig(jobConfig *job.JobConfig) error {
+	cronConfig := jobConfig.GetCronConfig()
+	if cronConfig == nil {
+		return nil
+	}
+
+	if jobConfig.GetType() != job.JobType_BATCH {
+		return errCronConfigNotBatch
+	}
+
+	if _, err := cron.Parse(cronConfig.GetSchedule()); err != nil {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"invalid cron schedule: %v", err)
+	}
+	return nil
+}
+
+// validateDependencies validates the jobs which a job depends on. Cycles
+// can only be detected by the job service, since they involve the configs
+// of the other jobs.
+func validateDependencies(jobConfig *job.JobConfig) error {
+	dependencies := jobConfig.GetDependencies()
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	if jobConfig.GetType() != job.JobType_BATCH {
+		return errDependenciesNotBatch
+	}
+
+	seen := make(map[string]bool, len(dependencies))
+	for _, dependency := range dependencies {
+		if len(dependency.GetValue()) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"job dependency has an empty job id")
+		}
+		if seen[dependency.GetValue()] {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"duplicate job dependency %s", dependency.GetValue())
+		}
+		seen[dependency.GetValue()] = true
+	}
+	return nil
+}
+
+// validatePlacementRelaxationPolicy validates that each step of the placement
+// relaxation policy relaxes a valid soft placement preference, and that no
+// preference is relaxed more than once.
+func validatePlacementRelaxationPolicy(jobConfig *job.JobConfig) error {
+	steps := jobConfig.GetPlacementRelaxationPolicy().GetSteps()
+	seen := make(map[job.PlacementPreference]bool, len(steps))
+	for _, step := range steps {
+		if step.GetPreference() ==
+			job.PlacementPreference_PLACEMENT_PREFERENCE_INVALID {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"placement relaxation step has an invalid preference")
+		}
+		if seen[step.GetPreference()] {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"duplicate placement relaxation step for %s",
+				step.GetPreference().String())
+		}
+		seen[step.GetPreference()] = true
+	}
+	return nil
+}
+
 // validatePortConfig checks port name and port env name exists for dynamic port.
 func validatePortConfig(taskConfig *task.TaskConfig) error {
 	portConfigs := taskConfig.GetPorts()
@@ -323,6 +622,16 @@ func validateBatchTaskConfig(taskConfig *task.TaskConfig) error {
 
 // validateBatchJobConfig validate jobconfig for batch job
 func validateBatchJobConfig(jobConfig *job.JobConfig) error {
+	for kind, budget := range jobConfig.GetSLA().GetResourceUsageBudget() {
+		if kind != common.CPU && kind != common.GPU && kind != common.MEMORY {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"invalid resource kind in ResourceUsageBudget: %s", kind)
+		}
+		if budget <= 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"ResourceUsageBudget for %s should be positive", kind)
+		}
+	}
 	return nil
 }
 
@@ -364,6 +673,16 @@ func validateStatelessJobConfig(jobConfig *job.JobConfig) error {
 		return errIncorrectMaxRunningTimeSLA
 	}
 
+	// stateless job should not set ResourceUsageBudget
+	if len(configSLA.GetResourceUsageBudget()) != 0 {
+		return errIncorrectResourceUsageBudgetSLA
+	}
+
+	// stateless job should not set MaxCompletionTime
+	if configSLA.GetMaxCompletionTime() != 0 {
+		return errIncorrectMaxCompletionTimeSLA
+	}
+
 	if configSLA.GetRevocable() == true &&
 		configSLA.GetPreemptible() != true {
 		return errIncorrectRevocableSLA
@@ -29,6 +29,7 @@ import (
 	"github.com/uber/peloton/pkg/common/util"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/yarpcerrors"
 	"gopkg.in/yaml.v2"
 )
 
@@ -151,6 +152,354 @@ func TestValidateTaskConfigFailureBatch(t *testing.T) {
 		"code:invalid-argument message:Batch job task should not set health check ")
 }
 
+func TestValidateInstanceOverride(t *testing.T) {
+	jobConfig := &job.JobConfig{
+		Name:          fmt.Sprintf("TestJob_1"),
+		InstanceCount: 2,
+		DefaultConfig: &task.TaskConfig{
+			Command: &mesos.CommandInfo{
+				Value: util.PtrPrintf("echo Hello"),
+			},
+		},
+	}
+
+	assert.NoError(t, ValidateInstanceOverride(jobConfig, 1, &task.TaskConfig{
+		Resource: &task.ResourceConfig{CpuLimit: 2},
+	}))
+
+	// override not set
+	err := ValidateInstanceOverride(jobConfig, 1, nil)
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	// instance out of range
+	err = ValidateInstanceOverride(jobConfig, 2, &task.TaskConfig{})
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	// the merged task config is invalid
+	err = ValidateInstanceOverride(jobConfig, 0, &task.TaskConfig{
+		Ports: []*task.PortConfig{{Value: 80}},
+	})
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+
+	// only the controller tasks can be overridden as controller
+	err = ValidateInstanceOverride(jobConfig, 0, &task.TaskConfig{
+		Controller: true,
+	})
+	assert.True(t, yarpcerrors.IsInvalidArgument(err))
+}
+
+func TestValidateTaskConfigController(t *testing.T) {
+	newJobConfig := func() *job.JobConfig {
+		return &job.JobConfig{
+			Name:          fmt.Sprintf("TestJob_1"),
+			InstanceCount: 4,
+			DefaultConfig: &task.TaskConfig{
+				Name: "worker",
+				Command: &mesos.CommandInfo{
+					Value: util.PtrPrintf("echo Hello"),
+				},
+			},
+			InstanceConfig: map[uint32]*task.TaskConfig{
+				2: {Name: "driver", Controller: true},
+			},
+		}
+	}
+
+	// controller task does not need to be instance 0
+	assert.NoError(t, ValidateConfig(newJobConfig(), maxTasksPerJob))
+
+	// controller task designated by name
+	jobConfig := newJobConfig()
+	jobConfig.ControllerTaskName = "driver"
+	jobConfig.InstanceConfig[2].Controller = false
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// more than one controller task
+	jobConfig = newJobConfig()
+	jobConfig.InstanceConfig[3] = &task.TaskConfig{Controller: true}
+	jobConfig.ControllerPolicy = job.ControllerPolicy_CONTROLLER_POLICY_MAJORITY
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// controller policy can't be updated
+	newConfig := newJobConfig()
+	newConfig.InstanceConfig[3] = &task.TaskConfig{Controller: true}
+	assert.Error(t, ValidateUpdatedConfig(jobConfig, newConfig, maxTasksPerJob))
+
+	// controller flag set on a task other than the named ones
+	jobConfig = newJobConfig()
+	jobConfig.ControllerTaskName = "worker"
+	assert.EqualError(t, ValidateConfig(jobConfig, maxTasksPerJob),
+		"code:invalid-argument message:task 2 can't be controller task, "+
+			"only tasks named worker can")
+
+	// controller flag of the default config set on multiple instances
+	jobConfig = newJobConfig()
+	jobConfig.DefaultConfig.Controller = true
+	assert.EqualError(t, ValidateConfig(jobConfig, maxTasksPerJob),
+		"code:invalid-argument message:task 1 can't be controller task, "+
+			"the controller flag of the default config only applies to "+
+			"its lowest instance")
+
+	// named controller task does not exist
+	jobConfig = newJobConfig()
+	jobConfig.ControllerTaskName = "master"
+	assert.EqualError(t, ValidateConfig(jobConfig, maxTasksPerJob),
+		"code:invalid-argument message:controller task master not found")
+}
+
+func TestValidateStableInstanceNames(t *testing.T) {
+	jobConfig := &job.JobConfig{
+		Name:                "test-job",
+		InstanceCount:       10,
+		StableInstanceNames: true,
+		DefaultConfig: &task.TaskConfig{
+			Command: &mesos.CommandInfo{
+				Value: util.PtrPrintf("echo Hello"),
+			},
+		},
+	}
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// job name which is not a DNS label
+	for _, name := range []string{"TestJob_1", "-test", "test-", "a.b", ""} {
+		jobConfig.Name = name
+		assert.Error(t, ValidateConfig(jobConfig, maxTasksPerJob), name)
+	}
+
+	// instance name longer than a DNS label
+	jobConfig.Name = strings.Repeat("a", 61)
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+	jobConfig.InstanceCount = 11
+	assert.EqualError(t, ValidateConfig(jobConfig, maxTasksPerJob),
+		fmt.Sprintf("code:invalid-argument message:instance name %q "+
+			"is longer than 63 characters", jobConfig.Name+"-10"))
+
+	// job name is not validated if instances do not have stable names
+	jobConfig.Name = "TestJob_1"
+	jobConfig.StableInstanceNames = false
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+}
+
+// TestValidateMaxCompletedJobTTL tests that a completed job TTL is only
+// accepted for batch jobs
+func TestValidateMaxCompletedJobTTL(t *testing.T) {
+	jobConfig := &job.JobConfig{
+		Name:               "test-job",
+		InstanceCount:      1,
+		MaxCompletedJobTtl: 3600,
+		DefaultConfig: &task.TaskConfig{
+			Command: &mesos.CommandInfo{
+				Value: util.PtrPrintf("echo Hello"),
+			},
+		},
+	}
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	jobConfig.Type = job.JobType_SERVICE
+	assert.Equal(t, errCompletedJobTTLNotBatch,
+		ValidateConfig(jobConfig, maxTasksPerJob))
+}
+
+// TestValidateCronConfig tests that a cron config is only accepted for
+// batch jobs with a valid schedule, and can't be added to an existing job
+func TestValidateCronConfig(t *testing.T) {
+	newJobConfig := func(cronConfig *job.CronConfig) *job.JobConfig {
+		return &job.JobConfig{
+			Name:          "test-job",
+			InstanceCount: 1,
+			DefaultConfig: &task.TaskConfig{
+				Command: &mesos.CommandInfo{
+					Value: util.PtrPrintf("echo Hello"),
+				},
+			},
+			CronConfig: cronConfig,
+		}
+	}
+
+	jobConfig := newJobConfig(&job.CronConfig{Schedule: "*/15 * * * *"})
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// invalid schedule
+	jobConfig.CronConfig.Schedule = "* * *"
+	assert.Error(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// not a batch job
+	jobConfig.CronConfig.Schedule = "@daily"
+	jobConfig.Type = job.JobType_SERVICE
+	assert.Equal(t, errCronConfigNotBatch,
+		ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// the schedule can be updated
+	assert.NoError(t, ValidateUpdatedConfig(
+		newJobConfig(&job.CronConfig{Schedule: "@daily"}),
+		newJobConfig(&job.CronConfig{Schedule: "@hourly"}),
+		maxTasksPerJob))
+
+	// but not to an invalid one
+	assert.Error(t, ValidateUpdatedConfig(
+		newJobConfig(&job.CronConfig{Schedule: "@daily"}),
+		newJobConfig(&job.CronConfig{Schedule: "@never"}),
+		maxTasksPerJob))
+
+	// cron config can't be added to an existing job
+	assert.Error(t, ValidateUpdatedConfig(
+		newJobConfig(nil),
+		newJobConfig(&job.CronConfig{Schedule: "@daily"}),
+		maxTasksPerJob))
+}
+
+// TestValidateDependencies tests that job dependencies are only accepted
+// for batch jobs without duplicates, and can't be updated
+func TestValidateDependencies(t *testing.T) {
+	newJobConfig := func(dependencies ...string) *job.JobConfig {
+		jobConfig := &job.JobConfig{
+			Name:          "test-job",
+			InstanceCount: 1,
+			DefaultConfig: &task.TaskConfig{
+				Command: &mesos.CommandInfo{
+					Value: util.PtrPrintf("echo Hello"),
+				},
+			},
+		}
+		for _, dependency := range dependencies {
+			jobConfig.Dependencies = append(jobConfig.Dependencies,
+				&peloton.JobID{Value: dependency})
+		}
+		return jobConfig
+	}
+
+	assert.NoError(t, ValidateConfig(
+		newJobConfig("job-1", "job-2"), maxTasksPerJob))
+
+	// empty job id
+	assert.Error(t, ValidateConfig(
+		newJobConfig("job-1", ""), maxTasksPerJob))
+
+	// duplicate dependency
+	assert.Error(t, ValidateConfig(
+		newJobConfig("job-1", "job-1"), maxTasksPerJob))
+
+	// not a batch job
+	jobConfig := newJobConfig("job-1")
+	jobConfig.Type = job.JobType_SERVICE
+	assert.Equal(t, errDependenciesNotBatch,
+		ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// dependencies can't be updated
+	assert.NoError(t, ValidateUpdatedConfig(
+		newJobConfig("job-1"), newJobConfig("job-1"), maxTasksPerJob))
+	assert.Error(t, ValidateUpdatedConfig(
+		newJobConfig("job-1"), newJobConfig("job-2"), maxTasksPerJob))
+	assert.Error(t, ValidateUpdatedConfig(
+		newJobConfig(), newJobConfig("job-1"), maxTasksPerJob))
+}
+
+// TestValidatePlacementRelaxationPolicy tests that each step of a placement
+// relaxation policy relaxes a distinct valid preference
+func TestValidatePlacementRelaxationPolicy(t *testing.T) {
+	newJobConfig := func(prefs ...job.PlacementPreference) *job.JobConfig {
+		jobConfig := &job.JobConfig{
+			Name:          "test-job",
+			InstanceCount: 1,
+			DefaultConfig: &task.TaskConfig{
+				Command: &mesos.CommandInfo{
+					Value: util.PtrPrintf("echo Hello"),
+				},
+			},
+			PlacementRelaxationPolicy: &job.PlacementRelaxationPolicy{},
+		}
+		for i, pref := range prefs {
+			jobConfig.PlacementRelaxationPolicy.Steps = append(
+				jobConfig.PlacementRelaxationPolicy.Steps,
+				&job.PlacementRelaxationStep{
+					WaitSecs:   uint32(60 * (i + 1)),
+					Preference: pref,
+				})
+		}
+		return jobConfig
+	}
+
+	assert.NoError(t, ValidateConfig(newJobConfig(), maxTasksPerJob))
+	assert.NoError(t, ValidateConfig(
+		newJobConfig(
+			job.PlacementPreference_PLACEMENT_PREFERENCE_DESIRED_HOST,
+			job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+		), maxTasksPerJob))
+
+	// invalid preference
+	assert.Error(t, ValidateConfig(
+		newJobConfig(job.PlacementPreference_PLACEMENT_PREFERENCE_INVALID),
+		maxTasksPerJob))
+
+	// duplicate preference
+	assert.Error(t, ValidateConfig(
+		newJobConfig(
+			job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+			job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+		), maxTasksPerJob))
+}
+
+func TestValidateArrayConfig(t *testing.T) {
+	newJobConfig := func(instanceCount uint32, parameters ...string) *job.JobConfig {
+		return &job.JobConfig{
+			Name:          "test-job",
+			InstanceCount: instanceCount,
+			DefaultConfig: &task.TaskConfig{
+				Command: &mesos.CommandInfo{
+					Value: util.PtrPrintf("echo Hello"),
+				},
+			},
+			ArrayConfig: &job.JobArrayConfig{
+				Parameters: parameters,
+			},
+		}
+	}
+
+	jobConfig := newJobConfig(2, "a", "b")
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// parameter count does not match instance count
+	jobConfig = newJobConfig(3, "a", "b")
+	assert.EqualError(t, ValidateConfig(jobConfig, maxTasksPerJob),
+		"code:invalid-argument message:job array has 2 parameters, "+
+			"which does not match instance count 3")
+
+	// both parameters and a parameter file
+	jobConfig = newJobConfig(2, "a", "b")
+	jobConfig.ArrayConfig.ParameterFile = "http://host/params.txt"
+	assert.Equal(t, errArrayParametersAndFile,
+		ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// only a parameter file
+	jobConfig.ArrayConfig.Parameters = nil
+	assert.NoError(t, ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// not a batch job
+	jobConfig.Type = job.JobType_SERVICE
+	assert.Equal(t, errArrayConfigNotBatch,
+		ValidateConfig(jobConfig, maxTasksPerJob))
+
+	// parameters can be added for new array elements
+	assert.NoError(t, ValidateUpdatedConfig(
+		newJobConfig(2, "a", "b"),
+		newJobConfig(3, "a", "b", "c"),
+		maxTasksPerJob))
+
+	// parameters of existing array elements can't be updated
+	assert.Error(t, ValidateUpdatedConfig(
+		newJobConfig(2, "a", "b"),
+		newJobConfig(3, "a", "x", "c"),
+		maxTasksPerJob))
+
+	// array config can't be added to an existing job
+	jobConfig = newJobConfig(2)
+	jobConfig.ArrayConfig = nil
+	assert.Error(t, ValidateUpdatedConfig(
+		jobConfig,
+		newJobConfig(2, "a", "b"),
+		maxTasksPerJob))
+}
+
 func TestValidateTaskConfigFailureMaxInstances(t *testing.T) {
 	// No error if there is a default task config
 	taskConfig := task.TaskConfig{
@@ -531,6 +880,16 @@ func TestValidateStatelessJobConfig(t *testing.T) {
 			SlaConfig: job.SlaConfig{MaxRunningTime: 1},
 			error:     errIncorrectMaxRunningTimeSLA,
 		},
+		{
+			SlaConfig: job.SlaConfig{
+				ResourceUsageBudget: map[string]float64{common.CPU: 1},
+			},
+			error: errIncorrectResourceUsageBudgetSLA,
+		},
+		{
+			SlaConfig: job.SlaConfig{MaxCompletionTime: 1},
+			error:     errIncorrectMaxCompletionTimeSLA,
+		},
 		{
 			SlaConfig: job.SlaConfig{Revocable: true, Preemptible: false},
 			error:     errIncorrectRevocableSLA,
@@ -574,6 +933,46 @@ func TestValidateStatelessTaskConfig(t *testing.T) {
 	}
 }
 
+func TestValidateBatchJobConfig(t *testing.T) {
+	testCases := []struct {
+		budget map[string]float64
+		valid  bool
+	}{
+		{
+			budget: map[string]float64{common.CPU: 3600, common.MEMORY: 1024},
+			valid:  true,
+		},
+		{
+			budget: map[string]float64{"disk": 3600},
+			valid:  false,
+		},
+		{
+			budget: map[string]float64{common.GPU: 0},
+			valid:  false,
+		},
+		{
+			valid: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		jobConfig := job.JobConfig{
+			Name:          "TestJob_1",
+			InstanceCount: 10,
+			DefaultConfig: &task.TaskConfig{},
+			SLA: &job.SlaConfig{
+				ResourceUsageBudget: testCase.budget,
+			},
+		}
+		err := validateBatchJobConfig(&jobConfig)
+		if testCase.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.True(t, yarpcerrors.IsInvalidArgument(err))
+		}
+	}
+}
+
 func TestValidateBatchTaskConfig(t *testing.T) {
 	testCases := []struct {
 		task.HealthCheckConfig
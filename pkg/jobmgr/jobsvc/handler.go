@@ -204,6 +204,19 @@ func (h *serviceHandler) Create(
 		}, nil
 	}
 
+	err = h.validateDependencies(ctx, jobID, jobConfig)
+	if err != nil {
+		h.metrics.JobCreateFail.Inc(1)
+		return &job.CreateResponse{
+			Error: &job.CreateResponse_Error{
+				InvalidConfig: &job.InvalidJobConfig{
+					Id:      jobID,
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
 	// check secrets and config for input sanity
 	if err = h.validateSecretsAndConfig(
 		jobConfig, req.GetSecrets()); err != nil {
@@ -605,6 +618,26 @@ func (h *serviceHandler) Query(ctx context.Context, req *job.QueryRequest) (resp
 		}, nil
 	}
 
+	// The cursor to resume the query after the page is only returned if
+	// the page size is set, there are more jobs after the page, and the
+	// jobs are sorted by properties a cursor supports.
+	var next *query.Cursor
+	pagination := req.GetSpec().GetPagination()
+	if limit := pagination.GetLimit(); limit > 0 &&
+		pagination.GetOffset()+limit < total {
+		summaries := jobSummary
+		if !req.GetSummaryOnly() {
+			summaries = convertJobInfosToJobSummaries(jobConfigs)
+		}
+		if cursor, err := storage.NewJobQueryCursor(
+			summaries,
+			storage.JobQueryOrderBy(req.GetSpec()),
+			pagination.GetAfter(),
+		); err == nil {
+			next = cursor
+		}
+	}
+
 	h.metrics.JobQuery.Inc(1)
 	resp = &job.QueryResponse{
 		Records: jobConfigs,
@@ -613,6 +646,7 @@ func (h *serviceHandler) Query(ctx context.Context, req *job.QueryRequest) (resp
 			Offset: req.GetSpec().GetPagination().GetOffset(),
 			Limit:  req.GetSpec().GetPagination().GetLimit(),
 			Total:  total,
+			Next:   next,
 		},
 		Spec: req.GetSpec(),
 	}
@@ -621,6 +655,21 @@ func (h *serviceHandler) Query(ctx context.Context, req *job.QueryRequest) (resp
 	return resp, nil
 }
 
+// convertJobInfosToJobSummaries returns the summaries of the jobs with the
+// properties a job query can be sorted by.
+func convertJobInfosToJobSummaries(jobInfos []*job.JobInfo) []*job.JobSummary {
+	var summaries []*job.JobSummary
+	for _, jobInfo := range jobInfos {
+		summaries = append(summaries, &job.JobSummary{
+			Id:      jobInfo.GetId(),
+			Name:    jobInfo.GetConfig().GetName(),
+			Owner:   jobInfo.GetConfig().GetOwningTeam(),
+			Runtime: jobInfo.GetRuntime(),
+		})
+	}
+	return summaries
+}
+
 // Delete removes jobs metadata from storage for a terminal job
 func (h *serviceHandler) Delete(
 	ctx context.Context,
@@ -681,7 +730,9 @@ func (h *serviceHandler) Delete(
 		return nil, err
 	}
 
-	// Delete job from goalstate and cache
+	// Delete job from goalstate and cache. A job which is not in cache
+	// has either never been loaded or was evicted, and eviction already
+	// untracks it from the goal state engines.
 	cachedJob := h.jobFactory.GetJob(req.GetId())
 	if cachedJob != nil {
 		taskMap := cachedJob.GetAllTasks()
@@ -1051,6 +1102,59 @@ func (h *serviceHandler) validateResourcePool(
 	return response.GetPoolinfo().GetPath(), nil
 }
 
+// validateDependencies checks that the jobs which a new job depends on
+// exist, and that none of them depends on the new job, directly or
+// transitively, since the jobs of a cycle would block each other forever.
+func (h *serviceHandler) validateDependencies(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	jobConfig *job.JobConfig,
+) error {
+	visited := make(map[string]bool)
+	queue := append([]*peloton.JobID(nil), jobConfig.GetDependencies()...)
+	for _, dependency := range queue {
+		visited[dependency.GetValue()] = true
+	}
+
+	for i := 0; i < len(queue); i++ {
+		dependency := queue[i]
+		if dependency.GetValue() == jobID.GetValue() {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"job dependencies form a cycle through job %s",
+				dependency.GetValue())
+		}
+
+		runtime, err := h.jobRuntimeOps.Get(ctx, dependency)
+		if yarpcerrors.IsNotFound(err) {
+			if i < len(jobConfig.GetDependencies()) {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"job dependency %s not found", dependency.GetValue())
+			}
+			// a deleted upstream job can't be part of a cycle
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		config, _, err := h.jobConfigOps.Get(
+			ctx,
+			dependency,
+			runtime.GetConfigurationVersion())
+		if err != nil {
+			return err
+		}
+
+		for _, upstream := range config.GetDependencies() {
+			if !visited[upstream.GetValue()] {
+				visited[upstream.GetValue()] = true
+				queue = append(queue, upstream)
+			}
+		}
+	}
+	return nil
+}
+
 // validateSecretsAndConfig checks the secrets for input sanity and makes sure
 // that config does not contain any existing secret volumes because that is
 // not supported.
@@ -25,6 +25,7 @@ import (
 	apierrors "github.com/uber/peloton/.gen/peloton/api/v0/errors"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -464,6 +465,67 @@ func (suite *JobHandlerTestSuite) TestCreateJob_ValidationErr() {
 	suite.Equal(expectedErr, resp.GetError())
 }
 
+// TestCreateJob_Dependencies tests creating a job which depends on
+// other jobs, which must exist and must not depend on the new job
+func (suite *JobHandlerTestSuite) TestCreateJob_Dependencies() {
+	testCmd := "echo test"
+	upstreamID := &peloton.JobID{Value: uuid.New()}
+	newJobConfig := func(dependencies ...*peloton.JobID) *job.JobConfig {
+		return &job.JobConfig{
+			DefaultConfig: &task.TaskConfig{
+				Command: &mesos.CommandInfo{Value: &testCmd},
+			},
+			RespoolID:    suite.testRespoolID,
+			Dependencies: dependencies,
+		}
+	}
+	jobConfig := newJobConfig(upstreamID)
+	suite.setupMocks(suite.testJobID, suite.testRespoolID)
+
+	// the upstream job does not exist
+	suite.mockedJobRuntimeOps.EXPECT().
+		Get(gomock.Any(), upstreamID).
+		Return(nil, yarpcerrors.NotFoundErrorf("not found"))
+	resp, err := suite.handler.Create(suite.context, &job.CreateRequest{
+		Id:     suite.testJobID,
+		Config: jobConfig,
+	})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidConfig())
+
+	// the upstream job depends on the new job
+	suite.mockedJobRuntimeOps.EXPECT().
+		Get(gomock.Any(), upstreamID).
+		Return(&job.RuntimeInfo{ConfigurationVersion: 1}, nil)
+	suite.mockedJobConfigOps.EXPECT().
+		Get(gomock.Any(), upstreamID, uint64(1)).
+		Return(newJobConfig(suite.testJobID), nil, nil)
+	resp, err = suite.handler.Create(suite.context, &job.CreateRequest{
+		Id:     suite.testJobID,
+		Config: jobConfig,
+	})
+	suite.NoError(err)
+	suite.NotNil(resp.GetError().GetInvalidConfig())
+
+	// the upstream job has no dependencies
+	suite.mockedJobRuntimeOps.EXPECT().
+		Get(gomock.Any(), upstreamID).
+		Return(&job.RuntimeInfo{ConfigurationVersion: 1}, nil)
+	suite.mockedJobConfigOps.EXPECT().
+		Get(gomock.Any(), upstreamID, uint64(1)).
+		Return(newJobConfig(), nil, nil)
+	suite.mockedCachedJob.EXPECT().
+		Create(gomock.Any(), jobConfig, gomock.Any(), nil).
+		Return(nil)
+	resp, err = suite.handler.Create(suite.context, &job.CreateRequest{
+		Id:     suite.testJobID,
+		Config: jobConfig,
+	})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(suite.testJobID, resp.GetJobId())
+}
+
 func (suite *JobHandlerTestSuite) TestCreateJob_RootRespoolFail() {
 	testCmd := "echo test"
 	jobID := &peloton.JobID{
@@ -847,7 +909,8 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgr() {
 			EnqueueGangs(
 				gomock.Any(),
 				gomock.Eq(&resmgrsvc.EnqueueGangsRequest{
-					Gangs: gangs,
+					Gangs:            gangs,
+					IdempotencyToken: jobmgrtask.GetEnqueueGangsToken(tasksInfo),
 				})).
 			Do(func(_ context.Context, reqBody interface{}) {
 				req := reqBody.(*resmgrsvc.EnqueueGangsRequest)
@@ -862,7 +925,9 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgr() {
 		suite.handler.rootCtx,
 		tasksInfo,
 		suite.testJobConfig,
-		suite.mockedResmgrClient)
+		suite.mockedResmgrClient,
+		nil,
+		tally.NoopScope)
 	suite.Equal(gangs, expectedGangs)
 }
 
@@ -878,7 +943,8 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgrError() {
 			EnqueueGangs(
 				gomock.Any(),
 				gomock.Eq(&resmgrsvc.EnqueueGangsRequest{
-					Gangs: gangs,
+					Gangs:            gangs,
+					IdempotencyToken: jobmgrtask.GetEnqueueGangsToken(tasksInfo),
 				})).
 			Do(func(_ context.Context, reqBody interface{}) {
 				req := reqBody.(*resmgrsvc.EnqueueGangsRequest)
@@ -892,7 +958,9 @@ func (suite *JobHandlerTestSuite) TestSubmitTasksToResmgrError() {
 		suite.handler.rootCtx,
 		tasksInfo,
 		suite.testJobConfig,
-		suite.mockedResmgrClient)
+		suite.mockedResmgrClient,
+		nil,
+		tally.NoopScope)
 	suite.Error(err)
 }
 
@@ -1719,6 +1787,54 @@ func (suite *JobHandlerTestSuite) TestJobQuery() {
 	suite.NotNil(resp)
 }
 
+// TestJobQueryNextCursor tests that the cursor of the last job is returned
+// when there are more jobs after the page
+func (suite *JobHandlerTestSuite) TestJobQueryNextCursor() {
+	spec := &job.QuerySpec{
+		Pagination: &query.PaginationSpec{
+			Limit: 2,
+			OrderBy: []*query.OrderBy{
+				{
+					Order:    query.OrderBy_ASC,
+					Property: &query.PropertyPath{Value: "state"},
+				},
+			},
+		},
+	}
+	summaries := []*job.JobSummary{
+		{
+			Id:      &peloton.JobID{Value: "job1"},
+			Runtime: &job.RuntimeInfo{State: job.JobState_PENDING},
+		},
+		{
+			Id:      &peloton.JobID{Value: "job2"},
+			Runtime: &job.RuntimeInfo{State: job.JobState_RUNNING},
+		},
+	}
+
+	suite.mockedJobStore.EXPECT().QueryJobs(suite.context, nil, spec, true).
+		Return(nil, summaries, uint32(3), nil)
+	resp, err := suite.handler.Query(suite.context, &job.QueryRequest{
+		Spec:        spec,
+		SummaryOnly: true,
+	})
+	suite.NoError(err)
+	suite.Equal(&query.Cursor{
+		Values: []string{job.JobState_RUNNING.String()},
+		Keys:   []string{"job2"},
+	}, resp.GetPagination().GetNext())
+
+	// no cursor is returned for the last page
+	suite.mockedJobStore.EXPECT().QueryJobs(suite.context, nil, spec, true).
+		Return(nil, summaries, uint32(2), nil)
+	resp, err = suite.handler.Query(suite.context, &job.QueryRequest{
+		Spec:        spec,
+		SummaryOnly: true,
+	})
+	suite.NoError(err)
+	suite.Nil(resp.GetPagination().GetNext())
+}
+
 // TestJobQuery tests failure case for Job Query API
 // This is fairly minimal, all interesting test cases are in the unit tests
 // for store.QueryJobs()
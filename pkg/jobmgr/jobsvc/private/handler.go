@@ -20,6 +20,7 @@ import (
 
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/private/jobmgrsvc"
@@ -32,6 +33,7 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	jobconfig "github.com/uber/peloton/pkg/jobmgr/job/config"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
@@ -53,6 +55,8 @@ type serviceHandler struct {
 	goalStateDriver goalstate.Driver
 	candidate       leader.Candidate
 	rootCtx         context.Context
+
+	instanceOverrideOps ormobjects.InstanceOverrideOps
 }
 
 // InitPrivateJobServiceHandler initializes the Job
@@ -78,6 +82,8 @@ func InitPrivateJobServiceHandler(
 		jobFactory:      jobFactory,
 		goalStateDriver: goalStateDriver,
 		candidate:       candidate,
+
+		instanceOverrideOps: ormobjects.NewInstanceOverrideOps(ormStore),
 	}
 	d.Register(jobmgrsvc.BuildJobManagerServiceYARPCProcedures(handler))
 }
@@ -324,6 +330,10 @@ func (h *serviceHandler) GetInstanceAvailabilityInfoForJob(
 	}()
 
 	job := h.jobFactory.GetJob(&peloton.JobID{Value: req.GetJobId().GetValue()})
+	if job == nil {
+		return nil,
+			yarpcerrors.NotFoundErrorf("job not found in cache")
+	}
 
 	instanceAvailabilityMap := make(map[uint32]string)
 	for i, t := range job.GetInstanceAvailabilityType(ctx, req.GetInstances()...) {
@@ -334,6 +344,140 @@ func (h *serviceHandler) GetInstanceAvailabilityInfoForJob(
 	}, nil
 }
 
+func (h *serviceHandler) SetInstanceOverride(
+	ctx context.Context,
+	req *jobmgrsvc.SetInstanceOverrideRequest,
+) (resp *jobmgrsvc.SetInstanceOverrideResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("JobSVC.SetInstanceOverride failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Info("JobSVC.SetInstanceOverride succeeded")
+	}()
+
+	jobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
+	if err := h.validateInstanceOverride(
+		ctx,
+		jobID,
+		req.GetInstanceId(),
+		req.GetOverride()); err != nil {
+		return nil, err
+	}
+
+	if err := h.instanceOverrideOps.Create(
+		ctx,
+		jobID,
+		req.GetInstanceId(),
+		req.GetOverride(),
+	); err != nil {
+		return nil, errors.Wrap(err, "fail to create instance override")
+	}
+
+	return &jobmgrsvc.SetInstanceOverrideResponse{}, nil
+}
+
+func (h *serviceHandler) DeleteInstanceOverride(
+	ctx context.Context,
+	req *jobmgrsvc.DeleteInstanceOverrideRequest,
+) (resp *jobmgrsvc.DeleteInstanceOverrideResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("JobSVC.DeleteInstanceOverride failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("headers", headers).
+			Info("JobSVC.DeleteInstanceOverride succeeded")
+	}()
+
+	if err := h.instanceOverrideOps.Delete(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+		req.GetInstanceId(),
+	); err != nil {
+		return nil, errors.Wrap(err, "fail to delete instance override")
+	}
+
+	return &jobmgrsvc.DeleteInstanceOverrideResponse{}, nil
+}
+
+func (h *serviceHandler) GetInstanceOverrides(
+	ctx context.Context,
+	req *jobmgrsvc.GetInstanceOverridesRequest,
+) (resp *jobmgrsvc.GetInstanceOverridesResponse, err error) {
+	defer func() {
+		headers := yarpcutil.GetHeaders(ctx)
+		if err != nil {
+			log.WithField("request", req).
+				WithField("headers", headers).
+				WithError(err).
+				Warn("JobSVC.GetInstanceOverrides failed")
+			err = yarpcutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("num_of_results", len(resp.GetOverrides())).
+			WithField("headers", headers).
+			Debug("JobSVC.GetInstanceOverrides succeeded")
+	}()
+
+	overrides, err := h.instanceOverrideOps.GetAll(
+		ctx,
+		&peloton.JobID{Value: req.GetJobId().GetValue()},
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to get instance overrides")
+	}
+
+	return &jobmgrsvc.GetInstanceOverridesResponse{Overrides: overrides}, nil
+}
+
+// validateInstanceOverride returns an error if the job is not found in
+// cache, or if the config of the instance with the override merged on top
+// of it is invalid for the current config of the job.
+func (h *serviceHandler) validateInstanceOverride(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	instanceID uint32,
+	override *pbtask.TaskConfig,
+) error {
+	cachedJob := h.jobFactory.GetJob(jobID)
+	if cachedJob == nil {
+		return yarpcerrors.NotFoundErrorf("job not found in cache")
+	}
+
+	cachedConfig, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fail to get job config")
+	}
+
+	jobConfig, _, err := h.jobConfigOps.Get(
+		ctx,
+		jobID,
+		cachedConfig.GetChangeLog().GetVersion())
+	if err != nil {
+		return errors.Wrap(err, "fail to get job config")
+	}
+
+	return jobconfig.ValidateInstanceOverride(jobConfig, instanceID, override)
+}
+
 // nameMatch returns true if queryName not set, or jobName
 // and queryName are the same
 func nameMatch(jobName string, queryName string) bool {
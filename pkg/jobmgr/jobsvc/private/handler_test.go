@@ -19,6 +19,7 @@ import (
 	"strconv"
 	"testing"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -71,6 +72,7 @@ type privateHandlerTestSuite struct {
 	jobIndexOps     *objectmocks.MockJobIndexOps
 	jobConfigOps    *objectmocks.MockJobConfigOps
 	jobRuntimeOps   *objectmocks.MockJobRuntimeOps
+	overrideOps     *objectmocks.MockInstanceOverrideOps
 }
 
 func (suite *privateHandlerTestSuite) SetupTest() {
@@ -86,6 +88,7 @@ func (suite *privateHandlerTestSuite) SetupTest() {
 	suite.jobIndexOps = objectmocks.NewMockJobIndexOps(suite.ctrl)
 	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.ctrl)
 	suite.jobRuntimeOps = objectmocks.NewMockJobRuntimeOps(suite.ctrl)
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
 	suite.handler = &serviceHandler{
 		jobFactory:      suite.jobFactory,
 		candidate:       suite.candidate,
@@ -97,6 +100,8 @@ func (suite *privateHandlerTestSuite) SetupTest() {
 		jobConfigOps:    suite.jobConfigOps,
 		jobRuntimeOps:   suite.jobRuntimeOps,
 		rootCtx:         context.Background(),
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 }
 
@@ -601,3 +606,161 @@ func (suite *privateHandlerTestSuite) TestGetInstanceAvailabilityInfoForJobSucce
 		)
 	}
 }
+
+// TestGetInstanceAvailabilityInfoForJobNotFound tests the failure case
+// of getting instance availability information for a job not in cache
+func (suite *privateHandlerTestSuite) TestGetInstanceAvailabilityInfoForJobNotFound() {
+	suite.jobFactory.EXPECT().GetJob(testPelotonJobID).Return(nil)
+
+	response, err := suite.handler.GetInstanceAvailabilityInfoForJob(
+		context.Background(),
+		&jobmgrsvc.GetInstanceAvailabilityInfoForJobRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		},
+	)
+	suite.Nil(response)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// expectOverrideJobConfig sets up the expectations to read the config of
+// the job an instance override is validated against
+func (suite *privateHandlerTestSuite) expectOverrideJobConfig() {
+	cachedConfig := cachedmocks.NewMockJobConfigCache(suite.ctrl)
+	command := "echo Hello"
+
+	suite.jobFactory.EXPECT().GetJob(testPelotonJobID).Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().GetConfig(gomock.Any()).Return(cachedConfig, nil)
+	cachedConfig.EXPECT().
+		GetChangeLog().
+		Return(&peloton.ChangeLog{Version: 2}).
+		AnyTimes()
+	suite.jobConfigOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID, uint64(2)).
+		Return(&pbjob.JobConfig{
+			InstanceCount: 3,
+			DefaultConfig: &pbtask.TaskConfig{
+				Command: &mesos.CommandInfo{Value: &command},
+			},
+		}, &models.ConfigAddOn{}, nil)
+}
+
+// TestSetInstanceOverrideSuccess tests setting the override of an instance
+func (suite *privateHandlerTestSuite) TestSetInstanceOverrideSuccess() {
+	override := &pbtask.TaskConfig{
+		Resource: &pbtask.ResourceConfig{CpuLimit: 2},
+	}
+
+	suite.expectOverrideJobConfig()
+	suite.overrideOps.EXPECT().
+		Create(gomock.Any(), testPelotonJobID, uint32(1), override).
+		Return(nil)
+
+	resp, err := suite.handler.SetInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.SetInstanceOverrideRequest{
+			JobId:      &v1alphapeloton.JobID{Value: testJobID},
+			InstanceId: 1,
+			Override:   override,
+		})
+	suite.NoError(err)
+	suite.NotNil(resp)
+}
+
+// TestSetInstanceOverrideInvalidInstance tests setting the override of
+// an instance out of the range of the job instances
+func (suite *privateHandlerTestSuite) TestSetInstanceOverrideInvalidInstance() {
+	suite.expectOverrideJobConfig()
+
+	resp, err := suite.handler.SetInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.SetInstanceOverrideRequest{
+			JobId:      &v1alphapeloton.JobID{Value: testJobID},
+			InstanceId: 3,
+			Override:   &pbtask.TaskConfig{},
+		})
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+	suite.Nil(resp)
+}
+
+// TestSetInstanceOverrideInvalidConfig tests setting an override which
+// makes the config of the instance invalid
+func (suite *privateHandlerTestSuite) TestSetInstanceOverrideInvalidConfig() {
+	suite.expectOverrideJobConfig()
+
+	resp, err := suite.handler.SetInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.SetInstanceOverrideRequest{
+			JobId:      &v1alphapeloton.JobID{Value: testJobID},
+			InstanceId: 1,
+			Override: &pbtask.TaskConfig{
+				Ports: []*pbtask.PortConfig{{Value: 80}},
+			},
+		})
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+	suite.Nil(resp)
+}
+
+// TestSetInstanceOverrideJobNotFound tests setting the override of
+// an instance of a job not in cache
+func (suite *privateHandlerTestSuite) TestSetInstanceOverrideJobNotFound() {
+	suite.jobFactory.EXPECT().GetJob(testPelotonJobID).Return(nil)
+
+	resp, err := suite.handler.SetInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.SetInstanceOverrideRequest{
+			JobId:    &v1alphapeloton.JobID{Value: testJobID},
+			Override: &pbtask.TaskConfig{},
+		})
+	suite.Error(err)
+	suite.True(yarpcerrors.IsNotFound(err))
+	suite.Nil(resp)
+}
+
+// TestDeleteInstanceOverride tests removing the override of an instance
+func (suite *privateHandlerTestSuite) TestDeleteInstanceOverride() {
+	suite.overrideOps.EXPECT().
+		Delete(gomock.Any(), testPelotonJobID, uint32(1)).
+		Return(nil)
+
+	resp, err := suite.handler.DeleteInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.DeleteInstanceOverrideRequest{
+			JobId:      &v1alphapeloton.JobID{Value: testJobID},
+			InstanceId: 1,
+		})
+	suite.NoError(err)
+	suite.NotNil(resp)
+
+	suite.overrideOps.EXPECT().
+		Delete(gomock.Any(), testPelotonJobID, uint32(1)).
+		Return(yarpcerrors.UnavailableErrorf("db unavailable"))
+
+	resp, err = suite.handler.DeleteInstanceOverride(
+		context.Background(),
+		&jobmgrsvc.DeleteInstanceOverrideRequest{
+			JobId:      &v1alphapeloton.JobID{Value: testJobID},
+			InstanceId: 1,
+		})
+	suite.Error(err)
+	suite.Nil(resp)
+}
+
+// TestGetInstanceOverrides tests getting the overrides of a job
+func (suite *privateHandlerTestSuite) TestGetInstanceOverrides() {
+	overrides := map[uint32]*pbtask.TaskConfig{
+		0: {Name: "override"},
+	}
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), testPelotonJobID).
+		Return(overrides, nil)
+
+	resp, err := suite.handler.GetInstanceOverrides(
+		context.Background(),
+		&jobmgrsvc.GetInstanceOverridesRequest{
+			JobId: &v1alphapeloton.JobID{Value: testJobID},
+		})
+	suite.NoError(err)
+	suite.Equal(overrides, resp.GetOverrides())
+}
@@ -25,6 +25,7 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbquery "github.com/uber/peloton/.gen/peloton/api/v0/query"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
@@ -39,6 +40,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	yarpcutil "github.com/uber/peloton/pkg/common/util/yarpc"
@@ -51,10 +53,12 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/task/activermtask"
 	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	updateutil "github.com/uber/peloton/pkg/jobmgr/util/update"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	"github.com/gocql/gocql"
+	"github.com/golang/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -321,6 +325,18 @@ func (h *serviceHandler) ReplaceJob(
 		return nil, errors.Wrap(err, "invalid job spec")
 	}
 
+	updateConfig := api.ConvertUpdateSpecToUpdateConfig(req.GetUpdateSpec())
+	if err := updateutil.ValidateVerificationHook(
+		updateConfig.GetVerificationHook()); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid update spec: %v", err)
+	}
+
+	if err := updateutil.ValidateHealthGate(updateConfig); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid update spec: %v", err)
+	}
+
 	jobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
 
 	cachedJob := h.jobFactory.AddJob(jobID)
@@ -368,7 +384,7 @@ func (h *serviceHandler) ReplaceJob(
 	updateID, newEntityVersion, err := cachedJob.CreateWorkflow(
 		ctx,
 		models.WorkflowType_UPDATE,
-		api.ConvertUpdateSpecToUpdateConfig(req.GetUpdateSpec()),
+		updateConfig,
 		req.GetVersion(),
 		cached.WithConfig(
 			jobConfig,
@@ -1307,10 +1323,26 @@ func (h *serviceHandler) QueryJobs(
 			Debug("JobSVC.QueryJobs succeeded")
 	}()
 
+	spec := req.GetSpec()
+	fingerprint := queryJobsFingerprint(spec)
+	after, offset, err := pagination.After(req.GetPageToken(), fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens issued with an offset resume the query at the offset
+	if offset > 0 {
+		spec = proto.Clone(spec).(*stateless.QuerySpec)
+		if spec.Pagination == nil {
+			spec.Pagination = &v1alphaquery.PaginationSpec{}
+		}
+		spec.Pagination.Offset = offset
+	}
+
 	var respoolID *peloton.ResourcePoolID
-	if len(req.GetSpec().GetRespool().GetValue()) > 0 {
+	if len(spec.GetRespool().GetValue()) > 0 {
 		respoolResp, err := h.respoolClient.LookupResourcePoolID(ctx, &respool.LookupRequest{
-			Path: &respool.ResourcePoolPath{Value: req.GetSpec().GetRespool().GetValue()},
+			Path: &respool.ResourcePoolPath{Value: spec.GetRespool().GetValue()},
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get respool id")
@@ -1318,7 +1350,16 @@ func (h *serviceHandler) QueryJobs(
 		respoolID = respoolResp.GetId()
 	}
 
-	querySpec := api.ConvertStatelessQuerySpecToJobQuerySpec(req.GetSpec())
+	querySpec := api.ConvertStatelessQuerySpecToJobQuerySpec(spec)
+	if after != nil {
+		if querySpec.Pagination == nil {
+			querySpec.Pagination = &pbquery.PaginationSpec{}
+		}
+		querySpec.Pagination.After = &pbquery.Cursor{
+			Values: after.Values,
+			Keys:   after.Keys,
+		}
+	}
 	log.WithField("spec", querySpec).Debug("converted spec")
 
 	_, jobSummaries, total, err := h.jobStore.QueryJobs(
@@ -1352,17 +1393,64 @@ func (h *serviceHandler) QueryJobs(
 		statelessJobSummaries = append(statelessJobSummaries, statelessJobSummary)
 	}
 
+	// a next page token is only returned if the page size is set,
+	// since the storage layer applies its own limit otherwise.
+	// The next page is resumed after the cursor of the last job, so
+	// that jobs created or deleted meanwhile do not shift the pages,
+	// unless the jobs are sorted by properties a cursor does not support.
+	var nextPageToken string
+	if limit := spec.GetPagination().GetLimit(); limit > 0 &&
+		spec.GetPagination().GetOffset()+limit < total {
+		cursor, cursorErr := storage.NewJobQueryCursor(
+			jobSummaries,
+			storage.JobQueryOrderBy(querySpec),
+			querySpec.GetPagination().GetAfter(),
+		)
+		if cursorErr == nil {
+			nextPageToken = pagination.NextCursorToken(
+				&pagination.Cursor{
+					Values: cursor.GetValues(),
+					Keys:   cursor.GetKeys(),
+				},
+				fingerprint,
+			)
+		} else {
+			nextPageToken = pagination.NextPageToken(
+				spec.GetPagination().GetOffset()+limit,
+				total,
+				fingerprint,
+			)
+		}
+	}
+
 	return &svc.QueryJobsResponse{
 		Records: statelessJobSummaries,
 		Pagination: &v1alphaquery.Pagination{
-			Offset: req.GetSpec().GetPagination().GetOffset(),
-			Limit:  req.GetSpec().GetPagination().GetLimit(),
+			Offset: spec.GetPagination().GetOffset(),
+			Limit:  spec.GetPagination().GetLimit(),
 			Total:  total,
 		},
-		Spec: req.GetSpec(),
+		Spec:          req.GetSpec(),
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+// queryJobsFingerprint returns the fingerprint of a job query, which
+// covers all the query criteria and the ordering of the result, but not
+// the offset and size of the page.
+func queryJobsFingerprint(spec *stateless.QuerySpec) string {
+	if spec == nil {
+		return pagination.Fingerprint()
+	}
+
+	spec = proto.Clone(spec).(*stateless.QuerySpec)
+	if spec.GetPagination() != nil {
+		spec.Pagination.Offset = 0
+		spec.Pagination.Limit = 0
+	}
+	return pagination.Fingerprint(proto.CompactTextString(spec))
+}
+
 func (h *serviceHandler) ListJobs(
 	req *svc.ListJobsRequest,
 	stream svc.JobServiceServiceListJobsYARPCServer) (err error) {
@@ -1455,9 +1543,26 @@ func (h *serviceHandler) ListJobWorkflows(
 		return nil, err
 	}
 
-	if req.GetUpdatesLimit() > 0 {
-		updateIDs = updateIDs[:util.Min(uint32(len(updateIDs)), req.GetUpdatesLimit())]
+	// updates are returned by the store ordered by creation time,
+	// newest first
+	keys := make([]string, 0, len(updateIDs))
+	for _, updateID := range updateIDs {
+		keys = append(keys, updateID.GetValue())
+	}
+	start, end, nextPageToken, err := pagination.Page(
+		req.GetPageToken(),
+		req.GetUpdatesLimit(),
+		pagination.Fingerprint(
+			req.GetJobId().GetValue(),
+			fmt.Sprint(req.GetInstanceEvents()),
+			fmt.Sprint(req.GetInstanceEventsLimit()),
+		),
+		keys,
+	)
+	if err != nil {
+		return nil, err
 	}
+	updateIDs = updateIDs[start:end]
 
 	pelotonJobID := &peloton.JobID{Value: req.GetJobId().GetValue()}
 
@@ -1539,7 +1644,10 @@ func (h *serviceHandler) ListJobWorkflows(
 		return nil, err
 	}
 
-	return &svc.ListJobWorkflowsResponse{WorkflowInfos: updateInfos}, nil
+	return &svc.ListJobWorkflowsResponse{
+		WorkflowInfos: updateInfos,
+		NextPageToken: nextPageToken,
+	}, nil
 }
 
 // getInstanceWorkflowEvents gets the workflow events for instances that were
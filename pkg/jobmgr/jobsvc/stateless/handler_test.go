@@ -25,6 +25,7 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbquery "github.com/uber/peloton/.gen/peloton/api/v0/query"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
@@ -40,6 +41,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
@@ -1159,6 +1161,99 @@ func (suite *statelessHandlerTestSuite) TestQueryJobsSuccess() {
 	suite.NoError(err)
 }
 
+// TestQueryJobsPageToken tests iterating over the result
+// of a job query using page tokens
+func (suite *statelessHandlerTestSuite) TestQueryJobsPageToken() {
+	spec := &stateless.QuerySpec{
+		Pagination: &v1alphaquery.PaginationSpec{
+			Limit: 2,
+		},
+		Owner: "owner1",
+	}
+
+	now := time.Now().UTC()
+	var jobSummaries []*pbjob.JobSummary
+	for i := 0; i < 3; i++ {
+		jobSummaries = append(jobSummaries, &pbjob.JobSummary{
+			Id:    &peloton.JobID{Value: fmt.Sprintf("job%d", i)},
+			Owner: "owner1",
+			Runtime: &pbjob.RuntimeInfo{
+				CreationTime: now.Add(-time.Duration(i) * time.Minute).
+					Format(time.RFC3339Nano),
+			},
+		})
+	}
+
+	gomock.InOrder(
+		suite.jobStore.EXPECT().
+			QueryJobs(gomock.Any(), nil, gomock.Any(), true).
+			Do(func(_ context.Context, _ *peloton.ResourcePoolID, querySpec *pbjob.QuerySpec, _ bool) {
+				suite.Nil(querySpec.GetPagination().GetAfter())
+			}).
+			Return(nil, jobSummaries[:2], uint32(3), nil),
+		suite.jobStore.EXPECT().
+			QueryJobs(gomock.Any(), nil, gomock.Any(), true).
+			Do(func(_ context.Context, _ *peloton.ResourcePoolID, querySpec *pbjob.QuerySpec, _ bool) {
+				// the second page is resumed after the last job of the first
+				suite.Equal(&pbquery.Cursor{
+					Values: []string{jobSummaries[1].GetRuntime().GetCreationTime()},
+					Keys:   []string{"job1"},
+				}, querySpec.GetPagination().GetAfter())
+			}).
+			Return(nil, jobSummaries[2:], uint32(1), nil),
+	)
+
+	resp, err := suite.handler.QueryJobs(
+		context.Background(),
+		&statelesssvc.QueryJobsRequest{Spec: spec},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetRecords(), 2)
+	suite.NotEmpty(resp.GetNextPageToken())
+
+	token := resp.GetNextPageToken()
+	resp, err = suite.handler.QueryJobs(
+		context.Background(),
+		&statelesssvc.QueryJobsRequest{Spec: spec, PageToken: token},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetRecords(), 1)
+	suite.Empty(resp.GetNextPageToken())
+
+	// tokens issued with an offset resume the query at the offset
+	suite.jobStore.EXPECT().
+		QueryJobs(gomock.Any(), nil, gomock.Any(), true).
+		Do(func(_ context.Context, _ *peloton.ResourcePoolID, querySpec *pbjob.QuerySpec, _ bool) {
+			suite.Equal(uint32(2), querySpec.GetPagination().GetOffset())
+			suite.Nil(querySpec.GetPagination().GetAfter())
+		}).
+		Return(nil, jobSummaries[2:], uint32(3), nil)
+	resp, err = suite.handler.QueryJobs(
+		context.Background(),
+		&statelesssvc.QueryJobsRequest{
+			Spec: spec,
+			PageToken: pagination.NextPageToken(
+				2, 3, queryJobsFingerprint(spec)),
+		},
+	)
+	suite.NoError(err)
+	suite.Equal(uint32(2), resp.GetPagination().GetOffset())
+	suite.Empty(resp.GetNextPageToken())
+
+	// the token cannot be used with a different query
+	_, err = suite.handler.QueryJobs(
+		context.Background(),
+		&statelesssvc.QueryJobsRequest{
+			Spec: &stateless.QuerySpec{
+				Pagination: spec.GetPagination(),
+				Owner:      "owner2",
+			},
+			PageToken: token,
+		},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
 // TestQueryJobsGetRespoolIDFail tests the failure case of query jobs
 // due to get respool id
 func (suite *statelessHandlerTestSuite) TestQueryJobsGetRespoolIdFail() {
@@ -3808,6 +3903,54 @@ func (suite *statelessHandlerTestSuite) TestListJobWorkflowsGetUpdatesLimitInsta
 	suite.Equal(1, len(resp.GetWorkflowInfos()[1].GetInstanceEvents()[0].GetEvents()))
 }
 
+// TestListJobWorkflowsPageToken tests listing the workflows
+// of a job page by page
+func (suite *statelessHandlerTestSuite) TestListJobWorkflowsPageToken() {
+	updateIDs := []*peloton.UpdateID{
+		{Value: "941ff353-ba82-49fe-8f80-fb5bc649b04r"},
+		{Value: "941ff353-ba82-49fe-8f80-fb5bc649b04p"},
+	}
+
+	suite.updateStore.EXPECT().
+		GetUpdatesForJob(gomock.Any(), testJobID).
+		Return(updateIDs, nil).
+		Times(2)
+
+	suite.jobRuntimeOps.EXPECT().
+		Get(gomock.Any(), testPelotonJobID).
+		Return(&pbjob.RuntimeInfo{State: pbjob.JobState_RUNNING}, nil).
+		Times(2)
+
+	for _, updateID := range updateIDs {
+		suite.updateStore.EXPECT().
+			GetUpdate(gomock.Any(), updateID).
+			Return(&models.UpdateModel{
+				UpdateID: updateID,
+				Type:     models.WorkflowType_UPDATE,
+				State:    pbupdate.State_SUCCEEDED,
+			}, nil)
+
+		suite.jobUpdateEventsOps.EXPECT().
+			GetAll(gomock.Any(), updateID).
+			Return(nil, nil)
+	}
+
+	req := &statelesssvc.ListJobWorkflowsRequest{
+		JobId:        &v1alphapeloton.JobID{Value: testJobID},
+		UpdatesLimit: 1,
+	}
+	resp, err := suite.handler.ListJobWorkflows(context.Background(), req)
+	suite.NoError(err)
+	suite.Len(resp.GetWorkflowInfos(), 1)
+	suite.NotEmpty(resp.GetNextPageToken())
+
+	req.PageToken = resp.GetNextPageToken()
+	resp, err = suite.handler.ListJobWorkflows(context.Background(), req)
+	suite.NoError(err)
+	suite.Len(resp.GetWorkflowInfos(), 1)
+	suite.Empty(resp.GetNextPageToken())
+}
+
 // TestListJobWorkflowsGetUpdatesFailure tests the failure
 // case of getting job updates due to fail to read updates of a job
 func (suite *statelessHandlerTestSuite) TestListJobWorkflowsGetUpdatesFailure() {
@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podevents
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+)
+
+const _podEventsCompactorName = "podEventsCompactor"
+
+// compactedRun is the run of a pod at its last compaction
+type compactedRun struct {
+	runID       uint64
+	compactedAt time.Time
+}
+
+// Compactor periodically enforces the retention policy of pod events,
+// which would otherwise grow unbounded for each pod. The events of the
+// runs of a pod which are beyond the retention are compacted to the last
+// event of each run. Only the pods of the jobs in cache are compacted,
+// and a pod is compacted again only once it has a new run, or once its
+// retained runs may have aged out of the retention.
+type Compactor struct {
+	JobFactory   cached.JobFactory
+	PodEventsOps objects.PodEventsOps
+	Metrics      *Metrics
+	Config       *Config
+
+	sync.Mutex
+	compacted map[string]compactedRun
+}
+
+// Register registers the compactor as a background work
+func (c *Compactor) Register(manager background.Manager) error {
+	if c.Config == nil {
+		c.Config = &Config{}
+	}
+
+	if !c.Config.Enabled {
+		log.Info("pod events compaction is disabled")
+		return nil
+	}
+
+	c.Config.normalize()
+	return manager.RegisterWorks(
+		background.Work{
+			Name: _podEventsCompactorName,
+			Func: func(_ *atomic.Bool) {
+				c.Compact()
+			},
+			Period: c.Config.CompactionPeriod,
+		},
+	)
+}
+
+// Compact compacts the events of the pods of all the jobs in cache
+// which are due for compaction.
+func (c *Compactor) Compact() {
+	c.Lock()
+	defer c.Unlock()
+
+	stopWatch := c.Metrics.ProcessDuration.Start()
+	defer stopWatch.Stop()
+
+	if c.compacted == nil {
+		c.compacted = make(map[string]compactedRun)
+	}
+
+	seen := make(map[string]struct{})
+	for id, cachedJob := range c.JobFactory.GetAllJobs() {
+		for instanceID, cachedTask := range cachedJob.GetAllTasks() {
+			// only the runtimes already in the cache are looked at
+			runtime := cachedTask.GetCacheRuntime()
+			if runtime == nil {
+				continue
+			}
+
+			runID, err := util.ParseRunID(runtime.GetMesosTaskId().GetValue())
+			if err != nil {
+				continue
+			}
+
+			key := fmt.Sprintf("%s-%d", id, instanceID)
+			seen[key] = struct{}{}
+			if !c.isCompactionDue(key, runID) {
+				continue
+			}
+
+			if err := c.compactPod(id, instanceID); err != nil {
+				log.WithFields(log.Fields{
+					"job_id":      id,
+					"instance_id": instanceID,
+				}).WithError(err).
+					Warn("failed to compact pod events")
+				c.Metrics.PodsCompactedFail.Inc(1)
+				continue
+			}
+			c.compacted[key] = compactedRun{
+				runID:       runID,
+				compactedAt: time.Now(),
+			}
+		}
+	}
+
+	// forget the pods which are no longer in cache
+	for key := range c.compacted {
+		if _, ok := seen[key]; !ok {
+			delete(c.compacted, key)
+		}
+	}
+}
+
+// isCompactionDue returns true if the events of a pod need to be
+// compacted. Pods whose runs are all within the retention by count
+// are never compacted.
+func (c *Compactor) isCompactionDue(key string, runID uint64) bool {
+	if runID <= c.Config.MaxRuns {
+		return false
+	}
+
+	last, ok := c.compacted[key]
+	if !ok || last.runID != runID {
+		return true
+	}
+	return c.Config.MaxAge > 0 &&
+		time.Since(last.compactedAt) >= c.Config.MaxAge
+}
+
+// compactPod compacts the events of a single pod.
+func (c *Compactor) compactPod(jobID string, instanceID uint32) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		c.Config.CompactionTimeout)
+	defer cancel()
+
+	removed, err := c.PodEventsOps.Compact(
+		ctx,
+		jobID,
+		instanceID,
+		c.Config.MaxRuns,
+		c.Config.MaxAge,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.Metrics.PodsCompacted.Inc(1)
+	c.Metrics.EventsRemoved.Inc(int64(removed))
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podevents
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	backgroundmocks "github.com/uber/peloton/pkg/common/background/mocks"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachemock "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+const _testJobID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06"
+
+type CompactorTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+
+	testScope    tally.TestScope
+	jobFactory   *cachemock.MockJobFactory
+	cachedJob    *cachemock.MockJob
+	cachedTask   *cachemock.MockTask
+	podEventsOps *objectmocks.MockPodEventsOps
+	compactor    *Compactor
+}
+
+func TestCompactor(t *testing.T) {
+	suite.Run(t, new(CompactorTestSuite))
+}
+
+func (s *CompactorTestSuite) SetupTest() {
+	s.mockCtrl = gomock.NewController(s.T())
+
+	s.testScope = tally.NewTestScope("", nil)
+	s.jobFactory = cachemock.NewMockJobFactory(s.mockCtrl)
+	s.cachedJob = cachemock.NewMockJob(s.mockCtrl)
+	s.cachedTask = cachemock.NewMockTask(s.mockCtrl)
+	s.podEventsOps = objectmocks.NewMockPodEventsOps(s.mockCtrl)
+
+	config := &Config{Enabled: true, MaxRuns: 2}
+	config.normalize()
+
+	s.compactor = &Compactor{
+		JobFactory:   s.jobFactory,
+		PodEventsOps: s.podEventsOps,
+		Metrics:      NewMetrics(s.testScope),
+		Config:       config,
+	}
+}
+
+func (s *CompactorTestSuite) TearDownTest() {
+	s.mockCtrl.Finish()
+}
+
+// expectRun sets up the cache to return the given run of the pod
+func (s *CompactorTestSuite) expectRun(runID uint64) {
+	s.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{_testJobID: s.cachedJob})
+	s.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(map[uint32]cached.Task{0: s.cachedTask})
+	s.cachedTask.EXPECT().
+		GetCacheRuntime().
+		Return(&pbtask.RuntimeInfo{
+			MesosTaskId: util.CreateMesosTaskID(
+				&peloton.JobID{Value: _testJobID}, 0, runID),
+		})
+}
+
+// TestRegister tests the compactor registers with background manager
+// only when enabled
+func (s *CompactorTestSuite) TestRegister() {
+	mockBackgroundManager := backgroundmocks.NewMockManager(s.mockCtrl)
+	mockBackgroundManager.EXPECT().RegisterWorks(gomock.Any()).Return(nil)
+	s.NoError(s.compactor.Register(mockBackgroundManager))
+
+	s.compactor.Config.Enabled = false
+	s.NoError(s.compactor.Register(mockBackgroundManager))
+}
+
+// TestCompact tests that pods are compacted only once they run beyond
+// the retention, and only again once they have a new run
+func (s *CompactorTestSuite) TestCompact() {
+	// runs within retention are not compacted
+	s.expectRun(2)
+	s.compactor.Compact()
+
+	// first run beyond retention is compacted
+	s.expectRun(3)
+	s.podEventsOps.EXPECT().
+		Compact(gomock.Any(), _testJobID, uint32(0), uint64(2), gomock.Any()).
+		Return(4, nil)
+	s.compactor.Compact()
+
+	// same run is not compacted again
+	s.expectRun(3)
+	s.compactor.Compact()
+
+	// new run is compacted
+	s.expectRun(4)
+	s.podEventsOps.EXPECT().
+		Compact(gomock.Any(), _testJobID, uint32(0), uint64(2), gomock.Any()).
+		Return(2, nil)
+	s.compactor.Compact()
+
+	s.Equal(int64(2), s.testScope.Snapshot().
+		Counters()["pod_events_compaction.pods_compacted+result=success"].Value())
+	s.Equal(int64(6), s.testScope.Snapshot().
+		Counters()["pod_events_compaction.events_removed+"].Value())
+}
+
+// TestCompactFailure tests that a failed compaction is retried on the
+// next compaction
+func (s *CompactorTestSuite) TestCompactFailure() {
+	s.expectRun(3)
+	s.podEventsOps.EXPECT().
+		Compact(gomock.Any(), _testJobID, uint32(0), uint64(2), gomock.Any()).
+		Return(0, errors.New("storage unavailable"))
+	s.compactor.Compact()
+
+	s.expectRun(3)
+	s.podEventsOps.EXPECT().
+		Compact(gomock.Any(), _testJobID, uint32(0), uint64(2), gomock.Any()).
+		Return(1, nil)
+	s.compactor.Compact()
+
+	s.Equal(int64(1), s.testScope.Snapshot().
+		Counters()["pod_events_compaction.pods_compacted+result=fail"].Value())
+}
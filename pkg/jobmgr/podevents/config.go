@@ -0,0 +1,60 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podevents
+
+import "time"
+
+const (
+	_defaultCompactionPeriod  = 1 * time.Hour
+	_defaultCompactionTimeout = 10 * time.Second
+	_defaultMaxRuns           = 10
+)
+
+// Config is the configuration of the retention policy of pod events.
+// The events of the runs of a pod beyond the retention are compacted
+// to the last event of each run.
+type Config struct {
+	// Enabled turns on the compaction of pod events
+	Enabled bool `yaml:"enabled"`
+
+	// MaxRuns is the number of the last runs of a pod whose events are
+	// all retained. Default to 10.
+	MaxRuns uint64 `yaml:"max_runs"`
+
+	// MaxAge is the age under which all the events of a run are
+	// retained, regardless of MaxRuns. Zero retains the runs by
+	// count only.
+	MaxAge time.Duration `yaml:"max_age"`
+
+	// CompactionPeriod is the period at which the pod events are compacted
+	CompactionPeriod time.Duration `yaml:"compaction_period"`
+
+	// CompactionTimeout is the timeout to compact the events of a pod
+	CompactionTimeout time.Duration `yaml:"compaction_timeout"`
+}
+
+func (c *Config) normalize() {
+	if c.MaxRuns == 0 {
+		c.MaxRuns = _defaultMaxRuns
+	}
+
+	if c.CompactionPeriod == time.Duration(0) {
+		c.CompactionPeriod = _defaultCompactionPeriod
+	}
+
+	if c.CompactionTimeout == time.Duration(0) {
+		c.CompactionTimeout = _defaultCompactionTimeout
+	}
+}
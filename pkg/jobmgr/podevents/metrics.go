@@ -0,0 +1,38 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podevents
+
+import "github.com/uber-go/tally"
+
+// Metrics tracks the compaction of pod events
+type Metrics struct {
+	PodsCompacted     tally.Counter
+	PodsCompactedFail tally.Counter
+	EventsRemoved     tally.Counter
+	ProcessDuration   tally.Timer
+}
+
+// NewMetrics returns a new Metrics struct rooted at the given scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	compactionScope := scope.SubScope("pod_events_compaction")
+	successScope := compactionScope.Tagged(map[string]string{"result": "success"})
+	failScope := compactionScope.Tagged(map[string]string{"result": "fail"})
+	return &Metrics{
+		PodsCompacted:     successScope.Counter("pods_compacted"),
+		PodsCompactedFail: failScope.Counter("pods_compacted"),
+		EventsRemoved:     compactionScope.Counter("events_removed"),
+		ProcessDuration:   compactionScope.Timer("duration"),
+	}
+}
@@ -16,6 +16,7 @@ package podsvc
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/common/pagination"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	yarpcutil "github.com/uber/peloton/pkg/common/util/yarpc"
@@ -533,11 +535,43 @@ func (h *serviceHandler) GetPodEvents(
 		return nil, errors.Wrap(err, "failed to get pod events from store")
 	}
 
+	// pod events are returned by the store ordered by
+	// pod run and event time, newest first
+	keys := make([]string, 0, len(podEvents))
+	for _, e := range podEvents {
+		keys = append(keys, podEventKey(e))
+	}
+	start, end, nextPageToken, err := pagination.Page(
+		req.GetPageToken(),
+		req.GetPageSize(),
+		pagination.Fingerprint(
+			req.GetPodName().GetValue(),
+			req.GetPodId().GetValue(),
+		),
+		keys,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &svc.GetPodEventsResponse{
-		Events: podEvents,
+		Events:        podEvents[start:end],
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
+// podEventKey returns the key which identifies a pod event
+// among the events of a pod
+func podEventKey(e *pbpod.PodEvent) string {
+	return strings.Join([]string{
+		e.GetPodId().GetValue(),
+		e.GetTimestamp(),
+		e.GetActualState(),
+		e.GetDesiredState(),
+		e.GetHealthy(),
+	}, "/")
+}
+
 func (h *serviceHandler) BrowsePodSandbox(
 	ctx context.Context,
 	req *svc.BrowsePodSandboxRequest,
@@ -855,14 +889,8 @@ func (h *serviceHandler) getPodIDForRestart(
 		return nil, err
 	}
 
-	runID, err :=
-		util.ParseRunID(runtimeInfo.GetMesosTaskId().GetValue())
-	if err != nil {
-		runID = 0
-	}
-
-	return util.CreateMesosTaskID(
-		cachedJob.ID(), instanceID, runID+1), nil
+	return taskutil.NextMesosTaskID(
+		cachedJob.ID(), instanceID, runtimeInfo.GetMesosTaskId()), nil
 }
 
 func (h *serviceHandler) getPodInfoForAllPodRuns(
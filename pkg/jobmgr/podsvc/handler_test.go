@@ -1775,6 +1775,49 @@ func (suite *podHandlerTestSuite) TestGetPodEvents() {
 	suite.Equal(events, response.GetEvents())
 }
 
+// TestGetPodEventsPagination tests getting pod events page by page,
+// with new events added between the pages
+func (suite *podHandlerTestSuite) TestGetPodEventsPagination() {
+	request := &svc.GetPodEventsRequest{
+		PodName: &v1alphapeloton.PodName{
+			Value: testPodName,
+		},
+		PageSize: 1,
+	}
+
+	newEvent := func(timestamp string, state pod.PodState) *pod.PodEvent {
+		return &pod.PodEvent{
+			PodId:        &v1alphapeloton.PodID{Value: testPodID},
+			Timestamp:    timestamp,
+			ActualState:  state.String(),
+			DesiredState: pod.PodState_POD_STATE_RUNNING.String(),
+		}
+	}
+	runningEvent := newEvent("2019-01-03T22:14:58Z", pod.PodState_POD_STATE_RUNNING)
+	launchedEvent := newEvent("2019-01-03T22:14:50Z", pod.PodState_POD_STATE_LAUNCHED)
+	killedEvent := newEvent("2019-01-03T22:15:10Z", pod.PodState_POD_STATE_KILLED)
+
+	gomock.InOrder(
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return([]*pod.PodEvent{runningEvent, launchedEvent}, nil),
+		suite.podStore.EXPECT().
+			GetPodEvents(gomock.Any(), testJobID, uint32(testInstanceID), "").
+			Return([]*pod.PodEvent{killedEvent, runningEvent, launchedEvent}, nil),
+	)
+
+	response, err := suite.handler.GetPodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Equal([]*pod.PodEvent{runningEvent}, response.GetEvents())
+	suite.NotEmpty(response.GetNextPageToken())
+
+	request.PageToken = response.GetNextPageToken()
+	response, err = suite.handler.GetPodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Equal([]*pod.PodEvent{launchedEvent}, response.GetEvents())
+	suite.Empty(response.GetNextPageToken())
+}
+
 // TestGetPodEventsPodNameParseError tests PodName parse error
 // while getting pod events for a given pod
 func (suite *podHandlerTestSuite) TestGetPodEventsPodNameParseError() {
@@ -0,0 +1,61 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/cassandra"
+)
+
+const (
+	_defaultReplicationPeriod = 30 * time.Second
+	_defaultWriteTimeout      = 10 * time.Second
+	_defaultReconcilePeriod   = 1 * time.Hour
+)
+
+// Config is the configuration of the job metadata replicator, which ships
+// job config and runtime summaries to a read-only mirror cluster.
+type Config struct {
+	// Enabled turns on replication to the mirror cluster
+	Enabled bool `yaml:"enabled"`
+
+	// ReplicationPeriod is the period at which job summaries are replicated
+	ReplicationPeriod time.Duration `yaml:"replication_period"`
+
+	// ReconcilePeriod is the period at which the jobs missing from the
+	// local storage are removed from the mirror storage
+	ReconcilePeriod time.Duration `yaml:"reconcile_period"`
+
+	// WriteTimeout is the timeout of a single write to the mirror storage
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// Mirror is the Cassandra configuration of the mirror cluster storage
+	Mirror cassandra.Config `yaml:"mirror"`
+}
+
+func (c *Config) normalize() {
+	if c.ReplicationPeriod == time.Duration(0) {
+		c.ReplicationPeriod = _defaultReplicationPeriod
+	}
+
+	if c.ReconcilePeriod == time.Duration(0) {
+		c.ReconcilePeriod = _defaultReconcilePeriod
+	}
+
+	if c.WriteTimeout == time.Duration(0) {
+		c.WriteTimeout = _defaultWriteTimeout
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import "github.com/uber-go/tally"
+
+// Metrics tracks the job metadata replication to the mirror cluster
+type Metrics struct {
+	JobsReplicated     tally.Counter
+	JobsReplicatedFail tally.Counter
+	JobsSkipped        tally.Counter
+	JobsDeleted        tally.Counter
+	JobsDeletedFail    tally.Counter
+	JobsLagging        tally.Gauge
+	ProcessDuration    tally.Timer
+}
+
+// NewMetrics returns a new Metrics struct rooted at the given scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	replicationScope := scope.SubScope("replication")
+	successScope := replicationScope.Tagged(map[string]string{"result": "success"})
+	failScope := replicationScope.Tagged(map[string]string{"result": "fail"})
+	return &Metrics{
+		JobsReplicated:     successScope.Counter("jobs_replicated"),
+		JobsReplicatedFail: failScope.Counter("jobs_replicated"),
+		JobsSkipped:        replicationScope.Counter("jobs_skipped"),
+		JobsDeleted:        successScope.Counter("jobs_deleted"),
+		JobsDeletedFail:    failScope.Counter("jobs_deleted"),
+		JobsLagging:        replicationScope.Gauge("jobs_lagging"),
+		ProcessDuration:    replicationScope.Timer("duration"),
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"go.uber.org/multierr"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const _jobReplicatorName = "jobReplicator"
+
+// replicatedVersion is the version of a job last shipped to the mirror
+type replicatedVersion struct {
+	configVersion   uint64
+	runtimeRevision uint64
+}
+
+// JobReplicator periodically ships the config and runtime summaries of
+// the jobs in cache to the job_index table of a read-only mirror
+// cluster, so that global dashboards and DR tooling can query job
+// state even when the control plane of this region is down.
+// Only the jobs whose runtime changed since the last run are shipped.
+// The jobs deleted from the local storage are deleted from the mirror.
+type JobReplicator struct {
+	JobFactory cached.JobFactory
+	// JobConfigOps reads the job configs from the local storage
+	JobConfigOps objects.JobConfigOps
+	// JobIndexOps reads the job summaries from the local storage
+	JobIndexOps objects.JobIndexOps
+	// MirrorJobIndexOps writes the job summaries to the mirror storage
+	MirrorJobIndexOps objects.JobIndexOps
+	Metrics           *Metrics
+	Config            *Config
+
+	sync.Mutex
+	replicated map[string]replicatedVersion
+	// lastReconciled is the last time the jobs missing from the local
+	// storage were removed from the mirror
+	lastReconciled time.Time
+}
+
+// Register registers the replicator as a background work
+func (r *JobReplicator) Register(manager background.Manager) error {
+	if r.Config == nil {
+		r.Config = &Config{}
+	}
+
+	if !r.Config.Enabled {
+		log.Info("job metadata replication is disabled")
+		return nil
+	}
+
+	r.Config.normalize()
+	return manager.RegisterWorks(
+		background.Work{
+			Name: _jobReplicatorName,
+			Func: func(_ *atomic.Bool) {
+				r.Replicate()
+			},
+			Period: r.Config.ReplicationPeriod,
+		},
+	)
+}
+
+// Replicate ships the summaries of all the jobs which changed since the
+// last replication to the mirror storage, and removes the jobs which
+// were deleted from the local storage from the mirror storage.
+func (r *JobReplicator) Replicate() {
+	r.Lock()
+	defer r.Unlock()
+
+	stopWatch := r.Metrics.ProcessDuration.Start()
+	defer stopWatch.Stop()
+
+	if r.replicated == nil {
+		r.replicated = make(map[string]replicatedVersion)
+	}
+
+	var lagging int
+	jobs := r.JobFactory.GetAllJobs()
+	for id, cachedJob := range jobs {
+		if err := r.replicateJob(id, cachedJob); err != nil {
+			log.WithField("job_id", id).
+				WithError(err).
+				Warn("failed to replicate job to mirror")
+			r.Metrics.JobsReplicatedFail.Inc(1)
+			lagging++
+		}
+	}
+
+	// the jobs which are no longer in cache are either deleted or
+	// evicted. The deleted jobs are removed from the mirror, while the
+	// mirror keeps the last replicated summary of the evicted ones, since
+	// a job is loaded back into cache before it is changed again.
+	for id := range r.replicated {
+		if _, ok := jobs[id]; ok {
+			continue
+		}
+		if err := r.replicateDelete(id); err != nil {
+			log.WithField("job_id", id).
+				WithError(err).
+				Warn("failed to replicate job delete to mirror")
+			r.Metrics.JobsDeletedFail.Inc(1)
+			lagging++
+			continue
+		}
+		delete(r.replicated, id)
+	}
+
+	if time.Since(r.lastReconciled) >= r.Config.ReconcilePeriod {
+		if err := r.reconcileDeletes(jobs); err != nil {
+			log.WithError(err).
+				Warn("failed to remove deleted jobs from mirror")
+			lagging++
+		} else {
+			r.lastReconciled = time.Now()
+		}
+	}
+
+	r.Metrics.JobsLagging.Update(float64(lagging))
+}
+
+// replicateDelete removes a job which is no longer in cache from the
+// mirror storage, if the job is deleted from the local storage.
+func (r *JobReplicator) replicateDelete(id string) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		r.Config.WriteTimeout)
+	defer cancel()
+
+	jobID := &peloton.JobID{Value: id}
+	_, err := r.JobIndexOps.Get(ctx, jobID)
+	if err == nil {
+		// the job is evicted from cache
+		return nil
+	}
+	if !yarpcerrors.IsNotFound(err) {
+		return err
+	}
+
+	if err := r.MirrorJobIndexOps.Delete(ctx, jobID); err != nil {
+		return err
+	}
+	r.Metrics.JobsDeleted.Inc(1)
+	return nil
+}
+
+// reconcileDeletes removes the jobs which are in the mirror storage but
+// not in the local storage. It covers the jobs deleted while this process
+// was not running, or while they were evicted from cache, which
+// replicateDelete never sees since they are not in the replicated
+// versions.
+func (r *JobReplicator) reconcileDeletes(jobs map[string]cached.Job) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		r.Config.WriteTimeout)
+	defer cancel()
+
+	mirrorSummaries, err := r.MirrorJobIndexOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	localSummaries, err := r.JobIndexOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	local := make(map[string]struct{})
+	for _, summary := range localSummaries {
+		local[summary.GetId().GetValue()] = struct{}{}
+	}
+
+	var errs []error
+	for _, summary := range mirrorSummaries {
+		id := summary.GetId().GetValue()
+		if _, ok := local[id]; ok {
+			continue
+		}
+		if _, ok := jobs[id]; ok {
+			continue
+		}
+		if err := r.MirrorJobIndexOps.Delete(ctx, summary.GetId()); err != nil {
+			r.Metrics.JobsDeletedFail.Inc(1)
+			errs = append(errs, err)
+			continue
+		}
+		r.Metrics.JobsDeleted.Inc(1)
+	}
+	return multierr.Combine(errs...)
+}
+
+// replicateJob ships the summary of a single job if it changed since
+// the last replication.
+func (r *JobReplicator) replicateJob(id string, cachedJob cached.Job) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		r.Config.WriteTimeout)
+	defer cancel()
+
+	runtime, err := cachedJob.GetRuntime(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := replicatedVersion{
+		configVersion:   runtime.GetConfigurationVersion(),
+		runtimeRevision: runtime.GetRevision().GetVersion(),
+	}
+	last, ok := r.replicated[id]
+	if ok && last == current {
+		r.Metrics.JobsSkipped.Inc(1)
+		return nil
+	}
+
+	// the config is only shipped when its version changed, otherwise
+	// only the runtime fields are updated in the mirror
+	var config *pbjob.JobConfig
+	if !ok || last.configVersion != current.configVersion {
+		config, _, err = r.JobConfigOps.Get(
+			ctx,
+			cachedJob.ID(),
+			current.configVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := r.MirrorJobIndexOps.Update(
+		ctx,
+		cachedJob.ID(),
+		config,
+		runtime,
+	); err != nil {
+		return err
+	}
+
+	r.replicated[id] = current
+	r.Metrics.JobsReplicated.Inc(1)
+	return nil
+}
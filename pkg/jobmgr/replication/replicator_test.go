@@ -0,0 +1,265 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replication
+
+import (
+	"testing"
+	"time"
+
+	backgroundmocks "github.com/uber/peloton/pkg/common/background/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachemock "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type JobReplicatorTestSuite struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+
+	testScope     tally.TestScope
+	jobFactory    *cachemock.MockJobFactory
+	jobConfigOps  *objectmocks.MockJobConfigOps
+	jobIndexOps   *objectmocks.MockJobIndexOps
+	mirrorIndexOp *objectmocks.MockJobIndexOps
+	replicator    *JobReplicator
+}
+
+func TestJobReplicator(t *testing.T) {
+	suite.Run(t, new(JobReplicatorTestSuite))
+}
+
+func (s *JobReplicatorTestSuite) SetupTest() {
+	s.mockCtrl = gomock.NewController(s.T())
+
+	s.testScope = tally.NewTestScope("", nil)
+	s.jobFactory = cachemock.NewMockJobFactory(s.mockCtrl)
+	s.jobConfigOps = objectmocks.NewMockJobConfigOps(s.mockCtrl)
+	s.jobIndexOps = objectmocks.NewMockJobIndexOps(s.mockCtrl)
+	s.mirrorIndexOp = objectmocks.NewMockJobIndexOps(s.mockCtrl)
+
+	config := &Config{Enabled: true}
+	config.normalize()
+
+	s.replicator = &JobReplicator{
+		JobFactory:        s.jobFactory,
+		JobConfigOps:      s.jobConfigOps,
+		JobIndexOps:       s.jobIndexOps,
+		MirrorJobIndexOps: s.mirrorIndexOp,
+		Metrics:           NewMetrics(s.testScope),
+		Config:            config,
+	}
+}
+
+func (s *JobReplicatorTestSuite) TearDownTest() {
+	s.mockCtrl.Finish()
+}
+
+// expectReconcile sets the expectations of the reconciliation of the
+// mirror, which has no job missing from the local storage
+func (s *JobReplicatorTestSuite) expectReconcile() {
+	s.mirrorIndexOp.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+	s.jobIndexOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+}
+
+// TestRegister tests the replicator registers with background manager
+// only when enabled
+func (s *JobReplicatorTestSuite) TestRegister() {
+	mockBackgroundManager := backgroundmocks.NewMockManager(s.mockCtrl)
+	mockBackgroundManager.EXPECT().RegisterWorks(gomock.Any()).Return(nil)
+	s.NoError(s.replicator.Register(mockBackgroundManager))
+
+	s.replicator.Config.Enabled = false
+	s.NoError(s.replicator.Register(mockBackgroundManager))
+}
+
+// TestReplicate tests that config is shipped on the first replication,
+// unchanged jobs are skipped and only the runtime is shipped when the
+// config version did not change
+func (s *JobReplicatorTestSuite) TestReplicate() {
+	jobID := &peloton.JobID{Value: "job1"}
+	cachedJob := cachemock.NewMockJob(s.mockCtrl)
+	config := &pbjob.JobConfig{Name: "job1"}
+	runtime := &pbjob.RuntimeInfo{
+		State:                pbjob.JobState_RUNNING,
+		ConfigurationVersion: 1,
+		Revision:             &peloton.ChangeLog{Version: 1},
+	}
+
+	s.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{jobID.GetValue(): cachedJob}).
+		Times(3)
+	cachedJob.EXPECT().ID().Return(jobID).AnyTimes()
+	s.expectReconcile()
+
+	// first replication ships config and runtime
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil)
+	s.jobConfigOps.EXPECT().
+		Get(gomock.Any(), jobID, uint64(1)).
+		Return(config, nil, nil)
+	s.mirrorIndexOp.EXPECT().
+		Update(gomock.Any(), jobID, config, runtime).
+		Return(nil)
+	s.replicator.Replicate()
+
+	// unchanged job is skipped
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil)
+	s.replicator.Replicate()
+
+	// runtime change only ships the runtime
+	newRuntime := &pbjob.RuntimeInfo{
+		State:                pbjob.JobState_SUCCEEDED,
+		ConfigurationVersion: 1,
+		Revision:             &peloton.ChangeLog{Version: 2},
+	}
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(newRuntime, nil)
+	s.mirrorIndexOp.EXPECT().
+		Update(gomock.Any(), jobID, nil, newRuntime).
+		Return(nil)
+	s.replicator.Replicate()
+
+	s.Equal(int64(2), s.testScope.Snapshot().
+		Counters()["replication.jobs_replicated+result=success"].Value())
+	s.Equal(int64(1), s.testScope.Snapshot().
+		Counters()["replication.jobs_skipped+"].Value())
+}
+
+// TestReplicateFailure tests that a failed write to the mirror is
+// retried on the next replication
+func (s *JobReplicatorTestSuite) TestReplicateFailure() {
+	jobID := &peloton.JobID{Value: "job1"}
+	cachedJob := cachemock.NewMockJob(s.mockCtrl)
+	config := &pbjob.JobConfig{Name: "job1"}
+	runtime := &pbjob.RuntimeInfo{
+		ConfigurationVersion: 1,
+		Revision:             &peloton.ChangeLog{Version: 1},
+	}
+
+	s.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{jobID.GetValue(): cachedJob}).
+		Times(2)
+	cachedJob.EXPECT().ID().Return(jobID).AnyTimes()
+	cachedJob.EXPECT().GetRuntime(gomock.Any()).Return(runtime, nil).Times(2)
+	s.expectReconcile()
+	s.jobConfigOps.EXPECT().
+		Get(gomock.Any(), jobID, uint64(1)).
+		Return(config, nil, nil).
+		Times(2)
+
+	gomock.InOrder(
+		s.mirrorIndexOp.EXPECT().
+			Update(gomock.Any(), jobID, config, runtime).
+			Return(errors.New("mirror unavailable")),
+		s.mirrorIndexOp.EXPECT().
+			Update(gomock.Any(), jobID, config, runtime).
+			Return(nil),
+	)
+
+	s.replicator.Replicate()
+	s.replicator.Replicate()
+}
+
+// TestReplicateDelete tests that a job which is no longer in cache is
+// deleted from the mirror only if it is deleted from the local storage
+func (s *JobReplicatorTestSuite) TestReplicateDelete() {
+	deletedJobID := &peloton.JobID{Value: "job1"}
+	evictedJobID := &peloton.JobID{Value: "job2"}
+	s.replicator.lastReconciled = time.Now()
+	s.replicator.replicated = map[string]replicatedVersion{
+		deletedJobID.GetValue(): {configVersion: 1, runtimeRevision: 1},
+		evictedJobID.GetValue(): {configVersion: 1, runtimeRevision: 1},
+	}
+
+	s.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{}).
+		Times(2)
+	s.jobIndexOps.EXPECT().
+		Get(gomock.Any(), evictedJobID).
+		Return(nil, nil)
+
+	// a failed delete is retried on the next replication
+	s.jobIndexOps.EXPECT().
+		Get(gomock.Any(), deletedJobID).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found")).
+		Times(2)
+	gomock.InOrder(
+		s.mirrorIndexOp.EXPECT().
+			Delete(gomock.Any(), deletedJobID).
+			Return(errors.New("mirror unavailable")),
+		s.mirrorIndexOp.EXPECT().
+			Delete(gomock.Any(), deletedJobID).
+			Return(nil),
+	)
+
+	s.replicator.Replicate()
+	s.Contains(s.replicator.replicated, deletedJobID.GetValue())
+	s.NotContains(s.replicator.replicated, evictedJobID.GetValue())
+
+	s.replicator.Replicate()
+	s.Empty(s.replicator.replicated)
+	s.Equal(int64(1), s.testScope.Snapshot().
+		Counters()["replication.jobs_deleted+result=success"].Value())
+}
+
+// TestReconcileDeletes tests that the jobs in the mirror which are not
+// in the local storage are deleted from the mirror, and that the
+// reconciliation is retried until it succeeds
+func (s *JobReplicatorTestSuite) TestReconcileDeletes() {
+	localJobID := &peloton.JobID{Value: "job1"}
+	deletedJobID := &peloton.JobID{Value: "job2"}
+
+	s.jobFactory.EXPECT().
+		GetAllJobs().
+		Return(map[string]cached.Job{}).
+		Times(3)
+
+	gomock.InOrder(
+		s.mirrorIndexOp.EXPECT().
+			GetAll(gomock.Any()).
+			Return(nil, errors.New("mirror unavailable")),
+		s.mirrorIndexOp.EXPECT().
+			GetAll(gomock.Any()).
+			Return([]*pbjob.JobSummary{
+				{Id: localJobID},
+				{Id: deletedJobID},
+			}, nil),
+	)
+	s.jobIndexOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*pbjob.JobSummary{{Id: localJobID}}, nil)
+	s.mirrorIndexOp.EXPECT().
+		Delete(gomock.Any(), deletedJobID).
+		Return(nil)
+
+	s.replicator.Replicate()
+	s.True(s.replicator.lastReconciled.IsZero())
+
+	s.replicator.Replicate()
+	s.False(s.replicator.lastReconciled.IsZero())
+
+	// the mirror is not reconciled again until the reconcile period passed
+	s.replicator.Replicate()
+}
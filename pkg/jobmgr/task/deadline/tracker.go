@@ -22,6 +22,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
 
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
@@ -59,6 +60,9 @@ type tracker struct {
 	config          *Config
 	metrics         *Metrics
 	lifeCycle       lifecycle.LifeCycle // lifecycle manager
+	// freezeState provides whether the cluster is frozen, during which
+	// tasks exceeding their deadline are not killed
+	freezeState freeze.State
 }
 
 // New creates a deadline tracker
@@ -70,6 +74,7 @@ func New(
 	goalStateDriver goalstate.Driver,
 	parent tally.Scope,
 	config *Config,
+	freezeState freeze.State,
 ) Tracker {
 	return &tracker{
 		jobStore:        jobStore,
@@ -79,6 +84,7 @@ func New(
 		config:          config,
 		metrics:         NewMetrics(parent.SubScope("jobmgr").SubScope("task")),
 		lifeCycle:       lifecycle.NewLifeCycle(),
+		freezeState:     freezeState,
 	}
 }
 
@@ -124,6 +130,11 @@ func (t *tracker) Stop() error {
 
 // trackDeadline functions keeps track of the deadline of each task
 func (t *tracker) trackDeadline() {
+	if t.freezeState != nil && t.freezeState.IsFrozen() {
+		log.Info("Cluster is frozen, not enforcing task deadlines")
+		return
+	}
+
 	jobs := t.jobFactory.GetAllJobs()
 
 	for id, cachedJob := range jobs {
@@ -279,6 +279,20 @@ func (suite *DeadlineTrackerTestSuite) TestDeadlineTrackStopFailed() {
 	suite.tracker.trackDeadline()
 }
 
+// frozenState is a freeze.State which is always frozen
+type frozenState struct{}
+
+func (frozenState) IsFrozen() bool { return true }
+
+// TestDeadlineTrackingFrozen tests that task deadlines are not
+// enforced while the cluster is frozen
+func (suite *DeadlineTrackerTestSuite) TestDeadlineTrackingFrozen() {
+	suite.tracker.freezeState = frozenState{}
+	defer func() { suite.tracker.freezeState = nil }()
+
+	suite.tracker.trackDeadline()
+}
+
 func TestDeadlineTracker(t *testing.T) {
 	suite.Run(t, new(DeadlineTrackerTestSuite))
 }
@@ -16,9 +16,15 @@ package task
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -28,23 +34,97 @@ import (
 	"go.uber.org/yarpc/yarpcerrors"
 )
 
+const (
+	_defaultEnqueueTimeout      = 10 * time.Second
+	_defaultEnqueueRetryBackoff = 1 * time.Second
+)
+
+// EnqueueConfig controls how tasks are enqueued to resource manager.
+type EnqueueConfig struct {
+	// Timeout is the timeout of a single EnqueueGangs call to
+	// resource manager. Default to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// RetryCount is the number of times an EnqueueGangs call which failed
+	// to reach resource manager is retried. Retries are safe since resource
+	// manager de-duplicates requests with the same idempotency token.
+	// Default to 0, i.e. no retries.
+	RetryCount int `yaml:"retry_count"`
+	// RetryBackoff is the delay between two EnqueueGangs attempts.
+	// Default to 1s.
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+}
+
+// getTimeout returns the timeout of a single EnqueueGangs call.
+func (c *EnqueueConfig) getTimeout() time.Duration {
+	if c == nil || c.Timeout <= 0 {
+		return _defaultEnqueueTimeout
+	}
+	return c.Timeout
+}
+
+// getRetryCount returns the number of retries of a failed EnqueueGangs call.
+func (c *EnqueueConfig) getRetryCount() int {
+	if c == nil || c.RetryCount < 0 {
+		return 0
+	}
+	return c.RetryCount
+}
+
+// getRetryBackoff returns the delay between two EnqueueGangs attempts.
+func (c *EnqueueConfig) getRetryBackoff() time.Duration {
+	if c == nil || c.RetryBackoff <= 0 {
+		return _defaultEnqueueRetryBackoff
+	}
+	return c.RetryBackoff
+}
+
 // EnqueueGangs enqueues all tasks organized in gangs to respool in resmgr.
+// Calls which fail to reach resmgr are retried as per the config, and the
+// enqueue latency is reported to the scope tagged with the respool.
 func EnqueueGangs(
 	ctx context.Context,
 	tasks []*task.TaskInfo,
 	jobConfig jobmgrcommon.JobConfig,
-	client resmgrsvc.ResourceManagerServiceYARPCClient) (*resmgrsvc.EnqueueGangsResponse, error) {
-	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
-	defer cancelFunc()
+	client resmgrsvc.ResourceManagerServiceYARPCClient,
+	config *EnqueueConfig,
+	scope tally.Scope) (*resmgrsvc.EnqueueGangsResponse, error) {
+	if scope == nil {
+		scope = tally.NoopScope
+	}
+	respoolScope := scope.Tagged(map[string]string{
+		"respool": jobConfig.GetRespoolID().GetValue(),
+	})
 
 	gangs := taskutil.ConvertToResMgrGangs(tasks, jobConfig)
 	var request = &resmgrsvc.EnqueueGangsRequest{
-		Gangs:   gangs,
-		ResPool: jobConfig.GetRespoolID(),
+		Gangs:            gangs,
+		ResPool:          jobConfig.GetRespoolID(),
+		IdempotencyToken: GetEnqueueGangsToken(tasks),
+	}
+
+	var response *resmgrsvc.EnqueueGangsResponse
+	var err error
+	callStart := time.Now()
+	for attempt := 0; ; attempt++ {
+		response, err = enqueueGangsOnce(ctx, client, request, config.getTimeout())
+		if err == nil || attempt >= config.getRetryCount() {
+			break
+		}
+
+		log.WithError(err).
+			WithField("respool_id", jobConfig.GetRespoolID().GetValue()).
+			WithField("attempt", attempt+1).
+			Warn("resource manager enqueue gangs failed, retrying")
+		respoolScope.Counter("enqueue_gangs_retry").Inc(1)
+
+		if !waitForRetry(ctx, config.getRetryBackoff()) {
+			break
+		}
 	}
+	respoolScope.Timer("enqueue_gangs_latency").Record(time.Since(callStart))
 
-	response, err := client.EnqueueGangs(ctxWithTimeout, request)
 	if err != nil {
+		respoolScope.Counter("enqueue_gangs_fail").Inc(1)
 		log.WithError(err).WithFields(log.Fields{
 			"request": request,
 		}).Error("resource manager enqueue gangs failed")
@@ -52,3 +132,92 @@ func EnqueueGangs(
 	}
 	return response, err
 }
+
+// enqueueGangsOnce makes a single EnqueueGangs call to resmgr.
+func enqueueGangsOnce(
+	ctx context.Context,
+	client resmgrsvc.ResourceManagerServiceYARPCClient,
+	request *resmgrsvc.EnqueueGangsRequest,
+	timeout time.Duration) (*resmgrsvc.EnqueueGangsResponse, error) {
+	ctxWithTimeout, cancelFunc := context.WithTimeout(ctx, timeout)
+	defer cancelFunc()
+	return client.EnqueueGangs(ctxWithTimeout, request)
+}
+
+// waitForRetry waits for the backoff before the next attempt. It returns
+// false if the context is done before the backoff elapses.
+func waitForRetry(ctx context.Context, backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// SetLaunchCorrelationIDs returns the tasks with a launch correlation ID
+// set in their runtime, which correlates the log lines of all the
+// components along the launch path of the tasks. The ID of a task which
+// already has one is kept, so that a retried enqueue of the same run is
+// correlated with the first one. The tasks passed in are not modified;
+// the ones which needed an ID are copied, and the generated IDs are
+// returned keyed by instance ID so that they can be persisted.
+func SetLaunchCorrelationIDs(
+	tasks []*task.TaskInfo) ([]*task.TaskInfo, map[uint32]string) {
+	result := make([]*task.TaskInfo, 0, len(tasks))
+	generated := make(map[uint32]string)
+	for _, t := range tasks {
+		if len(t.GetRuntime().GetLaunchCorrelationId()) != 0 {
+			result = append(result, t)
+			continue
+		}
+
+		id := uuid.New()
+		t = proto.Clone(t).(*task.TaskInfo)
+		if t.Runtime == nil {
+			t.Runtime = &task.RuntimeInfo{}
+		}
+		t.Runtime.LaunchCorrelationId = id
+		generated[t.GetInstanceId()] = id
+		result = append(result, t)
+	}
+	return result, generated
+}
+
+// GetEnqueueGangsToken returns the idempotency token of an EnqueueGangs
+// request for the given tasks. The token is made of the job ID, the
+// instance range and the config version of the tasks, along with a
+// digest of their mesos task IDs, so that a retry of the same request
+// gets the same token while a relaunch of the same instances does not.
+func GetEnqueueGangsToken(tasks []*task.TaskInfo) string {
+	if len(tasks) == 0 {
+		return ""
+	}
+
+	sorted := make([]*task.TaskInfo, len(tasks))
+	copy(sorted, tasks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetInstanceId() < sorted[j].GetInstanceId()
+	})
+
+	var configVersion uint64
+	digest := sha256.New()
+	for _, t := range sorted {
+		if t.GetRuntime().GetConfigVersion() > configVersion {
+			configVersion = t.GetRuntime().GetConfigVersion()
+		}
+		fmt.Fprintf(digest, "%d:%s;",
+			t.GetInstanceId(),
+			t.GetRuntime().GetMesosTaskId().GetValue())
+	}
+
+	return fmt.Sprintf("%s:%d-%d:%d:%x",
+		sorted[0].GetJobId().GetValue(),
+		sorted[0].GetInstanceId(),
+		sorted[len(sorted)-1].GetInstanceId(),
+		configVersion,
+		digest.Sum(nil)[:8])
+}
@@ -19,10 +19,12 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
@@ -120,7 +122,8 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangs() {
 		mockResmgrClient.EXPECT().EnqueueGangs(
 			gomock.Any(),
 			gomock.Eq(&resmgrsvc.EnqueueGangsRequest{
-				Gangs: gangs,
+				Gangs:            gangs,
+				IdempotencyToken: GetEnqueueGangsToken(tasksInfo),
 			})).
 			Do(func(_ context.Context, reqBody interface{}) {
 				req := reqBody.(*resmgrsvc.EnqueueGangsRequest)
@@ -135,7 +138,9 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangs() {
 		context.Background(),
 		tasksInfo,
 		suite.testJobConfig,
-		mockResmgrClient)
+		mockResmgrClient,
+		nil,
+		tally.NoopScope)
 	suite.Equal(gangs, expectedGangs)
 }
 
@@ -154,7 +159,8 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangsFailure() {
 		mockResmgrClient.EXPECT().EnqueueGangs(
 			gomock.Any(),
 			gomock.Eq(&resmgrsvc.EnqueueGangsRequest{
-				Gangs: gangs,
+				Gangs:            gangs,
+				IdempotencyToken: GetEnqueueGangsToken(tasksInfo),
 			})).
 			Do(func(_ context.Context, reqBody interface{}) {
 				req := reqBody.(*resmgrsvc.EnqueueGangsRequest)
@@ -168,6 +174,125 @@ func (suite *TaskUtilTestSuite) TestEnqueueGangsFailure() {
 		context.Background(),
 		tasksInfo,
 		suite.testJobConfig,
-		mockResmgrClient)
+		mockResmgrClient,
+		nil,
+		tally.NoopScope)
 	suite.Error(err)
 }
+
+// TestEnqueueGangsRetry tests that a failed EnqueueGangs call is retried
+// with the same request as per the enqueue config
+func (suite *TaskUtilTestSuite) TestEnqueueGangsRetry() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+
+	mockResmgrClient := res_mocks.NewMockResourceManagerServiceYARPCClient(ctrl)
+	var tasksInfo []*task.TaskInfo
+	for _, v := range suite.taskInfos {
+		tasksInfo = append(tasksInfo, v)
+	}
+	request := &resmgrsvc.EnqueueGangsRequest{
+		Gangs:            taskutil.ConvertToResMgrGangs(tasksInfo, suite.testJobConfig),
+		IdempotencyToken: GetEnqueueGangsToken(tasksInfo),
+	}
+	gomock.InOrder(
+		mockResmgrClient.EXPECT().EnqueueGangs(gomock.Any(), gomock.Eq(request)).
+			Return(nil, errors.New("Resmgr Error")),
+		mockResmgrClient.EXPECT().EnqueueGangs(gomock.Any(), gomock.Eq(request)).
+			Return(&resmgrsvc.EnqueueGangsResponse{}, nil),
+	)
+
+	scope := tally.NewTestScope("", map[string]string{})
+	_, err := EnqueueGangs(
+		context.Background(),
+		tasksInfo,
+		suite.testJobConfig,
+		mockResmgrClient,
+		&EnqueueConfig{
+			Timeout:      time.Second,
+			RetryCount:   2,
+			RetryBackoff: time.Millisecond,
+		},
+		scope)
+	suite.NoError(err)
+
+	snapshot := scope.Snapshot()
+	suite.Equal(int64(1),
+		snapshot.Counters()["enqueue_gangs_retry+respool="].Value())
+	suite.NotNil(snapshot.Timers()["enqueue_gangs_latency+respool="])
+}
+
+// TestEnqueueGangsRetryExhausted tests that EnqueueGangs returns an error
+// once all the retries have failed
+func (suite *TaskUtilTestSuite) TestEnqueueGangsRetryExhausted() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+
+	mockResmgrClient := res_mocks.NewMockResourceManagerServiceYARPCClient(ctrl)
+	var tasksInfo []*task.TaskInfo
+	for _, v := range suite.taskInfos {
+		tasksInfo = append(tasksInfo, v)
+	}
+	mockResmgrClient.EXPECT().EnqueueGangs(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("Resmgr Error")).
+		Times(3)
+
+	_, err := EnqueueGangs(
+		context.Background(),
+		tasksInfo,
+		suite.testJobConfig,
+		mockResmgrClient,
+		&EnqueueConfig{
+			RetryCount:   2,
+			RetryBackoff: time.Millisecond,
+		},
+		tally.NoopScope)
+	suite.Error(err)
+}
+
+func (suite *TaskUtilTestSuite) TestGetEnqueueGangsToken() {
+	var tasksInfo []*task.TaskInfo
+	for i := uint32(0); i < testInstanceCount; i++ {
+		tasksInfo = append(tasksInfo, suite.taskInfos[i])
+	}
+	token := GetEnqueueGangsToken(tasksInfo)
+	suite.Contains(token, "test_job:0-1:0:")
+
+	// the token does not depend on the order of the tasks
+	reversed := []*task.TaskInfo{tasksInfo[1], tasksInfo[0]}
+	suite.Equal(token, GetEnqueueGangsToken(reversed))
+
+	// a new run of the same instances gets a different token
+	newRunID := "test_job-1-2"
+	relaunched := []*task.TaskInfo{
+		tasksInfo[0],
+		{
+			Runtime: &task.RuntimeInfo{
+				MesosTaskId: &mesos.TaskID{Value: &newRunID},
+			},
+			InstanceId: 1,
+			JobId:      suite.testJobID,
+		},
+	}
+	suite.NotEqual(token, GetEnqueueGangsToken(relaunched))
+
+	suite.Empty(GetEnqueueGangsToken(nil))
+}
+
+func (suite *TaskUtilTestSuite) TestSetLaunchCorrelationIDs() {
+	suite.taskInfos[1].Runtime.LaunchCorrelationId = "existing-id"
+	tasksInfo := []*task.TaskInfo{suite.taskInfos[0], suite.taskInfos[1]}
+
+	tasks, generated := SetLaunchCorrelationIDs(tasksInfo)
+	suite.Len(tasks, 2)
+	suite.Len(generated, 1)
+
+	// the task without an ID gets a new one, leaving the input untouched
+	suite.NotEmpty(tasks[0].GetRuntime().GetLaunchCorrelationId())
+	suite.Equal(generated[0], tasks[0].GetRuntime().GetLaunchCorrelationId())
+	suite.Empty(suite.taskInfos[0].GetRuntime().GetLaunchCorrelationId())
+
+	// the task with an ID keeps it
+	suite.Equal(suite.taskInfos[1], tasks[1])
+	suite.Equal("existing-id", tasks[1].GetRuntime().GetLaunchCorrelationId())
+}
@@ -62,6 +62,9 @@ type asyncEventProcessor struct {
 	eventBuckets []*eventBucket
 	// waitgroup for bucket goroutines
 	bucketsWg sync.WaitGroup
+	// sampler rate limits the events of each job, nil if the
+	// events are not rate limited
+	sampler *eventSampler
 }
 
 // eventBucket is a bucket of task updates. All updates for one task would end
@@ -141,7 +144,7 @@ func dequeueEventsFromBucket(
 			t.ProcessListeners(event)
 
 			atomic.AddInt32(bucket.processedCount, 1)
-			atomic.StoreUint64(bucket.processedOffset, event.Offset())
+			storeMaxOffset(bucket.processedOffset, event.Offset())
 		case <-stopCh:
 			log.WithField("bucket_num", bucket.index).Info(
 				"Received bucket shutdown")
@@ -150,6 +153,19 @@ func dequeueEventsFromBucket(
 	}
 }
 
+// storeMaxOffset stores the event offset if it is larger than the current
+// one. Events coalesced by the sampler are processed after newer events
+// of other tasks, and must not move the processed offset backwards.
+func storeMaxOffset(processedOffset *uint64, offset uint64) {
+	for {
+		current := atomic.LoadUint64(processedOffset)
+		if offset <= current ||
+			atomic.CompareAndSwapUint64(processedOffset, current, offset) {
+			return
+		}
+	}
+}
+
 // Creates the event processor
 func newBucketEventProcessor(t StatusProcessor, bucketNum int,
 	chanSize int) *asyncEventProcessor {
@@ -199,7 +215,7 @@ func (t *asyncEventProcessor) addV0Event(event *pbeventstream.Event) error {
 	}
 	index := instanceID % uint32(len(t.eventBuckets))
 
-	t.eventBuckets[index].eventCh <- updateEvent
+	t.enqueue(updateEvent, index)
 	return nil
 }
 
@@ -215,10 +231,41 @@ func (t *asyncEventProcessor) addV1Event(event *v1pbevent.Event) error {
 	io.WriteString(h, podID)
 	index := h.Sum32() % uint32(len(t.eventBuckets))
 
-	t.eventBuckets[index].eventCh <- updateEvent
+	t.enqueue(updateEvent, index)
 	return nil
 }
 
+// enqueue adds an event to a bucket, unless the event is held back by
+// the sampler because its job is over the event rate limit.
+func (t *asyncEventProcessor) enqueue(
+	event *statusupdate.Event,
+	index uint32,
+) {
+	if t.sampler != nil && !t.sampler.admit(event, index) {
+		return
+	}
+	t.eventBuckets[index].eventCh <- event
+}
+
+// Loop to periodically enqueue the events held back by the sampler.
+// Terminates when stopCh is notified/closed. Events still held back
+// on shutdown are dropped, and are reconciled with the next leader.
+func (t *asyncEventProcessor) flushSampledEvents(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(t.sampler.cfg.FlushPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.sampler.flush(func(e *sampledEvent) {
+				t.eventBuckets[e.bucket].eventCh <- e.event
+			})
+		case <-stopCh:
+			return
+		}
+	}
+}
+
 // Starts the event processor. Launches goroutines to process events for
 // each bucket.
 func (t *asyncEventProcessor) start() {
@@ -233,6 +280,13 @@ func (t *asyncEventProcessor) start() {
 			t.bucketsWg.Done()
 		}(bucket)
 	}
+	if t.sampler != nil {
+		t.bucketsWg.Add(1)
+		go func() {
+			t.flushSampledEvents(stopCh)
+			t.bucketsWg.Done()
+		}()
+	}
 }
 
 // Completes processing events and terminates the processing loop
@@ -0,0 +1,57 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"time"
+)
+
+const (
+	// _defaultSamplingFlushPeriod is the default period at which
+	// coalesced events of rate limited jobs are processed.
+	_defaultSamplingFlushPeriod = 1 * time.Second
+)
+
+// Config is the config for processing task status update events.
+type Config struct {
+	// PerJobRate is the maximum rate, in events per second, at which
+	// non-terminal events of a single job are processed. Events of a job
+	// over the rate are coalesced, keeping only the latest event of each
+	// task. Terminal events are never rate limited. A rate <= 0 disables
+	// the limit.
+	PerJobRate float64 `yaml:"per_job_rate"`
+
+	// PerJobBurst is the maximum number of non-terminal events of a job
+	// processed at once. Defaults to the per job rate rounded up.
+	PerJobBurst int `yaml:"per_job_burst"`
+
+	// FlushPeriod is the period at which the coalesced events of rate
+	// limited jobs are processed.
+	FlushPeriod time.Duration `yaml:"flush_period"`
+}
+
+// normalize sets the defaults of the config.
+func (c Config) normalize() Config {
+	if c.PerJobBurst <= 0 {
+		c.PerJobBurst = int(c.PerJobRate)
+		if float64(c.PerJobBurst) < c.PerJobRate {
+			c.PerJobBurst++
+		}
+	}
+	if c.FlushPeriod <= 0 {
+		c.FlushPeriod = _defaultSamplingFlushPeriod
+	}
+	return c
+}
@@ -37,11 +37,21 @@ type Metrics struct {
 
 	TasksReconciledTotal tally.Counter
 
+	// metrics for revocable tasks preempted by the agent to reclaim
+	// their revocable resources
+	RevocableTasksPreemptedTotal tally.Counter
+
 	// metrics for in-place update/restart success rate
 	TasksInPlacePlacementTotal   tally.Counter
 	TasksInPlacePlacementSuccess tally.Counter
 
 	TasksFailedReason map[int32]tally.Counter
+
+	// metrics for the per job event sampling
+	EventsCoalesced  tally.Counter
+	EventsSuperseded tally.Counter
+	EventsPending    tally.Gauge
+	RateLimitedJobs  tally.Gauge
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -65,7 +75,15 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		TasksInPlacePlacementSuccess: scope.Counter("tasks_in_place_placement_success"),
 
 		TasksReconciledTotal: scope.Counter("tasks_reconciled_total"),
-		TasksFailedReason:    newTasksFailedReasonScope(scope),
+
+		RevocableTasksPreemptedTotal: scope.Counter("revocable_tasks_preempted_total"),
+
+		TasksFailedReason: newTasksFailedReasonScope(scope),
+
+		EventsCoalesced:  scope.Counter("events_coalesced_total"),
+		EventsSuperseded: scope.Counter("events_superseded_total"),
+		EventsPending:    scope.Gauge("events_coalesced_pending"),
+		RateLimitedJobs:  scope.Gauge("rate_limited_jobs"),
 	}
 }
 
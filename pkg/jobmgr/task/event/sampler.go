@@ -0,0 +1,162 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/common/statusupdate"
+	"github.com/uber/peloton/pkg/common/util"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// _jobSamplerIdleTimeout is the time after which the state of a job
+	// which has no coalesced events and has not received any event is
+	// dropped.
+	_jobSamplerIdleTimeout = 10 * time.Minute
+)
+
+// eventSampler rate limits the non-terminal events of each job. The
+// events of a job over its rate are coalesced, keeping only the latest
+// event of each task, so that a single job generating an event storm
+// (e.g. crash looping across thousands of instances) does not delay the
+// events of other jobs. Terminal events are never held back.
+type eventSampler struct {
+	sync.Mutex
+
+	cfg     Config
+	jobs    map[string]*jobEvents
+	metrics *Metrics
+
+	// countEvent records the task state counters of an event which is
+	// superseded by a newer event of its task and thus never processed.
+	countEvent func(event *statusupdate.Event)
+}
+
+// jobEvents is the sampling state of a single job.
+type jobEvents struct {
+	limiter *rate.Limiter
+	// pending is the latest coalesced event of each task of the job
+	pending map[string]*sampledEvent
+	// lastEventTime is the time the last event of the job was received
+	lastEventTime time.Time
+}
+
+// sampledEvent is an event held back by the sampler, along with the
+// bucket it is to be processed in.
+type sampledEvent struct {
+	event  *statusupdate.Event
+	bucket uint32
+}
+
+// newEventSampler creates an event sampler, or returns nil if the
+// events of a job are not rate limited.
+func newEventSampler(
+	cfg Config,
+	metrics *Metrics,
+	countEvent func(event *statusupdate.Event),
+) *eventSampler {
+	if cfg.PerJobRate <= 0 {
+		return nil
+	}
+	return &eventSampler{
+		cfg:        cfg.normalize(),
+		jobs:       make(map[string]*jobEvents),
+		metrics:    metrics,
+		countEvent: countEvent,
+	}
+}
+
+// admit returns whether an event is to be processed right away. If not,
+// the event is held back as the latest event of its task, to be
+// returned by flush once its job is within the rate limit again.
+func (s *eventSampler) admit(event *statusupdate.Event, bucket uint32) bool {
+	jobID, _, err := util.ParseTaskID(event.TaskID())
+	if err != nil {
+		// let the event fail in the processing
+		return true
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	j, ok := s.jobs[jobID]
+	if !ok {
+		j = &jobEvents{
+			limiter: rate.NewLimiter(
+				rate.Limit(s.cfg.PerJobRate), s.cfg.PerJobBurst),
+			pending: make(map[string]*sampledEvent),
+		}
+		s.jobs[jobID] = j
+	}
+	j.lastEventTime = now()
+
+	// any event held back for the task is older than this one
+	s.supersede(j, event.TaskID())
+
+	if util.IsPelotonStateTerminal(event.State()) || j.limiter.Allow() {
+		return true
+	}
+
+	j.pending[event.TaskID()] = &sampledEvent{event: event, bucket: bucket}
+	s.metrics.EventsCoalesced.Inc(1)
+	return false
+}
+
+// supersede drops the event held back for a task, if any.
+func (s *eventSampler) supersede(j *jobEvents, taskID string) {
+	prev, ok := j.pending[taskID]
+	if !ok {
+		return
+	}
+	delete(j.pending, taskID)
+	s.metrics.EventsSuperseded.Inc(1)
+	if s.countEvent != nil {
+		s.countEvent(prev.event)
+	}
+}
+
+// flush calls enqueue with the events held back for jobs which are
+// within their rate limit again, and drops the state of idle jobs. The
+// events are enqueued under the sampler lock, so that they are not
+// reordered with newer events of their tasks.
+func (s *eventSampler) flush(enqueue func(e *sampledEvent)) {
+	s.Lock()
+	defer s.Unlock()
+
+	var pending, limitedJobs int
+	for jobID, j := range s.jobs {
+		for taskID, e := range j.pending {
+			if !j.limiter.Allow() {
+				break
+			}
+			delete(j.pending, taskID)
+			enqueue(e)
+		}
+
+		if len(j.pending) > 0 {
+			pending += len(j.pending)
+			limitedJobs++
+		} else if now().Sub(j.lastEventTime) > _jobSamplerIdleTimeout {
+			delete(s.jobs, jobID)
+		}
+	}
+
+	s.metrics.EventsPending.Update(float64(pending))
+	s.metrics.RateLimitedJobs.Update(float64(limitedJobs))
+}
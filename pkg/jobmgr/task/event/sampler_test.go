@@ -0,0 +1,188 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"fmt"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	pbeventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
+
+	"github.com/uber/peloton/pkg/common/statusupdate"
+
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"golang.org/x/time/rate"
+)
+
+type EventSamplerTestSuite struct {
+	suite.Suite
+
+	jobID   string
+	counted []*statusupdate.Event
+	sampler *eventSampler
+}
+
+func TestEventSampler(t *testing.T) {
+	suite.Run(t, new(EventSamplerTestSuite))
+}
+
+func (suite *EventSamplerTestSuite) SetupTest() {
+	suite.jobID = uuid.New()
+	suite.counted = nil
+	// a single token which is never refilled during the test
+	suite.sampler = newEventSampler(
+		Config{PerJobRate: 0.001, PerJobBurst: 1},
+		NewMetrics(tally.NoopScope),
+		func(event *statusupdate.Event) {
+			suite.counted = append(suite.counted, event)
+		},
+	)
+}
+
+// createEvent creates a status update event of an instance of the job.
+func (suite *EventSamplerTestSuite) createEvent(
+	instanceID uint32,
+	state mesos.TaskState,
+	offset uint64,
+) *statusupdate.Event {
+	mesosTaskID := fmt.Sprintf("%s-%d-1", suite.jobID, instanceID)
+	event, err := statusupdate.NewV0(&pbeventstream.Event{
+		Offset: offset,
+		Type:   pbeventstream.Event_MESOS_TASK_STATUS,
+		MesosTaskStatus: &mesos.TaskStatus{
+			TaskId: &mesos.TaskID{Value: &mesosTaskID},
+			State:  &state,
+		},
+	})
+	suite.NoError(err)
+	return event
+}
+
+// TestNewEventSamplerDisabled tests that no sampler is created
+// when the per job rate is not set.
+func (suite *EventSamplerTestSuite) TestNewEventSamplerDisabled() {
+	suite.Nil(newEventSampler(Config{}, NewMetrics(tally.NoopScope), nil))
+}
+
+// TestAdmitCoalescesEvents tests that the events of a job over the rate
+// limit are coalesced to the latest event of each task.
+func (suite *EventSamplerTestSuite) TestAdmitCoalescesEvents() {
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_STARTING, 1), 0))
+
+	suite.False(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 2), 0))
+	suite.False(suite.sampler.admit(
+		suite.createEvent(1, mesos.TaskState_TASK_STARTING, 3), 1))
+	suite.Len(suite.sampler.jobs[suite.jobID].pending, 2)
+	suite.Empty(suite.counted)
+
+	// a newer event of the task replaces the held back one
+	latest := suite.createEvent(0, mesos.TaskState_TASK_STARTING, 4)
+	suite.False(suite.sampler.admit(latest, 0))
+	suite.Len(suite.sampler.jobs[suite.jobID].pending, 2)
+	suite.Len(suite.counted, 1)
+	suite.Equal(uint64(2), suite.counted[0].Offset())
+
+	pendingTaskID := fmt.Sprintf("%s-0", suite.jobID)
+	suite.Equal(latest,
+		suite.sampler.jobs[suite.jobID].pending[pendingTaskID].event)
+}
+
+// TestAdmitTerminalEvent tests that terminal events are not rate limited,
+// and supersede the events held back for their tasks.
+func (suite *EventSamplerTestSuite) TestAdmitTerminalEvent() {
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_STARTING, 1), 0))
+	suite.False(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 2), 0))
+
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_FAILED, 3), 0))
+	suite.True(suite.sampler.admit(
+		suite.createEvent(1, mesos.TaskState_TASK_KILLED, 4), 1))
+	suite.Empty(suite.sampler.jobs[suite.jobID].pending)
+	suite.Len(suite.counted, 1)
+}
+
+// TestAdmitIsPerJob tests that a job over the rate limit does not
+// hold back the events of other jobs.
+func (suite *EventSamplerTestSuite) TestAdmitIsPerJob() {
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 1), 0))
+	suite.False(suite.sampler.admit(
+		suite.createEvent(1, mesos.TaskState_TASK_RUNNING, 2), 1))
+
+	suite.jobID = uuid.New()
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 3), 0))
+}
+
+// TestFlush tests that held back events are enqueued once their
+// job is within the rate limit again.
+func (suite *EventSamplerTestSuite) TestFlush() {
+	var enqueued []*sampledEvent
+	enqueue := func(e *sampledEvent) {
+		enqueued = append(enqueued, e)
+	}
+
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 1), 0))
+	suite.False(suite.sampler.admit(
+		suite.createEvent(1, mesos.TaskState_TASK_RUNNING, 2), 1))
+	suite.False(suite.sampler.admit(
+		suite.createEvent(2, mesos.TaskState_TASK_RUNNING, 3), 2))
+
+	// the job is still over the limit
+	suite.sampler.flush(enqueue)
+	suite.Empty(enqueued)
+
+	suite.sampler.jobs[suite.jobID].limiter = rate.NewLimiter(rate.Inf, 0)
+	suite.sampler.flush(enqueue)
+	suite.Len(enqueued, 2)
+	suite.Empty(suite.sampler.jobs[suite.jobID].pending)
+	suite.Empty(suite.counted)
+	for _, e := range enqueued {
+		suite.Equal(e.event.Offset()-1, uint64(e.bucket))
+	}
+}
+
+// TestFlushDropsIdleJobs tests that the state of jobs without
+// recent events is dropped.
+func (suite *EventSamplerTestSuite) TestFlushDropsIdleJobs() {
+	suite.True(suite.sampler.admit(
+		suite.createEvent(0, mesos.TaskState_TASK_RUNNING, 1), 0))
+
+	suite.sampler.flush(func(*sampledEvent) {})
+	suite.Contains(suite.sampler.jobs, suite.jobID)
+
+	suite.sampler.jobs[suite.jobID].lastEventTime =
+		now().Add(-2 * _jobSamplerIdleTimeout)
+	suite.sampler.flush(func(*sampledEvent) {})
+	suite.NotContains(suite.sampler.jobs, suite.jobID)
+}
+
+// TestStoreMaxOffset tests that the processed offset of
+// a bucket never moves backwards.
+func (suite *EventSamplerTestSuite) TestStoreMaxOffset() {
+	var offset uint64
+	storeMaxOffset(&offset, 10)
+	suite.Equal(uint64(10), offset)
+	storeMaxOffset(&offset, 5)
+	suite.Equal(uint64(10), offset)
+}
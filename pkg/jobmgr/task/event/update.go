@@ -103,6 +103,7 @@ func NewTaskStatusUpdate(
 	listeners []Listener,
 	parentScope tally.Scope,
 	hmVersion api.Version,
+	cfg Config,
 ) StatusUpdate {
 
 	statusUpdater := &statusUpdate{
@@ -119,6 +120,8 @@ func NewTaskStatusUpdate(
 	}
 	// TODO: add config for BucketEventProcessor
 	statusUpdater.applier = newBucketEventProcessor(statusUpdater, 100, 10000)
+	statusUpdater.applier.sampler = newEventSampler(
+		cfg, statusUpdater.metrics, statusUpdater.logTaskMetrics)
 
 	if hmVersion.IsV1() {
 		v1eventClient := v1eventstream.NewEventStreamClient(
@@ -228,11 +231,24 @@ func (p *statusUpdate) ProcessStatusUpdate(
 		p.persistHealthyField(updateEvent.State(), reason, healthy, newRuntime)
 	}
 
-	// Update FailureCount
-	updateFailureCount(updateEvent.State(), taskInfo.GetRuntime(), newRuntime)
+	// Update FailureCount, unless the agent preempted the task to reclaim
+	// its revocable resources.
+	preempted := isRevocablePreemption(taskInfo, updateEvent)
+	if !preempted {
+		updateFailureCount(updateEvent.State(), taskInfo.GetRuntime(), newRuntime)
+	}
+	updateConsecutiveFailureCount(
+		taskInfo.GetRuntime(),
+		newRuntime,
+		taskInfo.GetConfig().GetRestartPolicy())
 
 	switch updateEvent.State() {
 	case pb_task.TaskState_FAILED:
+		if preempted {
+			p.setRevocablePreemption(updateEvent, newRuntime)
+			break
+		}
+
 		reason := updateEvent.Reason()
 		msg := updateEvent.Message()
 		if reason == mesos.TaskStatus_REASON_TASK_INVALID.String() &&
@@ -300,6 +316,11 @@ func (p *statusUpdate) ProcessStatusUpdate(
 			break
 		}
 
+		if preempted {
+			p.setRevocablePreemption(updateEvent, newRuntime)
+			break
+		}
+
 		log.WithFields(log.Fields{
 			"task_id":           updateEvent.TaskID(),
 			"db_task_runtime":   taskInfo.GetRuntime(),
@@ -631,6 +652,63 @@ func updateFailureCount(
 	}
 }
 
+// isRevocablePreemption returns true if the status update reports that the
+// agent preempted a task launched on revocable resources to reclaim them.
+func isRevocablePreemption(
+	taskInfo *pb_task.TaskInfo,
+	updateEvent *statusupdate.Event) bool {
+	return taskInfo.GetConfig().GetRevocable() &&
+		updateEvent.Reason() ==
+			mesos.TaskStatus_REASON_CONTAINER_PREEMPTED.String()
+}
+
+// setRevocablePreemption updates the runtime of a revocable task preempted
+// by the agent, so that the task is rescheduled on another host as its
+// resources were preempted rather than the task failed.
+func (p *statusUpdate) setRevocablePreemption(
+	updateEvent *statusupdate.Event,
+	newRuntime *pb_task.RuntimeInfo) {
+	log.WithField("task_id", updateEvent.TaskID()).
+		Info("revocable task preempted by agent")
+	p.metrics.RevocableTasksPreemptedTotal.Inc(1)
+
+	newRuntime.DesiredHost = ""
+	newRuntime.State = updateEvent.State()
+	newRuntime.Reason = updateEvent.Reason()
+	newRuntime.Message = "Task preempted: " + updateEvent.StatusMsg()
+	newRuntime.TerminationStatus = &pb_task.TerminationStatus{
+		Reason: pb_task.TerminationStatus_TERMINATION_STATUS_REASON_PREEMPTED_RESOURCES,
+	}
+}
+
+// updateConsecutiveFailureCount counts the failures of a task in a row,
+// which are used to back off its restarts. The count is reset if the task
+// ran for longer than the stable running time of its restart policy
+// before failing.
+func updateConsecutiveFailureCount(
+	runtime *pb_task.RuntimeInfo,
+	newRuntime *pb_task.RuntimeInfo,
+	restartPolicy *pb_task.RestartPolicy) {
+	if newRuntime.GetFailureCount() <= runtime.GetFailureCount() {
+		// the task has not failed
+		return
+	}
+
+	// the backoff of the previous failure does not apply anymore
+	newRuntime.RestartBackoffUntil = ""
+
+	if restartPolicy.GetStableRunningSec() > 0 {
+		startTime, err := time.Parse(time.RFC3339Nano, runtime.GetStartTime())
+		stableRunning := time.Duration(restartPolicy.GetStableRunningSec()) *
+			time.Second
+		if err == nil && now().Sub(startTime) >= stableRunning {
+			newRuntime.ConsecutiveFailureCount = 1
+			return
+		}
+	}
+	newRuntime.ConsecutiveFailureCount = runtime.GetConsecutiveFailureCount() + 1
+}
+
 // isDuplicateStateUpdate validates if the current instance state is left unchanged
 // by this status update.
 // If it is left unchanged, then the status update should be ignored.
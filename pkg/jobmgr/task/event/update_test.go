@@ -148,6 +148,7 @@ func (suite *TaskUpdaterTestSuite) TestNewTaskStatusUpdate() {
 		[]Listener{},
 		tally.NoopScope,
 		api.V0,
+		Config{},
 	)
 	suite.NotNil(statusUpdater)
 
@@ -161,6 +162,7 @@ func (suite *TaskUpdaterTestSuite) TestNewTaskStatusUpdate() {
 		[]Listener{},
 		tally.NoopScope,
 		api.V1Alpha,
+		Config{PerJobRate: 100},
 	)
 	suite.NotNil(statusUpdater)
 }
@@ -757,6 +759,60 @@ func (suite *TaskUpdaterTestSuite) doTestProcessTaskFailedStatusUpdate(
 	time.Sleep(_waitTime)
 }
 
+// Test processing FAILED status update of a revocable task preempted by
+// the agent, which should not count as a task failure.
+func (suite *TaskUpdaterTestSuite) TestProcessRevocableTaskPreemptedStatusUpdate() {
+	defer suite.ctrl.Finish()
+
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+	preemptedReason := mesos.TaskStatus_REASON_CONTAINER_PREEMPTED
+	event := createTestTaskUpdateEvent(mesos.TaskState_TASK_FAILED)
+	event.MesosTaskStatus.Reason = &preemptedReason
+	updateEvent, err := statusupdate.NewV0(event)
+	suite.NoError(err)
+	taskInfo := createTestTaskInfo(task.TaskState_RUNNING)
+	taskInfo.Config.Revocable = true
+	taskInfo.Runtime.DesiredHost = "hostname1"
+
+	suite.mockTaskStore.EXPECT().
+		GetTaskByID(context.Background(), _pelotonTaskID).
+		Return(taskInfo, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(_pelotonJobID).Return(cachedJob)
+	cachedJob.EXPECT().GetJobType().Return(job.JobType_BATCH)
+	cachedJob.EXPECT().
+		SetTaskUpdateTime(gomock.Any()).Return()
+	cachedJob.EXPECT().
+		CompareAndSetTask(
+			context.Background(),
+			_instanceID,
+			gomock.Any(),
+			false,
+		).Do(func(_ context.Context, _ uint32, runtime *task.RuntimeInfo, _ bool) {
+		suite.Equal(task.TaskState_FAILED, runtime.GetState())
+		suite.Equal(preemptedReason.String(), runtime.GetReason())
+		suite.Equal("Task preempted: "+_failureMsg, runtime.GetMessage())
+		suite.Equal(
+			task.TerminationStatus_TERMINATION_STATUS_REASON_PREEMPTED_RESOURCES,
+			runtime.GetTerminationStatus().GetReason())
+		suite.Equal(uint32(0), runtime.GetFailureCount())
+		suite.Empty(runtime.GetDesiredHost())
+	}).Return(nil, nil)
+	suite.goalStateDriver.EXPECT().EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).Return()
+	cachedJob.EXPECT().UpdateResourceUsage(gomock.Any()).Return()
+	cachedJob.EXPECT().GetJobType().Return(job.JobType_BATCH)
+	suite.goalStateDriver.EXPECT().
+		JobRuntimeDuration(job.JobType_BATCH).
+		Return(1 * time.Second)
+	suite.goalStateDriver.EXPECT().EnqueueJob(_pelotonJobID, gomock.Any()).Return()
+
+	suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), updateEvent))
+	suite.Equal(
+		int64(1),
+		suite.testScope.Snapshot().Counters()["status_updater.revocable_tasks_preempted_total+"].Value())
+	time.Sleep(_waitTime)
+}
+
 // Test processing task LOST status update w/ retry.
 func (suite *TaskUpdaterTestSuite) TestProcessTaskLostStatusUpdateWithRetry() {
 	defer suite.ctrl.Finish()
@@ -918,6 +974,67 @@ func (suite *TaskUpdaterTestSuite) TestProcessTaskFailureCountUpdate() {
 	}
 }
 
+// TestUpdateConsecutiveFailureCount tests counting the failures of a task
+// in a row, which is reset once the task has been running stably.
+func (suite *TaskUpdaterTestSuite) TestUpdateConsecutiveFailureCount() {
+	now = nowMock
+	currentTime := nowMock()
+	restartPolicy := &task.RestartPolicy{StableRunningSec: 60}
+
+	tt := []struct {
+		startTime               time.Time
+		failureCount            uint32
+		newFailureCount         uint32
+		consecutiveFailureCount uint32
+		expectedCount           uint32
+	}{
+		{
+			// the task has not failed
+			startTime:               currentTime,
+			failureCount:            3,
+			newFailureCount:         3,
+			consecutiveFailureCount: 2,
+			expectedCount:           2,
+		},
+		{
+			// the task failed shortly after starting
+			startTime:               currentTime.Add(-10 * time.Second),
+			failureCount:            3,
+			newFailureCount:         4,
+			consecutiveFailureCount: 2,
+			expectedCount:           3,
+		},
+		{
+			// the task failed after running stably
+			startTime:               currentTime.Add(-2 * time.Minute),
+			failureCount:            3,
+			newFailureCount:         4,
+			consecutiveFailureCount: 2,
+			expectedCount:           1,
+		},
+	}
+
+	for _, t := range tt {
+		runtime := &task.RuntimeInfo{
+			StartTime:               t.startTime.UTC().Format(time.RFC3339Nano),
+			FailureCount:            t.failureCount,
+			ConsecutiveFailureCount: t.consecutiveFailureCount,
+			RestartBackoffUntil:     currentTime.Format(time.RFC3339Nano),
+		}
+		newRuntime := &task.RuntimeInfo{
+			FailureCount:            t.newFailureCount,
+			ConsecutiveFailureCount: t.consecutiveFailureCount,
+			RestartBackoffUntil:     runtime.GetRestartBackoffUntil(),
+		}
+
+		updateConsecutiveFailureCount(runtime, newRuntime, restartPolicy)
+		suite.Equal(t.expectedCount, newRuntime.GetConsecutiveFailureCount())
+		if t.newFailureCount > t.failureCount {
+			suite.Empty(newRuntime.GetRestartBackoffUntil())
+		}
+	}
+}
+
 // Test processing task LOST status update w/o retry for stateful task.
 func (suite *TaskUpdaterTestSuite) TestProcessTaskLostStatusUpdateNoRetryForStatefulTask() {
 	defer suite.ctrl.Finish()
@@ -32,6 +32,7 @@ import (
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr"
+	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	multierror "github.com/hashicorp/go-multierror"
@@ -487,15 +488,10 @@ func getRuntimeDiffForPreempt(
 	}
 
 	// otherwise restart the task
-	prevRunID, err := util.ParseRunID(taskRuntime.GetMesosTaskId().GetValue())
-	if err != nil {
-		prevRunID = 0
-	}
-
-	runtimeDiff[jobmgrcommon.DesiredMesosTaskIDField] = util.CreateMesosTaskID(
+	runtimeDiff[jobmgrcommon.DesiredMesosTaskIDField] = taskutil.NextMesosTaskID(
 		jobID,
 		instanceID,
-		prevRunID+1,
+		taskRuntime.GetMesosTaskId(),
 	)
 
 	return runtimeDiff
@@ -92,6 +92,29 @@ type Manager interface {
 		leaseID string,
 	) error
 
+	// SupportsPodResize returns true if running pods can be resized in
+	// place with UpdatePodResources.
+	SupportsPodResize() bool
+
+	// UpdatePodResources updates the resources of a running pod in place
+	// using the given spec, without restarting it. An Unimplemented error is
+	// returned when the cluster manager cannot resize running pods.
+	UpdatePodResources(
+		ctx context.Context,
+		podID string,
+		hostname string,
+		spec *pbpod.PodSpec,
+	) error
+
+	// ReconcileTask explicitly reconciles the state of the task with the
+	// cluster manager, which sends a status update for it. This will be a
+	// no-op for v1 LifecycleMgr.
+	ReconcileTask(
+		ctx context.Context,
+		id string,
+		agentID string,
+	) error
+
 	// GetTasksOnDrainingHosts gets the taskIDs of the tasks on the
 	// hosts in DRAINING state
 	GetTasksOnDrainingHosts(
@@ -36,6 +36,12 @@ type Metrics struct {
 	TerminateLease     tally.Counter
 	TerminateLeaseFail tally.Counter
 
+	UpdatePodResources     tally.Counter
+	UpdatePodResourcesFail tally.Counter
+
+	Reconcile     tally.Counter
+	ReconcileFail tally.Counter
+
 	GetTasksOnDrainingHosts     tally.Counter
 	GetTasksOnDrainingHostsFail tally.Counter
 }
@@ -65,6 +71,12 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		TerminateLease:     successScope.Counter("terminate_lease"),
 		TerminateLeaseFail: failScope.Counter("terminate_lease"),
 
+		UpdatePodResources:     successScope.Counter("update_pod_resources"),
+		UpdatePodResourcesFail: failScope.Counter("update_pod_resources"),
+
+		Reconcile:     successScope.Counter("reconcile"),
+		ReconcileFail: failScope.Counter("reconcile"),
+
 		GetTasksOnDrainingHosts:     successScope.Counter("tasks_on_draining_hosts"),
 		GetTasksOnDrainingHostsFail: failScope.Counter("tasks_on_draining_hosts"),
 	}
@@ -23,6 +23,7 @@ import (
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	pbhost "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	v0_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 	aurora "github.com/uber/peloton/.gen/thrift/aurora/api"
@@ -30,6 +31,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
 	"github.com/uber/peloton/pkg/common/util"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -237,7 +239,12 @@ func (l *v0LifecycleMgr) launchBatchTasks(
 		if err != nil {
 			return err
 		}
-		mutateSystemLabels(&launchableTask, launchableTaskInfo.ConfigAddOn)
+		mutateSystemLabels(
+			&launchableTask,
+			launchableTaskInfo.ConfigAddOn,
+			launchableTaskInfo.GetInstanceId(),
+			launchableTaskInfo.GetRuntime().GetLaunchCorrelationId(),
+		)
 		launchableTasks = append(launchableTasks, &launchableTask)
 	}
 
@@ -266,6 +273,16 @@ func (l *v0LifecycleMgr) launchBatchTasks(
 		}
 		return yarpcerrors.InternalErrorf(response.Error.String())
 	}
+
+	for _, launchableTaskInfo := range tasks {
+		log.WithFields(log.Fields{
+			"job_id":                launchableTaskInfo.GetJobId().GetValue(),
+			"instance_id":           launchableTaskInfo.GetInstanceId(),
+			"mesos_task_id":         launchableTaskInfo.GetRuntime().GetMesosTaskId().GetValue(),
+			"launch_correlation_id": launchableTaskInfo.GetRuntime().GetLaunchCorrelationId(),
+			"hostname":              hostname,
+		}).Info("Launched task")
+	}
 	return nil
 }
 
@@ -376,6 +393,55 @@ func (l *v0LifecycleMgr) TerminateLease(
 	return nil
 }
 
+// SupportsPodResize returns false as the v0 hostmgr API has no call to
+// resize a running mesos task.
+func (l *v0LifecycleMgr) SupportsPodResize() bool {
+	return false
+}
+
+// UpdatePodResources is not supported by the v0 hostmgr API, which has no
+// call to resize a running mesos task.
+func (l *v0LifecycleMgr) UpdatePodResources(
+	ctx context.Context,
+	taskID string,
+	hostname string,
+	spec *pbpod.PodSpec,
+) error {
+	l.metrics.UpdatePodResourcesFail.Inc(1)
+	return yarpcerrors.UnimplementedErrorf(
+		"updating resources of task %s in place is not supported", taskID)
+}
+
+// ReconcileTask explicitly reconciles the state of the mesos task with
+// Mesos through the hostmgr.
+func (l *v0LifecycleMgr) ReconcileTask(
+	ctx context.Context,
+	taskID string,
+	agentID string,
+) error {
+	req := &v0_hostsvc.ReconcileTasksRequest{
+		Tasks: []*v0_hostsvc.ReconcileTasksRequest_Task{
+			{
+				TaskId:  &mesos.TaskID{Value: &taskID},
+				AgentId: &mesos.AgentID{Value: &agentID},
+			},
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, _defaultHostmgrAPITimeout)
+	defer cancel()
+
+	res, err := l.hostManagerV0.ReconcileTasks(ctx, req)
+	if err == nil && res.GetError() != nil {
+		err = yarpcerrors.InternalErrorf(res.GetError().GetMessage())
+	}
+	if err != nil {
+		l.metrics.ReconcileFail.Inc(1)
+		return err
+	}
+	l.metrics.Reconcile.Inc(1)
+	return nil
+}
+
 // GetTasksOnDrainingHosts gets the taskIDs of the tasks on the
 // hosts in DRAINING state
 func (l *v0LifecycleMgr) GetTasksOnDrainingHosts(
@@ -494,9 +560,14 @@ func generateAssignedTask(
 // fix to ensure job creates dont fail on clients adding the system labels
 // TODO: remove this once all Peloton clients have been modified
 // to not add these labels to jobs submitted through them.
+// The stable name of the instance is added to the system labels if the
+// job has stable instance names, along with the launch correlation ID of
+// the task so that it can be found in the Mesos labels.
 func mutateSystemLabels(
 	launchableTask *v0_hostsvc.LaunchableTask,
 	addOn *models.ConfigAddOn,
+	instanceID uint32,
+	launchCorrelationID string,
 ) {
 	var labels []*peloton.Label
 	for _, label := range launchableTask.GetConfig().GetLabels() {
@@ -507,4 +578,14 @@ func mutateSystemLabels(
 	launchableTask.Config.Labels = labels
 	launchableTask.Config.Labels = append(
 		launchableTask.Config.Labels, addOn.GetSystemLabels()...)
+
+	if label := jobutil.InstanceNameLabel(
+		addOn.GetSystemLabels(), instanceID); label != nil {
+		launchableTask.Config.Labels = append(launchableTask.Config.Labels, label)
+	}
+
+	if label := jobutil.LaunchCorrelationIDLabel(
+		launchCorrelationID); label != nil {
+		launchableTask.Config.Labels = append(launchableTask.Config.Labels, label)
+	}
 }
@@ -29,6 +29,7 @@ import (
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	v0_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	v0_host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
@@ -530,6 +531,51 @@ func (suite *v0LifecycleTestSuite) TestTerminateLease() {
 	suite.Nil(err)
 }
 
+// TestUpdatePodResources tests that lm.UpdatePodResources is not supported.
+func (suite *v0LifecycleTestSuite) TestUpdatePodResources() {
+	suite.False(suite.lm.SupportsPodResize())
+	err := suite.lm.UpdatePodResources(
+		suite.ctx, "task-id", "test-host-1", &pbpod.PodSpec{})
+	suite.True(yarpcerrors.IsUnimplemented(err))
+}
+
+// TestReconcileTask tests lm.ReconcileTask.
+func (suite *v0LifecycleTestSuite) TestReconcileTask() {
+	agentID := "agent-1"
+	req := &v0_hostsvc.ReconcileTasksRequest{
+		Tasks: []*v0_hostsvc.ReconcileTasksRequest_Task{
+			{
+				TaskId:  &mesos.TaskID{Value: &suite.mesosTaskID},
+				AgentId: &mesos.AgentID{Value: &agentID},
+			},
+		},
+	}
+
+	suite.mockHostMgr.EXPECT().
+		ReconcileTasks(gomock.Any(), req).
+		Return(&v0_hostsvc.ReconcileTasksResponse{}, nil)
+	suite.NoError(suite.lm.ReconcileTask(
+		suite.ctx, suite.mesosTaskID, agentID))
+
+	// error in response
+	suite.mockHostMgr.EXPECT().
+		ReconcileTasks(gomock.Any(), req).
+		Return(&v0_hostsvc.ReconcileTasksResponse{
+			Error: &v0_hostsvc.ReconcileTasksResponse_Error{
+				Message: randomErrorStr,
+			},
+		}, nil)
+	err := suite.lm.ReconcileTask(suite.ctx, suite.mesosTaskID, agentID)
+	suite.True(yarpcerrors.IsInternal(err))
+
+	// error in call
+	suite.mockHostMgr.EXPECT().
+		ReconcileTasks(gomock.Any(), req).
+		Return(nil, yarpcerrors.UnavailableErrorf(randomErrorStr))
+	suite.Error(suite.lm.ReconcileTask(
+		suite.ctx, suite.mesosTaskID, agentID))
+}
+
 // TestPopulateExecutorData tests populateExecutorData function to properly
 // fill out executor data in the launchable task, with the placement info
 // passed in.
@@ -627,3 +673,33 @@ func getTaskConfigData() []byte {
 	data, _ := ioutil.ReadFile(testTaskConfigData)
 	return data
 }
+
+// TestMutateSystemLabels tests that client provided system labels are
+// replaced and the stable instance name and launch correlation ID labels
+// are added.
+func (suite *v0LifecycleTestSuite) TestMutateSystemLabels() {
+	launchableTask := &v0_hostsvc.LaunchableTask{
+		Config: &task.TaskConfig{
+			Labels: []*peloton.Label{
+				{Key: "peloton.job_name", Value: "client"},
+				{Key: "peloton.launch_correlation_id", Value: "client"},
+				{Key: "key", Value: "value"},
+			},
+		},
+	}
+	addOn := &models.ConfigAddOn{
+		SystemLabels: []*peloton.Label{
+			{Key: "peloton.job_name", Value: "test-job"},
+			{Key: "peloton.stable_instance_names", Value: "true"},
+		},
+	}
+
+	mutateSystemLabels(launchableTask, addOn, 2, "launch-correlation-id")
+	suite.Equal([]*peloton.Label{
+		{Key: "key", Value: "value"},
+		{Key: "peloton.job_name", Value: "test-job"},
+		{Key: "peloton.stable_instance_names", Value: "true"},
+		{Key: "peloton.instance_name", Value: "test-job-2"},
+		{Key: "peloton.launch_correlation_id", Value: "launch-correlation-id"},
+	}, launchableTask.GetConfig().GetLabels())
+}
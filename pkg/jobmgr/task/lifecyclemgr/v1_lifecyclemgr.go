@@ -19,11 +19,13 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pbhostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	v1_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -108,6 +110,37 @@ func (l *v1LifecycleMgr) Launch(
 		// launchablePod.Spec.Labels = append(
 		// 	launchablePod.Spec.Labels,
 		// 	api.ConvertLabels(pod.ConfigAddOn.GetSystemLabels())...)
+		// The stable instance name and the launch correlation ID are
+		// valid DNS labels, so they can be added to the pod labels.
+		var labels []*peloton.Label
+		instanceName := jobutil.InstanceNameLabel(
+			pod.ConfigAddOn.GetSystemLabels(),
+			pod.GetInstanceId())
+		if instanceName != nil {
+			labels = append(labels, &peloton.Label{
+				Key:   instanceName.GetKey(),
+				Value: instanceName.GetValue(),
+			})
+		}
+		if label := jobutil.LaunchCorrelationIDLabel(
+			pod.GetRuntime().GetLaunchCorrelationId()); label != nil {
+			labels = append(labels,
+				&peloton.Label{Key: label.GetKey(), Value: label.GetValue()})
+		}
+		if len(labels) != 0 && pod.Spec != nil {
+			spec := *pod.Spec
+			spec.Labels = append(
+				append([]*peloton.Label{}, spec.GetLabels()...),
+				labels...)
+			// The stable instance name is passed to the containers through
+			// their environment as well, like the task builder of hostmgr
+			// does for the tasks launched through the v0 API.
+			if instanceName != nil {
+				spec.Containers = withInstanceNameEnv(
+					spec.GetContainers(), instanceName.GetValue())
+			}
+			launchablePod.Spec = &spec
+		}
 		launchablePods = append(launchablePods, &launchablePod)
 	}
 
@@ -137,6 +170,15 @@ func (l *v1LifecycleMgr) Launch(
 		"hostname": hostname,
 		"duration": callDuration.Seconds(),
 	}).Debug("Launched pods")
+	for _, pod := range pods {
+		log.WithFields(log.Fields{
+			"job_id":                pod.GetJobId().GetValue(),
+			"instance_id":           pod.GetInstanceId(),
+			"mesos_task_id":         pod.GetRuntime().GetMesosTaskId().GetValue(),
+			"launch_correlation_id": pod.GetRuntime().GetLaunchCorrelationId(),
+			"hostname":              hostname,
+		}).Info("Launched pod")
+	}
 	l.metrics.LaunchDuration.Record(callDuration)
 	return nil
 }
@@ -241,12 +283,91 @@ func (l *v1LifecycleMgr) TerminateLease(
 	return nil
 }
 
+// withInstanceNameEnv returns copies of the containers with the stable
+// name of their instance added to their environment.
+func withInstanceNameEnv(
+	containers []*pbpod.ContainerSpec,
+	instanceName string,
+) []*pbpod.ContainerSpec {
+	result := make([]*pbpod.ContainerSpec, 0, len(containers))
+	for _, c := range containers {
+		container := *c
+		container.Environment = append(
+			append([]*pbpod.Environment{}, c.GetEnvironment()...),
+			&pbpod.Environment{
+				Name:  jobutil.InstanceNameEnvVar,
+				Value: instanceName,
+			})
+		result = append(result, &container)
+	}
+	return result
+}
+
+// SupportsPodResize returns true as the v1 hostmgr API can resize pods,
+// provided the plugin of the hostmgr supports it.
+func (l *v1LifecycleMgr) SupportsPodResize() bool {
+	return true
+}
+
+// UpdatePodResources resizes a running pod in place through the hostmgr.
+func (l *v1LifecycleMgr) UpdatePodResources(
+	ctx context.Context,
+	podID string,
+	hostname string,
+	spec *pbpod.PodSpec,
+) error {
+	request := &v1_hostsvc.UpdatePodResourcesRequest{
+		PodId:    &peloton.PodID{Value: podID},
+		Hostname: hostname,
+		Spec:     spec,
+	}
+	ctx, cancel := context.WithTimeout(ctx, _defaultHostmgrAPITimeout)
+	defer cancel()
+	_, err := l.hostManagerV1.UpdatePodResources(ctx, request)
+	if err != nil {
+		l.metrics.UpdatePodResourcesFail.Inc(1)
+		return err
+	}
+	l.metrics.UpdatePodResources.Inc(1)
+	return nil
+}
+
+// ReconcileTask is a no-op for v1 LifecycleMgr, since the events of the
+// pods are streamed from hostmgr.
+func (l *v1LifecycleMgr) ReconcileTask(
+	ctx context.Context,
+	podID string,
+	agentID string,
+) error {
+	l.metrics.Reconcile.Inc(1)
+	return nil
+}
+
 // GetTasksOnDrainingHosts gets the taskIDs of the tasks on the
-// hosts in DRAINING state
+// hosts in DRAINING state. The timeout is ignored, as the pods are read
+// from the host cache rather than dequeued.
 func (l *v1LifecycleMgr) GetTasksOnDrainingHosts(
 	ctx context.Context,
 	limit uint32,
 	timeout uint32,
 ) ([]string, error) {
-	return nil, errors.New("not implemented")
+	ctx, cancel := context.WithTimeout(ctx, _defaultHostmgrAPITimeout)
+	defer cancel()
+	response, err := l.hostManagerV1.GetPodsOnDrainingHosts(
+		ctx,
+		&v1_hostsvc.GetPodsOnDrainingHostsRequest{Limit: limit},
+	)
+	if err != nil {
+		l.metrics.GetTasksOnDrainingHostsFail.Inc(1)
+		return nil, errors.Wrapf(err,
+			"failed to get pods on hosts in DRAINING state")
+	}
+
+	var taskIDs []string
+	for _, podID := range response.GetPodIds() {
+		taskIDs = append(taskIDs, podID.GetValue())
+	}
+
+	l.metrics.GetTasksOnDrainingHosts.Inc(1)
+	return taskIDs, nil
 }
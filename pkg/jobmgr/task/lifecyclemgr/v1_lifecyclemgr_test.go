@@ -20,14 +20,17 @@ import (
 	"strings"
 	"testing"
 
+	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	pbhostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	v1_hostsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 	v1_host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/rpc"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -192,6 +195,11 @@ func (suite *v1LifecycleTestSuite) TestShutdownExecutorLock() {
 	suite.NoError(err)
 }
 
+// TestReconcileTask tests lm.ReconcileTask, which is a no-op.
+func (suite *v1LifecycleTestSuite) TestReconcileTask() {
+	suite.NoError(suite.lm.ReconcileTask(suite.ctx, suite.podID, ""))
+}
+
 // TestLaunch tests the task launcher Launch API to launch pods.
 func (suite *v1LifecycleTestSuite) TestLaunch() {
 	// generate 25 test tasks
@@ -254,6 +262,43 @@ func (suite *v1LifecycleTestSuite) TestLaunch() {
 	suite.Equal(launchedPodSpecMap, expectedPodSpecs)
 }
 
+// TestLaunchStableInstanceName tests that the stable name of an instance
+// is passed to its containers through their environment.
+func (suite *v1LifecycleTestSuite) TestLaunchStableInstanceName() {
+	tmp := createTestTask(0)
+	tmp.ConfigAddOn = &models.ConfigAddOn{
+		SystemLabels: []*v0peloton.Label{
+			{Key: "peloton.job_name", Value: "test-job"},
+			{Key: "peloton.stable_instance_names", Value: "true"},
+		},
+	}
+	taskID := tmp.JobId.Value + "-" + fmt.Sprint(tmp.InstanceId)
+
+	var launchedSpec *pbpod.PodSpec
+	suite.mockHostMgr.EXPECT().
+		LaunchPods(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, reqBody interface{}) {
+			req := reqBody.(*v1_hostsvc.LaunchPodsRequest)
+			launchedSpec = req.GetPods()[0].GetSpec()
+		}).
+		Return(&v1_hostsvc.LaunchPodsResponse{}, nil)
+
+	err := suite.lm.Launch(
+		context.Background(),
+		uuid.New(),
+		"host-1",
+		"host-1",
+		map[string]*LaunchableTaskInfo{taskID: tmp},
+		nil,
+	)
+	suite.NoError(err)
+
+	env := launchedSpec.GetContainers()[0].GetEnvironment()
+	suite.Equal(jobutil.InstanceNameEnvVar, env[len(env)-1].GetName())
+	suite.Equal("test-job-0", env[len(env)-1].GetValue())
+	suite.Len(tmp.Spec.GetContainers()[0].GetEnvironment(), len(env)-1)
+}
+
 // TestLaunchErrors tests Launch errors.
 func (suite *v1LifecycleTestSuite) TestLaunchErrors() {
 	taskInfos := make(map[string]*LaunchableTaskInfo)
@@ -302,6 +347,31 @@ func (suite *v1LifecycleTestSuite) TestLaunchErrors() {
 	suite.True(yarpcerrors.IsResourceExhausted(err))
 }
 
+// TestUpdatePodResources tests lm.UpdatePodResources.
+func (suite *v1LifecycleTestSuite) TestUpdatePodResources() {
+	suite.True(suite.lm.SupportsPodResize())
+	hostname := "test-host-1"
+	spec := &pbpod.PodSpec{}
+	req := &v1_hostsvc.UpdatePodResourcesRequest{
+		PodId:    &peloton.PodID{Value: suite.podID},
+		Hostname: hostname,
+		Spec:     spec,
+	}
+
+	suite.mockHostMgr.EXPECT().
+		UpdatePodResources(gomock.Any(), req).
+		Return(&v1_hostsvc.UpdatePodResourcesResponse{}, nil)
+	suite.NoError(suite.lm.UpdatePodResources(
+		suite.ctx, suite.podID, hostname, spec))
+
+	suite.mockHostMgr.EXPECT().
+		UpdatePodResources(gomock.Any(), req).
+		Return(nil, yarpcerrors.ResourceExhaustedErrorf("no headroom"))
+	err := suite.lm.UpdatePodResources(
+		suite.ctx, suite.podID, hostname, spec)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
 // TestTerminateLease tests successful lm.TerminateLease.
 func (suite *v1LifecycleTestSuite) TestTerminateLease() {
 	hostname := "test-host-1"
@@ -323,3 +393,24 @@ func (suite *v1LifecycleTestSuite) TestTerminateLease() {
 	)
 	suite.Nil(err)
 }
+
+// TestGetTasksOnDrainingHosts tests getting the tasks on the hosts in
+// DRAINING state.
+func (suite *v1LifecycleTestSuite) TestGetTasksOnDrainingHosts() {
+	req := &v1_hostsvc.GetPodsOnDrainingHostsRequest{Limit: 10}
+
+	suite.mockHostMgr.EXPECT().
+		GetPodsOnDrainingHosts(gomock.Any(), req).
+		Return(&v1_hostsvc.GetPodsOnDrainingHostsResponse{
+			PodIds: []*peloton.PodID{{Value: suite.podID}},
+		}, nil)
+	taskIDs, err := suite.lm.GetTasksOnDrainingHosts(suite.ctx, 10, 10)
+	suite.NoError(err)
+	suite.Equal([]string{suite.podID}, taskIDs)
+
+	suite.mockHostMgr.EXPECT().
+		GetPodsOnDrainingHosts(gomock.Any(), req).
+		Return(nil, yarpcerrors.UnavailableErrorf("hostmgr unavailable"))
+	_, err = suite.lm.GetTasksOnDrainingHosts(suite.ctx, 10, 10)
+	suite.Error(err)
+}
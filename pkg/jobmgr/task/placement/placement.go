@@ -29,6 +29,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/lifecycle"
+	"github.com/uber/peloton/pkg/common/taskconfig"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
@@ -53,6 +54,9 @@ type Config struct {
 	// GetPlacementsTimeout is the timeout value for placement processor to
 	// call GetPlacements
 	GetPlacementsTimeout int `yaml:"get_placements_timeout_ms"`
+
+	// LaunchRateLimit limits the rate at which placed tasks are launched
+	LaunchRateLimit LaunchRateLimitConfig `yaml:"launch_rate_limit"`
 }
 
 // Processor defines the interface of placement processor
@@ -71,11 +75,17 @@ type processor struct {
 	goalStateDriver goalstate.Driver
 	taskConfigV2Ops ormobjects.TaskConfigV2Ops
 	secretInfoOps   ormobjects.SecretInfoOps
-	lifeCycle       lifecycle.LifeCycle
-	hmVersion       api.Version
-	lm              lifecyclemgr.Manager
-	config          *Config
-	metrics         *Metrics
+	// instanceOverrideOps reads per-instance overrides which are merged
+	// on top of the task config at launch
+	instanceOverrideOps ormobjects.InstanceOverrideOps
+	lifeCycle           lifecycle.LifeCycle
+	hmVersion           api.Version
+	lm                  lifecyclemgr.Manager
+	config              *Config
+	metrics             *Metrics
+	// launchRateLimiter limits the rate of task launches, it is nil
+	// if task launches are not rate limited
+	launchRateLimiter *launchRateLimiter
 }
 
 const (
@@ -100,6 +110,7 @@ func InitProcessor(
 	config *Config,
 	parent tally.Scope,
 ) Processor {
+	scope := parent.SubScope("jobmgr").SubScope("task")
 	return &processor{
 		resMgrClient:    resmgrsvc.NewResourceManagerServiceYARPCClient(d.ClientConfig(resMgrClientName)),
 		jobFactory:      jobFactory,
@@ -107,10 +118,15 @@ func InitProcessor(
 		lm:              lifecyclemgr.New(hmVersion, d, parent),
 		taskConfigV2Ops: ormobjects.NewTaskConfigV2Ops(ormStore),
 		secretInfoOps:   ormobjects.NewSecretInfoOps(ormStore),
-		config:          config,
-		metrics:         NewMetrics(parent.SubScope("jobmgr").SubScope("task")),
-		lifeCycle:       lifecycle.NewLifeCycle(),
-		hmVersion:       hmVersion,
+		instanceOverrideOps: ormobjects.NewInstanceOverrideOps(
+			ormStore),
+		config:    config,
+		metrics:   NewMetrics(scope),
+		lifeCycle: lifecycle.NewLifeCycle(),
+		hmVersion: hmVersion,
+		launchRateLimiter: newLaunchRateLimiter(
+			&config.LaunchRateLimit,
+			scope),
 	}
 }
 
@@ -191,6 +207,8 @@ func (p *processor) prepareTasksForLaunch(
 	portsIndex := 0
 	taskInfos := make(map[string]*lifecyclemgr.LaunchableTaskInfo)
 	skippedTaskIDs := make([]*peloton.TaskID, 0)
+	// instance overrides of the jobs of the tasks, read once per job
+	overrides := make(map[string]map[uint32]*task.TaskConfig)
 
 	for _, mtaskID := range taskIDs {
 		id, instanceID, err := util.ParseJobAndInstanceID(mtaskID.GetValue())
@@ -255,6 +273,36 @@ func (p *processor) prepareTasksForLaunch(
 			continue
 		}
 
+		jobConfig, err := cachedJob.GetConfig(ctx)
+		if err != nil {
+			log.WithError(err).
+				WithField("task_id", ptaskIDStr).
+				Error("not able to get job configuration")
+			continue
+		}
+
+		taskConfig, err = p.applyInstanceOverride(
+			ctx,
+			jobID,
+			uint32(instanceID),
+			taskConfig,
+			jobConfig.GetInstanceConfigMergeStrategy(),
+			overrides)
+		if err != nil {
+			log.WithError(err).
+				WithField("task_id", ptaskID.GetValue()).
+				Error("not able to get instance override")
+			continue
+		}
+
+		// Pass the array element parameter to the task if the job is a
+		// job array.
+		arrayConfig := cached.GetArrayConfig(jobConfig)
+		taskConfig = taskconfig.ApplyArrayParameter(
+			taskConfig,
+			arrayConfig,
+			uint32(instanceID))
+
 		var spec *pbpod.PodSpec
 		if p.hmVersion.IsV1() {
 			// TODO: unify this call with p.taskConfigV2Ops.GetTaskConfig().
@@ -269,6 +317,10 @@ func (p *processor) prepareTasksForLaunch(
 					Error("not able to get pod spec")
 				continue
 			}
+			spec = taskconfig.ApplyArrayParameterToPodSpec(
+				spec,
+				arrayConfig,
+				uint32(instanceID))
 		}
 
 		runtimeDiff := make(jobmgrcommon.RuntimeDiff)
@@ -335,15 +387,22 @@ func (p *processor) prepareTasksForLaunch(
 			)
 			if err == nil {
 				runtime, _ := cachedTask.GetRuntime(ctx)
+				// Expand the template placeholders now that the
+				// ports of the task are known.
+				vars := taskconfig.TemplateVars{
+					JobID:      jobID.GetValue(),
+					InstanceID: uint32(instanceID),
+					Ports:      runtime.GetPorts(),
+				}
 				taskInfos[ptaskIDStr] = &lifecyclemgr.LaunchableTaskInfo{
 					TaskInfo: &task.TaskInfo{
 						Runtime:    runtime,
-						Config:     taskConfig,
+						Config:     taskconfig.ExpandTaskConfig(taskConfig, vars),
 						InstanceId: uint32(instanceID),
 						JobId:      jobID,
 					},
 					ConfigAddOn: configAddOn,
-					Spec:        spec,
+					Spec:        taskconfig.ExpandPodSpec(spec, vars),
 				}
 				break
 			}
@@ -414,6 +473,29 @@ func (p *processor) processPlacement(
 		p.processSkippedLaunches(ctx, skippedTaskInfos)
 	}
 
+	if err := p.launchRateLimiter.wait(
+		ctx, tasksPerJob(launchableTaskInfos)); err != nil {
+		// Give up on the placement and let resmgr place the tasks again,
+		// since the host offer may have expired by the time the rate
+		// limits allow the launch.
+		if newErr := p.lm.TerminateLease(
+			ctx,
+			placement.GetHostname(),
+			placement.GetAgentId().GetValue(),
+			placement.GetHostOfferID().GetValue(),
+		); newErr != nil {
+			err = errors.Wrap(err, newErr.Error())
+		}
+		log.WithError(err).
+			WithFields(log.Fields{
+				"placement":   placement,
+				"tasks_total": len(launchableTaskInfos),
+			}).Warn("launch rate limited, process skipped launches")
+		p.metrics.TaskRequeuedOnLaunchFail.Inc(int64(len(launchableTaskInfos)))
+		p.processSkippedLaunches(ctx, launchableTaskInfos)
+		return
+	}
+
 	err = p.lm.Launch(
 		ctx,
 		placement.GetHostOfferID().GetValue(),
@@ -440,6 +522,17 @@ func (p *processor) processPlacement(
 	p.KillResManagerTasks(ctx, skippedTaskIDs)
 }
 
+// tasksPerJob returns the number of launchable tasks of each job.
+func tasksPerJob(
+	taskInfos map[string]*lifecyclemgr.LaunchableTaskInfo,
+) map[string]int {
+	counts := make(map[string]int)
+	for _, taskInfo := range taskInfos {
+		counts[taskInfo.GetJobId().GetValue()]++
+	}
+	return counts
+}
+
 // populateSecrets populates the eligible tasks with secret data.
 // For the tasks which have transient errors when fetching the
 // secret data from DB, it returns them as skipped.
@@ -541,6 +634,39 @@ func (p *processor) populateTaskConfigWithSecrets(
 	return nil
 }
 
+// applyInstanceOverride merges the override stored for the instance, if
+// any, on top of its task config. Overrides are kept outside of the job
+// config versions so they do not need a new config version to be patched.
+// The task was enqueued with the override already applied, the launched
+// task config is read again from the config version of the task so the
+// override is applied again, with the instance config merge strategy of
+// the job. The overrides of a job are read at once for all its tasks in
+// the placement, and kept in the given map.
+func (p *processor) applyInstanceOverride(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	instanceID uint32,
+	taskConfig *task.TaskConfig,
+	strategy *job.InstanceConfigMergeStrategy,
+	overrides map[string]map[uint32]*task.TaskConfig,
+) (*task.TaskConfig, error) {
+	jobOverrides, ok := overrides[jobID.GetValue()]
+	if !ok {
+		var err error
+		jobOverrides, err = p.instanceOverrideOps.GetAll(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		overrides[jobID.GetValue()] = jobOverrides
+	}
+
+	override, ok := jobOverrides[instanceID]
+	if !ok {
+		return taskConfig, nil
+	}
+	return taskconfig.MergeWithStrategy(taskConfig, override, strategy), nil
+}
+
 // updateTaskRuntime updates task runtime with goalstate, reason and message
 // for the given task id.
 func (p *processor) updateTaskRuntime(
@@ -170,6 +170,7 @@ type PlacementTestSuite struct {
 	lmMock          *lmmocks.MockManager
 	secretInfoOps   *objectmocks.MockSecretInfoOps
 	taskConfigV2Ops *objectmocks.MockTaskConfigV2Ops
+	overrideOps     *objectmocks.MockInstanceOverrideOps
 	config          *Config
 	metrics         *Metrics
 	scope           tally.Scope
@@ -186,6 +187,7 @@ func (suite *PlacementTestSuite) SetupTest() {
 	suite.lmMock = lmmocks.NewMockManager(suite.ctrl)
 	suite.taskConfigV2Ops = objectmocks.NewMockTaskConfigV2Ops(suite.ctrl)
 	suite.secretInfoOps = objectmocks.NewMockSecretInfoOps(suite.ctrl)
+	suite.overrideOps = objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
 	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
 	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
 	suite.config = &Config{
@@ -201,8 +203,21 @@ func (suite *PlacementTestSuite) SetupTest() {
 		jobFactory:      suite.jobFactory,
 		goalStateDriver: suite.goalStateDriver,
 		lifeCycle:       lifecycle.NewLifeCycle(),
+
+		instanceOverrideOps: suite.overrideOps,
 	}
 
+	// most tests do not care about instance overrides
+	suite.overrideOps.EXPECT().
+		GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		AnyTimes()
+
+	// most tests do not launch job arrays
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{}, nil).
+		AnyTimes()
 }
 
 func (suite *PlacementTestSuite) TearDownTest() {
@@ -635,6 +650,74 @@ func (suite *PlacementTestSuite) TestLaunchError() {
 	suite.pp.processPlacement(context.Background(), p)
 }
 
+// TestLaunchRateLimited tests that a placement which cannot be launched
+// within the launch rate limits is returned to resmgr.
+func (suite *PlacementTestSuite) TestLaunchRateLimited() {
+	testTask, _ := createTestTask(0) // taskinfo
+	rs := createResources(float64(1))
+	hostOffer := createHostOffer(0, rs)
+	p := createPlacements([]*task.TaskInfo{testTask}, hostOffer)
+	taskID := &peloton.TaskID{
+		Value: testTask.JobId.Value + "-" + fmt.Sprint(testTask.InstanceId),
+	}
+
+	suite.pp.launchRateLimiter = newLaunchRateLimiter(
+		&LaunchRateLimitConfig{
+			Rate:           0.001,
+			Burst:          1,
+			MaxLaunchDelay: 10 * time.Millisecond,
+		},
+		tally.NoopScope,
+	)
+	// exhaust the tokens of the cluster launch rate limit
+	suite.True(suite.pp.launchRateLimiter.global.Allow())
+
+	gomock.InOrder(
+		suite.jobFactory.EXPECT().
+			GetJob(testTask.JobId).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			AddTask(gomock.Any(), uint32(0)).
+			Return(suite.cachedTask, nil),
+		suite.cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).Return(testTask.Runtime, nil),
+		suite.taskConfigV2Ops.EXPECT().
+			GetTaskConfig(gomock.Any(), testTask.JobId, uint32(0), gomock.Any()).
+			Return(testTask.Config, &models.ConfigAddOn{}, nil),
+		suite.cachedJob.EXPECT().
+			PatchTasks(gomock.Any(), gomock.Any(), false).
+			Return(nil, nil, nil),
+		suite.cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).Return(testTask.Runtime, nil),
+		suite.lmMock.EXPECT().
+			TerminateLease(
+				gomock.Any(),
+				p.GetHostname(),
+				p.GetAgentId().GetValue(),
+				p.GetHostOfferID().GetValue(),
+			).Return(nil),
+		suite.resMgrClient.EXPECT().
+			KillTasks(gomock.Any(), &resmgrsvc.KillTasksRequest{
+				Tasks: []*peloton.TaskID{taskID},
+			}).
+			Return(&resmgrsvc.KillTasksResponse{}, nil),
+		suite.jobFactory.EXPECT().
+			AddJob(testTask.JobId).Return(suite.cachedJob),
+		suite.cachedJob.EXPECT().
+			PatchTasks(gomock.Any(), gomock.Any(), false).
+			Return(nil, nil, nil),
+		suite.goalStateDriver.EXPECT().
+			EnqueueTask(testTask.JobId, testTask.InstanceId, gomock.Any()).Return(),
+		suite.cachedJob.EXPECT().GetJobType().Return(job.JobType_BATCH),
+		suite.goalStateDriver.EXPECT().
+			JobRuntimeDuration(job.JobType_BATCH).
+			Return(1*time.Second),
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(testTask.JobId, gomock.Any()).Return(),
+	)
+
+	suite.pp.processPlacement(context.Background(), p)
+}
+
 // TestLaunchErrorAndResmgrEnqueueError tests failure in lifecyclemgr.Launch()
 // followed by an error enqueuing the tasks to resmgr.
 func (suite *PlacementTestSuite) TestLaunchErrorAndResmgrEnqueueError() {
@@ -906,6 +989,110 @@ func (suite *PlacementTestSuite) TestPopulateSecrets() {
 	suite.Equal(skipped, launchableTasks)
 }
 
+// TestApplyInstanceOverride tests merging the instance override on top
+// of the task config before launch.
+func (suite *PlacementTestSuite) TestApplyInstanceOverride() {
+	overrideOps := objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	suite.pp.instanceOverrideOps = overrideOps
+	jobID := &peloton.JobID{Value: _testJobID}
+	taskConfig := &task.TaskConfig{
+		Name: "base",
+		Resource: &task.ResourceConfig{
+			CpuLimit: 1,
+		},
+		Labels: []*peloton.Label{{Key: "k1", Value: "v1"}},
+	}
+	strategy := &job.InstanceConfigMergeStrategy{
+		Labels: job.MergeMode_MERGE_MODE_APPEND,
+	}
+
+	overrides := make(map[string]map[uint32]*task.TaskConfig)
+
+	// the overrides of the job are read once for all its instances
+	overrideOps.EXPECT().
+		GetAll(gomock.Any(), jobID).
+		Return(map[uint32]*task.TaskConfig{
+			1: {
+				Resource: &task.ResourceConfig{
+					CpuLimit: 2,
+				},
+				Labels: []*peloton.Label{{Key: "k2", Value: "v2"}},
+			},
+		}, nil)
+
+	// no override
+	config, err := suite.pp.applyInstanceOverride(
+		context.Background(), jobID, 0, taskConfig, strategy, overrides)
+	suite.NoError(err)
+	suite.Equal(taskConfig, config)
+
+	// override present, merged with the merge strategy of the job
+	config, err = suite.pp.applyInstanceOverride(
+		context.Background(), jobID, 1, taskConfig, strategy, overrides)
+	suite.NoError(err)
+	suite.Equal("base", config.GetName())
+	suite.Equal(float64(2), config.GetResource().GetCpuLimit())
+	suite.Len(config.GetLabels(), 2)
+
+	// db error
+	otherJobID := &peloton.JobID{Value: "other-job"}
+	overrideOps.EXPECT().
+		GetAll(gomock.Any(), otherJobID).
+		Return(nil, yarpcerrors.UnavailableErrorf("db unavailable"))
+	_, err = suite.pp.applyInstanceOverride(
+		context.Background(), otherJobID, 0, taskConfig, strategy, overrides)
+	suite.Error(err)
+}
+
+// TestPrepareTasksForLaunchJobArray tests passing the array element
+// parameter to the task of a job array before launch.
+func (suite *PlacementTestSuite) TestPrepareTasksForLaunchJobArray() {
+	testTask, _ := createTestTask(1)
+	cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+	suite.jobFactory.EXPECT().
+		GetJob(testTask.JobId).Return(cachedJob)
+	cachedJob.EXPECT().
+		AddTask(gomock.Any(), uint32(1)).
+		Return(suite.cachedTask, nil)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).Return(testTask.Runtime, nil).Times(2)
+	suite.taskConfigV2Ops.EXPECT().
+		GetTaskConfig(gomock.Any(), testTask.JobId, uint32(1), gomock.Any()).
+		Return(testTask.Config, &models.ConfigAddOn{}, nil)
+	cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{
+			ArrayConfig: &job.JobArrayConfig{
+				Parameters: []string{"first", "second"},
+			},
+		}, nil)
+	cachedJob.EXPECT().
+		PatchTasks(gomock.Any(), gomock.Any(), false).
+		Return(nil, nil, nil)
+
+	taskInfos, skipped, err := suite.pp.prepareTasksForLaunch(
+		context.Background(),
+		[]*mesos.TaskID{testTask.GetRuntime().GetMesosTaskId()},
+		"hostname",
+		"agent-id",
+		nil,
+	)
+	suite.NoError(err)
+	suite.Empty(skipped)
+	suite.Len(taskInfos, 1)
+	for _, taskInfo := range taskInfos {
+		env := make(map[string]string)
+		for _, v := range taskInfo.GetConfig().GetCommand().
+			GetEnvironment().GetVariables() {
+			env[v.GetName()] = v.GetValue()
+		}
+		suite.Equal("1", env["PELOTON_ARRAY_INDEX"])
+		suite.Equal("second", env["PELOTON_ARRAY_PARAMETER"])
+	}
+	suite.Nil(testTask.GetConfig().GetCommand())
+}
+
 // createPlacements creates the placement.
 func createPlacements(
 	tasks []*task.TaskInfo,
@@ -0,0 +1,263 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// _defaultMaxLaunchDelay is the default maximum time a placement
+	// waits for the launch rate limits before it is given up on.
+	_defaultMaxLaunchDelay = 5 * time.Second
+	// _jobLimiterIdleTimeout is the time after which the launch rate
+	// limiter of a job which has not launched any task is dropped.
+	_jobLimiterIdleTimeout = 10 * time.Minute
+)
+
+var (
+	errLaunchQueueFull     = errors.New("too many placements waiting for launch")
+	errLaunchDelayExceeded = errors.New(
+		"launch rate limits exceeded for the maximum launch delay")
+)
+
+// LaunchRateLimitConfig is the config for rate limiting task launches.
+// Rates are in tasks launched per second. A rate <= 0 disables the
+// corresponding limit.
+type LaunchRateLimitConfig struct {
+	// Rate is the maximum rate at which tasks are launched in the cluster
+	Rate float64 `yaml:"rate"`
+	// Burst is the maximum number of tasks launched at once in the
+	// cluster. Defaults to the rate rounded up.
+	Burst int `yaml:"burst"`
+
+	// PerJobRate is the maximum rate at which tasks of a job are launched
+	PerJobRate float64 `yaml:"per_job_rate"`
+	// PerJobBurst is the maximum number of tasks of a job launched at
+	// once. Defaults to the per job rate rounded up.
+	PerJobBurst int `yaml:"per_job_burst"`
+
+	// MaxQueuedPlacements is the maximum number of placements waiting for
+	// the launch rate limits. Placements beyond this are returned to
+	// resource manager. A value <= 0 means no limit.
+	MaxQueuedPlacements int `yaml:"max_queued_placements"`
+	// MaxLaunchDelay is the maximum time a placement waits for the launch
+	// rate limits, after which it is returned to resource manager, since
+	// the host offer of the placement may have expired by then.
+	MaxLaunchDelay time.Duration `yaml:"max_launch_delay"`
+}
+
+// newTokenBucket creates a rate limiter for the rate and burst, or returns
+// nil if the rate is not limited.
+func newTokenBucket(r float64, burst int) *rate.Limiter {
+	if r <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(r)
+		if float64(burst) < r {
+			burst++
+		}
+	}
+	return rate.NewLimiter(rate.Limit(r), burst)
+}
+
+// jobLimiter is the launch rate limiter of a job.
+type jobLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// launchRateLimiter limits the rate at which tasks are launched, both in
+// the cluster and for each job, so that jobs with a large number of
+// instances cannot overwhelm host manager and Mesos with launches.
+type launchRateLimiter struct {
+	sync.Mutex
+
+	config *LaunchRateLimitConfig
+
+	global *rate.Limiter
+	jobs   map[string]*jobLimiter
+	// lastPurge is the last time idle job limiters were dropped
+	lastPurge time.Time
+
+	// queued is the number of placements waiting for the rate limits
+	queued int32
+
+	queuedGauge tally.Gauge
+	delay       tally.Timer
+	rejected    tally.Counter
+}
+
+// newLaunchRateLimiter creates a launchRateLimiter, or returns nil if
+// neither the cluster nor the per job launch rate is limited.
+func newLaunchRateLimiter(
+	config *LaunchRateLimitConfig,
+	scope tally.Scope,
+) *launchRateLimiter {
+	if config == nil || (config.Rate <= 0 && config.PerJobRate <= 0) {
+		return nil
+	}
+
+	return &launchRateLimiter{
+		config:      config,
+		global:      newTokenBucket(config.Rate, config.Burst),
+		jobs:        make(map[string]*jobLimiter),
+		lastPurge:   time.Now(),
+		queuedGauge: scope.Gauge("launch_rate_limit_queued"),
+		delay:       scope.Timer("launch_rate_limit_delay"),
+		rejected:    scope.Counter("launch_rate_limit_rejected"),
+	}
+}
+
+// getJobLimiter returns the launch rate limiter of a job, creating it
+// if needed.
+func (l *launchRateLimiter) getJobLimiter(jobID string) *rate.Limiter {
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPurge) > _jobLimiterIdleTimeout {
+		for id, jl := range l.jobs {
+			if now.Sub(jl.lastUsed) > _jobLimiterIdleTimeout {
+				delete(l.jobs, id)
+			}
+		}
+		l.lastPurge = now
+	}
+
+	jl, ok := l.jobs[jobID]
+	if !ok {
+		jl = &jobLimiter{
+			limiter: newTokenBucket(
+				l.config.PerJobRate,
+				l.config.PerJobBurst),
+		}
+		l.jobs[jobID] = jl
+	}
+	jl.lastUsed = now
+	return jl.limiter
+}
+
+// wait blocks until the given number of tasks per job can be launched
+// without exceeding the launch rate limits. It returns an error if too
+// many placements are already waiting, or if the limits would not allow
+// the launch within the maximum launch delay.
+func (l *launchRateLimiter) wait(
+	ctx context.Context,
+	tasksPerJob map[string]int,
+) error {
+	if l == nil {
+		return nil
+	}
+
+	queued := atomic.AddInt32(&l.queued, 1)
+	defer func() {
+		l.queuedGauge.Update(float64(atomic.AddInt32(&l.queued, -1)))
+	}()
+	l.queuedGauge.Update(float64(queued))
+	if l.config.MaxQueuedPlacements > 0 &&
+		int(queued) > l.config.MaxQueuedPlacements {
+		l.rejected.Inc(1)
+		return errLaunchQueueFull
+	}
+
+	maxDelay := l.config.MaxLaunchDelay
+	if maxDelay <= 0 {
+		maxDelay = _defaultMaxLaunchDelay
+	}
+
+	// The tokens of all the limiters are reserved up front, so that they
+	// can all be given back if the placement is not launched.
+	start := time.Now()
+	var res reservations
+	total := 0
+	for jobID, count := range tasksPerJob {
+		total += count
+		if l.config.PerJobRate <= 0 {
+			continue
+		}
+		res = append(res, reserveN(l.getJobLimiter(jobID), count, start)...)
+	}
+	res = append(res, reserveN(l.global, total, start)...)
+
+	delay := res.delay(start)
+	if delay > maxDelay {
+		res.cancel(start)
+		l.rejected.Inc(1)
+		return errLaunchDelayExceeded
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		res.cancel(time.Now())
+		l.rejected.Inc(1)
+		return errors.Wrap(ctx.Err(), "failed to wait for launch rate limits")
+	case <-timer.C:
+	}
+	l.delay.Record(time.Since(start))
+	return nil
+}
+
+// reservations are the launch tokens reserved for a placement.
+type reservations []*rate.Reservation
+
+// delay returns the time to wait from now until all the reserved tokens
+// are available.
+func (r reservations) delay(now time.Time) time.Duration {
+	var delay time.Duration
+	for _, res := range r {
+		if d := res.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+	return delay
+}
+
+// cancel gives the reserved tokens which are not available yet at the
+// given time back to their limiters.
+func (r reservations) cancel(now time.Time) {
+	for _, res := range r {
+		res.CancelAt(now)
+	}
+}
+
+// reserveN reserves n tokens from the limiter, in chunks of at most the
+// burst size of the limiter.
+func reserveN(limiter *rate.Limiter, n int, now time.Time) reservations {
+	if limiter == nil {
+		return nil
+	}
+
+	var res reservations
+	for n > 0 {
+		k := n
+		if k > limiter.Burst() {
+			k = limiter.Burst()
+		}
+		res = append(res, limiter.ReserveN(now, k))
+		n -= k
+	}
+	return res
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type LaunchRateLimiterTestSuite struct {
+	suite.Suite
+}
+
+func TestLaunchRateLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LaunchRateLimiterTestSuite))
+}
+
+// TestNoLimit tests that no rate limiter is created if neither the
+// cluster nor the per job launch rate is limited
+func (suite *LaunchRateLimiterTestSuite) TestNoLimit() {
+	l := newLaunchRateLimiter(&LaunchRateLimitConfig{}, tally.NoopScope)
+	suite.Nil(l)
+	suite.NoError(l.wait(context.Background(), map[string]int{"job": 100}))
+}
+
+// TestDefaultBurst tests that the burst defaults to the rate rounded up
+func (suite *LaunchRateLimiterTestSuite) TestDefaultBurst() {
+	suite.Nil(newTokenBucket(0, 10))
+	suite.Equal(3, newTokenBucket(2.5, 0).Burst())
+	suite.Equal(10, newTokenBucket(2.5, 10).Burst())
+}
+
+// TestWaitMoreThanBurst tests waiting for more tasks than the burst
+func (suite *LaunchRateLimiterTestSuite) TestWaitMoreThanBurst() {
+	l := newLaunchRateLimiter(
+		&LaunchRateLimitConfig{
+			Rate:  1000,
+			Burst: 10,
+		},
+		tally.NoopScope,
+	)
+	suite.NoError(l.wait(context.Background(), map[string]int{"job": 25}))
+}
+
+// TestPerJobLimit tests that the launch rate limit of a job does not
+// affect the launches of other jobs
+func (suite *LaunchRateLimiterTestSuite) TestPerJobLimit() {
+	l := newLaunchRateLimiter(
+		&LaunchRateLimitConfig{
+			PerJobRate:     0.001,
+			PerJobBurst:    1,
+			MaxLaunchDelay: 10 * time.Millisecond,
+		},
+		tally.NoopScope,
+	)
+	suite.NoError(l.wait(context.Background(), map[string]int{"job1": 1}))
+	suite.Error(l.wait(context.Background(), map[string]int{"job1": 1}))
+	suite.NoError(l.wait(context.Background(), map[string]int{"job2": 1}))
+}
+
+// TestJobTokensReturned tests that the launch tokens of a job are given
+// back if the cluster launch rate limit is exceeded
+func (suite *LaunchRateLimiterTestSuite) TestJobTokensReturned() {
+	scope := tally.NewTestScope("", nil)
+	l := newLaunchRateLimiter(
+		&LaunchRateLimitConfig{
+			Rate:           0.001,
+			Burst:          1,
+			PerJobRate:     0.001,
+			PerJobBurst:    1,
+			MaxLaunchDelay: 10 * time.Millisecond,
+		},
+		scope,
+	)
+	suite.NoError(l.wait(context.Background(), map[string]int{"job1": 1}))
+	suite.Equal(errLaunchDelayExceeded,
+		l.wait(context.Background(), map[string]int{"job2": 1}))
+	suite.True(l.getJobLimiter("job2").Allow())
+	suite.Equal(int64(1),
+		scope.Snapshot().Counters()["launch_rate_limit_rejected+"].Value())
+}
+
+// TestQueueFull tests that placements are rejected once too many
+// placements are waiting for the rate limits
+func (suite *LaunchRateLimiterTestSuite) TestQueueFull() {
+	scope := tally.NewTestScope("", nil)
+	l := newLaunchRateLimiter(
+		&LaunchRateLimitConfig{
+			Rate:                1,
+			Burst:               1,
+			MaxQueuedPlacements: 1,
+		},
+		scope,
+	)
+	l.queued = 1
+
+	err := l.wait(context.Background(), map[string]int{"job": 1})
+	suite.Equal(errLaunchQueueFull, err)
+	suite.Equal(int64(1),
+		scope.Snapshot().Counters()["launch_rate_limit_rejected+"].Value())
+}
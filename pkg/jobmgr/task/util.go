@@ -31,6 +31,7 @@ import (
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr"
 	taskutil "github.com/uber/peloton/pkg/jobmgr/util/task"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -41,9 +42,10 @@ const _initialRunID = 1
 // enqueued.
 func CreateInitializingTask(jobID *peloton.JobID, instanceID uint32, jobConfig *job.JobConfig) *task.RuntimeInfo {
 	mesosTaskID := util.CreateMesosTaskID(jobID, instanceID, _initialRunID)
-	healthState := taskutil.GetInitialHealthState(taskconfig.Merge(
+	healthState := taskutil.GetInitialHealthState(taskconfig.MergeWithStrategy(
 		jobConfig.GetDefaultConfig(),
-		jobConfig.GetInstanceConfig()[instanceID]))
+		jobConfig.GetInstanceConfig()[instanceID],
+		jobConfig.GetInstanceConfigMergeStrategy()))
 
 	runtime := &task.RuntimeInfo{
 		MesosTaskId:          mesosTaskID,
@@ -220,3 +222,29 @@ func CreateResourceUsageMap(
 		(completionTimeUnix - startTimeUnix) * memlimit
 	return resourceUsage, nil
 }
+
+// ApplyInstanceOverrides merges the overrides of the instances of a job on
+// top of the configs of its tasks, so that the tasks are enqueued and
+// placed with their overridden config. The overrides are merged with the
+// instance config merge strategy of the job. The overrides of the job are
+// read at once for all the tasks.
+func ApplyInstanceOverrides(
+	ctx context.Context,
+	instanceOverrideOps ormobjects.InstanceOverrideOps,
+	jobID *peloton.JobID,
+	strategy *job.InstanceConfigMergeStrategy,
+	tasks []*task.TaskInfo,
+) error {
+	overrides, err := instanceOverrideOps.GetAll(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if override, ok := overrides[t.GetInstanceId()]; ok {
+			t.Config = taskconfig.MergeWithStrategy(
+				t.GetConfig(), override, strategy)
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
 	lmmocks "github.com/uber/peloton/pkg/jobmgr/task/lifecyclemgr/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -319,3 +320,57 @@ func (suite *JobmgrTaskUtilTestSuite) TestCreateResourceUsageMapError() {
 	suite.Error(err)
 	suite.Nil(rMap)
 }
+
+// TestApplyInstanceOverrides tests merging the overrides of the instances
+// on top of the configs of their tasks
+func (suite *JobmgrTaskUtilTestSuite) TestApplyInstanceOverrides() {
+	overrideOps := objectmocks.NewMockInstanceOverrideOps(suite.ctrl)
+	jobID := &peloton.JobID{Value: suite.jobID}
+	tasks := []*task.TaskInfo{
+		{
+			InstanceId: 0,
+			Config: &task.TaskConfig{
+				Name:     "base",
+				Resource: &task.ResourceConfig{CpuLimit: 1},
+			},
+		},
+		{
+			InstanceId: 1,
+			Config: &task.TaskConfig{
+				Name:     "base",
+				Resource: &task.ResourceConfig{CpuLimit: 1},
+			},
+		},
+	}
+
+	overrideOps.EXPECT().GetAll(gomock.Any(), jobID).
+		Return(map[uint32]*task.TaskConfig{
+			1: {Resource: &task.ResourceConfig{CpuLimit: 2}},
+		}, nil)
+	suite.NoError(ApplyInstanceOverrides(
+		suite.ctx, overrideOps, jobID, nil, tasks))
+	suite.Equal(float64(1), tasks[0].GetConfig().GetResource().GetCpuLimit())
+	suite.Equal("base", tasks[1].GetConfig().GetName())
+	suite.Equal(float64(2), tasks[1].GetConfig().GetResource().GetCpuLimit())
+
+	// the overrides are merged with the merge strategy of the job
+	tasks[0].Config.Labels = []*peloton.Label{{Key: "k1", Value: "v1"}}
+	overrideOps.EXPECT().GetAll(gomock.Any(), jobID).
+		Return(map[uint32]*task.TaskConfig{
+			0: {Labels: []*peloton.Label{{Key: "k2", Value: "v2"}}},
+		}, nil)
+	suite.NoError(ApplyInstanceOverrides(
+		suite.ctx,
+		overrideOps,
+		jobID,
+		&job.InstanceConfigMergeStrategy{
+			Labels: job.MergeMode_MERGE_MODE_APPEND,
+		},
+		tasks))
+	suite.Len(tasks[0].GetConfig().GetLabels(), 2)
+
+	overrideOps.EXPECT().GetAll(gomock.Any(), jobID).
+		Return(nil, errors.New(randomErrorStr))
+	suite.Error(ApplyInstanceOverrides(
+		suite.ctx, overrideOps, jobID, nil, tasks))
+}
@@ -257,7 +257,12 @@ func (m *serviceHandler) GetPodEvents(
 			break
 		}
 
-		result = append(result, taskEvents...)
+		if body.GetCompact() {
+			// events of a run are sorted by update time in descending order
+			result = append(result, taskEvents[0])
+		} else {
+			result = append(result, taskEvents...)
+		}
 		prevMesosTaskID := taskEvents[0].GetPrevTaskId().GetValue()
 
 		prevRunID, err := util.ParseRunID(prevMesosTaskID)
@@ -994,16 +999,8 @@ func (m *serviceHandler) getRuntimeDiffsForRestart(
 	}
 
 	for _, taskInfo := range taskInfos {
-		runID, err :=
-			util.ParseRunID(taskInfo.GetRuntime().GetMesosTaskId().GetValue())
-		if err != nil {
-			runID = 0
-		}
-
-		result[taskInfo.InstanceId] = jobmgrcommon.RuntimeDiff{
-			jobmgrcommon.DesiredMesosTaskIDField: util.CreateMesosTaskID(
-				cachedJob.ID(), taskInfo.InstanceId, runID+1),
-		}
+		result[taskInfo.InstanceId] = taskutil.RestartTaskDiff(
+			cachedJob.ID(), taskInfo.InstanceId, taskInfo.GetRuntime())
 	}
 
 	return result, nil
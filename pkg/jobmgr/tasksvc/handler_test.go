@@ -1340,6 +1340,44 @@ func (suite *TaskHandlerTestSuite) TestGetPodEventsForAllRuns() {
 	suite.NotNil(response)
 }
 
+// TestGetPodEventsCompact tests getting the compacted pod events of a run,
+// which has only the last event of the run
+func (suite *TaskHandlerTestSuite) TestGetPodEventsCompact() {
+	request := &task.GetPodEventsRequest{
+		JobId: &peloton.JobID{
+			Value: testJob,
+		},
+		InstanceId: testInstanceCount,
+		RunId:      testRunID,
+		Compact:    true,
+	}
+
+	mesosTaskID := testRunID
+	run, err := util.ParseRunID(testRunID)
+	suite.NoError(err)
+	prevMesosTaskID := fmt.Sprintf("%s-%d-%d", testJob, testInstanceCount, run-1)
+
+	var events []*task.PodEvent
+	for _, state := range []string{"RUNNING", "LAUNCHED"} {
+		events = append(events, &task.PodEvent{
+			TaskId: &mesos.TaskID{
+				Value: &mesosTaskID,
+			},
+			ActualState: state,
+			PrevTaskId: &mesos.TaskID{
+				Value: &prevMesosTaskID,
+			},
+		})
+	}
+	suite.mockedPodEventsOps.EXPECT().
+		GetAll(gomock.Any(), testJob, uint32(testInstanceCount), testRunID).
+		Return(events, nil)
+	response, err := suite.handler.GetPodEvents(context.Background(), request)
+	suite.NoError(err)
+	suite.Len(response.GetResult(), 1)
+	suite.Equal("RUNNING", response.GetResult()[0].GetActualState())
+}
+
 // TestGetPodEventsLimitToThreeRuns tests limiting the pod
 // events to last three runs of a task with 5 runs
 func (suite *TaskHandlerTestSuite) TestGetPodEventsFiveRunsLimitToThree() {
@@ -25,10 +25,12 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/pagination"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+	updateutil "github.com/uber/peloton/pkg/jobmgr/util/update"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
@@ -129,6 +131,17 @@ func (h *serviceHandler) CreateUpdate(
 		return nil, yarpcerrors.UnimplementedErrorf("in-place update is not supported yet")
 	}
 
+	if err := updateutil.ValidateVerificationHook(
+		req.GetUpdateConfig().GetVerificationHook()); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, yarpcerrors.InvalidArgumentErrorf(err.Error())
+	}
+
+	if err := updateutil.ValidateHealthGate(req.GetUpdateConfig()); err != nil {
+		h.metrics.UpdateCreateFail.Inc(1)
+		return nil, yarpcerrors.InvalidArgumentErrorf(err.Error())
+	}
+
 	// Validate that the job does exist
 	jobRuntime, err := h.jobRuntimeOps.Get(ctx, pelotonJobID)
 	if err != nil {
@@ -236,6 +249,8 @@ func (h *serviceHandler) GetUpdate(ctx context.Context, req *svc.GetUpdateReques
 					updateModel.GetInstancesFailed(),
 				NumTasksFailed: updateModel.GetInstancesFailed(),
 				State:          updateModel.GetState(),
+				CanaryPromoted: updateModel.GetCanaryPromoted(),
+				GateTripReason: updateModel.GetGateTripReason(),
 			},
 		}
 
@@ -265,6 +280,8 @@ func (h *serviceHandler) GetUpdate(ctx context.Context, req *svc.GetUpdateReques
 				updateModel.GetInstancesFailed(),
 			NumTasksFailed: updateModel.GetInstancesFailed(),
 			State:          updateModel.GetState(),
+			CanaryPromoted: updateModel.GetCanaryPromoted(),
+			GateTripReason: updateModel.GetGateTripReason(),
 		},
 	}
 
@@ -398,7 +415,28 @@ func (h *serviceHandler) ListUpdates(ctx context.Context,
 		return nil, err
 	}
 
+	// updates are returned by the store ordered by creation time,
+	// newest first
+	var pageSize uint32
+	if req.GetLimit() > 0 {
+		pageSize = uint32(req.GetLimit())
+	}
+	keys := make([]string, 0, len(updateIDs))
 	for _, updateID := range updateIDs {
+		keys = append(keys, updateID.GetValue())
+	}
+	start, end, nextPageToken, err := pagination.Page(
+		req.GetPageToken(),
+		pageSize,
+		pagination.Fingerprint(jobID.GetValue()),
+		keys,
+	)
+	if err != nil {
+		h.metrics.UpdateListFail.Inc(1)
+		return nil, err
+	}
+
+	for _, updateID := range updateIDs[start:end] {
 		updateModel, err := h.updateStore.GetUpdate(ctx, updateID)
 		if err != nil {
 			h.metrics.UpdateListFail.Inc(1)
@@ -418,6 +456,8 @@ func (h *serviceHandler) ListUpdates(ctx context.Context,
 					updateModel.GetInstancesFailed(),
 				NumTasksFailed: updateModel.GetInstancesFailed(),
 				State:          updateModel.GetState(),
+				CanaryPromoted: updateModel.GetCanaryPromoted(),
+				GateTripReason: updateModel.GetGateTripReason(),
 			},
 		}
 
@@ -426,7 +466,8 @@ func (h *serviceHandler) ListUpdates(ctx context.Context,
 
 	h.metrics.UpdateList.Inc(1)
 	return &svc.ListUpdatesResponse{
-		UpdateInfo: updates,
+		UpdateInfo:    updates,
+		NextPageToken: nextPageToken,
 	}, nil
 }
 
@@ -912,6 +912,68 @@ func (suite *UpdateSvcTestSuite) TestList() {
 	}
 }
 
+// TestListPagination tests fetching the updates for a job page by page
+func (suite *UpdateSvcTestSuite) TestListPagination() {
+	updates := []*peloton.UpdateID{
+		{Value: uuid.NewRandom().String()},
+		{Value: uuid.NewRandom().String()},
+		{Value: uuid.NewRandom().String()},
+	}
+
+	suite.updateStore.EXPECT().
+		GetUpdatesForJob(gomock.Any(), suite.jobID.GetValue()).
+		Return(updates, nil).
+		Times(2)
+
+	for _, updateID := range updates {
+		suite.updateStore.EXPECT().
+			GetUpdate(gomock.Any(), updateID).
+			Return(&models.UpdateModel{JobID: suite.jobID}, nil)
+	}
+
+	resp, err := suite.h.ListUpdates(
+		context.Background(),
+		&svc.ListUpdatesRequest{
+			JobID: suite.jobID,
+			Limit: 2,
+		},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetUpdateInfo(), 2)
+	suite.Equal(updates[0], resp.GetUpdateInfo()[0].GetUpdateId())
+	suite.Equal(updates[1], resp.GetUpdateInfo()[1].GetUpdateId())
+	suite.NotEmpty(resp.GetNextPageToken())
+
+	resp, err = suite.h.ListUpdates(
+		context.Background(),
+		&svc.ListUpdatesRequest{
+			JobID:     suite.jobID,
+			Limit:     2,
+			PageToken: resp.GetNextPageToken(),
+		},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetUpdateInfo(), 1)
+	suite.Equal(updates[2], resp.GetUpdateInfo()[0].GetUpdateId())
+	suite.Empty(resp.GetNextPageToken())
+}
+
+// TestListInvalidPageToken tests fetching updates with an invalid token
+func (suite *UpdateSvcTestSuite) TestListInvalidPageToken() {
+	suite.updateStore.EXPECT().
+		GetUpdatesForJob(gomock.Any(), suite.jobID.GetValue()).
+		Return([]*peloton.UpdateID{{Value: uuid.NewRandom().String()}}, nil)
+
+	_, err := suite.h.ListUpdates(
+		context.Background(),
+		&svc.ListUpdatesRequest{
+			JobID:     suite.jobID,
+			PageToken: "invalid",
+		},
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
 // TestAbortFail tests getting a DB error while aborting an update
 func (suite *UpdateSvcTestSuite) TestAbortFail() {
 	suite.updateStore.EXPECT().
@@ -32,6 +32,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/volume"
 	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/common/api"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
 
@@ -221,7 +222,10 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 
 	if taskConfig.GetRestartPolicy() != nil {
 		result.RestartPolicy = &pod.RestartPolicy{
-			MaxFailures: taskConfig.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:       taskConfig.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSec: taskConfig.GetRestartPolicy().GetInitialBackoffSec(),
+			MaxBackoffSec:     taskConfig.GetRestartPolicy().GetMaxBackoffSec(),
+			StableRunningSec:  taskConfig.GetRestartPolicy().GetStableRunningSec(),
 		}
 	}
 
@@ -260,29 +264,13 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 	}
 
 	if taskConfig.GetHealthCheck() != nil {
-		container.LivenessCheck = &pod.HealthCheckSpec{
-			Enabled:                taskConfig.GetHealthCheck().GetEnabled(),
-			InitialIntervalSecs:    taskConfig.GetHealthCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           taskConfig.GetHealthCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: taskConfig.GetHealthCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            taskConfig.GetHealthCheck().GetTimeoutSecs(),
-			Type:                   pod.HealthCheckSpec_HealthCheckType(taskConfig.GetHealthCheck().GetType()),
-		}
-
-		if taskConfig.GetHealthCheck().GetCommandCheck() != nil {
-			container.LivenessCheck.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
-				Command:             taskConfig.GetHealthCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: taskConfig.GetHealthCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
+		container.LivenessCheck = api.ConvertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetHealthCheck())
+	}
 
-		if taskConfig.GetHealthCheck().GetHttpCheck() != nil {
-			container.LivenessCheck.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
-				Scheme: taskConfig.GetHealthCheck().GetHttpCheck().GetScheme(),
-				Port:   taskConfig.GetHealthCheck().GetHttpCheck().GetPort(),
-				Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
-			}
-		}
+	if taskConfig.GetReadinessCheck() != nil {
+		container.ReadinessCheck = api.ConvertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetReadinessCheck())
 	}
 
 	if !reflect.DeepEqual(*container, pod.ContainerSpec{}) {
@@ -1184,31 +1172,13 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	}
 
 	if mainContainer.GetLivenessCheck() != nil {
-		healthCheck := &task.HealthCheckConfig{
-			Enabled:                mainContainer.GetLivenessCheck().GetEnabled(),
-			InitialIntervalSecs:    mainContainer.GetLivenessCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           mainContainer.GetLivenessCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: mainContainer.GetLivenessCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            mainContainer.GetLivenessCheck().GetTimeoutSecs(),
-			Type:                   task.HealthCheckConfig_Type(mainContainer.GetLivenessCheck().GetType()),
-		}
-
-		if mainContainer.GetLivenessCheck().GetCommandCheck() != nil {
-			healthCheck.CommandCheck = &task.HealthCheckConfig_CommandCheck{
-				Command:             mainContainer.GetLivenessCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: mainContainer.GetLivenessCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
-
-		if mainContainer.GetLivenessCheck().GetHttpCheck() != nil {
-			healthCheck.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
-				Scheme: mainContainer.GetLivenessCheck().GetHttpCheck().GetScheme(),
-				Port:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPort(),
-				Path:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPath(),
-			}
-		}
+		result.HealthCheck = api.ConvertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetLivenessCheck())
+	}
 
-		result.HealthCheck = healthCheck
+	if mainContainer.GetReadinessCheck() != nil {
+		result.ReadinessCheck = api.ConvertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetReadinessCheck())
 	}
 
 	if len(mainContainer.GetPorts()) != 0 {
@@ -1231,7 +1201,10 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 
 	if spec.GetRestartPolicy() != nil {
 		result.RestartPolicy = &task.RestartPolicy{
-			MaxFailures: spec.GetRestartPolicy().GetMaxFailures(),
+			MaxFailures:       spec.GetRestartPolicy().GetMaxFailures(),
+			InitialBackoffSec: spec.GetRestartPolicy().GetInitialBackoffSec(),
+			MaxBackoffSec:     spec.GetRestartPolicy().GetMaxBackoffSec(),
+			StableRunningSec:  spec.GetRestartPolicy().GetStableRunningSec(),
 		}
 	}
 
@@ -1435,6 +1408,8 @@ func convertTaskTerminationStatusToPodTerminationStatus(
 		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART
 	case task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART:
 		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART
+	case task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED:
+		podReason = pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED
 	}
 	return &pod.TerminationStatus{
 		Reason:   podReason,
@@ -1680,6 +1680,7 @@ func (suite *apiConverterTestSuite) TestConvertTerminationStatusReason() {
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART:           pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_RESTART,
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE:            pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_UPDATE,
 		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART: pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_FOR_SLA_AWARE_RESTART,
+		task.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED: pod.TerminationStatus_TERMINATION_STATUS_REASON_KILLED_JOB_DEADLINE_EXCEEDED,
 	}
 	// ensure that we have a test-case for every legal value of v0 reason
 	suite.Equal(len(task.TerminationStatus_Reason_name), len(expmap))
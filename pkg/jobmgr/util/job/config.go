@@ -26,7 +26,7 @@ import (
 
 // ConstructSystemLabels constructs and returns system labels
 func ConstructSystemLabels(jobConfig *job.JobConfig, respoolPath string) []*peloton.Label {
-	return append(
+	labels := append(
 		[]*peloton.Label{},
 		&peloton.Label{
 			Key: fmt.Sprintf(
@@ -64,4 +64,92 @@ func ConstructSystemLabels(jobConfig *job.JobConfig, respoolPath string) []*pelo
 			Value: respoolPath,
 		},
 	)
+
+	if jobConfig.GetStableInstanceNames() {
+		labels = append(labels, &peloton.Label{
+			Key: fmt.Sprintf(
+				common.SystemLabelKeyTemplate,
+				common.SystemLabelPrefix,
+				common.SystemLabelStableInstanceNames),
+			Value: "true",
+		})
+	}
+
+	return labels
+}
+
+// CronJobLabel returns the system label set on the runs of a cron job,
+// which carries the ID of the cron job.
+func CronJobLabel(cronJobID string) *peloton.Label {
+	return &peloton.Label{
+		Key: fmt.Sprintf(
+			common.SystemLabelKeyTemplate,
+			common.SystemLabelPrefix,
+			common.SystemLabelCronJob),
+		Value: cronJobID,
+	}
+}
+
+// InstanceNameEnvVar is the name of the environment variable through
+// which the stable name of an instance is passed to its containers.
+const InstanceNameEnvVar = "PELOTON_INSTANCE_NAME"
+
+// InstanceName returns the stable name of an instance of a job
+func InstanceName(jobName string, instanceID uint32) string {
+	return fmt.Sprintf("%s-%d", jobName, instanceID)
+}
+
+// InstanceNameLabel returns the label carrying the stable name of a task
+// instance, given the system labels of its job. Returns nil if the
+// instances of the job do not have stable names.
+func InstanceNameLabel(
+	systemLabels []*peloton.Label,
+	instanceID uint32,
+) *peloton.Label {
+	stableNamesKey := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelStableInstanceNames)
+	jobNameKey := fmt.Sprintf(
+		common.SystemLabelKeyTemplate,
+		common.SystemLabelPrefix,
+		common.SystemLabelJobName)
+
+	var stableNames bool
+	var jobName string
+	for _, label := range systemLabels {
+		switch label.GetKey() {
+		case stableNamesKey:
+			stableNames = label.GetValue() == "true"
+		case jobNameKey:
+			jobName = label.GetValue()
+		}
+	}
+	if !stableNames || len(jobName) == 0 {
+		return nil
+	}
+
+	return &peloton.Label{
+		Key: fmt.Sprintf(
+			common.SystemLabelKeyTemplate,
+			common.SystemLabelPrefix,
+			common.SystemLabelInstanceName),
+		Value: InstanceName(jobName, instanceID),
+	}
+}
+
+// LaunchCorrelationIDLabel returns the label carrying the launch
+// correlation ID of a task. Returns nil if the task does not have one.
+func LaunchCorrelationIDLabel(launchCorrelationID string) *peloton.Label {
+	if len(launchCorrelationID) == 0 {
+		return nil
+	}
+
+	return &peloton.Label{
+		Key: fmt.Sprintf(
+			common.SystemLabelKeyTemplate,
+			common.SystemLabelPrefix,
+			common.SystemLabelLaunchCorrelationID),
+		Value: launchCorrelationID,
+	}
 }
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +35,36 @@ func TestConstructSystemLabels(t *testing.T) {
 
 	assert.Len(t, labels, 5)
 }
+
+func TestConstructSystemLabelsStableInstanceNames(t *testing.T) {
+	jobConfig := &pbjob.JobConfig{
+		Name:                "test-name",
+		StableInstanceNames: true,
+	}
+
+	labels := ConstructSystemLabels(jobConfig, "/test")
+	assert.Len(t, labels, 6)
+	assert.Equal(t, &peloton.Label{
+		Key:   "peloton.instance_name",
+		Value: "test-name-3",
+	}, InstanceNameLabel(labels, 3))
+
+	jobConfig.StableInstanceNames = false
+	labels = ConstructSystemLabels(jobConfig, "/test")
+	assert.Nil(t, InstanceNameLabel(labels, 3))
+}
+
+func TestCronJobLabel(t *testing.T) {
+	assert.Equal(t, &peloton.Label{
+		Key:   "peloton.cron_job",
+		Value: "cron-job-id",
+	}, CronJobLabel("cron-job-id"))
+}
+
+func TestLaunchCorrelationIDLabel(t *testing.T) {
+	assert.Equal(t, &peloton.Label{
+		Key:   "peloton.launch_correlation_id",
+		Value: "launch-correlation-id",
+	}, LaunchCorrelationIDLabel("launch-correlation-id"))
+	assert.Nil(t, LaunchCorrelationIDLabel(""))
+}
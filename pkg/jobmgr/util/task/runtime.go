@@ -70,6 +70,7 @@ func RegenerateMesosTaskRuntime(
 	taskRuntime.TerminationStatus = nil
 	taskRuntime.Reason = ""
 	taskRuntime.Message = ""
+	taskRuntime.LaunchCorrelationId = ""
 }
 
 // RegenerateMesosTaskIDDiff returns a diff for patch with the previous mesos
@@ -89,14 +90,15 @@ func RegenerateMesosTaskIDDiff(
 		jobmgrcommon.DesiredMesosTaskIDField: mesosTaskID,
 		jobmgrcommon.HealthyField:            initHealthyField,
 
-		jobmgrcommon.AgentIDField:           nil,
-		jobmgrcommon.StartTimeField:         "",
-		jobmgrcommon.CompletionTimeField:    "",
-		jobmgrcommon.HostField:              "",
-		jobmgrcommon.PortsField:             make(map[string]uint32),
-		jobmgrcommon.TerminationStatusField: nil,
-		jobmgrcommon.MessageField:           "",
-		jobmgrcommon.ReasonField:            "",
+		jobmgrcommon.AgentIDField:             nil,
+		jobmgrcommon.StartTimeField:           "",
+		jobmgrcommon.CompletionTimeField:      "",
+		jobmgrcommon.HostField:                "",
+		jobmgrcommon.PortsField:               make(map[string]uint32),
+		jobmgrcommon.TerminationStatusField:   nil,
+		jobmgrcommon.MessageField:             "",
+		jobmgrcommon.ReasonField:              "",
+		jobmgrcommon.LaunchCorrelationIDField: "",
 	}
 }
 
@@ -120,13 +122,37 @@ func getMesosTaskID(
 	// in which case expected runID is not changed
 	// TODO: deprecate the check once mesos task id migration is complete
 	// and every task has runID populated
-	prevRunID, err := util.ParseRunID(taskRuntime.GetMesosTaskId().GetValue())
+	return NextMesosTaskID(jobID, instanceID, taskRuntime.GetMesosTaskId())
+}
+
+// NextMesosTaskID returns the mesos task id of the run following
+// the given mesos task id. If the run id cannot be parsed, the first
+// run is assumed.
+func NextMesosTaskID(
+	jobID *peloton.JobID,
+	instanceID uint32,
+	mesosTaskID *mesos.TaskID) *mesos.TaskID {
+	prevRunID, err := util.ParseRunID(mesosTaskID.GetValue())
 	if err != nil {
 		prevRunID = 0
 	}
 	return util.CreateMesosTaskID(jobID, instanceID, prevRunID+1)
 }
 
+// RestartTaskDiff returns a diff for patch which restarts a task. The
+// desired mesos task id is set to the next run of the current mesos task
+// id, and the goal state engine then kills the current run and launches
+// the new one.
+func RestartTaskDiff(
+	jobID *peloton.JobID,
+	instanceID uint32,
+	taskRuntime *task.RuntimeInfo) jobmgrcommon.RuntimeDiff {
+	return jobmgrcommon.RuntimeDiff{
+		jobmgrcommon.DesiredMesosTaskIDField: NextMesosTaskID(
+			jobID, instanceID, taskRuntime.GetMesosTaskId()),
+	}
+}
+
 // IsSystemFailure returns true is failure is due to a system failure like
 // container launch failure or container terminated with signal broken pipe.
 // System failures should be tried MaxSystemFailureAttempts irrespective of
@@ -63,8 +63,9 @@ func TestRegenerateMesosTaskRuntime(t *testing.T) {
 
 	for _, tt := range testTable {
 		runtime := &task.RuntimeInfo{
-			MesosTaskId:        &mesos.TaskID{Value: &tt.curMesosTaskID},
-			DesiredMesosTaskId: &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			MesosTaskId:         &mesos.TaskID{Value: &tt.curMesosTaskID},
+			DesiredMesosTaskId:  &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			LaunchCorrelationId: "launch-correlation-id",
 		}
 		RegenerateMesosTaskRuntime(
 			&peloton.JobID{Value: tt.jobID},
@@ -85,6 +86,7 @@ func TestRegenerateMesosTaskRuntime(t *testing.T) {
 		assert.Empty(t, runtime.Host)
 		assert.Empty(t, runtime.Ports)
 		assert.Empty(t, runtime.TerminationStatus)
+		assert.Empty(t, runtime.LaunchCorrelationId)
 	}
 }
 
@@ -125,8 +127,9 @@ func TestRegenerateMesosTaskIDDiff(t *testing.T) {
 
 	for _, tt := range testTable {
 		runtime := &task.RuntimeInfo{
-			MesosTaskId:        &mesos.TaskID{Value: &tt.curMesosTaskID},
-			DesiredMesosTaskId: &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			MesosTaskId:         &mesos.TaskID{Value: &tt.curMesosTaskID},
+			DesiredMesosTaskId:  &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			LaunchCorrelationId: "launch-correlation-id",
 		}
 		diff := RegenerateMesosTaskIDDiff(
 			&peloton.JobID{Value: tt.jobID},
@@ -150,6 +153,39 @@ func TestRegenerateMesosTaskIDDiff(t *testing.T) {
 		assert.Empty(t, diff[jobmgrcommon.HostField])
 		assert.Empty(t, diff[jobmgrcommon.PortsField])
 		assert.Empty(t, diff[jobmgrcommon.TerminationStatusField])
+		assert.Empty(t, diff[jobmgrcommon.LaunchCorrelationIDField])
+	}
+}
+
+func TestRestartTaskDiff(t *testing.T) {
+	jobID := &peloton.JobID{Value: "b64fd26b-0e39-41b7-b22a-205b69f247bd"}
+	testTable := []struct {
+		curMesosTaskID string
+		newMesosTaskID string
+	}{
+		{
+			curMesosTaskID: "b64fd26b-0e39-41b7-b22a-205b69f247bd-0-2",
+			newMesosTaskID: "b64fd26b-0e39-41b7-b22a-205b69f247bd-0-3",
+		},
+		{
+			curMesosTaskID: "b64fd26b-0e39-41b7-b22a-205b69f247bd-0-1690f7cf-9691-42ea-8fd3-7e417246b830",
+			newMesosTaskID: "b64fd26b-0e39-41b7-b22a-205b69f247bd-0-1",
+		},
+		{
+			curMesosTaskID: "",
+			newMesosTaskID: "b64fd26b-0e39-41b7-b22a-205b69f247bd-0-1",
+		},
+	}
+
+	for _, tt := range testTable {
+		runtime := &task.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &tt.curMesosTaskID},
+		}
+		diff := RestartTaskDiff(jobID, 0, runtime)
+
+		assert.Len(t, diff, 1)
+		assert.Equal(t, tt.newMesosTaskID,
+			diff[jobmgrcommon.DesiredMesosTaskIDField].(*mesos.TaskID).GetValue())
 	}
 }
 
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateHealthGate validates the canary phase and the health gate of
+// an update config. Both are optional.
+func ValidateHealthGate(config *pbupdate.UpdateConfig) error {
+	if config.GetCanary() != nil &&
+		config.GetCanary().GetInstances() == 0 &&
+		config.GetCanary().GetBakeTimeSecs() != 0 {
+		return errors.New("canary bake time is set without canary instances")
+	}
+
+	gate := config.GetHealthGate()
+	if gate == nil {
+		return nil
+	}
+
+	if gate.GetMaxFailureRate() < 0 || gate.GetMaxFailureRate() > 1 {
+		return errors.Errorf(
+			"health gate max failure rate %v is not between 0 and 1",
+			gate.GetMaxFailureRate())
+	}
+
+	// the instances being updated are unavailable, so the gate would
+	// trip as soon as a batch starts
+	if maxUnavailable := gate.GetMaxUnavailableInstances(); maxUnavailable != 0 {
+		if config.GetBatchSize() == 0 {
+			return errors.New(
+				"health gate max unavailable instances requires a batch size")
+		}
+		if maxUnavailable < config.GetBatchSize() {
+			return errors.Errorf(
+				"health gate max unavailable instances %d is less than batch size %d",
+				maxUnavailable,
+				config.GetBatchSize())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"testing"
+
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHealthGate(t *testing.T) {
+	validateTests := []struct {
+		config *pbupdate.UpdateConfig
+		valid  bool
+	}{
+		{nil, true},
+		{&pbupdate.UpdateConfig{BatchSize: 2}, true},
+		{&pbupdate.UpdateConfig{
+			Canary: &pbupdate.CanaryConfig{Instances: 1, BakeTimeSecs: 60},
+		}, true},
+		{&pbupdate.UpdateConfig{
+			Canary: &pbupdate.CanaryConfig{BakeTimeSecs: 60},
+		}, false},
+		{&pbupdate.UpdateConfig{
+			HealthGate: &pbupdate.HealthGate{MaxFailureRate: 0.1, WindowSecs: 60},
+		}, true},
+		{&pbupdate.UpdateConfig{
+			HealthGate: &pbupdate.HealthGate{MaxFailureRate: 1.5},
+		}, false},
+		{&pbupdate.UpdateConfig{
+			HealthGate: &pbupdate.HealthGate{MaxFailureRate: -0.1},
+		}, false},
+		{&pbupdate.UpdateConfig{
+			HealthGate: &pbupdate.HealthGate{MaxUnavailableInstances: 2},
+		}, false},
+		{&pbupdate.UpdateConfig{
+			BatchSize:  3,
+			HealthGate: &pbupdate.HealthGate{MaxUnavailableInstances: 2},
+		}, false},
+		{&pbupdate.UpdateConfig{
+			BatchSize:  2,
+			HealthGate: &pbupdate.HealthGate{MaxUnavailableInstances: 2},
+		}, true},
+	}
+
+	for i, test := range validateTests {
+		err := ValidateHealthGate(test.config)
+		assert.Equal(t, test.valid, err == nil, "test %d fails", i)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateVerificationHook validates the verification hook of an
+// update config. A nil hook is valid, and disables verification.
+func ValidateVerificationHook(hook *pbupdate.VerificationHook) error {
+	if hook == nil {
+		return nil
+	}
+
+	switch hook.GetType() {
+	case pbupdate.VerificationHook_COMMAND:
+		if len(hook.GetCommandCheck().GetCommand()) == 0 {
+			return errors.New("verification hook command is not set")
+		}
+	case pbupdate.VerificationHook_HTTP:
+		check := hook.GetHttpCheck()
+		if check.GetPort() == 0 && len(check.GetPortName()) == 0 {
+			return errors.New("verification hook http port is not set")
+		}
+		switch check.GetScheme() {
+		case "", "http", "https":
+		default:
+			return errors.Errorf(
+				"unsupported verification hook http scheme %s",
+				check.GetScheme())
+		}
+	default:
+		return errors.Errorf(
+			"unsupported verification hook type %s", hook.GetType())
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package update
+
+import (
+	"testing"
+
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateVerificationHook(t *testing.T) {
+	validateTests := []struct {
+		hook  *pbupdate.VerificationHook
+		valid bool
+	}{
+		{nil, true},
+		{&pbupdate.VerificationHook{}, false},
+		{&pbupdate.VerificationHook{
+			Type: pbupdate.VerificationHook_COMMAND,
+		}, false},
+		{&pbupdate.VerificationHook{
+			Type: pbupdate.VerificationHook_COMMAND,
+			CommandCheck: &pbupdate.VerificationHook_CommandCheck{
+				Command: "./check.sh",
+			},
+		}, true},
+		{&pbupdate.VerificationHook{
+			Type: pbupdate.VerificationHook_HTTP,
+			HttpCheck: &pbupdate.VerificationHook_HTTPCheck{
+				Path: "/health",
+			},
+		}, false},
+		{&pbupdate.VerificationHook{
+			Type: pbupdate.VerificationHook_HTTP,
+			HttpCheck: &pbupdate.VerificationHook_HTTPCheck{
+				Scheme: "ftp",
+				Port:   8080,
+			},
+		}, false},
+		{&pbupdate.VerificationHook{
+			Type: pbupdate.VerificationHook_HTTP,
+			HttpCheck: &pbupdate.VerificationHook_HTTPCheck{
+				PortName: "http",
+				Path:     "/health",
+			},
+		}, true},
+	}
+
+	for i, test := range validateTests {
+		err := ValidateVerificationHook(test.hook)
+		assert.Equal(t, test.valid, err == nil, "test %d fails", i)
+	}
+}
@@ -17,6 +17,7 @@ package watchsvc
 const (
 	_defaultBufferSize int = 100
 	_defaultMaxClient  int = 1000
+	_defaultHistory    int = 1000
 )
 
 // Config for Watch API
@@ -26,6 +27,9 @@ type Config struct {
 
 	// Maximum number of concurrent watch clients
 	MaxClient int `yaml:"max_client"`
+
+	// Number of the last changes kept to resume watches from
+	HistorySize int `yaml:"history_size"`
 }
 
 func (c *Config) normalize() {
@@ -35,4 +39,7 @@ func (c *Config) normalize() {
 	if c.MaxClient <= 0 {
 		c.MaxClient = _defaultMaxClient
 	}
+	if c.HistorySize <= 0 {
+		c.HistorySize = _defaultHistory
+	}
 }
@@ -69,7 +69,10 @@ func (h *ServiceHandler) watchPod(
 	log.WithField("request", req).
 		Debug("starting new pod watch")
 
-	watchID, watchClient, err := h.processor.NewTaskClient(req.GetPodFilter())
+	watchID, watchClient, err := h.processor.NewTaskClient(
+		req.GetPodFilter(),
+		req.GetStartRevision(),
+	)
 	if err != nil {
 		log.WithError(err).
 			Warn("failed to create pod watch client")
@@ -81,7 +84,8 @@ func (h *ServiceHandler) watchPod(
 	}()
 
 	initResp := &svc.WatchResponse{
-		WatchId: watchID,
+		WatchId:  watchID,
+		Revision: watchClient.Revision,
 	}
 	if err := stream.Send(initResp); err != nil {
 		log.WithField("watch_id", watchID).
@@ -92,10 +96,11 @@ func (h *ServiceHandler) watchPod(
 
 	for {
 		select {
-		case p := <-watchClient.Input:
+		case c := <-watchClient.Input:
 			resp := &svc.WatchResponse{
-				WatchId: watchID,
-				Pods:    []*pod.PodSummary{p},
+				WatchId:  watchID,
+				Revision: c.Revision,
+				Pods:     []*pod.PodSummary{c.Pod},
 			}
 			if err := stream.Send(resp); err != nil {
 				log.WithField("watch_id", watchID).
@@ -137,7 +142,10 @@ func (h *ServiceHandler) watchJob(
 	log.WithField("request", req).
 		Debug("starting new job watch")
 
-	watchID, watchClient, err := h.processor.NewJobClient(req.GetStatelessJobFilter())
+	watchID, watchClient, err := h.processor.NewJobClient(
+		req.GetStatelessJobFilter(),
+		req.GetStartRevision(),
+	)
 	if err != nil {
 		log.WithError(err).
 			Warn("failed to create job watch client")
@@ -149,7 +157,8 @@ func (h *ServiceHandler) watchJob(
 	}()
 
 	initResp := &svc.WatchResponse{
-		WatchId: watchID,
+		WatchId:  watchID,
+		Revision: watchClient.Revision,
 	}
 	if err := stream.Send(initResp); err != nil {
 		log.WithField("watch_id", watchID).
@@ -160,10 +169,11 @@ func (h *ServiceHandler) watchJob(
 
 	for {
 		select {
-		case j := <-watchClient.Input:
+		case c := <-watchClient.Input:
 			resp := &svc.WatchResponse{
 				WatchId:       watchID,
-				StatelessJobs: []*stateless.JobSummary{j},
+				Revision:      c.Revision,
+				StatelessJobs: []*stateless.JobSummary{c.Job},
 			}
 			if err := stream.Send(resp); err != nil {
 				log.WithField("watch_id", watchID).
@@ -248,8 +258,16 @@ func (h *ServiceHandler) Cancel(
 ) (*svc.CancelResponse, error) {
 	watchID := req.GetWatchId()
 
-	if strings.HasPrefix(watchID, ClientTypeTask.String()) {
-		err := h.processor.StopTaskClient(watchID)
+	var stop func(string) error
+	switch {
+	case strings.HasPrefix(watchID, ClientTypeTask.String()):
+		stop = h.processor.StopTaskClient
+	case strings.HasPrefix(watchID, ClientTypeJob.String()):
+		stop = h.processor.StopJobClient
+	}
+
+	if stop != nil {
+		err := stop(watchID)
 		if err != nil {
 			if yarpcerrors.IsNotFound(err) {
 				h.metrics.CancelNotFound.Inc(1)
@@ -257,7 +275,7 @@ func (h *ServiceHandler) Cancel(
 
 			log.WithField("watch_id", watchID).
 				WithError(err).
-				Warn("failed to stop task client")
+				Warn("failed to stop watch client")
 
 			return nil, err
 		}
@@ -101,11 +101,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(0)).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -139,7 +139,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 
 	go func() {
 		for _, p := range pods {
-			taskClient.Input <- p
+			taskClient.Input <- &PodChange{Pod: p}
 		}
 		// cancelling task watch
 		taskClient.Signal <- StopSignalCancel
@@ -160,11 +160,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(0)).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -198,7 +198,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 
 	go func() {
 		for _, p := range pods {
-			taskClient.Input <- p
+			taskClient.Input <- &PodChange{Pod: p}
 		}
 		// simulate buffer overflow
 		taskClient.Signal <- StopSignalOverflow
@@ -209,10 +209,55 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Overflow() {
 	suite.True(yarpcerrors.IsAborted(err))
 }
 
+// TestTaskWatch_Resume tests a pod watch resumed from a revision streams
+// back the revision of each change
+func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_Resume() {
+	watchID := NewWatchID(ClientTypeTask)
+	taskClient := &TaskClient{
+		Input:    make(chan *PodChange),
+		Signal:   make(chan StopSignal, 1),
+		Revision: 12,
+	}
+	p := &pod.PodSummary{
+		PodName: &peloton.PodName{Value: "pod-0"},
+	}
+
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(10)).
+		Return(watchID, taskClient, nil)
+	suite.processor.EXPECT().StopTaskClient(watchID)
+
+	gomock.InOrder(
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 12,
+			}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:  watchID,
+				Revision: 11,
+				Pods:     []*pod.PodSummary{p},
+			}).
+			Return(nil),
+	)
+
+	go func() {
+		taskClient.Input <- &PodChange{Revision: 11, Pod: p}
+		taskClient.Signal <- StopSignalCancel
+	}()
+
+	err := suite.handler.Watch(&watchsvc.WatchRequest{
+		StartRevision: 10,
+		PodFilter:     &watch.PodFilter{},
+	}, suite.watchServer)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
 // TestTaskWatch_MaxClientReached checks Watch will return resource-exhausted
 // error when NewTaskClient reached max client.
 func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(0)).
 		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
@@ -231,11 +276,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(0)).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -266,11 +311,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), uint64(0)).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -301,7 +346,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 	}
 
 	go func() {
-		taskClient.Input <- p
+		taskClient.Input <- &PodChange{Pod: p}
 		taskClient.Signal <- StopSignalCancel
 	}()
 
@@ -319,11 +364,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(0)).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -357,7 +402,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
 
 	go func() {
 		for _, j := range jobs {
-			jobClient.Input <- j
+			jobClient.Input <- &JobChange{Job: j}
 		}
 		// cancelling task watch
 		jobClient.Signal <- StopSignalCancel
@@ -378,11 +423,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(0)).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -416,7 +461,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 
 	go func() {
 		for _, j := range jobs {
-			jobClient.Input <- j
+			jobClient.Input <- &JobChange{Job: j}
 		}
 		// cancelling task watch
 		jobClient.Signal <- StopSignalOverflow
@@ -430,7 +475,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
 // TestJobWatch_MaxClientReached checks Watch will return resource-exhausted
 // error when NewJobClient reached max client.
 func (suite *WatchServiceHandlerTestSuite) TestJobWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(0)).
 		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
@@ -449,11 +494,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_InitSendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(0)).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -483,11 +528,11 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_SendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *stateless.JobSummary),
+		Input:  make(chan *JobChange),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+	suite.processor.EXPECT().NewJobClient(gomock.Any(), uint64(0)).
 		Return(watchID, jobClient, nil)
 	suite.processor.EXPECT().StopJobClient(watchID)
 
@@ -518,7 +563,7 @@ func (suite *WatchServiceHandlerTestSuite) TestJobWatch_SendError() {
 	}
 
 	go func() {
-		jobClient.Input <- j
+		jobClient.Input <- &JobChange{Job: j}
 		jobClient.Signal <- StopSignalCancel
 	}()
 
@@ -540,6 +585,19 @@ func (suite *WatchServiceHandlerTestSuite) TestCancel() {
 	suite.NoError(err)
 }
 
+// TestCancelJob tests Cancel of a job watch
+func (suite *WatchServiceHandlerTestSuite) TestCancelJob() {
+	watchID := NewWatchID(ClientTypeJob)
+
+	suite.processor.EXPECT().StopJobClient(watchID).Return(nil)
+
+	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
+		WatchId: watchID,
+	})
+	suite.NotNil(resp)
+	suite.NoError(err)
+}
+
 // TestCancel_NotFoundTask tests Cancel response returns not-found error, when
 // an invalid task watch-id is passed in.
 func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFoundTask() {
@@ -28,6 +28,9 @@ type Metrics struct {
 
 	CancelNotFound tally.Counter
 
+	WatchResume           tally.Counter
+	WatchResumeOutOfRange tally.Counter
+
 	// Time takes to acquire lock in watch processor
 	ProcessorLockDuration tally.Timer
 }
@@ -44,6 +47,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		CancelNotFound: subScope.Counter("cancel_not_found"),
 
+		WatchResume:           subScope.Counter("watch_resume"),
+		WatchResumeOutOfRange: subScope.Counter("watch_resume_out_of_range"),
+
 		ProcessorLockDuration: subScope.Timer("processor_lock_duration"),
 	}
 }
@@ -17,6 +17,7 @@ package watchsvc
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
@@ -76,8 +77,12 @@ func (t ClientType) String() string {
 // client lifecycle, and task / job event fan-out.
 type WatchProcessor interface {
 	// NewTaskClient creates a new watch client for task event changes.
-	// Returns the watch id and a new instance of TaskClient.
-	NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error)
+	// If start revision is set, the changes after it are replayed to the
+	// client first. Returns the watch id and a new instance of TaskClient.
+	NewTaskClient(
+		filter *watch.PodFilter,
+		startRevision uint64,
+	) (string, *TaskClient, error)
 
 	// StopTaskClient stops a task watch client. Returns "not-found" error
 	// if the corresponding watch client is not found.
@@ -91,8 +96,12 @@ type WatchProcessor interface {
 	NotifyPodChange(pod *pod.PodSummary, podLabels []*peloton.Label)
 
 	// NewJobClient creates a new watch client for job event changes.
-	// Returns the watch id and an new instance of JobClient.
-	NewJobClient(filter *watch.StatelessJobFilter) (string, *JobClient, error)
+	// If start revision is set, the changes after it are replayed to the
+	// client first. Returns the watch id and an new instance of JobClient.
+	NewJobClient(
+		filter *watch.StatelessJobFilter,
+		startRevision uint64,
+	) (string, *JobClient, error)
 
 	// StopJobClient stops a job watch client. Returns "not-found" error
 	// if the corresponding watch client is not found.
@@ -111,9 +120,38 @@ type watchProcessor struct {
 	sync.Mutex
 	bufferSize  int
 	maxClient   int
+	historySize int
 	taskClients map[string]*TaskClient
 	jobClients  map[string]*JobClient
 	metrics     *Metrics
+
+	// revision of the last change notified to the clients
+	revision uint64
+	// last changes notified to the clients, oldest first, which
+	// have consecutive revisions up to the current revision
+	history []*change
+}
+
+// change is a pod or job change recorded in the history of the processor
+type change struct {
+	revision  uint64
+	pod       *pod.PodSummary
+	podLabels []*peloton.Label
+	job       *stateless.JobSummary
+}
+
+// PodChange is a change of a pod streamed to a task watch client.
+type PodChange struct {
+	// Revision of the change
+	Revision uint64
+	Pod      *pod.PodSummary
+}
+
+// JobChange is a change of a job streamed to a job watch client.
+type JobChange struct {
+	// Revision of the change
+	Revision uint64
+	Job      *stateless.JobSummary
 }
 
 var processor *watchProcessor
@@ -127,9 +165,12 @@ type podFilter struct {
 
 // TaskClient represents a client which interested in task event changes.
 type TaskClient struct {
-	Input  chan *pod.PodSummary
+	Input  chan *PodChange
 	Signal chan StopSignal
 
+	// Revision of the processor when the client was created
+	Revision uint64
+
 	filter *podFilter
 }
 
@@ -140,9 +181,12 @@ type jobFilter struct {
 
 // JobClient represents a client which interested in job event changes.
 type JobClient struct {
-	Input  chan *stateless.JobSummary
+	Input  chan *JobChange
 	Signal chan StopSignal
 
+	// Revision of the processor when the client was created
+	Revision uint64
+
 	filter *jobFilter
 }
 
@@ -156,9 +200,13 @@ func newWatchProcessor(
 	return &watchProcessor{
 		bufferSize:  cfg.BufferSize,
 		maxClient:   cfg.MaxClient,
+		historySize: cfg.HistorySize,
 		taskClients: make(map[string]*TaskClient),
 		jobClients:  make(map[string]*JobClient),
 		metrics:     NewMetrics(parent),
+		// revisions start at the current time so that the revisions
+		// issued by a previous instance are never resumed from
+		revision: uint64(time.Now().UnixNano()),
 	}
 }
 
@@ -184,8 +232,12 @@ func NewWatchID(clientType ClientType) string {
 }
 
 // NewTaskClient creates a new watch client for task event changes.
-// Returns the watch id and a new instance of TaskClient.
-func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error) {
+// If start revision is set, the changes after it are replayed to the
+// client first. Returns the watch id and a new instance of TaskClient.
+func (p *watchProcessor) NewTaskClient(
+	filter *watch.PodFilter,
+	startRevision uint64,
+) (string, *TaskClient, error) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
@@ -195,6 +247,11 @@ func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskCl
 		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
 	}
 
+	changes, err := p.changesAfter(startRevision)
+	if err != nil {
+		return "", nil, err
+	}
+
 	podFilter := &podFilter{}
 	if filter != nil {
 		if filter.GetJobId() != nil {
@@ -214,18 +271,27 @@ func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskCl
 	}
 
 	watchID := NewWatchID(ClientTypeTask)
-	p.taskClients[watchID] = &TaskClient{
-		Input: make(chan *pod.PodSummary, p.bufferSize),
+	c := &TaskClient{
+		// Make room for the replayed changes on top of the buffer
+		Input: make(chan *PodChange, p.bufferSize+len(changes)),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
-		Signal: make(chan StopSignal, 1),
-		filter: podFilter,
+		Signal:   make(chan StopSignal, 1),
+		Revision: p.revision,
+		filter:   podFilter,
+	}
+	for _, ch := range changes {
+		if ch.pod != nil && podFilter.match(ch.pod, ch.podLabels) {
+			c.Input <- &PodChange{Revision: ch.revision, Pod: ch.pod}
+		}
 	}
+	p.taskClients[watchID] = c
 
 	log.WithField("watch_id", watchID).
 		WithField("filter", filter).
+		WithField("start_revision", startRevision).
 		Info("task watch client created")
-	return watchID, p.taskClients[watchID], nil
+	return watchID, c, nil
 }
 
 // StopTaskClient stops a task watch client. Returns "not-found" error
@@ -249,6 +315,7 @@ func (p *watchProcessor) StopTaskClients() {
 	for watchID := range p.taskClients {
 		p.stopTaskClient(watchID, StopSignalCancel)
 	}
+	p.resetHistory()
 }
 
 func (p *watchProcessor) stopTaskClient(
@@ -272,6 +339,57 @@ func (p *watchProcessor) stopTaskClient(
 	return nil
 }
 
+// match returns true if the pod passes the filter
+func (f *podFilter) match(
+	pod *pod.PodSummary,
+	podLabels []*peloton.Label,
+) bool {
+	if f == nil {
+		return true
+	}
+
+	// Check job ID filter
+	if len(f.jobID) > 0 {
+		jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
+		if err != nil {
+			// Cannot parse podName to match the jobID, assume that
+			// filter does not match.
+			return false
+		}
+
+		if jobID != f.jobID {
+			// job id filter did not match
+			return false
+		}
+	}
+
+	// Check pod name filter
+	if len(f.podNames) > 0 {
+		if _, ok := f.podNames[pod.GetPodName().GetValue()]; !ok {
+			return false
+		}
+	}
+
+	// Check pod label filter
+	for _, labelFilter := range f.labels {
+		found := false
+		for _, labelPod := range podLabels {
+			if labelFilter.GetKey() == labelPod.GetKey() &&
+				labelFilter.GetValue() == labelPod.GetValue() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// label filter did not match
+			return false
+		}
+	}
+
+	return true
+}
+
 // NotifyPodChange receives pod event, and notifies all the clients
 // which are interested in the pod.
 func (p *watchProcessor) NotifyPodChange(
@@ -282,58 +400,15 @@ func (p *watchProcessor) NotifyPodChange(
 	defer p.Unlock()
 	sw.Stop()
 
-	for watchID, c := range p.taskClients {
-		filterCheckPass := func() bool {
-			if c.filter != nil {
-				// Check job ID filter
-				if len(c.filter.jobID) > 0 {
-					jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
-					if err != nil {
-						// Cannot parse podName to match the jobID, assume that
-						// filter does not match.
-						return false
-					}
-
-					if jobID != c.filter.jobID {
-						// job id filter did not match
-						return false
-					}
-				}
-
-				// Check pod name filter
-				if len(c.filter.podNames) > 0 {
-					if _, ok := c.filter.podNames[pod.GetPodName().GetValue()]; !ok {
-						return false
-					}
-				}
-
-				// Check pod label filter
-				for _, labelFilter := range c.filter.labels {
-					found := false
-					for _, labelPod := range podLabels {
-						if labelFilter.GetKey() == labelPod.GetKey() &&
-							labelFilter.GetValue() == labelPod.GetValue() {
-							found = true
-							break
-						}
-					}
-
-					if !found {
-						// label filter did not match
-						return false
-					}
-				}
-			}
-
-			return true
-		}()
+	ch := p.record(&change{pod: pod, podLabels: podLabels})
 
-		if !filterCheckPass {
+	for watchID, c := range p.taskClients {
+		if !c.filter.match(pod, podLabels) {
 			continue
 		}
 
 		select {
-		case c.Input <- pod:
+		case c.Input <- &PodChange{Revision: ch.revision, Pod: pod}:
 		default:
 			log.WithField("watch_id", watchID).
 				Warn("event overflow for task watch client")
@@ -343,8 +418,12 @@ func (p *watchProcessor) NotifyPodChange(
 }
 
 // NewJobClient creates a new watch client for job event changes.
-// Returns the watch id and an new instance of JobClient.
-func (p *watchProcessor) NewJobClient(filter *watch.StatelessJobFilter) (string, *JobClient, error) {
+// If start revision is set, the changes after it are replayed to the
+// client first. Returns the watch id and an new instance of JobClient.
+func (p *watchProcessor) NewJobClient(
+	filter *watch.StatelessJobFilter,
+	startRevision uint64,
+) (string, *JobClient, error) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
@@ -354,6 +433,11 @@ func (p *watchProcessor) NewJobClient(filter *watch.StatelessJobFilter) (string,
 		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
 	}
 
+	changes, err := p.changesAfter(startRevision)
+	if err != nil {
+		return "", nil, err
+	}
+
 	jobFilter := &jobFilter{}
 	if filter != nil {
 		if len(filter.GetJobIds()) > 0 {
@@ -369,18 +453,27 @@ func (p *watchProcessor) NewJobClient(filter *watch.StatelessJobFilter) (string,
 	}
 
 	watchID := NewWatchID(ClientTypeJob)
-	p.jobClients[watchID] = &JobClient{
-		Input: make(chan *stateless.JobSummary, p.bufferSize),
+	c := &JobClient{
+		// Make room for the replayed changes on top of the buffer
+		Input: make(chan *JobChange, p.bufferSize+len(changes)),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
-		Signal: make(chan StopSignal, 1),
-		filter: jobFilter,
+		Signal:   make(chan StopSignal, 1),
+		Revision: p.revision,
+		filter:   jobFilter,
+	}
+	for _, ch := range changes {
+		if ch.job != nil && jobFilter.match(ch.job) {
+			c.Input <- &JobChange{Revision: ch.revision, Job: ch.job}
+		}
 	}
+	p.jobClients[watchID] = c
 
 	log.WithField("watch_id", watchID).
 		WithField("filter", filter).
+		WithField("start_revision", startRevision).
 		Info("job watch client created")
-	return watchID, p.jobClients[watchID], nil
+	return watchID, c, nil
 }
 
 // StopJobClient stops a job watch client. Returns "not-found" error
@@ -404,6 +497,7 @@ func (p *watchProcessor) StopJobClients() {
 	for watchID := range p.jobClients {
 		p.stopJobClient(watchID, StopSignalCancel)
 	}
+	p.resetHistory()
 }
 
 func (p *watchProcessor) stopJobClient(
@@ -427,6 +521,39 @@ func (p *watchProcessor) stopJobClient(
 	return nil
 }
 
+// match returns true if the job passes the filter
+func (f *jobFilter) match(job *stateless.JobSummary) bool {
+	if f == nil {
+		return true
+	}
+
+	// Check job IDs filter
+	if len(f.jobIDs) > 0 {
+		jobID := job.GetJobId().GetValue()
+		if _, ok := f.jobIDs[jobID]; !ok {
+			return false
+		}
+	}
+
+	// Check job label filter
+	for _, labelFilter := range f.labels {
+		found := false
+		for _, labelJob := range job.GetLabels() {
+			if labelFilter.GetKey() == labelJob.GetKey() &&
+				labelFilter.GetValue() == labelJob.GetValue() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
 // NotifyJobChange receives job event, and notifies all the clients
 // which are interested in the job.
 func (p *watchProcessor) NotifyJobChange(job *stateless.JobSummary) {
@@ -435,43 +562,15 @@ func (p *watchProcessor) NotifyJobChange(job *stateless.JobSummary) {
 	defer p.Unlock()
 	sw.Stop()
 
-	for watchID, c := range p.jobClients {
-		filterCheckPass := func() bool {
-			if c.filter != nil {
-				// Check job IDs filter
-				if len(c.filter.jobIDs) > 0 {
-					jobID := job.GetJobId().GetValue()
-					if _, ok := c.filter.jobIDs[jobID]; !ok {
-						return false
-					}
-				}
-
-				// Check job label filter
-				for _, labelFilter := range c.filter.labels {
-					found := false
-					for _, labelJob := range job.GetLabels() {
-						if labelFilter.GetKey() == labelJob.GetKey() &&
-							labelFilter.GetValue() == labelJob.GetValue() {
-							found = true
-							break
-						}
-					}
-
-					if !found {
-						return false
-					}
-				}
-			}
-
-			return true
-		}()
+	ch := p.record(&change{job: job})
 
-		if !filterCheckPass {
+	for watchID, c := range p.jobClients {
+		if !c.filter.match(job) {
 			continue
 		}
 
 		select {
-		case c.Input <- job:
+		case c.Input <- &JobChange{Revision: ch.revision, Job: job}:
 		default:
 			log.WithField("watch_id", watchID).
 				Warn("event overflow for job watch client")
@@ -479,3 +578,58 @@ func (p *watchProcessor) NotifyJobChange(job *stateless.JobSummary) {
 		}
 	}
 }
+
+// record assigns the next revision to a change, and adds it to the
+// history of changes. Must be called with the lock held.
+func (p *watchProcessor) record(ch *change) *change {
+	p.revision++
+	ch.revision = p.revision
+
+	if p.historySize <= 0 {
+		return ch
+	}
+	if len(p.history) >= p.historySize {
+		// drop the oldest change
+		copy(p.history, p.history[1:])
+		p.history = p.history[:len(p.history)-1]
+	}
+	p.history = append(p.history, ch)
+	return ch
+}
+
+// changesAfter returns the changes after the start revision, for a
+// watch resumed from it. Must be called with the lock held.
+func (p *watchProcessor) changesAfter(startRevision uint64) ([]*change, error) {
+	if startRevision == 0 {
+		return nil, nil
+	}
+
+	if startRevision > p.revision {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"start revision %d is newer than server revision %d",
+			startRevision, p.revision)
+	}
+
+	// the history has all the changes after the oldest revision
+	oldestRevision := p.revision - uint64(len(p.history))
+	if startRevision < oldestRevision {
+		p.metrics.WatchResumeOutOfRange.Inc(1)
+		return nil, yarpcerrors.OutOfRangeErrorf(
+			"start revision %d is older than oldest revision %d",
+			startRevision, oldestRevision)
+	}
+
+	p.metrics.WatchResume.Inc(1)
+	return p.history[len(p.history)-int(p.revision-startRevision):], nil
+}
+
+// resetHistory drops the history of changes on leader change, and moves
+// the revision past the revisions issued while this instance was the
+// leader before, so that watches are not resumed across leaders.
+// Must be called with the lock held.
+func (p *watchProcessor) resetHistory() {
+	p.history = nil
+	if now := uint64(time.Now().UnixNano()); now > p.revision {
+		p.revision = now
+	}
+}
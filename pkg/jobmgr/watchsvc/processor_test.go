@@ -73,7 +73,7 @@ func (suite *WatchProcessorTestSuite) TestInitWatchProcessor() {
 
 // TestTaskClient tests basic setup and teardown of task watch client
 func (suite *WatchProcessorTestSuite) TestTaskClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -103,7 +103,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient() {
 // TestTaskClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -115,12 +115,12 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
 
 // TestTaskClient_StopAllClients tests stop all clients on losing leadership
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewTaskClient(nil)
+	watchID1, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID1)
 	suite.NotNil(c)
 
-	watchID2, c, err := suite.processor.NewTaskClient(nil)
+	watchID2, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID2)
 	suite.NotNil(c)
@@ -136,7 +136,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
 // creating a new client if max number of clients is reached.
 func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewTaskClient(nil)
+		watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -152,7 +152,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
 func (suite *WatchProcessorTestSuite) TestTaskClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -195,7 +195,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -255,7 +255,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -301,7 +301,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 
 // TestJobClient tests basic setup and teardown of job watch client
 func (suite *WatchProcessorTestSuite) TestJobClient() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -331,7 +331,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient() {
 // TestJobClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
 func (suite *WatchProcessorTestSuite) TestJobClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -343,12 +343,12 @@ func (suite *WatchProcessorTestSuite) TestJobClient_StopNonexistentClient() {
 
 // TestJobClient_StopAllClients tests stop all clients on losing leadership
 func (suite *WatchProcessorTestSuite) TestJobClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewJobClient(nil)
+	watchID1, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID1)
 	suite.NotNil(c)
 
-	watchID2, c, err := suite.processor.NewJobClient(nil)
+	watchID2, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID2)
 	suite.NotNil(c)
@@ -364,7 +364,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient_StopAllClients() {
 // creating a new client if max number of clients is reached.
 func (suite *WatchProcessorTestSuite) TestJobClient_MaxClientReached() {
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewJobClient(nil)
+		watchID, c, err := suite.processor.NewJobClient(nil, 0)
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -380,7 +380,7 @@ func (suite *WatchProcessorTestSuite) TestJobClient_MaxClientReached() {
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
 func (suite *WatchProcessorTestSuite) TestJobClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewJobClient(nil)
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -422,7 +422,7 @@ func (suite *WatchProcessorTestSuite) TestJobClientJobFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewJobClient(filter)
+	watchID, c, err := suite.processor.NewJobClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -480,7 +480,7 @@ func (suite *WatchProcessorTestSuite) TestJobClientLabelFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewJobClient(filter)
+	watchID, c, err := suite.processor.NewJobClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -526,3 +526,66 @@ func (suite *WatchProcessorTestSuite) TestJobClientLabelFilter() {
 	suite.Equal(2, received)
 	mutex.Unlock()
 }
+
+// TestTaskClientResume tests a task watch resumed from a revision gets
+// the changes after the revision replayed first
+func (suite *WatchProcessorTestSuite) TestTaskClientResume() {
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	startRevision := c.Revision
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyPodChange(&pod.PodSummary{
+			PodName: &peloton.PodName{
+				Value: fmt.Sprintf("%s-%d", suite.jobID.GetValue(), i),
+			},
+		}, nil)
+	}
+	suite.processor.NotifyJobChange(&stateless.JobSummary{})
+
+	// resume after the first change
+	_, c, err = suite.processor.NewTaskClient(nil, startRevision+1)
+	suite.NoError(err)
+	suite.Equal(startRevision+4, c.Revision)
+	suite.Len(c.Input, 2)
+	for i := 1; i < 3; i++ {
+		change := <-c.Input
+		suite.Equal(startRevision+uint64(i)+1, change.Revision)
+		suite.Equal(
+			fmt.Sprintf("%s-%d", suite.jobID.GetValue(), i),
+			change.Pod.GetPodName().GetValue())
+	}
+
+	// start revision newer than the server revision
+	_, _, err = suite.processor.NewTaskClient(nil, startRevision+5)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestJobClientResumeOutOfRange tests a job watch cannot be resumed from
+// a revision older than the history, or issued before a leader change
+func (suite *WatchProcessorTestSuite) TestJobClientResumeOutOfRange() {
+	suite.config.HistorySize = 2
+	suite.processor = newWatchProcessor(suite.config, suite.testScope)
+
+	watchID, c, err := suite.processor.NewJobClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopJobClient(watchID))
+	startRevision := c.Revision
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyJobChange(&stateless.JobSummary{})
+	}
+
+	_, _, err = suite.processor.NewJobClient(nil, startRevision)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	watchID, c, err = suite.processor.NewJobClient(nil, startRevision+1)
+	suite.NoError(err)
+	suite.Len(c.Input, 2)
+
+	// the history is dropped on leader change
+	suite.processor.StopJobClients()
+	_, _, err = suite.processor.NewJobClient(nil, startRevision+3)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+}
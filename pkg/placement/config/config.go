@@ -20,6 +20,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/common/api"
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/health"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
@@ -45,6 +46,7 @@ type Config struct {
 	Storage      config.Config         `yaml:"storage"`
 	SentryConfig logging.SentryConfig  `yaml:"sentry"`
 	Auth         auth.Config           `yaml:"auth"`
+	Freeze       freeze.Config         `yaml:"freeze"`
 }
 
 // PlacementStrategy determines the placement strategy that the placement
@@ -111,6 +113,12 @@ type PlacementConfig struct {
 
 	// UseHostPool is the config switch to use host pool logic in placement engine
 	UseHostPool bool `yaml:"use_host_pool"`
+
+	// MaintenanceAvoidanceWindow is how long before the maintenance window
+	// of a host the engine stops placing tasks on the host, so that long
+	// running tasks are not placed on hosts about to be drained. Zero
+	// disables it.
+	MaintenanceAvoidanceWindow time.Duration `yaml:"maintenance_avoidance_window"`
 }
 
 // MaxRoundsConfig is the config of the maximal number of successful rounds
@@ -299,8 +299,11 @@ func (e *engine) placeAssignmentGroup(
 			tasks = append(tasks, a)
 		}
 
+		// Offers of hosts about to go into maintenance are not placed on,
+		// and are released along with the other unused offers.
+		candidates := e.filterUnavailableOffers(time.Now(), offers)
 		hosts := []plugins.Host{}
-		for _, o := range offers {
+		for _, o := range candidates {
 			hosts = append(hosts, o)
 		}
 
@@ -309,7 +312,7 @@ func (e *engine) placeAssignmentGroup(
 		placements := e.strategy.GetTaskPlacements(tasks, hosts)
 		for assignmentIdx, hostIdx := range placements {
 			if hostIdx != -1 {
-				assignments[assignmentIdx].SetPlacement(offers[hostIdx])
+				assignments[assignmentIdx].SetPlacement(candidates[hostIdx])
 			}
 		}
 
@@ -557,6 +560,45 @@ func (e *engine) cleanup(
 	}
 }
 
+// filterUnavailableOffers returns the offers whose hosts are not scheduled to
+// go into maintenance within the maintenance avoidance window.
+func (e *engine) filterUnavailableOffers(
+	now time.Time,
+	offers []models.Offer) []models.Offer {
+	if e.config.MaintenanceAvoidanceWindow <= 0 {
+		return offers
+	}
+
+	var available []models.Offer
+	for _, offer := range offers {
+		if isUnavailableWithin(offer, now, e.config.MaintenanceAvoidanceWindow) {
+			e.metrics.OfferUnavailable.Inc(1)
+			log.WithField("hostname", offer.Hostname()).
+				Debug("skipping host about to go into maintenance")
+			continue
+		}
+		available = append(available, offer)
+	}
+	return available
+}
+
+// isUnavailableWithin returns true if the host of the offer is scheduled to be
+// unavailable at some point within the given window from now.
+func isUnavailableWithin(
+	offer models.Offer,
+	now time.Time,
+	window time.Duration) bool {
+	start, duration := offer.Unavailability()
+	if start.IsZero() {
+		return false
+	}
+	// The maintenance window is already over.
+	if duration > 0 && !start.Add(duration).After(now) {
+		return false
+	}
+	return start.Before(now.Add(window))
+}
+
 func (e *engine) pastDeadline(now time.Time, assignments []models.Task) bool {
 	for _, assignment := range assignments {
 		if !assignment.IsPastDeadline(now) {
@@ -20,10 +20,12 @@ import (
 	"testing"
 	"time"
 
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/common/async"
 	"github.com/uber/peloton/pkg/placement/config"
 	"github.com/uber/peloton/pkg/placement/models"
+	models_v0 "github.com/uber/peloton/pkg/placement/models/v0"
 	offers_mock "github.com/uber/peloton/pkg/placement/offers/mocks"
 	"github.com/uber/peloton/pkg/placement/plugins"
 	"github.com/uber/peloton/pkg/placement/plugins/batch"
@@ -725,6 +727,56 @@ func TestEngineFilterAssignments(t *testing.T) {
 	assert.Equal(t, []models.Task{assignment4}, unassigned)
 }
 
+// Tests that offers of hosts about to go into maintenance are filtered out.
+func TestEngineFilterUnavailableOffers(t *testing.T) {
+	ctrl, engine, _, _, _, scope := setupEngine(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	setUnavailability := func(
+		host *models_v0.HostOffers,
+		start time.Time,
+		duration time.Duration) {
+		startNanos := start.UnixNano()
+		durationNanos := int64(duration)
+		host.GetOffer().Unavailability = &mesos.Unavailability{
+			Start:    &mesos.TimeInfo{Nanoseconds: &startNanos},
+			Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+		}
+	}
+
+	noMaintenance := testutil.SetupHostOffers()
+	soonMaintenance := testutil.SetupHostOffers()
+	setUnavailability(soonMaintenance, now.Add(10*time.Minute), time.Hour)
+	laterMaintenance := testutil.SetupHostOffers()
+	setUnavailability(laterMaintenance, now.Add(2*time.Hour), time.Hour)
+	pastMaintenance := testutil.SetupHostOffers()
+	setUnavailability(pastMaintenance, now.Add(-2*time.Hour), time.Hour)
+	ongoingMaintenance := testutil.SetupHostOffers()
+	setUnavailability(ongoingMaintenance, now.Add(-time.Minute), 0)
+
+	offers := []models.Offer{
+		noMaintenance,
+		soonMaintenance,
+		laterMaintenance,
+		pastMaintenance,
+		ongoingMaintenance,
+	}
+
+	// Nothing is filtered out if the avoidance window is not set.
+	assert.Equal(t, offers, engine.filterUnavailableOffers(now, offers))
+
+	engine.config.MaintenanceAvoidanceWindow = time.Hour
+	assert.Equal(
+		t,
+		[]models.Offer{noMaintenance, laterMaintenance, pastMaintenance},
+		engine.filterUnavailableOffers(now, offers))
+	assert.Equal(
+		t,
+		int64(2),
+		scope.Snapshot().Counters()["batch.offer.unavailable+result=fail"].Value())
+}
+
 func TestEngineCleanup(t *testing.T) {
 	ctrl, engine, _, mockTaskService, _, _ := setupEngine(t)
 	defer ctrl.Finish()
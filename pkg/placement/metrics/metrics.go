@@ -52,6 +52,10 @@ type Metrics struct {
 	// an Offer and it failed
 	OfferGetFail tally.Counter
 
+	// OfferUnavailable indicates the number of offers skipped since
+	// their hosts are about to go into maintenance
+	OfferUnavailable tally.Counter
+
 	// Launcher metrics
 
 	// LaunchTask is the number of mesos tasks launched. This is a
@@ -132,6 +136,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		OfferGet:     offerSuccessScope.Counter("get"),
 		OfferGetFail: offerFailScope.Counter("get"),
 
+		OfferUnavailable: offerFailScope.Counter("unavailable"),
+
 		LaunchTask:            taskSuccessScope.Counter("launch"),
 		LaunchTaskFail:        taskFailScope.Counter("launch"),
 		LaunchOfferAccept:     offerSuccessScope.Counter("accept"),
@@ -36,6 +36,11 @@ type Offer interface {
 
 	// Returns the available port ranges for this offer.
 	AvailablePortRanges() map[*PortRange]struct{}
+
+	// Returns the start and the duration of the window the host of the
+	// offer is scheduled to be unavailable in for maintenance. A zero start
+	// means no window is scheduled, and a zero duration an unbounded one.
+	Unavailability() (time.Time, time.Duration)
 }
 
 // Task is the interface that represents a resource manager task. This
@@ -153,6 +153,13 @@ func (a *Assignment) GetPlacementNeeds() plugins.PlacementNeeds {
 	if a.PreferredHost() != "" {
 		needs.HostHints[a.PelotonID()] = a.PreferredHost()
 	}
+	if volume := rmTask.GetVolume(); volume != nil {
+		needs.Volume = &plugins.VolumeNeeds{
+			DiskType: volume.GetDiskType(),
+			SizeMB:   volume.GetSizeMB(),
+			Mount:    volume.GetMount(),
+		}
+	}
 	// To spread out tasks over hosts, request host-manager
 	// to rank hosts randomly instead of a predictable order such
 	// as most-loaded.
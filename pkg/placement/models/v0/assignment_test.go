@@ -29,6 +29,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/plugins"
 )
 
 func setupAssignmentVariables() (
@@ -119,6 +120,23 @@ func TestAssignment(t *testing.T) {
 		require.Nil(t, needs.Constraint)
 		require.Equal(t, hostsvc.FilterHint_FILTER_HINT_RANKING_RANDOM, needs.RankHint)
 		require.Equal(t, uint32(1), needs.MaxHosts)
+		require.Nil(t, needs.Volume)
+	})
+
+	t.Run("placement needs volume", func(t *testing.T) {
+		_, _, _, _, _, assignment := setupAssignmentVariables()
+		assignment.GetTask().GetTask().Volume = &peloton_api_v0_task.PersistentVolumeConfig{
+			ContainerPath: "/data",
+			SizeMB:        1024,
+			DiskType:      "SSD",
+			Mount:         true,
+		}
+		needs := assignment.GetPlacementNeeds()
+		require.Equal(t, &plugins.VolumeNeeds{
+			DiskType: "SSD",
+			SizeMB:   1024,
+			Mount:    true,
+		}, needs.Volume)
 	})
 
 	t.Run("fits", func(t *testing.T) {
@@ -106,6 +106,17 @@ func (host *HostOffers) AvailablePortRanges() map[*models.PortRange]struct{} {
 	return availablePortRanges
 }
 
+// Unavailability returns the start and the duration of the maintenance
+// window of the host carried by the host offer.
+func (host *HostOffers) Unavailability() (time.Time, time.Duration) {
+	unavailability := host.GetOffer().GetUnavailability()
+	if unavailability == nil {
+		return time.Time{}, 0
+	}
+	return time.Unix(0, unavailability.GetStart().GetNanoseconds()),
+		time.Duration(unavailability.GetDuration().GetNanoseconds())
+}
+
 // Age will return the age of the host, which is the time since it was dequeued from the host manager.
 func (host *HostOffers) Age(now time.Time) time.Duration {
 	return now.Sub(host.Claimed)
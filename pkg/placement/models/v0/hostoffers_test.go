@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -48,6 +49,23 @@ func TestHost_Offer(t *testing.T) {
 	assert.Equal(t, hostOffer, host.GetOffer())
 }
 
+func TestHost_Unavailability(t *testing.T) {
+	_, hostOffer, _, _, host, _, _ := setupHostVariables()
+	start, duration := host.Unavailability()
+	assert.True(t, start.IsZero())
+	assert.Zero(t, duration)
+
+	startNanos := int64(time.Hour)
+	durationNanos := int64(time.Minute)
+	hostOffer.Unavailability = &mesos.Unavailability{
+		Start:    &mesos.TimeInfo{Nanoseconds: &startNanos},
+		Duration: &mesos.DurationInfo{Nanoseconds: &durationNanos},
+	}
+	start, duration = host.Unavailability()
+	assert.Equal(t, time.Unix(0, startNanos), start)
+	assert.Equal(t, time.Minute, duration)
+}
+
 func TestHost_Tasks(t *testing.T) {
 	_, _, resmgrGang, _, host, _, _ := setupHostVariables()
 	assert.Equal(t, resmgrGang.GetTasks(), host.GetTasks())
@@ -15,6 +15,8 @@
 package models_v1
 
 import (
+	"time"
+
 	hostmgr "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 
@@ -99,6 +101,12 @@ func (l lease) AvailablePortRanges() map[*models.PortRange]struct{} {
 	return result
 }
 
+// Unavailability returns no maintenance window, since the host summary of
+// the lease does not carry one.
+func (l lease) Unavailability() (time.Time, time.Duration) {
+	return time.Time{}, 0
+}
+
 func (l lease) countFreePorts() uint64 {
 	ranges := l.hostLease.GetHostSummary().GetAvailablePorts()
 	var total uint64
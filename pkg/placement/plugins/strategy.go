@@ -71,6 +71,22 @@ type PlacementNeeds struct {
 
 	// TODO: RankingHint
 	RankHint interface{}
+
+	// The disk the volume of each task needs on the host, if any.
+	Volume *VolumeNeeds
+}
+
+// VolumeNeeds is the disk which the volume of a task needs on a host.
+type VolumeNeeds struct {
+	// Type of the disk, matched against the disk type attribute of
+	// the host. Any disk type if empty.
+	DiskType string
+
+	// Size of the volume in MB.
+	SizeMB uint32
+
+	// Whether the volume needs a dedicated mount-point disk.
+	Mount bool
 }
 
 // Task is the interface that the Strategy takes in and tries to place on
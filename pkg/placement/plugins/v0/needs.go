@@ -36,6 +36,13 @@ func PlacementNeedsToHostFilter(needs plugins.PlacementNeeds) *hostsvc.HostFilte
 		NumPorts:  uint32(needs.Ports),
 		Revocable: needs.Revocable,
 	}
+	if needs.Volume != nil {
+		resConstraint.Volume = &hostsvc.VolumeConstraint{
+			DiskType: needs.Volume.DiskType,
+			SizeMb:   needs.Volume.SizeMB,
+			Mount:    needs.Volume.Mount,
+		}
+	}
 	quantity := &hostsvc.QuantityControl{
 		MaxHosts: needs.MaxHosts,
 	}
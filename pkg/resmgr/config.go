@@ -33,9 +33,19 @@ type Config struct {
 	// Period to run task scheduling in seconds
 	TaskSchedulingPeriod time.Duration `yaml:"task_scheduling_period"`
 
+	// Target duration of a task scheduling cycle. The number of gangs
+	// dequeued from each resource pool adapts to keep the cycles under
+	// it. Zero disables the adaptive batching.
+	SchedulingCycleBudget time.Duration `yaml:"scheduling_cycle_budget"`
+
 	// Period to run entitlement calculator
 	EntitlementCaculationPeriod time.Duration `yaml:"entitlement_calculation_period"`
 
+	// Period to check if the resource pools need back the reservation they
+	// lent, in which case the entitlement is calculated ahead of the
+	// calculation period. Zero disables the rebalancing.
+	EntitlementRebalancePeriod time.Duration `yaml:"entitlement_rebalance_period"`
+
 	// Period to run task reconciliation
 	TaskReconciliationPeriod time.Duration `yaml:"task_reconciliation_period"`
 
@@ -57,4 +67,17 @@ type Config struct {
 
 	// UseHostPool is the config switch to use host pool in Resource manager
 	UseHostPool bool `yaml:"use_host_pool"`
+
+	// Duration for which the idempotency token of an EnqueueGangs request
+	// which is in flight or succeeded is remembered to suppress retried
+	// requests.
+	EnqueueTokenTTL time.Duration `yaml:"enqueue_token_ttl"`
+
+	// Period to persist the queues of the leaf resource pools, which are
+	// restored in order by the next leader. Zero disables the snapshots.
+	QueueSnapshotPeriod time.Duration `yaml:"queue_snapshot_period"`
+
+	// Queue snapshots older than this are ignored on recovery. Zero means
+	// that snapshots never go stale.
+	QueueSnapshotMaxAge time.Duration `yaml:"queue_snapshot_max_age"`
 }
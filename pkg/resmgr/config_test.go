@@ -29,7 +29,9 @@ const testConfig = `
   http_port: 5290
   grpc_port: 5394
   task_scheduling_period: 100ms
+  scheduling_cycle_budget: 50ms
   entitlement_calculation_period: 60s
+  entitlement_rebalance_period: 5s
   task_reconciliation_period: 1h
   task:
     placing_timeout: 10m
@@ -61,7 +63,9 @@ func TestResourceManagerConfig(t *testing.T) {
 	assert.Equal(t, 5290, testConfig.HTTPPort)
 	assert.Equal(t, 5394, testConfig.GRPCPort)
 	assert.Equal(t, 100*time.Millisecond, testConfig.TaskSchedulingPeriod)
+	assert.Equal(t, 50*time.Millisecond, testConfig.SchedulingCycleBudget)
 	assert.Equal(t, 60*time.Second, testConfig.EntitlementCaculationPeriod)
+	assert.Equal(t, 5*time.Second, testConfig.EntitlementRebalancePeriod)
 	assert.Equal(t, 1*time.Hour, testConfig.TaskReconciliationPeriod)
 	assert.Equal(t, 10*time.Minute, testConfig.RmTaskConfig.PlacingTimeout)
 	assert.Equal(t, 20*time.Minute, testConfig.RmTaskConfig.LaunchingTimeout)
@@ -0,0 +1,152 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	t "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+	rmtask "github.com/uber/peloton/pkg/resmgr/task"
+)
+
+// getTaskEligibility returns the state of a task and the reasons of it not
+// being admitted or placed yet
+func getTaskEligibility(
+	rmTask *rmtask.RMTask,
+) *resmgrsvc.GetTaskEligibilityResponse_TaskEligibility {
+	state := rmTask.GetCurrentState()
+	eligibility := &resmgrsvc.GetTaskEligibilityResponse_TaskEligibility{
+		Task:        rmTask.Task().GetId(),
+		State:       state.State,
+		StateReason: state.Reason,
+	}
+	if node := rmTask.Respool(); node != nil {
+		eligibility.RespoolID = &peloton.ResourcePoolID{Value: node.ID()}
+	}
+
+	var reasons []*resmgrsvc.TaskEligibilityReason
+	switch state.State {
+	case t.TaskState_PENDING:
+		reasons = admissionReasons(rmTask.Respool(), rmTask.Task())
+	case t.TaskState_READY:
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_WAITING_FOR_PLACEMENT,
+			"task is admitted and waits for a placement engine"))
+	case t.TaskState_RESERVED:
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_HOST_RESERVATION,
+			"task waits for a host to be reserved for it"))
+	case t.TaskState_PLACING:
+	default:
+		// the task is placed
+		return eligibility
+	}
+
+	eligibility.Reasons = append(
+		reasons,
+		placementReasons(rmTask.Task(), rmTask.GetPlacementTrace())...)
+	return eligibility
+}
+
+// admissionReasons returns the reasons of a pending task not being admitted
+// into its resource pool. The resources of the task alone are checked
+// against the entitlement, the ones of the rest of its gang are not known.
+func admissionReasons(
+	node respool.ResPool,
+	task *resmgr.Task,
+) []*resmgrsvc.TaskEligibilityReason {
+	if node == nil {
+		return nil
+	}
+
+	var entitlement, allocation *scalar.Resources
+	if task.GetRevocable() {
+		entitlement = node.GetSlackEntitlement()
+		allocation = node.GetSlackAllocatedResources()
+	} else {
+		entitlement = node.GetNonSlackEntitlement()
+		allocation = node.GetNonSlackAllocatedResources()
+	}
+	if entitlement == nil || allocation == nil {
+		return nil
+	}
+
+	if !allocation.LessThanOrEqual(entitlement) {
+		return []*resmgrsvc.TaskEligibilityReason{newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PREEMPTION_PENDING,
+			fmt.Sprintf("allocation %s of resource pool exceeds its "+
+				"entitlement %s", allocation, entitlement))}
+	}
+
+	needed := scalar.ConvertToResmgrResource(task.GetResource())
+	if !allocation.Add(needed).LessThanOrEqual(entitlement) {
+		return []*resmgrsvc.TaskEligibilityReason{newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_RESPOOL_OVER_ENTITLEMENT,
+			fmt.Sprintf("task needs %s on top of allocation %s of resource "+
+				"pool with entitlement %s", needed, allocation, entitlement))}
+	}
+	return nil
+}
+
+// placementReasons returns the reasons from the placement history of a task
+// of it not being placed yet
+func placementReasons(
+	task *resmgr.Task,
+	trace rmtask.PlacementTrace,
+) []*resmgrsvc.TaskEligibilityReason {
+	var reasons []*resmgrsvc.TaskEligibilityReason
+	if trace.Failures > 0 {
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_FAILED,
+			fmt.Sprintf("%d placements failed, last with: %s",
+				trace.Failures, trace.LastFailure)))
+	}
+	if trace.Timeouts > 0 {
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_TIMEOUT,
+			fmt.Sprintf("%d placements timed out", trace.Timeouts)))
+	}
+	if task.GetPlacementRetryCount() > 0 {
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_BACKOFF,
+			fmt.Sprintf("placement retry cycle %.0f, attempt %.0f, placement "+
+				"timeout %.0fs",
+				task.GetPlacementRetryCount(),
+				task.GetPlacementAttemptCount(),
+				task.GetPlacementTimeoutSeconds())))
+	}
+	if trace.MissedDeadline {
+		reasons = append(reasons, newEligibilityReason(
+			resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_DEADLINE_MISSED,
+			fmt.Sprintf("priority elevated to %d", task.GetPriority())))
+	}
+	return reasons
+}
+
+func newEligibilityReason(
+	reason resmgrsvc.TaskIneligibilityReason,
+	message string,
+) *resmgrsvc.TaskEligibilityReason {
+	return &resmgrsvc.TaskEligibilityReason{
+		Reason:  reason,
+		Message: message,
+	}
+}
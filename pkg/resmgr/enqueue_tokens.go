@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"sync"
+	"time"
+)
+
+// _defaultEnqueueTokenTTL is the duration for which the idempotency token
+// of an EnqueueGangs request is remembered.
+const _defaultEnqueueTokenTTL = 5 * time.Minute
+
+// enqueueTokens remembers the idempotency tokens of EnqueueGangs requests
+// which are in flight or have succeeded for a limited duration, so that
+// retried requests do not enqueue the same gangs again.
+type enqueueTokens struct {
+	sync.Mutex
+
+	ttl time.Duration
+	// token -> time when the token expires
+	tokens map[string]time.Time
+	// time when the expired tokens were last purged
+	lastPurge time.Time
+}
+
+// newEnqueueTokens returns a new enqueueTokens which remembers tokens for
+// the given duration. The default duration is used if ttl is not positive.
+func newEnqueueTokens(ttl time.Duration) *enqueueTokens {
+	if ttl <= 0 {
+		ttl = _defaultEnqueueTokenTTL
+	}
+	return &enqueueTokens{
+		ttl:       ttl,
+		tokens:    make(map[string]time.Time),
+		lastPurge: time.Now(),
+	}
+}
+
+// Reserve remembers the token before its request is processed. It returns
+// false if the token is already reserved and has not expired yet, in which
+// case the request is a retry of a request in flight or which succeeded.
+// Expired tokens are purged at most once per ttl.
+func (e *enqueueTokens) Reserve(token string) bool {
+	e.Lock()
+	defer e.Unlock()
+
+	now := time.Now()
+	if expiry, ok := e.tokens[token]; ok && now.Before(expiry) {
+		return false
+	}
+
+	if now.Sub(e.lastPurge) >= e.ttl {
+		for t, expiry := range e.tokens {
+			if !now.Before(expiry) {
+				delete(e.tokens, t)
+			}
+		}
+		e.lastPurge = now
+	}
+	e.tokens[token] = now.Add(e.ttl)
+	return true
+}
+
+// Release forgets the token of a request which failed, so that it can be
+// retried.
+func (e *enqueueTokens) Release(token string) {
+	e.Lock()
+	defer e.Unlock()
+
+	delete(e.tokens, token)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnqueueTokens(t *testing.T) {
+	tokens := newEnqueueTokens(0)
+	assert.Equal(t, _defaultEnqueueTokenTTL, tokens.ttl)
+
+	assert.True(t, tokens.Reserve("token1"))
+	assert.False(t, tokens.Reserve("token1"))
+	assert.True(t, tokens.Reserve("token2"))
+
+	// a released token can be reserved again
+	tokens.Release("token1")
+	assert.True(t, tokens.Reserve("token1"))
+}
+
+func TestEnqueueTokensExpiry(t *testing.T) {
+	tokens := newEnqueueTokens(10 * time.Millisecond)
+	assert.True(t, tokens.Reserve("token1"))
+	assert.False(t, tokens.Reserve("token1"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	// reserving a token purges the expired ones
+	assert.True(t, tokens.Reserve("token2"))
+	assert.Len(t, tokens.tokens, 1)
+	assert.True(t, tokens.Reserve("token1"))
+}
@@ -49,6 +49,13 @@ type Calculator struct {
 	resPoolTree respool.Tree
 	// calculationPeriod defines how often to calculate the entitlements
 	calculationPeriod time.Duration
+	// rebalancePeriod defines how often to check if the entitlements
+	// need to be rebalanced before the next calculation, zero disables
+	// the rebalancing
+	rebalancePeriod time.Duration
+	// demand of the leaf resource pools at the last calculation, keyed
+	// by resource pool ID
+	calculatedDemands map[string]*scalar.Resources
 	// chan to stop the calculation
 	stopChan chan struct{}
 	// capMgr will fetch total and slack capacity from the mesos
@@ -71,6 +78,7 @@ type Calculator struct {
 // NewCalculator initializes the entitlement Calculator
 func NewCalculator(
 	calculationPeriod time.Duration,
+	rebalancePeriod time.Duration,
 	parent tally.Scope,
 	dispatcher *yarpc.Dispatcher,
 	tree respool.Tree,
@@ -81,6 +89,8 @@ func NewCalculator(
 		resPoolTree:          tree,
 		runningState:         res_common.RunningStateNotStarted,
 		calculationPeriod:    calculationPeriod,
+		rebalancePeriod:      rebalancePeriod,
+		calculatedDemands:    make(map[string]*scalar.Resources),
 		stopChan:             make(chan struct{}, 1),
 		capMgr:               getCapacityManager(hmApiVersion, dispatcher),
 		clusterCapacity:      make(map[string]float64),
@@ -113,18 +123,21 @@ func (c *Calculator) Start() error {
 
 		ticker := time.NewTicker(c.calculationPeriod)
 		defer ticker.Stop()
+		var rebalance <-chan time.Time
+		if c.rebalancePeriod > 0 {
+			rebalanceTicker := time.NewTicker(c.rebalancePeriod)
+			defer rebalanceTicker.Stop()
+			rebalance = rebalanceTicker.C
+		}
 		for {
 			if err := c.calculateEntitlement(context.Background()); err != nil {
 				c.metrics.calculationFailed.Inc(1)
 				log.WithError(err)
 			}
 
-			select {
-			case <-c.stopChan:
+			if !c.waitForCalculation(ticker.C, rebalance) {
 				log.Info("Exiting Entitlement Calculator")
 				return
-			case <-ticker.C:
-			case <-c.resPoolTree.UpdatedChannel():
 			}
 		}
 	}()
@@ -163,10 +176,13 @@ func (c *Calculator) calculateEntitlement(ctx context.Context) error {
 	}
 	// Invoking the demand calculation
 	rootResPool.CalculateDemand()
+	c.recordCalculatedDemands()
 	// Invoking the slack demand calculation
 	rootResPool.CalculateSlackDemand()
 	// Invoking the Allocation calculation
 	rootResPool.CalculateTotalAllocatedResources()
+	// Updating the capacity reservations based on the allocation
+	rootResPool.UpdateCapacityReservations(time.Now())
 	// Calculate Total Entitlement for non-revocable resources root respool's children
 	c.setEntitlementForChildren(rootResPool)
 	// Calculate entitlement for revocable resources and
@@ -189,7 +205,7 @@ func (c *Calculator) getChildShare(resp respool.ResPool, kind string) float64 {
 	totalShare := float64(0)
 	for e := children.Front(); e != nil; e = e.Next() {
 		n := e.Value.(respool.ResPool)
-		totalShare += n.Resources()[kind].Share
+		totalShare += n.Resources()[kind].GetShare()
 	}
 	return totalShare
 }
@@ -256,7 +272,6 @@ func (c *Calculator) updateClusterCapacity(
 				Limit:       c.clusterCapacity[common.MEMORY],
 			},
 		}
-		rootResourcePoolConfig.Resources = rootres
 	} else {
 		// update the reservation and limit to the cluster capacity
 		for _, resource := range rootres {
@@ -267,14 +282,27 @@ func (c *Calculator) updateClusterCapacity(
 		}
 	}
 
-	rootResPool.SetResourcePoolConfig(rootResourcePoolConfig)
-	rootResPool.SetEntitlement(
-		&scalar.Resources{
-			CPU:    c.clusterCapacity[common.CPU],
-			MEMORY: c.clusterCapacity[common.MEMORY],
-			DISK:   c.clusterCapacity[common.DISK],
-			GPU:    c.clusterCapacity[common.GPU],
+	// the custom resources of the cluster are added to the root resource
+	// pool, so that they can be configured in its children
+	capacity := toResources(c.clusterCapacity)
+	configured := make(map[string]bool)
+	for _, resource := range rootres {
+		configured[resource.Kind] = true
+	}
+	for _, kind := range capacity.CustomKinds() {
+		if configured[kind] {
+			continue
+		}
+		rootres = append(rootres, &pb_res.ResourceConfig{
+			Kind:        kind,
+			Reservation: capacity.Get(kind),
+			Limit:       capacity.Get(kind),
 		})
+	}
+	rootResourcePoolConfig.Resources = rootres
+
+	rootResPool.SetResourcePoolConfig(rootResourcePoolConfig)
+	rootResPool.SetEntitlement(capacity)
 	rootResPool.SetSlackEntitlement(
 		&scalar.Resources{
 			CPU: c.clusterSlackCapacity[common.CPU],
@@ -283,6 +311,17 @@ func (c *Calculator) updateClusterCapacity(
 	return nil
 }
 
+// toResources converts the capacity of each kind of resource to
+// scalar.Resources, the kinds other than cpu, memory, disk and gpu are
+// custom resources.
+func toResources(capacity map[string]float64) *scalar.Resources {
+	resources := &scalar.Resources{}
+	for kind, value := range capacity {
+		resources.Set(kind, value)
+	}
+	return resources
+}
+
 // Stop stops Entitlement process
 func (c *Calculator) Stop() error {
 	c.lock.Lock()
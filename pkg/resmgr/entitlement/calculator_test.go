@@ -15,6 +15,7 @@
 package entitlement
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"math"
@@ -33,6 +34,7 @@ import (
 	"github.com/uber/peloton/pkg/common/rpc"
 	res_common "github.com/uber/peloton/pkg/resmgr/common"
 	"github.com/uber/peloton/pkg/resmgr/respool"
+	respool_mocks "github.com/uber/peloton/pkg/resmgr/respool/mocks"
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 	"github.com/uber/peloton/pkg/resmgr/tasktestutil"
 	store_mocks "github.com/uber/peloton/pkg/storage/mocks"
@@ -101,6 +103,9 @@ func (s *EntitlementCalculatorTestSuite) TestPeriodicCalculationWhenStarted() {
 	i := 0
 
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	mockHostMgr.EXPECT().
 		ClusterCapacity(
 			gomock.Any(),
@@ -320,6 +325,9 @@ func (s *EntitlementCalculatorTestSuite) getStaticResPools() map[string]*pb_resp
 func (s *EntitlementCalculatorTestSuite) TestEntitlement() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().
 			ClusterCapacity(
@@ -421,6 +429,9 @@ func (s *EntitlementCalculatorTestSuite) TestEntitlement() {
 func (s *EntitlementCalculatorTestSuite) TestEntitlementForSlackResources() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().
 			ClusterCapacity(
@@ -559,6 +570,9 @@ func (s *EntitlementCalculatorTestSuite) TestEntitlementForSlackResources() {
 func (s *EntitlementCalculatorTestSuite) TestZeroSlackEntitlement() {
 	// No demand or allocation from revocable tasks should yeild zero slack entitlement
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().
 			ClusterCapacity(
@@ -613,6 +627,9 @@ func (s *EntitlementCalculatorTestSuite) TestZeroSlackEntitlement() {
 func (s *EntitlementCalculatorTestSuite) TestSlackEntitlementReduces() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().
 			ClusterCapacity(
@@ -710,6 +727,9 @@ func (s *EntitlementCalculatorTestSuite) TestSlackEntitlementReduces() {
 func (s *EntitlementCalculatorTestSuite) TestUpdateCapacity() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().ClusterCapacity(gomock.Any(), gomock.Any()).
 			Return(&hostsvc.ClusterCapacityResponse{
@@ -781,6 +801,9 @@ func (s *EntitlementCalculatorTestSuite) TestUpdateCapacity() {
 func (s *EntitlementCalculatorTestSuite) TestUpdateCapacityWithHostPool() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 
 	mockHostMgr.EXPECT().ClusterCapacity(gomock.Any(), gomock.Any()).
 		Return(&hostsvc.ClusterCapacityResponse{
@@ -847,6 +870,9 @@ func (s *EntitlementCalculatorTestSuite) TestUpdateCapacityWithHostPool() {
 func (s *EntitlementCalculatorTestSuite) TestEntitlementWithMoreDemand() {
 	// Mock LaunchTasks call.
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	gomock.InOrder(
 		mockHostMgr.EXPECT().
 			ClusterCapacity(
@@ -947,6 +973,7 @@ func (s *EntitlementCalculatorTestSuite) TestNewCalculator() {
 
 	calc := NewCalculator(
 		10*time.Millisecond,
+		time.Millisecond,
 		tally.NoopScope,
 		dispatcher,
 		s.resTree,
@@ -956,6 +983,7 @@ func (s *EntitlementCalculatorTestSuite) TestNewCalculator() {
 	s.NotNil(calc)
 	calc = NewCalculator(
 		10*time.Millisecond,
+		0,
 		tally.NoopScope,
 		dispatcher,
 		s.resTree,
@@ -970,6 +998,9 @@ func (s *EntitlementCalculatorTestSuite) TestStartCalculatorMultipleTimes() {
 	// multiple times it will not start the other one if
 	// previous is running
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	mockHostMgr.EXPECT().
 		ClusterCapacity(
 			gomock.Any(),
@@ -989,6 +1020,9 @@ func (s *EntitlementCalculatorTestSuite) TestStartCalculatorMultipleTimes() {
 func (s *EntitlementCalculatorTestSuite) TestUpdateCapacityError() {
 	// If hostmgr returns error, checking Entitlement fails
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	mockHostMgr.EXPECT().ClusterCapacity(gomock.Any(), gomock.Any()).
 		Return(&hostsvc.ClusterCapacityResponse{
 			PhysicalResources: nil,
@@ -1006,6 +1040,9 @@ func (s *EntitlementCalculatorTestSuite) TestStaticRespoolsEntitlement() {
 	// Building Local Tree for this test suite
 	mockCtrl := gomock.NewController(s.T())
 	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
 	mockResPoolOps := objectmocks.NewMockResPoolOps(s.mockCtrl)
 	gomock.InOrder(
 		mockResPoolOps.EXPECT().
@@ -1085,6 +1122,245 @@ func (s *EntitlementCalculatorTestSuite) TestStaticRespoolsEntitlement() {
 	s.initRespoolTree()
 }
 
+// TestBorrowLimit tests that the entitlement of a resource pool is capped
+// by its borrow limit
+func (s *EntitlementCalculatorTestSuite) TestBorrowLimit() {
+	newResPool := func(borrowLimit *pb_respool.BorrowLimit) respool.ResPool {
+		resPool, err := respool.NewRespool(
+			tally.NoopScope,
+			"respool-borrow",
+			nil,
+			&pb_respool.ResourcePoolConfig{
+				Name:        "respool-borrow",
+				Resources:   s.getResourceConfig(),
+				Policy:      pb_respool.SchedulingPolicy_PriorityFIFO,
+				BorrowLimit: borrowLimit,
+			},
+			res_common.PreemptionConfig{Enabled: false})
+		s.NoError(err)
+		return resPool
+	}
+
+	// without borrow limit the entitlement is capped by the limit
+	resPool := newResPool(nil)
+	s.Equal(float64(1000), getMaxEntitlement(resPool, common.CPU))
+	s.Equal(float64(4), getMaxEntitlement(resPool, common.GPU))
+
+	// the borrow limit caps the entitlement above the reservation
+	resPool = newResPool(&pb_respool.BorrowLimit{MaxPercent: 50})
+	s.Equal(float64(15), getMaxEntitlement(resPool, common.CPU))
+	s.Equal(float64(150), getMaxEntitlement(resPool, common.MEMORY))
+	s.Equal(float64(1000), getMaxEntitlement(resPool, common.DISK))
+
+	resPool.AddToDemand(&scalar.Resources{
+		CPU:    40,
+		MEMORY: 120,
+		DISK:   100,
+	})
+	demands := make(map[string]*scalar.Resources)
+	s.calculator.calculateDemandForRespool(resPool, demands)
+	s.Equal(float64(15), demands[resPool.ID()].Get(common.CPU))
+	s.Equal(float64(120), demands[resPool.ID()].Get(common.MEMORY))
+	s.Equal(float64(100), demands[resPool.ID()].Get(common.DISK))
+
+	// a zero borrow limit prevents borrowing
+	resPool = newResPool(&pb_respool.BorrowLimit{MaxPercent: 0})
+	s.Equal(float64(10), getMaxEntitlement(resPool, common.CPU))
+}
+
+// TestIsRebalanceNeeded tests that a rebalance is needed once the demand
+// of a resource pool lending its reservation rises
+func (s *EntitlementCalculatorTestSuite) TestIsRebalanceNeeded() {
+	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
+	mockHostMgr.EXPECT().
+		ClusterCapacity(
+			gomock.Any(),
+			gomock.Any()).
+		Return(&hostsvc.ClusterCapacityResponse{
+			PhysicalResources:      s.createClusterCapacity(),
+			PhysicalSlackResources: s.createSlackClusterCapacity(),
+		}, nil).
+		AnyTimes()
+	s.calculator.capMgr = &v0CapacityManager{
+		hostManagerV0: mockHostMgr,
+	}
+
+	resPool11, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool11"})
+	s.NoError(err)
+	resPool11.AddToDemand(&scalar.Resources{
+		CPU:    20,
+		MEMORY: 200,
+		DISK:   2000,
+	})
+	s.NoError(s.calculator.calculateEntitlement(context.Background()))
+	s.False(s.calculator.isRebalanceNeeded())
+
+	// respool12 lends its disk reservation to respool11, and needs it
+	// back once its demand rises
+	resPool12, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool12"})
+	s.NoError(err)
+	s.Equal(float64(0), resPool12.GetEntitlement().Get(common.DISK))
+	resPool12.AddToDemand(&scalar.Resources{DISK: 500})
+	s.True(s.calculator.isRebalanceNeeded())
+
+	// the demand is accounted for by the next calculation
+	s.NoError(s.calculator.calculateEntitlement(context.Background()))
+	s.False(s.calculator.isRebalanceNeeded())
+}
+
+// TestWaitForCalculation tests waiting for the next calculation
+func (s *EntitlementCalculatorTestSuite) TestWaitForCalculation() {
+	tick := make(chan time.Time, 1)
+	rebalance := make(chan time.Time)
+
+	tick <- time.Now()
+	s.True(s.calculator.waitForCalculation(tick, rebalance))
+
+	s.calculator.stopChan <- struct{}{}
+	s.False(s.calculator.waitForCalculation(tick, rebalance))
+
+	// no calculation is due if no rebalance is needed, the calculator
+	// keeps waiting until it is stopped
+	done := make(chan bool)
+	go func() {
+		done <- s.calculator.waitForCalculation(tick, rebalance)
+	}()
+	rebalance <- time.Now()
+	s.calculator.stopChan <- struct{}{}
+	s.False(<-done)
+}
+
+// TestAssignReservedGangDemand tests that the free resources are assigned
+// to the resource pools holding capacity reservations for large gangs
+// ahead of the distribution based on share
+func (s *EntitlementCalculatorTestSuite) TestAssignReservedGangDemand() {
+	parent := respool_mocks.NewMockResPool(s.mockCtrl)
+	reserving := respool_mocks.NewMockResPool(s.mockCtrl)
+	other := respool_mocks.NewMockResPool(s.mockCtrl)
+
+	children := list.New()
+	children.PushBack(reserving)
+	children.PushBack(other)
+	parent.EXPECT().Children().Return(children)
+
+	reserving.EXPECT().ID().Return("reserving").AnyTimes()
+	reserving.EXPECT().Name().Return("reserving").AnyTimes()
+	reserving.EXPECT().GetReservedGangDemand().Return(&scalar.Resources{
+		CPU:    30,
+		MEMORY: 300,
+	})
+	other.EXPECT().ID().Return("other").AnyTimes()
+	other.EXPECT().Name().Return("other").AnyTimes()
+	other.EXPECT().GetReservedGangDemand().Return(&scalar.Resources{})
+
+	demands := map[string]*scalar.Resources{
+		"reserving": {CPU: 40, MEMORY: 100},
+		"other":     {CPU: 40, MEMORY: 100},
+	}
+	assignments := map[string]*scalar.Resources{
+		"reserving": {CPU: 10, MEMORY: 100},
+		"other":     {CPU: 10, MEMORY: 100},
+	}
+	entitlement := &scalar.Resources{CPU: 20, MEMORY: 500}
+
+	s.calculator.assignReservedGangDemand(
+		parent, demands, entitlement, assignments)
+
+	// the gang demand is capped by the free resources and the demand
+	// of the pool
+	s.Equal(float64(30), assignments["reserving"].Get(common.CPU))
+	s.Equal(float64(200), assignments["reserving"].Get(common.MEMORY))
+	s.Equal(float64(20), demands["reserving"].Get(common.CPU))
+	s.Equal(float64(0), demands["reserving"].Get(common.MEMORY))
+	s.Equal(float64(0), entitlement.Get(common.CPU))
+	s.Equal(float64(400), entitlement.Get(common.MEMORY))
+
+	s.Equal(float64(10), assignments["other"].Get(common.CPU))
+	s.Equal(float64(40), demands["other"].Get(common.CPU))
+}
+
+// TestCustomResourceEntitlement tests that the custom resources of the
+// cluster are added to the root resource pool and distributed to the
+// resource pools which configure them
+func (s *EntitlementCalculatorTestSuite) TestCustomResourceEntitlement() {
+	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().GetHostResources(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil).
+		AnyTimes()
+	mockHostMgr.EXPECT().
+		ClusterCapacity(
+			gomock.Any(),
+			gomock.Any()).
+		Return(&hostsvc.ClusterCapacityResponse{
+			PhysicalResources: append(s.createClusterCapacity(),
+				&hostsvc.Resource{
+					Kind:     "fpgas",
+					Capacity: 8,
+				}),
+			PhysicalSlackResources: s.createSlackClusterCapacity(),
+		}, nil).
+		AnyTimes()
+	s.calculator.capMgr = &v0CapacityManager{
+		hostManagerV0: mockHostMgr,
+	}
+
+	withFPGAs := func(reservation, limit float64) []*pb_respool.ResourceConfig {
+		return append(s.getResourceConfig(), &pb_respool.ResourceConfig{
+			Share:       1,
+			Kind:        "fpgas",
+			Reservation: reservation,
+			Limit:       limit,
+		})
+	}
+	s.NoError(s.resTree.Upsert(
+		&peloton.ResourcePoolID{Value: "respool1"},
+		&pb_respool.ResourcePoolConfig{
+			Name:      "respool1",
+			Parent:    &peloton.ResourcePoolID{Value: "root"},
+			Resources: withFPGAs(2, 8),
+			Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		}))
+	s.NoError(s.resTree.Upsert(
+		&peloton.ResourcePoolID{Value: "respool11"},
+		&pb_respool.ResourcePoolConfig{
+			Name:      "respool11",
+			Parent:    &peloton.ResourcePoolID{Value: "respool1"},
+			Resources: withFPGAs(2, 6),
+			Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		}))
+
+	getResPool := func(id string) respool.ResPool {
+		resPool, err := s.resTree.Get(&peloton.ResourcePoolID{Value: id})
+		s.NoError(err)
+		return resPool
+	}
+	getResPool("respool11").AddToDemand(&scalar.Resources{
+		Custom: map[string]float64{"fpgas": 5},
+	})
+	// the demand of a custom resource which is not configured in the
+	// resource pool is capped to zero
+	getResPool("respool21").AddToDemand(&scalar.Resources{
+		Custom: map[string]float64{"fpgas": 5},
+	})
+
+	s.NoError(s.calculator.calculateEntitlement(context.Background()))
+
+	root := getResPool("root")
+	s.Equal(float64(8), root.Resources()["fpgas"].GetLimit())
+	s.Equal(float64(8), root.GetEntitlement().Get("fpgas"))
+
+	// the pools configuring the custom resource get the demand first and
+	// then the unclaimed resources capped by their limit
+	s.Equal(float64(8), getResPool("respool1").GetEntitlement().Get("fpgas"))
+	s.Equal(float64(6), getResPool("respool11").GetEntitlement().Get("fpgas"))
+	s.Equal(float64(0), getResPool("respool12").GetEntitlement().Get("fpgas"))
+	s.Equal(float64(0), getResPool("respool2").GetEntitlement().Get("fpgas"))
+	s.Equal(float64(0), getResPool("respool21").GetEntitlement().Get("fpgas"))
+}
+
 // createClusterCapacity returns the cluster capacity of the cluster
 func (s *EntitlementCalculatorTestSuite) createClusterCapacity() []*hostsvc.Resource {
 	return []*hostsvc.Resource{
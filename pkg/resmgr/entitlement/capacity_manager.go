@@ -25,6 +25,7 @@ import (
 	"github.com/uber/peloton/pkg/common/api"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc"
 	"go.uber.org/yarpc/yarpcerrors"
 )
@@ -176,9 +177,50 @@ func (c *v0CapacityManager) GetCapacity(
 		slack[res.Kind] = res.Capacity
 	}
 
+	// The quota of Peloton can exceed the capacity of the hosts actually
+	// registered, so cap the cluster capacity by the capacity of the hosts.
+	hostsCapacity, err := c.getHostsCapacity(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed to get capacity of hosts")
+		return total, slack, nil
+	}
+	for kind, capacity := range hostsCapacity {
+		if total[kind] > capacity {
+			total[kind] = capacity
+		}
+	}
+
 	return total, slack, nil
 }
 
+// getHostsCapacity returns the total physical capacity of the registered
+// hosts, or nil if no hosts are registered.
+func (c *v0CapacityManager) getHostsCapacity(
+	ctx context.Context,
+) (map[string]float64, error) {
+	response, err := c.hostManagerV0.GetHostResources(
+		ctx,
+		&v0_hostsvc.GetHostResourcesRequest{})
+	if err != nil {
+		return nil, errors.Wrap(err, "v0 GetHostResources failed: ")
+	}
+	if respErr := response.GetError(); respErr != nil {
+		return nil,
+			fmt.Errorf("v0 GetHostResources failed: %s", respErr.String())
+	}
+	if len(response.GetHosts()) == 0 {
+		return nil, nil
+	}
+
+	capacity := make(map[string]float64)
+	for _, host := range response.GetHosts() {
+		for _, res := range host.GetPhysicalCapacity() {
+			capacity[res.GetKind()] += res.GetCapacity()
+		}
+	}
+	return capacity, nil
+}
+
 // GetHostPoolCapacity implements GetHostPoolCapacity method for
 // v0 capacity manager.
 func (c *v0CapacityManager) GetHostPoolCapacity(ctx context.Context) (
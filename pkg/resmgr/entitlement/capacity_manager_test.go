@@ -23,6 +23,7 @@ import (
 	host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc"
 	v1_host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/v1alpha/svc/mocks"
+	"github.com/uber/peloton/pkg/common"
 
 	"github.com/golang/mock/gomock"
 )
@@ -37,7 +38,13 @@ func (s *EntitlementCalculatorTestSuite) TestV0CapacityManager() {
 		Return(&hostsvc.ClusterCapacityResponse{
 			PhysicalResources:      s.createClusterCapacity(),
 			PhysicalSlackResources: s.createSlackClusterCapacity(),
-		}, nil)
+		}, nil).
+		Times(3)
+	mockHostMgr.EXPECT().
+		GetHostResources(
+			gomock.Any(),
+			gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{}, nil)
 
 	capMgr := &v0CapacityManager{
 		hostManagerV0: mockHostMgr,
@@ -47,6 +54,45 @@ func (s *EntitlementCalculatorTestSuite) TestV0CapacityManager() {
 	s.NoError(err)
 	s.Len(total, 4)
 	s.Len(slack, 4)
+	s.EqualValues(100, total[common.CPU])
+
+	// Capacity is capped by the capacity of the registered hosts.
+	mockHostMgr.EXPECT().
+		GetHostResources(
+			gomock.Any(),
+			gomock.Any()).
+		Return(&hostsvc.GetHostResourcesResponse{
+			Hosts: []*hostsvc.HostResources{
+				{
+					Hostname: "h1",
+					PhysicalCapacity: []*hostsvc.Resource{
+						{Kind: common.CPU, Capacity: 30},
+						{Kind: common.MEMORY, Capacity: 2000},
+					},
+				},
+				{
+					Hostname: "h2",
+					PhysicalCapacity: []*hostsvc.Resource{
+						{Kind: common.CPU, Capacity: 40},
+					},
+				},
+			},
+		}, nil)
+	total, _, err = capMgr.GetCapacity(context.Background())
+	s.NoError(err)
+	s.EqualValues(70, total[common.CPU])
+	s.EqualValues(1000, total[common.MEMORY])
+	s.EqualValues(6000, total[common.DISK])
+
+	// Capacity is not capped if the capacity of hosts is not available.
+	mockHostMgr.EXPECT().
+		GetHostResources(
+			gomock.Any(),
+			gomock.Any()).
+		Return(nil, fmt.Errorf("v0 get host resources failed"))
+	total, _, err = capMgr.GetCapacity(context.Background())
+	s.NoError(err)
+	s.EqualValues(100, total[common.CPU])
 
 	mockHostMgr.EXPECT().
 		ClusterCapacity(
@@ -25,6 +25,9 @@ type metrics struct {
 	calculationFailed tally.Counter
 	// Tracks the duration of the calculation cycle.
 	calculationDuration tally.Timer
+	// Tracks the calculations triggered ahead of the period to reclaim
+	// the reservation lent by the resource pools.
+	rebalanceTriggered tally.Counter
 }
 
 // newMetrics returns a new instance of task.metrics.
@@ -38,5 +41,7 @@ func newMetrics(scope tally.Scope) *metrics {
 			"calculation_failed"),
 		calculationDuration: cScope.Timer(
 			"calculation_duration"),
+		rebalanceTriggered: cScope.Counter(
+			"rebalance_triggered"),
 	}
 }
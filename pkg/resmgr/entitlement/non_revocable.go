@@ -20,7 +20,6 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	pb_res "github.com/uber/peloton/.gen/peloton/api/v0/respool"
-	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/resmgr/respool"
 	"github.com/uber/peloton/pkg/resmgr/scalar"
@@ -60,6 +59,11 @@ func (c *Calculator) setEntitlementForChildren(
 		entitlement,
 		assignments,
 		totalShare)
+	c.assignReservedGangDemand(
+		resp,
+		demands,
+		entitlement,
+		assignments)
 
 	// This is second phase for distributing remaining resources
 	c.distributeRemainingResources(
@@ -115,6 +119,8 @@ func (c *Calculator) calculateAssignmentsFromReservation(
 	// of the kind of resources which demand is more then the resrevation
 	// As we can ignore the other whose demands are reached as they dont
 	// need to get the fare share
+	// The resources guaranteed by active capacity reservations are always
+	// assigned on top of the reservation, irrespective of the demand.
 	childs := resp.Children()
 	cloneEntitlement := entitlement.Clone()
 	for e := childs.Front(); e != nil; e = e.Next() {
@@ -122,6 +128,8 @@ func (c *Calculator) calculateAssignmentsFromReservation(
 		n := e.Value.(respool.ResPool)
 
 		resConfigMap := n.Resources()
+		capacityReserved := n.GetCapacityReserved()
+		disableLending := n.ResourcePoolConfig().GetDisableLending()
 		c.calculateDemandForRespool(n, demands)
 
 		limitedDemand := demands[n.ID()].Clone()
@@ -134,14 +142,19 @@ func (c *Calculator) calculateAssignmentsFromReservation(
 			// entitlement will always be greater than equal to
 			// reservation irrespective of the demand. Otherwise
 			// Based on the demand assignment := min(demand,reservation)
-			if cfg.Type == pb_res.ReservationType_STATIC {
+			// The same applies if the resource pool doesn't lend its
+			// unused reservation to its siblings.
+			if cfg.Type == pb_res.ReservationType_STATIC || disableLending {
 				assignment.Set(kind, cfg.Reservation)
 			} else {
 				assignment.Set(kind, math.Min(demand.Get(kind), cfg.Reservation))
 			}
-			if demand.Get(kind) > cfg.Reservation {
+			assignment.Set(kind,
+				assignment.Get(kind)+capacityReserved.Get(kind))
+			reservation := cfg.Reservation + capacityReserved.Get(kind)
+			if demand.Get(kind) > reservation {
 				totalShare[kind] += cfg.Share
-				demand.Set(kind, demand.Get(kind)-cfg.Reservation)
+				demand.Set(kind, demand.Get(kind)-reservation)
 			} else {
 				demand.Set(kind, 0)
 			}
@@ -164,6 +177,43 @@ func (c *Calculator) calculateAssignmentsFromReservation(
 	entitlement.Copy(cloneEntitlement)
 }
 
+// assignReservedGangDemand assigns the free resources to the resource
+// pools holding capacity reservations for large gangs, up to the resources
+// of the gangs, ahead of the distribution based on share. The resources the
+// pools lent to their siblings are then reclaimed by preemption, which
+// drains the smaller placements back into the resource pools they belong
+// to while the capacity accumulates for the gangs.
+func (c *Calculator) assignReservedGangDemand(
+	resp respool.ResPool,
+	demands map[string]*scalar.Resources,
+	entitlement *scalar.Resources,
+	assignments map[string]*scalar.Resources) {
+	childs := resp.Children()
+	for e := childs.Front(); e != nil; e = e.Next() {
+		n := e.Value.(respool.ResPool)
+		gangDemand := n.GetReservedGangDemand()
+		for _, kind := range entitlement.Kinds() {
+			// the demand left is the demand over the reservation of the
+			// pool, capped by its max entitlement
+			value := math.Min(gangDemand.Get(kind), demands[n.ID()].Get(kind))
+			value = math.Min(value, entitlement.Get(kind))
+			if value < util.ResourceEpsilon {
+				continue
+			}
+
+			demands[n.ID()].Set(kind, demands[n.ID()].Get(kind)-value)
+			entitlement.Set(kind, entitlement.Get(kind)-value)
+			assignments[n.ID()].Set(kind, assignments[n.ID()].Get(kind)+value)
+		}
+
+		log.WithFields(log.Fields{
+			"respool_name":         n.Name(),
+			"reserved_gang_demand": gangDemand.String(),
+			"assignment":           assignments[n.ID()].String(),
+		}).Debug("Reserved gang demand assigned for respool")
+	}
+}
+
 // calculateDemandForRespool calculates the demand based on number of
 // tasks waiting in the queue as well as current allocation
 // for non-revocable and revocable tasks.
@@ -188,10 +238,12 @@ func (c *Calculator) calculateDemandForRespool(
 	// Caping the demand with Limit for resource pool
 	// If demand is less then limit then we use demand for
 	// entitlement calculation otherwise we use limit as demand
-	// to cap the allocation till limit.
+	// to cap the allocation till limit. The limit is lowered by
+	// the borrow limit of the resource pool, if any. The demand of the
+	// custom resources not configured in the pool is capped to zero.
 	limitedDemand := demand
-	for kind, res := range resConfig {
-		limitedDemand.Set(kind, math.Min(demand.Get(kind), res.GetLimit()))
+	for _, kind := range demand.Kinds() {
+		limitedDemand.Set(kind, math.Min(demand.Get(kind), getMaxEntitlement(n, kind)))
 	}
 
 	log.WithFields(log.Fields{
@@ -215,12 +267,8 @@ func (c *Calculator) distributeRemainingResources(
 	assignments map[string]*scalar.Resources,
 	totalShare map[string]float64) {
 	childs := resp.Children()
-	for _, kind := range []string{
-		common.CPU,
-		common.GPU,
-		common.MEMORY,
-		common.DISK} {
-		remaining := *entitlement
+	for _, kind := range entitlement.Kinds() {
+		remaining := entitlement.Clone()
 		log.WithFields(log.Fields{
 			"kind":       kind,
 			"remianing ": remaining.Get(kind),
@@ -251,7 +299,7 @@ func (c *Calculator) distributeRemainingResources(
 					continue
 				}
 
-				value := float64(n.Resources()[kind].Share * entitlement.Get(kind))
+				value := float64(n.Resources()[kind].GetShare() * entitlement.Get(kind))
 				value = float64(value / totalShare[kind])
 				log.WithField("value", value).Debug(" value to evaluate ")
 
@@ -291,7 +339,7 @@ func (c *Calculator) distributeRemainingResources(
 					"demand_not_satisfied":           demands[n.ID()],
 				}).Info("Second pass completed for respool")
 			}
-			*entitlement = remaining
+			entitlement.Copy(remaining)
 		}
 	}
 }
@@ -307,11 +355,7 @@ func (c *Calculator) distributeUnclaimedResources(
 	entitlement *scalar.Resources,
 	assignments map[string]*scalar.Resources) {
 	childs := resp.Children()
-	for _, kind := range []string{
-		common.CPU,
-		common.GPU,
-		common.MEMORY,
-		common.DISK} {
+	for _, kind := range entitlement.Kinds() {
 		// Third pass : Now all the demand is been satisfied
 		// we need to distribute the rest of the entitlement
 		// to all the nodes for the anticipation of some work
@@ -320,7 +364,7 @@ func (c *Calculator) distributeUnclaimedResources(
 			totalChildShare := c.getChildShare(resp, kind)
 			for e := childs.Front(); e != nil; e = e.Next() {
 				n := e.Value.(respool.ResPool)
-				nshare := n.Resources()[kind].Share
+				nshare := n.Resources()[kind].GetShare()
 				value := assignments[n.ID()].Get(kind)
 				if nshare > 0 {
 					value += float64(nshare / totalChildShare *
@@ -329,10 +373,10 @@ func (c *Calculator) distributeUnclaimedResources(
 
 				// We need to cap the limit here for free resources
 				// as we can not give more then limit to resource pool
-				if value > n.Resources()[kind].GetLimit() {
+				if maxEntitlement := getMaxEntitlement(n, kind); value > maxEntitlement {
 					assignments[n.ID()].Set(
 						kind,
-						n.Resources()[kind].GetLimit(),
+						maxEntitlement,
 					)
 				} else {
 					assignments[n.ID()].Set(kind, value)
@@ -347,6 +391,22 @@ func (c *Calculator) distributeUnclaimedResources(
 	}
 }
 
+// getMaxEntitlement returns the max entitlement of a resource pool for a
+// kind of resource, which is its limit capped by the reservation plus the
+// resources it can borrow from its siblings as per its borrow limit.
+func getMaxEntitlement(n respool.ResPool, kind string) float64 {
+	cfg := n.Resources()[kind]
+	borrowLimit := n.ResourcePoolConfig().GetBorrowLimit()
+	if borrowLimit == nil {
+		return cfg.GetLimit()
+	}
+
+	maxBorrowed := cfg.GetReservation() * borrowLimit.GetMaxPercent() / 100
+	return math.Min(
+		cfg.GetLimit(),
+		cfg.GetReservation()+n.GetCapacityReserved().Get(kind)+maxBorrowed)
+}
+
 // getNonSlackResourcesRequirement returns the total non-revocable resources
 // allocated + demand (pending for launch) for non-revocable tasks
 func (c *Calculator) getNonSlackResourcesRequirement(
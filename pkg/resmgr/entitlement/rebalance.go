@@ -0,0 +1,105 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entitlement
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The entitlement of the resource pools is rebalanced ahead of the
+// calculation period when a resource pool needs back the reservation it
+// lent to its siblings. The rebalance is a regular entitlement calculation,
+// which lowers the entitlement of the resource pools which borrowed the
+// reservation; the preemptor then reclaims the borrowed resources by
+// preempting the tasks of the borrowers over their entitlement.
+
+// waitForCalculation waits until the next entitlement calculation is due,
+// which is at the next period, when the resource pool tree is updated, or
+// when a rebalance is needed. It returns false if the Calculator is stopped.
+func (c *Calculator) waitForCalculation(
+	tick <-chan time.Time,
+	rebalance <-chan time.Time) bool {
+	for {
+		select {
+		case <-c.stopChan:
+			return false
+		case <-tick:
+			return true
+		case <-c.resPoolTree.UpdatedChannel():
+			return true
+		case <-rebalance:
+			if c.isRebalanceNeeded() {
+				c.metrics.rebalanceTriggered.Inc(1)
+				return true
+			}
+		}
+	}
+}
+
+// isRebalanceNeeded returns true if a leaf resource pool lending part of
+// its reservation to its siblings needs it back, because its demand rose
+// since the last calculation and its allocation and demand are over its
+// entitlement.
+func (c *Calculator) isRebalanceNeeded() bool {
+	nodes := c.resPoolTree.GetAllNodes(true)
+	for e := nodes.Front(); e != nil; e = e.Next() {
+		n := e.Value.(respool.ResPool)
+		entitlement := n.GetEntitlement()
+		capacityReserved := n.GetCapacityReserved()
+		demand := n.GetDemand()
+		calculatedDemand, ok := c.calculatedDemands[n.ID()]
+		if !ok {
+			calculatedDemand = &scalar.Resources{}
+		}
+		requirement := c.getNonSlackResourcesRequirement(n)
+		for kind, cfg := range n.Resources() {
+			lent := cfg.GetReservation() + capacityReserved.Get(kind) -
+				entitlement.Get(kind)
+			if lent < util.ResourceEpsilon {
+				continue
+			}
+			if demand.Get(kind) > calculatedDemand.Get(kind)+util.ResourceEpsilon &&
+				requirement.Get(kind) > entitlement.Get(kind)+util.ResourceEpsilon {
+				log.WithFields(log.Fields{
+					"respool_id":  n.ID(),
+					"kind":        kind,
+					"lent":        lent,
+					"demand":      demand,
+					"entitlement": entitlement,
+				}).Info("resource pool needs back its lent reservation")
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordCalculatedDemands records the demand of the leaf resource pools
+// the entitlement was last calculated for.
+func (c *Calculator) recordCalculatedDemands() {
+	demands := make(map[string]*scalar.Resources)
+	nodes := c.resPoolTree.GetAllNodes(true)
+	for e := nodes.Front(); e != nil; e = e.Next() {
+		n := e.Value.(respool.ResPool)
+		demands[n.ID()] = n.GetDemand()
+	}
+	c.calculatedDemands = demands
+}
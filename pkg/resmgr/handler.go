@@ -91,6 +91,9 @@ type ServiceHandler struct {
 	resPoolTree respool.Tree
 
 	hostmgrClient hostsvc.InternalHostServiceYARPCClient
+
+	// idempotency tokens of recent successful EnqueueGangs requests
+	enqueueTokens *enqueueTokens
 }
 
 // NewServiceHandler initializes the handler for ResourceManagerService
@@ -124,6 +127,7 @@ func NewServiceHandler(
 			_eventStreamBufferSize,
 			parent.SubScope("resmgr")),
 		hostmgrClient: hostmgrClient,
+		enqueueTokens: newEnqueueTokens(conf.EnqueueTokenTTL),
 	}
 
 	d.Register(resmgrsvc.BuildResourceManagerServiceYARPCProcedures(handler))
@@ -175,9 +179,20 @@ func (h *ServiceHandler) EnqueueGangs(
 		}, nil
 	}
 
+	// The request is a retry of a request which is in flight or which
+	// already succeeded
+	token := req.GetIdempotencyToken()
+	if token != "" && !h.enqueueTokens.Reserve(token) {
+		log.WithField("idempotency_token", token).
+			Info("Ignoring duplicate EnqueueGangs request")
+		h.metrics.EnqueueGangsDuplicate.Inc(1)
+		return &resmgrsvc.EnqueueGangsResponse{}, nil
+	}
+
 	// Lookup respool from the resource pool tree
 	resourcePool, err = h.resPoolTree.Get(respoolID)
 	if err != nil {
+		h.releaseEnqueueToken(token)
 		h.metrics.EnqueueGangFail.Inc(1)
 		return &resmgrsvc.EnqueueGangsResponse{
 			Error: &resmgrsvc.EnqueueGangsResponse_Error{
@@ -205,6 +220,7 @@ func (h *ServiceHandler) EnqueueGangs(
 
 	// Even if one gang fails we return as error.
 	if len(failedGangs) > 0 {
+		h.releaseEnqueueToken(token)
 		return &resmgrsvc.EnqueueGangsResponse{
 			Error: &resmgrsvc.EnqueueGangsResponse_Error{
 				Failure: &resmgrsvc.EnqueueGangsFailure{
@@ -218,6 +234,14 @@ func (h *ServiceHandler) EnqueueGangs(
 	return &resmgrsvc.EnqueueGangsResponse{}, nil
 }
 
+// releaseEnqueueToken releases the idempotency token of a failed
+// EnqueueGangs request, so that its retries are not ignored.
+func (h *ServiceHandler) releaseEnqueueToken(token string) {
+	if token != "" {
+		h.enqueueTokens.Release(token)
+	}
+}
+
 // enqueueGang adds the new gangs to pending queue or
 // requeue the gang if the tasks have different mesos
 // taskid.
@@ -229,6 +253,10 @@ func (h *ServiceHandler) enqueueGang(
 	var failed []*resmgrsvc.EnqueueGangsFailure_FailedTask
 	var failedTask *resmgrsvc.EnqueueGangsFailure_FailedTask
 	var err error
+	// addedTasks are the tasks of the gang newly added to the tracker, and
+	// replacedTasks the tracked tasks replaced by a new run of the task
+	var addedTasks []*resmgr.Task
+	var replacedTasks []*rmtask.RMTask
 	failedTasks := make(map[string]bool)
 	for _, task := range gang.GetTasks() {
 		if !(h.isTaskPresent(task)) {
@@ -236,11 +264,18 @@ func (h *ServiceHandler) enqueueGang(
 			// this means its a new task and needs to be
 			// added to tracker
 			failedTask, err = h.addTask(task, respool)
+			if err == nil {
+				addedTasks = append(addedTasks, task)
+			}
 		} else {
 			// This is the already present task,
 			// We need to check if it has same mesos
 			// id or different mesos task id.
+			prevTask := h.rmTracker.GetTask(task.GetId())
 			failedTask, err = h.requeueTask(task, respool)
+			if err == nil {
+				replacedTasks = append(replacedTasks, prevTask)
+			}
 		}
 
 		// If there is any failure we need to add those tasks to
@@ -253,21 +288,27 @@ func (h *ServiceHandler) enqueueGang(
 
 	if len(failed) == 0 {
 		err = h.addingGangToPendingQueue(gang, respool)
-		// if there is error , we need to mark all tasks in gang failed.
-		if err != nil {
-			failed = append(failed, h.markingTasksFailInGang(gang, failedTasks, errFailingGangMemberTask)...)
-			err = errGangNotEnqueued
+		if err == nil {
+			return nil, nil
 		}
-		return failed, err
 	}
 	// we need to fail the other tasks which are not failed
 	// as we have to enqueue whole gang or not
 	// here we are assuming that all the tasks in gang whether
 	// be enqueued or requeued.
 	failed = append(failed, h.markingTasksFailInGang(gang, failedTasks, errFailingGangMemberTask)...)
-	err = errGangNotEnqueued
 
-	return failed, err
+	// Roll back the tracker, so that none of the tasks of a gang which is
+	// not enqueued are left behind, and the runs of the tasks replaced by
+	// the gang are tracked again.
+	for _, task := range addedTasks {
+		h.rmTracker.DeleteTask(task.GetId())
+	}
+	for _, prevTask := range replacedTasks {
+		h.rmTracker.RestoreTask(prevTask)
+	}
+
+	return failed, errGangNotEnqueued
 }
 
 // isTaskPresent checks if the task is present in the tracker, Returns
@@ -276,16 +317,10 @@ func (h *ServiceHandler) isTaskPresent(requeuedTask *resmgr.Task) bool {
 	return h.rmTracker.GetTask(requeuedTask.Id) != nil
 }
 
-// removeGangFromTracker removes the  task from the tracker
-func (h *ServiceHandler) removeGangFromTracker(gang *resmgrsvc.Gang) {
-	for _, task := range gang.Tasks {
-		h.rmTracker.DeleteTask(task.Id)
-	}
-}
-
 // addingGangToPendingQueue transit all tasks of gang to PENDING state
 // and add them to pending queue by that they can be scheduled for
-// next scheduling cycle
+// next scheduling cycle. The caller rolls back the tasks of the gang in
+// the tracker on failure.
 func (h *ServiceHandler) addingGangToPendingQueue(
 	gang *resmgrsvc.Gang,
 	respool respool.ResPool) error {
@@ -298,9 +333,6 @@ func (h *ServiceHandler) addingGangToPendingQueue(
 			if err != nil {
 				log.WithError(err).WithField("task", task.Id.Value).
 					Error("not able to transit task to PENDING")
-
-				// Removing the gang from the tracker if some tasks fail to transit
-				h.removeGangFromTracker(gang)
 				return errGangNotEnqueued
 			}
 		}
@@ -308,8 +340,6 @@ func (h *ServiceHandler) addingGangToPendingQueue(
 
 	// Adding gang to pending queue
 	if err := respool.EnqueueGang(gang); err != nil {
-		// We need to remove gang tasks from tracker
-		h.removeGangFromTracker(gang)
 		return errGangNotEnqueued
 	}
 
@@ -403,6 +433,12 @@ func (h *ServiceHandler) requeueTask(
 
 }
 
+// isTaskPendingAdmission returns true if the task in the given state is
+// not yet admitted into its resource pool
+func isTaskPendingAdmission(state t.TaskState) bool {
+	return state == t.TaskState_INITIALIZED || state == t.TaskState_PENDING
+}
+
 // isTaskInTransitRunning return TRUE if the task state is in
 // RUNNING or LAUNCHED state else it returns FALSE
 func (h *ServiceHandler) isTaskInTransitRunning(state t.TaskState) bool {
@@ -705,7 +741,15 @@ func (h *ServiceHandler) transitTasksInPlacement(
 					WithField("task_id", task.GetPelotonTaskID().GetValue()).
 					Info("Failed to transit tasks in placement")
 				invalidTaskSet[task.GetMesosTaskID().GetValue()] = struct{}{}
+				continue
 			}
+			log.WithFields(log.Fields{
+				"task_id":               task.GetPelotonTaskID().GetValue(),
+				"mesos_task_id":         task.GetMesosTaskID().GetValue(),
+				"launch_correlation_id": rmTask.Task().GetLaunchCorrelationId(),
+				"hostname":              placement.GetHostname(),
+				"new_state":             newState.String(),
+			}).Info("Transited task in placement")
 		}
 	}
 	return h.removeTasksFromPlacements(placement, invalidTaskSet)
@@ -814,11 +858,12 @@ func (h *ServiceHandler) fillTaskEntry(task *rmtask.RMTask,
 ) *resmgrsvc.GetActiveTasksResponse_TaskEntry {
 	rmTaskState := task.GetCurrentState()
 	taskEntry := &resmgrsvc.GetActiveTasksResponse_TaskEntry{
-		TaskID:         task.Task().GetTaskId().GetValue(),
-		TaskState:      rmTaskState.State.String(),
-		Reason:         rmTaskState.Reason,
-		LastUpdateTime: rmTaskState.LastUpdateTime.String(),
-		Hostname:       task.Task().GetHostname(),
+		TaskID:               task.Task().GetTaskId().GetValue(),
+		TaskState:            rmTaskState.State.String(),
+		Reason:               rmTaskState.Reason,
+		LastUpdateTime:       rmTaskState.LastUpdateTime.String(),
+		Hostname:             task.Task().GetHostname(),
+		PlacementRelaxations: task.Task().GetPlacementRelaxations(),
 	}
 	return taskEntry
 }
@@ -1172,6 +1217,38 @@ func (h *ServiceHandler) UpdateTasksState(
 	return &resmgrsvc.UpdateTasksStateResponse{}, nil
 }
 
+// UpdateTaskResources will be called by job manager after resizing a
+// running task in place, so that resource manager accounts the new
+// resources of the task in the allocation of its resource pool.
+func (h *ServiceHandler) UpdateTaskResources(
+	ctx context.Context,
+	req *resmgrsvc.UpdateTaskResourcesRequest,
+) (*resmgrsvc.UpdateTaskResourcesResponse, error) {
+	h.metrics.APIUpdateTaskResources.Inc(1)
+
+	if req.GetResource() == nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"resource is required")
+	}
+
+	if h.rmTracker.GetTask(req.GetTask()) == nil {
+		return nil, status.Errorf(codes.NotFound,
+			"task %s not found", req.GetTask().GetValue())
+	}
+
+	if err := h.rmTracker.UpdateResources(
+		req.GetMesosTaskId().GetValue(),
+		req.GetResource(),
+	); err != nil {
+		log.WithError(err).
+			WithField("task_id", req.GetTask().GetValue()).
+			Error("failed to update task resources")
+		return nil, status.Errorf(codes.FailedPrecondition, err.Error())
+	}
+
+	return &resmgrsvc.UpdateTaskResourcesResponse{}, nil
+}
+
 // GetOrphanTasks returns the list of orphan tasks
 func (h *ServiceHandler) GetOrphanTasks(
 	ctx context.Context,
@@ -1187,6 +1264,150 @@ func (h *ServiceHandler) GetOrphanTasks(
 	}, nil
 }
 
+// GetGangAdmissionStatus returns the admission status of a gang given the
+// IDs of its tasks
+func (h *ServiceHandler) GetGangAdmissionStatus(
+	ctx context.Context,
+	req *resmgrsvc.GetGangAdmissionStatusRequest,
+) (*resmgrsvc.GetGangAdmissionStatusResponse, error) {
+	h.metrics.APIGetGangAdmissionStatus.Inc(1)
+
+	var tasks []*resmgrsvc.GetGangAdmissionStatusResponse_TaskState
+	var pending, admitted int
+	for _, taskID := range req.GetTasks() {
+		state := t.TaskState_UNKNOWN
+		if rmTask := h.rmTracker.GetTask(taskID); rmTask != nil {
+			state = rmTask.GetCurrentState().State
+			if isTaskPendingAdmission(state) {
+				pending++
+			} else {
+				admitted++
+			}
+		}
+		tasks = append(tasks,
+			&resmgrsvc.GetGangAdmissionStatusResponse_TaskState{
+				Task:  taskID,
+				State: state,
+			})
+	}
+
+	var status resmgrsvc.GangAdmissionStatus
+	switch numTasks := len(req.GetTasks()); {
+	case pending+admitted == 0:
+		status = resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_UNKNOWN
+	case pending == numTasks:
+		status = resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_PENDING
+	case admitted == numTasks:
+		status = resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_ADMITTED
+	default:
+		status = resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_PARTIAL
+	}
+
+	return &resmgrsvc.GetGangAdmissionStatusResponse{
+		Status: status,
+		Tasks:  tasks,
+	}, nil
+}
+
+// GetTaskEligibility returns why tasks are not admitted or placed yet
+func (h *ServiceHandler) GetTaskEligibility(
+	ctx context.Context,
+	req *resmgrsvc.GetTaskEligibilityRequest,
+) (*resmgrsvc.GetTaskEligibilityResponse, error) {
+	h.metrics.APIGetTaskEligibility.Inc(1)
+
+	var tasks []*resmgrsvc.GetTaskEligibilityResponse_TaskEligibility
+	for _, taskID := range req.GetTasks() {
+		rmTask := h.rmTracker.GetTask(taskID)
+		if rmTask == nil {
+			tasks = append(tasks,
+				&resmgrsvc.GetTaskEligibilityResponse_TaskEligibility{
+					Task:  taskID,
+					State: t.TaskState_UNKNOWN,
+					Reasons: []*resmgrsvc.TaskEligibilityReason{
+						newEligibilityReason(
+							resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_NOT_FOUND,
+							"task is not known to resource manager"),
+					},
+				})
+			continue
+		}
+		tasks = append(tasks, getTaskEligibility(rmTask))
+	}
+
+	return &resmgrsvc.GetTaskEligibilityResponse{Tasks: tasks}, nil
+}
+
+// GetResourcePoolUsage returns the demand, allocation and preemptions of
+// resource pools sampled over a recent time window
+func (h *ServiceHandler) GetResourcePoolUsage(
+	ctx context.Context,
+	req *resmgrsvc.GetResourcePoolUsageRequest,
+) (*resmgrsvc.GetResourcePoolUsageResponse, error) {
+	h.metrics.APIGetResourcePoolUsage.Inc(1)
+
+	var nodes []respool.ResPool
+	if len(req.GetRespoolIDs()) == 0 {
+		allNodes := h.resPoolTree.GetAllNodes(false)
+		for e := allNodes.Front(); e != nil; e = e.Next() {
+			nodes = append(nodes, e.Value.(respool.ResPool))
+		}
+	}
+	for _, respoolID := range req.GetRespoolIDs() {
+		node, err := h.resPoolTree.Get(respoolID)
+		if err != nil {
+			return &resmgrsvc.GetResourcePoolUsageResponse{},
+				status.Errorf(codes.NotFound,
+					"resource pool ID not found:%s", respoolID.GetValue())
+		}
+		nodes = append(nodes, node)
+	}
+
+	// time zero returns all the retained samples
+	var since time.Time
+	if window := req.GetWindowSeconds(); window > 0 {
+		since = time.Now().UTC().Add(-time.Duration(window) * time.Second)
+	}
+
+	var usages []*resmgrsvc.GetResourcePoolUsageResponse_ResourcePoolUsage
+	for _, node := range nodes {
+		var samples []*resmgrsvc.ResourcePoolUsageSample
+		for _, s := range node.GetUsageHistory(since) {
+			samples = append(samples, &resmgrsvc.ResourcePoolUsageSample{
+				Time:           s.Time.Format(time.RFC3339),
+				Demand:         toResources(s.Demand),
+				Allocation:     toResources(s.Allocation),
+				Entitlement:    toResources(s.Entitlement),
+				PreemptedTasks: uint32(s.PreemptedTasks),
+			})
+		}
+		usages = append(usages,
+			&resmgrsvc.GetResourcePoolUsageResponse_ResourcePoolUsage{
+				RespoolID: &peloton.ResourcePoolID{Value: node.ID()},
+				Path:      node.GetPath(),
+				Samples:   samples,
+			})
+	}
+
+	return &resmgrsvc.GetResourcePoolUsageResponse{
+		Usages: usages,
+	}, nil
+}
+
+// toResources converts scalar resources to the resources of the API
+func toResources(r *scalar.Resources) *resmgrsvc.Resources {
+	resources := map[string]float64{
+		common.CPU:    r.GetCPU(),
+		common.MEMORY: r.GetMem(),
+		common.DISK:   r.GetDisk(),
+		common.GPU:    r.GetGPU(),
+	}
+	for _, kind := range r.CustomKinds() {
+		resources[kind] = r.GetCustom(kind)
+	}
+	return &resmgrsvc.Resources{Resources: resources}
+}
+
 // GetHostsByScores returns a list of batch hosts with lowest host scores
 func (h *ServiceHandler) GetHostsByScores(
 	ctx context.Context,
@@ -116,6 +116,7 @@ func (s *handlerTestSuite) SetupSuite() {
 		tally.NoopScope,
 		s.resTree,
 		1*time.Second,
+		0,
 		s.rmTaskTracker,
 	)
 
@@ -134,6 +135,7 @@ func (s *handlerTestSuite) SetupSuite() {
 		},
 		hostmgrClient: s.mockHostmgrClient,
 		batchScorer:   s.mockBatchScorer,
+		enqueueTokens: newEnqueueTokens(0),
 	}
 	s.handler.eventStreamHandler = eventstream.NewEventStreamHandler(
 		1000,
@@ -212,6 +214,63 @@ func (s *handlerTestSuite) TestEnqueueDequeueGangsOneResPool() {
 	s.assertTasksAdmitted(gangs)
 }
 
+// TestEnqueueGangsIdempotencyToken tests that a retried request with the
+// same idempotency token is acknowledged without enqueuing the gangs again
+func (s *handlerTestSuite) TestEnqueueGangsIdempotencyToken() {
+	gangs := s.pendingGangs()
+	enqReq := &resmgrsvc.EnqueueGangsRequest{
+		ResPool:          &peloton.ResourcePoolID{Value: "respool3"},
+		Gangs:            gangs,
+		IdempotencyToken: "job1:0-4:1:abcd",
+	}
+	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+	node.SetNonSlackEntitlement(s.getEntitlement())
+
+	enqResp, err := s.handler.EnqueueGangs(s.context, enqReq)
+	s.NoError(err)
+	s.Nil(enqResp.GetError())
+	s.assertTasksAdmitted(gangs)
+
+	// retry with the same token succeeds
+	enqResp, err = s.handler.EnqueueGangs(s.context, enqReq)
+	s.NoError(err)
+	s.Nil(enqResp.GetError())
+
+	// the same gangs with a different token are rejected as duplicates
+	enqReq.IdempotencyToken = "job1:0-4:1:efgh"
+	enqResp, err = s.handler.EnqueueGangs(s.context, enqReq)
+	s.NoError(err)
+	s.NotNil(enqResp.GetError().GetFailure())
+
+	// the token of the failed request is released
+	s.True(s.handler.enqueueTokens.Reserve("job1:0-4:1:efgh"))
+}
+
+// TestEnqueueGangsIdempotencyTokenInFlight tests that a retried request
+// sent while the request with the same idempotency token is still in
+// flight does not enqueue the gangs
+func (s *handlerTestSuite) TestEnqueueGangsIdempotencyTokenInFlight() {
+	gangs := s.pendingGangs()
+	enqReq := &resmgrsvc.EnqueueGangsRequest{
+		ResPool:          &peloton.ResourcePoolID{Value: "respool3"},
+		Gangs:            gangs,
+		IdempotencyToken: "job1:0-4:1:abcd",
+	}
+
+	// the first request reserved the token and is still enqueuing
+	s.True(s.handler.enqueueTokens.Reserve(enqReq.IdempotencyToken))
+
+	enqResp, err := s.handler.EnqueueGangs(s.context, enqReq)
+	s.NoError(err)
+	s.Nil(enqResp.GetError())
+	for _, gang := range gangs {
+		for _, t := range gang.GetTasks() {
+			s.Nil(s.rmTaskTracker.GetTask(t.GetId()))
+		}
+	}
+}
+
 func (s *handlerTestSuite) TestDequeueGangsOnReservedTasks() {
 	gangs := make([]*resmgrsvc.Gang, 3)
 	gangs[0] = s.pendingGang0()
@@ -416,6 +475,221 @@ func (s *handlerTestSuite) TestRequeueFailures() {
 		resmgrsvc.EnqueueGangsFailure_ENQUEUE_GANGS_FAILURE_ERROR_CODE_INTERNAL)
 }
 
+// TestEnqueueGangRollback tests that the tasks of a gang added to the
+// tracker are removed from it if another task of the gang fails
+func (s *handlerTestSuite) TestEnqueueGangRollback() {
+	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+
+	// the task of pendingGang0 is already present in the tracker with the
+	// same mesos task ID, so the gang can't be enqueued
+	s.NoError(s.rmTaskTracker.AddTask(
+		s.pendingGang0().Tasks[0],
+		nil,
+		node,
+		tasktestutil.CreateTaskConfig()))
+
+	gang := &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			s.pendingGang1().Tasks[0],
+			s.pendingGang0().Tasks[0],
+		},
+	}
+	enqResp, err := s.handler.EnqueueGangs(s.context, &resmgrsvc.EnqueueGangsRequest{
+		ResPool: &peloton.ResourcePoolID{Value: "respool3"},
+		Gangs:   []*resmgrsvc.Gang{gang},
+	})
+	s.NoError(err)
+	s.Len(enqResp.GetError().GetFailure().GetFailed(), 2)
+
+	// the new task of the gang is rolled back
+	s.Nil(s.rmTaskTracker.GetTask(s.pendingGang1().Tasks[0].GetId()))
+	s.NotNil(s.rmTaskTracker.GetTask(s.pendingGang0().Tasks[0].GetId()))
+	_, err = node.PeekGangs(respool.PendingQueue, 10)
+	s.Error(err)
+}
+
+// TestEnqueueGangRollbackRequeuedTask tests that the runs of the tasks
+// replaced by a gang are tracked again if the gang can't be enqueued, on
+// both the failure of a task of the gang and of the enqueue of the gang
+func (s *handlerTestSuite) TestEnqueueGangRollbackRequeuedTask() {
+	leaf, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+	parent, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool1"})
+	s.NoError(err)
+
+	// the task of pendingGang0 is running, and is requeued with a new run
+	s.NoError(s.rmTaskTracker.AddTask(
+		s.pendingGang0().Tasks[0],
+		nil,
+		leaf,
+		tasktestutil.CreateTaskConfig()))
+	prevTask := s.rmTaskTracker.GetTask(s.pendingGang0().Tasks[0].GetId())
+	s.NoError(prevTask.TransitTo(task.TaskState_PENDING.String(),
+		statemachine.WithInfo(mesosTaskID,
+			*s.pendingGang0().Tasks[0].TaskId.Value)))
+	tasktestutil.ValidateStateTransitions(prevTask, []task.TaskState{
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING,
+		task.TaskState_LAUNCHED,
+		task.TaskState_RUNNING})
+
+	newRunTask := func() *resmgr.Task {
+		newRun := s.pendingGang0().Tasks[0]
+		newMesosTaskID := fmt.Sprintf("%s-%d-%d", jobID, 1, 2)
+		newRun.TaskId = &mesos.TaskID{Value: &newMesosTaskID}
+		return newRun
+	}
+
+	// another task of the gang fails
+	s.NoError(s.rmTaskTracker.AddTask(
+		s.pendingGang1().Tasks[0],
+		nil,
+		leaf,
+		tasktestutil.CreateTaskConfig()))
+	gang := &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{newRunTask(), s.pendingGang1().Tasks[0]},
+	}
+	failed, err := s.handler.enqueueGang(gang, leaf)
+	s.Equal(errGangNotEnqueued, err)
+	s.Len(failed, 2)
+	s.Equal(prevTask, s.rmTaskTracker.GetTask(prevTask.Task().GetId()))
+	s.Nil(s.rmTaskTracker.GetOrphanTask(
+		*s.pendingGang0().Tasks[0].TaskId.Value))
+	s.rmTaskTracker.DeleteTask(s.pendingGang1().Tasks[0].GetId())
+
+	// the gang can't be enqueued into a resource pool which is not a leaf
+	gang = &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{newRunTask(), s.pendingGang1().Tasks[0]},
+	}
+	failed, err = s.handler.enqueueGang(gang, parent)
+	s.Equal(errGangNotEnqueued, err)
+	s.Len(failed, 2)
+	s.Equal(prevTask, s.rmTaskTracker.GetTask(prevTask.Task().GetId()))
+	s.Equal(task.TaskState_RUNNING, prevTask.GetCurrentState().State)
+	s.Nil(s.rmTaskTracker.GetTask(s.pendingGang1().Tasks[0].GetId()))
+}
+
+// TestGetGangAdmissionStatus tests the admission status of a gang
+func (s *handlerTestSuite) TestGetGangAdmissionStatus() {
+	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+
+	taskIDs := []*peloton.TaskID{
+		s.pendingGang0().Tasks[0].GetId(),
+		s.pendingGang1().Tasks[0].GetId(),
+	}
+	req := &resmgrsvc.GetGangAdmissionStatusRequest{Tasks: taskIDs}
+
+	resp, err := s.handler.GetGangAdmissionStatus(s.context, req)
+	s.NoError(err)
+	s.Equal(resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_UNKNOWN,
+		resp.GetStatus())
+	s.Len(resp.GetTasks(), 2)
+	s.Equal(task.TaskState_UNKNOWN, resp.GetTasks()[0].GetState())
+
+	for _, gang := range []*resmgrsvc.Gang{s.pendingGang0(), s.pendingGang1()} {
+		s.NoError(s.rmTaskTracker.AddTask(
+			gang.Tasks[0],
+			nil,
+			node,
+			tasktestutil.CreateTaskConfig()))
+		rmTask := s.rmTaskTracker.GetTask(gang.Tasks[0].GetId())
+		s.NoError(rmTask.TransitTo(task.TaskState_PENDING.String()))
+	}
+
+	resp, err = s.handler.GetGangAdmissionStatus(s.context, req)
+	s.NoError(err)
+	s.Equal(resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_PENDING,
+		resp.GetStatus())
+
+	rmTask := s.rmTaskTracker.GetTask(taskIDs[0])
+	s.NoError(rmTask.TransitTo(task.TaskState_READY.String()))
+
+	resp, err = s.handler.GetGangAdmissionStatus(s.context, req)
+	s.NoError(err)
+	s.Equal(resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_PARTIAL,
+		resp.GetStatus())
+	s.Equal(task.TaskState_READY, resp.GetTasks()[0].GetState())
+	s.Equal(task.TaskState_PENDING, resp.GetTasks()[1].GetState())
+
+	rmTask = s.rmTaskTracker.GetTask(taskIDs[1])
+	s.NoError(rmTask.TransitTo(task.TaskState_READY.String()))
+
+	resp, err = s.handler.GetGangAdmissionStatus(s.context, req)
+	s.NoError(err)
+	s.Equal(resmgrsvc.GangAdmissionStatus_GANG_ADMISSION_STATUS_ADMITTED,
+		resp.GetStatus())
+}
+
+func eligibilityReasons(
+	eligibility *resmgrsvc.GetTaskEligibilityResponse_TaskEligibility,
+) []resmgrsvc.TaskIneligibilityReason {
+	var reasons []resmgrsvc.TaskIneligibilityReason
+	for _, r := range eligibility.GetReasons() {
+		reasons = append(reasons, r.GetReason())
+	}
+	return reasons
+}
+
+func (s *handlerTestSuite) TestGetTaskEligibility() {
+	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
+	s.NoError(err)
+
+	taskID := s.pendingGang0().Tasks[0].GetId()
+	req := &resmgrsvc.GetTaskEligibilityRequest{
+		Tasks: []*peloton.TaskID{taskID},
+	}
+
+	resp, err := s.handler.GetTaskEligibility(s.context, req)
+	s.NoError(err)
+	s.Len(resp.GetTasks(), 1)
+	s.Equal(task.TaskState_UNKNOWN, resp.GetTasks()[0].GetState())
+	s.Equal([]resmgrsvc.TaskIneligibilityReason{
+		resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_NOT_FOUND,
+	}, eligibilityReasons(resp.GetTasks()[0]))
+
+	s.NoError(s.rmTaskTracker.AddTask(
+		s.pendingGang0().Tasks[0],
+		nil,
+		node,
+		tasktestutil.CreateTaskConfig()))
+	rmTask := s.rmTaskTracker.GetTask(taskID)
+	s.NoError(rmTask.TransitTo(task.TaskState_PENDING.String()))
+
+	// the resource pool has no entitlement left for the task
+	node.SetNonSlackEntitlement(&scalar.Resources{})
+	resp, err = s.handler.GetTaskEligibility(s.context, req)
+	s.NoError(err)
+	s.Equal(task.TaskState_PENDING, resp.GetTasks()[0].GetState())
+	s.Equal("respool3", resp.GetTasks()[0].GetRespoolID().GetValue())
+	s.Equal([]resmgrsvc.TaskIneligibilityReason{
+		resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_RESPOOL_OVER_ENTITLEMENT,
+	}, eligibilityReasons(resp.GetTasks()[0]))
+
+	s.NoError(rmTask.TransitTo(task.TaskState_READY.String()))
+	resp, err = s.handler.GetTaskEligibility(s.context, req)
+	s.NoError(err)
+	s.Equal([]resmgrsvc.TaskIneligibilityReason{
+		resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_WAITING_FOR_PLACEMENT,
+	}, eligibilityReasons(resp.GetTasks()[0]))
+
+	// the placement of the task fails
+	s.NoError(rmTask.TransitTo(task.TaskState_PLACING.String()))
+	s.NoError(rmTask.RequeueUnPlaced("no matching hosts"))
+	resp, err = s.handler.GetTaskEligibility(s.context, req)
+	s.NoError(err)
+	s.Contains(eligibilityReasons(resp.GetTasks()[0]),
+		resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_FAILED)
+	for _, r := range resp.GetTasks()[0].GetReasons() {
+		if r.GetReason() == resmgrsvc.TaskIneligibilityReason_TASK_INELIGIBILITY_REASON_PLACEMENT_FAILED {
+			s.Contains(r.GetMessage(), "no matching hosts")
+		}
+	}
+}
+
 func (s *handlerTestSuite) TestAddingToPendingQueue() {
 	node, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool3"})
 	s.NoError(err)
@@ -819,6 +1093,73 @@ func (s *handlerTestSuite) TestKillTasks() {
 	s.Equal(res.Error[0].NotFound.Task.Value, tasks[0].Value)
 }
 
+// TestUpdateTaskResources tests updating the resources of a running task
+func (s *handlerTestSuite) TestUpdateTaskResources() {
+	s.rmTaskTracker.Clear()
+	rmTasks, _ := s.createRMTasks()
+	newResource := &task.ResourceConfig{
+		CpuLimit:    2,
+		DiskLimitMb: 10,
+		MemLimitMb:  200,
+	}
+
+	rmTask := s.rmTaskTracker.GetTask(rmTasks[0].GetId())
+	for _, state := range []task.TaskState{
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING,
+		task.TaskState_LAUNCHED,
+		task.TaskState_RUNNING,
+	} {
+		s.NoError(rmTask.TransitTo(state.String()))
+	}
+	s.NoError(rmTask.Respool().AddToAllocation(
+		scalar.GetTaskAllocation(rmTask.Task())))
+
+	_, err := s.handler.UpdateTaskResources(
+		s.context,
+		&resmgrsvc.UpdateTaskResourcesRequest{
+			Task:        rmTasks[0].GetId(),
+			MesosTaskId: rmTasks[0].GetTaskId(),
+			Resource:    newResource,
+		})
+	s.NoError(err)
+	s.Equal(newResource, rmTask.Task().GetResource())
+
+	// the resources of a task which is not running are not updated
+	_, err = s.handler.UpdateTaskResources(
+		s.context,
+		&resmgrsvc.UpdateTaskResourcesRequest{
+			Task:        rmTasks[1].GetId(),
+			MesosTaskId: rmTasks[1].GetTaskId(),
+			Resource:    newResource,
+		})
+	s.Error(err)
+
+	// unknown tasks are not updated
+	s.rmTaskTracker.DeleteTask(rmTasks[2].GetId())
+	_, err = s.handler.UpdateTaskResources(
+		s.context,
+		&resmgrsvc.UpdateTaskResourcesRequest{
+			Task:        rmTasks[2].GetId(),
+			MesosTaskId: rmTasks[2].GetTaskId(),
+			Resource:    newResource,
+		})
+	s.Error(err)
+
+	// resources are required
+	_, err = s.handler.UpdateTaskResources(
+		s.context,
+		&resmgrsvc.UpdateTaskResourcesRequest{
+			Task:        rmTasks[0].GetId(),
+			MesosTaskId: rmTasks[0].GetTaskId(),
+		})
+	s.Error(err)
+	s.rmTaskTracker.Clear()
+}
+
 // TestUpdateTasksState tests the update tasks by state
 func (s *handlerTestSuite) TestUpdateTasksState() {
 	s.rmTaskTracker.Clear()
@@ -1573,6 +1914,60 @@ func (s *handlerTestSuite) TestGetPendingTasks() {
 	}
 }
 
+func (s *handlerTestSuite) TestGetResourcePoolUsage() {
+	respoolID := &peloton.ResourcePoolID{Value: "respool3"}
+	now := time.Now().UTC()
+
+	mr := rm.NewMockResPool(s.ctrl)
+	mr.EXPECT().ID().Return(respoolID.GetValue())
+	mr.EXPECT().GetPath().Return("/respool1/respool3")
+	mr.EXPECT().GetUsageHistory(gomock.Any()).Return([]respool.UsageSample{
+		{
+			Time:           now,
+			Demand:         &scalar.Resources{CPU: 10},
+			Allocation:     &scalar.Resources{CPU: 5, MEMORY: 100},
+			Entitlement:    &scalar.Resources{CPU: 20},
+			PreemptedTasks: 3,
+		},
+	})
+
+	mt := rm.NewMockTree(s.ctrl)
+	mt.EXPECT().Get(respoolID).Return(mr, nil)
+	mt.EXPECT().Get(&peloton.ResourcePoolID{Value: "unknown"}).
+		Return(nil, errors.New("not found"))
+
+	handler := &ServiceHandler{
+		metrics:     NewMetrics(tally.NoopScope),
+		resPoolTree: mt,
+	}
+
+	resp, err := handler.GetResourcePoolUsage(
+		s.context,
+		&resmgrsvc.GetResourcePoolUsageRequest{
+			RespoolIDs:    []*peloton.ResourcePoolID{respoolID},
+			WindowSeconds: 3600,
+		})
+	s.NoError(err)
+	s.Len(resp.GetUsages(), 1)
+	usage := resp.GetUsages()[0]
+	s.Equal(respoolID.GetValue(), usage.GetRespoolID().GetValue())
+	s.Equal("/respool1/respool3", usage.GetPath())
+	s.Len(usage.GetSamples(), 1)
+	sample := usage.GetSamples()[0]
+	s.Equal(now.Format(time.RFC3339), sample.GetTime())
+	s.Equal(float64(10), sample.GetDemand().GetResources()[common.CPU])
+	s.Equal(float64(100), sample.GetAllocation().GetResources()[common.MEMORY])
+	s.Equal(float64(20), sample.GetEntitlement().GetResources()[common.CPU])
+	s.Equal(uint32(3), sample.GetPreemptedTasks())
+
+	_, err = handler.GetResourcePoolUsage(
+		s.context,
+		&resmgrsvc.GetResourcePoolUsageRequest{
+			RespoolIDs: []*peloton.ResourcePoolID{{Value: "unknown"}},
+		})
+	s.Error(err)
+}
+
 func (s *handlerTestSuite) TestGetOrphanTasks() {
 	rmTasks, _ := s.createRMTasks()
 	for _, t := range rmTasks {
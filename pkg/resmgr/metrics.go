@@ -18,9 +18,10 @@ import "github.com/uber-go/tally"
 
 // Metrics is a placeholder for all metrics in resmgr.
 type Metrics struct {
-	APIEnqueueGangs    tally.Counter
-	EnqueueGangSuccess tally.Counter
-	EnqueueGangFail    tally.Counter
+	APIEnqueueGangs       tally.Counter
+	EnqueueGangSuccess    tally.Counter
+	EnqueueGangFail       tally.Counter
+	EnqueueGangsDuplicate tally.Counter
 
 	APIDequeueGangs    tally.Counter
 	DequeueGangSuccess tally.Counter
@@ -40,14 +41,24 @@ type Metrics struct {
 
 	APILaunchedTasks tally.Counter
 
+	APIUpdateTaskResources tally.Counter
+
+	APIGetGangAdmissionStatus tally.Counter
+	APIGetResourcePoolUsage   tally.Counter
+	APIGetTaskEligibility     tally.Counter
+
 	RecoverySuccess             tally.Counter
 	RecoveryFail                tally.Counter
 	RecoveryRunningSuccessCount tally.Counter
 	RecoveryRunningFailCount    tally.Counter
 	RecoveryEnqueueFailedCount  tally.Counter
 	RecoveryEnqueueSuccessCount tally.Counter
+	RecoverySnapshotGangCount   tally.Counter
 	RecoveryTimer               tally.Timer
 
+	QueueSnapshotSuccess tally.Counter
+	QueueSnapshotFail    tally.Counter
+
 	PlacementQueueLen tally.Gauge
 	PlacementFailed   tally.Counter
 
@@ -65,9 +76,10 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	recovery := scope.SubScope("recovery")
 
 	return &Metrics{
-		APIEnqueueGangs:    apiScope.Counter("enqueue_gangs"),
-		EnqueueGangSuccess: successScope.Counter("enqueue_gang"),
-		EnqueueGangFail:    failScope.Counter("enqueue_gang"),
+		APIEnqueueGangs:       apiScope.Counter("enqueue_gangs"),
+		EnqueueGangSuccess:    successScope.Counter("enqueue_gang"),
+		EnqueueGangFail:       failScope.Counter("enqueue_gang"),
+		EnqueueGangsDuplicate: apiScope.Counter("enqueue_gangs_duplicate"),
 
 		APIDequeueGangs:    apiScope.Counter("dequeue_gangs"),
 		DequeueGangSuccess: successScope.Counter("dequeue_gangs"),
@@ -87,14 +99,24 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		APILaunchedTasks: apiScope.Counter("launched_tasks"),
 
+		APIUpdateTaskResources: apiScope.Counter("update_task_resources"),
+
+		APIGetGangAdmissionStatus: apiScope.Counter("get_gang_admission_status"),
+		APIGetResourcePoolUsage:   apiScope.Counter("get_resource_pool_usage"),
+		APIGetTaskEligibility:     apiScope.Counter("get_task_eligibility"),
+
 		RecoverySuccess:             successScope.Counter("recovery"),
 		RecoveryFail:                failScope.Counter("recovery"),
 		RecoveryRunningSuccessCount: successScope.Counter("task_count"),
 		RecoveryRunningFailCount:    failScope.Counter("task_count"),
 		RecoveryEnqueueFailedCount:  failScope.Counter("enqueue_task_count"),
 		RecoveryEnqueueSuccessCount: successScope.Counter("enqueue_task_count"),
+		RecoverySnapshotGangCount:   recovery.Counter("snapshot_gang_count"),
 		RecoveryTimer:               recovery.Timer("running_tasks"),
 
+		QueueSnapshotSuccess: successScope.Counter("queue_snapshot"),
+		QueueSnapshotFail:    failScope.Counter("queue_snapshot"),
+
 		PlacementQueueLen: placement.Gauge("placement_queue_length"),
 		PlacementFailed:   placement.Counter("fail"),
 
@@ -23,6 +23,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
+	"github.com/uber/peloton/pkg/common/freeze"
 	"github.com/uber/peloton/pkg/common/lifecycle"
 	"github.com/uber/peloton/pkg/common/queue"
 	"github.com/uber/peloton/pkg/common/statemachine"
@@ -85,6 +86,8 @@ type Preemptor struct {
 	ranker ranker
 	// The task tracker
 	tracker task.Tracker
+	// The cluster freeze state, preemption is halted while frozen
+	freezeState freeze.State
 
 	// The metrics scope
 	scope tally.Scope
@@ -98,6 +101,7 @@ func NewPreemptor(
 	cfg *common.PreemptionConfig,
 	tracker task.Tracker,
 	resTree respool.Tree,
+	freezeState freeze.State,
 ) *Preemptor {
 
 	return &Preemptor{
@@ -113,10 +117,11 @@ func NewPreemptor(
 			reflect.TypeOf(resmgr.PreemptionCandidate{}),
 			maxPreemptionQueueSize,
 		),
-		ranker:  newStatePriorityRuntimeRanker(tracker),
-		tracker: tracker,
-		scope:   parent.SubScope("preemption"),
-		m:       make(map[string]*Metrics),
+		ranker:      newStatePriorityRuntimeRanker(tracker),
+		tracker:     tracker,
+		freezeState: freezeState,
+		scope:       parent.SubScope("preemption"),
+		m:           make(map[string]*Metrics),
 	}
 }
 
@@ -154,6 +159,10 @@ func (p *Preemptor) Start() error {
 					log.Info("Exiting Task Preemptor")
 					return
 				case <-ticker.C:
+					if p.freezeState != nil && p.freezeState.IsFrozen() {
+						log.Debug("Cluster is frozen, skipping preemption cycle")
+						continue
+					}
 					err := p.preemptOnce()
 					if err != nil {
 						log.WithError(err).Warn("Preemption cycle failed")
@@ -385,6 +394,7 @@ func (p *Preemptor) processRunningTask(
 	// There could be cases where preemption is taking longer than usual
 	// so we don't want to add the same task in the next preemption cycle.
 	p.taskSet.Add(preemptionCandidate.GetTaskId().GetValue())
+	t.Respool().AddPreemptedTasks(1)
 
 	// ToDo: ResourcesFreed are speculated to get free if preemption
 	// runs uninterrupted. Fix it to track that running tasks reached
@@ -298,6 +298,7 @@ func (suite *preemptorTestSuite) TestUpdateResourcePoolsStateReset() {
 func (suite *preemptorTestSuite) TestProcessResourcePoolForRunningTasks() {
 	mockResTree := mocks.NewMockTree(suite.mockCtrl)
 	mockResPool := mocks.NewMockResPool(suite.mockCtrl)
+	mockResPool.EXPECT().AddPreemptedTasks(1).AnyTimes()
 
 	// Mocks
 	mockResTree.EXPECT().Get(&peloton.ResourcePoolID{Value: "respool-1"}).
@@ -487,6 +488,7 @@ func (suite *preemptorTestSuite) TestRevocableTaskPreemptionOnly() {
 	defer ctr.Finish()
 	mockResTree := mocks.NewMockTree(ctr)
 	mockResPool := mocks.NewMockResPool(ctr)
+	mockResPool.EXPECT().AddPreemptedTasks(1).AnyTimes()
 
 	mockResTree.EXPECT().
 		Get(&peloton.ResourcePoolID{Value: "respool-1"}).
@@ -813,6 +815,7 @@ func (suite *preemptorTestSuite) TestPreemptorEnqueue() {
 	defer ctr.Finish()
 
 	mockResPool := mocks.NewMockResPool(ctr)
+	mockResPool.EXPECT().AddPreemptedTasks(1).AnyTimes()
 	mockResPool.EXPECT().ID().
 		Return("respool-1").
 		AnyTimes()
@@ -849,6 +852,7 @@ func (suite *preemptorTestSuite) TestNewPreemptor() {
 	},
 		suite.tracker,
 		suite.getResourceTree(),
+		nil,
 	)
 	suite.NotNil(p)
 }
@@ -856,6 +860,7 @@ func (suite *preemptorTestSuite) TestNewPreemptor() {
 func (suite *preemptorTestSuite) TestPreemptionQueueDuplicateTasks() {
 	mockResTree := mocks.NewMockTree(suite.mockCtrl)
 	mockResPool := mocks.NewMockResPool(suite.mockCtrl)
+	mockResPool.EXPECT().AddPreemptedTasks(1).AnyTimes()
 
 	// Mocks
 	mockResTree.EXPECT().Get(&peloton.ResourcePoolID{Value: "respool-1"}).Return(mockResPool, nil)
@@ -921,6 +926,7 @@ func (suite *preemptorTestSuite) TestPreemptionQueueDuplicateTasks() {
 func (suite *preemptorTestSuite) TestPreemptorDequeueTask() {
 	mockResTree := mocks.NewMockTree(suite.mockCtrl)
 	mockResPool := mocks.NewMockResPool(suite.mockCtrl)
+	mockResPool.EXPECT().AddPreemptedTasks(1).AnyTimes()
 
 	// Mocks
 	mockResTree.EXPECT().Get(&peloton.ResourcePoolID{Value: "respool-1"}).Return(mockResPool, nil)
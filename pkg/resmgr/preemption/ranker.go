@@ -16,6 +16,7 @@ package preemption
 
 import (
 	"sort"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 
@@ -46,7 +47,8 @@ type ranker interface {
 
 // statePriorityRuntimeRanker sorts the tasks in the following order
 // * Task State : READY > PLACING > RUNNING
-// * If task state is the same it sorts on the task Priority
+// * If task state is the same it sorts on the task preemption tier
+// * If the tier is the same it sorts on the task Priority
 // * If the priority is the same it sorts on the task runtime(how long the task has been running)
 type statePriorityRuntimeRanker struct {
 	tracker rm_task.Tracker
@@ -59,6 +61,7 @@ func newStatePriorityRuntimeRanker(tracker rm_task.Tracker) ranker {
 		tracker: tracker,
 		sorter: taskSorter{
 			cmpFuncs: []cmpFunc{
+				tierCmp,
 				priorityCmp,
 				startTimeCmp,
 			},
@@ -126,7 +129,7 @@ func filterRevocableTasks(
 	allTasks []*rm_task.RMTask) []*rm_task.RMTask {
 	var p []*rm_task.RMTask
 	for _, t := range allTasks {
-		if t.Task().Preemptible && t.Task().Revocable {
+		if t.Task().Preemptible && t.Task().Revocable && hasMinRunningTime(t) {
 			p = append(p, t)
 		}
 	}
@@ -139,7 +142,7 @@ func filterNonRevocableTasks(
 	allTasks []*rm_task.RMTask) []*rm_task.RMTask {
 	var p []*rm_task.RMTask
 	for _, t := range allTasks {
-		if t.Task().Preemptible && !t.Task().Revocable {
+		if t.Task().Preemptible && !t.Task().Revocable && hasMinRunningTime(t) {
 			p = append(p, t)
 		}
 	}
@@ -147,6 +150,18 @@ func filterNonRevocableTasks(
 	return p
 }
 
+// hasMinRunningTime returns false if the task is running and hasn't been
+// running for the minimum time required before it can be preempted
+func hasMinRunningTime(t *rm_task.RMTask) bool {
+	minRunningSecs := t.Task().GetMinRunningSecs()
+	if minRunningSecs == 0 ||
+		t.GetCurrentState().State != task.TaskState_RUNNING {
+		return true
+	}
+	return time.Since(t.RunTimeStats().StartTime) >=
+		time.Duration(minRunningSecs)*time.Second
+}
+
 // filterTasks filters tasks which satisfy the resourcesLimit
 // This method assumes the list of tasks supplied is already sorted in the preferred order
 func filterTasks(
@@ -183,6 +198,11 @@ func filterTasks(
 // return >0 if  t1 > t2
 type cmpFunc func(t1, t2 *rm_task.RMTask) int
 
+// compares tasks based on their preemption tier
+func tierCmp(t1, t2 *rm_task.RMTask) int {
+	return int(t1.Task().GetPreemptionTier()) - int(t2.Task().GetPreemptionTier())
+}
+
 // compares tasks based on their priority
 func priorityCmp(t1, t2 *rm_task.RMTask) int {
 	log.WithField("task_1_ID", t1.Task().Id.Value).
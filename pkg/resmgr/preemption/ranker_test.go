@@ -455,3 +455,54 @@ func (suite *RankerTestSuite) TestStatePriorityRuntimeRanker_FilterNonPreemptibl
 		}
 	}
 }
+
+func (suite *RankerTestSuite) TestStatePriorityRuntimeRanker_PreemptionPolicy() {
+	tt := []struct {
+		tid            string
+		priority       uint32
+		tier           uint32
+		minRunningSecs uint32
+	}{
+		{
+			tid:      "job1-1",
+			priority: 0,
+			tier:     1,
+		},
+		{
+			tid:      "job2-1",
+			priority: 5,
+			tier:     0,
+		},
+		{
+			// hasn't been running for long enough to be preempted
+			tid:            "job3-1",
+			priority:       0,
+			tier:           0,
+			minRunningSecs: 3600,
+		},
+	}
+
+	for i, t := range tt {
+		rmTask := suite.createTask(i, t.priority)
+		rmTask.Id = &peloton.TaskID{Value: t.tid}
+		rmTask.PreemptionTier = t.tier
+		rmTask.MinRunningSecs = t.minRunningSecs
+		suite.addTaskToTracker(rmTask)
+		suite.transitToRunning(rmTask.GetId())
+	}
+
+	ranker := newStatePriorityRuntimeRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict("respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    3,
+			MEMORY: 300,
+			GPU:    0,
+			DISK:   27,
+		})
+
+	// tasks in the lower tier are evicted first regardless of priority
+	suite.Equal(2, len(tasksToEvict))
+	suite.Equal("job2-1", tasksToEvict[0].Task().GetId().GetValue())
+	suite.Equal("job1-1", tasksToEvict[1].Task().GetId().GetValue())
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// FairShareQueue is a queue which is shared fairly between the owners of
+// the gangs in it. The gangs of an owner are returned by priority and in
+// the order they came into the queue. Between owners, the gang with the
+// highest priority goes first, and for the same priority the owner which
+// has been served the least so far goes first.
+type FairShareQueue struct {
+	sync.RWMutex
+	limit  int64
+	owners map[string]*ownerQueue
+}
+
+// ownerQueue holds the gangs of a single owner of a FairShareQueue
+type ownerQueue struct {
+	queue *PriorityQueue
+	// number of gangs of the owner removed from the queue
+	served uint64
+}
+
+// NewFairShareQueue intializes the fair share queue and returns the pointer
+func NewFairShareQueue(limit int64) *FairShareQueue {
+	return &FairShareQueue{
+		limit:  limit,
+		owners: make(map[string]*ownerQueue),
+	}
+}
+
+// Enqueue queues a gang (task list gang) into the queue of its owner
+func (f *FairShareQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+
+	if f.limit >= 0 && f.limit <= int64(f.size()) {
+		return fmt.Errorf("queue size limit reached")
+	}
+
+	owner := gangOwner(gang)
+	oq, ok := f.owners[owner]
+	if !ok {
+		// a new owner starts with the least served owner, so that it
+		// does not get ahead of the owners already waiting
+		oq = &ownerQueue{
+			queue:  NewPriorityQueue(f.limit),
+			served: f.minServed(),
+		}
+		f.owners[owner] = oq
+	}
+	return oq.queue.Enqueue(gang)
+}
+
+// Dequeue dequeues the next gang (task list gang) according to the fair
+// share between the owners
+func (f *FairShareQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	gangs := f.peek(1)
+	if len(gangs) == 0 {
+		return nil, ErrorQueueEmpty("dequeue failed, queue is empty")
+	}
+
+	if err := f.remove(gangs[0]); err != nil {
+		return nil, err
+	}
+	return gangs[0], nil
+}
+
+// Peek peeks the limit number of gangs in the order they would be
+// dequeued.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (f *FairShareQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	gangs := f.peek(int(limit))
+	if len(gangs) == 0 {
+		return gangs, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return gangs, nil
+}
+
+// PeekHead returns the gang which is dequeued next according to the fair
+// share between the owners, without removing it from the queue.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (f *FairShareQueue) PeekHead() (*resmgrsvc.Gang, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	gangs := f.peek(1)
+	if len(gangs) == 0 {
+		return nil, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return gangs[0], nil
+}
+
+// Remove removes the item from the queue
+func (f *FairShareQueue) Remove(gang *resmgrsvc.Gang) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+	return f.remove(gang)
+}
+
+// RemoveTask removes the task with the given ID from the queue of its
+// owner. The gang of the task is removed from the queue if the task is its
+// only task, otherwise the task is removed from the gang. A removed gang
+// is not counted as served for its owner.
+// It returns the removed task, or an `ErrorQueueEmpty` if the task is not
+// present in the queue.
+func (f *FairShareQueue) RemoveTask(taskID string) (*resmgr.Task, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	for owner, oq := range f.owners {
+		task, err := oq.queue.RemoveTask(taskID)
+		if err != nil {
+			continue
+		}
+		if oq.queue.Size() == 0 {
+			delete(f.owners, owner)
+		}
+		return task, nil
+	}
+
+	return nil, ErrorQueueEmpty(
+		fmt.Sprintf("task %s not found in queue", taskID))
+}
+
+// Size returns the number of elements in the FairShareQueue
+func (f *FairShareQueue) Size() int {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.size()
+}
+
+func (f *FairShareQueue) size() int {
+	size := 0
+	for _, oq := range f.owners {
+		size += oq.queue.Size()
+	}
+	return size
+}
+
+// minServed returns the number of gangs served for the least served owner
+func (f *FairShareQueue) minServed() uint64 {
+	if len(f.owners) == 0 {
+		return 0
+	}
+
+	served := uint64(math.MaxUint64)
+	for _, oq := range f.owners {
+		if oq.served < served {
+			served = oq.served
+		}
+	}
+	return served
+}
+
+// remove removes the gang from the queue of its owner, and counts it as
+// served for the owner
+func (f *FairShareQueue) remove(gang *resmgrsvc.Gang) error {
+	owner := gangOwner(gang)
+	oq, ok := f.owners[owner]
+	if !ok {
+		return ErrorQueueEmpty(
+			fmt.Sprintf("No items found in queue for owner %s", owner))
+	}
+
+	if err := oq.queue.Remove(gang); err != nil {
+		return err
+	}
+
+	oq.served++
+	if oq.queue.Size() == 0 {
+		delete(f.owners, owner)
+	}
+	return nil
+}
+
+// peek returns up to limit gangs in the order they would be dequeued,
+// without removing them from the queue
+func (f *FairShareQueue) peek(limit int) []*resmgrsvc.Gang {
+	pending := make(map[string][]*resmgrsvc.Gang)
+	served := make(map[string]uint64)
+	for owner, oq := range f.owners {
+		gangs, err := oq.queue.Peek(uint32(limit))
+		if err != nil {
+			continue
+		}
+		pending[owner] = gangs
+		served[owner] = oq.served
+	}
+
+	var gangs []*resmgrsvc.Gang
+	for len(gangs) < limit {
+		owner, ok := nextOwner(pending, served)
+		if !ok {
+			break
+		}
+		gangs = append(gangs, pending[owner][0])
+		pending[owner] = pending[owner][1:]
+		served[owner]++
+	}
+	return gangs
+}
+
+// nextOwner returns the owner whose gang is dequeued next. It returns
+// false if none of the owners have any gangs left.
+func nextOwner(
+	pending map[string][]*resmgrsvc.Gang,
+	served map[string]uint64,
+) (string, bool) {
+	var next string
+	found := false
+	for owner, gangs := range pending {
+		if len(gangs) == 0 {
+			continue
+		}
+		if !found {
+			next = owner
+			found = true
+			continue
+		}
+
+		priority := gangs[0].GetTasks()[0].GetPriority()
+		nextPriority := pending[next][0].GetTasks()[0].GetPriority()
+		switch {
+		case priority != nextPriority:
+			if priority > nextPriority {
+				next = owner
+			}
+		case served[owner] != served[next]:
+			if served[owner] < served[next] {
+				next = owner
+			}
+		case owner < next:
+			next = owner
+		}
+	}
+	return next, found
+}
+
+// gangOwner returns the owner of a gang, which is the owner of its tasks
+func gangOwner(gang *resmgrsvc.Gang) string {
+	return gang.GetTasks()[0].GetOwner()
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFairShareQueue tests that the owners are served in turn for gangs
+// of the same priority
+func TestFairShareQueue(t *testing.T) {
+	q := NewFairShareQueue(math.MaxInt64)
+
+	_, err := q.Peek(1)
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+	_, err = q.Dequeue()
+	assert.Error(t, err)
+	assert.Error(t, q.Enqueue(nil))
+
+	for _, gang := range []*resmgrsvc.Gang{
+		makeGang("a1", 0, "alice"),
+		makeGang("a2", 0, "alice"),
+		makeGang("a3", 0, "alice"),
+		makeGang("b1", 0, "bob"),
+		makeGang("c1", 1, "carol"),
+	} {
+		assert.NoError(t, q.Enqueue(gang))
+	}
+	assert.Equal(t, 5, q.Size())
+
+	// higher priority goes first, then the owners take turns
+	peeked, err := q.Peek(10)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		[]string{"c1", "a1", "b1", "a2", "a3"},
+		gangIDs(peeked))
+
+	var dequeued []*resmgrsvc.Gang
+	for i := 0; i < 3; i++ {
+		gang, err := q.Dequeue()
+		assert.NoError(t, err)
+		dequeued = append(dequeued, gang)
+	}
+	assert.Equal(t, []string{"c1", "a1", "b1"}, gangIDs(dequeued))
+
+	// a new owner does not get ahead of the owners already waiting
+	assert.NoError(t, q.Enqueue(makeGang("d1", 0, "dave")))
+	peeked, err = q.Peek(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a2", "d1", "a3"}, gangIDs(peeked))
+}
+
+// TestFairShareQueueRemove tests removing gangs from the queue
+func TestFairShareQueueRemove(t *testing.T) {
+	q := NewFairShareQueue(math.MaxInt64)
+
+	a1 := makeGang("a1", 0, "alice")
+	b1 := makeGang("b1", 0, "bob")
+	assert.NoError(t, q.Enqueue(a1))
+	assert.NoError(t, q.Enqueue(b1))
+
+	assert.NoError(t, q.Remove(a1))
+	assert.Error(t, q.Remove(a1))
+	assert.Error(t, q.Remove(makeGang("c1", 0, "carol")))
+	assert.Equal(t, 1, q.Size())
+
+	gang, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, b1, gang)
+	assert.Equal(t, 0, q.Size())
+}
+
+// TestFairShareQueueRemoveTask tests removing tasks from the queues of
+// the owners
+func TestFairShareQueueRemoveTask(t *testing.T) {
+	q := NewFairShareQueue(math.MaxInt64)
+
+	_, err := q.PeekHead()
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+
+	assert.NoError(t, q.Enqueue(makeGang("a1", 0, "alice")))
+	assert.NoError(t, q.Enqueue(makeGang("b1", 1, "bob")))
+
+	head, err := q.PeekHead()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b1"}, gangIDs([]*resmgrsvc.Gang{head}))
+
+	task, err := q.RemoveTask("b1")
+	assert.NoError(t, err)
+	assert.Equal(t, "b1", task.GetId().GetValue())
+	assert.Equal(t, 1, q.Size())
+	assert.Len(t, q.owners, 1)
+
+	_, err = q.RemoveTask("b1")
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+
+	head, err = q.PeekHead()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1"}, gangIDs([]*resmgrsvc.Gang{head}))
+}
+
+// TestFairShareQueueLimit tests that gangs can't be enqueued beyond the
+// limit across all the owners
+func TestFairShareQueueLimit(t *testing.T) {
+	q := NewFairShareQueue(1)
+	assert.NoError(t, q.Enqueue(makeGang("a1", 0, "alice")))
+	assert.Error(t, q.Enqueue(makeGang("b1", 0, "bob")))
+}
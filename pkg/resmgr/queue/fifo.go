@@ -0,0 +1,119 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+)
+
+// fifoLevel is the only level of the list backing a FIFOQueue
+const fifoLevel = 0
+
+// FIFOQueue is a queue which returns the gangs strictly in the order they
+// entered the queue, irrespective of their priority
+type FIFOQueue struct {
+	sync.RWMutex
+	list MultiLevelList
+}
+
+// NewFIFOQueue intializes the strict fifo queue and returns the pointer
+func NewFIFOQueue(limit int64) *FIFOQueue {
+	return &FIFOQueue{
+		list: NewMultiLevelList("fifo", limit),
+	}
+}
+
+// Enqueue queues a gang (task list gang) at the end of the queue
+func (f *FIFOQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+	return f.list.Push(fifoLevel, gang)
+}
+
+// Dequeue dequeues the gang (task list gang) which entered the queue first
+func (f *FIFOQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	item, err := f.list.Pop(fifoLevel)
+	if err != nil {
+		return nil, err
+	}
+	return item.(*resmgrsvc.Gang), nil
+}
+
+// Peek peeks the limit number of gangs in the order they came into the
+// queue.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (f *FIFOQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	items, err := f.list.PeekItems(fifoLevel, int(limit))
+	if err != nil {
+		return nil, err
+	}
+	return toGang(items), nil
+}
+
+// PeekHead returns the gang which entered the queue first, without
+// removing it from the queue.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (f *FIFOQueue) PeekHead() (*resmgrsvc.Gang, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	item, err := f.list.PeekItem(fifoLevel)
+	if err != nil {
+		return nil, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return item.(*resmgrsvc.Gang), nil
+}
+
+// Remove removes the item from the queue
+func (f *FIFOQueue) Remove(gang *resmgrsvc.Gang) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+	return f.list.Remove(fifoLevel, gang)
+}
+
+// RemoveTask removes the task with the given ID from the queue. The gang
+// of the task is removed from the queue if the task is its only task,
+// otherwise the task is removed from the gang.
+// It returns the removed task, or an `ErrorQueueEmpty` if the task is not
+// present in the queue.
+func (f *FIFOQueue) RemoveTask(taskID string) (*resmgr.Task, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	return removeTask(f.list, []int{fifoLevel}, taskID, nil)
+}
+
+// Size returns the number of elements in the FIFOQueue
+func (f *FIFOQueue) Size() int {
+	return f.list.Size()
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeGang(taskID string, priority uint32, owner string) *resmgrsvc.Gang {
+	task := CreateResmgrTask(
+		&peloton.JobID{Value: "job"},
+		&peloton.TaskID{Value: taskID},
+		priority)
+	task.Owner = owner
+	return &resmgrsvc.Gang{Tasks: []*resmgr.Task{task}}
+}
+
+func gangIDs(gangs []*resmgrsvc.Gang) []string {
+	var ids []string
+	for _, gang := range gangs {
+		ids = append(ids, gang.GetTasks()[0].GetId().GetValue())
+	}
+	return ids
+}
+
+// TestFIFOQueue tests that the gangs are returned in the order they were
+// enqueued, irrespective of their priority
+func TestFIFOQueue(t *testing.T) {
+	q := NewFIFOQueue(math.MaxInt64)
+
+	_, err := q.Peek(1)
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+	assert.Error(t, q.Enqueue(nil))
+
+	gangs := []*resmgrsvc.Gang{
+		makeGang("t1", 0, ""),
+		makeGang("t2", 2, ""),
+		makeGang("t3", 1, ""),
+	}
+	for _, gang := range gangs {
+		assert.NoError(t, q.Enqueue(gang))
+	}
+	assert.Equal(t, 3, q.Size())
+
+	peeked, err := q.Peek(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2", "t3"}, gangIDs(peeked))
+
+	assert.NoError(t, q.Remove(gangs[1]))
+	assert.Error(t, q.Remove(gangs[1]))
+
+	gang, err := q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, gangs[0], gang)
+	gang, err = q.Dequeue()
+	assert.NoError(t, err)
+	assert.Equal(t, gangs[2], gang)
+
+	_, err = q.Dequeue()
+	assert.Error(t, err)
+	assert.Equal(t, 0, q.Size())
+}
+
+// TestFIFOQueueRemoveTask tests removing tasks from the gangs in the queue
+func TestFIFOQueueRemoveTask(t *testing.T) {
+	q := NewFIFOQueue(math.MaxInt64)
+
+	_, err := q.PeekHead()
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+
+	t1 := makeGang("t1", 0, "")
+	t2 := makeGang("t2", 1, "")
+	t2.Tasks = append(t2.Tasks, makeGang("t3", 1, "").Tasks...)
+	assert.NoError(t, q.Enqueue(t1))
+	assert.NoError(t, q.Enqueue(t2))
+
+	head, err := q.PeekHead()
+	assert.NoError(t, err)
+	assert.Equal(t, t1, head)
+
+	// the gang of a single task is removed
+	task, err := q.RemoveTask("t1")
+	assert.NoError(t, err)
+	assert.Equal(t, "t1", task.GetId().GetValue())
+	assert.Equal(t, 1, q.Size())
+
+	// the task is removed from a gang of multiple tasks
+	task, err = q.RemoveTask("t3")
+	assert.NoError(t, err)
+	assert.Equal(t, "t3", task.GetId().GetValue())
+	head, err = q.PeekHead()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t2"}, gangIDs([]*resmgrsvc.Gang{head}))
+	assert.Len(t, head.GetTasks(), 1)
+
+	_, err = q.RemoveTask("t3")
+	assert.IsType(t, ErrorQueueEmpty(""), err)
+}
+
+// TestFIFOQueueLimit tests that gangs can't be enqueued beyond the limit
+func TestFIFOQueueLimit(t *testing.T) {
+	q := NewFIFOQueue(1)
+	assert.NoError(t, q.Enqueue(makeGang("t1", 0, "")))
+	assert.Error(t, q.Enqueue(makeGang("t2", 0, "")))
+}
@@ -17,25 +17,63 @@ package queue
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
 )
 
+// now is the clock used to compute the wait time of the gangs, it is
+// replaced in tests.
+var now = time.Now
+
 // PriorityQueue is FIFO queue which remove the highest priority task item entered first in the queue
 type PriorityQueue struct {
+	// RWMutex guards all the operations on the list, so that the highest
+	// level read from the list is consistent with the items popped from it
 	sync.RWMutex
 	list MultiLevelList
+
+	// size is the number of gangs in the queue. It is only updated with
+	// the write lock held, along with the list, and can be read atomically
+	// without taking the lock.
+	size int64
+
+	// maxWait is the time after which a gang waiting in the queue is
+	// dequeued ahead of the gangs of higher priorities. Zero disables the
+	// aging of the gangs.
+	maxWait time.Duration
+	// enqueue time of the gangs in the queue
+	enqueueTimes map[*resmgrsvc.Gang]time.Time
+	// scope to report the wait time of the gangs per priority, can be nil
+	scope tally.Scope
 }
 
 // NewPriorityQueue intializes the fifo queue and returns the pointer
 func NewPriorityQueue(limit int64) *PriorityQueue {
-	fq := PriorityQueue{
-		list: NewMultiLevelList("list", limit),
+	return NewAgingPriorityQueue(limit, 0, nil)
+}
+
+// NewAgingPriorityQueue initializes a priority queue which dequeues the
+// gangs waiting for longer than maxWait ahead of the gangs of higher
+// priorities, and reports the wait time of the gangs per priority to the
+// given scope, if not nil.
+func NewAgingPriorityQueue(
+	limit int64,
+	maxWait time.Duration,
+	scope tally.Scope) *PriorityQueue {
+	return &PriorityQueue{
+		list:         NewMultiLevelList("list", limit),
+		maxWait:      maxWait,
+		enqueueTimes: make(map[*resmgrsvc.Gang]time.Time),
+		scope:        scope,
 	}
-	return &fq
 }
 
 // Enqueue queues a gang (task list gang) based on its priority into FIFO queue
@@ -49,20 +87,31 @@ func (f *PriorityQueue) Enqueue(gang *resmgrsvc.Gang) error {
 
 	tasks := gang.GetTasks()
 	priority := tasks[0].Priority
-	return f.list.Push(int(priority), gang)
+	if err := f.list.Push(int(priority), gang); err != nil {
+		return err
+	}
+	atomic.AddInt64(&f.size, 1)
+	f.enqueueTimes[gang] = now()
+	return nil
 }
 
 // Dequeue dequeues the gang (task list gang) based on the priority and order
 // they came into the queue
 func (f *PriorityQueue) Dequeue() (*resmgrsvc.Gang, error) {
-	// TODO: optimize the write lock here with potential read lock
 	f.Lock()
 	defer f.Unlock()
 
+	if gang, level, ok := f.agedGang(); ok {
+		if err := f.list.Remove(level, gang); err != nil {
+			return nil, err
+		}
+		f.removed(level, gang)
+		return gang, nil
+	}
+
 	highestPriority := f.list.GetHighestLevel()
 	item, err := f.list.Pop(highestPriority)
 	if err != nil {
-		// TODO: Need to add test case for this case
 		for highestPriority != f.list.GetHighestLevel() {
 			highestPriority = f.list.GetHighestLevel()
 			item, err = f.list.Pop(highestPriority)
@@ -75,10 +124,12 @@ func (f *PriorityQueue) Dequeue() (*resmgrsvc.Gang, error) {
 		}
 	}
 	if item == nil {
+		atomic.AddInt64(&f.size, -1)
 		return nil, errors.New("dequeue failed")
 	}
 
 	res := item.(*resmgrsvc.Gang)
+	f.removed(highestPriority, res)
 	return res, nil
 }
 
@@ -86,14 +137,21 @@ func (f *PriorityQueue) Dequeue() (*resmgrsvc.Gang, error) {
 // they came into the queue.
 // It will return an `ErrorQueueEmpty` if there is no gangs in the queue
 func (f *PriorityQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
-	// TODO: optimize the write lock here with potential read lock
-	f.Lock()
-	defer f.Unlock()
+	f.RLock()
+	defer f.RUnlock()
 
 	var items []*resmgrsvc.Gang
 	priority := f.list.GetHighestLevel()
 	itemsLeft := int(limit)
 
+	// the aged gang goes first, ahead of the gangs of its level and of
+	// the levels above it
+	aged, agedLevel, hasAged := f.agedGang()
+	if hasAged && itemsLeft > 0 {
+		items = append(items, aged)
+		itemsLeft--
+	}
+
 	// start at the highest priority
 	// keep going down until priority 0 or until limit is satisfied
 	for {
@@ -107,7 +165,12 @@ func (f *PriorityQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
 			break
 		}
 
-		itemsByPriority, err := f.list.PeekItems(priority, itemsLeft)
+		peekLimit := itemsLeft
+		if hasAged && priority == agedLevel {
+			// the aged gang is at the front of its level
+			peekLimit++
+		}
+		itemsByPriority, err := f.list.PeekItems(priority, peekLimit)
 		if err != nil {
 			if _, ok := err.(ErrorQueueEmpty); ok {
 				// no items for priority, continue to the next one
@@ -118,11 +181,15 @@ func (f *PriorityQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
 		}
 
 		gangs := toGang(itemsByPriority)
+		if hasAged && priority == agedLevel &&
+			len(gangs) > 0 && gangs[0] == aged {
+			gangs = gangs[1:]
+		}
 
 		items = append(items, gangs...)
 
 		priority--
-		itemsLeft = itemsLeft - len(itemsByPriority)
+		itemsLeft = itemsLeft - len(gangs)
 	}
 
 	if len(items) == 0 {
@@ -132,6 +199,78 @@ func (f *PriorityQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
 	return items, nil
 }
 
+// PeekHead returns the gang at the head of the queue, which is the gang
+// with the highest priority that entered the queue first unless a gang of
+// a lower priority waited for longer than maxWait, without removing it
+// from the queue.
+// It will return an `ErrorQueueEmpty` if there is no gangs in the queue
+func (f *PriorityQueue) PeekHead() (*resmgrsvc.Gang, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	if gang, _, ok := f.agedGang(); ok {
+		return gang, nil
+	}
+
+	item, err := f.list.PeekItem(f.list.GetHighestLevel())
+	if err != nil {
+		return nil, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return item.(*resmgrsvc.Gang), nil
+}
+
+// agedGang returns the gang which waited for the longest time among the
+// gangs which waited for longer than maxWait at the front of the levels
+// below the highest level, along with its level.
+// NB: The function calling agedGang should acquire the lock
+func (f *PriorityQueue) agedGang() (*resmgrsvc.Gang, int, bool) {
+	if f.maxWait <= 0 {
+		return nil, 0, false
+	}
+
+	var aged *resmgrsvc.Gang
+	var agedLevel int
+	var agedTime time.Time
+	highestLevel := f.list.GetHighestLevel()
+	deadline := now().Add(-f.maxWait)
+	for _, level := range f.list.Levels() {
+		if level == highestLevel {
+			continue
+		}
+		item, err := f.list.PeekItem(level)
+		if err != nil {
+			continue
+		}
+		gang := item.(*resmgrsvc.Gang)
+		enqueueTime, ok := f.enqueueTimes[gang]
+		if !ok || !enqueueTime.Before(deadline) {
+			continue
+		}
+		if aged == nil || enqueueTime.Before(agedTime) {
+			aged, agedLevel, agedTime = gang, level, enqueueTime
+		}
+	}
+	return aged, agedLevel, aged != nil
+}
+
+// removed updates the size of the queue and reports the wait time of a
+// gang removed from the given level.
+// NB: The function calling removed should acquire the lock
+func (f *PriorityQueue) removed(level int, gang *resmgrsvc.Gang) {
+	atomic.AddInt64(&f.size, -1)
+
+	enqueueTime, ok := f.enqueueTimes[gang]
+	if !ok {
+		return
+	}
+	delete(f.enqueueTimes, gang)
+	if f.scope != nil {
+		f.scope.Tagged(map[string]string{
+			"priority": strconv.Itoa(level),
+		}).Timer("wait_time").Record(now().Sub(enqueueTime))
+	}
+}
+
 func toGang(items []interface{}) []*resmgrsvc.Gang {
 	var gangs []*resmgrsvc.Gang
 	for _, item := range items {
@@ -155,15 +294,79 @@ func (f *PriorityQueue) Remove(gang *resmgrsvc.Gang) error {
 		"item ":    firstItem,
 		"priority": priority,
 	}).Debug("Trying to remove")
-	return f.list.Remove(int(priority), gang)
+	if err := f.list.Remove(int(priority), gang); err != nil {
+		return err
+	}
+	f.removed(int(priority), gang)
+	return nil
+}
+
+// RemoveTask removes the task with the given ID from the queue, so that
+// a task killed while pending does not stay in the queue until it is
+// dequeued. The gang of the task is removed from the queue if the task
+// is its only task, otherwise the task is removed from the gang.
+// It returns the removed task, or an `ErrorQueueEmpty` if the task is not
+// present in the queue.
+func (f *PriorityQueue) RemoveTask(taskID string) (*resmgr.Task, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	return removeTask(f.list, f.list.Levels(), taskID, f.removed)
+}
+
+// removeTask removes the task with the given ID from the gangs in the
+// given levels of the list. The gang of the task is removed from the list
+// if the task is its only task, and passed to removed, otherwise the task
+// is removed from the gang.
+// NB: The function calling removeTask should acquire the lock of the queue
+func removeTask(
+	list MultiLevelList,
+	levels []int,
+	taskID string,
+	removed func(level int, gang *resmgrsvc.Gang),
+) (*resmgr.Task, error) {
+	for _, level := range levels {
+		items, err := list.PeekItems(level, list.Len(level))
+		if err != nil {
+			continue
+		}
+
+		for _, gang := range toGang(items) {
+			for i, task := range gang.GetTasks() {
+				if task.GetId().GetValue() != taskID {
+					continue
+				}
+
+				if len(gang.Tasks) == 1 {
+					if err := list.Remove(level, gang); err != nil {
+						return nil, err
+					}
+					if removed != nil {
+						removed(level, gang)
+					}
+				} else {
+					// copy the remaining tasks to not modify the
+					// tasks of the gang seen by the callers
+					gang.Tasks = append(gang.Tasks[:i:i], gang.Tasks[i+1:]...)
+				}
+				return task, nil
+			}
+		}
+	}
+
+	return nil, ErrorQueueEmpty(
+		fmt.Sprintf("task %s not found in queue", taskID))
 }
 
 // Len returns the length of the queue for specified priority
 func (f *PriorityQueue) Len(priority int) int {
+	f.RLock()
+	defer f.RUnlock()
 	return f.list.Len(priority)
 }
 
-// Size returns the number of elements in the PriorityQueue
+// Size returns the number of gangs in the PriorityQueue. It does not take
+// the lock of the queue, so it can be called while the queue is in use.
 func (f *PriorityQueue) Size() int {
-	return f.list.Size()
+	return int(atomic.LoadInt64(&f.size))
 }
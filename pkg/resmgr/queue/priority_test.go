@@ -18,7 +18,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -29,6 +31,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 )
 
 type FifoQueueTestSuite struct {
@@ -212,6 +215,188 @@ func (suite *FifoQueueTestSuite) TestRemove() {
 	suite.Error(err)
 }
 
+func (suite *FifoQueueTestSuite) TestPeekHead() {
+	gang, err := suite.fq.PeekHead()
+	suite.NoError(err)
+	suite.Equal("job2-1", gang.Tasks[0].GetId().GetValue())
+	suite.Equal(4, suite.fq.Size())
+
+	q := NewPriorityQueue(1000)
+	_, err = q.PeekHead()
+	suite.EqualError(err, "peek failed, queue is empty")
+}
+
+func (suite *FifoQueueTestSuite) TestRemoveTask() {
+	// removing the only task of a gang removes the gang
+	task, err := suite.fq.RemoveTask("job2-1")
+	suite.NoError(err)
+	suite.Equal("job2-1", task.GetId().GetValue())
+	suite.Equal(1, suite.fq.Len(2))
+	suite.Equal(3, suite.fq.Size())
+
+	gang, err := suite.fq.PeekHead()
+	suite.NoError(err)
+	suite.Equal("job2-2", gang.Tasks[0].GetId().GetValue())
+
+	// removing a task of a gang with multiple tasks keeps the gang
+	enq1 := CreateResmgrTask(
+		&peloton.JobID{Value: "job3"},
+		&peloton.TaskID{Value: "job3-1"},
+		3)
+	enq2 := CreateResmgrTask(
+		&peloton.JobID{Value: "job3"},
+		&peloton.TaskID{Value: "job3-2"},
+		3)
+	tasks := []*resmgr.Task{enq1, enq2}
+	suite.NoError(suite.fq.Enqueue(&resmgrsvc.Gang{Tasks: tasks}))
+
+	task, err = suite.fq.RemoveTask("job3-1")
+	suite.NoError(err)
+	suite.Equal("job3-1", task.GetId().GetValue())
+	suite.Equal(1, suite.fq.Len(3))
+
+	gang, err = suite.fq.PeekHead()
+	suite.NoError(err)
+	suite.Len(gang.Tasks, 1)
+	suite.Equal("job3-2", gang.Tasks[0].GetId().GetValue())
+	// the tasks of the enqueued gang are not modified
+	suite.Equal("job3-1", tasks[0].GetId().GetValue())
+
+	// removing an unknown task fails
+	_, err = suite.fq.RemoveTask("job3-1")
+	suite.Error(err)
+	_, ok := err.(ErrorQueueEmpty)
+	suite.True(ok)
+}
+
+// TestConcurrentEnqueueDequeue checks that the size of the queue stays
+// consistent with its content while gangs are enqueued and dequeued
+// concurrently.
+func (suite *FifoQueueTestSuite) TestConcurrentEnqueueDequeue() {
+	q := NewPriorityQueue(math.MaxInt64)
+
+	numWorkers := 10
+	numGangs := 100
+	var dequeued int64
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < numGangs; i++ {
+				jobID := &peloton.JobID{Value: fmt.Sprintf("job-%d", w)}
+				taskID := &peloton.TaskID{
+					Value: fmt.Sprintf("%s-%d", jobID.Value, i)}
+				gang := &resmgrsvc.Gang{
+					Tasks: []*resmgr.Task{
+						CreateResmgrTask(jobID, taskID, uint32(i%5)),
+					},
+				}
+				suite.NoError(q.Enqueue(gang))
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < numGangs; i++ {
+				if _, err := q.Dequeue(); err == nil {
+					mu.Lock()
+					dequeued++
+					mu.Unlock()
+				}
+				q.Size()
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := int64(numWorkers * numGangs)
+	suite.Equal(int(total-dequeued), q.Size())
+
+	// drain the queue
+	for q.Size() > 0 {
+		_, err := q.Dequeue()
+		suite.NoError(err)
+		dequeued++
+	}
+	suite.Equal(total, dequeued)
+	_, err := q.Dequeue()
+	suite.Error(err)
+	suite.Equal(0, q.Size())
+}
+
+// TestAging checks that a gang waiting for longer than the max wait time
+// is dequeued ahead of the gangs of higher priorities.
+func (suite *FifoQueueTestSuite) TestAging() {
+	currentTime := time.Now()
+	now = func() time.Time { return currentTime }
+	defer func() { now = time.Now }()
+
+	scope := tally.NewTestScope("", map[string]string{})
+	q := NewAgingPriorityQueue(math.MaxInt64, time.Minute, scope)
+
+	newGang := func(id string, priority uint32) *resmgrsvc.Gang {
+		return &resmgrsvc.Gang{
+			Tasks: []*resmgr.Task{
+				CreateResmgrTask(
+					&peloton.JobID{Value: id},
+					&peloton.TaskID{Value: id + "-0"},
+					priority),
+			},
+		}
+	}
+	suite.NoError(q.Enqueue(newGang("low", 0)))
+	currentTime = currentTime.Add(30 * time.Second)
+	suite.NoError(q.Enqueue(newGang("medium", 1)))
+	suite.NoError(q.Enqueue(newGang("high1", 2)))
+	suite.NoError(q.Enqueue(newGang("high2", 2)))
+
+	// no gang waited for longer than the max wait time
+	gangs, err := q.Peek(4)
+	suite.NoError(err)
+	suite.Equal("high1", gangs[0].Tasks[0].GetJobId().GetValue())
+	suite.Equal("low", gangs[3].Tasks[0].GetJobId().GetValue())
+
+	head, err := q.PeekHead()
+	suite.NoError(err)
+	suite.Equal("high1", head.Tasks[0].GetJobId().GetValue())
+
+	// the low priority gang waited for longer than the max wait time
+	currentTime = currentTime.Add(45 * time.Second)
+	head, err = q.PeekHead()
+	suite.NoError(err)
+	suite.Equal("low", head.Tasks[0].GetJobId().GetValue())
+	gangs, err = q.Peek(4)
+	suite.NoError(err)
+	suite.Len(gangs, 4)
+	suite.Equal("low", gangs[0].Tasks[0].GetJobId().GetValue())
+	suite.Equal("high1", gangs[1].Tasks[0].GetJobId().GetValue())
+	suite.Equal("high2", gangs[2].Tasks[0].GetJobId().GetValue())
+	suite.Equal("medium", gangs[3].Tasks[0].GetJobId().GetValue())
+
+	gang, err := q.Dequeue()
+	suite.NoError(err)
+	suite.Equal("low", gang.Tasks[0].GetJobId().GetValue())
+	suite.Equal(3, q.Size())
+
+	timer, ok := scope.Snapshot().Timers()["wait_time+priority=0"]
+	suite.True(ok)
+	suite.Equal([]time.Duration{75 * time.Second}, timer.Values())
+
+	// the medium priority gang waited for longer than the max wait time
+	currentTime = currentTime.Add(time.Minute)
+	gang, err = q.Dequeue()
+	suite.NoError(err)
+	suite.Equal("medium", gang.Tasks[0].GetJobId().GetValue())
+
+	// gangs of the highest priority are dequeued in order
+	gang, err = q.Dequeue()
+	suite.NoError(err)
+	suite.Equal("high1", gang.Tasks[0].GetJobId().GetValue())
+	suite.Equal(1, q.Size())
+}
+
 func (suite *FifoQueueTestSuite) TestEnqueueError() {
 	err := suite.fq.Enqueue(nil)
 	suite.Error(err)
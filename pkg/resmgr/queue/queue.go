@@ -16,9 +16,13 @@ package queue
 
 import (
 	"errors"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber-go/tally"
 )
 
 // Queue is the interface implemented by all the the queues
@@ -33,18 +37,38 @@ type Queue interface {
 	// limit is the number of gangs to peek.
 	// It will return an error if there is no gang in the queue
 	Peek(limit uint32) ([]*resmgrsvc.Gang, error)
+	// PeekHead returns the gang which is dequeued next, without removing
+	// it from the queue.
+	// It will return an error if there is no gang in the queue
+	PeekHead() (*resmgrsvc.Gang, error)
 	// Remove removes the item from the queue
 	Remove(item *resmgrsvc.Gang) error
+	// RemoveTask removes the task with the given ID from the gang it
+	// belongs to in the queue, and the gang from the queue if the task is
+	// its only task. It returns the removed task.
+	// It will return an error if the task is not in the queue
+	RemoveTask(taskID string) (*resmgr.Task, error)
 	// Size returns the total number of items in the queue
 	Size() int
 }
 
-// CreateQueue is factory method to create the specified queue
-func CreateQueue(policy respool.SchedulingPolicy, limit int64) (Queue, error) {
+// CreateQueue is factory method to create the queue of the scheduling
+// policy of the resource pool config. The queue reports its metrics to
+// the given scope.
+func CreateQueue(
+	config *respool.ResourcePoolConfig,
+	limit int64,
+	scope tally.Scope) (Queue, error) {
 	// Factory method to create specific queue object based on policy
-	switch policy {
+	switch config.GetPolicy() {
 	case respool.SchedulingPolicy_PriorityFIFO:
-		return NewPriorityQueue(limit), nil
+		maxWait := time.Duration(
+			config.GetPriorityAging().GetMaxWaitSeconds()) * time.Second
+		return NewAgingPriorityQueue(limit, maxWait, scope), nil
+	case respool.SchedulingPolicy_FIFO:
+		return NewFIFOQueue(limit), nil
+	case respool.SchedulingPolicy_FairShare:
+		return NewFairShareQueue(limit), nil
 	default:
 		//if type is invalid, return an error
 		return nil, errors.New("invalid queue type")
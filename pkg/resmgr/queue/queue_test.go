@@ -16,10 +16,12 @@ package queue
 
 import (
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 )
 
 // QueueTestSuite is the struct for Queue Tests
@@ -33,14 +35,34 @@ func TestQueue(t *testing.T) {
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueSuccess() {
-	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100)
+	q, err := CreateQueue(&respool.ResourcePoolConfig{
+		Policy: respool.SchedulingPolicy_PriorityFIFO,
+		PriorityAging: &respool.PriorityAging{
+			MaxWaitSeconds: 60,
+		},
+	}, 100, tally.NoopScope)
 	suite.NoError(err)
-	suite.NotNil(q)
+	suite.IsType(&PriorityQueue{}, q)
+	suite.Equal(time.Minute, q.(*PriorityQueue).maxWait)
+
+	q, err = CreateQueue(&respool.ResourcePoolConfig{
+		Policy: respool.SchedulingPolicy_FIFO,
+	}, 100, tally.NoopScope)
+	suite.NoError(err)
+	suite.IsType(&FIFOQueue{}, q)
+
+	q, err = CreateQueue(&respool.ResourcePoolConfig{
+		Policy: respool.SchedulingPolicy_FairShare,
+	}, 100, tally.NoopScope)
+	suite.NoError(err)
+	suite.IsType(&FairShareQueue{}, q)
 }
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueError() {
-	q, err := CreateQueue(2, 100)
+	q, err := CreateQueue(&respool.ResourcePoolConfig{
+		Policy: respool.SchedulingPolicy_UNKNOWN,
+	}, 100, tally.NoopScope)
 	suite.Nil(q)
 	suite.Error(err)
 	suite.EqualError(err, "invalid queue type")
@@ -0,0 +1,208 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common/lifecycle"
+	r_queue "github.com/uber/peloton/pkg/resmgr/queue"
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	rmtask "github.com/uber/peloton/pkg/resmgr/task"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+)
+
+const (
+	// timeout to persist the queue snapshots of all resource pools
+	queueSnapshotTimeout = 30 * time.Second
+)
+
+// snapshotQueueTypes are the queues of a resource pool in the order
+// they are snapshotted and restored
+var snapshotQueueTypes = []respool.QueueType{
+	respool.NonPreemptibleQueue,
+	respool.ControllerQueue,
+	respool.RevocableQueue,
+	respool.PendingQueue,
+}
+
+// QueueSnapshotter periodically persists the pending gangs of each leaf
+// resource pool along with its gangs in the ready queue, so that the next
+// leader restores them in their state and order instead of re-enqueueing
+// the non-running tasks as they are read from the database. Snapshots are
+// only written while the snapshotter is running, which is while this
+// resource manager is the leader.
+type QueueSnapshotter struct {
+	metrics     *Metrics
+	resTree     respool.Tree
+	scheduler   rmtask.Scheduler
+	tracker     rmtask.Tracker
+	snapshotOps ormobjects.QueueSnapshotOps
+	period      time.Duration
+
+	// Lifecycle manager
+	lifecycle lifecycle.LifeCycle
+}
+
+// NewQueueSnapshotter initializes the QueueSnapshotter
+func NewQueueSnapshotter(
+	parent tally.Scope,
+	tree respool.Tree,
+	scheduler rmtask.Scheduler,
+	tracker rmtask.Tracker,
+	snapshotOps ormobjects.QueueSnapshotOps,
+	config Config,
+) *QueueSnapshotter {
+	return &QueueSnapshotter{
+		metrics:     NewMetrics(parent),
+		resTree:     tree,
+		scheduler:   scheduler,
+		tracker:     tracker,
+		snapshotOps: snapshotOps,
+		period:      config.QueueSnapshotPeriod,
+		lifecycle:   lifecycle.NewLifeCycle(),
+	}
+}
+
+// Start starts persisting the queue snapshots periodically
+func (s *QueueSnapshotter) Start() error {
+	if s.period == 0 {
+		log.Info("Queue snapshots are disabled")
+		return nil
+	}
+
+	if !s.lifecycle.Start() {
+		log.Warn("Queue snapshotter is already started, no" +
+			" action will be performed")
+		return nil
+	}
+
+	go func() {
+		defer s.lifecycle.StopComplete()
+
+		ticker := time.NewTicker(s.period)
+		defer ticker.Stop()
+
+		log.Info("Starting queue snapshotter")
+
+		for {
+			select {
+			case <-s.lifecycle.StopCh():
+				log.Info("Exiting queue snapshotter")
+				return
+			case <-ticker.C:
+				if err := s.Snapshot(); err != nil {
+					log.WithError(err).Warn("Failed to snapshot queues")
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the periodic snapshots. No snapshot is persisted once it
+// returns, as another resource manager may already be the leader.
+func (s *QueueSnapshotter) Stop() error {
+	if !s.lifecycle.Stop() {
+		log.Warn("Queue snapshotter is already stopped, no" +
+			" action will be performed")
+		return nil
+	}
+	log.Info("Stopping queue snapshotter")
+
+	s.lifecycle.Wait()
+	log.Info("Queue snapshotter stopped")
+	return nil
+}
+
+// Snapshot persists the pending and ready gangs of every leaf resource
+// pool. The snapshot of an empty resource pool is persisted too, so that a
+// stale snapshot is not restored by the next leader.
+func (s *QueueSnapshotter) Snapshot() error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		queueSnapshotTimeout)
+	defer cancel()
+
+	readyGangs := s.readyGangsByResPool()
+
+	var lastErr error
+	nodes := s.resTree.GetAllNodes(true)
+	for e := nodes.Front(); e != nil; e = e.Next() {
+		node := e.Value.(respool.ResPool)
+
+		gangs, err := peekQueuedGangs(node)
+		if err == nil {
+			err = s.snapshotOps.Create(
+				ctx,
+				node.ID(),
+				gangs,
+				readyGangs[node.ID()])
+		}
+		if err != nil {
+			s.metrics.QueueSnapshotFail.Inc(1)
+			log.WithError(err).
+				WithField("respool_id", node.ID()).
+				Warn("Failed to snapshot resource pool queues")
+			lastErr = err
+			continue
+		}
+		s.metrics.QueueSnapshotSuccess.Inc(1)
+	}
+	return lastErr
+}
+
+// readyGangsByResPool returns the gangs in the ready queue keyed by the ID
+// of their resource pool. Gangs whose tasks are no longer tracked are left
+// out.
+func (s *QueueSnapshotter) readyGangsByResPool() map[string][]*resmgrsvc.Gang {
+	readyGangs := make(map[string][]*resmgrsvc.Gang)
+	for _, gang := range s.scheduler.PeekGangs() {
+		rmTask := s.tracker.GetTask(gang.GetTasks()[0].GetId())
+		if rmTask == nil {
+			continue
+		}
+		id := rmTask.Respool().ID()
+		readyGangs[id] = append(readyGangs[id], gang)
+	}
+	return readyGangs
+}
+
+// peekQueuedGangs returns all the gangs queued in a resource pool in the
+// order of snapshotQueueTypes
+func peekQueuedGangs(node respool.ResPool) ([]*resmgrsvc.Gang, error) {
+	var queued []*resmgrsvc.Gang
+	for _, qt := range snapshotQueueTypes {
+		gangs, err := node.PeekGangs(qt, math.MaxInt32)
+		if err != nil {
+			if _, ok := err.(r_queue.ErrorQueueEmpty); ok {
+				// queue is empty, move to the next one
+				continue
+			}
+			return nil, errors.Wrap(err, "failed to peek queued gangs")
+		}
+		queued = append(queued, gangs...)
+	}
+	return queued, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resmgr
+
+import (
+	"container/list"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	r_queue "github.com/uber/peloton/pkg/resmgr/queue"
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	rm "github.com/uber/peloton/pkg/resmgr/respool/mocks"
+	rm_task "github.com/uber/peloton/pkg/resmgr/task"
+	task_mocks "github.com/uber/peloton/pkg/resmgr/task/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+// TestQueueSnapshotterSnapshot tests that the pending and ready gangs of
+// the leaf resource pools are persisted in order.
+func TestQueueSnapshotterSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree := rm.NewMockTree(ctrl)
+	node := rm.NewMockResPool(ctrl)
+	scheduler := task_mocks.NewMockScheduler(ctrl)
+	tracker := task_mocks.NewMockTracker(ctrl)
+	snapshotOps := objectmocks.NewMockQueueSnapshotOps(ctrl)
+
+	nodes := list.New()
+	nodes.PushBack(node)
+	tree.EXPECT().GetAllNodes(true).Return(nodes)
+
+	npGang := newRecoveryGang("job-0", "job-0-1")
+	pendingGang := newRecoveryGang("job-1", "job-1-1")
+	node.EXPECT().ID().Return("respool1").AnyTimes()
+	node.EXPECT().PeekGangs(respool.NonPreemptibleQueue, gomock.Any()).
+		Return([]*resmgrsvc.Gang{npGang}, nil)
+	node.EXPECT().PeekGangs(respool.ControllerQueue, gomock.Any()).
+		Return(nil, r_queue.ErrorQueueEmpty("empty"))
+	node.EXPECT().PeekGangs(respool.RevocableQueue, gomock.Any()).
+		Return(nil, r_queue.ErrorQueueEmpty("empty"))
+	node.EXPECT().PeekGangs(respool.PendingQueue, gomock.Any()).
+		Return([]*resmgrsvc.Gang{pendingGang}, nil)
+
+	readyGang := newRecoveryGang("job-2", "job-2-1")
+	untrackedGang := newRecoveryGang("job-3", "job-3-1")
+	readyTask, err := rm_task.CreateRMTask(
+		tally.NoopScope,
+		readyGang.GetTasks()[0],
+		nil,
+		node,
+		&rm_task.Config{
+			LaunchingTimeout: time.Minute,
+			PlacingTimeout:   time.Minute,
+			PolicyName:       rm_task.ExponentialBackOffPolicy,
+		})
+	assert.NoError(t, err)
+	scheduler.EXPECT().PeekGangs().
+		Return([]*resmgrsvc.Gang{readyGang, untrackedGang})
+	tracker.EXPECT().GetTask(readyGang.GetTasks()[0].GetId()).
+		Return(readyTask)
+	tracker.EXPECT().GetTask(untrackedGang.GetTasks()[0].GetId()).
+		Return(nil)
+
+	snapshotOps.EXPECT().Create(
+		gomock.Any(),
+		"respool1",
+		[]*resmgrsvc.Gang{npGang, pendingGang},
+		[]*resmgrsvc.Gang{readyGang}).
+		Return(nil)
+
+	s := NewQueueSnapshotter(
+		tally.NoopScope, tree, scheduler, tracker, snapshotOps, Config{})
+	assert.NoError(t, s.Snapshot())
+}
+
+// TestQueueSnapshotterSnapshotError tests that a failure to snapshot a
+// resource pool is returned.
+func TestQueueSnapshotterSnapshotError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tree := rm.NewMockTree(ctrl)
+	node := rm.NewMockResPool(ctrl)
+	scheduler := task_mocks.NewMockScheduler(ctrl)
+	snapshotOps := objectmocks.NewMockQueueSnapshotOps(ctrl)
+
+	nodes := list.New()
+	nodes.PushBack(node)
+	tree.EXPECT().GetAllNodes(true).Return(nodes)
+	scheduler.EXPECT().PeekGangs().Return(nil)
+
+	node.EXPECT().ID().Return("respool1").AnyTimes()
+	node.EXPECT().PeekGangs(gomock.Any(), gomock.Any()).
+		Return(nil, r_queue.ErrorQueueEmpty("empty")).Times(4)
+	snapshotOps.EXPECT().
+		Create(gomock.Any(), "respool1", gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+
+	s := NewQueueSnapshotter(
+		tally.NoopScope,
+		tree,
+		scheduler,
+		task_mocks.NewMockTracker(ctrl),
+		snapshotOps,
+		Config{})
+	assert.EqualError(t, s.Snapshot(), "create failed")
+}
+
+// TestQueueSnapshotterStartStop tests that nothing is persisted when the
+// snapshotter is stopped, as another resource manager may already be the
+// leader.
+func TestQueueSnapshotterStartStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewQueueSnapshotter(
+		tally.NoopScope,
+		rm.NewMockTree(ctrl),
+		task_mocks.NewMockScheduler(ctrl),
+		task_mocks.NewMockTracker(ctrl),
+		objectmocks.NewMockQueueSnapshotOps(ctrl),
+		Config{QueueSnapshotPeriod: time.Hour})
+	assert.NoError(t, s.Start())
+	assert.NoError(t, s.Stop())
+
+	// Stopping again is a no-op
+	assert.NoError(t, s.Stop())
+}
+
+// TestQueueSnapshotterDisabled tests that nothing is persisted when the
+// snapshots are disabled.
+func TestQueueSnapshotterDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := NewQueueSnapshotter(
+		tally.NoopScope,
+		rm.NewMockTree(ctrl),
+		task_mocks.NewMockScheduler(ctrl),
+		task_mocks.NewMockTracker(ctrl),
+		objectmocks.NewMockQueueSnapshotOps(ctrl),
+		Config{})
+	assert.NoError(t, s.Start())
+	assert.NoError(t, s.Stop())
+}
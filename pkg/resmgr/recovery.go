@@ -24,6 +24,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/models"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/common/lifecycle"
@@ -31,6 +32,7 @@ import (
 	"github.com/uber/peloton/pkg/common/statemachine"
 	taskutil "github.com/uber/peloton/pkg/common/util/task"
 	"github.com/uber/peloton/pkg/resmgr/respool"
+	"github.com/uber/peloton/pkg/resmgr/scalar"
 	rmtask "github.com/uber/peloton/pkg/resmgr/task"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
@@ -88,7 +90,10 @@ incoming requests.
 
 Phase 2 - This phase is performed in the background and involves recovery of
 non-running tasks by the re-enqueueing them resource manager.
-Failure in this phase is non-fatal.
+The gangs of the queue snapshots persisted by the previous leader are
+restored first: the ready gangs are put back in the ready queue and the
+pending gangs are enqueued in their snapshotted order, followed by the
+remaining non-running tasks. Failure in this phase is non-fatal.
 
 Recovery of maintenance queue is performed
 */
@@ -99,6 +104,7 @@ type RecoveryHandler struct {
 	activeJobsOps   ormobjects.ActiveJobsOps
 	jobConfigOps    ormobjects.JobConfigOps
 	jobRuntimeOps   ormobjects.JobRuntimeOps
+	snapshotOps     ormobjects.QueueSnapshotOps
 	handler         *ServiceHandler
 	config          Config
 	hostmgrClient   hostsvc.InternalHostServiceYARPCClient
@@ -120,6 +126,7 @@ func NewRecovery(
 	activeJobsOps ormobjects.ActiveJobsOps,
 	jobConfigOps ormobjects.JobConfigOps,
 	jobRuntimeOps ormobjects.JobRuntimeOps,
+	snapshotOps ormobjects.QueueSnapshotOps,
 	handler *ServiceHandler,
 	tree respool.Tree,
 	config Config,
@@ -133,6 +140,7 @@ func NewRecovery(
 		activeJobsOps: activeJobsOps,
 		jobConfigOps:  jobConfigOps,
 		jobRuntimeOps: jobRuntimeOps,
+		snapshotOps:   snapshotOps,
 		handler:       handler,
 		hostmgrClient: hostmgrClient,
 		tracker:       rmtask.GetTracker(),
@@ -201,7 +209,7 @@ func (r *RecoveryHandler) recoverNonRunningTasks() {
 	defer cancel()
 	successTasks, failedTasks := 0, 0
 
-	for _, nr := range r.nonRunningTasks {
+	for _, nr := range r.restoreQueueSnapshots(ctx) {
 		select {
 		case <-r.lifecycle.StopCh():
 			return
@@ -245,6 +253,216 @@ func (r *RecoveryHandler) recoverNonRunningTasks() {
 	log.Info("Recovery of non running tasks completed")
 }
 
+// restoreQueueSnapshots restores the gangs of the queue snapshots persisted
+// by the previous leader and returns the enqueue requests of the rest of the
+// non-running tasks. A snapshotted gang is restored only if all of its tasks
+// were recovered as non-running in the same resource pool and with the same
+// mesos task id. The ready gangs are put back in the ready queue directly,
+// and the pending gangs are enqueued first in their snapshotted order. The
+// restored tasks are left out of the requests of the non-running tasks
+// read from the DB, so they are not enqueued again.
+func (r *RecoveryHandler) restoreQueueSnapshots(
+	ctx context.Context) []*resmgrsvc.EnqueueGangsRequest {
+	if r.snapshotOps == nil || r.config.QueueSnapshotPeriod == 0 {
+		return r.nonRunningTasks
+	}
+
+	snapshots, err := r.snapshotOps.GetAll(ctx)
+	if err != nil {
+		log.WithError(err).
+			Warn("Failed to load queue snapshots, enqueueing recovered tasks")
+		return r.nonRunningTasks
+	}
+
+	// respool IDs of the non-running tasks keyed by task ID
+	respools := make(map[string]string)
+	recovered := make(map[string]*resmgr.Task)
+	for _, request := range r.nonRunningTasks {
+		for _, gang := range request.GetGangs() {
+			for _, t := range gang.GetTasks() {
+				respools[t.GetId().GetValue()] = request.GetResPool().GetValue()
+				recovered[t.GetId().GetValue()] = t
+			}
+		}
+	}
+
+	var requests []*resmgrsvc.EnqueueGangsRequest
+	restored := make(map[string]bool)
+	restoredGangs := 0
+	for _, snapshot := range snapshots {
+		if r.config.QueueSnapshotMaxAge > 0 &&
+			time.Since(snapshot.UpdateTime) > r.config.QueueSnapshotMaxAge {
+			log.WithField("respool_id", snapshot.RespoolID).
+				WithField("update_time", snapshot.UpdateTime).
+				Info("Skipping stale queue snapshot")
+			continue
+		}
+
+		// gangs of the snapshot to enqueue in the pending queue, starting
+		// with the ready gangs which fail to be restored
+		var gangs []*resmgrsvc.Gang
+		for _, gang := range snapshot.ReadyGangs {
+			if !isGangRestorable(
+				gang, snapshot.RespoolID, respools, recovered, restored) {
+				continue
+			}
+			for _, t := range gang.GetTasks() {
+				restored[t.GetId().GetValue()] = true
+			}
+			restoredGangs++
+			if err := r.restoreReadyGang(snapshot.RespoolID, gang); err != nil {
+				log.WithError(err).
+					WithField("respool_id", snapshot.RespoolID).
+					WithField("gang", gang).
+					Warn("Failed to restore ready gang, enqueueing it")
+				gangs = append(gangs, gang)
+			}
+		}
+
+		for _, gang := range snapshot.PendingGangs {
+			if !isGangRestorable(
+				gang, snapshot.RespoolID, respools, recovered, restored) {
+				continue
+			}
+			for _, t := range gang.GetTasks() {
+				restored[t.GetId().GetValue()] = true
+			}
+			restoredGangs++
+			gangs = append(gangs, gang)
+		}
+
+		if len(gangs) == 0 {
+			continue
+		}
+		requests = append(requests, &resmgrsvc.EnqueueGangsRequest{
+			ResPool: &peloton.ResourcePoolID{Value: snapshot.RespoolID},
+			Gangs:   gangs,
+		})
+	}
+
+	// enqueue the non-running tasks which were not snapshotted
+	for _, request := range r.nonRunningTasks {
+		var gangs []*resmgrsvc.Gang
+		for _, gang := range request.GetGangs() {
+			var tasks []*resmgr.Task
+			for _, t := range gang.GetTasks() {
+				if !restored[t.GetId().GetValue()] {
+					tasks = append(tasks, t)
+				}
+			}
+			if len(tasks) > 0 {
+				gangs = append(gangs, &resmgrsvc.Gang{Tasks: tasks})
+			}
+		}
+
+		if len(gangs) > 0 {
+			requests = append(requests, &resmgrsvc.EnqueueGangsRequest{
+				ResPool: request.GetResPool(),
+				Gangs:   gangs,
+			})
+		}
+	}
+
+	r.metrics.RecoverySnapshotGangCount.Inc(int64(restoredGangs))
+	log.WithField("restored_gangs", restoredGangs).
+		Info("Restored gangs from queue snapshots")
+	return requests
+}
+
+// restoreReadyGang puts a snapshotted gang back in the ready queue, with
+// its tasks in READY state and their resources allocated to the resource
+// pool, as they were left by the previous leader. The gang is not admitted
+// again, so the resource pool may go over its entitlement until the gang is
+// placed. Nothing is left behind if the gang fails to be restored.
+func (r *RecoveryHandler) restoreReadyGang(
+	respoolID string,
+	gang *resmgrsvc.Gang) error {
+	pool, err := r.resTree.Get(&peloton.ResourcePoolID{Value: respoolID})
+	if err != nil {
+		return err
+	}
+
+	var added []*resmgr.Task
+	rollback := func() {
+		for _, t := range added {
+			r.tracker.DeleteTask(t.GetId())
+		}
+	}
+
+	for _, t := range gang.GetTasks() {
+		if r.tracker.GetTask(t.GetId()) != nil {
+			rollback()
+			return errors.Errorf("task %s is already tracked",
+				t.GetId().GetValue())
+		}
+		if _, err := r.handler.addTask(t, pool); err != nil {
+			rollback()
+			return err
+		}
+		added = append(added, t)
+
+		rmTask := r.tracker.GetTask(t.GetId())
+		if err := rmTask.TransitTo(
+			task.TaskState_PENDING.String(),
+			statemachine.WithReason("restored from queue snapshot"),
+			statemachine.WithInfo(mesosTaskID, t.GetTaskId().GetValue()),
+		); err != nil {
+			rollback()
+			return err
+		}
+		if err := rmTask.TransitTo(
+			task.TaskState_READY.String(),
+			statemachine.WithReason("restored from queue snapshot"),
+		); err != nil {
+			rollback()
+			return err
+		}
+	}
+
+	allocation := scalar.GetGangAllocation(gang)
+	if err := pool.AddToAllocation(allocation); err != nil {
+		rollback()
+		return err
+	}
+
+	if err := rmtask.GetScheduler().EnqueueGang(gang); err != nil {
+		if err := pool.SubtractFromAllocation(allocation); err != nil {
+			log.WithError(err).
+				WithField("respool_id", respoolID).
+				Error("Failed to remove allocation of ready gang")
+		}
+		rollback()
+		return err
+	}
+	return nil
+}
+
+// isGangRestorable returns true if all the tasks of a snapshotted gang were
+// recovered as non-running in the same resource pool and with the same mesos
+// task id, and none of them is restored already.
+func isGangRestorable(
+	gang *resmgrsvc.Gang,
+	respoolID string,
+	respools map[string]string,
+	recovered map[string]*resmgr.Task,
+	restored map[string]bool) bool {
+	if len(gang.GetTasks()) == 0 {
+		return false
+	}
+
+	for _, t := range gang.GetTasks() {
+		id := t.GetId().GetValue()
+		rt, ok := recovered[id]
+		if !ok || restored[id] || respools[id] != respoolID {
+			return false
+		}
+		if rt.GetTaskId().GetValue() != t.GetTaskId().GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *RecoveryHandler) requeueTasksInRange(ctx context.Context,
 	jobID string, jobConfig *job.JobConfig, configAddOn *models.ConfigAddOn,
 	jobRuntime *job.RuntimeInfo, batch cmn_recovery.TasksBatch, errChan chan<- error) {
@@ -41,6 +41,7 @@ import (
 	rm_task "github.com/uber/peloton/pkg/resmgr/task"
 	task_mocks "github.com/uber/peloton/pkg/resmgr/task/mocks"
 	store_mocks "github.com/uber/peloton/pkg/storage/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -63,6 +64,7 @@ type recoveryTestSuite struct {
 	jobConfigOps      *objectmocks.MockJobConfigOps
 	jobRuntimeOps     *objectmocks.MockJobRuntimeOps
 	mockResPoolOps    *objectmocks.MockResPoolOps
+	queueSnapshotOps  *objectmocks.MockQueueSnapshotOps
 	mockHostmgrClient *host_mocks.MockInternalHostServiceYARPCClient
 }
 
@@ -76,6 +78,7 @@ func (suite *recoveryTestSuite) SetupSuite() {
 	suite.activeJobsOps = objectmocks.NewMockActiveJobsOps(suite.mockCtrl)
 	suite.jobConfigOps = objectmocks.NewMockJobConfigOps(suite.mockCtrl)
 	suite.jobRuntimeOps = objectmocks.NewMockJobRuntimeOps(suite.mockCtrl)
+	suite.queueSnapshotOps = objectmocks.NewMockQueueSnapshotOps(suite.mockCtrl)
 	suite.mockHostmgrClient = host_mocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
 
 	suite.resourceTree = rp.NewTree(tally.NoopScope, suite.mockResPoolOps, suite.mockJobStore,
@@ -93,6 +96,7 @@ func (suite *recoveryTestSuite) SetupSuite() {
 		tally.NoopScope,
 		suite.resourceTree,
 		100*time.Second,
+		0,
 		suite.rmTaskTracker,
 	)
 	suite.taskScheduler = rm_task.GetScheduler()
@@ -131,6 +135,7 @@ func (suite *recoveryTestSuite) SetupTest() {
 		suite.activeJobsOps,
 		suite.jobConfigOps,
 		suite.jobRuntimeOps,
+		suite.queueSnapshotOps,
 		suite.handler,
 		suite.resourceTree,
 		Config{
@@ -665,6 +670,149 @@ func (suite *recoveryTestSuite) TestRecoverNonRunningTasks_Stop() {
 	<-suite.recovery.finished
 }
 
+func newRecoveryGang(taskID string, runID string) *resmgrsvc.Gang {
+	return &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			{
+				Id:     &peloton.TaskID{Value: taskID},
+				TaskId: &mesos.TaskID{Value: &runID},
+			},
+		},
+	}
+}
+
+// TestRestoreQueueSnapshots tests that the snapshotted ready gangs of the
+// recovered non-running tasks are restored in the ready queue, and that the
+// snapshotted pending gangs are enqueued first in their snapshotted order.
+func (suite *recoveryTestSuite) TestRestoreQueueSnapshots() {
+	suite.recovery.config.QueueSnapshotPeriod = time.Minute
+	suite.recovery.config.QueueSnapshotMaxAge = 10 * time.Minute
+
+	suite.recovery.nonRunningTasks = []*resmgrsvc.EnqueueGangsRequest{
+		{
+			ResPool: &peloton.ResourcePoolID{Value: "respool11"},
+			Gangs: []*resmgrsvc.Gang{
+				newRecoveryGang("job-0", "job-0-1"),
+				newRecoveryGang("job-1", "job-1-1"),
+				newRecoveryGang("job-2", "job-2-1"),
+			},
+		},
+	}
+
+	suite.queueSnapshotOps.EXPECT().GetAll(gomock.Any()).Return(
+		[]*ormobjects.QueueSnapshot{
+			{
+				RespoolID: "respool11",
+				PendingGangs: []*resmgrsvc.Gang{
+					newRecoveryGang("job-2", "job-2-1"),
+					// previous run of the task
+					newRecoveryGang("job-0", "job-0-0"),
+					// task which is not recovered as non-running
+					newRecoveryGang("job-3", "job-3-1"),
+				},
+				ReadyGangs: []*resmgrsvc.Gang{
+					newRecoveryGang("job-1", "job-1-1"),
+				},
+				UpdateTime: time.Now(),
+			},
+			{
+				// stale snapshot
+				RespoolID: "respool11",
+				PendingGangs: []*resmgrsvc.Gang{
+					newRecoveryGang("job-0", "job-0-1"),
+				},
+				UpdateTime: time.Now().Add(-time.Hour),
+			},
+			{
+				// task recovered in a different resource pool
+				RespoolID: "respool12",
+				PendingGangs: []*resmgrsvc.Gang{
+					newRecoveryGang("job-0", "job-0-1"),
+				},
+				UpdateTime: time.Now(),
+			},
+		}, nil)
+
+	requests := suite.recovery.restoreQueueSnapshots(context.Background())
+	suite.Len(requests, 2)
+
+	suite.Equal("respool11", requests[0].GetResPool().GetValue())
+	suite.Len(requests[0].GetGangs(), 1)
+	suite.Equal("job-2",
+		requests[0].GetGangs()[0].GetTasks()[0].GetId().GetValue())
+
+	suite.Equal("respool11", requests[1].GetResPool().GetValue())
+	suite.Len(requests[1].GetGangs(), 1)
+	suite.Equal("job-0",
+		requests[1].GetGangs()[0].GetTasks()[0].GetId().GetValue())
+
+	// the ready gang is restored directly
+	readyID := &peloton.TaskID{Value: "job-1"}
+	rmTask := suite.rmTaskTracker.GetTask(readyID)
+	suite.NotNil(rmTask)
+	suite.Equal(task.TaskState_READY, rmTask.GetCurrentState().State)
+	suite.Equal("respool11", rmTask.Respool().ID())
+
+	gang, err := suite.taskScheduler.DequeueGang(
+		time.Second, resmgr.TaskType_UNKNOWN)
+	suite.NoError(err)
+	suite.Equal("job-1", gang.GetTasks()[0].GetId().GetValue())
+	suite.rmTaskTracker.DeleteTask(readyID)
+}
+
+// TestRestoreReadyGangFailure tests that a ready gang which fails to be
+// restored is enqueued in the pending queue and nothing is left behind.
+func (suite *recoveryTestSuite) TestRestoreReadyGangFailure() {
+	suite.recovery.config.QueueSnapshotPeriod = time.Minute
+
+	suite.recovery.nonRunningTasks = []*resmgrsvc.EnqueueGangsRequest{
+		{
+			ResPool: &peloton.ResourcePoolID{Value: "respool-unknown"},
+			Gangs: []*resmgrsvc.Gang{
+				newRecoveryGang("job-0", "job-0-1"),
+			},
+		},
+	}
+
+	suite.queueSnapshotOps.EXPECT().GetAll(gomock.Any()).Return(
+		[]*ormobjects.QueueSnapshot{
+			{
+				RespoolID: "respool-unknown",
+				ReadyGangs: []*resmgrsvc.Gang{
+					newRecoveryGang("job-0", "job-0-1"),
+				},
+				UpdateTime: time.Now(),
+			},
+		}, nil)
+
+	requests := suite.recovery.restoreQueueSnapshots(context.Background())
+	suite.Len(requests, 1)
+	suite.Equal("respool-unknown", requests[0].GetResPool().GetValue())
+	suite.Len(requests[0].GetGangs(), 1)
+	suite.Nil(suite.rmTaskTracker.GetTask(&peloton.TaskID{Value: "job-0"}))
+}
+
+// TestRestoreQueueSnapshotsError tests that the non-running tasks are
+// enqueued as recovered if the queue snapshots fail to load.
+func (suite *recoveryTestSuite) TestRestoreQueueSnapshotsError() {
+	suite.recovery.config.QueueSnapshotPeriod = time.Minute
+	suite.recovery.nonRunningTasks = []*resmgrsvc.EnqueueGangsRequest{
+		{
+			ResPool: &peloton.ResourcePoolID{Value: "respool11"},
+			Gangs: []*resmgrsvc.Gang{
+				newRecoveryGang("job-0", "job-0-1"),
+			},
+		},
+	}
+
+	suite.queueSnapshotOps.EXPECT().GetAll(gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	suite.Equal(
+		suite.recovery.nonRunningTasks,
+		suite.recovery.restoreQueueSnapshots(context.Background()))
+}
+
 func TestResmgrRecovery(t *testing.T) {
 	suite.Run(t, new(recoveryTestSuite))
 }
@@ -15,7 +15,6 @@
 package respool
 
 import (
-	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/resmgr/scalar"
@@ -25,9 +24,8 @@ import (
 )
 
 var (
-	errGangInvalid          = errors.New("gang is invalid")
-	errGangValidationFailed = errors.New("gang validation failed")
-	errResourcePoolFull     = errors.New("resource pool full")
+	errGangInvalid      = errors.New("gang is invalid")
+	errResourcePoolFull = errors.New("resource pool full")
 
 	errSkipControllerGang = errors.New(
 		"skipping controller gang from admitting")
@@ -35,6 +33,8 @@ var (
 		"skipping non-preemptible gang from admitting")
 	errSkipRevocableGang = errors.New(
 		"skipping revocable gang from admitting")
+	errOwnerQuotaExceeded = errors.New(
+		"gang exceeds the quota of its owner or job")
 )
 
 // QueueType defines the different queues of the resource pool from which
@@ -135,7 +135,8 @@ func controllerAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 // 2. Lower priority gangs will not be admitted if
 //    (higher priority allocation) > reservation
 // Peloton takes approach 1 by checking the total allocation of all
-// non-preemptible gangs and the resource pool reservation.
+// non-preemptible gangs and the resource pool reservation, including the
+// resources guaranteed by its active capacity reservations.
 func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 	if !pool.isPreemptionEnabled() ||
 		isPreemptible(gang) ||
@@ -148,7 +149,7 @@ func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 
 	npAllocation := pool.allocation.GetByType(scalar.NonPreemptibleAllocation)
 	neededResources := scalar.GetGangResources(gang)
-	reservation := pool.reservation
+	reservation := pool.reservation.Add(pool.capacityReserved)
 
 	log.WithFields(log.Fields{
 		"respool_id":            pool.id,
@@ -162,6 +163,58 @@ func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 		LessThanOrEqual(reservation)
 }
 
+// returns true if the gang can be admitted without exceeding the quota of
+// the owners and jobs of its tasks in the pool
+func ownerQuotaAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
+	quota := pool.poolConfig.GetOwnerQuota()
+	if quota == nil {
+		return true
+	}
+
+	gangAllocation := scalar.GetGangAllocation(gang)
+
+	maxTasksPerOwner := int(quota.GetMaxTasksPerOwner())
+	var maxResourcesPerOwner *scalar.Resources
+	if pct := quota.GetMaxPercentPerOwner(); pct > 0 {
+		maxResourcesPerOwner = pool.entitlement.Multiply(pct / 100)
+	}
+	for owner, needed := range gangAllocation.Owners {
+		usage := pool.usages.GetOwnerUsage(owner)
+
+		log.WithFields(log.Fields{
+			"respool_id":         pool.id,
+			"owner":              owner,
+			"owner_tasks":        usage.Tasks,
+			"owner_alloc":        usage.Resources,
+			"max_resources":      maxResourcesPerOwner,
+			"resources_required": needed.Resources,
+		}).Debug("checking owner quota")
+
+		if maxTasksPerOwner > 0 &&
+			usage.Tasks+needed.Tasks > maxTasksPerOwner {
+			return false
+		}
+		if maxResourcesPerOwner != nil &&
+			!usage.Resources.
+				Add(needed.Resources).
+				LessThanOrEqual(maxResourcesPerOwner) {
+			return false
+		}
+	}
+
+	maxTasksPerJob := int(quota.GetMaxTasksPerJob())
+	if maxTasksPerJob == 0 {
+		return true
+	}
+	for jobID, needed := range gangAllocation.Jobs {
+		usage := pool.usages.GetJobUsage(jobID)
+		if usage.Tasks+needed.Tasks > maxTasksPerJob {
+			return false
+		}
+	}
+	return true
+}
+
 type admissionController struct {
 	admitters []admitter
 }
@@ -184,16 +237,17 @@ func (ac admissionController) TryAdmit(
 	pool.Lock()
 	defer pool.Unlock()
 
-	ok, err := ac.validateGang(gang, pool, qt)
-	if err != nil {
-		return errGangValidationFailed
-	}
-
 	// Gang is invalid
-	if !ok {
+	if gang == nil || len(gang.GetTasks()) == 0 {
 		return errGangInvalid
 	}
 
+	// The gang is kept in its queue if its owner or job has exhausted its
+	// quota, so that the gangs behind it can still be admitted.
+	if !ownerQuotaAdmitter(gang, pool) {
+		return errOwnerQuotaExceeded
+	}
+
 	if admitted := ac.canAdmit(gang, pool); !admitted {
 		if qt == PendingQueue {
 			// If a gang can't be admitted from the pending queue to the resource
@@ -243,7 +297,9 @@ func (ac admissionController) TryAdmit(
 		return err
 	}
 
-	pool.allocation = pool.allocation.Add(scalar.GetGangAllocation(gang))
+	gangAllocation := scalar.GetGangAllocation(gang)
+	pool.allocation = pool.allocation.Add(gangAllocation)
+	pool.usages.Add(gangAllocation)
 	return nil
 }
 
@@ -275,92 +331,6 @@ func (ac admissionController) moveToQueue(
 	return nil
 }
 
-// validateGang checks for all/some tasks been deleted. This function will delete that
-// gang from the queue if all tasks are deleted or enqueue gang back with
-// rest of the tasks in the gang.
-// Returns true if the gang is valid and ready for admission control,
-// false if the gang is invalid and an optional error if the validation failed.
-func (ac admissionController) validateGang(
-	gang *resmgrsvc.Gang,
-	pool *resPool,
-	qt QueueType) (bool, error) {
-
-	// return false if gang is nil
-	if gang == nil {
-		return false, nil
-	}
-
-	// list of tasks which are still valid inside the gang.
-	// There could be cases where only some tasks are invalidated.
-	// In such cases a new gang is created with the valid tasks and enqueued
-	// back to the queue.
-	var validTasks []*resmgr.Task
-	isGangValid := true
-	for _, task := range gang.GetTasks() {
-		// check if the task in the gang has been invalidated
-		if _, exists := pool.invalidTasks[task.Id.Value]; !exists {
-			validTasks = append(validTasks, task)
-		} else {
-			// the task is invalid so we mark the gang as invalid
-			delete(pool.invalidTasks, task.Id.Value)
-			isGangValid = false
-		}
-	}
-
-	// Gang is validated so ready for admission control
-	if isGangValid {
-		return true, nil
-	}
-
-	// Now we perform the cleanup of the invalid gang and if required requeue
-	// the gang with the valid tasks back to the queue.
-	log.WithFields(log.Fields{
-		"respool_name": pool.poolConfig.Name,
-		"respool_id":   pool.id,
-		"gang":         gang,
-		"queue_type":   qt,
-	}).Info("Tasks are invalidated for this gang")
-
-	// Removing the gang from queue as tasks are deleted
-	// from this gang
-	if err := removeGangFromQueue(
-		pool,
-		qt,
-		gang); err != nil {
-		log.WithFields(log.Fields{
-			"respool_name": pool.poolConfig.Name,
-			"respool_id":   pool.id,
-			"gang":         gang,
-			"queue_type":   qt,
-		}).WithError(err).Error("failed to remove from queue post validateGang")
-		return false, err
-	}
-
-	// We need to enqueue the gang if all the tasks are not
-	// deleted from the gang
-	if len(validTasks) != 0 {
-		gang.Tasks = validTasks
-		// TODO this should enqueue at the head of the queue
-		if err := addGangToQueue(
-			pool,
-			qt,
-			gang); err != nil {
-			log.WithFields(log.Fields{
-				"respool_name": pool.poolConfig.Name,
-				"respool_id":   pool.id,
-				"gang":         gang,
-				"queue_type":   qt,
-			}).WithError(err).Error("failed to add gang (with valid tasks) " +
-				"to queue post validateGang")
-			return false, err
-		}
-	}
-
-	// All the tasks in this gang are deleted
-	log.WithField("gang", gang).Info("All tasks are killed for gang")
-	return false, nil
-}
-
 // returns true if gang can be admitted to the pool
 func (ac admissionController) canAdmit(
 	gang *resmgrsvc.Gang,
@@ -35,7 +35,7 @@ func (s *ResPoolSuite) respoolWithConfig(respoolConfig *respool.ResourcePoolConf
 	return resPoolNode
 }
 
-func (s *ResPoolSuite) TestBatchAdmissionController_TryAdmitValidationFail() {
+func (s *ResPoolSuite) TestBatchAdmissionController_RemoveTask() {
 
 	s.False(isRevocable(nil))
 	s.False(isPreemptible(nil))
@@ -56,14 +56,14 @@ func (s *ResPoolSuite) TestBatchAdmissionController_TryAdmitValidationFail() {
 	s.Equal(float64(10), resPool.GetDemand().DISK)
 	s.Equal(float64(0), resPool.GetDemand().GPU)
 
-	// mark the task is invalid
-	resPool.AddInvalidTask(task.Id)
+	// remove the killed task
+	s.NoError(resPool.RemoveTask(task.Id))
+	s.Error(resPool.RemoveTask(task.Id))
+	s.Equal(0, resPool.pendingQueue.Size())
 
 	// try and admit
 	err = admission.TryAdmit(nil, resPool, PendingQueue)
 	s.Equal(err, errGangInvalid)
-	err = admission.TryAdmit(gang, resPool, PendingQueue)
-	s.Equal(err, errGangInvalid)
 
 	// demand should be removed
 	s.Equal(float64(0), resPool.GetDemand().CPU)
@@ -87,20 +87,18 @@ func (s *ResPoolSuite) TestBatchAdmissionController_TryAdmitValidationFail() {
 	s.Equal(float64(20), resPool.GetDemand().DISK)
 	s.Equal(float64(0), resPool.GetDemand().GPU)
 
-	// mark one task as invalid
-	resPool.AddInvalidTask(task.Id)
-	resPool.SetNonSlackEntitlement(s.getEntitlement())
-
-	// gang will be re-enqueued with one task (removing invalid task)
-	err = admission.TryAdmit(gang, resPool, PendingQueue)
-	s.Equal(err, errGangInvalid)
+	// remove one task of the gang
+	s.NoError(resPool.RemoveTask(task.Id))
 
 	s.Equal(resPool.GetTotalAllocatedResources().String(), scalar.ZeroResource.String())
 
-	// valid task is re-enqueued
+	// the gang stays in the queue with the other task
 	s.Equal(1, resPool.pendingQueue.Size())
+	head, err := resPool.pendingQueue.PeekHead()
+	s.NoError(err)
+	s.Equal([]*resmgr.Task{task1}, head.GetTasks())
 
-	// demand is added back for valid task
+	// demand is removed for the removed task only
 	s.Equal(float64(1), resPool.GetDemand().CPU)
 	s.Equal(float64(100), resPool.GetDemand().MEMORY)
 	s.Equal(float64(10), resPool.GetDemand().DISK)
@@ -175,6 +173,46 @@ func (s *ResPoolSuite) TestBatchAdmissionController_TryAdmitFailure() {
 	s.Equal(float64(0), resPool.GetTotalAllocatedResources().GPU)
 }
 
+// TestCustomResourceAdmission tests that gangs requesting custom resources
+// are admitted up to the entitlement of the custom resources
+func (s *ResPoolSuite) TestCustomResourceAdmission() {
+	poolConfig := &respool.ResourcePoolConfig{
+		Name:   _testResPoolName,
+		Parent: &_rootResPoolID,
+		Resources: append(s.getResources(), &respool.ResourceConfig{
+			Share:       1,
+			Kind:        "fpgas",
+			Reservation: 2,
+			Limit:       4,
+		}),
+		Policy: respool.SchedulingPolicy_PriorityFIFO,
+	}
+	resPool, ok := s.respoolWithConfig(poolConfig).(*resPool)
+	s.True(ok)
+	s.Equal(float64(2), resPool.reservation.Get("fpgas"))
+
+	entitlement := s.getEntitlement()
+	entitlement.Set("fpgas", 3)
+	resPool.SetNonSlackEntitlement(entitlement)
+
+	tasks := s.getTasks()[:2]
+	tasks[0].Resource.CustomLimits = map[string]float64{"fpgas": 2}
+	tasks[1].Resource.CustomLimits = map[string]float64{"fpgas": 2}
+
+	gang := makeTaskGang(tasks[0])
+	s.NoError(resPool.EnqueueGang(gang))
+	s.Equal(float64(2), resPool.GetDemand().Get("fpgas"))
+	s.NoError(admission.TryAdmit(gang, resPool, PendingQueue))
+	s.Equal(float64(2), resPool.GetTotalAllocatedResources().Get("fpgas"))
+
+	// the second gang exceeds the entitlement of the custom resource
+	gang = makeTaskGang(tasks[1])
+	s.NoError(resPool.EnqueueGang(gang))
+	s.Equal(errResourcePoolFull, admission.TryAdmit(gang, resPool, PendingQueue))
+	s.Equal(1, resPool.pendingQueue.Size())
+	s.Equal(float64(2), resPool.GetTotalAllocatedResources().Get("fpgas"))
+}
+
 // Test adds 9 revocable tasks and 2 non-revocable tasks.
 // 8 revocable and 2 non-revocable tasks are admitted based,
 // on their entitlement for the resource pool.
@@ -707,3 +745,106 @@ func assertAdmittedSuccessfully(s *ResPoolSuite, task *resmgr.Task, resPool *res
 	s.Equal(0, resPool.controllerQueue.Size())
 	s.Equal(0, resPool.npQueue.Size())
 }
+
+func (s *ResPoolSuite) TestBatchAdmissionController_OwnerQuotaAdmitter() {
+	poolConfig := &respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    respool.SchedulingPolicy_PriorityFIFO,
+		OwnerQuota: &respool.OwnerQuota{
+			MaxTasksPerOwner: 1,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+	resPool.SetNonSlackEntitlement(s.getEntitlement())
+
+	tasks := s.getTasks()
+	tasks[0].Owner = "alice"
+	tasks[1].Owner = "alice"
+	tasks[2].Owner = "bob"
+	for _, t := range tasks[:3] {
+		s.NoError(resPool.EnqueueGang(makeTaskGang(t)))
+	}
+
+	// the gang of alice behind the head of the queue is kept in the queue,
+	// and the gang of bob behind it is admitted
+	gangs, err := resPool.DequeueGangs(3)
+	s.NoError(err)
+	s.Len(gangs, 2)
+	s.Equal("bob", gangs[0].GetTasks()[0].GetOwner())
+	s.Equal("alice", gangs[1].GetTasks()[0].GetOwner())
+	s.Equal(1, resPool.pendingQueue.Size())
+	s.Equal(1, resPool.usages.GetOwnerUsage("alice").Tasks)
+
+	// the gang of alice is admitted once her first task is done
+	s.NoError(resPool.SubtractFromAllocation(
+		scalar.GetGangAllocation(gangs[1])))
+	gangs, err = resPool.DequeueGangs(3)
+	s.NoError(err)
+	s.Len(gangs, 1)
+	s.Equal(0, resPool.pendingQueue.Size())
+}
+
+func (s *ResPoolSuite) TestOwnerQuotaAdmitter() {
+	tt := []struct {
+		msg      string
+		quota    *respool.OwnerQuota
+		canAdmit bool
+	}{
+		{
+			msg:      "no quota",
+			quota:    nil,
+			canAdmit: true,
+		},
+		{
+			msg:      "task quota of the owner exceeded",
+			quota:    &respool.OwnerQuota{MaxTasksPerOwner: 1},
+			canAdmit: false,
+		},
+		{
+			msg:      "resource quota of the owner exceeded",
+			quota:    &respool.OwnerQuota{MaxPercentPerOwner: 1},
+			canAdmit: false,
+		},
+		{
+			msg:      "resource quota of the owner not exceeded",
+			quota:    &respool.OwnerQuota{MaxPercentPerOwner: 50},
+			canAdmit: true,
+		},
+		{
+			msg:      "task quota of the job exceeded",
+			quota:    &respool.OwnerQuota{MaxTasksPerJob: 1},
+			canAdmit: false,
+		},
+		{
+			msg:      "task quota of the job not exceeded",
+			quota:    &respool.OwnerQuota{MaxTasksPerJob: 2},
+			canAdmit: true,
+		},
+	}
+
+	for _, t := range tt {
+		rp := s.respoolWithConfig(&respool.ResourcePoolConfig{
+			Name:       _testResPoolName,
+			Parent:     &_rootResPoolID,
+			Resources:  s.getResources(),
+			Policy:     respool.SchedulingPolicy_PriorityFIFO,
+			OwnerQuota: t.quota,
+		})
+		resPool, ok := rp.(*resPool)
+		s.True(ok)
+		resPool.SetEntitlement(s.getEntitlement())
+
+		tasks := s.getTasks()
+		tasks[0].Owner = "alice"
+		tasks[1].Owner = "alice"
+		resPool.allocation = resPool.allocation.Add(
+			scalar.GetTaskAllocation(tasks[0]))
+
+		s.Equal(t.canAdmit,
+			ownerQuotaAdmitter(makeTaskGang(tasks[1]), resPool), t.msg)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package respool
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// capacityReservation tracks the state of a capacity reservation of a
+// resource pool.
+type capacityReservation struct {
+	config *respool.CapacityReservation
+
+	// the reserved resources
+	resources *scalar.Resources
+
+	// the reservation window
+	startTime time.Time
+	endTime   time.Time
+
+	// the time after the start of the window within which the reserved
+	// resources have to be used, 0 if they are held for the whole window.
+	gracePeriod time.Duration
+
+	// the time the state of the reservation was first updated by this
+	// process, zero until then.
+	firstUpdate time.Time
+
+	state respool.CapacityReservationStatus_State
+}
+
+// newCapacityReservation parses the config of a capacity reservation.
+func newCapacityReservation(
+	cfg *respool.CapacityReservation) (*capacityReservation, error) {
+	startTime, err := time.Parse(time.RFC3339, cfg.GetStartTime())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid start time")
+	}
+
+	endTime, err := time.Parse(time.RFC3339, cfg.GetEndTime())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid end time")
+	}
+
+	if !endTime.After(startTime) {
+		return nil, errors.New("end time should be after start time")
+	}
+
+	resources := &scalar.Resources{}
+	for _, res := range cfg.GetResources() {
+		if !isValidResourceKind(res.GetKind()) {
+			return nil, errors.Errorf("invalid resource kind %s", res.GetKind())
+		}
+		if res.GetAmount() <= 0 {
+			return nil, errors.Errorf("amount of %s should be positive",
+				res.GetKind())
+		}
+		resources.Set(res.GetKind(),
+			resources.Get(res.GetKind())+res.GetAmount())
+	}
+
+	if resources.LessThanOrEqual(scalar.ZeroResource) {
+		return nil, errors.New("no resources reserved")
+	}
+
+	return &capacityReservation{
+		config:    cfg,
+		resources: resources,
+		startTime: startTime,
+		endTime:   endTime,
+		gracePeriod: time.Duration(cfg.GetGracePeriodSecs()) *
+			time.Second,
+		state: respool.CapacityReservationStatus_PENDING,
+	}, nil
+}
+
+// isGuaranteed returns true if the reserved resources are currently
+// guaranteed to the resource pool.
+func (r *capacityReservation) isGuaranteed() bool {
+	return r.state == respool.CapacityReservationStatus_ACTIVE ||
+		r.state == respool.CapacityReservationStatus_IN_USE
+}
+
+// overlaps returns true if the windows of the reservations overlap.
+func (r *capacityReservation) overlaps(other *capacityReservation) bool {
+	return r.startTime.Before(other.endTime) &&
+		other.startTime.Before(r.endTime)
+}
+
+// releaseTime returns the time after which the reservation is released if
+// its resources are not used. The state of the reservations is not
+// persisted, so it is rebuilt when they are loaded again after a restart.
+// The grace period runs from the later of the start of the window and the
+// first update of the reservation, so that a reservation which was in use
+// before the restart is not released before the resource pool had the
+// chance to use it again.
+func (r *capacityReservation) releaseTime() time.Time {
+	if r.firstUpdate.After(r.startTime) {
+		return r.firstUpdate.Add(r.gracePeriod)
+	}
+	return r.startTime.Add(r.gracePeriod)
+}
+
+// update moves the reservation to its state at the given time. inUse
+// tells whether the resource pool is using the reserved resources.
+func (r *capacityReservation) update(now time.Time, inUse bool) {
+	if r.firstUpdate.IsZero() {
+		r.firstUpdate = now
+	}
+
+	switch {
+	case r.state == respool.CapacityReservationStatus_EXPIRED:
+	case !now.Before(r.endTime):
+		r.state = respool.CapacityReservationStatus_EXPIRED
+	case r.state == respool.CapacityReservationStatus_RELEASED:
+	case now.Before(r.startTime):
+		r.state = respool.CapacityReservationStatus_PENDING
+	case inUse || r.state == respool.CapacityReservationStatus_IN_USE:
+		r.state = respool.CapacityReservationStatus_IN_USE
+	case r.gracePeriod > 0 && !now.Before(r.releaseTime()):
+		r.state = respool.CapacityReservationStatus_RELEASED
+	default:
+		r.state = respool.CapacityReservationStatus_ACTIVE
+	}
+}
+
+// ValidateCapacityReservation validates a new capacity reservation of the
+// resource pool at the given time.
+func ValidateCapacityReservation(
+	pool ResPool,
+	cfg *respool.CapacityReservation,
+	now time.Time) error {
+	if !pool.IsLeaf() {
+		return errors.New("capacity can only be reserved in a leaf " +
+			"resource pool")
+	}
+
+	reservation, err := newCapacityReservation(cfg)
+	if err != nil {
+		return err
+	}
+
+	if !reservation.endTime.After(now) {
+		return errors.New("reservation window has already ended")
+	}
+
+	// The reservation of the pool along with all the capacity reservations
+	// overlapping the new one should fit in the limit of the pool.
+	reserved := reservation.resources
+	for _, other := range pool.ResourcePoolConfig().GetCapacityReservations() {
+		o, err := newCapacityReservation(other)
+		if err != nil || !reservation.overlaps(o) {
+			continue
+		}
+		reserved = reserved.Add(o.resources)
+	}
+
+	resources := pool.Resources()
+	for _, kind := range reservation.resources.Kinds() {
+		if reservation.resources.Get(kind) == 0 {
+			continue
+		}
+		resCfg, ok := resources[kind]
+		if !ok {
+			return errors.Errorf("resource kind %s is not configured in "+
+				"the resource pool", kind)
+		}
+		if resCfg.GetReservation()+reserved.Get(kind) > resCfg.GetLimit() {
+			return errors.Errorf("reserved %s exceeds the limit %v of "+
+				"the resource pool", kind, resCfg.GetLimit())
+		}
+	}
+	return nil
+}
+
+// initCapacityReservations initializes the capacity reservations from the
+// config, keeping the state of the existing ones.
+func (n *resPool) initCapacityReservations(cfg *respool.ResourcePoolConfig) {
+	existing := make(map[string]*capacityReservation)
+	for _, r := range n.capacityReservations {
+		existing[r.config.GetId()] = r
+	}
+
+	reservations := make([]*capacityReservation, 0,
+		len(cfg.GetCapacityReservations()))
+	for _, rc := range cfg.GetCapacityReservations() {
+		r, err := newCapacityReservation(rc)
+		if err != nil {
+			log.WithError(err).
+				WithField("respool_id", n.id).
+				WithField("reservation_id", rc.GetId()).
+				Error("invalid capacity reservation")
+			continue
+		}
+		if e, ok := existing[rc.GetId()]; ok {
+			r.state = e.state
+			r.firstUpdate = e.firstUpdate
+		}
+		reservations = append(reservations, r)
+	}
+	n.capacityReservations = reservations
+}
+
+// UpdateCapacityReservations updates the state of the capacity
+// reservations recursively for the subtree at the given time and returns
+// the resources guaranteed by them.
+func (n *resPool) UpdateCapacityReservations(now time.Time) *scalar.Resources {
+	n.Lock()
+	defer n.Unlock()
+	return n.updateCapacityReservations(now)
+}
+
+// updateCapacityReservations is the private method to recursively update
+// the capacity reservations of the subtree. Every child is locked while it
+// is updated, as its reservations and allocation are also changed by the
+// API handlers and the scheduler.
+func (n *resPool) updateCapacityReservations(now time.Time) *scalar.Resources {
+	reserved := &scalar.Resources{}
+	for child := n.children.Front(); child != nil; child = child.Next() {
+		if childResPool, ok := child.Value.(*resPool); ok {
+			reserved = reserved.Add(
+				childResPool.UpdateCapacityReservations(now))
+		}
+	}
+
+	// The reserved resources are in use once the non-slack allocation of
+	// the pool exceeds its reservation in any of the reserved kinds.
+	nonSlackAllocation := n.allocation.GetByType(scalar.NonSlackAllocation)
+	for _, r := range n.capacityReservations {
+		inUse := false
+		for _, kind := range r.resources.Kinds() {
+			if r.resources.Get(kind) > 0 &&
+				nonSlackAllocation.Get(kind) > n.reservation.Get(kind) {
+				inUse = true
+				break
+			}
+		}
+
+		prevState := r.state
+		r.update(now, inUse)
+		if r.state != prevState {
+			log.WithFields(log.Fields{
+				"respool_id":     n.id,
+				"reservation_id": r.config.GetId(),
+				"prev_state":     prevState.String(),
+				"state":          r.state.String(),
+			}).Info("Capacity reservation state changed")
+		}
+
+		if r.isGuaranteed() {
+			reserved = reserved.Add(r.resources)
+		}
+	}
+	n.capacityReserved = reserved
+	return reserved
+}
+
+// GetCapacityReserved returns the resources guaranteed to the subtree of
+// the resource pool by capacity reservations.
+func (n *resPool) GetCapacityReserved() *scalar.Resources {
+	n.RLock()
+	defer n.RUnlock()
+	return n.capacityReserved
+}
+
+// capacityReservationStatuses returns the status of the capacity
+// reservations of the resource pool.
+// NB: The function calling capacityReservationStatuses should acquire the lock
+func (n *resPool) capacityReservationStatuses() []*respool.CapacityReservationStatus {
+	if len(n.capacityReservations) == 0 {
+		return nil
+	}
+	statuses := make([]*respool.CapacityReservationStatus, 0,
+		len(n.capacityReservations))
+	for _, r := range n.capacityReservations {
+		statuses = append(statuses, &respool.CapacityReservationStatus{
+			Reservation: r.config,
+			State:       r.state,
+		})
+	}
+	return statuses
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package respool
+
+import (
+	"container/list"
+	"time"
+
+	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+func (s *ResPoolSuite) TestUpdateCapacityReservations() {
+	start := time.Date(2019, time.March, 1, 10, 0, 0, 0, time.UTC)
+	newReservation := func(
+		id string,
+		kind string,
+		amount float64) *pb_respool.CapacityReservation {
+		return &pb_respool.CapacityReservation{
+			Id: id,
+			Resources: []*pb_respool.CapacityReservationResource{
+				{
+					Kind:   kind,
+					Amount: amount,
+				},
+			},
+			StartTime:       start.Format(time.RFC3339),
+			EndTime:         start.Add(2 * time.Hour).Format(time.RFC3339),
+			GracePeriodSecs: 600,
+		}
+	}
+
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		CapacityReservations: []*pb_respool.CapacityReservation{
+			newReservation("cpu-reservation", "cpu", 50),
+			newReservation("gpu-reservation", "gpu", 1),
+		},
+	}
+	pool := s.respoolWithConfig(poolConfig)
+
+	states := func() []pb_respool.CapacityReservationStatus_State {
+		var states []pb_respool.CapacityReservationStatus_State
+		for _, status := range pool.ToResourcePoolInfo().
+			GetCapacityReservations() {
+			states = append(states, status.GetState())
+		}
+		return states
+	}
+
+	// window not open yet
+	reserved := pool.UpdateCapacityReservations(start.Add(-time.Minute))
+	s.Equal(scalar.ZeroResource, reserved)
+	s.Equal([]pb_respool.CapacityReservationStatus_State{
+		pb_respool.CapacityReservationStatus_PENDING,
+		pb_respool.CapacityReservationStatus_PENDING,
+	}, states())
+
+	// window opens, the resources are guaranteed
+	reserved = pool.UpdateCapacityReservations(start)
+	s.Equal(&scalar.Resources{CPU: 50, GPU: 1}, reserved)
+	s.Equal(reserved, pool.GetCapacityReserved())
+	s.Equal([]pb_respool.CapacityReservationStatus_State{
+		pb_respool.CapacityReservationStatus_ACTIVE,
+		pb_respool.CapacityReservationStatus_ACTIVE,
+	}, states())
+
+	// cpu allocation goes beyond the reservation of the pool
+	alloc := scalar.NewAllocation()
+	alloc.Value[scalar.TotalAllocation] = &scalar.Resources{CPU: 120}
+	alloc.Value[scalar.NonSlackAllocation] = &scalar.Resources{CPU: 120}
+	s.NoError(pool.AddToAllocation(alloc))
+	pool.UpdateCapacityReservations(start.Add(5 * time.Minute))
+	s.Equal([]pb_respool.CapacityReservationStatus_State{
+		pb_respool.CapacityReservationStatus_IN_USE,
+		pb_respool.CapacityReservationStatus_ACTIVE,
+	}, states())
+
+	// the state is kept when the config is set again
+	pool.SetResourcePoolConfig(poolConfig)
+
+	// grace period is over, the unused gpu reservation is released
+	reserved = pool.UpdateCapacityReservations(start.Add(11 * time.Minute))
+	s.Equal(&scalar.Resources{CPU: 50}, reserved)
+	s.Equal([]pb_respool.CapacityReservationStatus_State{
+		pb_respool.CapacityReservationStatus_IN_USE,
+		pb_respool.CapacityReservationStatus_RELEASED,
+	}, states())
+
+	// window closes
+	reserved = pool.UpdateCapacityReservations(start.Add(2 * time.Hour))
+	s.Equal(scalar.ZeroResource, reserved)
+	s.Equal([]pb_respool.CapacityReservationStatus_State{
+		pb_respool.CapacityReservationStatus_EXPIRED,
+		pb_respool.CapacityReservationStatus_EXPIRED,
+	}, states())
+}
+
+func (s *ResPoolSuite) TestUpdateCapacityReservationsNonLeaf() {
+	start := time.Now()
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		CapacityReservations: []*pb_respool.CapacityReservation{
+			{
+				Id: "reservation",
+				Resources: []*pb_respool.CapacityReservationResource{
+					{
+						Kind:   "memory",
+						Amount: 100,
+					},
+				},
+				StartTime: start.Format(time.RFC3339),
+				EndTime:   start.Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+	}
+	parent := s.createTestResourcePool()
+	child := s.respoolWithConfig(poolConfig)
+	children := list.New()
+	children.PushBack(child)
+	parent.SetChildren(children)
+
+	reserved := parent.UpdateCapacityReservations(start)
+	s.Equal(&scalar.Resources{MEMORY: 100}, reserved)
+	s.Equal(reserved, child.GetCapacityReserved())
+	s.Equal(reserved, parent.GetCapacityReserved())
+}
+
+// TestUpdateCapacityReservationsRecovered tests that the grace period of a
+// reservation loaded after its window opened, as after a restart, runs
+// from its first update.
+func (s *ResPoolSuite) TestUpdateCapacityReservationsRecovered() {
+	start := time.Date(2019, time.March, 1, 10, 0, 0, 0, time.UTC)
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		CapacityReservations: []*pb_respool.CapacityReservation{
+			{
+				Id: "reservation",
+				Resources: []*pb_respool.CapacityReservationResource{
+					{
+						Kind:   "cpu",
+						Amount: 50,
+					},
+				},
+				StartTime:       start.Format(time.RFC3339),
+				EndTime:         start.Add(2 * time.Hour).Format(time.RFC3339),
+				GracePeriodSecs: 600,
+			},
+		},
+	}
+	pool := s.respoolWithConfig(poolConfig)
+
+	// loaded long after the grace period, the reservation is still active
+	recovered := start.Add(time.Hour)
+	reserved := pool.UpdateCapacityReservations(recovered)
+	s.Equal(&scalar.Resources{CPU: 50}, reserved)
+
+	reserved = pool.UpdateCapacityReservations(recovered.Add(5 * time.Minute))
+	s.Equal(&scalar.Resources{CPU: 50}, reserved)
+
+	// not used within the grace period after it was loaded
+	reserved = pool.UpdateCapacityReservations(recovered.Add(10 * time.Minute))
+	s.Equal(scalar.ZeroResource, reserved)
+	s.Equal(pb_respool.CapacityReservationStatus_RELEASED,
+		pool.ToResourcePoolInfo().GetCapacityReservations()[0].GetState())
+}
+
+// TestUpdateCapacityReservationsConcurrent tests that the capacity
+// reservations of a child can be updated while its config is changed.
+func (s *ResPoolSuite) TestUpdateCapacityReservationsConcurrent() {
+	start := time.Now()
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		CapacityReservations: []*pb_respool.CapacityReservation{
+			{
+				Id: "reservation",
+				Resources: []*pb_respool.CapacityReservationResource{
+					{
+						Kind:   "memory",
+						Amount: 100,
+					},
+				},
+				StartTime: start.Format(time.RFC3339),
+				EndTime:   start.Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+	}
+	parent := s.createTestResourcePool()
+	child := s.respoolWithConfig(poolConfig)
+	children := list.New()
+	children.PushBack(child)
+	parent.SetChildren(children)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			child.SetResourcePoolConfig(poolConfig)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		parent.UpdateCapacityReservations(start)
+	}
+	<-done
+
+	s.Equal(&scalar.Resources{MEMORY: 100},
+		parent.UpdateCapacityReservations(start))
+}
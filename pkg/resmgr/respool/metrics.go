@@ -48,6 +48,14 @@ type Metrics struct {
 	QueryResourcePoolsSuccess tally.Counter
 	QueryResourcePoolsFail    tally.Counter
 
+	APICreateCapacityReservation     tally.Counter
+	CreateCapacityReservationSuccess tally.Counter
+	CreateCapacityReservationFail    tally.Counter
+
+	APIDeleteCapacityReservation     tally.Counter
+	DeleteCapacityReservationSuccess tally.Counter
+	DeleteCapacityReservationFail    tally.Counter
+
 	PendingQueueSize    tally.Gauge
 	RevocableQueueSize  tally.Gauge
 	ControllerQueueSize tally.Gauge
@@ -66,6 +74,11 @@ type Metrics struct {
 	NonSlackAvailable scalar.GaugeMaps
 	SlackAvailable    scalar.GaugeMaps
 
+	// Resources of the reservation lent to the siblings of the pool, and
+	// resources borrowed by the pool on top of its reservation.
+	Lent     scalar.GaugeMaps
+	Borrowed scalar.GaugeMaps
+
 	Demand      scalar.GaugeMaps
 	SlackDemand scalar.GaugeMaps
 
@@ -73,8 +86,17 @@ type Metrics struct {
 	ResourcePoolLimit       scalar.GaugeMaps
 	ResourcePoolShare       scalar.GaugeMaps
 
+	CapacityReserved scalar.GaugeMaps
+
 	ControllerLimit scalar.GaugeMaps
 	SlackLimit      scalar.GaugeMaps
+
+	// Capacity reservations for large gangs, the ones which expired before
+	// the gang could be admitted, and the ones released as the gang left
+	// the pending queue.
+	GangReservations         tally.Counter
+	GangReservationsExpired  tally.Counter
+	GangReservationsReleased tally.Counter
 }
 
 // NewMetrics returns a new instance of respool.Metrics.
@@ -95,6 +117,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	reservationScope := scope.SubScope("reservation")
 	limitScope := scope.SubScope("limit")
 	shareScope := scope.SubScope("share")
+	capacityReservationScope := scope.SubScope("capacity_reservation")
 	return &Metrics{
 		APICreateResourcePool:          apiScope.Counter("create_resource_pool"),
 		CreateResourcePoolSuccess:      successScope.Counter("create_resource_pool"),
@@ -122,6 +145,14 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		QueryResourcePoolsSuccess: successScope.Counter("query_resource_pools"),
 		QueryResourcePoolsFail:    failScope.Counter("query_resource_pools"),
 
+		APICreateCapacityReservation:     apiScope.Counter("create_capacity_reservation"),
+		CreateCapacityReservationSuccess: successScope.Counter("create_capacity_reservation"),
+		CreateCapacityReservationFail:    failScope.Counter("create_capacity_reservation"),
+
+		APIDeleteCapacityReservation:     apiScope.Counter("delete_capacity_reservation"),
+		DeleteCapacityReservationSuccess: successScope.Counter("delete_capacity_reservation"),
+		DeleteCapacityReservationFail:    failScope.Counter("delete_capacity_reservation"),
+
 		PendingQueueSize:    queueScope.Gauge("pending_queue_size"),
 		RevocableQueueSize:  queueScope.Gauge("revocable_queue_size"),
 		ControllerQueueSize: queueScope.Gauge("controller_queue_size"),
@@ -146,6 +177,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		NonSlackAvailable: scalar.NewGaugeMaps(availableScope),
 		SlackAvailable:    scalar.NewGaugeMaps(slackAvailableScope),
 
+		Lent:     scalar.NewGaugeMaps(entitlementScope.SubScope("lent")),
+		Borrowed: scalar.NewGaugeMaps(entitlementScope.SubScope("borrowed")),
+
 		Demand:      scalar.NewGaugeMaps(demandScope),
 		SlackDemand: scalar.NewGaugeMaps(slackDemandScope),
 
@@ -153,9 +187,15 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ResourcePoolLimit:       scalar.NewGaugeMaps(limitScope),
 		ResourcePoolShare:       scalar.NewGaugeMaps(shareScope),
 
+		CapacityReserved: scalar.NewGaugeMaps(capacityReservationScope),
+
 		ControllerLimit: scalar.NewGaugeMaps(limitScope.SubScope(
 			"controller_limit")),
 		SlackLimit: scalar.NewGaugeMaps(limitScope.SubScope(
 			"slack_limit")),
+
+		GangReservations:         queueScope.Counter("gang_reservations"),
+		GangReservationsExpired:  queueScope.Counter("gang_reservations_expired"),
+		GangReservationsReleased: queueScope.Counter("gang_reservations_released"),
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"container/list"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -90,6 +91,9 @@ type ResPool interface {
 	// on the queue type. limit determines the max number of gangs to be
 	// returned.
 	PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang, error)
+	// ReprioritizeGang moves a gang in the pending queue of the resource
+	// pool to the given priority, updating the priority of its tasks.
+	ReprioritizeGang(gang *resmgrsvc.Gang, priority uint32) error
 
 	// SetEntitlement sets the entitlement of non-revocable resources
 	// for non-revocable tasks + revocable tasks for this resource pool.
@@ -158,13 +162,31 @@ type ResPool interface {
 	// can be used by revocable tasks.
 	GetSlackLimit() *scalar.Resources
 
-	// AddInvalidTask will add the killed tasks to respool which can be
-	// discarded asynchronously which scheduling.
-	AddInvalidTask(task *peloton.TaskID)
+	// RemoveTask removes a killed task from the queue it is pending
+	// admission in, along with its demand.
+	RemoveTask(task *peloton.TaskID) error
 
 	// UpdateResourceMetrics updates metrics for this resource pool
 	// on each entitlement cycle calculation (15s)
 	UpdateResourceMetrics()
+
+	// UpdateCapacityReservations updates the state of the capacity
+	// reservations recursively for the subtree at the given time and
+	// returns the resources guaranteed by them.
+	UpdateCapacityReservations(now time.Time) *scalar.Resources
+	// GetCapacityReserved returns the resources guaranteed to the subtree
+	// of the resource pool by capacity reservations.
+	GetCapacityReserved() *scalar.Resources
+	// GetReservedGangDemand returns the resources of the large gangs the
+	// capacity of the subtree of the resource pool is reserved for.
+	GetReservedGangDemand() *scalar.Resources
+
+	// AddPreemptedTasks records running tasks of the resource pool which
+	// are preempted.
+	AddPreemptedTasks(count int)
+	// GetUsageHistory returns the usage samples of the resource pool
+	// taken at or after the given time, the oldest first.
+	GetUsageHistory(since time.Time) []UsageSample
 }
 
 // resPool implements the ResPool interface.
@@ -183,6 +205,8 @@ type resPool struct {
 
 	// Tracks the allocation across different task dimensions
 	allocation *scalar.Allocation
+	// Tracks the usage of the owners and jobs of the tasks allocated
+	usages *scalar.Usages
 
 	// Tracks the max resources this resource pool can use in a given
 	// entitlement cycle
@@ -208,6 +232,12 @@ type resPool struct {
 	// the reserved resources of this pool
 	reservation *scalar.Resources
 
+	// the capacity reservations of this pool
+	capacityReservations []*capacityReservation
+	// the resources guaranteed to the subtree of this pool by the capacity
+	// reservations, updated on each entitlement cycle
+	capacityReserved *scalar.Resources
+
 	// queue containing gangs waiting to be admitted into the resource pool.
 	// queue semantics is defined by the SchedulingPolicy
 	pendingQueue queue.Queue
@@ -240,10 +270,17 @@ type resPool struct {
 	// the max limit of resources revocable tasks can use in this pool.
 	slackLimit *scalar.Resources
 
-	// set of invalid tasks which will be discarded during admission control.
-	invalidTasks map[string]bool
-
+	// scope of the resource pool, tagged with its path
+	scope   tally.Scope
 	metrics *Metrics
+
+	// usage of the pool sampled on each entitlement cycle
+	usage usageHistory
+
+	// large gang the capacity freed up in the pool is reserved for, and
+	// the time at which the capacity was reserved
+	reservedGang  *resmgrsvc.Gang
+	reservedSince time.Time
 }
 
 // NewRespool will initialize the resource pool node and return that.
@@ -259,37 +296,14 @@ func NewRespool(
 			"ResourcePoolConfig is nil", id)
 	}
 
-	pq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	cq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	nq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
-	}
-
-	rq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
-	if err != nil {
-		return nil, errors.Wrapf(err, "error creating revocable queue %s", id)
-	}
-
 	pool := &resPool{
 		id:                  id,
 		children:            list.New(),
 		parent:              parent,
 		resourceConfigs:     make(map[string]*respool.ResourceConfig),
 		poolConfig:          config,
-		pendingQueue:        pq,
-		controllerQueue:     cq,
-		npQueue:             nq,
-		revocableQueue:      rq,
 		allocation:          scalar.NewAllocation(),
+		usages:              scalar.NewUsages(),
 		entitlement:         &scalar.Resources{},
 		nonSlackEntitlement: &scalar.Resources{},
 		slackEntitlement:    &scalar.Resources{},
@@ -297,15 +311,37 @@ func NewRespool(
 		slackDemand:         &scalar.Resources{},
 		slackLimit:          &scalar.Resources{},
 		reservation:         &scalar.Resources{},
-		invalidTasks:        make(map[string]bool),
+		capacityReserved:    &scalar.Resources{},
 		preemptionCfg:       preemptionConfig,
 	}
 	pool.path = pool.calculatePath()
 
 	// Initialize metrics
-	pool.metrics = NewMetrics(scope.Tagged(map[string]string{
+	pool.scope = scope.Tagged(map[string]string{
 		"path": pool.GetPath(),
-	}))
+	})
+	pool.metrics = NewMetrics(pool.scope)
+
+	var err error
+	pool.pendingQueue, err = pool.createQueue(PendingQueue, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.controllerQueue, err = pool.createQueue(ControllerQueue, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.npQueue, err = pool.createQueue(NonPreemptibleQueue, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
+	}
+
+	pool.revocableQueue, err = pool.createQueue(RevocableQueue, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating revocable queue %s", id)
+	}
 
 	// Initialize resources and limits.
 	pool.initialize(config)
@@ -367,10 +403,75 @@ func (n *resPool) Resources() map[string]*respool.ResourceConfig {
 func (n *resPool) SetResourcePoolConfig(config *respool.ResourcePoolConfig) {
 	n.Lock()
 	defer n.Unlock()
+	if config.GetPolicy() != n.poolConfig.GetPolicy() ||
+		config.GetPriorityAging().GetMaxWaitSeconds() !=
+			n.poolConfig.GetPriorityAging().GetMaxWaitSeconds() {
+		if err := n.setSchedulingPolicy(config); err != nil {
+			log.WithError(err).
+				WithField("respool_id", n.id).
+				WithField("policy", config.GetPolicy().String()).
+				Error("failed to change scheduling policy")
+		}
+	}
 	n.poolConfig = config
 	n.initialize(config)
 }
 
+// setSchedulingPolicy moves the gangs of all the queues of the pool to new
+// queues with the scheduling policy of the given config. The gangs are
+// enqueued in the order of the old queues, so they keep their relative
+// order wherever the new policy allows it.
+// NB: The function calling setSchedulingPolicy should acquire the lock
+func (n *resPool) setSchedulingPolicy(
+	config *respool.ResourcePoolConfig) error {
+	queueTypes := []QueueType{
+		PendingQueue,
+		ControllerQueue,
+		NonPreemptibleQueue,
+		RevocableQueue,
+	}
+
+	queues := make(map[QueueType]queue.Queue)
+	for _, qt := range queueTypes {
+		q, err := n.createQueue(qt, config)
+		if err != nil {
+			return err
+		}
+
+		if size := n.queue(qt).Size(); size > 0 {
+			gangs, err := n.queue(qt).Peek(uint32(size))
+			if err != nil {
+				return err
+			}
+			for _, gang := range gangs {
+				if err := q.Enqueue(gang); err != nil {
+					return err
+				}
+			}
+		}
+		queues[qt] = q
+	}
+
+	n.pendingQueue = queues[PendingQueue]
+	n.controllerQueue = queues[ControllerQueue]
+	n.npQueue = queues[NonPreemptibleQueue]
+	n.revocableQueue = queues[RevocableQueue]
+	return nil
+}
+
+// createQueue creates a queue of the given type with the scheduling policy
+// of the given config.
+func (n *resPool) createQueue(
+	qt QueueType,
+	config *respool.ResourcePoolConfig) (queue.Queue, error) {
+	return queue.CreateQueue(
+		config,
+		math.MaxInt64,
+		n.scope.SubScope("queue").Tagged(map[string]string{
+			"queue": qt.String(),
+		}))
+}
+
 // ResourcePoolConfig returns the resource pool config.
 func (n *resPool) ResourcePoolConfig() *respool.ResourcePoolConfig {
 	n.RLock()
@@ -397,7 +498,11 @@ func (n *resPool) EnqueueGang(gang *resmgrsvc.Gang) error {
 		return errors.Errorf("resource pool %s is not a leaf node", n.id)
 	}
 
-	if err := n.pendingQueue.Enqueue(gang); err != nil {
+	// the queue is replaced when the scheduling policy changes
+	n.RLock()
+	err := n.pendingQueue.Enqueue(gang)
+	n.RUnlock()
+	if err != nil {
 		return err
 	}
 
@@ -433,6 +538,11 @@ func (n *resPool) DequeueGangs(limit int) ([]*resmgrsvc.Gang, error) {
 		return nil, err
 	}
 
+	// no other gang is admitted while the capacity is reserved for a gang
+	if gangs, reserved := n.dequeueReservedGang(); reserved {
+		return gangs, nil
+	}
+
 	var err error
 	var gangList []*resmgrsvc.Gang
 
@@ -472,8 +582,13 @@ func (n *resPool) dequeue(
 		return gangList, nil
 	}
 
+	// number of gangs at the head of the queue which are kept in the queue
+	// because they exceed the quota of their owner or job
+	skipped := 0
 	for i := 0; i < limit; i++ {
-		gangs, err := n.queue(qt).Peek(1)
+		n.RLock()
+		gangs, err := n.queue(qt).Peek(uint32(skipped + 1))
+		n.RUnlock()
 		if err != nil {
 			if _, ok := err.(queue.ErrorQueueEmpty); ok {
 				// queue is empty we are done
@@ -482,8 +597,21 @@ func (n *resPool) dequeue(
 			log.WithError(err).Error("Failed to peek into queue")
 			return gangList, err
 		}
-		gang := gangs[0]
+		if len(gangs) <= skipped {
+			// all the gangs left in the queue exceed their quota
+			return gangList, nil
+		}
+		gang := gangs[skipped]
 		err = admission.TryAdmit(gang, n, qt)
+		if err == errOwnerQuotaExceeded {
+			// move on to the gang behind it in the queue
+			log.WithFields(log.Fields{
+				"respool_id": n.id,
+				"error":      err.Error(),
+			}).Debug("skipping gang from admission")
+			skipped++
+			continue
+		}
 		if err != nil {
 			if err == errGangInvalid ||
 				err == errSkipNonPreemptibleGang ||
@@ -491,9 +619,7 @@ func (n *resPool) dequeue(
 				err == errSkipRevocableGang {
 				// the admission can fail  :
 				// 1. Because the gang is invalid.
-				// In this case we move on to the next gang in the queue with the
-				// expectation that the invalid gang is removed from the head of
-				// the queue.
+				// In this case we move on to the next gang in the queue.
 				// 2. Because the gang should be skipped (
 				// revocable gang, controller gang or non-preemptible gang)
 				log.WithFields(log.Fields{
@@ -502,6 +628,9 @@ func (n *resPool) dequeue(
 				}).Debug("skipping gang from admission")
 				continue
 			}
+			if err == errResourcePoolFull && qt == PendingQueue {
+				n.reserveCapacity(gang)
+			}
 			break
 		}
 		gangList = append(gangList, gang)
@@ -509,6 +638,127 @@ func (n *resPool) dequeue(
 	return gangList, err
 }
 
+// reserveCapacity reserves the capacity freed up in the pool for a large
+// gang at the head of the pending queue which couldn't be admitted, if the
+// pool has a gang reservation policy.
+func (n *resPool) reserveCapacity(gang *resmgrsvc.Gang) {
+	n.Lock()
+	defer n.Unlock()
+
+	config := n.poolConfig.GetGangReservation()
+	if config == nil ||
+		config.GetMaxWaitSeconds() == 0 ||
+		n.reservedGang != nil ||
+		len(gang.GetTasks()) < int(config.GetMinTasks()) {
+		return
+	}
+
+	n.reservedGang = gang
+	n.reservedSince = time.Now()
+	n.metrics.GangReservations.Inc(1)
+	log.WithFields(log.Fields{
+		"respool_id": n.id,
+		"num_tasks":  len(gang.GetTasks()),
+	}).Info("Reserving capacity for gang")
+}
+
+// dequeueReservedGang tries to admit the gang the capacity of the pool is
+// reserved for. It returns true while the reservation holds, so that the
+// capacity freed up in the pool keeps accumulating for the gang, and the
+// gang if it was admitted. The reservation is released once the gang is
+// no longer in the pending queue, e.g. if it was killed.
+func (n *resPool) dequeueReservedGang() ([]*resmgrsvc.Gang, bool) {
+	n.Lock()
+	gang := n.reservedGang
+	if gang == nil {
+		n.Unlock()
+		return nil, false
+	}
+
+	if !n.isGangPending(gang) {
+		n.reservedGang = nil
+		n.metrics.GangReservationsReleased.Inc(1)
+		n.Unlock()
+		log.WithField("respool_id", n.id).
+			Info("Gang left the pending queue, releasing capacity reservation")
+		return nil, false
+	}
+
+	maxWait := time.Duration(
+		n.poolConfig.GetGangReservation().GetMaxWaitSeconds()) * time.Second
+	if time.Since(n.reservedSince) > maxWait {
+		// don't keep the pool idle any longer
+		n.reservedGang = nil
+		n.metrics.GangReservationsExpired.Inc(1)
+		n.Unlock()
+		log.WithField("respool_id", n.id).
+			Info("Capacity reservation for gang expired")
+		return nil, false
+	}
+	n.Unlock()
+
+	err := admission.TryAdmit(gang, n, PendingQueue)
+	if err == errResourcePoolFull {
+		return nil, true
+	}
+
+	// the gang is either admitted or no longer waiting for admission
+	n.Lock()
+	n.reservedGang = nil
+	n.Unlock()
+	if err != nil {
+		return nil, false
+	}
+	return []*resmgrsvc.Gang{gang}, true
+}
+
+// isGangPending returns true if the gang is in the pending queue.
+// NB: The function calling isGangPending should acquire the lock
+func (n *resPool) isGangPending(gang *resmgrsvc.Gang) bool {
+	// the gang is usually still at the head of the queue
+	if head, err := n.pendingQueue.PeekHead(); err != nil {
+		return false
+	} else if head == gang {
+		return true
+	}
+
+	gangs, err := n.pendingQueue.Peek(uint32(n.pendingQueue.Size()))
+	if err != nil {
+		return false
+	}
+	for _, pending := range gangs {
+		if pending == gang {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReservedGangDemand returns the resources of the large gangs the
+// capacity of the subtree of the resource pool is reserved for.
+func (n *resPool) GetReservedGangDemand() *scalar.Resources {
+	n.RLock()
+	if n.isLeaf() {
+		defer n.RUnlock()
+		if n.reservedGang == nil {
+			return &scalar.Resources{}
+		}
+		return scalar.GetGangResources(n.reservedGang)
+	}
+
+	var children []ResPool
+	for child := n.children.Front(); child != nil; child = child.Next() {
+		children = append(children, child.Value.(ResPool))
+	}
+	n.RUnlock()
+
+	demand := &scalar.Resources{}
+	for _, child := range children {
+		demand = demand.Add(child.GetReservedGangDemand())
+	}
+	return demand
+}
+
 // AggregatedChildrenReservations returns aggregated child reservations by
 // resource kind
 func (n *resPool) AggregatedChildrenReservations() (map[string]float64, error) {
@@ -566,6 +816,7 @@ func (n *resPool) ToResourcePoolInfo() *respool.ResourcePoolInfo {
 		Usage: n.createRespoolUsage(
 			n.allocation.GetByType(scalar.TotalAllocation),
 			n.allocation.GetByType(scalar.SlackAllocation)),
+		CapacityReservations: n.capacityReservationStatuses(),
 	}
 }
 
@@ -691,6 +942,13 @@ func (n *resPool) createRespoolUsage(
 		Slack:      slackAllocation.DISK,
 	}
 	resUsage = append(resUsage, ru)
+	for _, kind := range allocation.CustomKinds() {
+		resUsage = append(resUsage, &respool.ResourceUsage{
+			Kind:       kind,
+			Allocation: allocation.Get(kind) - slackAllocation.Get(kind),
+			Slack:      slackAllocation.Get(kind),
+		})
+	}
 	return resUsage
 }
 
@@ -706,31 +964,40 @@ func (n *resPool) initialize(cfg *respool.ResourcePoolConfig) {
 	n.initControllerLimit(cfg)
 	n.initSlackLimit(cfg)
 	n.initReservation(cfg)
+	n.initCapacityReservations(cfg)
 }
 
 // initializes the reserved resources
 func (n *resPool) initReservation(cfg *respool.ResourcePoolConfig) {
+	reservation := &scalar.Resources{}
 	for kind, res := range n.resourceConfigs {
 		switch kind {
 		case common.CPU:
-			n.reservation.CPU = res.Reservation
+			reservation.CPU = res.Reservation
 		case common.MEMORY:
-			n.reservation.MEMORY = res.Reservation
+			reservation.MEMORY = res.Reservation
 		case common.GPU:
-			n.reservation.GPU = res.Reservation
+			reservation.GPU = res.Reservation
 		case common.DISK:
-			n.reservation.DISK = res.Reservation
+			reservation.DISK = res.Reservation
+		default:
+			reservation.Set(kind, res.Reservation)
 		}
 	}
+	n.reservation = reservation
 	log.WithField("reservation", n.reservation).
 		WithField("respool_id", n.id).
 		Info("Setting reservation")
 }
 
+// initializes the resource configs, the kinds of resources no longer in
+// the config are dropped
 func (n *resPool) initResConfig(cfg *respool.ResourcePoolConfig) {
+	resourceConfigs := make(map[string]*respool.ResourceConfig)
 	for _, res := range cfg.Resources {
-		n.resourceConfigs[res.Kind] = res
+		resourceConfigs[res.Kind] = res
 	}
+	n.resourceConfigs = resourceConfigs
 }
 
 // initControllerLimit initializes the limit of resources controller tasks can use.
@@ -753,6 +1020,8 @@ func (n *resPool) initControllerLimit(cfg *respool.ResourcePoolConfig) {
 			controllerLimit.GPU = res.Reservation * multiplier
 		case common.DISK:
 			controllerLimit.DISK = res.Reservation * multiplier
+		default:
+			controllerLimit.Set(kind, res.Reservation*multiplier)
 		}
 	}
 	n.controllerLimit = controllerLimit
@@ -783,6 +1052,8 @@ func (n *resPool) initSlackLimit(cfg *respool.ResourcePoolConfig) {
 			slackLimit.MEMORY = res.Reservation * multiplier
 		case common.DISK:
 			slackLimit.DISK = res.Reservation * multiplier
+		default:
+			slackLimit.Set(kind, res.Reservation*multiplier)
 		}
 	}
 	n.slackLimit = slackLimit
@@ -907,6 +1178,7 @@ func (n *resPool) SubtractFromAllocation(allocation *scalar.Allocation) error {
 		return errors.Errorf("couldn't update the resources")
 	}
 	n.allocation = newAllocation
+	n.usages.Subtract(allocation)
 
 	log.WithFields(log.Fields{
 		"respool_id": n.id,
@@ -966,6 +1238,7 @@ func (n *resPool) AddToAllocation(allocation *scalar.Allocation) error {
 	defer n.Unlock()
 
 	n.allocation = n.allocation.Add(allocation)
+	n.usages.Add(allocation)
 
 	log.WithFields(log.Fields{
 		"respool_id": n.id,
@@ -1077,6 +1350,10 @@ func (n *resPool) updateDynamicResourceMetrics() {
 	n.metrics.SlackEntitlement.Update(n.slackEntitlement)
 	n.metrics.NonSlackEntitlement.Update(n.nonSlackEntitlement)
 
+	guaranteed := n.reservation.Add(n.capacityReserved)
+	n.metrics.Lent.Update(guaranteed.Subtract(n.entitlement))
+	n.metrics.Borrowed.Update(n.entitlement.Subtract(guaranteed))
+
 	n.metrics.TotalAllocation.Update(n.allocation.GetByType(
 		scalar.TotalAllocation))
 	n.metrics.SlackAllocation.Update(n.allocation.GetByType(
@@ -1096,6 +1373,8 @@ func (n *resPool) updateDynamicResourceMetrics() {
 	n.metrics.Demand.Update(n.demand)
 	n.metrics.SlackDemand.Update(n.slackDemand)
 
+	n.metrics.CapacityReserved.Update(n.capacityReserved)
+
 	n.metrics.PendingQueueSize.Update(float64(n.aggregateQueueByType(PendingQueue)))
 	n.metrics.RevocableQueueSize.Update(float64(n.aggregateQueueByType(RevocableQueue)))
 	n.metrics.ControllerQueueSize.Update(float64(n.aggregateQueueByType(ControllerQueue)))
@@ -1117,20 +1396,66 @@ func (n *resPool) aggregateQueueByType(qt QueueType) int {
 	return queueSize
 }
 
-// updates all the metrics (static and dynamic)
+// updates all the metrics (static and dynamic), and samples the usage of
+// the resource pool
 func (n *resPool) UpdateResourceMetrics() {
-	n.RLock()
-	defer n.RUnlock()
+	n.Lock()
+	defer n.Unlock()
 	n.updateStaticResourceMetrics()
 	n.updateDynamicResourceMetrics()
+
+	n.usage.record(UsageSample{
+		Time:        time.Now().UTC(),
+		Demand:      n.demand.Add(n.slackDemand),
+		Allocation:  n.allocation.GetByType(scalar.TotalAllocation),
+		Entitlement: n.entitlement,
+	})
 }
 
-// AddInvalidTask adds an invalid task so that it can
-// remove them from the respool later
-func (n *resPool) AddInvalidTask(task *peloton.TaskID) {
+// AddPreemptedTasks records running tasks of the resource pool which are
+// preempted
+func (n *resPool) AddPreemptedTasks(count int) {
 	n.Lock()
 	defer n.Unlock()
-	n.invalidTasks[task.Value] = true
+	n.usage.preemptedTasks += count
+}
+
+// GetUsageHistory returns the usage samples of the resource pool taken at
+// or after the given time, the oldest first
+func (n *resPool) GetUsageHistory(since time.Time) []UsageSample {
+	n.RLock()
+	defer n.RUnlock()
+	return n.usage.since(since)
+}
+
+// RemoveTask removes a killed task from the queue it is pending admission
+// in, and subtracts its resources from the demand of the resource pool.
+func (n *resPool) RemoveTask(task *peloton.TaskID) error {
+	n.Lock()
+	defer n.Unlock()
+
+	for _, qt := range []QueueType{
+		PendingQueue,
+		ControllerQueue,
+		NonPreemptibleQueue,
+		RevocableQueue,
+	} {
+		t, err := n.queue(qt).RemoveTask(task.GetValue())
+		if err != nil {
+			continue
+		}
+
+		res := scalar.ConvertToResmgrResource(t.GetResource())
+		if !t.GetRevocable() {
+			n.demand = n.demand.Subtract(res)
+		} else {
+			n.slackDemand = n.slackDemand.Subtract(res)
+		}
+		return nil
+	}
+
+	return errors.Errorf("task %s is not pending in the resource pool %s",
+		task.GetValue(), n.id)
 }
 
 // PeekGangs returns a list of gangs from the queue based on the queue type.
@@ -1154,6 +1479,23 @@ func (n *resPool) PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang,
 	return nil, nil
 }
 
+// ReprioritizeGang moves a gang in the pending queue to the given priority.
+func (n *resPool) ReprioritizeGang(
+	gang *resmgrsvc.Gang,
+	priority uint32) error {
+	n.Lock()
+	defer n.Unlock()
+
+	// the gang is removed from the level of its current priority
+	if err := n.pendingQueue.Remove(gang); err != nil {
+		return err
+	}
+	for _, task := range gang.GetTasks() {
+		task.Priority = priority
+	}
+	return n.pendingQueue.Enqueue(gang)
+}
+
 func (n *resPool) isPreemptionEnabled() bool {
 	return n.preemptionCfg.Enabled
 }
@@ -1170,6 +1512,8 @@ func getLimits(resourceConfigs map[string]*respool.ResourceConfig) *scalar.Resou
 			resources.MEMORY = res.Limit
 		case common.DISK:
 			resources.DISK = res.Limit
+		default:
+			resources.Set(kind, res.Limit)
 		}
 	}
 	return &resources
@@ -1187,6 +1531,8 @@ func getShare(resourceConfigs map[string]*respool.ResourceConfig) *scalar.Resour
 			resources.MEMORY = res.Share
 		case common.DISK:
 			resources.DISK = res.Share
+		default:
+			resources.Set(kind, res.Share)
 		}
 	}
 	return &resources
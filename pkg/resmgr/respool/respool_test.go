@@ -18,6 +18,7 @@ import (
 	"container/list"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -396,6 +397,78 @@ func (s *ResPoolSuite) TestResPoolDequeue() {
 	s.Equal(0, priorityQueue.Len(2))
 }
 
+// TestResPoolReprioritizeGang tests moving a pending gang to a new priority
+func (s *ResPoolSuite) TestResPoolReprioritizeGang() {
+	resPoolNode := s.createTestResourcePool()
+
+	for _, t := range s.getTasks() {
+		s.NoError(resPoolNode.EnqueueGang(makeTaskGang(t)))
+	}
+	demand := resPoolNode.GetDemand()
+
+	gangs, err := resPoolNode.PeekGangs(PendingQueue, 10)
+	s.NoError(err)
+	last := gangs[len(gangs)-1]
+	s.Equal("job1-1", last.GetTasks()[0].GetId().GetValue())
+
+	s.NoError(resPoolNode.ReprioritizeGang(last, 100))
+	s.Equal(uint32(100), last.GetTasks()[0].GetPriority())
+	s.Equal(demand, resPoolNode.GetDemand())
+
+	gangs, err = resPoolNode.PeekGangs(PendingQueue, 10)
+	s.NoError(err)
+	s.Len(gangs, 4)
+	s.Equal("job1-1", gangs[0].GetTasks()[0].GetId().GetValue())
+
+	// a gang not in the pending queue can't be reprioritized
+	s.Error(resPoolNode.ReprioritizeGang(
+		makeTaskGang(s.getTasks()[0]), 100))
+}
+
+// TestResPoolSetSchedulingPolicy tests that the queued gangs are moved to
+// new queues when the scheduling policy of the pool changes
+func (s *ResPoolSuite) TestResPoolSetSchedulingPolicy() {
+	resPoolNode := s.createTestResourcePool()
+
+	for _, t := range s.getTasks() {
+		s.NoError(resPoolNode.EnqueueGang(makeTaskGang(t)))
+	}
+
+	resPoolNode.SetResourcePoolConfig(&pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_FIFO,
+	})
+
+	resPool, ok := resPoolNode.(*resPool)
+	s.True(ok)
+
+	// SchedulingPolicy_FIFO uses FIFOQueue
+	_, ok = resPool.pendingQueue.(*queue.FIFOQueue)
+	s.True(ok)
+	_, ok = resPool.npQueue.(*queue.FIFOQueue)
+	s.True(ok)
+
+	// the gangs keep the order of the priority queue
+	gangs, err := resPoolNode.PeekGangs(PendingQueue, 10)
+	s.NoError(err)
+	s.Len(gangs, 4)
+	for i, id := range []string{"job2-1", "job2-2", "job1-2", "job1-1"} {
+		s.Equal(id, gangs[i].GetTasks()[0].GetId().GetValue())
+	}
+
+	// an unknown policy keeps the existing queues
+	resPoolNode.SetResourcePoolConfig(&pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+	})
+	_, ok = resPool.pendingQueue.(*queue.FIFOQueue)
+	s.True(ok)
+	s.Equal(4, resPool.pendingQueue.Size())
+}
+
 func (s *ResPoolSuite) TestResPoolDequeueNonLeaf() {
 	resPoolNode := s.createTestResourcePool()
 	children := list.New()
@@ -942,7 +1015,6 @@ func (s *ResPoolSuite) TestTaskValidation() {
 
 	for _, t := range s.getTasks() {
 		resPoolNode1.EnqueueGang(makeTaskGang(t))
-		resPoolNode1.AddInvalidTask(t.Id)
 	}
 	demand := resPoolNode1.GetDemand()
 	s.NotNil(demand)
@@ -951,6 +1023,10 @@ func (s *ResPoolSuite) TestTaskValidation() {
 	s.Equal(float64(40), demand.DISK)
 	s.Equal(float64(0), demand.GPU)
 
+	for _, t := range s.getTasks() {
+		s.NoError(resPoolNode1.RemoveTask(t.Id))
+	}
+
 	gangs, err := resPoolNode1.DequeueGangs(4)
 	s.NoError(err)
 	s.Equal(0, len(gangs))
@@ -1313,3 +1389,145 @@ func (s *ResPoolSuite) TestAggregateQueue() {
 func TestResPoolSuite(t *testing.T) {
 	suite.Run(t, new(ResPoolSuite))
 }
+
+func (s *ResPoolSuite) TestGangReservation() {
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		GangReservation: &pb_respool.GangReservation{
+			MinTasks:       2,
+			MaxWaitSeconds: 60,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+	// the pool can only fit one task
+	resPool.SetNonSlackEntitlement(&scalar.Resources{
+		CPU:    1,
+		MEMORY: 1000,
+		DISK:   100,
+		GPU:    2,
+	})
+
+	tasks := s.getTasks()
+	largeGang := makeTaskGang(tasks[0])
+	largeGang.Tasks = append(largeGang.Tasks, tasks[1])
+	s.NoError(resPool.EnqueueGang(largeGang))
+
+	// the capacity is reserved for the large gang which can't be admitted
+	gangs, err := resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Empty(gangs)
+	s.Equal(largeGang, resPool.reservedGang)
+	s.Equal(scalar.GetGangResources(largeGang), resPool.GetReservedGangDemand())
+
+	// a gang of a higher priority which fits isn't admitted while the
+	// capacity is reserved
+	s.NoError(resPool.EnqueueGang(makeTaskGang(tasks[2])))
+	gangs, err = resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Empty(gangs)
+	s.Equal(2, resPool.pendingQueue.Size())
+
+	// the large gang is admitted once enough capacity is freed up
+	resPool.SetNonSlackEntitlement(s.getEntitlement())
+	gangs, err = resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Equal([]*resmgrsvc.Gang{largeGang}, gangs)
+	s.Nil(resPool.reservedGang)
+
+	gangs, err = resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Len(gangs, 1)
+	s.Equal(0, resPool.pendingQueue.Size())
+}
+
+func (s *ResPoolSuite) TestGangReservationReleased() {
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		GangReservation: &pb_respool.GangReservation{
+			MinTasks:       2,
+			MaxWaitSeconds: 60,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+	resPool.SetNonSlackEntitlement(s.getEntitlement())
+
+	tasks := s.getTasks()
+	largeGang := makeTaskGang(tasks[0])
+	largeGang.Tasks = append(largeGang.Tasks, tasks[1])
+	s.NoError(resPool.EnqueueGang(largeGang))
+	s.NoError(resPool.EnqueueGang(makeTaskGang(tasks[2])))
+	resPool.reservedGang = largeGang
+	resPool.reservedSince = time.Now()
+
+	// the reservation is released once the gang is killed
+	s.NoError(resPool.RemoveTask(tasks[0].GetId()))
+	s.NoError(resPool.RemoveTask(tasks[1].GetId()))
+	gangs, err := resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Len(gangs, 1)
+	s.Nil(resPool.reservedGang)
+	s.Equal(&scalar.Resources{}, resPool.GetReservedGangDemand())
+}
+
+func (s *ResPoolSuite) TestGangReservationNoMaxWait() {
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		GangReservation: &pb_respool.GangReservation{
+			MinTasks: 2,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+
+	// no capacity is reserved without a max wait
+	tasks := s.getTasks()
+	largeGang := makeTaskGang(tasks[0])
+	largeGang.Tasks = append(largeGang.Tasks, tasks[1])
+	resPool.reserveCapacity(largeGang)
+	s.Nil(resPool.reservedGang)
+}
+
+func (s *ResPoolSuite) TestGangReservationExpired() {
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+		GangReservation: &pb_respool.GangReservation{
+			MinTasks:       2,
+			MaxWaitSeconds: 60,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+	resPool.SetNonSlackEntitlement(s.getEntitlement())
+
+	tasks := s.getTasks()
+	largeGang := makeTaskGang(tasks[0])
+	largeGang.Tasks = append(largeGang.Tasks, tasks[1])
+	s.NoError(resPool.EnqueueGang(largeGang))
+	s.NoError(resPool.EnqueueGang(makeTaskGang(tasks[2])))
+	resPool.reservedGang = largeGang
+	resPool.reservedSince = time.Now().Add(-2 * time.Minute)
+
+	// the pool admits the gangs in order once the reservation expired
+	gangs, err := resPool.DequeueGangs(10)
+	s.NoError(err)
+	s.Len(gangs, 2)
+	s.Nil(resPool.reservedGang)
+}
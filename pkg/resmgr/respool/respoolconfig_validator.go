@@ -63,6 +63,9 @@ func NewResourcePoolConfigValidator(rTree Tree) (Validator, error) {
 			ValidateSiblings,
 			ValidateChildrenReservations,
 			ValidateControllerLimit,
+			ValidateBorrowLimit,
+			ValidateOwnerQuota,
+			ValidateGangReservation,
 		},
 	)
 }
@@ -273,17 +276,26 @@ func ValidateResourcePool(_ Tree,
 		common.MEMORY: false,
 		common.DISK:   false,
 	}
+	// any other kind is a custom resource, which is not set to default
+	// value if not configured
+	customConfigSet := make(map[string]bool)
 	cResources := resPoolConfig.Resources
 	for _, cResource := range cResources {
 		kind := cResource.Kind
+		if !isValidResourceKind(kind) {
+			return errors.Errorf("resource pool config has invalid resource type %s", kind)
+		}
 		configed, ok := resconfigSet[kind]
 		if !ok {
-			return errors.Errorf("resource pool config has unknown resource type %s", kind)
+			configed = customConfigSet[kind]
+			customConfigSet[kind] = true
 		}
 		if configed {
 			return errors.Errorf("resource pool config has multiple configurations for resource type %s", kind)
 		}
-		resconfigSet[kind] = true
+		if ok {
+			resconfigSet[kind] = true
+		}
 		if cResource.Reservation < 0 {
 			return errors.Errorf("resource pool config resource values can not be negative "+
 				"%s: Reservation %v",
@@ -330,6 +342,20 @@ func ValidateResourcePool(_ Tree,
 	return nil
 }
 
+// isValidResourceKind returns false for an empty kind and for the names of
+// the Mesos resources which are tracked as cpu, memory, disk, gpu or ports.
+func isValidResourceKind(kind string) bool {
+	switch kind {
+	case "",
+		common.MesosCPU,
+		common.MesosMem,
+		common.MesosGPU,
+		common.MesosPorts:
+		return false
+	}
+	return true
+}
+
 // ValidateCycle if adding/updating current pool would result in a cycle
 func ValidateCycle(_ Tree,
 	resourcePoolConfigData ResourcePoolConfigData) error {
@@ -388,3 +414,50 @@ func ValidateControllerLimit(_ Tree,
 	}
 	return nil
 }
+
+// ValidateBorrowLimit validates the borrow limit
+func ValidateBorrowLimit(_ Tree,
+	resourcePoolConfigData ResourcePoolConfigData) error {
+	borrowLimit := resourcePoolConfigData.ResourcePoolConfig.GetBorrowLimit()
+	if borrowLimit == nil {
+		return nil
+	}
+
+	if borrowLimit.GetMaxPercent() < 0 {
+		return errors.New("borrow limit, " +
+			"max percent cannot be negative")
+	}
+	return nil
+}
+
+// ValidateGangReservation validates the gang reservation
+func ValidateGangReservation(_ Tree,
+	resourcePoolConfigData ResourcePoolConfigData) error {
+	gangReservation := resourcePoolConfigData.ResourcePoolConfig.
+		GetGangReservation()
+	if gangReservation == nil {
+		return nil
+	}
+
+	if gangReservation.GetMaxWaitSeconds() == 0 {
+		return errors.New("gang reservation, " +
+			"max wait seconds should be more than 0")
+	}
+	return nil
+}
+
+// ValidateOwnerQuota validates the owner quota
+func ValidateOwnerQuota(_ Tree,
+	resourcePoolConfigData ResourcePoolConfigData) error {
+	ownerQuota := resourcePoolConfigData.ResourcePoolConfig.GetOwnerQuota()
+	if ownerQuota == nil {
+		return nil
+	}
+
+	maxPercent := ownerQuota.GetMaxPercentPerOwner()
+	if maxPercent < 0 || maxPercent > 100 {
+		return errors.New("owner quota, " +
+			"max percent per owner should be between 0 and 100")
+	}
+	return nil
+}
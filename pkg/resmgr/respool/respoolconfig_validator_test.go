@@ -753,6 +753,126 @@ func (s *resPoolConfigValidatorSuite) TestValidateControllerLimit() {
 	}
 }
 
+func (s *resPoolConfigValidatorSuite) TestValidateBorrowLimit() {
+	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
+	_, err := rv.Register(
+		[]ResourcePoolConfigValidatorFunc{
+			ValidateBorrowLimit,
+		},
+	)
+	s.NoError(err)
+
+	tt := []struct {
+		maxPercent float64
+		err        error
+	}{
+		{
+			maxPercent: -10,
+			err:        errors.New("borrow limit, max percent cannot be negative"),
+		},
+		{
+			maxPercent: 0,
+			err:        nil,
+		},
+		{
+			maxPercent: 150,
+			err:        nil,
+		},
+	}
+
+	for _, t := range tt {
+		resourcePoolConfigData := ResourcePoolConfigData{
+			ResourcePoolConfig: &pb_respool.ResourcePoolConfig{
+				BorrowLimit: &pb_respool.BorrowLimit{
+					MaxPercent: t.maxPercent,
+				},
+			},
+		}
+		err = rv.Validate(resourcePoolConfigData)
+		if t.err != nil {
+			s.EqualError(t.err, err.Error())
+		} else {
+			s.NoError(err)
+		}
+	}
+}
+
+func (s *resPoolConfigValidatorSuite) TestValidateGangReservation() {
+	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
+	_, err := rv.Register(
+		[]ResourcePoolConfigValidatorFunc{
+			ValidateGangReservation,
+		},
+	)
+	s.NoError(err)
+
+	err = rv.Validate(ResourcePoolConfigData{
+		ResourcePoolConfig: &pb_respool.ResourcePoolConfig{
+			GangReservation: &pb_respool.GangReservation{
+				MinTasks: 10,
+			},
+		},
+	})
+	s.EqualError(err, "gang reservation, "+
+		"max wait seconds should be more than 0")
+
+	err = rv.Validate(ResourcePoolConfigData{
+		ResourcePoolConfig: &pb_respool.ResourcePoolConfig{
+			GangReservation: &pb_respool.GangReservation{
+				MinTasks:       10,
+				MaxWaitSeconds: 300,
+			},
+		},
+	})
+	s.NoError(err)
+}
+
+func (s *resPoolConfigValidatorSuite) TestValidateOwnerQuota() {
+	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
+	_, err := rv.Register(
+		[]ResourcePoolConfigValidatorFunc{
+			ValidateOwnerQuota,
+		},
+	)
+	s.NoError(err)
+
+	tt := []struct {
+		maxPercent float64
+		err        error
+	}{
+		{
+			maxPercent: -10,
+			err: errors.New("owner quota, " +
+				"max percent per owner should be between 0 and 100"),
+		},
+		{
+			maxPercent: 150,
+			err: errors.New("owner quota, " +
+				"max percent per owner should be between 0 and 100"),
+		},
+		{
+			maxPercent: 50,
+			err:        nil,
+		},
+	}
+
+	for _, t := range tt {
+		resourcePoolConfigData := ResourcePoolConfigData{
+			ResourcePoolConfig: &pb_respool.ResourcePoolConfig{
+				OwnerQuota: &pb_respool.OwnerQuota{
+					MaxPercentPerOwner: t.maxPercent,
+				},
+			},
+		}
+		err = rv.Validate(resourcePoolConfigData)
+		if t.err != nil {
+			s.EqualError(t.err, err.Error())
+		} else {
+			s.NoError(err)
+		}
+	}
+}
+
 func (s *resPoolConfigValidatorSuite) TestValidateNoConfigResources() {
 	mockResourcePoolID := &peloton.ResourcePoolID{Value: "respool33"}
 	mockParentPoolID := &peloton.ResourcePoolID{Value: "respool11"}
@@ -793,6 +913,42 @@ func (s *resPoolConfigValidatorSuite) TestValidateNoConfigResources() {
 	s.ElementsMatch(shares, []float64{1, 1, 1, 1})
 }
 
+// TestValidateCustomResources tests that custom resources are accepted and
+// not set to default value, while the unset resources other than the
+// custom ones are
+func (s *resPoolConfigValidatorSuite) TestValidateCustomResources() {
+	mockResourcePoolConfig := &pb_respool.ResourcePoolConfig{
+		Parent: &peloton.ResourcePoolID{Value: "respool11"},
+		Resources: []*pb_respool.ResourceConfig{
+			{
+				Reservation: 1,
+				Kind:        "fpgas",
+				Limit:       2,
+				Share:       1,
+			},
+		},
+		Policy: pb_respool.SchedulingPolicy_PriorityFIFO,
+		Name:   "respool33",
+	}
+
+	rv := &resourcePoolConfigValidator{resTree: s.resourceTree}
+	_, err := rv.Register(
+		[]ResourcePoolConfigValidatorFunc{ValidateResourcePool})
+	s.NoError(err)
+
+	s.NoError(rv.Validate(ResourcePoolConfigData{
+		ID:                 &peloton.ResourcePoolID{Value: "respool33"},
+		ResourcePoolConfig: mockResourcePoolConfig,
+	}))
+
+	kinds := []string{}
+	for _, v := range mockResourcePoolConfig.Resources {
+		kinds = append(kinds, v.Kind)
+	}
+	s.ElementsMatch(kinds, []string{
+		"fpgas", common.CPU, common.MEMORY, common.DISK, common.GPU})
+}
+
 func (s *resPoolConfigValidatorSuite) validateOnWrongResources(resources []*pb_respool.ResourceConfig) error {
 	mockParentPoolID := &peloton.ResourcePoolID{Value: "respoolp"}
 	mockResourcePoolID := &peloton.ResourcePoolID{Value: "respoolc"}
@@ -867,12 +1023,29 @@ func (s *resPoolConfigValidatorSuite) TestValidateResourcePoolOnWrongResources()
 			resources: []*pb_respool.ResourceConfig{
 				{
 					Reservation: 5,
-					Kind:        "aaa",
+					Kind:        "cpus",
 					Limit:       10,
 					Share:       2,
 				},
 			},
-			expectedErr: "resource pool config has unknown resource type aaa",
+			expectedErr: "resource pool config has invalid resource type cpus",
+		},
+		{
+			resources: []*pb_respool.ResourceConfig{
+				{
+					Reservation: 1,
+					Kind:        "fpgas",
+					Limit:       2,
+					Share:       1,
+				},
+				{
+					Reservation: 1,
+					Kind:        "fpgas",
+					Limit:       2,
+					Share:       1,
+				},
+			},
+			expectedErr: "resource pool config has multiple configurations for resource type fpgas",
 		},
 	}
 	for _, t := range test {
@@ -17,6 +17,7 @@ package respoolsvc
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -26,6 +27,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -37,6 +39,8 @@ const (
 	resPoolDeleteErrString    = "resource pool could not be deleted"
 	resPoolIsBusyErrString    = "resource pool is busy"
 	resPoolIsNotLeafErrString = "resource pool is not leaf"
+
+	capacityReservationNotFoundErrString = "capacity reservation not found"
 )
 
 // ServiceHandler implements peloton.api.respool.ResourcePoolService
@@ -457,6 +461,14 @@ func (h *ServiceHandler) UpdateResourcePool(
 		}, nil
 	}
 
+	existingConfig := existingResPool.ResourcePoolConfig()
+
+	// capacity reservations are only managed by their own APIs.
+	if resPoolConfig != nil {
+		resPoolConfig.CapacityReservations =
+			existingConfig.GetCapacityReservations()
+	}
+
 	// update persistent store.
 	if err := h.resPoolOps.Update(ctx, resPoolID, resPoolConfig); err != nil {
 		h.metrics.UpdateResourcePoolFail.Inc(1)
@@ -493,7 +505,7 @@ func (h *ServiceHandler) UpdateResourcePool(
 		if err := h.resPoolOps.Update(
 			ctx,
 			resPoolID,
-			existingResPool.ResourcePoolConfig(),
+			existingConfig,
 		); err != nil {
 			log.WithError(err).
 				Infof("Error rolling back respoolID: %s in store",
@@ -507,6 +519,184 @@ func (h *ServiceHandler) UpdateResourcePool(
 	return &respool.UpdateResponse{}, nil
 }
 
+// CreateCapacityReservation reserves capacity in a leaf resource pool for a
+// future time window.
+func (h *ServiceHandler) CreateCapacityReservation(
+	ctx context.Context,
+	req *respool.CreateCapacityReservationRequest) (
+	*respool.CreateCapacityReservationResponse,
+	error) {
+
+	h.Lock()
+	defer h.Unlock()
+
+	h.metrics.APICreateCapacityReservation.Inc(1)
+	log.WithField(
+		"request",
+		req,
+	).Info("CreateCapacityReservation called")
+
+	resPoolID := req.GetId()
+	resPool, err := h.resPoolTree.Get(resPoolID)
+	if err != nil {
+		h.metrics.CreateCapacityReservationFail.Inc(1)
+		return &respool.CreateCapacityReservationResponse{
+			Error: &respool.CreateCapacityReservationResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{
+					Id:      resPoolID,
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	if err := res.ValidateCapacityReservation(
+		resPool,
+		req.GetReservation(),
+		time.Now()); err != nil {
+		h.metrics.CreateCapacityReservationFail.Inc(1)
+		log.WithError(err).
+			WithField("respool_id", resPoolID.GetValue()).
+			Info("Error validating capacity reservation")
+		return &respool.CreateCapacityReservationResponse{
+			Error: &respool.CreateCapacityReservationResponse_Error{
+				InvalidReservation: &respool.InvalidCapacityReservation{
+					Id:      resPoolID,
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	reservation := proto.Clone(
+		req.GetReservation()).(*respool.CapacityReservation)
+	reservation.Id = uuid.New()
+
+	existingConfig := resPool.ResourcePoolConfig()
+	resPoolConfig := proto.Clone(existingConfig).(*respool.ResourcePoolConfig)
+	resPoolConfig.CapacityReservations = append(
+		resPoolConfig.CapacityReservations,
+		reservation)
+
+	if err := h.updateResPoolConfig(
+		ctx,
+		resPoolID,
+		existingConfig,
+		resPoolConfig); err != nil {
+		h.metrics.CreateCapacityReservationFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.CreateCapacityReservationSuccess.Inc(1)
+	log.WithFields(log.Fields{
+		"respool_id":     resPoolID.GetValue(),
+		"reservation_id": reservation.GetId(),
+	}).Info("Capacity reservation created")
+	return &respool.CreateCapacityReservationResponse{
+		ReservationId: reservation.GetId(),
+	}, nil
+}
+
+// DeleteCapacityReservation deletes a capacity reservation of a resource
+// pool.
+func (h *ServiceHandler) DeleteCapacityReservation(
+	ctx context.Context,
+	req *respool.DeleteCapacityReservationRequest) (
+	*respool.DeleteCapacityReservationResponse,
+	error) {
+
+	h.Lock()
+	defer h.Unlock()
+
+	h.metrics.APIDeleteCapacityReservation.Inc(1)
+	log.WithField(
+		"request",
+		req,
+	).Info("DeleteCapacityReservation called")
+
+	resPoolID := req.GetId()
+	resPool, err := h.resPoolTree.Get(resPoolID)
+	if err != nil {
+		h.metrics.DeleteCapacityReservationFail.Inc(1)
+		return &respool.DeleteCapacityReservationResponse{
+			Error: &respool.DeleteCapacityReservationResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{
+					Id:      resPoolID,
+					Message: err.Error(),
+				},
+			},
+		}, nil
+	}
+
+	existingConfig := resPool.ResourcePoolConfig()
+	var reservations []*respool.CapacityReservation
+	for _, r := range existingConfig.GetCapacityReservations() {
+		if r.GetId() != req.GetReservationId() {
+			reservations = append(reservations, r)
+		}
+	}
+
+	if len(reservations) == len(existingConfig.GetCapacityReservations()) {
+		h.metrics.DeleteCapacityReservationFail.Inc(1)
+		return &respool.DeleteCapacityReservationResponse{
+			Error: &respool.DeleteCapacityReservationResponse_Error{
+				ReservationNotFound: &respool.CapacityReservationNotFound{
+					Id:            resPoolID,
+					ReservationId: req.GetReservationId(),
+					Message:       capacityReservationNotFoundErrString,
+				},
+			},
+		}, nil
+	}
+
+	resPoolConfig := proto.Clone(existingConfig).(*respool.ResourcePoolConfig)
+	resPoolConfig.CapacityReservations = reservations
+
+	if err := h.updateResPoolConfig(
+		ctx,
+		resPoolID,
+		existingConfig,
+		resPoolConfig); err != nil {
+		h.metrics.DeleteCapacityReservationFail.Inc(1)
+		return nil, err
+	}
+
+	h.metrics.DeleteCapacityReservationSuccess.Inc(1)
+	return &respool.DeleteCapacityReservationResponse{}, nil
+}
+
+// updateResPoolConfig updates the config of the resource pool in the store
+// and in the in-memory tree, the store is rolled back to the existing config
+// if the tree could not be updated.
+func (h *ServiceHandler) updateResPoolConfig(
+	ctx context.Context,
+	resPoolID *peloton.ResourcePoolID,
+	existingConfig *respool.ResourcePoolConfig,
+	resPoolConfig *respool.ResourcePoolConfig) error {
+	if err := h.resPoolOps.Update(ctx, resPoolID, resPoolConfig); err != nil {
+		log.WithError(err).
+			Infof("Error updating respoolID: %s in store",
+				resPoolID.GetValue())
+		return err
+	}
+
+	if err := h.resPoolTree.Upsert(resPoolID, resPoolConfig); err != nil {
+		log.WithError(err).
+			Infof("Error updating respoolID: %s in memory tree",
+				resPoolID.GetValue())
+		if err := h.resPoolOps.Update(
+			ctx,
+			resPoolID,
+			existingConfig); err != nil {
+			log.WithError(err).
+				Infof("Error rolling back respoolID: %s in store",
+					resPoolID.GetValue())
+		}
+		return err
+	}
+	return nil
+}
+
 // LookupResourcePoolID returns the resource pool ID for a given resource pool
 // path.
 func (h *ServiceHandler) LookupResourcePoolID(ctx context.Context,
@@ -17,6 +17,7 @@ package respoolsvc
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -527,6 +528,7 @@ func (s *resPoolHandlerTestSuite) TestUpdateError() {
 
 	updateReq := s.getUpdateRequest()
 	resTree.EXPECT().Get(gomock.Any()).Return(respool, nil)
+	respool.EXPECT().ResourcePoolConfig().Return(nil)
 	// set expectations
 	s.mockResPoolOps.EXPECT().Update(
 		gomock.Any(), gomock.Any(), gomock.Any()).Return(assert.AnError)
@@ -877,6 +879,181 @@ func (s *resPoolHandlerTestSuite) TestDeleteResourcePoolErrors() {
 	}
 }
 
+func (s *resPoolHandlerTestSuite) TestCreateDeleteCapacityReservation() {
+	resPoolID := &peloton.ResourcePoolID{Value: "respool23"}
+	now := time.Now()
+	reservation := &pb_respool.CapacityReservation{
+		Resources: []*pb_respool.CapacityReservationResource{
+			{
+				Kind:   "cpu",
+				Amount: 20,
+			},
+		},
+		StartTime:       now.Add(time.Hour).Format(time.RFC3339),
+		EndTime:         now.Add(2 * time.Hour).Format(time.RFC3339),
+		GracePeriodSecs: 600,
+	}
+
+	s.mockResPoolOps.EXPECT().
+		Update(gomock.Any(), resPoolID, gomock.Any()).
+		Do(func(_ context.Context,
+			_ *peloton.ResourcePoolID,
+			config *pb_respool.ResourcePoolConfig) {
+			s.Len(config.GetCapacityReservations(), 1)
+			s.NotEmpty(config.GetCapacityReservations()[0].GetId())
+		}).
+		Return(nil)
+
+	createResp, err := s.handler.CreateCapacityReservation(
+		s.context,
+		&pb_respool.CreateCapacityReservationRequest{
+			Id:          resPoolID,
+			Reservation: reservation,
+		})
+	s.NoError(err)
+	s.Nil(createResp.GetError())
+	s.NotEmpty(createResp.GetReservationId())
+
+	resPool, err := s.resourceTree.Get(resPoolID)
+	s.NoError(err)
+	statuses := resPool.ToResourcePoolInfo().GetCapacityReservations()
+	s.Len(statuses, 1)
+	s.Equal(createResp.GetReservationId(),
+		statuses[0].GetReservation().GetId())
+	s.Equal(pb_respool.CapacityReservationStatus_PENDING,
+		statuses[0].GetState())
+
+	// capacity reservations are kept across updates of the config.
+	s.mockResPoolOps.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil)
+	updateResp, err := s.handler.UpdateResourcePool(
+		s.context,
+		&pb_respool.UpdateRequest{
+			Id: resPoolID,
+			Config: &pb_respool.ResourcePoolConfig{
+				Name:      "respool23",
+				Parent:    &peloton.ResourcePoolID{Value: "respool22"},
+				Resources: resPool.ResourcePoolConfig().GetResources(),
+				Policy:    pb_respool.SchedulingPolicy_PriorityFIFO,
+			},
+		})
+	s.NoError(err)
+	s.Nil(updateResp.GetError())
+	s.Len(resPool.ResourcePoolConfig().GetCapacityReservations(), 1)
+
+	s.mockResPoolOps.EXPECT().
+		Update(gomock.Any(), resPoolID, gomock.Any()).
+		Do(func(_ context.Context,
+			_ *peloton.ResourcePoolID,
+			config *pb_respool.ResourcePoolConfig) {
+			s.Empty(config.GetCapacityReservations())
+		}).
+		Return(nil)
+
+	deleteReq := &pb_respool.DeleteCapacityReservationRequest{
+		Id:            resPoolID,
+		ReservationId: createResp.GetReservationId(),
+	}
+	deleteResp, err := s.handler.DeleteCapacityReservation(
+		s.context,
+		deleteReq)
+	s.NoError(err)
+	s.Nil(deleteResp.GetError())
+	s.Empty(resPool.ToResourcePoolInfo().GetCapacityReservations())
+
+	// deleting it again should fail
+	deleteResp, err = s.handler.DeleteCapacityReservation(
+		s.context,
+		deleteReq)
+	s.NoError(err)
+	s.Equal(capacityReservationNotFoundErrString,
+		deleteResp.GetError().GetReservationNotFound().GetMessage())
+}
+
+func (s *resPoolHandlerTestSuite) TestCreateCapacityReservationErrors() {
+	now := time.Now()
+	newReservation := func(
+		kind string,
+		amount float64,
+		start time.Time) *pb_respool.CapacityReservation {
+		return &pb_respool.CapacityReservation{
+			Resources: []*pb_respool.CapacityReservationResource{
+				{
+					Kind:   kind,
+					Amount: amount,
+				},
+			},
+			StartTime: start.Format(time.RFC3339),
+			EndTime:   start.Add(time.Hour).Format(time.RFC3339),
+		}
+	}
+
+	tt := []struct {
+		msg         string
+		id          string
+		reservation *pb_respool.CapacityReservation
+		notFound    bool
+		wantErr     string
+	}{
+		{
+			msg:         "resource pool not found",
+			id:          "respool105",
+			reservation: newReservation("cpu", 10, now),
+			notFound:    true,
+		},
+		{
+			msg:         "resource pool not leaf",
+			id:          "respool22",
+			reservation: newReservation("cpu", 10, now),
+			wantErr: "capacity can only be reserved in a leaf " +
+				"resource pool",
+		},
+		{
+			msg:         "reservation exceeds limit",
+			id:          "respool23",
+			reservation: newReservation("cpu", 60, now),
+			wantErr:     "reserved cpu exceeds the limit 100 of the resource pool",
+		},
+		{
+			msg:         "unknown resource kind",
+			id:          "respool23",
+			reservation: newReservation("ports", 10, now),
+			wantErr:     "unknown resource kind ports",
+		},
+		{
+			msg:         "window already ended",
+			id:          "respool23",
+			reservation: newReservation("cpu", 10, now.Add(-2*time.Hour)),
+			wantErr:     "reservation window has already ended",
+		},
+		{
+			msg:     "missing reservation",
+			id:      "respool23",
+			wantErr: "invalid start time",
+		},
+	}
+
+	for _, t := range tt {
+		s.T().Run(t.msg, func(_ *testing.T) {
+			resp, err := s.handler.CreateCapacityReservation(
+				s.context,
+				&pb_respool.CreateCapacityReservationRequest{
+					Id:          &peloton.ResourcePoolID{Value: t.id},
+					Reservation: t.reservation,
+				})
+			s.NoError(err)
+			if t.notFound {
+				s.NotNil(resp.GetError().GetNotFound())
+				return
+			}
+			s.Contains(
+				resp.GetError().GetInvalidReservation().GetMessage(),
+				t.wantErr)
+		})
+	}
+}
+
 func TestResPoolHandler(t *testing.T) {
 	suite.Run(t, new(resPoolHandlerTestSuite))
 }
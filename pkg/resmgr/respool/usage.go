@@ -0,0 +1,73 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package respool
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+// usageRetention is how long the usage samples of a resource pool are
+// retained for.
+const usageRetention = 24 * time.Hour
+
+// UsageSample is the usage of a resource pool sampled on an entitlement
+// cycle.
+type UsageSample struct {
+	// Time of the sample
+	Time time.Time
+	// Resources of the gangs waiting to be admitted
+	Demand *scalar.Resources
+	// Resources allocated to the admitted tasks
+	Allocation *scalar.Resources
+	// Entitlement of the resource pool
+	Entitlement *scalar.Resources
+	// Number of running tasks preempted since the previous sample
+	PreemptedTasks int
+}
+
+// usageHistory keeps the usage samples of a resource pool within the
+// retention period, the oldest first.
+type usageHistory struct {
+	samples []UsageSample
+	// number of running tasks preempted since the last sample
+	preemptedTasks int
+}
+
+// record adds a sample of the current usage and drops the samples older
+// than the retention period.
+func (h *usageHistory) record(sample UsageSample) {
+	sample.PreemptedTasks = h.preemptedTasks
+	h.preemptedTasks = 0
+
+	cutoff := sample.Time.Add(-usageRetention)
+	i := 0
+	for i < len(h.samples) && h.samples[i].Time.Before(cutoff) {
+		i++
+	}
+	h.samples = append(h.samples[i:], sample)
+}
+
+// since returns the samples taken at or after the given time.
+func (h *usageHistory) since(t time.Time) []UsageSample {
+	i := len(h.samples)
+	for i > 0 && !h.samples[i-1].Time.Before(t) {
+		i--
+	}
+	samples := make([]UsageSample, len(h.samples)-i)
+	copy(samples, h.samples[i:])
+	return samples
+}
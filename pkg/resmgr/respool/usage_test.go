@@ -0,0 +1,54 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package respool
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+func (s *ResPoolSuite) TestUsageHistory() {
+	pool := s.createTestResourcePool()
+
+	pool.AddPreemptedTasks(2)
+	pool.UpdateResourceMetrics()
+	pool.AddPreemptedTasks(1)
+	pool.UpdateResourceMetrics()
+
+	samples := pool.GetUsageHistory(time.Time{})
+	s.Len(samples, 2)
+	s.Equal(2, samples[0].PreemptedTasks)
+	s.Equal(1, samples[1].PreemptedTasks)
+	s.Equal(scalar.ZeroResource, samples[1].Allocation)
+
+	s.Empty(pool.GetUsageHistory(time.Now().UTC().Add(time.Minute)))
+}
+
+func (s *ResPoolSuite) TestUsageHistoryRetention() {
+	start := time.Date(2019, time.March, 1, 10, 0, 0, 0, time.UTC)
+	h := &usageHistory{}
+	for i := 0; i < 3; i++ {
+		h.record(UsageSample{Time: start.Add(time.Duration(i) * time.Hour)})
+	}
+	s.Len(h.since(start), 3)
+	s.Len(h.since(start.Add(90*time.Minute)), 1)
+
+	// the samples older than the retention period are dropped
+	h.record(UsageSample{Time: start.Add(usageRetention + 90*time.Minute)})
+	samples := h.since(time.Time{})
+	s.Len(samples, 2)
+	s.Equal(start.Add(2*time.Hour), samples[0].Time)
+}
@@ -17,6 +17,8 @@ package scalar
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -51,6 +53,67 @@ const (
 // Allocation is the container to track allocation across different dimensions
 type Allocation struct {
 	Value map[AllocationType]*Resources
+	// Owners tracks the usage of the tasks of each owner of a task or gang
+	// allocation, they are not carried over by Add and Subtract
+	Owners map[string]*Usage
+	// Jobs tracks the usage of the tasks of each job of a task or gang
+	// allocation, they are not carried over by Add and Subtract
+	Jobs map[string]*Usage
+}
+
+// Usage is the number of tasks and the resources allocated to a consumer of
+// the resource pool, like an owner or a job
+type Usage struct {
+	Tasks     int
+	Resources *Resources
+}
+
+// zeroUsage is the usage of a consumer without any allocation
+var zeroUsage = &Usage{Resources: ZeroResource}
+
+// Usages tracks the usage of the tasks of each owner and job of a resource
+// pool. It is updated in place, so it must be guarded by the lock of the
+// resource pool.
+type Usages struct {
+	owners map[string]*Usage
+	jobs   map[string]*Usage
+}
+
+// NewUsages returns a new Usages
+func NewUsages() *Usages {
+	return &Usages{
+		owners: make(map[string]*Usage),
+		jobs:   make(map[string]*Usage),
+	}
+}
+
+// GetOwnerUsage returns the usage of the tasks of the owner
+func (u *Usages) GetOwnerUsage(owner string) *Usage {
+	if usage, ok := u.owners[owner]; ok {
+		return usage
+	}
+	return zeroUsage
+}
+
+// GetJobUsage returns the usage of the tasks of the job
+func (u *Usages) GetJobUsage(jobID string) *Usage {
+	if usage, ok := u.jobs[jobID]; ok {
+		return usage
+	}
+	return zeroUsage
+}
+
+// Add adds the usages of the owners and jobs of a task or gang allocation
+func (u *Usages) Add(alloc *Allocation) {
+	addUsages(u.owners, alloc.Owners, 1)
+	addUsages(u.jobs, alloc.Jobs, 1)
+}
+
+// Subtract subtracts the usages of the owners and jobs of a task or gang
+// allocation
+func (u *Usages) Subtract(alloc *Allocation) {
+	addUsages(u.owners, alloc.Owners, -1)
+	addUsages(u.jobs, alloc.Jobs, -1)
 }
 
 // NewAllocation returns a new Allocation
@@ -81,10 +144,33 @@ func (a *Allocation) Subtract(other *Allocation) *Allocation {
 	return result
 }
 
+// addUsages adds(sign 1) or subtracts(sign -1) the usages of u2 to the
+// usages of u1 in place. The consumers without any tasks left are dropped.
+func addUsages(u1, u2 map[string]*Usage, sign int) {
+	for k, v := range u2 {
+		current, ok := u1[k]
+		if !ok {
+			current = &Usage{Resources: ZeroResource}
+		}
+		tasks := current.Tasks + sign*v.Tasks
+		if tasks <= 0 {
+			delete(u1, k)
+			continue
+		}
+		resources := current.Resources.Add(v.Resources)
+		if sign < 0 {
+			resources = current.Resources.Subtract(v.Resources)
+		}
+		u1[k] = &Usage{Tasks: tasks, Resources: resources}
+	}
+}
+
 // initializeZeroAlloc initializes a zero alloc
 func initializeZeroAlloc() *Allocation {
 	alloc := &Allocation{
-		Value: make(map[AllocationType]*Resources),
+		Value:  make(map[AllocationType]*Resources),
+		Owners: make(map[string]*Usage),
+		Jobs:   make(map[string]*Usage),
 	}
 
 	alloc.Value[TotalAllocation] = ZeroResource
@@ -103,7 +189,13 @@ func GetGangAllocation(gang *resmgrsvc.Gang) *Allocation {
 	gangAllocation := initializeZeroAlloc()
 
 	for _, t := range gang.GetTasks() {
-		gangAllocation = gangAllocation.Add(GetTaskAllocation(t))
+		taskAllocation := GetTaskAllocation(t)
+		for allocationType, v := range gangAllocation.Value {
+			gangAllocation.Value[allocationType] = v.Add(
+				taskAllocation.Value[allocationType])
+		}
+		addUsages(gangAllocation.Owners, taskAllocation.Owners, 1)
+		addUsages(gangAllocation.Jobs, taskAllocation.Jobs, 1)
 	}
 	return gangAllocation
 }
@@ -136,6 +228,14 @@ func GetTaskAllocation(rmTask *resmgr.Task) *Allocation {
 	// every task account for total allocation
 	alloc.Value[TotalAllocation] = taskResource
 
+	// account the task to its owner and job
+	if owner := rmTask.GetOwner(); owner != "" {
+		alloc.Owners[owner] = &Usage{Tasks: 1, Resources: taskResource}
+	}
+	if jobID := rmTask.GetJobId().GetValue(); jobID != "" {
+		alloc.Jobs[jobID] = &Usage{Tasks: 1, Resources: taskResource}
+	}
+
 	return alloc
 }
 
@@ -153,6 +253,67 @@ type Resources struct {
 	MEMORY float64
 	DISK   float64
 	GPU    float64
+	// Custom holds named scalar resources other than cpu, memory, disk and
+	// gpu, keyed by resource kind. A missing kind is zero.
+	Custom map[string]float64
+}
+
+// resourceKinds are the kinds of resources every Resources has.
+var resourceKinds = []string{
+	common.CPU,
+	common.GPU,
+	common.MEMORY,
+	common.DISK,
+}
+
+// Kinds returns the kinds of resources of r, which are cpu, gpu, memory
+// and disk followed by its custom resources sorted by name.
+func (r *Resources) Kinds() []string {
+	return append(append([]string{}, resourceKinds...), r.CustomKinds()...)
+}
+
+// CustomKinds returns the kinds of the custom resources of r sorted by name.
+func (r *Resources) CustomKinds() []string {
+	var kinds []string
+	for kind := range r.Custom {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// mergeCustom merges the custom resources of r1 and r2 with the given
+// function, the values missing in either of them are zero.
+func mergeCustom(
+	r1, r2 map[string]float64,
+	f func(v1, v2 float64) float64) map[string]float64 {
+	if len(r1) == 0 && len(r2) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64, len(r1))
+	for kind, v := range r1 {
+		result[kind] = f(v, r2[kind])
+	}
+	for kind, v := range r2 {
+		if _, ok := r1[kind]; !ok {
+			result[kind] = f(0, v)
+		}
+	}
+	return result
+}
+
+// copyCustom returns a copy of the custom resources.
+func copyCustom(custom map[string]float64) map[string]float64 {
+	if len(custom) == 0 {
+		return nil
+	}
+
+	result := make(map[string]float64, len(custom))
+	for kind, v := range custom {
+		result[kind] = v
+	}
+	return result
 }
 
 // GetCPU returns the CPU resource
@@ -175,7 +336,13 @@ func (r *Resources) GetGPU() float64 {
 	return r.GPU
 }
 
-// Get returns the kind of resource
+// GetCustom returns the custom resource of the given kind
+func (r *Resources) GetCustom(kind string) float64 {
+	return r.Custom[kind]
+}
+
+// Get returns the kind of resource, any kind other than cpu, gpu, memory
+// and disk is a custom resource
 func (r *Resources) Get(kind string) float64 {
 	switch kind {
 	case common.CPU:
@@ -187,10 +354,11 @@ func (r *Resources) Get(kind string) float64 {
 	case common.DISK:
 		return r.GetDisk()
 	}
-	return float64(0)
+	return r.GetCustom(kind)
 }
 
-// Set sets the kind of resource with the Value
+// Set sets the kind of resource with the Value, any kind other than cpu,
+// gpu, memory and disk is a custom resource
 func (r *Resources) Set(kind string, value float64) {
 	switch kind {
 	case common.CPU:
@@ -201,6 +369,11 @@ func (r *Resources) Set(kind string, value float64) {
 		r.MEMORY = value
 	case common.DISK:
 		r.DISK = value
+	default:
+		if r.Custom == nil {
+			r.Custom = make(map[string]float64)
+		}
+		r.Custom[kind] = value
 	}
 }
 
@@ -211,6 +384,9 @@ func (r *Resources) Add(other *Resources) *Resources {
 		MEMORY: r.MEMORY + other.MEMORY,
 		DISK:   r.DISK + other.DISK,
 		GPU:    r.GPU + other.GPU,
+		Custom: mergeCustom(r.Custom, other.Custom, func(v1, v2 float64) float64 {
+			return v1 + v2
+		}),
 	}
 }
 
@@ -225,10 +401,18 @@ func lessThanOrEqual(f1, f2 float64) bool {
 // LessThanOrEqual determines current Resources is less than or equal
 // the other one.
 func (r *Resources) LessThanOrEqual(other *Resources) bool {
-	return lessThanOrEqual(r.CPU, other.CPU) &&
-		lessThanOrEqual(r.MEMORY, other.MEMORY) &&
-		lessThanOrEqual(r.DISK, other.DISK) &&
-		lessThanOrEqual(r.GPU, other.GPU)
+	if !lessThanOrEqual(r.CPU, other.CPU) ||
+		!lessThanOrEqual(r.MEMORY, other.MEMORY) ||
+		!lessThanOrEqual(r.DISK, other.DISK) ||
+		!lessThanOrEqual(r.GPU, other.GPU) {
+		return false
+	}
+	for kind, v := range r.Custom {
+		if !lessThanOrEqual(v, other.GetCustom(kind)) {
+			return false
+		}
+	}
+	return true
 }
 
 func equal(f1, f2 float64) bool {
@@ -238,10 +422,23 @@ func equal(f1, f2 float64) bool {
 // Equal determines current Resources is equal to
 // the other one.
 func (r *Resources) Equal(other *Resources) bool {
-	return equal(r.CPU, other.CPU) &&
-		equal(r.MEMORY, other.MEMORY) &&
-		equal(r.DISK, other.DISK) &&
-		equal(r.GPU, other.GPU)
+	if !equal(r.CPU, other.CPU) ||
+		!equal(r.MEMORY, other.MEMORY) ||
+		!equal(r.DISK, other.DISK) ||
+		!equal(r.GPU, other.GPU) {
+		return false
+	}
+	for kind, v := range r.Custom {
+		if !equal(v, other.GetCustom(kind)) {
+			return false
+		}
+	}
+	for kind, v := range other.Custom {
+		if !equal(r.GetCustom(kind), v) {
+			return false
+		}
+	}
+	return true
 }
 
 // ConvertToResmgrResource converts task resource config to scalar.Resources
@@ -251,6 +448,7 @@ func ConvertToResmgrResource(resource *task.ResourceConfig) *Resources {
 		DISK:   resource.GetDiskLimitMb(),
 		GPU:    resource.GetGpuLimit(),
 		MEMORY: resource.GetMemLimitMb(),
+		Custom: copyCustom(resource.GetCustomLimits()),
 	}
 }
 
@@ -268,8 +466,17 @@ func GetGangResources(gang *resmgrsvc.Gang) *Resources {
 }
 
 func (r *Resources) String() string {
-	return fmt.Sprintf("CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
+	s := fmt.Sprintf("CPU:%.2f MEM:%.2f DISK:%.2f GPU:%.2f",
 		r.GetCPU(), r.GetMem(), r.GetDisk(), r.GetGPU())
+	if len(r.Custom) == 0 {
+		return s
+	}
+
+	custom := make([]string, 0, len(r.Custom))
+	for _, kind := range r.CustomKinds() {
+		custom = append(custom, fmt.Sprintf("%s:%.2f", kind, r.Custom[kind]))
+	}
+	return s + " " + strings.Join(custom, " ")
 }
 
 // Min Gets the minimum value for each resource type
@@ -279,6 +486,21 @@ func Min(r1, r2 *Resources) *Resources {
 		MEMORY: math.Min(r1.GetMem(), r2.GetMem()),
 		DISK:   math.Min(r1.GetDisk(), r2.GetDisk()),
 		GPU:    math.Min(r1.GetGPU(), r2.GetGPU()),
+		Custom: mergeCustom(r1.Custom, r2.Custom, math.Min),
+	}
+}
+
+// Multiply returns a new copy of the resources with the value of each
+// resource type multiplied by the factor
+func (r *Resources) Multiply(factor float64) *Resources {
+	return &Resources{
+		CPU:    r.CPU * factor,
+		MEMORY: r.MEMORY * factor,
+		DISK:   r.DISK * factor,
+		GPU:    r.GPU * factor,
+		Custom: mergeCustom(r.Custom, nil, func(v, _ float64) float64 {
+			return v * factor
+		}),
 	}
 }
 
@@ -336,6 +558,13 @@ func (r *Resources) Subtract(other *Resources) *Resources {
 			result.DISK = float64(0)
 		}
 	}
+
+	result.Custom = mergeCustom(r.Custom, other.Custom, func(v1, v2 float64) float64 {
+		if v1-v2 < util.ResourceEpsilon {
+			return float64(0)
+		}
+		return v1 - v2
+	})
 	return &result
 }
 
@@ -348,6 +577,7 @@ func (r *Resources) Clone() *Resources {
 		DISK:   r.DISK,
 		MEMORY: r.MEMORY,
 		GPU:    r.GPU,
+		Custom: copyCustom(r.Custom),
 	}
 }
 
@@ -358,4 +588,5 @@ func (r *Resources) Copy(other *Resources) {
 	r.DISK = other.DISK
 	r.MEMORY = other.MEMORY
 	r.GPU = other.GPU
+	r.Custom = copyCustom(other.Custom)
 }
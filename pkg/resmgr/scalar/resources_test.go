@@ -17,6 +17,7 @@ package scalar
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -35,10 +36,10 @@ func TestAdd(t *testing.T) {
 	}
 
 	result := empty.Add(&empty)
-	assertEqual(t, &Resources{0.0, 0.0, 0.0, 0.0}, result)
+	assertEqual(t, &Resources{CPU: 0.0, MEMORY: 0.0, DISK: 0.0, GPU: 0.0}, result)
 
 	result = r1.Add(&Resources{})
-	assertEqual(t, &Resources{1.0, 0.0, 0.0, 0.0}, result)
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 0.0, DISK: 0.0, GPU: 0.0}, result)
 
 	r2 := Resources{
 		CPU:    4.0,
@@ -47,15 +48,11 @@ func TestAdd(t *testing.T) {
 		GPU:    1.0,
 	}
 	result = r1.Add(&r2)
-	assertEqual(t, &Resources{5.0, 3.0, 2.0, 1.0}, result)
+	assertEqual(t, &Resources{CPU: 5.0, MEMORY: 3.0, DISK: 2.0, GPU: 1.0}, result)
 }
 
 func assertEqual(t *testing.T, expected *Resources, result *Resources) {
-	for _, typeRes := range []string{
-		common.CPU,
-		common.MEMORY,
-		common.DISK,
-		common.GPU} {
+	for _, typeRes := range append(expected.Kinds(), result.CustomKinds()...) {
 		assert.InDelta(t, expected.Get(typeRes), result.Get(typeRes), _zeroDelta)
 	}
 }
@@ -71,7 +68,7 @@ func TestSubtract(t *testing.T) {
 
 	res := r1.Subtract(&empty)
 	assert.NotNil(t, res)
-	assertEqual(t, &Resources{1.0, 2.0, 3.0, 4.0}, res)
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 2.0, DISK: 3.0, GPU: 4.0}, res)
 
 	r2 := Resources{
 		CPU:    2.0,
@@ -82,10 +79,10 @@ func TestSubtract(t *testing.T) {
 	res = r2.Subtract(&r1)
 
 	assert.NotNil(t, res)
-	assertEqual(t, &Resources{1.0, 3.0, 1.0, 3.0}, res)
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 3.0, DISK: 1.0, GPU: 3.0}, res)
 
 	res = r1.Subtract(&r2)
-	assertEqual(t, &Resources{0.0, 0.0, 0.0, 0.0}, res)
+	assertEqual(t, &Resources{CPU: 0.0, MEMORY: 0.0, DISK: 0.0, GPU: 0.0}, res)
 }
 
 func TestSubtractLessThanEpsilon(t *testing.T) {
@@ -103,7 +100,7 @@ func TestSubtractLessThanEpsilon(t *testing.T) {
 	}
 	res := r2.Subtract(&r1)
 	assert.NotNil(t, res)
-	assertEqual(t, &Resources{0.0, 0.0, 0.0, 0.0}, res)
+	assertEqual(t, &Resources{CPU: 0.0, MEMORY: 0.0, DISK: 0.0, GPU: 0.0}, res)
 }
 
 func TestLessThanOrEqual(t *testing.T) {
@@ -154,7 +151,7 @@ func TestConvertToResmgrResource(t *testing.T) {
 		MemLimitMb:  10.0,
 	}
 	res := ConvertToResmgrResource(taskConfig)
-	assertEqual(t, &Resources{4.0, 10.0, 5.0, 1.0}, res)
+	assertEqual(t, &Resources{CPU: 4.0, MEMORY: 10.0, DISK: 5.0, GPU: 1.0}, res)
 }
 
 func TestSet(t *testing.T) {
@@ -164,12 +161,12 @@ func TestSet(t *testing.T) {
 		DISK:   3.0,
 		GPU:    4.0,
 	}
-	assertEqual(t, &Resources{1.0, 2.0, 3.0, 4.0}, &r1)
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 2.0, DISK: 3.0, GPU: 4.0}, &r1)
 	r1.Set(common.CPU, float64(2.0))
 	r1.Set(common.MEMORY, float64(3.0))
 	r1.Set(common.DISK, float64(4.0))
 	r1.Set(common.GPU, float64(5.0))
-	assertEqual(t, &Resources{2.0, 3.0, 4.0, 5.0}, &r1)
+	assertEqual(t, &Resources{CPU: 2.0, MEMORY: 3.0, DISK: 4.0, GPU: 5.0}, &r1)
 }
 
 func TestClone(t *testing.T) {
@@ -200,6 +197,66 @@ func TestCopy(t *testing.T) {
 	assert.Equal(t, r1.Equal(&r2), true)
 }
 
+func TestCustomResources(t *testing.T) {
+	r1 := &Resources{
+		CPU:    1.0,
+		Custom: map[string]float64{"fpgas": 2.0},
+	}
+	r2 := &Resources{
+		CPU:    2.0,
+		Custom: map[string]float64{"fpgas": 1.0, "tpus": 4.0},
+	}
+
+	assert.Equal(t, 2.0, r1.Get("fpgas"))
+	assert.Equal(t, 0.0, r1.Get("tpus"))
+	assert.Equal(t, []string{"fpgas", "tpus"}, r2.CustomKinds())
+	assert.Equal(t,
+		[]string{common.CPU, common.GPU, common.MEMORY, common.DISK, "fpgas"},
+		r1.Kinds())
+	assert.Equal(t, "CPU:2.00 MEM:0.00 DISK:0.00 GPU:0.00 fpgas:1.00 tpus:4.00",
+		r2.String())
+
+	assertEqual(t, &Resources{
+		CPU:    3.0,
+		Custom: map[string]float64{"fpgas": 3.0, "tpus": 4.0},
+	}, r1.Add(r2))
+	assertEqual(t, &Resources{
+		Custom: map[string]float64{"fpgas": 1.0},
+	}, r1.Subtract(r2))
+	assertEqual(t, &Resources{
+		CPU:    1.0,
+		Custom: map[string]float64{"fpgas": 1.0},
+	}, Min(r1, r2))
+	assertEqual(t, &Resources{
+		CPU:    0.5,
+		Custom: map[string]float64{"fpgas": 1.0},
+	}, r1.Multiply(0.5))
+
+	// a custom resource missing in the other resources is zero
+	assert.False(t, r1.LessThanOrEqual(r2))
+	assert.False(t, r2.LessThanOrEqual(r1))
+	assert.True(t, (&Resources{CPU: 1.0}).LessThanOrEqual(r2))
+	assert.False(t, r1.Equal(&Resources{CPU: 1.0}))
+	assert.False(t, (&Resources{CPU: 1.0}).Equal(r1))
+	assert.True(t, r1.Equal(r1.Clone()))
+
+	// the custom resources of a clone or a copy are not shared
+	clone := r1.Clone()
+	clone.Set("fpgas", 5.0)
+	var copied Resources
+	copied.Copy(r1)
+	copied.Set("fpgas", 6.0)
+	assert.Equal(t, 2.0, r1.Get("fpgas"))
+	assert.Equal(t, 5.0, clone.Get("fpgas"))
+	assert.Equal(t, 6.0, copied.Get("fpgas"))
+
+	res := ConvertToResmgrResource(&task.ResourceConfig{
+		CpuLimit:     1.0,
+		CustomLimits: map[string]float64{"fpgas": 2.0},
+	})
+	assertEqual(t, r1, res)
+}
+
 func TestInitializeAllocation(t *testing.T) {
 	alloc := NewAllocation()
 	for _, v := range alloc.Value {
@@ -359,12 +416,12 @@ func TestGetTaskAllocation(t *testing.T) {
 
 		// total should always be equal to the taskConfig
 		res := alloc.GetByType(TotalAllocation)
-		assertEqual(t, &Resources{4.0, 10.0, 5.0, 1.0}, res)
+		assertEqual(t, &Resources{CPU: 4.0, MEMORY: 10.0, DISK: 5.0, GPU: 1.0}, res)
 
 		// these should be equal to the taskConfig
 		for _, allocType := range test.hasAlloc {
 			res := alloc.GetByType(allocType)
-			assertEqual(t, &Resources{4.0, 10.0, 5.0, 1.0}, res)
+			assertEqual(t, &Resources{CPU: 4.0, MEMORY: 10.0, DISK: 5.0, GPU: 1.0}, res)
 		}
 
 		// these should be equal to zero
@@ -390,7 +447,7 @@ func TestGetGangResources(t *testing.T) {
 			},
 		},
 	})
-	assertEqual(t, &Resources{1.0, 1.0, 1.0, 1.0}, res)
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 1.0, DISK: 1.0, GPU: 1.0}, res)
 	assert.Equal(t, "CPU:1.00 MEM:1.00 DISK:1.00 GPU:1.00", res.String())
 }
 
@@ -407,5 +464,53 @@ func TestGetGangAllocation(t *testing.T) {
 			},
 		},
 	})
-	assertEqual(t, &Resources{1.0, 1.0, 1.0, 1.0}, res.GetByType(TotalAllocation))
+	assertEqual(t, &Resources{CPU: 1.0, MEMORY: 1.0, DISK: 1.0, GPU: 1.0}, res.GetByType(TotalAllocation))
+}
+
+func TestOwnerAndJobUsage(t *testing.T) {
+	newTask := func(owner, jobID string) *resmgr.Task {
+		return &resmgr.Task{
+			Owner: owner,
+			JobId: &peloton.JobID{Value: jobID},
+			Resource: &task.ResourceConfig{
+				CpuLimit:    1,
+				DiskLimitMb: 1,
+				GpuLimit:    1,
+				MemLimitMb:  1,
+			},
+		}
+	}
+
+	gangAlloc := GetGangAllocation(&resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{
+			newTask("alice", "job1"),
+			newTask("alice", "job1"),
+		},
+	})
+	assert.Equal(t, 2, gangAlloc.Owners["alice"].Tasks)
+	assertEqual(t, &Resources{CPU: 2.0, MEMORY: 2.0, DISK: 2.0, GPU: 2.0},
+		gangAlloc.GetByType(TotalAllocation))
+
+	usages := NewUsages()
+	usages.Add(gangAlloc)
+	usages.Add(GetTaskAllocation(newTask("bob", "job2")))
+
+	assert.Equal(t, 2, usages.GetOwnerUsage("alice").Tasks)
+	assertEqual(t, &Resources{CPU: 2.0, MEMORY: 2.0, DISK: 2.0, GPU: 2.0},
+		usages.GetOwnerUsage("alice").Resources)
+	assert.Equal(t, 2, usages.GetJobUsage("job1").Tasks)
+	assert.Equal(t, 1, usages.GetOwnerUsage("bob").Tasks)
+	assert.Equal(t, 1, usages.GetJobUsage("job2").Tasks)
+
+	// the consumers without tasks are dropped
+	usages.Subtract(gangAlloc)
+	assert.Equal(t, 0, usages.GetOwnerUsage("alice").Tasks)
+	assert.Equal(t, ZeroResource, usages.GetOwnerUsage("alice").Resources)
+	assert.NotContains(t, usages.owners, "alice")
+	assert.NotContains(t, usages.jobs, "job1")
+	assert.Equal(t, 1, usages.GetOwnerUsage("bob").Tasks)
+
+	// allocations without usages are tolerated
+	usages.Add(&Allocation{Value: withTotalAlloc().Value})
+	assert.Equal(t, 1, usages.GetJobUsage("job2").Tasks)
 }
@@ -49,6 +49,7 @@ type Server struct {
 	drainer               ServerProcess
 	preemptor             ServerProcess
 	batchScorer           ServerProcess
+	queueSnapshotter      ServerProcess
 	// TODO move these to use ServerProcess
 	getTaskScheduler func() task.Scheduler
 
@@ -67,7 +68,8 @@ func NewServer(
 	reconciler ServerProcess,
 	preemptor ServerProcess,
 	drainer ServerProcess,
-	batchScorer ServerProcess) *Server {
+	batchScorer ServerProcess,
+	queueSnapshotter ServerProcess) *Server {
 	return &Server{
 		ID:                    leader.NewID(httpPort, grpcPort),
 		role:                  common.ResourceManagerRole,
@@ -79,6 +81,7 @@ func NewServer(
 		preemptor:             preemptor,
 		drainer:               drainer,
 		batchScorer:           batchScorer,
+		queueSnapshotter:      queueSnapshotter,
 		metrics:               NewMetrics(parent),
 	}
 }
@@ -158,6 +161,13 @@ func (s *Server) GainedLeadershipCallback() (err error) {
 			Error("Failed to start batch scorer")
 		return err
 	}
+
+	// Start the queue snapshotter
+	if err = s.queueSnapshotter.Start(); err != nil {
+		log.WithError(err).
+			Error("Failed to start queue snapshotter")
+		return err
+	}
 	return nil
 }
 
@@ -172,6 +182,13 @@ func (s *Server) LostLeadershipCallback() error {
 	// we set the node as anon-leader before we stop the services
 	s.isLeader = false
 
+	// The queue snapshotter is stopped first, so that no snapshot is
+	// written once another resource manager may be the leader.
+	if err := s.queueSnapshotter.Stop(); err != nil {
+		log.Errorf("Failed to stop queue snapshotter")
+		return err
+	}
+
 	if err := s.drainer.Stop(); err != nil {
 		log.Errorf("Failed to stop host drainer")
 		return err
@@ -155,6 +155,23 @@ func TestServer_GainedLeadershipCallback(t *testing.T) {
 				preemptor:             &FakeServerProcess{nil},
 				drainer:               &FakeServerProcess{nil},
 				batchScorer:           &FakeServerProcess{nil},
+				queueSnapshotter:      &FakeServerProcess{errFake},
+			},
+			wantErr: errFake,
+		},
+		{
+			s: &Server{
+				role:                  "testResMgr",
+				metrics:               NewMetrics(tally.NoopScope),
+				resTree:               &FakeServerProcess{nil},
+				recoveryHandler:       &FakeServerProcess{nil},
+				entitlementCalculator: &FakeServerProcess{nil},
+				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				reconciler:            &FakeServerProcess{nil},
+				preemptor:             &FakeServerProcess{nil},
+				drainer:               &FakeServerProcess{nil},
+				batchScorer:           &FakeServerProcess{nil},
+				queueSnapshotter:      &FakeServerProcess{nil},
 			},
 			wantErr: nil,
 		},
@@ -179,28 +196,28 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 	}{
 		{
 			s: &Server{
-				role:    "testResMgr",
-				metrics: NewMetrics(tally.NoopScope),
-				drainer: &FakeServerProcess{errFake},
+				role:             "testResMgr",
+				metrics:          NewMetrics(tally.NoopScope),
+				queueSnapshotter: &FakeServerProcess{errFake},
 			},
 			wantErr: errFake,
 		},
 		{
 			s: &Server{
-				role:      "testResMgr",
-				metrics:   NewMetrics(tally.NoopScope),
-				drainer:   &FakeServerProcess{nil},
-				preemptor: &FakeServerProcess{errFake},
+				role:             "testResMgr",
+				metrics:          NewMetrics(tally.NoopScope),
+				queueSnapshotter: &FakeServerProcess{nil},
+				drainer:          &FakeServerProcess{errFake},
 			},
 			wantErr: errFake,
 		},
 		{
 			s: &Server{
-				role:       "testResMgr",
-				metrics:    NewMetrics(tally.NoopScope),
-				drainer:    &FakeServerProcess{nil},
-				preemptor:  &FakeServerProcess{nil},
-				reconciler: &FakeServerProcess{errFake},
+				role:             "testResMgr",
+				metrics:          NewMetrics(tally.NoopScope),
+				queueSnapshotter: &FakeServerProcess{nil},
+				drainer:          &FakeServerProcess{nil},
+				preemptor:        &FakeServerProcess{errFake},
 			},
 			wantErr: errFake,
 		},
@@ -208,6 +225,18 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 			s: &Server{
 				role:             "testResMgr",
 				metrics:          NewMetrics(tally.NoopScope),
+				queueSnapshotter: &FakeServerProcess{nil},
+				drainer:          &FakeServerProcess{nil},
+				preemptor:        &FakeServerProcess{nil},
+				reconciler:       &FakeServerProcess{errFake},
+			},
+			wantErr: errFake,
+		},
+		{
+			s: &Server{
+				role:             "testResMgr",
+				metrics:          NewMetrics(tally.NoopScope),
+				queueSnapshotter: &FakeServerProcess{nil},
 				drainer:          &FakeServerProcess{nil},
 				preemptor:        &FakeServerProcess{nil},
 				reconciler:       &FakeServerProcess{nil},
@@ -223,6 +252,7 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				preemptor:             &FakeServerProcess{nil},
 				reconciler:            &FakeServerProcess{nil},
 				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				queueSnapshotter:      &FakeServerProcess{nil},
 				entitlementCalculator: &FakeServerProcess{errFake},
 			},
 			wantErr: errFake,
@@ -235,6 +265,7 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				preemptor:             &FakeServerProcess{nil},
 				reconciler:            &FakeServerProcess{nil},
 				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				queueSnapshotter:      &FakeServerProcess{nil},
 				entitlementCalculator: &FakeServerProcess{nil},
 				recoveryHandler:       &FakeServerProcess{errFake},
 			},
@@ -249,6 +280,7 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				reconciler:            &FakeServerProcess{nil},
 				entitlementCalculator: &FakeServerProcess{nil},
 				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				queueSnapshotter:      &FakeServerProcess{nil},
 				recoveryHandler:       &FakeServerProcess{nil},
 				resTree:               &FakeServerProcess{errFake},
 			},
@@ -263,6 +295,7 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				reconciler:            &FakeServerProcess{nil},
 				entitlementCalculator: &FakeServerProcess{nil},
 				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				queueSnapshotter:      &FakeServerProcess{nil},
 				recoveryHandler:       &FakeServerProcess{nil},
 				resTree:               &FakeServerProcess{nil},
 				batchScorer:           &FakeServerProcess{errFake},
@@ -278,6 +311,7 @@ func TestServer_LostLeadershipCallback(t *testing.T) {
 				reconciler:            &FakeServerProcess{nil},
 				entitlementCalculator: &FakeServerProcess{nil},
 				getTaskScheduler:      mockSchedulerWithErr(nil, t),
+				queueSnapshotter:      &FakeServerProcess{nil},
 				recoveryHandler:       &FakeServerProcess{nil},
 				resTree:               &FakeServerProcess{nil},
 				batchScorer:           &FakeServerProcess{nil},
@@ -308,6 +342,7 @@ func TestServer_GetID(t *testing.T) {
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
+		&FakeServerProcess{nil},
 	)
 
 	assert.NotNil(t, s)
@@ -333,6 +368,7 @@ func TestServer_ShutDownCallback(t *testing.T) {
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
 		&FakeServerProcess{nil},
+		&FakeServerProcess{nil},
 	)
 
 	assert.NoError(t, s.ShutDownCallback())
@@ -43,4 +43,10 @@ type Config struct {
 	EnableSLATracking bool `yaml:"enable_sla_tracking"`
 	// This flag will enable/disable host reservation of tasks
 	EnableHostReservation bool `yaml:"enable_host_reservation"`
+	// Default time a task can wait to be placed, counted from when it is
+	// enqueued, before it is requeued at an elevated priority. Overridden
+	// by the placement deadline of the task, and disabled if zero.
+	PlacementDeadline time.Duration `yaml:"placement_deadline"`
+	// Priority added to a task which missed its placement deadline
+	PlacementDeadlinePriorityBoost uint32 `yaml:"placement_deadline_priority_boost"`
 }
@@ -14,11 +14,22 @@
 
 package task
 
+import "time"
+
 const (
 	// maxReadyQueueSize is the max size of the task ready queue.
 	maxReadyQueueSize = 100 * 1000
 	// dequeueGangLimit is the max number of pending gangs to dequeue
 	dequeueGangLimit = 1000
+	// minDequeueGangLimit is the min number of pending gangs to dequeue
+	// from a resource pool when the scheduling cycle budget is enabled
+	minDequeueGangLimit = 10
+	// gangLatencyWeight is the weight of the moving average of the gang
+	// scheduling latency, the last cycle counts for 1/gangLatencyWeight
+	gangLatencyWeight = 4
+	// _placementDeadlineCheckPeriod is how often the gangs pending in the
+	// resource pools are checked for missed placement deadlines
+	_placementDeadlineCheckPeriod = 10 * time.Second
 	// ExponentialBackOffPolicy is Backoff Policy Name
 	ExponentialBackOffPolicy = "exponential-policy"
 )
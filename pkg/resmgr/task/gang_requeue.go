@@ -0,0 +1,118 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/respool"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// _gangRequeueWindow is the time for which the members of a gang which
+// time out in PLACING state are gathered, before they are requeued as
+// one gang. The members of a gang are placed together, hence time out
+// within the same window.
+const _gangRequeueWindow = 2 * time.Second
+
+// gangRequeueKey identifies the gang of a task which timed out in
+// PLACING state. A job has at most one gang of multiple tasks.
+type gangRequeueKey struct {
+	jobID string
+	// pending is true if the gang is requeued to the pending queue of
+	// its resource pool, false if to the ready queue
+	pending bool
+}
+
+// gangRequeuer requeues the members of a gang which time out in PLACING
+// state together, so that the gang is not split into gangs of single
+// tasks by the placement timeouts.
+type gangRequeuer struct {
+	sync.Mutex
+
+	window time.Duration
+	// gangs are the gangs being gathered
+	gangs map[gangRequeueKey]*resmgrsvc.Gang
+}
+
+var _gangRequeuer = newGangRequeuer(_gangRequeueWindow)
+
+// newGangRequeuer returns a new gang requeuer gathering the members of
+// the gangs for the given window.
+func newGangRequeuer(window time.Duration) *gangRequeuer {
+	return &gangRequeuer{
+		window: window,
+		gangs:  make(map[gangRequeueKey]*resmgrsvc.Gang),
+	}
+}
+
+// add adds a member of a gang which timed out in PLACING state to its
+// gang, which is requeued at the end of the window started by its first
+// member.
+// NB: Acquire lock on rm task before calling
+func (r *gangRequeuer) add(rmTask *RMTask, pending bool) {
+	key := gangRequeueKey{
+		jobID:   rmTask.task.GetJobId().GetValue(),
+		pending: pending,
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	gang, ok := r.gangs[key]
+	if !ok {
+		gang = &resmgrsvc.Gang{}
+		r.gangs[key] = gang
+		respool := rmTask.Respool()
+		time.AfterFunc(r.window, func() {
+			r.requeue(key, respool)
+		})
+	}
+	gang.Tasks = append(gang.Tasks, rmTask.task)
+}
+
+// requeue requeues a gang gathered by the requeuer.
+func (r *gangRequeuer) requeue(key gangRequeueKey, respool respool.ResPool) {
+	r.Lock()
+	gang := r.gangs[key]
+	delete(r.gangs, key)
+	r.Unlock()
+
+	var err error
+	if key.pending {
+		err = readmitGang(respool, gang)
+	} else {
+		err = GetScheduler().EnqueueGang(gang)
+	}
+	if err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"job_id":    key.jobID,
+				"num_tasks": len(gang.GetTasks()),
+				"pending":   key.pending,
+			}).Error("failed to requeue gang timed out in placement")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"job_id":    key.jobID,
+		"num_tasks": len(gang.GetTasks()),
+		"pending":   key.pending,
+	}).Info("Gang timed out in placement is requeued")
+}
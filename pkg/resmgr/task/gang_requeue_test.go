@@ -0,0 +1,81 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGangRequeuerPending tests that the members of a gang which time out
+// in PLACING state are readmitted together as one gang
+func TestGangRequeuerPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := mocks.NewMockResPool(ctrl)
+	r := newGangRequeuer(10 * time.Millisecond)
+
+	var rmTasks []*RMTask
+	for _, jobID := range []string{"job1", "job1", "job2"} {
+		rmTasks = append(rmTasks, &RMTask{
+			task: &resmgr.Task{
+				JobId:        &peloton.JobID{Value: jobID},
+				MinInstances: 2,
+			},
+			respool: pool,
+		})
+	}
+
+	requeued := make(chan *resmgrsvc.Gang, 2)
+	pool.EXPECT().
+		EnqueueGang(gomock.Any()).
+		Do(func(gang *resmgrsvc.Gang) { requeued <- gang }).
+		Return(nil).
+		Times(2)
+	pool.EXPECT().
+		SubtractFromAllocation(gomock.Any()).
+		Return(nil).
+		Times(2)
+
+	for _, rmTask := range rmTasks {
+		r.add(rmTask, true)
+	}
+
+	gangSizes := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		select {
+		case gang := <-requeued:
+			gangSizes[gang.GetTasks()[0].GetJobId().GetValue()] =
+				len(gang.GetTasks())
+		case <-time.After(time.Second):
+			assert.Fail(t, "gang not requeued")
+		}
+	}
+	assert.Equal(t, map[string]int{"job1": 2, "job2": 1}, gangSizes)
+
+	r.Lock()
+	defer r.Unlock()
+	assert.Empty(t, r.gangs)
+}
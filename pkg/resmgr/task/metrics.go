@@ -37,6 +37,12 @@ type Metrics struct {
 	ReconciliationFail    tally.Counter
 
 	OrphanTasks tally.Gauge
+
+	SchedulingCycleTime tally.Timer
+	DequeueBatchSize    tally.Gauge
+	DeferredResPools    tally.Counter
+
+	PendingDeadlineEscalations tally.Counter
 }
 
 // NewMetrics returns a new instance of task.Metrics.
@@ -44,6 +50,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	readyScope := scope.SubScope("ready")
 	trackerScope := scope.SubScope("tracker")
 	taskStateScope := scope.SubScope("tasks_state")
+	schedulingScope := scope.SubScope("scheduling")
 
 	reconcilerScope := scope.SubScope("reconciler")
 	leakScope := reconcilerScope.SubScope("leaks")
@@ -112,5 +119,12 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ReconciliationSuccess: successScope.Counter("run"),
 		ReconciliationFail:    failScope.Counter("run"),
 		OrphanTasks:           scope.Gauge("orphan_tasks"),
+
+		SchedulingCycleTime: schedulingScope.Timer("cycle_time"),
+		DequeueBatchSize:    schedulingScope.Gauge("dequeue_batch_size"),
+		DeferredResPools:    schedulingScope.Counter("deferred_respools"),
+
+		PendingDeadlineEscalations: schedulingScope.Counter(
+			"pending_deadline_escalations"),
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -43,6 +44,10 @@ var (
 var (
 	reasonPlacementFailed = "Reached placement failure backoff threshold"
 	reasonPlacementRetry  = "Previous placement failed"
+	reasonPlacementRelax  = "Relaxed placement preferences"
+	// ReasonPlacementDeadline is the reason of a task which was requeued
+	// because it missed its placement deadline
+	ReasonPlacementDeadline = "Placement deadline exceeded, insufficient capacity"
 )
 
 // RunTimeStats is the container for run time stats of the resmgr task
@@ -60,6 +65,19 @@ type RMTaskState struct {
 	LastUpdateTime time.Time
 }
 
+// PlacementTrace is the placement history of a task, used to explain why
+// the task is not placed yet
+type PlacementTrace struct {
+	// Number of placements of the task which failed
+	Failures int
+	// Reason of the last failed placement, empty if none failed
+	LastFailure string
+	// Number of times the task timed out waiting to be placed
+	Timeouts int
+	// True if the task missed its placement deadline
+	MissedDeadline bool
+}
+
 // RMTask is the wrapper around resmgr.task for state machine
 type RMTask struct {
 	mu sync.Mutex // Mutex for synchronization
@@ -75,6 +93,25 @@ type RMTask struct {
 
 	runTimeStats *RunTimeStats // run time stats for resmgr task
 
+	// time at which the task was first made ready for placement, used to
+	// relax its soft placement preferences
+	placementStartTime time.Time
+
+	// time at which the task was enqueued, used for its placement deadline
+	enqueueTime time.Time
+	// true once the task has been escalated for missing its placement
+	// deadline
+	deadlineEscalated bool
+	// reason of the task pending admission, reported in place of the
+	// reason of its state until its next transition
+	pendingReason string
+
+	// number of failed placements and the reason of the last one
+	placementFailures    int
+	lastPlacementFailure string
+	// number of timeouts of the task in PLACING state
+	placementTimeouts int
+
 	// observes the state transitions of the rm task
 	transitionObserver TransitionObserver
 }
@@ -95,6 +132,7 @@ func CreateRMTask(
 		runTimeStats: &RunTimeStats{
 			StartTime: time.Time{},
 		},
+		enqueueTime: time.Now().UTC(),
 		transitionObserver: NewTransitionObserver(
 			taskConfig.EnableSLATracking,
 			scope,
@@ -386,15 +424,31 @@ func (rmTask *RMTask) GetCurrentState() RMTaskState {
 }
 
 func (rmTask *RMTask) getCurrentState() RMTaskState {
+	reason := rmTask.stateMachine.GetReason()
+	if rmTask.pendingReason != "" {
+		reason = rmTask.pendingReason
+	}
 	return RMTaskState{
 		State: task.TaskState(
 			task.TaskState_value[string(
 				rmTask.stateMachine.GetCurrentState())]),
-		Reason:         rmTask.stateMachine.GetReason(),
+		Reason:         reason,
 		LastUpdateTime: rmTask.stateMachine.GetLastUpdateTime(),
 	}
 }
 
+// GetPlacementTrace returns the placement history of the task
+func (rmTask *RMTask) GetPlacementTrace() PlacementTrace {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+	return PlacementTrace{
+		Failures:       rmTask.placementFailures,
+		LastFailure:    rmTask.lastPlacementFailure,
+		Timeouts:       rmTask.placementTimeouts,
+		MissedDeadline: rmTask.deadlineEscalated,
+	}
+}
+
 // Respool returns the respool of the RMTask.
 func (rmTask *RMTask) Respool() respool.ResPool {
 	return rmTask.respool
@@ -467,8 +521,25 @@ func (rmTask *RMTask) RequeueUnPlaced(reason string) error {
 		return errUnplacedTaskInWrongState
 	}
 
+	rmTask.placementFailures++
+	rmTask.lastPlacementFailure = reason
+
+	// Relax the soft placement preferences whose wait threshold has
+	// been reached before the task is retried
+	if relaxed := rmTask.relaxPlacementPreferences(time.Now().UTC()); len(relaxed) > 0 {
+		reason = strings.Join(
+			[]string{
+				reason, reasonPlacementRelax, strings.Join(relaxed, ","),
+			}, ":")
+	}
+
 	// If task is in PLACING state we need to determine which STATE it will
-	// transition to based on retry attempts
+	// transition to based on the placement deadline and retry attempts
+
+	if rmTask.hasMissedPlacementDeadline(time.Now().UTC()) {
+		// requeue to pending queue at an elevated priority
+		return rmTask.requeueForMissedDeadline(reason)
+	}
 
 	if rmTask.hasFinishedPlacementCycle() {
 		// If this task is been failed enough times
@@ -529,6 +600,146 @@ func (rmTask *RMTask) requeueToPendingQueue(reason string) error {
 	return nil
 }
 
+// requeues a placing task which missed its placement deadline to pending
+// queue at an elevated priority
+// NB: Acquire lock on rm task before calling
+func (rmTask *RMTask) requeueForMissedDeadline(reason string) error {
+	rmTask.escalatePriority()
+
+	// Transitioning task state to PENDING with the reason
+	if err := rmTask.TransitTo(
+		task.TaskState_PENDING.String(),
+		state.WithReason(strings.Join(
+			[]string{
+				ReasonPlacementDeadline, reason,
+			}, ":"))); err != nil {
+		return err
+	}
+
+	if rmTask.task.GetMinInstances() > 1 {
+		// the members of a gang of multiple tasks are escalated and
+		// readmitted together
+		_gangRequeuer.add(rmTask, true)
+		return nil
+	}
+
+	// Pushing task to PENDING queue
+	if err := rmTask.pushTaskForReadmission(); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"task_id":    rmTask.Task().Id.Value,
+		"from_state": task.TaskState_PLACING.String(),
+		"to_state":   task.TaskState_PENDING.String(),
+		"priority":   rmTask.Task().GetPriority(),
+	}).Info("Task missed its placement deadline and is pushed back to " +
+		"pending queue")
+	return nil
+}
+
+// hasMissedPlacementDeadline returns true if the task has been waiting to be
+// placed for longer than its placement deadline, and hasn't been escalated
+// for it yet
+// NB: Acquire lock on rm task before calling
+func (rmTask *RMTask) hasMissedPlacementDeadline(now time.Time) bool {
+	if rmTask.deadlineEscalated {
+		return false
+	}
+
+	deadline := rmTask.config.PlacementDeadline
+	if secs := rmTask.task.GetPlacementDeadline(); secs > 0 {
+		deadline = time.Duration(secs) * time.Second
+	}
+	if deadline == 0 {
+		return false
+	}
+	return now.Sub(rmTask.enqueueTime) > deadline
+}
+
+// markPendingDeadlineMissed records that a task pending admission missed
+// its placement deadline, after its gang was escalated in the pending
+// queue of its resource pool. The priority of the task is updated along
+// with the gang.
+func (rmTask *RMTask) markPendingDeadlineMissed() {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+
+	rmTask.deadlineEscalated = true
+	rmTask.pendingReason = ReasonPlacementDeadline
+}
+
+// HasMissedPlacementDeadline returns true if the task has been waiting to
+// be placed for longer than its placement deadline, and hasn't been
+// escalated for it yet
+func (rmTask *RMTask) HasMissedPlacementDeadline(now time.Time) bool {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+	return rmTask.hasMissedPlacementDeadline(now)
+}
+
+// escalatePriority elevates the priority of a task which missed its
+// placement deadline, so that it's admitted ahead of the tasks of its
+// former priority
+// NB: Acquire lock on rm task before calling
+func (rmTask *RMTask) escalatePriority() {
+	rmTask.deadlineEscalated = true
+	rmTask.task.Priority += rmTask.config.PlacementDeadlinePriorityBoost
+}
+
+// relaxPlacementPreferences relaxes the soft placement preferences of the
+// task whose wait threshold in the placement relaxation policy has been
+// reached, and records each relaxation in the task. It returns the names
+// of the relaxed preferences.
+// NB: Acquire lock on rm task before calling
+func (rmTask *RMTask) relaxPlacementPreferences(now time.Time) []string {
+	steps := rmTask.task.GetPlacementRelaxationPolicy().GetSteps()
+	if len(steps) == 0 || rmTask.placementStartTime.IsZero() {
+		return nil
+	}
+
+	relaxedPrefs := make(map[job.PlacementPreference]bool)
+	for _, r := range rmTask.task.GetPlacementRelaxations() {
+		relaxedPrefs[r.GetPreference()] = true
+	}
+
+	var relaxed []string
+	waited := now.Sub(rmTask.placementStartTime)
+	for _, step := range steps {
+		if relaxedPrefs[step.GetPreference()] ||
+			waited < time.Duration(step.GetWaitSecs())*time.Second {
+			continue
+		}
+
+		switch step.GetPreference() {
+		case job.PlacementPreference_PLACEMENT_PREFERENCE_DESIRED_HOST:
+			rmTask.task.DesiredHost = ""
+		case job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY:
+			rmTask.task.PlacementStrategy =
+				job.PlacementStrategy_PLACEMENT_STRATEGY_INVALID
+		default:
+			continue
+		}
+
+		relaxedPrefs[step.GetPreference()] = true
+		rmTask.task.PlacementRelaxations = append(
+			rmTask.task.PlacementRelaxations,
+			&resmgr.PlacementRelaxation{
+				Preference: step.GetPreference(),
+				WaitSecs:   step.GetWaitSecs(),
+				RelaxedAt:  now.Format(time.RFC3339),
+			})
+		relaxed = append(relaxed, step.GetPreference().String())
+
+		log.WithFields(log.Fields{
+			"task_id":    rmTask.Task().GetId().GetValue(),
+			"preference": step.GetPreference().String(),
+			"wait_secs":  step.GetWaitSecs(),
+			"waited":     waited.String(),
+		}).Info("Relaxed soft placement preference of task")
+	}
+	return relaxed
+}
+
 // hasFinishedPlacementCycle returns true if one placement cycle is completed
 // otherwise false
 // NB: Acquire lock before calling
@@ -560,14 +771,19 @@ func (rmTask *RMTask) pushTaskForReadmission() error {
 	gang := &resmgrsvc.Gang{
 		Tasks: append(tasks, rmTask.task),
 	}
+	return readmitGang(rmTask.Respool(), gang)
+}
 
+// readmitGang pushes an admitted gang back to the pending queue of its
+// resource pool for readmission
+func readmitGang(respool respool.ResPool, gang *resmgrsvc.Gang) error {
 	// push to pending queue and add demand
-	if err := rmTask.Respool().EnqueueGang(gang); err != nil {
+	if err := respool.EnqueueGang(gang); err != nil {
 		return errors.Wrapf(err, "failed to enqueue gang")
 	}
 
 	// remove allocation
-	if err := rmTask.Respool().SubtractFromAllocation(
+	if err := respool.SubtractFromAllocation(
 		scalar.GetGangAllocation(gang)); err != nil {
 		return errors.Wrapf(err, "failed to remove allocation from respool")
 	}
@@ -602,12 +818,22 @@ func (rmTask *RMTask) transitionCallBack(t *state.Transition) error {
 
 	tState := task.TaskState(task.TaskState_value[string(t.To)])
 
+	// the reason of the new state is reported from now on
+	rmTask.pendingReason = ""
+
 	rmTask.transitionObserver.Observe(
 		rmTask.Task().GetTaskId().GetValue(),
 		tState)
 
-	// we only care about running state here
-	if tState == task.TaskState_RUNNING {
+	switch tState {
+	case task.TaskState_READY:
+		// remember when the task first became ready for placement
+		if rmTask.placementStartTime.IsZero() {
+			rmTask.placementStartTime = time.Now().UTC()
+		}
+	case task.TaskState_PLACED:
+		rmTask.placementStartTime = time.Time{}
+	case task.TaskState_RUNNING:
 		// update the start time
 		rmTask.UpdateStartTime(time.Now().UTC())
 	}
@@ -621,6 +847,22 @@ func (rmTask *RMTask) timeoutCallbackFromPlacing(t *state.Transition) error {
 		return errTaskNotPresent
 	}
 
+	// the members of a gang of multiple tasks are requeued together, so
+	// that the gang is placed as a whole again
+	if rmTask.task.GetMinInstances() > 1 {
+		pending := t.To == state.State(task.TaskState_PENDING.String())
+		if !pending {
+			rmTask.task.Hostname = ""
+		}
+		log.WithFields(log.Fields{
+			"task_id":    rmTask.Task().GetTaskId().GetValue(),
+			"from_state": t.From,
+			"to_state":   t.To,
+		}).Info("Gang member is pushed back to be requeued with its gang")
+		_gangRequeuer.add(rmTask, pending)
+		return nil
+	}
+
 	if t.To == state.State(task.TaskState_PENDING.String()) {
 		log.WithFields(log.Fields{
 			"task_id":    rmTask.Task().GetTaskId().Value,
@@ -715,12 +957,20 @@ func (rmTask *RMTask) preTimeoutCallback(t *state.Transition) error {
 		return errTaskNotPresent
 	}
 
+	rmTask.placementTimeouts++
+
 	if rmTask.config.EnableHostReservation && rmTask.hasFinishedAllPlacementCycles() {
 		rmTask.task.ReadyForHostReservation = true
 		t.To = state.State(task.TaskState_READY.String())
 		return nil
 	}
 
+	if rmTask.hasMissedPlacementDeadline(time.Now().UTC()) {
+		rmTask.escalatePriority()
+		t.To = state.State(task.TaskState_PENDING.String())
+		return nil
+	}
+
 	if rmTask.hasFinishedPlacementCycle() {
 		t.To = state.State(task.TaskState_PENDING.String())
 		return nil
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	resp "github.com/uber/peloton/.gen/peloton/api/v0/respool"
@@ -75,6 +76,7 @@ func (s *RMTaskTestSuite) SetupSuite() {
 		tally.NoopScope,
 		s.resTree,
 		1*time.Second,
+		0,
 		s.tracker)
 	s.taskScheduler = GetScheduler()
 }
@@ -1115,3 +1117,145 @@ func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedTaskInPlacingToPending() {
 		mockStateMachine)
 	s.NoError(err, "placing to pending requeue should not fail")
 }
+
+// TestRMTaskRelaxPlacementPreferences tests that the soft placement
+// preferences of a task are relaxed progressively as its wait thresholds
+// are reached, and that each relaxation is recorded in the task.
+func (s *RMTaskTestSuite) TestRMTaskRelaxPlacementPreferences() {
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").Times(1)
+
+	t := s.createTask(1)
+	t.DesiredHost = "host1"
+	t.PlacementStrategy = job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB
+	t.PlacementRelaxationPolicy = &job.PlacementRelaxationPolicy{
+		Steps: []*job.PlacementRelaxationStep{
+			{
+				WaitSecs:   60,
+				Preference: job.PlacementPreference_PLACEMENT_PREFERENCE_DESIRED_HOST,
+			},
+			{
+				WaitSecs:   300,
+				Preference: job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+			},
+		},
+	}
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		t,
+		nil,
+		mockNode,
+		&Config{
+			PolicyName: ExponentialBackOffPolicy,
+		},
+	)
+	s.NoError(err)
+
+	// nothing is relaxed before the task is ready for placement
+	s.Empty(rmTask.relaxPlacementPreferences(time.Now()))
+
+	s.NoError(rmTask.transitionCallBack(&statemachine.Transition{
+		To: statemachine.State(task.TaskState_READY.String()),
+	}))
+	start := rmTask.placementStartTime
+	s.False(start.IsZero())
+
+	// wait threshold not reached
+	s.Empty(rmTask.relaxPlacementPreferences(start.Add(30 * time.Second)))
+	s.Equal("host1", t.GetDesiredHost())
+
+	// desired host is relaxed
+	s.Equal(
+		[]string{job.PlacementPreference_PLACEMENT_PREFERENCE_DESIRED_HOST.String()},
+		rmTask.relaxPlacementPreferences(start.Add(90*time.Second)))
+	s.Empty(t.GetDesiredHost())
+	s.Equal(
+		job.PlacementStrategy_PLACEMENT_STRATEGY_SPREAD_JOB,
+		t.GetPlacementStrategy())
+	s.Len(t.GetPlacementRelaxations(), 1)
+
+	// a relaxed preference is not relaxed again
+	s.Empty(rmTask.relaxPlacementPreferences(start.Add(120 * time.Second)))
+
+	// placement strategy is relaxed
+	s.Equal(
+		[]string{job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY.String()},
+		rmTask.relaxPlacementPreferences(start.Add(600*time.Second)))
+	s.Equal(
+		job.PlacementStrategy_PLACEMENT_STRATEGY_INVALID,
+		t.GetPlacementStrategy())
+	s.Len(t.GetPlacementRelaxations(), 2)
+	s.Equal(
+		job.PlacementPreference_PLACEMENT_PREFERENCE_STRATEGY,
+		t.GetPlacementRelaxations()[1].GetPreference())
+	s.Equal(uint32(300), t.GetPlacementRelaxations()[1].GetWaitSecs())
+	s.Equal(
+		start.Add(600*time.Second).Format(time.RFC3339),
+		t.GetPlacementRelaxations()[1].GetRelaxedAt())
+
+	// placement wait is reset once the task is placed
+	s.NoError(rmTask.transitionCallBack(&statemachine.Transition{
+		To: statemachine.State(task.TaskState_PLACED.String()),
+	}))
+	s.True(rmTask.placementStartTime.IsZero())
+}
+
+// TestRMTaskRequeueUnPlacedMissedDeadline tests that a task which missed its
+// placement deadline is requeued once to the pending queue at an elevated
+// priority.
+func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedMissedDeadline() {
+	mockNode := mocks.NewMockResPool(s.ctrl)
+	mockNode.EXPECT().GetPath().Return("/mocknode").Times(1)
+	mockStateMachine := sm_mock.NewMockStateMachine(s.ctrl)
+
+	t := s.createTask(1)
+	t.Priority = 1
+	t.PlacementDeadline = 60
+
+	rmTask, err := CreateRMTask(
+		tally.NoopScope,
+		t,
+		nil,
+		mockNode,
+		&Config{
+			PlacingTimeout:                 2 * time.Second,
+			PolicyName:                     ExponentialBackOffPolicy,
+			PlacementDeadline:              time.Hour,
+			PlacementDeadlinePriorityBoost: 2,
+		},
+	)
+	s.NoError(err)
+	rmTask.stateMachine = mockStateMachine
+
+	// the deadline of the task overrides the default one
+	s.False(rmTask.hasMissedPlacementDeadline(
+		rmTask.enqueueTime.Add(30 * time.Second)))
+	s.True(rmTask.hasMissedPlacementDeadline(
+		rmTask.enqueueTime.Add(90 * time.Second)))
+	rmTask.enqueueTime = rmTask.enqueueTime.Add(-90 * time.Second)
+
+	mockStateMachine.
+		EXPECT().GetCurrentState().
+		Return(statemachine.State(task.TaskState_PLACING.String())).AnyTimes()
+	mockStateMachine.
+		EXPECT().TransitTo(
+		statemachine.State(task.TaskState_PENDING.String()),
+		gomock.Any(),
+	).Return(nil)
+	mockNode.EXPECT().
+		EnqueueGang(gomock.Any()).Return(nil)
+	mockNode.EXPECT().
+		SubtractFromAllocation(gomock.Any()).Return(nil)
+
+	s.NoError(rmTask.RequeueUnPlaced(""))
+	s.Equal(uint32(3), t.GetPriority())
+
+	// the task is escalated only once
+	s.False(rmTask.hasMissedPlacementDeadline(time.Now().UTC()))
+
+	// the failed placement is recorded in the placement trace
+	s.Equal(
+		PlacementTrace{Failures: 1, MissedDeadline: true},
+		rmTask.GetPlacementTrace())
+}
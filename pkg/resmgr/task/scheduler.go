@@ -16,6 +16,7 @@ package task
 
 import (
 	"context"
+	"math"
 	"math/rand"
 	"sync"
 	"sync/atomic"
@@ -57,6 +58,9 @@ type Scheduler interface {
 	DequeueGang(maxWaitTime time.Duration, taskType resmgr.TaskType) (*resmgrsvc.Gang, error)
 	// Adds an invalid task so that it can be removed from the ready queue later.
 	AddInvalidTask(task *peloton.TaskID)
+	// Returns the gangs in the ready queue in dequeue order of each level,
+	// without removing them.
+	PeekGangs() []*resmgrsvc.Gang
 }
 
 // scheduler implements the TaskScheduler interface
@@ -78,6 +82,25 @@ type scheduler struct {
 	metrics          *Metrics
 	random           *rand.Rand
 
+	// cycleBudget is the target duration of a scheduling cycle. The number
+	// of gangs dequeued from each resource pool adapts to the recent
+	// scheduling latency to keep cycles under it. Zero disables it.
+	cycleBudget time.Duration
+	// batchSize is the number of gangs dequeued from each resource pool
+	// in the next scheduling cycle.
+	batchSize int
+	// gangLatency is the moving average of the time taken to schedule
+	// a gang.
+	gangLatency time.Duration
+	// nextResPool is the index of the resource pool the next scheduling
+	// cycle starts from, so that pools deferred by the cycle budget are
+	// scheduled first in the next cycle.
+	nextResPool int
+
+	// lastDeadlineCheck is the time the gangs pending in the resource
+	// pools were last checked for missed placement deadlines.
+	lastDeadlineCheck time.Time
+
 	stopChan chan struct{}
 }
 
@@ -88,6 +111,7 @@ func InitScheduler(
 	parent tally.Scope,
 	tree respool.Tree,
 	taskSchedulingPeriod time.Duration,
+	schedulingCycleBudget time.Duration,
 	rmTaskTracker Tracker) {
 
 	if sched != nil {
@@ -101,6 +125,7 @@ func InitScheduler(
 		rmTaskTracker:    rmTaskTracker,
 		resPoolTree:      tree,
 		schedulingPeriod: taskSchedulingPeriod,
+		cycleBudget:      schedulingCycleBudget,
 		metrics:          NewMetrics(parent.SubScope("task_scheduler")),
 		random:           rand.New(rand.NewSource(time.Now().UnixNano())),
 		stopChan:         make(chan struct{}, 1),
@@ -159,76 +184,209 @@ func (s *scheduler) Start() error {
 
 // scheduleTasks moves gang tasks to ready queue in every scheduling cycle
 func (s *scheduler) scheduleTasks() {
+	start := time.Now()
+
 	// We need to iterate for all the leaf nodes in the list
 	nodes := s.resPoolTree.GetAllNodes(true)
+	resPools := make([]respool.ResPool, 0, nodes.Len())
 	for e := nodes.Front(); e != nil; e = e.Next() {
-		n := e.Value.(respool.ResPool)
-		// DequeueGangs checks the entitlement for the
-		// resource pool and takes the decision if we can
-		// dequeue gang or not based on resource availability.
-		gangList, err := n.DequeueGangs(dequeueGangLimit)
-		if err != nil {
+		resPools = append(resPools, e.Value.(respool.ResPool))
+	}
+
+	if start.Sub(s.lastDeadlineCheck) >= _placementDeadlineCheckPeriod {
+		s.lastDeadlineCheck = start
+		for _, n := range resPools {
+			s.escalatePendingGangs(n, start)
+		}
+	}
+
+	limit := s.dequeueLimit()
+	numGangs := 0
+	for i := range resPools {
+		if i > 0 && s.cycleBudget > 0 && time.Since(start) > s.cycleBudget {
+			// Defer the remaining resource pools to the next cycle
+			// rather than stalling the ones already scheduled.
+			s.metrics.DeferredResPools.Inc(int64(len(resPools) - i))
+			s.nextResPool = (s.nextResPool + i) % len(resPools)
+			break
+		}
+		n := resPools[(s.nextResPool+i)%len(resPools)]
+		numGangs += s.scheduleResPool(n, limit)
+	}
+
+	s.adaptDequeueLimit(time.Since(start), numGangs, len(resPools))
+}
+
+// escalatePendingGangs escalates the gangs pending admission in a resource
+// pool which missed their placement deadline, for example because the pool
+// is over its entitlement. The whole gang is moved to the escalated
+// priority in the pending queue, and its tasks report the missed deadline
+// as their reason. It returns the number of escalated gangs.
+func (s *scheduler) escalatePendingGangs(n respool.ResPool, now time.Time) int {
+	gangs, err := n.PeekGangs(respool.PendingQueue, math.MaxUint32)
+	if err != nil {
+		// the pending queue is empty
+		return 0
+	}
+
+	escalated := 0
+	for _, gang := range gangs {
+		var rmTasks []*RMTask
+		missed := false
+		for _, t := range gang.GetTasks() {
+			rmTask := s.rmTaskTracker.GetTask(t.GetId())
+			if rmTask == nil {
+				continue
+			}
+			rmTasks = append(rmTasks, rmTask)
+			if rmTask.HasMissedPlacementDeadline(now) {
+				missed = true
+			}
+		}
+		if !missed {
+			continue
+		}
+
+		priority := gang.GetTasks()[0].GetPriority() +
+			rmTasks[0].config.PlacementDeadlinePriorityBoost
+		if err := n.ReprioritizeGang(gang, priority); err != nil {
+			// the gang may have been admitted in the meantime
 			log.WithError(err).
 				WithField("respool_id", n.ID()).
-				Error("Failed to dequeue from resource pool")
+				Debug("failed to escalate pending gang")
 			continue
 		}
-		var invalidGangs []*resmgrsvc.Gang
-		for _, gang := range gangList {
-			invalidTasks, err := s.transitGang(
-				gang,
-				pt.TaskState_PENDING,
-				pt.TaskState_READY,
-				"gang admitted")
-			if err != nil {
-				newGang := s.processGangFailure(n, gang, invalidTasks)
-				if newGang != nil {
-					// All the tasks are not deleted from the gang,
-					// Adding the new gang to invalid gang by that
-					// it can be requeued again
-					invalidGangs = append(invalidGangs, newGang)
-				}
-				continue
-			}
+		for _, rmTask := range rmTasks {
+			rmTask.markPendingDeadlineMissed()
+		}
+		escalated++
+
+		log.WithFields(log.Fields{
+			"respool_id": n.ID(),
+			"task_id":    gang.GetTasks()[0].GetId().GetValue(),
+			"num_tasks":  len(gang.GetTasks()),
+			"priority":   priority,
+		}).Info("Pending gang missed its placement deadline and is escalated")
+	}
+	s.metrics.PendingDeadlineEscalations.Inc(int64(escalated))
+	return escalated
+}
 
-			// Once the transition is done to ready state for all
-			// the tasks in the gang , we are enqueuing the gang to
-			// ready queue.
-			if err = s.EnqueueGang(gang); err != nil {
-				invalidGangs = append(invalidGangs, gang)
-				continue
-			}
+// dequeueLimit returns the max number of gangs to dequeue from each
+// resource pool in a scheduling cycle.
+func (s *scheduler) dequeueLimit() int {
+	if s.cycleBudget == 0 || s.batchSize == 0 {
+		return dequeueGangLimit
+	}
+	return s.batchSize
+}
+
+// adaptDequeueLimit updates the number of gangs dequeued from each resource
+// pool based on the latency of the last scheduling cycle, so that the next
+// cycle fits in the cycle budget.
+func (s *scheduler) adaptDequeueLimit(
+	elapsed time.Duration,
+	numGangs int,
+	numResPools int) {
+	s.metrics.SchedulingCycleTime.Record(elapsed)
+	if s.cycleBudget == 0 {
+		return
+	}
+
+	if numGangs > 0 {
+		latency := elapsed / time.Duration(numGangs)
+		if s.gangLatency == 0 {
+			s.gangLatency = latency
+		} else {
+			s.gangLatency = (s.gangLatency*(gangLatencyWeight-1) + latency) /
+				gangLatencyWeight
 		}
+	}
 
-		// For invalid gangs, which are unable to be admitted to ready queue
-		// at scheduling phase for placement
-		//
-		// 1. remove the allocation for invalid gangs
-		// 2. transit those gangs from ready -> pending state
-		// 3. enqueue those invalid gangs back to pending queue at respool
-		for _, invalidGang := range invalidGangs {
-			if err = n.SubtractFromAllocation(
-				scalar.GetGangAllocation(invalidGang)); err != nil {
-				log.WithField("gang", invalidGang).
-					WithError(err).
-					Error("unable to remove allocation for invalid gang from respool at scheduler")
-			}
+	if s.gangLatency == 0 || numResPools == 0 {
+		return
+	}
 
-			if _, err := s.transitGang(
-				invalidGang,
-				pt.TaskState_READY,
-				pt.TaskState_PENDING,
-				"gang admission failure"); err != nil {
-				log.WithError(err).
-					Error("not able to transit from READY -> PENDING")
-			}
+	limit := int(s.cycleBudget / (s.gangLatency * time.Duration(numResPools)))
+	if limit < minDequeueGangLimit {
+		limit = minDequeueGangLimit
+	}
+	if limit > dequeueGangLimit {
+		limit = dequeueGangLimit
+	}
+	s.batchSize = limit
+	s.metrics.DequeueBatchSize.Update(float64(limit))
+}
 
-			if err = n.EnqueueGang(invalidGang); err != nil {
-				log.WithError(err).
-					Error("not able to enqueue gang back to pending queue at resource pool")
+// scheduleResPool moves up to limit gangs of a resource pool to the
+// ready queue and returns the number of gangs dequeued.
+func (s *scheduler) scheduleResPool(n respool.ResPool, limit int) int {
+	// DequeueGangs checks the entitlement for the
+	// resource pool and takes the decision if we can
+	// dequeue gang or not based on resource availability.
+	gangList, err := n.DequeueGangs(limit)
+	if err != nil {
+		log.WithError(err).
+			WithField("respool_id", n.ID()).
+			Error("Failed to dequeue from resource pool")
+		return 0
+	}
+	var invalidGangs []*resmgrsvc.Gang
+	for _, gang := range gangList {
+		invalidTasks, err := s.transitGang(
+			gang,
+			pt.TaskState_PENDING,
+			pt.TaskState_READY,
+			"gang admitted")
+		if err != nil {
+			newGang := s.processGangFailure(n, gang, invalidTasks)
+			if newGang != nil {
+				// All the tasks are not deleted from the gang,
+				// Adding the new gang to invalid gang by that
+				// it can be requeued again
+				invalidGangs = append(invalidGangs, newGang)
 			}
+			continue
+		}
+
+		// Once the transition is done to ready state for all
+		// the tasks in the gang , we are enqueuing the gang to
+		// ready queue.
+		if err = s.EnqueueGang(gang); err != nil {
+			invalidGangs = append(invalidGangs, gang)
+			continue
 		}
 	}
+
+	// For invalid gangs, which are unable to be admitted to ready queue
+	// at scheduling phase for placement
+	//
+	// 1. remove the allocation for invalid gangs
+	// 2. transit those gangs from ready -> pending state
+	// 3. enqueue those invalid gangs back to pending queue at respool
+	for _, invalidGang := range invalidGangs {
+		if err = n.SubtractFromAllocation(
+			scalar.GetGangAllocation(invalidGang)); err != nil {
+			log.WithField("gang", invalidGang).
+				WithError(err).
+				Error("unable to remove allocation for invalid gang from respool at scheduler")
+		}
+
+		if _, err := s.transitGang(
+			invalidGang,
+			pt.TaskState_READY,
+			pt.TaskState_PENDING,
+			"gang admission failure"); err != nil {
+			log.WithError(err).
+				Error("not able to transit from READY -> PENDING")
+		}
+
+		if err = n.EnqueueGang(invalidGang); err != nil {
+			log.WithError(err).
+				Error("not able to enqueue gang back to pending queue at resource pool")
+		}
+	}
+	return len(gangList)
 }
 
 // processGangFailure removes the deleted tasks from the gang
@@ -402,6 +560,31 @@ func (s *scheduler) DequeueGang(
 	return &resmgrsvc.Gang{Tasks: validTasks}, nil
 }
 
+// PeekGangs returns the gangs in the ready queue in dequeue order of each
+// level, without removing them. The invalid tasks are left out of the gangs.
+func (s *scheduler) PeekGangs() []*resmgrsvc.Gang {
+	var gangs []*resmgrsvc.Gang
+	for _, level := range s.queue.Levels() {
+		items, err := s.queue.PeekItems(level, math.MaxInt32)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			var validTasks []*resmgr.Task
+			for _, t := range item.(*resmgrsvc.Gang).GetTasks() {
+				if _, ok := s.invalidTasks.Load(t.GetId().GetValue()); ok {
+					continue
+				}
+				validTasks = append(validTasks, t)
+			}
+			if len(validTasks) > 0 {
+				gangs = append(gangs, &resmgrsvc.Gang{Tasks: validTasks})
+			}
+		}
+	}
+	return gangs
+}
+
 // thread safe way to get random level
 func (s *scheduler) getRandLevel(n int) int {
 	s.lock.Lock()
@@ -17,6 +17,7 @@ package task
 import (
 	"container/list"
 	"context"
+	"fmt"
 	"math/rand"
 	"sync"
 	"sync/atomic"
@@ -125,6 +126,7 @@ func (suite *SchedulerTestSuite) SetupSuite() {
 		tally.NoopScope,
 		suite.resTree,
 		time.Duration(1)*time.Second,
+		0,
 		suite.rmTaskTracker,
 	)
 }
@@ -202,6 +204,24 @@ func TestScheduler_EnqueueGang_enqueues_until_the_queue_is_full(t *testing.T) {
 	}
 }
 
+func TestScheduler_PeekGangs(t *testing.T) {
+	scheduler := setupScheduler(3)
+	gangs := createGangs(3, resmgr.TaskType_BATCH)
+	for i, gang := range gangs {
+		gang.Tasks[0].Id = &peloton.TaskID{Value: fmt.Sprintf("job-%d", i)}
+		assert.NoError(t, scheduler.EnqueueGang(gang))
+	}
+	scheduler.AddInvalidTask(gangs[1].GetTasks()[0].GetId())
+
+	peeked := scheduler.PeekGangs()
+	assert.Len(t, peeked, 2)
+	assert.Equal(t, gangs[0].GetTasks(), peeked[0].GetTasks())
+	assert.Equal(t, gangs[2].GetTasks(), peeked[1].GetTasks())
+
+	// the gangs are left in the ready queue
+	assert.Equal(t, 3, scheduler.queue.Size())
+}
+
 func TestScheduler_DequeueGang_blocks_until_a_gang_is_added(t *testing.T) {
 	scheduler := setupScheduler(3)
 	gangs := createGangs(1, resmgr.TaskType_BATCH)
@@ -422,6 +442,154 @@ func (suite *SchedulerTestSuite) TestDeletedTasksDequeue() {
 	suite.Equal(2, len(g.GetTasks()))
 }
 
+// Tests that resource pools which do not fit in the scheduling cycle
+// budget are deferred to the next cycle.
+func (suite *SchedulerTestSuite) TestSchedulingCycleBudgetDefersResPools() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+
+	node1 := respool_mocks.NewMockResPool(ctrl)
+	node2 := respool_mocks.NewMockResPool(ctrl)
+	mtree := respool_mocks.NewMockTree(ctrl)
+	mtree.EXPECT().GetAllNodes(true).DoAndReturn(func(_ bool) *list.List {
+		nodesList := new(list.List)
+		nodesList.PushBack(node1)
+		nodesList.PushBack(node2)
+		return nodesList
+	}).Times(2)
+
+	testScope := tally.NewTestScope("", map[string]string{})
+	sched := &scheduler{
+		condition:   sync.NewCond(&sync.Mutex{}),
+		resPoolTree: mtree,
+		queue: queue.NewMultiLevelList(
+			"ready-queue",
+			maxReadyQueueSize),
+		rmTaskTracker: suite.rmTaskTracker,
+		metrics:       NewMetrics(testScope),
+		cycleBudget:   time.Nanosecond,
+	}
+
+	// the first resource pool is always scheduled, the second one
+	// is deferred as the budget is exceeded
+	node1.EXPECT().DequeueGangs(dequeueGangLimit).
+		DoAndReturn(func(_ int) ([]*resmgrsvc.Gang, error) {
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		})
+	sched.scheduleTasks()
+	suite.Equal(1, sched.nextResPool)
+	suite.Equal(
+		int64(1),
+		testScope.Snapshot().Counters()["scheduling.deferred_respools+"].Value())
+
+	// the next cycle starts from the deferred resource pool
+	node2.EXPECT().DequeueGangs(dequeueGangLimit).
+		DoAndReturn(func(_ int) ([]*resmgrsvc.Gang, error) {
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		})
+	sched.scheduleTasks()
+	suite.Equal(0, sched.nextResPool)
+}
+
+// Tests that the gangs pending admission past their placement deadline
+// are escalated as a whole.
+func (suite *SchedulerTestSuite) TestEscalatePendingGangs() {
+	ctrl := gomock.NewController(suite.T())
+	defer ctrl.Finish()
+
+	node := respool_mocks.NewMockResPool(ctrl)
+	node.EXPECT().ID().Return("respool-deadline").AnyTimes()
+	sched := &scheduler{
+		rmTaskTracker: suite.rmTaskTracker,
+		metrics:       NewMetrics(tally.NoopScope),
+	}
+
+	gang := &resmgrsvc.Gang{}
+	for i := 1; i <= 2; i++ {
+		t := &resmgr.Task{
+			Name:         fmt.Sprintf("job-deadline-%d", i),
+			Priority:     1,
+			MinInstances: 2,
+			JobId:        &peloton.JobID{Value: "job-deadline"},
+			Id: &peloton.TaskID{
+				Value: fmt.Sprintf("job-deadline-%d", i),
+			},
+			Resource: _testTasks[0].Resource,
+		}
+		suite.rmTaskTracker.AddTask(t, suite.eventStreamHandler, node, &Config{
+			PolicyName:                     ExponentialBackOffPolicy,
+			PlacementDeadline:              time.Minute,
+			PlacementDeadlinePriorityBoost: 2,
+		})
+		rmTask := suite.rmTaskTracker.GetTask(t.Id)
+		suite.NoError(rmTask.TransitTo(task.TaskState_PENDING.String()))
+		gang.Tasks = append(gang.Tasks, t)
+	}
+	defer func() {
+		for _, t := range gang.GetTasks() {
+			suite.rmTaskTracker.DeleteTask(t.Id)
+		}
+	}()
+
+	node.EXPECT().PeekGangs(respool.PendingQueue, gomock.Any()).
+		Return([]*resmgrsvc.Gang{gang}, nil).AnyTimes()
+
+	// the gang is still within its deadline
+	suite.Equal(0, sched.escalatePendingGangs(node, time.Now()))
+
+	// the whole gang is escalated once past its deadline
+	node.EXPECT().ReprioritizeGang(gang, uint32(3)).Return(nil)
+	suite.Equal(1, sched.escalatePendingGangs(
+		node, time.Now().Add(2*time.Minute)))
+	for _, t := range gang.GetTasks() {
+		state := suite.rmTaskTracker.GetTask(t.Id).GetCurrentState()
+		suite.Equal(task.TaskState_PENDING, state.State)
+		suite.Equal(ReasonPlacementDeadline, state.Reason)
+	}
+
+	// and only once
+	suite.Equal(0, sched.escalatePendingGangs(
+		node, time.Now().Add(4*time.Minute)))
+}
+
+// Tests that the dequeue limit adapts to the scheduling latency.
+func (suite *SchedulerTestSuite) TestAdaptDequeueLimit() {
+	sched := &scheduler{
+		metrics:     NewMetrics(tally.NoopScope),
+		cycleBudget: 100 * time.Millisecond,
+	}
+	suite.Equal(dequeueGangLimit, sched.dequeueLimit())
+
+	// 1ms per gang with 2 resource pools fits 50 gangs per pool
+	sched.adaptDequeueLimit(10*time.Millisecond, 10, 2)
+	suite.Equal(time.Millisecond, sched.gangLatency)
+	suite.Equal(50, sched.dequeueLimit())
+
+	// slower gangs shrink the limit, down to the min limit
+	sched.adaptDequeueLimit(10*time.Second, 10, 2)
+	suite.Equal(minDequeueGangLimit, sched.dequeueLimit())
+
+	// no gangs keeps the latency estimate
+	latency := sched.gangLatency
+	sched.adaptDequeueLimit(time.Millisecond, 0, 2)
+	suite.Equal(latency, sched.gangLatency)
+
+	// faster gangs grow the limit, up to the max limit
+	for i := 0; i < 50; i++ {
+		sched.adaptDequeueLimit(time.Microsecond, 10, 2)
+	}
+	suite.Equal(dequeueGangLimit, sched.dequeueLimit())
+
+	// adaptive batching is disabled without a budget
+	sched = &scheduler{
+		metrics: NewMetrics(tally.NoopScope),
+	}
+	sched.adaptDequeueLimit(10*time.Second, 10, 2)
+	suite.Equal(dequeueGangLimit, sched.dequeueLimit())
+}
+
 func BenchmarkScheduler_EnqueueGang(b *testing.B) {
 	share := b.N / 4
 	gangs := createMixedGangs(map[resmgr.TaskType]int{
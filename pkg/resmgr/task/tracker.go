@@ -54,12 +54,16 @@ type Tracker interface {
 	// DeleteTask deletes the task from the map
 	DeleteTask(t *peloton.TaskID)
 
+	// RestoreTask tracks again a run of a task which was replaced by a
+	// new run of the task, undoing the replacement
+	RestoreTask(rmTask *RMTask)
+
 	// MarkItDone marks the task done and add back those
 	// resources to respool
 	MarkItDone(mesosTaskID string) error
 
-	// MarkItInvalid marks the task done and invalidate them
-	// in to respool by that they can be removed from the queue
+	// MarkItInvalid marks the task done and removes it from the queue of
+	// its respool, or invalidates it in the ready queue
 	MarkItInvalid(mesosTaskID string) error
 
 	// TasksByHosts returns all tasks of the given type running on the given hosts.
@@ -68,6 +72,10 @@ type Tracker interface {
 	// AddResources adds the task resources to respool
 	AddResources(taskID *peloton.TaskID) error
 
+	// UpdateResources updates the resources of a running task which was
+	// resized in place, along with the allocation of its respool
+	UpdateResources(mesosTaskID string, resource *task.ResourceConfig) error
+
 	// GetSize returns the number of the tasks in tracker
 	GetSize() int64
 
@@ -319,6 +327,27 @@ func (tr *tracker) deleteTask(t *peloton.TaskID) {
 	tr.metrics.TasksCountInTracker.Update(float64(tr.GetSize()))
 }
 
+// RestoreTask tracks again a run of a task which was replaced by a new
+// run of the task in AddTask, and is not an orphan task anymore
+func (tr *tracker) RestoreTask(rmTask *RMTask) {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+
+	tr.deleteTask(rmTask.task.GetId())
+
+	mesosTaskID := rmTask.task.GetTaskId().GetValue()
+	if _, ok := tr.orphanTasks[mesosTaskID]; ok {
+		delete(tr.orphanTasks, mesosTaskID)
+		tr.metrics.OrphanTasks.Update(float64(len(tr.orphanTasks)))
+	}
+
+	tr.tasks[rmTask.task.GetId().GetValue()] = rmTask
+	if rmTask.task.Hostname != "" {
+		tr.setPlacement(rmTask.task.GetTaskId(), rmTask.task.GetHostname())
+	}
+	tr.metrics.TasksCountInTracker.Update(float64(tr.GetSize()))
+}
+
 // MarkItDone updates the resources in resmgr and removes the task
 // from the tracker
 func (tr *tracker) MarkItDone(
@@ -329,8 +358,8 @@ func (tr *tracker) MarkItDone(
 	return tr.markItDone(mesosTaskID)
 }
 
-// MarkItInvalid marks the task done and invalidate them
-// in to respool by that they can be removed from the queue
+// MarkItInvalid marks the task done and removes it from the queue of its
+// respool, or invalidates it in the ready queue
 func (tr *tracker) MarkItInvalid(mesosTaskID string) error {
 	tr.lock.Lock()
 	defer tr.lock.Unlock()
@@ -354,9 +383,13 @@ func (tr *tracker) MarkItInvalid(mesosTaskID string) error {
 
 	switch t.GetCurrentState().State {
 	case task.TaskState_PENDING, task.TaskState_INITIALIZED:
-		// If task is in INITIALIZED or PENDING state we need to invalidate
-		// it from in pending queue
-		t.respool.AddInvalidTask(tID)
+		// If task is in INITIALIZED or PENDING state we need to remove
+		// it from the queue of its resource pool
+		if err := t.respool.RemoveTask(tID); err != nil {
+			log.WithError(err).
+				WithField("task_id", taskID).
+				Debug("killed task not removed from its resource pool")
+		}
 	case task.TaskState_READY:
 		// If task is in READY state we need to invalidate
 		// it from in ready queue
@@ -481,6 +514,70 @@ func (tr *tracker) AddResources(
 	return nil
 }
 
+// UpdateResources replaces the resources of the running task with the
+// given mesos task ID, and updates the allocation of its respool with the
+// difference between the new and the old resources of the task.
+func (tr *tracker) UpdateResources(
+	mesosTaskID string,
+	resource *task.ResourceConfig,
+) error {
+	tr.lock.Lock()
+	defer tr.lock.Unlock()
+
+	taskID, err := util.ParseTaskIDFromMesosTaskID(mesosTaskID)
+	if err != nil {
+		return err
+	}
+
+	t := tr.getTask(&peloton.TaskID{Value: taskID})
+	if t == nil || t.Task().GetTaskId().GetValue() != mesosTaskID {
+		return errors.Errorf("rmTask %s is not in tracker", mesosTaskID)
+	}
+
+	// Only the resources of running tasks are resized in place, the
+	// resources of other tasks are not accounted in the same way
+	taskState := t.GetCurrentState().State
+	if taskState != task.TaskState_RUNNING {
+		return errors.Errorf("rmTask %s is in state %s, not running",
+			mesosTaskID, taskState)
+	}
+
+	oldRes := scalar.ConvertToResmgrResource(t.Task().GetResource())
+	if err := t.respool.SubtractFromAllocation(
+		scalar.GetTaskAllocation(t.Task())); err != nil {
+		return errors.Errorf("failed to subtract resources of rmTask %s "+
+			"from respool %s", mesosTaskID, t.respool.Name())
+	}
+
+	t.Task().Resource = resource
+	newRes := scalar.ConvertToResmgrResource(resource)
+	if err := t.respool.AddToAllocation(
+		scalar.GetTaskAllocation(t.Task())); err != nil {
+		return errors.Errorf("failed to add resources of rmTask %s "+
+			"to respool %s", mesosTaskID, t.respool.Name())
+	}
+
+	tr.metricsLock.Lock()
+	defer tr.metricsLock.Unlock()
+
+	if val, ok := tr.resourcesHeldByTaskState[taskState]; ok {
+		tr.resourcesHeldByTaskState[taskState] = val.Subtract(oldRes).Add(newRes)
+	}
+
+	// publish metrics
+	if gauge, ok := tr.metrics.ResourcesHeldByTaskState[taskState]; ok {
+		gauge.Update(tr.resourcesHeldByTaskState[taskState])
+	}
+
+	log.WithFields(log.Fields{
+		"mesos_task_id": mesosTaskID,
+		"respool_id":    t.respool.ID(),
+		"old_resources": oldRes,
+		"new_resources": newRes,
+	}).Info("Updated resources of resized task")
+	return nil
+}
+
 // GetSize gets the number of tasks in tracker
 func (tr *tracker) GetSize() int64 {
 	return int64(len(tr.tasks))
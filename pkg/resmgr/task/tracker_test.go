@@ -707,6 +707,27 @@ func (suite *trackerTestSuite) TestGetOrphanTask() {
 	}
 }
 
+// TestRestoreTask tests tracking again a run of a task replaced by
+// a new run of the task
+func (suite *trackerTestSuite) TestRestoreTask() {
+	prevRMTask := suite.tracker.GetTask(suite.task.GetId())
+	prevMesosTaskID := suite.task.GetTaskId().GetValue()
+
+	newMesosTaskID := fmt.Sprintf("%s-0-2", suite.task.GetJobId().GetValue())
+	newTask := *suite.task
+	newTask.TaskId = &mesos_v1.TaskID{Value: &newMesosTaskID}
+	newTask.Hostname = ""
+	suite.addTaskToTracker(&newTask)
+	suite.Equal(prevRMTask, suite.tracker.GetOrphanTask(prevMesosTaskID))
+	suite.NotEqual(prevRMTask, suite.tracker.GetTask(suite.task.GetId()))
+
+	suite.tracker.RestoreTask(prevRMTask)
+	suite.Equal(prevRMTask, suite.tracker.GetTask(suite.task.GetId()))
+	suite.Nil(suite.tracker.GetOrphanTask(prevMesosTaskID))
+	suite.Len(suite.tracker.TasksByHosts(
+		[]string{suite.hostname}, resmgr.TaskType_UNKNOWN)[suite.hostname], 1)
+}
+
 // TestGetOrphanTaskNoTask tests getting an unknown orphan rm Task
 func (suite *trackerTestSuite) TestGetOrphanTaskNoTask() {
 	tr := suite.tracker.(*tracker)
@@ -903,6 +924,53 @@ func (suite *trackerTestSuite) TestResourcesHeldByTaskStateRelease() {
 	)
 }
 
+// TestUpdateResources tests updating the resources of a running task
+// along with the allocation of its respool
+func (suite *trackerTestSuite) TestUpdateResources() {
+	rmTask := suite.tracker.GetTask(suite.task.GetId())
+	mesosTaskID := rmTask.Task().GetTaskId().GetValue()
+	newResource := &task.ResourceConfig{
+		CpuLimit:    2,
+		DiskLimitMb: 10,
+		MemLimitMb:  200,
+	}
+
+	// the resources of a task which is not running are not updated
+	err := suite.tracker.UpdateResources(mesosTaskID, newResource)
+	suite.Error(err)
+
+	for _, s := range []task.TaskState{
+		task.TaskState_PENDING,
+		task.TaskState_READY,
+		task.TaskState_PLACING,
+		task.TaskState_PLACED,
+		task.TaskState_LAUNCHING,
+		task.TaskState_LAUNCHED,
+		task.TaskState_RUNNING,
+	} {
+		suite.NoError(rmTask.TransitTo(s.String()))
+	}
+	suite.NoError(rmTask.respool.AddToAllocation(
+		scalar.GetTaskAllocation(rmTask.Task())))
+
+	err = suite.tracker.UpdateResources(mesosTaskID, newResource)
+	suite.NoError(err)
+	suite.Equal(newResource, rmTask.Task().GetResource())
+	suite.Equal(&scalar.Resources{
+		CPU:    float64(2),
+		DISK:   float64(10),
+		GPU:    float64(0),
+		MEMORY: float64(200),
+	}, suite.respool.GetTotalAllocatedResources())
+	resourcesHeld := suite.tracker.(*tracker).resourcesHeldByTaskState[task.TaskState_RUNNING]
+	suite.Equal(float64(2), resourcesHeld.GetCPU())
+	suite.Equal(float64(200), resourcesHeld.GetMem())
+
+	// the resources of an older run of the task are not updated
+	err = suite.tracker.UpdateResources(unknownMesosTaskID, newResource)
+	suite.Error(err)
+}
+
 // TestResourcesHeldByTaskStateReleaseResourceOrphanTasks ensures
 // ResourcesHeldByTaskState is updated when orphan task resources are released
 func (suite *trackerTestSuite) TestResourcesHeldByTaskStateReleaseResourceOrphanTasks() {
@@ -49,4 +49,14 @@ type Config struct {
 	MaxUpdatesPerJob int `yaml:"max_updates_job"`
 	// Replication controls the replication config of the keyspace
 	Replication *Replication `yaml:"replication"`
+	// JobConfigCacheSize is the maximum number of job config versions
+	// cached in memory by the ORM store
+	JobConfigCacheSize int `yaml:"job_config_cache_size"`
+	// AutoMigrateSchema migrates the tables of the ORM storage objects to
+	// their definitions when the ORM store is created. The tables created
+	// by the cql migrations are left to them.
+	AutoMigrateSchema bool `yaml:"auto_migrate_schema"`
+	// LoggedBatches makes the batch writes of the ORM storage objects
+	// logged instead of unlogged
+	LoggedBatches bool `yaml:"logged_batches"`
 }
@@ -244,6 +244,8 @@ func (suite *MockDatastoreTestSuite) TestDataStoreFailureDeleteJobCfgVersion() {
 
 	// Setup mocks for this context
 
+	suite.jobConfigOps.EXPECT().Invalidate(suite.testJobID).AnyTimes()
+
 	// Simulate failure to delete task config
 	suite.mockedDataStore.EXPECT().Execute(ctx, gomock.Any()).
 		Return(result, errors.New("my-error"))
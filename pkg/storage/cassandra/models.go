@@ -171,6 +171,8 @@ type UpdateRecord struct {
 	UpdateTime           time.Time         `cql:"update_time"`
 	OpaqueData           string            `cql:"opaque_data"`
 	CompletionTime       string            `cql:"completion_time"`
+	CanaryPromoted       bool              `cql:"canary_promoted"`
+	GateTripReason       string            `cql:"gate_trip_reason"`
 }
 
 // GetUpdateConfig unmarshals and returns the configuration of the job update.
@@ -107,6 +107,11 @@ const (
 	// Default context timeout for the method to cleanup old
 	// job updates from the storage
 	_jobUpdatesCleanupTimeout = 120 * time.Second
+
+	// _taskRuntimeBatchSize is the maximum number of task runtimes
+	// written in a single batch, to stay within the batch size limits
+	// of Cassandra
+	_taskRuntimeBatchSize = 50
 )
 
 // GenerateTestCassandraConfig generates a test config for local C* client
@@ -158,7 +163,10 @@ func ToOrmConfig(c *Config) *ormcassandra.Config {
 			CQLVersion:         c.CassandraConn.CQLVersion,
 			MaxGoRoutines:      c.CassandraConn.MaxGoRoutines,
 		},
-		StoreName: c.StoreName,
+		StoreName:          c.StoreName,
+		JobConfigCacheSize: c.JobConfigCacheSize,
+		AutoMigrateSchema:  c.AutoMigrateSchema,
+		LoggedBatches:      c.LoggedBatches,
 	}
 }
 
@@ -216,17 +224,28 @@ type Store struct {
 
 // NewStore creates a Store
 func NewStore(config *Config, scope tally.Scope) (*Store, error) {
-	dataStore, err := impl.CreateStore(config.CassandraConn, config.StoreName, scope)
-	if err != nil {
-		log.Errorf("Failed to NewStore, err=%v", err)
-		return nil, err
-	}
 	ormStore, ormErr := ormobjects.NewCassandraStore(
 		ToOrmConfig(config),
 		scope)
 	if ormErr != nil {
 		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
 	}
+	return NewStoreWithORM(config, ormStore, scope)
+}
+
+// NewStoreWithORM creates a Store which reads and deletes job configs
+// through the given ORM store, so that they go through the same job config
+// cache as the ORM objects of the process.
+func NewStoreWithORM(
+	config *Config,
+	ormStore *ormobjects.Store,
+	scope tally.Scope,
+) (*Store, error) {
+	dataStore, err := impl.CreateStore(config.CassandraConn, config.StoreName, scope)
+	if err != nil {
+		log.Errorf("Failed to NewStore, err=%v", err)
+		return nil, err
+	}
 
 	return &Store{
 		DataStore: dataStore,
@@ -325,6 +344,26 @@ func (s *Store) executeWrite(ctx context.Context, stmt api.Statement) (api.Resul
 	}
 }
 
+// executeBatchWrite executes a batch of write statements, retrying
+// on transient errors.
+func (s *Store) executeBatchWrite(ctx context.Context, stmts []api.Statement) error {
+	p := backoff.NewRetrier(s.retryPolicy)
+	for {
+		err := s.DataStore.ExecuteBatch(ctx, stmts)
+		if err == nil {
+			return nil
+		}
+		err = s.handleDataStoreError(err, p)
+
+		if err != nil {
+			if !common.IsTransientError(err) {
+				s.metrics.ErrorMetrics.NotTransient.Inc(1)
+			}
+			return err
+		}
+	}
+}
+
 func (s *Store) executeRead(
 	ctx context.Context,
 	stmt api.Statement) ([]map[string]interface{}, error) {
@@ -449,6 +488,88 @@ func (c *luceneClauses) WithTimeRangeFilter(timeRange *peloton.TimeRange, timeFi
 	return nil
 }
 
+// WithCursorFilter will take the first order of a query and its value in
+// the cursor the query is resumed after, and create a range filter on the
+// order field which skips the jobs sorting before the cursor
+func (c *luceneClauses) WithCursorFilter(order *query.OrderBy, value string) {
+	if c == nil || len(value) == 0 {
+		return
+	}
+
+	field := order.GetProperty().GetValue()
+	if storage.IsJobQueryTimeProperty(field) {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return
+		}
+		value = t.UTC().Format(jobIndexTimeFormat)
+	}
+
+	if order.GetOrder() == query.OrderBy_DESC {
+		*c = append(*c, fmt.Sprintf(`{type: "range", field:"%s", upper: %s, include_upper: true}`, field, strconv.Quote(value)))
+		return
+	}
+	*c = append(*c, fmt.Sprintf(`{type: "range", field:"%s", lower: %s, include_lower: true}`, field, strconv.Quote(value)))
+}
+
+// filterJobIndexResults filters the jobs returned by the job index for a
+// query, keeping only the ones which match the label selectors of the
+// query exactly, and sort after the cursor the query is resumed after.
+func (s *Store) filterJobIndexResults(
+	ctx context.Context,
+	allResults []map[string]interface{},
+	spec *job.QuerySpec,
+	orderBy []*query.OrderBy,
+) ([]map[string]interface{}, error) {
+	summaries, err := s.getJobSummaryFromResultMap(ctx, allResults)
+	if err != nil {
+		return nil, err
+	}
+
+	after := spec.GetPagination().GetAfter()
+	seen := make(map[string]bool)
+	for _, key := range after.GetKeys() {
+		seen[key] = true
+	}
+
+	var results []map[string]interface{}
+	for i, summary := range summaries {
+		if !hasLabels(summary.GetLabels(), spec.GetLabelSelectors()) {
+			continue
+		}
+
+		if after != nil {
+			cmp, err := storage.CompareJobSummaryToCursor(summary, orderBy, after)
+			if err != nil {
+				return nil, err
+			}
+			if cmp < 0 || (cmp == 0 && seen[summary.GetId().GetValue()]) {
+				continue
+			}
+		}
+		results = append(results, allResults[i])
+	}
+	return results, nil
+}
+
+// hasLabels returns true if the labels contain all the label selectors
+func hasLabels(labels []*peloton.Label, selectors []*peloton.Label) bool {
+	for _, selector := range selectors {
+		found := false
+		for _, label := range labels {
+			if label.GetKey() == selector.GetKey() &&
+				label.GetValue() == selector.GetValue() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 // QueryJobs returns all jobs in the resource pool that matches the spec.
 func (s *Store) QueryJobs(ctx context.Context, respoolID *peloton.ResourcePoolID, spec *job.QuerySpec, summaryOnly bool) ([]*job.JobInfo, []*job.JobSummary, uint32, error) {
 	// Query is based on stratio lucene index on jobs.
@@ -467,6 +588,14 @@ func (s *Store) QueryJobs(ctx context.Context, respoolID *peloton.ResourcePoolID
 		clauses = append(clauses, fmt.Sprintf(`{type: "contains", field:"labels", values:%s}`, strconv.Quote(label.Value)))
 	}
 
+	// Labels field must contain both key and value of the label selectors.
+	// Since the labels field is analyzed, the exact match of the selectors
+	// is done on the jobs returned by the index.
+	for _, label := range spec.GetLabelSelectors() {
+		clauses = append(clauses, fmt.Sprintf(`{type: "contains", field:"labels", values:%s}`, strconv.Quote(label.GetKey())))
+		clauses = append(clauses, fmt.Sprintf(`{type: "contains", field:"labels", values:%s}`, strconv.Quote(label.GetValue())))
+	}
+
 	// jobconfig field must contain all specified keywords
 	for _, word := range spec.GetKeywords() {
 		// Lucene for some reason does wildcard search as case insensitive
@@ -565,6 +694,21 @@ func (s *Store) QueryJobs(ctx context.Context, respoolID *peloton.ResourcePoolID
 		}
 	}
 
+	// add default sorting by creation time in descending order in case orderby
+	// is not specificed in the query spec
+	orderBy := storage.JobQueryOrderBy(spec)
+
+	// Skip the jobs sorting before the cursor in the index, the ones with
+	// the same first sort value are skipped after the query.
+	if after := spec.GetPagination().GetAfter(); after != nil {
+		if len(after.GetValues()) != len(orderBy) {
+			s.metrics.JobMetrics.JobQueryFail.Inc(1)
+			return nil, nil, 0, yarpcerrors.InvalidArgumentErrorf(
+				"cursor does not match the order of the query")
+		}
+		clauses.WithCursorFilter(orderBy[0], after.GetValues()[0])
+	}
+
 	where := `expr(job_index_lucene_v2, '{filter: [`
 	for i, c := range clauses {
 		if i > 0 {
@@ -574,20 +718,6 @@ func (s *Store) QueryJobs(ctx context.Context, respoolID *peloton.ResourcePoolID
 	}
 	where += "]"
 
-	// add default sorting by creation time in descending order in case orderby
-	// is not specificed in the query spec
-	var orderBy = spec.GetPagination().GetOrderBy()
-	if orderBy == nil || len(orderBy) == 0 {
-		orderBy = []*query.OrderBy{
-			{
-				Order: query.OrderBy_DESC,
-				Property: &query.PropertyPath{
-					Value: "creation_time",
-				},
-			},
-		}
-	}
-
 	// add sorter into the query
 	where += ", sort:["
 	count := 0
@@ -638,10 +768,22 @@ func (s *Store) QueryJobs(ctx context.Context, respoolID *peloton.ResourcePoolID
 		return nil, nil, 0, err
 	}
 
+	if len(spec.GetLabelSelectors()) > 0 || spec.GetPagination().GetAfter() != nil {
+		allResults, err = s.filterJobIndexResults(ctx, allResults, spec, orderBy)
+		if err != nil {
+			s.metrics.JobMetrics.JobQueryFail.Inc(1)
+			return nil, nil, 0, err
+		}
+	}
+
 	total := uint32(len(allResults))
 
-	// Apply offset and limit.
-	begin := spec.GetPagination().GetOffset()
+	// Apply offset and limit. The offset is ignored if the query is
+	// resumed after a cursor, since the jobs before it are skipped already.
+	var begin uint32
+	if spec.GetPagination().GetAfter() == nil {
+		begin = spec.GetPagination().GetOffset()
+	}
 	if begin > total {
 		begin = total
 	}
@@ -836,6 +978,7 @@ func (s *Store) addPodEvent(
 			"volumeID",
 			"message",
 			"reason",
+			"launch_correlation_id",
 			"update_timestamp").
 		Values(
 			jobID.GetValue(),
@@ -854,6 +997,7 @@ func (s *Store) addPodEvent(
 			runtime.GetVolumeID().GetValue(),
 			runtime.GetMessage(),
 			runtime.GetReason(),
+			runtime.GetLaunchCorrelationId(),
 			time.Now()).Into(podEventsTable)
 
 	err = s.applyStatement(ctx, stmt, runtime.GetMesosTaskId().GetValue())
@@ -968,6 +1112,10 @@ func (s *Store) GetPodEvents(
 		podEvent.Reason = value["reason"].(string)
 		podEvent.AgentId = value["agent_id"].(string)
 		podEvent.Hostname = value["hostname"].(string)
+		// events added before the launch correlation ID was tracked
+		// do not have one.
+		podEvent.LaunchCorrelationId, _ =
+			value["launch_correlation_id"].(string)
 
 		podEvents = append(podEvents, podEvent)
 	}
@@ -1554,6 +1702,64 @@ func (s *Store) UpdateTaskRuntime(
 	return nil
 }
 
+// UpdateTaskRuntimes updates the runtimes of multiple tasks of a peloton
+// job. The runtimes are written in batches, which are all in the same
+// partition since the task runtimes are partitioned by job.
+func (s *Store) UpdateTaskRuntimes(
+	ctx context.Context,
+	jobID *peloton.JobID,
+	runtimes map[uint32]*task.RuntimeInfo,
+	jobType job.JobType) error {
+	var instanceIDs []uint32
+	for instanceID := range runtimes {
+		instanceIDs = append(instanceIDs, instanceID)
+	}
+	sort.Slice(instanceIDs, func(i, j int) bool {
+		return instanceIDs[i] < instanceIDs[j]
+	})
+
+	for start := 0; start < len(instanceIDs); start += _taskRuntimeBatchSize {
+		end := start + _taskRuntimeBatchSize
+		if end > len(instanceIDs) {
+			end = len(instanceIDs)
+		}
+
+		var stmts []api.Statement
+		for _, instanceID := range instanceIDs[start:end] {
+			runtime := runtimes[instanceID]
+			runtimeBuffer, err := proto.Marshal(runtime)
+			if err != nil {
+				s.metrics.TaskMetrics.TaskUpdateFail.Inc(1)
+				return err
+			}
+
+			queryBuilder := s.DataStore.NewQuery()
+			stmts = append(stmts, queryBuilder.Update(taskRuntimeTable).
+				Set("version", runtime.Revision.Version).
+				Set("update_time", time.Now().UTC()).
+				Set("state", runtime.GetState().String()).
+				Set("runtime_info", runtimeBuffer).
+				Where(qb.Eq{"job_id": jobID.GetValue(), "instance_id": instanceID}))
+		}
+
+		if err := s.executeBatchWrite(ctx, stmts); err != nil {
+			log.WithError(err).
+				WithField("job_id", jobID.GetValue()).
+				WithField("instances", instanceIDs[start:end]).
+				Debug("Fail to update task runtimes")
+			s.metrics.TaskMetrics.TaskUpdateFail.Inc(int64(end - start))
+			return err
+		}
+
+		s.metrics.TaskMetrics.TaskUpdate.Inc(int64(end - start))
+		for _, instanceID := range instanceIDs[start:end] {
+			s.addPodEvent(ctx, jobID, instanceID, runtimes[instanceID])
+		}
+	}
+
+	return nil
+}
+
 // GetTaskForJob returns a task by jobID and instanceID
 func (s *Store) GetTaskForJob(ctx context.Context, jobID string, instanceID uint32) (map[uint32]*task.TaskInfo, error) {
 	taskID := fmt.Sprintf(taskIDFmt, jobID, int(instanceID))
@@ -1682,8 +1888,7 @@ func (s *Store) DeleteJob(
 		}
 	}
 
-	stmt = queryBuilder.Delete(jobConfigTable).Where(qb.Eq{"job_id": jobID})
-	if err := s.applyStatement(ctx, stmt, jobID); err != nil {
+	if err := s.deleteJobConfigOnDeleteJob(ctx, jobID); err != nil {
 		s.metrics.JobMetrics.JobDeleteFail.Inc(1)
 		return err
 	}
@@ -1698,6 +1903,28 @@ func (s *Store) DeleteJob(
 	return err
 }
 
+// deleteJobConfigOnDeleteJob deletes all the config versions of a job
+// through jobConfigOps, which also drops them from the job config cache.
+func (s *Store) deleteJobConfigOnDeleteJob(
+	ctx context.Context,
+	jobID string) error {
+	id := &peloton.JobID{Value: jobID}
+	jobConfig, _, err := s.jobConfigOps.GetCurrentVersion(ctx, id)
+	if err != nil {
+		// if the config is not found, then the job has already been deleted.
+		if yarpcerrors.IsNotFound(errors.Cause(err)) {
+			return nil
+		}
+		return err
+	}
+	for i := uint64(1); i <= jobConfig.GetChangeLog().GetVersion(); i++ {
+		if err := s.jobConfigOps.Delete(ctx, id, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // task_config_v2 has partition key of jobID, version, instance_id
 // so we need to delete this table per job, per version, per instance
 func (s *Store) deleteTaskConfigV2OnDeleteJob(
@@ -2553,6 +2780,8 @@ func (s *Store) GetUpdate(ctx context.Context, id *peloton.UpdateID) (
 			UpdateTime:           record.UpdateTime.Format(time.RFC3339Nano),
 			OpaqueData:           &peloton.OpaqueData{Data: record.OpaqueData},
 			CompletionTime:       record.CompletionTime,
+			CanaryPromoted:       record.CanaryPromoted,
+			GateTripReason:       record.GateTripReason,
 		}
 
 		s.metrics.UpdateMetrics.UpdateGet.Inc(1)
@@ -2623,7 +2852,9 @@ func (s *Store) deleteJobConfigVersion(
 	version uint64) error {
 	queryBuilder := s.DataStore.NewQuery()
 
-	// next delete the job configuration
+	// next delete the job configuration, dropping it from the job config
+	// cache once the delete is done
+	defer s.jobConfigOps.Invalidate(jobID)
 	stmt := queryBuilder.Delete(jobConfigTable).Where(qb.Eq{
 		"job_id": jobID.GetValue(), "version": version})
 	err := s.applyStatement(ctx, stmt, jobID.GetValue())
@@ -2670,6 +2901,16 @@ func (s *Store) WriteUpdateProgress(
 		stmt = stmt.Set("completion_time", updateInfo.GetCompletionTime())
 	}
 
+	// the canaries of an update are only ever promoted once, and its
+	// health gate only trips once, so these are set but never reset
+	if updateInfo.GetCanaryPromoted() {
+		stmt = stmt.Set("canary_promoted", true)
+	}
+
+	if len(updateInfo.GetGateTripReason()) != 0 {
+		stmt = stmt.Set("gate_trip_reason", updateInfo.GetGateTripReason())
+	}
+
 	stmt = stmt.Where(qb.Eq{"update_id": updateInfo.GetUpdateID().GetValue()})
 
 	if err := s.applyStatement(
@@ -2777,6 +3018,8 @@ func (s *Store) GetUpdateProgress(ctx context.Context, id *peloton.UpdateID) (
 			InstancesCurrent: record.GetProcessingInstances(),
 			UpdateTime:       record.UpdateTime.Format(time.RFC3339Nano),
 			CompletionTime:   record.CompletionTime,
+			CanaryPromoted:   record.CanaryPromoted,
+			GateTripReason:   record.GateTripReason,
 		}
 
 		s.metrics.UpdateMetrics.UpdateGetProgess.Inc(1)
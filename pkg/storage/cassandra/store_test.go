@@ -870,8 +870,17 @@ func (suite *CassandraStoreTestSuite) TestGetJobSummary() {
 	suite.Equal(0, len(summary))
 	suite.Equal(0, int(total))
 
+	// read the config to populate the job config cache
+	_, _, err = store.jobConfigOps.Get(context.Background(), &jobID, 1)
+	suite.NoError(err)
+
 	suite.NoError(jobStore.DeleteJob(context.Background(), jobID.GetValue()))
 	suite.NoError(deleteJobIndex(context.Background(), &jobID))
+
+	// the config is deleted through jobConfigOps, so it is not served
+	// from the job config cache either
+	_, _, err = store.jobConfigOps.Get(context.Background(), &jobID, 1)
+	suite.True(yarpcerrors.IsNotFound(err))
 }
 
 func (suite *CassandraStoreTestSuite) TestQueryJob() {
@@ -2329,6 +2338,79 @@ func (suite *CassandraStoreTestSuite) TestWriteUpdateProgressChangUpdateTimeOnly
 	proto.Equal(updateModel, updateProgress)
 }
 
+// TestWriteUpdateProgressHealthGate tests writing the canary promotion
+// and the health gate trip of an update.
+func (suite *CassandraStoreTestSuite) TestWriteUpdateProgressHealthGate() {
+	jobID := &peloton.JobID{Value: uuid.New()}
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+	now := time.Now().UTC()
+
+	suite.NoError(store.CreateUpdate(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID: updateID,
+			JobID:    jobID,
+			UpdateConfig: &update.UpdateConfig{
+				BatchSize: 1,
+				Canary:    &update.CanaryConfig{Instances: 1},
+			},
+			JobConfigVersion:     2,
+			PrevJobConfigVersion: 1,
+			State:                update.State_ROLLING_FORWARD,
+			InstancesTotal:       2,
+			InstancesUpdated:     []uint32{0, 1},
+			Type:                 models.WorkflowType_UPDATE,
+			CreationTime:         now.Format(time.RFC3339Nano),
+			UpdateTime:           now.Format(time.RFC3339Nano),
+		},
+	))
+
+	updateProgress, err := store.GetUpdateProgress(context.Background(), updateID)
+	suite.NoError(err)
+	suite.False(updateProgress.GetCanaryPromoted())
+	suite.Empty(updateProgress.GetGateTripReason())
+
+	suite.NoError(store.WriteUpdateProgress(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID:       updateID,
+			CanaryPromoted: true,
+			UpdateTime:     now.Format(time.RFC3339Nano),
+		},
+	))
+	suite.NoError(store.WriteUpdateProgress(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID:       updateID,
+			GateTripReason: "failure rate exceeded",
+			UpdateTime:     now.Format(time.RFC3339Nano),
+		},
+	))
+
+	// a progress update without them does not reset them
+	suite.NoError(store.WriteUpdateProgress(
+		context.Background(),
+		&models.UpdateModel{
+			UpdateID:         updateID,
+			State:            update.State_ROLLING_BACKWARD,
+			PrevState:        update.State_ROLLING_FORWARD,
+			InstancesCurrent: []uint32{},
+			UpdateTime:       now.Format(time.RFC3339Nano),
+		},
+	))
+
+	updateProgress, err = store.GetUpdateProgress(context.Background(), updateID)
+	suite.NoError(err)
+	suite.True(updateProgress.GetCanaryPromoted())
+	suite.Equal("failure rate exceeded", updateProgress.GetGateTripReason())
+
+	updateInfo, err := store.GetUpdate(context.Background(), updateID)
+	suite.NoError(err)
+	suite.True(updateInfo.GetCanaryPromoted())
+	suite.Equal("failure rate exceeded", updateInfo.GetGateTripReason())
+	suite.Equal(uint32(1), updateInfo.GetUpdateConfig().GetCanary().GetInstances())
+}
+
 // TestModifyUpdate tests ModifyUpdate call
 func (suite *CassandraStoreTestSuite) TestModifyUpdate() {
 	// the job identifier
@@ -2533,6 +2615,47 @@ func (suite *CassandraStoreTestSuite) TestGetTaskRuntime() {
 	suite.Equal(info.Runtime, runtime)
 }
 
+// TestUpdateTaskRuntimes tests updating the runtimes of multiple tasks
+// spanning more than one batch
+func (suite *CassandraStoreTestSuite) TestUpdateTaskRuntimes() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	instanceCount := uint32(_taskRuntimeBatchSize + 1)
+
+	runtimes := make(map[uint32]*task.RuntimeInfo)
+	for i := uint32(0); i < instanceCount; i++ {
+		tID := fmt.Sprintf("%s-%d-%d", jobID.GetValue(), i, 1)
+		suite.NoError(store.CreateTaskRuntime(
+			context.Background(),
+			jobID,
+			i,
+			&task.RuntimeInfo{
+				MesosTaskId: &mesos.TaskID{Value: &tID},
+				State:       task.TaskState_INITIALIZED,
+			},
+			"",
+			job.JobType_BATCH))
+
+		runtimes[i] = &task.RuntimeInfo{
+			MesosTaskId: &mesos.TaskID{Value: &tID},
+			State:       task.TaskState_PENDING,
+			Revision:    &peloton.ChangeLog{Version: 2},
+		}
+	}
+
+	suite.NoError(store.UpdateTaskRuntimes(
+		context.Background(),
+		jobID,
+		runtimes,
+		job.JobType_BATCH))
+
+	for i := uint32(0); i < instanceCount; i++ {
+		runtime, err := store.GetTaskRuntime(context.Background(), jobID, i)
+		suite.NoError(err)
+		suite.Equal(task.TaskState_PENDING, runtime.GetState())
+		suite.Equal(uint64(2), runtime.GetRevision().GetVersion())
+	}
+}
+
 func (suite *CassandraStoreTestSuite) TestTaskQueryFilter() {
 	var taskStore storage.TaskStore
 	taskStore = store
@@ -3043,6 +3166,7 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 		},
 		ConfigVersion:        3,
 		DesiredConfigVersion: 4,
+		LaunchCorrelationId:  "launch-correlation-id",
 	}
 
 	store.addPodEvent(context.Background(), jobID, 0, runtime)
@@ -3053,6 +3177,7 @@ func (suite *CassandraStoreTestSuite) TestGetPodEvent() {
 		"7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2")
 	suite.Equal(len(podEvents), 1)
 	suite.NoError(err)
+	suite.Equal("launch-correlation-id", podEvents[0].GetLaunchCorrelationId())
 
 	mesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-3"
 	prevMesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2"
@@ -45,6 +45,13 @@ const (
 	getIter = "get_iter"
 	update  = "update"
 	del     = "delete"
+	schema  = "schema"
+
+	createBatch = "create_batch"
+	updateBatch = "update_batch"
+
+	// table tag of the metrics of schema statements
+	schemaTable = "system_schema"
 
 	// default limit for select statements.
 	_defaultQueryLimit = 1
@@ -95,6 +102,7 @@ func NewCassandraConnector(
 
 // ensure that implementation (cassandraConnector) satisfies the interface
 var _ orm.Connector = (*cassandraConnector)(nil)
+var _ orm.SchemaConnector = (*cassandraConnector)(nil)
 
 // getGocqlErrorTag gets a error tag for metrics based on gocql error
 // We cannot just use err.Error() as a tag because it contains invalid
@@ -492,6 +500,101 @@ func (c *cassandraConnector) GetAllIter(
 	), nil
 }
 
+// CreateBatch creates new rows in DB in a single batch.
+func (c *cassandraConnector) CreateBatch(
+	ctx context.Context,
+	e *base.Definition,
+	rows [][]base.Column,
+) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	b := c.Session.NewBatch(c.batchType()).WithContext(ctx)
+	for _, row := range rows {
+		// split row into a list of names and values to compose query stmt
+		// using names and use values in the batch query call, so the order
+		// needs to be maintained.
+		colNames, colValues := splitColumnNameValue(row)
+
+		// Prepare insert statement
+		stmt, err := InsertStmt(
+			Table(e.Name),
+			Columns(colNames),
+			Values(colValues),
+			IfNotExist(!useCasWrite),
+		)
+		if err != nil {
+			return err
+		}
+		b.Query(stmt, colValues...)
+	}
+
+	return c.executeBatch(e, b, createBatch)
+}
+
+// UpdateBatch updates existing rows in DB in a single batch.
+func (c *cassandraConnector) UpdateBatch(
+	ctx context.Context,
+	e *base.Definition,
+	rows [][]base.Column,
+	keyRows [][]base.Column,
+) error {
+	if len(rows) != len(keyRows) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"%d rows to update for %d keys", len(rows), len(keyRows))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	b := c.Session.NewBatch(c.batchType()).WithContext(ctx)
+	for i, row := range rows {
+		// split keyCols and row into a list of names and values to compose
+		// query stmt using names and use values in the batch query call, so
+		// the order needs to be maintained.
+		keyColNames, keyColValues := splitColumnNameValue(keyRows[i])
+		colNames, colValues := splitColumnNameValue(row)
+
+		// Prepare update statement
+		stmt, err := UpdateStmt(
+			Table(e.Name),
+			Updates(colNames),
+			Conditions(keyColNames),
+		)
+		if err != nil {
+			return err
+		}
+		b.Query(stmt, append(colValues, keyColValues...)...)
+	}
+
+	return c.executeBatch(e, b, updateBatch)
+}
+
+// batchType returns the type of the batches of writes as per the config
+func (c *cassandraConnector) batchType() gocql.BatchType {
+	if c.Conf.LoggedBatches {
+		return gocql.LoggedBatch
+	}
+	return gocql.UnloggedBatch
+}
+
+// executeBatch executes a batch of writes to a table
+func (c *cassandraConnector) executeBatch(
+	e *base.Definition,
+	b *gocql.Batch,
+	operation string,
+) error {
+	if err := c.Session.ExecuteBatch(b); err != nil {
+		sendCounters(c.executeFailScope, e.Name, operation, err)
+		return err
+	}
+
+	sendLatency(c.scope, e.Name, operation, time.Duration(b.Latency()))
+	sendCounters(c.executeSuccessScope, e.Name, operation, nil)
+	return nil
+}
+
 // Delete deletes a record from DB using primary keys
 func (c *cassandraConnector) Delete(
 	ctx context.Context,
@@ -570,6 +673,48 @@ func (c *cassandraConnector) Update(
 	return nil
 }
 
+// GetColumnTypes returns the CQL type of each column of a table of the
+// keyspace keyed by column name, or an empty map if the table doesn't exist
+func (c *cassandraConnector) GetColumnTypes(
+	ctx context.Context,
+	table string,
+) (map[string]string, error) {
+	q := c.Session.Query(
+		"SELECT column_name, type FROM system_schema.columns "+
+			"WHERE keyspace_name = ? AND table_name = ?;",
+		c.Conf.StoreName, table).WithContext(ctx)
+
+	columnTypes := make(map[string]string)
+	var columnName, columnType string
+	iter := q.Iter()
+	for iter.Scan(&columnName, &columnType) {
+		columnTypes[columnName] = columnType
+	}
+	if err := iter.Close(); err != nil {
+		sendCounters(c.executeFailScope, table, schema, err)
+		return nil, err
+	}
+
+	sendCounters(c.executeSuccessScope, table, schema, nil)
+	return columnTypes, nil
+}
+
+// ExecuteSchemaStmt executes a statement altering the schema of the
+// keyspace
+func (c *cassandraConnector) ExecuteSchemaStmt(
+	ctx context.Context,
+	stmt string,
+) error {
+	q := c.Session.Query(stmt).WithContext(ctx)
+	if err := q.Exec(); err != nil {
+		sendCounters(c.executeFailScope, schemaTable, schema, err)
+		return err
+	}
+
+	sendCounters(c.executeSuccessScope, schemaTable, schema, nil)
+	return nil
+}
+
 // cassandraIterator implements interface Iterator for Cassandra
 type cassandraIterator struct {
 	cqlIter        *gocql.Iter
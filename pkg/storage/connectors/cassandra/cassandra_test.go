@@ -341,6 +341,64 @@ func (suite *CassandraConnSuite) TestCreateGetAll() {
 	}
 }
 
+// TestCreateUpdateBatch tests the CreateBatch and UpdateBatch operations
+func (suite *CassandraConnSuite) TestCreateUpdateBatch() {
+	// Definition stores schema information about an Object
+	obj := &base.Definition{
+		Name: testTableName2,
+		Key: &base.PrimaryKey{
+			PartitionKeys: []string{"id"},
+			ClusteringKeys: []*base.ClusteringKey{
+				{
+					Name:       "ck",
+					Descending: true,
+				},
+			},
+		},
+		// Column name to data type mapping of the object
+		ColumnToType: map[string]reflect.Type{
+			"id":   reflect.TypeOf(1),
+			"ck":   reflect.TypeOf(1),
+			"data": reflect.TypeOf("data"),
+			"name": reflect.TypeOf("name"),
+		},
+	}
+
+	// create the test rows in C* in a single batch
+	err := connector.CreateBatch(context.Background(), obj, testRowsWithCK)
+	suite.NoError(err)
+
+	// update the name of both rows in a single batch
+	updateRows := [][]base.Column{
+		{{Name: "name", Value: "test-update"}},
+		{{Name: "name", Value: "test-update"}},
+	}
+	keyRows := [][]base.Column{
+		{{Name: "id", Value: uint64(1)}, {Name: "ck", Value: uint64(10)}},
+		{{Name: "id", Value: uint64(1)}, {Name: "ck", Value: uint64(20)}},
+	}
+	err = connector.UpdateBatch(
+		context.Background(), obj, updateRows, keyRows)
+	suite.NoError(err)
+
+	// read the rows from C* test table for given keys
+	rows, err := connector.GetAll(context.Background(), obj, keyRow)
+	suite.NoError(err)
+	suite.Len(rows, 2)
+	for _, row := range rows {
+		suite.Equal("test-update", row["name"])
+	}
+
+	// mismatched rows and keys
+	err = connector.UpdateBatch(
+		context.Background(), obj, updateRows, keyRows[:1])
+	suite.Error(err)
+
+	// empty batches are a noop
+	suite.NoError(connector.CreateBatch(context.Background(), obj, nil))
+	suite.NoError(connector.UpdateBatch(context.Background(), obj, nil, nil))
+}
+
 // TestCreateGetAllIter tests the GetAllIter operation
 func (suite *CassandraConnSuite) TestCreateGetAllIter() {
 	// Definition stores schema information about an Object
@@ -515,6 +573,15 @@ func (suite *CassandraConnSuite) TestDBFailures() {
 	err = connector.Update(ctx, obj, testRow, keyRow)
 	suite.Error(err)
 
+	// batch create using wrong table name
+	err = connector.CreateBatch(ctx, obj, [][]base.Column{testRow})
+	suite.Error(err)
+
+	// batch update using wrong table name
+	err = connector.UpdateBatch(
+		ctx, obj, [][]base.Column{testRow}, [][]base.Column{keyRow})
+	suite.Error(err)
+
 	// delete using wrong table name
 	err = connector.Delete(ctx, obj, keyRow)
 	suite.Error(err)
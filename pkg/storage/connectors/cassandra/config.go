@@ -46,4 +46,16 @@ type Config struct {
 	CassandraConn *CassandraConn `yaml:"connection"`
 	StoreName     string         `yaml:"store_name"`
 	Migrations    string         `yaml:"migrations"`
+	// JobConfigCacheSize is the maximum number of job config versions
+	// cached in memory by the store. Defaults to 1000 if not set.
+	JobConfigCacheSize int `yaml:"job_config_cache_size"`
+	// AutoMigrateSchema creates the tables of the storage objects and adds
+	// their missing columns when the store is created. The tables created
+	// by the cql migrations are left to them.
+	AutoMigrateSchema bool `yaml:"auto_migrate_schema"`
+	// LoggedBatches makes the batch writes of the storage objects logged,
+	// so that all the writes of a batch are eventually applied even if the
+	// coordinator fails. Batch writes are unlogged by default since they
+	// are for the objects of a single partition.
+	LoggedBatches bool `yaml:"logged_batches"`
 }
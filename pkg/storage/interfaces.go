@@ -78,6 +78,13 @@ type TaskStore interface {
 		instanceID uint32,
 		runtime *task.RuntimeInfo,
 		jobType job.JobType) error
+	// UpdateTaskRuntimes updates the runtimes of multiple tasks of a job
+	// in batched writes
+	UpdateTaskRuntimes(
+		ctx context.Context,
+		jobID *peloton.JobID,
+		runtimes map[uint32]*task.RuntimeInfo,
+		jobType job.JobType) error
 
 	// GetTasksForJob gets the task info for all tasks in a job
 	GetTasksForJob(ctx context.Context, id *peloton.JobID) (map[uint32]*task.TaskInfo, error)
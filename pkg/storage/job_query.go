@@ -0,0 +1,184 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Properties a job query resumed after a cursor can be sorted by
+const (
+	JobQueryCreationTime   = "creation_time"
+	JobQueryStartTime      = "start_time"
+	JobQueryCompletionTime = "completion_time"
+	JobQueryState          = "state"
+	JobQueryOwner          = "owner"
+	JobQueryName           = "name"
+)
+
+// JobQueryOrderBy returns the order of the result of a job query, which
+// defaults to creation time in descending order if the query has none.
+func JobQueryOrderBy(spec *job.QuerySpec) []*query.OrderBy {
+	if orderBy := spec.GetPagination().GetOrderBy(); len(orderBy) > 0 {
+		return orderBy
+	}
+
+	return []*query.OrderBy{
+		{
+			Order: query.OrderBy_DESC,
+			Property: &query.PropertyPath{
+				Value: JobQueryCreationTime,
+			},
+		},
+	}
+}
+
+// IsJobQueryTimeProperty returns true if the job query property is a time.
+func IsJobQueryTimeProperty(property string) bool {
+	switch property {
+	case JobQueryCreationTime, JobQueryStartTime, JobQueryCompletionTime:
+		return true
+	}
+	return false
+}
+
+// JobSummarySortValue returns the value of the property of a job summary
+// a job query is sorted by.
+func JobSummarySortValue(
+	summary *job.JobSummary,
+	property string,
+) (string, error) {
+	switch property {
+	case JobQueryCreationTime:
+		return summary.GetRuntime().GetCreationTime(), nil
+	case JobQueryStartTime:
+		return summary.GetRuntime().GetStartTime(), nil
+	case JobQueryCompletionTime:
+		return summary.GetRuntime().GetCompletionTime(), nil
+	case JobQueryState:
+		return summary.GetRuntime().GetState().String(), nil
+	case JobQueryOwner:
+		return summary.GetOwner(), nil
+	case JobQueryName:
+		return summary.GetName(), nil
+	}
+	return "", yarpcerrors.InvalidArgumentErrorf(
+		"query with a cursor cannot be sorted by %s", property)
+}
+
+// compareJobSortValues compares two values of a property a job query is
+// sorted by. Times are compared at the precision of the job index, which
+// is a second, since that is the precision the jobs are sorted at.
+func compareJobSortValues(property string, a string, b string) int {
+	if !IsJobQueryTimeProperty(property) {
+		return strings.Compare(a, b)
+	}
+
+	// unset times sort before any other time
+	ta, _ := time.Parse(time.RFC3339Nano, a)
+	tb, _ := time.Parse(time.RFC3339Nano, b)
+	ta = ta.Truncate(time.Second)
+	tb = tb.Truncate(time.Second)
+	switch {
+	case ta.Before(tb):
+		return -1
+	case ta.After(tb):
+		return 1
+	}
+	return 0
+}
+
+// CompareJobSummaryToCursor returns a negative number if the job summary
+// sorts before the cursor in a job query sorted by orderBy, zero if it has
+// the same values as the cursor and a positive number if it sorts after.
+func CompareJobSummaryToCursor(
+	summary *job.JobSummary,
+	orderBy []*query.OrderBy,
+	cursor *query.Cursor,
+) (int, error) {
+	if len(cursor.GetValues()) != len(orderBy) {
+		return 0, yarpcerrors.InvalidArgumentErrorf(
+			"cursor does not match the order of the query")
+	}
+
+	for i, order := range orderBy {
+		property := order.GetProperty().GetValue()
+		value, err := JobSummarySortValue(summary, property)
+		if err != nil {
+			return 0, err
+		}
+
+		cmp := compareJobSortValues(property, value, cursor.GetValues()[i])
+		if order.GetOrder() == query.OrderBy_DESC {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp, nil
+		}
+	}
+	return 0, nil
+}
+
+// NewJobQueryCursor returns the cursor of the last of the job summaries of
+// a page of a job query sorted by orderBy. The previous cursor the page was
+// resumed after, if any, carries the keys of the jobs of the previous pages
+// which have the same values as the last job.
+func NewJobQueryCursor(
+	summaries []*job.JobSummary,
+	orderBy []*query.OrderBy,
+	prev *query.Cursor,
+) (*query.Cursor, error) {
+	if len(summaries) == 0 {
+		return prev, nil
+	}
+
+	last := summaries[len(summaries)-1]
+	cursor := &query.Cursor{}
+	for _, order := range orderBy {
+		value, err := JobSummarySortValue(last, order.GetProperty().GetValue())
+		if err != nil {
+			return nil, err
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+
+	// collect the keys of the trailing jobs with the same values
+	i := len(summaries) - 1
+	for ; i >= 0; i-- {
+		cmp, err := CompareJobSummaryToCursor(summaries[i], orderBy, cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cmp != 0 {
+			break
+		}
+		cursor.Keys = append(cursor.Keys, summaries[i].GetId().GetValue())
+	}
+
+	// all the jobs of the page have the same values as the previous cursor
+	if i < 0 && prev != nil {
+		if cmp, err := CompareJobSummaryToCursor(
+			last, orderBy, prev); err == nil && cmp == 0 {
+			cursor.Keys = append(cursor.Keys, prev.GetKeys()...)
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJobSummary(
+	id string,
+	owner string,
+	creationTime time.Time,
+) *job.JobSummary {
+	return &job.JobSummary{
+		Id:    &peloton.JobID{Value: id},
+		Owner: owner,
+		Runtime: &job.RuntimeInfo{
+			CreationTime: creationTime.Format(time.RFC3339Nano),
+		},
+	}
+}
+
+// TestJobQueryOrderBy tests the default order of a job query
+func TestJobQueryOrderBy(t *testing.T) {
+	orderBy := JobQueryOrderBy(&job.QuerySpec{})
+	require.Len(t, orderBy, 1)
+	assert.Equal(t, JobQueryCreationTime, orderBy[0].GetProperty().GetValue())
+	assert.Equal(t, query.OrderBy_DESC, orderBy[0].GetOrder())
+}
+
+// TestCompareJobSummaryToCursor tests comparing jobs to a cursor on
+// multiple sort properties
+func TestCompareJobSummaryToCursor(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	orderBy := []*query.OrderBy{
+		{
+			Order:    query.OrderBy_ASC,
+			Property: &query.PropertyPath{Value: JobQueryOwner},
+		},
+		{
+			Order:    query.OrderBy_DESC,
+			Property: &query.PropertyPath{Value: JobQueryCreationTime},
+		},
+	}
+	cursor := &query.Cursor{
+		Values: []string{"owner2", now.Format(time.RFC3339Nano)},
+	}
+
+	tt := []struct {
+		summary  *job.JobSummary
+		expected int
+	}{
+		{newTestJobSummary("job1", "owner1", now), -1},
+		{newTestJobSummary("job2", "owner2", now.Add(time.Minute)), -1},
+		// times are compared at the precision of the job index
+		{newTestJobSummary("job3", "owner2", now.Add(time.Millisecond)), 0},
+		{newTestJobSummary("job4", "owner2", now.Add(-time.Minute)), 1},
+		{newTestJobSummary("job5", "owner3", now.Add(time.Minute)), 1},
+	}
+	for _, test := range tt {
+		cmp, err := CompareJobSummaryToCursor(test.summary, orderBy, cursor)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, cmp, test.summary.GetId().GetValue())
+	}
+
+	// cursor does not match the order
+	_, err := CompareJobSummaryToCursor(
+		tt[0].summary, orderBy[:1], cursor)
+	assert.Error(t, err)
+
+	// unsupported sort property
+	_, err = CompareJobSummaryToCursor(
+		tt[0].summary,
+		[]*query.OrderBy{{Property: &query.PropertyPath{Value: "labels"}}},
+		&query.Cursor{Values: []string{""}},
+	)
+	assert.Error(t, err)
+}
+
+// TestNewJobQueryCursor tests the cursor of a page carries the keys of
+// all the jobs returned with the same values as the last one
+func TestNewJobQueryCursor(t *testing.T) {
+	now := time.Now().UTC()
+	orderBy := []*query.OrderBy{
+		{
+			Order:    query.OrderBy_ASC,
+			Property: &query.PropertyPath{Value: JobQueryOwner},
+		},
+	}
+
+	cursor, err := NewJobQueryCursor([]*job.JobSummary{
+		newTestJobSummary("job1", "owner1", now),
+		newTestJobSummary("job2", "owner2", now),
+		newTestJobSummary("job3", "owner2", now),
+	}, orderBy, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"owner2"}, cursor.GetValues())
+	assert.Equal(t, []string{"job3", "job2"}, cursor.GetKeys())
+
+	// the next page has only jobs with the same values
+	next, err := NewJobQueryCursor([]*job.JobSummary{
+		newTestJobSummary("job4", "owner2", now),
+	}, orderBy, cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"owner2"}, next.GetValues())
+	assert.Equal(t, []string{"job4", "job3", "job2"}, next.GetKeys())
+
+	// an empty page keeps the previous cursor
+	next, err = NewJobQueryCursor(nil, orderBy, cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, next)
+}
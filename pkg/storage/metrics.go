@@ -94,6 +94,8 @@ type OrmJobMetrics struct {
 	JobConfigGetFail    tally.Counter
 	JobConfigDelete     tally.Counter
 	JobConfigDeleteFail tally.Counter
+	JobConfigCacheHit   tally.Counter
+	JobConfigCacheMiss  tally.Counter
 
 	// active_jobs.
 	ActiveJobsCreate         tally.Counter
@@ -304,6 +306,9 @@ type OrmTaskMetrics struct {
 	PodEventsGet     tally.Counter
 	PodEventsGetFail tally.Counter
 
+	PodEventsCompact     tally.Counter
+	PodEventsCompactFail tally.Counter
+
 	TaskConfigV2Create     tally.Counter
 	TaskConfigV2CreateFail tally.Counter
 
@@ -315,6 +320,13 @@ type OrmTaskMetrics struct {
 
 	PodSpecGet     tally.Counter
 	PodSpecGetFail tally.Counter
+
+	InstanceOverrideCreate     tally.Counter
+	InstanceOverrideCreateFail tally.Counter
+	InstanceOverrideGet        tally.Counter
+	InstanceOverrideGetFail    tally.Counter
+	InstanceOverrideDelete     tally.Counter
+	InstanceOverrideDeleteFail tally.Counter
 }
 
 // OrmHostInfoMetrics tracks counters for host info related table
@@ -355,22 +367,49 @@ type OrmJobUpdateEventsMetrics struct {
 	JobUpdateEventsDeleteFail tally.Counter
 }
 
+// OrmClusterFreezeMetrics tracks counters for cluster freeze table
+type OrmClusterFreezeMetrics struct {
+	ClusterFreezeGet     tally.Counter
+	ClusterFreezeGetFail tally.Counter
+	ClusterFreezeSet     tally.Counter
+	ClusterFreezeSetFail tally.Counter
+}
+
+// OrmEventStreamCursorMetrics tracks counters for event stream cursors table
+type OrmEventStreamCursorMetrics struct {
+	EventStreamCursorCreate     tally.Counter
+	EventStreamCursorCreateFail tally.Counter
+	EventStreamCursorGet        tally.Counter
+	EventStreamCursorGetFail    tally.Counter
+}
+
+// OrmQueueSnapshotMetrics tracks counters for resmgr queue snapshots table
+type OrmQueueSnapshotMetrics struct {
+	QueueSnapshotCreate     tally.Counter
+	QueueSnapshotCreateFail tally.Counter
+	QueueSnapshotGet        tally.Counter
+	QueueSnapshotGetFail    tally.Counter
+}
+
 // Metrics is a struct for tracking all the general purpose counters that have relevance to the storage
 // layer, i.e. how many jobs and tasks were created/deleted in the storage layer
 type Metrics struct {
-	JobMetrics                *JobMetrics
-	TaskMetrics               *TaskMetrics
-	UpdateMetrics             *UpdateMetrics
-	ResourcePoolMetrics       *ResourcePoolMetrics
-	FrameworkStoreMetrics     *FrameworkStoreMetrics
-	VolumeMetrics             *VolumeMetrics
-	ErrorMetrics              *ErrorMetrics
-	WorkflowMetrics           *WorkflowMetrics
-	OrmJobMetrics             *OrmJobMetrics
-	OrmRespoolMetrics         *OrmRespoolMetrics
-	OrmTaskMetrics            *OrmTaskMetrics
-	OrmHostInfoMetrics        *OrmHostInfoMetrics
-	OrmJobUpdateEventsMetrics *OrmJobUpdateEventsMetrics
+	JobMetrics                  *JobMetrics
+	TaskMetrics                 *TaskMetrics
+	UpdateMetrics               *UpdateMetrics
+	ResourcePoolMetrics         *ResourcePoolMetrics
+	FrameworkStoreMetrics       *FrameworkStoreMetrics
+	VolumeMetrics               *VolumeMetrics
+	ErrorMetrics                *ErrorMetrics
+	WorkflowMetrics             *WorkflowMetrics
+	OrmJobMetrics               *OrmJobMetrics
+	OrmRespoolMetrics           *OrmRespoolMetrics
+	OrmTaskMetrics              *OrmTaskMetrics
+	OrmHostInfoMetrics          *OrmHostInfoMetrics
+	OrmJobUpdateEventsMetrics   *OrmJobUpdateEventsMetrics
+	OrmClusterFreezeMetrics     *OrmClusterFreezeMetrics
+	OrmEventStreamCursorMetrics *OrmEventStreamCursorMetrics
+	OrmQueueSnapshotMetrics     *OrmQueueSnapshotMetrics
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized and rooted at the given tally.Scope
@@ -641,6 +680,30 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	podSpecFailScope := podSpecScope.Tagged(
 		map[string]string{"result": "fail"})
 
+	instanceOverrideScope := ormScope.SubScope("instance_override")
+	instanceOverrideSuccessScope := instanceOverrideScope.Tagged(
+		map[string]string{"result": "success"})
+	instanceOverrideFailScope := instanceOverrideScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	clusterFreezeScope := ormScope.SubScope("cluster_freeze")
+	clusterFreezeSuccessScope := clusterFreezeScope.Tagged(
+		map[string]string{"result": "success"})
+	clusterFreezeFailScope := clusterFreezeScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	eventStreamCursorScope := ormScope.SubScope("event_stream_cursor")
+	eventStreamCursorSuccessScope := eventStreamCursorScope.Tagged(
+		map[string]string{"result": "success"})
+	eventStreamCursorFailScope := eventStreamCursorScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	queueSnapshotScope := ormScope.SubScope("queue_snapshot")
+	queueSnapshotSuccessScope := queueSnapshotScope.Tagged(
+		map[string]string{"result": "success"})
+	queueSnapshotFailScope := queueSnapshotScope.Tagged(
+		map[string]string{"result": "fail"})
+
 	respoolScope := ormScope.SubScope("respool")
 	respoolSuccessScope := respoolScope.Tagged(
 		map[string]string{"result": "success"})
@@ -682,6 +745,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		JobConfigGetFail:    jobConfigFailScope.Counter("get"),
 		JobConfigDelete:     jobConfigSuccessScope.Counter("delete"),
 		JobConfigDeleteFail: jobConfigFailScope.Counter("delete"),
+		JobConfigCacheHit:   jobConfigSuccessScope.Counter("cache_hit"),
+		JobConfigCacheMiss:  jobConfigSuccessScope.Counter("cache_miss"),
 
 		ActiveJobsCreate:         activeJobsSuccessScope.Counter("create"),
 		ActiveJobsCreateFail:     activeJobsFailScope.Counter("create"),
@@ -720,6 +785,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		PodEventsGet:     podEventsSuccessScope.Counter("get"),
 		PodEventsGetFail: podEventsFailScope.Counter("get"),
 
+		PodEventsCompact:     podEventsSuccessScope.Counter("compact"),
+		PodEventsCompactFail: podEventsFailScope.Counter("compact"),
+
 		TaskConfigV2Create:     taskConfigV2SuccessScope.Counter("create"),
 		TaskConfigV2CreateFail: taskConfigV2FailScope.Counter("create"),
 
@@ -731,6 +799,13 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		PodSpecGet:     podSpecSuccessScope.Counter("get"),
 		PodSpecGetFail: podSpecFailScope.Counter("get"),
+
+		InstanceOverrideCreate:     instanceOverrideSuccessScope.Counter("create"),
+		InstanceOverrideCreateFail: instanceOverrideFailScope.Counter("create"),
+		InstanceOverrideGet:        instanceOverrideSuccessScope.Counter("get"),
+		InstanceOverrideGetFail:    instanceOverrideFailScope.Counter("get"),
+		InstanceOverrideDelete:     instanceOverrideSuccessScope.Counter("delete"),
+		InstanceOverrideDeleteFail: instanceOverrideFailScope.Counter("delete"),
 	}
 
 	ormHostInfoMetrics := &OrmHostInfoMetrics{
@@ -761,20 +836,44 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		JobUpdateEventsDeleteFail: jobUpdateEventsFailScope.Counter("delete"),
 	}
 
+	ormClusterFreezeMetrics := &OrmClusterFreezeMetrics{
+		ClusterFreezeGet:     clusterFreezeSuccessScope.Counter("get"),
+		ClusterFreezeGetFail: clusterFreezeFailScope.Counter("get"),
+		ClusterFreezeSet:     clusterFreezeSuccessScope.Counter("set"),
+		ClusterFreezeSetFail: clusterFreezeFailScope.Counter("set"),
+	}
+
+	ormEventStreamCursorMetrics := &OrmEventStreamCursorMetrics{
+		EventStreamCursorCreate:     eventStreamCursorSuccessScope.Counter("create"),
+		EventStreamCursorCreateFail: eventStreamCursorFailScope.Counter("create"),
+		EventStreamCursorGet:        eventStreamCursorSuccessScope.Counter("get"),
+		EventStreamCursorGetFail:    eventStreamCursorFailScope.Counter("get"),
+	}
+
+	ormQueueSnapshotMetrics := &OrmQueueSnapshotMetrics{
+		QueueSnapshotCreate:     queueSnapshotSuccessScope.Counter("create"),
+		QueueSnapshotCreateFail: queueSnapshotFailScope.Counter("create"),
+		QueueSnapshotGet:        queueSnapshotSuccessScope.Counter("get"),
+		QueueSnapshotGetFail:    queueSnapshotFailScope.Counter("get"),
+	}
+
 	metrics := &Metrics{
-		JobMetrics:                jobMetrics,
-		TaskMetrics:               taskMetrics,
-		UpdateMetrics:             updateMetrics,
-		ResourcePoolMetrics:       resourcePoolMetrics,
-		FrameworkStoreMetrics:     frameworkStoreMetrics,
-		VolumeMetrics:             volumeMetrics,
-		ErrorMetrics:              errorMetrics,
-		WorkflowMetrics:           workflowMetrics,
-		OrmJobMetrics:             ormJobMetrics,
-		OrmRespoolMetrics:         ormRespoolMetrics,
-		OrmTaskMetrics:            ormTaskMetrics,
-		OrmJobUpdateEventsMetrics: ormJobUpdateEventsMetrics,
-		OrmHostInfoMetrics:        ormHostInfoMetrics,
+		JobMetrics:                  jobMetrics,
+		TaskMetrics:                 taskMetrics,
+		UpdateMetrics:               updateMetrics,
+		ResourcePoolMetrics:         resourcePoolMetrics,
+		FrameworkStoreMetrics:       frameworkStoreMetrics,
+		VolumeMetrics:               volumeMetrics,
+		ErrorMetrics:                errorMetrics,
+		WorkflowMetrics:             workflowMetrics,
+		OrmJobMetrics:               ormJobMetrics,
+		OrmRespoolMetrics:           ormRespoolMetrics,
+		OrmTaskMetrics:              ormTaskMetrics,
+		OrmJobUpdateEventsMetrics:   ormJobUpdateEventsMetrics,
+		OrmHostInfoMetrics:          ormHostInfoMetrics,
+		OrmClusterFreezeMetrics:     ormClusterFreezeMetrics,
+		OrmEventStreamCursorMetrics: ormEventStreamCursorMetrics,
+		OrmQueueSnapshotMetrics:     ormQueueSnapshotMetrics,
 	}
 
 	return metrics
@@ -26,6 +26,24 @@ type Definition struct {
 	Key *PrimaryKey
 	// Column name to data type mapping of the object
 	ColumnToType map[string]reflect.Type
+	// Secondary indexes and materialized views of the object
+	Indexes []*Index
+}
+
+// Index stores information about a secondary index or a materialized view
+// which allows looking up objects by columns other than the primary key
+type Index struct {
+	// Name of the secondary index or materialized view
+	Name string
+	// List of column names the objects are looked up by
+	Columns []string
+	// Primary key of the materialized view, nil for a secondary index
+	Key *PrimaryKey
+}
+
+// IsView returns true if the index is a materialized view
+func (i *Index) IsView() bool {
+	return i.Key != nil
 }
 
 // Column holds a column name and value for one row.
@@ -82,6 +100,18 @@ func (o *Definition) GetColumnsToRead() []string {
 // The `cassandra` keyword denotes that this annotation is for Cassandra
 // connector. The only primary key format supported right now is:
 // ((PK1,PK2..), CK1, CK2..)
+//
+// Secondary indexes and materialized views of the object are declared with
+// the `index` and `view` keywords, multiple declarations being separated by
+// a semicolon. For example, adding the following annotations to the
+// base.Object field of ValidObject:
+//
+//	index:"name=data_idx, columns=(data)"
+//	view:"name=valid_object_by_data, primaryKey=((data), id, name)"
+//
+// objects can be looked up by `data` using the secondary index
+// `data_idx` or the materialized view `valid_object_by_data`, whose primary
+// key must contain all the primary key columns of the object.
 type Object interface {
 	// transform will convert all the value from DB into the corresponding type
 	// in ORM object to be interpreted by base store client
@@ -0,0 +1,126 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+// clusterFreezeID is the key of the single row in cluster_freeze table.
+const clusterFreezeID = "cluster"
+
+// init adds a ClusterFreezeObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &ClusterFreezeObject{})
+}
+
+// ClusterFreezeObject corresponds to the row in cluster_freeze table.
+// While the cluster is frozen, the daemons halt non-essential automatic
+// actions like task restarts, updates and preemptions.
+type ClusterFreezeObject struct {
+	// base.Object DB specific annotations
+	base.Object `cassandra:"name=cluster_freeze, primaryKey=((id))"`
+	// ID of the row, always clusterFreezeID
+	ID string `column:"name=id"`
+	// Frozen is set when the cluster is frozen
+	Frozen bool `column:"name=frozen"`
+	// Reason the cluster was frozen
+	Reason string `column:"name=reason"`
+	// Owner is the operator who last changed the freeze state
+	Owner string `column:"name=owner"`
+	// UpdateTime of the freeze state
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *ClusterFreezeObject) transform(row map[string]interface{}) {
+	o.ID = row["id"].(string)
+	o.Frozen = row["frozen"].(bool)
+	o.Reason = row["reason"].(string)
+	o.Owner = row["owner"].(string)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// ClusterFreezeOps provides methods for manipulating cluster_freeze table.
+type ClusterFreezeOps interface {
+	// Get returns the freeze state of the cluster. The cluster is not
+	// frozen if the freeze state has never been set.
+	Get(ctx context.Context) (*ClusterFreezeObject, error)
+
+	// Set sets the freeze state of the cluster.
+	Set(ctx context.Context, frozen bool, reason string, owner string) error
+}
+
+// ensure that default implementation (clusterFreezeOps) satisfies
+// the interface
+var _ ClusterFreezeOps = (*clusterFreezeOps)(nil)
+
+// clusterFreezeOps implements ClusterFreezeOps using a particular Store
+type clusterFreezeOps struct {
+	store *Store
+}
+
+// NewClusterFreezeOps constructs a ClusterFreezeOps object for
+// provided Store.
+func NewClusterFreezeOps(s *Store) ClusterFreezeOps {
+	return &clusterFreezeOps{store: s}
+}
+
+// Get returns the freeze state of the cluster.
+func (d *clusterFreezeOps) Get(ctx context.Context) (*ClusterFreezeObject, error) {
+	obj := &ClusterFreezeObject{ID: clusterFreezeID}
+
+	row, err := d.store.oClient.Get(ctx, obj)
+	if err != nil {
+		d.store.metrics.OrmClusterFreezeMetrics.ClusterFreezeGetFail.Inc(1)
+		return nil, err
+	}
+
+	if len(row) != 0 {
+		obj.transform(row)
+	}
+
+	d.store.metrics.OrmClusterFreezeMetrics.ClusterFreezeGet.Inc(1)
+	return obj, nil
+}
+
+// Set sets the freeze state of the cluster.
+func (d *clusterFreezeOps) Set(
+	ctx context.Context,
+	frozen bool,
+	reason string,
+	owner string,
+) error {
+	obj := &ClusterFreezeObject{
+		ID:         clusterFreezeID,
+		Frozen:     frozen,
+		Reason:     reason,
+		Owner:      owner,
+		UpdateTime: time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmClusterFreezeMetrics.ClusterFreezeSetFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmClusterFreezeMetrics.ClusterFreezeSet.Inc(1)
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClusterFreezeTestSuite struct {
+	suite.Suite
+}
+
+func TestClusterFreezeSuite(t *testing.T) {
+	suite.Run(t, new(ClusterFreezeTestSuite))
+}
+
+func (s *ClusterFreezeTestSuite) SetupTest() {
+	setupTestStore()
+}
+
+// TestSetGetClusterFreeze tests setting and getting the freeze state
+// of the cluster.
+func (s *ClusterFreezeTestSuite) TestSetGetClusterFreeze() {
+	ops := NewClusterFreezeOps(testStore)
+	ctx := context.Background()
+
+	s.NoError(ops.Set(ctx, true, "incident", "operator"))
+
+	obj, err := ops.Get(ctx)
+	s.NoError(err)
+	s.True(obj.Frozen)
+	s.Equal("incident", obj.Reason)
+	s.Equal("operator", obj.Owner)
+	s.False(obj.UpdateTime.IsZero())
+
+	s.NoError(ops.Set(ctx, false, "", "operator"))
+
+	obj, err = ops.Get(ctx)
+	s.NoError(err)
+	s.False(obj.Frozen)
+}
+
+// TestClusterFreezeOpsClientFail tests failure cases due to ORM
+// Client errors.
+func (s *ClusterFreezeTestSuite) TestClusterFreezeOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewClusterFreezeOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+
+	ctx := context.Background()
+
+	err := ops.Set(ctx, true, "incident", "operator")
+	s.Equal("create failed", err.Error())
+
+	_, err = ops.Get(ctx)
+	s.Equal("get failed", err.Error())
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+// init adds a EventStreamCursorObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &EventStreamCursorObject{})
+}
+
+// EventStreamCursorObject corresponds to a row in event_stream_cursors
+// table, which is the purge offset of a client of an event stream.
+type EventStreamCursorObject struct {
+	// base.Object DB specific annotations
+	base.Object `cassandra:"name=event_stream_cursors, primaryKey=((stream_name), client_name)"`
+	// StreamName is the name of the event stream
+	StreamName string `column:"name=stream_name"`
+	// ClientName is the name of the client consuming the stream
+	ClientName string `column:"name=client_name"`
+	// PurgeOffset is the offset up to which the client consumed the stream
+	PurgeOffset uint64 `column:"name=purge_offset"`
+	// UpdateTime of the purge offset
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *EventStreamCursorObject) transform(row map[string]interface{}) {
+	o.StreamName = row["stream_name"].(string)
+	o.ClientName = row["client_name"].(string)
+	o.PurgeOffset = row["purge_offset"].(uint64)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// EventStreamCursorOps provides methods for manipulating
+// event_stream_cursors table.
+type EventStreamCursorOps interface {
+	// Create creates or replaces the purge offset of a client of a stream.
+	Create(
+		ctx context.Context,
+		streamName string,
+		clientName string,
+		purgeOffset uint64,
+	) error
+
+	// GetAll returns the purge offset of each client of a stream, keyed
+	// by client name.
+	GetAll(ctx context.Context, streamName string) (map[string]uint64, error)
+}
+
+// ensure that default implementation (eventStreamCursorOps) satisfies
+// the interface
+var _ EventStreamCursorOps = (*eventStreamCursorOps)(nil)
+
+// eventStreamCursorOps implements EventStreamCursorOps using a particular
+// Store
+type eventStreamCursorOps struct {
+	store *Store
+}
+
+// NewEventStreamCursorOps constructs a EventStreamCursorOps object for
+// provided Store.
+func NewEventStreamCursorOps(s *Store) EventStreamCursorOps {
+	return &eventStreamCursorOps{store: s}
+}
+
+// Create creates or replaces the purge offset of a client of a stream.
+func (d *eventStreamCursorOps) Create(
+	ctx context.Context,
+	streamName string,
+	clientName string,
+	purgeOffset uint64,
+) error {
+	obj := &EventStreamCursorObject{
+		StreamName:  streamName,
+		ClientName:  clientName,
+		PurgeOffset: purgeOffset,
+		UpdateTime:  time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmEventStreamCursorMetrics.EventStreamCursorCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmEventStreamCursorMetrics.EventStreamCursorCreate.Inc(1)
+	return nil
+}
+
+// GetAll returns the purge offset of each client of a stream.
+func (d *eventStreamCursorOps) GetAll(
+	ctx context.Context,
+	streamName string,
+) (map[string]uint64, error) {
+	rows, err := d.store.oClient.GetAll(
+		ctx,
+		&EventStreamCursorObject{StreamName: streamName})
+	if err != nil {
+		d.store.metrics.OrmEventStreamCursorMetrics.EventStreamCursorGetFail.Inc(1)
+		return nil, err
+	}
+
+	result := make(map[string]uint64)
+	for _, row := range rows {
+		obj := &EventStreamCursorObject{}
+		obj.transform(row)
+		result[obj.ClientName] = obj.PurgeOffset
+	}
+
+	d.store.metrics.OrmEventStreamCursorMetrics.EventStreamCursorGet.Inc(1)
+	return result, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventStreamCursorTestSuite struct {
+	suite.Suite
+}
+
+func TestEventStreamCursorSuite(t *testing.T) {
+	suite.Run(t, new(EventStreamCursorTestSuite))
+}
+
+func (s *EventStreamCursorTestSuite) SetupTest() {
+	setupTestStore()
+}
+
+// TestCreateGetAllEventStreamCursors tests creating and getting the
+// cursors of the clients of a stream.
+func (s *EventStreamCursorTestSuite) TestCreateGetAllEventStreamCursors() {
+	ops := NewEventStreamCursorOps(testStore)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, "stream1", "client1", 10))
+	s.NoError(ops.Create(ctx, "stream1", "client2", 20))
+	s.NoError(ops.Create(ctx, "stream2", "client1", 30))
+
+	// Creating the cursor of a client again replaces it.
+	s.NoError(ops.Create(ctx, "stream1", "client1", 15))
+
+	cursors, err := ops.GetAll(ctx, "stream1")
+	s.NoError(err)
+	s.Equal(map[string]uint64{"client1": 15, "client2": 20}, cursors)
+
+	cursors, err = ops.GetAll(ctx, "unknown")
+	s.NoError(err)
+	s.Empty(cursors)
+}
+
+// TestEventStreamCursorOpsClientFail tests failure cases due to ORM
+// Client errors.
+func (s *EventStreamCursorTestSuite) TestEventStreamCursorOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewEventStreamCursorOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	ctx := context.Background()
+
+	err := ops.Create(ctx, "stream", "client", 10)
+	s.Equal("create failed", err.Error())
+
+	_, err = ops.GetAll(ctx, "stream")
+	s.Equal("getall failed", err.Error())
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// init adds a InstanceOverrideObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &InstanceOverrideObject{})
+}
+
+// InstanceOverrideObject corresponds to a row in instance_overrides table.
+// An instance override is a partial task config which is merged on top of
+// the task config of an instance, without bumping the job config version.
+type InstanceOverrideObject struct {
+	// base.Object DB specific annotations
+	base.Object `cassandra:"name=instance_overrides, primaryKey=((job_id), instance_id)"`
+	// JobID of the job which the instance belongs to (uuid)
+	JobID string `column:"name=job_id"`
+	// InstanceID of the overridden instance
+	InstanceID uint32 `column:"name=instance_id"`
+	// Override is the serialized partial task config
+	Override []byte `column:"name=override"`
+	// UpdateTime of the override
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *InstanceOverrideObject) transform(row map[string]interface{}) {
+	o.JobID = row["job_id"].(string)
+	o.InstanceID = row["instance_id"].(uint32)
+	o.Override = row["override"].([]byte)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// InstanceOverrideOps provides methods for manipulating
+// instance_overrides table.
+type InstanceOverrideOps interface {
+	// Create creates or replaces the override of an instance.
+	Create(
+		ctx context.Context,
+		id *peloton.JobID,
+		instanceID uint32,
+		override *pbtask.TaskConfig,
+	) error
+
+	// Get returns the override of an instance, NotFound error is
+	// returned if the instance has no override.
+	Get(
+		ctx context.Context,
+		id *peloton.JobID,
+		instanceID uint32,
+	) (*pbtask.TaskConfig, error)
+
+	// GetAll returns the overrides of all the instances of a job.
+	GetAll(
+		ctx context.Context,
+		id *peloton.JobID,
+	) (map[uint32]*pbtask.TaskConfig, error)
+
+	// Delete removes the override of an instance.
+	Delete(ctx context.Context, id *peloton.JobID, instanceID uint32) error
+}
+
+// ensure that default implementation (instanceOverrideOps) satisfies
+// the interface
+var _ InstanceOverrideOps = (*instanceOverrideOps)(nil)
+
+// instanceOverrideOps implements InstanceOverrideOps using a
+// particular Store
+type instanceOverrideOps struct {
+	store *Store
+}
+
+// NewInstanceOverrideOps constructs a InstanceOverrideOps object for
+// provided Store.
+func NewInstanceOverrideOps(s *Store) InstanceOverrideOps {
+	return &instanceOverrideOps{store: s}
+}
+
+// Create creates or replaces the override of an instance.
+func (d *instanceOverrideOps) Create(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID uint32,
+	override *pbtask.TaskConfig,
+) error {
+	overrideBuffer, err := proto.Marshal(override)
+	if err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideCreateFail.Inc(1)
+		return errors.Wrap(err, "failed to marshal instance override")
+	}
+
+	obj := &InstanceOverrideObject{
+		JobID:      id.GetValue(),
+		InstanceID: instanceID,
+		Override:   overrideBuffer,
+		UpdateTime: time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmTaskMetrics.InstanceOverrideCreate.Inc(1)
+	return nil
+}
+
+// Get returns the override of an instance.
+func (d *instanceOverrideOps) Get(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID uint32,
+) (*pbtask.TaskConfig, error) {
+	obj := &InstanceOverrideObject{
+		JobID:      id.GetValue(),
+		InstanceID: instanceID,
+	}
+
+	row, err := d.store.oClient.Get(ctx, obj)
+	if err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideGetFail.Inc(1)
+		return nil, err
+	}
+
+	if len(row) == 0 {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"instance override not found for job %s instance %d",
+			id.GetValue(), instanceID)
+	}
+
+	obj.transform(row)
+	override, err := unmarshalInstanceOverride(obj.Override)
+	if err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideGetFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmTaskMetrics.InstanceOverrideGet.Inc(1)
+	return override, nil
+}
+
+// GetAll returns the overrides of all the instances of a job.
+func (d *instanceOverrideOps) GetAll(
+	ctx context.Context,
+	id *peloton.JobID,
+) (map[uint32]*pbtask.TaskConfig, error) {
+	rows, err := d.store.oClient.GetAll(
+		ctx,
+		&InstanceOverrideObject{JobID: id.GetValue()})
+	if err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideGetFail.Inc(1)
+		return nil, err
+	}
+
+	result := make(map[uint32]*pbtask.TaskConfig)
+	for _, row := range rows {
+		obj := &InstanceOverrideObject{}
+		obj.transform(row)
+
+		override, err := unmarshalInstanceOverride(obj.Override)
+		if err != nil {
+			d.store.metrics.OrmTaskMetrics.InstanceOverrideGetFail.Inc(1)
+			return nil, err
+		}
+		result[obj.InstanceID] = override
+	}
+
+	d.store.metrics.OrmTaskMetrics.InstanceOverrideGet.Inc(1)
+	return result, nil
+}
+
+// Delete removes the override of an instance.
+func (d *instanceOverrideOps) Delete(
+	ctx context.Context,
+	id *peloton.JobID,
+	instanceID uint32,
+) error {
+	obj := &InstanceOverrideObject{
+		JobID:      id.GetValue(),
+		InstanceID: instanceID,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmTaskMetrics.InstanceOverrideDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmTaskMetrics.InstanceOverrideDelete.Inc(1)
+	return nil
+}
+
+func unmarshalInstanceOverride(buffer []byte) (*pbtask.TaskConfig, error) {
+	override := &pbtask.TaskConfig{}
+	if err := proto.Unmarshal(buffer, override); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal instance override")
+	}
+	return override, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+)
+
+type InstanceOverrideTestSuite struct {
+	suite.Suite
+	jobID *peloton.JobID
+}
+
+func TestInstanceOverrideSuite(t *testing.T) {
+	suite.Run(t, new(InstanceOverrideTestSuite))
+}
+
+func (s *InstanceOverrideTestSuite) SetupTest() {
+	setupTestStore()
+	s.jobID = &peloton.JobID{Value: uuid.New()}
+}
+
+// TestCreateGetDeleteInstanceOverride tests creating, getting and
+// deleting instance overrides.
+func (s *InstanceOverrideTestSuite) TestCreateGetDeleteInstanceOverride() {
+	ops := NewInstanceOverrideOps(testStore)
+	ctx := context.Background()
+
+	override := &pbtask.TaskConfig{
+		Labels: []*peloton.Label{{Key: "debug", Value: "true"}},
+	}
+
+	_, err := ops.Get(ctx, s.jobID, 7)
+	s.True(yarpcerrors.IsNotFound(err))
+
+	s.NoError(ops.Create(ctx, s.jobID, 7, override))
+	s.NoError(ops.Create(ctx, s.jobID, 8, &pbtask.TaskConfig{}))
+
+	result, err := ops.Get(ctx, s.jobID, 7)
+	s.NoError(err)
+	s.Equal(override, result)
+
+	all, err := ops.GetAll(ctx, s.jobID)
+	s.NoError(err)
+	s.Len(all, 2)
+	s.Equal(override, all[7])
+
+	s.NoError(ops.Delete(ctx, s.jobID, 7))
+	all, err = ops.GetAll(ctx, s.jobID)
+	s.NoError(err)
+	s.Len(all, 1)
+
+	s.NoError(ops.Delete(ctx, s.jobID, 8))
+}
+
+// TestInstanceOverrideOpsClientFail tests failure cases due to ORM
+// Client errors.
+func (s *InstanceOverrideTestSuite) TestInstanceOverrideOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewInstanceOverrideOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("get failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getAll failed"))
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	ctx := context.Background()
+
+	err := ops.Create(ctx, s.jobID, 0, &pbtask.TaskConfig{})
+	s.Equal("create failed", err.Error())
+
+	_, err = ops.Get(ctx, s.jobID, 0)
+	s.Equal("get failed", err.Error())
+
+	_, err = ops.GetAll(ctx, s.jobID)
+	s.Equal("getAll failed", err.Error())
+
+	err = ops.Delete(ctx, s.jobID, 0)
+	s.Equal("delete failed", err.Error())
+}
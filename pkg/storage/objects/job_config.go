@@ -127,6 +127,10 @@ type JobConfigOps interface {
 
 	// Delete removes an object from the table.
 	Delete(ctx context.Context, id *peloton.JobID, version uint64) error
+
+	// Invalidate drops all the cached config versions of the job. It must
+	// be called after deleting job config rows without using Delete.
+	Invalidate(id *peloton.JobID)
 }
 
 // ensure that default implementation (jobConfigOps) satisfies the interface
@@ -298,27 +302,51 @@ func (d *jobConfigOps) GetCurrentVersion(
 	return d.Get(ctx, id, runtime.GetConfigurationVersion())
 }
 
-// Get gets a JobConfigObject from db
-func (d *jobConfigOps) Get(
+// getObject reads a job config row through the job config cache. It returns
+// nil if the row does not exist in the DB.
+func (d *jobConfigOps) getObject(
 	ctx context.Context,
 	id *peloton.JobID,
 	version uint64,
-) (*job.JobConfig, *models.ConfigAddOn, error) {
+) (*JobConfigObject, error) {
+	if obj, ok := d.store.jobConfigCache.get(id.GetValue(), version); ok {
+		d.store.metrics.OrmJobMetrics.JobConfigCacheHit.Inc(1)
+		return obj, nil
+	}
+	d.store.metrics.OrmJobMetrics.JobConfigCacheMiss.Inc(1)
+
+	generation := d.store.jobConfigCache.getGeneration()
 	obj := &JobConfigObject{
 		JobID:   id.GetValue(),
 		Version: version,
 	}
-
 	row, err := d.store.oClient.Get(ctx, obj)
 	if err != nil {
 		d.store.metrics.OrmJobMetrics.JobConfigGetFail.Inc(1)
-		return nil, nil, err
+		return nil, err
 	}
 	if len(row) == 0 {
+		return nil, nil
+	}
+	obj.transform(row)
+	d.store.jobConfigCache.add(obj, generation)
+	return obj, nil
+}
+
+// Get gets a JobConfigObject from db
+func (d *jobConfigOps) Get(
+	ctx context.Context,
+	id *peloton.JobID,
+	version uint64,
+) (*job.JobConfig, *models.ConfigAddOn, error) {
+	obj, err := d.getObject(ctx, id, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if obj == nil {
 		return nil, nil, yarpcerrors.NotFoundErrorf(
 			"Job config not found %s", id.Value)
 	}
-	obj.transform(row)
 	config, err := obj.toConfig()
 	if err != nil {
 		d.store.metrics.OrmJobMetrics.JobConfigGetFail.Inc(1)
@@ -354,19 +382,13 @@ func (d *jobConfigOps) GetResult(
 	id *peloton.JobID,
 	version uint64,
 ) (*JobConfigOpsResult, error) {
-	obj := &JobConfigObject{
-		JobID:   id.GetValue(),
-		Version: version,
-	}
-	row, err := d.store.oClient.Get(ctx, obj)
+	obj, err := d.getObject(ctx, id, version)
 	if err != nil {
-		d.store.metrics.OrmJobMetrics.JobConfigGetFail.Inc(1)
 		return nil, err
 	}
-	if len(row) == 0 {
+	if obj == nil {
 		return nil, nil
 	}
-	obj.transform(row)
 	config, err := obj.toConfig()
 	if err != nil {
 		d.store.metrics.OrmJobMetrics.JobConfigGetFail.Inc(1)
@@ -405,6 +427,10 @@ func (d *jobConfigOps) Delete(
 		Version: version,
 	}
 
+	// Drop the cached row once the delete is done, even if it failed, as
+	// it may have been applied. Reads which started before are not
+	// allowed to add the row they read back to the cache.
+	defer d.store.jobConfigCache.remove(obj.JobID, obj.Version)
 	if err := d.store.oClient.Delete(ctx, obj); err != nil {
 		d.store.metrics.OrmJobMetrics.JobConfigDeleteFail.Inc(1)
 		return err
@@ -412,3 +438,8 @@ func (d *jobConfigOps) Delete(
 	d.store.metrics.OrmJobMetrics.JobConfigDelete.Inc(1)
 	return nil
 }
+
+// Invalidate drops all the cached config versions of the job
+func (d *jobConfigOps) Invalidate(id *peloton.JobID) {
+	d.store.jobConfigCache.removeJob(id.GetValue())
+}
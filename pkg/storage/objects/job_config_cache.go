@@ -0,0 +1,167 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"container/list"
+	"sync"
+)
+
+// _defaultJobConfigCacheSize is the default maximum number of job config
+// versions held by the job config cache.
+const _defaultJobConfigCacheSize = 1000
+
+// jobConfigCacheKey identifies a single version of a job config.
+type jobConfigCacheKey struct {
+	jobID   string
+	version uint64
+}
+
+// jobConfigCache is a size bounded LRU cache of job config rows. Job config
+// rows are never updated once written for a given version, so they can be
+// served from memory without going to the DB. Rows are cached in their
+// serialized form so every read unmarshals a fresh copy which the caller
+// is free to modify. Callers deleting job config rows without going
+// through jobConfigOps.Delete must invalidate them with
+// jobConfigOps.Invalidate.
+type jobConfigCache struct {
+	sync.Mutex
+
+	maxSize int
+	// generation is incremented whenever rows are removed, so that a row
+	// read from the DB before a removal is not added back afterwards
+	generation uint64
+	// order keeps the cached rows from most to least recently used
+	order   *list.List
+	entries map[jobConfigCacheKey]*list.Element
+}
+
+// newJobConfigCache creates a jobConfigCache holding up to maxSize rows.
+func newJobConfigCache(maxSize int) *jobConfigCache {
+	return &jobConfigCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[jobConfigCacheKey]*list.Element),
+	}
+}
+
+// get returns the cached row for the job config version, if present.
+func (c *jobConfigCache) get(jobID string, version uint64) (*JobConfigObject, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[jobConfigCacheKey{jobID: jobID, version: version}]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*JobConfigObject), true
+}
+
+// getGeneration returns the current generation of the cache, which is to
+// be read before reading a row from the DB, and passed when adding the row.
+func (c *jobConfigCache) getGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return c.generation
+}
+
+// add caches a row read from the DB at the given generation of the cache,
+// evicting the least recently used row if the cache is full. The row is
+// not cached if any row was removed since, as it may have been deleted
+// from the DB after it was read.
+func (c *jobConfigCache) add(obj *JobConfigObject, generation uint64) {
+	if c == nil || c.maxSize <= 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	key := jobConfigCacheKey{jobID: obj.JobID, version: obj.Version}
+	if e, ok := c.entries[key]; ok {
+		e.Value = obj
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(obj)
+	for c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		evicted := c.order.Remove(oldest).(*JobConfigObject)
+		delete(c.entries, jobConfigCacheKey{
+			jobID:   evicted.JobID,
+			version: evicted.Version,
+		})
+	}
+}
+
+// remove drops the cached row for the job config version, if present.
+func (c *jobConfigCache) remove(jobID string, version uint64) {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.generation++
+	key := jobConfigCacheKey{jobID: jobID, version: version}
+	if e, ok := c.entries[key]; ok {
+		c.order.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// removeJob drops all the cached rows of the job.
+func (c *jobConfigCache) removeJob(jobID string) {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.generation++
+	for key, e := range c.entries {
+		if key.jobID == jobID {
+			c.order.Remove(e)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// size returns the number of cached rows.
+func (c *jobConfigCache) size() int {
+	if c == nil {
+		return 0
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	return c.order.Len()
+}
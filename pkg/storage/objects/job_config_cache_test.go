@@ -0,0 +1,128 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type JobConfigCacheTestSuite struct {
+	suite.Suite
+}
+
+func TestJobConfigCacheSuite(t *testing.T) {
+	suite.Run(t, new(JobConfigCacheTestSuite))
+}
+
+// TestAddGetRemove tests the basic operations of the cache
+func (s *JobConfigCacheTestSuite) TestAddGetRemove() {
+	c := newJobConfigCache(10)
+
+	_, ok := c.get("job", 1)
+	s.False(ok)
+
+	obj := &JobConfigObject{JobID: "job", Version: 1}
+	c.add(obj, c.getGeneration())
+	cached, ok := c.get("job", 1)
+	s.True(ok)
+	s.Equal(obj, cached)
+
+	_, ok = c.get("job", 2)
+	s.False(ok)
+
+	c.remove("job", 1)
+	_, ok = c.get("job", 1)
+	s.False(ok)
+	s.Equal(0, c.size())
+}
+
+// TestEvictLeastRecentlyUsed tests that the least recently used row is
+// evicted once the cache is full
+func (s *JobConfigCacheTestSuite) TestEvictLeastRecentlyUsed() {
+	c := newJobConfigCache(2)
+
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, c.getGeneration())
+	c.add(&JobConfigObject{JobID: "job", Version: 2}, c.getGeneration())
+
+	// touch version 1 so that version 2 becomes the eviction candidate
+	_, ok := c.get("job", 1)
+	s.True(ok)
+
+	c.add(&JobConfigObject{JobID: "job", Version: 3}, c.getGeneration())
+	s.Equal(2, c.size())
+
+	_, ok = c.get("job", 1)
+	s.True(ok)
+	_, ok = c.get("job", 2)
+	s.False(ok)
+	_, ok = c.get("job", 3)
+	s.True(ok)
+}
+
+// TestNilCache tests that a nil cache disables caching
+func (s *JobConfigCacheTestSuite) TestNilCache() {
+	var c *jobConfigCache
+
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, c.getGeneration())
+	_, ok := c.get("job", 1)
+	s.False(ok)
+	c.remove("job", 1)
+	s.Equal(0, c.size())
+}
+
+// TestRemoveJob tests that all the cached versions of a job are dropped
+func (s *JobConfigCacheTestSuite) TestRemoveJob() {
+	c := newJobConfigCache(10)
+
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, c.getGeneration())
+	c.add(&JobConfigObject{JobID: "job", Version: 2}, c.getGeneration())
+	c.add(&JobConfigObject{JobID: "other", Version: 1}, c.getGeneration())
+
+	c.removeJob("job")
+	s.Equal(1, c.size())
+	_, ok := c.get("job", 1)
+	s.False(ok)
+	_, ok = c.get("job", 2)
+	s.False(ok)
+	_, ok = c.get("other", 1)
+	s.True(ok)
+
+	var nilCache *jobConfigCache
+	nilCache.removeJob("job")
+}
+
+// TestAddAfterRemove tests that a row read before a removal is not added
+// to the cache after the removal
+func (s *JobConfigCacheTestSuite) TestAddAfterRemove() {
+	c := newJobConfigCache(10)
+
+	generation := c.getGeneration()
+	c.remove("job", 1)
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, generation)
+	_, ok := c.get("job", 1)
+	s.False(ok)
+
+	generation = c.getGeneration()
+	c.removeJob("other")
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, generation)
+	_, ok = c.get("job", 1)
+	s.False(ok)
+
+	c.add(&JobConfigObject{JobID: "job", Version: 1}, c.getGeneration())
+	_, ok = c.get("job", 1)
+	s.True(ok)
+}
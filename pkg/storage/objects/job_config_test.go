@@ -174,6 +174,108 @@ func (s *JobConfigObjectTestSuite) TestCreateGetDeleteJobConfigFail() {
 	s.Equal("delete failed", err.Error())
 }
 
+// TestGetJobConfigFromCache tests that job config reads are served from
+// the job config cache after the first read and until the row is deleted
+func (s *JobConfigObjectTestSuite) TestGetJobConfigFromCache() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{
+		oClient:        mockClient,
+		metrics:        testStore.metrics,
+		jobConfigCache: newJobConfigCache(10),
+	}
+	configOps := NewJobConfigOps(mockStore)
+
+	ctx := context.Background()
+	version := uint64(1)
+
+	obj, err := newJobConfigObject(
+		s.jobID, version, s.config, s.configAddOn, s.spec)
+	s.NoError(err)
+	row := map[string]interface{}{
+		"job_id":        obj.JobID,
+		"version":       obj.Version,
+		"config":        obj.Config,
+		"config_addon":  obj.ConfigAddOn,
+		"spec":          obj.Spec,
+		"api_version":   obj.ApiVersion,
+		"creation_time": obj.CreationTime,
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return(row, nil),
+		mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil),
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil),
+	)
+
+	config, configAddOn, err := configOps.Get(ctx, s.jobID, version)
+	s.NoError(err)
+	s.True(proto.Equal(config, s.config))
+	s.True(proto.Equal(configAddOn, s.configAddOn))
+
+	// modifying the returned config must not affect the cached row
+	config.InstanceCount = 100
+
+	result, err := configOps.GetResult(ctx, s.jobID, version)
+	s.NoError(err)
+	s.True(proto.Equal(result.JobConfig, s.config))
+	s.True(proto.Equal(result.ConfigAddOn, s.configAddOn))
+	s.True(proto.Equal(result.JobSpec, s.spec))
+
+	s.NoError(configOps.Delete(ctx, s.jobID, version))
+
+	_, _, err = configOps.Get(ctx, s.jobID, version)
+	s.Error(err)
+	s.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestInvalidateJobConfigCache tests that invalidating a job drops its
+// cached config versions so that the next read goes to the DB
+func (s *JobConfigObjectTestSuite) TestInvalidateJobConfigCache() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{
+		oClient:        mockClient,
+		metrics:        testStore.metrics,
+		jobConfigCache: newJobConfigCache(10),
+	}
+	configOps := NewJobConfigOps(mockStore)
+
+	ctx := context.Background()
+	version := uint64(1)
+
+	obj, err := newJobConfigObject(
+		s.jobID, version, s.config, s.configAddOn, s.spec)
+	s.NoError(err)
+	row := map[string]interface{}{
+		"job_id":        obj.JobID,
+		"version":       obj.Version,
+		"config":        obj.Config,
+		"config_addon":  obj.ConfigAddOn,
+		"spec":          obj.Spec,
+		"api_version":   obj.ApiVersion,
+		"creation_time": obj.CreationTime,
+	}
+
+	gomock.InOrder(
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return(row, nil),
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil),
+	)
+
+	_, _, err = configOps.Get(ctx, s.jobID, version)
+	s.NoError(err)
+
+	configOps.Invalidate(s.jobID)
+
+	_, _, err = configOps.Get(ctx, s.jobID, version)
+	s.Error(err)
+	s.True(yarpcerrors.IsNotFound(err))
+}
+
 func (s *JobConfigObjectTestSuite) buildConfig() {
 	s.jobID = &peloton.JobID{Value: uuid.New()}
 
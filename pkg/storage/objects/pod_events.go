@@ -65,6 +65,8 @@ type PodEventsObject struct {
 	Healthy string `column:"name=healthy"`
 	// Hostname of the pod event
 	Hostname string `column:"name=hostname"`
+	// LaunchCorrelationID of the pod event
+	LaunchCorrelationID string `column:"name=launch_correlation_id"`
 	// Message of the pod event
 	Message string `column:"name=message"`
 	// PodStatus of the pod event
@@ -92,6 +94,7 @@ func (o *PodEventsObject) transform(row map[string]interface{}) {
 	o.DesiredRunID = row["desired_run_id"].(uint64)
 	o.Healthy = row["healthy"].(string)
 	o.Hostname = row["hostname"].(string)
+	o.LaunchCorrelationID = row["launch_correlation_id"].(string)
 	o.Message = row["message"].(string)
 	o.PodStatus = row["pod_status"].([]byte)
 	o.PreviousRunID = row["previous_run_id"].(uint64)
@@ -119,6 +122,18 @@ type PodEventsOps interface {
 		instanceID uint32,
 		podID ...string,
 	) ([]*task.PodEvent, error)
+
+	// Compact compacts the pod events of the runs of a Job + Instance
+	// which are neither among its last maxRuns runs, nor have an event
+	// newer than maxAge, keeping only the last event of each such run.
+	// It returns the number of pod events removed.
+	Compact(
+		ctx context.Context,
+		jobID string,
+		instanceID uint32,
+		maxRuns uint64,
+		maxAge time.Duration,
+	) (int, error)
 }
 
 // ensure that default implementation (podEventsOps) satisfies the interface
@@ -192,6 +207,7 @@ func (d *podEventsOps) Create(
 		VolumeID:             runtime.GetVolumeID().GetValue(),
 		Message:              runtime.GetMessage(),
 		Reason:               runtime.GetReason(),
+		LaunchCorrelationID:  runtime.GetLaunchCorrelationId(),
 		PodStatus:            podStatus,
 	}
 
@@ -307,6 +323,7 @@ func (d *podEventsOps) GetAll(
 		podEvent.AgentID = podEventsObjectValue.AgentID
 		podEvent.Hostname = podEventsObjectValue.Hostname
 		podEvent.Healthy = podEventsObjectValue.Healthy
+		podEvent.LaunchCorrelationId = podEventsObjectValue.LaunchCorrelationID
 
 		podEvents = append(podEvents, podEvent)
 	}
@@ -314,3 +331,68 @@ func (d *podEventsOps) GetAll(
 
 	return podEvents, nil
 }
+
+// Compact compacts the pod events of the runs of a Job + Instance which
+// are neither among its last maxRuns runs, nor have an event newer than
+// maxAge, keeping only the last event of each such run.
+func (d *podEventsOps) Compact(
+	ctx context.Context,
+	jobID string,
+	instanceID uint32,
+	maxRuns uint64,
+	maxAge time.Duration,
+) (int, error) {
+	rows, err := d.store.oClient.GetAll(ctx, &PodEventsObject{
+		JobID:      jobID,
+		InstanceID: instanceID,
+	})
+	if err != nil {
+		d.store.metrics.OrmTaskMetrics.PodEventsCompactFail.Inc(1)
+		return 0, err
+	}
+
+	var removed int
+	var runs, runID uint64
+	var retainRun bool
+	now := time.Now()
+	for _, row := range rows {
+		podEventsObjectValue := &PodEventsObject{}
+		podEventsObjectValue.transform(row)
+
+		// Events are sorted in descending order by run_id and then
+		// update_time, so the first event of a run is its last one,
+		// which is always kept.
+		if runs == 0 || podEventsObjectValue.RunID.Value != runID {
+			runs++
+			runID = podEventsObjectValue.RunID.Value
+
+			updateTime, err := gocql.ParseUUID(
+				podEventsObjectValue.UpdateTime.Value)
+			if err != nil {
+				d.store.metrics.OrmTaskMetrics.PodEventsCompactFail.Inc(1)
+				return removed, errors.Wrap(err, "Failed to parse update_time")
+			}
+			retainRun = runs <= maxRuns ||
+				(maxAge > 0 && now.Sub(updateTime.Time()) < maxAge)
+			continue
+		}
+
+		if retainRun {
+			continue
+		}
+
+		if err := d.store.oClient.Delete(ctx, &PodEventsObject{
+			JobID:      jobID,
+			InstanceID: instanceID,
+			RunID:      podEventsObjectValue.RunID,
+			UpdateTime: podEventsObjectValue.UpdateTime,
+		}); err != nil {
+			d.store.metrics.OrmTaskMetrics.PodEventsCompactFail.Inc(1)
+			return removed, err
+		}
+		removed++
+	}
+
+	d.store.metrics.OrmTaskMetrics.PodEventsCompact.Inc(1)
+	return removed, nil
+}
@@ -16,6 +16,7 @@ package objects
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -178,6 +179,7 @@ func (s *PodEventsObjectTestSuite) TestGetPodEvents() {
 		},
 		ConfigVersion:        3,
 		DesiredConfigVersion: 4,
+		LaunchCorrelationId:  "launch-correlation-id",
 	}
 
 	db.Create(context.Background(), jobID, 0, runtime)
@@ -188,6 +190,7 @@ func (s *PodEventsObjectTestSuite) TestGetPodEvents() {
 		"7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2")
 	s.Equal(len(podEvents), 1)
 	s.NoError(err)
+	s.Equal("launch-correlation-id", podEvents[0].GetLaunchCorrelationId())
 
 	mesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-3"
 	prevMesosTaskID = "7ac74273-4ef0-4ca4-8fd2-34bc52aeac06-0-2"
@@ -230,3 +233,55 @@ func (s *PodEventsObjectTestSuite) TestGetPodEvents() {
 	s.Equal(len(podEvents), 1)
 	s.NoError(err)
 }
+
+// TestCompactPodEvents tests compacting the pod events of the runs
+// beyond the retention to their last event
+func (s *PodEventsObjectTestSuite) TestCompactPodEvents() {
+	db := NewPodEventsOps(testStore)
+	ctx := context.Background()
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+
+	// add two events for each of three runs
+	for runID := 1; runID <= 3; runID++ {
+		mesosTaskID := fmt.Sprintf("%s-0-%d", jobID.GetValue(), runID)
+		for _, state := range []task.TaskState{
+			task.TaskState_PENDING,
+			task.TaskState_RUNNING,
+		} {
+			s.NoError(db.Create(ctx, jobID, 0, &task.RuntimeInfo{
+				State:     state,
+				GoalState: task.TaskState_RUNNING,
+				MesosTaskId: &mesos.TaskID{
+					Value: &mesosTaskID,
+				},
+			}))
+		}
+	}
+
+	// all the runs have recent events
+	removed, err := db.Compact(ctx, jobID.GetValue(), 0, 1, time.Hour)
+	s.NoError(err)
+	s.Equal(0, removed)
+
+	// only the last run is retained
+	removed, err = db.Compact(ctx, jobID.GetValue(), 0, 1, 0)
+	s.NoError(err)
+	s.Equal(2, removed)
+
+	for runID, count := range map[int]int{1: 1, 2: 1, 3: 2} {
+		podEvents, err := db.GetAll(
+			ctx,
+			jobID.GetValue(),
+			0,
+			fmt.Sprintf("%s-0-%d", jobID.GetValue(), runID))
+		s.NoError(err)
+		s.Len(podEvents, count)
+		// the last event of the run is kept
+		s.Equal(task.TaskState_RUNNING.String(), podEvents[0].GetActualState())
+	}
+
+	// compacting again is a no-op
+	removed, err = db.Compact(ctx, jobID.GetValue(), 0, 1, 0)
+	s.NoError(err)
+	s.Equal(0, removed)
+}
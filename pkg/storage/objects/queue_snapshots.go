@@ -0,0 +1,164 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// init adds a QueueSnapshotObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &QueueSnapshotObject{})
+}
+
+// QueueSnapshotObject corresponds to a row in resmgr_queue_snapshots table,
+// which is the content of the queues of a leaf resource pool at some point
+// in time.
+type QueueSnapshotObject struct {
+	// base.Object DB specific annotations
+	base.Object `cassandra:"name=resmgr_queue_snapshots, primaryKey=((respool_id))"`
+	// RespoolID is the ID of the resource pool
+	RespoolID *base.OptionalString `column:"name=respool_id"`
+	// Gangs is the serialized QueueSnapshot holding the pending and ready
+	// gangs in dequeue order
+	Gangs []byte `column:"name=gangs"`
+	// UpdateTime of the snapshot
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// transform will convert all the value from DB into the corresponding type
+// in ORM object to be interpreted by base store client
+func (o *QueueSnapshotObject) transform(row map[string]interface{}) {
+	o.RespoolID = base.NewOptionalString(row["respool_id"])
+	o.Gangs = row["gangs"].([]byte)
+	o.UpdateTime = row["update_time"].(time.Time)
+}
+
+// QueueSnapshot is the unmarshalled content of a row in
+// resmgr_queue_snapshots table.
+type QueueSnapshot struct {
+	// RespoolID is the ID of the resource pool
+	RespoolID string
+	// PendingGangs waiting for admission in the resource pool in dequeue
+	// order
+	PendingGangs []*resmgrsvc.Gang
+	// ReadyGangs of the resource pool waiting for placement in dequeue
+	// order
+	ReadyGangs []*resmgrsvc.Gang
+	// UpdateTime of the snapshot
+	UpdateTime time.Time
+}
+
+// QueueSnapshotOps provides methods for manipulating
+// resmgr_queue_snapshots table.
+type QueueSnapshotOps interface {
+	// Create creates or replaces the queue snapshot of a resource pool.
+	Create(
+		ctx context.Context,
+		respoolID string,
+		pendingGangs []*resmgrsvc.Gang,
+		readyGangs []*resmgrsvc.Gang,
+	) error
+
+	// GetAll returns the queue snapshots of all resource pools.
+	GetAll(ctx context.Context) ([]*QueueSnapshot, error)
+}
+
+// ensure that default implementation (queueSnapshotOps) satisfies
+// the interface
+var _ QueueSnapshotOps = (*queueSnapshotOps)(nil)
+
+// queueSnapshotOps implements QueueSnapshotOps using a particular Store
+type queueSnapshotOps struct {
+	store *Store
+}
+
+// NewQueueSnapshotOps constructs a QueueSnapshotOps object for provided
+// Store.
+func NewQueueSnapshotOps(s *Store) QueueSnapshotOps {
+	return &queueSnapshotOps{store: s}
+}
+
+// Create creates or replaces the queue snapshot of a resource pool.
+func (d *queueSnapshotOps) Create(
+	ctx context.Context,
+	respoolID string,
+	pendingGangs []*resmgrsvc.Gang,
+	readyGangs []*resmgrsvc.Gang,
+) error {
+	buffer, err := proto.Marshal(&resmgrsvc.QueueSnapshot{
+		PendingGangs: pendingGangs,
+		ReadyGangs:   readyGangs,
+	})
+	if err != nil {
+		d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotCreateFail.Inc(1)
+		return errors.Wrap(err, "failed to marshal queue snapshot")
+	}
+
+	obj := &QueueSnapshotObject{
+		RespoolID:  base.NewOptionalString(respoolID),
+		Gangs:      buffer,
+		UpdateTime: time.Now().UTC(),
+	}
+
+	if err := d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotCreate.Inc(1)
+	return nil
+}
+
+// GetAll returns the queue snapshots of all resource pools.
+func (d *queueSnapshotOps) GetAll(
+	ctx context.Context,
+) ([]*QueueSnapshot, error) {
+	rows, err := d.store.oClient.GetAll(ctx, &QueueSnapshotObject{})
+	if err != nil {
+		d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotGetFail.Inc(1)
+		return nil, err
+	}
+
+	var result []*QueueSnapshot
+	for _, row := range rows {
+		obj := &QueueSnapshotObject{}
+		obj.transform(row)
+
+		snapshot := &resmgrsvc.QueueSnapshot{}
+		if err := proto.Unmarshal(obj.Gangs, snapshot); err != nil {
+			d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotGetFail.Inc(1)
+			return nil, errors.Wrap(err, "failed to unmarshal queue snapshot")
+		}
+
+		result = append(result, &QueueSnapshot{
+			RespoolID:    obj.RespoolID.String(),
+			PendingGangs: snapshot.GetPendingGangs(),
+			ReadyGangs:   snapshot.GetReadyGangs(),
+			UpdateTime:   obj.UpdateTime,
+		})
+	}
+
+	d.store.metrics.OrmQueueSnapshotMetrics.QueueSnapshotGet.Inc(1)
+	return result, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueSnapshotTestSuite struct {
+	suite.Suite
+}
+
+func TestQueueSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(QueueSnapshotTestSuite))
+}
+
+func (s *QueueSnapshotTestSuite) SetupTest() {
+	setupTestStore()
+}
+
+func newSnapshotGang(taskID string) *resmgrsvc.Gang {
+	return &resmgrsvc.Gang{
+		Tasks: []*resmgr.Task{{Id: &peloton.TaskID{Value: taskID}}},
+	}
+}
+
+// TestCreateGetAllQueueSnapshots tests creating and getting the queue
+// snapshots of resource pools.
+func (s *QueueSnapshotTestSuite) TestCreateGetAllQueueSnapshots() {
+	ops := NewQueueSnapshotOps(testStore)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, "respool1", []*resmgrsvc.Gang{
+		newSnapshotGang("job1-0"),
+	}, nil))
+
+	// Creating the snapshot of a resource pool again replaces it.
+	s.NoError(ops.Create(ctx, "respool1", []*resmgrsvc.Gang{
+		newSnapshotGang("job1-1"),
+		newSnapshotGang("job1-2"),
+	}, []*resmgrsvc.Gang{
+		newSnapshotGang("job1-3"),
+	}))
+
+	snapshots, err := ops.GetAll(ctx)
+	s.NoError(err)
+
+	var found *QueueSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.RespoolID == "respool1" {
+			found = snapshot
+		}
+	}
+	s.NotNil(found)
+	s.False(found.UpdateTime.IsZero())
+	s.Len(found.PendingGangs, 2)
+	s.Equal("job1-1",
+		found.PendingGangs[0].GetTasks()[0].GetId().GetValue())
+	s.Equal("job1-2",
+		found.PendingGangs[1].GetTasks()[0].GetId().GetValue())
+	s.Len(found.ReadyGangs, 1)
+	s.Equal("job1-3",
+		found.ReadyGangs[0].GetTasks()[0].GetId().GetValue())
+}
+
+// TestQueueSnapshotOpsClientFail tests failure cases due to ORM Client
+// errors.
+func (s *QueueSnapshotTestSuite) TestQueueSnapshotOpsClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewQueueSnapshotOps(mockStore)
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	ctx := context.Background()
+
+	err := ops.Create(ctx, "respool1", nil, nil)
+	s.Equal("create failed", err.Error())
+
+	_, err = ops.GetAll(ctx)
+	s.Equal("getall failed", err.Error())
+}
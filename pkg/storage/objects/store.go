@@ -15,6 +15,7 @@
 package objects
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -27,6 +28,7 @@ import (
 
 	_ "github.com/gemnasium/migrate/driver/cassandra" // Pull in C* driver for migrate
 	"github.com/gemnasium/migrate/migrate"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
 )
@@ -40,6 +42,9 @@ var Objs []base.Object
 type Store struct {
 	oClient orm.Client
 	metrics *pelotonstore.Metrics
+	// jobConfigCache caches job config rows, which are immutable per
+	// version. Caching is disabled if it is nil.
+	jobConfigCache *jobConfigCache
 }
 
 // NewCassandraStore creates a new Cassandra storage client
@@ -57,9 +62,24 @@ func NewCassandraStore(
 	if err != nil {
 		return nil, err
 	}
+	if config.AutoMigrateSchema {
+		schemaConnector, ok := connector.(orm.SchemaConnector)
+		if !ok {
+			return nil, errors.New("connector cannot migrate schema")
+		}
+		if err := orm.NewMigrator(schemaConnector, Objs...).
+			Migrate(context.Background()); err != nil {
+			return nil, errors.Wrap(err, "failed to migrate schema")
+		}
+	}
+	cacheSize := config.JobConfigCacheSize
+	if cacheSize <= 0 {
+		cacheSize = _defaultJobConfigCacheSize
+	}
 	return &Store{
-		oClient: oclient,
-		metrics: pelotonstore.NewMetrics(scope),
+		oClient:        oclient,
+		metrics:        pelotonstore.NewMetrics(scope),
+		jobConfigCache: newJobConfigCache(cacheSize),
 	}, nil
 }
 
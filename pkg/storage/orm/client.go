@@ -30,6 +30,9 @@ type Client interface {
 	CreateIfNotExists(ctx context.Context, e base.Object) error
 	// Create creates the storage object in the database
 	Create(ctx context.Context, e base.Object) error
+	// CreateBatch creates the storage objects of a single partition in the
+	// database in a single batch
+	CreateBatch(ctx context.Context, objects []base.Object) error
 	// Get gets the storage object from the database
 	Get(ctx context.Context, e base.Object, fieldsToRead ...string) (
 		map[string]interface{}, error)
@@ -39,12 +42,24 @@ type Client interface {
 	// GetAllIter provides an iterative way to fetch all storage objects
 	// for the partition key
 	GetAllIter(ctx context.Context, e base.Object) (Iterator, error)
+	// GetByIndex gets all the storage objects matching the values of the
+	// columns of a secondary index or materialized view from the database
+	GetByIndex(ctx context.Context, e base.Object, index string) (
+		[]map[string]interface{}, error)
 	// Update updates the storage object in the database
 	// The fields to be updated can be specified as fieldsToUpdate which is
 	// a variable list of field names and is to be optionally specified by
 	// the caller. If not specified, all fields in the object will be updated
 	// to the DB
 	Update(ctx context.Context, e base.Object, fieldsToUpdate ...string) error
+	// UpdateBatch updates the storage objects of a single partition in the
+	// database in a single batch. The fields to be updated can be specified
+	// as fieldsToUpdate, same as for Update
+	UpdateBatch(
+		ctx context.Context,
+		objects []base.Object,
+		fieldsToUpdate ...string,
+	) error
 	// Delete deletes the storage object from the database
 	Delete(ctx context.Context, e base.Object) error
 }
@@ -109,6 +124,56 @@ func (c *client) Create(ctx context.Context, e base.Object) error {
 	return c.connector.Create(ctx, &table.Definition, table.GetRowFromObject(e))
 }
 
+// getBatchTable gets the base Table structure that matches the base
+// instances of a batch. Return an error if the instances are not of the same
+// type or not of the same partition.
+func (c *client) getBatchTable(objects []base.Object) (*Table, error) {
+	table, err := c.getTable(objects[0])
+	if err != nil {
+		return nil, err
+	}
+
+	t := reflect.TypeOf(objects[0])
+	partitionKeyRow := table.GetPartitionKeyRowFromObject(objects[0])
+	for _, e := range objects[1:] {
+		if reflect.TypeOf(e) != t {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"batch of %q contains %q", t.Elem().Name(),
+				reflect.TypeOf(e).Elem().Name())
+		}
+		if !reflect.DeepEqual(
+			table.GetPartitionKeyRowFromObject(e), partitionKeyRow) {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"batch of %q spans multiple partitions", t.Elem().Name())
+		}
+	}
+	return table, nil
+}
+
+// CreateBatch creates the storage objects of a single partition in the
+// database
+func (c *client) CreateBatch(ctx context.Context, objects []base.Object) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	// lookup if a table exists for these objects, return error if not found
+	// or if the objects are not of a single partition
+	table, err := c.getBatchTable(objects)
+	if err != nil {
+		return err
+	}
+
+	// translate the storage objects into rows
+	rows := make([][]base.Column, 0, len(objects))
+	for _, e := range objects {
+		rows = append(rows, table.GetRowFromObject(e))
+	}
+
+	// Tell the connector to create the rows in the DB in a single batch
+	return c.connector.CreateBatch(ctx, &table.Definition, rows)
+}
+
 // Get fetches an base by primary key, The base provided must contain
 // values for all components of its primary key for the operation to succeed.
 func (c *client) Get(
@@ -179,6 +244,33 @@ func (c *client) GetAllIter(
 	return c.connector.GetAllIter(ctx, &table.Definition, keyRow)
 }
 
+// GetByIndex fetches a list of base objects using the secondary index or
+// materialized view with the given name. The base object provided must
+// contain the values of the columns the index looks up objects by
+func (c *client) GetByIndex(
+	ctx context.Context,
+	e base.Object,
+	index string,
+) ([]map[string]interface{}, error) {
+
+	// lookup if a table exists for this object, return error if not found
+	table, err := c.getTable(e)
+	if err != nil {
+		return nil, err
+	}
+
+	// lookup if the index exists on this table, return error if not found
+	idx, err := table.GetIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	// build a row of the index columns from storage object
+	indexRow := table.GetIndexRowFromObject(e, idx)
+
+	return c.connector.GetAll(ctx, table.GetIndexDefinition(idx), indexRow)
+}
+
 // Update updates the storage object in the database
 func (c *client) Update(
 	ctx context.Context,
@@ -201,6 +293,36 @@ func (c *client) Update(
 	return c.connector.Update(ctx, &table.Definition, row, keyRow)
 }
 
+// UpdateBatch updates the storage objects of a single partition in the
+// database
+func (c *client) UpdateBatch(
+	ctx context.Context,
+	objects []base.Object,
+	fieldsToUpdate ...string,
+) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	// lookup if a table exists for these objects, return error if not found
+	// or if the objects are not of a single partition
+	table, err := c.getBatchTable(objects)
+	if err != nil {
+		return err
+	}
+
+	// translate the storage objects into rows and primary key rows
+	rows := make([][]base.Column, 0, len(objects))
+	keyRows := make([][]base.Column, 0, len(objects))
+	for _, e := range objects {
+		rows = append(rows, table.GetRowFromObject(e, fieldsToUpdate...))
+		keyRows = append(keyRows, table.GetKeyRowFromObject(e))
+	}
+
+	// Tell the connector to update the rows in the DB in a single batch
+	return c.connector.UpdateBatch(ctx, &table.Definition, rows, keyRows)
+}
+
 // Delete deletes the storage object in the database
 func (c *client) Delete(ctx context.Context, e base.Object) error {
 	// lookup if a table exists for this object, return error if not found
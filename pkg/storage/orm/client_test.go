@@ -151,6 +151,50 @@ func (suite *ORMTestSuite) TestClientCreate() {
 	suite.Error(err)
 }
 
+// TestClientCreateBatch tests client batch create operation on valid and
+// invalid batches
+func (suite *ORMTestSuite) TestClientCreateBatch() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	other := &ValidObject{
+		ID:   uint64(1),
+		Name: "test2",
+		Data: "testdata2",
+	}
+
+	conn.EXPECT().CreateBatch(suite.ctx, gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition,
+			rows [][]base.Column) {
+			suite.Len(rows, 2)
+			suite.ensureRowsEqual(rows[0], testRow)
+		}).Return(nil)
+
+	client, err := orm.NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	err = client.CreateBatch(
+		suite.ctx, []base.Object{testValidObject, other})
+	suite.NoError(err)
+
+	// empty batch is a noop
+	err = client.CreateBatch(suite.ctx, nil)
+	suite.NoError(err)
+
+	// batch of objects of multiple partitions
+	err = client.CreateBatch(suite.ctx, []base.Object{
+		testValidObject, &ValidObject{ID: uint64(2)}})
+	suite.Error(err)
+
+	// batch of objects of multiple types
+	err = client.CreateBatch(suite.ctx, []base.Object{
+		testValidObject, &ValidObjectWithOptString{}})
+	suite.Error(err)
+
+	err = client.CreateBatch(suite.ctx, []base.Object{&InvalidObject1{}})
+	suite.Error(err)
+}
+
 // TestClientGet tests client get operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientGet() {
 	defer suite.ctrl.Finish()
@@ -258,6 +302,98 @@ func (suite *ORMTestSuite) TestClientGetAllIter() {
 	suite.Error(err)
 }
 
+// TestClientGetByIndex tests client GetByIndex operation using a secondary
+// index and a materialized view
+func (suite *ORMTestSuite) TestClientGetByIndex() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	// IndexedObject instance with only the indexed columns set
+	e := &IndexedObject{
+		Data:  "testdata1",
+		State: "RUNNING",
+	}
+
+	gomock.InOrder(
+		conn.EXPECT().GetAll(suite.ctx, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, def *base.Definition,
+				row []base.Column) {
+				suite.Equal("indexed_object", def.Name)
+				suite.Len(row, 1)
+				suite.Equal("state", row[0].Name)
+				suite.Equal(e.State, row[0].Value)
+			}).Return(testRows, nil),
+		conn.EXPECT().GetAll(suite.ctx, gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, def *base.Definition,
+				row []base.Column) {
+				suite.Equal("indexed_object_by_data", def.Name)
+				suite.Len(row, 1)
+				suite.Equal("data", row[0].Name)
+				suite.Equal(e.Data, row[0].Value)
+			}).Return(testRows[:1], nil),
+	)
+
+	client, err := orm.NewClient(conn, &IndexedObject{})
+	suite.NoError(err)
+
+	objs, err := client.GetByIndex(suite.ctx, e, "state_idx")
+	suite.NoError(err)
+	suite.Len(objs, 2)
+
+	objs, err = client.GetByIndex(suite.ctx, e, "indexed_object_by_data")
+	suite.NoError(err)
+	suite.Len(objs, 1)
+
+	_, err = client.GetByIndex(suite.ctx, e, "unknown_idx")
+	suite.Error(err)
+
+	_, err = client.GetByIndex(suite.ctx, &ValidObject{}, "state_idx")
+	suite.Error(err)
+}
+
+// TestClientUpdateBatch tests client batch update operation on valid and
+// invalid batches
+func (suite *ORMTestSuite) TestClientUpdateBatch() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	other := &ValidObject{
+		ID:   uint64(1),
+		Name: "test2",
+		Data: "testdata2",
+	}
+
+	conn.EXPECT().UpdateBatch(
+		suite.ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition,
+			rows [][]base.Column, keyRows [][]base.Column) {
+			suite.Len(rows, 2)
+			suite.Len(keyRows, 2)
+			suite.ensureRowsEqual(rows[1],
+				[]base.Column{{Name: "data", Value: "testdata2"}})
+			suite.ensureRowsEqual(keyRows[0], keyRow)
+		}).Return(nil)
+
+	client, err := orm.NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	err = client.UpdateBatch(
+		suite.ctx, []base.Object{testValidObject, other}, "Data")
+	suite.NoError(err)
+
+	// empty batch is a noop
+	err = client.UpdateBatch(suite.ctx, nil, "Data")
+	suite.NoError(err)
+
+	// batch of objects of multiple partitions
+	err = client.UpdateBatch(suite.ctx, []base.Object{
+		testValidObject, &ValidObject{ID: uint64(2)}}, "Data")
+	suite.Error(err)
+
+	err = client.UpdateBatch(suite.ctx, []base.Object{&InvalidObject1{}})
+	suite.Error(err)
+}
+
 // TestClientUpdate tests client update operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientUpdate() {
 	defer suite.ctrl.Finish()
@@ -33,6 +33,14 @@ type Connector interface {
 	// Create creates a row in the DB for the base object
 	Create(ctx context.Context, e *base.Definition, values []base.Column) error
 
+	// CreateBatch creates rows in the DB for the base objects in a single
+	// batch
+	CreateBatch(
+		ctx context.Context,
+		e *base.Definition,
+		rows [][]base.Column,
+	) error
+
 	// Get fetches a row by primary key of base object
 	Get(
 		ctx context.Context,
@@ -63,6 +71,16 @@ type Connector interface {
 		keys []base.Column,
 	) error
 
+	// UpdateBatch updates rows in the DB for the base objects in a single
+	// batch, the values at each position being updated for the keys at the
+	// same position
+	UpdateBatch(
+		ctx context.Context,
+		e *base.Definition,
+		values [][]base.Column,
+		keys [][]base.Column,
+	) error
+
 	// Delete deletes a row from the DB for the base object
 	Delete(ctx context.Context, e *base.Definition, keys []base.Column) error
 }
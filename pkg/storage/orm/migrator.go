@@ -0,0 +1,216 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// SchemaConnector is implemented by the connectors which can apply the
+// schema of the storage objects to the DB
+type SchemaConnector interface {
+	Connector
+
+	// GetColumnTypes returns the type of each column of a table keyed by
+	// column name, or an empty map if the table doesn't exist
+	GetColumnTypes(ctx context.Context, table string) (map[string]string, error)
+
+	// ExecuteSchemaStmt executes a statement altering the schema
+	ExecuteSchemaStmt(ctx context.Context, stmt string) error
+}
+
+// SchemaVersionObject corresponds to a row in orm_schema_versions table,
+// which is the version of the schema applied to the table of a storage
+// object by the Migrator.
+type SchemaVersionObject struct {
+	// base.Object DB specific annotations
+	base.Object `cassandra:"name=orm_schema_versions, primaryKey=((table_name))"`
+	// TableName is the name of the table
+	TableName string `column:"name=table_name"`
+	// Version is incremented each time the schema of the table is migrated
+	Version uint64 `column:"name=version"`
+	// Checksum of the table definition the schema was migrated to
+	Checksum string `column:"name=checksum"`
+	// UpdateTime of the schema
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// Migrator creates the tables of the storage objects which don't exist and
+// adds the columns missing from the existing ones, along with their
+// secondary indexes and materialized views, as derived from the
+// annotations of the storage objects. The version of the schema of each
+// table is tracked in orm_schema_versions table, so that the tables whose
+// definition didn't change since their last migration are skipped.
+// The tables which exist without a schema version were created by the
+// .cql migrations, which keep owning their schema, so the Migrator never
+// alters them.
+type Migrator struct {
+	conn    SchemaConnector
+	objects []base.Object
+}
+
+// NewMigrator returns a Migrator of the tables of the storage objects
+func NewMigrator(conn SchemaConnector, objects ...base.Object) *Migrator {
+	return &Migrator{
+		conn:    conn,
+		objects: objects,
+	}
+}
+
+// Migrate migrates the tables of the storage objects to their definitions
+func (m *Migrator) Migrate(ctx context.Context) error {
+	versionTable, err := TableFromObject(&SchemaVersionObject{})
+	if err != nil {
+		return err
+	}
+	if err := m.migrateTable(ctx, versionTable); err != nil {
+		return errors.Wrap(err, "failed to migrate schema version table")
+	}
+
+	for _, o := range m.objects {
+		table, err := TableFromObject(o)
+		if err != nil {
+			return err
+		}
+
+		stmt, err := table.CreateTableStmt()
+		if err != nil {
+			return err
+		}
+		checksum := schemaChecksum(
+			append([]string{stmt}, table.CreateIndexStmts()...))
+
+		version, currentChecksum, err := m.getVersion(ctx, versionTable, table.Name)
+		if err != nil {
+			return errors.Wrapf(err,
+				"failed to get schema version of table %s", table.Name)
+		}
+		if currentChecksum == checksum {
+			continue
+		}
+
+		existing, err := m.conn.GetColumnTypes(ctx, table.Name)
+		if err != nil {
+			return errors.Wrapf(err,
+				"failed to get columns of table %s", table.Name)
+		}
+		if version == 0 && len(existing) != 0 {
+			log.WithField("table", table.Name).
+				Debug("Skipping table created by the cql migrations")
+			continue
+		}
+
+		if err := m.migrateTableWithColumns(ctx, table, existing); err != nil {
+			return errors.Wrapf(err,
+				"failed to migrate schema of table %s", table.Name)
+		}
+
+		row := versionTable.GetRowFromObject(&SchemaVersionObject{
+			TableName:  table.Name,
+			Version:    version + 1,
+			Checksum:   checksum,
+			UpdateTime: time.Now().UTC(),
+		})
+		if err := m.conn.Create(ctx, &versionTable.Definition, row); err != nil {
+			return errors.Wrapf(err,
+				"failed to set schema version of table %s", table.Name)
+		}
+
+		log.WithField("table", table.Name).
+			WithField("version", version+1).
+			Info("Migrated table schema")
+	}
+	return nil
+}
+
+// migrateTable creates a table, or adds the columns missing from it if it
+// exists, and then creates its missing secondary indexes and materialized
+// views
+func (m *Migrator) migrateTable(ctx context.Context, table *Table) error {
+	existing, err := m.conn.GetColumnTypes(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	return m.migrateTableWithColumns(ctx, table, existing)
+}
+
+// migrateTableWithColumns migrates a table given the type of each of its
+// existing columns
+func (m *Migrator) migrateTableWithColumns(
+	ctx context.Context,
+	table *Table,
+	existing map[string]string,
+) error {
+	var err error
+	var stmts []string
+	if len(existing) == 0 {
+		stmt, err := table.CreateTableStmt()
+		if err != nil {
+			return err
+		}
+		stmts = append(stmts, stmt)
+	} else if stmts, err = table.AlterTableStmts(existing); err != nil {
+		return err
+	}
+	stmts = append(stmts, table.CreateIndexStmts()...)
+
+	for _, stmt := range stmts {
+		log.WithField("stmt", stmt).Info("Executing schema statement")
+		if err := m.conn.ExecuteSchemaStmt(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getVersion returns the version of the schema of a table and the checksum
+// of the definition it was migrated to, zero and empty if it was never
+// migrated
+func (m *Migrator) getVersion(
+	ctx context.Context,
+	versionTable *Table,
+	tableName string,
+) (uint64, string, error) {
+	keys := versionTable.GetKeyRowFromObject(
+		&SchemaVersionObject{TableName: tableName})
+	row, err := m.conn.Get(ctx, &versionTable.Definition, keys)
+	if err != nil || row == nil {
+		return 0, "", err
+	}
+
+	columns := make([]base.Column, 0, len(row))
+	for name, value := range row {
+		columns = append(columns, base.Column{Name: name, Value: value})
+	}
+	obj := &SchemaVersionObject{}
+	versionTable.SetObjectFromRow(obj, columns)
+	return obj.Version, obj.Checksum, nil
+}
+
+// schemaChecksum returns the checksum of the statements creating a table
+// and its secondary indexes and materialized views
+func schemaChecksum(stmts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(stmts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
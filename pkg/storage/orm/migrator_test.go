@@ -0,0 +1,178 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+)
+
+// checksum returns the checksum the Migrator records for a table definition
+func checksum(stmts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(stmts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestMigrate tests migrating the tables of storage objects
+func (suite *ORMTestSuite) TestMigrate() {
+	defer suite.ctrl.Finish()
+
+	conn := ormmocks.NewMockSchemaConnector(suite.ctrl)
+
+	table, err := orm.TableFromObject(&ValidObjectWithOptString{})
+	suite.NoError(err)
+	createStmt, err := table.CreateTableStmt()
+	suite.NoError(err)
+
+	// the schema version table exists
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "orm_schema_versions").
+		Return(map[string]string{
+			"table_name":  "text",
+			"version":     "bigint",
+			"checksum":    "text",
+			"update_time": "timestamp",
+		}, nil)
+
+	// valid_object_opt_string was never migrated and doesn't exist
+	conn.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "valid_object_opt_string").
+		Return(map[string]string{}, nil)
+	conn.EXPECT().ExecuteSchemaStmt(gomock.Any(), createStmt).Return(nil)
+	conn.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// valid_object is already migrated to its current definition
+	validTable, err := orm.TableFromObject(&ValidObject{})
+	suite.NoError(err)
+	validStmt, err := validTable.CreateTableStmt()
+	suite.NoError(err)
+	conn.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(map[string]interface{}{
+			"table_name": "valid_object",
+			"version":    int64(1),
+			"checksum":   checksum(validStmt),
+		}, nil)
+
+	suite.NoError(orm.NewMigrator(
+		conn, &ValidObjectWithOptString{}, &ValidObject{}).
+		Migrate(suite.ctx))
+}
+
+// TestMigrateError tests failing to migrate the tables of storage objects
+func (suite *ORMTestSuite) TestMigrateError() {
+	defer suite.ctrl.Finish()
+
+	conn := ormmocks.NewMockSchemaConnector(suite.ctrl)
+
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "orm_schema_versions").
+		Return(nil, errors.New("get column types failed"))
+	suite.Error(orm.NewMigrator(conn, &ValidObject{}).Migrate(suite.ctx))
+
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "orm_schema_versions").
+		Return(map[string]string{}, nil)
+	conn.EXPECT().ExecuteSchemaStmt(gomock.Any(), gomock.Any()).
+		Return(errors.New("execute failed"))
+	suite.Error(orm.NewMigrator(conn, &ValidObject{}).Migrate(suite.ctx))
+}
+
+// TestMigrateIndexes tests migrating the secondary indexes and materialized
+// views of storage objects
+func (suite *ORMTestSuite) TestMigrateIndexes() {
+	defer suite.ctrl.Finish()
+
+	conn := ormmocks.NewMockSchemaConnector(suite.ctrl)
+
+	table, err := orm.TableFromObject(&IndexedObject{})
+	suite.NoError(err)
+	indexStmts := table.CreateIndexStmts()
+
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "orm_schema_versions").
+		Return(map[string]string{
+			"table_name":  "text",
+			"version":     "bigint",
+			"checksum":    "text",
+			"update_time": "timestamp",
+		}, nil)
+
+	// indexed_object exists without the state column and the indexes
+	conn.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(map[string]interface{}{
+			"table_name": "indexed_object",
+			"version":    int64(1),
+			"checksum":   "stale",
+		}, nil)
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "indexed_object").
+		Return(map[string]string{
+			"id":   "bigint",
+			"name": "text",
+			"data": "text",
+		}, nil)
+	gomock.InOrder(
+		conn.EXPECT().ExecuteSchemaStmt(gomock.Any(),
+			"ALTER TABLE indexed_object ADD state text;").Return(nil),
+		conn.EXPECT().ExecuteSchemaStmt(gomock.Any(), indexStmts[0]).
+			Return(nil),
+		conn.EXPECT().ExecuteSchemaStmt(gomock.Any(), indexStmts[1]).
+			Return(nil),
+	)
+	conn.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition, row []base.Column) {
+			for _, column := range row {
+				if column.Name == "version" {
+					suite.Equal(uint64(2), column.Value)
+				}
+			}
+		}).
+		Return(nil)
+
+	suite.NoError(orm.NewMigrator(conn, &IndexedObject{}).Migrate(suite.ctx))
+}
+
+// TestMigrateSkipsCqlTables tests that the tables created by the cql
+// migrations are not altered
+func (suite *ORMTestSuite) TestMigrateSkipsCqlTables() {
+	defer suite.ctrl.Finish()
+
+	conn := ormmocks.NewMockSchemaConnector(suite.ctrl)
+
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "orm_schema_versions").
+		Return(map[string]string{
+			"table_name":  "text",
+			"version":     "bigint",
+			"checksum":    "text",
+			"update_time": "timestamp",
+		}, nil)
+
+	// indexed_object exists without a schema version
+	conn.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	conn.EXPECT().GetColumnTypes(gomock.Any(), "indexed_object").
+		Return(map[string]string{
+			"id":   "bigint",
+			"name": "text",
+			"data": "text",
+		}, nil)
+
+	suite.NoError(orm.NewMigrator(conn, &IndexedObject{}).Migrate(suite.ctx))
+}
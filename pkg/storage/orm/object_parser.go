@@ -30,6 +30,13 @@ const (
 	// columnTag will describe column specific annotations on every storage
 	// object field
 	columnTag = "column"
+	// indexTag will describe the secondary indexes of a storage object
+	indexTag = "index"
+	// viewTag will describe the materialized views of a storage object
+	viewTag = "view"
+	// declarationSeparator separates the declarations of multiple secondary
+	// indexes or materialized views
+	declarationSeparator = ";"
 	// "Object" is the reflection name of the marker interface used to embed DB
 	// annotations in storage objects
 	objectName = "Object"
@@ -41,6 +48,8 @@ var (
 	// primaryKeyPattern is regex for the format((PK1,PK2..), CK1, CK2..)
 	primaryKeyPattern = regexp.MustCompile(`\(\s*\((.*)\)(.*)\)`)
 	namePattern       = regexp.MustCompile(`name\s*=\s*(\S*)`)
+	// columnsPattern is regex for the format columns=(C1)
+	columnsPattern = regexp.MustCompile(`columns\s*=\s*\(([^)]*)\)`)
 )
 
 // parseClusteringKeys func parses the clustering key of storage object
@@ -161,3 +170,67 @@ func parseCassandraObjectTag(ormAnnotation string) (
 
 	return name, key, nil
 }
+
+// splitDeclarations splits a tag into the declarations of multiple
+// secondary indexes or materialized views
+func splitDeclarations(tag string) []string {
+	var declarations []string
+	for _, d := range strings.Split(tag, declarationSeparator) {
+		if d = strings.TrimSpace(d); len(d) > 0 {
+			declarations = append(declarations, d)
+		}
+	}
+	return declarations
+}
+
+// parseIndexTag function parses the secondary indexes annotation on the
+// "Object" field of the storage object. Each secondary index should be of
+// the format name=N, columns=(C1)
+func parseIndexTag(tag string) ([]*base.Index, error) {
+	var indexes []*base.Index
+	for _, d := range splitDeclarations(tag) {
+		matches := columnsPattern.FindStringSubmatch(d)
+		if len(matches) != 2 {
+			return nil, yarpcerrors.InternalErrorf(
+				"columns pattern mismatch for index %v", d)
+		}
+
+		// Cassandra secondary indexes are on a single column
+		columns := parsePartitionKey(matches[1])
+		if len(columns) != 1 {
+			return nil, yarpcerrors.InternalErrorf(
+				"secondary index must be on a single column: %v", d)
+		}
+
+		name, err := parseNameTag(strings.Replace(d, matches[0], "", 1))
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, &base.Index{
+			Name:    name,
+			Columns: columns,
+		})
+	}
+	return indexes, nil
+}
+
+// parseViewTag function parses the materialized views annotation on the
+// "Object" field of the storage object. Each materialized view should be of
+// the format name=N, primaryKey=((PK1,PK2..), CK1, CK2..)
+func parseViewTag(tag string) ([]*base.Index, error) {
+	var views []*base.Index
+	for _, d := range splitDeclarations(tag) {
+		name, key, err := parseCassandraObjectTag(d)
+		if err != nil {
+			return nil, err
+		}
+
+		views = append(views, &base.Index{
+			Name:    name,
+			Columns: key.PartitionKeys,
+			Key:     key,
+		})
+	}
+	return views, nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	optionalStringType = reflect.TypeOf(&base.OptionalString{})
+	optionalUInt64Type = reflect.TypeOf(&base.OptionalUInt64{})
+
+	// CQL types of the columns of string fields
+	stringCQLTypes = []string{"text", "varchar", "ascii", "uuid", "timeuuid"}
+	// CQL types of the columns of 32 bit integer fields
+	intCQLTypes = []string{"int", "bigint"}
+	// CQL types of the columns of 64 bit integer fields
+	bigintCQLTypes = []string{"bigint", "counter", "varint"}
+)
+
+// cqlTypes returns the CQL types of the column of a storage object field of
+// the given type. The first type is the one the column is created with, the
+// others are accepted for an existing column.
+func cqlTypes(typ reflect.Type) ([]string, error) {
+	switch typ {
+	case timeType:
+		return []string{"timestamp"}, nil
+	case optionalStringType:
+		return stringCQLTypes, nil
+	case optionalUInt64Type:
+		return bigintCQLTypes, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return stringCQLTypes, nil
+	case reflect.Int32, reflect.Uint32, reflect.Int:
+		return intCQLTypes, nil
+	case reflect.Int64, reflect.Uint64:
+		return bigintCQLTypes, nil
+	case reflect.Bool:
+		return []string{"boolean"}, nil
+	case reflect.Float64:
+		return []string{"double"}, nil
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return []string{"blob"}, nil
+		}
+	}
+	return nil, yarpcerrors.InternalErrorf(
+		"no CQL type for field type %s", typ)
+}
+
+// sortedColumns returns the column names of the table in sorted order, so
+// that the statements generated for a table don't change between runs
+func (t *Table) sortedColumns() []string {
+	columns := make([]string, 0, len(t.ColumnToType))
+	for column := range t.ColumnToType {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// CreateTableStmt returns the CQL statement creating the table of the
+// storage object if it doesn't exist
+func (t *Table) CreateTableStmt() (string, error) {
+	var columnDefs []string
+	for _, column := range t.sortedColumns() {
+		types, err := cqlTypes(t.ColumnToType[column])
+		if err != nil {
+			return "", err
+		}
+		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", column, types[0]))
+	}
+
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s, %s)",
+		t.Name, strings.Join(columnDefs, ", "), primaryKeyDef(t.Key))
+	return stmt + clusteringOrderDef(t.Key) + ";", nil
+}
+
+// CreateIndexStmts returns the CQL statements creating the secondary indexes
+// and materialized views of the storage object if they don't exist
+func (t *Table) CreateIndexStmts() []string {
+	var stmts []string
+	for _, index := range t.Indexes {
+		if !index.IsView() {
+			stmts = append(stmts, fmt.Sprintf(
+				"CREATE INDEX IF NOT EXISTS %s ON %s (%s);",
+				index.Name, t.Name, strings.Join(index.Columns, ", ")))
+			continue
+		}
+
+		// All the primary key columns of a materialized view must be
+		// restricted to non null values
+		var conditions []string
+		for _, column := range keyColumns(index.Key) {
+			conditions = append(conditions,
+				fmt.Sprintf("%s IS NOT NULL", column))
+		}
+		stmts = append(stmts, fmt.Sprintf(
+			"CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS SELECT * FROM %s "+
+				"WHERE %s %s%s;",
+			index.Name, t.Name, strings.Join(conditions, " AND "),
+			primaryKeyDef(index.Key), clusteringOrderDef(index.Key)))
+	}
+	return stmts
+}
+
+// primaryKeyDef returns the definition of a primary key in a CQL statement
+func primaryKeyDef(key *base.PrimaryKey) string {
+	primaryKey := fmt.Sprintf("(%s)", strings.Join(key.PartitionKeys, ", "))
+	for _, ck := range key.ClusteringKeys {
+		primaryKey += ", " + ck.Name
+	}
+	return fmt.Sprintf("PRIMARY KEY (%s)", primaryKey)
+}
+
+// clusteringOrderDef returns the clustering order option of a primary key in
+// a CQL statement, or an empty string if all clustering keys are ascending
+func clusteringOrderDef(key *base.PrimaryKey) string {
+	var clusteringOrder []string
+	descending := false
+	for _, ck := range key.ClusteringKeys {
+		order := "ASC"
+		if ck.Descending {
+			order = "DESC"
+			descending = true
+		}
+		clusteringOrder = append(
+			clusteringOrder, fmt.Sprintf("%s %s", ck.Name, order))
+	}
+
+	if !descending {
+		return ""
+	}
+	return fmt.Sprintf(" WITH CLUSTERING ORDER BY (%s)",
+		strings.Join(clusteringOrder, ", "))
+}
+
+// AlterTableStmts returns the CQL statements adding the columns of the
+// storage object missing from its existing table, given the CQL type of
+// each existing column keyed by column name. Primary key columns can't be
+// added to an existing table, and the type of an existing column must be
+// compatible with its field.
+func (t *Table) AlterTableStmts(existing map[string]string) ([]string, error) {
+	keys := make(map[string]bool)
+	for _, column := range keyColumns(t.Key) {
+		keys[column] = true
+	}
+
+	var stmts []string
+	for _, column := range t.sortedColumns() {
+		types, err := cqlTypes(t.ColumnToType[column])
+		if err != nil {
+			return nil, err
+		}
+
+		existingType, ok := existing[column]
+		if !ok {
+			if keys[column] {
+				return nil, yarpcerrors.InternalErrorf(
+					"cannot add primary key column %s to table %s",
+					column, t.Name)
+			}
+			stmts = append(stmts, fmt.Sprintf(
+				"ALTER TABLE %s ADD %s %s;", t.Name, column, types[0]))
+			continue
+		}
+
+		if !containsType(types, existingType) {
+			return nil, yarpcerrors.InternalErrorf(
+				"column %s of table %s has type %s, expected one of %s",
+				column, t.Name, existingType, strings.Join(types, ", "))
+		}
+	}
+	return stmts, nil
+}
+
+func containsType(types []string, typ string) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orm_test
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+)
+
+// SchemaObject has a field of each type supported by the schema generation
+type SchemaObject struct {
+	base.Object `cassandra:"name=schema_object, primaryKey=((id), version)"`
+	ID          *base.OptionalString `column:"name=id"`
+	Version     uint64               `column:"name=version"`
+	Count       uint32               `column:"name=count"`
+	Size        *base.OptionalUInt64 `column:"name=size"`
+	Enabled     bool                 `column:"name=enabled"`
+	Data        []byte               `column:"name=data"`
+	UpdateTime  time.Time            `column:"name=update_time"`
+}
+
+// UnsupportedObject has a field whose type has no CQL type
+type UnsupportedObject struct {
+	base.Object `cassandra:"name=unsupported_object, primaryKey=((id))"`
+	ID          string            `column:"name=id"`
+	Labels      map[string]string `column:"name=labels"`
+}
+
+// TestCreateTableStmt tests generating the statement creating the table of
+// a storage object
+func (suite *ORMTestSuite) TestCreateTableStmt() {
+	table, err := orm.TableFromObject(&SchemaObject{})
+	suite.NoError(err)
+
+	stmt, err := table.CreateTableStmt()
+	suite.NoError(err)
+	suite.Equal("CREATE TABLE IF NOT EXISTS schema_object ("+
+		"count int, data blob, enabled boolean, id text, size bigint, "+
+		"update_time timestamp, version bigint, "+
+		"PRIMARY KEY ((id), version)) "+
+		"WITH CLUSTERING ORDER BY (version DESC);", stmt)
+
+	table, err = orm.TableFromObject(&ValidObjectWithOptString{})
+	suite.NoError(err)
+
+	stmt, err = table.CreateTableStmt()
+	suite.NoError(err)
+	suite.Equal("CREATE TABLE IF NOT EXISTS valid_object_opt_string ("+
+		"data text, name text, PRIMARY KEY ((name)));", stmt)
+
+	table, err = orm.TableFromObject(&UnsupportedObject{})
+	suite.NoError(err)
+
+	_, err = table.CreateTableStmt()
+	suite.Error(err)
+}
+
+// TestAlterTableStmts tests generating the statements adding the missing
+// columns to the existing table of a storage object
+func (suite *ORMTestSuite) TestAlterTableStmts() {
+	table, err := orm.TableFromObject(&SchemaObject{})
+	suite.NoError(err)
+
+	existing := map[string]string{
+		"id":          "uuid",
+		"version":     "bigint",
+		"count":       "int",
+		"size":        "bigint",
+		"update_time": "timestamp",
+	}
+	stmts, err := table.AlterTableStmts(existing)
+	suite.NoError(err)
+	suite.Equal([]string{
+		"ALTER TABLE schema_object ADD data blob;",
+		"ALTER TABLE schema_object ADD enabled boolean;",
+	}, stmts)
+
+	// the type of an existing column is not compatible with its field
+	existing["count"] = "text"
+	_, err = table.AlterTableStmts(existing)
+	suite.Error(err)
+
+	// a primary key column can't be added
+	existing["count"] = "int"
+	delete(existing, "version")
+	_, err = table.AlterTableStmts(existing)
+	suite.Error(err)
+}
+
+// TestCreateIndexStmts tests generating the statements creating the
+// secondary indexes and materialized views of a storage object
+func (suite *ORMTestSuite) TestCreateIndexStmts() {
+	table, err := orm.TableFromObject(&IndexedObject{})
+	suite.NoError(err)
+
+	suite.Equal([]string{
+		"CREATE INDEX IF NOT EXISTS state_idx ON indexed_object (state);",
+		"CREATE MATERIALIZED VIEW IF NOT EXISTS indexed_object_by_data AS " +
+			"SELECT * FROM indexed_object WHERE data IS NOT NULL AND " +
+			"id IS NOT NULL AND name IS NOT NULL " +
+			"PRIMARY KEY ((data), id, name) " +
+			"WITH CLUSTERING ORDER BY (id DESC, name DESC);",
+	}, table.CreateIndexStmts())
+
+	table, err = orm.TableFromObject(&ValidObject{})
+	suite.NoError(err)
+	suite.Empty(table.CreateIndexStmts())
+}
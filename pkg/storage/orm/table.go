@@ -239,6 +239,18 @@ func TableFromObject(e base.Object) (*Table, error) {
 				parseCassandraObjectTag(tag); err != nil {
 				return nil, err
 			}
+
+			// Parse the secondary indexes and materialized views which
+			// allow looking up the object by non primary key columns
+			indexes, err := parseIndexTag(structField.Tag.Get(indexTag))
+			if err != nil {
+				return nil, err
+			}
+			views, err := parseViewTag(structField.Tag.Get(viewTag))
+			if err != nil {
+				return nil, err
+			}
+			t.Indexes = append(indexes, views...)
 		} else {
 			// For all other fields of this object, parse the column name tag
 			tag := strings.TrimSpace(structField.Tag.Get(columnTag))
@@ -262,9 +274,128 @@ func TableFromObject(e base.Object) (*Table, error) {
 			"cannot find orm.Object in object %v", e)
 	}
 
+	if err := t.validateIndexes(); err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
+// validateIndexes validates that the secondary indexes and materialized
+// views of the table are on its columns, and that the primary key of each
+// materialized view contains all the primary key columns of the table
+func (t *Table) validateIndexes() error {
+	names := make(map[string]bool)
+	for _, index := range t.Indexes {
+		if names[index.Name] || index.Name == t.Name {
+			return yarpcerrors.InternalErrorf(
+				"duplicate index %s on table %s", index.Name, t.Name)
+		}
+		names[index.Name] = true
+
+		for _, column := range index.Columns {
+			if _, ok := t.ColumnToType[column]; !ok {
+				return yarpcerrors.InternalErrorf(
+					"index %s is on unknown column %s of table %s",
+					index.Name, column, t.Name)
+			}
+		}
+		if !index.IsView() {
+			continue
+		}
+
+		viewKeys := make(map[string]bool)
+		for _, column := range keyColumns(index.Key) {
+			if _, ok := t.ColumnToType[column]; !ok {
+				return yarpcerrors.InternalErrorf(
+					"view %s has unknown key column %s of table %s",
+					index.Name, column, t.Name)
+			}
+			viewKeys[column] = true
+		}
+		for _, column := range keyColumns(t.Key) {
+			if !viewKeys[column] {
+				return yarpcerrors.InternalErrorf(
+					"view %s is missing key column %s of table %s",
+					index.Name, column, t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// keyColumns returns the names of the partition and clustering key columns
+// of a primary key
+func keyColumns(key *base.PrimaryKey) []string {
+	columns := append([]string{}, key.PartitionKeys...)
+	for _, ck := range key.ClusteringKeys {
+		columns = append(columns, ck.Name)
+	}
+	return columns
+}
+
+// GetIndex returns the secondary index or materialized view of the table
+// with the given name
+func (t *Table) GetIndex(name string) (*base.Index, error) {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			return index, nil
+		}
+	}
+	return nil, yarpcerrors.NotFoundErrorf(
+		"index %s not found on table %s", name, t.Name)
+}
+
+// GetIndexRowFromObject is a helper for generating a row of the values of
+// the columns of a secondary index or materialized view to be used in a
+// select query.
+func (t *Table) GetIndexRowFromObject(
+	e base.Object,
+	index *base.Index,
+) []base.Column {
+	v := reflect.ValueOf(e).Elem()
+	row := []base.Column{}
+
+	for _, column := range index.Columns {
+		value := v.FieldByName(t.ColToField[column])
+
+		// Special case for optional type:
+		// conversion needed from custom optional type
+		// into raw type understandable by DB layer
+		if base.IsOfTypeOptional(value) {
+			// nil value of type optional should not be accounted for
+			if !value.IsNil() {
+				row = append(row, base.Column{
+					Name:  column,
+					Value: base.ConvertFromOptionalToRawType(value),
+				})
+			}
+			continue
+		}
+
+		row = append(row, base.Column{
+			Name:  column,
+			Value: value.Interface(),
+		})
+	}
+	return row
+}
+
+// GetIndexDefinition returns the definition to be used when querying a
+// secondary index or materialized view of the table. A secondary index is
+// queried through the table itself, while a materialized view is queried
+// as a table of its own with the same columns.
+func (t *Table) GetIndexDefinition(index *base.Index) *base.Definition {
+	if !index.IsView() {
+		return &t.Definition
+	}
+	return &base.Definition{
+		Name:         index.Name,
+		Key:          index.Key,
+		ColumnToType: t.ColumnToType,
+	}
+}
+
 // BuildObjectIndex builds an index to map storage object type to its
 // Table representation
 func BuildObjectIndex(objects []base.Object) (
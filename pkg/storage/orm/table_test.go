@@ -35,6 +35,46 @@ type ValidObjectWithOptString struct {
 	Data        string               `column:"name=data"`
 }
 
+// IndexedObject is a representation of the orm annotations with a
+// secondary index and a materialized view
+type IndexedObject struct {
+	base.Object `cassandra:"name=indexed_object, primaryKey=((id), name)" index:"name=state_idx, columns=(state)" view:"name=indexed_object_by_data, primaryKey=((data), id, name)"`
+	ID          uint64 `column:"name=id"`
+	Name        string `column:"name=name"`
+	Data        string `column:"name=data"`
+	State       string `column:"name=state"`
+}
+
+// InvalidIndexObject1 has a secondary index on multiple columns
+type InvalidIndexObject1 struct {
+	base.Object `cassandra:"name=indexed_object, primaryKey=((id))" index:"name=data_idx, columns=(id, data)"`
+	ID          uint64 `column:"name=id"`
+	Data        string `column:"name=data"`
+}
+
+// InvalidIndexObject2 has a secondary index on an unknown column
+type InvalidIndexObject2 struct {
+	base.Object `cassandra:"name=indexed_object, primaryKey=((id))" index:"name=data_idx, columns=(state)"`
+	ID          uint64 `column:"name=id"`
+	Data        string `column:"name=data"`
+}
+
+// InvalidIndexObject3 has a materialized view missing a primary key column
+type InvalidIndexObject3 struct {
+	base.Object `cassandra:"name=indexed_object, primaryKey=((id), name)" view:"name=indexed_object_by_data, primaryKey=((data), id)"`
+	ID          uint64 `column:"name=id"`
+	Name        string `column:"name=name"`
+	Data        string `column:"name=data"`
+}
+
+// InvalidIndexObject4 has duplicate index names
+type InvalidIndexObject4 struct {
+	base.Object `cassandra:"name=indexed_object, primaryKey=((id))" index:"name=data_idx, columns=(data); name=data_idx, columns=(state)"`
+	ID          uint64 `column:"name=id"`
+	Data        string `column:"name=data"`
+	State       string `column:"name=state"`
+}
+
 // InvalidObject1 has primary key as empty
 type InvalidObject1 struct {
 	base.Object `cassandra:"name=valid_object, primaryKey=()"`
@@ -64,13 +104,47 @@ func (suite *ORMTestSuite) TestTableFromObject() {
 	suite.NoError(err)
 
 	tt := []base.Object{
-		&InvalidObject1{}, &InvalidObject2{}, &InvalidObject3{}}
+		&InvalidObject1{}, &InvalidObject2{}, &InvalidObject3{},
+		&InvalidIndexObject1{}, &InvalidIndexObject2{},
+		&InvalidIndexObject3{}, &InvalidIndexObject4{}}
 	for _, t := range tt {
 		_, err := orm.TableFromObject(t)
 		suite.Error(err)
 	}
 }
 
+// TestTableFromObjectWithIndexes tests parsing the secondary indexes and
+// materialized views of a base object
+func (suite *ORMTestSuite) TestTableFromObjectWithIndexes() {
+	table, err := orm.TableFromObject(&IndexedObject{})
+	suite.NoError(err)
+	suite.Len(table.Indexes, 2)
+
+	index, err := table.GetIndex("state_idx")
+	suite.NoError(err)
+	suite.False(index.IsView())
+	suite.Equal([]string{"state"}, index.Columns)
+	suite.Equal(&table.Definition, table.GetIndexDefinition(index))
+
+	view, err := table.GetIndex("indexed_object_by_data")
+	suite.NoError(err)
+	suite.True(view.IsView())
+	suite.Equal([]string{"data"}, view.Columns)
+	suite.Equal([]string{"data"}, view.Key.PartitionKeys)
+	suite.Len(view.Key.ClusteringKeys, 2)
+	def := table.GetIndexDefinition(view)
+	suite.Equal("indexed_object_by_data", def.Name)
+	suite.Equal(view.Key, def.Key)
+	suite.Equal(table.ColumnToType, def.ColumnToType)
+
+	_, err = table.GetIndex("unknown_idx")
+	suite.Error(err)
+
+	row := table.GetIndexRowFromObject(&IndexedObject{State: "RUNNING"}, index)
+	suite.ensureRowsEqual(
+		[]base.Column{{Name: "state", Value: "RUNNING"}}, row)
+}
+
 // TestGetRowFromObject tests building a row (list of base.Column) from base
 // object
 func (suite *ORMTestSuite) TestGetRowFromObject() {
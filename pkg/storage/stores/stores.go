@@ -20,12 +20,16 @@ import (
 	"github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra"
 	storage_config "github.com/uber/peloton/pkg/storage/config"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 )
 
 // MustCreateStore creates a generic store that is needed by peloton
-// and exits if store can't be created
+// and exits if store can't be created. The store shares the given ORM
+// store with the ORM objects of the process.
 func MustCreateStore(
-	cfg *storage_config.Config, rootScope tally.Scope) storage.Store {
+	cfg *storage_config.Config,
+	ormStore *ormobjects.Store,
+	rootScope tally.Scope) storage.Store {
 	log.WithFields(log.Fields{
 		"cassandra_connection": cfg.Cassandra.CassandraConn,
 		"cassandra_config":     cfg.Cassandra,
@@ -35,7 +39,7 @@ func MustCreateStore(
 			log.Fatalf("Could not migrate database: %+v", errs)
 		}
 	}
-	store, err := cassandra.NewStore(&cfg.Cassandra, rootScope)
+	store, err := cassandra.NewStoreWithORM(&cfg.Cassandra, ormStore, rootScope)
 	if err != nil {
 		log.Fatalf("Could not create cassandra store: %+v", err)
 	}